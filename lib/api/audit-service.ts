@@ -42,7 +42,7 @@ export class AuditServiceClient {
           throw new Error('Audit service is currently unavailable');
         } else {
           const error = await response.json();
-          throw new Error(error.message || 'Failed to fetch audit logs');
+          throw new Error(error.detail || 'Failed to fetch audit logs');
         }
       }
       
@@ -91,14 +91,14 @@ export class AuditServiceClient {
           throw new Error('Authentication failed: Invalid or expired token');
         } else if (response.status === 400) {
           const error = await response.json();
-          throw new Error(`Invalid request: ${error.message || 'Bad request'}`);
+          throw new Error(`Invalid request: ${error.detail || 'Bad request'}`);
         } else if (response.status === 404) {
           throw new Error('Audit service endpoint not found');
         } else if (response.status === 503) {
           throw new Error('Audit service is currently unavailable');
         } else {
           const error = await response.json();
-          throw new Error(error.message || 'Failed to create audit event');
+          throw new Error(error.detail || 'Failed to create audit event');
         }
       }
     } catch (error) {
@@ -3,6 +3,7 @@ import { fetchWithAuthAndCors } from '../api/api-utils';
 
 // Queue item structure
 interface QueuedAuditEvent {
+  idempotencyKey?: string; // Lets the audit service drop duplicates when a send is retried
   sessionId: string;
   type: AuditAction;
   details?: any;
@@ -60,6 +61,7 @@ export class AuditQueueService {
     
     const sessionId = this.currentSessionId;
     const event: QueuedAuditEvent = {
+      idempotencyKey: crypto.randomUUID(),
       sessionId,
       type,
       details,
@@ -204,6 +206,7 @@ export class AuditQueueService {
           method: 'POST',
           headers: {
             'Content-Type': 'application/json',
+            ...(event.idempotencyKey ? { 'Idempotency-Key': event.idempotencyKey } : {}),
           },
           body: JSON.stringify({
             sessionId: event.sessionId,
@@ -220,7 +223,7 @@ export class AuditQueueService {
           throw new Error('Authentication failed: Invalid or expired token');
         } else if (response.status === 400) {
           const error = await response.json();
-          throw new Error(`Invalid request: ${error.message || 'Bad request'}`);
+          throw new Error(`Invalid request: ${error.detail || 'Bad request'}`);
         } else if (response.status === 404) {
           throw new Error('Audit service endpoint not found');
         } else if (response.status === 503) {
@@ -43,7 +43,7 @@ export async function createServerAuditEvent(
     
     if (!response.ok) {
       const errorData = await response.json();
-      throw new Error(`Failed to create audit event: ${errorData.message || response.statusText}`);
+      throw new Error(`Failed to create audit event: ${errorData.detail || response.statusText}`);
     }
   } catch (error) {
     // Log the error but don't throw - audit logging should not break core functionality
@@ -0,0 +1,92 @@
+// Command archive-restore copies archived audit events from cold storage back into Supabase.
+//
+// Usage:
+//
+//	archive-restore -prefix view/2024/01 [-dry-run]
+//
+// The prefix is relative to ARCHIVE_PREFIX and selects the batches to restore, e.g. an event
+// type, a year, a month or a day. Connection settings are read from the same environment as the
+// server.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"audit-service/internal/archive"
+	"audit-service/internal/config"
+	"audit-service/internal/domain"
+	"audit-service/internal/repository"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	prefix := flag.String("prefix", "", "archive path to restore, relative to ARCHIVE_PREFIX (e.g. view/2024/01)")
+	dryRun := flag.Bool("dry-run", false, "verify and count archived events without inserting them")
+	flag.Parse()
+
+	if *prefix == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if cfg.ArchiveS3Endpoint == "" || cfg.ArchiveS3Bucket == "" {
+		log.Fatalf("ARCHIVE_S3_ENDPOINT and ARCHIVE_S3_BUCKET must be set")
+	}
+
+	zapLogger, err := logger.New(cfg.LogLevel, cfg.Secrets()...)
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer zapLogger.Sync()
+
+	clk := clock.New()
+	store, err := archive.NewS3Store(archive.S3Config{
+		Endpoint:        cfg.ArchiveS3Endpoint,
+		Region:          cfg.ArchiveS3Region,
+		Bucket:          cfg.ArchiveS3Bucket,
+		AccessKeyID:     cfg.ArchiveS3AccessKeyID,
+		SecretAccessKey: cfg.ArchiveS3SecretAccessKey,
+		PathStyle:       cfg.ArchiveS3PathStyle,
+	}, &http.Client{Timeout: cfg.HTTPTimeout}, clk)
+	if err != nil {
+		zapLogger.Fatal("failed to initialize archive store", zap.Error(err))
+	}
+	archiver := archive.NewArchiver(store, cfg.ArchivePrefix, clk, zapLogger)
+
+	insert := repository.NewAuditRepository(repository.NewSupabaseClient(cfg, zapLogger), zapLogger).CreateEvents
+	if *dryRun {
+		insert = func(context.Context, []domain.AuditEntry) error { return nil }
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	result, err := archiver.Restore(ctx, *prefix, insert)
+	if err != nil {
+		zapLogger.Fatal("restore failed",
+			zap.Int("batches_restored", result.Batches),
+			zap.Int("events_restored", result.Events),
+			zap.Error(err),
+		)
+	}
+
+	action := "Restored"
+	if *dryRun {
+		action = "Verified"
+	}
+	fmt.Printf("%s %d events from %d archived batches\n", action, result.Events, result.Batches)
+}
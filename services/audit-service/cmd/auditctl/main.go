@@ -0,0 +1,13 @@
+// Command auditctl administers a running audit service through its admin API; see package
+// auditctl for its commands. The service binary runs it as "audit-service auditctl".
+package main
+
+import (
+	"os"
+
+	"audit-service/internal/auditctl"
+)
+
+func main() {
+	auditctl.Run(os.Args[1:])
+}
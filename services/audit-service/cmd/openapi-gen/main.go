@@ -0,0 +1,31 @@
+// Command openapi-gen converts the Swagger 2.0 document generated by swag into the OpenAPI 3.0
+// document served at /api/v1/openapi.json. It runs through go generate after swag init:
+//
+//	swag init -g cmd/server/main.go -o docs && go generate ./docs
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"audit-service/internal/openapi"
+)
+
+func main() {
+	in := flag.String("in", "docs/swagger.json", "swagger 2.0 document generated by swag")
+	out := flag.String("out", "docs/openapi.json", "OpenAPI 3.0 document to write")
+	flag.Parse()
+
+	swagger, err := os.ReadFile(*in)
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v", *in, err)
+	}
+	spec, err := openapi.Convert(swagger)
+	if err != nil {
+		log.Fatalf("Failed to convert %s: %v", *in, err)
+	}
+	if err := os.WriteFile(*out, append(spec, '\n'), 0o644); err != nil {
+		log.Fatalf("Failed to write %s: %v", *out, err)
+	}
+}
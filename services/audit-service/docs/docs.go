@@ -24,40 +24,142 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/events": {
-            "post": {
+        "/admin/config": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Creates a new audit event for a session",
-                "consumes": [
+                "description": "Returns the configuration of this instance keyed by field name. Secrets such as keys, tokens and passwords are reported as [REDACTED] when set and empty when not; connection URLs keep everything but their password. Admin only.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get the running configuration",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/dlq": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists outbox messages of the admin's organization that ran out of delivery attempts, newest first, with the payload posted to the webhooks and the failure of the last delivery. Admin only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Audit"
+                    "Admin"
                 ],
-                "summary": "Create a new audit event",
+                "summary": "List dead letters",
                 "parameters": [
                     {
-                        "description": "Event details",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                        "type": "integer",
+                        "description": "Number of items to return (default: 50, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only dead letters with a lower ID, from a previous response's nextBefore",
+                        "name": "before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.CreateEventRequest"
+                            "$ref": "#/definitions/handlers.DeadLetterList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
                         }
                     }
+                }
+            }
+        },
+        "/admin/dlq/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Makes one outbox message that ran out of delivery attempts due again with a fresh attempt count, so the relay redelivers it to every webhook. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Retry a dead letter",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Dead letter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/handlers.CreateEventResponse"
+                            "$ref": "#/definitions/handlers.DeadLetterRetryResult"
                         }
                     },
                     "400": {
@@ -72,6 +174,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/domain.APIError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -81,31 +201,121 @@ const docTemplate = `{
                 }
             }
         },
-        "/sessions/{sessionId}/history": {
+        "/admin/events": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves paginated audit log entries for a specific session",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Searches the audit log of every session by user, event type and time window. Entries include the client IP and user agent. Admin only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Audit"
+                    "Admin"
                 ],
-                "summary": "Get audit history for a session",
+                "summary": "Query audit events across sessions",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Session ID",
+                        "description": "Only events of this session",
                         "name": "sessionId",
-                        "in": "path",
-                        "required": true
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events created by this user",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Event types, comma-separated or repeated",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Categories (content, access, security, system, usage), comma-separated or repeated",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Severities (info, warning, critical), comma-separated or repeated",
+                        "name": "severity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Labels, comma-separated or repeated; only events carrying one of them",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or after this RFC3339 timestamp",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or before this RFC3339 timestamp",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Free-text search over event details",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events of this correlation ID",
+                        "name": "correlationId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events whose details reference this slide",
+                        "name": "slideId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events whose details reference this shape",
+                        "name": "shapeId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events whose details reference this comment",
+                        "name": "commentId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events whose details reference this comment thread",
+                        "name": "threadId",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
@@ -121,8 +331,8 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Share token for reviewer access",
-                        "name": "share_token",
+                        "description": "Opaque cursor from a previous response's nextCursor",
+                        "name": "cursor",
                         "in": "query"
                     }
                 ],
@@ -151,6 +361,58 @@ const docTemplate = `{
                             "$ref": "#/definitions/domain.APIError"
                         }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/events/{id}/annotations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the annotation thread of an audit event, oldest first. Only reviewers and admins may read it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Annotations"
+                ],
+                "summary": "List the annotations of an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AnnotationThread"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -164,36 +426,5784 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "domain.APIError": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string"
-                },
-                "message": {
-                    "type": "string"
-                }
-            }
-        },
-        "domain.AuditAction": {
-            "type": "string",
-            "enum": [
-                "create",
-                "edit",
-                "merge",
-                "reorder",
-                "comment",
-                "export",
-                "share",
-                "unshare",
-                "view"
-            ],
-            "x-enum-varnames": [
-                "ActionCreate",
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds an annotation, such as a finding of a security investigation, to the thread of an audit event, with its version in the ETag header. Annotations are stored beside the event, which stays unchanged, and are recorded as annotation_created events of the session, children of the annotated event, naming the annotation but not its body. Only reviewers and admins may annotate events.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Annotations"
+                ],
+                "summary": "Annotate an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Annotation",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.EventAnnotationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EventAnnotation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/events/{id}/annotations/{annotationId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns an annotation of an audit event, with its version in the ETag header. Only reviewers and admins may read it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Annotations"
+                ],
+                "summary": "Get an annotation of an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Annotation ID",
+                        "name": "annotationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EventAnnotation"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the body of an annotation. The If-Match header must carry the ETag of the annotation the edit was made against; editing an annotation changed since is answered with 412. Only its author may edit an annotation; each edit is recorded as an annotation_edited event.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Annotations"
+                ],
+                "summary": "Edit an annotation of an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Annotation ID",
+                        "name": "annotationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the annotation being edited",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Annotation",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.EventAnnotationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EventAnnotation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes an annotation from the thread of an audit event. The If-Match header must carry the ETag of the annotation being deleted. Only its author may delete an annotation; the deletion is recorded as an annotation_deleted event.",
+                "tags": [
+                    "Annotations"
+                ],
+                "summary": "Delete an annotation of an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Annotation ID",
+                        "name": "annotationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the annotation being deleted",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/outbox/replay": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Makes outbox messages that ran out of delivery attempts due again with a fresh attempt count, so the relay redelivers them. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Replay dead outbox messages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only messages created at or after this RFC3339 timestamp",
+                        "name": "since",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OutboxReplayResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/retention/run": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes expired events of every retention policy now instead of at the next scheduled run and records the usual retention_purge events. Waits for a scheduled run in progress. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Run the retention purge",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RetentionRunResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rollups/run": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rebuilds the hourly and daily activity rollups from the recorded events now instead of at the next scheduled run. Pass from to backfill history older than the scheduled lookback; rollups are rebuilt from the start of its UTC day. Waits for a scheduled run in progress. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Rebuild activity rollups",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rebuild from this RFC3339 timestamp instead of the scheduled lookback",
+                        "name": "from",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RollupRunResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/sessions/{sessionId}/events/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Streams every audit entry matching the filters as a CSV or JSON download, newest first, up to the configured row cap. The ocsf format is a JSON array of OCSF 1.1.0 events. Admins may export any session under /admin.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Export audit history for a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Export format: csv, json or ocsf (default: json)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated event types (e.g. edit,comment)",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated categories: content, access, security, system or usage",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated severities: info, warning or critical",
+                        "name": "severity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events created by this user",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or after this RFC3339 timestamp",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or before this RFC3339 timestamp",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Free-text search over event details",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for reviewer access",
+                        "name": "share_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.AuditEntry"
+                            }
+                        },
+                        "headers": {
+                            "X-Export-Truncated": {
+                                "type": "boolean",
+                                "description": "Present when the row cap was reached"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Number of matching entries"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/sessions/{sessionId}/events/verify": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Recomputes the per-session hash chain and reports entries that were modified, removed or re-linked. Entries recorded before hash chaining was enabled are not checked. Admins may verify any session under /admin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Verify the audit trail of a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for reviewer access",
+                        "name": "share_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ChainVerification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/erasure-jobs/{jobId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the progress of a user erasure job. Finished jobs are kept for 24 hours. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get an erasure job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Erasure job ID",
+                        "name": "jobId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErasureJob"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/event-types": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the built-in event types and the custom types registered with POST /event-types. Events of any other type are rejected.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "List event types",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.EventTypeList"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Registers an event type with a display name, severity and optional JSON schema for its details. Events of the type are accepted by POST /events from then on. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Register a custom event type",
+                "parameters": [
+                    {
+                        "description": "Event type",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateEventTypeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EventType"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Creates a new audit event for a session. Accepts a JSON or protobuf event; an NDJSON body is ingested as a batch. Events over the daily quota of their session or user are rejected with 429 and a Retry-After header. Review workflow events that the review state of their slide or shape does not allow are rejected with 409.",
+                "consumes": [
+                    "application/json",
+                    "application/protobuf",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/protobuf"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Create a new audit event",
+                "parameters": [
+                    {
+                        "description": "Event details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateEventRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key deduplicating retried submissions; defaults to the event id",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateEventResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/batch": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Creates up to 100 JSON events, or up to 5000 NDJSON or protobuf events, in a single request and persists them in one database call. Nothing is stored when an event is invalid; the error names the index of the first invalid event. Review workflow events are checked in order, so a batch may take a slide through several review states.",
+                "consumes": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "application/protobuf"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/protobuf"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Create multiple audit events",
+                "parameters": [
+                    {
+                        "description": "Events to create",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.CreateEventRequest"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key deduplicating retried submissions of the batch",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "gzip or deflate when the body is compressed",
+                        "name": "Content-Encoding",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BatchCreateEventResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BatchEventError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Imports a gzipped NDJSON file of historical events, one event per line with the fields of the events API and the time it happened, into the caller's organization. The file is uploaded as the multipart part \"file\", or fetched from the signed HTTPS URL of a JSON body. Lines are validated like events recorded through the API; invalid lines are skipped and reported on the job. Events whose ID is already stored, or repeated in the file, are skipped, so a failed import can be run again. With dryRun the file is only validated. The work runs in the background; poll the returned job for progress. Admin only.",
+                "consumes": [
+                    "multipart/form-data",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Import historical audit events",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Gzipped NDJSON file",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "description": "Signed URL of the file, instead of an upload",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ImportRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the file and count duplicates (default: false)",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ImportJob"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the import job"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/{id}/annotations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the annotation thread of an audit event, oldest first. Only reviewers and admins may read it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Annotations"
+                ],
+                "summary": "List the annotations of an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AnnotationThread"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds an annotation, such as a finding of a security investigation, to the thread of an audit event, with its version in the ETag header. Annotations are stored beside the event, which stays unchanged, and are recorded as annotation_created events of the session, children of the annotated event, naming the annotation but not its body. Only reviewers and admins may annotate events.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Annotations"
+                ],
+                "summary": "Annotate an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Annotation",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.EventAnnotationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EventAnnotation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/{id}/annotations/{annotationId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns an annotation of an audit event, with its version in the ETag header. Only reviewers and admins may read it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Annotations"
+                ],
+                "summary": "Get an annotation of an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Annotation ID",
+                        "name": "annotationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EventAnnotation"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the body of an annotation. The If-Match header must carry the ETag of the annotation the edit was made against; editing an annotation changed since is answered with 412. Only its author may edit an annotation; each edit is recorded as an annotation_edited event.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Annotations"
+                ],
+                "summary": "Edit an annotation of an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Annotation ID",
+                        "name": "annotationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the annotation being edited",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Annotation",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.EventAnnotationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EventAnnotation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes an annotation from the thread of an audit event. The If-Match header must carry the ETag of the annotation being deleted. Only its author may delete an annotation; the deletion is recorded as an annotation_deleted event.",
+                "tags": [
+                    "Annotations"
+                ],
+                "summary": "Delete an annotation of an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Annotation ID",
+                        "name": "annotationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the annotation being deleted",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/{id}/chain": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the events of the same session that share the event's correlation ID, plus its ancestors reached through parentEventId, oldest first. Only the session owner may read it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get the causal chain of an audit event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EventChain"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/{id}/labels": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the labels attached to an audit event, with the version of the label set in the ETag header; version 0 and no labels for events never labeled. Reviewers, admins and the session owner may read them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Labels"
+                ],
+                "summary": "Get the labels of an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EventLabels"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the labels of an audit event. The If-Match header must carry the ETag of the label set the replacement was made against; replacing labels another reviewer changed since is answered with 412. Reviewers, admins and the session owner may label its events.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Labels"
+                ],
+                "summary": "Replace the labels of an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the label set being replaced",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Labels",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.EventLabelsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EventLabels"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Attaches labels, such as \"billing-relevant\" or \"disputed\", to an audit event, keeping those it already carries. Labels are lowercase slugs of letters, digits, hyphens and underscores; an event carries at most 20. They are stored beside the event, which stays unchanged, and filter the event queries through their label parameter. Reviewers, admins and the session owner may label its events.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Labels"
+                ],
+                "summary": "Label an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Labels to add",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.EventLabelsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EventLabels"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/events/{id}/labels/{label}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Detaches a label from an audit event and returns the remaining labels. Removing a label the event does not carry changes nothing. Reviewers, admins and the session owner may label its events.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Labels"
+                ],
+                "summary": "Remove a label from an event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label",
+                        "name": "label",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EventLabels"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports/callbacks": {
+            "post": {
+                "description": "Records a lifecycle step (queued, rendering, uploaded, failed) of an export rendered by the export worker. The request is signed like outbox deliveries: X-Audit-Timestamp holds the Unix time and X-Audit-Signature \"sha256=\" and the hex HMAC-SHA256 of \"timestamp.body\" with EXPORT_CALLBACK_SECRET. Callbacks signed more than EXPORT_CALLBACK_TOLERANCE away from server time are rejected. A step already recorded for the export is answered with 200 and not stored again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exports"
+                ],
+                "summary": "Report an export step",
+                "parameters": [
+                    {
+                        "description": "Export step",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ExportCallback"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unix time the callback was signed at",
+                        "name": "X-Audit-Timestamp",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "sha256= and the hex HMAC-SHA256 of timestamp.body",
+                        "name": "X-Audit-Signature",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ExportRecord"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ExportRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports/{exportId}/audit": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the export record stitched from the export event of the frontend and the lifecycle steps reported by the export worker, correlated by the export ID. Only readers of the session of the export may read it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exports"
+                ],
+                "summary": "Get the audit record of an export",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Export ID",
+                        "name": "exportId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ExportRecord"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/import-jobs/{jobId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the progress of an import job, with the first rejected lines. Finished jobs are kept for 24 hours. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get an import job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Import job ID",
+                        "name": "jobId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ImportJob"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/legal-holds": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists legal holds newest first, including released ones unless active is set. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List legal holds",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only holds that are still in force (default: false)",
+                        "name": "active",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LegalHoldList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Exempts every entry of a session, or every entry created by a user, from retention purges and erasure until the hold is released. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Place a legal hold",
+                "parameters": [
+                    {
+                        "description": "Legal hold",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateLegalHoldRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.LegalHold"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/legal-holds/{holdId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ends a legal hold so retention and erasure apply to its entries again. The hold is kept with its release details. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Release a legal hold",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Legal hold ID",
+                        "name": "holdId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.LegalHold"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/queries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the saved queries of the caller and those shared with the organization, by name.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Saved Queries"
+                ],
+                "summary": "List saved queries",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SavedQueryList"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Saves a named filter set of the event queries, such as \"My edits this week\". The filter takes the filters of the session events query; days restricts the results to the last days before each run and a userId of \"me\" stands for the user running the query. Private queries are seen by the caller only; organization queries by every user of the organization.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Saved Queries"
+                ],
+                "summary": "Save a query",
+                "parameters": [
+                    {
+                        "description": "Saved query",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SavedQueryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SavedQuery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/queries/{queryId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a saved query of the caller or one shared with the organization.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Saved Queries"
+                ],
+                "summary": "Get a saved query",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Saved query ID",
+                        "name": "queryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SavedQuery"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the name, description, scope and filter of a saved query. Only the creator of a query may change it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Saved Queries"
+                ],
+                "summary": "Replace a saved query",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Saved query ID",
+                        "name": "queryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Saved query",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SavedQueryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SavedQuery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes a saved query. Only the creator of a query may delete it.",
+                "tags": [
+                    "Saved Queries"
+                ],
+                "summary": "Delete a saved query",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Saved query ID",
+                        "name": "queryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/replay": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Re-delivers the stored events of the caller's organization in a time range, optionally of some types or one session, to a consumer that lost data. The webhook target posts them to one of the configured outbox webhooks, the outbox target queues them in the outbox, which forwards them to every outbox webhook. Events are sent newest first at no more than ratePerSecond. The work runs in the background; poll the returned job for progress. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Replay events to downstream consumers",
+                "parameters": [
+                    {
+                        "description": "Events to replay and where to send them",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReplayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ReplayJob"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the replay job"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/replay-jobs/{jobId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the progress of a replay job. Finished jobs are kept for 24 hours. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get a replay job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Replay job ID",
+                        "name": "jobId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ReplayJob"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the scheduled reports of the organization of the caller by name. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "List reports",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportList"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Schedules a recurring session activity or export summary report of the organization of the caller. Each run covers the events since the previous scheduled run and is delivered as CSV or table-shaped JSON to a webhook or Supabase Storage. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Schedule a report",
+                "parameters": [
+                    {
+                        "description": "Report",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateReportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{reportId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a scheduled report with its next run. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get a report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report ID",
+                        "name": "reportId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Report"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stops a scheduled report and deletes its run history. Delivered reports are kept. Admin only.",
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Delete a report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report ID",
+                        "name": "reportId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{reportId}/runs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the latest scheduled and manual runs of a report, newest first. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "List report runs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report ID",
+                        "name": "reportId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of runs (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportRunList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Generates and delivers a report outside of its schedule, by default for the events since its last scheduled run. The schedule is not changed. A failed generation or delivery is returned as a run with status failed. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Run a report now",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report ID",
+                        "name": "reportId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reported period",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RunReportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ReportRun"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/revocations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists token revocations newest first, including lifted and expired ones unless active is set. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List token revocations",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only revocations that are still in force (default: false)",
+                        "name": "active",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RevocationList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rejects every token of a user, the tokens of a login session (session_id claim) or a single token (jti claim) from the next request on, until the revocation is lifted or expires. Only tokens of the admin's organization are affected. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Revoke access tokens",
+                "parameters": [
+                    {
+                        "description": "Revocation",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateRevocationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Revocation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/revocations/{revocationId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ends a revocation so the tokens it covered are accepted again until they expire. The revocation is kept with its lift details. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Lift a token revocation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Revocation ID",
+                        "name": "revocationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Revocation"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/access-review": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Takes a point-in-time snapshot of who opened a session, from its view events: every user with the access method (jwt or share_token) and share link they came through, their number of views, first and last access and client addresses, the most recent first. Views that do not record details.accessMethod are reported as unknown. Only the owner of the session and admins may take the snapshot; share tokens are rejected.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get the access review of a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Response format, json (default) or csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only views at or after this RFC3339 timestamp",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only views at or before this RFC3339 timestamp",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AccessReview"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/activity": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns event counts per user and hour or day, served from rollups rebuilt on a schedule; events recorded since the last rollup run are not counted yet. Without a range the last 30 buckets are returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get the activity timeline of a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "day",
+                            "hour"
+                        ],
+                        "type": "string",
+                        "description": "Bucket length, day (default) or hour",
+                        "name": "granularity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only buckets starting at or after this RFC3339 timestamp, rounded down to a bucket",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only buckets starting before this RFC3339 timestamp, rounded up to a bucket",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only activity of this user",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for reviewer access",
+                        "name": "share_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SessionActivity"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/comments/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves the comment lifecycle events of a session (comment_created, comment_edited, comment_resolved and comment_deleted), newest first, read from the comment and thread indexes. threadId and commentId narrow the activity to one thread or comment; type narrows it to some of the lifecycle types.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Query the comment activity of a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events of this comment thread",
+                        "name": "threadId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events of this comment",
+                        "name": "commentId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated comment lifecycle types (default: all four)",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated labels; only events carrying one of them",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events created by this user",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or after this RFC3339 timestamp",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or before this RFC3339 timestamp",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to return (default: 50, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip (default: 0)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from a previous response's nextCursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for reviewer access",
+                        "name": "share_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response; answered with 304 when unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Consistency token returned by event creation; waits until the events it names are readable",
+                        "name": "Consistency-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuditResponse"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/contributors": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns per-user counts of edits, comments and merges with the time of their first and last activity, most active users first. Comments count comment and comment_created events. Events written by the service itself are left out. Without a range the whole history of the session is counted.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get the contribution report of a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Response format, json (default) or csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or after this RFC3339 timestamp",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or before this RFC3339 timestamp",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for reviewer access",
+                        "name": "share_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SessionContributors"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves audit log entries for a session filtered by type, user, time range and free-text search over details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Query audit events for a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated event types (e.g. edit,comment)",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated categories: content, access, security, system or usage",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated severities: info, warning or critical",
+                        "name": "severity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated labels; only events carrying one of them",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events created by this user",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or after this RFC3339 timestamp",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or before this RFC3339 timestamp",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Free-text search over event details",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events of this correlation ID",
+                        "name": "correlationId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events whose details reference this slide",
+                        "name": "slideId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events whose details reference this shape",
+                        "name": "shapeId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events whose details reference this comment",
+                        "name": "commentId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events whose details reference this comment thread",
+                        "name": "threadId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to return (default: 50, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip (default: 0)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from a previous response's nextCursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for reviewer access",
+                        "name": "share_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response; answered with 304 when unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Consistency token returned by event creation; waits until the events it names are readable",
+                        "name": "Consistency-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuditResponse"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/events/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Streams every audit entry matching the filters as a CSV or JSON download, newest first, up to the configured row cap. The ocsf format is a JSON array of OCSF 1.1.0 events. Admins may export any session under /admin.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Export audit history for a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Export format: csv, json or ocsf (default: json)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated event types (e.g. edit,comment)",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated categories: content, access, security, system or usage",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated severities: info, warning or critical",
+                        "name": "severity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated labels; only events carrying one of them",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events created by this user",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or after this RFC3339 timestamp",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or before this RFC3339 timestamp",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Free-text search over event details",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for reviewer access",
+                        "name": "share_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.AuditEntry"
+                            }
+                        },
+                        "headers": {
+                            "X-Export-Truncated": {
+                                "type": "boolean",
+                                "description": "Present when the row cap was reached"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Number of matching entries"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/events/stream": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Pushes audit entries as Server-Sent Events (\"audit\" event, JSON data) as soon as they are created",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Stream live audit events for a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for reviewer access",
+                        "name": "share_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuditEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/events/verify": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Recomputes the per-session hash chain and reports entries that were modified, removed or re-linked. Entries recorded before hash chaining was enabled are not checked. Admins may verify any session under /admin.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Verify the audit trail of a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for reviewer access",
+                        "name": "share_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ChainVerification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/events/{eventId}/diff": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the change an edit event made to the text, as spans of equal, inserted and deleted text ready to display. The diff computed when the event was recorded is used when present; older edits are diffed when read. Share tokens of the session may read it; events without text.before and text.after answer 422.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get the text diff of an edit event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the edit event",
+                        "name": "eventId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for reviewer access",
+                        "name": "share_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EditDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/events/{eventId}/revert-payload": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the edit event that undoes an edit of the session: the recorded text.before and state.before become the after values. Send it to POST /events to record the undo. Only the session owner may read it; edits without a before value, or whose before value was redacted, answer 422.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get the inverse of an edit event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the edit event to revert",
+                        "name": "eventId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.RevertPayload"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/heatmap": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns event counts per slide and UTC hour, ordered by slide index, aggregated from the events that reference a slide. Without a range the last 7 days are returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get the slide heatmap of a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only hours starting at or after this RFC3339 timestamp, rounded down to an hour",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only hours starting before this RFC3339 timestamp, rounded up to an hour",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events of this user",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for reviewer access",
+                        "name": "share_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SessionHeatmap"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves paginated audit log entries for a specific session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get audit history for a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to return (default: 50, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip (default: 0)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from a previous response's nextCursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for reviewer access",
+                        "name": "share_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response; answered with 304 when unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Consistency token returned by event creation; waits until the events it names are readable",
+                        "name": "Consistency-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuditResponse"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/mt/costs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rolls up the mt_request events of a session per provider, model and currency: requests, failed requests, characters sent and returned, latency and estimated cost, the most expensive first, with the cost totals per currency. Without a range the whole history of the session is counted; at most 100000 requests are, and truncated is set when there were more.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get the machine translation costs of a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only requests at or after this RFC3339 timestamp",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only requests at or before this RFC3339 timestamp",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for reviewer access",
+                        "name": "share_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SessionMTCosts"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/queries/{queryId}/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves the audit log entries of a session that match a saved query of the caller or one shared with the organization, newest first. The relative time range and the \"me\" user filter are resolved for the caller at the time of the request. Share tokens cannot run saved queries.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Saved Queries"
+                ],
+                "summary": "Run a saved query",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Saved query ID",
+                        "name": "queryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to return (default: 50, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip (default: 0)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from a previous response's nextCursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response; answered with 304 when unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Consistency token returned by event creation; waits until the events it names are readable",
+                        "name": "Consistency-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuditResponse"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/quota": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns how many events the session and the caller recorded today, out of their daily quotas, and when the quotas reset. Quotas reset at midnight UTC; a limit of 0 is unlimited and reports no use.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get the event quotas of a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.QuotaStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/slides/{slideId}/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves the audit log entries of a session whose details reference the slide through slideId, newest first, read from an index. Takes the filters and pagination of the session events query; shapeId narrows the history to one shape of the slide.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Query the audit events of a slide",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Slide ID as sent in details.slideId",
+                        "name": "slideId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events whose details reference this shape",
+                        "name": "shapeId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated event types (e.g. edit,comment)",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated categories: content, access, security, system or usage",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated severities: info, warning or critical",
+                        "name": "severity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated labels; only events carrying one of them",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events created by this user",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or after this RFC3339 timestamp",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or before this RFC3339 timestamp",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to return (default: 50, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip (default: 0)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from a previous response's nextCursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for reviewer access",
+                        "name": "share_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response; answered with 304 when unchanged",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Consistency token returned by event creation; waits until the events it names are readable",
+                        "name": "Consistency-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuditResponse"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/stats": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns event counts by type, distinct contributors, first and last activity and edits per slide",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get audit statistics for a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for reviewer access",
+                        "name": "share_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Consistency token returned by event creation; waits until the events it names are readable",
+                        "name": "Consistency-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SessionStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/timeline": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Merges the events of a session into a compacted, human-readable timeline, the oldest first. Consecutive edits, merges and reorders of a user form one burst, and consecutive views of a user one run, while each follows the previous within gapSeconds; any other event of the user ends them. Other events are single groups. Each group has a summary for display and an ID derived from its first event, which stays the same as later events join the group. At most the newest 10000 events of the range are merged, and truncated is set when there were more.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get the timeline of a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or after this RFC3339 timestamp",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or before this RFC3339 timestamp",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Longest pause within a burst of edits or a run of views, 1 to 86400 seconds (default 300)",
+                        "name": "gapSeconds",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for reviewer access",
+                        "name": "share_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SessionTimeline"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/tm/leverage": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Counts the tm_hit and tm_override events of a session: TM matches offered, accepted and rejected, overrides of accepted matches, leveraged words and the hits per match band. Without a range the whole history of the session is counted; at most 100000 events are, and truncated is set when there were more.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get the translation memory leverage of a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or after this RFC3339 timestamp",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or before this RFC3339 timestamp",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for reviewer access",
+                        "name": "share_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SessionTMLeverage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{sessionId}/watch": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the event types of the session the caller watches.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watches"
+                ],
+                "summary": "Get the watch of a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Watch"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Subscribes the caller to share, export and comment events of a session, which are forwarded to the notification sink in digests. Events of the caller are not forwarded. Watching a session again replaces its event types. Share tokens cannot watch sessions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watches"
+                ],
+                "summary": "Watch a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Event types to watch",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WatchSessionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Watch"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Unsubscribes the caller from a session. Events already collected for the next digest are still sent.",
+                "tags": [
+                    "Watches"
+                ],
+                "summary": "Stop watching a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/share-tokens/{tokenId}/audit": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the lifecycle of a share link stitched from its events correlated by the token ID: the share event that created it, every redemption with the client IP, user agent, time and requested path, the first use after it expired and the unshare event that revoked it. Redemptions by the same client of the same path within five minutes are recorded once. Only readers of the session of the link may read it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sharing"
+                ],
+                "summary": "Get the audit trail of a share link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token ID (jti) of the share link",
+                        "name": "tokenId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ShareTrail"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userId}/events": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Anonymizes or deletes every audit entry created by a user across all sessions. Entries under a legal hold are kept unless overrideHolds is set with a reason, which is recorded in the erasure events. The work runs in the background; poll the returned job for progress. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Erase a user's audit entries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Erasure mode: anonymize or delete (default: anonymize)",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also erase entries under a legal hold (default: false)",
+                        "name": "overrideHolds",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Why legal holds are overridden; required with overrideHolds",
+                        "name": "overrideReason",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErasureJob"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the erasure job"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/watches": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the sessions the caller watches, most recently watched first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watches"
+                ],
+                "summary": "List watched sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WatchList"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upgrades to a WebSocket. Clients send {\"action\":\"subscribe\",\"sessionId\":\"...\"} or {\"action\":\"subscribe\",\"userId\":\"...\"} and receive {\"type\":\"event\",\"topic\":\"...\",\"event\":{...}} messages.",
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Subscribe to live audit events over WebSocket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT for clients that cannot set the Authorization header",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.APIError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "not_found"
+                },
+                "detail": {
+                    "description": "Message explains this occurrence of the problem",
+                    "type": "string",
+                    "example": "The requested resource was not found"
+                },
+                "instance": {
+                    "description": "Instance is the path of the request that failed",
+                    "type": "string",
+                    "example": "/api/v1/sessions/550e8400-e29b-41d4-a716-446655440000/history"
+                },
+                "limit": {
+                    "description": "Limit describes the payload of a payload_too_large problem",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.PayloadLimit"
+                        }
+                    ]
+                },
+                "quota": {
+                    "description": "Quota describes the exhausted quota of a quota_exceeded problem",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.QuotaUsage"
+                        }
+                    ]
+                },
+                "requestId": {
+                    "type": "string",
+                    "example": "5c9e1a7b-2d4f-4e8a-b3c6-7f0d1e2a3b4c"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "description": "Title is the HTTP status text of the problem",
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "description": "Type identifies the kind of problem; it is ProblemTypePrefix followed by Code",
+                    "type": "string",
+                    "example": "urn:audit-service:problem:not_found"
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.SchemaViolation"
+                    }
+                }
+            }
+        },
+        "domain.AccessMethod": {
+            "type": "string",
+            "enum": [
+                "jwt",
+                "share_token",
+                "unknown"
+            ],
+            "x-enum-varnames": [
+                "AccessJWT",
+                "AccessShareToken",
+                "AccessUnknown"
+            ]
+        },
+        "domain.AccessReview": {
+            "type": "object",
+            "properties": {
+                "accessors": {
+                    "description": "Accessors lists the accessors, the most recent first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AccessReviewEntry"
+                    }
+                },
+                "from": {
+                    "description": "From and To repeat the requested range; omitted when open",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "generatedAt": {
+                    "description": "GeneratedAt is the time the snapshot was taken",
+                    "type": "string",
+                    "example": "2024-02-01T08:00:00Z"
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2024-01-31T23:59:59Z"
+                },
+                "totalViews": {
+                    "type": "integer",
+                    "example": 23
+                },
+                "truncated": {
+                    "description": "Truncated is set when the session had more than MaxAccessReviewEvents views in the range",
+                    "type": "boolean"
+                },
+                "users": {
+                    "description": "Users counts the distinct users among the accessors",
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "domain.AccessReviewEntry": {
+            "type": "object",
+            "properties": {
+                "accessMethod": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AccessMethod"
+                        }
+                    ],
+                    "example": "share_token"
+                },
+                "firstAccess": {
+                    "type": "string",
+                    "example": "2024-01-02T09:12:00Z"
+                },
+                "ipAddresses": {
+                    "description": "IPAddresses lists the distinct client addresses of the views, at most 10",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "203.0.113.7"
+                    ]
+                },
+                "lastAccess": {
+                    "type": "string",
+                    "example": "2024-01-05T17:40:00Z"
+                },
+                "shareTokenId": {
+                    "type": "string",
+                    "example": "share-91c2"
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440003"
+                },
+                "views": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "domain.ActivityBucket": {
+            "type": "object",
+            "properties": {
+                "byType": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "eventCount": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440003"
+                }
+            }
+        },
+        "domain.ActivityGranularity": {
+            "type": "string",
+            "enum": [
+                "hour",
+                "day"
+            ],
+            "x-enum-varnames": [
+                "ActivityHour",
+                "ActivityDay"
+            ]
+        },
+        "domain.AnnotationThread": {
+            "type": "object",
+            "properties": {
+                "eventId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.EventAnnotation"
+                    }
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "domain.AuditAction": {
+            "type": "string",
+            "enum": [
+                "create",
+                "edit",
+                "merge",
+                "reorder",
+                "comment",
+                "export",
+                "share",
+                "unshare",
+                "view",
+                "share_redeemed",
+                "share_expired",
+                "thumbnail",
+                "export_lifecycle",
+                "comment_created",
+                "comment_edited",
+                "comment_resolved",
+                "comment_deleted",
+                "submitted_for_review",
+                "approved",
+                "rejected",
+                "reopened",
+                "tm_hit",
+                "tm_override",
+                "mt_request",
+                "retention_purge",
+                "user_erasure",
+                "security_alert",
+                "external_change",
+                "config_changed",
+                "auth_failure"
+            ],
+            "x-enum-varnames": [
+                "ActionCreate",
                 "ActionEdit",
                 "ActionMerge",
                 "ActionReorder",
@@ -201,104 +6211,2869 @@ const docTemplate = `{
                 "ActionExport",
                 "ActionShare",
                 "ActionUnshare",
-                "ActionView"
+                "ActionView",
+                "ActionShareRedeemed",
+                "ActionShareExpired",
+                "ActionThumbnail",
+                "ActionExportLifecycle",
+                "ActionCommentCreated",
+                "ActionCommentEdited",
+                "ActionCommentResolved",
+                "ActionCommentDeleted",
+                "ActionSubmittedForReview",
+                "ActionApproved",
+                "ActionRejected",
+                "ActionReopened",
+                "ActionTMHit",
+                "ActionTMOverride",
+                "ActionMTRequest",
+                "ActionRetentionPurge",
+                "ActionUserErasure",
+                "ActionSecurityAlert",
+                "ActionExternalChange",
+                "ActionConfigChanged",
+                "ActionAuthFailure"
+            ]
+        },
+        "domain.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "Category and Severity classify the entry's type in the taxonomy; they are set on reads\nand not stored",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.EventCategory"
+                        }
+                    ],
+                    "example": "content"
+                },
+                "commentId": {
+                    "description": "CommentID and ThreadID repeat details.commentId and details.threadId as indexed columns",
+                    "type": "string",
+                    "example": "comment-7"
+                },
+                "correlationId": {
+                    "description": "CorrelationID groups the events of one operation, e.g. an export from request to download",
+                    "type": "string",
+                    "example": "export-7f3a"
+                },
+                "details": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "ipAddress": {
+                    "type": "string",
+                    "example": "192.168.1.1"
+                },
+                "organizationId": {
+                    "description": "OrganizationID is the tenant the entry belongs to; empty for the default organization",
+                    "type": "string",
+                    "example": "acme"
+                },
+                "parentEventId": {
+                    "description": "ParentEventID references the event that caused this one",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440003"
+                },
+                "receivedAt": {
+                    "description": "ReceivedAt is the server time the event arrived at, while Timestamp is the client time it\nhappened at; nil for entries the service wrote itself and entries stored before it existed",
+                    "type": "string",
+                    "example": "2023-12-01T10:30:02Z"
+                },
+                "redactedFields": {
+                    "description": "RedactedFields lists the paths of details values masked on ingestion, e.g. shapes[0].text",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "after"
+                    ]
+                },
+                "schemaVersion": {
+                    "description": "SchemaVersion is the version of the action's details schema; zero for entries stored before versioning",
+                    "type": "integer",
+                    "example": 2
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "severity": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.EntrySeverity"
+                        }
+                    ],
+                    "example": "info"
+                },
+                "shapeId": {
+                    "type": "string",
+                    "example": "shape-12"
+                },
+                "slideId": {
+                    "description": "SlideID and ShapeID repeat details.slideId and details.shapeId as indexed columns; they are\nnot hashed separately since the details are",
+                    "type": "string",
+                    "example": "slide-3"
+                },
+                "threadId": {
+                    "type": "string",
+                    "example": "thread-2"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-12-01T10:30:00Z"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "edit"
+                },
+                "userAgent": {
+                    "type": "string",
+                    "example": "Mozilla/5.0"
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440002"
+                }
+            }
+        },
+        "domain.AuditResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AuditEntry"
+                    }
+                },
+                "nextCursor": {
+                    "type": "string",
+                    "example": "MjAyMy0xMi0wMVQxMDozMDowMFp8NTUwZTg0MDA"
+                },
+                "totalCount": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "domain.ChainIssue": {
+            "type": "object",
+            "properties": {
+                "eventId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "stored entry does not match its content hash"
+                },
+                "problem": {
+                    "type": "string",
+                    "example": "content_mismatch"
+                },
+                "seq": {
+                    "type": "integer",
+                    "example": 17
+                }
+            }
+        },
+        "domain.ChainVerification": {
+            "type": "object",
+            "properties": {
+                "checkedEntries": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "firstSeq": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "headHash": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ChainIssue"
+                    }
+                },
+                "lastSeq": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "redactedEntries": {
+                    "description": "RedactedEntries were anonymized on request; their links are checked but not their content",
+                    "type": "integer",
+                    "example": 0
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "valid": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "domain.ContributorStats": {
+            "type": "object",
+            "properties": {
+                "comments": {
+                    "description": "Comments counts comment and comment_created events; edits, resolutions and deletions of\ncomments are not contributions of their own",
+                    "type": "integer",
+                    "example": 14
+                },
+                "edits": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "firstActivity": {
+                    "type": "string",
+                    "example": "2024-01-02T09:12:00Z"
+                },
+                "lastActivity": {
+                    "type": "string",
+                    "example": "2024-01-05T17:40:00Z"
+                },
+                "merges": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "totalEvents": {
+                    "type": "integer",
+                    "example": 162
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440003"
+                }
+            }
+        },
+        "domain.DeadLetter": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Attempts counts the failed deliveries",
+                    "type": "integer",
+                    "example": 8
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-01-01T10:00:00Z"
+                },
+                "deadAt": {
+                    "type": "string",
+                    "example": "2024-01-01T12:04:00Z"
+                },
+                "eventId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440002"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "lastError": {
+                    "description": "LastError is the failure of the last delivery",
+                    "type": "string",
+                    "example": "webhook responded with status 503"
+                },
+                "payload": {
+                    "description": "Payload is the event body posted to the webhooks",
+                    "type": "object"
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "share"
+                }
+            }
+        },
+        "domain.EditDiff": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "eventId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440010"
+                },
+                "inserted": {
+                    "description": "Inserted and Deleted count the characters inserted and deleted",
+                    "type": "integer",
+                    "example": 7
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "shapeId": {
+                    "type": "string",
+                    "example": "shape-12"
+                },
+                "slideId": {
+                    "type": "string",
+                    "example": "slide-3"
+                },
+                "spans": {
+                    "description": "Spans cover the before text with the insertions added, in order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.RenderedDiffSpan"
+                    }
+                },
+                "stored": {
+                    "description": "Stored reports whether the diff was stored with the event, rather than computed when read",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "domain.EntrySeverity": {
+            "type": "string",
+            "enum": [
+                "info",
+                "warning",
+                "critical"
+            ],
+            "x-enum-varnames": [
+                "EntrySeverityInfo",
+                "EntrySeverityWarning",
+                "EntrySeverityCritical"
+            ]
+        },
+        "domain.ErasureJob": {
+            "type": "object",
+            "properties": {
+                "affectedEvents": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "affectedSessions": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "completedAt": {
+                    "type": "string",
+                    "example": "2024-01-01T10:00:05Z"
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-01-01T10:00:00Z"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "holdOverride": {
+                    "description": "HoldOverride is set when the job also erases entries under a legal hold",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.HoldOverride"
+                        }
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "7d3c2b8e-4f0a-4b5e-9c1d-2a6f8e9b0c31"
+                },
+                "mode": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ErasureMode"
+                        }
+                    ],
+                    "example": "anonymize"
+                },
+                "organizationId": {
+                    "description": "OrganizationID is the organization whose entries the job erases; empty for the default organization",
+                    "type": "string",
+                    "example": "acme"
+                },
+                "requestedBy": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440003"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ErasureStatus"
+                        }
+                    ],
+                    "example": "running"
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440002"
+                }
+            }
+        },
+        "domain.ErasureMode": {
+            "type": "string",
+            "enum": [
+                "anonymize",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "ErasureAnonymize",
+                "ErasureDelete"
+            ]
+        },
+        "domain.ErasureStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "completed",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ErasurePending",
+                "ErasureRunning",
+                "ErasureCompleted",
+                "ErasureFailed"
+            ]
+        },
+        "domain.EventAnnotation": {
+            "type": "object",
+            "properties": {
+                "authorId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440003"
+                },
+                "body": {
+                    "type": "string",
+                    "example": "Export made from an unrecognized IP; confirmed with the owner"
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-01-02T10:00:00Z"
+                },
+                "eventId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "6f1b2c3d-4e5f-4a57-9d0e-0b8f5e0c3c1d"
+                },
+                "organizationId": {
+                    "description": "OrganizationID is the organization of the event; empty for the default organization",
+                    "type": "string",
+                    "example": "acme"
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "updatedAt": {
+                    "type": "string",
+                    "example": "2024-01-02T10:00:00Z"
+                },
+                "version": {
+                    "description": "Version counts the edits of the annotation, starting at 1",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "domain.EventCategory": {
+            "type": "string",
+            "enum": [
+                "content",
+                "access",
+                "security",
+                "system",
+                "usage"
+            ],
+            "x-enum-varnames": [
+                "CategoryContent",
+                "CategoryAccess",
+                "CategorySecurity",
+                "CategorySystem",
+                "CategoryUsage"
+            ]
+        },
+        "domain.EventChain": {
+            "type": "object",
+            "properties": {
+                "correlationId": {
+                    "type": "string",
+                    "example": "export-7f3a"
+                },
+                "eventId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AuditEntry"
+                    }
+                }
+            }
+        },
+        "domain.EventLabels": {
+            "type": "object",
+            "properties": {
+                "eventId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "labels": {
+                    "description": "Labels are sorted; empty for events never labeled or whose labels were all removed",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "billing-relevant",
+                        "disputed"
+                    ]
+                },
+                "organizationId": {
+                    "description": "OrganizationID is the organization of the event; empty for the default organization",
+                    "type": "string",
+                    "example": "acme"
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "updatedAt": {
+                    "type": "string",
+                    "example": "2024-01-02T10:00:00Z"
+                },
+                "updatedBy": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440003"
+                },
+                "version": {
+                    "description": "Version counts the changes of the label set; 0 for events never labeled",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "domain.EventSeverity": {
+            "type": "string",
+            "enum": [
+                "info",
+                "low",
+                "medium",
+                "high"
+            ],
+            "x-enum-varnames": [
+                "SeverityInfo",
+                "SeverityLow",
+                "SeverityMedium",
+                "SeverityHigh"
+            ]
+        },
+        "domain.EventType": {
+            "type": "object",
+            "properties": {
+                "builtIn": {
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "string"
+                },
+                "displayName": {
+                    "type": "string",
+                    "example": "Terminology approved"
+                },
+                "name": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AuditAction"
+                        }
+                    ],
+                    "example": "terminology_approved"
+                },
+                "schema": {
+                    "description": "Schema validates the details of events of a custom type; nil accepts any details",
+                    "type": "object"
+                },
+                "severity": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.EventSeverity"
+                        }
+                    ],
+                    "example": "low"
+                }
+            }
+        },
+        "domain.ExportCallback": {
+            "type": "object",
+            "required": [
+                "exportId",
+                "sessionId",
+                "status",
+                "userId"
+            ],
+            "properties": {
+                "error": {
+                    "description": "Error is the failure reason of a failed export",
+                    "type": "string",
+                    "example": "font not found"
+                },
+                "exportId": {
+                    "type": "string",
+                    "example": "export-7f3a"
+                },
+                "format": {
+                    "type": "string",
+                    "example": "pptx"
+                },
+                "organizationId": {
+                    "type": "string",
+                    "example": "org-acme"
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "sizeBytes": {
+                    "type": "integer",
+                    "example": 482133
+                },
+                "slideCount": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ExportStatus"
+                        }
+                    ],
+                    "example": "uploaded"
+                },
+                "timestamp": {
+                    "description": "Timestamp is when the worker reached the status; it defaults to the time the callback arrived",
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "url": {
+                    "description": "URL and SizeBytes describe the uploaded file",
+                    "type": "string",
+                    "example": "https://storage.example.com/exports/export-7f3a.pptx"
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "user-123"
+                }
+            }
+        },
+        "domain.ExportRecord": {
+            "type": "object",
+            "properties": {
+                "completedAt": {
+                    "description": "CompletedAt is the time of the uploaded or failed step, and DurationMs the time from the\nfirst step to it",
+                    "type": "string",
+                    "example": "2024-01-15T10:30:20Z"
+                },
+                "durationMs": {
+                    "type": "integer",
+                    "example": 20000
+                },
+                "error": {
+                    "type": "string"
+                },
+                "exportId": {
+                    "type": "string",
+                    "example": "export-7f3a"
+                },
+                "format": {
+                    "type": "string",
+                    "example": "pptx"
+                },
+                "requestEventId": {
+                    "type": "string"
+                },
+                "requestedAt": {
+                    "description": "RequestedAt and RequestEventID identify the export event of the frontend, when it shares the export ID",
+                    "type": "string",
+                    "example": "2024-01-15T10:29:58Z"
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "sizeBytes": {
+                    "type": "integer",
+                    "example": 482133
+                },
+                "slideCount": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "status": {
+                    "description": "Status is the final status once the export is uploaded or failed, the latest one before",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ExportStatus"
+                        }
+                    ],
+                    "example": "uploaded"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ExportStep"
+                    }
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://storage.example.com/exports/export-7f3a.pptx"
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "user-123"
+                }
+            }
+        },
+        "domain.ExportStatus": {
+            "type": "string",
+            "enum": [
+                "queued",
+                "rendering",
+                "uploaded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ExportQueued",
+                "ExportRendering",
+                "ExportUploaded",
+                "ExportFailed"
+            ]
+        },
+        "domain.ExportStep": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ExportStatus"
+                        }
+                    ],
+                    "example": "rendering"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2024-01-15T10:30:05Z"
+                }
+            }
+        },
+        "domain.HeatmapCell": {
+            "type": "object",
+            "properties": {
+                "eventCount": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "hour": {
+                    "type": "string",
+                    "example": "2024-01-02T10:00:00Z"
+                },
+                "slideId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440010"
+                },
+                "slideIndex": {
+                    "description": "SlideIndex is the 1-based position of the slide in the deck; omitted for slides that were\ndeleted or whose ID is not a slide of the session",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "domain.HoldOverride": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Court order 2024-031 permits erasure"
+                }
+            }
+        },
+        "domain.ImportJob": {
+            "type": "object",
+            "properties": {
+                "bytesRead": {
+                    "description": "BytesRead and TotalBytes are the compressed bytes of the file; TotalBytes is zero when unknown",
+                    "type": "integer",
+                    "example": 1048576
+                },
+                "completedAt": {
+                    "type": "string",
+                    "example": "2024-01-01T10:04:00Z"
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-01-01T10:00:00Z"
+                },
+                "dryRun": {
+                    "description": "DryRun jobs validate the file and look for duplicates without storing anything",
+                    "type": "boolean"
+                },
+                "duplicates": {
+                    "description": "Duplicates are events already stored, or repeated in the file, which were skipped",
+                    "type": "integer",
+                    "example": 40
+                },
+                "error": {
+                    "description": "Error is why a failed job stopped; events imported before are kept",
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Errors lists the first MaxImportErrors rejected lines",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ImportLineError"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f1e7c2a-9b4d-4e8f-a6c5-1d2e3f4a5b6c"
+                },
+                "imported": {
+                    "type": "integer",
+                    "example": 11950
+                },
+                "invalid": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "lines": {
+                    "description": "Lines counts the non-empty lines read so far",
+                    "type": "integer",
+                    "example": 12000
+                },
+                "organizationId": {
+                    "description": "OrganizationID is the organization the events are imported into; empty for the default organization",
+                    "type": "string",
+                    "example": "acme"
+                },
+                "requestedBy": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440003"
+                },
+                "source": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ImportSource"
+                        }
+                    ],
+                    "example": "upload"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ImportStatus"
+                        }
+                    ],
+                    "example": "running"
+                },
+                "totalBytes": {
+                    "type": "integer",
+                    "example": 4194304
+                }
+            }
+        },
+        "domain.ImportLineError": {
+            "type": "object",
+            "properties": {
+                "line": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "message": {
+                    "type": "string",
+                    "example": "timestamp is required"
+                }
+            }
+        },
+        "domain.ImportSource": {
+            "type": "string",
+            "enum": [
+                "upload",
+                "url"
+            ],
+            "x-enum-varnames": [
+                "ImportUpload",
+                "ImportURL"
+            ]
+        },
+        "domain.ImportStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "completed",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ImportPending",
+                "ImportRunning",
+                "ImportCompleted",
+                "ImportFailed"
+            ]
+        },
+        "domain.LegalHold": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "0b8f5e0c-3c1d-4a57-9d0e-6f1b2c3d4e5f"
+                },
+                "organizationId": {
+                    "description": "OrganizationID is the organization of the admin who placed the hold; empty for the default organization",
+                    "type": "string",
+                    "example": "acme"
+                },
+                "placedAt": {
+                    "type": "string",
+                    "example": "2024-01-01T10:00:00Z"
+                },
+                "placedBy": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440003"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Litigation 2024-017"
+                },
+                "releasedAt": {
+                    "type": "string"
+                },
+                "releasedBy": {
+                    "type": "string"
+                },
+                "scope": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.LegalHoldScope"
+                        }
+                    ],
+                    "example": "session"
+                },
+                "target": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "domain.LegalHoldScope": {
+            "type": "string",
+            "enum": [
+                "session",
+                "user"
+            ],
+            "x-enum-varnames": [
+                "LegalHoldSession",
+                "LegalHoldUser"
+            ]
+        },
+        "domain.MTCurrencyCost": {
+            "type": "object",
+            "properties": {
+                "costEstimate": {
+                    "type": "number",
+                    "example": 1.524
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                }
+            }
+        },
+        "domain.MTProviderCost": {
+            "type": "object",
+            "properties": {
+                "averageLatencyMs": {
+                    "description": "AverageLatencyMs and MaxLatencyMs cover the requests that reported a latency",
+                    "type": "integer",
+                    "example": 640
+                },
+                "costEstimate": {
+                    "type": "number",
+                    "example": 1.024
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "failedRequests": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "maxLatencyMs": {
+                    "type": "integer",
+                    "example": 2310
+                },
+                "model": {
+                    "description": "Model is empty for requests that named none",
+                    "type": "string",
+                    "example": "next-gen"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "deepl"
+                },
+                "requests": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "sourceCharacters": {
+                    "type": "integer",
+                    "example": 51200
+                },
+                "targetCharacters": {
+                    "type": "integer",
+                    "example": 55874
+                }
+            }
+        },
+        "domain.PayloadLimit": {
+            "type": "object",
+            "properties": {
+                "excessBytes": {
+                    "type": "integer",
+                    "example": 4464
+                },
+                "field": {
+                    "description": "Field is the part of the request over its limit: body or details",
+                    "type": "string",
+                    "example": "details"
+                },
+                "limitBytes": {
+                    "type": "integer",
+                    "example": 65536
+                },
+                "sizeBytes": {
+                    "description": "SizeBytes is the size of the payload and ExcessBytes how much of it has to be cut; both are\nomitted for streamed bodies, which are not read past the limit",
+                    "type": "integer",
+                    "example": 70000
+                }
+            }
+        },
+        "domain.QuotaStatus": {
+            "type": "object",
+            "properties": {
+                "session": {
+                    "$ref": "#/definitions/domain.QuotaUsage"
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "user": {
+                    "$ref": "#/definitions/domain.QuotaUsage"
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                }
+            }
+        },
+        "domain.QuotaUsage": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "description": "Limit is the number of events accepted per day; 0 is unlimited",
+                    "type": "integer",
+                    "example": 10000
+                },
+                "remaining": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "resetAt": {
+                    "type": "string",
+                    "example": "2024-01-02T00:00:00Z"
+                },
+                "scope": {
+                    "type": "string",
+                    "example": "session"
+                },
+                "used": {
+                    "type": "integer",
+                    "example": 9950
+                }
+            }
+        },
+        "domain.RenderedDiffSpan": {
+            "type": "object",
+            "properties": {
+                "op": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.TextDiffOp"
+                        }
+                    ],
+                    "example": "insert"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Bonjour"
+                }
+            }
+        },
+        "domain.ReplayJob": {
+            "type": "object",
+            "properties": {
+                "completedAt": {
+                    "type": "string",
+                    "example": "2024-01-03T10:00:24Z"
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-01-03T10:00:00Z"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "from": {
+                    "description": "From and To bound the event timestamps, inclusively",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5c9e1a7b-2d4f-4e8a-b3c6-7f0d1e2a3b4c"
+                },
+                "organizationId": {
+                    "description": "OrganizationID is the organization whose events are replayed; empty for the default organization",
+                    "type": "string",
+                    "example": "acme"
+                },
+                "ratePerSecond": {
+                    "description": "RatePerSecond caps the events sent or queued per second",
+                    "type": "integer",
+                    "example": 50
+                },
+                "replayed": {
+                    "description": "Replayed counts the events posted to the webhook or queued in the outbox so far",
+                    "type": "integer",
+                    "example": 600
+                },
+                "requestedBy": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440003"
+                },
+                "sessionId": {
+                    "description": "SessionID restricts the replay to one session; empty replays every session of the organization",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "skipped": {
+                    "description": "Skipped counts the events whose outbox message was still waiting for delivery",
+                    "type": "integer",
+                    "example": 3
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ReplayStatus"
+                        }
+                    ],
+                    "example": "running"
+                },
+                "target": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ReplayTarget"
+                        }
+                    ],
+                    "example": "webhook"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2024-01-02T00:00:00Z"
+                },
+                "total": {
+                    "description": "Total is the number of matching events when the job was accepted",
+                    "type": "integer",
+                    "example": 1200
+                },
+                "types": {
+                    "description": "Types restricts the replay to these event types; empty replays every type",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "share",
+                        "export"
+                    ]
+                },
+                "webhookUrl": {
+                    "description": "WebhookURL is the outbox webhook the events are posted to with the webhook target",
+                    "type": "string",
+                    "example": "https://notifications.example.com/audit"
+                }
+            }
+        },
+        "domain.ReplayStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "completed",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ReplayPending",
+                "ReplayRunning",
+                "ReplayCompleted",
+                "ReplayFailed"
+            ]
+        },
+        "domain.ReplayTarget": {
+            "type": "string",
+            "enum": [
+                "webhook",
+                "outbox"
+            ],
+            "x-enum-varnames": [
+                "ReplayWebhook",
+                "ReplayOutbox"
+            ]
+        },
+        "domain.Report": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-01-01T10:00:00Z"
+                },
+                "createdBy": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440003"
+                },
+                "delivery": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ReportDelivery"
+                        }
+                    ],
+                    "example": "webhook"
+                },
+                "format": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ReportFormat"
+                        }
+                    ],
+                    "example": "csv"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0b8f5e0c-3c1d-4a57-9d0e-6f1b2c3d4e5f"
+                },
+                "kind": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ReportKind"
+                        }
+                    ],
+                    "example": "session_activity"
+                },
+                "lastRunAt": {
+                    "description": "LastRunAt is the time of the last scheduled run, where the period of the next one starts",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Weekly session activity"
+                },
+                "nextRunAt": {
+                    "description": "NextRunAt is the next scheduled run",
+                    "type": "string",
+                    "example": "2024-01-08T06:00:00Z"
+                },
+                "organizationId": {
+                    "description": "OrganizationID is the organization whose events are reported; empty for the default organization",
+                    "type": "string",
+                    "example": "acme"
+                },
+                "schedule": {
+                    "description": "Schedule is a five-field cron expression evaluated in UTC, or @daily, @weekly or @monthly",
+                    "type": "string",
+                    "example": "0 6 * * 1"
+                },
+                "sessionId": {
+                    "description": "SessionID restricts the report to one session; empty covers every session of the organization",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "target": {
+                    "description": "Target is the webhook URL, or the storage path prefix of the uploaded reports",
+                    "type": "string",
+                    "example": "https://reports.example.com/audit"
+                }
+            }
+        },
+        "domain.ReportDelivery": {
+            "type": "string",
+            "enum": [
+                "webhook",
+                "storage"
+            ],
+            "x-enum-varnames": [
+                "ReportWebhook",
+                "ReportStorage"
+            ]
+        },
+        "domain.ReportFormat": {
+            "type": "string",
+            "enum": [
+                "csv",
+                "json"
+            ],
+            "x-enum-varnames": [
+                "ReportCSV",
+                "ReportJSON"
+            ]
+        },
+        "domain.ReportKind": {
+            "type": "string",
+            "enum": [
+                "session_activity",
+                "export_summary"
+            ],
+            "x-enum-varnames": [
+                "ReportSessionActivity",
+                "ReportExportSummary"
+            ]
+        },
+        "domain.ReportRun": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "type": "string",
+                    "example": "2024-01-08T06:00:05Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5d2c1b0a-9e8f-4a7b-8c6d-5e4f3a2b1c0d"
+                },
+                "location": {
+                    "description": "Location is the storage object of uploaded reports",
+                    "type": "string",
+                    "example": "audit-reports/weekly/2024-01-08T06-00-00Z.csv"
+                },
+                "organizationId": {
+                    "description": "OrganizationID is the organization of the report; empty for the default organization",
+                    "type": "string",
+                    "example": "acme"
+                },
+                "periodFrom": {
+                    "description": "PeriodFrom and PeriodTo bound the reported events; PeriodTo is exclusive",
+                    "type": "string",
+                    "example": "2024-01-01T06:00:00Z"
+                },
+                "periodTo": {
+                    "type": "string",
+                    "example": "2024-01-08T06:00:00Z"
+                },
+                "reportId": {
+                    "type": "string",
+                    "example": "0b8f5e0c-3c1d-4a57-9d0e-6f1b2c3d4e5f"
+                },
+                "rows": {
+                    "description": "Rows is the number of rows of the generated report",
+                    "type": "integer",
+                    "example": 42
+                },
+                "startedAt": {
+                    "type": "string",
+                    "example": "2024-01-08T06:00:02Z"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ReportRunStatus"
+                        }
+                    ],
+                    "example": "succeeded"
+                },
+                "trigger": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ReportTrigger"
+                        }
+                    ],
+                    "example": "schedule"
+                }
+            }
+        },
+        "domain.ReportRunStatus": {
+            "type": "string",
+            "enum": [
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ReportRunSucceeded",
+                "ReportRunFailed"
+            ]
+        },
+        "domain.ReportTrigger": {
+            "type": "string",
+            "enum": [
+                "schedule",
+                "manual"
+            ],
+            "x-enum-varnames": [
+                "ReportTriggerSchedule",
+                "ReportTriggerManual"
+            ]
+        },
+        "domain.RevertPayload": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "type": "object"
+                },
+                "parentEventId": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "schemaVersion": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "edit"
+                }
+            }
+        },
+        "domain.Revocation": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "description": "ExpiresAt ends the revocation on its own; revocations without it last until they are lifted",
+                    "type": "string",
+                    "example": "2024-01-02T10:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "7d9f2b1e-4c3a-4e8b-9f0a-1b2c3d4e5f60"
+                },
+                "kind": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.RevocationKind"
+                        }
+                    ],
+                    "example": "user"
+                },
+                "liftedAt": {
+                    "type": "string"
+                },
+                "liftedBy": {
+                    "type": "string"
+                },
+                "organizationId": {
+                    "description": "OrganizationID is the organization of the admin who revoked; only tokens of that organization are rejected",
+                    "type": "string",
+                    "example": "acme"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Account compromised"
+                },
+                "revokedAt": {
+                    "type": "string",
+                    "example": "2024-01-01T10:00:00Z"
+                },
+                "revokedBy": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440003"
+                },
+                "target": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                }
+            }
+        },
+        "domain.RevocationKind": {
+            "type": "string",
+            "enum": [
+                "user",
+                "session",
+                "token"
+            ],
+            "x-enum-varnames": [
+                "RevocationUser",
+                "RevocationSession",
+                "RevocationToken"
+            ]
+        },
+        "domain.SavedQuery": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-01-01T10:00:00Z"
+                },
+                "createdBy": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440003"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Text edits and merges of the last 7 days"
+                },
+                "filter": {
+                    "$ref": "#/definitions/domain.SavedQueryFilter"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0b8f5e0c-3c1d-4a57-9d0e-6f1b2c3d4e5f"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "My edits this week"
+                },
+                "organizationId": {
+                    "description": "OrganizationID is the organization of the creator; empty for the default organization",
+                    "type": "string",
+                    "example": "acme"
+                },
+                "scope": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.SavedQueryScope"
+                        }
+                    ],
+                    "example": "private"
+                },
+                "updatedAt": {
+                    "type": "string",
+                    "example": "2024-01-02T10:00:00Z"
+                }
+            }
+        },
+        "domain.SavedQueryFilter": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.EventCategory"
+                    },
+                    "example": [
+                        "content"
+                    ]
+                },
+                "commentId": {
+                    "type": "string",
+                    "example": "comment-7"
+                },
+                "correlationId": {
+                    "type": "string",
+                    "example": "export-7f3a"
+                },
+                "days": {
+                    "description": "Days restricts the results to the events of the last days before the query runs; 0 is unlimited",
+                    "type": "integer",
+                    "example": 7
+                },
+                "labels": {
+                    "description": "Labels restricts the results to the events carrying one of the labels",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "disputed"
+                    ]
+                },
+                "q": {
+                    "type": "string",
+                    "example": "title"
+                },
+                "severities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.EntrySeverity"
+                    },
+                    "example": [
+                        "warning",
+                        "critical"
+                    ]
+                },
+                "shapeId": {
+                    "type": "string",
+                    "example": "shape-12"
+                },
+                "slideId": {
+                    "type": "string",
+                    "example": "slide-3"
+                },
+                "threadId": {
+                    "type": "string",
+                    "example": "thread-2"
+                },
+                "types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "edit",
+                        "merge"
+                    ]
+                },
+                "userId": {
+                    "description": "UserID restricts the results to the events of one user; \"me\" is the user running the query",
+                    "type": "string",
+                    "example": "me"
+                }
+            }
+        },
+        "domain.SavedQueryScope": {
+            "type": "string",
+            "enum": [
+                "private",
+                "organization"
+            ],
+            "x-enum-varnames": [
+                "SavedQueryPrivate",
+                "SavedQueryOrganization"
+            ]
+        },
+        "domain.SchemaViolation": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "details.slideId"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Invalid type. Expected: string, given: integer"
+                }
+            }
+        },
+        "domain.SessionActivity": {
+            "type": "object",
+            "properties": {
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ActivityBucket"
+                    }
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "granularity": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ActivityGranularity"
+                        }
+                    ],
+                    "example": "day"
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2024-01-31T00:00:00Z"
+                }
+            }
+        },
+        "domain.SessionContributors": {
+            "type": "object",
+            "properties": {
+                "contributors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ContributorStats"
+                    }
+                },
+                "from": {
+                    "description": "From and To repeat the requested range; omitted when open",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2024-01-31T23:59:59Z"
+                }
+            }
+        },
+        "domain.SessionHeatmap": {
+            "type": "object",
+            "properties": {
+                "cells": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.HeatmapCell"
+                    }
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2024-01-08T00:00:00Z"
+                },
+                "totalEvents": {
+                    "type": "integer",
+                    "example": 240
+                }
+            }
+        },
+        "domain.SessionMTCosts": {
+            "type": "object",
+            "properties": {
+                "costs": {
+                    "description": "Costs totals the estimates per currency, as estimates in different currencies are not added up",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.MTCurrencyCost"
+                    }
+                },
+                "failedRequests": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "from": {
+                    "description": "From and To repeat the requested range; omitted when open",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "providers": {
+                    "description": "Providers lists the provider and model pairs, the most expensive first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.MTProviderCost"
+                    }
+                },
+                "requests": {
+                    "type": "integer",
+                    "example": 60
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "sourceCharacters": {
+                    "type": "integer",
+                    "example": 73400
+                },
+                "targetCharacters": {
+                    "type": "integer",
+                    "example": 80112
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2024-01-31T23:59:59Z"
+                },
+                "truncated": {
+                    "description": "Truncated is set when the session had more than MaxMTCostEvents requests in the range",
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.SessionStats": {
+            "type": "object",
+            "properties": {
+                "byType": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "contributors": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "editsPerSlide": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "firstActivity": {
+                    "type": "string",
+                    "example": "2023-12-01T10:30:00Z"
+                },
+                "lastActivity": {
+                    "type": "string",
+                    "example": "2023-12-02T16:45:00Z"
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "totalEvents": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "domain.SessionTMLeverage": {
+            "type": "object",
+            "properties": {
+                "acceptanceRate": {
+                    "description": "AcceptanceRate is the share of hits accepted, OverrideRate the share of accepted hits\noverridden later",
+                    "type": "number",
+                    "example": 0.8
+                },
+                "accepted": {
+                    "type": "integer",
+                    "example": 104
+                },
+                "averageMatchScore": {
+                    "description": "AverageMatchScore is the mean score of the accepted hits",
+                    "type": "number",
+                    "example": 93.5
+                },
+                "bands": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.TMBand"
+                    }
+                },
+                "from": {
+                    "description": "From and To repeat the requested range; omitted when open",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "hits": {
+                    "type": "integer",
+                    "example": 130
+                },
+                "leveragedWords": {
+                    "type": "integer",
+                    "example": 862
+                },
+                "overrideRate": {
+                    "type": "number",
+                    "example": 0.0865
+                },
+                "overrides": {
+                    "type": "integer",
+                    "example": 9
+                },
+                "rejected": {
+                    "type": "integer",
+                    "example": 26
+                },
+                "segments": {
+                    "description": "Segments counts the distinct segment sources with a TM match",
+                    "type": "integer",
+                    "example": 118
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2024-01-31T23:59:59Z"
+                },
+                "truncated": {
+                    "description": "Truncated is set when the session had more than MaxTMLeverageEvents events in the range",
+                    "type": "boolean"
+                },
+                "wordLeverage": {
+                    "type": "number",
+                    "example": 0.8288
+                },
+                "words": {
+                    "description": "Words and LeveragedWords count the words of all hits and of the accepted ones, and\nWordLeverage is their ratio; only hits that reported a word count are included",
+                    "type": "integer",
+                    "example": 1040
+                }
+            }
+        },
+        "domain.SessionTimeline": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "description": "Events counts the events merged into the groups",
+                    "type": "integer",
+                    "example": 57
+                },
+                "from": {
+                    "description": "From and To repeat the requested range; omitted when open",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "gapSeconds": {
+                    "description": "GapSeconds is the longest pause within a group",
+                    "type": "integer",
+                    "example": 300
+                },
+                "groups": {
+                    "description": "Groups lists the groups by their first event, the oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.TimelineGroup"
+                    }
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2024-01-31T23:59:59Z"
+                },
+                "truncated": {
+                    "description": "Truncated is set when the session had more than MaxTimelineEvents events in the range",
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.ShareLinkStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "expired",
+                "revoked"
+            ],
+            "x-enum-varnames": [
+                "ShareLinkActive",
+                "ShareLinkExpired",
+                "ShareLinkRevoked"
+            ]
+        },
+        "domain.SharePermission": {
+            "type": "string",
+            "enum": [
+                "VIEW",
+                "COMMENT"
+            ],
+            "x-enum-varnames": [
+                "SharePermissionView",
+                "SharePermissionComment"
+            ]
+        },
+        "domain.ShareRedemption": {
+            "type": "object",
+            "properties": {
+                "eventId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440009"
+                },
+                "ipAddress": {
+                    "type": "string",
+                    "example": "198.51.100.4"
+                },
+                "method": {
+                    "type": "string",
+                    "example": "GET"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/api/v1/sessions/550e8400-e29b-41d4-a716-446655440000/history"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2024-01-16T09:12:00Z"
+                },
+                "userAgent": {
+                    "type": "string",
+                    "example": "Mozilla/5.0"
+                }
+            }
+        },
+        "domain.ShareTrail": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-01-15T10:00:00Z"
+                },
+                "createdBy": {
+                    "description": "CreatedBy, CreatedAt, Permissions and ExpiresAt come from the share event, when recorded",
+                    "type": "string",
+                    "example": "user-123"
+                },
+                "expiredAt": {
+                    "description": "ExpiredAt is the first use after the link expired, or its expiry once passed",
+                    "type": "string",
+                    "example": "2024-01-22T11:30:00Z"
+                },
+                "expiredAttempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "expiresAt": {
+                    "type": "string",
+                    "example": "2024-01-22T10:00:00Z"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.SharePermission"
+                    }
+                },
+                "redemptions": {
+                    "description": "Redemptions lists the uses of the link, the earliest first; ExpiredAttempts counts the\nuses rejected after it expired",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ShareRedemption"
+                    }
+                },
+                "revokedAt": {
+                    "type": "string",
+                    "example": "2024-01-20T08:00:00Z"
+                },
+                "revokedBy": {
+                    "type": "string",
+                    "example": "user-123"
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ShareLinkStatus"
+                        }
+                    ],
+                    "example": "active"
+                },
+                "tokenId": {
+                    "type": "string",
+                    "example": "share-91c2"
+                },
+                "truncated": {
+                    "description": "Truncated is set when the link had more than MaxShareTrailEvents events",
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.TMBand": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "integer",
+                    "example": 36
+                },
+                "band": {
+                    "description": "Band names the scores of the band, e.g. 95-99",
+                    "type": "string",
+                    "example": "95-99"
+                },
+                "hits": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "minScore": {
+                    "type": "number",
+                    "example": 95
+                },
+                "rejected": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "words": {
+                    "description": "Words counts the words of the segments, for hits that reported them",
+                    "type": "integer",
+                    "example": 310
+                }
+            }
+        },
+        "domain.TextDiffOp": {
+            "type": "string",
+            "enum": [
+                "equal",
+                "insert",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "DiffEqual",
+                "DiffInsert",
+                "DiffDelete"
+            ]
+        },
+        "domain.TimelineGroup": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Count is the number of events merged into the group, collapsed views counting as the\nviews they stand for",
+                    "type": "integer",
+                    "example": 12
+                },
+                "end": {
+                    "type": "string",
+                    "example": "2024-01-02T10:07:30Z"
+                },
+                "firstEventId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440010"
+                },
+                "id": {
+                    "description": "ID is derived from the first event of the group, so it stays the same as later events join it",
+                    "type": "string",
+                    "example": "3b8e0f55-8c4a-5d7e-9f61-2a0c4b1d9e77"
+                },
+                "kind": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.TimelineGroupKind"
+                        }
+                    ],
+                    "example": "edits"
+                },
+                "lastEventId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440021"
+                },
+                "slideIds": {
+                    "description": "SlideIDs lists the slides the events touched, sorted",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "slide-1",
+                        "slide-3"
+                    ]
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2024-01-02T10:00:00Z"
+                },
+                "summary": {
+                    "description": "Summary describes the group for display, such as \"12 edits on 3 slides\"",
+                    "type": "string",
+                    "example": "12 edits on 3 slides"
+                },
+                "type": {
+                    "description": "Type is the event type of single events and views; bursts of edits may mix types",
+                    "type": "string",
+                    "example": "comment_created"
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440003"
+                }
+            }
+        },
+        "domain.TimelineGroupKind": {
+            "type": "string",
+            "enum": [
+                "edits",
+                "views",
+                "event"
+            ],
+            "x-enum-varnames": [
+                "TimelineEdits",
+                "TimelineViews",
+                "TimelineEvent"
             ]
         },
-        "domain.AuditEntry": {
+        "domain.Watch": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-01-01T10:00:00Z"
+                },
+                "eventTypes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "share",
+                        "export",
+                        "comment",
+                        "comment_created"
+                    ]
+                },
+                "organizationId": {
+                    "description": "OrganizationID is the organization of the watcher; empty for the default organization",
+                    "type": "string",
+                    "example": "acme"
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440003"
+                }
+            }
+        },
+        "handlers.BatchCreateEventResponse": {
+            "type": "object",
+            "properties": {
+                "consistencyToken": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.CreateEventResponse"
+                    }
+                }
+            }
+        },
+        "handlers.BatchEventError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "not_found"
+                },
+                "detail": {
+                    "description": "Message explains this occurrence of the problem",
+                    "type": "string",
+                    "example": "The requested resource was not found"
+                },
+                "index": {
+                    "description": "Index is the position of the rejected event in the batch",
+                    "type": "integer",
+                    "example": 3
+                },
+                "instance": {
+                    "description": "Instance is the path of the request that failed",
+                    "type": "string",
+                    "example": "/api/v1/sessions/550e8400-e29b-41d4-a716-446655440000/history"
+                },
+                "limit": {
+                    "description": "Limit describes the payload of a payload_too_large problem",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.PayloadLimit"
+                        }
+                    ]
+                },
+                "quota": {
+                    "description": "Quota describes the exhausted quota of a quota_exceeded problem",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.QuotaUsage"
+                        }
+                    ]
+                },
+                "requestId": {
+                    "type": "string",
+                    "example": "5c9e1a7b-2d4f-4e8a-b3c6-7f0d1e2a3b4c"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "description": "Title is the HTTP status text of the problem",
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "description": "Type identifies the kind of problem; it is ProblemTypePrefix followed by Code",
+                    "type": "string",
+                    "example": "urn:audit-service:problem:not_found"
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.SchemaViolation"
+                    }
+                }
+            }
+        },
+        "handlers.CreateEventRequest": {
+            "type": "object",
+            "required": [
+                "sessionId",
+                "type"
+            ],
+            "properties": {
+                "correlationId": {
+                    "description": "CorrelationID groups the events of one operation; ParentEventID is the UUID of the event that caused this one",
+                    "type": "string"
+                },
+                "details": {},
+                "id": {
+                    "description": "Optional client-generated UUID, also used as idempotency key",
+                    "type": "string"
+                },
+                "organizationId": {
+                    "description": "OrganizationID is the organization a service acted for; only honored for API-key callers,\nusers record events in the organization of their token",
+                    "type": "string"
+                },
+                "parentEventId": {
+                    "type": "string"
+                },
+                "schemaVersion": {
+                    "description": "SchemaVersion selects the version of the details schema; requests without it use version 1",
+                    "type": "integer"
+                },
+                "sessionId": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.AuditAction"
+                },
+                "userId": {
+                    "description": "User a service acted for; only honored for API-key callers",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CreateEventResponse": {
+            "type": "object",
+            "properties": {
+                "collapsed": {
+                    "description": "Collapsed is set on views counted into an identical view held for deduplication, whose ID\nis returned instead of their own",
+                    "type": "boolean"
+                },
+                "consistencyToken": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "redactedFields": {
+                    "description": "RedactedFields lists the details values masked before the event was stored",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "sessionId": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.AuditAction"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CreateEventTypeRequest": {
             "type": "object",
+            "required": [
+                "displayName",
+                "name"
+            ],
             "properties": {
-                "details": {
+                "displayName": {
+                    "type": "string",
+                    "example": "Terminology approved"
+                },
+                "name": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AuditAction"
+                        }
+                    ],
+                    "example": "terminology_approved"
+                },
+                "schema": {
+                    "description": "Schema is a JSON schema for the details of events of this type; omit it to accept any details",
                     "type": "object"
                 },
-                "id": {
+                "severity": {
+                    "description": "Severity defaults to info",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.EventSeverity"
+                        }
+                    ],
+                    "example": "low"
+                }
+            }
+        },
+        "handlers.CreateLegalHoldRequest": {
+            "type": "object",
+            "required": [
+                "reason",
+                "scope",
+                "target"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Litigation 2024-017"
+                },
+                "scope": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.LegalHoldScope"
+                        }
+                    ],
+                    "example": "session"
+                },
+                "target": {
+                    "description": "Target is a session ID for session holds and a user ID for user holds",
                     "type": "string",
                     "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "handlers.CreateReportRequest": {
+            "type": "object",
+            "required": [
+                "delivery",
+                "format",
+                "kind",
+                "name",
+                "schedule"
+            ],
+            "properties": {
+                "delivery": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ReportDelivery"
+                        }
+                    ],
+                    "example": "webhook"
                 },
-                "ipAddress": {
+                "format": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ReportFormat"
+                        }
+                    ],
+                    "example": "csv"
+                },
+                "kind": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ReportKind"
+                        }
+                    ],
+                    "example": "session_activity"
+                },
+                "name": {
                     "type": "string",
-                    "example": "192.168.1.1"
+                    "example": "Weekly session activity"
                 },
-                "sessionId": {
+                "schedule": {
+                    "description": "Schedule is a five-field cron expression in UTC, or @daily, @weekly or @monthly",
                     "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                    "example": "0 6 * * 1"
                 },
-                "timestamp": {
+                "sessionId": {
+                    "description": "SessionID restricts the report to one session; omit it to cover every session",
                     "type": "string",
-                    "example": "2023-12-01T10:30:00Z"
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
                 },
-                "type": {
+                "target": {
+                    "description": "Target is the webhook URL, or the storage path prefix of the uploaded reports",
                     "type": "string",
-                    "example": "edit"
+                    "example": "https://reports.example.com/audit"
+                }
+            }
+        },
+        "handlers.CreateRevocationRequest": {
+            "type": "object",
+            "required": [
+                "kind",
+                "reason",
+                "target"
+            ],
+            "properties": {
+                "expiresAt": {
+                    "description": "ExpiresAt ends the revocation on its own, e.g. when the revoked tokens have expired anyway",
+                    "type": "string",
+                    "example": "2024-01-02T10:00:00Z"
                 },
-                "userAgent": {
+                "kind": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.RevocationKind"
+                        }
+                    ],
+                    "example": "user"
+                },
+                "reason": {
                     "type": "string",
-                    "example": "Mozilla/5.0"
+                    "example": "Account compromised"
                 },
-                "userId": {
+                "target": {
+                    "description": "Target is a user ID, a session_id claim or a jti claim, depending on the kind",
                     "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440002"
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
                 }
             }
         },
-        "domain.AuditResponse": {
+        "handlers.DeadLetterList": {
             "type": "object",
             "properties": {
                 "items": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/domain.AuditEntry"
+                        "$ref": "#/definitions/domain.DeadLetter"
                     }
                 },
-                "totalCount": {
+                "nextBefore": {
+                    "description": "NextBefore is the before parameter of the next page; omitted on the last page",
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "handlers.DeadLetterRetryResult": {
+            "type": "object",
+            "properties": {
+                "id": {
                     "type": "integer",
                     "example": 42
+                },
+                "status": {
+                    "type": "string",
+                    "example": "queued"
                 }
             }
         },
-        "handlers.CreateEventRequest": {
+        "handlers.EventAnnotationRequest": {
             "type": "object",
             "required": [
-                "sessionId",
-                "type"
+                "body"
             ],
             "properties": {
-                "details": {},
+                "body": {
+                    "type": "string",
+                    "example": "Export made from an unrecognized IP; confirmed with the owner"
+                }
+            }
+        },
+        "handlers.EventLabelsRequest": {
+            "type": "object",
+            "properties": {
+                "labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "billing-relevant",
+                        "disputed"
+                    ]
+                }
+            }
+        },
+        "handlers.EventTypeList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.EventType"
+                    }
+                }
+            }
+        },
+        "handlers.ImportRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "url": {
+                    "description": "URL is a signed HTTPS URL of a gzipped NDJSON file",
+                    "type": "string",
+                    "example": "https://storage.example.com/exports/audit-2021.ndjson.gz?signature=..."
+                }
+            }
+        },
+        "handlers.LegalHoldList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.LegalHold"
+                    }
+                }
+            }
+        },
+        "handlers.OutboxReplayResult": {
+            "type": "object",
+            "properties": {
+                "replayed": {
+                    "description": "Replayed is the number of dead messages made due again",
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "handlers.ReplayRequest": {
+            "type": "object",
+            "required": [
+                "from",
+                "target",
+                "to"
+            ],
+            "properties": {
+                "from": {
+                    "description": "From and To bound the event timestamps, inclusively",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "ratePerSecond": {
+                    "description": "RatePerSecond caps the events sent per second; defaults to REPLAY_MAX_RATE",
+                    "type": "integer",
+                    "example": 50
+                },
                 "sessionId": {
+                    "description": "SessionID restricts the replay to one session; omit it to replay every session",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "target": {
+                    "description": "Target is webhook, to post the events to WebhookURL, or outbox, to forward them to every outbox webhook",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ReplayTarget"
+                        }
+                    ],
+                    "example": "webhook"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2024-01-02T00:00:00Z"
+                },
+                "types": {
+                    "description": "Types restricts the replay to these event types; omit it to replay every type",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "share",
+                        "export"
+                    ]
+                },
+                "webhookUrl": {
+                    "description": "WebhookURL must be one of the configured outbox webhooks",
+                    "type": "string",
+                    "example": "https://notifications.example.com/audit"
+                }
+            }
+        },
+        "handlers.ReportList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Report"
+                    }
+                }
+            }
+        },
+        "handlers.ReportRunList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ReportRun"
+                    }
+                }
+            }
+        },
+        "handlers.RetentionRunResult": {
+            "type": "object",
+            "properties": {
+                "completedAt": {
                     "type": "string"
                 },
-                "timestamp": {
+                "startedAt": {
                     "type": "string"
                 },
-                "type": {
-                    "$ref": "#/definitions/domain.AuditAction"
+                "status": {
+                    "type": "string",
+                    "example": "completed"
                 }
             }
         },
-        "handlers.CreateEventResponse": {
+        "handlers.RevocationList": {
             "type": "object",
             "properties": {
-                "id": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Revocation"
+                    }
+                }
+            }
+        },
+        "handlers.RollupRunResult": {
+            "type": "object",
+            "properties": {
+                "completedAt": {
                     "type": "string"
                 },
-                "sessionId": {
+                "rollups": {
+                    "description": "Rollups is the number of hourly and daily rollups written",
+                    "type": "integer",
+                    "example": 240
+                },
+                "startedAt": {
                     "type": "string"
                 },
-                "success": {
-                    "type": "boolean"
+                "status": {
+                    "type": "string",
+                    "example": "completed"
+                }
+            }
+        },
+        "handlers.RunReportRequest": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "From defaults to the last scheduled run, or the creation of the report",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
                 },
-                "timestamp": {
-                    "type": "string"
+                "to": {
+                    "description": "To defaults to now",
+                    "type": "string",
+                    "example": "2024-01-08T00:00:00Z"
+                }
+            }
+        },
+        "handlers.SavedQueryList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.SavedQuery"
+                    }
+                }
+            }
+        },
+        "handlers.SavedQueryRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Text edits and merges of the last 7 days"
                 },
-                "type": {
-                    "$ref": "#/definitions/domain.AuditAction"
+                "filter": {
+                    "$ref": "#/definitions/domain.SavedQueryFilter"
                 },
-                "userId": {
-                    "type": "string"
+                "name": {
+                    "type": "string",
+                    "example": "My edits this week"
+                },
+                "scope": {
+                    "description": "Scope is private, the default, or organization to share the query with the organization",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.SavedQueryScope"
+                        }
+                    ],
+                    "example": "private"
+                }
+            }
+        },
+        "handlers.WatchList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Watch"
+                    }
+                }
+            }
+        },
+        "handlers.WatchSessionRequest": {
+            "type": "object",
+            "properties": {
+                "eventTypes": {
+                    "description": "EventTypes defaults to share, export, comment and comment_created",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "share",
+                        "export"
+                    ]
                 }
             }
         }
     },
     "securityDefinitions": {
+        "APIKeyAuth": {
+            "description": "Service API key for writing audit events without a user JWT.",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
         "BearerAuth": {
             "description": "Type \"Bearer\" followed by a space and JWT token.",
             "type": "apiKey",
@@ -0,0 +1,10 @@
+package docs
+
+import _ "embed"
+
+//go:generate go run ../cmd/openapi-gen -in swagger.json -out openapi.json
+
+// OpenAPI is the OpenAPI 3.0 document converted from swagger.json
+//
+//go:embed openapi.json
+var OpenAPI []byte
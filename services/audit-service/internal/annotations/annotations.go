@@ -0,0 +1,263 @@
+// Package annotations keeps the threads of annotations reviewers attach to audit events, so
+// security investigations can record their findings beside the evidence. Annotations are stored
+// beside the events, which stay unchanged, and every change of one is itself recorded as an
+// annotation_created, annotation_edited or annotation_deleted event of the session, naming who
+// annotated what.
+package annotations
+
+import (
+	"context"
+	"strings"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/requestid"
+	"audit-service/pkg/tenant"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Repository stores the annotations
+type Repository interface {
+	CreateAnnotation(ctx context.Context, annotation domain.EventAnnotation) error
+	GetAnnotation(ctx context.Context, id string) (*domain.EventAnnotation, error)
+	ListAnnotations(ctx context.Context, eventID string) ([]domain.EventAnnotation, error)
+	UpdateAnnotation(ctx context.Context, annotation domain.EventAnnotation) error
+	DeleteAnnotation(ctx context.Context, id string, version int64) error
+}
+
+// Events looks up the events annotations are attached to
+type Events interface {
+	FindEvent(ctx context.Context, eventID domain.EventID) (*domain.AuditEntry, error)
+}
+
+// Authorizer checks that a user may read the audit activity of a session
+type Authorizer interface {
+	AuthorizeSession(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool) error
+}
+
+// Writer stores the events recording the changes of annotations
+type Writer interface {
+	CreateEvent(ctx context.Context, entry domain.AuditEntry) error
+}
+
+// Service reads and changes the annotation threads of audit events. Its routes are restricted to
+// reviewers and admins, who may read and add to the thread of an event of any session; only the
+// author of an annotation may edit or delete it. Annotations are looked up by event ID, so share
+// tokens, which are bound to a session, cannot use them.
+type Service struct {
+	repo       Repository
+	events     Events
+	authorizer Authorizer
+	writer     Writer
+	clock      clock.Clock
+	logger     *zap.Logger
+}
+
+// New creates the annotation service
+func New(repo Repository, events Events, authorizer Authorizer, writer Writer, clk clock.Clock, logger *zap.Logger) *Service {
+	return &Service{
+		repo:       repo,
+		events:     events,
+		authorizer: authorizer,
+		writer:     writer,
+		clock:      clk,
+		logger:     logger,
+	}
+}
+
+// List returns the annotation thread of an event, oldest first
+func (s *Service) List(ctx context.Context, eventID, userID string) (domain.AnnotationThread, error) {
+	entry, err := s.authorize(ctx, eventID, userID)
+	if err != nil {
+		return domain.AnnotationThread{}, err
+	}
+	annotations, err := s.repo.ListAnnotations(ctx, entry.ID)
+	if err != nil {
+		return domain.AnnotationThread{}, err
+	}
+	if annotations == nil {
+		annotations = []domain.EventAnnotation{}
+	}
+	return domain.AnnotationThread{EventID: entry.ID, SessionID: entry.SessionID, Items: annotations}, nil
+}
+
+// Get returns an annotation of an event
+func (s *Service) Get(ctx context.Context, eventID, annotationID, userID string) (domain.EventAnnotation, error) {
+	entry, err := s.authorize(ctx, eventID, userID)
+	if err != nil {
+		return domain.EventAnnotation{}, err
+	}
+	return s.annotation(ctx, entry, annotationID)
+}
+
+// Create adds an annotation by userID to the thread of an event
+func (s *Service) Create(ctx context.Context, eventID, userID, body string) (domain.EventAnnotation, error) {
+	entry, err := s.authorize(ctx, eventID, userID)
+	if err != nil {
+		return domain.EventAnnotation{}, err
+	}
+
+	organizationID, _ := tenant.FromContext(ctx)
+	now := s.clock.Now().UTC()
+	annotation := domain.EventAnnotation{
+		ID:             uuid.New().String(),
+		EventID:        entry.ID,
+		SessionID:      entry.SessionID,
+		AuthorID:       userID,
+		Body:           strings.TrimSpace(body),
+		Version:        1,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		OrganizationID: organizationID,
+	}
+	if err := annotation.Validate(); err != nil {
+		return domain.EventAnnotation{}, err
+	}
+	if err := s.repo.CreateAnnotation(ctx, annotation); err != nil {
+		return domain.EventAnnotation{}, err
+	}
+	if err := s.record(ctx, domain.ActionAnnotationCreated, annotation, userID); err != nil {
+		return domain.EventAnnotation{}, err
+	}
+	return annotation, nil
+}
+
+// Edit replaces the body of an annotation userID wrote, provided expectedVersion is its current
+// version. Editing an annotation changed since it was read returns domain.ErrVersionConflict.
+func (s *Service) Edit(ctx context.Context, eventID, annotationID, userID, body string, expectedVersion int64) (domain.EventAnnotation, error) {
+	annotation, err := s.authored(ctx, eventID, annotationID, userID, expectedVersion)
+	if err != nil {
+		return domain.EventAnnotation{}, err
+	}
+
+	annotation.Body = strings.TrimSpace(body)
+	annotation.Version++
+	annotation.UpdatedAt = s.clock.Now().UTC()
+	if err := annotation.Validate(); err != nil {
+		return domain.EventAnnotation{}, err
+	}
+	if err := s.repo.UpdateAnnotation(ctx, annotation); err != nil {
+		return domain.EventAnnotation{}, err
+	}
+	if err := s.record(ctx, domain.ActionAnnotationEdited, annotation, userID); err != nil {
+		return domain.EventAnnotation{}, err
+	}
+	return annotation, nil
+}
+
+// Delete removes an annotation userID wrote, provided expectedVersion is its current version
+func (s *Service) Delete(ctx context.Context, eventID, annotationID, userID string, expectedVersion int64) error {
+	annotation, err := s.authored(ctx, eventID, annotationID, userID, expectedVersion)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteAnnotation(ctx, annotation.ID, annotation.Version); err != nil {
+		return err
+	}
+	return s.record(ctx, domain.ActionAnnotationDeleted, annotation, userID)
+}
+
+// authored returns an annotation of an event written by userID, at expectedVersion
+func (s *Service) authored(ctx context.Context, eventID, annotationID, userID string, expectedVersion int64) (domain.EventAnnotation, error) {
+	entry, err := s.authorize(ctx, eventID, userID)
+	if err != nil {
+		return domain.EventAnnotation{}, err
+	}
+	annotation, err := s.annotation(ctx, entry, annotationID)
+	if err != nil {
+		return domain.EventAnnotation{}, err
+	}
+	if annotation.AuthorID != userID {
+		return domain.EventAnnotation{}, domain.ErrForbidden
+	}
+	if err := domain.CheckVersion(annotation.Version, expectedVersion); err != nil {
+		return domain.EventAnnotation{}, err
+	}
+	return annotation, nil
+}
+
+// annotation returns an annotation of the thread of an event
+func (s *Service) annotation(ctx context.Context, entry *domain.AuditEntry, annotationID string) (domain.EventAnnotation, error) {
+	if _, err := uuid.Parse(annotationID); err != nil {
+		return domain.EventAnnotation{}, domain.ErrAnnotationNotFound
+	}
+	annotation, err := s.repo.GetAnnotation(ctx, strings.ToLower(annotationID))
+	if err != nil {
+		return domain.EventAnnotation{}, err
+	}
+	// Annotations of other events are not disclosed
+	if annotation.EventID != entry.ID {
+		return domain.EventAnnotation{}, domain.ErrAnnotationNotFound
+	}
+	return *annotation, nil
+}
+
+// authorize returns an event of a session userID may read, which reviewers and admins may for
+// every session
+func (s *Service) authorize(ctx context.Context, eventID, userID string) (*domain.AuditEntry, error) {
+	parsed, err := domain.ParseEventID(eventID)
+	if err != nil {
+		return nil, domain.ErrEventNotFound
+	}
+	entry, err := s.events.FindEvent(ctx, parsed)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizer.AuthorizeSession(ctx, domain.SessionID(entry.SessionID), domain.UserID(userID), false); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// record writes the event recording a change of an annotation by userID. It is a child of the
+// annotated event and correlated with the other changes of the annotation by its ID. The event is
+// part of the session history its owner reads, so it names the annotation but not its body.
+func (s *Service) record(ctx context.Context, action domain.AuditAction, annotation domain.EventAnnotation, userID string) error {
+	details, err := domain.EncodeDetails(domain.AnnotationDetails{
+		AnnotationID: annotation.ID,
+		EventID:      annotation.EventID,
+		Version:      annotation.Version,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now().UTC()
+	entry := domain.AuditEntry{
+		ID:             uuid.New().String(),
+		SessionID:      annotation.SessionID,
+		UserID:         userID,
+		Type:           string(action),
+		Timestamp:      now,
+		Details:        details,
+		ReceivedAt:     &now,
+		CorrelationID:  annotation.ID,
+		ParentEventID:  annotation.EventID,
+		OrganizationID: annotation.OrganizationID,
+	}
+	if err := s.writer.CreateEvent(ctx, entry); err != nil {
+		// The annotation was changed; its trail is what is missing
+		s.logger.Error("failed to record annotation change",
+			requestid.Field(ctx),
+			zap.String("annotation_id", annotation.ID),
+			zap.String("event_id", annotation.EventID),
+			zap.String("type", entry.Type),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	s.logger.Info("annotation changed",
+		requestid.Field(ctx),
+		zap.String("annotation_id", annotation.ID),
+		zap.String("event_id", annotation.EventID),
+		zap.String("session_id", annotation.SessionID),
+		zap.String("type", entry.Type),
+		zap.Int64("version", annotation.Version),
+		zap.String("user_id", userID),
+		tenant.Field(ctx),
+	)
+	return nil
+}
@@ -0,0 +1,243 @@
+package annotations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2024, 2, 8, 12, 0, 0, 0, time.UTC)
+
+const (
+	testSession = "550e8400-e29b-41d4-a716-446655440000"
+	testEvent   = "550e8400-e29b-41d4-a716-446655440001"
+	otherEvent  = "550e8400-e29b-41d4-a716-446655440002"
+)
+
+// memoryAnnotations keeps annotations in memory like the audit_event_annotations table
+type memoryAnnotations struct {
+	annotations map[string]domain.EventAnnotation
+	order       []string
+}
+
+func (r *memoryAnnotations) CreateAnnotation(_ context.Context, annotation domain.EventAnnotation) error {
+	r.annotations[annotation.ID] = annotation
+	r.order = append(r.order, annotation.ID)
+	return nil
+}
+
+func (r *memoryAnnotations) GetAnnotation(_ context.Context, id string) (*domain.EventAnnotation, error) {
+	annotation, ok := r.annotations[id]
+	if !ok {
+		return nil, domain.ErrAnnotationNotFound
+	}
+	return &annotation, nil
+}
+
+func (r *memoryAnnotations) ListAnnotations(_ context.Context, eventID string) ([]domain.EventAnnotation, error) {
+	var annotations []domain.EventAnnotation
+	for _, id := range r.order {
+		if annotation, ok := r.annotations[id]; ok && annotation.EventID == eventID {
+			annotations = append(annotations, annotation)
+		}
+	}
+	return annotations, nil
+}
+
+func (r *memoryAnnotations) UpdateAnnotation(_ context.Context, annotation domain.EventAnnotation) error {
+	stored, ok := r.annotations[annotation.ID]
+	if !ok || stored.Version != annotation.Version-1 {
+		return domain.ErrVersionConflict
+	}
+	r.annotations[annotation.ID] = annotation
+	return nil
+}
+
+func (r *memoryAnnotations) DeleteAnnotation(_ context.Context, id string, version int64) error {
+	stored, ok := r.annotations[id]
+	if !ok || stored.Version != version {
+		return domain.ErrVersionConflict
+	}
+	delete(r.annotations, id)
+	return nil
+}
+
+// memoryEvents serves two events of a session readable by user-1 and user-2
+type memoryEvents struct{}
+
+func (memoryEvents) FindEvent(_ context.Context, eventID domain.EventID) (*domain.AuditEntry, error) {
+	if eventID != testEvent && eventID != otherEvent {
+		return nil, domain.ErrEventNotFound
+	}
+	return &domain.AuditEntry{ID: string(eventID), SessionID: testSession}, nil
+}
+
+func (memoryEvents) AuthorizeSession(_ context.Context, _ domain.SessionID, userID domain.UserID, _ bool) error {
+	if userID != "user-1" && userID != "user-2" {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// memoryWriter collects the recorded events
+type memoryWriter struct {
+	entries []domain.AuditEntry
+	err     error
+}
+
+func (w *memoryWriter) CreateEvent(_ context.Context, entry domain.AuditEntry) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.entries = append(w.entries, entry)
+	return nil
+}
+
+func newTestService() (*Service, *memoryAnnotations, *memoryWriter) {
+	repo := &memoryAnnotations{annotations: map[string]domain.EventAnnotation{}}
+	writer := &memoryWriter{}
+	return New(repo, memoryEvents{}, memoryEvents{}, writer, clock.NewFakeClock(testNow), zap.NewNop()), repo, writer
+}
+
+func decodeDetails(t *testing.T, entry domain.AuditEntry) domain.AnnotationDetails {
+	t.Helper()
+	var details domain.AnnotationDetails
+	require.NoError(t, json.Unmarshal(entry.Details, &details))
+	return details
+}
+
+func TestService_Thread(t *testing.T) {
+	svc, _, writer := newTestService()
+	ctx := context.Background()
+
+	thread, err := svc.List(ctx, testEvent, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.EventAnnotation{}, thread.Items)
+
+	first, err := svc.Create(ctx, testEvent, "user-1", " Export made from an unrecognized IP ")
+	require.NoError(t, err)
+	assert.Equal(t, "Export made from an unrecognized IP", first.Body)
+	assert.Equal(t, int64(1), first.Version)
+	assert.Equal(t, testSession, first.SessionID)
+	assert.Equal(t, "user-1", first.AuthorID)
+	assert.Equal(t, testNow, first.CreatedAt)
+
+	second, err := svc.Create(ctx, testEvent, "user-2", "Confirmed with the owner")
+	require.NoError(t, err)
+
+	thread, err = svc.List(ctx, testEvent, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, testEvent, thread.EventID)
+	assert.Equal(t, testSession, thread.SessionID)
+	assert.Equal(t, []domain.EventAnnotation{first, second}, thread.Items)
+
+	// Each annotation records who annotated what, as a child of the annotated event
+	require.Len(t, writer.entries, 2)
+	entry := writer.entries[0]
+	assert.Equal(t, string(domain.ActionAnnotationCreated), entry.Type)
+	assert.Equal(t, testSession, entry.SessionID)
+	assert.Equal(t, "user-1", entry.UserID)
+	assert.Equal(t, testEvent, entry.ParentEventID)
+	assert.Equal(t, first.ID, entry.CorrelationID)
+	assert.Equal(t, domain.AnnotationDetails{
+		AnnotationID: first.ID,
+		EventID:      testEvent,
+		Version:      1,
+	}, decodeDetails(t, entry))
+	// The session owner reads the trail, so it leaves out the finding
+	assert.NotContains(t, string(entry.Details), "unrecognized IP")
+	assert.Equal(t, "user-2", writer.entries[1].UserID)
+}
+
+func TestService_EditAndDelete(t *testing.T) {
+	svc, repo, writer := newTestService()
+	ctx := context.Background()
+
+	created, err := svc.Create(ctx, testEvent, "user-1", "First finding")
+	require.NoError(t, err)
+
+	edited, err := svc.Edit(ctx, testEvent, created.ID, "user-1", "Revised finding", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), edited.Version)
+	assert.Equal(t, "Revised finding", edited.Body)
+	assert.Equal(t, created.CreatedAt, edited.CreatedAt)
+
+	// A stale version is rejected
+	_, err = svc.Edit(ctx, testEvent, created.ID, "user-1", "Lost edit", 1)
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+	assert.ErrorIs(t, svc.Delete(ctx, testEvent, created.ID, "user-1", 1), domain.ErrVersionConflict)
+
+	require.NoError(t, svc.Delete(ctx, testEvent, created.ID, "user-1", 2))
+	assert.Empty(t, repo.annotations)
+
+	require.Len(t, writer.entries, 3)
+	assert.Equal(t, string(domain.ActionAnnotationEdited), writer.entries[1].Type)
+	deleted := writer.entries[2]
+	assert.Equal(t, string(domain.ActionAnnotationDeleted), deleted.Type)
+	assert.Equal(t, created.ID, deleted.CorrelationID)
+	assert.Equal(t, int64(2), decodeDetails(t, deleted).Version)
+	assert.NotContains(t, string(deleted.Details), "Revised finding")
+
+	_, err = svc.Get(ctx, testEvent, created.ID, "user-1")
+	assert.ErrorIs(t, err, domain.ErrAnnotationNotFound)
+}
+
+func TestService_Rejects(t *testing.T) {
+	svc, _, writer := newTestService()
+	ctx := context.Background()
+
+	created, err := svc.Create(ctx, testEvent, "user-1", "Finding")
+	require.NoError(t, err)
+
+	t.Run("other author", func(t *testing.T) {
+		_, err := svc.Edit(ctx, testEvent, created.ID, "user-2", "Not mine", 1)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.ErrorIs(t, svc.Delete(ctx, testEvent, created.ID, "user-2", 1), domain.ErrForbidden)
+	})
+
+	t.Run("unauthorized reader", func(t *testing.T) {
+		_, err := svc.List(ctx, testEvent, "user-3")
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		_, err = svc.Create(ctx, testEvent, "user-3", "Finding")
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("other event", func(t *testing.T) {
+		_, err := svc.Get(ctx, otherEvent, created.ID, "user-1")
+		assert.ErrorIs(t, err, domain.ErrAnnotationNotFound)
+	})
+
+	t.Run("malformed IDs", func(t *testing.T) {
+		_, err := svc.Get(ctx, testEvent, "not-a-uuid", "user-1")
+		assert.ErrorIs(t, err, domain.ErrAnnotationNotFound)
+		_, err = svc.List(ctx, "not-a-uuid", "user-1")
+		assert.ErrorIs(t, err, domain.ErrEventNotFound)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		_, err := svc.Create(ctx, testEvent, "user-1", "  ")
+		assert.ErrorIs(t, err, domain.ErrInvalidAnnotation)
+		_, err = svc.Edit(ctx, testEvent, created.ID, "user-1", "", 1)
+		assert.ErrorIs(t, err, domain.ErrInvalidAnnotation)
+	})
+
+	// Nothing but the first annotation was recorded
+	assert.Len(t, writer.entries, 1)
+}
+
+func TestService_RecordFailure(t *testing.T) {
+	svc, _, writer := newTestService()
+	writer.err = errors.New("database unavailable")
+
+	_, err := svc.Create(context.Background(), testEvent, "user-1", "Finding")
+	assert.ErrorIs(t, err, writer.err)
+}
@@ -0,0 +1,415 @@
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"audit-service/internal/broadcast"
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/internal/outbox"
+	"audit-service/internal/retention"
+	"audit-service/pkg/clock"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Rules that raise security alerts
+const (
+	RuleMassDeletion = "mass_deletion"
+	RuleExportBurst  = "export_burst"
+	RuleNewIP        = "new_ip"
+	RuleOffHours     = "off_hours"
+	// RuleBruteForce is raised by the brute-force guard when it throttles a client or user
+	RuleBruteForce = "brute_force"
+)
+
+// Alert severities
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+const (
+	// queueSize is how many published entries may wait for the detector before new ones are dropped
+	queueSize = 4096
+
+	// maxKnownIPs bounds the addresses remembered per user; the least recently used is forgotten first
+	maxKnownIPs = 20
+
+	// stateTTL is how long the activity of an idle user is remembered
+	stateTTL = 30 * 24 * time.Hour
+
+	// pruneInterval is how often the state of idle users is dropped
+	pruneInterval = time.Hour
+)
+
+// RecordFunc persists the security_alert events
+type RecordFunc func(ctx context.Context, entries []domain.AuditEntry) error
+
+// Config holds the thresholds of the detection rules
+type Config struct {
+	// Window is the period over which deletions and exports are counted
+	Window time.Duration
+	// DeletionThreshold and ExportThreshold are the counts within Window that raise an alert; zero disables the rule
+	DeletionThreshold int
+	ExportThreshold   int
+	// WorkdayStart and WorkdayEnd are offsets from local midnight; equal values disable the off-hours rule.
+	// A start after the end describes a shift that crosses midnight.
+	WorkdayStart time.Duration
+	WorkdayEnd   time.Duration
+	Location     *time.Location
+}
+
+// Alert is the details payload of a security_alert event
+type Alert struct {
+	Rule        string   `json:"rule"`
+	Severity    string   `json:"severity"`
+	UserID      string   `json:"userId"`
+	Description string   `json:"description"`
+	Count       int      `json:"count,omitempty"`
+	Window      string   `json:"window,omitempty"`
+	IPAddress   string   `json:"ipAddress,omitempty"`
+	Lockout     string   `json:"lockout,omitempty"`
+	EventIDs    []string `json:"eventIds"`
+}
+
+// Detector watches newly created audit entries and flags suspicious activity per user.
+// State is kept in memory, so after a restart windows start empty and the next address
+// seen for a user becomes its baseline again.
+type Detector struct {
+	broker   *broadcast.Broker
+	record   RecordFunc
+	notifier outbox.Sink
+	cfg      Config
+	clock    clock.Clock
+	metrics  *metrics.Metrics
+	logger   *zap.Logger
+
+	// users is only accessed by the detector goroutine
+	users map[string]*userState
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// userState is the recent activity of one user
+type userState struct {
+	deletions []observation
+	exports   []observation
+	// ips holds known addresses, least recently used first
+	ips []string
+	// offHoursDay is the local date of the last off-hours alert
+	offHoursDay string
+	lastSeen    time.Time
+}
+
+// observation is one counted event
+type observation struct {
+	id string
+	at time.Time
+}
+
+// New creates a detector; call Start to begin watching the broker.
+// When notifier is nil alerts are only recorded as audit events.
+func New(broker *broadcast.Broker, record RecordFunc, notifier outbox.Sink, cfg Config, clk clock.Clock, m *metrics.Metrics, logger *zap.Logger) *Detector {
+	if cfg.Location == nil {
+		cfg.Location = time.UTC
+	}
+	return &Detector{
+		broker:   broker,
+		record:   record,
+		notifier: notifier,
+		cfg:      cfg,
+		clock:    clk,
+		metrics:  m,
+		logger:   logger,
+		users:    make(map[string]*userState),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// Start subscribes to every published entry; entries created after Start returns are analyzed
+func (d *Detector) Start() {
+	sub := d.broker.SubscribeBuffered(broadcast.AllTopic, queueSize)
+	go d.run(sub)
+}
+
+// Close stops the detector and waits for alerts being recorded to be cancelled
+func (d *Detector) Close(ctx context.Context) error {
+	d.closeOnce.Do(func() { close(d.stop) })
+
+	select {
+	case <-d.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("anomaly detector did not stop: %w", ctx.Err())
+	}
+}
+
+// run analyzes entries until Close is called or the broker shuts down
+func (d *Detector) run(sub *broadcast.Subscription) {
+	defer close(d.stopped)
+	defer sub.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-d.stop
+		cancel()
+	}()
+
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			d.prune(d.clock.Now())
+		case entry, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if alerts := d.Observe(entry); len(alerts) > 0 {
+				d.raise(ctx, entry, alerts)
+			}
+		}
+	}
+}
+
+// Observe applies every rule to an entry and returns the alerts it triggers.
+// It is not safe for concurrent use.
+func (d *Detector) Observe(entry domain.AuditEntry) []Alert {
+	// Events written by the service itself, including earlier alerts, are not user activity
+	if entry.UserID == "" || entry.UserID == retention.SystemUserID {
+		return nil
+	}
+
+	at := entry.Timestamp
+	if at.IsZero() {
+		at = d.clock.Now()
+	}
+
+	state, ok := d.users[entry.UserID]
+	if !ok {
+		state = &userState{}
+		d.users[entry.UserID] = state
+	}
+	state.lastSeen = at
+
+	var alerts []Alert
+	if isDeletion(entry) {
+		if alert, ok := d.countInWindow(&state.deletions, entry, at, d.cfg.DeletionThreshold); ok {
+			alert.Rule, alert.Severity = RuleMassDeletion, SeverityHigh
+			alert.Description = fmt.Sprintf("%d deletions within %s", alert.Count, d.cfg.Window)
+			alerts = append(alerts, alert)
+		}
+	}
+	if entry.Type == string(domain.ActionExport) {
+		if alert, ok := d.countInWindow(&state.exports, entry, at, d.cfg.ExportThreshold); ok {
+			alert.Rule, alert.Severity = RuleExportBurst, SeverityMedium
+			alert.Description = fmt.Sprintf("%d exports within %s", alert.Count, d.cfg.Window)
+			alerts = append(alerts, alert)
+		}
+	}
+	if alert, ok := d.checkIP(state, entry); ok {
+		alerts = append(alerts, alert)
+	}
+	if alert, ok := d.checkWorkingHours(state, entry, at); ok {
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// countInWindow adds an event to a sliding window and reports an alert once the threshold is
+// reached. The window is emptied after an alert, so a burst is reported once.
+func (d *Detector) countInWindow(window *[]observation, entry domain.AuditEntry, at time.Time, threshold int) (Alert, bool) {
+	if threshold <= 0 {
+		return Alert{}, false
+	}
+
+	cutoff := at.Add(-d.cfg.Window)
+	kept := (*window)[:0]
+	for _, obs := range *window {
+		if obs.at.After(cutoff) {
+			kept = append(kept, obs)
+		}
+	}
+	kept = append(kept, observation{id: entry.ID, at: at})
+	*window = kept
+
+	if len(kept) < threshold {
+		return Alert{}, false
+	}
+
+	ids := make([]string, len(kept))
+	for i, obs := range kept {
+		ids[i] = obs.id
+	}
+	*window = nil
+	return Alert{
+		UserID:   entry.UserID,
+		Count:    len(ids),
+		Window:   d.cfg.Window.String(),
+		EventIDs: ids,
+	}, true
+}
+
+// checkIP flags a user acting from an address not seen before. The first address of a user is
+// its baseline and is not reported.
+func (d *Detector) checkIP(state *userState, entry domain.AuditEntry) (Alert, bool) {
+	ip := entry.IPAddress
+	if ip == "" {
+		return Alert{}, false
+	}
+
+	if i := slices.Index(state.ips, ip); i >= 0 {
+		state.ips = append(slices.Delete(state.ips, i, i+1), ip)
+		return Alert{}, false
+	}
+
+	baseline := len(state.ips) == 0
+	state.ips = append(state.ips, ip)
+	if len(state.ips) > maxKnownIPs {
+		state.ips = state.ips[1:]
+	}
+	if baseline {
+		return Alert{}, false
+	}
+
+	return Alert{
+		Rule:        RuleNewIP,
+		Severity:    SeverityLow,
+		UserID:      entry.UserID,
+		Description: "activity from a new IP address " + ip,
+		IPAddress:   ip,
+		EventIDs:    []string{entry.ID},
+	}, true
+}
+
+// checkWorkingHours flags activity on weekends or outside working hours, once per user and local day
+func (d *Detector) checkWorkingHours(state *userState, entry domain.AuditEntry, at time.Time) (Alert, bool) {
+	if d.cfg.WorkdayStart == d.cfg.WorkdayEnd {
+		return Alert{}, false
+	}
+
+	local := at.In(d.cfg.Location)
+	if d.isWorkingTime(local) {
+		return Alert{}, false
+	}
+
+	day := local.Format(time.DateOnly)
+	if state.offHoursDay == day {
+		return Alert{}, false
+	}
+	state.offHoursDay = day
+
+	return Alert{
+		Rule:        RuleOffHours,
+		Severity:    SeverityLow,
+		UserID:      entry.UserID,
+		Description: "activity outside working hours at " + local.Format("Mon 15:04 MST"),
+		EventIDs:    []string{entry.ID},
+	}, true
+}
+
+// isWorkingTime reports whether a local time falls on a weekday within working hours
+func (d *Detector) isWorkingTime(local time.Time) bool {
+	if weekday := local.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+		return false
+	}
+
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	offset := local.Sub(midnight)
+	if d.cfg.WorkdayStart < d.cfg.WorkdayEnd {
+		return offset >= d.cfg.WorkdayStart && offset < d.cfg.WorkdayEnd
+	}
+	return offset >= d.cfg.WorkdayStart || offset < d.cfg.WorkdayEnd
+}
+
+// raise records the alerts as security_alert events in the session of the triggering entry and
+// notifies the webhook. Failures are logged; the entry that triggered them is already stored.
+func (d *Detector) raise(ctx context.Context, trigger domain.AuditEntry, alerts []Alert) {
+	now := d.clock.Now()
+	entries := make([]domain.AuditEntry, 0, len(alerts))
+	for _, alert := range alerts {
+		details, _ := domain.EncodeDetails(alert)
+		entries = append(entries, domain.AuditEntry{
+			ID:        uuid.New().String(),
+			SessionID: trigger.SessionID,
+			UserID:    retention.SystemUserID,
+			Type:      string(domain.ActionSecurityAlert),
+			Timestamp: now,
+			Details:   details,
+			// Alerts are only visible in the organization of the triggering entry
+			OrganizationID: trigger.OrganizationID,
+		})
+
+		d.metrics.ObserveAnomalyAlert(alert.Rule)
+		d.logger.Warn("security alert raised",
+			zap.String("rule", alert.Rule),
+			zap.String("severity", alert.Severity),
+			zap.String("user_id", alert.UserID),
+			zap.String("session_id", trigger.SessionID),
+			zap.String("description", alert.Description),
+		)
+	}
+
+	if err := d.record(ctx, entries); err != nil {
+		d.logger.Error("failed to record security alerts", zap.Error(err))
+	}
+
+	if d.notifier == nil {
+		return
+	}
+	for _, entry := range entries {
+		payload, err := domain.NewOutboxPayload(entry)
+		if err != nil {
+			continue
+		}
+		// Alerts are notified once; the stored events remain the source of truth
+		err = d.notifier.Deliver(ctx, domain.OutboxMessage{
+			EventID:   entry.ID,
+			SessionID: entry.SessionID,
+			Type:      entry.Type,
+			Payload:   payload,
+			Attempts:  1,
+			CreatedAt: entry.Timestamp,
+		})
+		if err != nil {
+			d.logger.Error("failed to notify security alert", zap.String("event_id", entry.ID), zap.Error(err))
+		}
+	}
+}
+
+// prune forgets users that have been idle for longer than stateTTL
+func (d *Detector) prune(now time.Time) {
+	cutoff := now.Add(-stateTTL)
+	for userID, state := range d.users {
+		if state.lastSeen.Before(cutoff) {
+			delete(d.users, userID)
+		}
+	}
+}
+
+// isDeletion reports whether an entry records a deletion. Deletions are logged as edits whose
+// details.action starts with "delete", such as delete_session.
+func isDeletion(entry domain.AuditEntry) bool {
+	details, err := domain.DecodeDetails[struct {
+		Action string `json:"action"`
+	}](entry)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(details.Action, "delete")
+}
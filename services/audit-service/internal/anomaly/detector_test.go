@@ -0,0 +1,244 @@
+package anomaly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"audit-service/internal/broadcast"
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/internal/outbox"
+	"audit-service/internal/retention"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testNow is a Wednesday afternoon, within the default working hours of the tests
+var testNow = time.Date(2024, 1, 10, 14, 0, 0, 0, time.UTC)
+
+var testConfig = Config{
+	Window:            10 * time.Minute,
+	DeletionThreshold: 3,
+	ExportThreshold:   2,
+	WorkdayStart:      8 * time.Hour,
+	WorkdayEnd:        19 * time.Hour,
+}
+
+func newTestDetector(cfg Config, record RecordFunc, notifier *fakeNotifier) *Detector {
+	if record == nil {
+		record = func(context.Context, []domain.AuditEntry) error { return nil }
+	}
+	var sink outbox.Sink
+	if notifier != nil {
+		sink = notifier
+	}
+	return New(broadcast.NewBroker(zap.NewNop()), record, sink, cfg, clock.NewFakeClock(testNow), metrics.New(), zap.NewNop())
+}
+
+type fakeNotifier struct {
+	messages chan domain.OutboxMessage
+}
+
+func (n *fakeNotifier) Deliver(_ context.Context, message domain.OutboxMessage) error {
+	n.messages <- message
+	return nil
+}
+
+func deletion(id string, at time.Time) domain.AuditEntry {
+	return domain.AuditEntry{
+		ID: id, SessionID: "session-1", UserID: "user-1", Type: "edit", Timestamp: at,
+		Details: json.RawMessage(`{"action":"delete_session"}`),
+	}
+}
+
+func rules(alerts []Alert) []string {
+	names := make([]string, len(alerts))
+	for i, alert := range alerts {
+		names[i] = alert.Rule
+	}
+	return names
+}
+
+func TestDetector_MassDeletion(t *testing.T) {
+	detector := newTestDetector(testConfig, nil, nil)
+
+	assert.Empty(t, detector.Observe(deletion("event-1", testNow)))
+	// Deletions that fell out of the window do not count
+	assert.Empty(t, detector.Observe(deletion("event-2", testNow.Add(11*time.Minute))))
+	assert.Empty(t, detector.Observe(deletion("event-3", testNow.Add(12*time.Minute))))
+
+	alerts := detector.Observe(deletion("event-4", testNow.Add(13*time.Minute)))
+	require.Len(t, alerts, 1)
+	assert.Equal(t, RuleMassDeletion, alerts[0].Rule)
+	assert.Equal(t, SeverityHigh, alerts[0].Severity)
+	assert.Equal(t, 3, alerts[0].Count)
+	assert.Equal(t, []string{"event-2", "event-3", "event-4"}, alerts[0].EventIDs)
+
+	// The burst is reported once
+	assert.Empty(t, detector.Observe(deletion("event-5", testNow.Add(14*time.Minute))))
+
+	// Ordinary edits are not deletions
+	edit := deletion("event-6", testNow.Add(14*time.Minute))
+	edit.Details = json.RawMessage(`{"slide":1}`)
+	assert.Empty(t, detector.Observe(edit))
+}
+
+func TestDetector_ExportBurst(t *testing.T) {
+	detector := newTestDetector(testConfig, nil, nil)
+
+	export := domain.AuditEntry{ID: "event-1", SessionID: "session-1", UserID: "user-1", Type: "export", Timestamp: testNow}
+	assert.Empty(t, detector.Observe(export))
+
+	// Exports of another user are counted separately
+	other := export
+	other.ID, other.UserID = "event-2", "user-2"
+	assert.Empty(t, detector.Observe(other))
+
+	export.ID = "event-3"
+	alerts := detector.Observe(export)
+	assert.Equal(t, []string{RuleExportBurst}, rules(alerts))
+	assert.Equal(t, 2, alerts[0].Count)
+}
+
+func TestDetector_NewIP(t *testing.T) {
+	detector := newTestDetector(testConfig, nil, nil)
+
+	entry := domain.AuditEntry{ID: "event-1", SessionID: "session-1", UserID: "user-1", Type: "view", Timestamp: testNow}
+
+	// The first address is the baseline
+	entry.IPAddress = "192.0.2.1"
+	assert.Empty(t, detector.Observe(entry))
+	assert.Empty(t, detector.Observe(entry))
+
+	entry.IPAddress = "198.51.100.7"
+	alerts := detector.Observe(entry)
+	require.Equal(t, []string{RuleNewIP}, rules(alerts))
+	assert.Equal(t, "198.51.100.7", alerts[0].IPAddress)
+
+	// Known addresses are not reported again
+	assert.Empty(t, detector.Observe(entry))
+	entry.IPAddress = "192.0.2.1"
+	assert.Empty(t, detector.Observe(entry))
+}
+
+func TestDetector_OffHours(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		at       time.Time
+		expected bool
+	}{
+		{name: "within working hours", cfg: testConfig, at: testNow, expected: false},
+		{name: "before work", cfg: testConfig, at: time.Date(2024, 1, 10, 6, 30, 0, 0, time.UTC), expected: true},
+		{name: "at the end of the day", cfg: testConfig, at: time.Date(2024, 1, 10, 19, 0, 0, 0, time.UTC), expected: true},
+		{name: "weekend", cfg: testConfig, at: time.Date(2024, 1, 13, 14, 0, 0, 0, time.UTC), expected: true},
+		{
+			name: "local time zone",
+			cfg: Config{WorkdayStart: 8 * time.Hour, WorkdayEnd: 19 * time.Hour,
+				Location: time.FixedZone("UTC+9", 9*60*60)},
+			// 14:00 UTC is 23:00 in UTC+9
+			at:       testNow,
+			expected: true,
+		},
+		{
+			name:     "shift across midnight",
+			cfg:      Config{WorkdayStart: 22 * time.Hour, WorkdayEnd: 6 * time.Hour},
+			at:       time.Date(2024, 1, 10, 2, 0, 0, 0, time.UTC),
+			expected: false,
+		},
+		{name: "rule disabled", cfg: Config{}, at: time.Date(2024, 1, 10, 3, 0, 0, 0, time.UTC), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := newTestDetector(tt.cfg, nil, nil)
+
+			alerts := detector.Observe(domain.AuditEntry{ID: "event-1", UserID: "user-1", Type: "view", Timestamp: tt.at})
+			assert.Equal(t, tt.expected, len(alerts) == 1)
+		})
+	}
+}
+
+func TestDetector_OffHoursOncePerDay(t *testing.T) {
+	detector := newTestDetector(testConfig, nil, nil)
+
+	night := time.Date(2024, 1, 10, 22, 0, 0, 0, time.UTC)
+	assert.Len(t, detector.Observe(domain.AuditEntry{ID: "event-1", UserID: "user-1", Timestamp: night}), 1)
+	assert.Empty(t, detector.Observe(domain.AuditEntry{ID: "event-2", UserID: "user-1", Timestamp: night.Add(time.Hour)}))
+	assert.Len(t, detector.Observe(domain.AuditEntry{ID: "event-3", UserID: "user-1", Timestamp: night.Add(8 * time.Hour)}), 1)
+}
+
+func TestDetector_IgnoresSystemEvents(t *testing.T) {
+	detector := newTestDetector(testConfig, nil, nil)
+
+	for i := 0; i < 5; i++ {
+		entry := deletion(fmt.Sprintf("event-%d", i), testNow)
+		entry.UserID = retention.SystemUserID
+		assert.Empty(t, detector.Observe(entry))
+	}
+	assert.Empty(t, detector.users)
+}
+
+func TestDetector_Prune(t *testing.T) {
+	detector := newTestDetector(testConfig, nil, nil)
+
+	detector.Observe(domain.AuditEntry{ID: "event-1", UserID: "user-1", Timestamp: testNow})
+	detector.Observe(domain.AuditEntry{ID: "event-2", UserID: "user-2", Timestamp: testNow.Add(stateTTL)})
+
+	detector.prune(testNow.Add(stateTTL + time.Minute))
+	assert.NotContains(t, detector.users, "user-1")
+	assert.Contains(t, detector.users, "user-2")
+}
+
+func TestDetector_RecordsAndNotifiesAlerts(t *testing.T) {
+	recorded := make(chan []domain.AuditEntry, 1)
+	notifier := &fakeNotifier{messages: make(chan domain.OutboxMessage, 1)}
+	detector := newTestDetector(testConfig, func(_ context.Context, entries []domain.AuditEntry) error {
+		recorded <- entries
+		return nil
+	}, notifier)
+
+	detector.Start()
+	for i := 0; i < testConfig.ExportThreshold; i++ {
+		detector.broker.Publish(domain.AuditEntry{
+			ID: fmt.Sprintf("event-%d", i), SessionID: "session-1", UserID: "user-1", Type: "export", Timestamp: testNow,
+		})
+	}
+
+	var entries []domain.AuditEntry
+	select {
+	case entries = <-recorded:
+	case <-time.After(time.Second):
+		t.Fatal("alert was not recorded")
+	}
+	require.Len(t, entries, 1)
+	assert.Equal(t, "session-1", entries[0].SessionID)
+	assert.Equal(t, retention.SystemUserID, entries[0].UserID)
+	assert.Equal(t, string(domain.ActionSecurityAlert), entries[0].Type)
+
+	var alert Alert
+	require.NoError(t, json.Unmarshal(entries[0].Details, &alert))
+	assert.Equal(t, RuleExportBurst, alert.Rule)
+	assert.Equal(t, "user-1", alert.UserID)
+	assert.Equal(t, []string{"event-0", "event-1"}, alert.EventIDs)
+
+	select {
+	case message := <-notifier.messages:
+		assert.Equal(t, entries[0].ID, message.EventID)
+		assert.Equal(t, string(domain.ActionSecurityAlert), message.Type)
+	case <-time.After(time.Second):
+		t.Fatal("alert was not notified")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, detector.Close(ctx))
+	require.NoError(t, detector.Close(ctx)) // closing twice is safe
+	assert.Equal(t, 0, detector.broker.SubscriberCount(broadcast.AllTopic))
+}
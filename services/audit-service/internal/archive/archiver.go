@@ -0,0 +1,229 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	manifestVersion = 1
+	manifestSuffix  = ".manifest.json"
+	dataSuffix      = ".ndjson.gz"
+
+	// restoreBatchSize bounds the entries inserted per request during a restore
+	restoreBatchSize = 500
+)
+
+// Manifest describes one archived batch; it is written after the data object it points to
+type Manifest struct {
+	Version   int       `json:"version"`
+	Object    string    `json:"object"`
+	EventType string    `json:"eventType"`
+	Count     int       `json:"count"`
+	SHA256    string    `json:"sha256"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Sessions  []string  `json:"sessions"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// InsertFunc stores restored entries
+type InsertFunc func(ctx context.Context, entries []domain.AuditEntry) error
+
+// RestoreResult counts what a restore read from the archive
+type RestoreResult struct {
+	Batches int
+	Events  int
+}
+
+// Archiver writes expired audit entries to an object store as gzip-compressed NDJSON
+type Archiver struct {
+	store  ObjectStore
+	prefix string
+	clock  clock.Clock
+	logger *zap.Logger
+}
+
+// NewArchiver creates an archiver writing below the given key prefix
+func NewArchiver(store ObjectStore, prefix string, clk clock.Clock, logger *zap.Logger) *Archiver {
+	return &Archiver{
+		store:  store,
+		prefix: strings.Trim(prefix, "/"),
+		clock:  clk,
+		logger: logger,
+	}
+}
+
+// Archive uploads entries of one event type followed by their manifest
+func (a *Archiver) Archive(ctx context.Context, eventType string, entries []domain.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gz)
+	sessions := map[string]struct{}{}
+	from, to := entries[0].Timestamp, entries[0].Timestamp
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("failed to encode audit entry %s: %w", entry.ID, err)
+		}
+		sessions[entry.SessionID] = struct{}{}
+		if entry.Timestamp.Before(from) {
+			from = entry.Timestamp
+		}
+		if entry.Timestamp.After(to) {
+			to = entry.Timestamp
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress archive: %w", err)
+	}
+	data := buf.Bytes()
+
+	// Objects are grouped by event type and the day of their oldest entry
+	base := path.Join(a.prefix, eventType, from.UTC().Format("2006/01/02"), uuid.New().String())
+	manifest := Manifest{
+		Version:   manifestVersion,
+		Object:    base + dataSuffix,
+		EventType: eventType,
+		Count:     len(entries),
+		SHA256:    sha256Hex(data),
+		From:      from.UTC(),
+		To:        to.UTC(),
+		Sessions:  sortedSet(sessions),
+		CreatedAt: a.clock.Now(),
+	}
+
+	if err := a.store.Put(ctx, manifest.Object, data, "application/gzip"); err != nil {
+		return fmt.Errorf("failed to upload archive: %w", err)
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := a.store.Put(ctx, base+manifestSuffix, manifestJSON, "application/json"); err != nil {
+		return fmt.Errorf("failed to upload manifest: %w", err)
+	}
+
+	a.logger.Info("archived audit events",
+		zap.String("type", eventType),
+		zap.String("object", manifest.Object),
+		zap.Int("count", len(entries)),
+	)
+	return nil
+}
+
+// Restore reads every archived batch below the prefix, relative to the archive prefix, and inserts its entries
+func (a *Archiver) Restore(ctx context.Context, prefix string, insert InsertFunc) (RestoreResult, error) {
+	var result RestoreResult
+
+	keys, err := a.store.List(ctx, path.Join(a.prefix, prefix))
+	if err != nil {
+		return result, fmt.Errorf("failed to list archive: %w", err)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !strings.HasSuffix(key, manifestSuffix) {
+			continue
+		}
+
+		entries, err := a.readBatch(ctx, key)
+		if err != nil {
+			return result, err
+		}
+
+		for start := 0; start < len(entries); start += restoreBatchSize {
+			end := min(start+restoreBatchSize, len(entries))
+			if err := insert(ctx, entries[start:end]); err != nil {
+				return result, fmt.Errorf("failed to restore %s: %w", key, err)
+			}
+		}
+
+		result.Batches++
+		result.Events += len(entries)
+		a.logger.Info("restored archived audit events",
+			zap.String("manifest", key),
+			zap.Int("count", len(entries)),
+		)
+	}
+
+	return result, nil
+}
+
+// readBatch downloads the data object of a manifest and verifies it before decoding
+func (a *Archiver) readBatch(ctx context.Context, manifestKey string) ([]domain.AuditEntry, error) {
+	manifestJSON, err := a.store.Get(ctx, manifestKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", manifestKey, err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", manifestKey, err)
+	}
+	if manifest.Version != manifestVersion {
+		return nil, fmt.Errorf("manifest %s has unsupported version %d", manifestKey, manifest.Version)
+	}
+
+	data, err := a.store.Get(ctx, manifest.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %w", manifest.Object, err)
+	}
+	if sha256Hex(data) != manifest.SHA256 {
+		return nil, fmt.Errorf("archive %s does not match its manifest checksum", manifest.Object)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress archive %s: %w", manifest.Object, err)
+	}
+	defer gz.Close()
+
+	entries := make([]domain.AuditEntry, 0, manifest.Count)
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry domain.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid entry in archive %s: %w", manifest.Object, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read archive %s: %w", manifest.Object, err)
+	}
+
+	if len(entries) != manifest.Count {
+		return nil, fmt.Errorf("archive %s has %d entries, manifest expects %d", manifest.Object, len(entries), manifest.Count)
+	}
+	return entries, nil
+}
+
+// sortedSet returns the members of a set in a stable order
+func sortedSet(set map[string]struct{}) []string {
+	items := make([]string, 0, len(set))
+	for item := range set {
+		items = append(items, item)
+	}
+	sort.Strings(items)
+	return items
+}
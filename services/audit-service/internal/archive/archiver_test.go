@@ -0,0 +1,150 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memStore keeps objects in memory
+type memStore struct {
+	objects map[string][]byte
+	putErr  error
+}
+
+func newMemStore() *memStore {
+	return &memStore{objects: map[string][]byte{}}
+}
+
+func (s *memStore) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	if s.putErr != nil {
+		return s.putErr
+	}
+	s.objects[key] = body
+	return nil
+}
+
+func (s *memStore) Get(ctx context.Context, key string) ([]byte, error) {
+	body, ok := s.objects[key]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return body, nil
+}
+
+func (s *memStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (s *memStore) manifests() []string {
+	keys, _ := s.List(context.Background(), "")
+	var manifests []string
+	for _, key := range keys {
+		if strings.HasSuffix(key, manifestSuffix) {
+			manifests = append(manifests, key)
+		}
+	}
+	return manifests
+}
+
+var archiveNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func archivedEntries() []domain.AuditEntry {
+	return []domain.AuditEntry{
+		{ID: "audit-1", SessionID: "session-b", UserID: "user-1", Type: "view",
+			Timestamp: time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC), Details: json.RawMessage(`{"slideId":"slide-1"}`)},
+		{ID: "audit-2", SessionID: "session-a", UserID: "user-2", Type: "view",
+			Timestamp: time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC), IPAddress: "203.0.113.7"},
+	}
+}
+
+func TestArchiver_ArchiveWritesDataAndManifest(t *testing.T) {
+	store := newMemStore()
+	archiver := NewArchiver(store, "/audit-logs/", clock.NewFakeClock(archiveNow), zap.NewNop())
+
+	require.NoError(t, archiver.Archive(context.Background(), "view", archivedEntries()))
+
+	manifests := store.manifests()
+	require.Len(t, manifests, 1)
+	assert.True(t, strings.HasPrefix(manifests[0], "audit-logs/view/2024/01/02/"))
+
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(store.objects[manifests[0]], &manifest))
+	assert.Equal(t, 1, manifest.Version)
+	assert.Equal(t, "view", manifest.EventType)
+	assert.Equal(t, 2, manifest.Count)
+	assert.Equal(t, []string{"session-a", "session-b"}, manifest.Sessions)
+	assert.Equal(t, time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC), manifest.From)
+	assert.Equal(t, time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC), manifest.To)
+	assert.Equal(t, archiveNow, manifest.CreatedAt)
+	assert.Equal(t, strings.TrimSuffix(manifests[0], manifestSuffix)+dataSuffix, manifest.Object)
+	assert.Equal(t, sha256Hex(store.objects[manifest.Object]), manifest.SHA256)
+}
+
+func TestArchiver_ArchiveUploadFailure(t *testing.T) {
+	store := newMemStore()
+	store.putErr = errors.New("bucket unavailable")
+	archiver := NewArchiver(store, "audit-logs", clock.NewFakeClock(archiveNow), zap.NewNop())
+
+	err := archiver.Archive(context.Background(), "view", archivedEntries())
+
+	assert.ErrorContains(t, err, "failed to upload archive: bucket unavailable")
+}
+
+func TestArchiver_RestoreRoundTrip(t *testing.T) {
+	store := newMemStore()
+	archiver := NewArchiver(store, "audit-logs", clock.NewFakeClock(archiveNow), zap.NewNop())
+	ctx := context.Background()
+
+	require.NoError(t, archiver.Archive(ctx, "view", archivedEntries()))
+	require.NoError(t, archiver.Archive(ctx, "edit", []domain.AuditEntry{
+		{ID: "audit-3", SessionID: "session-a", Type: "edit", Timestamp: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}))
+
+	var restored []domain.AuditEntry
+	result, err := archiver.Restore(ctx, "view", func(ctx context.Context, entries []domain.AuditEntry) error {
+		restored = append(restored, entries...)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, RestoreResult{Batches: 1, Events: 2}, result)
+	assert.Equal(t, archivedEntries(), restored)
+}
+
+func TestArchiver_RestoreRejectsTamperedArchive(t *testing.T) {
+	store := newMemStore()
+	archiver := NewArchiver(store, "audit-logs", clock.NewFakeClock(archiveNow), zap.NewNop())
+	ctx := context.Background()
+
+	require.NoError(t, archiver.Archive(ctx, "view", archivedEntries()))
+
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(store.objects[store.manifests()[0]], &manifest))
+	store.objects[manifest.Object] = append(store.objects[manifest.Object], 0)
+
+	_, err := archiver.Restore(ctx, "", func(context.Context, []domain.AuditEntry) error {
+		t.Fatal("tampered entries must not be inserted")
+		return nil
+	})
+
+	assert.ErrorContains(t, err, "does not match its manifest checksum")
+}
@@ -0,0 +1,236 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"audit-service/pkg/clock"
+	"audit-service/pkg/sigv4"
+)
+
+// ErrObjectNotFound is returned when a requested object does not exist
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectStore stores archive files
+type ObjectStore interface {
+	Put(ctx context.Context, key string, body []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// S3Config identifies an S3-compatible bucket and its credentials
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PathStyle addresses the bucket as endpoint/bucket instead of bucket.endpoint, as MinIO expects
+	PathStyle bool
+}
+
+// S3Store is a minimal S3 client signing requests with AWS Signature Version 4
+type S3Store struct {
+	cfg        S3Config
+	endpoint   *url.URL
+	httpClient *http.Client
+	clock      clock.Clock
+}
+
+// NewS3Store creates an object store for the configured bucket
+func NewS3Store(cfg S3Config, httpClient *http.Client, clk clock.Clock) (*S3Store, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q: scheme and host are required", cfg.Endpoint)
+	}
+
+	return &S3Store{
+		cfg:        cfg,
+		endpoint:   endpoint,
+		httpClient: httpClient,
+		clock:      clk,
+	}, nil
+}
+
+// Put uploads an object
+func (s *S3Store) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, nil, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	_, err = s.do(req, body)
+	return err
+}
+
+// Get downloads an object
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.do(req, nil)
+}
+
+// listBucketResult is the ListObjectsV2 response body
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns the keys of all objects under the prefix
+func (s *S3Store) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		req, err := s.newRequest(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		data, err := s.do(req, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listBucketResult
+		if err := xml.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse S3 listing: %w", err)
+		}
+		for _, item := range result.Contents {
+			keys = append(keys, item.Key)
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// newRequest builds a request for an object key, or the bucket itself when key is empty
+func (s *S3Store) newRequest(ctx context.Context, method, key string, query url.Values, body []byte) (*http.Request, error) {
+	u := *s.endpoint
+	path := strings.TrimSuffix(u.Path, "/")
+	if s.cfg.PathStyle {
+		path += "/" + s.cfg.Bucket
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+	}
+	path += "/" + key
+
+	u.Path = path
+	u.RawPath = uriEncode(path, false)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	return req, nil
+}
+
+// do signs and sends a request, returning the response body
+func (s *S3Store) do(req *http.Request, body []byte) ([]byte, error) {
+	s.sign(req, body, s.clock.Now())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read S3 response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound && req.Method == http.MethodGet && !strings.HasSuffix(req.URL.Path, "/") {
+		return nil, fmt.Errorf("%s: %w", req.URL.Path, ErrObjectNotFound)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("S3 %s %s failed with status %d: %s", req.Method, req.URL.Path, resp.StatusCode, truncate(string(data), 256))
+	}
+	return data, nil
+}
+
+// sign adds AWS Signature Version 4 headers covering the host and every header already set
+func (s *S3Store) sign(req *http.Request, body []byte, now time.Time) {
+	sigv4.Sign(req, body, sigv4.Credentials{
+		AccessKeyID:     s.cfg.AccessKeyID,
+		SecretAccessKey: s.cfg.SecretAccessKey,
+	}, s.cfg.Region, "s3", now)
+}
+
+// canonicalQuery encodes query parameters sorted by name as SigV4 requires
+func canonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var parts []string
+	for _, name := range names {
+		for _, value := range query[name] {
+			parts = append(parts, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything except RFC 3986 unreserved characters
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
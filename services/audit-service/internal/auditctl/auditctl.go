@@ -0,0 +1,259 @@
+// Package auditctl administers a running audit service through its admin API. It runs as the
+// auditctl command and as the auditctl subcommand of the service binary.
+//
+// Usage:
+//
+//	auditctl [-url URL] events [-session ID] [-user ID] [-type T1,T2] [-from RFC3339] [-to RFC3339] [-q TEXT] [-limit N]
+//	auditctl [-url URL] export -session ID [-format json|csv|ocsf] [-out FILE]
+//	auditctl [-url URL] verify -session ID
+//	auditctl [-url URL] retention run
+//	auditctl [-url URL] outbox replay [-since RFC3339]
+//
+// The instance defaults to AUDITCTL_URL, or http://localhost:4006. Requests are authenticated
+// with the admin JWT in AUDITCTL_TOKEN; without one a short-lived admin token is signed with the
+// HMAC secret in SUPABASE_JWT_SECRET for the user AUDITCTL_USER_ID of organization AUDITCTL_ORG_ID,
+// with the issuer and audience the service expects (SUPABASE_JWT_ISSUER or SUPABASE_URL, and
+// SUPABASE_JWT_AUDIENCE).
+// Events are printed as one JSON object per line. verify exits with status 1 when the chain of
+// the session is broken.
+package auditctl
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/handlers"
+)
+
+// pageSize is how many events are requested per page; the API caps pages at 100
+const pageSize = 100
+
+// Run runs the command line of auditctl without the program name, exiting with a non-zero
+// status when it fails
+func Run(arguments []string) {
+	log.SetFlags(0)
+	log.SetPrefix("auditctl: ")
+
+	flags := flag.NewFlagSet("auditctl", flag.ExitOnError)
+	baseURL := flags.String("url", envOr("AUDITCTL_URL", "http://localhost:4006"), "base URL of the audit service")
+	usage := func() { printUsage(flags) }
+	flags.Usage = usage
+	flags.Parse(arguments)
+
+	if flags.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	token, err := adminToken()
+	if err != nil {
+		log.Fatalf("Failed to authenticate: %v", err)
+	}
+	client := newClient(*baseURL, token)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	args := flags.Args()
+	switch args[0] {
+	case "events":
+		err = queryEvents(ctx, client, args[1:])
+	case "export":
+		err = exportSession(ctx, client, args[1:])
+	case "verify":
+		err = verifySession(ctx, client, args[1:])
+	case "retention":
+		if len(args) < 2 || args[1] != "run" {
+			usage()
+			os.Exit(2)
+		}
+		err = runRetention(ctx, client)
+	case "outbox":
+		if len(args) < 2 || args[1] != "replay" {
+			usage()
+			os.Exit(2)
+		}
+		err = replayOutbox(ctx, client, args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// printUsage describes the commands and the global flags
+func printUsage(flags *flag.FlagSet) {
+	fmt.Fprintf(flags.Output(), `Usage: auditctl [-url URL] <command> [flags]
+
+Commands:
+  events          query events across sessions
+  export          export the events of a session
+  verify          verify the hash chain of a session
+  retention run   purge expired events now
+  outbox replay   redeliver outbox messages that ran out of attempts
+
+Flags:
+`)
+	flags.PrintDefaults()
+}
+
+// queryEvents prints matching events as JSON lines, following the cursor until the limit
+func queryEvents(ctx context.Context, client *client, args []string) error {
+	fs := flag.NewFlagSet("events", flag.ExitOnError)
+	sessionID := fs.String("session", "", "only events of this session")
+	userID := fs.String("user", "", "only events of this user")
+	types := fs.String("type", "", "comma-separated event types")
+	from := fs.String("from", "", "only events at or after this RFC3339 time")
+	to := fs.String("to", "", "only events at or before this RFC3339 time")
+	search := fs.String("q", "", "full-text search in event details")
+	limit := fs.Int("limit", 1000, "maximum number of events to print")
+	fs.Parse(args)
+
+	query := url.Values{}
+	for key, value := range map[string]string{"sessionId": *sessionID, "userId": *userID, "type": *types, "from": *from, "to": *to, "q": *search} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	printed := 0
+	for printed < *limit {
+		query.Set("limit", strconv.Itoa(min(pageSize, *limit-printed)))
+
+		var page domain.AuditResponse
+		if err := client.getJSON(ctx, "/api/v1/admin/events", query, &page); err != nil {
+			return err
+		}
+		for _, entry := range page.Items {
+			if err := encoder.Encode(entry); err != nil {
+				return err
+			}
+		}
+		printed += len(page.Items)
+
+		if page.NextCursor == "" || len(page.Items) == 0 {
+			break
+		}
+		query.Set("cursor", page.NextCursor)
+	}
+	return nil
+}
+
+// exportSession writes the export of a session to a file or standard output
+func exportSession(ctx context.Context, client *client, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	sessionID := fs.String("session", "", "session to export (required)")
+	format := fs.String("format", "json", "export format: json, csv or ocsf")
+	out := fs.String("out", "", "file to write the export to instead of standard output")
+	fs.Parse(args)
+
+	if *sessionID == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	body, err := client.get(ctx, "/api/v1/admin/sessions/"+url.PathEscape(*sessionID)+"/events/export", url.Values{"format": {*format}})
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	written, err := io.Copy(w, body)
+	if err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	if *out != "" {
+		fmt.Printf("Exported session %s to %s (%d bytes)\n", *sessionID, *out, written)
+	}
+	return nil
+}
+
+// verifySession prints the chain verification of a session and exits with status 1 when it is broken
+func verifySession(ctx context.Context, client *client, args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	sessionID := fs.String("session", "", "session to verify (required)")
+	fs.Parse(args)
+
+	if *sessionID == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var result domain.ChainVerification
+	if err := client.getJSON(ctx, "/api/v1/admin/sessions/"+url.PathEscape(*sessionID)+"/events/verify", nil, &result); err != nil {
+		return err
+	}
+	if err := printJSON(result); err != nil {
+		return err
+	}
+	if !result.Valid {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// runRetention purges expired events now and waits for the run to complete
+func runRetention(ctx context.Context, client *client) error {
+	var result handlers.RetentionRunResult
+	if err := client.postJSON(ctx, "/api/v1/admin/retention/run", nil, &result); err != nil {
+		return err
+	}
+	fmt.Printf("Retention run %s in %s\n", result.Status, result.CompletedAt.Sub(result.StartedAt).Round(time.Millisecond))
+	return nil
+}
+
+// replayOutbox makes dead outbox messages due again
+func replayOutbox(ctx context.Context, client *client, args []string) error {
+	fs := flag.NewFlagSet("outbox replay", flag.ExitOnError)
+	since := fs.String("since", "", "only messages created at or after this RFC3339 time")
+	fs.Parse(args)
+
+	query := url.Values{}
+	if *since != "" {
+		query.Set("since", *since)
+	}
+
+	var result handlers.OutboxReplayResult
+	if err := client.postJSON(ctx, "/api/v1/admin/outbox/replay", query, &result); err != nil {
+		return err
+	}
+	fmt.Printf("Replayed %d outbox messages\n", result.Replayed)
+	return nil
+}
+
+func printJSON(value interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
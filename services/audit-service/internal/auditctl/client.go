@@ -0,0 +1,143 @@
+package auditctl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/jwt"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// requestTimeout bounds every request; retention runs and large exports can take a while
+	requestTimeout = 10 * time.Minute
+
+	// tokenTTL is how long minted admin tokens are valid
+	tokenTTL = 5 * time.Minute
+)
+
+// client calls the admin API of an audit service instance
+type client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+func newClient(baseURL, token string) *client {
+	return &client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// get returns the body of a successful GET request; the caller closes it
+func (c *client) get(ctx context.Context, path string, query url.Values) (io.ReadCloser, error) {
+	return c.do(ctx, http.MethodGet, path, query)
+}
+
+func (c *client) getJSON(ctx context.Context, path string, query url.Values, out interface{}) error {
+	body, err := c.do(ctx, http.MethodGet, path, query)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return decode(body, out)
+}
+
+func (c *client) postJSON(ctx context.Context, path string, query url.Values, out interface{}) error {
+	body, err := c.do(ctx, http.MethodPost, path, query)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return decode(body, out)
+}
+
+func (c *client) do(ctx context.Context, method, path string, query url.Values) (io.ReadCloser, error) {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s failed: %w", method, path, err)
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, responseError(resp)
+	}
+	return resp.Body, nil
+}
+
+// responseError describes a failed request using the API error body when there is one
+func responseError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var apiErr domain.APIError
+	if err := json.Unmarshal(data, &apiErr); err == nil && apiErr.Message != "" {
+		return fmt.Errorf("%s: %s (%s)", resp.Status, apiErr.Message, apiErr.Code)
+	}
+	return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+}
+
+func decode(body io.Reader, out interface{}) error {
+	if err := json.NewDecoder(body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// adminToken returns AUDITCTL_TOKEN, or mints a short-lived admin token with the HMAC secret
+// the service validates tokens with
+func adminToken() (string, error) {
+	if token := os.Getenv("AUDITCTL_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	secret := os.Getenv("SUPABASE_JWT_SECRET")
+	if secret == "" {
+		return "", errors.New("set AUDITCTL_TOKEN or SUPABASE_JWT_SECRET")
+	}
+	if _, err := jwtlib.ParseRSAPublicKeyFromPEM([]byte(secret)); err == nil {
+		return "", errors.New("SUPABASE_JWT_SECRET is an RSA public key and cannot sign tokens; set AUDITCTL_TOKEN")
+	}
+
+	// The service checks iss and aud like it does for tokens of the auth server
+	issuer := os.Getenv("SUPABASE_JWT_ISSUER")
+	if issuer == "" && os.Getenv("SUPABASE_URL") != "" {
+		issuer = jwt.SupabaseIssuer(os.Getenv("SUPABASE_URL"))
+	}
+
+	now := time.Now()
+	claims := jwt.Claims{
+		RegisteredClaims: jwtlib.RegisteredClaims{
+			Subject:   envOr("AUDITCTL_USER_ID", "auditctl"),
+			Issuer:    issuer,
+			Audience:  jwtlib.ClaimStrings{envOr("SUPABASE_JWT_AUDIENCE", "authenticated")},
+			IssuedAt:  jwtlib.NewNumericDate(now),
+			ExpiresAt: jwtlib.NewNumericDate(now.Add(tokenTTL)),
+		},
+		AppMetadata: jwt.AppMetadata{
+			Role:           jwt.RoleAdmin,
+			OrganizationID: os.Getenv("AUDITCTL_ORG_ID"),
+		},
+	}
+	return jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, claims).SignedString([]byte(secret))
+}
@@ -0,0 +1,182 @@
+// Package authaudit records the requests rejected by authentication or authorization as
+// auth_failure events of the system session, so rejected tokens and RBAC denials stay in the
+// audit history, where admins query them by the security category, instead of rotating away
+// with the logs.
+package authaudit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/pkg/clock"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// queueSize is how many failures may wait to be recorded before new ones are dropped, so a
+	// flood of rejected requests cannot hold up the requests or the storage
+	queueSize = 4096
+
+	// maxBatch is the number of failures written at once
+	maxBatch = 100
+
+	// writeTimeout bounds the write of a batch
+	writeTimeout = 5 * time.Second
+
+	// AnonymousUserID is the user recorded for requests rejected before a user was authenticated
+	AnonymousUserID = "anonymous"
+)
+
+// RecordFunc persists the auth_failure events
+type RecordFunc func(ctx context.Context, entries []domain.AuditEntry) error
+
+// Recorder writes rejected requests to the system session in batches. Failures are recorded
+// once: a batch that cannot be written is logged and dropped.
+type Recorder struct {
+	sessionID string
+	record    RecordFunc
+	clock     clock.Clock
+	metrics   *metrics.Metrics
+	logger    *zap.Logger
+
+	failures chan domain.AuthFailure
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// New creates a recorder writing to the system session; call Start to begin recording
+func New(sessionID string, record RecordFunc, clk clock.Clock, m *metrics.Metrics, logger *zap.Logger) *Recorder {
+	return &Recorder{
+		sessionID: sessionID,
+		record:    record,
+		clock:     clk,
+		metrics:   m,
+		logger:    logger,
+		failures:  make(chan domain.AuthFailure, queueSize),
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+}
+
+// Start begins recording the reported failures
+func (r *Recorder) Start() {
+	go r.run()
+}
+
+// Close records the failures still queued and stops the recorder
+func (r *Recorder) Close(ctx context.Context) error {
+	r.closeOnce.Do(func() { close(r.stop) })
+
+	select {
+	case <-r.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("auth failure recorder did not stop: %w", ctx.Err())
+	}
+}
+
+// ReportAuthFailure queues a rejected request to be recorded. It never blocks the request;
+// failures reported while the queue is full are dropped.
+func (r *Recorder) ReportAuthFailure(failure domain.AuthFailure) {
+	select {
+	case r.failures <- failure:
+	default:
+		r.metrics.ObserveAuthFailureEvents("dropped", 1)
+	}
+}
+
+// run records the queued failures until Close is called
+func (r *Recorder) run() {
+	defer close(r.stopped)
+
+	for {
+		select {
+		case <-r.stop:
+			// Failures reported before Close are not lost on shutdown
+			for batch := r.drain(nil); len(batch) > 0; batch = r.drain(nil) {
+				r.write(batch)
+			}
+			return
+		case failure := <-r.failures:
+			r.write(r.drain([]domain.AuthFailure{failure}))
+		}
+	}
+}
+
+// drain adds the queued failures to the batch, up to maxBatch, without waiting for more
+func (r *Recorder) drain(batch []domain.AuthFailure) []domain.AuthFailure {
+	for len(batch) < maxBatch {
+		select {
+		case failure := <-r.failures:
+			batch = append(batch, failure)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// write stores a batch of failures as auth_failure events
+func (r *Recorder) write(batch []domain.AuthFailure) {
+	now := r.clock.Now()
+	entries := make([]domain.AuditEntry, 0, len(batch))
+	for _, failure := range batch {
+		entry, err := r.entry(failure, now)
+		if err != nil {
+			r.metrics.ObserveAuthFailureEvents("failed", 1)
+			r.logger.Error("failed to encode auth_failure event", zap.String("request_id", failure.RequestID), zap.Error(err))
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	if err := r.record(ctx, entries); err != nil {
+		r.metrics.ObserveAuthFailureEvents("failed", len(entries))
+		r.logger.Error("failed to record auth_failure events",
+			zap.Int("count", len(entries)),
+			zap.Error(err),
+		)
+		return
+	}
+	r.metrics.ObserveAuthFailureEvents("recorded", len(entries))
+}
+
+// entry builds the auth_failure event of a rejected request
+func (r *Recorder) entry(failure domain.AuthFailure, now time.Time) (domain.AuditEntry, error) {
+	details, err := domain.EncodeDetails(failure.Details())
+	if err != nil {
+		return domain.AuditEntry{}, err
+	}
+	userID := failure.UserID
+	if userID == "" {
+		userID = AnonymousUserID
+	}
+	timestamp := failure.Time
+	if timestamp.IsZero() {
+		timestamp = now
+	}
+	return domain.AuditEntry{
+		ID:         uuid.New().String(),
+		SessionID:  r.sessionID,
+		UserID:     userID,
+		Type:       string(domain.ActionAuthFailure),
+		Timestamp:  timestamp,
+		Details:    details,
+		IPAddress:  failure.ClientIP,
+		UserAgent:  failure.UserAgent,
+		ReceivedAt: &now,
+	}, nil
+}
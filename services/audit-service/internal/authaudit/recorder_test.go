@@ -0,0 +1,126 @@
+package authaudit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+const testSystemSessionID = "550e8400-e29b-41d4-a716-446655440099"
+
+// store collects the auth_failure events
+type store struct {
+	mu      sync.Mutex
+	entries []domain.AuditEntry
+	err     error
+}
+
+func (s *store) record(_ context.Context, entries []domain.AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.entries = append(s.entries, entries...)
+	return nil
+}
+
+func (s *store) recorded() []domain.AuditEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]domain.AuditEntry(nil), s.entries...)
+}
+
+func TestRecorder_RecordsFailures(t *testing.T) {
+	s := &store{}
+	r := New(testSystemSessionID, s.record, clock.NewFakeClock(testNow), metrics.New(), zap.NewNop())
+	r.Start()
+
+	rejectedAt := testNow.Add(-time.Second)
+	r.ReportAuthFailure(domain.AuthFailure{
+		Time:      rejectedAt,
+		Method:    http.MethodGet,
+		Route:     "/api/v1/sessions/:sessionId/history",
+		Status:    http.StatusUnauthorized,
+		Reason:    domain.AuthReasonExpiredToken,
+		ClientIP:  "203.0.113.7",
+		UserAgent: "curl/8.0",
+		RequestID: "req-1",
+	})
+	r.ReportAuthFailure(domain.AuthFailure{
+		Time:      rejectedAt,
+		Method:    http.MethodGet,
+		Route:     "/api/v1/admin/events",
+		Status:    http.StatusForbidden,
+		Reason:    domain.AuthReasonNotAdmin,
+		ClientIP:  "203.0.113.7",
+		UserID:    "user-1",
+		RequestID: "req-2",
+	})
+	require.NoError(t, r.Close(context.Background()))
+
+	entries := s.recorded()
+	require.Len(t, entries, 2)
+
+	expired := entries[0]
+	assert.Equal(t, testSystemSessionID, expired.SessionID)
+	assert.Equal(t, AnonymousUserID, expired.UserID)
+	assert.Equal(t, string(domain.ActionAuthFailure), expired.Type)
+	assert.Equal(t, rejectedAt, expired.Timestamp)
+	assert.Equal(t, testNow, *expired.ReceivedAt)
+	assert.Equal(t, "203.0.113.7", expired.IPAddress)
+	assert.Equal(t, "curl/8.0", expired.UserAgent)
+	var details domain.AuthFailureDetails
+	require.NoError(t, json.Unmarshal(expired.Details, &details))
+	assert.Equal(t, domain.AuthFailureDetails{
+		Reason:    domain.AuthReasonExpiredToken,
+		Method:    http.MethodGet,
+		Route:     "/api/v1/sessions/:sessionId/history",
+		Status:    http.StatusUnauthorized,
+		RequestID: "req-1",
+	}, details)
+
+	// RBAC denials are recorded for the authenticated user
+	assert.Equal(t, "user-1", entries[1].UserID)
+	assert.JSONEq(t, `{"reason":"not_admin","method":"GET","route":"/api/v1/admin/events","status":403,"userId":"user-1","requestId":"req-2"}`,
+		string(entries[1].Details))
+}
+
+func TestRecorder_DropsFailedBatches(t *testing.T) {
+	s := &store{err: errors.New("storage unavailable")}
+	r := New(testSystemSessionID, s.record, clock.NewFakeClock(testNow), metrics.New(), zap.NewNop())
+	r.Start()
+
+	r.ReportAuthFailure(domain.AuthFailure{Status: http.StatusUnauthorized, Reason: domain.AuthReasonInvalidToken})
+	require.NoError(t, r.Close(context.Background()))
+	assert.Empty(t, s.recorded())
+}
+
+func TestRecorder_DropsFailuresWhenFull(t *testing.T) {
+	s := &store{}
+	// Not started, so nothing is taken off the queue
+	r := New(testSystemSessionID, s.record, clock.NewFakeClock(testNow), metrics.New(), zap.NewNop())
+
+	for i := 0; i < queueSize+10; i++ {
+		r.ReportAuthFailure(domain.AuthFailure{Status: http.StatusUnauthorized, Reason: domain.AuthReasonMissingToken})
+	}
+	assert.Len(t, r.failures, queueSize)
+
+	r.Start()
+	require.NoError(t, r.Close(context.Background()))
+	assert.Len(t, s.recorded(), queueSize)
+}
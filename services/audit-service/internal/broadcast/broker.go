@@ -0,0 +1,152 @@
+package broadcast
+
+import (
+	"sync"
+
+	"audit-service/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// defaultBufferSize is how many undelivered entries a subscription may hold before new ones are dropped
+const defaultBufferSize = 64
+
+// AllTopic carries every published entry, for consumers that watch all activity
+const AllTopic = "all"
+
+// SessionTopic returns the topic carrying every entry of a session
+func SessionTopic(sessionID string) string {
+	return "session:" + sessionID
+}
+
+// UserTopic returns the topic carrying every entry created by a user
+func UserTopic(userID string) string {
+	return "user:" + userID
+}
+
+// Broker fans out newly created audit entries to subscribers of session and user topics
+type Broker struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[*Subscription]struct{}
+	bufferSize  int
+	closed      bool
+	logger      *zap.Logger
+}
+
+// NewBroker creates a new broker instance
+func NewBroker(logger *zap.Logger) *Broker {
+	return &Broker{
+		subscribers: make(map[string]map[*Subscription]struct{}),
+		bufferSize:  defaultBufferSize,
+		logger:      logger,
+	}
+}
+
+// Subscription receives entries published on a single topic
+type Subscription struct {
+	topic  string
+	events chan domain.AuditEntry
+	broker *Broker
+	once   sync.Once
+}
+
+// Subscribe registers a new subscription for the given topic
+func (b *Broker) Subscribe(topic string) *Subscription {
+	return b.SubscribeBuffered(topic, b.bufferSize)
+}
+
+// SubscribeBuffered registers a subscription that holds up to size undelivered entries,
+// for consumers that must keep up with bursts on busy topics
+func (b *Broker) SubscribeBuffered(topic string, size int) *Subscription {
+	sub := &Subscription{
+		topic:  topic,
+		events: make(chan domain.AuditEntry, size),
+		broker: b,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// After shutdown subscribers get a closed channel so streams end immediately
+	if b.closed {
+		sub.once.Do(func() { close(sub.events) })
+		return sub
+	}
+
+	if _, exists := b.subscribers[topic]; !exists {
+		b.subscribers[topic] = make(map[*Subscription]struct{})
+	}
+	b.subscribers[topic][sub] = struct{}{}
+
+	return sub
+}
+
+// Publish delivers an entry to every subscriber of its session, user and the all topic without blocking
+func (b *Broker) Publish(entry domain.AuditEntry) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	b.deliver(SessionTopic(entry.SessionID), entry)
+	if entry.UserID != "" {
+		b.deliver(UserTopic(entry.UserID), entry)
+	}
+	b.deliver(AllTopic, entry)
+}
+
+// deliver sends an entry to the subscribers of one topic; callers must hold the read lock
+func (b *Broker) deliver(topic string, entry domain.AuditEntry) {
+	for sub := range b.subscribers[topic] {
+		select {
+		case sub.events <- entry:
+		default:
+			// A slow consumer must never hold up event creation
+			b.logger.Warn("dropping audit entry for slow subscriber",
+				zap.String("topic", topic),
+				zap.String("event_id", entry.ID),
+			)
+		}
+	}
+}
+
+// SubscriberCount returns the number of active subscriptions for a topic
+func (b *Broker) SubscriberCount(topic string) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return len(b.subscribers[topic])
+}
+
+// Close ends every subscription so open streams terminate during shutdown
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, subs := range b.subscribers {
+		for sub := range subs {
+			sub.once.Do(func() { close(sub.events) })
+		}
+	}
+	b.subscribers = make(map[string]map[*Subscription]struct{})
+	b.closed = true
+}
+
+// Events returns the channel on which published entries are delivered
+func (s *Subscription) Events() <-chan domain.AuditEntry {
+	return s.events
+}
+
+// Close unregisters the subscription and closes its channel
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		b := s.broker
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.subscribers[s.topic], s)
+		if len(b.subscribers[s.topic]) == 0 {
+			delete(b.subscribers, s.topic)
+		}
+		close(s.events)
+	})
+}
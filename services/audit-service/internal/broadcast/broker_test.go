@@ -0,0 +1,113 @@
+package broadcast
+
+import (
+	"testing"
+
+	"audit-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestBroker_PublishToSessionSubscribers(t *testing.T) {
+	broker := NewBroker(zap.NewNop())
+
+	first := broker.Subscribe(SessionTopic("session-1"))
+	second := broker.Subscribe(SessionTopic("session-1"))
+	other := broker.Subscribe(SessionTopic("session-2"))
+	defer first.Close()
+	defer second.Close()
+	defer other.Close()
+
+	entry := domain.AuditEntry{ID: "event-1", SessionID: "session-1", Type: "edit"}
+	broker.Publish(entry)
+
+	assert.Equal(t, entry, <-first.Events())
+	assert.Equal(t, entry, <-second.Events())
+	assert.Len(t, other.Events(), 0)
+}
+
+func TestBroker_PublishToUserSubscribers(t *testing.T) {
+	broker := NewBroker(zap.NewNop())
+
+	user := broker.Subscribe(UserTopic("user-1"))
+	session := broker.Subscribe(SessionTopic("session-1"))
+	defer user.Close()
+	defer session.Close()
+
+	broker.Publish(domain.AuditEntry{ID: "event-1", SessionID: "session-1", UserID: "user-1"})
+	broker.Publish(domain.AuditEntry{ID: "event-2", SessionID: "session-2", UserID: "user-1"})
+	broker.Publish(domain.AuditEntry{ID: "event-3", SessionID: "session-1", UserID: "user-2"})
+
+	assert.Equal(t, "event-1", (<-user.Events()).ID)
+	assert.Equal(t, "event-2", (<-user.Events()).ID)
+	assert.Len(t, user.Events(), 0)
+
+	assert.Equal(t, "event-1", (<-session.Events()).ID)
+	assert.Equal(t, "event-3", (<-session.Events()).ID)
+}
+
+func TestBroker_DropsWhenSubscriberIsFull(t *testing.T) {
+	broker := NewBroker(zap.NewNop())
+	broker.bufferSize = 1
+
+	sub := broker.Subscribe(SessionTopic("session-1"))
+	defer sub.Close()
+
+	broker.Publish(domain.AuditEntry{ID: "event-1", SessionID: "session-1"})
+	broker.Publish(domain.AuditEntry{ID: "event-2", SessionID: "session-1"})
+
+	assert.Equal(t, "event-1", (<-sub.Events()).ID)
+	assert.Len(t, sub.Events(), 0)
+}
+
+func TestSubscription_Close(t *testing.T) {
+	broker := NewBroker(zap.NewNop())
+
+	sub := broker.Subscribe(SessionTopic("session-1"))
+	assert.Equal(t, 1, broker.SubscriberCount(SessionTopic("session-1")))
+
+	sub.Close()
+	sub.Close() // closing twice is safe
+
+	assert.Equal(t, 0, broker.SubscriberCount(SessionTopic("session-1")))
+	_, open := <-sub.Events()
+	assert.False(t, open)
+
+	// Publishing after close must not panic
+	broker.Publish(domain.AuditEntry{ID: "event-1", SessionID: "session-1"})
+}
+
+func TestBroker_Close(t *testing.T) {
+	broker := NewBroker(zap.NewNop())
+
+	sub := broker.Subscribe(SessionTopic("session-1"))
+	broker.Close()
+
+	_, open := <-sub.Events()
+	assert.False(t, open)
+	assert.Equal(t, 0, broker.SubscriberCount(SessionTopic("session-1")))
+
+	// Closing the subscription afterwards is a no-op
+	sub.Close()
+
+	late := broker.Subscribe(SessionTopic("session-1"))
+	_, open = <-late.Events()
+	assert.False(t, open)
+	assert.Equal(t, 0, broker.SubscriberCount(SessionTopic("session-1")))
+}
+
+func TestBroker_PublishToAllTopic(t *testing.T) {
+	broker := NewBroker(zap.NewNop())
+
+	all := broker.SubscribeBuffered(AllTopic, 2)
+	defer all.Close()
+
+	broker.Publish(domain.AuditEntry{ID: "event-1", SessionID: "session-1", UserID: "user-1"})
+	broker.Publish(domain.AuditEntry{ID: "event-2", SessionID: "session-2", UserID: "user-2"})
+	broker.Publish(domain.AuditEntry{ID: "event-3", SessionID: "session-3"})
+
+	assert.Equal(t, "event-1", (<-all.Events()).ID)
+	assert.Equal(t, "event-2", (<-all.Events()).ID)
+	assert.Len(t, all.Events(), 0)
+}
@@ -0,0 +1,206 @@
+// Package bruteforce throttles the client IPs and users behind repeated rejected requests.
+// Each auth failure is counted per IP and per user; reaching the threshold within the window
+// locks the offender out for a period that doubles with every lockout of the day, and raises a
+// security_alert event.
+package bruteforce
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"audit-service/internal/anomaly"
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/internal/retention"
+	"audit-service/pkg/cache"
+	"audit-service/pkg/clock"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// strikeTTL is how long lockouts are remembered to lengthen the next one
+	strikeTTL = 24 * time.Hour
+
+	// writeTimeout bounds the write of a security alert
+	writeTimeout = 5 * time.Second
+)
+
+// Subjects of the counts
+const (
+	SubjectIP   = "ip"
+	SubjectUser = "user"
+)
+
+// RecordFunc persists the security_alert events
+type RecordFunc func(ctx context.Context, entries []domain.AuditEntry) error
+
+// Config holds the thresholds of the guard
+type Config struct {
+	// Threshold is the number of rejected requests within Window that locks a subject out
+	Threshold int
+	Window    time.Duration
+	// Lockout is the first lockout of a subject; each further lockout within a day doubles it, up to MaxLockout
+	Lockout    time.Duration
+	MaxLockout time.Duration
+}
+
+// Guard counts rejected requests and reports the subjects locked out. Counts live in the
+// counters, so Redis counters share them between replicas.
+type Guard struct {
+	counters  cache.Counters
+	sessionID string
+	record    RecordFunc
+	// cfg holds the thresholds, which can be changed while the guard is in use
+	cfg     atomic.Pointer[Config]
+	clock   clock.Clock
+	metrics *metrics.Metrics
+	logger  *zap.Logger
+}
+
+// New creates a guard raising its alerts in the system session; record may be nil when there is
+// none, in which case alerts are only logged
+func New(counters cache.Counters, sessionID string, record RecordFunc, cfg Config, clk clock.Clock, m *metrics.Metrics, logger *zap.Logger) *Guard {
+	g := &Guard{
+		counters:  counters,
+		sessionID: sessionID,
+		record:    record,
+		clock:     clk,
+		metrics:   m,
+		logger:    logger,
+	}
+	g.SetConfig(cfg)
+	return g
+}
+
+// SetConfig changes the thresholds; running lockouts keep their length
+func (g *Guard) SetConfig(cfg Config) {
+	g.cfg.Store(&cfg)
+}
+
+// Throttled returns how long the client IP or user stays locked out, zero when neither is.
+// Counters that cannot be read do not lock anyone out.
+func (g *Guard) Throttled(ctx context.Context, clientIP, userID string) time.Duration {
+	var remaining time.Duration
+	for _, subject := range subjects(clientIP, userID) {
+		_, ttl, err := g.counters.Get(ctx, "lock:"+subject)
+		if err != nil {
+			g.logger.Warn("failed to read brute-force lockout", zap.String("subject", subject), zap.Error(err))
+			continue
+		}
+		remaining = max(remaining, ttl)
+	}
+	return remaining
+}
+
+// ReportAuthFailure counts a rejected request against its client IP and user
+func (g *Guard) ReportAuthFailure(failure domain.AuthFailure) {
+	ctx := context.Background()
+	cfg := g.cfg.Load()
+	for _, subject := range subjects(failure.ClientIP, failure.UserID) {
+		n, err := g.counters.Incr(ctx, "fail:"+subject, cfg.Window)
+		if err != nil {
+			g.logger.Warn("failed to count rejected request", zap.String("subject", subject), zap.Error(err))
+			continue
+		}
+		// Only the failure reaching the threshold locks out, so concurrent ones do not lock twice
+		if n != int64(cfg.Threshold) {
+			continue
+		}
+		g.lockOut(ctx, cfg, subject, failure)
+	}
+}
+
+// lockOut locks the subject out and raises a security alert
+func (g *Guard) lockOut(ctx context.Context, cfg *Config, subject string, failure domain.AuthFailure) {
+	// The next window starts with the lockout
+	if err := g.counters.Delete(ctx, "fail:"+subject); err != nil {
+		g.logger.Warn("failed to reset rejected request count", zap.String("subject", subject), zap.Error(err))
+	}
+	strikes, err := g.counters.Incr(ctx, "strikes:"+subject, strikeTTL)
+	if err != nil {
+		g.logger.Warn("failed to count brute-force lockout", zap.String("subject", subject), zap.Error(err))
+		strikes = 1
+	}
+	lockout := cfg.lockoutFor(strikes)
+	if err := g.counters.Set(ctx, "lock:"+subject, strikes, lockout); err != nil {
+		g.logger.Error("failed to lock out subject", zap.String("subject", subject), zap.Error(err))
+		return
+	}
+
+	kind, value, _ := strings.Cut(subject, ":")
+	g.metrics.ObserveBruteForceLockout(kind)
+	g.logger.Warn("brute-force lockout",
+		zap.String("subject", subject),
+		zap.Int64("strikes", strikes),
+		zap.Duration("lockout", lockout),
+	)
+
+	severity := anomaly.SeverityMedium
+	if strikes > 1 {
+		severity = anomaly.SeverityHigh
+	}
+	alert := anomaly.Alert{
+		Rule:        anomaly.RuleBruteForce,
+		Severity:    severity,
+		UserID:      failure.UserID,
+		Description: fmt.Sprintf("%s %s locked out for %s after %d rejected requests", kind, value, lockout, cfg.Threshold),
+		Count:       cfg.Threshold,
+		Window:      cfg.Window.String(),
+		IPAddress:   failure.ClientIP,
+		Lockout:     lockout.String(),
+		EventIDs:    []string{},
+	}
+	g.raise(alert, failure)
+}
+
+// lockoutFor returns the lockout of the given strike, doubling the first one per earlier strike
+func (cfg *Config) lockoutFor(strikes int64) time.Duration {
+	lockout := cfg.Lockout
+	for i := int64(1); i < strikes && lockout < cfg.MaxLockout; i++ {
+		lockout *= 2
+	}
+	return min(lockout, cfg.MaxLockout)
+}
+
+// raise records the alert as a security_alert event of the system session. Failures are logged.
+func (g *Guard) raise(alert anomaly.Alert, failure domain.AuthFailure) {
+	g.metrics.ObserveAnomalyAlert(alert.Rule)
+	if g.record == nil {
+		return
+	}
+
+	details, _ := domain.EncodeDetails(alert)
+	now := g.clock.Now()
+	entry := domain.AuditEntry{
+		ID:         uuid.New().String(),
+		SessionID:  g.sessionID,
+		UserID:     retention.SystemUserID,
+		Type:       string(domain.ActionSecurityAlert),
+		Timestamp:  now,
+		Details:    details,
+		IPAddress:  failure.ClientIP,
+		ReceivedAt: &now,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	if err := g.record(ctx, []domain.AuditEntry{entry}); err != nil {
+		g.logger.Error("failed to record brute-force alert", zap.Error(err))
+	}
+}
+
+// subjects returns the counted subjects of a request: its client IP and, once authenticated, its user
+func subjects(clientIP, userID string) []string {
+	var out []string
+	if clientIP != "" {
+		out = append(out, SubjectIP+":"+clientIP)
+	}
+	if userID != "" {
+		out = append(out, SubjectUser+":"+userID)
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+package bruteforce
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"audit-service/internal/anomaly"
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/internal/retention"
+	"audit-service/pkg/cache"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testSystemSessionID = "550e8400-e29b-41d4-a716-446655440099"
+
+var testConfig = Config{Threshold: 3, Window: 5 * time.Minute, Lockout: time.Minute, MaxLockout: 3 * time.Minute}
+
+func newTestGuard(t *testing.T) (*Guard, *clock.FakeClock, *[]domain.AuditEntry) {
+	t.Helper()
+	clk := clock.NewFakeClock(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC))
+	var recorded []domain.AuditEntry
+	record := func(_ context.Context, entries []domain.AuditEntry) error {
+		recorded = append(recorded, entries...)
+		return nil
+	}
+	guard := New(cache.NewMemoryCounters(clk), testSystemSessionID, record, testConfig, clk, metrics.New(), zap.NewNop())
+	return guard, clk, &recorded
+}
+
+func failure(clientIP, userID string) domain.AuthFailure {
+	return domain.AuthFailure{Status: http.StatusUnauthorized, Reason: domain.AuthReasonInvalidToken, ClientIP: clientIP, UserID: userID}
+}
+
+func TestGuard_LocksOutAtThreshold(t *testing.T) {
+	ctx := context.Background()
+	guard, clk, recorded := newTestGuard(t)
+
+	guard.ReportAuthFailure(failure("203.0.113.7", ""))
+	guard.ReportAuthFailure(failure("203.0.113.7", ""))
+	assert.Zero(t, guard.Throttled(ctx, "203.0.113.7", ""))
+	assert.Empty(t, *recorded)
+
+	guard.ReportAuthFailure(failure("203.0.113.7", ""))
+	assert.Equal(t, time.Minute, guard.Throttled(ctx, "203.0.113.7", ""))
+	// Other clients are not throttled
+	assert.Zero(t, guard.Throttled(ctx, "198.51.100.1", ""))
+
+	require.Len(t, *recorded, 1)
+	entry := (*recorded)[0]
+	assert.Equal(t, testSystemSessionID, entry.SessionID)
+	assert.Equal(t, retention.SystemUserID, entry.UserID)
+	assert.Equal(t, string(domain.ActionSecurityAlert), entry.Type)
+	assert.Equal(t, "203.0.113.7", entry.IPAddress)
+	var alert anomaly.Alert
+	require.NoError(t, json.Unmarshal(entry.Details, &alert))
+	assert.Equal(t, anomaly.RuleBruteForce, alert.Rule)
+	assert.Equal(t, anomaly.SeverityMedium, alert.Severity)
+	assert.Equal(t, 3, alert.Count)
+	assert.Equal(t, "5m0s", alert.Window)
+	assert.Equal(t, "1m0s", alert.Lockout)
+
+	clk.Advance(time.Minute)
+	assert.Zero(t, guard.Throttled(ctx, "203.0.113.7", ""))
+}
+
+func TestGuard_LockoutGrowsWithStrikes(t *testing.T) {
+	ctx := context.Background()
+	guard, clk, recorded := newTestGuard(t)
+
+	expected := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute}
+	for _, lockout := range expected {
+		for i := 0; i < testConfig.Threshold; i++ {
+			guard.ReportAuthFailure(failure("203.0.113.7", ""))
+		}
+		assert.Equal(t, lockout, guard.Throttled(ctx, "203.0.113.7", ""))
+		clk.Advance(lockout)
+	}
+
+	require.Len(t, *recorded, len(expected))
+	var alert anomaly.Alert
+	require.NoError(t, json.Unmarshal((*recorded)[1].Details, &alert))
+	assert.Equal(t, anomaly.SeverityHigh, alert.Severity)
+}
+
+func TestGuard_SetConfig(t *testing.T) {
+	ctx := context.Background()
+	guard, _, _ := newTestGuard(t)
+
+	guard.SetConfig(Config{Threshold: 2, Window: 5 * time.Minute, Lockout: 10 * time.Minute, MaxLockout: time.Hour})
+	guard.ReportAuthFailure(failure("203.0.113.7", ""))
+	guard.ReportAuthFailure(failure("203.0.113.7", ""))
+
+	assert.Equal(t, 10*time.Minute, guard.Throttled(ctx, "203.0.113.7", ""))
+}
+
+func TestGuard_CountsUsersAcrossAddresses(t *testing.T) {
+	ctx := context.Background()
+	guard, _, _ := newTestGuard(t)
+
+	guard.ReportAuthFailure(failure("203.0.113.7", "user-1"))
+	guard.ReportAuthFailure(failure("203.0.113.8", "user-1"))
+	guard.ReportAuthFailure(failure("203.0.113.9", "user-1"))
+
+	assert.Zero(t, guard.Throttled(ctx, "203.0.113.7", ""))
+	assert.Equal(t, time.Minute, guard.Throttled(ctx, "198.51.100.1", "user-1"))
+}
+
+func TestGuard_WindowExpires(t *testing.T) {
+	ctx := context.Background()
+	guard, clk, _ := newTestGuard(t)
+
+	guard.ReportAuthFailure(failure("203.0.113.7", ""))
+	guard.ReportAuthFailure(failure("203.0.113.7", ""))
+	clk.Advance(testConfig.Window)
+	guard.ReportAuthFailure(failure("203.0.113.7", ""))
+
+	assert.Zero(t, guard.Throttled(ctx, "203.0.113.7", ""))
+}
+
+func TestGuard_LogsAlertsWithoutSystemSession(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFakeClock(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC))
+	guard := New(cache.NewMemoryCounters(clk), "", nil, testConfig, clk, metrics.New(), zap.NewNop())
+
+	for i := 0; i < testConfig.Threshold; i++ {
+		guard.ReportAuthFailure(failure("203.0.113.7", ""))
+	}
+	assert.Equal(t, time.Minute, guard.Throttled(ctx, "203.0.113.7", ""))
+}
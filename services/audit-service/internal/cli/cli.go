@@ -0,0 +1,132 @@
+// Package cli is the command line of the service binary. Without a subcommand it serves, as the
+// binary did before it had subcommands, so existing deployments start unchanged.
+package cli
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"audit-service/internal/auditctl"
+	"audit-service/internal/config"
+	"audit-service/internal/metrics"
+	"audit-service/internal/server"
+	"audit-service/pkg/clock"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// migrateTimeout bounds a run of the migrate command
+const migrateTimeout = 5 * time.Minute
+
+// Commands run by the command line; replaced in tests
+var (
+	serve       = runServe
+	migrate     = runMigrate
+	checkConfig = server.CheckConfig
+	runAuditctl = auditctl.Run
+)
+
+// Execute runs the command named by the arguments of the process and returns the exit status
+func Execute() int {
+	if err := NewRootCommand().Execute(); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// NewRootCommand creates the audit-service command with its serve, migrate and auditctl subcommands
+func NewRootCommand() *cobra.Command {
+	root := newServeCommand("audit-service")
+	root.Short = "Audit log service of the PowerPoint translation platform"
+	root.SilenceUsage = true
+	root.AddCommand(newServeCommand("serve"), newMigrateCommand(), newAuditctlCommand())
+	return root
+}
+
+// newServeCommand creates a command that runs the service, or checks its configuration with
+// --check-config
+func newServeCommand(use string) *cobra.Command {
+	var check bool
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: "Run the service",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if check {
+				os.Exit(checkConfig())
+			}
+			serve()
+		},
+	}
+	cmd.Flags().BoolVar(&check, "check-config", false, "validate the configuration and test its connections, print a JSON report and exit")
+	return cmd
+}
+
+// newMigrateCommand creates the command that creates or updates the storage schema
+func newMigrateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate",
+		Short: "Create or update the schema of the storage backend",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			migrate()
+		},
+	}
+}
+
+// newAuditctlCommand creates the command that administers a running instance. Its arguments are
+// passed on untouched, since auditctl parses its own flags.
+func newAuditctlCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:                "auditctl [-url URL] <command> [flags]",
+		Short:              "Administer a running instance through its admin API",
+		DisableFlagParsing: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			runAuditctl(args)
+		},
+	}
+}
+
+// runServe wires the storage, background workers and listeners of the service and serves until
+// it receives SIGINT or SIGTERM
+func runServe() {
+	cfg, logLevel, logger := server.Load()
+	defer logger.Sync()
+
+	server.New(cfg, logLevel, logger).Run()
+}
+
+// runMigrate creates or updates the schema of the storage backend
+func runMigrate() {
+	cfg, _, logger := server.Load()
+	defer logger.Sync()
+
+	if err := migrateStorage(cfg, logger); err != nil {
+		logger.Fatal("migration failed", zap.String("backend", cfg.StorageBackend), zap.Error(err))
+	}
+}
+
+// migrateStorage applies the pending schema migrations of the configured storage backend
+func migrateStorage(cfg *config.Config, logger *zap.Logger) error {
+	ctx, cancel := context.WithTimeout(context.Background(), migrateTimeout)
+	defer cancel()
+
+	store, err := server.OpenStorage(ctx, cfg, metrics.New(), clock.New(), logger)
+	if err != nil {
+		return err
+	}
+	defer store.Close(ctx)
+
+	applied, err := store.Migrate(ctx)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("storage schema is up to date",
+		zap.String("backend", cfg.StorageBackend),
+		zap.Strings("applied", applied),
+	)
+	return nil
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"io"
+	"testing"
+
+	"audit-service/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// stubCommands records which command ran instead of running it
+func stubCommands(t *testing.T) *[]string {
+	t.Helper()
+	var ran []string
+	savedServe, savedMigrate, savedAuditctl := serve, migrate, runAuditctl
+	serve = func() { ran = append(ran, "serve") }
+	migrate = func() { ran = append(ran, "migrate") }
+	runAuditctl = func(args []string) { ran = append(ran, append([]string{"auditctl"}, args...)...) }
+	t.Cleanup(func() {
+		serve, migrate, runAuditctl = savedServe, savedMigrate, savedAuditctl
+	})
+	return &ran
+}
+
+func TestRootCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		// Deployments that start the binary without arguments keep serving
+		{name: "no subcommand serves", args: nil, want: []string{"serve"}},
+		{name: "serve", args: []string{"serve"}, want: []string{"serve"}},
+		{name: "migrate", args: []string{"migrate"}, want: []string{"migrate"}},
+		{name: "auditctl flags are passed on", args: []string{"auditctl", "-url", "http://audit:4006", "verify", "-session", "s-1"},
+			want: []string{"auditctl", "-url", "http://audit:4006", "verify", "-session", "s-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran := stubCommands(t)
+			root := NewRootCommand()
+			root.SetArgs(tt.args)
+			require.NoError(t, root.Execute())
+			assert.Equal(t, tt.want, *ran)
+		})
+	}
+}
+
+func TestRootCommand_RejectsUnknownArguments(t *testing.T) {
+	ran := stubCommands(t)
+	root := NewRootCommand()
+	root.SetArgs([]string{"migrate", "now"})
+	root.SetErr(io.Discard)
+	assert.Error(t, root.Execute())
+	assert.Empty(t, *ran)
+}
+
+func TestRootCommand_CheckConfigFlag(t *testing.T) {
+	root := NewRootCommand()
+	assert.NotNil(t, root.Flags().Lookup("check-config"))
+
+	serveCmd, _, err := root.Find([]string{"serve"})
+	require.NoError(t, err)
+	assert.NotNil(t, serveCmd.Flags().Lookup("check-config"))
+}
+
+func TestMigrateStorage(t *testing.T) {
+	assert.NoError(t, migrateStorage(&config.Config{StorageBackend: "memory"}, zap.NewNop()))
+	assert.Error(t, migrateStorage(&config.Config{StorageBackend: "mysql"}, zap.NewNop()))
+}
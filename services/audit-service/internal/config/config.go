@@ -1,28 +1,125 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"audit-service/internal/domain"
+	"audit-service/internal/redact"
+	"audit-service/pkg/apikey"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/fieldcrypt"
+	"audit-service/pkg/jwt"
+	"audit-service/pkg/secrets"
+	"audit-service/pkg/sigv4"
+
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 )
 
+// Keys of the credentials in the secret read from the secrets manager
+const (
+	SecretServiceRoleKey = "SUPABASE_SERVICE_ROLE_KEY"
+	SecretJWTSecret      = "SUPABASE_JWT_SECRET"
+)
+
+// Service roles, selecting what a deployment serves so reads and writes can scale apart
+const (
+	// RoleAll serves ingestion and the history, with every background worker
+	RoleAll = "all"
+	// RoleWriter serves ingestion and live streams and runs the write side workers
+	RoleWriter = "writer"
+	// RoleReader serves the history queries, stats, verification and exports
+	RoleReader = "reader"
+)
+
 // Config holds all configuration for the audit service
 type Config struct {
 	// Server configuration
-	Port       string `mapstructure:"PORT"`
-	LogLevel   string `mapstructure:"LOG_LEVEL"`
-	CORSOrigin string `mapstructure:"CORS_ORIGIN"`
+	Port            string        `mapstructure:"PORT"`
+	LogLevel        string        `mapstructure:"LOG_LEVEL"`
+	CORSOrigin      string        `mapstructure:"CORS_ORIGIN"`
+	ShutdownTimeout time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	TrustedProxies  []netip.Prefix
+	// ForwardedHeader is the one forwarding header of the trusted proxies client IPs are read
+	// from: "x-forwarded-for" or "forwarded" (RFC 7239). The other is ignored, so clients cannot
+	// pass a spoofed one through proxies that only append to the first.
+	ForwardedHeader string `mapstructure:"FORWARDED_HEADER"`
+	// DiagnosticsAddr is the listen address of the pprof and runtime stats server; empty disables it
+	DiagnosticsAddr string `mapstructure:"DIAGNOSTICS_ADDR"`
+	// InternalAddr is the listen address of the listener serving the admin routes, service
+	// callbacks, metrics and profiles to other pptxTrans services; empty serves them on Port.
+	// With the TLS files set, clients must present a certificate signed by the CA bundle in
+	// InternalTLSCAFile carrying one of InternalTLSAllowedSANs, any SAN when the list is empty.
+	InternalAddr           string `mapstructure:"INTERNAL_ADDR"`
+	InternalTLSCertFile    string `mapstructure:"INTERNAL_TLS_CERT_FILE"`
+	InternalTLSKeyFile     string `mapstructure:"INTERNAL_TLS_KEY_FILE"`
+	InternalTLSCAFile      string `mapstructure:"INTERNAL_TLS_CA_FILE"`
+	InternalTLSAllowedSANs []string
+	// ServiceRole selects the routes and background workers of this deployment: all, writer or reader
+	ServiceRole string `mapstructure:"SERVICE_ROLE"`
+
+	// Access log configuration: share of successful requests logged, overall and per route
+	AccessLogSampleRate    float64 `mapstructure:"ACCESS_LOG_SAMPLE_RATE"`
+	AccessLogRouteSampling map[string]float64
 
 	// Supabase configuration
 	SupabaseURL            string `mapstructure:"SUPABASE_URL"`
-	SupabaseAnonKey        string `mapstructure:"SUPABASE_ANON_KEY"`
-	SupabaseServiceRoleKey string `mapstructure:"SUPABASE_SERVICE_ROLE_KEY"`
-	SupabaseJWTSecret      string `mapstructure:"SUPABASE_JWT_SECRET"`
+	SupabaseAnonKey        string `mapstructure:"SUPABASE_ANON_KEY" secret:"true"`
+	SupabaseServiceRoleKey string `mapstructure:"SUPABASE_SERVICE_ROLE_KEY" secret:"true"`
+	SupabaseJWTSecret      string `mapstructure:"SUPABASE_JWT_SECRET" secret:"true"`
+
+	// JWT verification: JWKS of the asymmetric signing keys, the HMAC secret replaced by the
+	// last rotation, and the iss and aud claims tokens must carry
+	SupabaseJWTPreviousSecret   string        `mapstructure:"SUPABASE_JWT_PREVIOUS_SECRET" secret:"true"`
+	SupabaseJWKSURL             string        `mapstructure:"SUPABASE_JWKS_URL"`
+	SupabaseJWKSRefreshInterval time.Duration `mapstructure:"SUPABASE_JWKS_REFRESH_INTERVAL"`
+	SupabaseJWTIssuer           string        `mapstructure:"SUPABASE_JWT_ISSUER"`
+	SupabaseJWTAudience         string        `mapstructure:"SUPABASE_JWT_AUDIENCE"`
+
+	// Secrets manager: SUPABASE_SERVICE_ROLE_KEY and SUPABASE_JWT_SECRET are read from the secret
+	// at SecretsPath of SecretsProvider (vault or aws), overriding the environment, and read again
+	// every SecretsRefreshInterval to pick up rotations; zero reads them only at startup
+	SecretsProvider        string        `mapstructure:"SECRETS_PROVIDER"`
+	SecretsPath            string        `mapstructure:"SECRETS_PATH"`
+	SecretsRefreshInterval time.Duration `mapstructure:"SECRETS_REFRESH_INTERVAL"`
+	VaultAddr              string        `mapstructure:"VAULT_ADDR"`
+	VaultToken             string        `mapstructure:"VAULT_TOKEN" secret:"true"`
+
+	SecretsAWSRegion          string `mapstructure:"SECRETS_AWS_REGION"`
+	SecretsAWSEndpoint        string `mapstructure:"SECRETS_AWS_ENDPOINT"`
+	SecretsAWSAccessKeyID     string `mapstructure:"SECRETS_AWS_ACCESS_KEY_ID"`
+	SecretsAWSSecretAccessKey string `mapstructure:"SECRETS_AWS_SECRET_ACCESS_KEY" secret:"true"`
+	SecretsAWSSessionToken    string `mapstructure:"SECRETS_AWS_SESSION_TOKEN" secret:"true"`
+	// ManagedSecrets holds the secret as last read from the secrets manager
+	ManagedSecrets map[string]string `secret:"true"`
+
+	// Supabase resilience configuration
+	SupabaseBreakerFailureThreshold int           `mapstructure:"SUPABASE_BREAKER_FAILURE_THRESHOLD"`
+	SupabaseBreakerOpenTimeout      time.Duration `mapstructure:"SUPABASE_BREAKER_OPEN_TIMEOUT"`
+	SupabaseBreakerHalfOpenRequests int           `mapstructure:"SUPABASE_BREAKER_HALF_OPEN_REQUESTS"`
+	SupabaseRetryMaxAttempts        int           `mapstructure:"SUPABASE_RETRY_MAX_ATTEMPTS"`
+	SupabaseRetryBaseDelay          time.Duration `mapstructure:"SUPABASE_RETRY_BASE_DELAY"`
+	SupabaseRetryMaxDelay           time.Duration `mapstructure:"SUPABASE_RETRY_MAX_DELAY"`
+
+	// Storage configuration
+	StorageBackend string `mapstructure:"STORAGE_BACKEND"`
+	DatabaseURL    string `mapstructure:"DATABASE_URL" secret:"url"`
+	SQLitePath     string `mapstructure:"SQLITE_PATH"`
+
+	// Secret the share service signs share-link tokens with
+	ShareTokenSecret string `mapstructure:"SHARE_TOKEN_SECRET" secret:"true"`
 
 	// HTTP Client configuration
 	HTTPTimeout         time.Duration `mapstructure:"HTTP_TIMEOUT"`
@@ -34,14 +131,213 @@ type Config struct {
 	CacheJWTTTL          time.Duration `mapstructure:"CACHE_JWT_TTL"`
 	CacheShareTokenTTL   time.Duration `mapstructure:"CACHE_SHARE_TOKEN_TTL"`
 	CacheCleanupInterval time.Duration `mapstructure:"CACHE_CLEANUP_INTERVAL"`
+	IdempotencyTTL       time.Duration `mapstructure:"IDEMPOTENCY_TTL"`
+	CacheBackend         string        `mapstructure:"CACHE_BACKEND"`
+	RedisURL             string        `mapstructure:"REDIS_URL" secret:"url"`
+	CacheRedisPrefix     string        `mapstructure:"CACHE_REDIS_PREFIX"`
+	CacheRedisTimeout    time.Duration `mapstructure:"CACHE_REDIS_TIMEOUT"`
+
+	// Session owners looked up for access checks are kept in an in-memory LRU cache of
+	// SessionCacheSize entries for SessionCacheTTL; a size of zero disables the cache
+	SessionCacheSize int           `mapstructure:"SESSION_CACHE_SIZE"`
+	SessionCacheTTL  time.Duration `mapstructure:"SESSION_CACHE_TTL"`
+
+	// History pages and stats are kept in an in-memory LRU cache of QueryCacheSize entries for
+	// QueryCacheTTL, dropped when events of their session are written; zero disables the cache
+	QueryCacheSize int           `mapstructure:"QUERY_CACHE_SIZE"`
+	QueryCacheTTL  time.Duration `mapstructure:"QUERY_CACHE_TTL"`
+
+	// History reads sent with a consistency token wait this long for the events of the token to
+	// become readable, such as while they are in the write buffer
+	ConsistencyWait time.Duration `mapstructure:"CONSISTENCY_WAIT"`
+
+	// Token revocations are loaded from the database into the token cache this often
+	RevocationRefreshInterval time.Duration `mapstructure:"REVOCATION_REFRESH_INTERVAL"`
 
 	// Application configuration
 	MaxPageSize     int `mapstructure:"MAX_PAGE_SIZE"`
 	DefaultPageSize int `mapstructure:"DEFAULT_PAGE_SIZE"`
+	ExportMaxRows   int `mapstructure:"EXPORT_MAX_ROWS"`
+
+	// Read side tuning: entries fetched per storage request during exports, and the exports and
+	// chain verifications running at once (0 is unlimited)
+	ExportPageSize      int `mapstructure:"EXPORT_PAGE_SIZE"`
+	ExportMaxConcurrent int `mapstructure:"EXPORT_MAX_CONCURRENT"`
+
+	// Export worker callbacks, signed with ExportCallbackSecret at most ExportCallbackTolerance
+	// away from server time; an empty secret disables them
+	ExportCallbackSecret    string        `mapstructure:"EXPORT_CALLBACK_SECRET" secret:"true"`
+	ExportCallbackTolerance time.Duration `mapstructure:"EXPORT_CALLBACK_TOLERANCE"`
+
+	// Write side tuning: storage write attempts and the base delay between them
+	WriteRetryAttempts int           `mapstructure:"WRITE_RETRY_ATTEMPTS"`
+	WriteRetryBackoff  time.Duration `mapstructure:"WRITE_RETRY_BACKOFF"`
+
+	// Client timestamps more than TimestampMaxFutureSkew ahead of or TimestampMaxPastSkew (0 is
+	// unlimited) behind server time are rejected, or moved into that window with
+	// TimestampSkewMode clamp
+	TimestampMaxFutureSkew time.Duration `mapstructure:"TIMESTAMP_MAX_FUTURE_SKEW"`
+	TimestampMaxPastSkew   time.Duration `mapstructure:"TIMESTAMP_MAX_PAST_SKEW"`
+	TimestampSkewMode      string        `mapstructure:"TIMESTAMP_SKEW_MODE"`
+
+	// CompressionMinSize is the smallest response body compressed for clients accepting gzip or deflate
+	CompressionMinSize int `mapstructure:"COMPRESSION_MIN_SIZE"`
+
+	// Request bodies are limited to MaxBodySize bytes, or the limit of their route in
+	// MaxBodySizeRoutes, and event details to MaxDetailsSize bytes; 0 is unlimited
+	MaxBodySize       int `mapstructure:"MAX_BODY_SIZE"`
+	MaxBodySizeRoutes map[string]int
+	MaxDetailsSize    int `mapstructure:"MAX_DETAILS_SIZE"`
+
+	// Daily event quotas per session and per user, reset at midnight UTC; 0 is unlimited
+	QuotaSessionDaily int `mapstructure:"QUOTA_SESSION_DAILY"`
+	QuotaUserDaily    int `mapstructure:"QUOTA_USER_DAILY"`
+
+	// Repeated identical views by a user of a session within ViewDedupWindow are stored as one view
+	// event with a counter; 0 stores every view. At most ViewDedupMaxHeld views are held at once.
+	ViewDedupWindow  time.Duration `mapstructure:"VIEW_DEDUP_WINDOW"`
+	ViewDedupMaxHeld int           `mapstructure:"VIEW_DEDUP_MAX_HELD"`
+
+	// Classifications of event types as type=category:severity, overriding the default taxonomy
+	EventTaxonomy string `mapstructure:"EVENT_TAXONOMY"`
+
+	// Write buffer configuration
+	WriteBufferEnabled       bool          `mapstructure:"WRITE_BUFFER_ENABLED"`
+	WriteBufferCapacity      int           `mapstructure:"WRITE_BUFFER_CAPACITY"`
+	WriteBufferBatchSize     int           `mapstructure:"WRITE_BUFFER_BATCH_SIZE"`
+	WriteBufferFlushInterval time.Duration `mapstructure:"WRITE_BUFFER_FLUSH_INTERVAL"`
+	WriteBufferOverflow      string        `mapstructure:"WRITE_BUFFER_OVERFLOW"`
+
+	// Retention configuration
+	RetentionPolicies  map[string]time.Duration
+	RetentionInterval  time.Duration `mapstructure:"RETENTION_INTERVAL"`
+	RetentionBatchSize int           `mapstructure:"RETENTION_BATCH_SIZE"`
+
+	// Activity rollup configuration
+	RollupEnabled  bool          `mapstructure:"ROLLUP_ENABLED"`
+	RollupInterval time.Duration `mapstructure:"ROLLUP_INTERVAL"`
+	RollupLookback time.Duration `mapstructure:"ROLLUP_LOOKBACK"`
+
+	// Archive configuration
+	ArchiveEnabled           bool   `mapstructure:"ARCHIVE_ENABLED"`
+	ArchivePrefix            string `mapstructure:"ARCHIVE_PREFIX"`
+	ArchiveS3Endpoint        string `mapstructure:"ARCHIVE_S3_ENDPOINT"`
+	ArchiveS3Region          string `mapstructure:"ARCHIVE_S3_REGION"`
+	ArchiveS3Bucket          string `mapstructure:"ARCHIVE_S3_BUCKET"`
+	ArchiveS3AccessKeyID     string `mapstructure:"ARCHIVE_S3_ACCESS_KEY_ID"`
+	ArchiveS3SecretAccessKey string `mapstructure:"ARCHIVE_S3_SECRET_ACCESS_KEY" secret:"true"`
+	ArchiveS3PathStyle       bool   `mapstructure:"ARCHIVE_S3_PATH_STYLE"`
+
+	// Outbox configuration
+	OutboxWebhookURLs   []string
+	OutboxWebhookSecret string        `mapstructure:"OUTBOX_WEBHOOK_SECRET" secret:"true"`
+	OutboxPollInterval  time.Duration `mapstructure:"OUTBOX_POLL_INTERVAL"`
+	OutboxBatchSize     int           `mapstructure:"OUTBOX_BATCH_SIZE"`
+	OutboxMaxAttempts   int           `mapstructure:"OUTBOX_MAX_ATTEMPTS"`
+	// WebhookFormat is the body of outbox and anomaly webhook deliveries: native or ocsf
+	WebhookFormat string `mapstructure:"WEBHOOK_FORMAT"`
+
+	// Anomaly detection configuration
+	AnomalyDetectionEnabled  bool          `mapstructure:"ANOMALY_DETECTION_ENABLED"`
+	AnomalyWindow            time.Duration `mapstructure:"ANOMALY_WINDOW"`
+	AnomalyDeletionThreshold int           `mapstructure:"ANOMALY_DELETION_THRESHOLD"`
+	AnomalyExportThreshold   int           `mapstructure:"ANOMALY_EXPORT_THRESHOLD"`
+	AnomalyWebhookURL        string        `mapstructure:"ANOMALY_WEBHOOK_URL"`
+	AnomalyWebhookSecret     string        `mapstructure:"ANOMALY_WEBHOOK_SECRET" secret:"true"`
+	// Working hours as offsets from local midnight, parsed from ANOMALY_WORKING_HOURS
+	AnomalyWorkdayStart time.Duration
+	AnomalyWorkdayEnd   time.Duration
+	AnomalyTimezone     *time.Location
+
+	// Watch notification configuration; NotifySink is webhook or supabase, empty disables them
+	NotifySink            string        `mapstructure:"NOTIFY_SINK"`
+	NotifyWebhookURL      string        `mapstructure:"NOTIFY_WEBHOOK_URL"`
+	NotifyWebhookSecret   string        `mapstructure:"NOTIFY_WEBHOOK_SECRET" secret:"true"`
+	NotifyTable           string        `mapstructure:"NOTIFY_TABLE"`
+	NotifyDigestInterval  time.Duration `mapstructure:"NOTIFY_DIGEST_INTERVAL"`
+	NotifyDigestMaxEvents int           `mapstructure:"NOTIFY_DIGEST_MAX_EVENTS"`
+
+	// SIEM export configuration; an empty SIEMSyslogAddress disables the export
+	SIEMSyslogAddress string `mapstructure:"SIEM_SYSLOG_ADDRESS"`
+	SIEMSyslogNetwork string `mapstructure:"SIEM_SYSLOG_NETWORK"`
+	SIEMFormat        string `mapstructure:"SIEM_FORMAT"`
+
+	// Brute-force throttling: clients and users rejected BruteForceThreshold times within
+	// BruteForceWindow are throttled for BruteForceLockout, doubled on every repeat up to
+	// BruteForceMaxLockout
+	BruteForceEnabled    bool          `mapstructure:"BRUTE_FORCE_ENABLED"`
+	BruteForceThreshold  int           `mapstructure:"BRUTE_FORCE_THRESHOLD"`
+	BruteForceWindow     time.Duration `mapstructure:"BRUTE_FORCE_WINDOW"`
+	BruteForceLockout    time.Duration `mapstructure:"BRUTE_FORCE_LOCKOUT"`
+	BruteForceMaxLockout time.Duration `mapstructure:"BRUTE_FORCE_MAX_LOCKOUT"`
+
+	// Scheduled report configuration; reports are uploaded to ReportsStorageBucket when the
+	// Supabase URL and service role key are set
+	ReportsEnabled       bool          `mapstructure:"REPORTS_ENABLED"`
+	ReportsPollInterval  time.Duration `mapstructure:"REPORTS_POLL_INTERVAL"`
+	ReportsMaxEvents     int           `mapstructure:"REPORTS_MAX_EVENTS"`
+	ReportsWebhookSecret string        `mapstructure:"REPORTS_WEBHOOK_SECRET" secret:"true"`
+	ReportsStorageBucket string        `mapstructure:"REPORTS_STORAGE_BUCKET"`
+
+	// Historical imports; uploaded files are kept in ImportTempDir, or the system temporary
+	// directory, while they are imported
+	ImportMaxFileSizeMB int    `mapstructure:"IMPORT_MAX_FILE_SIZE_MB"`
+	ImportTempDir       string `mapstructure:"IMPORT_TEMP_DIR"`
+
+	// Event replays to outbox webhooks; each replay is throttled to at most ReplayMaxRate events
+	// per second and may cover at most ReplayMaxEvents events
+	ReplayMaxRate   int `mapstructure:"REPLAY_MAX_RATE"`
+	ReplayMaxEvents int `mapstructure:"REPLAY_MAX_EVENTS"`
+
+	// Supabase Realtime consumer configuration
+	RealtimeEnabled     bool          `mapstructure:"REALTIME_ENABLED"`
+	RealtimeMatchWindow time.Duration `mapstructure:"REALTIME_MATCH_WINDOW"`
+
+	// EventTypesRefreshInterval is how often custom event types registered through other
+	// instances are loaded; 0 only loads them at startup
+	EventTypesRefreshInterval time.Duration `mapstructure:"EVENT_TYPES_REFRESH_INTERVAL"`
+
+	// Admin configuration
+	AdminUserIDs []string
+
+	// SystemSessionID is the existing session that records events about the service itself,
+	// such as configuration changes; empty only logs them
+	SystemSessionID string `mapstructure:"SYSTEM_SESSION_ID"`
+
+	// Service-to-service API keys
+	APIKeys []apikey.Key `secret:"true"`
+
+	// Details encryption configuration; no event types disables encryption
+	DetailsEncryptionTypes    []string
+	DetailsEncryptionProvider string `mapstructure:"DETAILS_ENCRYPTION_PROVIDER"`
+	// Key encryption keys parsed from DETAILS_ENCRYPTION_KEYS; the first one wraps new data keys
+	DetailsEncryptionKeys               []fieldcrypt.Key `secret:"true"`
+	DetailsEncryptionKMSKeyID           string           `mapstructure:"DETAILS_ENCRYPTION_KMS_KEY_ID"`
+	DetailsEncryptionKMSRegion          string           `mapstructure:"DETAILS_ENCRYPTION_KMS_REGION"`
+	DetailsEncryptionKMSEndpoint        string           `mapstructure:"DETAILS_ENCRYPTION_KMS_ENDPOINT"`
+	DetailsEncryptionKMSAccessKeyID     string           `mapstructure:"DETAILS_ENCRYPTION_KMS_ACCESS_KEY_ID"`
+	DetailsEncryptionKMSSecretAccessKey string           `mapstructure:"DETAILS_ENCRYPTION_KMS_SECRET_ACCESS_KEY" secret:"true"`
+	DetailsEncryptionDataKeyTTL         time.Duration    `mapstructure:"DETAILS_ENCRYPTION_DATA_KEY_TTL"`
+
+	// Redaction rules parsed from REDACTION_RULES and REDACTION_PATTERNS; none disables redaction
+	RedactionRules []redact.Rule
 }
 
-// Load reads configuration from environment variables
+// Load reads configuration from environment variables and validates it
 func Load() (*Config, error) {
+	cfg, err := Read()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+	return cfg, nil
+}
+
+// Read reads configuration from environment variables without validating it, so the
+// configuration self-check can report an invalid configuration
+func Read() (*Config, error) {
 	// First try to load from .env file using godotenv
 	// Try multiple possible locations for the .env file
 	possiblePaths := []string{
@@ -55,6 +351,9 @@ func Load() (*Config, error) {
 	cwd, _ := os.Getwd()
 	log.Printf("Current working directory: %s", cwd)
 
+	// Variables of the process environment take precedence over the .env file, also on reload
+	snapshotProcessEnv()
+
 	// Try each possible path
 	loaded := false
 	for _, path := range possiblePaths {
@@ -62,6 +361,7 @@ func Load() (*Config, error) {
 			log.Printf("Found .env file at: %s", path)
 			err := godotenv.Load(path)
 			if err == nil {
+				rememberEnvFile(path)
 				loaded = true
 				log.Printf("Successfully loaded environment from: %s", path)
 				break
@@ -89,7 +389,12 @@ func Load() (*Config, error) {
 	// Set default values
 	viper.SetDefault("PORT", "4006")
 	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("ACCESS_LOG_SAMPLE_RATE", 1)
 	viper.SetDefault("CORS_ORIGIN", "http://localhost:3000")
+	viper.SetDefault("SHUTDOWN_TIMEOUT", "30s")
+	viper.SetDefault("FORWARDED_HEADER", "x-forwarded-for")
+	viper.SetDefault("STORAGE_BACKEND", "supabase")
+	viper.SetDefault("SQLITE_PATH", "audit.db")
 
 	// HTTP defaults
 	viper.SetDefault("HTTP_TIMEOUT", "30s")
@@ -101,18 +406,140 @@ func Load() (*Config, error) {
 	viper.SetDefault("CACHE_JWT_TTL", "5m")
 	viper.SetDefault("CACHE_SHARE_TOKEN_TTL", "1m")
 	viper.SetDefault("CACHE_CLEANUP_INTERVAL", "10m")
+	viper.SetDefault("IDEMPOTENCY_TTL", "1h")
+	viper.SetDefault("CACHE_BACKEND", "memory")
+	viper.SetDefault("CACHE_REDIS_PREFIX", "audit-service:token:")
+	viper.SetDefault("REVOCATION_REFRESH_INTERVAL", "30s")
+	viper.SetDefault("CACHE_REDIS_TIMEOUT", "250ms")
+	viper.SetDefault("SESSION_CACHE_SIZE", 10000)
+	viper.SetDefault("SESSION_CACHE_TTL", "30s")
+	viper.SetDefault("QUERY_CACHE_SIZE", 1000)
+	viper.SetDefault("QUERY_CACHE_TTL", "5s")
+	viper.SetDefault("CONSISTENCY_WAIT", "5s")
+
+	// Supabase resilience defaults
+	viper.SetDefault("SUPABASE_BREAKER_FAILURE_THRESHOLD", 5)
+	viper.SetDefault("SUPABASE_BREAKER_OPEN_TIMEOUT", "30s")
+	viper.SetDefault("SUPABASE_BREAKER_HALF_OPEN_REQUESTS", 1)
+	viper.SetDefault("SUPABASE_RETRY_MAX_ATTEMPTS", 3)
+	viper.SetDefault("SUPABASE_RETRY_BASE_DELAY", "100ms")
+	viper.SetDefault("SUPABASE_RETRY_MAX_DELAY", "2s")
 
 	// Pagination defaults
 	viper.SetDefault("MAX_PAGE_SIZE", 100)
 	viper.SetDefault("DEFAULT_PAGE_SIZE", 50)
+	viper.SetDefault("EXPORT_MAX_ROWS", 50000)
+	viper.SetDefault("EXPORT_PAGE_SIZE", 500)
+	viper.SetDefault("EXPORT_MAX_CONCURRENT", 4)
+	viper.SetDefault("EXPORT_CALLBACK_TOLERANCE", "5m")
+	viper.SetDefault("COMPRESSION_MIN_SIZE", 1024)
+	viper.SetDefault("MAX_BODY_SIZE", 1<<20)
+	viper.SetDefault("MAX_DETAILS_SIZE", 64<<10)
+	viper.SetDefault("QUOTA_SESSION_DAILY", 0)
+	viper.SetDefault("QUOTA_USER_DAILY", 0)
+	viper.SetDefault("EVENT_TAXONOMY", "")
+	viper.SetDefault("VIEW_DEDUP_WINDOW", "0s")
+	viper.SetDefault("VIEW_DEDUP_MAX_HELD", 10000)
+
+	// Write side defaults
+	viper.SetDefault("SERVICE_ROLE", "all")
+	viper.SetDefault("WRITE_RETRY_ATTEMPTS", 3)
+	viper.SetDefault("WRITE_RETRY_BACKOFF", "100ms")
+	viper.SetDefault("TIMESTAMP_MAX_FUTURE_SKEW", "5m")
+	viper.SetDefault("TIMESTAMP_MAX_PAST_SKEW", "0s")
+	viper.SetDefault("TIMESTAMP_SKEW_MODE", "reject")
+
+	// Write buffer defaults
+	viper.SetDefault("WRITE_BUFFER_ENABLED", true)
+	viper.SetDefault("WRITE_BUFFER_CAPACITY", 10000)
+	viper.SetDefault("WRITE_BUFFER_BATCH_SIZE", 100)
+	viper.SetDefault("WRITE_BUFFER_FLUSH_INTERVAL", "1s")
+	viper.SetDefault("WRITE_BUFFER_OVERFLOW", "reject")
+
+	// Retention defaults
+	viper.SetDefault("RETENTION_INTERVAL", "1h")
+	viper.SetDefault("RETENTION_BATCH_SIZE", 1000)
+
+	// Activity rollup defaults
+	viper.SetDefault("ROLLUP_ENABLED", true)
+	viper.SetDefault("ROLLUP_INTERVAL", "15m")
+	viper.SetDefault("ROLLUP_LOOKBACK", "48h")
+
+	// Archive defaults
+	viper.SetDefault("ARCHIVE_ENABLED", false)
+	viper.SetDefault("ARCHIVE_PREFIX", "audit-logs")
+	viper.SetDefault("ARCHIVE_S3_REGION", "us-east-1")
+	viper.SetDefault("ARCHIVE_S3_PATH_STYLE", true)
+
+	// Outbox defaults
+	viper.SetDefault("OUTBOX_POLL_INTERVAL", "1s")
+	viper.SetDefault("OUTBOX_BATCH_SIZE", 100)
+	viper.SetDefault("OUTBOX_MAX_ATTEMPTS", 12)
+	viper.SetDefault("WEBHOOK_FORMAT", "native")
+
+	// Anomaly detection defaults
+	viper.SetDefault("ANOMALY_DETECTION_ENABLED", false)
+	viper.SetDefault("ANOMALY_WINDOW", "10m")
+	viper.SetDefault("ANOMALY_DELETION_THRESHOLD", 20)
+	viper.SetDefault("ANOMALY_EXPORT_THRESHOLD", 10)
+	viper.SetDefault("ANOMALY_TIMEZONE", "UTC")
+
+	// Watch notification defaults
+	viper.SetDefault("NOTIFY_TABLE", "notifications")
+	viper.SetDefault("NOTIFY_DIGEST_INTERVAL", "15m")
+	viper.SetDefault("NOTIFY_DIGEST_MAX_EVENTS", 100)
+
+	// SIEM export defaults
+	viper.SetDefault("SIEM_SYSLOG_NETWORK", "udp")
+	viper.SetDefault("SIEM_FORMAT", "cef")
+
+	// Brute-force throttling defaults
+	viper.SetDefault("BRUTE_FORCE_ENABLED", false)
+	viper.SetDefault("BRUTE_FORCE_THRESHOLD", 10)
+	viper.SetDefault("BRUTE_FORCE_WINDOW", "5m")
+	viper.SetDefault("BRUTE_FORCE_LOCKOUT", "1m")
+	viper.SetDefault("BRUTE_FORCE_MAX_LOCKOUT", "1h")
+
+	// Scheduled report defaults
+	viper.SetDefault("REPORTS_ENABLED", true)
+	viper.SetDefault("REPORTS_POLL_INTERVAL", "1m")
+	viper.SetDefault("REPORTS_MAX_EVENTS", 100000)
+	viper.SetDefault("REPORTS_STORAGE_BUCKET", "audit-reports")
+
+	// Import defaults
+	viper.SetDefault("IMPORT_MAX_FILE_SIZE_MB", 1024)
+
+	// Replay defaults
+	viper.SetDefault("REPLAY_MAX_RATE", 100)
+	viper.SetDefault("REPLAY_MAX_EVENTS", 100000)
+
+	// Realtime consumer defaults
+	viper.SetDefault("REALTIME_ENABLED", false)
+	viper.SetDefault("REALTIME_MATCH_WINDOW", "30s")
+
+	// Event type defaults
+	viper.SetDefault("EVENT_TYPES_REFRESH_INTERVAL", "1m")
+
+	// JWT verification defaults
+	viper.SetDefault("SUPABASE_JWKS_REFRESH_INTERVAL", "10m")
+	viper.SetDefault("SUPABASE_JWT_AUDIENCE", "authenticated")
+
+	// Secrets manager defaults
+	viper.SetDefault("SECRETS_REFRESH_INTERVAL", "5m")
+	viper.SetDefault("SECRETS_AWS_REGION", "us-east-1")
+
+	// Details encryption defaults
+	viper.SetDefault("DETAILS_ENCRYPTION_PROVIDER", "env")
+	viper.SetDefault("DETAILS_ENCRYPTION_KMS_REGION", "us-east-1")
+	viper.SetDefault("DETAILS_ENCRYPTION_DATA_KEY_TTL", "1h")
 
 	// Read from environment (this will override .env file values)
 	viper.AutomaticEnv()
 
-	// Debug: Print all environment variables
+	// Secrets are only reported as set or not
 	log.Printf("SUPABASE_URL: %s", os.Getenv("SUPABASE_URL"))
-	log.Printf("SUPABASE_SERVICE_ROLE_KEY: %s", os.Getenv("SUPABASE_SERVICE_ROLE_KEY"))
-	log.Printf("SUPABASE_JWT_SECRET: %s", os.Getenv("SUPABASE_JWT_SECRET"))
+	log.Printf("SUPABASE_SERVICE_ROLE_KEY: %s", setOrNot(os.Getenv("SUPABASE_SERVICE_ROLE_KEY")))
+	log.Printf("SUPABASE_JWT_SECRET: %s", setOrNot(os.Getenv("SUPABASE_JWT_SECRET")))
 	log.Printf("CORS_ORIGIN: %s", os.Getenv("CORS_ORIGIN"))
 
 	// Create config with direct environment variable access as fallback
@@ -121,17 +548,136 @@ func Load() (*Config, error) {
 		LogLevel:   getEnvOrDefault("LOG_LEVEL", "info"),
 		CORSOrigin: getEnvOrDefault("CORS_ORIGIN", "http://localhost:3000"),
 
+		ForwardedHeader: strings.ToLower(getEnvOrDefault("FORWARDED_HEADER", "x-forwarded-for")),
+
+		DiagnosticsAddr: os.Getenv("DIAGNOSTICS_ADDR"),
+		ServiceRole:     getEnvOrDefault("SERVICE_ROLE", "all"),
+
+		InternalAddr:           os.Getenv("INTERNAL_ADDR"),
+		InternalTLSCertFile:    os.Getenv("INTERNAL_TLS_CERT_FILE"),
+		InternalTLSKeyFile:     os.Getenv("INTERNAL_TLS_KEY_FILE"),
+		InternalTLSCAFile:      os.Getenv("INTERNAL_TLS_CA_FILE"),
+		InternalTLSAllowedSANs: parseList(os.Getenv("INTERNAL_TLS_ALLOWED_SANS")),
+
 		SupabaseURL:            os.Getenv("SUPABASE_URL"),
 		SupabaseAnonKey:        os.Getenv("SUPABASE_ANON_KEY"),
 		SupabaseServiceRoleKey: os.Getenv("SUPABASE_SERVICE_ROLE_KEY"),
 		SupabaseJWTSecret:      os.Getenv("SUPABASE_JWT_SECRET"),
+		ShareTokenSecret:       os.Getenv("SHARE_TOKEN_SECRET"),
+
+		SupabaseJWTPreviousSecret: os.Getenv("SUPABASE_JWT_PREVIOUS_SECRET"),
+		SupabaseJWKSURL:           os.Getenv("SUPABASE_JWKS_URL"),
+		SupabaseJWTIssuer:         os.Getenv("SUPABASE_JWT_ISSUER"),
+		SupabaseJWTAudience:       getEnvOrDefault("SUPABASE_JWT_AUDIENCE", "authenticated"),
+
+		SupabaseBreakerFailureThreshold: getEnvOrDefaultInt("SUPABASE_BREAKER_FAILURE_THRESHOLD", 5),
+		SupabaseBreakerHalfOpenRequests: getEnvOrDefaultInt("SUPABASE_BREAKER_HALF_OPEN_REQUESTS", 1),
+		SupabaseRetryMaxAttempts:        getEnvOrDefaultInt("SUPABASE_RETRY_MAX_ATTEMPTS", 3),
+
+		StorageBackend: getEnvOrDefault("STORAGE_BACKEND", "supabase"),
+		DatabaseURL:    os.Getenv("DATABASE_URL"),
+		SQLitePath:     getEnvOrDefault("SQLITE_PATH", "audit.db"),
 
 		MaxPageSize:     getEnvOrDefaultInt("MAX_PAGE_SIZE", 100),
 		DefaultPageSize: getEnvOrDefaultInt("DEFAULT_PAGE_SIZE", 50),
+		ExportMaxRows:   getEnvOrDefaultInt("EXPORT_MAX_ROWS", 50000),
+
+		ExportPageSize:       getEnvOrDefaultInt("EXPORT_PAGE_SIZE", 500),
+		ExportMaxConcurrent:  getEnvOrDefaultInt("EXPORT_MAX_CONCURRENT", 4),
+		ExportCallbackSecret: os.Getenv("EXPORT_CALLBACK_SECRET"),
+		WriteRetryAttempts:   getEnvOrDefaultInt("WRITE_RETRY_ATTEMPTS", 3),
+
+		TimestampSkewMode: getEnvOrDefault("TIMESTAMP_SKEW_MODE", "reject"),
+
+		CompressionMinSize: getEnvOrDefaultInt("COMPRESSION_MIN_SIZE", 1024),
+
+		MaxBodySize:    getEnvOrDefaultInt("MAX_BODY_SIZE", 1<<20),
+		MaxDetailsSize: getEnvOrDefaultInt("MAX_DETAILS_SIZE", 64<<10),
+
+		QuotaSessionDaily: getEnvOrDefaultInt("QUOTA_SESSION_DAILY", 0),
+		QuotaUserDaily:    getEnvOrDefaultInt("QUOTA_USER_DAILY", 0),
+
+		ViewDedupMaxHeld: getEnvOrDefaultInt("VIEW_DEDUP_MAX_HELD", 10000),
+
+		EventTaxonomy: os.Getenv("EVENT_TAXONOMY"),
+
+		CacheBackend:     getEnvOrDefault("CACHE_BACKEND", "memory"),
+		RedisURL:         os.Getenv("REDIS_URL"),
+		CacheRedisPrefix: getEnvOrDefault("CACHE_REDIS_PREFIX", "audit-service:token:"),
+		SessionCacheSize: getEnvOrDefaultInt("SESSION_CACHE_SIZE", 10000),
+		QueryCacheSize:   getEnvOrDefaultInt("QUERY_CACHE_SIZE", 1000),
+
+		WriteBufferCapacity:  getEnvOrDefaultInt("WRITE_BUFFER_CAPACITY", 10000),
+		WriteBufferBatchSize: getEnvOrDefaultInt("WRITE_BUFFER_BATCH_SIZE", 100),
+		WriteBufferOverflow:  getEnvOrDefault("WRITE_BUFFER_OVERFLOW", "reject"),
+
+		RetentionBatchSize: getEnvOrDefaultInt("RETENTION_BATCH_SIZE", 1000),
+
+		ArchivePrefix:            getEnvOrDefault("ARCHIVE_PREFIX", "audit-logs"),
+		ArchiveS3Endpoint:        os.Getenv("ARCHIVE_S3_ENDPOINT"),
+		ArchiveS3Region:          getEnvOrDefault("ARCHIVE_S3_REGION", "us-east-1"),
+		ArchiveS3Bucket:          os.Getenv("ARCHIVE_S3_BUCKET"),
+		ArchiveS3AccessKeyID:     os.Getenv("ARCHIVE_S3_ACCESS_KEY_ID"),
+		ArchiveS3SecretAccessKey: os.Getenv("ARCHIVE_S3_SECRET_ACCESS_KEY"),
+
+		OutboxWebhookURLs:   parseList(os.Getenv("OUTBOX_WEBHOOK_URLS")),
+		OutboxWebhookSecret: os.Getenv("OUTBOX_WEBHOOK_SECRET"),
+		OutboxBatchSize:     getEnvOrDefaultInt("OUTBOX_BATCH_SIZE", 100),
+		OutboxMaxAttempts:   getEnvOrDefaultInt("OUTBOX_MAX_ATTEMPTS", 12),
+		WebhookFormat:       getEnvOrDefault("WEBHOOK_FORMAT", "native"),
+
+		AnomalyDeletionThreshold: getEnvOrDefaultInt("ANOMALY_DELETION_THRESHOLD", 20),
+		AnomalyExportThreshold:   getEnvOrDefaultInt("ANOMALY_EXPORT_THRESHOLD", 10),
+		AnomalyWebhookURL:        os.Getenv("ANOMALY_WEBHOOK_URL"),
+		AnomalyWebhookSecret:     os.Getenv("ANOMALY_WEBHOOK_SECRET"),
+
+		NotifySink:            os.Getenv("NOTIFY_SINK"),
+		NotifyWebhookURL:      os.Getenv("NOTIFY_WEBHOOK_URL"),
+		NotifyWebhookSecret:   os.Getenv("NOTIFY_WEBHOOK_SECRET"),
+		NotifyTable:           getEnvOrDefault("NOTIFY_TABLE", "notifications"),
+		NotifyDigestMaxEvents: getEnvOrDefaultInt("NOTIFY_DIGEST_MAX_EVENTS", 100),
+
+		SIEMSyslogAddress: os.Getenv("SIEM_SYSLOG_ADDRESS"),
+		SIEMSyslogNetwork: getEnvOrDefault("SIEM_SYSLOG_NETWORK", "udp"),
+		SIEMFormat:        getEnvOrDefault("SIEM_FORMAT", "cef"),
+
+		BruteForceThreshold: getEnvOrDefaultInt("BRUTE_FORCE_THRESHOLD", 10),
+
+		ReportsMaxEvents:     getEnvOrDefaultInt("REPORTS_MAX_EVENTS", 100000),
+		ReportsWebhookSecret: os.Getenv("REPORTS_WEBHOOK_SECRET"),
+		ReportsStorageBucket: getEnvOrDefault("REPORTS_STORAGE_BUCKET", "audit-reports"),
+
+		ImportMaxFileSizeMB: getEnvOrDefaultInt("IMPORT_MAX_FILE_SIZE_MB", 1024),
+		ImportTempDir:       os.Getenv("IMPORT_TEMP_DIR"),
+
+		ReplayMaxRate:   getEnvOrDefaultInt("REPLAY_MAX_RATE", 100),
+		ReplayMaxEvents: getEnvOrDefaultInt("REPLAY_MAX_EVENTS", 100000),
+
+		DetailsEncryptionTypes:              parseList(os.Getenv("DETAILS_ENCRYPTION_TYPES")),
+		DetailsEncryptionProvider:           getEnvOrDefault("DETAILS_ENCRYPTION_PROVIDER", "env"),
+		DetailsEncryptionKMSKeyID:           os.Getenv("DETAILS_ENCRYPTION_KMS_KEY_ID"),
+		DetailsEncryptionKMSRegion:          getEnvOrDefault("DETAILS_ENCRYPTION_KMS_REGION", "us-east-1"),
+		DetailsEncryptionKMSEndpoint:        os.Getenv("DETAILS_ENCRYPTION_KMS_ENDPOINT"),
+		DetailsEncryptionKMSAccessKeyID:     os.Getenv("DETAILS_ENCRYPTION_KMS_ACCESS_KEY_ID"),
+		DetailsEncryptionKMSSecretAccessKey: os.Getenv("DETAILS_ENCRYPTION_KMS_SECRET_ACCESS_KEY"),
+
+		SecretsProvider: os.Getenv("SECRETS_PROVIDER"),
+		SecretsPath:     os.Getenv("SECRETS_PATH"),
+		VaultAddr:       os.Getenv("VAULT_ADDR"),
+		VaultToken:      os.Getenv("VAULT_TOKEN"),
+
+		SecretsAWSRegion:          getEnvOrDefault("SECRETS_AWS_REGION", "us-east-1"),
+		SecretsAWSEndpoint:        os.Getenv("SECRETS_AWS_ENDPOINT"),
+		SecretsAWSAccessKeyID:     os.Getenv("SECRETS_AWS_ACCESS_KEY_ID"),
+		SecretsAWSSecretAccessKey: os.Getenv("SECRETS_AWS_SECRET_ACCESS_KEY"),
+		SecretsAWSSessionToken:    os.Getenv("SECRETS_AWS_SESSION_TOKEN"),
 	}
 
 	// Parse duration fields
 	var err error
+	if cfg.ShutdownTimeout, err = time.ParseDuration(getEnvOrDefault("SHUTDOWN_TIMEOUT", "30s")); err != nil {
+		return nil, fmt.Errorf("invalid SHUTDOWN_TIMEOUT: %w", err)
+	}
 	if cfg.HTTPTimeout, err = time.ParseDuration(getEnvOrDefault("HTTP_TIMEOUT", "30s")); err != nil {
 		return nil, fmt.Errorf("invalid HTTP_TIMEOUT: %w", err)
 	}
@@ -147,6 +693,187 @@ func Load() (*Config, error) {
 	if cfg.CacheCleanupInterval, err = time.ParseDuration(getEnvOrDefault("CACHE_CLEANUP_INTERVAL", "10m")); err != nil {
 		return nil, fmt.Errorf("invalid CACHE_CLEANUP_INTERVAL: %w", err)
 	}
+	if cfg.RevocationRefreshInterval, err = time.ParseDuration(getEnvOrDefault("REVOCATION_REFRESH_INTERVAL", "30s")); err != nil {
+		return nil, fmt.Errorf("invalid REVOCATION_REFRESH_INTERVAL: %w", err)
+	}
+	if cfg.IdempotencyTTL, err = time.ParseDuration(getEnvOrDefault("IDEMPOTENCY_TTL", "1h")); err != nil {
+		return nil, fmt.Errorf("invalid IDEMPOTENCY_TTL: %w", err)
+	}
+	if cfg.CacheRedisTimeout, err = time.ParseDuration(getEnvOrDefault("CACHE_REDIS_TIMEOUT", "250ms")); err != nil {
+		return nil, fmt.Errorf("invalid CACHE_REDIS_TIMEOUT: %w", err)
+	}
+	if cfg.SessionCacheTTL, err = time.ParseDuration(getEnvOrDefault("SESSION_CACHE_TTL", "30s")); err != nil {
+		return nil, fmt.Errorf("invalid SESSION_CACHE_TTL: %w", err)
+	}
+	if cfg.QueryCacheTTL, err = time.ParseDuration(getEnvOrDefault("QUERY_CACHE_TTL", "5s")); err != nil {
+		return nil, fmt.Errorf("invalid QUERY_CACHE_TTL: %w", err)
+	}
+	if cfg.ConsistencyWait, err = time.ParseDuration(getEnvOrDefault("CONSISTENCY_WAIT", "5s")); err != nil {
+		return nil, fmt.Errorf("invalid CONSISTENCY_WAIT: %w", err)
+	}
+
+	if cfg.SupabaseBreakerOpenTimeout, err = time.ParseDuration(getEnvOrDefault("SUPABASE_BREAKER_OPEN_TIMEOUT", "30s")); err != nil {
+		return nil, fmt.Errorf("invalid SUPABASE_BREAKER_OPEN_TIMEOUT: %w", err)
+	}
+	if cfg.SupabaseRetryBaseDelay, err = time.ParseDuration(getEnvOrDefault("SUPABASE_RETRY_BASE_DELAY", "100ms")); err != nil {
+		return nil, fmt.Errorf("invalid SUPABASE_RETRY_BASE_DELAY: %w", err)
+	}
+	if cfg.SupabaseRetryMaxDelay, err = time.ParseDuration(getEnvOrDefault("SUPABASE_RETRY_MAX_DELAY", "2s")); err != nil {
+		return nil, fmt.Errorf("invalid SUPABASE_RETRY_MAX_DELAY: %w", err)
+	}
+
+	if cfg.WriteRetryBackoff, err = time.ParseDuration(getEnvOrDefault("WRITE_RETRY_BACKOFF", "100ms")); err != nil {
+		return nil, fmt.Errorf("invalid WRITE_RETRY_BACKOFF: %w", err)
+	}
+	if cfg.TimestampMaxFutureSkew, err = time.ParseDuration(getEnvOrDefault("TIMESTAMP_MAX_FUTURE_SKEW", "5m")); err != nil {
+		return nil, fmt.Errorf("invalid TIMESTAMP_MAX_FUTURE_SKEW: %w", err)
+	}
+	if cfg.TimestampMaxPastSkew, err = time.ParseDuration(getEnvOrDefault("TIMESTAMP_MAX_PAST_SKEW", "0s")); err != nil {
+		return nil, fmt.Errorf("invalid TIMESTAMP_MAX_PAST_SKEW: %w", err)
+	}
+	if cfg.WriteBufferFlushInterval, err = time.ParseDuration(getEnvOrDefault("WRITE_BUFFER_FLUSH_INTERVAL", "1s")); err != nil {
+		return nil, fmt.Errorf("invalid WRITE_BUFFER_FLUSH_INTERVAL: %w", err)
+	}
+	if cfg.ViewDedupWindow, err = time.ParseDuration(getEnvOrDefault("VIEW_DEDUP_WINDOW", "0s")); err != nil {
+		return nil, fmt.Errorf("invalid VIEW_DEDUP_WINDOW: %w", err)
+	}
+
+	if cfg.ExportCallbackTolerance, err = time.ParseDuration(getEnvOrDefault("EXPORT_CALLBACK_TOLERANCE", "5m")); err != nil {
+		return nil, fmt.Errorf("invalid EXPORT_CALLBACK_TOLERANCE: %w", err)
+	}
+
+	if cfg.RetentionInterval, err = time.ParseDuration(getEnvOrDefault("RETENTION_INTERVAL", "1h")); err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_INTERVAL: %w", err)
+	}
+
+	if cfg.RollupInterval, err = time.ParseDuration(getEnvOrDefault("ROLLUP_INTERVAL", "15m")); err != nil {
+		return nil, fmt.Errorf("invalid ROLLUP_INTERVAL: %w", err)
+	}
+	if cfg.RollupLookback, err = time.ParseDuration(getEnvOrDefault("ROLLUP_LOOKBACK", "48h")); err != nil {
+		return nil, fmt.Errorf("invalid ROLLUP_LOOKBACK: %w", err)
+	}
+
+	if cfg.OutboxPollInterval, err = time.ParseDuration(getEnvOrDefault("OUTBOX_POLL_INTERVAL", "1s")); err != nil {
+		return nil, fmt.Errorf("invalid OUTBOX_POLL_INTERVAL: %w", err)
+	}
+
+	if cfg.AnomalyWindow, err = time.ParseDuration(getEnvOrDefault("ANOMALY_WINDOW", "10m")); err != nil {
+		return nil, fmt.Errorf("invalid ANOMALY_WINDOW: %w", err)
+	}
+
+	if cfg.BruteForceWindow, err = time.ParseDuration(getEnvOrDefault("BRUTE_FORCE_WINDOW", "5m")); err != nil {
+		return nil, fmt.Errorf("invalid BRUTE_FORCE_WINDOW: %w", err)
+	}
+	if cfg.BruteForceLockout, err = time.ParseDuration(getEnvOrDefault("BRUTE_FORCE_LOCKOUT", "1m")); err != nil {
+		return nil, fmt.Errorf("invalid BRUTE_FORCE_LOCKOUT: %w", err)
+	}
+	if cfg.BruteForceMaxLockout, err = time.ParseDuration(getEnvOrDefault("BRUTE_FORCE_MAX_LOCKOUT", "1h")); err != nil {
+		return nil, fmt.Errorf("invalid BRUTE_FORCE_MAX_LOCKOUT: %w", err)
+	}
+
+	if cfg.NotifyDigestInterval, err = time.ParseDuration(getEnvOrDefault("NOTIFY_DIGEST_INTERVAL", "15m")); err != nil {
+		return nil, fmt.Errorf("invalid NOTIFY_DIGEST_INTERVAL: %w", err)
+	}
+
+	if cfg.ReportsPollInterval, err = time.ParseDuration(getEnvOrDefault("REPORTS_POLL_INTERVAL", "1m")); err != nil {
+		return nil, fmt.Errorf("invalid REPORTS_POLL_INTERVAL: %w", err)
+	}
+
+	if cfg.RealtimeMatchWindow, err = time.ParseDuration(getEnvOrDefault("REALTIME_MATCH_WINDOW", "30s")); err != nil {
+		return nil, fmt.Errorf("invalid REALTIME_MATCH_WINDOW: %w", err)
+	}
+
+	if cfg.EventTypesRefreshInterval, err = time.ParseDuration(getEnvOrDefault("EVENT_TYPES_REFRESH_INTERVAL", "1m")); err != nil {
+		return nil, fmt.Errorf("invalid EVENT_TYPES_REFRESH_INTERVAL: %w", err)
+	}
+
+	if cfg.SupabaseJWKSRefreshInterval, err = time.ParseDuration(getEnvOrDefault("SUPABASE_JWKS_REFRESH_INTERVAL", "10m")); err != nil {
+		return nil, fmt.Errorf("invalid SUPABASE_JWKS_REFRESH_INTERVAL: %w", err)
+	}
+
+	if cfg.DetailsEncryptionDataKeyTTL, err = time.ParseDuration(getEnvOrDefault("DETAILS_ENCRYPTION_DATA_KEY_TTL", "1h")); err != nil {
+		return nil, fmt.Errorf("invalid DETAILS_ENCRYPTION_DATA_KEY_TTL: %w", err)
+	}
+	if cfg.SecretsRefreshInterval, err = time.ParseDuration(getEnvOrDefault("SECRETS_REFRESH_INTERVAL", "5m")); err != nil {
+		return nil, fmt.Errorf("invalid SECRETS_REFRESH_INTERVAL: %w", err)
+	}
+
+	// Parse the working hours and time zone of the off-hours rule
+	if cfg.AnomalyWorkdayStart, cfg.AnomalyWorkdayEnd, err = parseWorkingHours(os.Getenv("ANOMALY_WORKING_HOURS")); err != nil {
+		return nil, fmt.Errorf("invalid ANOMALY_WORKING_HOURS: %w", err)
+	}
+	if cfg.AnomalyTimezone, err = time.LoadLocation(getEnvOrDefault("ANOMALY_TIMEZONE", "UTC")); err != nil {
+		return nil, fmt.Errorf("invalid ANOMALY_TIMEZONE: %w", err)
+	}
+
+	// Parse retention periods per event type
+	if cfg.RetentionPolicies, err = parseRetentionPolicies(os.Getenv("RETENTION_POLICIES")); err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_POLICIES: %w", err)
+	}
+
+	// Parse access log sampling rates
+	if cfg.AccessLogSampleRate, err = strconv.ParseFloat(getEnvOrDefault("ACCESS_LOG_SAMPLE_RATE", "1"), 64); err != nil {
+		return nil, fmt.Errorf("invalid ACCESS_LOG_SAMPLE_RATE: %w", err)
+	}
+	if cfg.AccessLogRouteSampling, err = parseSamplingRates(os.Getenv("ACCESS_LOG_ROUTE_SAMPLING")); err != nil {
+		return nil, fmt.Errorf("invalid ACCESS_LOG_ROUTE_SAMPLING: %w", err)
+	}
+
+	// Parse body size limits per route
+	if cfg.MaxBodySizeRoutes, err = parseSizeLimits(getEnvOrDefault("MAX_BODY_SIZE_ROUTES", defaultBodySizeRoutes)); err != nil {
+		return nil, fmt.Errorf("invalid MAX_BODY_SIZE_ROUTES: %w", err)
+	}
+
+	// Parse trusted proxy ranges
+	if cfg.TrustedProxies, err = parseTrustedProxies(os.Getenv("TRUSTED_PROXIES")); err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+
+	// Users allowed to call the admin endpoints
+	cfg.AdminUserIDs = parseList(os.Getenv("ADMIN_USER_IDS"))
+
+	cfg.SystemSessionID = os.Getenv("SYSTEM_SESSION_ID")
+
+	// Parse hashed service API keys
+	if cfg.APIKeys, err = apikey.Parse(os.Getenv("API_KEYS")); err != nil {
+		return nil, fmt.Errorf("invalid API_KEYS: %w", err)
+	}
+
+	// Parse the rules masking personal data in event details
+	if cfg.RedactionRules, err = redact.ParseRules(os.Getenv("REDACTION_RULES"), os.Getenv("REDACTION_PATTERNS")); err != nil {
+		return nil, fmt.Errorf("invalid REDACTION_RULES or REDACTION_PATTERNS: %w", err)
+	}
+
+	// Parse the key encryption keys of the details encryption
+	if cfg.DetailsEncryptionKeys, err = fieldcrypt.ParseKeys(os.Getenv("DETAILS_ENCRYPTION_KEYS")); err != nil {
+		return nil, fmt.Errorf("invalid DETAILS_ENCRYPTION_KEYS: %w", err)
+	}
+
+	// Parse bool fields
+	if cfg.WriteBufferEnabled, err = strconv.ParseBool(getEnvOrDefault("WRITE_BUFFER_ENABLED", "true")); err != nil {
+		return nil, fmt.Errorf("invalid WRITE_BUFFER_ENABLED: %w", err)
+	}
+	if cfg.ArchiveEnabled, err = strconv.ParseBool(getEnvOrDefault("ARCHIVE_ENABLED", "false")); err != nil {
+		return nil, fmt.Errorf("invalid ARCHIVE_ENABLED: %w", err)
+	}
+	if cfg.RollupEnabled, err = strconv.ParseBool(getEnvOrDefault("ROLLUP_ENABLED", "true")); err != nil {
+		return nil, fmt.Errorf("invalid ROLLUP_ENABLED: %w", err)
+	}
+	if cfg.ArchiveS3PathStyle, err = strconv.ParseBool(getEnvOrDefault("ARCHIVE_S3_PATH_STYLE", "true")); err != nil {
+		return nil, fmt.Errorf("invalid ARCHIVE_S3_PATH_STYLE: %w", err)
+	}
+	if cfg.AnomalyDetectionEnabled, err = strconv.ParseBool(getEnvOrDefault("ANOMALY_DETECTION_ENABLED", "false")); err != nil {
+		return nil, fmt.Errorf("invalid ANOMALY_DETECTION_ENABLED: %w", err)
+	}
+	if cfg.BruteForceEnabled, err = strconv.ParseBool(getEnvOrDefault("BRUTE_FORCE_ENABLED", "false")); err != nil {
+		return nil, fmt.Errorf("invalid BRUTE_FORCE_ENABLED: %w", err)
+	}
+	if cfg.ReportsEnabled, err = strconv.ParseBool(getEnvOrDefault("REPORTS_ENABLED", "true")); err != nil {
+		return nil, fmt.Errorf("invalid REPORTS_ENABLED: %w", err)
+	}
+	if cfg.RealtimeEnabled, err = strconv.ParseBool(getEnvOrDefault("REALTIME_ENABLED", "false")); err != nil {
+		return nil, fmt.Errorf("invalid REALTIME_ENABLED: %w", err)
+	}
 
 	// Parse int fields
 	if cfg.HTTPMaxIdleConns = getEnvOrDefaultInt("HTTP_MAX_IDLE_CONNS", 100); cfg.HTTPMaxIdleConns <= 0 {
@@ -171,9 +898,21 @@ func Load() (*Config, error) {
 		}
 	}
 
-	// Validate required fields
-	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("config validation failed: %w", err)
+	// Credentials in the secrets manager take precedence over the environment
+	if cfg.SecretsManagerEnabled() {
+		if err := cfg.validateSecretsManager(); err != nil {
+			return nil, err
+		}
+		values, err := secrets.Fetch(context.Background(), cfg.NewSecretsProvider(clock.New()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secrets from %s: %w", cfg.SecretsProvider, err)
+		}
+		cfg.ApplySecrets(values)
+	}
+
+	// Tokens of the project's auth server carry its URL as issuer
+	if cfg.SupabaseJWTIssuer == "" && cfg.SupabaseURL != "" {
+		cfg.SupabaseJWTIssuer = jwt.SupabaseIssuer(cfg.SupabaseURL)
 	}
 
 	return &cfg, nil
@@ -197,6 +936,175 @@ func getEnvOrDefaultInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// parseList splits a comma-separated value, dropping empty items
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseSamplingRates parses a comma-separated list of route=rate pairs such as "GET /health=0,/metrics=0.1"
+func parseSamplingRates(value string) (map[string]float64, error) {
+	rates := map[string]float64{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		// Routes never contain '=', so the rate follows the last one
+		i := strings.LastIndex(item, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("%q is not a route=rate pair", item)
+		}
+		route := strings.TrimSpace(item[:i])
+		rate, err := strconv.ParseFloat(strings.TrimSpace(item[i+1:]), 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("rate of %q must be between 0 and 1", route)
+		}
+		rates[route] = rate
+	}
+	return rates, nil
+}
+
+// defaultBodySizeRoutes lets batches, which carry up to 5000 events, exceed MAX_BODY_SIZE
+const defaultBodySizeRoutes = "POST /api/v1/events/batch=16777216"
+
+// parseSizeLimits parses a comma-separated list of route=bytes pairs such as "POST /api/v1/events/batch=8388608"
+func parseSizeLimits(value string) (map[string]int, error) {
+	limits := map[string]int{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		// Routes never contain '=', so the limit follows the last one
+		i := strings.LastIndex(item, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("%q is not a route=bytes pair", item)
+		}
+		route := strings.TrimSpace(item[:i])
+		limit, err := strconv.Atoi(strings.TrimSpace(item[i+1:]))
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("limit of %q must be a non-negative number of bytes", route)
+		}
+		limits[route] = limit
+	}
+	return limits, nil
+}
+
+// parseTrustedProxies parses a comma-separated list of IP addresses and CIDR ranges
+func parseTrustedProxies(value string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		if strings.Contains(item, "/") {
+			prefix, err := netip.ParsePrefix(item)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(item)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// parseWorkingHours parses a range such as 08:00-19:00 into offsets from midnight.
+// An empty value returns equal offsets, which disables the off-hours rule.
+func parseWorkingHours(value string) (time.Duration, time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, 0, nil
+	}
+
+	startValue, endValue, found := strings.Cut(value, "-")
+	if !found {
+		return 0, 0, fmt.Errorf("%q is not a HH:MM-HH:MM range", value)
+	}
+	start, err := parseTimeOfDay(strings.TrimSpace(startValue))
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseTimeOfDay(strings.TrimSpace(endValue))
+	if err != nil {
+		return 0, 0, err
+	}
+	if start == end {
+		return 0, 0, fmt.Errorf("%q is an empty range", value)
+	}
+	return start, end, nil
+}
+
+// parseTimeOfDay parses HH:MM into an offset from midnight; 24:00 is accepted as the end of the day
+func parseTimeOfDay(value string) (time.Duration, error) {
+	if value == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a HH:MM time", value)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// parseRetentionPolicies parses a comma-separated list of type=duration pairs such as view=30d,edit=730d
+func parseRetentionPolicies(value string) (map[string]time.Duration, error) {
+	policies := map[string]time.Duration{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		eventType, period, ok := strings.Cut(item, "=")
+		eventType = strings.TrimSpace(eventType)
+		if !ok || eventType == "" {
+			return nil, fmt.Errorf("%q is not a type=duration pair", item)
+		}
+		if _, exists := policies[eventType]; exists {
+			return nil, fmt.Errorf("duplicate policy for %q", eventType)
+		}
+
+		retention, err := parseRetentionPeriod(strings.TrimSpace(period))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", eventType, err)
+		}
+		if retention <= 0 {
+			return nil, fmt.Errorf("%s: retention period must be positive", eventType)
+		}
+		policies[eventType] = retention
+	}
+	return policies, nil
+}
+
+// parseRetentionPeriod parses a Go duration, additionally accepting whole days such as 30d
+func parseRetentionPeriod(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
 // Helper function to parse int from string
 func parseIntFromString(s string) (int, error) {
 	var result int
@@ -206,14 +1114,80 @@ func parseIntFromString(s string) (int, error) {
 
 // Validate ensures all required configuration is present
 func (c *Config) Validate() error {
-	if c.SupabaseURL == "" {
-		return fmt.Errorf("SUPABASE_URL is required")
+	if c.AccessLogSampleRate < 0 || c.AccessLogSampleRate > 1 {
+		return fmt.Errorf("ACCESS_LOG_SAMPLE_RATE must be between 0 and 1")
+	}
+	if c.DiagnosticsAddr != "" {
+		if _, _, err := net.SplitHostPort(c.DiagnosticsAddr); err != nil {
+			return fmt.Errorf("DIAGNOSTICS_ADDR must be a host:port listen address: %w", err)
+		}
+	}
+	if c.InternalAddr != "" {
+		if _, _, err := net.SplitHostPort(c.InternalAddr); err != nil {
+			return fmt.Errorf("INTERNAL_ADDR must be a host:port listen address: %w", err)
+		}
 	}
-	if c.SupabaseServiceRoleKey == "" {
-		return fmt.Errorf("SUPABASE_SERVICE_ROLE_KEY is required")
+	if c.InternalTLSCertFile != "" || c.InternalTLSKeyFile != "" || c.InternalTLSCAFile != "" {
+		if c.InternalTLSCertFile == "" || c.InternalTLSKeyFile == "" || c.InternalTLSCAFile == "" {
+			return fmt.Errorf("INTERNAL_TLS_CERT_FILE, INTERNAL_TLS_KEY_FILE and INTERNAL_TLS_CA_FILE must be set together")
+		}
+		if c.InternalAddr == "" {
+			return fmt.Errorf("INTERNAL_ADDR is required when INTERNAL_TLS_CERT_FILE is set")
+		}
 	}
-	if c.SupabaseJWTSecret == "" {
-		return fmt.Errorf("SUPABASE_JWT_SECRET is required")
+	switch c.ServiceRole {
+	case RoleAll, RoleWriter, RoleReader:
+	default:
+		return fmt.Errorf("SERVICE_ROLE must be one of all, writer, reader")
+	}
+	switch c.StorageBackend {
+	case "supabase":
+		if c.SupabaseURL == "" {
+			return fmt.Errorf("SUPABASE_URL is required")
+		}
+		if c.SupabaseServiceRoleKey == "" {
+			return fmt.Errorf("SUPABASE_SERVICE_ROLE_KEY is required")
+		}
+		if c.SupabaseBreakerFailureThreshold <= 0 || c.SupabaseBreakerHalfOpenRequests <= 0 {
+			return fmt.Errorf("SUPABASE_BREAKER_FAILURE_THRESHOLD and SUPABASE_BREAKER_HALF_OPEN_REQUESTS must be positive")
+		}
+		if c.SupabaseBreakerOpenTimeout <= 0 {
+			return fmt.Errorf("SUPABASE_BREAKER_OPEN_TIMEOUT must be positive")
+		}
+		if c.SupabaseRetryMaxAttempts <= 0 {
+			return fmt.Errorf("SUPABASE_RETRY_MAX_ATTEMPTS must be positive")
+		}
+		if c.SupabaseRetryBaseDelay < 0 || c.SupabaseRetryMaxDelay < c.SupabaseRetryBaseDelay {
+			return fmt.Errorf("SUPABASE_RETRY_MAX_DELAY must not be below SUPABASE_RETRY_BASE_DELAY")
+		}
+	case "postgres":
+		if c.DatabaseURL == "" {
+			return fmt.Errorf("DATABASE_URL is required when STORAGE_BACKEND is postgres")
+		}
+	case "sqlite":
+		if c.SQLitePath == "" {
+			return fmt.Errorf("SQLITE_PATH is required when STORAGE_BACKEND is sqlite")
+		}
+	case "memory":
+	default:
+		return fmt.Errorf("STORAGE_BACKEND must be one of supabase, postgres, sqlite, memory")
+	}
+	if c.SupabaseJWTSecret == "" && c.SupabaseJWKSURL == "" {
+		return fmt.Errorf("SUPABASE_JWT_SECRET or SUPABASE_JWKS_URL is required")
+	}
+	if c.SupabaseJWKSURL != "" {
+		parsed, err := url.Parse(c.SupabaseJWKSURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("SUPABASE_JWKS_URL must be an absolute http(s) URL")
+		}
+	}
+	if c.SupabaseJWKSRefreshInterval < 0 {
+		return fmt.Errorf("SUPABASE_JWKS_REFRESH_INTERVAL must not be negative")
+	}
+	if c.SystemSessionID != "" {
+		if _, err := uuid.Parse(c.SystemSessionID); err != nil {
+			return fmt.Errorf("SYSTEM_SESSION_ID must be a UUID")
+		}
 	}
 	if c.Port == "" {
 		return fmt.Errorf("PORT is required")
@@ -221,21 +1195,412 @@ func (c *Config) Validate() error {
 	if c.HTTPTimeout <= 0 {
 		return fmt.Errorf("HTTP_TIMEOUT must be positive")
 	}
+	if c.ShutdownTimeout <= 0 {
+		return fmt.Errorf("SHUTDOWN_TIMEOUT must be positive")
+	}
+	switch c.ForwardedHeader {
+	case "x-forwarded-for", "forwarded":
+	default:
+		return fmt.Errorf("FORWARDED_HEADER must be one of x-forwarded-for, forwarded")
+	}
 	if c.CacheJWTTTL <= 0 {
 		return fmt.Errorf("CACHE_JWT_TTL must be positive")
 	}
 	if c.CacheShareTokenTTL <= 0 {
 		return fmt.Errorf("CACHE_SHARE_TOKEN_TTL must be positive")
 	}
+	if c.RevocationRefreshInterval <= 0 {
+		return fmt.Errorf("REVOCATION_REFRESH_INTERVAL must be positive")
+	}
+	switch c.CacheBackend {
+	case "memory":
+	case "redis":
+		if c.RedisURL == "" {
+			return fmt.Errorf("REDIS_URL is required when CACHE_BACKEND is redis")
+		}
+		if c.CacheRedisTimeout <= 0 {
+			return fmt.Errorf("CACHE_REDIS_TIMEOUT must be positive")
+		}
+	default:
+		return fmt.Errorf("CACHE_BACKEND must be one of memory, redis")
+	}
+	if c.SessionCacheSize < 0 {
+		return fmt.Errorf("SESSION_CACHE_SIZE must not be negative")
+	}
+	if c.SessionCacheEnabled() && c.SessionCacheTTL <= 0 {
+		return fmt.Errorf("SESSION_CACHE_TTL must be positive")
+	}
+	if c.QueryCacheSize < 0 {
+		return fmt.Errorf("QUERY_CACHE_SIZE must not be negative")
+	}
+	if c.QueryCacheEnabled() && c.QueryCacheTTL <= 0 {
+		return fmt.Errorf("QUERY_CACHE_TTL must be positive")
+	}
+	if c.ConsistencyWait <= 0 {
+		return fmt.Errorf("CONSISTENCY_WAIT must be positive")
+	}
+	if c.ExportMaxRows <= 0 {
+		return fmt.Errorf("EXPORT_MAX_ROWS must be positive")
+	}
+	if c.ExportPageSize <= 0 {
+		return fmt.Errorf("EXPORT_PAGE_SIZE must be positive")
+	}
+	if c.ExportMaxConcurrent < 0 {
+		return fmt.Errorf("EXPORT_MAX_CONCURRENT must not be negative")
+	}
+	if c.ExportCallbacksEnabled() && c.ExportCallbackTolerance <= 0 {
+		return fmt.Errorf("EXPORT_CALLBACK_TOLERANCE must be positive")
+	}
+	if c.WriteRetryAttempts <= 0 {
+		return fmt.Errorf("WRITE_RETRY_ATTEMPTS must be positive")
+	}
+	if c.WriteRetryBackoff < 0 {
+		return fmt.Errorf("WRITE_RETRY_BACKOFF must not be negative")
+	}
+	if c.TimestampMaxFutureSkew < 0 {
+		return fmt.Errorf("TIMESTAMP_MAX_FUTURE_SKEW must not be negative")
+	}
+	if c.TimestampMaxPastSkew < 0 {
+		return fmt.Errorf("TIMESTAMP_MAX_PAST_SKEW must not be negative")
+	}
+	switch c.TimestampSkewMode {
+	case "reject", "clamp":
+	default:
+		return fmt.Errorf("TIMESTAMP_SKEW_MODE must be one of reject, clamp")
+	}
+	if c.CompressionMinSize < 0 {
+		return fmt.Errorf("COMPRESSION_MIN_SIZE must not be negative")
+	}
+	if c.MaxBodySize < 0 {
+		return fmt.Errorf("MAX_BODY_SIZE must not be negative")
+	}
+	if c.MaxDetailsSize < 0 {
+		return fmt.Errorf("MAX_DETAILS_SIZE must not be negative")
+	}
+	if c.QuotaSessionDaily < 0 {
+		return fmt.Errorf("QUOTA_SESSION_DAILY must not be negative")
+	}
+	if c.QuotaUserDaily < 0 {
+		return fmt.Errorf("QUOTA_USER_DAILY must not be negative")
+	}
+	if c.ViewDedupWindow < 0 {
+		return fmt.Errorf("VIEW_DEDUP_WINDOW must not be negative")
+	}
+	if c.ViewDedupWindow > 0 && c.ViewDedupMaxHeld <= 0 {
+		return fmt.Errorf("VIEW_DEDUP_MAX_HELD must be positive")
+	}
+	if _, err := domain.ParseTaxonomy(c.EventTaxonomy); err != nil {
+		return fmt.Errorf("invalid EVENT_TAXONOMY: %w", err)
+	}
+	if c.IdempotencyTTL <= 0 {
+		return fmt.Errorf("IDEMPOTENCY_TTL must be positive")
+	}
+	if c.EventTypesRefreshInterval < 0 {
+		return fmt.Errorf("EVENT_TYPES_REFRESH_INTERVAL must not be negative")
+	}
+	if c.WriteBufferEnabled {
+		if c.WriteBufferCapacity <= 0 {
+			return fmt.Errorf("WRITE_BUFFER_CAPACITY must be positive")
+		}
+		if c.WriteBufferBatchSize <= 0 || c.WriteBufferBatchSize > c.WriteBufferCapacity {
+			return fmt.Errorf("WRITE_BUFFER_BATCH_SIZE must be between 1 and WRITE_BUFFER_CAPACITY")
+		}
+		if c.WriteBufferFlushInterval <= 0 {
+			return fmt.Errorf("WRITE_BUFFER_FLUSH_INTERVAL must be positive")
+		}
+		switch c.WriteBufferOverflow {
+		case "reject", "block", "drop_oldest":
+		default:
+			return fmt.Errorf("WRITE_BUFFER_OVERFLOW must be one of reject, block, drop_oldest")
+		}
+	}
+	if len(c.RetentionPolicies) > 0 {
+		if c.RetentionInterval <= 0 {
+			return fmt.Errorf("RETENTION_INTERVAL must be positive")
+		}
+		if c.RetentionBatchSize <= 0 {
+			return fmt.Errorf("RETENTION_BATCH_SIZE must be positive")
+		}
+	}
+	if c.RollupEnabled {
+		if c.RollupInterval <= 0 {
+			return fmt.Errorf("ROLLUP_INTERVAL must be positive")
+		}
+		if c.RollupLookback <= 0 {
+			return fmt.Errorf("ROLLUP_LOOKBACK must be positive")
+		}
+	}
+	if c.ArchiveEnabled {
+		if c.ArchiveS3Endpoint == "" || c.ArchiveS3Bucket == "" {
+			return fmt.Errorf("ARCHIVE_S3_ENDPOINT and ARCHIVE_S3_BUCKET are required when ARCHIVE_ENABLED is set")
+		}
+		if c.ArchiveS3AccessKeyID == "" || c.ArchiveS3SecretAccessKey == "" {
+			return fmt.Errorf("ARCHIVE_S3_ACCESS_KEY_ID and ARCHIVE_S3_SECRET_ACCESS_KEY are required when ARCHIVE_ENABLED is set")
+		}
+	}
+	if c.OutboxEnabled() {
+		for _, webhookURL := range c.OutboxWebhookURLs {
+			parsed, err := url.Parse(webhookURL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return fmt.Errorf("OUTBOX_WEBHOOK_URLS must contain absolute http(s) URLs")
+			}
+		}
+		if c.OutboxPollInterval <= 0 {
+			return fmt.Errorf("OUTBOX_POLL_INTERVAL must be positive")
+		}
+		if c.OutboxBatchSize <= 0 {
+			return fmt.Errorf("OUTBOX_BATCH_SIZE must be positive")
+		}
+		if c.OutboxMaxAttempts <= 0 {
+			return fmt.Errorf("OUTBOX_MAX_ATTEMPTS must be positive")
+		}
+	}
+	if c.WebhookFormat != "native" && c.WebhookFormat != "ocsf" {
+		return fmt.Errorf("WEBHOOK_FORMAT must be one of native, ocsf")
+	}
+	if c.AnomalyDetectionEnabled {
+		if c.AnomalyWindow <= 0 {
+			return fmt.Errorf("ANOMALY_WINDOW must be positive")
+		}
+		if c.AnomalyDeletionThreshold < 0 || c.AnomalyExportThreshold < 0 {
+			return fmt.Errorf("ANOMALY_DELETION_THRESHOLD and ANOMALY_EXPORT_THRESHOLD must not be negative")
+		}
+		if c.AnomalyWebhookURL != "" {
+			parsed, err := url.Parse(c.AnomalyWebhookURL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return fmt.Errorf("ANOMALY_WEBHOOK_URL must be an absolute http(s) URL")
+			}
+		}
+	}
+	if c.NotificationsEnabled() {
+		switch c.NotifySink {
+		case "webhook":
+			parsed, err := url.Parse(c.NotifyWebhookURL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return fmt.Errorf("NOTIFY_WEBHOOK_URL must be an absolute http(s) URL when NOTIFY_SINK is webhook")
+			}
+		case "supabase":
+			if c.SupabaseURL == "" || c.SupabaseServiceRoleKey == "" {
+				return fmt.Errorf("SUPABASE_URL and SUPABASE_SERVICE_ROLE_KEY are required when NOTIFY_SINK is supabase")
+			}
+			if c.NotifyTable == "" {
+				return fmt.Errorf("NOTIFY_TABLE is required when NOTIFY_SINK is supabase")
+			}
+		default:
+			return fmt.Errorf("NOTIFY_SINK must be one of webhook, supabase")
+		}
+		if c.NotifyDigestInterval <= 0 {
+			return fmt.Errorf("NOTIFY_DIGEST_INTERVAL must be positive")
+		}
+		if c.NotifyDigestMaxEvents <= 0 {
+			return fmt.Errorf("NOTIFY_DIGEST_MAX_EVENTS must be positive")
+		}
+	}
+	if c.SIEMEnabled() {
+		if _, _, err := net.SplitHostPort(c.SIEMSyslogAddress); err != nil {
+			return fmt.Errorf("SIEM_SYSLOG_ADDRESS must be a host:port address")
+		}
+		if c.SIEMSyslogNetwork != "udp" && c.SIEMSyslogNetwork != "tcp" && c.SIEMSyslogNetwork != "tls" {
+			return fmt.Errorf("SIEM_SYSLOG_NETWORK must be one of udp, tcp, tls")
+		}
+		if c.SIEMFormat != "cef" && c.SIEMFormat != "leef" {
+			return fmt.Errorf("SIEM_FORMAT must be one of cef, leef")
+		}
+	}
+	if c.BruteForceEnabled {
+		if c.BruteForceThreshold <= 0 {
+			return fmt.Errorf("BRUTE_FORCE_THRESHOLD must be positive")
+		}
+		if c.BruteForceWindow <= 0 || c.BruteForceLockout <= 0 {
+			return fmt.Errorf("BRUTE_FORCE_WINDOW and BRUTE_FORCE_LOCKOUT must be positive")
+		}
+		if c.BruteForceMaxLockout < c.BruteForceLockout {
+			return fmt.Errorf("BRUTE_FORCE_MAX_LOCKOUT must not be shorter than BRUTE_FORCE_LOCKOUT")
+		}
+	}
+	if c.ReportsEnabled {
+		if c.ReportsPollInterval <= 0 {
+			return fmt.Errorf("REPORTS_POLL_INTERVAL must be positive")
+		}
+		if c.ReportsMaxEvents <= 0 {
+			return fmt.Errorf("REPORTS_MAX_EVENTS must be positive")
+		}
+	}
+	if c.ImportMaxFileSizeMB <= 0 {
+		return fmt.Errorf("IMPORT_MAX_FILE_SIZE_MB must be positive")
+	}
+	if c.OutboxEnabled() {
+		if c.ReplayMaxRate <= 0 {
+			return fmt.Errorf("REPLAY_MAX_RATE must be positive")
+		}
+		if c.ReplayMaxEvents <= 0 {
+			return fmt.Errorf("REPLAY_MAX_EVENTS must be positive")
+		}
+	}
+	if c.RealtimeEnabled {
+		parsed, err := url.Parse(c.SupabaseURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("SUPABASE_URL must be an absolute http(s) URL when REALTIME_ENABLED is set")
+		}
+		if c.SupabaseServiceRoleKey == "" {
+			return fmt.Errorf("SUPABASE_SERVICE_ROLE_KEY is required when REALTIME_ENABLED is set")
+		}
+		if c.RealtimeMatchWindow < 0 {
+			return fmt.Errorf("REALTIME_MATCH_WINDOW must not be negative")
+		}
+	}
+	if c.SecretsManagerEnabled() {
+		if err := c.validateSecretsManager(); err != nil {
+			return err
+		}
+	}
+	if c.DetailsEncryptionEnabled() {
+		switch c.DetailsEncryptionProvider {
+		case "env":
+			if len(c.DetailsEncryptionKeys) == 0 {
+				return fmt.Errorf("DETAILS_ENCRYPTION_KEYS is required when DETAILS_ENCRYPTION_PROVIDER is env")
+			}
+		case "kms":
+			if c.DetailsEncryptionKMSKeyID == "" || c.DetailsEncryptionKMSRegion == "" {
+				return fmt.Errorf("DETAILS_ENCRYPTION_KMS_KEY_ID and DETAILS_ENCRYPTION_KMS_REGION are required when DETAILS_ENCRYPTION_PROVIDER is kms")
+			}
+			if c.DetailsEncryptionKMSAccessKeyID == "" || c.DetailsEncryptionKMSSecretAccessKey == "" {
+				return fmt.Errorf("DETAILS_ENCRYPTION_KMS_ACCESS_KEY_ID and DETAILS_ENCRYPTION_KMS_SECRET_ACCESS_KEY are required when DETAILS_ENCRYPTION_PROVIDER is kms")
+			}
+		default:
+			return fmt.Errorf("DETAILS_ENCRYPTION_PROVIDER must be one of env, kms")
+		}
+		if c.DetailsEncryptionDataKeyTTL <= 0 {
+			return fmt.Errorf("DETAILS_ENCRYPTION_DATA_KEY_TTL must be positive")
+		}
+	}
+	return nil
+}
+
+// validateSecretsManager checks the settings of the secrets manager, which Read needs before
+// it can read the credentials
+func (c *Config) validateSecretsManager() error {
+	switch c.SecretsProvider {
+	case "vault":
+		if c.VaultAddr == "" || c.VaultToken == "" {
+			return fmt.Errorf("VAULT_ADDR and VAULT_TOKEN are required when SECRETS_PROVIDER is vault")
+		}
+	case "aws":
+		if c.SecretsAWSRegion == "" {
+			return fmt.Errorf("SECRETS_AWS_REGION is required when SECRETS_PROVIDER is aws")
+		}
+		if c.SecretsAWSAccessKeyID == "" || c.SecretsAWSSecretAccessKey == "" {
+			return fmt.Errorf("SECRETS_AWS_ACCESS_KEY_ID and SECRETS_AWS_SECRET_ACCESS_KEY are required when SECRETS_PROVIDER is aws")
+		}
+	default:
+		return fmt.Errorf("SECRETS_PROVIDER must be one of vault, aws")
+	}
+	if c.SecretsPath == "" {
+		return fmt.Errorf("SECRETS_PATH is required when SECRETS_PROVIDER is set")
+	}
+	if c.SecretsRefreshInterval < 0 {
+		return fmt.Errorf("SECRETS_REFRESH_INTERVAL must not be negative")
+	}
 	return nil
 }
 
+// SecretsManagerEnabled reports whether the Supabase credentials are read from a secrets manager
+func (c *Config) SecretsManagerEnabled() bool {
+	return c.SecretsProvider != ""
+}
+
+// NewSecretsProvider creates the client of the configured secrets manager
+func (c *Config) NewSecretsProvider(clk clock.Clock) secrets.Provider {
+	client := &http.Client{Timeout: c.HTTPTimeout}
+	if c.SecretsProvider == "aws" {
+		creds := secrets.AWSCredentials{
+			Credentials:  sigv4.Credentials{AccessKeyID: c.SecretsAWSAccessKeyID, SecretAccessKey: c.SecretsAWSSecretAccessKey},
+			SessionToken: c.SecretsAWSSessionToken,
+		}
+		return secrets.NewAWS(c.SecretsAWSEndpoint, c.SecretsAWSRegion, c.SecretsPath, creds, client, clk)
+	}
+	return secrets.NewVault(c.VaultAddr, c.VaultToken, c.SecretsPath, client)
+}
+
+// ApplySecrets takes the credentials of a secret read from the secrets manager; keys missing
+// from the secret keep their value from the environment
+func (c *Config) ApplySecrets(values map[string]string) {
+	c.ManagedSecrets = values
+	if key := values[SecretServiceRoleKey]; key != "" {
+		c.SupabaseServiceRoleKey = key
+	}
+	if secret := values[SecretJWTSecret]; secret != "" {
+		c.SupabaseJWTSecret = secret
+	}
+}
+
+// DetailsEncryptionEnabled reports whether the details of some event types are encrypted at rest
+func (c *Config) DetailsEncryptionEnabled() bool {
+	return len(c.DetailsEncryptionTypes) > 0
+}
+
+// SessionCacheEnabled reports whether session owners are cached for access checks
+func (c *Config) SessionCacheEnabled() bool {
+	return c.SessionCacheSize > 0
+}
+
+// OperationTimeout bounds one storage operation of a request: every attempt the Supabase retry
+// policy allows, each within HTTPTimeout, and the delays between them
+func (c *Config) OperationTimeout() time.Duration {
+	attempts := max(c.SupabaseRetryMaxAttempts, 1)
+	return c.HTTPTimeout*time.Duration(attempts) + c.SupabaseRetryMaxDelay*time.Duration(attempts-1)
+}
+
+// QueryCacheEnabled reports whether history pages and stats are cached
+func (c *Config) QueryCacheEnabled() bool {
+	return c.QueryCacheSize > 0
+}
+
+// ServesWrites reports whether this deployment ingests events and runs the write side workers
+func (c *Config) ServesWrites() bool {
+	return c.ServiceRole != RoleReader
+}
+
+// ServesReads reports whether this deployment serves the history queries and exports
+func (c *Config) ServesReads() bool {
+	return c.ServiceRole != RoleWriter
+}
+
+// OutboxEnabled reports whether stored events are forwarded through the outbox
+func (c *Config) OutboxEnabled() bool {
+	return len(c.OutboxWebhookURLs) > 0
+}
+
+// ExportCallbacksEnabled reports whether the export worker may report export steps
+func (c *Config) ExportCallbacksEnabled() bool {
+	return c.ExportCallbackSecret != ""
+}
+
+// InternalMTLSEnabled reports whether the internal listener requires client certificates
+func (c *Config) InternalMTLSEnabled() bool {
+	return c.InternalTLSCertFile != ""
+}
+
+// NotificationsEnabled reports whether events of watched sessions are forwarded to a notification sink
+func (c *Config) NotificationsEnabled() bool {
+	return c.NotifySink != ""
+}
+
+// SIEMEnabled reports whether security events are exported to a syslog endpoint
+func (c *Config) SIEMEnabled() bool {
+	return c.SIEMSyslogAddress != ""
+}
+
+// ReportStorageEnabled reports whether scheduled reports can be uploaded to Supabase Storage
+func (c *Config) ReportStorageEnabled() bool {
+	return c.SupabaseURL != "" && c.SupabaseServiceRoleKey != "" && c.ReportsStorageBucket != ""
+}
+
 // GetSupabaseHeaders returns the required headers for Supabase REST API calls
 func (c *Config) GetSupabaseHeaders() map[string]string {
 	return map[string]string{
 		"apikey":        c.SupabaseServiceRoleKey,
 		"Authorization": "Bearer " + c.SupabaseServiceRoleKey,
 		"Content-Type":  "application/json",
-		"Prefer":        "count=exact",
 	}
 }
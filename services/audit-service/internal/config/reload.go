@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/joho/godotenv"
+	"github.com/spf13/viper"
+)
+
+var (
+	processEnvOnce sync.Once
+	// processEnv holds the variables set before any .env file was read
+	processEnv map[string]bool
+
+	envFileMu sync.Mutex
+	// envFile is the .env file read by Load and envFileKeys the variables it set
+	envFile     string
+	envFileKeys map[string]bool
+)
+
+// snapshotProcessEnv records which variables the process environment sets, the first time only
+func snapshotProcessEnv() {
+	processEnvOnce.Do(func() {
+		processEnv = make(map[string]bool)
+		for _, variable := range os.Environ() {
+			for i := 0; i < len(variable); i++ {
+				if variable[i] == '=' {
+					processEnv[variable[:i]] = true
+					break
+				}
+			}
+		}
+	})
+}
+
+// rememberEnvFile records the .env file Load read, so Reload reads the same one
+func rememberEnvFile(path string) {
+	envFileMu.Lock()
+	defer envFileMu.Unlock()
+	if envFile != "" {
+		return
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	envFile = path
+	if values, err := godotenv.Read(path); err == nil {
+		envFileKeys = make(map[string]bool, len(values))
+		for key := range values {
+			if !processEnv[key] {
+				envFileKeys[key] = true
+			}
+		}
+	}
+}
+
+// Reload reads the .env file again and loads the configuration. Values from the file replace
+// those it set before and variables removed from it are unset; variables of the process
+// environment always win, as they do at startup.
+func Reload() (*Config, error) {
+	envFileMu.Lock()
+	if envFile != "" {
+		values, err := godotenv.Read(envFile)
+		if err != nil {
+			envFileMu.Unlock()
+			return nil, fmt.Errorf("failed to read %s: %w", envFile, err)
+		}
+		for key := range envFileKeys {
+			if _, ok := values[key]; !ok {
+				os.Unsetenv(key)
+			}
+		}
+		envFileKeys = make(map[string]bool, len(values))
+		for key, value := range values {
+			if processEnv[key] {
+				continue
+			}
+			os.Setenv(key, value)
+			envFileKeys[key] = true
+		}
+	}
+	envFileMu.Unlock()
+
+	return Load()
+}
+
+// WatchEnvFile calls onChange whenever the .env file read at startup changes. It reports false
+// when no file was read and there is nothing to watch.
+func WatchEnvFile(onChange func()) bool {
+	if viper.ConfigFileUsed() == "" {
+		return false
+	}
+	viper.OnConfigChange(func(fsnotify.Event) { onChange() })
+	viper.WatchConfig()
+	return true
+}
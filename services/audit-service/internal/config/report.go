@@ -0,0 +1,130 @@
+package config
+
+import (
+	"encoding/base64"
+	"net/url"
+	"reflect"
+	"time"
+
+	"audit-service/internal/redact"
+	"audit-service/pkg/fieldcrypt"
+)
+
+// redactedValue replaces secrets in the configuration report
+const redactedValue = "[REDACTED]"
+
+// setOrNot describes a secret without revealing it
+func setOrNot(value string) string {
+	if value == "" {
+		return "not set"
+	}
+	return "set"
+}
+
+// Redacted returns the configuration keyed by field name for the config self-report, with the
+// values of fields tagged secret replaced. URLs tagged secret:"url" keep everything but their
+// password. Unset secrets are reported as empty so operators can tell them apart.
+func (c *Config) Redacted() map[string]interface{} {
+	report := make(map[string]interface{})
+	value := reflect.ValueOf(*c)
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		fieldValue := value.Field(i)
+
+		switch field.Tag.Get("secret") {
+		case "true":
+			if fieldValue.IsZero() || (fieldValue.Kind() == reflect.Slice && fieldValue.Len() == 0) {
+				report[field.Name] = ""
+			} else {
+				report[field.Name] = redactedValue
+			}
+		case "url":
+			report[field.Name] = redactURL(fieldValue.String())
+		default:
+			report[field.Name] = reportValue(fieldValue.Interface())
+		}
+	}
+	return report
+}
+
+// Secrets returns the configured secret values, so the logger can mask them wherever they appear
+func (c *Config) Secrets() []string {
+	var secrets []string
+	value := reflect.ValueOf(*c)
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		switch secret := value.Field(i).Interface().(type) {
+		case string:
+			if field.Tag.Get("secret") == "url" {
+				secret = urlPassword(secret)
+			} else if field.Tag.Get("secret") != "true" {
+				continue
+			}
+			if secret != "" {
+				secrets = append(secrets, secret)
+			}
+		case []fieldcrypt.Key:
+			// Keys are configured, and would be logged, in base64
+			for _, key := range secret {
+				secrets = append(secrets, base64.StdEncoding.EncodeToString(key.Secret))
+			}
+		}
+	}
+	return secrets
+}
+
+// reportValue converts values that do not encode readably as JSON
+func reportValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Duration:
+		return v.String()
+	case *time.Location:
+		if v == nil {
+			return ""
+		}
+		return v.String()
+	case map[string]time.Duration:
+		durations := make(map[string]string, len(v))
+		for key, duration := range v {
+			durations[key] = duration.String()
+		}
+		return durations
+	case []redact.Rule:
+		patterns := make(map[string]string, len(v))
+		for _, rule := range v {
+			patterns[rule.Name] = rule.Pattern.String()
+		}
+		return patterns
+	default:
+		return v
+	}
+}
+
+// redactURL hides the password of a connection URL; values that are not URLs are hidden entirely
+func redactURL(value string) string {
+	if value == "" {
+		return ""
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return redactedValue
+	}
+	// Postgres also accepts the password as a query parameter
+	if query := parsed.Query(); query.Has("password") {
+		query.Set("password", "xxxxx")
+		parsed.RawQuery = query.Encode()
+	}
+	return parsed.Redacted()
+}
+
+// urlPassword returns the password of a connection URL, if any
+func urlPassword(value string) string {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return ""
+	}
+	if password, ok := parsed.User.Password(); ok {
+		return password
+	}
+	return parsed.Query().Get("password")
+}
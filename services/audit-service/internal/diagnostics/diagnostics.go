@@ -0,0 +1,103 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"audit-service/pkg/clock"
+)
+
+// Stats is the runtime snapshot served by /debug/stats
+type Stats struct {
+	StartedAt     time.Time   `json:"startedAt"`
+	UptimeSeconds float64     `json:"uptimeSeconds"`
+	GoVersion     string      `json:"goVersion"`
+	NumCPU        int         `json:"numCpu"`
+	GOMAXPROCS    int         `json:"gomaxprocs"`
+	Goroutines    int         `json:"goroutines"`
+	Memory        MemoryStats `json:"memory"`
+	GC            GCStats     `json:"gc"`
+}
+
+// MemoryStats summarizes heap and runtime memory usage in bytes
+type MemoryStats struct {
+	HeapAlloc   uint64 `json:"heapAlloc"`
+	HeapInuse   uint64 `json:"heapInuse"`
+	HeapIdle    uint64 `json:"heapIdle"`
+	HeapObjects uint64 `json:"heapObjects"`
+	StackInuse  uint64 `json:"stackInuse"`
+	TotalAlloc  uint64 `json:"totalAlloc"`
+	Sys         uint64 `json:"sys"`
+}
+
+// GCStats summarizes garbage collector activity
+type GCStats struct {
+	NumGC            uint32     `json:"numGc"`
+	LastGC           *time.Time `json:"lastGc,omitempty"`
+	LastPauseMillis  float64    `json:"lastPauseMillis"`
+	PauseTotalMillis float64    `json:"pauseTotalMillis"`
+	NextGC           uint64     `json:"nextGc"`
+	CPUFraction      float64    `json:"cpuFraction"`
+}
+
+// NewHandler serves pprof profiles under /debug/pprof/, expvar under /debug/vars and a
+// runtime snapshot under /debug/stats. It must only be exposed on a private listener.
+func NewHandler(startedAt time.Time, clk clock.Clock) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	mux.HandleFunc("/debug/stats", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ReadStats(startedAt, clk.Now()))
+	})
+
+	return mux
+}
+
+// ReadStats collects the current runtime statistics
+func ReadStats(startedAt, now time.Time) Stats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	gc := GCStats{
+		NumGC:            mem.NumGC,
+		PauseTotalMillis: float64(mem.PauseTotalNs) / float64(time.Millisecond),
+		NextGC:           mem.NextGC,
+		CPUFraction:      mem.GCCPUFraction,
+	}
+	if mem.NumGC > 0 {
+		lastGC := time.Unix(0, int64(mem.LastGC)).UTC()
+		gc.LastGC = &lastGC
+		// PauseNs is a circular buffer; the most recent pause is at (NumGC+255)%256
+		gc.LastPauseMillis = float64(mem.PauseNs[(mem.NumGC+255)%256]) / float64(time.Millisecond)
+	}
+
+	return Stats{
+		StartedAt:     startedAt.UTC(),
+		UptimeSeconds: now.Sub(startedAt).Seconds(),
+		GoVersion:     runtime.Version(),
+		NumCPU:        runtime.NumCPU(),
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		Goroutines:    runtime.NumGoroutine(),
+		Memory: MemoryStats{
+			HeapAlloc:   mem.HeapAlloc,
+			HeapInuse:   mem.HeapInuse,
+			HeapIdle:    mem.HeapIdle,
+			HeapObjects: mem.HeapObjects,
+			StackInuse:  mem.StackInuse,
+			TotalAlloc:  mem.TotalAlloc,
+			Sys:         mem.Sys,
+		},
+		GC: gc,
+	}
+}
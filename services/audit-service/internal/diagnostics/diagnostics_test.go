@@ -0,0 +1,62 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Stats(t *testing.T) {
+	startedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	handler := NewHandler(startedAt, clock.NewFakeClock(startedAt.Add(90*time.Second)))
+
+	// Make sure there is a collection to report
+	runtime.GC()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/stats", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var stats Stats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, startedAt, stats.StartedAt)
+	assert.Equal(t, 90.0, stats.UptimeSeconds)
+	assert.Equal(t, runtime.Version(), stats.GoVersion)
+	assert.Positive(t, stats.Goroutines)
+	assert.Positive(t, stats.Memory.HeapAlloc)
+	assert.Positive(t, stats.GC.NumGC)
+	assert.NotNil(t, stats.GC.LastGC)
+}
+
+func TestHandler_ProfilesAndVars(t *testing.T) {
+	handler := NewHandler(time.Now(), clock.New())
+
+	tests := []struct {
+		path         string
+		expectedCode int
+	}{
+		{"/debug/pprof/", http.StatusOK},
+		{"/debug/pprof/goroutine?debug=1", http.StatusOK},
+		{"/debug/pprof/heap", http.StatusOK},
+		{"/debug/vars", http.StatusOK},
+		{"/debug/unknown", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
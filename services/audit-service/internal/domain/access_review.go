@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// AccessMethod is how a viewer was authenticated when opening a session
+type AccessMethod string
+
+// Access methods recorded in the details of view events
+const (
+	AccessJWT        AccessMethod = "jwt"
+	AccessShareToken AccessMethod = "share_token"
+	// AccessUnknown is reported for view events that do not record their access method
+	AccessUnknown AccessMethod = "unknown"
+)
+
+const (
+	// MaxAccessReviewEvents caps the view events read for one access review; the review is
+	// marked truncated when a session has more
+	MaxAccessReviewEvents = 100000
+
+	// maxAccessReviewIPs caps the distinct IP addresses listed per accessor
+	maxAccessReviewIPs = 10
+)
+
+// AccessReviewQuery restricts an access review to a time range; zero bounds are open
+type AccessReviewQuery struct {
+	// From and To bound the views, both inclusive
+	From time.Time
+	To   time.Time
+}
+
+// Validate ensures the range is ordered
+func (q AccessReviewQuery) Validate() error {
+	if !q.From.IsZero() && !q.To.IsZero() && q.From.After(q.To) {
+		return fmt.Errorf("%w: from must not be after to", ErrInvalidFilter)
+	}
+	return nil
+}
+
+// ViewDetails are the details of a view event read for access reviews
+type ViewDetails struct {
+	AccessMethod AccessMethod `json:"accessMethod"`
+	// ShareTokenID identifies the share link a session was opened through
+	ShareTokenID string `json:"shareTokenId"`
+	// Repeats is set on views identical views were collapsed into
+	Repeats *ViewRepeats `json:"repeats"`
+}
+
+// AccessReviewEntry is one accessor of a session: a user, through one access method and, for
+// share tokens, one share link
+type AccessReviewEntry struct {
+	UserID       string       `json:"userId" example:"550e8400-e29b-41d4-a716-446655440003"`
+	AccessMethod AccessMethod `json:"accessMethod" example:"share_token"`
+	ShareTokenID string       `json:"shareTokenId,omitempty" example:"share-91c2"`
+	Views        int          `json:"views" example:"7"`
+	FirstAccess  time.Time    `json:"firstAccess" example:"2024-01-02T09:12:00Z"`
+	LastAccess   time.Time    `json:"lastAccess" example:"2024-01-05T17:40:00Z"`
+	// IPAddresses lists the distinct client addresses of the views, at most 10
+	IPAddresses []string `json:"ipAddresses" example:"203.0.113.7"`
+}
+
+// AccessReview is a point-in-time snapshot of who accessed a session, how and when, built from
+// its view events for periodic access reviews
+type AccessReview struct {
+	SessionID string `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	// GeneratedAt is the time the snapshot was taken
+	GeneratedAt time.Time `json:"generatedAt" example:"2024-02-01T08:00:00Z"`
+	// From and To repeat the requested range; omitted when open
+	From       *time.Time `json:"from,omitempty" example:"2024-01-01T00:00:00Z"`
+	To         *time.Time `json:"to,omitempty" example:"2024-01-31T23:59:59Z"`
+	TotalViews int        `json:"totalViews" example:"23"`
+	// Users counts the distinct users among the accessors
+	Users int `json:"users" example:"4"`
+	// Accessors lists the accessors, the most recent first
+	Accessors []AccessReviewEntry `json:"accessors"`
+	// Truncated is set when the session had more than MaxAccessReviewEvents views in the range
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// AccessReviewTally collects view events into an access review
+type AccessReviewTally struct {
+	review    *AccessReview
+	accessors map[[3]string]*AccessReviewEntry
+}
+
+// NewAccessReviewTally starts the access review of a session over the query range
+func NewAccessReviewTally(sessionID string, query AccessReviewQuery) *AccessReviewTally {
+	review := &AccessReview{SessionID: sessionID}
+	if !query.From.IsZero() {
+		review.From = &query.From
+	}
+	if !query.To.IsZero() {
+		review.To = &query.To
+	}
+	return &AccessReviewTally{review: review, accessors: make(map[[3]string]*AccessReviewEntry)}
+}
+
+// Add counts a view event; entries of other types are skipped
+func (t *AccessReviewTally) Add(entry AuditEntry) {
+	if AuditAction(entry.Type) != ActionView {
+		return
+	}
+
+	// Views recorded without details, or before the access method was recorded, are unknown
+	details, _ := DecodeDetails[ViewDetails](entry)
+	switch details.AccessMethod {
+	case AccessJWT:
+		details.ShareTokenID = ""
+	case AccessShareToken:
+	default:
+		details = ViewDetails{AccessMethod: AccessUnknown}
+	}
+
+	key := [3]string{entry.UserID, string(details.AccessMethod), details.ShareTokenID}
+	accessor, ok := t.accessors[key]
+	if !ok {
+		accessor = &AccessReviewEntry{
+			UserID:       entry.UserID,
+			AccessMethod: details.AccessMethod,
+			ShareTokenID: details.ShareTokenID,
+			FirstAccess:  entry.Timestamp,
+			LastAccess:   entry.Timestamp,
+			IPAddresses:  []string{},
+		}
+		t.accessors[key] = accessor
+	}
+
+	// Collapsed views count as the views they stand for
+	repeats := ViewRepeatsOf(entry)
+	t.review.TotalViews += repeats.Count
+	accessor.Views += repeats.Count
+	if entry.Timestamp.Before(accessor.FirstAccess) {
+		accessor.FirstAccess = entry.Timestamp
+	}
+	if repeats.LastTimestamp.After(accessor.LastAccess) {
+		accessor.LastAccess = repeats.LastTimestamp
+	}
+	if entry.IPAddress != "" && len(accessor.IPAddresses) < maxAccessReviewIPs && !slices.Contains(accessor.IPAddresses, entry.IPAddress) {
+		accessor.IPAddresses = append(accessor.IPAddresses, entry.IPAddress)
+	}
+}
+
+// Report returns the access review of the views added, taken at generatedAt
+func (t *AccessReviewTally) Report(generatedAt time.Time) *AccessReview {
+	review := t.review
+	review.GeneratedAt = generatedAt
+	review.Accessors = make([]AccessReviewEntry, 0, len(t.accessors))
+	users := make(map[string]struct{})
+	for _, accessor := range t.accessors {
+		sort.Strings(accessor.IPAddresses)
+		review.Accessors = append(review.Accessors, *accessor)
+		users[accessor.UserID] = struct{}{}
+	}
+	review.Users = len(users)
+
+	sort.Slice(review.Accessors, func(i, j int) bool {
+		a, b := review.Accessors[i], review.Accessors[j]
+		if !a.LastAccess.Equal(b.LastAccess) {
+			return a.LastAccess.After(b.LastAccess)
+		}
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		if a.AccessMethod != b.AccessMethod {
+			return a.AccessMethod < b.AccessMethod
+		}
+		return a.ShareTokenID < b.ShareTokenID
+	})
+	return review
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessReviewTally(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	generatedAt := base.Add(24 * time.Hour)
+	view := func(userID string, hours int, ip, details string) AuditEntry {
+		return AuditEntry{Type: string(ActionView), UserID: userID, Timestamp: base.Add(time.Duration(hours) * time.Hour),
+			IPAddress: ip, Details: []byte(details)}
+	}
+
+	tally := NewAccessReviewTally("session-1", AccessReviewQuery{From: base})
+	for _, entry := range []AuditEntry{
+		view("user-1", 2, "203.0.113.7", `{"accessMethod":"jwt"}`),
+		view("user-1", 0, "203.0.113.9", `{"accessMethod":"jwt","shareTokenId":"ignored"}`),
+		view("user-1", 1, "203.0.113.7", `{"accessMethod":"jwt"}`),
+		view("reviewer-1", 5, "198.51.100.4", `{"accessMethod":"share_token","shareTokenId":"share-91c2"}`),
+		view("reviewer-1", 3, "198.51.100.4", `{"accessMethod":"share_token","shareTokenId":"share-77aa"}`),
+		// Views without an access method, or with one not supported, are unknown
+		view("user-2", 4, "", `{}`),
+		view("user-2", 4, "", `{"accessMethod":"password"}`),
+		{Type: string(ActionEdit), UserID: "user-3", Timestamp: base},
+	} {
+		tally.Add(entry)
+	}
+
+	review := tally.Report(generatedAt)
+	assert.Equal(t, "session-1", review.SessionID)
+	assert.Equal(t, generatedAt, review.GeneratedAt)
+	assert.Equal(t, &base, review.From)
+	assert.Nil(t, review.To)
+	assert.Equal(t, 7, review.TotalViews)
+	assert.Equal(t, 3, review.Users)
+
+	require.Len(t, review.Accessors, 4)
+	assert.Equal(t, AccessReviewEntry{UserID: "reviewer-1", AccessMethod: AccessShareToken, ShareTokenID: "share-91c2", Views: 1,
+		FirstAccess: base.Add(5 * time.Hour), LastAccess: base.Add(5 * time.Hour), IPAddresses: []string{"198.51.100.4"}}, review.Accessors[0])
+	assert.Equal(t, AccessReviewEntry{UserID: "user-2", AccessMethod: AccessUnknown, Views: 2,
+		FirstAccess: base.Add(4 * time.Hour), LastAccess: base.Add(4 * time.Hour), IPAddresses: []string{}}, review.Accessors[1])
+	assert.Equal(t, "share-77aa", review.Accessors[2].ShareTokenID)
+	assert.Equal(t, AccessReviewEntry{UserID: "user-1", AccessMethod: AccessJWT, Views: 3,
+		FirstAccess: base, LastAccess: base.Add(2 * time.Hour), IPAddresses: []string{"203.0.113.7", "203.0.113.9"}}, review.Accessors[3])
+}
+
+func TestAccessReviewQuery_Validate(t *testing.T) {
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, AccessReviewQuery{To: from}.Validate())
+	assert.ErrorIs(t, AccessReviewQuery{From: from, To: from.Add(-time.Hour)}.Validate(), ErrInvalidFilter)
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ActivityGranularity is the length of the buckets activity is rolled up into
+type ActivityGranularity string
+
+// Activity granularities
+const (
+	ActivityHour ActivityGranularity = "hour"
+	ActivityDay  ActivityGranularity = "day"
+)
+
+const (
+	// DefaultActivityBuckets is the number of buckets returned when a query sets no range
+	DefaultActivityBuckets = 30
+
+	// MaxActivityBuckets caps the buckets a single activity query may span
+	MaxActivityBuckets = 1000
+)
+
+// ErrInvalidActivityQuery is returned for activity queries with an unknown granularity or range
+var ErrInvalidActivityQuery = errors.New("invalid activity query")
+
+// Valid reports whether the granularity is supported
+func (g ActivityGranularity) Valid() bool {
+	return g == ActivityHour || g == ActivityDay
+}
+
+// Duration returns the length of a bucket
+func (g ActivityGranularity) Duration() time.Duration {
+	if g == ActivityHour {
+		return time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// Truncate returns the start of the UTC bucket containing t
+func (g ActivityGranularity) Truncate(t time.Time) time.Time {
+	return t.UTC().Truncate(g.Duration())
+}
+
+// ActivityQuery selects the rolled-up activity of a session
+type ActivityQuery struct {
+	Granularity ActivityGranularity
+	// From and To bound the bucket starts, From inclusive and To exclusive
+	From time.Time
+	To   time.Time
+	// UserID restricts the buckets to one user when set
+	UserID string
+}
+
+// Normalize defaults the granularity to days and an unset range to the last DefaultActivityBuckets
+// buckets before now, aligns the range to bucket boundaries and validates it
+func (q *ActivityQuery) Normalize(now time.Time) error {
+	if q.Granularity == "" {
+		q.Granularity = ActivityDay
+	}
+	if !q.Granularity.Valid() {
+		return fmt.Errorf("%w: granularity must be day or hour", ErrInvalidActivityQuery)
+	}
+
+	size := q.Granularity.Duration()
+	if q.To.IsZero() {
+		q.To = q.Granularity.Truncate(now).Add(size)
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-DefaultActivityBuckets * size)
+	}
+	q.From = q.Granularity.Truncate(q.From)
+	// A partial bucket at the end of the range is included
+	if to := q.Granularity.Truncate(q.To); to.Before(q.To) {
+		q.To = to.Add(size)
+	} else {
+		q.To = to
+	}
+
+	if !q.From.Before(q.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidActivityQuery)
+	}
+	if q.To.Sub(q.From) > MaxActivityBuckets*size {
+		return fmt.Errorf("%w: range must not exceed %d buckets", ErrInvalidActivityQuery, MaxActivityBuckets)
+	}
+	return nil
+}
+
+// ActivityBucket counts the events a user recorded in a session during one bucket
+type ActivityBucket struct {
+	Start      time.Time      `json:"start" example:"2024-01-01T00:00:00Z"`
+	UserID     string         `json:"userId" example:"550e8400-e29b-41d4-a716-446655440003"`
+	EventCount int            `json:"eventCount" example:"42"`
+	ByType     map[string]int `json:"byType"`
+}
+
+// SessionActivity is the rolled-up activity of a session, buckets ordered by start and user
+type SessionActivity struct {
+	SessionID   string              `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Granularity ActivityGranularity `json:"granularity" example:"day"`
+	From        time.Time           `json:"from" example:"2024-01-01T00:00:00Z"`
+	To          time.Time           `json:"to" example:"2024-01-31T00:00:00Z"`
+	Buckets     []ActivityBucket    `json:"buckets"`
+}
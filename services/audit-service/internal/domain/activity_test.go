@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivityQuery_Normalize(t *testing.T) {
+	now := time.Date(2024, 3, 10, 14, 25, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name        string
+		query       ActivityQuery
+		expected    ActivityQuery
+		expectedErr bool
+	}{
+		{
+			name:     "defaults to the last days",
+			query:    ActivityQuery{},
+			expected: ActivityQuery{Granularity: ActivityDay, From: day(11).AddDate(0, 0, -30), To: day(11)},
+		},
+		{
+			name:  "defaults to the last hours",
+			query: ActivityQuery{Granularity: ActivityHour},
+			expected: ActivityQuery{
+				Granularity: ActivityHour,
+				From:        time.Date(2024, 3, 9, 9, 0, 0, 0, time.UTC),
+				To:          time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "aligns the range to buckets",
+			query: ActivityQuery{
+				Granularity: ActivityDay,
+				From:        time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC),
+				To:          time.Date(2024, 3, 5, 8, 0, 0, 0, time.UTC),
+			},
+			expected: ActivityQuery{Granularity: ActivityDay, From: day(2), To: day(6)},
+		},
+		{
+			name:     "keeps aligned ends",
+			query:    ActivityQuery{Granularity: ActivityDay, From: day(2), To: day(5), UserID: "user-1"},
+			expected: ActivityQuery{Granularity: ActivityDay, From: day(2), To: day(5), UserID: "user-1"},
+		},
+		{name: "unknown granularity", query: ActivityQuery{Granularity: "week"}, expectedErr: true},
+		{name: "from after to", query: ActivityQuery{From: day(5), To: day(2)}, expectedErr: true},
+		{
+			name:        "too many buckets",
+			query:       ActivityQuery{Granularity: ActivityHour, From: day(1).AddDate(0, -2, 0), To: day(1)},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			err := query.Normalize(now)
+			if tt.expectedErr {
+				assert.ErrorIs(t, err, ErrInvalidActivityQuery)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, query)
+		})
+	}
+}
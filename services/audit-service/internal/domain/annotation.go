@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxAnnotationLength is the longest accepted annotation body, in characters
+const MaxAnnotationLength = 4000
+
+var (
+	// ErrAnnotationNotFound is returned for annotations that do not exist or belong to another event
+	ErrAnnotationNotFound = errors.New("annotation not found")
+	// ErrInvalidAnnotation is returned for empty or too long annotation bodies
+	ErrInvalidAnnotation = errors.New("invalid annotation")
+)
+
+// EventAnnotation is a note a reviewer attaches to an audit event, such as a finding of a security
+// investigation. The annotations of an event form its thread; they are stored beside the event,
+// which stays unchanged, and are versioned: each edit moves an annotation to the next version.
+type EventAnnotation struct {
+	ID        string `json:"id" example:"6f1b2c3d-4e5f-4a57-9d0e-0b8f5e0c3c1d"`
+	EventID   string `json:"eventId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	SessionID string `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	AuthorID  string `json:"authorId" example:"550e8400-e29b-41d4-a716-446655440003"`
+	Body      string `json:"body" example:"Export made from an unrecognized IP; confirmed with the owner"`
+	// Version counts the edits of the annotation, starting at 1
+	Version   int64     `json:"version" example:"1"`
+	CreatedAt time.Time `json:"createdAt" example:"2024-01-02T10:00:00Z"`
+	UpdatedAt time.Time `json:"updatedAt" example:"2024-01-02T10:00:00Z"`
+	// OrganizationID is the organization of the event; empty for the default organization
+	OrganizationID string `json:"organizationId,omitempty" example:"acme"`
+}
+
+// Validate checks that the body of an annotation is not blank and at most MaxAnnotationLength
+// characters long
+func (a EventAnnotation) Validate() error {
+	if strings.TrimSpace(a.Body) == "" {
+		return fmt.Errorf("%w: body must not be empty", ErrInvalidAnnotation)
+	}
+	if utf8.RuneCountInString(a.Body) > MaxAnnotationLength {
+		return fmt.Errorf("%w: body must have at most %d characters", ErrInvalidAnnotation, MaxAnnotationLength)
+	}
+	return nil
+}
+
+// AnnotationThread is the thread of annotations of an audit event, oldest first
+type AnnotationThread struct {
+	EventID   string            `json:"eventId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	SessionID string            `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Items     []EventAnnotation `json:"items"`
+}
+
+// AnnotationDetails are the details of annotation_created, annotation_edited and
+// annotation_deleted events, which record who annotated what. They are read by the session owner
+// with the rest of the session history, so they leave out the body of the annotation.
+type AnnotationDetails struct {
+	AnnotationID string `json:"annotationId"`
+	EventID      string `json:"eventId"`
+	Version      int64  `json:"version"`
+}
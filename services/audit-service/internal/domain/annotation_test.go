@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventAnnotation_Validate(t *testing.T) {
+	assert.NoError(t, EventAnnotation{Body: "Export made from an unrecognized IP"}.Validate())
+	// The limit counts characters, not bytes
+	assert.NoError(t, EventAnnotation{Body: strings.Repeat("é", MaxAnnotationLength)}.Validate())
+
+	for name, body := range map[string]string{
+		"empty": "",
+		"blank": " \n\t",
+		"long":  strings.Repeat("a", MaxAnnotationLength+1),
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, EventAnnotation{Body: body}.Validate(), ErrInvalidAnnotation)
+		})
+	}
+}
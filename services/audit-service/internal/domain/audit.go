@@ -1,28 +1,16 @@
 package domain
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 )
 
-// CreateEventRequest represents the request to create a new audit event
-type CreateEventRequest struct {
-	SessionID string                 `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440001" binding:"required"`
-	Type      string                 `json:"type" example:"edit" binding:"required"`
-	Details   map[string]interface{} `json:"details,omitempty" swaggertype:"object"`
-}
-
-// AuditEvent represents an event in the audit log
-type AuditEvent struct {
-	ID        string                 `json:"id"`
-	SessionID string                 `json:"sessionId"`
-	UserID    string                 `json:"userId"`
-	Type      string                 `json:"type"`
-	Details   map[string]interface{} `json:"details,omitempty"`
-	Timestamp time.Time              `json:"timestamp"`
-}
-
-// AuditEntry represents a single audit log entry
+// AuditEntry is the one model of an audit event: it is what the handlers build from requests,
+// what the storage backends persist and return, and what workers and reports consume. Details
+// holds the JSON of the event details; EncodeDetails and DecodeDetails convert typed details.
 type AuditEntry struct {
 	ID        string          `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
 	SessionID string          `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440001"`
@@ -32,12 +20,55 @@ type AuditEntry struct {
 	Details   json.RawMessage `json:"details,omitempty" swaggertype:"object"`
 	IPAddress string          `json:"ipAddress,omitempty" example:"192.168.1.1"`
 	UserAgent string          `json:"userAgent,omitempty" example:"Mozilla/5.0"`
+	// ReceivedAt is the server time the event arrived at, while Timestamp is the client time it
+	// happened at; nil for entries the service wrote itself and entries stored before it existed
+	ReceivedAt *time.Time `json:"receivedAt,omitempty" example:"2023-12-01T10:30:02Z"`
+	// CorrelationID groups the events of one operation, e.g. an export from request to download
+	CorrelationID string `json:"correlationId,omitempty" example:"export-7f3a"`
+	// ParentEventID references the event that caused this one
+	ParentEventID string `json:"parentEventId,omitempty" example:"550e8400-e29b-41d4-a716-446655440003"`
+	// SchemaVersion is the version of the action's details schema; zero for entries stored before versioning
+	SchemaVersion int `json:"schemaVersion,omitempty" example:"2"`
+	// RedactedFields lists the paths of details values masked on ingestion, e.g. shapes[0].text
+	RedactedFields []string `json:"redactedFields,omitempty" example:"after"`
+	// SlideID and ShapeID repeat details.slideId and details.shapeId as indexed columns; they are
+	// not hashed separately since the details are
+	SlideID string `json:"slideId,omitempty" example:"slide-3"`
+	ShapeID string `json:"shapeId,omitempty" example:"shape-12"`
+	// CommentID and ThreadID repeat details.commentId and details.threadId as indexed columns
+	CommentID string `json:"commentId,omitempty" example:"comment-7"`
+	ThreadID  string `json:"threadId,omitempty" example:"thread-2"`
+	// OrganizationID is the tenant the entry belongs to; empty for the default organization
+	OrganizationID string `json:"organizationId,omitempty" example:"acme"`
+	// Category and Severity classify the entry's type in the taxonomy; they are set on reads
+	// and not stored
+	Category EventCategory `json:"category,omitempty" example:"content"`
+	Severity EntrySeverity `json:"severity,omitempty" example:"info"`
 }
 
 // AuditResponse represents the paginated audit log response
 type AuditResponse struct {
 	TotalCount int          `json:"totalCount" example:"42"`
 	Items      []AuditEntry `json:"items"`
+	NextCursor string       `json:"nextCursor,omitempty" example:"MjAyMy0xMi0wMVQxMDozMDowMFp8NTUwZTg0MDA"`
+}
+
+// SessionStats summarizes the audit activity of a session
+type SessionStats struct {
+	SessionID     string         `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	TotalEvents   int            `json:"totalEvents" example:"42"`
+	Contributors  int            `json:"contributors" example:"3"`
+	FirstActivity *time.Time     `json:"firstActivity,omitempty" example:"2023-12-01T10:30:00Z"`
+	LastActivity  *time.Time     `json:"lastActivity,omitempty" example:"2023-12-02T16:45:00Z"`
+	ByType        map[string]int `json:"byType"`
+	EditsPerSlide map[string]int `json:"editsPerSlide"`
+}
+
+// PurgedEvents counts the events of a session removed by the retention policy
+type PurgedEvents struct {
+	SessionID      string `json:"session_id"`
+	OrganizationID string `json:"organization_id,omitempty"`
+	Deleted        int    `json:"deleted"`
 }
 
 // AuditAction represents the type of action performed
@@ -54,12 +85,68 @@ const (
 	ActionShare   AuditAction = "share"
 	ActionUnshare AuditAction = "unshare"
 	ActionView    AuditAction = "view"
+
+	// Share link lifecycle actions recorded by the audit service when a share token is presented:
+	// redeemed when it granted access, expired when its link had expired. Like share and unshare
+	// events, they are correlated by the token ID of the share link.
+	ActionShareRedeemed AuditAction = "share_redeemed"
+	ActionShareExpired  AuditAction = "share_expired"
+
+	// ActionThumbnail records a slide thumbnail generated by the processor service
+	ActionThumbnail AuditAction = "thumbnail"
+	// ActionExportLifecycle records a step of an export reported by the export worker, correlated
+	// with the export event by the export ID
+	ActionExportLifecycle AuditAction = "export_lifecycle"
+
+	// Comment lifecycle actions, recorded with the commentId and threadId of the comment
+	ActionCommentCreated  AuditAction = "comment_created"
+	ActionCommentEdited   AuditAction = "comment_edited"
+	ActionCommentResolved AuditAction = "comment_resolved"
+	ActionCommentDeleted  AuditAction = "comment_deleted"
+
+	// Annotation lifecycle actions recorded by the audit service when a reviewer annotates an
+	// event, with the event as parent and the annotation ID as correlation ID
+	ActionAnnotationCreated AuditAction = "annotation_created"
+	ActionAnnotationEdited  AuditAction = "annotation_edited"
+	ActionAnnotationDeleted AuditAction = "annotation_deleted"
+
+	// Review workflow actions, recorded with the slideId, and shapeId when one shape is reviewed,
+	// and validated against the review state of the slide or shape
+	ActionSubmittedForReview AuditAction = "submitted_for_review"
+	ActionApproved           AuditAction = "approved"
+	ActionRejected           AuditAction = "rejected"
+	ActionReopened           AuditAction = "reopened"
+
+	// Translation memory actions, recorded with the source text of the segment and the TM match
+	// score, and counted in the TM leverage of a session
+	ActionTMHit      AuditAction = "tm_hit"
+	ActionTMOverride AuditAction = "tm_override"
+
+	// ActionMTRequest records a call of the translation pipeline to a machine translation
+	// provider, with its character counts, latency and cost estimate
+	ActionMTRequest AuditAction = "mt_request"
+
+	// ActionRetentionPurge summarizes events removed by the retention policy
+	ActionRetentionPurge AuditAction = "retention_purge"
+	// ActionUserErasure records that a user's entries were anonymized or deleted in the session
+	ActionUserErasure AuditAction = "user_erasure"
+	// ActionSecurityAlert records suspicious activity flagged by the anomaly detector
+	ActionSecurityAlert AuditAction = "security_alert"
+	// ActionExternalChange records a change to session data made outside the API
+	ActionExternalChange AuditAction = "external_change"
+	// ActionConfigChanged records configuration changes applied while the service runs
+	ActionConfigChanged AuditAction = "config_changed"
+	// ActionAuthFailure records a request rejected by authentication or authorization, with the
+	// reason it was rejected for
+	ActionAuthFailure AuditAction = "auth_failure"
 )
 
 // PaginationParams defines pagination parameters
 type PaginationParams struct {
 	Limit  int
 	Offset int
+	// Cursor continues after the given entry and takes precedence over Offset
+	Cursor *Cursor
 }
 
 // Validate ensures pagination parameters are within acceptable ranges
@@ -73,4 +160,146 @@ func (p *PaginationParams) Validate() {
 	if p.Offset < 0 {
 		p.Offset = 0 // Minimum offset
 	}
+
+	if p.Cursor != nil {
+		p.Offset = 0 // Keyset pagination does not skip rows
+	}
+}
+
+// Cursor identifies a position in the audit log ordered by timestamp and ID
+type Cursor struct {
+	Timestamp time.Time
+	ID        string
+}
+
+// NewCursor creates a cursor positioned at the given entry
+func NewCursor(entry AuditEntry) *Cursor {
+	return &Cursor{
+		Timestamp: entry.Timestamp.UTC(),
+		ID:        entry.ID,
+	}
+}
+
+// Encode returns the opaque string representation of the cursor
+func (c *Cursor) Encode() string {
+	raw := c.Timestamp.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor produced by Encode
+func DecodeCursor(value string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed encoding", ErrInvalidCursor)
+	}
+
+	timestamp, id, found := strings.Cut(string(raw), "|")
+	if !found || id == "" {
+		return nil, fmt.Errorf("%w: missing entry ID", ErrInvalidCursor)
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid timestamp", ErrInvalidCursor)
+	}
+
+	return &Cursor{
+		Timestamp: parsed.UTC(),
+		ID:        id,
+	}, nil
+}
+
+// MaxCorrelationIDLength limits the correlation IDs accepted from clients
+const MaxCorrelationIDLength = 128
+
+// ValidCorrelationID reports whether id is usable as a correlation ID: at most
+// MaxCorrelationIDLength printable ASCII characters without spaces. Empty IDs are valid.
+func ValidCorrelationID(id string) bool {
+	if len(id) > MaxCorrelationIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// EventChain lists the causally related events of an operation in time order
+type EventChain struct {
+	EventID       string       `json:"eventId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CorrelationID string       `json:"correlationId" example:"export-7f3a"`
+	Items         []AuditEntry `json:"items"`
+}
+
+// maxSearchLength limits the free-text search term accepted by event queries
+const maxSearchLength = 200
+
+// EventFilter narrows down the audit entries returned by event queries
+type EventFilter struct {
+	Types  []string
+	UserID string
+	From   time.Time
+	To     time.Time
+	Search string
+	// CorrelationID restricts the results to one causal chain
+	CorrelationID string
+	// SlideID and ShapeID restrict the results to the events of one slide or shape
+	SlideID string
+	ShapeID string
+	// CommentID and ThreadID restrict the results to the events of one comment or thread
+	CommentID string
+	ThreadID  string
+	// Categories and Severities restrict the results to the event types classified with them
+	Categories []EventCategory
+	Severities []EntrySeverity
+	// Labels restricts the results to the events carrying one of the labels
+	Labels []string
+}
+
+// Validate ensures the filter values are consistent
+func (f *EventFilter) Validate() error {
+	if !f.From.IsZero() && !f.To.IsZero() && f.From.After(f.To) {
+		return fmt.Errorf("%w: from must not be after to", ErrInvalidFilter)
+	}
+
+	if len(f.Search) > maxSearchLength {
+		return fmt.Errorf("%w: search term exceeds %d characters", ErrInvalidFilter, maxSearchLength)
+	}
+
+	if !ValidCorrelationID(f.CorrelationID) {
+		return fmt.Errorf("%w: invalid correlation ID", ErrInvalidFilter)
+	}
+
+	if len(f.SlideID) > MaxSlideRefLength || len(f.ShapeID) > MaxSlideRefLength {
+		return fmt.Errorf("%w: slide and shape IDs must not exceed %d characters", ErrInvalidFilter, MaxSlideRefLength)
+	}
+
+	if len(f.CommentID) > MaxCommentRefLength || len(f.ThreadID) > MaxCommentRefLength {
+		return fmt.Errorf("%w: comment and thread IDs must not exceed %d characters", ErrInvalidFilter, MaxCommentRefLength)
+	}
+
+	for _, category := range f.Categories {
+		if !category.Valid() {
+			return fmt.Errorf("%w: category must be content, access, security, system or usage", ErrInvalidFilter)
+		}
+	}
+
+	for _, severity := range f.Severities {
+		if !severity.Valid() {
+			return fmt.Errorf("%w: severity must be info, warning or critical", ErrInvalidFilter)
+		}
+	}
+
+	if len(f.Labels) > MaxEventLabels {
+		return fmt.Errorf("%w: at most %d labels can be filtered on", ErrInvalidFilter, MaxEventLabels)
+	}
+	for _, label := range f.Labels {
+		if !ValidLabel(label) {
+			return fmt.Errorf("%w: invalid label %q", ErrInvalidFilter, label)
+		}
+	}
+
+	return nil
 }
@@ -1,7 +1,9 @@
 package domain
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -63,6 +65,11 @@ func TestPaginationParams_Validate(t *testing.T) {
 			input:    PaginationParams{Limit: 25, Offset: 10},
 			expected: PaginationParams{Limit: 25, Offset: 10},
 		},
+		{
+			name:     "cursor resets offset",
+			input:    PaginationParams{Limit: 25, Offset: 10, Cursor: &Cursor{ID: "entry-1"}},
+			expected: PaginationParams{Limit: 25, Offset: 0, Cursor: &Cursor{ID: "entry-1"}},
+		},
 	}
 
 	for _, tt := range tests {
@@ -131,3 +138,139 @@ func TestAuditResponse_Structure(t *testing.T) {
 	assert.Equal(t, response.TotalCount, unmarshaled.TotalCount)
 	assert.Len(t, unmarshaled.Items, 2)
 }
+
+func TestEventFilter_Validate(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	tests := []struct {
+		name        string
+		filter      EventFilter
+		expectError bool
+	}{
+		{
+			name:   "empty filter",
+			filter: EventFilter{},
+		},
+		{
+			name:   "valid range",
+			filter: EventFilter{From: from, To: to, Types: []string{"edit"}},
+		},
+		{
+			name:   "open ended range",
+			filter: EventFilter{From: from},
+		},
+		{
+			name:        "inverted range",
+			filter:      EventFilter{From: to, To: from},
+			expectError: true,
+		},
+		{
+			name:        "search too long",
+			filter:      EventFilter{Search: strings.Repeat("a", maxSearchLength+1)},
+			expectError: true,
+		},
+		{
+			name:   "categories and severities",
+			filter: EventFilter{Categories: []EventCategory{CategoryAccess}, Severities: []EntrySeverity{EntrySeverityCritical}},
+		},
+		{
+			name:        "unknown category",
+			filter:      EventFilter{Categories: []EventCategory{"billing"}},
+			expectError: true,
+		},
+		{
+			name:        "unknown severity",
+			filter:      EventFilter{Severities: []EntrySeverity{"high"}},
+			expectError: true,
+		},
+		{
+			name:   "correlation ID",
+			filter: EventFilter{CorrelationID: "export-7f3a"},
+		},
+		{
+			name:        "correlation ID with spaces",
+			filter:      EventFilter{CorrelationID: "export 7f3a"},
+			expectError: true,
+		},
+		{
+			name:   "slide and shape",
+			filter: EventFilter{SlideID: "slide-1", ShapeID: "shape-2"},
+		},
+		{
+			name:   "comment and thread",
+			filter: EventFilter{CommentID: "comment-7", ThreadID: "thread-2"},
+		},
+		{
+			name:        "thread ID too long",
+			filter:      EventFilter{ThreadID: strings.Repeat("t", MaxCommentRefLength+1)},
+			expectError: true,
+		},
+		{
+			name:        "slide ID too long",
+			filter:      EventFilter{SlideID: strings.Repeat("s", MaxSlideRefLength+1)},
+			expectError: true,
+		},
+		{
+			name:   "labels",
+			filter: EventFilter{Labels: []string{"billing-relevant", "disputed"}},
+		},
+		{
+			name:        "label with spaces",
+			filter:      EventFilter{Labels: []string{"billing relevant"}},
+			expectError: true,
+		},
+		{
+			name:        "correlation ID too long",
+			filter:      EventFilter{CorrelationID: strings.Repeat("a", MaxCorrelationIDLength+1)},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if tt.expectError {
+				assert.ErrorIs(t, err, ErrInvalidFilter)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	entry := AuditEntry{
+		ID:        "550e8400-e29b-41d4-a716-446655440000",
+		Timestamp: time.Date(2024, 1, 1, 12, 30, 0, 123456789, time.FixedZone("UTC+3", 3*60*60)),
+	}
+
+	encoded := NewCursor(entry).Encode()
+	assert.NotContains(t, encoded, "|")
+
+	decoded, err := DecodeCursor(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, entry.ID, decoded.ID)
+	assert.True(t, entry.Timestamp.Equal(decoded.Timestamp))
+	assert.Equal(t, time.UTC, decoded.Timestamp.Location())
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "not base64", value: "!!!"},
+		{name: "missing separator", value: base64.RawURLEncoding.EncodeToString([]byte("2024-01-01T00:00:00Z"))},
+		{name: "missing id", value: base64.RawURLEncoding.EncodeToString([]byte("2024-01-01T00:00:00Z|"))},
+		{name: "invalid timestamp", value: base64.RawURLEncoding.EncodeToString([]byte("yesterday|entry-1"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := DecodeCursor(tt.value)
+			assert.Nil(t, cursor)
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"net/http"
+	"time"
+)
+
+// AuthFailureReason is why a request was rejected by authentication or authorization
+type AuthFailureReason string
+
+// Reasons of rejected requests, set by the auth middleware that rejected them
+const (
+	AuthReasonMissingToken      AuthFailureReason = "missing_token"
+	AuthReasonMalformedHeader   AuthFailureReason = "malformed_authorization_header"
+	AuthReasonInvalidToken      AuthFailureReason = "invalid_token"
+	AuthReasonExpiredToken      AuthFailureReason = "expired_token"
+	AuthReasonRevokedToken      AuthFailureReason = "revoked_token"
+	AuthReasonInvalidShareToken AuthFailureReason = "invalid_share_token"
+	// AuthReasonShareSessionMismatch is a share token presented for a session it was not issued for
+	AuthReasonShareSessionMismatch AuthFailureReason = "share_session_mismatch"
+	// AuthReasonShareNotActive is a share token of a revoked or deleted share link
+	AuthReasonShareNotActive AuthFailureReason = "share_not_active"
+	AuthReasonShareExpired   AuthFailureReason = "share_expired"
+	AuthReasonInvalidAPIKey  AuthFailureReason = "invalid_api_key"
+
+	// RBAC denials of authenticated requests
+	AuthReasonNotAdmin            AuthFailureReason = "not_admin"
+	AuthReasonNotReviewer         AuthFailureReason = "not_reviewer"
+	AuthReasonSharePermission     AuthFailureReason = "share_permission_denied"
+	AuthReasonAPIKeyScope         AuthFailureReason = "api_key_scope_denied"
+	AuthReasonSessionAccessDenied AuthFailureReason = "session_access_denied"
+
+	// AuthReasonUnauthenticated is a 401 without a more specific reason
+	AuthReasonUnauthenticated AuthFailureReason = "unauthenticated"
+)
+
+// DefaultAuthFailureReason is the reason of requests rejected without one set, such as a user
+// denied access to a session by the ownership check of a handler
+func DefaultAuthFailureReason(status int) AuthFailureReason {
+	if status == http.StatusForbidden {
+		return AuthReasonSessionAccessDenied
+	}
+	return AuthReasonUnauthenticated
+}
+
+// AuthFailure describes a request rejected for missing or invalid credentials (401) or for
+// lacking access to the requested resource (403)
+type AuthFailure struct {
+	Time   time.Time
+	Method string
+	// Route is the matched route pattern, e.g. /api/v1/sessions/:sessionId/history
+	Route     string
+	Status    int
+	Reason    AuthFailureReason
+	ClientIP  string
+	UserAgent string
+	// UserID is the authenticated user of requests denied after authentication
+	UserID    string
+	RequestID string
+}
+
+// AuthFailureDetails are the details of auth_failure events; the client IP and user agent are
+// recorded in the columns of the entry
+type AuthFailureDetails struct {
+	Reason    AuthFailureReason `json:"reason"`
+	Method    string            `json:"method"`
+	Route     string            `json:"route"`
+	Status    int               `json:"status"`
+	UserID    string            `json:"userId,omitempty"`
+	RequestID string            `json:"requestId,omitempty"`
+}
+
+// Details returns the details recorded for the failure
+func (f AuthFailure) Details() AuthFailureDetails {
+	return AuthFailureDetails{
+		Reason:    f.Reason,
+		Method:    f.Method,
+		Route:     f.Route,
+		Status:    f.Status,
+		UserID:    f.UserID,
+		RequestID: f.RequestID,
+	}
+}
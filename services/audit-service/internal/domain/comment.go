@@ -0,0 +1,39 @@
+package domain
+
+import "encoding/json"
+
+// MaxCommentRefLength limits the comment and thread IDs indexed from event details
+const MaxCommentRefLength = 255
+
+// CommentActions are the comment lifecycle events served to the comments panel, in lifecycle order
+var CommentActions = []AuditAction{ActionCommentCreated, ActionCommentEdited, ActionCommentResolved, ActionCommentDeleted}
+
+// IsCommentAction reports whether the event type is part of the comment lifecycle
+func IsCommentAction(eventType string) bool {
+	for _, action := range CommentActions {
+		if string(action) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// CommentRef returns the commentId and threadId of event details, which are stored as indexed
+// columns so the activity of a comment thread can be read without searching details. Both are
+// optional here, the comment lifecycle schemas require them; ok is false when either is present
+// but not a string of at most MaxCommentRefLength characters.
+func CommentRef(details json.RawMessage) (commentID, threadID string, ok bool) {
+	var refs map[string]json.RawMessage
+	// Details that are not an object carry no references
+	if json.Unmarshal(details, &refs) != nil {
+		return "", "", true
+	}
+
+	if commentID, ok = detailRef(refs["commentId"], MaxCommentRefLength); !ok {
+		return "", "", false
+	}
+	if threadID, ok = detailRef(refs["threadId"], MaxCommentRefLength); !ok {
+		return "", "", false
+	}
+	return commentID, threadID, true
+}
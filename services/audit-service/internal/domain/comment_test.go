@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommentRef(t *testing.T) {
+	tests := []struct {
+		name              string
+		details           string
+		expectedCommentID string
+		expectedThreadID  string
+		expectedOK        bool
+	}{
+		{name: "comment and thread", details: `{"commentId":"c-2","threadId":"t-1","text":"Hi"}`, expectedCommentID: "c-2", expectedThreadID: "t-1", expectedOK: true},
+		{name: "thread only", details: `{"threadId":"t-1"}`, expectedThreadID: "t-1", expectedOK: true},
+		{name: "none", details: `{"slideId":"slide-1"}`, expectedOK: true},
+		{name: "null", details: `{"commentId":null}`, expectedOK: true},
+		{name: "not an object", details: `"c-2"`, expectedOK: true},
+		{name: "numeric comment", details: `{"commentId":2,"threadId":"t-1"}`},
+		{name: "too long", details: `{"threadId":"` + strings.Repeat("t", MaxCommentRefLength+1) + `"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commentID, threadID, ok := CommentRef(json.RawMessage(tt.details))
+
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expectedCommentID, commentID)
+			assert.Equal(t, tt.expectedThreadID, threadID)
+		})
+	}
+}
+
+func TestIsCommentAction(t *testing.T) {
+	assert.True(t, IsCommentAction("comment_resolved"))
+	assert.False(t, IsCommentAction("comment"))
+	assert.False(t, IsCommentAction("edit"))
+}
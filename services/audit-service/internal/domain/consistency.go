@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidConsistencyToken is returned for consistency tokens that were not issued by the service
+	ErrInvalidConsistencyToken = errors.New("invalid consistency token")
+	// ErrWriteNotVisible is returned when the write of a consistency token is not readable in time
+	ErrWriteNotVisible = errors.New("write of the consistency token is not readable yet")
+)
+
+// ConsistencyToken identifies a write that reads made with the token must see. It names the
+// last event the write stored: the events of a write are stored in order, whether directly or
+// through the write buffer, so once that event is readable every event of the write is.
+type ConsistencyToken struct {
+	// EventID is the last event stored by the write
+	EventID string
+	// IssuedAt is when the write was accepted
+	IssuedAt time.Time
+}
+
+// NewConsistencyToken creates the token of a write storing entries, or nil when it stores none
+func NewConsistencyToken(entries []AuditEntry, issuedAt time.Time) *ConsistencyToken {
+	if len(entries) == 0 {
+		return nil
+	}
+	return &ConsistencyToken{
+		EventID:  entries[len(entries)-1].ID,
+		IssuedAt: issuedAt.UTC(),
+	}
+}
+
+// Encode returns the opaque string representation of the token
+func (t *ConsistencyToken) Encode() string {
+	raw := t.IssuedAt.UTC().Format(time.RFC3339Nano) + "|" + t.EventID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeConsistencyToken parses a token produced by Encode
+func DecodeConsistencyToken(value string) (*ConsistencyToken, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed encoding", ErrInvalidConsistencyToken)
+	}
+
+	issuedAt, eventID, found := strings.Cut(string(raw), "|")
+	if !found {
+		return nil, fmt.Errorf("%w: missing event ID", ErrInvalidConsistencyToken)
+	}
+	id, err := ParseEventID(eventID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid event ID", ErrInvalidConsistencyToken)
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, issuedAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid timestamp", ErrInvalidConsistencyToken)
+	}
+
+	return &ConsistencyToken{
+		EventID:  id.String(),
+		IssuedAt: parsed.UTC(),
+	}, nil
+}
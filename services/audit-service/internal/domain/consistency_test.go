@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsistencyToken_RoundTrip(t *testing.T) {
+	issuedAt := time.Date(2024, 1, 1, 12, 30, 0, 123456789, time.FixedZone("UTC+3", 3*60*60))
+	token := NewConsistencyToken([]AuditEntry{
+		{ID: "550e8400-e29b-41d4-a716-446655440000"},
+		{ID: "550E8400-E29B-41D4-A716-446655440001"},
+	}, issuedAt)
+
+	decoded, err := DecodeConsistencyToken(token.Encode())
+	require.NoError(t, err)
+	// The token names the last event of the write
+	assert.Equal(t, "550e8400-e29b-41d4-a716-446655440001", decoded.EventID)
+	assert.True(t, issuedAt.Equal(decoded.IssuedAt))
+	assert.Equal(t, time.UTC, decoded.IssuedAt.Location())
+
+	assert.Nil(t, NewConsistencyToken(nil, issuedAt))
+}
+
+func TestDecodeConsistencyToken_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "not base64", value: "!!!"},
+		{name: "missing separator", value: base64.RawURLEncoding.EncodeToString([]byte("2024-01-01T00:00:00Z"))},
+		{name: "invalid event id", value: base64.RawURLEncoding.EncodeToString([]byte("2024-01-01T00:00:00Z|entry-1"))},
+		{name: "invalid timestamp", value: base64.RawURLEncoding.EncodeToString([]byte("yesterday|550e8400-e29b-41d4-a716-446655440000"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := DecodeConsistencyToken(tt.value)
+			assert.Nil(t, token)
+			assert.ErrorIs(t, err, ErrInvalidConsistencyToken)
+		})
+	}
+}
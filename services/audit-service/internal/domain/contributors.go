@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// ContributorQuery restricts a contributor report to a time range; zero bounds are open
+type ContributorQuery struct {
+	// From and To bound the counted events, both inclusive
+	From time.Time
+	To   time.Time
+}
+
+// Validate ensures the range is ordered
+func (q ContributorQuery) Validate() error {
+	if !q.From.IsZero() && !q.To.IsZero() && q.From.After(q.To) {
+		return fmt.Errorf("%w: from must not be after to", ErrInvalidFilter)
+	}
+	return nil
+}
+
+// ContributorStats summarizes what one user contributed to a session
+type ContributorStats struct {
+	UserID string `json:"userId" example:"550e8400-e29b-41d4-a716-446655440003"`
+	Edits  int    `json:"edits" example:"120"`
+	// Comments counts comment and comment_created events; edits, resolutions and deletions of
+	// comments are not contributions of their own
+	Comments      int       `json:"comments" example:"14"`
+	Merges        int       `json:"merges" example:"3"`
+	TotalEvents   int       `json:"totalEvents" example:"162"`
+	FirstActivity time.Time `json:"firstActivity" example:"2024-01-02T09:12:00Z"`
+	LastActivity  time.Time `json:"lastActivity" example:"2024-01-05T17:40:00Z"`
+}
+
+// SessionContributors is the contribution report of a session, contributors with the most
+// events first
+type SessionContributors struct {
+	SessionID string `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	// From and To repeat the requested range; omitted when open
+	From         *time.Time         `json:"from,omitempty" example:"2024-01-01T00:00:00Z"`
+	To           *time.Time         `json:"to,omitempty" example:"2024-01-31T23:59:59Z"`
+	Contributors []ContributorStats `json:"contributors"`
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// EncodeDetails encodes the details of an entry, such as one of the typed details structs or a
+// map decoded from a request; nil encodes as no details
+func EncodeDetails(details interface{}) (json.RawMessage, error) {
+	if details == nil {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(details)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode details: %w", err)
+	}
+	return encoded, nil
+}
+
+// DecodeDetails decodes the details of an entry into T, such as one of the typed details structs
+// or map[string]interface{}. Entries without details, or with null details, decode to the zero
+// value of T, so callers check the fields they need rather than the error.
+func DecodeDetails[T any](entry AuditEntry) (T, error) {
+	var details T
+	raw := bytes.TrimSpace(entry.Details)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return details, nil
+	}
+	if err := json.Unmarshal(raw, &details); err != nil {
+		return details, fmt.Errorf("invalid details of %s event %s: %w", entry.Type, entry.ID, err)
+	}
+	return details, nil
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeDetails(t *testing.T) {
+	details, err := DecodeDetails[ShareUseDetails](AuditEntry{Details: json.RawMessage(`{"method":"share_token"}`)})
+	require.NoError(t, err)
+	assert.Equal(t, "share_token", details.Method)
+
+	// Entries without details decode to the zero value
+	for _, raw := range []string{"", " ", "null"} {
+		details, err := DecodeDetails[map[string]interface{}](AuditEntry{Details: json.RawMessage(raw)})
+		require.NoError(t, err, raw)
+		assert.Nil(t, details, raw)
+	}
+
+	_, err = DecodeDetails[map[string]interface{}](AuditEntry{ID: "event-1", Type: "edit", Details: json.RawMessage(`[1, 2]`)})
+	assert.ErrorContains(t, err, "invalid details of edit event event-1")
+}
+
+func TestEncodeDetails(t *testing.T) {
+	encoded, err := EncodeDetails(map[string]interface{}{"slideId": "slide-3"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"slideId":"slide-3"}`, string(encoded))
+
+	encoded, err = EncodeDetails(nil)
+	require.NoError(t, err)
+	assert.Nil(t, encoded)
+
+	_, err = EncodeDetails(map[string]interface{}{"invalid": make(chan int)})
+	assert.Error(t, err)
+
+	// Typed details round-trip
+	encoded, err = EncodeDetails(ShareUseDetails{TokenID: "token-1", Method: "share_token"})
+	require.NoError(t, err)
+	details, err := DecodeDetails[ShareUseDetails](AuditEntry{Details: encoded})
+	require.NoError(t, err)
+	assert.Equal(t, ShareUseDetails{TokenID: "token-1", Method: "share_token"}, details)
+}
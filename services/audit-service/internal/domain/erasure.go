@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErasureMode selects how a user's audit entries are erased
+type ErasureMode string
+
+// Supported erasure modes
+const (
+	// ErasureAnonymize removes personal data but keeps the entries
+	ErasureAnonymize ErasureMode = "anonymize"
+	// ErasureDelete removes the entries entirely
+	ErasureDelete ErasureMode = "delete"
+)
+
+// ErasureStatus is the progress of an erasure job
+type ErasureStatus string
+
+// Erasure job states
+const (
+	ErasurePending   ErasureStatus = "pending"
+	ErasureRunning   ErasureStatus = "running"
+	ErasureCompleted ErasureStatus = "completed"
+	ErasureFailed    ErasureStatus = "failed"
+)
+
+// RedactedUserID replaces the user ID of anonymized entries
+const RedactedUserID = "redacted"
+
+// ErrErasureJobNotFound is returned for unknown or expired erasure job IDs
+var ErrErasureJobNotFound = errors.New("erasure job not found")
+
+// Valid reports whether the mode is supported
+func (m ErasureMode) Valid() bool {
+	return m == ErasureAnonymize || m == ErasureDelete
+}
+
+// ErasureJob tracks an asynchronous data-subject erasure request
+type ErasureJob struct {
+	ID               string        `json:"id" example:"7d3c2b8e-4f0a-4b5e-9c1d-2a6f8e9b0c31"`
+	UserID           UserID        `json:"userId" example:"550e8400-e29b-41d4-a716-446655440002"`
+	Mode             ErasureMode   `json:"mode" example:"anonymize"`
+	Status           ErasureStatus `json:"status" example:"running"`
+	AffectedEvents   int           `json:"affectedEvents" example:"120"`
+	AffectedSessions int           `json:"affectedSessions" example:"4"`
+	RequestedBy      string        `json:"requestedBy" example:"550e8400-e29b-41d4-a716-446655440003"`
+	Error            string        `json:"error,omitempty"`
+	CreatedAt        time.Time     `json:"createdAt" example:"2024-01-01T10:00:00Z"`
+	CompletedAt      *time.Time    `json:"completedAt,omitempty" example:"2024-01-01T10:00:05Z"`
+	// HoldOverride is set when the job also erases entries under a legal hold
+	HoldOverride *HoldOverride `json:"holdOverride,omitempty"`
+	// OrganizationID is the organization whose entries the job erases; empty for the default organization
+	OrganizationID string `json:"organizationId,omitempty" example:"acme"`
+}
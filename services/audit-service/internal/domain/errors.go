@@ -1,8 +1,11 @@
 package domain
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"time"
 )
 
 // Common domain errors
@@ -20,21 +23,51 @@ var (
 	// Resource errors
 	ErrNotFound        = errors.New("resource not found")
 	ErrSessionNotFound = errors.New("session not found")
+	ErrShareNotFound   = errors.New("share link not found or revoked")
+	ErrEventNotFound   = errors.New("event not found")
 
 	// Validation errors
 	ErrInvalidSessionID  = errors.New("invalid session ID format")
+	ErrInvalidUserID     = errors.New("invalid user ID format")
+	ErrInvalidEventID    = errors.New("invalid event ID format")
 	ErrInvalidPagination = errors.New("invalid pagination parameters")
+	ErrInvalidEvent      = errors.New("invalid audit event")
+	ErrInvalidFilter     = errors.New("invalid event filter")
+	ErrInvalidCursor     = errors.New("invalid pagination cursor")
+	ErrInvalidEventType  = errors.New("invalid event type")
+	ErrUnknownEventType  = errors.New("unknown event type")
+
+	// Conflict errors
+	ErrEventTypeExists = errors.New("event type already exists")
 
 	// Service errors
 	ErrServiceUnavailable = errors.New("service temporarily unavailable")
 	ErrTimeout            = errors.New("request timeout")
 )
 
-// APIError represents an error response to be returned to the client
+// ProblemTypePrefix prefixes the code of an API error to form the type URI of its problem document
+const ProblemTypePrefix = "urn:audit-service:problem:"
+
+// APIError represents an error response to be returned to the client. It is written as an
+// RFC 7807 problem document (application/problem+json); Code is an extension member carrying
+// the short form of Type.
 type APIError struct {
-	Code    string `json:"error"`
-	Message string `json:"message"`
-	Status  int    `json:"-"`
+	// Type identifies the kind of problem; it is ProblemTypePrefix followed by Code
+	Type string `json:"type" example:"urn:audit-service:problem:not_found"`
+	// Title is the HTTP status text of the problem
+	Title  string `json:"title" example:"Not Found"`
+	Status int    `json:"status" example:"404"`
+	// Message explains this occurrence of the problem
+	Message string `json:"detail" example:"The requested resource was not found"`
+	// Instance is the path of the request that failed
+	Instance   string            `json:"instance,omitempty" example:"/api/v1/sessions/550e8400-e29b-41d4-a716-446655440000/history"`
+	RequestID  string            `json:"requestId,omitempty" example:"5c9e1a7b-2d4f-4e8a-b3c6-7f0d1e2a3b4c"`
+	Code       string            `json:"code" example:"not_found"`
+	Violations []SchemaViolation `json:"violations,omitempty"`
+	// Limit describes the payload of a payload_too_large problem
+	Limit *PayloadLimit `json:"limit,omitempty"`
+	// Quota describes the exhausted quota of a quota_exceeded problem
+	Quota *QuotaUsage `json:"quota,omitempty"`
 }
 
 // Error implements the error interface
@@ -42,6 +75,17 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Code, e.Message)
 }
 
+// ForRequest returns a copy of the error as the problem document of a request, with its type,
+// title, path and request ID filled in
+func (e *APIError) ForRequest(instance, requestID string) *APIError {
+	copied := *e
+	copied.Type = ProblemTypePrefix + e.Code
+	copied.Title = http.StatusText(e.Status)
+	copied.Instance = instance
+	copied.RequestID = requestID
+	return &copied
+}
+
 // Common API errors
 var (
 	APIErrInvalidRequest = &APIError{
@@ -80,6 +124,18 @@ var (
 		Status:  400,
 	}
 
+	APIErrInvalidEvent = &APIError{
+		Code:    "invalid_event",
+		Message: "The audit event was rejected by storage",
+		Status:  422,
+	}
+
+	APIErrTooManyAuthFailures = &APIError{
+		Code:    "too_many_auth_failures",
+		Message: "Too many rejected requests; retry later",
+		Status:  429,
+	}
+
 	APIErrInternalServer = &APIError{
 		Code:    "internal_server_error",
 		Message: "An internal server error occurred",
@@ -112,21 +168,145 @@ func ToAPIError(err error) *APIError {
 		return APIErrUnauthorized
 
 	case errors.Is(err, ErrForbidden),
-		errors.Is(err, ErrAccessDenied):
+		errors.Is(err, ErrAccessDenied),
+		errors.Is(err, ErrShareNotFound):
 		return APIErrForbidden
 
 	case errors.Is(err, ErrNotFound),
-		errors.Is(err, ErrSessionNotFound):
+		errors.Is(err, ErrSessionNotFound),
+		errors.Is(err, ErrEventNotFound),
+		errors.Is(err, ErrErasureJobNotFound),
+		errors.Is(err, ErrLegalHoldNotFound),
+		errors.Is(err, ErrRevocationNotFound),
+		errors.Is(err, ErrWatchNotFound),
+		errors.Is(err, ErrReportNotFound),
+		errors.Is(err, ErrSavedQueryNotFound),
+		errors.Is(err, ErrAnnotationNotFound),
+		errors.Is(err, ErrExportNotFound),
+		errors.Is(err, ErrShareTrailNotFound),
+		errors.Is(err, ErrImportJobNotFound),
+		errors.Is(err, ErrReplayJobNotFound),
+		errors.Is(err, ErrDeadLetterNotFound):
 		return APIErrNotFound
 
+	case errors.Is(err, ErrInvalidLegalHold):
+		return NewAPIError("invalid_legal_hold", "Invalid legal hold", 400)
+
+	case errors.Is(err, ErrLegalHoldReleased):
+		return NewAPIError("legal_hold_released", "Legal hold was already released", 409)
+
+	case errors.Is(err, ErrInvalidRevocation):
+		return NewAPIError("invalid_revocation", "Invalid revocation", 400)
+
+	case errors.Is(err, ErrRevocationLifted):
+		return NewAPIError("revocation_lifted", "Revocation was already lifted", 409)
+
+	case errors.Is(err, ErrInvalidWatch):
+		return NewAPIError("invalid_watch", "Invalid watch", 400)
+
+	case errors.Is(err, ErrInvalidReport):
+		return NewAPIError("invalid_report", "Invalid report", 400)
+
+	case errors.Is(err, ErrInvalidLabel):
+		return NewAPIError("invalid_label", "Invalid label", 400)
+
+	case errors.Is(err, ErrInvalidAnnotation):
+		return NewAPIError("invalid_annotation", "Invalid annotation", 400)
+
+	case errors.Is(err, ErrInvalidSavedQuery):
+		return NewAPIError("invalid_saved_query", "Invalid saved query", 400)
+
+	case errors.Is(err, ErrInvalidExportCallback):
+		return NewAPIError("invalid_export_callback", "Invalid export callback", 400)
+
+	case errors.Is(err, ErrInvalidImport):
+		return NewAPIError("invalid_import", "Invalid import", 400)
+
+	case errors.Is(err, ErrInvalidReplay):
+		return NewAPIError("invalid_replay", "Invalid replay", 400)
+
 	case errors.Is(err, ErrInvalidSessionID),
-		errors.Is(err, ErrInvalidPagination):
+		errors.Is(err, ErrInvalidUserID),
+		errors.Is(err, ErrInvalidEventID),
+		errors.Is(err, ErrInvalidPagination),
+		errors.Is(err, ErrInvalidFilter),
+		errors.Is(err, ErrInvalidCursor),
+		errors.Is(err, ErrInvalidActivityQuery):
 		return APIErrBadRequest
 
+	case errors.Is(err, ErrInvalidConsistencyToken):
+		return NewAPIError("invalid_consistency_token", "Invalid consistency token", 400)
+
+	case errors.Is(err, ErrTimestampSkew):
+		return NewAPIError("invalid_timestamp", "Event timestamp is too far from server time", 400)
+
+	case errors.Is(err, ErrInvalidEventType):
+		return NewAPIError("invalid_event_type", "Invalid event type definition", 400)
+
+	case errors.Is(err, ErrEventTypeExists):
+		return NewAPIError("event_type_exists", "An event type with this name already exists", 409)
+
+	case errors.Is(err, ErrUnknownEventType):
+		return NewAPIError("unknown_event_type", "Event type is not registered", 422)
+
+	case errors.Is(err, ErrInvalidEventDetails):
+		apiErr := NewAPIError("invalid_event_details", "Event details do not match the schema for this event type", 422)
+		var schemaErr *SchemaValidationError
+		if errors.As(err, &schemaErr) {
+			apiErr.Violations = schemaErr.Violations
+		}
+		return apiErr
+
+	case errors.Is(err, ErrPayloadTooLarge):
+		apiErr := NewAPIError("payload_too_large", "Request payload exceeds its size limit", 413)
+		var sizeErr *PayloadTooLargeError
+		if errors.As(err, &sizeErr) {
+			limit := sizeErr.Limit
+			apiErr.Limit = &limit
+		}
+		return apiErr
+
+	case errors.Is(err, ErrQuotaExceeded):
+		apiErr := NewAPIError("quota_exceeded", "Daily event quota exceeded", 429)
+		var quotaErr *QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			usage := quotaErr.Usage
+			apiErr.Message = fmt.Sprintf("Daily %s quota of %d events exceeded; it resets at %s",
+				usage.Scope, usage.Limit, usage.ResetAt.UTC().Format(time.RFC3339))
+			apiErr.Quota = &usage
+		}
+		return apiErr
+
+	case errors.Is(err, ErrInvalidReviewTransition):
+		apiErr := NewAPIError("invalid_review_transition", "Review event is not allowed in the review state of its slide or shape", 409)
+		var transitionErr *ReviewTransitionError
+		if errors.As(err, &transitionErr) {
+			apiErr.Message = transitionErr.Error()
+		}
+		return apiErr
+
+	case errors.Is(err, ErrPreconditionRequired):
+		return NewAPIError("precondition_required", "Send the ETag of the record in the If-Match header to change it", 428)
+
+	case errors.Is(err, ErrVersionConflict):
+		return NewAPIError("version_conflict", "The record was changed since it was read; reload it and retry", 412)
+
+	case errors.Is(err, ErrNotReversible):
+		return NewAPIError("not_reversible", "Event does not record the state it replaced", 422)
+
+	case errors.Is(err, ErrNoTextChange):
+		return NewAPIError("no_text_change", "Event does not record the text before and after an edit", 422)
+
+	case errors.Is(err, ErrInvalidEvent):
+		return APIErrInvalidEvent
+
+	case errors.Is(err, ErrWriteNotVisible):
+		return NewAPIError("write_not_visible", "Events of the consistency token are not readable yet; retry the request", 503)
+
 	case errors.Is(err, ErrServiceUnavailable):
 		return APIErrServiceUnavailable
 
-	case errors.Is(err, ErrTimeout):
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.DeadlineExceeded):
 		return NewAPIError("timeout", "Request timeout", 504)
 
 	default:
@@ -1,7 +1,10 @@
 package domain
 
 import (
+	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -29,6 +32,20 @@ func TestNewAPIError(t *testing.T) {
 	assert.Equal(t, status, apiErr.Status)
 }
 
+func TestAPIError_ForRequest(t *testing.T) {
+	apiErr := APIErrNotFound.ForRequest("/api/v1/events/1", "req-1")
+
+	assert.Equal(t, "urn:audit-service:problem:not_found", apiErr.Type)
+	assert.Equal(t, "Not Found", apiErr.Title)
+	assert.Equal(t, "/api/v1/events/1", apiErr.Instance)
+	assert.Equal(t, "req-1", apiErr.RequestID)
+	assert.Equal(t, APIErrNotFound.Code, apiErr.Code)
+	assert.Equal(t, APIErrNotFound.Status, apiErr.Status)
+	// The shared error is left untouched
+	assert.Empty(t, APIErrNotFound.RequestID)
+	assert.Empty(t, APIErrNotFound.Type)
+}
+
 func TestToAPIError(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -65,6 +82,11 @@ func TestToAPIError(t *testing.T) {
 			inputError:  ErrAccessDenied,
 			expectedErr: APIErrForbidden,
 		},
+		{
+			name:        "share not found error",
+			inputError:  ErrShareNotFound,
+			expectedErr: APIErrForbidden,
+		},
 		{
 			name:        "not found error",
 			inputError:  ErrNotFound,
@@ -75,16 +97,251 @@ func TestToAPIError(t *testing.T) {
 			inputError:  ErrSessionNotFound,
 			expectedErr: APIErrNotFound,
 		},
+		{
+			name:        "event not found error",
+			inputError:  ErrEventNotFound,
+			expectedErr: APIErrNotFound,
+		},
+		{
+			name:        "erasure job not found error",
+			inputError:  ErrErasureJobNotFound,
+			expectedErr: APIErrNotFound,
+		},
+		{
+			name:        "legal hold not found error",
+			inputError:  ErrLegalHoldNotFound,
+			expectedErr: APIErrNotFound,
+		},
+		{
+			name:        "invalid legal hold error",
+			inputError:  fmt.Errorf("%w: scope must be session or user", ErrInvalidLegalHold),
+			expectedErr: &APIError{Code: "invalid_legal_hold", Message: "Invalid legal hold", Status: 400},
+		},
+		{
+			name:        "legal hold released error",
+			inputError:  ErrLegalHoldReleased,
+			expectedErr: &APIError{Code: "legal_hold_released", Message: "Legal hold was already released", Status: 409},
+		},
+		{
+			name:        "not reversible error",
+			inputError:  fmt.Errorf("%w: only edit events can be reverted", ErrNotReversible),
+			expectedErr: &APIError{Code: "not_reversible", Message: "Event does not record the state it replaced", Status: 422},
+		},
+		{
+			name:        "no text change error",
+			inputError:  fmt.Errorf("%w: only edit events record text changes", ErrNoTextChange),
+			expectedErr: &APIError{Code: "no_text_change", Message: "Event does not record the text before and after an edit", Status: 422},
+		},
+		{
+			name:        "revocation not found error",
+			inputError:  ErrRevocationNotFound,
+			expectedErr: APIErrNotFound,
+		},
+		{
+			name:        "invalid revocation error",
+			inputError:  fmt.Errorf("%w: target is required", ErrInvalidRevocation),
+			expectedErr: &APIError{Code: "invalid_revocation", Message: "Invalid revocation", Status: 400},
+		},
+		{
+			name:        "revocation lifted error",
+			inputError:  ErrRevocationLifted,
+			expectedErr: &APIError{Code: "revocation_lifted", Message: "Revocation was already lifted", Status: 409},
+		},
+		{
+			name:        "watch not found error",
+			inputError:  ErrWatchNotFound,
+			expectedErr: APIErrNotFound,
+		},
+		{
+			name:        "invalid watch error",
+			inputError:  fmt.Errorf("%w: at least one event type is required", ErrInvalidWatch),
+			expectedErr: &APIError{Code: "invalid_watch", Message: "Invalid watch", Status: 400},
+		},
+		{
+			name:        "report not found error",
+			inputError:  ErrReportNotFound,
+			expectedErr: APIErrNotFound,
+		},
+		{
+			name:        "invalid report error",
+			inputError:  fmt.Errorf("%w: format must be csv or json", ErrInvalidReport),
+			expectedErr: &APIError{Code: "invalid_report", Message: "Invalid report", Status: 400},
+		},
+		{
+			name:        "saved query not found error",
+			inputError:  ErrSavedQueryNotFound,
+			expectedErr: APIErrNotFound,
+		},
+		{
+			name:        "invalid saved query error",
+			inputError:  fmt.Errorf("%w: scope must be private or organization", ErrInvalidSavedQuery),
+			expectedErr: &APIError{Code: "invalid_saved_query", Message: "Invalid saved query", Status: 400},
+		},
+		{
+			name: "review transition error",
+			inputError: &ReviewTransitionError{
+				Target: ReviewTarget{SlideID: "slide-3"},
+				State:  ReviewDraft,
+				Action: ActionApproved,
+			},
+			expectedErr: &APIError{
+				Code:    "invalid_review_transition",
+				Message: "approved cannot be recorded for slide slide-3 in state draft; allowed events: submitted_for_review",
+				Status:  409,
+			},
+		},
+		{
+			name:        "export not found error",
+			inputError:  ErrExportNotFound,
+			expectedErr: APIErrNotFound,
+		},
+		{
+			name:        "invalid export callback error",
+			inputError:  fmt.Errorf("%w: status must be queued, rendering, uploaded or failed", ErrInvalidExportCallback),
+			expectedErr: &APIError{Code: "invalid_export_callback", Message: "Invalid export callback", Status: 400},
+		},
+		{
+			name:        "import job not found error",
+			inputError:  ErrImportJobNotFound,
+			expectedErr: APIErrNotFound,
+		},
+		{
+			name:        "invalid import error",
+			inputError:  fmt.Errorf("%w: url must use https", ErrInvalidImport),
+			expectedErr: &APIError{Code: "invalid_import", Message: "Invalid import", Status: 400},
+		},
+		{
+			name:        "replay job not found error",
+			inputError:  ErrReplayJobNotFound,
+			expectedErr: APIErrNotFound,
+		},
+		{
+			name:        "invalid replay error",
+			inputError:  fmt.Errorf("%w: target must be webhook or outbox", ErrInvalidReplay),
+			expectedErr: &APIError{Code: "invalid_replay", Message: "Invalid replay", Status: 400},
+		},
+		{
+			name:        "dead letter not found error",
+			inputError:  ErrDeadLetterNotFound,
+			expectedErr: APIErrNotFound,
+		},
+		{
+			name:        "invalid activity query error",
+			inputError:  fmt.Errorf("%w: granularity must be day or hour", ErrInvalidActivityQuery),
+			expectedErr: APIErrBadRequest,
+		},
 		{
 			name:        "invalid session ID error",
 			inputError:  ErrInvalidSessionID,
 			expectedErr: APIErrBadRequest,
 		},
+		{
+			name:        "invalid user ID error",
+			inputError:  ErrInvalidUserID,
+			expectedErr: APIErrBadRequest,
+		},
+		{
+			name:        "invalid event ID error",
+			inputError:  ErrInvalidEventID,
+			expectedErr: APIErrBadRequest,
+		},
 		{
 			name:        "invalid pagination error",
 			inputError:  ErrInvalidPagination,
 			expectedErr: APIErrBadRequest,
 		},
+		{
+			name:        "invalid filter error",
+			inputError:  ErrInvalidFilter,
+			expectedErr: APIErrBadRequest,
+		},
+		{
+			name:        "invalid cursor error",
+			inputError:  ErrInvalidCursor,
+			expectedErr: APIErrBadRequest,
+		},
+		{
+			name:        "invalid event type error",
+			inputError:  fmt.Errorf("%w: severity must be info, low, medium or high", ErrInvalidEventType),
+			expectedErr: &APIError{Code: "invalid_event_type", Message: "Invalid event type definition", Status: 400},
+		},
+		{
+			name:        "event type exists error",
+			inputError:  ErrEventTypeExists,
+			expectedErr: &APIError{Code: "event_type_exists", Message: "An event type with this name already exists", Status: 409},
+		},
+		{
+			name:        "unknown event type error",
+			inputError:  ErrUnknownEventType,
+			expectedErr: &APIError{Code: "unknown_event_type", Message: "Event type is not registered", Status: 422},
+		},
+		{
+			name:        "timestamp skew error",
+			inputError:  fmt.Errorf("%w: 1h0m0s ahead of server time", ErrTimestampSkew),
+			expectedErr: &APIError{Code: "invalid_timestamp", Message: "Event timestamp is too far from server time", Status: 400},
+		},
+		{
+			name:        "invalid consistency token error",
+			inputError:  fmt.Errorf("%w: malformed encoding", ErrInvalidConsistencyToken),
+			expectedErr: &APIError{Code: "invalid_consistency_token", Message: "Invalid consistency token", Status: 400},
+		},
+		{
+			name:        "write not visible error",
+			inputError:  ErrWriteNotVisible,
+			expectedErr: &APIError{Code: "write_not_visible", Message: "Events of the consistency token are not readable yet; retry the request", Status: 503},
+		},
+		{
+			name:        "invalid label error",
+			inputError:  fmt.Errorf("%w: at most 20 labels are allowed", ErrInvalidLabel),
+			expectedErr: &APIError{Code: "invalid_label", Message: "Invalid label", Status: 400},
+		},
+		{
+			name:        "annotation not found error",
+			inputError:  ErrAnnotationNotFound,
+			expectedErr: APIErrNotFound,
+		},
+		{
+			name:        "invalid annotation error",
+			inputError:  fmt.Errorf("%w: body must not be empty", ErrInvalidAnnotation),
+			expectedErr: &APIError{Code: "invalid_annotation", Message: "Invalid annotation", Status: 400},
+		},
+		{
+			name:        "precondition required error",
+			inputError:  ErrPreconditionRequired,
+			expectedErr: &APIError{Code: "precondition_required", Message: "Send the ETag of the record in the If-Match header to change it", Status: 428},
+		},
+		{
+			name:        "version conflict error",
+			inputError:  fmt.Errorf("%w: version 3 is current, not 2", ErrVersionConflict),
+			expectedErr: &APIError{Code: "version_conflict", Message: "The record was changed since it was read; reload it and retry", Status: 412},
+		},
+		{
+			name:       "payload too large error",
+			inputError: fmt.Errorf("event 2: %w", NewPayloadTooLargeError(PayloadDetails, 70000, 65536)),
+			expectedErr: &APIError{
+				Code:    "payload_too_large",
+				Message: "Request payload exceeds its size limit",
+				Status:  413,
+				Limit:   &PayloadLimit{Field: "details", LimitBytes: 65536, SizeBytes: 70000, ExcessBytes: 4464},
+			},
+		},
+		{
+			name: "quota exceeded error",
+			inputError: &QuotaExceededError{Requested: 5, Usage: QuotaUsage{
+				Scope: "session", Limit: 100, Used: 98, Remaining: 2, ResetAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			}},
+			expectedErr: &APIError{
+				Code:    "quota_exceeded",
+				Message: "Daily session quota of 100 events exceeded; it resets at 2024-01-02T00:00:00Z",
+				Status:  429,
+				Quota:   &QuotaUsage{Scope: "session", Limit: 100, Used: 98, Remaining: 2, ResetAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:        "invalid event error",
+			inputError:  ErrInvalidEvent,
+			expectedErr: APIErrInvalidEvent,
+		},
 		{
 			name:        "service unavailable error",
 			inputError:  ErrServiceUnavailable,
@@ -99,6 +356,15 @@ func TestToAPIError(t *testing.T) {
 				Status:  504,
 			},
 		},
+		{
+			name:       "storage deadline exceeded",
+			inputError: fmt.Errorf("failed to query audit events: %w", context.DeadlineExceeded),
+			expectedErr: &APIError{
+				Code:    "timeout",
+				Message: "Request timeout",
+				Status:  504,
+			},
+		},
 		{
 			name:        "unknown error",
 			inputError:  assert.AnError,
@@ -123,6 +389,7 @@ func TestCommonAPIErrors(t *testing.T) {
 		APIErrForbidden,
 		APIErrNotFound,
 		APIErrBadRequest,
+		APIErrInvalidEvent,
 		APIErrInternalServer,
 		APIErrServiceUnavailable,
 	}
@@ -146,8 +413,50 @@ func TestDomainErrors(t *testing.T) {
 		ErrAccessDenied,
 		ErrNotFound,
 		ErrSessionNotFound,
+		ErrShareNotFound,
+		ErrEventNotFound,
 		ErrInvalidSessionID,
+		ErrInvalidUserID,
+		ErrInvalidEventID,
 		ErrInvalidPagination,
+		ErrInvalidEvent,
+		ErrInvalidFilter,
+		ErrInvalidCursor,
+		ErrInvalidEventType,
+		ErrUnknownEventType,
+		ErrEventTypeExists,
+		ErrInvalidLegalHold,
+		ErrLegalHoldNotFound,
+		ErrLegalHoldReleased,
+		ErrInvalidRevocation,
+		ErrRevocationNotFound,
+		ErrRevocationLifted,
+		ErrInvalidWatch,
+		ErrWatchNotFound,
+		ErrInvalidReport,
+		ErrReportNotFound,
+		ErrInvalidSavedQuery,
+		ErrSavedQueryNotFound,
+		ErrInvalidReviewTransition,
+		ErrInvalidExportCallback,
+		ErrExportNotFound,
+		ErrImportJobNotFound,
+		ErrInvalidImport,
+		ErrReplayJobNotFound,
+		ErrInvalidReplay,
+		ErrDeadLetterNotFound,
+		ErrInvalidActivityQuery,
+		ErrNotReversible,
+		ErrTimestampSkew,
+		ErrInvalidConsistencyToken,
+		ErrWriteNotVisible,
+		ErrInvalidLabel,
+		ErrInvalidAnnotation,
+		ErrAnnotationNotFound,
+		ErrPreconditionRequired,
+		ErrVersionConflict,
+		ErrPayloadTooLarge,
+		ErrQuotaExceeded,
 		ErrServiceUnavailable,
 		ErrTimeout,
 	}
@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// EventSeverity ranks how much attention events of a type deserve from reviewers
+type EventSeverity string
+
+// Event type severities
+const (
+	SeverityInfo   EventSeverity = "info"
+	SeverityLow    EventSeverity = "low"
+	SeverityMedium EventSeverity = "medium"
+	SeverityHigh   EventSeverity = "high"
+)
+
+// Valid reports whether the severity is supported
+func (s EventSeverity) Valid() bool {
+	switch s {
+	case SeverityInfo, SeverityLow, SeverityMedium, SeverityHigh:
+		return true
+	}
+	return false
+}
+
+const (
+	// MaxEventTypeNameLength is the longest accepted name of a custom event type
+	MaxEventTypeNameLength = 64
+
+	// MaxEventTypeDisplayNameLength is the longest accepted display name of a custom event type
+	MaxEventTypeDisplayNameLength = 128
+)
+
+// eventTypeNamePattern matches lowercase snake_case names such as terminology_approved
+var eventTypeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// EventType describes an action that audit events may be recorded with
+type EventType struct {
+	Name        AuditAction   `json:"name" example:"terminology_approved"`
+	DisplayName string        `json:"displayName" example:"Terminology approved"`
+	Severity    EventSeverity `json:"severity" example:"low"`
+	// Schema validates the details of events of a custom type; nil accepts any details
+	Schema    json.RawMessage `json:"schema,omitempty" swaggertype:"object"`
+	BuiltIn   bool            `json:"builtIn"`
+	CreatedBy string          `json:"createdBy,omitempty"`
+	CreatedAt *time.Time      `json:"createdAt,omitempty"`
+}
+
+// ValidEventTypeName reports whether a name is lowercase snake_case of up to MaxEventTypeNameLength characters
+func ValidEventTypeName(name string) bool {
+	return len(name) <= MaxEventTypeNameLength && eventTypeNamePattern.MatchString(name)
+}
+
+// Validate checks the name, display name, severity and schema of an event type
+func (t EventType) Validate() error {
+	_, err := t.compile()
+	return err
+}
+
+// compile validates the event type and compiles its schema, nil for types without one
+func (t EventType) compile() (*gojsonschema.Schema, error) {
+	if !ValidEventTypeName(string(t.Name)) {
+		return nil, fmt.Errorf("%w: name must be lowercase letters, digits and underscores of up to %d characters, starting with a letter",
+			ErrInvalidEventType, MaxEventTypeNameLength)
+	}
+	if t.DisplayName == "" || utf8.RuneCountInString(t.DisplayName) > MaxEventTypeDisplayNameLength {
+		return nil, fmt.Errorf("%w: displayName must have 1 to %d characters", ErrInvalidEventType, MaxEventTypeDisplayNameLength)
+	}
+	if !t.Severity.Valid() {
+		return nil, fmt.Errorf("%w: severity must be info, low, medium or high", ErrInvalidEventType)
+	}
+	if len(t.Schema) == 0 {
+		return nil, nil
+	}
+
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(t.Schema))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid schema: %v", ErrInvalidEventType, err)
+	}
+	return schema, nil
+}
+
+// builtinEventTypes are the actions the service records without registration
+var builtinEventTypes = []EventType{
+	{Name: ActionCreate, DisplayName: "Session created", Severity: SeverityInfo},
+	{Name: ActionEdit, DisplayName: "Text edited", Severity: SeverityLow},
+	{Name: ActionMerge, DisplayName: "Shapes merged", Severity: SeverityLow},
+	{Name: ActionReorder, DisplayName: "Slides reordered", Severity: SeverityLow},
+	{Name: ActionComment, DisplayName: "Comment added", Severity: SeverityInfo},
+	{Name: ActionCommentCreated, DisplayName: "Comment created", Severity: SeverityInfo},
+	{Name: ActionCommentEdited, DisplayName: "Comment edited", Severity: SeverityInfo},
+	{Name: ActionCommentResolved, DisplayName: "Comment resolved", Severity: SeverityInfo},
+	{Name: ActionCommentDeleted, DisplayName: "Comment deleted", Severity: SeverityLow},
+	{Name: ActionAnnotationCreated, DisplayName: "Event annotated", Severity: SeverityLow},
+	{Name: ActionAnnotationEdited, DisplayName: "Annotation edited", Severity: SeverityLow},
+	{Name: ActionAnnotationDeleted, DisplayName: "Annotation deleted", Severity: SeverityMedium},
+	{Name: ActionSubmittedForReview, DisplayName: "Submitted for review", Severity: SeverityInfo},
+	{Name: ActionApproved, DisplayName: "Translation approved", Severity: SeverityLow},
+	{Name: ActionRejected, DisplayName: "Translation rejected", Severity: SeverityLow},
+	{Name: ActionReopened, DisplayName: "Approval reopened", Severity: SeverityLow},
+	{Name: ActionTMHit, DisplayName: "Translation memory match", Severity: SeverityInfo},
+	{Name: ActionTMOverride, DisplayName: "Translation memory overridden", Severity: SeverityInfo},
+	{Name: ActionMTRequest, DisplayName: "Machine translation requested", Severity: SeverityInfo},
+	{Name: ActionExport, DisplayName: "Session exported", Severity: SeverityMedium},
+	{Name: ActionExportLifecycle, DisplayName: "Export progressed", Severity: SeverityInfo},
+	{Name: ActionShare, DisplayName: "Session shared", Severity: SeverityMedium},
+	{Name: ActionUnshare, DisplayName: "Share link revoked", Severity: SeverityMedium},
+	{Name: ActionShareRedeemed, DisplayName: "Share link redeemed", Severity: SeverityLow},
+	{Name: ActionShareExpired, DisplayName: "Expired share link presented", Severity: SeverityLow},
+	{Name: ActionView, DisplayName: "Session viewed", Severity: SeverityInfo},
+	{Name: ActionThumbnail, DisplayName: "Thumbnail generated", Severity: SeverityInfo},
+	{Name: ActionRetentionPurge, DisplayName: "Events purged by retention", Severity: SeverityMedium},
+	{Name: ActionUserErasure, DisplayName: "User data erased", Severity: SeverityHigh},
+	{Name: ActionSecurityAlert, DisplayName: "Security alert", Severity: SeverityHigh},
+	{Name: ActionExternalChange, DisplayName: "Changed outside the API", Severity: SeverityMedium},
+	{Name: ActionConfigChanged, DisplayName: "Configuration changed", Severity: SeverityMedium},
+	{Name: ActionAuthFailure, DisplayName: "Authentication or authorization failed", Severity: SeverityMedium},
+}
@@ -0,0 +1,236 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ExportStatus is a step of an export rendered by the export worker
+type ExportStatus string
+
+// Export statuses, in lifecycle order
+const (
+	ExportQueued    ExportStatus = "queued"
+	ExportRendering ExportStatus = "rendering"
+	ExportUploaded  ExportStatus = "uploaded"
+	ExportFailed    ExportStatus = "failed"
+)
+
+// Valid reports whether the status is reported by the export worker
+func (s ExportStatus) Valid() bool {
+	switch s {
+	case ExportQueued, ExportRendering, ExportUploaded, ExportFailed:
+		return true
+	}
+	return false
+}
+
+// Final reports whether the export ends with the status
+func (s ExportStatus) Final() bool {
+	return s == ExportUploaded || s == ExportFailed
+}
+
+// MaxExportErrorLength is the longest failure reason kept for a failed export
+const MaxExportErrorLength = 2000
+
+var (
+	// ErrInvalidExportCallback is returned for export callbacks with missing or invalid fields
+	ErrInvalidExportCallback = errors.New("invalid export callback")
+	// ErrExportNotFound is returned for exports without recorded lifecycle events, or of
+	// sessions the user cannot read
+	ErrExportNotFound = errors.New("export not found")
+)
+
+// ExportCallback is the lifecycle step of an export reported by the export worker. The export
+// ID is recorded as the correlation ID of the step, so the frontend export event sharing it
+// is stitched into the export record.
+type ExportCallback struct {
+	ExportID  string       `json:"exportId" binding:"required" example:"export-7f3a"`
+	SessionID string       `json:"sessionId" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID    string       `json:"userId" binding:"required" example:"user-123"`
+	Status    ExportStatus `json:"status" binding:"required" example:"uploaded"`
+	// Timestamp is when the worker reached the status; it defaults to the time the callback arrived
+	Timestamp      *time.Time `json:"timestamp,omitempty" example:"2024-01-15T10:30:00Z"`
+	OrganizationID string     `json:"organizationId,omitempty" example:"org-acme"`
+	Format         string     `json:"format,omitempty" example:"pptx"`
+	SlideCount     *int       `json:"slideCount,omitempty" example:"12"`
+	// URL and SizeBytes describe the uploaded file
+	URL       string `json:"url,omitempty" example:"https://storage.example.com/exports/export-7f3a.pptx"`
+	SizeBytes int64  `json:"sizeBytes,omitempty" example:"482133"`
+	// Error is the failure reason of a failed export
+	Error string `json:"error,omitempty" example:"font not found"`
+}
+
+// Validate checks the callback before its step is recorded
+func (c ExportCallback) Validate() error {
+	if c.ExportID == "" || !ValidCorrelationID(c.ExportID) {
+		return fmt.Errorf("%w: exportId must be at most %d printable characters without spaces", ErrInvalidExportCallback, MaxCorrelationIDLength)
+	}
+	if _, err := ParseSessionID(c.SessionID); err != nil {
+		return fmt.Errorf("%w: sessionId must be a UUID", ErrInvalidExportCallback)
+	}
+	if c.UserID == "" {
+		return fmt.Errorf("%w: userId is required", ErrInvalidExportCallback)
+	}
+	if !c.Status.Valid() {
+		return fmt.Errorf("%w: status must be queued, rendering, uploaded or failed", ErrInvalidExportCallback)
+	}
+	if c.SlideCount != nil && *c.SlideCount < 0 {
+		return fmt.Errorf("%w: slideCount must not be negative", ErrInvalidExportCallback)
+	}
+	if c.SizeBytes < 0 {
+		return fmt.Errorf("%w: sizeBytes must not be negative", ErrInvalidExportCallback)
+	}
+	if len(c.Error) > MaxExportErrorLength {
+		return fmt.Errorf("%w: error must not exceed %d characters", ErrInvalidExportCallback, MaxExportErrorLength)
+	}
+	return nil
+}
+
+// ExportLifecycleDetails are the details of an export_lifecycle event
+type ExportLifecycleDetails struct {
+	ExportID   string       `json:"exportId"`
+	Status     ExportStatus `json:"status"`
+	Format     string       `json:"format,omitempty"`
+	SlideCount *int         `json:"slideCount,omitempty"`
+	URL        string       `json:"url,omitempty"`
+	SizeBytes  int64        `json:"sizeBytes,omitempty"`
+	Error      string       `json:"error,omitempty"`
+}
+
+// Details returns the details recorded for the callback
+func (c ExportCallback) Details() ExportLifecycleDetails {
+	return ExportLifecycleDetails{
+		ExportID:   c.ExportID,
+		Status:     c.Status,
+		Format:     c.Format,
+		SlideCount: c.SlideCount,
+		URL:        c.URL,
+		SizeBytes:  c.SizeBytes,
+		Error:      c.Error,
+	}
+}
+
+// ExportStep is a recorded lifecycle step of an export
+type ExportStep struct {
+	Status    ExportStatus `json:"status" example:"rendering"`
+	Timestamp time.Time    `json:"timestamp" example:"2024-01-15T10:30:05Z"`
+	EventID   string       `json:"eventId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// ExportRecord is the audit record of one export, stitched from the export event of the frontend
+// and the lifecycle steps reported by the export worker
+type ExportRecord struct {
+	ExportID  string `json:"exportId" example:"export-7f3a"`
+	SessionID string `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID    string `json:"userId" example:"user-123"`
+	// Status is the final status once the export is uploaded or failed, the latest one before
+	Status     ExportStatus `json:"status" example:"uploaded"`
+	Format     string       `json:"format,omitempty" example:"pptx"`
+	SlideCount *int         `json:"slideCount,omitempty" example:"12"`
+	URL        string       `json:"url,omitempty" example:"https://storage.example.com/exports/export-7f3a.pptx"`
+	SizeBytes  int64        `json:"sizeBytes,omitempty" example:"482133"`
+	Error      string       `json:"error,omitempty"`
+	// RequestedAt and RequestEventID identify the export event of the frontend, when it shares the export ID
+	RequestedAt    *time.Time `json:"requestedAt,omitempty" example:"2024-01-15T10:29:58Z"`
+	RequestEventID string     `json:"requestEventId,omitempty"`
+	// CompletedAt is the time of the uploaded or failed step, and DurationMs the time from the
+	// first step to it
+	CompletedAt *time.Time   `json:"completedAt,omitempty" example:"2024-01-15T10:30:20Z"`
+	DurationMs  *int64       `json:"durationMs,omitempty" example:"20000"`
+	Steps       []ExportStep `json:"steps"`
+}
+
+// NewExportRecord stitches the export record from the export_lifecycle and export events
+// correlated by the export ID. It returns false when no lifecycle step was recorded.
+func NewExportRecord(exportID string, entries []AuditEntry) (ExportRecord, bool) {
+	sorted := append([]AuditEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	// The session of the first step is the session of the export; events of other sessions
+	// reusing the export ID are left out
+	record := ExportRecord{ExportID: exportID, Steps: []ExportStep{}}
+	var requests []AuditEntry
+	for _, entry := range sorted {
+		switch AuditAction(entry.Type) {
+		case ActionExport:
+			requests = append(requests, entry)
+		case ActionExportLifecycle:
+			details, err := DecodeDetails[ExportLifecycleDetails](entry)
+			if err != nil || !details.Status.Valid() {
+				continue
+			}
+			if record.SessionID != "" && entry.SessionID != record.SessionID {
+				continue
+			}
+			record.Steps = append(record.Steps, ExportStep{
+				Status:    details.Status,
+				Timestamp: entry.Timestamp,
+				EventID:   entry.ID,
+				Error:     details.Error,
+			})
+			record.apply(entry, details)
+		}
+	}
+	if len(record.Steps) == 0 {
+		return ExportRecord{}, false
+	}
+
+	for _, request := range requests {
+		if request.SessionID == record.SessionID {
+			requestedAt := request.Timestamp
+			record.RequestedAt = &requestedAt
+			record.RequestEventID = request.ID
+			break
+		}
+	}
+	if record.CompletedAt != nil {
+		duration := record.CompletedAt.Sub(record.Steps[0].Timestamp).Milliseconds()
+		record.DurationMs = &duration
+	}
+	return record, true
+}
+
+// apply takes the status and the known fields of a lifecycle step, in time order. A final
+// status is kept over steps reported after it.
+func (r *ExportRecord) apply(entry AuditEntry, details ExportLifecycleDetails) {
+	if r.SessionID == "" {
+		r.SessionID = entry.SessionID
+		r.UserID = entry.UserID
+	}
+	if r.CompletedAt == nil {
+		r.Status = details.Status
+	}
+	if details.Status.Final() && r.CompletedAt == nil {
+		completedAt := entry.Timestamp
+		r.CompletedAt = &completedAt
+	}
+	if details.Format != "" {
+		r.Format = details.Format
+	}
+	if details.SlideCount != nil {
+		r.SlideCount = details.SlideCount
+	}
+	if details.URL != "" {
+		r.URL = details.URL
+	}
+	if details.SizeBytes > 0 {
+		r.SizeBytes = details.SizeBytes
+	}
+	if details.Error != "" {
+		r.Error = details.Error
+	}
+}
+
+// HasStep reports whether the record holds a step with the status
+func (r ExportRecord) HasStep(status ExportStatus) bool {
+	for _, step := range r.Steps {
+		if step.Status == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testExportSession = "550e8400-e29b-41d4-a716-446655440000"
+
+var testExportTime = time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+func exportStep(t *testing.T, id, sessionID string, seconds int, details ExportLifecycleDetails) AuditEntry {
+	t.Helper()
+	raw, err := json.Marshal(details)
+	require.NoError(t, err)
+	return AuditEntry{
+		ID:            id,
+		SessionID:     sessionID,
+		UserID:        "user-123",
+		Type:          string(ActionExportLifecycle),
+		Timestamp:     testExportTime.Add(time.Duration(seconds) * time.Second),
+		Details:       raw,
+		CorrelationID: details.ExportID,
+	}
+}
+
+func TestNewExportRecord(t *testing.T) {
+	slides := 12
+	entries := []AuditEntry{
+		exportStep(t, "step-3", testExportSession, 20, ExportLifecycleDetails{ExportID: "export-7f3a", Status: ExportUploaded,
+			URL: "https://storage.example.com/export-7f3a.pptx", SizeBytes: 482133}),
+		exportStep(t, "step-1", testExportSession, 0, ExportLifecycleDetails{ExportID: "export-7f3a", Status: ExportQueued, Format: "pptx"}),
+		exportStep(t, "step-2", testExportSession, 5, ExportLifecycleDetails{ExportID: "export-7f3a", Status: ExportRendering, SlideCount: &slides}),
+		// Steps reported late do not reopen a finished export
+		exportStep(t, "step-4", testExportSession, 25, ExportLifecycleDetails{ExportID: "export-7f3a", Status: ExportRendering}),
+		// Events of other sessions reusing the export ID are left out
+		exportStep(t, "other", "660e8400-e29b-41d4-a716-446655440000", 10, ExportLifecycleDetails{ExportID: "export-7f3a", Status: ExportFailed}),
+		{ID: "request", SessionID: testExportSession, UserID: "user-123", Type: string(ActionExport),
+			Timestamp: testExportTime.Add(-2 * time.Second), CorrelationID: "export-7f3a"},
+	}
+
+	record, ok := NewExportRecord("export-7f3a", entries)
+	require.True(t, ok)
+
+	assert.Equal(t, testExportSession, record.SessionID)
+	assert.Equal(t, "user-123", record.UserID)
+	assert.Equal(t, ExportUploaded, record.Status)
+	assert.Equal(t, "pptx", record.Format)
+	assert.Equal(t, &slides, record.SlideCount)
+	assert.Equal(t, "https://storage.example.com/export-7f3a.pptx", record.URL)
+	assert.Equal(t, int64(482133), record.SizeBytes)
+	assert.Equal(t, "request", record.RequestEventID)
+	require.NotNil(t, record.RequestedAt)
+	assert.Equal(t, testExportTime.Add(-2*time.Second), *record.RequestedAt)
+	require.NotNil(t, record.CompletedAt)
+	assert.Equal(t, testExportTime.Add(20*time.Second), *record.CompletedAt)
+	require.NotNil(t, record.DurationMs)
+	assert.Equal(t, int64(20000), *record.DurationMs)
+
+	statuses := make([]ExportStatus, len(record.Steps))
+	for i, step := range record.Steps {
+		statuses[i] = step.Status
+	}
+	assert.Equal(t, []ExportStatus{ExportQueued, ExportRendering, ExportUploaded, ExportRendering}, statuses)
+	assert.True(t, record.HasStep(ExportUploaded))
+	assert.False(t, record.HasStep(ExportFailed))
+}
+
+func TestNewExportRecord_InProgressAndFailed(t *testing.T) {
+	record, ok := NewExportRecord("export-1", []AuditEntry{
+		exportStep(t, "step-1", testExportSession, 0, ExportLifecycleDetails{ExportID: "export-1", Status: ExportQueued}),
+	})
+	require.True(t, ok)
+	assert.Equal(t, ExportQueued, record.Status)
+	assert.Nil(t, record.CompletedAt)
+	assert.Nil(t, record.DurationMs)
+	assert.Nil(t, record.RequestedAt)
+
+	record, ok = NewExportRecord("export-1", []AuditEntry{
+		exportStep(t, "step-1", testExportSession, 0, ExportLifecycleDetails{ExportID: "export-1", Status: ExportQueued}),
+		exportStep(t, "step-2", testExportSession, 3, ExportLifecycleDetails{ExportID: "export-1", Status: ExportFailed, Error: "font not found"}),
+	})
+	require.True(t, ok)
+	assert.Equal(t, ExportFailed, record.Status)
+	assert.Equal(t, "font not found", record.Error)
+	assert.Equal(t, "font not found", record.Steps[1].Error)
+
+	// The export event of the frontend alone is no export record
+	_, ok = NewExportRecord("export-1", []AuditEntry{
+		{ID: "request", SessionID: testExportSession, Type: string(ActionExport), CorrelationID: "export-1"},
+	})
+	assert.False(t, ok)
+}
+
+func TestExportCallback_Validate(t *testing.T) {
+	negative := -1
+	valid := ExportCallback{ExportID: "export-7f3a", SessionID: testExportSession, UserID: "user-123", Status: ExportQueued}
+	require.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(*ExportCallback)
+	}{
+		{"export ID with spaces", func(c *ExportCallback) { c.ExportID = "export 7f3a" }},
+		{"session ID not a UUID", func(c *ExportCallback) { c.SessionID = "session-1" }},
+		{"missing user", func(c *ExportCallback) { c.UserID = "" }},
+		{"unknown status", func(c *ExportCallback) { c.Status = "downloaded" }},
+		{"negative slide count", func(c *ExportCallback) { c.SlideCount = &negative }},
+		{"negative size", func(c *ExportCallback) { c.SizeBytes = -1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			callback := valid
+			tt.modify(&callback)
+			assert.ErrorIs(t, callback.Validate(), ErrInvalidExportCallback)
+		})
+	}
+}
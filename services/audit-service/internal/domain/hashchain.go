@@ -0,0 +1,181 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Problems reported by chain verification
+const (
+	ChainContentMismatch = "content_mismatch"
+	ChainHashMismatch    = "chain_mismatch"
+	ChainLinkMismatch    = "link_mismatch"
+	ChainMissingEntries  = "missing_entries"
+)
+
+// ChainedEntry is a stored audit entry with its position and hashes in the session chain
+type ChainedEntry struct {
+	AuditEntry
+	Seq         int64
+	ContentHash string
+	PrevHash    string
+	ChainHash   string
+	// RedactedAt is set when personal data was removed after the entry was hashed
+	RedactedAt *time.Time
+}
+
+// ChainIssue describes one integrity problem found in a session chain
+type ChainIssue struct {
+	Seq     int64  `json:"seq" example:"17"`
+	EventID string `json:"eventId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Problem string `json:"problem" example:"content_mismatch"`
+	Message string `json:"message" example:"stored entry does not match its content hash"`
+}
+
+// ChainVerification is the result of validating the hash chain of a session
+type ChainVerification struct {
+	SessionID      string `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Valid          bool   `json:"valid" example:"true"`
+	CheckedEntries int    `json:"checkedEntries" example:"42"`
+	// RedactedEntries were anonymized on request; their links are checked but not their content
+	RedactedEntries int          `json:"redactedEntries" example:"0"`
+	FirstSeq        int64        `json:"firstSeq,omitempty" example:"1"`
+	LastSeq         int64        `json:"lastSeq,omitempty" example:"42"`
+	HeadHash        string       `json:"headHash,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	Issues          []ChainIssue `json:"issues"`
+}
+
+// canonicalEntry fixes the field order and formatting of the hashed payload
+type canonicalEntry struct {
+	ID        string      `json:"id"`
+	SessionID string      `json:"sessionId"`
+	UserID    string      `json:"userId"`
+	Type      string      `json:"type"`
+	Timestamp string      `json:"timestamp"`
+	Details   interface{} `json:"details"`
+	IPAddress string      `json:"ipAddress"`
+	UserAgent string      `json:"userAgent"`
+	// Omitted when empty so hashes of entries written before these fields existed stay unchanged
+	CorrelationID string `json:"correlationId,omitempty"`
+	ParentEventID string `json:"parentEventId,omitempty"`
+	SchemaVersion int    `json:"schemaVersion,omitempty"`
+	// RedactedFields is omitted when empty for the same reason
+	RedactedFields []string `json:"redactedFields,omitempty"`
+	OrganizationID string   `json:"organizationId,omitempty"`
+	ReceivedAt     string   `json:"receivedAt,omitempty"`
+}
+
+// ContentHash returns the SHA-256 of the canonical form of an entry.
+// The form survives a database round trip: UUIDs are lower-cased, timestamps are cut to
+// microseconds and details are re-encoded with sorted keys.
+func ContentHash(entry AuditEntry) (string, error) {
+	details, err := DecodeDetails[interface{}](entry)
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(canonicalEntry{
+		ID:        strings.ToLower(entry.ID),
+		SessionID: strings.ToLower(entry.SessionID),
+		UserID:    entry.UserID,
+		Type:      entry.Type,
+		Timestamp: entry.Timestamp.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+		Details:   details,
+		IPAddress: entry.IPAddress,
+		UserAgent: entry.UserAgent,
+		// Parent IDs are UUIDs; correlation IDs are opaque and kept as sent
+		CorrelationID:  entry.CorrelationID,
+		ParentEventID:  strings.ToLower(entry.ParentEventID),
+		SchemaVersion:  entry.SchemaVersion,
+		RedactedFields: entry.RedactedFields,
+		OrganizationID: entry.OrganizationID,
+		ReceivedAt:     canonicalTime(entry.ReceivedAt),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalTime formats an optional time like the entry timestamp, or empty when unset
+func canonicalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+}
+
+// ChainHash links an entry to its predecessor; it matches the audit_logs_chain database trigger
+func ChainHash(prevHash, contentHash string) string {
+	sum := sha256.Sum256([]byte(prevHash + contentHash))
+	return hex.EncodeToString(sum[:])
+}
+
+// ChainVerifier checks session entries fed to it in sequence order
+type ChainVerifier struct {
+	result ChainVerification
+	last   *ChainedEntry
+}
+
+// NewChainVerifier creates a verifier for a session
+func NewChainVerifier(sessionID string) *ChainVerifier {
+	return &ChainVerifier{
+		result: ChainVerification{SessionID: sessionID, Issues: []ChainIssue{}},
+	}
+}
+
+// Add verifies the next entry of the chain
+func (v *ChainVerifier) Add(entry ChainedEntry) {
+	v.result.CheckedEntries++
+	if v.result.FirstSeq == 0 {
+		v.result.FirstSeq = entry.Seq
+	}
+	v.result.LastSeq = entry.Seq
+	v.result.HeadHash = entry.ChainHash
+
+	// The stored payload must still produce the hash recorded at insert time
+	if entry.RedactedAt != nil {
+		v.result.RedactedEntries++
+	} else if hash, err := ContentHash(entry.AuditEntry); err != nil || hash != entry.ContentHash {
+		v.addIssue(entry, ChainContentMismatch, "stored entry does not match its content hash")
+	}
+
+	if ChainHash(entry.PrevHash, entry.ContentHash) != entry.ChainHash {
+		v.addIssue(entry, ChainHashMismatch, "chain hash does not match the previous hash and content hash")
+	}
+
+	// Neighbouring entries must link; a sequence gap means entries were removed
+	if v.last != nil {
+		switch {
+		case entry.Seq != v.last.Seq+1:
+			v.addIssue(entry, ChainMissingEntries,
+				fmt.Sprintf("entries %d to %d are missing", v.last.Seq+1, entry.Seq-1))
+		case entry.PrevHash != v.last.ChainHash:
+			v.addIssue(entry, ChainLinkMismatch, "previous hash does not match the preceding entry")
+		}
+	}
+
+	v.last = &entry
+}
+
+func (v *ChainVerifier) addIssue(entry ChainedEntry, problem, message string) {
+	v.result.Issues = append(v.result.Issues, ChainIssue{
+		Seq:     entry.Seq,
+		EventID: entry.ID,
+		Problem: problem,
+		Message: message,
+	})
+}
+
+// Result returns the verification outcome for all entries added so far
+func (v *ChainVerifier) Result() *ChainVerification {
+	result := v.result
+	result.Valid = len(result.Issues) == 0
+	return &result
+}
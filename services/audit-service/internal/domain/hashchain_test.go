@@ -0,0 +1,206 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func chainEntry(id string, details string) AuditEntry {
+	return AuditEntry{
+		ID:        id,
+		SessionID: "550e8400-e29b-41d4-a716-446655440001",
+		UserID:    "user-1",
+		Type:      "edit",
+		Timestamp: time.Date(2024, 1, 1, 10, 0, 0, 123456789, time.UTC),
+		Details:   json.RawMessage(details),
+		IPAddress: "203.0.113.7",
+	}
+}
+
+// buildChain links entries the same way the database trigger does
+func buildChain(t *testing.T, entries ...AuditEntry) []ChainedEntry {
+	chain := make([]ChainedEntry, len(entries))
+	prev := ""
+	for i, entry := range entries {
+		hash, err := ContentHash(entry)
+		require.NoError(t, err)
+		chain[i] = ChainedEntry{
+			AuditEntry:  entry,
+			Seq:         int64(i + 1),
+			ContentHash: hash,
+			PrevHash:    prev,
+			ChainHash:   ChainHash(prev, hash),
+		}
+		prev = chain[i].ChainHash
+	}
+	return chain
+}
+
+func TestContentHash_SurvivesDatabaseRoundTrip(t *testing.T) {
+	written := chainEntry("550E8400-E29B-41D4-A716-446655440000", `{"slideId":"slide-1","changes":{"b":1.50,"a":"é"}}`)
+
+	// jsonb reorders keys and reformats values; timestamptz keeps microseconds
+	read := written
+	read.ID = "550e8400-e29b-41d4-a716-446655440000"
+	read.Details = json.RawMessage(`{"changes": {"a": "é", "b": 1.5}, "slideId": "slide-1"}`)
+	read.Timestamp = time.Date(2024, 1, 1, 11, 0, 0, 123456000, time.FixedZone("CET", 3600))
+
+	writtenHash, err := ContentHash(written)
+	require.NoError(t, err)
+	readHash, err := ContentHash(read)
+	require.NoError(t, err)
+
+	assert.Equal(t, writtenHash, readHash)
+	assert.Len(t, writtenHash, 64)
+}
+
+func TestContentHash_ChangesWithPayload(t *testing.T) {
+	original, err := ContentHash(chainEntry("audit-1", `{"slideId":"slide-1"}`))
+	require.NoError(t, err)
+	edited, err := ContentHash(chainEntry("audit-1", `{"slideId":"slide-2"}`))
+	require.NoError(t, err)
+
+	assert.NotEqual(t, original, edited)
+
+	_, err = ContentHash(chainEntry("audit-1", `{invalid`))
+	assert.Error(t, err)
+}
+
+func TestContentHash_CoversCausalLinks(t *testing.T) {
+	plain := chainEntry("audit-1", `{"slideId":"slide-1"}`)
+	plainHash, err := ContentHash(plain)
+	require.NoError(t, err)
+
+	linked := plain
+	linked.CorrelationID = "export-7f3a"
+	linked.ParentEventID = "550E8400-E29B-41D4-A716-446655440003"
+	linkedHash, err := ContentHash(linked)
+	require.NoError(t, err)
+	assert.NotEqual(t, plainHash, linkedHash)
+
+	// The parent UUID is stored lower-cased
+	linked.ParentEventID = "550e8400-e29b-41d4-a716-446655440003"
+	readHash, err := ContentHash(linked)
+	require.NoError(t, err)
+	assert.Equal(t, linkedHash, readHash)
+}
+
+func TestContentHash_CoversReceivedTime(t *testing.T) {
+	plain := chainEntry("audit-1", `{"slideId":"slide-1"}`)
+	plainHash, err := ContentHash(plain)
+	require.NoError(t, err)
+
+	received := time.Date(2024, 1, 1, 10, 0, 2, 123456789, time.UTC)
+	stamped := plain
+	stamped.ReceivedAt = &received
+	stampedHash, err := ContentHash(stamped)
+	require.NoError(t, err)
+	assert.NotEqual(t, plainHash, stampedHash)
+
+	// timestamptz keeps microseconds
+	read := received.Truncate(time.Microsecond).In(time.FixedZone("CET", 3600))
+	stamped.ReceivedAt = &read
+	readHash, err := ContentHash(stamped)
+	require.NoError(t, err)
+	assert.Equal(t, stampedHash, readHash)
+}
+
+func TestChainHash_KnownValue(t *testing.T) {
+	// sha256("") matches encode(sha256(''::bytea), 'hex') in PostgreSQL
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", ChainHash("", ""))
+}
+
+func TestChainVerifier(t *testing.T) {
+	entries := []AuditEntry{
+		chainEntry("audit-1", `{"slideId":"slide-1"}`),
+		chainEntry("audit-2", `{"slideId":"slide-2"}`),
+		chainEntry("audit-3", `{"slideId":"slide-3"}`),
+	}
+
+	verify := func(chain []ChainedEntry) *ChainVerification {
+		v := NewChainVerifier("session-1")
+		for _, entry := range chain {
+			v.Add(entry)
+		}
+		return v.Result()
+	}
+
+	t.Run("intact", func(t *testing.T) {
+		chain := buildChain(t, entries...)
+		result := verify(chain)
+
+		assert.True(t, result.Valid)
+		assert.Equal(t, 3, result.CheckedEntries)
+		assert.Equal(t, int64(1), result.FirstSeq)
+		assert.Equal(t, int64(3), result.LastSeq)
+		assert.Equal(t, chain[2].ChainHash, result.HeadHash)
+		assert.Empty(t, result.Issues)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		result := verify(nil)
+
+		assert.True(t, result.Valid)
+		assert.Zero(t, result.CheckedEntries)
+		assert.NotNil(t, result.Issues)
+	})
+
+	t.Run("edited_details", func(t *testing.T) {
+		chain := buildChain(t, entries...)
+		chain[1].Details = json.RawMessage(`{"slideId":"slide-9"}`)
+		result := verify(chain)
+
+		assert.False(t, result.Valid)
+		assert.Equal(t, []ChainIssue{{Seq: 2, EventID: "audit-2", Problem: ChainContentMismatch,
+			Message: "stored entry does not match its content hash"}}, result.Issues)
+	})
+
+	t.Run("rehashed_entry_breaks_link", func(t *testing.T) {
+		chain := buildChain(t, entries...)
+		// Recomputing the content and chain hash of an edited entry still breaks the next link
+		chain[1].Details = json.RawMessage(`{"slideId":"slide-9"}`)
+		chain[1].ContentHash, _ = ContentHash(chain[1].AuditEntry)
+		chain[1].ChainHash = ChainHash(chain[1].PrevHash, chain[1].ContentHash)
+		result := verify(chain)
+
+		assert.False(t, result.Valid)
+		require.Len(t, result.Issues, 1)
+		assert.Equal(t, int64(3), result.Issues[0].Seq)
+		assert.Equal(t, ChainLinkMismatch, result.Issues[0].Problem)
+	})
+
+	t.Run("redacted_entry", func(t *testing.T) {
+		chain := buildChain(t, entries...)
+		redactedAt := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		chain[1].UserID = RedactedUserID
+		chain[1].IPAddress = ""
+		chain[1].RedactedAt = &redactedAt
+		result := verify(chain)
+
+		assert.True(t, result.Valid)
+		assert.Equal(t, 1, result.RedactedEntries)
+	})
+
+	t.Run("deleted_entry", func(t *testing.T) {
+		chain := buildChain(t, entries...)
+		result := verify([]ChainedEntry{chain[0], chain[2]})
+
+		assert.False(t, result.Valid)
+		require.Len(t, result.Issues, 1)
+		assert.Equal(t, ChainMissingEntries, result.Issues[0].Problem)
+		assert.Equal(t, "entries 2 to 2 are missing", result.Issues[0].Message)
+	})
+
+	t.Run("tampered_chain_hash", func(t *testing.T) {
+		chain := buildChain(t, entries...)
+		chain[2].ChainHash = "0000"
+		result := verify(chain)
+
+		assert.False(t, result.Valid)
+		assert.Equal(t, ChainHashMismatch, result.Issues[0].Problem)
+	})
+}
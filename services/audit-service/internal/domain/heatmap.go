@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultHeatmapRange is the range of a heatmap query that sets none, ending with the current hour
+const DefaultHeatmapRange = 7 * 24 * time.Hour
+
+// HeatmapQuery selects the hourly event counts per slide of a session
+type HeatmapQuery struct {
+	// From and To bound the hours, From inclusive and To exclusive
+	From time.Time
+	To   time.Time
+	// UserID restricts the counts to one user when set
+	UserID string
+}
+
+// Normalize defaults an unset range to the DefaultHeatmapRange before now, aligns the range to
+// UTC hours and validates it like an hourly activity query
+func (q *HeatmapQuery) Normalize(now time.Time) error {
+	if q.To.IsZero() {
+		q.To = ActivityHour.Truncate(now).Add(time.Hour)
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-DefaultHeatmapRange)
+	}
+
+	hourly := ActivityQuery{Granularity: ActivityHour, From: q.From, To: q.To}
+	if err := hourly.Normalize(now); err != nil {
+		return err
+	}
+	q.From, q.To = hourly.From, hourly.To
+	return nil
+}
+
+// HeatmapCell counts the events recorded on a slide during one UTC hour
+type HeatmapCell struct {
+	SlideID string `json:"slideId" example:"550e8400-e29b-41d4-a716-446655440010"`
+	// SlideIndex is the 1-based position of the slide in the deck; omitted for slides that were
+	// deleted or whose ID is not a slide of the session
+	SlideIndex int       `json:"slideIndex,omitempty" example:"3"`
+	Hour       time.Time `json:"hour" example:"2024-01-02T10:00:00Z"`
+	EventCount int       `json:"eventCount" example:"12"`
+}
+
+// SessionHeatmap is the hourly activity per slide of a session. Events that reference no slide
+// are not counted.
+type SessionHeatmap struct {
+	SessionID   string        `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	From        time.Time     `json:"from" example:"2024-01-01T00:00:00Z"`
+	To          time.Time     `json:"to" example:"2024-01-08T00:00:00Z"`
+	TotalEvents int           `json:"totalEvents" example:"240"`
+	Cells       []HeatmapCell `json:"cells"`
+}
+
+// NewSessionHeatmap totals the cells and orders them by slide index, slides without an index
+// last, then by slide ID and hour
+func NewSessionHeatmap(sessionID string, query HeatmapQuery, cells []HeatmapCell) *SessionHeatmap {
+	heatmap := &SessionHeatmap{SessionID: sessionID, From: query.From, To: query.To, Cells: cells}
+	if heatmap.Cells == nil {
+		heatmap.Cells = []HeatmapCell{}
+	}
+
+	for _, cell := range heatmap.Cells {
+		heatmap.TotalEvents += cell.EventCount
+	}
+	sort.SliceStable(heatmap.Cells, func(i, j int) bool {
+		a, b := heatmap.Cells[i], heatmap.Cells[j]
+		if a.SlideIndex != b.SlideIndex {
+			if a.SlideIndex == 0 || b.SlideIndex == 0 {
+				return b.SlideIndex == 0
+			}
+			return a.SlideIndex < b.SlideIndex
+		}
+		if a.SlideID != b.SlideID {
+			return a.SlideID < b.SlideID
+		}
+		return a.Hour.Before(b.Hour)
+	})
+	return heatmap
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeatmapQuery_Normalize(t *testing.T) {
+	now := time.Date(2024, 3, 10, 14, 25, 0, 0, time.UTC)
+
+	t.Run("defaults to the last week", func(t *testing.T) {
+		query := HeatmapQuery{}
+		require.NoError(t, query.Normalize(now))
+		assert.Equal(t, time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC), query.To)
+		assert.Equal(t, time.Date(2024, 3, 3, 15, 0, 0, 0, time.UTC), query.From)
+	})
+
+	t.Run("aligns the range to hours", func(t *testing.T) {
+		query := HeatmapQuery{From: time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC), To: time.Date(2024, 3, 1, 10, 5, 0, 0, time.UTC)}
+		require.NoError(t, query.Normalize(now))
+		assert.Equal(t, time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC), query.From)
+		assert.Equal(t, time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC), query.To)
+	})
+
+	t.Run("rejects ranges over the bucket limit", func(t *testing.T) {
+		query := HeatmapQuery{From: now.Add(-(MaxActivityBuckets + 1) * time.Hour)}
+		assert.ErrorIs(t, query.Normalize(now), ErrInvalidActivityQuery)
+	})
+}
+
+func TestNewSessionHeatmap(t *testing.T) {
+	hour := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	query := HeatmapQuery{From: hour, To: hour.Add(2 * time.Hour)}
+
+	heatmap := NewSessionHeatmap("session-1", query, []HeatmapCell{
+		{SlideID: "deleted", Hour: hour, EventCount: 1},
+		{SlideID: "slide-2", SlideIndex: 2, Hour: hour.Add(time.Hour), EventCount: 4},
+		{SlideID: "slide-2", SlideIndex: 2, Hour: hour, EventCount: 3},
+		{SlideID: "slide-1", SlideIndex: 1, Hour: hour, EventCount: 2},
+	})
+
+	assert.Equal(t, 10, heatmap.TotalEvents)
+	assert.Equal(t, []HeatmapCell{
+		{SlideID: "slide-1", SlideIndex: 1, Hour: hour, EventCount: 2},
+		{SlideID: "slide-2", SlideIndex: 2, Hour: hour, EventCount: 3},
+		{SlideID: "slide-2", SlideIndex: 2, Hour: hour.Add(time.Hour), EventCount: 4},
+		{SlideID: "deleted", Hour: hour, EventCount: 1},
+	}, heatmap.Cells)
+
+	assert.NotNil(t, NewSessionHeatmap("session-1", query, nil).Cells)
+}
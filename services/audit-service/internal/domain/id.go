@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// SessionID identifies a translation session
+type SessionID string
+
+// UserID identifies a user
+type UserID string
+
+// EventID identifies an audit event
+type EventID string
+
+// TestSessionPrefix starts the IDs of the sessions recorded to the in-memory test store instead
+// of storage
+const TestSessionPrefix = "test-"
+
+// ParseSessionID parses a session ID, see parseID
+func ParseSessionID(s string) (SessionID, error) {
+	id, err := parseID(s)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidSessionID, err)
+	}
+	return SessionID(id), nil
+}
+
+// ParseUserID parses a user ID, see parseID
+func ParseUserID(s string) (UserID, error) {
+	id, err := parseID(s)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidUserID, err)
+	}
+	return UserID(id), nil
+}
+
+// ParseEventID parses an event ID, see parseID
+func ParseEventID(s string) (EventID, error) {
+	id, err := parseID(s)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidEventID, err)
+	}
+	return EventID(id), nil
+}
+
+// parseID accepts an RFC 4122 UUID in the 36-character hyphenated form, in either case, and
+// returns it in lowercase. Other forms google/uuid understands, such as URNs or braces, and the
+// nil UUID are rejected.
+func parseID(s string) (string, error) {
+	if len(s) != 36 {
+		return "", fmt.Errorf("%q is not a UUID", s)
+	}
+	parsed, err := uuid.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("%q is not a UUID", s)
+	}
+	if parsed == uuid.Nil {
+		return "", fmt.Errorf("the nil UUID is not an ID")
+	}
+	if parsed.Variant() != uuid.RFC4122 {
+		return "", fmt.Errorf("%q is not an RFC 4122 UUID", s)
+	}
+	return parsed.String(), nil
+}
+
+// IsTestSession reports whether a session ID belongs to the in-memory test store
+func (id SessionID) IsTestSession() bool {
+	return strings.HasPrefix(string(id), TestSessionPrefix)
+}
+
+// String returns the ID as a string
+func (id SessionID) String() string { return string(id) }
+
+// String returns the ID as a string
+func (id UserID) String() string { return string(id) }
+
+// String returns the ID as a string
+func (id EventID) String() string { return string(id) }
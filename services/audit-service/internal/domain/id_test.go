@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSessionID(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		expected SessionID
+		valid    bool
+	}{
+		{name: "valid UUID", id: "550e8400-e29b-41d4-a716-446655440000", expected: "550e8400-e29b-41d4-a716-446655440000", valid: true},
+		{name: "uppercase is lowered", id: "550E8400-E29B-41D4-A716-446655440000", expected: "550e8400-e29b-41d4-a716-446655440000", valid: true},
+		{name: "invalid length", id: "550e8400-e29b-41d4-a716"},
+		{name: "missing hyphens", id: "550e8400e29b41d4a716446655440000"},
+		{name: "hyphens in the wrong place", id: "550e840-0e29b-41d4-a716-446655440000"},
+		{name: "invalid characters", id: "550e8400-e29b-41d4-a716-44665544000g"},
+		{name: "URN", id: "urn:uuid:550e8400-e29b-41d4-a716-446655440000"},
+		{name: "braces", id: "{550e8400-e29b-41d4-a716-44665544000}"},
+		{name: "nil UUID", id: "00000000-0000-0000-0000-000000000000"},
+		{name: "NCS variant", id: "550e8400-e29b-41d4-0716-446655440000"},
+		{name: "test session", id: "test-session-1"},
+		{name: "empty string", id: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := ParseSessionID(tt.id)
+			if !tt.valid {
+				assert.ErrorIs(t, err, ErrInvalidSessionID)
+				assert.Empty(t, id)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, id)
+		})
+	}
+}
+
+func TestParseUserIDAndEventID(t *testing.T) {
+	userID, err := ParseUserID("7C9E6679-7425-40DE-944B-E07FC1F90AE7")
+	require.NoError(t, err)
+	assert.Equal(t, UserID("7c9e6679-7425-40de-944b-e07fc1f90ae7"), userID)
+
+	_, err = ParseUserID(RedactedUserID)
+	assert.True(t, errors.Is(err, ErrInvalidUserID))
+
+	eventID, err := ParseEventID("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+	require.NoError(t, err)
+	assert.Equal(t, "7c9e6679-7425-40de-944b-e07fc1f90ae7", eventID.String())
+
+	_, err = ParseEventID("00000000-0000-0000-0000-000000000000")
+	assert.True(t, errors.Is(err, ErrInvalidEventID))
+}
+
+func TestSessionID_IsTestSession(t *testing.T) {
+	assert.True(t, SessionID("test-session-1").IsTestSession())
+	assert.False(t, SessionID("550e8400-e29b-41d4-a716-446655440000").IsTestSession())
+}
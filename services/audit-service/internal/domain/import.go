@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ImportStatus is the progress of an import job
+type ImportStatus string
+
+// Import job states
+const (
+	ImportPending   ImportStatus = "pending"
+	ImportRunning   ImportStatus = "running"
+	ImportCompleted ImportStatus = "completed"
+	ImportFailed    ImportStatus = "failed"
+)
+
+// ImportSource records how the imported file was provided
+type ImportSource string
+
+// Import sources
+const (
+	// ImportUpload is a file uploaded with the import request
+	ImportUpload ImportSource = "upload"
+	// ImportURL is a file fetched from a signed URL
+	ImportURL ImportSource = "url"
+)
+
+// MaxImportErrors caps the rejected lines listed on an import job
+const MaxImportErrors = 100
+
+var (
+	// ErrImportJobNotFound is returned for unknown or expired import job IDs
+	ErrImportJobNotFound = errors.New("import job not found")
+	// ErrInvalidImport is returned for import requests that cannot be started
+	ErrInvalidImport = errors.New("invalid import")
+)
+
+// ImportLineError is a line of an import file that was rejected
+type ImportLineError struct {
+	Line    int    `json:"line" example:"42"`
+	Message string `json:"message" example:"timestamp is required"`
+}
+
+// ImportJob tracks an asynchronous import of historical audit events
+type ImportJob struct {
+	ID     string       `json:"id" example:"3f1e7c2a-9b4d-4e8f-a6c5-1d2e3f4a5b6c"`
+	Source ImportSource `json:"source" example:"upload"`
+	Status ImportStatus `json:"status" example:"running"`
+	// DryRun jobs validate the file and look for duplicates without storing anything
+	DryRun bool `json:"dryRun"`
+	// BytesRead and TotalBytes are the compressed bytes of the file; TotalBytes is zero when unknown
+	BytesRead  int64 `json:"bytesRead" example:"1048576"`
+	TotalBytes int64 `json:"totalBytes,omitempty" example:"4194304"`
+	// Lines counts the non-empty lines read so far
+	Lines    int `json:"lines" example:"12000"`
+	Imported int `json:"imported" example:"11950"`
+	// Duplicates are events already stored, or repeated in the file, which were skipped
+	Duplicates int `json:"duplicates" example:"40"`
+	Invalid    int `json:"invalid" example:"10"`
+	// Errors lists the first MaxImportErrors rejected lines
+	Errors      []ImportLineError `json:"errors,omitempty"`
+	RequestedBy string            `json:"requestedBy" example:"550e8400-e29b-41d4-a716-446655440003"`
+	// Error is why a failed job stopped; events imported before are kept
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt" example:"2024-01-01T10:00:00Z"`
+	CompletedAt *time.Time `json:"completedAt,omitempty" example:"2024-01-01T10:04:00Z"`
+	// OrganizationID is the organization the events are imported into; empty for the default organization
+	OrganizationID string `json:"organizationId,omitempty" example:"acme"`
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxEventLabels is the most labels an event can carry, and the most a filter can name
+	MaxEventLabels = 20
+
+	// MaxLabelLength is the longest accepted label
+	MaxLabelLength = 50
+)
+
+// ErrInvalidLabel is returned for labels that are not lowercase slugs and for too many labels
+var ErrInvalidLabel = errors.New("invalid label")
+
+// labelPattern matches labels such as "billing-relevant" or "disputed"
+var labelPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidLabel reports whether a label is a lowercase slug of letters, digits, hyphens and
+// underscores, starting with a letter or digit
+func ValidLabel(label string) bool {
+	return len(label) <= MaxLabelLength && labelPattern.MatchString(label)
+}
+
+// EventLabels is the label set reviewers keep on an audit event, such as "billing-relevant" or
+// "disputed". Labels are stored beside the event, which stays unchanged, and the set is
+// versioned: each change moves it to the next version.
+type EventLabels struct {
+	EventID   string `json:"eventId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	SessionID string `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Labels are sorted; empty for events never labeled or whose labels were all removed
+	Labels []string `json:"labels" example:"billing-relevant,disputed"`
+	// Version counts the changes of the label set; 0 for events never labeled
+	Version   int64      `json:"version" example:"2"`
+	UpdatedBy string     `json:"updatedBy,omitempty" example:"550e8400-e29b-41d4-a716-446655440003"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty" example:"2024-01-02T10:00:00Z"`
+	// OrganizationID is the organization of the event; empty for the default organization
+	OrganizationID string `json:"organizationId,omitempty" example:"acme"`
+}
+
+// NormalizeLabels trims and lowercases labels, drops duplicates and sorts them. It returns
+// ErrInvalidLabel for labels that are not slugs and for more than MaxEventLabels labels.
+func NormalizeLabels(labels []string) ([]string, error) {
+	normalized := make([]string, 0, len(labels))
+	for _, label := range labels {
+		label = strings.ToLower(strings.TrimSpace(label))
+		if !ValidLabel(label) {
+			return nil, fmt.Errorf("%w: %q must be a lowercase slug of at most %d characters", ErrInvalidLabel, label, MaxLabelLength)
+		}
+		normalized = append(normalized, label)
+	}
+
+	slices.Sort(normalized)
+	normalized = slices.Compact(normalized)
+	if len(normalized) > MaxEventLabels {
+		return nil, fmt.Errorf("%w: at most %d labels are allowed", ErrInvalidLabel, MaxEventLabels)
+	}
+	return normalized, nil
+}
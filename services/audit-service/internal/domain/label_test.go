@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeLabels(t *testing.T) {
+	labels, err := NormalizeLabels([]string{" Disputed", "billing-relevant", "disputed", "q3_review"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"billing-relevant", "disputed", "q3_review"}, labels)
+
+	labels, err = NormalizeLabels(nil)
+	require.NoError(t, err)
+	assert.Empty(t, labels)
+}
+
+func TestNormalizeLabels_Invalid(t *testing.T) {
+	tooMany := make([]string, MaxEventLabels+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("label-%d", i)
+	}
+
+	tests := map[string][]string{
+		"empty":            {""},
+		"spaces":           {"billing relevant"},
+		"leading hyphen":   {"-disputed"},
+		"punctuation":      {"disputed!"},
+		"too long":         {strings.Repeat("a", MaxLabelLength+1)},
+		"too many labels":  tooMany,
+		"one invalid of 2": {"disputed", "über"},
+	}
+
+	for name, labels := range tests {
+		t.Run(name, func(t *testing.T) {
+			normalized, err := NormalizeLabels(labels)
+			assert.Nil(t, normalized)
+			assert.ErrorIs(t, err, ErrInvalidLabel)
+		})
+	}
+}
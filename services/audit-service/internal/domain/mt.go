@@ -0,0 +1,188 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// MaxMTCostEvents caps the machine translation requests counted for one cost rollup; the rollup
+// is marked truncated when a session has more
+const MaxMTCostEvents = 100000
+
+// DefaultMTCurrency is the currency of cost estimates that name none
+const DefaultMTCurrency = "USD"
+
+// MTCostQuery restricts an MT cost rollup to a time range; zero bounds are open
+type MTCostQuery struct {
+	// From and To bound the counted requests, both inclusive
+	From time.Time
+	To   time.Time
+}
+
+// Validate ensures the range is ordered
+func (q MTCostQuery) Validate() error {
+	if !q.From.IsZero() && !q.To.IsZero() && q.From.After(q.To) {
+		return fmt.Errorf("%w: from must not be after to", ErrInvalidFilter)
+	}
+	return nil
+}
+
+// MTRequestDetails are the details of an mt_request event read for the cost rollup
+type MTRequestDetails struct {
+	Provider         string  `json:"provider"`
+	Model            string  `json:"model"`
+	SourceCharacters int64   `json:"sourceCharacters"`
+	TargetCharacters int64   `json:"targetCharacters"`
+	LatencyMs        int64   `json:"latencyMs"`
+	CostEstimate     float64 `json:"costEstimate"`
+	Currency         string  `json:"currency"`
+	// Error is set when the provider call failed
+	Error string `json:"error"`
+}
+
+// MTProviderCost rolls up the requests sent to one model of a provider, in one currency
+type MTProviderCost struct {
+	Provider string `json:"provider" example:"deepl"`
+	// Model is empty for requests that named none
+	Model            string  `json:"model,omitempty" example:"next-gen"`
+	Currency         string  `json:"currency" example:"USD"`
+	Requests         int     `json:"requests" example:"42"`
+	FailedRequests   int     `json:"failedRequests" example:"1"`
+	SourceCharacters int64   `json:"sourceCharacters" example:"51200"`
+	TargetCharacters int64   `json:"targetCharacters" example:"55874"`
+	CostEstimate     float64 `json:"costEstimate" example:"1.024"`
+	// AverageLatencyMs and MaxLatencyMs cover the requests that reported a latency
+	AverageLatencyMs int64 `json:"averageLatencyMs" example:"640"`
+	MaxLatencyMs     int64 `json:"maxLatencyMs" example:"2310"`
+}
+
+// MTCurrencyCost totals the cost estimates in one currency
+type MTCurrencyCost struct {
+	Currency     string  `json:"currency" example:"USD"`
+	CostEstimate float64 `json:"costEstimate" example:"1.524"`
+}
+
+// SessionMTCosts rolls up the machine translation spend of a session from its mt_request events
+type SessionMTCosts struct {
+	SessionID string `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	// From and To repeat the requested range; omitted when open
+	From             *time.Time `json:"from,omitempty" example:"2024-01-01T00:00:00Z"`
+	To               *time.Time `json:"to,omitempty" example:"2024-01-31T23:59:59Z"`
+	Requests         int        `json:"requests" example:"60"`
+	FailedRequests   int        `json:"failedRequests" example:"1"`
+	SourceCharacters int64      `json:"sourceCharacters" example:"73400"`
+	TargetCharacters int64      `json:"targetCharacters" example:"80112"`
+	// Costs totals the estimates per currency, as estimates in different currencies are not added up
+	Costs []MTCurrencyCost `json:"costs"`
+	// Providers lists the provider and model pairs, the most expensive first
+	Providers []MTProviderCost `json:"providers"`
+	// Truncated is set when the session had more than MaxMTCostEvents requests in the range
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// MTCostTally rolls up machine translation requests into a cost report
+type MTCostTally struct {
+	report *SessionMTCosts
+	// providers are keyed by provider, model and currency
+	providers map[[3]string]*mtProviderTally
+	costs     map[string]float64
+}
+
+// mtProviderTally is the rollup of a provider and the latencies averaged into it
+type mtProviderTally struct {
+	MTProviderCost
+	latencySum   int64
+	latencyCount int64
+}
+
+// NewMTCostTally starts the cost rollup of a session over the query range
+func NewMTCostTally(sessionID string, query MTCostQuery) *MTCostTally {
+	report := &SessionMTCosts{SessionID: sessionID}
+	if !query.From.IsZero() {
+		report.From = &query.From
+	}
+	if !query.To.IsZero() {
+		report.To = &query.To
+	}
+	return &MTCostTally{report: report, providers: make(map[[3]string]*mtProviderTally), costs: make(map[string]float64)}
+}
+
+// Add counts an entry; entries of other types, and requests without a provider, are skipped
+func (t *MTCostTally) Add(entry AuditEntry) {
+	if AuditAction(entry.Type) != ActionMTRequest {
+		return
+	}
+	details, err := DecodeDetails[MTRequestDetails](entry)
+	if err != nil || details.Provider == "" {
+		return
+	}
+	if details.Currency == "" {
+		details.Currency = DefaultMTCurrency
+	}
+
+	key := [3]string{details.Provider, details.Model, details.Currency}
+	provider, ok := t.providers[key]
+	if !ok {
+		provider = &mtProviderTally{MTProviderCost: MTProviderCost{Provider: details.Provider, Model: details.Model, Currency: details.Currency}}
+		t.providers[key] = provider
+	}
+
+	provider.Requests++
+	provider.SourceCharacters += details.SourceCharacters
+	provider.TargetCharacters += details.TargetCharacters
+	provider.CostEstimate += details.CostEstimate
+	if details.Error != "" {
+		provider.FailedRequests++
+	}
+	if details.LatencyMs > 0 {
+		provider.latencySum += details.LatencyMs
+		provider.latencyCount++
+		provider.MaxLatencyMs = max(provider.MaxLatencyMs, details.LatencyMs)
+	}
+	t.costs[details.Currency] += details.CostEstimate
+}
+
+// Report returns the cost rollup of the entries added
+func (t *MTCostTally) Report() *SessionMTCosts {
+	report := t.report
+	report.Providers = make([]MTProviderCost, 0, len(t.providers))
+	for _, provider := range t.providers {
+		report.Requests += provider.Requests
+		report.FailedRequests += provider.FailedRequests
+		report.SourceCharacters += provider.SourceCharacters
+		report.TargetCharacters += provider.TargetCharacters
+		provider.CostEstimate = roundCost(provider.CostEstimate)
+		if provider.latencyCount > 0 {
+			provider.AverageLatencyMs = provider.latencySum / provider.latencyCount
+		}
+		report.Providers = append(report.Providers, provider.MTProviderCost)
+	}
+	sort.Slice(report.Providers, func(i, j int) bool {
+		a, b := report.Providers[i], report.Providers[j]
+		if a.CostEstimate != b.CostEstimate {
+			return a.CostEstimate > b.CostEstimate
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		return a.Currency < b.Currency
+	})
+
+	report.Costs = make([]MTCurrencyCost, 0, len(t.costs))
+	for currency, cost := range t.costs {
+		report.Costs = append(report.Costs, MTCurrencyCost{Currency: currency, CostEstimate: roundCost(cost)})
+	}
+	sort.Slice(report.Costs, func(i, j int) bool { return report.Costs[i].Currency < report.Costs[j].Currency })
+	return report
+}
+
+// roundCost rounds a cost estimate to six decimals, below the price of a single character of
+// most providers
+func roundCost(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
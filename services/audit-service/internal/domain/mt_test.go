@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMTCostTally(t *testing.T) {
+	to := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+	tally := NewMTCostTally("session-1", MTCostQuery{To: to})
+
+	for _, entry := range []AuditEntry{
+		{Type: string(ActionMTRequest), Details: []byte(`{"provider":"deepl","model":"next-gen","sourceCharacters":1000,"targetCharacters":1100,"latencyMs":400,"costEstimate":0.02}`)},
+		{Type: string(ActionMTRequest), Details: []byte(`{"provider":"deepl","model":"next-gen","sourceCharacters":500,"targetCharacters":520,"latencyMs":800,"costEstimate":0.01,"currency":"USD"}`)},
+		{Type: string(ActionMTRequest), Details: []byte(`{"provider":"deepl","model":"next-gen","sourceCharacters":300,"costEstimate":0.006,"error":"timeout"}`)},
+		{Type: string(ActionMTRequest), Details: []byte(`{"provider":"google","sourceCharacters":2000,"targetCharacters":2100,"latencyMs":300,"costEstimate":0.04,"currency":"EUR"}`)},
+		// Requests without a provider, and other types, are skipped
+		{Type: string(ActionMTRequest), Details: []byte(`{"sourceCharacters":100}`)},
+		{Type: string(ActionEdit), Details: []byte(`{"provider":"deepl","sourceCharacters":100}`)},
+	} {
+		tally.Add(entry)
+	}
+
+	report := tally.Report()
+	assert.Equal(t, "session-1", report.SessionID)
+	assert.Nil(t, report.From)
+	assert.Equal(t, &to, report.To)
+	assert.Equal(t, 4, report.Requests)
+	assert.Equal(t, 1, report.FailedRequests)
+	assert.Equal(t, int64(3800), report.SourceCharacters)
+	assert.Equal(t, int64(3720), report.TargetCharacters)
+	assert.Equal(t, []MTCurrencyCost{{Currency: "EUR", CostEstimate: 0.04}, {Currency: "USD", CostEstimate: 0.036}}, report.Costs)
+
+	require.Len(t, report.Providers, 2)
+	assert.Equal(t, MTProviderCost{Provider: "google", Currency: "EUR", Requests: 1, SourceCharacters: 2000, TargetCharacters: 2100,
+		CostEstimate: 0.04, AverageLatencyMs: 300, MaxLatencyMs: 300}, report.Providers[0])
+	assert.Equal(t, MTProviderCost{Provider: "deepl", Model: "next-gen", Currency: "USD", Requests: 3, FailedRequests: 1,
+		SourceCharacters: 1800, TargetCharacters: 1620, CostEstimate: 0.036, AverageLatencyMs: 600, MaxLatencyMs: 800}, report.Providers[1])
+}
+
+func TestMTCostTally_Empty(t *testing.T) {
+	report := NewMTCostTally("session-1", MTCostQuery{}).Report()
+
+	assert.Zero(t, report.Requests)
+	assert.Empty(t, report.Costs)
+	assert.NotNil(t, report.Providers)
+}
+
+func TestMTCostQuery_Validate(t *testing.T) {
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, MTCostQuery{From: from}.Validate())
+	assert.ErrorIs(t, MTCostQuery{From: from, To: from.Add(-time.Hour)}.Validate(), ErrInvalidFilter)
+}
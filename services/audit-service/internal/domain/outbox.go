@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrDeadLetterNotFound is returned for unknown outbox message IDs and for messages that are
+// not dead, such as ones still being retried or already delivered
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// OutboxMessage is an audit event waiting to be forwarded to external consumers
+type OutboxMessage struct {
+	ID        int64
+	EventID   string
+	SessionID string
+	Type      string
+	Payload   json.RawMessage
+	// Attempts counts the deliveries started so far, including the current one
+	Attempts  int
+	CreatedAt time.Time
+}
+
+// NewOutboxPayload returns the event body sent to consumers; the client IP and user agent are not forwarded
+func NewOutboxPayload(entry AuditEntry) (json.RawMessage, error) {
+	entry.IPAddress = ""
+	entry.UserAgent = ""
+	entry.Timestamp = entry.Timestamp.UTC()
+	return json.Marshal(entry)
+}
+
+// DeadLetter is an outbox message that ran out of delivery attempts and waits for an operator
+type DeadLetter struct {
+	ID        int64  `json:"id" example:"42"`
+	EventID   string `json:"eventId" example:"550e8400-e29b-41d4-a716-446655440002"`
+	SessionID string `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Type      string `json:"type" example:"share"`
+	// Payload is the event body posted to the webhooks
+	Payload json.RawMessage `json:"payload" swaggertype:"object"`
+	// Attempts counts the failed deliveries
+	Attempts int `json:"attempts" example:"8"`
+	// LastError is the failure of the last delivery
+	LastError string    `json:"lastError" example:"webhook responded with status 503"`
+	CreatedAt time.Time `json:"createdAt" example:"2024-01-01T10:00:00Z"`
+	DeadAt    time.Time `json:"deadAt" example:"2024-01-01T12:04:00Z"`
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrPayloadTooLarge is returned for request bodies and event details over their size limit
+var ErrPayloadTooLarge = errors.New("payload too large")
+
+// DefaultMaxDetailsSize is the largest details payload of an event accepted by default
+const DefaultMaxDetailsSize = 64 << 10
+
+// Parts of a request whose size is limited
+const (
+	PayloadBody    = "body"
+	PayloadDetails = "details"
+)
+
+// PayloadLimit tells a client how much of a payload to cut to fit its limit
+type PayloadLimit struct {
+	// Field is the part of the request over its limit: body or details
+	Field      string `json:"field" example:"details"`
+	LimitBytes int64  `json:"limitBytes" example:"65536"`
+	// SizeBytes is the size of the payload and ExcessBytes how much of it has to be cut; both are
+	// omitted for streamed bodies, which are not read past the limit
+	SizeBytes   int64 `json:"sizeBytes,omitempty" example:"70000"`
+	ExcessBytes int64 `json:"excessBytes,omitempty" example:"4464"`
+}
+
+// PayloadTooLargeError is returned for a payload over its size limit; a size of zero is unknown
+type PayloadTooLargeError struct {
+	Limit PayloadLimit
+}
+
+// NewPayloadTooLargeError creates the error of a payload of size bytes over limit bytes
+func NewPayloadTooLargeError(field string, size, limit int64) *PayloadTooLargeError {
+	payloadLimit := PayloadLimit{Field: field, LimitBytes: limit}
+	if size > limit {
+		payloadLimit.SizeBytes = size
+		payloadLimit.ExcessBytes = size - limit
+	}
+	return &PayloadTooLargeError{Limit: payloadLimit}
+}
+
+// Error implements the error interface
+func (e *PayloadTooLargeError) Error() string {
+	if e.Limit.SizeBytes == 0 {
+		return fmt.Sprintf("%s exceeds %d bytes", e.Limit.Field, e.Limit.LimitBytes)
+	}
+	return fmt.Sprintf("%s of %d bytes exceeds %d bytes", e.Limit.Field, e.Limit.SizeBytes, e.Limit.LimitBytes)
+}
+
+// Unwrap allows errors.Is(err, ErrPayloadTooLarge)
+func (e *PayloadTooLargeError) Unwrap() error {
+	return ErrPayloadTooLarge
+}
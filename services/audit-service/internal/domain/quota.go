@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrQuotaExceeded is returned for events over the daily quota of their session or user
+var ErrQuotaExceeded = errors.New("event quota exceeded")
+
+// Scopes of the daily event quotas
+const (
+	QuotaScopeSession = "session"
+	QuotaScopeUser    = "user"
+)
+
+// QuotaUsage is the use of one daily event quota. Quotas reset at midnight UTC.
+type QuotaUsage struct {
+	Scope string `json:"scope" example:"session"`
+	// Limit is the number of events accepted per day; 0 is unlimited
+	Limit     int64     `json:"limit" example:"10000"`
+	Used      int64     `json:"used" example:"9950"`
+	Remaining int64     `json:"remaining" example:"50"`
+	ResetAt   time.Time `json:"resetAt" example:"2024-01-02T00:00:00Z"`
+}
+
+// QuotaStatus is the use of the daily quotas of a session and of the user asking
+type QuotaStatus struct {
+	SessionID string     `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID    string     `json:"userId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Session   QuotaUsage `json:"session"`
+	User      QuotaUsage `json:"user"`
+}
+
+// QuotaExceededError is returned when recording Requested events would exceed a quota
+type QuotaExceededError struct {
+	Usage     QuotaUsage
+	Requested int64
+	// RetryAfter is the time left until the quota resets
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("daily %s quota of %d events exceeded: %d used, %d requested",
+		e.Usage.Scope, e.Usage.Limit, e.Usage.Used, e.Requested)
+}
+
+// Unwrap allows errors.Is(err, ErrQuotaExceeded)
+func (e *QuotaExceededError) Unwrap() error {
+	return ErrQuotaExceeded
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ReplayTarget selects where replayed events are sent
+type ReplayTarget string
+
+// Replay targets
+const (
+	// ReplayWebhook posts the events straight to one of the outbox webhooks
+	ReplayWebhook ReplayTarget = "webhook"
+	// ReplayOutbox queues the events in the outbox, which forwards them to every outbox webhook
+	ReplayOutbox ReplayTarget = "outbox"
+)
+
+// ReplayStatus is the progress of a replay job
+type ReplayStatus string
+
+// Replay job states
+const (
+	ReplayPending   ReplayStatus = "pending"
+	ReplayRunning   ReplayStatus = "running"
+	ReplayCompleted ReplayStatus = "completed"
+	ReplayFailed    ReplayStatus = "failed"
+)
+
+var (
+	// ErrReplayJobNotFound is returned for unknown or expired replay job IDs
+	ErrReplayJobNotFound = errors.New("replay job not found")
+	// ErrInvalidReplay is returned for replays with an invalid filter, target or rate
+	ErrInvalidReplay = errors.New("invalid replay")
+)
+
+// Valid reports whether the target is supported
+func (t ReplayTarget) Valid() bool {
+	return t == ReplayWebhook || t == ReplayOutbox
+}
+
+// ReplayJob tracks an asynchronous re-delivery of stored events to downstream consumers
+type ReplayJob struct {
+	ID     string       `json:"id" example:"5c9e1a7b-2d4f-4e8a-b3c6-7f0d1e2a3b4c"`
+	Target ReplayTarget `json:"target" example:"webhook"`
+	// WebhookURL is the outbox webhook the events are posted to with the webhook target
+	WebhookURL string `json:"webhookUrl,omitempty" example:"https://notifications.example.com/audit"`
+	// From and To bound the event timestamps, inclusively
+	From time.Time `json:"from" example:"2024-01-01T00:00:00Z"`
+	To   time.Time `json:"to" example:"2024-01-02T00:00:00Z"`
+	// Types restricts the replay to these event types; empty replays every type
+	Types []string `json:"types,omitempty" example:"share,export"`
+	// SessionID restricts the replay to one session; empty replays every session of the organization
+	SessionID string `json:"sessionId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// RatePerSecond caps the events sent or queued per second
+	RatePerSecond int          `json:"ratePerSecond" example:"50"`
+	Status        ReplayStatus `json:"status" example:"running"`
+	// Total is the number of matching events when the job was accepted
+	Total int `json:"total" example:"1200"`
+	// Replayed counts the events posted to the webhook or queued in the outbox so far
+	Replayed int `json:"replayed" example:"600"`
+	// Skipped counts the events whose outbox message was still waiting for delivery
+	Skipped     int        `json:"skipped,omitempty" example:"3"`
+	RequestedBy string     `json:"requestedBy" example:"550e8400-e29b-41d4-a716-446655440003"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt" example:"2024-01-03T10:00:00Z"`
+	CompletedAt *time.Time `json:"completedAt,omitempty" example:"2024-01-03T10:00:24Z"`
+	// OrganizationID is the organization whose events are replayed; empty for the default organization
+	OrganizationID string `json:"organizationId,omitempty" example:"acme"`
+}
+
+// Validate checks the filter and target of a replay. Whether the webhook is registered and
+// the rate is allowed is checked by the replay manager.
+func (j ReplayJob) Validate() error {
+	if !j.Target.Valid() {
+		return fmt.Errorf("%w: target must be webhook or outbox", ErrInvalidReplay)
+	}
+	if j.Target == ReplayWebhook && j.WebhookURL == "" {
+		return fmt.Errorf("%w: webhookUrl is required with the webhook target", ErrInvalidReplay)
+	}
+	if j.Target == ReplayOutbox && j.WebhookURL != "" {
+		return fmt.Errorf("%w: webhookUrl is only allowed with the webhook target", ErrInvalidReplay)
+	}
+	if j.From.IsZero() || j.To.IsZero() {
+		return fmt.Errorf("%w: from and to are required", ErrInvalidReplay)
+	}
+	if j.From.After(j.To) {
+		return fmt.Errorf("%w: from must not be after to", ErrInvalidReplay)
+	}
+	for _, eventType := range j.Types {
+		if strings.TrimSpace(eventType) == "" {
+			return fmt.Errorf("%w: types must not be empty", ErrInvalidReplay)
+		}
+	}
+	if j.SessionID != "" {
+		if _, err := ParseSessionID(j.SessionID); err != nil {
+			return fmt.Errorf("%w: sessionId must be a session ID", ErrInvalidReplay)
+		}
+	}
+	if j.RatePerSecond < 0 {
+		return fmt.Errorf("%w: ratePerSecond must be positive", ErrInvalidReplay)
+	}
+	return nil
+}
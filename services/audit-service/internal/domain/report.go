@@ -0,0 +1,200 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ReportKind selects what a scheduled report aggregates
+type ReportKind string
+
+// Report kinds
+const (
+	// ReportSessionActivity counts the events per day, session and event type
+	ReportSessionActivity ReportKind = "session_activity"
+	// ReportExportSummary counts the exports per session and user
+	ReportExportSummary ReportKind = "export_summary"
+)
+
+// ReportFormat is the encoding of a delivered report
+type ReportFormat string
+
+// Report formats
+const (
+	ReportCSV  ReportFormat = "csv"
+	ReportJSON ReportFormat = "json"
+)
+
+// ReportDelivery selects where a generated report is delivered
+type ReportDelivery string
+
+// Report deliveries
+const (
+	// ReportWebhook posts the report to the URL in the report target
+	ReportWebhook ReportDelivery = "webhook"
+	// ReportStorage uploads the report to Supabase Storage under the path prefix in the report target
+	ReportStorage ReportDelivery = "storage"
+)
+
+// ReportRunStatus is the outcome of a report run
+type ReportRunStatus string
+
+// Report run statuses
+const (
+	ReportRunSucceeded ReportRunStatus = "succeeded"
+	ReportRunFailed    ReportRunStatus = "failed"
+)
+
+// ReportTrigger records what started a report run
+type ReportTrigger string
+
+// Report triggers
+const (
+	ReportTriggerSchedule ReportTrigger = "schedule"
+	ReportTriggerManual   ReportTrigger = "manual"
+)
+
+// MaxReportNameLength is the longest accepted report name
+const MaxReportNameLength = 200
+
+var (
+	// ErrInvalidReport is returned for reports with an unknown kind, format, delivery or schedule
+	ErrInvalidReport = errors.New("invalid report")
+	// ErrReportNotFound is returned for unknown report IDs
+	ErrReportNotFound = errors.New("report not found")
+)
+
+// Valid reports whether the kind is supported
+func (k ReportKind) Valid() bool {
+	return k == ReportSessionActivity || k == ReportExportSummary
+}
+
+// Valid reports whether the format is supported
+func (f ReportFormat) Valid() bool {
+	return f == ReportCSV || f == ReportJSON
+}
+
+// ContentType returns the media type of reports in the format
+func (f ReportFormat) ContentType() string {
+	if f == ReportCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/json"
+}
+
+// Valid reports whether the delivery is supported
+func (d ReportDelivery) Valid() bool {
+	return d == ReportWebhook || d == ReportStorage
+}
+
+// Report is a recurring report definition. Each scheduled run covers the events recorded since
+// the previous scheduled run, or since the report was created for the first one.
+type Report struct {
+	ID   string     `json:"id" example:"0b8f5e0c-3c1d-4a57-9d0e-6f1b2c3d4e5f"`
+	Name string     `json:"name" example:"Weekly session activity"`
+	Kind ReportKind `json:"kind" example:"session_activity"`
+	// SessionID restricts the report to one session; empty covers every session of the organization
+	SessionID string `json:"sessionId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Schedule is a five-field cron expression evaluated in UTC, or @daily, @weekly or @monthly
+	Schedule string         `json:"schedule" example:"0 6 * * 1"`
+	Format   ReportFormat   `json:"format" example:"csv"`
+	Delivery ReportDelivery `json:"delivery" example:"webhook"`
+	// Target is the webhook URL, or the storage path prefix of the uploaded reports
+	Target    string    `json:"target" example:"https://reports.example.com/audit"`
+	CreatedBy string    `json:"createdBy" example:"550e8400-e29b-41d4-a716-446655440003"`
+	CreatedAt time.Time `json:"createdAt" example:"2024-01-01T10:00:00Z"`
+	// NextRunAt is the next scheduled run
+	NextRunAt time.Time `json:"nextRunAt" example:"2024-01-08T06:00:00Z"`
+	// LastRunAt is the time of the last scheduled run, where the period of the next one starts
+	LastRunAt *time.Time `json:"lastRunAt,omitempty"`
+	// OrganizationID is the organization whose events are reported; empty for the default organization
+	OrganizationID string `json:"organizationId,omitempty" example:"acme"`
+}
+
+// Validate checks the name, kind, format, delivery, target and session of a report. The
+// schedule is validated by the scheduler that parses it.
+func (r Report) Validate() error {
+	if strings.TrimSpace(r.Name) == "" || utf8.RuneCountInString(r.Name) > MaxReportNameLength {
+		return fmt.Errorf("%w: name must have 1 to %d characters", ErrInvalidReport, MaxReportNameLength)
+	}
+	if !r.Kind.Valid() {
+		return fmt.Errorf("%w: kind must be session_activity or export_summary", ErrInvalidReport)
+	}
+	if !r.Format.Valid() {
+		return fmt.Errorf("%w: format must be csv or json", ErrInvalidReport)
+	}
+	if r.SessionID != "" {
+		if _, err := ParseSessionID(r.SessionID); err != nil {
+			return fmt.Errorf("%w: sessionId must be a session ID", ErrInvalidReport)
+		}
+	}
+
+	switch r.Delivery {
+	case ReportWebhook:
+		target, err := url.Parse(r.Target)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return fmt.Errorf("%w: target must be an http or https URL for webhook delivery", ErrInvalidReport)
+		}
+	case ReportStorage:
+		if strings.HasPrefix(r.Target, "/") || strings.Contains(r.Target, "..") {
+			return fmt.Errorf("%w: target must be a relative storage path for storage delivery", ErrInvalidReport)
+		}
+	default:
+		return fmt.Errorf("%w: delivery must be webhook or storage", ErrInvalidReport)
+	}
+	return nil
+}
+
+// PeriodStart returns where the period of the next scheduled run starts
+func (r Report) PeriodStart() time.Time {
+	if r.LastRunAt != nil {
+		return *r.LastRunAt
+	}
+	return r.CreatedAt
+}
+
+// ReportRun records one generation and delivery of a report
+type ReportRun struct {
+	ID       string          `json:"id" example:"5d2c1b0a-9e8f-4a7b-8c6d-5e4f3a2b1c0d"`
+	ReportID string          `json:"reportId" example:"0b8f5e0c-3c1d-4a57-9d0e-6f1b2c3d4e5f"`
+	Trigger  ReportTrigger   `json:"trigger" example:"schedule"`
+	Status   ReportRunStatus `json:"status" example:"succeeded"`
+	// PeriodFrom and PeriodTo bound the reported events; PeriodTo is exclusive
+	PeriodFrom time.Time `json:"periodFrom" example:"2024-01-01T06:00:00Z"`
+	PeriodTo   time.Time `json:"periodTo" example:"2024-01-08T06:00:00Z"`
+	StartedAt  time.Time `json:"startedAt" example:"2024-01-08T06:00:02Z"`
+	FinishedAt time.Time `json:"finishedAt" example:"2024-01-08T06:00:05Z"`
+	// Rows is the number of rows of the generated report
+	Rows int `json:"rows" example:"42"`
+	// Location is the storage object of uploaded reports
+	Location string `json:"location,omitempty" example:"audit-reports/weekly/2024-01-08T06-00-00Z.csv"`
+	Error    string `json:"error,omitempty"`
+	// OrganizationID is the organization of the report; empty for the default organization
+	OrganizationID string `json:"organizationId,omitempty" example:"acme"`
+}
+
+// ReportColumn describes one column of a generated report
+type ReportColumn struct {
+	Key   string `json:"key" example:"events"`
+	Title string `json:"title" example:"Events"`
+}
+
+// ReportDocument is a generated report. Rows hold one value per column, so the document can be
+// laid out as a table by PDF renderers as is.
+type ReportDocument struct {
+	ReportID    string         `json:"reportId"`
+	Name        string         `json:"name"`
+	Kind        ReportKind     `json:"kind"`
+	SessionID   string         `json:"sessionId,omitempty"`
+	PeriodFrom  time.Time      `json:"periodFrom"`
+	PeriodTo    time.Time      `json:"periodTo"`
+	GeneratedAt time.Time      `json:"generatedAt"`
+	Columns     []ReportColumn `json:"columns"`
+	Rows        [][]any        `json:"rows"`
+	// Truncated is set when the period held more events than a run scans
+	Truncated bool `json:"truncated,omitempty"`
+}
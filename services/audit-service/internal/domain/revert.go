@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotReversible is returned for events that do not record the state they replaced
+var ErrNotReversible = errors.New("event is not reversible")
+
+// RevertAction is the details action of edits that undo a recorded edit
+const RevertAction = "revert"
+
+// revertSchemaVersion is the edit schema version revert payloads are written in
+const revertSchemaVersion = 2
+
+// RevertPayload is the edit that undoes a recorded edit, ready to be sent to POST /api/v1/events.
+// ParentEventID links the undo to the event it reverts.
+type RevertPayload struct {
+	SessionID     string          `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Type          string          `json:"type" example:"edit"`
+	SchemaVersion int             `json:"schemaVersion" example:"2"`
+	ParentEventID string          `json:"parentEventId" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Details       json.RawMessage `json:"details" swaggertype:"object"`
+}
+
+// InverseEdit returns the edit restoring what an edit replaced: its text.before and state.before
+// become the after values of the inverse. Edits recorded without a before value, or whose before
+// values were redacted on ingestion, cannot be reverted.
+func InverseEdit(entry AuditEntry) (*RevertPayload, error) {
+	if entry.Type != string(ActionEdit) {
+		return nil, fmt.Errorf("%w: only edit events can be reverted", ErrNotReversible)
+	}
+	for _, field := range entry.RedactedFields {
+		if isRevertedField(field) {
+			return nil, fmt.Errorf("%w: %s was redacted", ErrNotReversible, field)
+		}
+	}
+
+	details, err := DecodeDetails[map[string]interface{}](entry)
+	if err != nil {
+		return nil, fmt.Errorf("%w: details are not an object", ErrNotReversible)
+	}
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	// Entries the reader could not upgrade still use the flat version 1 layout
+	if entry.SchemaVersion < revertSchemaVersion {
+		details, _ = upgradeEditV2(details)
+	}
+
+	inverse := map[string]interface{}{"action": RevertAction}
+	for _, key := range []string{"slideId", "shapeId", "textId"} {
+		if value, ok := details[key]; ok {
+			inverse[key] = value
+		}
+	}
+
+	reversible := false
+	for _, key := range []string{"text", "state"} {
+		change, _ := details[key].(map[string]interface{})
+		before, ok := change["before"]
+		if !ok {
+			continue
+		}
+		inverse[key] = map[string]interface{}{"before": change["after"], "after": before}
+		reversible = true
+	}
+	if !reversible {
+		return nil, fmt.Errorf("%w: the edit records no text.before or state.before", ErrNotReversible)
+	}
+
+	encoded, err := json.Marshal(inverse)
+	if err != nil {
+		return nil, err
+	}
+	return &RevertPayload{
+		SessionID:     entry.SessionID,
+		Type:          string(ActionEdit),
+		SchemaVersion: revertSchemaVersion,
+		ParentEventID: entry.ID,
+		Details:       encoded,
+	}, nil
+}
+
+// isRevertedField reports whether a redacted details path holds a value the inverse restores
+func isRevertedField(path string) bool {
+	for _, prefix := range []string{"before", "after", "text", "state"} {
+		if path == prefix || strings.HasPrefix(path, prefix+".") || strings.HasPrefix(path, prefix+"[") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInverseEdit(t *testing.T) {
+	tests := []struct {
+		name            string
+		entry           AuditEntry
+		expectedDetails string
+		expectError     bool
+	}{
+		{
+			name: "text edit",
+			entry: AuditEntry{Type: "edit", SchemaVersion: 2,
+				Details: json.RawMessage(`{"action":"text_edited","slideId":"slide-1","shapeId":"shape-2","text":{"before":"Hello","after":"Hello World"},"newStatus":"edited"}`)},
+			expectedDetails: `{"action":"revert","slideId":"slide-1","shapeId":"shape-2","text":{"before":"Hello World","after":"Hello"}}`,
+		},
+		{
+			name: "state snapshot",
+			entry: AuditEntry{Type: "edit", SchemaVersion: 2,
+				Details: json.RawMessage(`{"textId":"t-1","state":{"before":{"status":"draft"},"after":{"status":"approved"}}}`)},
+			expectedDetails: `{"action":"revert","textId":"t-1","state":{"before":{"status":"approved"},"after":{"status":"draft"}}}`,
+		},
+		{
+			name:            "version 1 layout",
+			entry:           AuditEntry{Type: "edit", Details: json.RawMessage(`{"slideId":"slide-1","before":"Hola","after":"Hello"}`)},
+			expectedDetails: `{"action":"revert","slideId":"slide-1","text":{"before":"Hello","after":"Hola"}}`,
+		},
+		{
+			name:        "no before value",
+			entry:       AuditEntry{Type: "edit", SchemaVersion: 2, Details: json.RawMessage(`{"text":{"after":"Hello"}}`)},
+			expectError: true,
+		},
+		{
+			name: "redacted before value",
+			entry: AuditEntry{Type: "edit", SchemaVersion: 2, RedactedFields: []string{"text.before"},
+				Details: json.RawMessage(`{"text":{"before":"[REDACTED:email]","after":"Hello"}}`)},
+			expectError: true,
+		},
+		{
+			name:        "not an edit",
+			entry:       AuditEntry{Type: "comment", Details: json.RawMessage(`{"text":"Hi"}`)},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.entry.ID = "event-1"
+			tt.entry.SessionID = "session-1"
+
+			payload, err := InverseEdit(tt.entry)
+			if tt.expectError {
+				assert.ErrorIs(t, err, ErrNotReversible)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "session-1", payload.SessionID)
+			assert.Equal(t, "edit", payload.Type)
+			assert.Equal(t, 2, payload.SchemaVersion)
+			assert.Equal(t, "event-1", payload.ParentEventID)
+			assert.JSONEq(t, tt.expectedDetails, string(payload.Details))
+		})
+	}
+}
+
+func TestInverseEdit_MatchesEditSchema(t *testing.T) {
+	registry, err := NewDefaultSchemaRegistry()
+	require.NoError(t, err)
+
+	payload, err := InverseEdit(AuditEntry{ID: "event-1", Type: "edit", SchemaVersion: 2,
+		Details: json.RawMessage(`{"text":{"before":"Hello","after":"Hi"},"state":{"before":{"bold":true},"after":{"bold":false}}}`)})
+	require.NoError(t, err)
+
+	assert.NoError(t, registry.ValidateVersion(ActionEdit, payload.SchemaVersion, payload.Details))
+}
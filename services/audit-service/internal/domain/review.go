@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidReviewTransition is returned for review events that the review state of their
+// slide or shape does not allow, such as approving a slide that was never submitted
+var ErrInvalidReviewTransition = errors.New("invalid review transition")
+
+// ReviewActions are the translation review workflow events, in workflow order
+var ReviewActions = []AuditAction{ActionSubmittedForReview, ActionApproved, ActionRejected, ActionReopened}
+
+// IsReviewAction reports whether the event type is part of the review workflow
+func IsReviewAction(eventType string) bool {
+	return slices.Contains(ReviewActions, AuditAction(eventType))
+}
+
+// ReviewState is the review state of a slide or shape, given by its latest review event
+type ReviewState string
+
+// Review states
+const (
+	// ReviewDraft is the state of slides and shapes without review events
+	ReviewDraft    ReviewState = "draft"
+	ReviewInReview ReviewState = "in_review"
+	ReviewApproved ReviewState = "approved"
+	ReviewRejected ReviewState = "rejected"
+	ReviewReopened ReviewState = "reopened"
+)
+
+// reviewTransitions lists the states each review event moves from, and the state it moves to
+var reviewTransitions = map[AuditAction]struct {
+	from []ReviewState
+	to   ReviewState
+}{
+	ActionSubmittedForReview: {from: []ReviewState{ReviewDraft, ReviewRejected, ReviewReopened}, to: ReviewInReview},
+	ActionApproved:           {from: []ReviewState{ReviewInReview}, to: ReviewApproved},
+	ActionRejected:           {from: []ReviewState{ReviewInReview}, to: ReviewRejected},
+	ActionReopened:           {from: []ReviewState{ReviewApproved}, to: ReviewReopened},
+}
+
+// ReviewStateAfter returns the state a review event leaves its slide or shape in
+func ReviewStateAfter(action AuditAction) ReviewState {
+	return reviewTransitions[action].to
+}
+
+// ReviewTarget is the slide, or the shape of a slide, a review event is about. Slides and their
+// shapes are reviewed independently.
+type ReviewTarget struct {
+	SlideID string
+	// ShapeID is empty when the whole slide is reviewed
+	ShapeID string
+}
+
+// String names the target in error messages
+func (t ReviewTarget) String() string {
+	if t.ShapeID == "" {
+		return "slide " + t.SlideID
+	}
+	return fmt.Sprintf("shape %s of slide %s", t.ShapeID, t.SlideID)
+}
+
+// ReviewTransitionError is returned for a review event its target's state does not allow
+type ReviewTransitionError struct {
+	Target ReviewTarget
+	State  ReviewState
+	Action AuditAction
+	// OutOfOrder is set when the event is older than the latest review event of the target
+	OutOfOrder bool
+}
+
+// Error implements the error interface
+func (e *ReviewTransitionError) Error() string {
+	if e.OutOfOrder {
+		return fmt.Sprintf("%s event of %s is older than its latest review event", e.Action, e.Target)
+	}
+	return fmt.Sprintf("%s cannot be recorded for %s in state %s; allowed events: %s",
+		e.Action, e.Target, e.State, strings.Join(AllowedReviewActions(e.State), ", "))
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidReviewTransition)
+func (e *ReviewTransitionError) Unwrap() error {
+	return ErrInvalidReviewTransition
+}
+
+// NextReviewState validates a review event against the current state of its target and returns
+// the state the event moves the target to
+func NextReviewState(target ReviewTarget, state ReviewState, action AuditAction) (ReviewState, error) {
+	transition, ok := reviewTransitions[action]
+	if !ok {
+		return state, fmt.Errorf("%w: %s is not a review event", ErrInvalidReviewTransition, action)
+	}
+	if !slices.Contains(transition.from, state) {
+		return state, &ReviewTransitionError{Target: target, State: state, Action: action}
+	}
+	return transition.to, nil
+}
+
+// AllowedReviewActions returns the review events a target in the state accepts, in workflow order
+func AllowedReviewActions(state ReviewState) []string {
+	var allowed []string
+	for _, action := range ReviewActions {
+		if slices.Contains(reviewTransitions[action].from, state) {
+			allowed = append(allowed, string(action))
+		}
+	}
+	return allowed
+}
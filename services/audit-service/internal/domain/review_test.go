@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextReviewState(t *testing.T) {
+	target := ReviewTarget{SlideID: "slide-3", ShapeID: "shape-12"}
+
+	tests := []struct {
+		name     string
+		state    ReviewState
+		action   AuditAction
+		expected ReviewState
+		allowed  bool
+	}{
+		{"submit draft", ReviewDraft, ActionSubmittedForReview, ReviewInReview, true},
+		{"approve in review", ReviewInReview, ActionApproved, ReviewApproved, true},
+		{"reject in review", ReviewInReview, ActionRejected, ReviewRejected, true},
+		{"resubmit rejected", ReviewRejected, ActionSubmittedForReview, ReviewInReview, true},
+		{"reopen approved", ReviewApproved, ActionReopened, ReviewReopened, true},
+		{"resubmit reopened", ReviewReopened, ActionSubmittedForReview, ReviewInReview, true},
+		{"approve draft", ReviewDraft, ActionApproved, ReviewDraft, false},
+		{"submit twice", ReviewInReview, ActionSubmittedForReview, ReviewInReview, false},
+		{"reject approved", ReviewApproved, ActionRejected, ReviewApproved, false},
+		{"reopen rejected", ReviewRejected, ActionReopened, ReviewRejected, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, err := NextReviewState(target, tt.state, tt.action)
+			assert.Equal(t, tt.expected, next)
+			if tt.allowed {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrInvalidReviewTransition)
+			var transitionErr *ReviewTransitionError
+			require.ErrorAs(t, err, &transitionErr)
+			assert.Equal(t, tt.state, transitionErr.State)
+			assert.Equal(t, tt.action, transitionErr.Action)
+		})
+	}
+
+	_, err := NextReviewState(target, ReviewDraft, ActionEdit)
+	assert.ErrorIs(t, err, ErrInvalidReviewTransition)
+}
+
+func TestReviewTransitionError_Message(t *testing.T) {
+	err := &ReviewTransitionError{
+		Target: ReviewTarget{SlideID: "slide-3", ShapeID: "shape-12"},
+		State:  ReviewInReview,
+		Action: ActionReopened,
+	}
+	assert.Equal(t, "reopened cannot be recorded for shape shape-12 of slide slide-3 in state in_review; allowed events: approved, rejected", err.Error())
+
+	err.OutOfOrder = true
+	assert.Equal(t, "reopened event of shape shape-12 of slide slide-3 is older than its latest review event", err.Error())
+}
+
+func TestIsReviewAction(t *testing.T) {
+	assert.True(t, IsReviewAction("submitted_for_review"))
+	assert.True(t, IsReviewAction("reopened"))
+	assert.False(t, IsReviewAction("edit"))
+}
+
+func TestReviewSchemas_RequireSlide(t *testing.T) {
+	registry, err := NewDefaultSchemaRegistry()
+	require.NoError(t, err)
+
+	require.NoError(t, registry.Validate(ActionApproved, []byte(`{"slideId":"slide-3","shapeId":"shape-12","note":"Looks good"}`)))
+
+	err = registry.Validate(ActionRejected, []byte(`{"note":"Wrong terminology"}`))
+	assert.ErrorIs(t, err, ErrInvalidEventDetails)
+}
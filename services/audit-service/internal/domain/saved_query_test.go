@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavedQuery_Validate(t *testing.T) {
+	valid := SavedQuery{Name: "All exports", Scope: SavedQueryOrganization, Filter: SavedQueryFilter{Types: []string{"export"}}}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		change func(q *SavedQuery)
+	}{
+		{name: "blank name", change: func(q *SavedQuery) { q.Name = "  " }},
+		{name: "long name", change: func(q *SavedQuery) { q.Name = strings.Repeat("a", MaxSavedQueryNameLength+1) }},
+		{name: "long description", change: func(q *SavedQuery) { q.Description = strings.Repeat("a", MaxSavedQueryDescriptionLength+1) }},
+		{name: "unknown scope", change: func(q *SavedQuery) { q.Scope = "public" }},
+		{name: "negative days", change: func(q *SavedQuery) { q.Filter.Days = -1 }},
+		{name: "too many days", change: func(q *SavedQuery) { q.Filter.Days = MaxSavedQueryDays + 1 }},
+		{name: "invalid event type", change: func(q *SavedQuery) { q.Filter.Types = []string{"Export"} }},
+		{name: "unknown category", change: func(q *SavedQuery) { q.Filter.Categories = []EventCategory{"billing"} }},
+		{name: "invalid correlation ID", change: func(q *SavedQuery) { q.Filter.CorrelationID = "bad id" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := valid
+			tt.change(&query)
+			assert.ErrorIs(t, query.Validate(), ErrInvalidSavedQuery)
+		})
+	}
+}
+
+func TestSavedQueryFilter_EventFilter(t *testing.T) {
+	now := time.Date(2024, 2, 8, 12, 0, 0, 0, time.UTC)
+	filter := SavedQueryFilter{Types: []string{"edit", "merge"}, UserID: SavedQueryCurrentUser, Days: 7, SlideID: "slide-3"}
+
+	assert.Equal(t, EventFilter{
+		Types:   []string{"edit", "merge"},
+		UserID:  "user-1",
+		From:    time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC),
+		SlideID: "slide-3",
+	}, filter.EventFilter("user-1", now))
+
+	// Other users and unlimited ranges are kept as saved
+	assert.Equal(t, EventFilter{UserID: "user-2"}, SavedQueryFilter{UserID: "user-2"}.EventFilter("user-1", now))
+}
+
+func TestSavedQuery_VisibleTo(t *testing.T) {
+	private := SavedQuery{Scope: SavedQueryPrivate, CreatedBy: "user-1"}
+	shared := SavedQuery{Scope: SavedQueryOrganization, CreatedBy: "user-1"}
+
+	assert.True(t, private.VisibleTo("user-1"))
+	assert.False(t, private.VisibleTo("user-2"))
+	assert.True(t, shared.VisibleTo("user-2"))
+}
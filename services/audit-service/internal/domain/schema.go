@@ -0,0 +1,292 @@
+package domain
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+//go:embed schemas/*.json
+var builtinSchemas embed.FS
+
+// ErrInvalidEventDetails indicates that event details do not match the schema of their action
+var ErrInvalidEventDetails = errors.New("invalid event details")
+
+// SchemaViolation describes one way event details failed schema validation
+type SchemaViolation struct {
+	Field   string `json:"field" example:"details.slideId"`
+	Message string `json:"message" example:"Invalid type. Expected: string, given: integer"`
+}
+
+// SchemaValidationError lists every violation found in an event's details
+type SchemaValidationError struct {
+	Action     AuditAction
+	Violations []SchemaViolation
+}
+
+// Error implements the error interface
+func (e *SchemaValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Field + ": " + v.Message
+	}
+	return fmt.Sprintf("%s details are invalid: %s", e.Action, strings.Join(messages, "; "))
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidEventDetails)
+func (e *SchemaValidationError) Unwrap() error {
+	return ErrInvalidEventDetails
+}
+
+// DetailsUpgrader converts event details from the previous schema version of an action to the
+// version it is registered with. It may modify and return the map it is given.
+type DetailsUpgrader func(details map[string]interface{}) (map[string]interface{}, error)
+
+// SchemaRegistry holds the versioned JSON schemas that validate the details of each action, the
+// upgraders that convert stored details to the current version and the event types in use
+type SchemaRegistry struct {
+	mu        sync.RWMutex
+	schemas   map[AuditAction]map[int]*gojsonschema.Schema
+	upgraders map[AuditAction]map[int]DetailsUpgrader
+	current   map[AuditAction]int
+	types     map[AuditAction]EventType
+}
+
+// NewSchemaRegistry creates an empty schema registry
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{
+		schemas:   make(map[AuditAction]map[int]*gojsonschema.Schema),
+		upgraders: make(map[AuditAction]map[int]DetailsUpgrader),
+		current:   make(map[AuditAction]int),
+		types:     make(map[AuditAction]EventType),
+	}
+}
+
+// NewDefaultSchemaRegistry creates a registry with the built-in event types and the built-in
+// schemas for edit, merge, reorder, comment, comment lifecycle, review, translation memory, machine
+// translation, export, export lifecycle, share and thumbnail events
+func NewDefaultSchemaRegistry() (*SchemaRegistry, error) {
+	r := NewSchemaRegistry()
+	for _, eventType := range builtinEventTypes {
+		eventType.BuiltIn = true
+		r.types[eventType.Name] = eventType
+	}
+
+	for _, action := range []AuditAction{ActionEdit, ActionMerge, ActionReorder, ActionComment, ActionExport, ActionShare, ActionThumbnail,
+		ActionCommentCreated, ActionCommentEdited, ActionCommentResolved, ActionCommentDeleted,
+		ActionSubmittedForReview, ActionApproved, ActionRejected, ActionReopened, ActionTMHit, ActionTMOverride, ActionMTRequest, ActionExportLifecycle} {
+		schema, err := builtinSchemas.ReadFile("schemas/" + string(action) + ".json")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s schema: %w", action, err)
+		}
+		if err := r.Register(action, schema); err != nil {
+			return nil, err
+		}
+	}
+
+	// Later versions are read from schemas/<action>.v<version>.json
+	for _, upgrade := range builtinUpgrades {
+		schema, err := builtinSchemas.ReadFile(fmt.Sprintf("schemas/%s.v%d.json", upgrade.action, upgrade.version))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s schema version %d: %w", upgrade.action, upgrade.version, err)
+		}
+		if err := r.RegisterVersion(upgrade.action, upgrade.version, schema, upgrade.upgrade); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register compiles a JSON schema and uses it as version 1 of the action's details, replacing
+// the action's previous versions
+func (r *SchemaRegistry) Register(action AuditAction, schema []byte) error {
+	compiled, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schema))
+	if err != nil {
+		return fmt.Errorf("invalid %s schema: %w", action, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schemas[action] = map[int]*gojsonschema.Schema{1: compiled}
+	r.upgraders[action] = map[int]DetailsUpgrader{}
+	r.current[action] = 1
+	return nil
+}
+
+// RegisterVersion adds the next schema version of an action. upgrade converts details of the
+// previous version, which must already be registered, and makes the new version current.
+func (r *SchemaRegistry) RegisterVersion(action AuditAction, version int, schema []byte, upgrade DetailsUpgrader) error {
+	if upgrade == nil {
+		return fmt.Errorf("%s schema version %d needs an upgrader", action, version)
+	}
+
+	compiled, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schema))
+	if err != nil {
+		return fmt.Errorf("invalid %s schema version %d: %w", action, version, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if current := r.current[action]; current == 0 || version != current+1 {
+		return fmt.Errorf("%s schema version %d must follow version %d", action, version, current)
+	}
+	r.schemas[action][version] = compiled
+	r.upgraders[action][version] = upgrade
+	r.current[action] = version
+	return nil
+}
+
+// DefineEventType adds a custom event type and, when it has a schema, uses the schema as version 1
+// of its details. Names that are already defined return ErrEventTypeExists.
+func (r *SchemaRegistry) DefineEventType(eventType EventType) error {
+	compiled, err := eventType.compile()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.types[eventType.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrEventTypeExists, eventType.Name)
+	}
+	eventType.BuiltIn = false
+	r.types[eventType.Name] = eventType
+	if compiled != nil {
+		r.schemas[eventType.Name] = map[int]*gojsonschema.Schema{1: compiled}
+		r.upgraders[eventType.Name] = map[int]DetailsUpgrader{}
+		r.current[eventType.Name] = 1
+	}
+	return nil
+}
+
+// LookupEventType returns the event type defined for an action
+func (r *SchemaRegistry) LookupEventType(action AuditAction) (EventType, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	eventType, ok := r.types[action]
+	return eventType, ok
+}
+
+// EventTypes returns every defined event type, built-in types first and each group sorted by name
+func (r *SchemaRegistry) EventTypes() []EventType {
+	r.mu.RLock()
+	types := make([]EventType, 0, len(r.types))
+	for _, eventType := range r.types {
+		types = append(types, eventType)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(types, func(i, j int) bool {
+		if types[i].BuiltIn != types[j].BuiltIn {
+			return types[i].BuiltIn
+		}
+		return types[i].Name < types[j].Name
+	})
+	return types
+}
+
+// CurrentVersion returns the newest schema version of an action, 1 for actions without a schema
+func (r *SchemaRegistry) CurrentVersion(action AuditAction) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if current, ok := r.current[action]; ok {
+		return current
+	}
+	return 1
+}
+
+// Validate checks details against version 1 of the action's schema, which requests without a
+// schema version use; actions without a schema accept any details
+func (r *SchemaRegistry) Validate(action AuditAction, details json.RawMessage) error {
+	return r.ValidateVersion(action, 1, details)
+}
+
+// ValidateVersion checks details against a schema version of the action; actions without a
+// schema accept any details
+func (r *SchemaRegistry) ValidateVersion(action AuditAction, version int, details json.RawMessage) error {
+	r.mu.RLock()
+	versions, ok := r.schemas[action]
+	schema := versions[version]
+	r.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	if schema == nil {
+		return &SchemaValidationError{
+			Action:     action,
+			Violations: []SchemaViolation{{Field: "schemaVersion", Message: fmt.Sprintf("unsupported schema version %d", version)}},
+		}
+	}
+
+	if len(details) == 0 {
+		details = json.RawMessage("{}")
+	}
+
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(details))
+	if err != nil {
+		return &SchemaValidationError{
+			Action:     action,
+			Violations: []SchemaViolation{{Field: "details", Message: "must be valid JSON"}},
+		}
+	}
+	if result.Valid() {
+		return nil
+	}
+
+	violations := make([]SchemaViolation, len(result.Errors()))
+	for i, resultErr := range result.Errors() {
+		field := "details"
+		if resultErr.Field() != gojsonschema.STRING_CONTEXT_ROOT {
+			field += "." + resultErr.Field()
+		}
+		violations[i] = SchemaViolation{Field: field, Message: resultErr.Description()}
+	}
+
+	return &SchemaValidationError{Action: action, Violations: violations}
+}
+
+// Upgrade converts the details of an entry recorded with an older schema version to the current
+// version of its action. Entries stored before versioning count as version 1.
+func (r *SchemaRegistry) Upgrade(entry AuditEntry) (AuditEntry, error) {
+	action := AuditAction(entry.Type)
+	version := max(entry.SchemaVersion, 1)
+	current := r.CurrentVersion(action)
+	if version >= current {
+		entry.SchemaVersion = version
+		return entry, nil
+	}
+
+	details, err := DecodeDetails[map[string]interface{}](entry)
+	if err != nil {
+		return entry, fmt.Errorf("cannot upgrade audit entry %s: %w", entry.ID, err)
+	}
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+
+	r.mu.RLock()
+	upgraders := r.upgraders[action]
+	r.mu.RUnlock()
+
+	for v := version + 1; v <= current; v++ {
+		upgraded, err := upgraders[v](details)
+		if err != nil {
+			return entry, fmt.Errorf("cannot upgrade audit entry %s to %s schema version %d: %w", entry.ID, action, v, err)
+		}
+		details = upgraded
+	}
+
+	encoded, err := EncodeDetails(details)
+	if err != nil {
+		return entry, err
+	}
+	entry.Details = encoded
+	entry.SchemaVersion = current
+	return entry, nil
+}
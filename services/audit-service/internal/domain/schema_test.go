@@ -0,0 +1,363 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaRegistry_DefaultSchemas(t *testing.T) {
+	registry, err := NewDefaultSchemaRegistry()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		action        AuditAction
+		details       string
+		wantFields    []string
+		expectInvalid bool
+	}{
+		{
+			name:    "edit with text change",
+			action:  ActionEdit,
+			details: `{"slideId":"slide-1","textId":"text-1","before":"Hello","after":"Hello World"}`,
+		},
+		{
+			name:    "edit from the editor",
+			action:  ActionEdit,
+			details: `{"action":"text_translated","slideId":"slide-1","shapeId":"shape-1"}`,
+		},
+		{
+			name:    "missing details are an empty object",
+			action:  ActionEdit,
+			details: ``,
+		},
+		{
+			name:          "edit with wrong types",
+			action:        ActionEdit,
+			details:       `{"slideId":3,"before":"Hello"}`,
+			expectInvalid: true,
+			wantFields:    []string{"details.slideId", "details"},
+		},
+		{
+			name:          "details must be an object",
+			action:        ActionEdit,
+			details:       `"garbage"`,
+			expectInvalid: true,
+			wantFields:    []string{"details"},
+		},
+		{
+			name:    "merge",
+			action:  ActionMerge,
+			details: `{"slideId":"slide-1","shapeIds":["a","b"]}`,
+		},
+		{
+			name:          "merge needs two shapes",
+			action:        ActionMerge,
+			details:       `{"shapeIds":["a"]}`,
+			expectInvalid: true,
+			wantFields:    []string{"details.shapeIds"},
+		},
+		{
+			name:          "reorder needs indexes",
+			action:        ActionReorder,
+			details:       `{"fromIndex":-1}`,
+			expectInvalid: true,
+			wantFields:    []string{"details", "details.fromIndex"},
+		},
+		{
+			name:          "edit state without after",
+			action:        ActionEdit,
+			details:       `{"slideId":"slide-1","state":{"before":{"status":"draft"}}}`,
+			expectInvalid: true,
+			wantFields:    []string{"details.state"},
+		},
+		{
+			name:    "comment",
+			action:  ActionComment,
+			details: `{"text":"Check this translation","slideId":"slide-1"}`,
+		},
+		{
+			name:          "comment without text",
+			action:        ActionComment,
+			details:       `{}`,
+			expectInvalid: true,
+			wantFields:    []string{"details"},
+		},
+		{
+			name:    "comment created",
+			action:  ActionCommentCreated,
+			details: `{"commentId":"comment-7","threadId":"thread-2","text":"Please check the tone","slideId":"slide-1"}`,
+		},
+		{
+			name:          "comment resolved without thread",
+			action:        ActionCommentResolved,
+			details:       `{"commentId":"comment-7","resolved":"yes"}`,
+			expectInvalid: true,
+			wantFields:    []string{"details", "details.resolved"},
+		},
+		{
+			name:    "export",
+			action:  ActionExport,
+			details: `{"action":"export_session","slideCount":12,"format":"pptx"}`,
+		},
+		{
+			name:          "export with fractional slide count",
+			action:        ActionExport,
+			details:       `{"slideCount":1.5}`,
+			expectInvalid: true,
+			wantFields:    []string{"details.slideCount"},
+		},
+		{
+			name:    "thumbnail",
+			action:  ActionThumbnail,
+			details: `{"slideId":"slide-1","slideNumber":3,"thumbnailUrl":"https://example.com/t.png"}`,
+		},
+		{
+			name:          "thumbnail with invalid slide number",
+			action:        ActionThumbnail,
+			details:       `{"slideNumber":0}`,
+			expectInvalid: true,
+			wantFields:    []string{"details.slideNumber"},
+		},
+		{
+			name:          "share with invalid expiry",
+			action:        ActionShare,
+			details:       `{"action":"share_session","expiresAt":"tomorrow"}`,
+			expectInvalid: true,
+			wantFields:    []string{"details.expiresAt"},
+		},
+		{
+			name:    "actions without a schema accept anything",
+			action:  ActionView,
+			details: `["anything"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.Validate(tt.action, json.RawMessage(tt.details))
+
+			if !tt.expectInvalid {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, ErrInvalidEventDetails)
+
+			var schemaErr *SchemaValidationError
+			require.True(t, errors.As(err, &schemaErr))
+			assert.Equal(t, tt.action, schemaErr.Action)
+
+			fields := make([]string, len(schemaErr.Violations))
+			for i, v := range schemaErr.Violations {
+				fields[i] = v.Field
+				assert.NotEmpty(t, v.Message)
+			}
+			assert.ElementsMatch(t, tt.wantFields, fields)
+		})
+	}
+}
+
+func TestSchemaRegistry_Register(t *testing.T) {
+	registry := NewSchemaRegistry()
+
+	assert.Error(t, registry.Register(ActionView, []byte(`{"type": 42}`)))
+
+	require.NoError(t, registry.Register(ActionView, []byte(`{"type":"object","required":["page"]}`)))
+	assert.NoError(t, registry.Validate(ActionView, json.RawMessage(`{"page":"editor"}`)))
+	assert.ErrorIs(t, registry.Validate(ActionView, json.RawMessage(`{}`)), ErrInvalidEventDetails)
+	assert.ErrorIs(t, registry.Validate(ActionView, json.RawMessage(`{not json`)), ErrInvalidEventDetails)
+}
+
+func TestToAPIError_SchemaValidation(t *testing.T) {
+	err := &SchemaValidationError{
+		Action:     ActionComment,
+		Violations: []SchemaViolation{{Field: "details", Message: "text is required"}},
+	}
+
+	apiErr := ToAPIError(err)
+
+	assert.Equal(t, 422, apiErr.Status)
+	assert.Equal(t, "invalid_event_details", apiErr.Code)
+	assert.Equal(t, err.Violations, apiErr.Violations)
+}
+
+func TestSchemaRegistry_EditVersions(t *testing.T) {
+	registry, err := NewDefaultSchemaRegistry()
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, registry.CurrentVersion(ActionEdit))
+	assert.Equal(t, 1, registry.CurrentVersion(ActionComment))
+	assert.Equal(t, 1, registry.CurrentVersion(ActionView))
+
+	v2 := json.RawMessage(`{"slideId":"slide-1","text":{"before":"Hello","after":"Hello World"}}`)
+	v1 := json.RawMessage(`{"slideId":"slide-1","before":"Hello","after":"Hello World"}`)
+	assert.NoError(t, registry.ValidateVersion(ActionEdit, 2, v2))
+	assert.NoError(t, registry.ValidateVersion(ActionEdit, 1, v1))
+	// Each version only accepts its own shape
+	assert.ErrorIs(t, registry.ValidateVersion(ActionEdit, 2, v1), ErrInvalidEventDetails)
+	assert.ErrorIs(t, registry.ValidateVersion(ActionEdit, 2, json.RawMessage(`{"text":{"before":"Hello"}}`)), ErrInvalidEventDetails)
+
+	err = registry.ValidateVersion(ActionEdit, 3, v2)
+	var schemaErr *SchemaValidationError
+	require.True(t, errors.As(err, &schemaErr))
+	assert.Equal(t, "schemaVersion", schemaErr.Violations[0].Field)
+
+	// Actions without a schema accept any version
+	assert.NoError(t, registry.ValidateVersion(ActionView, 7, json.RawMessage(`{}`)))
+}
+
+func TestSchemaRegistry_Upgrade(t *testing.T) {
+	registry, err := NewDefaultSchemaRegistry()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		entry           AuditEntry
+		expectedDetails string
+		expectedVersion int
+	}{
+		{
+			name:            "edit stored before versioning",
+			entry:           AuditEntry{Type: "edit", Details: json.RawMessage(`{"slideId":"slide-1","before":"Hello","after":"Hi"}`)},
+			expectedDetails: `{"slideId":"slide-1","text":{"before":"Hello","after":"Hi"}}`,
+			expectedVersion: 2,
+		},
+		{
+			name:            "edit without a text change",
+			entry:           AuditEntry{Type: "edit", SchemaVersion: 1, Details: json.RawMessage(`{"action":"delete_session"}`)},
+			expectedDetails: `{"action":"delete_session"}`,
+			expectedVersion: 2,
+		},
+		{
+			name:            "current edit is unchanged",
+			entry:           AuditEntry{Type: "edit", SchemaVersion: 2, Details: json.RawMessage(`{"text":{"after":"Hi"}}`)},
+			expectedDetails: `{"text":{"after":"Hi"}}`,
+			expectedVersion: 2,
+		},
+		{
+			name:            "edit without details",
+			entry:           AuditEntry{Type: "edit"},
+			expectedDetails: `{}`,
+			expectedVersion: 2,
+		},
+		{
+			name:            "action with a single version",
+			entry:           AuditEntry{Type: "comment", Details: json.RawMessage(`{"text":"Looks good"}`)},
+			expectedDetails: `{"text":"Looks good"}`,
+			expectedVersion: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upgraded, err := registry.Upgrade(tt.entry)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expectedDetails, string(upgraded.Details))
+			assert.Equal(t, tt.expectedVersion, upgraded.SchemaVersion)
+		})
+	}
+
+	_, err = registry.Upgrade(AuditEntry{ID: "audit-1", Type: "edit", Details: json.RawMessage(`"garbage"`)})
+	assert.Error(t, err)
+}
+
+func TestSchemaRegistry_RegisterVersion(t *testing.T) {
+	registry := NewSchemaRegistry()
+	upgrade := func(details map[string]interface{}) (map[string]interface{}, error) {
+		details["page"] = details["screen"]
+		delete(details, "screen")
+		return details, nil
+	}
+	v2 := []byte(`{"type":"object","required":["page"]}`)
+
+	// Versions build on the previous one
+	assert.Error(t, registry.RegisterVersion(ActionView, 2, v2, upgrade))
+	require.NoError(t, registry.Register(ActionView, []byte(`{"type":"object"}`)))
+	assert.Error(t, registry.RegisterVersion(ActionView, 3, v2, upgrade))
+	assert.Error(t, registry.RegisterVersion(ActionView, 2, v2, nil))
+	assert.Error(t, registry.RegisterVersion(ActionView, 2, []byte(`{"type": 42}`), upgrade))
+	require.NoError(t, registry.RegisterVersion(ActionView, 2, v2, upgrade))
+	assert.Equal(t, 2, registry.CurrentVersion(ActionView))
+
+	upgraded, err := registry.Upgrade(AuditEntry{Type: "view", Details: json.RawMessage(`{"screen":"editor"}`)})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"page":"editor"}`, string(upgraded.Details))
+	assert.NoError(t, registry.ValidateVersion(ActionView, 2, upgraded.Details))
+
+	// Registering version 1 again starts a new history
+	require.NoError(t, registry.Register(ActionView, []byte(`{"type":"object"}`)))
+	assert.Equal(t, 1, registry.CurrentVersion(ActionView))
+}
+
+func TestEventType_Validate(t *testing.T) {
+	valid := EventType{Name: "terminology_approved", DisplayName: "Terminology approved", Severity: SeverityLow}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(eventType *EventType)
+	}{
+		{name: "upper case name", modify: func(e *EventType) { e.Name = "Terminology" }},
+		{name: "name with spaces", modify: func(e *EventType) { e.Name = "terminology approved" }},
+		{name: "name starting with a digit", modify: func(e *EventType) { e.Name = "2fa_enabled" }},
+		{name: "name too long", modify: func(e *EventType) { e.Name = AuditAction(strings.Repeat("a", MaxEventTypeNameLength+1)) }},
+		{name: "missing display name", modify: func(e *EventType) { e.DisplayName = "" }},
+		{name: "unknown severity", modify: func(e *EventType) { e.Severity = "critical" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eventType := valid
+			tt.modify(&eventType)
+			assert.ErrorIs(t, eventType.Validate(), ErrInvalidEventType)
+		})
+	}
+}
+
+func TestSchemaRegistry_DefineEventType(t *testing.T) {
+	registry, err := NewDefaultSchemaRegistry()
+	require.NoError(t, err)
+
+	edit, ok := registry.LookupEventType(ActionEdit)
+	require.True(t, ok)
+	assert.True(t, edit.BuiltIn)
+	_, ok = registry.LookupEventType("terminology_approved")
+	assert.False(t, ok)
+
+	approved := EventType{
+		Name: "terminology_approved", DisplayName: "Terminology approved", Severity: SeverityLow,
+		Schema: json.RawMessage(`{"type":"object","required":["term"],"properties":{"term":{"type":"string"}}}`),
+	}
+	require.NoError(t, registry.DefineEventType(approved))
+
+	got, ok := registry.LookupEventType("terminology_approved")
+	require.True(t, ok)
+	assert.False(t, got.BuiltIn)
+	assert.NoError(t, registry.Validate("terminology_approved", json.RawMessage(`{"term":"Folie"}`)))
+	assert.ErrorIs(t, registry.Validate("terminology_approved", json.RawMessage(`{}`)), ErrInvalidEventDetails)
+
+	// Built-in and defined names cannot be redefined
+	assert.ErrorIs(t, registry.DefineEventType(approved), ErrEventTypeExists)
+	assert.ErrorIs(t, registry.DefineEventType(EventType{Name: ActionEdit, DisplayName: "Edit", Severity: SeverityLow}), ErrEventTypeExists)
+
+	// Types without a schema accept any details
+	require.NoError(t, registry.DefineEventType(EventType{Name: "glossary_synced", DisplayName: "Glossary synced", Severity: SeverityInfo}))
+	assert.NoError(t, registry.Validate("glossary_synced", json.RawMessage(`{"anything":1}`)))
+
+	invalid := EventType{Name: "broken", DisplayName: "Broken", Severity: SeverityLow, Schema: json.RawMessage(`{"type": 42}`)}
+	assert.ErrorIs(t, registry.DefineEventType(invalid), ErrInvalidEventType)
+	_, ok = registry.LookupEventType("broken")
+	assert.False(t, ok)
+
+	types := registry.EventTypes()
+	require.Len(t, types, len(builtinEventTypes)+2)
+	assert.True(t, types[0].BuiltIn)
+	assert.Equal(t, AuditAction("glossary_synced"), types[len(types)-2].Name)
+	assert.Equal(t, AuditAction("terminology_approved"), types[len(types)-1].Name)
+}
@@ -0,0 +1,41 @@
+package domain
+
+// builtinUpgrade is a schema version shipped with the service
+type builtinUpgrade struct {
+	action  AuditAction
+	version int
+	upgrade DetailsUpgrader
+}
+
+// builtinUpgrades lists the built-in schema versions after the first, in registration order.
+// Add an entry and a schemas/<action>.v<version>.json file to evolve a payload; stored events
+// keep their version and are upgraded when read.
+var builtinUpgrades = []builtinUpgrade{
+	{action: ActionEdit, version: 2, upgrade: upgradeEditV2},
+}
+
+// upgradeEditV2 moves the flat before/after strings of a version 1 edit, and the diff stored
+// beside them, into the text object
+func upgradeEditV2(details map[string]interface{}) (map[string]interface{}, error) {
+	before, hasBefore := details["before"]
+	after, hasAfter := details["after"]
+	if !hasBefore && !hasAfter {
+		return details, nil
+	}
+
+	text := map[string]interface{}{}
+	if hasBefore {
+		text["before"] = before
+	}
+	if hasAfter {
+		text["after"] = after
+	}
+	if diff, ok := details["diff"]; ok {
+		text["diff"] = diff
+	}
+	delete(details, "before")
+	delete(details, "after")
+	delete(details, "diff")
+	details["text"] = text
+	return details, nil
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// SharePermission is a role granted by a share link
+type SharePermission string
+
+// Share link permissions issued by the share service
+const (
+	// SharePermissionView lets reviewers read the session
+	SharePermissionView SharePermission = "VIEW"
+	// SharePermissionComment lets reviewers comment, which includes reading
+	SharePermissionComment SharePermission = "COMMENT"
+)
+
+// Share is an active share link for a session
+type Share struct {
+	ID          string
+	SessionID   string
+	TokenJTI    string
+	Permissions []SharePermission
+	ExpiresAt   *time.Time
+}
+
+// Allows reports whether the granted permissions include the permission; comment access implies view access
+func Allows(granted []SharePermission, permission SharePermission) bool {
+	for _, p := range granted {
+		if p == permission || (permission == SharePermissionView && p == SharePermissionComment) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithoutClientInfo returns the entry without the IP address and user agent of its author.
+// Share-link readers see what happened, not where other users connected from.
+func (e AuditEntry) WithoutClientInfo() AuditEntry {
+	e.IPAddress = ""
+	e.UserAgent = ""
+	return e
+}
@@ -0,0 +1,208 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"sort"
+	"time"
+)
+
+// ShareTrailActions are the events of the lifecycle of a share link, correlated by its token ID
+var ShareTrailActions = []AuditAction{ActionShare, ActionShareRedeemed, ActionShareExpired, ActionUnshare}
+
+// MaxShareTrailEvents caps the events read for the trail of one share link; the trail is marked
+// truncated when the link has more
+const MaxShareTrailEvents = 1000
+
+// ShareLinkStatus is the state of a share link in its trail
+type ShareLinkStatus string
+
+// Share link statuses
+const (
+	ShareLinkActive  ShareLinkStatus = "active"
+	ShareLinkExpired ShareLinkStatus = "expired"
+	ShareLinkRevoked ShareLinkStatus = "revoked"
+)
+
+// ErrShareTrailNotFound is returned for share tokens without recorded events, or of sessions the
+// user cannot read
+var ErrShareTrailNotFound = errors.New("share token not found")
+
+// ShareUserID is the user recorded for the events of a share link presented by a reviewer, who
+// has no account of their own
+func ShareUserID(tokenID string) string {
+	return "share:" + tokenID
+}
+
+// ShareTokenRef returns the tokenId of the details of share and unshare events, which is recorded
+// as their correlation ID so the lifecycle of the link can be read by it. It is empty for other
+// types and for token IDs that are not valid correlation IDs.
+func ShareTokenRef(action AuditAction, details json.RawMessage) string {
+	if action != ActionShare && action != ActionUnshare {
+		return ""
+	}
+	var ref struct {
+		TokenID string `json:"tokenId"`
+	}
+	if json.Unmarshal(details, &ref) != nil || !ValidCorrelationID(ref.TokenID) {
+		return ""
+	}
+	return ref.TokenID
+}
+
+// ShareTokenUse is a request that presented the token of an existing share link: a redemption
+// when the link granted access, or an attempt after the link expired
+type ShareTokenUse struct {
+	TokenID        string
+	SessionID      string
+	OrganizationID string
+	Expired        bool
+	Method         string
+	// Path is the requested path, without the share token passed in the query
+	Path      string
+	Status    int
+	ClientIP  string
+	UserAgent string
+	RequestID string
+}
+
+// ShareLinkDetails are the details of share and unshare events read for share trails
+type ShareLinkDetails struct {
+	TokenID     string            `json:"tokenId"`
+	Permissions []SharePermission `json:"permissions"`
+	ExpiresAt   *time.Time        `json:"expiresAt"`
+}
+
+// ShareUseDetails are the details of share_redeemed and share_expired events
+type ShareUseDetails struct {
+	TokenID string `json:"tokenId"`
+	Method  string `json:"method,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Status  int    `json:"status,omitempty"`
+}
+
+// Details returns the details recorded for the use
+func (u ShareTokenUse) Details() ShareUseDetails {
+	return ShareUseDetails{TokenID: u.TokenID, Method: u.Method, Path: u.Path, Status: u.Status}
+}
+
+// ShareRedemption is a recorded use of a share link
+type ShareRedemption struct {
+	EventID   string    `json:"eventId" example:"550e8400-e29b-41d4-a716-446655440009"`
+	Timestamp time.Time `json:"timestamp" example:"2024-01-16T09:12:00Z"`
+	IPAddress string    `json:"ipAddress,omitempty" example:"198.51.100.4"`
+	UserAgent string    `json:"userAgent,omitempty" example:"Mozilla/5.0"`
+	Method    string    `json:"method,omitempty" example:"GET"`
+	Path      string    `json:"path,omitempty" example:"/api/v1/sessions/550e8400-e29b-41d4-a716-446655440000/history"`
+	Status    int       `json:"status,omitempty" example:"200"`
+}
+
+// ShareTrail is the forensic trail of one share link, stitched from its share, share_redeemed,
+// share_expired and unshare events correlated by the token ID
+type ShareTrail struct {
+	TokenID   string          `json:"tokenId" example:"share-91c2"`
+	SessionID string          `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Status    ShareLinkStatus `json:"status" example:"active"`
+	// CreatedBy, CreatedAt, Permissions and ExpiresAt come from the share event, when recorded
+	CreatedBy   string            `json:"createdBy,omitempty" example:"user-123"`
+	CreatedAt   *time.Time        `json:"createdAt,omitempty" example:"2024-01-15T10:00:00Z"`
+	Permissions []SharePermission `json:"permissions,omitempty"`
+	ExpiresAt   *time.Time        `json:"expiresAt,omitempty" example:"2024-01-22T10:00:00Z"`
+	// ExpiredAt is the first use after the link expired, or its expiry once passed
+	ExpiredAt *time.Time `json:"expiredAt,omitempty" example:"2024-01-22T11:30:00Z"`
+	RevokedBy string     `json:"revokedBy,omitempty" example:"user-123"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty" example:"2024-01-20T08:00:00Z"`
+	// Redemptions lists the uses of the link, the earliest first; ExpiredAttempts counts the
+	// uses rejected after it expired
+	Redemptions     []ShareRedemption `json:"redemptions"`
+	ExpiredAttempts int               `json:"expiredAttempts" example:"1"`
+	// Truncated is set when the link had more than MaxShareTrailEvents events
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// NewShareTrail stitches the trail of a share link from its correlated events, as of now. It
+// returns false when no event of the link was recorded.
+func NewShareTrail(tokenID string, entries []AuditEntry, now time.Time) (ShareTrail, bool) {
+	sorted := append([]AuditEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	// The session of the share event, or of the first event without one, is the session of the
+	// link; events of other sessions reusing the token ID are left out
+	trail := ShareTrail{TokenID: tokenID, Redemptions: []ShareRedemption{}}
+	for _, entry := range sorted {
+		if AuditAction(entry.Type) == ActionShare {
+			trail.SessionID = entry.SessionID
+			break
+		}
+	}
+
+	found := false
+	for _, entry := range sorted {
+		action := AuditAction(entry.Type)
+		if !slices.Contains(ShareTrailActions, action) {
+			continue
+		}
+		if trail.SessionID == "" {
+			trail.SessionID = entry.SessionID
+		}
+		if entry.SessionID != trail.SessionID {
+			continue
+		}
+		found = true
+		timestamp := entry.Timestamp
+
+		switch action {
+		case ActionShare:
+			if trail.CreatedAt != nil {
+				continue
+			}
+			details, _ := DecodeDetails[ShareLinkDetails](entry)
+			trail.CreatedBy = entry.UserID
+			trail.CreatedAt = &timestamp
+			trail.Permissions = details.Permissions
+			trail.ExpiresAt = details.ExpiresAt
+		case ActionUnshare:
+			if trail.RevokedAt == nil {
+				trail.RevokedBy = entry.UserID
+				trail.RevokedAt = &timestamp
+			}
+		case ActionShareExpired:
+			trail.ExpiredAttempts++
+			if trail.ExpiredAt == nil {
+				trail.ExpiredAt = &timestamp
+			}
+		case ActionShareRedeemed:
+			details, _ := DecodeDetails[ShareUseDetails](entry)
+			trail.Redemptions = append(trail.Redemptions, ShareRedemption{
+				EventID:   entry.ID,
+				Timestamp: entry.Timestamp,
+				IPAddress: entry.IPAddress,
+				UserAgent: entry.UserAgent,
+				Method:    details.Method,
+				Path:      details.Path,
+				Status:    details.Status,
+			})
+		}
+	}
+	if !found {
+		return ShareTrail{}, false
+	}
+
+	// Links expire without an event unless they are presented afterwards
+	if trail.ExpiresAt != nil && !now.Before(*trail.ExpiresAt) &&
+		(trail.ExpiredAt == nil || trail.ExpiresAt.Before(*trail.ExpiredAt)) {
+		expiredAt := *trail.ExpiresAt
+		trail.ExpiredAt = &expiredAt
+	}
+
+	switch {
+	case trail.RevokedAt != nil:
+		trail.Status = ShareLinkRevoked
+	case trail.ExpiredAt != nil:
+		trail.Status = ShareLinkExpired
+	default:
+		trail.Status = ShareLinkActive
+	}
+	return trail, true
+}
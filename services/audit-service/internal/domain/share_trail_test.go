@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewShareTrail(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	expiresAt := base.Add(48 * time.Hour)
+	event := func(id string, action AuditAction, sessionID string, hours int, details string) AuditEntry {
+		return AuditEntry{ID: id, Type: string(action), SessionID: sessionID, UserID: "user-123", Timestamp: base.Add(time.Duration(hours) * time.Hour),
+			IPAddress: "198.51.100.4", UserAgent: "Mozilla/5.0", Details: []byte(details), CorrelationID: "share-91c2"}
+	}
+	entries := []AuditEntry{
+		event("redeemed-2", ActionShareRedeemed, "session-1", 3, `{"tokenId":"share-91c2","method":"GET","path":"/api/v1/sessions/session-1/events","status":200}`),
+		event("shared", ActionShare, "session-1", 0, `{"tokenId":"share-91c2","permissions":["VIEW"],"expiresAt":"`+expiresAt.Format(time.RFC3339)+`"}`),
+		event("redeemed-1", ActionShareRedeemed, "session-1", 1, `{"tokenId":"share-91c2","method":"GET","path":"/api/v1/sessions/session-1/history","status":200}`),
+		// Events of other sessions reusing the token ID, and of other types, are left out
+		event("other-session", ActionShareRedeemed, "session-2", 2, `{}`),
+		event("view", ActionView, "session-1", 2, `{}`),
+	}
+
+	trail, ok := NewShareTrail("share-91c2", entries, base.Add(24*time.Hour))
+	require.True(t, ok)
+	assert.Equal(t, "session-1", trail.SessionID)
+	assert.Equal(t, ShareLinkActive, trail.Status)
+	assert.Equal(t, "user-123", trail.CreatedBy)
+	assert.Equal(t, base, *trail.CreatedAt)
+	assert.Equal(t, []SharePermission{SharePermissionView}, trail.Permissions)
+	assert.True(t, expiresAt.Equal(*trail.ExpiresAt))
+	assert.Nil(t, trail.ExpiredAt)
+	require.Len(t, trail.Redemptions, 2)
+	assert.Equal(t, ShareRedemption{EventID: "redeemed-1", Timestamp: base.Add(time.Hour), IPAddress: "198.51.100.4", UserAgent: "Mozilla/5.0",
+		Method: "GET", Path: "/api/v1/sessions/session-1/history", Status: 200}, trail.Redemptions[0])
+	assert.Equal(t, "redeemed-2", trail.Redemptions[1].EventID)
+
+	// Links expire once their expiry has passed, whether or not they are presented afterwards
+	trail, _ = NewShareTrail("share-91c2", entries, base.Add(72*time.Hour))
+	assert.Equal(t, ShareLinkExpired, trail.Status)
+	assert.True(t, expiresAt.Equal(*trail.ExpiredAt))
+
+	expired := append(entries, event("expired", ActionShareExpired, "session-1", 50, `{"tokenId":"share-91c2"}`))
+	trail, _ = NewShareTrail("share-91c2", expired, base.Add(51*time.Hour))
+	assert.Equal(t, ShareLinkExpired, trail.Status)
+	assert.Equal(t, 1, trail.ExpiredAttempts)
+	assert.True(t, expiresAt.Equal(*trail.ExpiredAt))
+
+	// Revocation takes precedence over expiry
+	revoked := append(expired, event("revoked", ActionUnshare, "session-1", 4, `{"tokenId":"share-91c2"}`))
+	trail, _ = NewShareTrail("share-91c2", revoked, base.Add(51*time.Hour))
+	assert.Equal(t, ShareLinkRevoked, trail.Status)
+	assert.Equal(t, base.Add(4*time.Hour), *trail.RevokedAt)
+	assert.Equal(t, "user-123", trail.RevokedBy)
+}
+
+func TestNewShareTrail_WithoutShareEvent(t *testing.T) {
+	// Links shared before their token ID was recorded are traced by their uses
+	trail, ok := NewShareTrail("share-91c2", []AuditEntry{
+		{ID: "redeemed", Type: string(ActionShareRedeemed), SessionID: "session-1", Timestamp: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)},
+	}, time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC))
+	require.True(t, ok)
+	assert.Equal(t, "session-1", trail.SessionID)
+	assert.Equal(t, ShareLinkActive, trail.Status)
+	assert.Nil(t, trail.CreatedAt)
+	assert.Len(t, trail.Redemptions, 1)
+
+	_, ok = NewShareTrail("share-91c2", []AuditEntry{{Type: string(ActionView), SessionID: "session-1"}}, time.Now())
+	assert.False(t, ok)
+}
+
+func TestShareTokenRef(t *testing.T) {
+	assert.Equal(t, "share-91c2", ShareTokenRef(ActionShare, []byte(`{"tokenId":"share-91c2"}`)))
+	assert.Equal(t, "share-91c2", ShareTokenRef(ActionUnshare, []byte(`{"tokenId":"share-91c2"}`)))
+	assert.Empty(t, ShareTokenRef(ActionExport, []byte(`{"tokenId":"share-91c2"}`)))
+	assert.Empty(t, ShareTokenRef(ActionShare, []byte(`{"tokenId":"share 91c2"}`)))
+	assert.Empty(t, ShareTokenRef(ActionShare, []byte(`{"tokenId":7}`)))
+	assert.Empty(t, ShareTokenRef(ActionShare, []byte(`{}`)))
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "encoding/json"
+
+// MaxSlideRefLength limits the slide and shape IDs indexed from event details
+const MaxSlideRefLength = 255
+
+// SlideRef returns the slideId and shapeId of event details, which are stored as indexed columns
+// so the history of a slide can be read without searching details. Both are optional; ok is false
+// when either is present but not a string of at most MaxSlideRefLength characters.
+func SlideRef(details json.RawMessage) (slideID, shapeID string, ok bool) {
+	var refs map[string]json.RawMessage
+	// Details that are not an object carry no references
+	if json.Unmarshal(details, &refs) != nil {
+		return "", "", true
+	}
+
+	if slideID, ok = detailRef(refs["slideId"], MaxSlideRefLength); !ok {
+		return "", "", false
+	}
+	if shapeID, ok = detailRef(refs["shapeId"], MaxSlideRefLength); !ok {
+		return "", "", false
+	}
+	return slideID, shapeID, true
+}
+
+// detailRef decodes an optional ID of at most maxLength characters; null counts as absent
+func detailRef(raw json.RawMessage, maxLength int) (string, bool) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", true
+	}
+	var id string
+	if err := json.Unmarshal(raw, &id); err != nil || len(id) > maxLength {
+		return "", false
+	}
+	return id, true
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlideRef(t *testing.T) {
+	tests := []struct {
+		name            string
+		details         string
+		expectedSlideID string
+		expectedShapeID string
+		expectedOK      bool
+	}{
+		{name: "slide and shape", details: `{"slideId":"slide-1","shapeId":"shape-2","after":"Hi"}`, expectedSlideID: "slide-1", expectedShapeID: "shape-2", expectedOK: true},
+		{name: "slide only", details: `{"slideId":"slide-1"}`, expectedSlideID: "slide-1", expectedOK: true},
+		{name: "none", details: `{"action":"text_translated"}`, expectedOK: true},
+		{name: "null", details: `{"slideId":null}`, expectedOK: true},
+		{name: "not an object", details: `["slide-1"]`, expectedOK: true},
+		{name: "empty", details: ``, expectedOK: true},
+		{name: "numeric slide", details: `{"slideId":3}`},
+		{name: "object shape", details: `{"slideId":"slide-1","shapeId":{"id":"shape-2"}}`},
+		{name: "too long", details: `{"slideId":"` + strings.Repeat("s", MaxSlideRefLength+1) + `"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slideID, shapeID, ok := SlideRef(json.RawMessage(tt.details))
+
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expectedSlideID, slideID)
+			assert.Equal(t, tt.expectedShapeID, shapeID)
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// EntrySeverity grades audit entries for security review; it is derived from the event type
+// through the Taxonomy, so reclassifying a type applies to the events already stored
+type EntrySeverity string
+
+// Entry severities
+const (
+	EntrySeverityInfo     EntrySeverity = "info"
+	EntrySeverityWarning  EntrySeverity = "warning"
+	EntrySeverityCritical EntrySeverity = "critical"
+)
+
+// Valid reports whether the severity is supported
+func (s EntrySeverity) Valid() bool {
+	switch s {
+	case EntrySeverityInfo, EntrySeverityWarning, EntrySeverityCritical:
+		return true
+	}
+	return false
+}
+
+// EventCategory separates what audit entries are about, e.g. access to a session from edits of its content
+type EventCategory string
+
+// Event categories
+const (
+	CategoryContent  EventCategory = "content"
+	CategoryAccess   EventCategory = "access"
+	CategorySecurity EventCategory = "security"
+	CategorySystem   EventCategory = "system"
+	// CategoryUsage holds metered calls to external services, e.g. machine translation providers
+	CategoryUsage EventCategory = "usage"
+)
+
+// Valid reports whether the category is supported
+func (c EventCategory) Valid() bool {
+	switch c {
+	case CategoryContent, CategoryAccess, CategorySecurity, CategorySystem, CategoryUsage:
+		return true
+	}
+	return false
+}
+
+// Classification is the category and severity of an event type
+type Classification struct {
+	Category EventCategory
+	Severity EntrySeverity
+}
+
+// defaultClassifications classify the built-in event types
+var defaultClassifications = map[AuditAction]Classification{
+	ActionCreate:             {CategoryContent, EntrySeverityInfo},
+	ActionEdit:               {CategoryContent, EntrySeverityInfo},
+	ActionMerge:              {CategoryContent, EntrySeverityInfo},
+	ActionReorder:            {CategoryContent, EntrySeverityInfo},
+	ActionComment:            {CategoryContent, EntrySeverityInfo},
+	ActionCommentCreated:     {CategoryContent, EntrySeverityInfo},
+	ActionCommentEdited:      {CategoryContent, EntrySeverityInfo},
+	ActionCommentResolved:    {CategoryContent, EntrySeverityInfo},
+	ActionCommentDeleted:     {CategoryContent, EntrySeverityInfo},
+	ActionSubmittedForReview: {CategoryContent, EntrySeverityInfo},
+	ActionApproved:           {CategoryContent, EntrySeverityInfo},
+	ActionRejected:           {CategoryContent, EntrySeverityInfo},
+	ActionReopened:           {CategoryContent, EntrySeverityInfo},
+	ActionTMHit:              {CategoryContent, EntrySeverityInfo},
+	ActionTMOverride:         {CategoryContent, EntrySeverityInfo},
+	ActionThumbnail:          {CategoryContent, EntrySeverityInfo},
+	ActionView:               {CategoryAccess, EntrySeverityInfo},
+	ActionExport:             {CategoryAccess, EntrySeverityWarning},
+	ActionShare:              {CategoryAccess, EntrySeverityWarning},
+	ActionUnshare:            {CategoryAccess, EntrySeverityWarning},
+	ActionShareRedeemed:      {CategoryAccess, EntrySeverityInfo},
+	ActionShareExpired:       {CategoryAccess, EntrySeverityWarning},
+	ActionSecurityAlert:      {CategorySecurity, EntrySeverityCritical},
+	ActionUserErasure:        {CategorySecurity, EntrySeverityWarning},
+	ActionExternalChange:     {CategorySecurity, EntrySeverityWarning},
+	ActionAuthFailure:        {CategorySecurity, EntrySeverityWarning},
+	ActionAnnotationCreated:  {CategorySecurity, EntrySeverityInfo},
+	ActionAnnotationEdited:   {CategorySecurity, EntrySeverityInfo},
+	ActionAnnotationDeleted:  {CategorySecurity, EntrySeverityWarning},
+	ActionRetentionPurge:     {CategorySystem, EntrySeverityInfo},
+	ActionExportLifecycle:    {CategorySystem, EntrySeverityInfo},
+	ActionConfigChanged:      {CategorySystem, EntrySeverityWarning},
+	ActionMTRequest:          {CategoryUsage, EntrySeverityInfo},
+}
+
+// Taxonomy maps event types to their category and severity. Types it does not list, such as
+// custom ones, are content whose severity follows the severity they were registered with.
+type Taxonomy struct {
+	classes map[AuditAction]Classification
+}
+
+// DefaultTaxonomy classifies the built-in event types
+func DefaultTaxonomy() *Taxonomy {
+	classes := make(map[AuditAction]Classification, len(defaultClassifications))
+	for action, class := range defaultClassifications {
+		classes[action] = class
+	}
+	return &Taxonomy{classes: classes}
+}
+
+// ParseTaxonomy returns the default taxonomy with the classifications of spec, a
+// comma-separated list of type=category:severity such as "export=access:critical"
+func ParseTaxonomy(spec string) (*Taxonomy, error) {
+	taxonomy := DefaultTaxonomy()
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(item, "=")
+		category, severity, hasSeverity := strings.Cut(value, ":")
+		name = strings.TrimSpace(name)
+		if !found || !hasSeverity || !ValidEventTypeName(name) {
+			return nil, fmt.Errorf("invalid classification %q, expected type=category:severity", item)
+		}
+
+		class := Classification{
+			Category: EventCategory(strings.TrimSpace(category)),
+			Severity: EntrySeverity(strings.TrimSpace(severity)),
+		}
+		if !class.Category.Valid() {
+			return nil, fmt.Errorf("invalid category %q for %s, expected content, access, security, system or usage", class.Category, name)
+		}
+		if !class.Severity.Valid() {
+			return nil, fmt.Errorf("invalid severity %q for %s, expected info, warning or critical", class.Severity, name)
+		}
+		taxonomy.classes[AuditAction(name)] = class
+	}
+	return taxonomy, nil
+}
+
+// Classify returns the category and severity of events of a type
+func (t *Taxonomy) Classify(eventType EventType) Classification {
+	if class, ok := t.classes[eventType.Name]; ok {
+		return class
+	}
+
+	class := Classification{Category: CategoryContent, Severity: EntrySeverityInfo}
+	switch eventType.Severity {
+	case SeverityMedium:
+		class.Severity = EntrySeverityWarning
+	case SeverityHigh:
+		class.Severity = EntrySeverityCritical
+	}
+	return class
+}
+
+// Types returns the names of the event types whose classification passes the category and
+// severity filters, out of eventTypes and the types the taxonomy lists, sorted by name
+func (t *Taxonomy) Types(eventTypes []EventType, categories []EventCategory, severities []EntrySeverity) []string {
+	classes := make(map[AuditAction]Classification, len(t.classes)+len(eventTypes))
+	for action, class := range t.classes {
+		classes[action] = class
+	}
+	for _, eventType := range eventTypes {
+		classes[eventType.Name] = t.Classify(eventType)
+	}
+
+	var types []string
+	for action, class := range classes {
+		if class.Matches(categories, severities) {
+			types = append(types, string(action))
+		}
+	}
+	slices.Sort(types)
+	return types
+}
+
+// Matches reports whether events of a type pass the category and severity filters; empty
+// filters pass every type
+func (c Classification) Matches(categories []EventCategory, severities []EntrySeverity) bool {
+	return (len(categories) == 0 || slices.Contains(categories, c.Category)) &&
+		(len(severities) == 0 || slices.Contains(severities, c.Severity))
+}
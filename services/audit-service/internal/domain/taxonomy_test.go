@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaxonomy_Classify(t *testing.T) {
+	taxonomy := DefaultTaxonomy()
+
+	assert.Equal(t, Classification{CategoryContent, EntrySeverityInfo}, taxonomy.Classify(EventType{Name: ActionEdit}))
+	assert.Equal(t, Classification{CategoryAccess, EntrySeverityWarning}, taxonomy.Classify(EventType{Name: ActionShare}))
+	assert.Equal(t, Classification{CategorySecurity, EntrySeverityCritical}, taxonomy.Classify(EventType{Name: ActionSecurityAlert}))
+
+	// Custom types are content, as severe as they were registered
+	assert.Equal(t, Classification{CategoryContent, EntrySeverityInfo},
+		taxonomy.Classify(EventType{Name: "terminology_approved", Severity: SeverityLow}))
+	assert.Equal(t, Classification{CategoryContent, EntrySeverityCritical},
+		taxonomy.Classify(EventType{Name: "glossary_deleted", Severity: SeverityHigh}))
+}
+
+func TestParseTaxonomy(t *testing.T) {
+	taxonomy, err := ParseTaxonomy(" export=access:critical, terminology_approved = content:warning ")
+	require.NoError(t, err)
+
+	assert.Equal(t, Classification{CategoryAccess, EntrySeverityCritical}, taxonomy.Classify(EventType{Name: ActionExport}))
+	assert.Equal(t, Classification{CategoryContent, EntrySeverityWarning}, taxonomy.Classify(EventType{Name: "terminology_approved"}))
+	// Types left out keep their default classification
+	assert.Equal(t, Classification{CategoryAccess, EntrySeverityInfo}, taxonomy.Classify(EventType{Name: ActionView}))
+
+	for _, spec := range []string{"export", "export=access", "export=billing:info", "export=access:high", "Export=access:info"} {
+		_, err := ParseTaxonomy(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestTaxonomy_Types(t *testing.T) {
+	taxonomy := DefaultTaxonomy()
+	custom := []EventType{{Name: "glossary_deleted", Severity: SeverityHigh}}
+
+	assert.Equal(t, []string{"export", "share", "share_expired", "share_redeemed", "unshare", "view"},
+		taxonomy.Types(custom, []EventCategory{CategoryAccess}, nil))
+	assert.Equal(t, []string{"glossary_deleted", "security_alert"},
+		taxonomy.Types(custom, nil, []EntrySeverity{EntrySeverityCritical}))
+	assert.Empty(t, taxonomy.Types(custom, []EventCategory{CategorySystem}, []EntrySeverity{EntrySeverityCritical}))
+}
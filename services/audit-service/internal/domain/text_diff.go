@@ -0,0 +1,484 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrNoTextChange is returned for events that do not record the text before and after an edit
+var ErrNoTextChange = errors.New("event records no text change")
+
+const (
+	// MaxStoredDiffLength caps the characters of the before and after texts of an edit for
+	// which a diff is stored on ingestion; longer edits are diffed when read
+	MaxStoredDiffLength = 20000
+
+	// maxDiffEdits caps the words inserted and deleted that are matched word by word; texts that
+	// differ in more are diffed as one replacement between their common prefix and suffix
+	maxDiffEdits = 500
+)
+
+// TextDiffOp is the operation of a span of a text diff
+type TextDiffOp string
+
+// Operations of text diff spans
+const (
+	DiffEqual  TextDiffOp = "equal"
+	DiffInsert TextDiffOp = "insert"
+	DiffDelete TextDiffOp = "delete"
+)
+
+// TextDiffSpan is a span of a text diff. In the compact form equal and delete spans give the
+// number of characters of the before text they cover; in the standalone form stored in place of
+// the texts they carry that text. Insert spans always carry the inserted text.
+type TextDiffSpan struct {
+	Op     TextDiffOp `json:"op"`
+	Length int        `json:"length,omitempty"`
+	Text   string     `json:"text,omitempty"`
+}
+
+// TextDiff is the compact diff of the text an edit replaced, in the order of the text. Within a
+// change, deletions come before insertions.
+type TextDiff []TextDiffSpan
+
+// RenderedDiffSpan is a span of a rendered diff, with the text it covers
+type RenderedDiffSpan struct {
+	Op   TextDiffOp `json:"op" example:"insert"`
+	Text string     `json:"text" example:"Bonjour"`
+}
+
+// EditDiff is the rendered diff of the text replaced by an edit event, ready to display
+type EditDiff struct {
+	EventID   string `json:"eventId" example:"550e8400-e29b-41d4-a716-446655440010"`
+	SessionID string `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	SlideID   string `json:"slideId,omitempty" example:"slide-3"`
+	ShapeID   string `json:"shapeId,omitempty" example:"shape-12"`
+	// Spans cover the before text with the insertions added, in order
+	Spans []RenderedDiffSpan `json:"spans"`
+	// Inserted and Deleted count the characters inserted and deleted
+	Inserted int `json:"inserted" example:"7"`
+	Deleted  int `json:"deleted" example:"5"`
+	// Stored reports whether the diff was stored with the event, rather than computed when read
+	Stored bool `json:"stored" example:"true"`
+}
+
+// ComputeTextDiff returns the compact diff turning before into after. Texts are compared word by
+// word, with whitespace, punctuation and CJK characters as words of their own.
+func ComputeTextDiff(before, after string) TextDiff {
+	a, b := diffTokens(before), diffTokens(after)
+
+	// The common prefix and suffix are kept out of the word by word comparison
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var builder diffBuilder
+	builder.add(DiffEqual, a[:prefix]...)
+	middleA, middleB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if ops, ok := myersDiff(middleA, middleB, maxDiffEdits); ok {
+		for _, op := range ops {
+			builder.add(op.op, op.token)
+		}
+	} else {
+		builder.add(DiffDelete, middleA...)
+		builder.add(DiffInsert, middleB...)
+	}
+	builder.add(DiffEqual, a[len(a)-suffix:]...)
+	return builder.diff()
+}
+
+// Render expands the diff against the text it was computed from. It fails when the spans do
+// not cover before exactly, such as for diffs stored with a redacted text.
+func (d TextDiff) Render(before string) ([]RenderedDiffSpan, error) {
+	runes := []rune(before)
+	spans := make([]RenderedDiffSpan, 0, len(d))
+	position := 0
+	for _, span := range d {
+		switch span.Op {
+		case DiffEqual, DiffDelete:
+			if span.Length <= 0 || position+span.Length > len(runes) {
+				return nil, fmt.Errorf("%w: diff does not match the text before", ErrNoTextChange)
+			}
+			spans = append(spans, RenderedDiffSpan{Op: span.Op, Text: string(runes[position : position+span.Length])})
+			position += span.Length
+		case DiffInsert:
+			if span.Text == "" {
+				return nil, fmt.Errorf("%w: diff inserts no text", ErrNoTextChange)
+			}
+			spans = append(spans, RenderedDiffSpan{Op: DiffInsert, Text: span.Text})
+		default:
+			return nil, fmt.Errorf("%w: unknown diff operation %q", ErrNoTextChange, span.Op)
+		}
+	}
+	if position != len(runes) {
+		return nil, fmt.Errorf("%w: diff does not match the text before", ErrNoTextChange)
+	}
+	return spans, nil
+}
+
+// Standalone returns the diff with the text of every span, against the text it was computed
+// from, so it can be stored instead of the texts before and after
+func (d TextDiff) Standalone(before string) (TextDiff, error) {
+	spans, err := d.Render(before)
+	if err != nil {
+		return nil, err
+	}
+	standalone := make(TextDiff, len(spans))
+	for i, span := range spans {
+		standalone[i] = TextDiffSpan{Op: span.Op, Text: span.Text}
+	}
+	return standalone, nil
+}
+
+// Texts returns the texts before and after of a standalone diff, and the compact diff between
+// them. It fails for spans without their text, such as those of a compact diff.
+func (d TextDiff) Texts() (before, after string, compact TextDiff, err error) {
+	var b, a strings.Builder
+	compact = make(TextDiff, 0, len(d))
+	for _, span := range d {
+		if span.Text == "" {
+			return "", "", nil, fmt.Errorf("%w: diff span carries no text", ErrNoTextChange)
+		}
+		switch span.Op {
+		case DiffEqual, DiffDelete:
+			b.WriteString(span.Text)
+			compact = append(compact, TextDiffSpan{Op: span.Op, Length: utf8.RuneCountInString(span.Text)})
+		case DiffInsert:
+			compact = append(compact, span)
+		default:
+			return "", "", nil, fmt.Errorf("%w: unknown diff operation %q", ErrNoTextChange, span.Op)
+		}
+		if span.Op != DiffDelete {
+			a.WriteString(span.Text)
+		}
+	}
+	return b.String(), a.String(), compact, nil
+}
+
+// editTextDetails are the details of an edit event read for its diff, in the layout of version 2
+type editTextDetails struct {
+	Text *struct {
+		Before *string  `json:"before"`
+		After  *string  `json:"after"`
+		Diff   TextDiff `json:"diff"`
+	} `json:"text"`
+}
+
+// WithEditDiff returns the details of an edit event with the text it replaced stored as a diff:
+// as text.diff in version 2 details and as diff in the flat layout of version 1, which the
+// upgrade to version 2 moves into text. The standalone diff replaces before and after when it is
+// smaller than they are, and ExpandEditDiff restores them when read; otherwise the texts are kept
+// without a diff. A diff sent by the client is dropped. Details of other events are returned as
+// they are, as are the texts of edits without both or longer than MaxStoredDiffLength together.
+func WithEditDiff(action AuditAction, schemaVersion int, details json.RawMessage) json.RawMessage {
+	if action != ActionEdit {
+		return details
+	}
+	fields, container, ok := editTextContainer(details, schemaVersion)
+	if !ok {
+		return details
+	}
+
+	delete(container, "diff")
+	before, hasBefore := jsonString(container["before"])
+	after, hasAfter := jsonString(container["after"])
+	if hasBefore && hasAfter && utf8.RuneCountInString(before)+utf8.RuneCountInString(after) <= MaxStoredDiffLength {
+		diff, err := ComputeTextDiff(before, after).Standalone(before)
+		if err != nil {
+			return details
+		}
+		encoded, err := json.Marshal(diff)
+		if err != nil {
+			return details
+		}
+		if len(encoded) < len(container["before"])+len(container["after"]) {
+			delete(container, "before")
+			delete(container, "after")
+			container["diff"] = encoded
+		}
+	}
+	return encodeEditText(details, fields, container, schemaVersion)
+}
+
+// ExpandEditDiff restores the texts before and after of an edit stored as a standalone diff, with
+// the compact diff beside them, so readers see the details in the layout they were sent in.
+// Other entries are returned as they are.
+func ExpandEditDiff(entry AuditEntry) AuditEntry {
+	if entry.Type != string(ActionEdit) {
+		return entry
+	}
+	version := max(entry.SchemaVersion, 1)
+	fields, container, ok := editTextContainer(entry.Details, version)
+	if !ok || container["diff"] == nil || container["before"] != nil || container["after"] != nil {
+		return entry
+	}
+
+	var diff TextDiff
+	if json.Unmarshal(container["diff"], &diff) != nil || diff == nil {
+		return entry
+	}
+	before, after, compact, err := diff.Texts()
+	if err != nil {
+		return entry
+	}
+	for key, value := range map[string]interface{}{"before": before, "after": after, "diff": compact} {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return entry
+		}
+		container[key] = encoded
+	}
+	entry.Details = encodeEditText(entry.Details, fields, container, version)
+	return entry
+}
+
+// editTextContainer decodes the details of an edit and returns them with the object holding its
+// text: the details themselves in version 1, their text object from version 2
+func editTextContainer(details json.RawMessage, schemaVersion int) (fields, container map[string]json.RawMessage, ok bool) {
+	if json.Unmarshal(details, &fields) != nil || fields == nil {
+		return nil, nil, false
+	}
+	if schemaVersion < 2 {
+		return fields, fields, true
+	}
+	if json.Unmarshal(fields["text"], &container) != nil || container == nil {
+		return nil, nil, false
+	}
+	return fields, container, true
+}
+
+// encodeEditText encodes details whose text object was changed, or returns the original details
+// when they cannot be encoded
+func encodeEditText(original json.RawMessage, fields, container map[string]json.RawMessage, schemaVersion int) json.RawMessage {
+	if schemaVersion >= 2 {
+		encoded, err := json.Marshal(container)
+		if err != nil {
+			return original
+		}
+		fields["text"] = encoded
+	}
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return original
+	}
+	return encoded
+}
+
+// jsonString decodes a JSON string value, reporting false for any other value, null included
+func jsonString(value json.RawMessage) (string, bool) {
+	var text string
+	if len(value) == 0 || value[0] != '"' || json.Unmarshal(value, &text) != nil {
+		return "", false
+	}
+	return text, true
+}
+
+// RenderEditDiff renders the diff of the text replaced by an edit event, from version 2 details.
+// The diff stored with the event is used when it matches the text before; others are computed.
+func RenderEditDiff(entry AuditEntry) (*EditDiff, error) {
+	if entry.Type != string(ActionEdit) {
+		return nil, fmt.Errorf("%w: only edit events record text changes", ErrNoTextChange)
+	}
+	details, err := DecodeDetails[editTextDetails](entry)
+	if err != nil || details.Text == nil || details.Text.Before == nil || details.Text.After == nil {
+		return nil, fmt.Errorf("%w: the edit records no text.before and text.after", ErrNoTextChange)
+	}
+	before, after := *details.Text.Before, *details.Text.After
+
+	diff := &EditDiff{EventID: entry.ID, SessionID: entry.SessionID, SlideID: entry.SlideID, ShapeID: entry.ShapeID}
+	if details.Text.Diff != nil {
+		if spans, err := details.Text.Diff.Render(before); err == nil {
+			diff.Spans, diff.Stored = spans, true
+		}
+	}
+	if !diff.Stored {
+		// Edits stored before diffs were, or with texts too long to store one, are diffed now
+		diff.Spans, err = ComputeTextDiff(before, after).Render(before)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, span := range diff.Spans {
+		switch span.Op {
+		case DiffInsert:
+			diff.Inserted += utf8.RuneCountInString(span.Text)
+		case DiffDelete:
+			diff.Deleted += utf8.RuneCountInString(span.Text)
+		}
+	}
+	return diff, nil
+}
+
+// diffTokens splits a text into the words it is compared by: runs of letters and digits, runs of
+// whitespace, and every other character, CJK characters included, on its own
+func diffTokens(text string) []string {
+	var tokens []string
+	start := 0
+	class := 0
+	for i, r := range text {
+		c := tokenClass(r)
+		if i > start && (c != class || c == tokenSingle) {
+			tokens = append(tokens, text[start:i])
+			start = i
+		}
+		class = c
+	}
+	if start < len(text) {
+		tokens = append(tokens, text[start:])
+	}
+	return tokens
+}
+
+// Classes of the characters of diff tokens
+const (
+	tokenWord = iota + 1
+	tokenSpace
+	tokenSingle
+)
+
+// tokenClass returns the class of a character of a diff token
+func tokenClass(r rune) int {
+	switch {
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+		return tokenSingle
+	case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+		return tokenWord
+	case unicode.IsSpace(r):
+		return tokenSpace
+	default:
+		return tokenSingle
+	}
+}
+
+// diffOp is one token of an edit script
+type diffOp struct {
+	op    TextDiffOp
+	token string
+}
+
+// myersDiff returns the shortest edit script turning a into b, by the algorithm of Myers, or
+// false when it takes more than maxEdits insertions and deletions
+func myersDiff(a, b []string, maxEdits int) ([]diffOp, bool) {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxEdits)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrackDiff(trace, a, b, offset), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// backtrackDiff walks the trace of myersDiff back from the end of both texts
+func backtrackDiff(trace [][]int, a, b []string, offset int) []diffOp {
+	var ops []diffOp
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{DiffEqual, a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, diffOp{DiffInsert, b[y-1]})
+			} else {
+				ops = append(ops, diffOp{DiffDelete, a[x-1]})
+			}
+			x, y = prevX, prevY
+		}
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// diffBuilder merges the tokens of an edit script into spans, moving the deletions of a change
+// before its insertions
+type diffBuilder struct {
+	spans    TextDiff
+	inserted strings.Builder
+	deleted  int
+}
+
+// add appends tokens with an operation
+func (b *diffBuilder) add(op TextDiffOp, tokens ...string) {
+	for _, token := range tokens {
+		switch op {
+		case DiffInsert:
+			b.inserted.WriteString(token)
+		case DiffDelete:
+			b.deleted += utf8.RuneCountInString(token)
+		default:
+			b.flush()
+			if last := len(b.spans) - 1; last >= 0 && b.spans[last].Op == DiffEqual {
+				b.spans[last].Length += utf8.RuneCountInString(token)
+			} else {
+				b.spans = append(b.spans, TextDiffSpan{Op: DiffEqual, Length: utf8.RuneCountInString(token)})
+			}
+		}
+	}
+}
+
+// flush ends the change being built
+func (b *diffBuilder) flush() {
+	if b.deleted > 0 {
+		b.spans = append(b.spans, TextDiffSpan{Op: DiffDelete, Length: b.deleted})
+		b.deleted = 0
+	}
+	if b.inserted.Len() > 0 {
+		b.spans = append(b.spans, TextDiffSpan{Op: DiffInsert, Text: b.inserted.String()})
+		b.inserted.Reset()
+	}
+}
+
+// diff returns the spans built
+func (b *diffBuilder) diff() TextDiff {
+	b.flush()
+	if b.spans == nil {
+		return TextDiff{}
+	}
+	return b.spans
+}
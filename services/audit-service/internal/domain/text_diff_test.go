@@ -0,0 +1,242 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// applyDiff rebuilds the after text from a rendered diff
+func applyDiff(spans []RenderedDiffSpan) (before, after string) {
+	var b, a strings.Builder
+	for _, span := range spans {
+		if span.Op != DiffInsert {
+			b.WriteString(span.Text)
+		}
+		if span.Op != DiffDelete {
+			a.WriteString(span.Text)
+		}
+	}
+	return b.String(), a.String()
+}
+
+func TestComputeTextDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected TextDiff
+	}{
+		{
+			name:   "word replaced",
+			before: "Hello brave world",
+			after:  "Hello new world",
+			expected: TextDiff{
+				{Op: DiffEqual, Length: 6},
+				{Op: DiffDelete, Length: 5},
+				{Op: DiffInsert, Text: "new"},
+				{Op: DiffEqual, Length: 6},
+			},
+		},
+		{
+			name:     "appended",
+			before:   "Bonjour",
+			after:    "Bonjour, monde",
+			expected: TextDiff{{Op: DiffEqual, Length: 7}, {Op: DiffInsert, Text: ", monde"}},
+		},
+		{
+			name:     "unchanged",
+			before:   "Same text",
+			after:    "Same text",
+			expected: TextDiff{{Op: DiffEqual, Length: 9}},
+		},
+		{
+			name:     "both empty",
+			expected: TextDiff{},
+		},
+		{
+			name:     "CJK characters are words of their own",
+			before:   "你好世界",
+			after:    "你好朋友",
+			expected: TextDiff{{Op: DiffEqual, Length: 2}, {Op: DiffDelete, Length: 2}, {Op: DiffInsert, Text: "朋友"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := ComputeTextDiff(tt.before, tt.after)
+			assert.Equal(t, tt.expected, diff)
+
+			spans, err := diff.Render(tt.before)
+			require.NoError(t, err)
+			before, after := applyDiff(spans)
+			assert.Equal(t, tt.before, before)
+			assert.Equal(t, tt.after, after)
+		})
+	}
+}
+
+func TestComputeTextDiff_RoundTrip(t *testing.T) {
+	before := "The quick brown fox jumps over the lazy dog. It was not amused, and left."
+	after := "A quick red fox leaped over the dog! It was amused, and stayed for tea."
+
+	spans, err := ComputeTextDiff(before, after).Render(before)
+	require.NoError(t, err)
+	gotBefore, gotAfter := applyDiff(spans)
+	assert.Equal(t, before, gotBefore)
+	assert.Equal(t, after, gotAfter)
+
+	// Texts differing in too many words are diffed as one replacement within the common ends
+	long := strings.Repeat("a ", maxDiffEdits) + "end"
+	other := strings.Repeat("b ", maxDiffEdits) + "end"
+	diff := ComputeTextDiff(long, other)
+	assert.Equal(t, TextDiff{
+		{Op: DiffDelete, Length: 2*maxDiffEdits - 1},
+		{Op: DiffInsert, Text: strings.Repeat("b ", maxDiffEdits-1) + "b"},
+		{Op: DiffEqual, Length: 4},
+	}, diff)
+}
+
+func TestTextDiff_Render(t *testing.T) {
+	for name, diff := range map[string]TextDiff{
+		"too short":    {{Op: DiffEqual, Length: 3}},
+		"too long":     {{Op: DiffEqual, Length: 9}},
+		"empty insert": {{Op: DiffEqual, Length: 5}, {Op: DiffInsert}},
+		"unknown op":   {{Op: "replace", Length: 5}},
+		"zero length":  {{Op: DiffDelete}, {Op: DiffEqual, Length: 5}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := diff.Render("Hello")
+			assert.ErrorIs(t, err, ErrNoTextChange)
+		})
+	}
+}
+
+// paragraph is a text long enough for its diff against a small change to be stored
+const paragraph = "Quarterly revenue grew across every region, led by strong demand for the new product line"
+
+func TestTextDiff_StandaloneRoundTrip(t *testing.T) {
+	before, after := "Hello brave world", "Hello new world"
+	compact := ComputeTextDiff(before, after)
+
+	standalone, err := compact.Standalone(before)
+	require.NoError(t, err)
+	assert.Equal(t, TextDiff{
+		{Op: DiffEqual, Text: "Hello "},
+		{Op: DiffDelete, Text: "brave"},
+		{Op: DiffInsert, Text: "new"},
+		{Op: DiffEqual, Text: " world"},
+	}, standalone)
+
+	gotBefore, gotAfter, gotCompact, err := standalone.Texts()
+	require.NoError(t, err)
+	assert.Equal(t, before, gotBefore)
+	assert.Equal(t, after, gotAfter)
+	assert.Equal(t, compact, gotCompact)
+
+	// Compact spans do not carry the text they cover
+	_, _, _, err = compact.Texts()
+	assert.ErrorIs(t, err, ErrNoTextChange)
+}
+
+func TestWithEditDiff(t *testing.T) {
+	t.Run("version 2 stores the diff instead of the texts", func(t *testing.T) {
+		details := WithEditDiff(ActionEdit, 2, json.RawMessage(`{"slideId":"slide-1","text":{"before":"`+paragraph+`","after":"`+paragraph+`.","diff":"forged"}}`))
+		assert.JSONEq(t, `{"slideId":"slide-1","text":{"diff":[{"op":"equal","text":"`+paragraph+`"},{"op":"insert","text":"."}]}}`, string(details))
+	})
+
+	t.Run("version 1 upgrades with the diff", func(t *testing.T) {
+		details := WithEditDiff(ActionEdit, 1, json.RawMessage(`{"before":"`+paragraph+`","after":"`+paragraph+`."}`))
+		assert.JSONEq(t, `{"diff":[{"op":"equal","text":"`+paragraph+`"},{"op":"insert","text":"."}]}`, string(details))
+
+		upgraded, err := upgradeEditV2(map[string]interface{}{"before": "a", "after": "b", "diff": []interface{}{}})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"text": map[string]interface{}{"before": "a", "after": "b", "diff": []interface{}{}}}, upgraded)
+	})
+
+	t.Run("texts kept when the diff is not smaller", func(t *testing.T) {
+		details := WithEditDiff(ActionEdit, 2, json.RawMessage(`{"text":{"before":"Hi there","after":"Hi you","diff":"forged"}}`))
+		assert.JSONEq(t, `{"text":{"before":"Hi there","after":"Hi you"}}`, string(details))
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			action  AuditAction
+			version int
+			details string
+		}{
+			"other type":     {ActionComment, 1, `{"before":"a","after":"b"}`},
+			"no text":        {ActionEdit, 2, `{"state":{"after":{}}}`},
+			"not object":     {ActionEdit, 2, `"edit"`},
+			"after only":     {ActionEdit, 2, `{"text":{"after":"` + paragraph + `"}}`},
+			"null before":    {ActionEdit, 2, `{"text":{"before":null,"after":"` + paragraph + `"}}`},
+			"texts too long": {ActionEdit, 2, `{"text":{"before":"` + strings.Repeat("a", MaxStoredDiffLength) + `","after":"b"}}`},
+		} {
+			t.Run(name, func(t *testing.T) {
+				assert.JSONEq(t, tc.details, string(WithEditDiff(tc.action, tc.version, json.RawMessage(tc.details))))
+			})
+		}
+	})
+}
+
+func TestExpandEditDiff(t *testing.T) {
+	for _, version := range []int{1, 2} {
+		sent := `{"slideId":"slide-1","text":{"before":"` + paragraph + `","after":"` + paragraph + `."}}`
+		expanded := `{"slideId":"slide-1","text":{"before":"` + paragraph + `","after":"` + paragraph + `.",` +
+			`"diff":[{"op":"equal","length":89},{"op":"insert","text":"."}]}}`
+		if version == 1 {
+			sent = `{"slideId":"slide-1","before":"` + paragraph + `","after":"` + paragraph + `."}`
+			expanded = `{"slideId":"slide-1","before":"` + paragraph + `","after":"` + paragraph + `.",` +
+				`"diff":[{"op":"equal","length":89},{"op":"insert","text":"."}]}`
+		}
+		entry := AuditEntry{Type: "edit", SchemaVersion: version, Details: WithEditDiff(ActionEdit, version, json.RawMessage(sent))}
+		require.Less(t, len(entry.Details), len(sent))
+
+		assert.JSONEq(t, expanded, string(ExpandEditDiff(entry).Details))
+	}
+
+	// Entries stored with their texts, and other types, are returned as they are
+	for _, entry := range []AuditEntry{
+		{Type: "edit", SchemaVersion: 2, Details: json.RawMessage(`{"text":{"before":"a","after":"b","diff":[{"op":"equal","text":"x"}]}}`)},
+		{Type: "edit", SchemaVersion: 2, Details: json.RawMessage(`{"text":{"diff":null}}`)},
+		{Type: "comment", Details: json.RawMessage(`{"diff":[{"op":"equal","text":"x"}]}`)},
+	} {
+		assert.Equal(t, entry, ExpandEditDiff(entry))
+	}
+}
+
+func TestRenderEditDiff(t *testing.T) {
+	entry := AuditEntry{ID: "audit-1", SessionID: "session-1", Type: "edit", SlideID: "slide-1", SchemaVersion: 2,
+		Details: json.RawMessage(`{"text":{"before":"Hello world","after":"Hello there world",` +
+			`"diff":[{"op":"equal","length":6},{"op":"insert","text":"there "},{"op":"equal","length":5}]}}`)}
+
+	diff, err := RenderEditDiff(entry)
+	require.NoError(t, err)
+	assert.True(t, diff.Stored)
+	assert.Equal(t, "slide-1", diff.SlideID)
+	assert.Equal(t, []RenderedDiffSpan{{Op: DiffEqual, Text: "Hello "}, {Op: DiffInsert, Text: "there "}, {Op: DiffEqual, Text: "world"}}, diff.Spans)
+	assert.Equal(t, 6, diff.Inserted)
+	assert.Equal(t, 0, diff.Deleted)
+
+	// Edits stored without a diff, or with one that does not match, are diffed when read
+	entry.Details = json.RawMessage(`{"text":{"before":"Hello world","after":"Bye world","diff":[{"op":"equal","length":99}]}}`)
+	diff, err = RenderEditDiff(entry)
+	require.NoError(t, err)
+	assert.False(t, diff.Stored)
+	assert.Equal(t, 3, diff.Inserted)
+	assert.Equal(t, 5, diff.Deleted)
+
+	for name, entry := range map[string]AuditEntry{
+		"other type": {Type: "comment", Details: json.RawMessage(`{"text":{"before":"a","after":"b"}}`)},
+		"no before":  {Type: "edit", Details: json.RawMessage(`{"text":{"after":"b"}}`)},
+		"no details": {Type: "edit"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := RenderEditDiff(entry)
+			assert.ErrorIs(t, err, ErrNoTextChange)
+		})
+	}
+}
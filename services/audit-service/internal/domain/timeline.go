@@ -0,0 +1,225 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxTimelineEvents caps the events read for one session timeline; the timeline is marked
+// truncated when a session has more, and leaves out the oldest
+const MaxTimelineEvents = 10000
+
+const (
+	// DefaultTimelineGap is the longest pause within a burst of edits or a run of views
+	DefaultTimelineGap = 5 * time.Minute
+	// MinTimelineGap and MaxTimelineGap bound the pause a timeline may be requested with
+	MinTimelineGap = time.Second
+	MaxTimelineGap = 24 * time.Hour
+)
+
+// timelineNamespace derives the group IDs of timelines from the IDs of their first events
+var timelineNamespace = uuid.MustParse("8f6a4d1e-2c3b-5e7f-9a0b-1c2d3e4f5a6b")
+
+// TimelineQuery restricts a session timeline to a time range; zero bounds are open. Gap is the
+// longest pause within a group, DefaultTimelineGap when zero.
+type TimelineQuery struct {
+	// From and To bound the events, both inclusive
+	From time.Time
+	To   time.Time
+	Gap  time.Duration
+}
+
+// Validate ensures the range is ordered and the gap within its bounds
+func (q TimelineQuery) Validate() error {
+	if !q.From.IsZero() && !q.To.IsZero() && q.From.After(q.To) {
+		return fmt.Errorf("%w: from must not be after to", ErrInvalidFilter)
+	}
+	if q.Gap != 0 && (q.Gap < MinTimelineGap || q.Gap > MaxTimelineGap) {
+		return fmt.Errorf("%w: gap must be between %d and %d seconds", ErrInvalidFilter,
+			int(MinTimelineGap.Seconds()), int(MaxTimelineGap.Seconds()))
+	}
+	return nil
+}
+
+// TimelineGroupKind is how a group of a timeline merges its events
+type TimelineGroupKind string
+
+const (
+	// TimelineEdits is a burst of edits, merges and reorders by one user
+	TimelineEdits TimelineGroupKind = "edits"
+	// TimelineViews is a run of repeated views by one user
+	TimelineViews TimelineGroupKind = "views"
+	// TimelineEvent is a single event of any other type
+	TimelineEvent TimelineGroupKind = "event"
+)
+
+// timelineKind returns how events of an action are grouped
+func timelineKind(action AuditAction) TimelineGroupKind {
+	switch action {
+	case ActionEdit, ActionMerge, ActionReorder:
+		return TimelineEdits
+	case ActionView:
+		return TimelineViews
+	default:
+		return TimelineEvent
+	}
+}
+
+// TimelineGroup is one line of a session timeline: a burst of edits, a run of views or a single
+// event
+type TimelineGroup struct {
+	// ID is derived from the first event of the group, so it stays the same as later events join it
+	ID   string            `json:"id" example:"3b8e0f55-8c4a-5d7e-9f61-2a0c4b1d9e77"`
+	Kind TimelineGroupKind `json:"kind" example:"edits"`
+	// Type is the event type of single events and views; bursts of edits may mix types
+	Type   string `json:"type,omitempty" example:"comment_created"`
+	UserID string `json:"userId" example:"550e8400-e29b-41d4-a716-446655440003"`
+	// Summary describes the group for display, such as "12 edits on 3 slides"
+	Summary string `json:"summary" example:"12 edits on 3 slides"`
+	// Count is the number of events merged into the group, collapsed views counting as the
+	// views they stand for
+	Count int       `json:"count" example:"12"`
+	Start time.Time `json:"start" example:"2024-01-02T10:00:00Z"`
+	End   time.Time `json:"end" example:"2024-01-02T10:07:30Z"`
+	// SlideIDs lists the slides the events touched, sorted
+	SlideIDs     []string `json:"slideIds,omitempty" example:"slide-1,slide-3"`
+	FirstEventID string   `json:"firstEventId" example:"550e8400-e29b-41d4-a716-446655440010"`
+	LastEventID  string   `json:"lastEventId" example:"550e8400-e29b-41d4-a716-446655440021"`
+}
+
+// SessionTimeline is the compacted, human-readable history of a session, merged from its events
+type SessionTimeline struct {
+	SessionID string `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	// From and To repeat the requested range; omitted when open
+	From *time.Time `json:"from,omitempty" example:"2024-01-01T00:00:00Z"`
+	To   *time.Time `json:"to,omitempty" example:"2024-01-31T23:59:59Z"`
+	// GapSeconds is the longest pause within a group
+	GapSeconds int `json:"gapSeconds" example:"300"`
+	// Events counts the events merged into the groups
+	Events int `json:"events" example:"57"`
+	// Groups lists the groups by their first event, the oldest first
+	Groups []TimelineGroup `json:"groups"`
+	// Truncated is set when the session had more than MaxTimelineEvents events in the range
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// TimelineBuilder collects the events of a session into its timeline
+type TimelineBuilder struct {
+	timeline *SessionTimeline
+	gap      time.Duration
+	entries  []AuditEntry
+}
+
+// NewTimelineBuilder starts the timeline of a session over the query range
+func NewTimelineBuilder(sessionID string, query TimelineQuery) *TimelineBuilder {
+	gap := query.Gap
+	if gap == 0 {
+		gap = DefaultTimelineGap
+	}
+	timeline := &SessionTimeline{SessionID: sessionID, GapSeconds: int(gap.Seconds())}
+	if !query.From.IsZero() {
+		timeline.From = &query.From
+	}
+	if !query.To.IsZero() {
+		timeline.To = &query.To
+	}
+	return &TimelineBuilder{timeline: timeline, gap: gap}
+}
+
+// Add collects an event; events arrive newest first, so they are grouped once all are read
+func (b *TimelineBuilder) Add(entry AuditEntry) {
+	b.entries = append(b.entries, entry)
+}
+
+// Report merges the events added into the timeline. Consecutive edits of a user, and consecutive
+// views, form one group while each follows the previous within the gap; any other event of the
+// user ends the group, so the groups of a user read in order.
+func (b *TimelineBuilder) Report() *SessionTimeline {
+	sort.Slice(b.entries, func(i, j int) bool {
+		a, c := b.entries[i], b.entries[j]
+		if !a.Timestamp.Equal(c.Timestamp) {
+			return a.Timestamp.Before(c.Timestamp)
+		}
+		return a.ID < c.ID
+	})
+
+	var groups []*TimelineGroup
+	slides := make(map[*TimelineGroup]map[string]struct{})
+	open := make(map[string]*TimelineGroup)
+	for _, entry := range b.entries {
+		kind := timelineKind(AuditAction(entry.Type))
+		group := open[entry.UserID]
+		if kind == TimelineEvent || group == nil || group.Kind != kind || entry.Timestamp.Sub(group.End) > b.gap {
+			group = &TimelineGroup{
+				ID:           uuid.NewSHA1(timelineNamespace, []byte(entry.ID)).String(),
+				Kind:         kind,
+				Type:         entry.Type,
+				UserID:       entry.UserID,
+				Start:        entry.Timestamp,
+				FirstEventID: entry.ID,
+			}
+			groups = append(groups, group)
+			slides[group] = make(map[string]struct{})
+			if kind == TimelineEvent {
+				delete(open, entry.UserID)
+			} else {
+				open[entry.UserID] = group
+			}
+		}
+
+		repeats := ViewRepeatsOf(entry)
+		group.Count += repeats.Count
+		if repeats.LastTimestamp.After(group.End) {
+			group.End = repeats.LastTimestamp
+		}
+		group.LastEventID = entry.ID
+		if group.Type != entry.Type {
+			group.Type = ""
+		}
+		if entry.SlideID != "" {
+			slides[group][entry.SlideID] = struct{}{}
+		}
+	}
+
+	timeline := b.timeline
+	timeline.Events = len(b.entries)
+	timeline.Groups = make([]TimelineGroup, 0, len(groups))
+	for _, group := range groups {
+		for slideID := range slides[group] {
+			group.SlideIDs = append(group.SlideIDs, slideID)
+		}
+		sort.Strings(group.SlideIDs)
+		group.Summary = timelineSummary(*group)
+		timeline.Groups = append(timeline.Groups, *group)
+	}
+	return timeline
+}
+
+// timelineSummary describes a group for display, by the display name of its type for single
+// events
+func timelineSummary(group TimelineGroup) string {
+	switch {
+	case group.Kind == TimelineEdits && group.Count > 1:
+		summary := fmt.Sprintf("%d edits", group.Count)
+		switch len(group.SlideIDs) {
+		case 0:
+			return summary
+		case 1:
+			return summary + " on " + group.SlideIDs[0]
+		default:
+			return fmt.Sprintf("%s on %d slides", summary, len(group.SlideIDs))
+		}
+	case group.Kind == TimelineViews && group.Count > 1:
+		return fmt.Sprintf("Session viewed %d times", group.Count)
+	}
+
+	for _, eventType := range builtinEventTypes {
+		if string(eventType.Name) == group.Type {
+			return eventType.DisplayName
+		}
+	}
+	return group.Type
+}
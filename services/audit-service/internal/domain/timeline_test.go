@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimelineBuilder(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	event := func(id string, action AuditAction, userID string, minutes int, slideID string) AuditEntry {
+		return AuditEntry{ID: id, Type: string(action), UserID: userID, SlideID: slideID,
+			Timestamp: base.Add(time.Duration(minutes) * time.Minute)}
+	}
+	// Events are read newest first
+	entries := []AuditEntry{
+		event("e-11", ActionEdit, "user-1", 40, "slide-1"),
+		event("e-10", ActionView, "user-2", 12, ""),
+		event("e-09", ActionView, "user-2", 9, ""),
+		event("e-08", ActionView, "user-2", 6, ""),
+		event("e-07", ActionComment, "user-1", 8, "slide-2"),
+		event("e-06", ActionEdit, "user-1", 7, "slide-2"),
+		// Edits of another user interleave without breaking the burst
+		event("e-05", ActionEdit, "user-3", 5, "slide-4"),
+		event("e-04", ActionMerge, "user-1", 4, "slide-2"),
+		event("e-03", ActionEdit, "user-1", 2, "slide-1"),
+		event("e-02", ActionEdit, "user-1", 1, "slide-1"),
+		event("e-01", ActionCreate, "user-1", 0, ""),
+	}
+
+	builder := NewTimelineBuilder("session-1", TimelineQuery{From: base})
+	for _, entry := range entries {
+		builder.Add(entry)
+	}
+	timeline := builder.Report()
+
+	assert.Equal(t, "session-1", timeline.SessionID)
+	assert.Equal(t, &base, timeline.From)
+	assert.Equal(t, 300, timeline.GapSeconds)
+	assert.Equal(t, 11, timeline.Events)
+
+	require.Len(t, timeline.Groups, 6)
+	created, burst, other, views, comment, late := timeline.Groups[0], timeline.Groups[1], timeline.Groups[2],
+		timeline.Groups[3], timeline.Groups[4], timeline.Groups[5]
+
+	assert.Equal(t, TimelineEvent, created.Kind)
+	assert.Equal(t, "Session created", created.Summary)
+
+	assert.Equal(t, TimelineEdits, burst.Kind)
+	assert.Equal(t, 4, burst.Count)
+	assert.Empty(t, burst.Type, "the burst mixes edits and merges")
+	assert.Equal(t, []string{"slide-1", "slide-2"}, burst.SlideIDs)
+	assert.Equal(t, "4 edits on 2 slides", burst.Summary)
+	assert.Equal(t, base.Add(time.Minute), burst.Start)
+	assert.Equal(t, base.Add(7*time.Minute), burst.End)
+	assert.Equal(t, "e-02", burst.FirstEventID)
+	assert.Equal(t, "e-06", burst.LastEventID)
+
+	assert.Equal(t, "user-3", other.UserID)
+	assert.Equal(t, "Text edited", other.Summary)
+
+	assert.Equal(t, TimelineViews, views.Kind)
+	assert.Equal(t, 3, views.Count)
+	assert.Equal(t, "Session viewed 3 times", views.Summary)
+
+	assert.Equal(t, "Comment added", comment.Summary)
+
+	// Edits after the comment, and more than the gap after the burst, start a new group
+	assert.Equal(t, TimelineEdits, late.Kind)
+	assert.Equal(t, 1, late.Count)
+	assert.Equal(t, "e-11", late.FirstEventID)
+
+	// Group IDs only depend on the first event, so they survive events joining the group
+	builder = NewTimelineBuilder("session-1", TimelineQuery{})
+	for _, entry := range entries[6:] {
+		builder.Add(entry)
+	}
+	again := builder.Report()
+	assert.Equal(t, burst.ID, again.Groups[1].ID)
+	assert.Equal(t, 3, again.Groups[1].Count)
+	assert.NotEqual(t, burst.ID, created.ID)
+}
+
+func TestTimelineBuilder_Gap(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	builder := NewTimelineBuilder("session-1", TimelineQuery{Gap: time.Hour})
+	for i := 0; i < 3; i++ {
+		builder.Add(AuditEntry{ID: string(rune('a' + i)), Type: string(ActionView), UserID: "user-1",
+			Timestamp: base.Add(time.Duration(i*50) * time.Minute)})
+	}
+
+	timeline := builder.Report()
+	assert.Equal(t, 3600, timeline.GapSeconds)
+	require.Len(t, timeline.Groups, 1)
+	assert.Equal(t, 3, timeline.Groups[0].Count)
+	assert.Equal(t, []TimelineGroup{}, NewTimelineBuilder("session-1", TimelineQuery{}).Report().Groups)
+}
+
+func TestTimelineQuery_Validate(t *testing.T) {
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, TimelineQuery{To: from, Gap: time.Minute}.Validate())
+	assert.ErrorIs(t, TimelineQuery{From: from, To: from.Add(-time.Hour)}.Validate(), ErrInvalidFilter)
+	assert.ErrorIs(t, TimelineQuery{Gap: time.Millisecond}.Validate(), ErrInvalidFilter)
+	assert.ErrorIs(t, TimelineQuery{Gap: 48 * time.Hour}.Validate(), ErrInvalidFilter)
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTimestampSkew is returned for client timestamps outside the accepted skew window
+var ErrTimestampSkew = errors.New("timestamp outside the accepted clock skew")
+
+// SkewMode decides what happens to client timestamps outside the skew window
+type SkewMode string
+
+const (
+	// SkewReject rejects the event
+	SkewReject SkewMode = "reject"
+	// SkewClamp records the event with its timestamp moved to the nearest edge of the window
+	SkewClamp SkewMode = "clamp"
+)
+
+// TimestampPolicy bounds how far the timestamp a client sends with an event may be from the
+// time the service received it. The received time is authoritative; the client timestamp is
+// kept as the time the action happened.
+type TimestampPolicy struct {
+	// MaxFutureSkew is how far ahead of the received time a timestamp may be
+	MaxFutureSkew time.Duration
+	// MaxPastSkew is how far behind the received time a timestamp may be; 0 accepts any
+	// past timestamp, as clients may send events queued while offline
+	MaxPastSkew time.Duration
+	Mode        SkewMode
+}
+
+// DefaultTimestampPolicy rejects timestamps more than five minutes ahead of server time
+var DefaultTimestampPolicy = TimestampPolicy{MaxFutureSkew: 5 * time.Minute, Mode: SkewReject}
+
+// Apply returns the timestamp to record for a client timestamp received at receivedAt
+func (p TimestampPolicy) Apply(timestamp, receivedAt time.Time) (time.Time, error) {
+	latest := receivedAt.Add(p.MaxFutureSkew)
+	if timestamp.After(latest) {
+		if p.Mode == SkewClamp {
+			return latest, nil
+		}
+		return time.Time{}, fmt.Errorf("%w: %s ahead of server time, at most %s allowed",
+			ErrTimestampSkew, timestamp.Sub(receivedAt), p.MaxFutureSkew)
+	}
+
+	if p.MaxPastSkew > 0 {
+		earliest := receivedAt.Add(-p.MaxPastSkew)
+		if timestamp.Before(earliest) {
+			if p.Mode == SkewClamp {
+				return earliest, nil
+			}
+			return time.Time{}, fmt.Errorf("%w: %s behind server time, at most %s allowed",
+				ErrTimestampSkew, receivedAt.Sub(timestamp), p.MaxPastSkew)
+		}
+	}
+
+	return timestamp, nil
+}
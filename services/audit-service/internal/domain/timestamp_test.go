@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestampPolicy_Apply(t *testing.T) {
+	received := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	reject := TimestampPolicy{MaxFutureSkew: 5 * time.Minute, MaxPastSkew: time.Hour, Mode: SkewReject}
+	clamp := TimestampPolicy{MaxFutureSkew: 5 * time.Minute, MaxPastSkew: time.Hour, Mode: SkewClamp}
+
+	tests := []struct {
+		name      string
+		policy    TimestampPolicy
+		timestamp time.Time
+		expected  time.Time
+		expectErr bool
+	}{
+		{name: "within window", policy: reject, timestamp: received.Add(-time.Minute), expected: received.Add(-time.Minute)},
+		{name: "at future edge", policy: reject, timestamp: received.Add(5 * time.Minute), expected: received.Add(5 * time.Minute)},
+		{name: "at past edge", policy: reject, timestamp: received.Add(-time.Hour), expected: received.Add(-time.Hour)},
+		{name: "future rejected", policy: reject, timestamp: received.Add(6 * time.Minute), expectErr: true},
+		{name: "past rejected", policy: reject, timestamp: received.Add(-2 * time.Hour), expectErr: true},
+		{name: "future clamped", policy: clamp, timestamp: received.Add(time.Hour), expected: received.Add(5 * time.Minute)},
+		{name: "past clamped", policy: clamp, timestamp: received.Add(-48 * time.Hour), expected: received.Add(-time.Hour)},
+		{name: "no past limit", policy: DefaultTimestampPolicy, timestamp: received.Add(-365 * 24 * time.Hour), expected: received.Add(-365 * 24 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timestamp, err := tt.policy.Apply(tt.timestamp, received)
+
+			if tt.expectErr {
+				assert.ErrorIs(t, err, ErrTimestampSkew)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, timestamp)
+		})
+	}
+}
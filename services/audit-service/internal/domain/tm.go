@@ -0,0 +1,189 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// TMActions are the translation memory events counted in the TM leverage of a session
+var TMActions = []AuditAction{ActionTMHit, ActionTMOverride}
+
+// MaxTMLeverageEvents caps the translation memory events counted for one leverage report; the
+// report is marked truncated when a session has more
+const MaxTMLeverageEvents = 100000
+
+// TMLeverageQuery restricts a TM leverage report to a time range; zero bounds are open
+type TMLeverageQuery struct {
+	// From and To bound the counted events, both inclusive
+	From time.Time
+	To   time.Time
+}
+
+// Validate ensures the range is ordered
+func (q TMLeverageQuery) Validate() error {
+	if !q.From.IsZero() && !q.To.IsZero() && q.From.After(q.To) {
+		return fmt.Errorf("%w: from must not be after to", ErrInvalidFilter)
+	}
+	return nil
+}
+
+// TMDetails are the details of tm_hit and tm_override events read for the leverage report
+type TMDetails struct {
+	SegmentSource string   `json:"segmentSource"`
+	MatchScore    *float64 `json:"matchScore"`
+	// Accepted is set by tm_hit events only
+	Accepted  *bool `json:"accepted"`
+	WordCount int   `json:"wordCount"`
+}
+
+// TMBand counts the TM matches whose score falls in a match band
+type TMBand struct {
+	// Band names the scores of the band, e.g. 95-99
+	Band     string  `json:"band" example:"95-99"`
+	MinScore float64 `json:"minScore" example:"95"`
+	Hits     int     `json:"hits" example:"40"`
+	Accepted int     `json:"accepted" example:"36"`
+	Rejected int     `json:"rejected" example:"4"`
+	// Words counts the words of the segments, for hits that reported them
+	Words int `json:"words" example:"310"`
+}
+
+// tmBands are the match bands of a leverage report, best matches first. A score belongs to the
+// first band whose minimum it reaches.
+var tmBands = []struct {
+	name     string
+	minScore float64
+}{
+	{"101", 101},
+	{"100", 100},
+	{"95-99", 95},
+	{"85-94", 85},
+	{"75-84", 75},
+	{"50-74", 50},
+	{"0-49", 0},
+}
+
+// SessionTMLeverage reports how much of the translation of a session was taken from the
+// translation memory, from its tm_hit and tm_override events
+type SessionTMLeverage struct {
+	SessionID string `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	// From and To repeat the requested range; omitted when open
+	From *time.Time `json:"from,omitempty" example:"2024-01-01T00:00:00Z"`
+	To   *time.Time `json:"to,omitempty" example:"2024-01-31T23:59:59Z"`
+	// Segments counts the distinct segment sources with a TM match
+	Segments  int `json:"segments" example:"118"`
+	Hits      int `json:"hits" example:"130"`
+	Accepted  int `json:"accepted" example:"104"`
+	Rejected  int `json:"rejected" example:"26"`
+	Overrides int `json:"overrides" example:"9"`
+	// AcceptanceRate is the share of hits accepted, OverrideRate the share of accepted hits
+	// overridden later
+	AcceptanceRate float64 `json:"acceptanceRate" example:"0.8"`
+	OverrideRate   float64 `json:"overrideRate" example:"0.0865"`
+	// AverageMatchScore is the mean score of the accepted hits
+	AverageMatchScore float64 `json:"averageMatchScore" example:"93.5"`
+	// Words and LeveragedWords count the words of all hits and of the accepted ones, and
+	// WordLeverage is their ratio; only hits that reported a word count are included
+	Words          int      `json:"words" example:"1040"`
+	LeveragedWords int      `json:"leveragedWords" example:"862"`
+	WordLeverage   float64  `json:"wordLeverage" example:"0.8288"`
+	Bands          []TMBand `json:"bands"`
+	// Truncated is set when the session had more than MaxTMLeverageEvents events in the range
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// TMLeverageTally counts translation memory events into a leverage report
+type TMLeverageTally struct {
+	report   *SessionTMLeverage
+	segments map[string]struct{}
+	scoreSum float64
+}
+
+// NewTMLeverageTally starts the leverage report of a session over the query range
+func NewTMLeverageTally(sessionID string, query TMLeverageQuery) *TMLeverageTally {
+	report := &SessionTMLeverage{SessionID: sessionID, Bands: make([]TMBand, len(tmBands))}
+	if !query.From.IsZero() {
+		report.From = &query.From
+	}
+	if !query.To.IsZero() {
+		report.To = &query.To
+	}
+	for i, band := range tmBands {
+		report.Bands[i] = TMBand{Band: band.name, MinScore: band.minScore}
+	}
+	return &TMLeverageTally{report: report, segments: make(map[string]struct{})}
+}
+
+// Add counts an entry; entries of other types, and TM events without a segment source or match
+// score, are skipped
+func (t *TMLeverageTally) Add(entry AuditEntry) {
+	action := AuditAction(entry.Type)
+	if action != ActionTMHit && action != ActionTMOverride {
+		return
+	}
+	details, err := DecodeDetails[TMDetails](entry)
+	if err != nil || details.SegmentSource == "" || details.MatchScore == nil {
+		return
+	}
+
+	if action == ActionTMOverride {
+		t.report.Overrides++
+		return
+	}
+	if details.Accepted == nil {
+		return
+	}
+
+	t.segments[details.SegmentSource] = struct{}{}
+	band := t.band(*details.MatchScore)
+	t.report.Hits++
+	band.Hits++
+	t.report.Words += details.WordCount
+	band.Words += details.WordCount
+	if *details.Accepted {
+		t.report.Accepted++
+		band.Accepted++
+		t.report.LeveragedWords += details.WordCount
+		t.scoreSum += *details.MatchScore
+	} else {
+		t.report.Rejected++
+		band.Rejected++
+	}
+}
+
+// band returns the band of a match score
+func (t *TMLeverageTally) band(score float64) *TMBand {
+	for i := range t.report.Bands {
+		if score >= t.report.Bands[i].MinScore {
+			return &t.report.Bands[i]
+		}
+	}
+	return &t.report.Bands[len(t.report.Bands)-1]
+}
+
+// Report returns the leverage report of the entries added
+func (t *TMLeverageTally) Report() *SessionTMLeverage {
+	report := t.report
+	report.Segments = len(t.segments)
+	report.AcceptanceRate = ratio(report.Accepted, report.Hits)
+	report.OverrideRate = ratio(report.Overrides, report.Accepted)
+	report.WordLeverage = ratio(report.LeveragedWords, report.Words)
+	if report.Accepted > 0 {
+		report.AverageMatchScore = roundRatio(t.scoreSum / float64(report.Accepted))
+	}
+	return report
+}
+
+// ratio divides two counts rounded to four decimals, 0 when the divisor is 0
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return roundRatio(float64(n) / float64(d))
+}
+
+// roundRatio rounds to four decimals
+func roundRatio(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTMLeverageTally(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tally := NewTMLeverageTally("session-1", TMLeverageQuery{From: from})
+
+	for _, entry := range []AuditEntry{
+		{Type: string(ActionTMHit), Details: []byte(`{"segmentSource":"Hello","matchScore":100,"accepted":true,"wordCount":1}`)},
+		{Type: string(ActionTMHit), Details: []byte(`{"segmentSource":"Hello","matchScore":101,"accepted":true,"wordCount":1}`)},
+		{Type: string(ActionTMHit), Details: []byte(`{"segmentSource":"Quarterly results","matchScore":96.5,"accepted":true,"wordCount":2}`)},
+		{Type: string(ActionTMHit), Details: []byte(`{"segmentSource":"Next steps","matchScore":72,"accepted":false,"wordCount":2}`)},
+		{Type: string(ActionTMOverride), Details: []byte(`{"segmentSource":"Quarterly results","matchScore":96.5}`)},
+		// Events without the fields counted, and other types, are skipped
+		{Type: string(ActionTMHit), Details: []byte(`{"segmentSource":"Agenda"}`)},
+		{Type: string(ActionTMHit), Details: []byte(`not json`)},
+		{Type: string(ActionEdit), Details: []byte(`{"segmentSource":"Agenda","matchScore":100,"accepted":true}`)},
+	} {
+		tally.Add(entry)
+	}
+
+	report := tally.Report()
+	assert.Equal(t, "session-1", report.SessionID)
+	assert.Equal(t, &from, report.From)
+	assert.Nil(t, report.To)
+	assert.Equal(t, 3, report.Segments)
+	assert.Equal(t, 4, report.Hits)
+	assert.Equal(t, 3, report.Accepted)
+	assert.Equal(t, 1, report.Rejected)
+	assert.Equal(t, 1, report.Overrides)
+	assert.Equal(t, 0.75, report.AcceptanceRate)
+	assert.Equal(t, 0.3333, report.OverrideRate)
+	assert.Equal(t, 99.1667, report.AverageMatchScore)
+	assert.Equal(t, 6, report.Words)
+	assert.Equal(t, 4, report.LeveragedWords)
+	assert.Equal(t, 0.6667, report.WordLeverage)
+
+	bands := make(map[string]TMBand, len(report.Bands))
+	for _, band := range report.Bands {
+		bands[band.Band] = band
+	}
+	require.Len(t, report.Bands, 7)
+	assert.Equal(t, "101", report.Bands[0].Band)
+	assert.Equal(t, TMBand{Band: "100", MinScore: 100, Hits: 1, Accepted: 1, Words: 1}, bands["100"])
+	assert.Equal(t, TMBand{Band: "95-99", MinScore: 95, Hits: 1, Accepted: 1, Words: 2}, bands["95-99"])
+	assert.Equal(t, TMBand{Band: "50-74", MinScore: 50, Hits: 1, Rejected: 1, Words: 2}, bands["50-74"])
+	assert.Zero(t, bands["0-49"].Hits)
+}
+
+func TestTMLeverageTally_Empty(t *testing.T) {
+	report := NewTMLeverageTally("session-1", TMLeverageQuery{}).Report()
+
+	assert.Zero(t, report.Hits)
+	assert.Zero(t, report.AcceptanceRate)
+	assert.Zero(t, report.AverageMatchScore)
+	assert.Len(t, report.Bands, 7)
+	assert.Nil(t, report.From)
+}
+
+func TestTMLeverageQuery_Validate(t *testing.T) {
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, TMLeverageQuery{From: from, To: from.Add(time.Hour)}.Validate())
+	assert.ErrorIs(t, TMLeverageQuery{From: from, To: from.Add(-time.Hour)}.Validate(), ErrInvalidFilter)
+}
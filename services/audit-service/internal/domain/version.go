@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrVersionConflict is returned for updates of a versioned record made against a version
+	// that is no longer current: another user changed the record since it was read
+	ErrVersionConflict = errors.New("version conflict")
+	// ErrPreconditionRequired is returned for updates of a versioned record that do not name the
+	// version they replace
+	ErrPreconditionRequired = errors.New("precondition required")
+)
+
+// Versioned records, such as the annotations reviewers keep on audit events, carry a version
+// that counts their changes, 0 before the first one. An update names the version it was made
+// against and is only applied while that version is current, so that of two reviewers changing
+// a record at once the second is told to reload it instead of silently overwriting the first.
+
+// CheckVersion returns ErrVersionConflict unless expected is the current version of a record
+func CheckVersion(current, expected int64) error {
+	if current != expected {
+		return fmt.Errorf("%w: version %d is current, not %d", ErrVersionConflict, current, expected)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckVersion(t *testing.T) {
+	assert.NoError(t, CheckVersion(3, 3))
+
+	err := CheckVersion(4, 3)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	assert.EqualError(t, err, "version conflict: version 4 is current, not 3")
+}
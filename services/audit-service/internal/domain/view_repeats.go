@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ViewRepeats records the identical views collapsed into a view event on ingestion. It is stored
+// in the details of the view under "repeats"; views that were not collapsed have none.
+type ViewRepeats struct {
+	// Count is the number of views the event stands for, the first included
+	Count int `json:"count"`
+	// LastTimestamp is the time of the last of the views
+	LastTimestamp time.Time `json:"lastTimestamp"`
+}
+
+// WithViewRepeats returns the details of a view with repeats stored under "repeats", replacing
+// any sent by the client. Details that are not an object are returned unchanged.
+func WithViewRepeats(details json.RawMessage, repeats ViewRepeats) json.RawMessage {
+	var fields map[string]json.RawMessage
+	if len(details) == 0 {
+		fields = make(map[string]json.RawMessage)
+	} else if json.Unmarshal(details, &fields) != nil || fields == nil {
+		return details
+	}
+
+	repeats.LastTimestamp = repeats.LastTimestamp.UTC()
+	encoded, err := json.Marshal(repeats)
+	if err != nil {
+		return details
+	}
+	fields["repeats"] = encoded
+
+	encoded, err = json.Marshal(fields)
+	if err != nil {
+		return details
+	}
+	return encoded
+}
+
+// ViewRepeatsOf returns the views a view event stands for and the time of the last of them: the
+// recorded repeats of a collapsed view, or the view itself
+func ViewRepeatsOf(entry AuditEntry) ViewRepeats {
+	single := ViewRepeats{Count: 1, LastTimestamp: entry.Timestamp}
+	if AuditAction(entry.Type) != ActionView {
+		return single
+	}
+	details, err := DecodeDetails[ViewDetails](entry)
+	if err != nil || details.Repeats == nil || details.Repeats.Count < 1 {
+		return single
+	}
+	repeats := *details.Repeats
+	if repeats.LastTimestamp.Before(entry.Timestamp) {
+		repeats.LastTimestamp = entry.Timestamp
+	}
+	return repeats
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithViewRepeats(t *testing.T) {
+	last := time.Date(2024, 1, 15, 10, 4, 0, 0, time.FixedZone("CET", 3600))
+	repeats := ViewRepeats{Count: 4, LastTimestamp: last}
+
+	details := WithViewRepeats(json.RawMessage(`{"accessMethod":"jwt","repeats":"forged"}`), repeats)
+	assert.JSONEq(t, `{"accessMethod":"jwt","repeats":{"count":4,"lastTimestamp":"2024-01-15T09:04:00Z"}}`, string(details))
+
+	assert.JSONEq(t, `{"repeats":{"count":4,"lastTimestamp":"2024-01-15T09:04:00Z"}}`, string(WithViewRepeats(nil, repeats)))
+	assert.Equal(t, `"view"`, string(WithViewRepeats(json.RawMessage(`"view"`), repeats)))
+}
+
+func TestViewRepeatsOf(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	collapsed := AuditEntry{Type: string(ActionView), Timestamp: base,
+		Details: WithViewRepeats(json.RawMessage(`{"accessMethod":"jwt"}`), ViewRepeats{Count: 3, LastTimestamp: base.Add(time.Minute)})}
+
+	assert.Equal(t, ViewRepeats{Count: 3, LastTimestamp: base.Add(time.Minute)}, ViewRepeatsOf(collapsed))
+	for name, entry := range map[string]AuditEntry{
+		"single view":     {Type: string(ActionView), Timestamp: base, Details: json.RawMessage(`{"accessMethod":"jwt"}`)},
+		"invalid repeats": {Type: string(ActionView), Timestamp: base, Details: json.RawMessage(`{"repeats":{"count":0}}`)},
+		"other type":      {Type: string(ActionEdit), Timestamp: base, Details: collapsed.Details},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, ViewRepeats{Count: 1, LastTimestamp: base}, ViewRepeatsOf(entry))
+		})
+	}
+}
+
+func TestCollapsedViewsCountAsTheirRepeats(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	entries := []AuditEntry{
+		{ID: "e-2", Type: string(ActionView), UserID: "user-1", Timestamp: base.Add(30 * time.Minute),
+			Details: json.RawMessage(`{"accessMethod":"jwt"}`)},
+		{ID: "e-1", Type: string(ActionView), UserID: "user-1", Timestamp: base,
+			Details: WithViewRepeats(json.RawMessage(`{"accessMethod":"jwt"}`), ViewRepeats{Count: 5, LastTimestamp: base.Add(28 * time.Minute)})},
+	}
+
+	tally := NewAccessReviewTally("session-1", AccessReviewQuery{})
+	builder := NewTimelineBuilder("session-1", TimelineQuery{})
+	for _, entry := range entries {
+		tally.Add(entry)
+		builder.Add(entry)
+	}
+
+	review := tally.Report(base.Add(time.Hour))
+	assert.Equal(t, 6, review.TotalViews)
+	require.Len(t, review.Accessors, 1)
+	assert.Equal(t, 6, review.Accessors[0].Views)
+
+	// The collapsed views end 2 minutes before the next view, so they form one run
+	timeline := builder.Report()
+	assert.Equal(t, 2, timeline.Events)
+	require.Len(t, timeline.Groups, 1)
+	assert.Equal(t, 6, timeline.Groups[0].Count)
+	assert.Equal(t, base.Add(30*time.Minute), timeline.Groups[0].End)
+	assert.Equal(t, "Session viewed 6 times", timeline.Groups[0].Summary)
+}
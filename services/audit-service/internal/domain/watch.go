@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// WatchableEventTypes are the event types whose events can be forwarded to the watchers of a session
+var WatchableEventTypes = []AuditAction{
+	ActionShare, ActionUnshare, ActionExport,
+	ActionComment, ActionCommentCreated, ActionCommentEdited, ActionCommentResolved, ActionCommentDeleted,
+}
+
+// DefaultWatchedEventTypes are forwarded to watchers who do not choose their own
+var DefaultWatchedEventTypes = []AuditAction{ActionShare, ActionExport, ActionComment, ActionCommentCreated}
+
+var (
+	// ErrInvalidWatch is returned for watches of event types that cannot be watched
+	ErrInvalidWatch = errors.New("invalid watch")
+	// ErrWatchNotFound is returned when a user does not watch the session
+	ErrWatchNotFound = errors.New("watch not found")
+)
+
+// Watch subscribes a user to the share, export and comment events of a session. Matching events
+// of other users are forwarded to the notification sink in periodic digests.
+type Watch struct {
+	SessionID  string    `json:"sessionId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID     string    `json:"userId" example:"550e8400-e29b-41d4-a716-446655440003"`
+	EventTypes []string  `json:"eventTypes" example:"share,export,comment,comment_created"`
+	CreatedAt  time.Time `json:"createdAt" example:"2024-01-01T10:00:00Z"`
+	// OrganizationID is the organization of the watcher; empty for the default organization
+	OrganizationID string `json:"organizationId,omitempty" example:"acme"`
+}
+
+// Validate checks that every event type of the watch can be watched
+func (w Watch) Validate() error {
+	if len(w.EventTypes) == 0 {
+		return fmt.Errorf("%w: at least one event type is required", ErrInvalidWatch)
+	}
+	for _, eventType := range w.EventTypes {
+		if !slices.Contains(WatchableEventTypes, AuditAction(eventType)) {
+			return fmt.Errorf("%w: %q cannot be watched", ErrInvalidWatch, eventType)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether an entry is forwarded to the watcher; users are not notified of
+// their own events
+func (w Watch) Matches(entry AuditEntry) bool {
+	return entry.SessionID == w.SessionID && entry.UserID != w.UserID && slices.Contains(w.EventTypes, entry.Type)
+}
+
+// WatchDigest is the notification sent to a watcher with the events collected since the last one
+type WatchDigest struct {
+	ID     string `json:"id"`
+	UserID string `json:"userId"`
+	// From and To are the timestamps of the first and last event
+	From   time.Time    `json:"from"`
+	To     time.Time    `json:"to"`
+	Events []AuditEntry `json:"events"`
+}
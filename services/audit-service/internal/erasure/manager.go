@@ -0,0 +1,307 @@
+package erasure
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/tenant"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// batchSize is the number of entries erased per database call
+	batchSize = 500
+
+	// jobRetention is how long finished jobs can still be looked up
+	jobRetention = 24 * time.Hour
+)
+
+// Repository erases a user's audit entries and activity rollups
+type Repository interface {
+	EraseUserEvents(ctx context.Context, userID domain.UserID, mode domain.ErasureMode, overrideHolds bool, limit int) ([]domain.PurgedEvents, error)
+	DeleteUserActivity(ctx context.Context, userID domain.UserID) error
+}
+
+// RecordFunc persists the erasure events written to affected sessions
+type RecordFunc func(ctx context.Context, entries []domain.AuditEntry) error
+
+// Summary is the details payload of the erasure event recorded in each affected session
+type Summary struct {
+	JobID          string             `json:"jobId"`
+	Mode           domain.ErasureMode `json:"mode"`
+	AffectedEvents int                `json:"affectedEvents"`
+	// HoldOverride is set when entries under a legal hold were erased too
+	HoldOverride *domain.HoldOverride `json:"holdOverride,omitempty"`
+}
+
+// sessionKey identifies a session within its organization
+type sessionKey struct {
+	sessionID      string
+	organizationID string
+}
+
+// Manager runs erasure jobs in the background and keeps their status in memory
+type Manager struct {
+	repo   Repository
+	record RecordFunc
+	clock  clock.Clock
+	logger *zap.Logger
+
+	mu     sync.Mutex
+	jobs   map[string]*domain.ErasureJob
+	closed bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager creates an erasure job manager
+func NewManager(repo Repository, record RecordFunc, clk clock.Clock, logger *zap.Logger) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		repo:   repo,
+		record: record,
+		clock:  clk,
+		logger: logger,
+		jobs:   map[string]*domain.ErasureJob{},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start queues the erasure of a user's entries and returns the job. Only entries of the
+// organization ctx is scoped to are erased, and entries under a legal hold are kept unless
+// override is given. A job still running for the same user, organization, mode and override
+// is returned instead of starting another.
+func (m *Manager) Start(ctx context.Context, userID domain.UserID, mode domain.ErasureMode, requestedBy string, override *domain.HoldOverride) (domain.ErasureJob, error) {
+	organizationID, scoped := tenant.FromContext(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return domain.ErasureJob{}, domain.ErrServiceUnavailable
+	}
+
+	m.pruneLocked()
+	for _, job := range m.jobs {
+		if job.UserID == userID && job.OrganizationID == organizationID && job.Mode == mode &&
+			(job.HoldOverride != nil) == (override != nil) &&
+			(job.Status == domain.ErasurePending || job.Status == domain.ErasureRunning) {
+			return *job, nil
+		}
+	}
+
+	job := &domain.ErasureJob{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		Mode:        mode,
+		Status:      domain.ErasurePending,
+		RequestedBy: requestedBy,
+		CreatedAt:   m.clock.Now(),
+
+		OrganizationID: organizationID,
+	}
+	if override != nil {
+		job.HoldOverride = &domain.HoldOverride{Reason: override.Reason}
+		m.logger.Warn("erasure job overrides legal holds",
+			zap.String("job_id", job.ID),
+			zap.String("mode", string(mode)),
+			zap.String("requested_by", requestedBy),
+			zap.String("reason", override.Reason),
+		)
+	}
+	m.jobs[job.ID] = job
+
+	m.wg.Add(1)
+	go m.run(job.ID, scoped)
+
+	return *job, nil
+}
+
+// Get returns the current state of a job; jobs of other organizations are reported as not found
+func (m *Manager) Get(ctx context.Context, id string) (domain.ErasureJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok || !tenant.Allows(ctx, job.OrganizationID) {
+		return domain.ErasureJob{}, domain.ErrErasureJobNotFound
+	}
+	return *job, nil
+}
+
+// Close cancels running jobs and waits for them to stop
+func (m *Manager) Close(ctx context.Context) error {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("erasure jobs did not stop: %w", ctx.Err())
+	}
+}
+
+// run erases the user's entries in batches and records an erasure event per affected session.
+// Scoped jobs only erase entries of their organization.
+func (m *Manager) run(id string, scoped bool) {
+	defer m.wg.Done()
+
+	job := m.update(id, func(job *domain.ErasureJob) { job.Status = domain.ErasureRunning })
+
+	ctx := m.ctx
+	if scoped {
+		ctx = tenant.NewContext(ctx, job.OrganizationID)
+	}
+
+	perSession := map[sessionKey]int{}
+	err := m.erase(ctx, job, perSession)
+
+	if len(perSession) > 0 {
+		if recordErr := m.recordErasure(job, perSession); recordErr != nil && err == nil {
+			err = recordErr
+		}
+	}
+
+	now := m.clock.Now()
+	job = m.update(id, func(job *domain.ErasureJob) {
+		job.CompletedAt = &now
+		job.Status = domain.ErasureCompleted
+		if err != nil {
+			job.Status = domain.ErasureFailed
+			job.Error = err.Error()
+		}
+	})
+
+	if err != nil {
+		m.logger.Error("erasure job failed",
+			zap.String("job_id", id),
+			zap.String("mode", string(job.Mode)),
+			zap.Int("affected_events", job.AffectedEvents),
+			zap.Error(err),
+		)
+		return
+	}
+
+	m.logger.Info("erasure job completed",
+		zap.String("job_id", id),
+		zap.String("mode", string(job.Mode)),
+		zap.String("requested_by", job.RequestedBy),
+		zap.Int("affected_events", job.AffectedEvents),
+		zap.Int("affected_sessions", job.AffectedSessions),
+	)
+}
+
+// erase repeats batches until the user has no entries left, then drops the user's activity
+// rollups; rollups rebuilt later no longer count the erased entries
+func (m *Manager) erase(ctx context.Context, job domain.ErasureJob, perSession map[sessionKey]int) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		affected, err := m.repo.EraseUserEvents(ctx, job.UserID, job.Mode, job.HoldOverride != nil, batchSize)
+		if err != nil {
+			return err
+		}
+
+		count := 0
+		for _, item := range affected {
+			perSession[sessionKey{item.SessionID, item.OrganizationID}] += item.Deleted
+			count += item.Deleted
+		}
+		m.update(job.ID, func(job *domain.ErasureJob) {
+			job.AffectedEvents += count
+			job.AffectedSessions = len(perSession)
+		})
+
+		// A short batch means nothing of the user is left
+		if count < batchSize {
+			if err := m.repo.DeleteUserActivity(ctx, job.UserID); err != nil {
+				return fmt.Errorf("failed to delete activity rollups: %w", err)
+			}
+			return nil
+		}
+	}
+}
+
+// recordErasure writes an erasure event to every affected session; the erased user is not named
+func (m *Manager) recordErasure(job domain.ErasureJob, perSession map[sessionKey]int) error {
+	sessions := make([]sessionKey, 0, len(perSession))
+	for key := range perSession {
+		sessions = append(sessions, key)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].sessionID != sessions[j].sessionID {
+			return sessions[i].sessionID < sessions[j].sessionID
+		}
+		return sessions[i].organizationID < sessions[j].organizationID
+	})
+
+	now := m.clock.Now()
+	entries := make([]domain.AuditEntry, len(sessions))
+	for i, key := range sessions {
+		details, _ := domain.EncodeDetails(Summary{
+			JobID:          job.ID,
+			Mode:           job.Mode,
+			AffectedEvents: perSession[key],
+			HoldOverride:   job.HoldOverride,
+		})
+		entries[i] = domain.AuditEntry{
+			ID:        uuid.New().String(),
+			SessionID: key.sessionID,
+			UserID:    job.RequestedBy,
+			Type:      string(domain.ActionUserErasure),
+			Timestamp: now,
+			Details:   details,
+			// Recorded in the organization of the erased entries
+			OrganizationID: key.organizationID,
+		}
+	}
+
+	// Recorded even when the job was cancelled, since entries were already erased
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(m.ctx), 30*time.Second)
+	defer cancel()
+	if err := m.record(ctx, entries); err != nil {
+		return fmt.Errorf("failed to record erasure events: %w", err)
+	}
+	return nil
+}
+
+// update applies a change to a job under the lock and returns a copy
+func (m *Manager) update(id string, apply func(job *domain.ErasureJob)) domain.ErasureJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job := m.jobs[id]
+	apply(job)
+	return *job
+}
+
+// pruneLocked forgets jobs that finished more than jobRetention ago
+func (m *Manager) pruneLocked() {
+	cutoff := m.clock.Now().Add(-jobRetention)
+	for id, job := range m.jobs {
+		if job.CompletedAt != nil && job.CompletedAt.Before(cutoff) {
+			delete(m.jobs, id)
+		}
+	}
+}
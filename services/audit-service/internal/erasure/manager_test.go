@@ -0,0 +1,254 @@
+package erasure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/mocks"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// testUser is the user whose entries are erased
+const testUser domain.UserID = "user-1"
+
+// recorder collects the erasure events written by jobs
+type recorder struct {
+	mu      sync.Mutex
+	entries []domain.AuditEntry
+}
+
+func (r *recorder) record(ctx context.Context, entries []domain.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entries...)
+	return nil
+}
+
+func (r *recorder) recorded() []domain.AuditEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]domain.AuditEntry(nil), r.entries...)
+}
+
+// waitFor polls the job until it has finished
+func waitFor(t *testing.T, m *Manager, id string) domain.ErasureJob {
+	var job domain.ErasureJob
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = m.Get(context.Background(), id)
+		require.NoError(t, err)
+		return job.Status == domain.ErasureCompleted || job.Status == domain.ErasureFailed
+	}, time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestManager_AnonymizesInBatches(t *testing.T) {
+	repo := mocks.NewMockAuditRepository(t)
+	rec := &recorder{}
+	m := NewManager(repo, rec.record, clock.NewFakeClock(testNow), zap.NewNop())
+
+	repo.On("EraseUserEvents", mock.Anything, testUser, domain.ErasureAnonymize, false, batchSize).
+		Return([]domain.PurgedEvents{{SessionID: "session-b", Deleted: batchSize - 3}, {SessionID: "session-a", Deleted: 3}}, nil).Once()
+	repo.On("EraseUserEvents", mock.Anything, testUser, domain.ErasureAnonymize, false, batchSize).
+		Return([]domain.PurgedEvents{{SessionID: "session-a", Deleted: 2}}, nil).Once()
+	repo.On("DeleteUserActivity", mock.Anything, testUser).Return(nil).Once()
+
+	job, err := m.Start(context.Background(), testUser, domain.ErasureAnonymize, "admin-1", nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, job.ID)
+	assert.Equal(t, testNow, job.CreatedAt)
+
+	job = waitFor(t, m, job.ID)
+
+	assert.Equal(t, domain.ErasureCompleted, job.Status)
+	assert.Equal(t, batchSize+2, job.AffectedEvents)
+	assert.Equal(t, 2, job.AffectedSessions)
+	assert.Equal(t, testNow, *job.CompletedAt)
+
+	entries := rec.recorded()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "session-a", entries[0].SessionID)
+	assert.Equal(t, "admin-1", entries[0].UserID)
+	assert.Equal(t, string(domain.ActionUserErasure), entries[0].Type)
+
+	var summary Summary
+	require.NoError(t, json.Unmarshal(entries[0].Details, &summary))
+	assert.Equal(t, Summary{JobID: job.ID, Mode: domain.ErasureAnonymize, AffectedEvents: 5}, summary)
+	assert.NotContains(t, string(entries[0].Details), testUser.String())
+}
+
+func TestManager_ReportsFailure(t *testing.T) {
+	repo := mocks.NewMockAuditRepository(t)
+	rec := &recorder{}
+	m := NewManager(repo, rec.record, clock.NewFakeClock(testNow), zap.NewNop())
+
+	repo.On("EraseUserEvents", mock.Anything, testUser, domain.ErasureDelete, false, batchSize).
+		Return([]domain.PurgedEvents{{SessionID: "session-a", Deleted: batchSize}}, nil).Once()
+	repo.On("EraseUserEvents", mock.Anything, testUser, domain.ErasureDelete, false, batchSize).
+		Return(nil, errors.New("network error")).Once()
+
+	job, err := m.Start(context.Background(), testUser, domain.ErasureDelete, "admin-1", nil)
+	require.NoError(t, err)
+	job = waitFor(t, m, job.ID)
+
+	assert.Equal(t, domain.ErasureFailed, job.Status)
+	assert.Equal(t, "network error", job.Error)
+	assert.Equal(t, batchSize, job.AffectedEvents)
+	// Sessions erased before the failure still get their erasure event
+	assert.Len(t, rec.recorded(), 1)
+}
+
+func TestManager_OverridesLegalHolds(t *testing.T) {
+	repo := mocks.NewMockAuditRepository(t)
+	rec := &recorder{}
+	m := NewManager(repo, rec.record, clock.NewFakeClock(testNow), zap.NewNop())
+
+	release := make(chan struct{})
+	repo.On("EraseUserEvents", mock.Anything, testUser, domain.ErasureDelete, false, batchSize).
+		Run(func(mock.Arguments) { <-release }).
+		Return(nil, nil).Once()
+	repo.On("EraseUserEvents", mock.Anything, testUser, domain.ErasureDelete, true, batchSize).
+		Return([]domain.PurgedEvents{{SessionID: "session-a", Deleted: 2}}, nil).Once()
+	repo.On("DeleteUserActivity", mock.Anything, testUser).Return(nil).Twice()
+
+	// A running job that respects holds is not reused for an override
+	plain, err := m.Start(context.Background(), testUser, domain.ErasureDelete, "admin-1", nil)
+	require.NoError(t, err)
+	override := &domain.HoldOverride{Reason: "Court order 2024-031"}
+	job, err := m.Start(context.Background(), testUser, domain.ErasureDelete, "admin-2", override)
+	require.NoError(t, err)
+	assert.NotEqual(t, plain.ID, job.ID)
+	assert.Equal(t, override, job.HoldOverride)
+
+	job = waitFor(t, m, job.ID)
+	close(release)
+	waitFor(t, m, plain.ID)
+
+	assert.Equal(t, domain.ErasureCompleted, job.Status)
+	entries := rec.recorded()
+	require.Len(t, entries, 1)
+	var summary Summary
+	require.NoError(t, json.Unmarshal(entries[0].Details, &summary))
+	assert.Equal(t, Summary{JobID: job.ID, Mode: domain.ErasureDelete, AffectedEvents: 2, HoldOverride: override}, summary)
+}
+
+func TestManager_ReusesRunningJob(t *testing.T) {
+	repo := mocks.NewMockAuditRepository(t)
+	rec := &recorder{}
+	m := NewManager(repo, rec.record, clock.NewFakeClock(testNow), zap.NewNop())
+
+	release := make(chan struct{})
+	repo.On("EraseUserEvents", mock.Anything, testUser, domain.ErasureDelete, false, batchSize).
+		Run(func(mock.Arguments) { <-release }).
+		Return(nil, nil).Once()
+	repo.On("DeleteUserActivity", mock.Anything, testUser).Return(nil).Once()
+
+	first, err := m.Start(context.Background(), testUser, domain.ErasureDelete, "admin-1", nil)
+	require.NoError(t, err)
+	second, err := m.Start(context.Background(), testUser, domain.ErasureDelete, "admin-2", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, first.ID, second.ID)
+
+	close(release)
+	waitFor(t, m, first.ID)
+	assert.Empty(t, rec.recorded())
+}
+
+func TestManager_ScopesJobsToOrganization(t *testing.T) {
+	repo := mocks.NewMockAuditRepository(t)
+	rec := &recorder{}
+	m := NewManager(repo, rec.record, clock.NewFakeClock(testNow), zap.NewNop())
+
+	repo.On("EraseUserEvents", mock.MatchedBy(func(ctx context.Context) bool {
+		org, scoped := tenant.FromContext(ctx)
+		return scoped && org == "acme"
+	}), testUser, domain.ErasureDelete, false, batchSize).
+		Return([]domain.PurgedEvents{{SessionID: "session-a", OrganizationID: "acme", Deleted: 2}}, nil).Once()
+	repo.On("DeleteUserActivity", mock.MatchedBy(func(ctx context.Context) bool {
+		org, scoped := tenant.FromContext(ctx)
+		return scoped && org == "acme"
+	}), testUser).Return(nil).Once()
+
+	job, err := m.Start(tenant.NewContext(context.Background(), "acme"), testUser, domain.ErasureDelete, "admin-1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", job.OrganizationID)
+
+	job = waitFor(t, m, job.ID)
+	assert.Equal(t, domain.ErasureCompleted, job.Status)
+
+	// Admins of other organizations cannot see the job
+	_, err = m.Get(tenant.NewContext(context.Background(), "other"), job.ID)
+	assert.ErrorIs(t, err, domain.ErrErasureJobNotFound)
+
+	recorded := rec.recorded()
+	require.Len(t, recorded, 1)
+	assert.Equal(t, "acme", recorded[0].OrganizationID)
+}
+
+func TestManager_GetUnknownJob(t *testing.T) {
+	m := NewManager(mocks.NewMockAuditRepository(t), (&recorder{}).record, clock.NewFakeClock(testNow), zap.NewNop())
+
+	_, err := m.Get(context.Background(), "missing")
+
+	assert.ErrorIs(t, err, domain.ErrErasureJobNotFound)
+}
+
+func TestManager_CloseCancelsJobs(t *testing.T) {
+	repo := mocks.NewMockAuditRepository(t)
+	m := NewManager(repo, (&recorder{}).record, clock.NewFakeClock(testNow), zap.NewNop())
+
+	started := make(chan struct{})
+	repo.On("EraseUserEvents", mock.Anything, testUser, domain.ErasureDelete, false, batchSize).
+		Run(func(args mock.Arguments) {
+			close(started)
+			<-args.Get(0).(context.Context).Done()
+		}).
+		Return(nil, context.Canceled).Once()
+
+	job, err := m.Start(context.Background(), testUser, domain.ErasureDelete, "admin-1", nil)
+	require.NoError(t, err)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, m.Close(ctx))
+
+	job, err = m.Get(context.Background(), job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ErasureFailed, job.Status)
+
+	_, err = m.Start(context.Background(), "user-2", domain.ErasureDelete, "admin-1", nil)
+	assert.ErrorIs(t, err, domain.ErrServiceUnavailable)
+}
+
+func TestManager_FailsWhenRollupsRemain(t *testing.T) {
+	repo := mocks.NewMockAuditRepository(t)
+	rec := &recorder{}
+	m := NewManager(repo, rec.record, clock.NewFakeClock(testNow), zap.NewNop())
+
+	repo.On("EraseUserEvents", mock.Anything, testUser, domain.ErasureDelete, false, batchSize).
+		Return([]domain.PurgedEvents{{SessionID: "session-a", Deleted: 2}}, nil).Once()
+	repo.On("DeleteUserActivity", mock.Anything, testUser).Return(errors.New("network error")).Once()
+
+	job, err := m.Start(context.Background(), testUser, domain.ErasureDelete, "admin-1", nil)
+	require.NoError(t, err)
+	job = waitFor(t, m, job.ID)
+
+	assert.Equal(t, domain.ErasureFailed, job.Status)
+	assert.Contains(t, job.Error, "activity rollups")
+	assert.Len(t, rec.recorded(), 1)
+}
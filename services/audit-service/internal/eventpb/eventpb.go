@@ -0,0 +1,284 @@
+package eventpb
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Media types of protobuf payloads; the x- form is what older clients and gin send
+const (
+	ContentType       = "application/protobuf"
+	LegacyContentType = "application/x-protobuf"
+)
+
+// ErrInvalidUTF8 is returned when a string field does not hold valid UTF-8, as proto3 requires
+var ErrInvalidUTF8 = errors.New("string field contains invalid UTF-8")
+
+// Event is the protobuf form of an event creation request
+type Event struct {
+	ID        string
+	SessionID string
+	Type      string
+	// Details holds the JSON-encoded event details
+	Details   []byte
+	Timestamp string
+	UserID    string
+	// CorrelationID and ParentEventID link the event to the operation and event that caused it
+	CorrelationID string
+	ParentEventID string
+	// SchemaVersion is the version of the details schema; zero means version 1
+	SchemaVersion int32
+	// OrganizationID is the organization a service acted for
+	OrganizationID string
+}
+
+// EventBatch is the protobuf form of a batch creation request
+type EventBatch struct {
+	Events []Event
+}
+
+// EventResult is the protobuf form of a created event
+type EventResult struct {
+	ID        string
+	SessionID string
+	UserID    string
+	Type      string
+	Timestamp string
+	Success   bool
+	// ConsistencyToken is set on results of events stored outside test sessions
+	ConsistencyToken string
+}
+
+// BatchResult is the protobuf form of a created batch
+type BatchResult struct {
+	Count            int64
+	Events           []EventResult
+	ConsistencyToken string
+}
+
+// Marshal encodes the event
+func (e *Event) Marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, e.ID)
+	b = appendString(b, 2, e.SessionID)
+	b = appendString(b, 3, e.Type)
+	b = appendBytes(b, 4, e.Details)
+	b = appendString(b, 5, e.Timestamp)
+	b = appendString(b, 6, e.UserID)
+	b = appendString(b, 7, e.CorrelationID)
+	b = appendString(b, 8, e.ParentEventID)
+	if e.SchemaVersion != 0 {
+		b = protowire.AppendTag(b, 9, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(e.SchemaVersion))
+	}
+	b = appendString(b, 10, e.OrganizationID)
+	return b
+}
+
+// Unmarshal decodes an event, skipping unknown fields
+func (e *Event) Unmarshal(b []byte) error {
+	*e = Event{}
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			return consumeString(b, &e.ID)
+		case num == 2 && typ == protowire.BytesType:
+			return consumeString(b, &e.SessionID)
+		case num == 3 && typ == protowire.BytesType:
+			return consumeString(b, &e.Type)
+		case num == 4 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			e.Details = append([]byte(nil), v...)
+			return n, nil
+		case num == 5 && typ == protowire.BytesType:
+			return consumeString(b, &e.Timestamp)
+		case num == 6 && typ == protowire.BytesType:
+			return consumeString(b, &e.UserID)
+		case num == 7 && typ == protowire.BytesType:
+			return consumeString(b, &e.CorrelationID)
+		case num == 8 && typ == protowire.BytesType:
+			return consumeString(b, &e.ParentEventID)
+		case num == 9 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			e.SchemaVersion = int32(v)
+			return n, nil
+		case num == 10 && typ == protowire.BytesType:
+			return consumeString(b, &e.OrganizationID)
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+}
+
+// Marshal encodes the batch
+func (eb *EventBatch) Marshal() []byte {
+	var b []byte
+	for i := range eb.Events {
+		b = appendMessage(b, 1, eb.Events[i].Marshal())
+	}
+	return b
+}
+
+// Unmarshal decodes a batch, skipping unknown fields
+func (eb *EventBatch) Unmarshal(b []byte) error {
+	*eb = EventBatch{}
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num == 1 && typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return n, nil
+			}
+			var event Event
+			if err := event.Unmarshal(v); err != nil {
+				return 0, fmt.Errorf("event %d: %w", len(eb.Events), err)
+			}
+			eb.Events = append(eb.Events, event)
+			return n, nil
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+}
+
+// Marshal encodes the result
+func (r *EventResult) Marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, r.ID)
+	b = appendString(b, 2, r.SessionID)
+	b = appendString(b, 3, r.UserID)
+	b = appendString(b, 4, r.Type)
+	b = appendString(b, 5, r.Timestamp)
+	if r.Success {
+		b = protowire.AppendTag(b, 6, protowire.VarintType)
+		b = protowire.AppendVarint(b, 1)
+	}
+	b = appendString(b, 7, r.ConsistencyToken)
+	return b
+}
+
+// Unmarshal decodes a result, skipping unknown fields
+func (r *EventResult) Unmarshal(b []byte) error {
+	*r = EventResult{}
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			return consumeString(b, &r.ID)
+		case num == 2 && typ == protowire.BytesType:
+			return consumeString(b, &r.SessionID)
+		case num == 3 && typ == protowire.BytesType:
+			return consumeString(b, &r.UserID)
+		case num == 4 && typ == protowire.BytesType:
+			return consumeString(b, &r.Type)
+		case num == 5 && typ == protowire.BytesType:
+			return consumeString(b, &r.Timestamp)
+		case num == 6 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			r.Success = v != 0
+			return n, nil
+		case num == 7 && typ == protowire.BytesType:
+			return consumeString(b, &r.ConsistencyToken)
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+}
+
+// Marshal encodes the batch result
+func (r *BatchResult) Marshal() []byte {
+	var b []byte
+	if r.Count != 0 {
+		b = protowire.AppendTag(b, 1, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(r.Count))
+	}
+	for i := range r.Events {
+		b = appendMessage(b, 2, r.Events[i].Marshal())
+	}
+	b = appendString(b, 3, r.ConsistencyToken)
+	return b
+}
+
+// Unmarshal decodes a batch result, skipping unknown fields
+func (r *BatchResult) Unmarshal(b []byte) error {
+	*r = BatchResult{}
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			r.Count = int64(v)
+			return n, nil
+		case num == 2 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return n, nil
+			}
+			var result EventResult
+			if err := result.Unmarshal(v); err != nil {
+				return 0, err
+			}
+			r.Events = append(r.Events, result)
+			return n, nil
+		case num == 3 && typ == protowire.BytesType:
+			return consumeString(b, &r.ConsistencyToken)
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+}
+
+// consumeFields walks the fields of a message. field decodes the value following a tag and
+// returns its length, or a negative protowire error code.
+func consumeFields(b []byte, field func(num protowire.Number, typ protowire.Type, b []byte) (int, error)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		n, err := field(num, typ, b)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// consumeString decodes a UTF-8 string value into dst
+func consumeString(b []byte, dst *string) (int, error) {
+	v, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return n, nil
+	}
+	if !utf8.Valid(v) {
+		return 0, ErrInvalidUTF8
+	}
+	*dst = string(v)
+	return n, nil
+}
+
+// appendString encodes a string field, omitting the proto3 default
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+// appendBytes encodes a bytes field, omitting the proto3 default
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+// appendMessage encodes an embedded message field; repeated elements are kept even when empty
+func appendMessage(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
@@ -0,0 +1,73 @@
+package eventpb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestEventBatch_RoundTrip(t *testing.T) {
+	batch := EventBatch{Events: []Event{
+		{
+			ID:        "3f2a8c1e-6b7d-4e5f-9a0b-1c2d3e4f5a6b",
+			SessionID: "test-session",
+			Type:      "edit",
+			Details:   []byte(`{"slideId":"s1"}`),
+			Timestamp: "2026-10-16T12:00:00Z",
+			UserID:    "user-1",
+			// Causal links
+			CorrelationID: "export-7f3a",
+			ParentEventID: "7c9e6679-7425-40de-944b-e07fc1f90ae7",
+			SchemaVersion: 2,
+			// Tenant
+			OrganizationID: "acme",
+		},
+		// An event with every field at its default is still kept in the batch
+		{},
+	}}
+
+	var decoded EventBatch
+	require.NoError(t, decoded.Unmarshal(batch.Marshal()))
+	assert.Equal(t, batch.Events[0], decoded.Events[0])
+	assert.Len(t, decoded.Events, 2)
+}
+
+func TestEvent_UnmarshalWireFormat(t *testing.T) {
+	// session_id = "s1", type = "edit", plus an unknown varint field 15 that must be skipped
+	b := []byte{0x12, 0x02, 's', '1', 0x1a, 0x04, 'e', 'd', 'i', 't', 0x78, 0x01}
+
+	var event Event
+	require.NoError(t, event.Unmarshal(b))
+	assert.Equal(t, Event{SessionID: "s1", Type: "edit"}, event)
+}
+
+func TestEvent_UnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "truncated length", data: []byte{0x12, 0x05, 's'}},
+		{name: "truncated tag", data: []byte{0x80}},
+		{name: "invalid utf-8", data: protowire.AppendString(protowire.AppendTag(nil, 2, protowire.BytesType), "\xff")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var event Event
+			assert.Error(t, event.Unmarshal(tt.data))
+		})
+	}
+}
+
+func TestBatchResult_RoundTrip(t *testing.T) {
+	result := BatchResult{Count: 2, Events: []EventResult{
+		{ID: "e1", SessionID: "s1", UserID: "u1", Type: "edit", Timestamp: "2026-10-16T12:00:00Z", Success: true},
+		{ID: "e2", SessionID: "s1", UserID: "u1", Type: "merge", Timestamp: "2026-10-16T12:00:01Z", Success: true, ConsistencyToken: "token-2"},
+	}, ConsistencyToken: "token"}
+
+	var decoded BatchResult
+	require.NoError(t, decoded.Unmarshal(result.Marshal()))
+	assert.Equal(t, result, decoded)
+}
@@ -0,0 +1,157 @@
+package eventtypes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/requestid"
+
+	"go.uber.org/zap"
+)
+
+// Repository stores the custom event types
+type Repository interface {
+	ListEventTypes(ctx context.Context) ([]domain.EventType, error)
+	CreateEventType(ctx context.Context, eventType domain.EventType) error
+}
+
+// Registry registers custom event types and defines them in the schema registry that validates
+// ingested events. Types registered through other instances are picked up by Load, which runs
+// at startup and once per refresh interval.
+type Registry struct {
+	repo     Repository
+	schemas  *domain.SchemaRegistry
+	interval time.Duration
+	clock    clock.Clock
+	logger   *zap.Logger
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// New creates an event type registry; call Load before serving and Start to refresh periodically
+func New(repo Repository, schemas *domain.SchemaRegistry, interval time.Duration, clk clock.Clock, logger *zap.Logger) *Registry {
+	return &Registry{
+		repo:     repo,
+		schemas:  schemas,
+		interval: interval,
+		clock:    clk,
+		logger:   logger,
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// Load defines the stored event types that the schema registry does not know yet.
+// Stored types that are no longer valid are logged and skipped.
+func (r *Registry) Load(ctx context.Context) error {
+	types, err := r.repo.ListEventTypes(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, eventType := range types {
+		err := r.schemas.DefineEventType(eventType)
+		switch {
+		case err == nil:
+			r.logger.Info("loaded custom event type", zap.String("name", string(eventType.Name)))
+		case errors.Is(err, domain.ErrEventTypeExists):
+		default:
+			r.logger.Warn("skipping invalid custom event type",
+				zap.String("name", string(eventType.Name)),
+				zap.Error(err),
+			)
+		}
+	}
+	return nil
+}
+
+// Start reloads the stored event types once per interval
+func (r *Registry) Start() {
+	go r.run()
+}
+
+// Close stops the refresh and waits for a running reload to be cancelled
+func (r *Registry) Close(ctx context.Context) error {
+	r.closeOnce.Do(func() { close(r.stop) })
+
+	select {
+	case <-r.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("event type refresh did not stop: %w", ctx.Err())
+	}
+}
+
+// run reloads the event types on the configured interval until Close is called
+func (r *Registry) run() {
+	defer close(r.stopped)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-r.stop
+		cancel()
+	}()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+
+		if err := r.Load(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Error("failed to refresh event types", zap.Error(err))
+		}
+	}
+}
+
+// Register validates and stores a custom event type and makes it available for ingestion
+func (r *Registry) Register(ctx context.Context, eventType domain.EventType, createdBy string) (domain.EventType, error) {
+	now := r.clock.Now()
+	eventType.BuiltIn = false
+	eventType.CreatedBy = createdBy
+	eventType.CreatedAt = &now
+
+	if err := eventType.Validate(); err != nil {
+		return domain.EventType{}, err
+	}
+	if _, ok := r.schemas.LookupEventType(eventType.Name); ok {
+		return domain.EventType{}, fmt.Errorf("%w: %s", domain.ErrEventTypeExists, eventType.Name)
+	}
+
+	if err := r.repo.CreateEventType(ctx, eventType); err != nil {
+		return domain.EventType{}, err
+	}
+
+	// A concurrent reload may already have defined the stored type
+	if err := r.schemas.DefineEventType(eventType); err != nil && !errors.Is(err, domain.ErrEventTypeExists) {
+		return domain.EventType{}, err
+	}
+
+	r.logger.Info("registered custom event type",
+		requestid.Field(ctx),
+		zap.String("name", string(eventType.Name)),
+		zap.String("severity", string(eventType.Severity)),
+		zap.String("created_by", createdBy),
+	)
+	return eventType, nil
+}
+
+// List returns the built-in and custom event types, reloading the stored types first
+func (r *Registry) List(ctx context.Context) ([]domain.EventType, error) {
+	if err := r.Load(ctx); err != nil {
+		return nil, err
+	}
+	return r.schemas.EventTypes(), nil
+}
@@ -0,0 +1,144 @@
+package eventtypes
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2024, 1, 10, 14, 0, 0, 0, time.UTC)
+
+// memoryRepository keeps event types in memory like the audit_event_types table
+type memoryRepository struct {
+	mu    sync.Mutex
+	types []domain.EventType
+	err   error
+}
+
+func (r *memoryRepository) ListEventTypes(context.Context) ([]domain.EventType, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]domain.EventType(nil), r.types...), r.err
+}
+
+func (r *memoryRepository) CreateEventType(_ context.Context, eventType domain.EventType) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	for _, existing := range r.types {
+		if existing.Name == eventType.Name {
+			return domain.ErrEventTypeExists
+		}
+	}
+	r.types = append(r.types, eventType)
+	return nil
+}
+
+func newTestRegistry(t *testing.T, repo Repository, interval time.Duration) (*Registry, *domain.SchemaRegistry) {
+	t.Helper()
+
+	schemas, err := domain.NewDefaultSchemaRegistry()
+	require.NoError(t, err)
+	return New(repo, schemas, interval, clock.NewFakeClock(testNow), zap.NewNop()), schemas
+}
+
+var approved = domain.EventType{
+	Name: "terminology_approved", DisplayName: "Terminology approved", Severity: domain.SeverityLow,
+	Schema: json.RawMessage(`{"type":"object","required":["term"]}`),
+}
+
+func TestRegistry_Register(t *testing.T) {
+	repo := &memoryRepository{}
+	registry, schemas := newTestRegistry(t, repo, 0)
+
+	created, err := registry.Register(context.Background(), approved, "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, "admin-1", created.CreatedBy)
+	require.NotNil(t, created.CreatedAt)
+	assert.Equal(t, testNow, *created.CreatedAt)
+
+	// The type is stored and accepted for ingestion right away
+	require.Len(t, repo.types, 1)
+	_, ok := schemas.LookupEventType("terminology_approved")
+	assert.True(t, ok)
+	assert.ErrorIs(t, schemas.Validate("terminology_approved", json.RawMessage(`{}`)), domain.ErrInvalidEventDetails)
+
+	_, err = registry.Register(context.Background(), approved, "admin-1")
+	assert.ErrorIs(t, err, domain.ErrEventTypeExists)
+
+	// Built-in types cannot be replaced
+	_, err = registry.Register(context.Background(), domain.EventType{Name: "edit", DisplayName: "Edit", Severity: domain.SeverityLow}, "admin-1")
+	assert.ErrorIs(t, err, domain.ErrEventTypeExists)
+
+	// Invalid definitions are not stored
+	_, err = registry.Register(context.Background(), domain.EventType{Name: "Bad Name", DisplayName: "Bad", Severity: domain.SeverityLow}, "admin-1")
+	assert.ErrorIs(t, err, domain.ErrInvalidEventType)
+	assert.Len(t, repo.types, 1)
+}
+
+func TestRegistry_RegisterStorageFailure(t *testing.T) {
+	repo := &memoryRepository{err: assert.AnError}
+	registry, schemas := newTestRegistry(t, repo, 0)
+
+	_, err := registry.Register(context.Background(), approved, "admin-1")
+	assert.ErrorIs(t, err, assert.AnError)
+
+	// Types that were not stored are not accepted
+	_, ok := schemas.LookupEventType("terminology_approved")
+	assert.False(t, ok)
+}
+
+func TestRegistry_Load(t *testing.T) {
+	repo := &memoryRepository{types: []domain.EventType{
+		approved,
+		// Stored types that became invalid are skipped
+		{Name: "broken", DisplayName: "Broken", Severity: "critical"},
+	}}
+	registry, schemas := newTestRegistry(t, repo, 0)
+
+	require.NoError(t, registry.Load(context.Background()))
+	_, ok := schemas.LookupEventType("terminology_approved")
+	assert.True(t, ok)
+	_, ok = schemas.LookupEventType("broken")
+	assert.False(t, ok)
+
+	// Loading again keeps the defined types
+	require.NoError(t, registry.Load(context.Background()))
+
+	types, err := registry.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, domain.AuditAction("terminology_approved"), types[len(types)-1].Name)
+
+	repo.err = assert.AnError
+	assert.ErrorIs(t, registry.Load(context.Background()), assert.AnError)
+}
+
+func TestRegistry_RefreshesPeriodically(t *testing.T) {
+	repo := &memoryRepository{}
+	registry, schemas := newTestRegistry(t, repo, 10*time.Millisecond)
+	registry.Start()
+
+	// Another instance registers a type
+	require.NoError(t, repo.CreateEventType(context.Background(), approved))
+
+	assert.Eventually(t, func() bool {
+		_, ok := schemas.LookupEventType("terminology_approved")
+		return ok
+	}, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, registry.Close(ctx))
+	require.NoError(t, registry.Close(ctx)) // closing twice is safe
+}
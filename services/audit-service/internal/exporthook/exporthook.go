@@ -0,0 +1,177 @@
+// Package exporthook records the lifecycle of exports rendered by the pptx export worker. The
+// worker reports each step (queued, rendering, uploaded, failed) in a callback signed with a
+// shared secret; the steps are stored as export_lifecycle events correlated by the export ID and
+// stitched, with the export event of the frontend, into one export record.
+package exporthook
+
+import (
+	"context"
+	"crypto/hmac"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/outbox"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/requestid"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxExportEvents caps the correlated events read to stitch an export record
+const maxExportEvents = 100
+
+// Verify checks the signature of a callback: signature is "sha256=" and the hex HMAC-SHA256 of
+// "timestamp.body", as signed for outbox deliveries, and timestamp the Unix time it was signed
+// at, at most tolerance away from now so captured callbacks cannot be replayed later.
+func Verify(secret []byte, timestamp, signature string, body []byte, now time.Time, tolerance time.Duration) error {
+	if timestamp == "" || signature == "" {
+		return fmt.Errorf("%w: callback signature is missing", domain.ErrUnauthorized)
+	}
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: callback timestamp is not a Unix time", domain.ErrUnauthorized)
+	}
+	if skew := now.Sub(time.Unix(signedAt, 0)).Abs(); skew > tolerance {
+		return fmt.Errorf("%w: callback timestamp is %s away from server time", domain.ErrUnauthorized, skew)
+	}
+
+	expected := outbox.Sign(secret, timestamp, body)
+	if !hmac.Equal([]byte(strings.TrimPrefix(signature, "sha256=")), []byte(expected)) {
+		return fmt.Errorf("%w: callback signature does not match", domain.ErrUnauthorized)
+	}
+	return nil
+}
+
+// Writer stores the lifecycle events
+type Writer interface {
+	CreateEvent(ctx context.Context, entry domain.AuditEntry) error
+}
+
+// EventReader reads the correlated events of an export and authorizes its readers
+type EventReader interface {
+	QueryAllEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, pagination domain.PaginationParams) (*domain.AuditResponse, error)
+	AuthorizeSession(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool) error
+}
+
+// Service records the export callbacks and serves the export records
+type Service struct {
+	writer     Writer
+	reader     EventReader
+	timestamps domain.TimestampPolicy
+	clock      clock.Clock
+	logger     *zap.Logger
+}
+
+// New creates the export hook service; step timestamps reported by the worker are checked
+// against the clock with the timestamps policy, like those of ingested events
+func New(writer Writer, reader EventReader, timestamps domain.TimestampPolicy, clk clock.Clock, logger *zap.Logger) *Service {
+	return &Service{
+		writer:     writer,
+		reader:     reader,
+		timestamps: timestamps,
+		clock:      clk,
+		logger:     logger,
+	}
+}
+
+// Record stores the lifecycle step of a callback and returns the export record including it.
+// Callbacks retried for a step already recorded are not stored again; created is false for them.
+func (s *Service) Record(ctx context.Context, callback domain.ExportCallback) (record domain.ExportRecord, created bool, err error) {
+	if err := callback.Validate(); err != nil {
+		return domain.ExportRecord{}, false, err
+	}
+
+	entries, err := s.correlated(ctx, domain.SessionID(callback.SessionID), callback.ExportID)
+	if err != nil {
+		return domain.ExportRecord{}, false, err
+	}
+	if existing, ok := domain.NewExportRecord(callback.ExportID, entries); ok && existing.HasStep(callback.Status) {
+		return existing, false, nil
+	}
+
+	receivedAt := s.clock.Now()
+	timestamp := receivedAt
+	if callback.Timestamp != nil {
+		if timestamp, err = s.timestamps.Apply(*callback.Timestamp, receivedAt); err != nil {
+			return domain.ExportRecord{}, false, err
+		}
+	}
+
+	details, err := domain.EncodeDetails(callback.Details())
+	if err != nil {
+		return domain.ExportRecord{}, false, err
+	}
+	entry := domain.AuditEntry{
+		ID:             uuid.New().String(),
+		SessionID:      callback.SessionID,
+		UserID:         callback.UserID,
+		Type:           string(domain.ActionExportLifecycle),
+		Timestamp:      timestamp,
+		Details:        details,
+		ReceivedAt:     &receivedAt,
+		CorrelationID:  callback.ExportID,
+		OrganizationID: callback.OrganizationID,
+	}
+	if err := s.writer.CreateEvent(ctx, entry); err != nil {
+		s.logger.Error("failed to record export step",
+			requestid.Field(ctx),
+			zap.String("export_id", callback.ExportID),
+			zap.String("status", string(callback.Status)),
+			zap.Error(err),
+		)
+		return domain.ExportRecord{}, false, err
+	}
+
+	s.logger.Info("export step recorded",
+		requestid.Field(ctx),
+		zap.String("export_id", callback.ExportID),
+		zap.String("session_id", callback.SessionID),
+		zap.String("status", string(callback.Status)),
+	)
+
+	record, _ = domain.NewExportRecord(callback.ExportID, append(entries, entry))
+	return record, true, nil
+}
+
+// Get returns the record of an export of a session the user may read
+func (s *Service) Get(ctx context.Context, exportID string, userID domain.UserID) (domain.ExportRecord, error) {
+	if exportID == "" || !domain.ValidCorrelationID(exportID) {
+		return domain.ExportRecord{}, domain.ErrExportNotFound
+	}
+
+	entries, err := s.correlated(ctx, "", exportID)
+	if err != nil {
+		return domain.ExportRecord{}, err
+	}
+	record, ok := domain.NewExportRecord(exportID, entries)
+	if !ok {
+		return domain.ExportRecord{}, domain.ErrExportNotFound
+	}
+
+	// Exports of sessions the user cannot read are not disclosed
+	if err := s.reader.AuthorizeSession(ctx, domain.SessionID(record.SessionID), userID, false); err != nil {
+		if domain.ToAPIError(err).Status < 500 {
+			return domain.ExportRecord{}, domain.ErrExportNotFound
+		}
+		return domain.ExportRecord{}, err
+	}
+	return record, nil
+}
+
+// correlated returns the export and export_lifecycle events carrying the export ID, of one
+// session or, when sessionID is empty, of all sessions
+func (s *Service) correlated(ctx context.Context, sessionID domain.SessionID, exportID string) ([]domain.AuditEntry, error) {
+	filter := domain.EventFilter{
+		Types:         []string{string(domain.ActionExport), string(domain.ActionExportLifecycle)},
+		CorrelationID: exportID,
+	}
+	response, err := s.reader.QueryAllEvents(ctx, sessionID, filter, domain.PaginationParams{Limit: maxExportEvents})
+	if err != nil {
+		return nil, err
+	}
+	return response.Items, nil
+}
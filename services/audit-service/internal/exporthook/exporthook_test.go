@@ -0,0 +1,160 @@
+package exporthook
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/outbox"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testSessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+var testNow = time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+// memoryEvents stores the written entries and answers the queries of the service from them
+type memoryEvents struct {
+	entries    []domain.AuditEntry
+	authorized map[string]bool
+}
+
+func (m *memoryEvents) CreateEvent(_ context.Context, entry domain.AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *memoryEvents) QueryAllEvents(_ context.Context, sessionID domain.SessionID, filter domain.EventFilter, _ domain.PaginationParams) (*domain.AuditResponse, error) {
+	items := []domain.AuditEntry{}
+	for _, entry := range m.entries {
+		if (sessionID == "" || entry.SessionID == string(sessionID)) && entry.CorrelationID == filter.CorrelationID {
+			items = append(items, entry)
+		}
+	}
+	return &domain.AuditResponse{Items: items, TotalCount: len(items)}, nil
+}
+
+func (m *memoryEvents) AuthorizeSession(_ context.Context, sessionID domain.SessionID, userID domain.UserID, _ bool) error {
+	if !m.authorized[string(userID)+"/"+string(sessionID)] {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+func newTestService() (*Service, *memoryEvents, *clock.FakeClock) {
+	events := &memoryEvents{authorized: map[string]bool{"user-123/" + testSessionID: true}}
+	fakeClock := clock.NewFakeClock(testNow)
+	return New(events, events, domain.DefaultTimestampPolicy, fakeClock, zap.NewNop()), events, fakeClock
+}
+
+func callback(status domain.ExportStatus) domain.ExportCallback {
+	return domain.ExportCallback{ExportID: "export-7f3a", SessionID: testSessionID, UserID: "user-123", Status: status}
+}
+
+func TestVerify(t *testing.T) {
+	secret := []byte("shared-secret")
+	body := []byte(`{"exportId":"export-7f3a"}`)
+	timestamp := strconv.FormatInt(testNow.Unix(), 10)
+	signature := "sha256=" + outbox.Sign(secret, timestamp, body)
+
+	require.NoError(t, Verify(secret, timestamp, signature, body, testNow, 5*time.Minute))
+	require.NoError(t, Verify(secret, timestamp, signature, body, testNow.Add(-4*time.Minute), 5*time.Minute))
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		body      string
+		now       time.Time
+	}{
+		{"missing signature", timestamp, "", string(body), testNow},
+		{"invalid timestamp", "yesterday", signature, string(body), testNow},
+		{"stale timestamp", timestamp, signature, string(body), testNow.Add(6 * time.Minute)},
+		{"tampered body", timestamp, signature, `{"exportId":"export-other"}`, testNow},
+		{"wrong secret", timestamp, "sha256=" + outbox.Sign([]byte("other"), timestamp, body), string(body), testNow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(secret, tt.timestamp, tt.signature, []byte(tt.body), tt.now, 5*time.Minute)
+			assert.ErrorIs(t, err, domain.ErrUnauthorized)
+		})
+	}
+}
+
+func TestService_RecordStitchesSteps(t *testing.T) {
+	service, events, fakeClock := newTestService()
+	ctx := context.Background()
+
+	record, created, err := service.Record(ctx, callback(domain.ExportQueued))
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, domain.ExportQueued, record.Status)
+
+	fakeClock.Advance(10 * time.Second)
+	uploaded := callback(domain.ExportUploaded)
+	uploaded.Format = "pptx"
+	record, created, err = service.Record(ctx, uploaded)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, domain.ExportUploaded, record.Status)
+	assert.Len(t, record.Steps, 2)
+	require.NotNil(t, record.DurationMs)
+	assert.Equal(t, int64(10000), *record.DurationMs)
+
+	require.Len(t, events.entries, 2)
+	entry := events.entries[1]
+	assert.Equal(t, string(domain.ActionExportLifecycle), entry.Type)
+	assert.Equal(t, "export-7f3a", entry.CorrelationID)
+	assert.Equal(t, testNow.Add(10*time.Second), entry.Timestamp)
+	assert.JSONEq(t, `{"exportId":"export-7f3a","status":"uploaded","format":"pptx"}`, string(entry.Details))
+
+	// A retried callback is acknowledged without being stored again
+	_, created, err = service.Record(ctx, uploaded)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Len(t, events.entries, 2)
+}
+
+func TestService_RecordValidates(t *testing.T) {
+	service, events, _ := newTestService()
+
+	_, _, err := service.Record(context.Background(), callback("downloaded"))
+	assert.ErrorIs(t, err, domain.ErrInvalidExportCallback)
+
+	// Steps are dated by the worker within the timestamp policy
+	future := callback(domain.ExportQueued)
+	at := testNow.Add(time.Hour)
+	future.Timestamp = &at
+	_, _, err = service.Record(context.Background(), future)
+	assert.ErrorIs(t, err, domain.ErrTimestampSkew)
+	assert.Empty(t, events.entries)
+}
+
+func TestService_Get(t *testing.T) {
+	service, _, _ := newTestService()
+	ctx := context.Background()
+
+	_, _, err := service.Record(ctx, callback(domain.ExportRendering))
+	require.NoError(t, err)
+
+	record, err := service.Get(ctx, "export-7f3a", "user-123")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ExportRendering, record.Status)
+	assert.Equal(t, testSessionID, record.SessionID)
+
+	// Exports of sessions the user cannot read are not disclosed
+	_, err = service.Get(ctx, "export-7f3a", "user-456")
+	assert.ErrorIs(t, err, domain.ErrExportNotFound)
+
+	_, err = service.Get(ctx, "export-unknown", "user-123")
+	assert.ErrorIs(t, err, domain.ErrExportNotFound)
+
+	_, err = service.Get(ctx, "export with spaces", "user-123")
+	assert.ErrorIs(t, err, domain.ErrExportNotFound)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+	"audit-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AdminHandler handles audit queries of support staff across sessions
+type AdminHandler struct {
+	service service.Reader
+	logger  *zap.Logger
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(service service.Reader, logger *zap.Logger) *AdminHandler {
+	return &AdminHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// QueryEvents handles GET /admin/events
+// @Summary Query audit events across sessions
+// @Description Searches the audit log of every session by user, event type and time window. Entries include the client IP and user agent. Admin only.
+// @Tags Admin
+// @Produce json
+// @Param sessionId query string false "Only events of this session"
+// @Param userId query string false "Only events created by this user"
+// @Param type query []string false "Event types, comma-separated or repeated" collectionFormat(multi)
+// @Param category query []string false "Categories (content, access, security, system, usage), comma-separated or repeated" collectionFormat(multi)
+// @Param severity query []string false "Severities (info, warning, critical), comma-separated or repeated" collectionFormat(multi)
+// @Param label query []string false "Labels, comma-separated or repeated; only events carrying one of them" collectionFormat(multi)
+// @Param from query string false "Only events at or after this RFC3339 timestamp"
+// @Param to query string false "Only events at or before this RFC3339 timestamp"
+// @Param q query string false "Free-text search over event details"
+// @Param correlationId query string false "Only events of this correlation ID"
+// @Param slideId query string false "Only events whose details reference this slide"
+// @Param shapeId query string false "Only events whose details reference this shape"
+// @Param commentId query string false "Only events whose details reference this comment"
+// @Param threadId query string false "Only events whose details reference this comment thread"
+// @Param limit query int false "Number of items to return (default: 50, max: 100)"
+// @Param offset query int false "Number of items to skip (default: 0)"
+// @Param cursor query string false "Opaque cursor from a previous response's nextCursor"
+// @Security BearerAuth
+// @Success 200 {object} domain.AuditResponse
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /admin/events [get]
+func (h *AdminHandler) QueryEvents(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	var sessionID domain.SessionID
+	if value := c.Query("sessionId"); value != "" {
+		parsed, ok := parseSessionID(c, value)
+		if !ok {
+			return
+		}
+		sessionID = parsed
+	}
+
+	pagination, apiErr := parsePagination(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	filter, apiErr := parseEventFilter(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	// Cross-session reads are logged at info level so support access can be reviewed
+	h.logger.Info("admin audit query",
+		zap.String("request_id", requestID),
+		zap.String("admin_id", middleware.GetAuthUserID(c)),
+		zap.Stringer("session_id", sessionID),
+		zap.String("filter_user_id", filter.UserID),
+		zap.Strings("types", filter.Types),
+		zap.Time("from", filter.From),
+		zap.Time("to", filter.To),
+	)
+
+	response, err := h.service.QueryAllEvents(c.Request.Context(), sessionID, filter, pagination)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
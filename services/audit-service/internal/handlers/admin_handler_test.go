@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func performAdminQuery(handler *AdminHandler, query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/admin/events"+query, nil)
+	c.Set(middleware.AuthUserIDKey, "admin-1")
+
+	handler.QueryEvents(c)
+	return w
+}
+
+func TestAdminHandler_QueryEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name              string
+		query             string
+		expectedSessionID string
+		expectedFilter    domain.EventFilter
+	}{
+		{
+			name:           "all sessions",
+			query:          "?userId=user-1&type=export,share&from=2024-01-01T00:00:00Z&to=2024-01-31T00:00:00Z",
+			expectedFilter: domain.EventFilter{UserID: "user-1", Types: []string{"export", "share"}, From: from, To: to},
+		},
+		{
+			name:              "single session",
+			query:             "?sessionId=" + sessionID + "&type=edit",
+			expectedSessionID: sessionID,
+			expectedFilter:    domain.EventFilter{Types: []string{"edit"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			response := &domain.AuditResponse{
+				TotalCount: 1,
+				Items: []domain.AuditEntry{{
+					ID: "event-1", SessionID: sessionID, UserID: "user-1", Type: "export",
+					Timestamp: from, IPAddress: "203.0.113.7",
+				}},
+			}
+			mockService.On("QueryAllEvents", mock.Anything, tt.expectedSessionID, tt.expectedFilter, domain.PaginationParams{Limit: 50}).
+				Return(response, nil).Once()
+
+			w := performAdminQuery(NewAdminHandler(mockService, zap.NewNop()), tt.query)
+
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			var got domain.AuditResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, 1, got.TotalCount)
+			// Support staff see the client details of entries
+			assert.Equal(t, "203.0.113.7", got.Items[0].IPAddress)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminHandler_QueryEvents_InvalidParameters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "invalid session ID", query: "?sessionId=not-a-uuid"},
+		{name: "invalid time", query: "?from=yesterday"},
+		{name: "inverted time window", query: "?from=2024-02-01T00:00:00Z&to=2024-01-01T00:00:00Z"},
+		{name: "invalid limit", query: "?limit=-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+
+			w := performAdminQuery(NewAdminHandler(mockService, zap.NewNop()), tt.query)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockService.AssertNotCalled(t, "QueryAllEvents")
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// EventAnnotationService reads and changes the annotation threads of audit events
+type EventAnnotationService interface {
+	List(ctx context.Context, eventID, userID string) (domain.AnnotationThread, error)
+	Get(ctx context.Context, eventID, annotationID, userID string) (domain.EventAnnotation, error)
+	Create(ctx context.Context, eventID, userID, body string) (domain.EventAnnotation, error)
+	Edit(ctx context.Context, eventID, annotationID, userID, body string, expectedVersion int64) (domain.EventAnnotation, error)
+	Delete(ctx context.Context, eventID, annotationID, userID string, expectedVersion int64) error
+}
+
+// EventAnnotationRequest defines the request body for adding or editing an annotation
+type EventAnnotationRequest struct {
+	Body string `json:"body" binding:"required" example:"Export made from an unrecognized IP; confirmed with the owner"`
+}
+
+// AnnotationsHandler handles the annotation threads of audit events
+type AnnotationsHandler struct {
+	annotations EventAnnotationService
+	logger      *zap.Logger
+}
+
+// NewAnnotationsHandler creates a new annotations handler
+func NewAnnotationsHandler(annotations EventAnnotationService, logger *zap.Logger) *AnnotationsHandler {
+	return &AnnotationsHandler{
+		annotations: annotations,
+		logger:      logger,
+	}
+}
+
+// ListEventAnnotations handles GET /events/{id}/annotations
+// @Summary List the annotations of an event
+// @Description Returns the annotation thread of an audit event, oldest first. Only reviewers and admins may read it.
+// @Tags Annotations
+// @Produce json
+// @Param id path string true "Event ID"
+// @Security BearerAuth
+// @Success 200 {object} domain.AnnotationThread
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /events/{id}/annotations [get]
+// @Router /admin/events/{id}/annotations [get]
+func (h *AnnotationsHandler) ListEventAnnotations(c *gin.Context) {
+	thread, err := h.annotations.List(c.Request.Context(), c.Param("id"), middleware.GetAuthUserID(c))
+	if err != nil {
+		h.writeError(c, "failed to list event annotations", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, thread)
+}
+
+// CreateEventAnnotation handles POST /events/{id}/annotations
+// @Summary Annotate an event
+// @Description Adds an annotation, such as a finding of a security investigation, to the thread of an audit event, with its version in the ETag header. Annotations are stored beside the event, which stays unchanged, and are recorded as annotation_created events of the session, children of the annotated event, naming the annotation but not its body. Only reviewers and admins may annotate events.
+// @Tags Annotations
+// @Accept json
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param request body EventAnnotationRequest true "Annotation"
+// @Security BearerAuth
+// @Success 201 {object} domain.EventAnnotation
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /events/{id}/annotations [post]
+// @Router /admin/events/{id}/annotations [post]
+func (h *AnnotationsHandler) CreateEventAnnotation(c *gin.Context) {
+	var req EventAnnotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.WriteError(c, invalidBody(c, err))
+		return
+	}
+
+	annotation, err := h.annotations.Create(c.Request.Context(), c.Param("id"), middleware.GetAuthUserID(c), req.Body)
+	if err != nil {
+		h.writeError(c, "failed to create event annotation", err)
+		return
+	}
+
+	respondWithVersion(c, http.StatusCreated, annotation.Version, annotation)
+}
+
+// GetEventAnnotation handles GET /events/{id}/annotations/{annotationId}
+// @Summary Get an annotation of an event
+// @Description Returns an annotation of an audit event, with its version in the ETag header. Only reviewers and admins may read it.
+// @Tags Annotations
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param annotationId path string true "Annotation ID"
+// @Security BearerAuth
+// @Success 200 {object} domain.EventAnnotation
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /events/{id}/annotations/{annotationId} [get]
+// @Router /admin/events/{id}/annotations/{annotationId} [get]
+func (h *AnnotationsHandler) GetEventAnnotation(c *gin.Context) {
+	annotation, err := h.annotations.Get(c.Request.Context(), c.Param("id"), c.Param("annotationId"), middleware.GetAuthUserID(c))
+	if err != nil {
+		h.writeError(c, "failed to get event annotation", err)
+		return
+	}
+
+	respondWithVersion(c, http.StatusOK, annotation.Version, annotation)
+}
+
+// EditEventAnnotation handles PUT /events/{id}/annotations/{annotationId}
+// @Summary Edit an annotation of an event
+// @Description Replaces the body of an annotation. The If-Match header must carry the ETag of the annotation the edit was made against; editing an annotation changed since is answered with 412. Only its author may edit an annotation; each edit is recorded as an annotation_edited event.
+// @Tags Annotations
+// @Accept json
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param annotationId path string true "Annotation ID"
+// @Param If-Match header string true "ETag of the annotation being edited"
+// @Param request body EventAnnotationRequest true "Annotation"
+// @Security BearerAuth
+// @Success 200 {object} domain.EventAnnotation
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 412 {object} domain.APIError
+// @Failure 428 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /events/{id}/annotations/{annotationId} [put]
+// @Router /admin/events/{id}/annotations/{annotationId} [put]
+func (h *AnnotationsHandler) EditEventAnnotation(c *gin.Context) {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+	var req EventAnnotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.WriteError(c, invalidBody(c, err))
+		return
+	}
+
+	annotation, err := h.annotations.Edit(c.Request.Context(), c.Param("id"), c.Param("annotationId"), middleware.GetAuthUserID(c), req.Body, version)
+	if err != nil {
+		h.writeError(c, "failed to edit event annotation", err)
+		return
+	}
+
+	respondWithVersion(c, http.StatusOK, annotation.Version, annotation)
+}
+
+// DeleteEventAnnotation handles DELETE /events/{id}/annotations/{annotationId}
+// @Summary Delete an annotation of an event
+// @Description Removes an annotation from the thread of an audit event. The If-Match header must carry the ETag of the annotation being deleted. Only its author may delete an annotation; the deletion is recorded as an annotation_deleted event.
+// @Tags Annotations
+// @Param id path string true "Event ID"
+// @Param annotationId path string true "Annotation ID"
+// @Param If-Match header string true "ETag of the annotation being deleted"
+// @Security BearerAuth
+// @Success 204
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 412 {object} domain.APIError
+// @Failure 428 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /events/{id}/annotations/{annotationId} [delete]
+// @Router /admin/events/{id}/annotations/{annotationId} [delete]
+func (h *AnnotationsHandler) DeleteEventAnnotation(c *gin.Context) {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+
+	if err := h.annotations.Delete(c.Request.Context(), c.Param("id"), c.Param("annotationId"), middleware.GetAuthUserID(c), version); err != nil {
+		h.writeError(c, "failed to delete event annotation", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// writeError writes the API error of a failed annotation operation, logging server errors
+func (h *AnnotationsHandler) writeError(c *gin.Context, message string, err error) {
+	// Reasons for an invalid annotation are returned to the caller as is
+	if errors.Is(err, domain.ErrInvalidAnnotation) {
+		middleware.WriteError(c, domain.NewAPIError("invalid_annotation", err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	apiErr := domain.ToAPIError(err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		h.logger.Error(message,
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.String("event_id", c.Param("id")),
+			zap.String("annotation_id", c.Param("annotationId")),
+			zap.Error(err),
+		)
+	}
+	middleware.WriteError(c, apiErr)
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockEventAnnotationService is a mock implementation of EventAnnotationService
+type MockEventAnnotationService struct {
+	mock.Mock
+}
+
+func (m *MockEventAnnotationService) List(ctx context.Context, eventID, userID string) (domain.AnnotationThread, error) {
+	args := m.Called(ctx, eventID, userID)
+	return args.Get(0).(domain.AnnotationThread), args.Error(1)
+}
+
+func (m *MockEventAnnotationService) Get(ctx context.Context, eventID, annotationID, userID string) (domain.EventAnnotation, error) {
+	args := m.Called(ctx, eventID, annotationID, userID)
+	return args.Get(0).(domain.EventAnnotation), args.Error(1)
+}
+
+func (m *MockEventAnnotationService) Create(ctx context.Context, eventID, userID, body string) (domain.EventAnnotation, error) {
+	args := m.Called(ctx, eventID, userID, body)
+	return args.Get(0).(domain.EventAnnotation), args.Error(1)
+}
+
+func (m *MockEventAnnotationService) Edit(ctx context.Context, eventID, annotationID, userID, body string, expectedVersion int64) (domain.EventAnnotation, error) {
+	args := m.Called(ctx, eventID, annotationID, userID, body, expectedVersion)
+	return args.Get(0).(domain.EventAnnotation), args.Error(1)
+}
+
+func (m *MockEventAnnotationService) Delete(ctx context.Context, eventID, annotationID, userID string, expectedVersion int64) error {
+	args := m.Called(ctx, eventID, annotationID, userID, expectedVersion)
+	return args.Error(0)
+}
+
+const (
+	testAnnotatedEvent = "550e8400-e29b-41d4-a716-446655440001"
+	testAnnotation     = "6f1b2c3d-4e5f-4a57-9d0e-0b8f5e0c3c1d"
+)
+
+var testEventAnnotation = domain.EventAnnotation{
+	ID: testAnnotation, EventID: testAnnotatedEvent, SessionID: "550e8400-e29b-41d4-a716-446655440000",
+	AuthorID: "user-1", Body: "Export made from an unrecognized IP", Version: 2,
+}
+
+func performAnnotationRequest(method, body string, header map[string]string, handle func(c *gin.Context)) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/api/v1/events/"+testAnnotatedEvent+"/annotations/"+testAnnotation, bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	for name, value := range header {
+		c.Request.Header.Set(name, value)
+	}
+	c.Set(middleware.AuthUserIDKey, "user-1")
+	c.Params = gin.Params{{Key: "id", Value: testAnnotatedEvent}, {Key: "annotationId", Value: testAnnotation}}
+
+	handle(c)
+	// The router writes the status of bodiless responses once the handler returns
+	c.Writer.WriteHeaderNow()
+	return w
+}
+
+func TestAnnotationsHandler_ListAndCreate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	thread := domain.AnnotationThread{EventID: testAnnotatedEvent, Items: []domain.EventAnnotation{testEventAnnotation}}
+	annotations := new(MockEventAnnotationService)
+	annotations.On("List", mock.Anything, testAnnotatedEvent, "user-1").Return(thread, nil).Once()
+	annotations.On("Create", mock.Anything, testAnnotatedEvent, "user-1", "Finding").Return(testEventAnnotation, nil).Once()
+	annotations.On("Create", mock.Anything, testAnnotatedEvent, "user-1", " ").
+		Return(domain.EventAnnotation{}, fmt.Errorf("%w: body must not be empty", domain.ErrInvalidAnnotation)).Once()
+	handler := NewAnnotationsHandler(annotations, zap.NewNop())
+
+	w := performAnnotationRequest("GET", "", nil, handler.ListEventAnnotations)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var got domain.AnnotationThread
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, thread, got)
+
+	w = performAnnotationRequest("POST", `{"body":"Finding"}`, nil, handler.CreateEventAnnotation)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	w = performAnnotationRequest("POST", `{"body":" "}`, nil, handler.CreateEventAnnotation)
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	var apiErr domain.APIError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+	assert.Equal(t, "invalid_annotation", apiErr.Code)
+	assert.Contains(t, apiErr.Message, "must not be empty")
+
+	w = performAnnotationRequest("POST", `{}`, nil, handler.CreateEventAnnotation)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	annotations.AssertExpectations(t)
+}
+
+func TestAnnotationsHandler_EditAndDelete(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		ifMatch        string
+		serviceErr     error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "current version", ifMatch: `"1"`},
+		{name: "stale version", ifMatch: `"1"`, serviceErr: domain.ErrVersionConflict, expectedStatus: http.StatusPreconditionFailed, expectedCode: "version_conflict"},
+		{name: "other author", ifMatch: `"1"`, serviceErr: domain.ErrForbidden, expectedStatus: http.StatusForbidden, expectedCode: "forbidden"},
+		{name: "missing If-Match", expectedStatus: http.StatusPreconditionRequired, expectedCode: "precondition_required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := new(MockEventAnnotationService)
+			if tt.ifMatch != "" {
+				annotations.On("Edit", mock.Anything, testAnnotatedEvent, testAnnotation, "user-1", "Revised", int64(1)).
+					Return(testEventAnnotation, tt.serviceErr).Once()
+				annotations.On("Delete", mock.Anything, testAnnotatedEvent, testAnnotation, "user-1", int64(1)).
+					Return(tt.serviceErr).Once()
+			}
+			handler := NewAnnotationsHandler(annotations, zap.NewNop())
+			header := map[string]string{}
+			if tt.ifMatch != "" {
+				header["If-Match"] = tt.ifMatch
+			}
+
+			edit := performAnnotationRequest("PUT", `{"body":"Revised"}`, header, handler.EditEventAnnotation)
+			del := performAnnotationRequest("DELETE", "", header, handler.DeleteEventAnnotation)
+
+			if tt.expectedCode == "" {
+				require.Equal(t, http.StatusOK, edit.Code, edit.Body.String())
+				assert.Equal(t, `"2"`, edit.Header().Get("ETag"))
+				assert.Equal(t, http.StatusNoContent, del.Code, del.Body.String())
+			} else {
+				for _, w := range []*httptest.ResponseRecorder{edit, del} {
+					require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+					var apiErr domain.APIError
+					require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+					assert.Equal(t, tt.expectedCode, apiErr.Code)
+				}
+			}
+			annotations.AssertExpectations(t)
+		})
+	}
+}
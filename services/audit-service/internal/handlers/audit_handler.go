@@ -1,28 +1,38 @@
 package handlers
 
 import (
+	"context"
+	"encoding/csv"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"audit-service/internal/domain"
 	"audit-service/internal/middleware"
 	"audit-service/internal/service"
+	"audit-service/pkg/clock"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+// consistencyTokenHeader carries the consistency token of an event creation on history reads
+const consistencyTokenHeader = "Consistency-Token"
+
 // AuditHandler handles audit-related HTTP requests
 type AuditHandler struct {
-	service service.AuditService
+	service service.Reader
+	clock   clock.Clock
 	logger  *zap.Logger
 }
 
 // NewAuditHandler creates a new audit handler
-func NewAuditHandler(service service.AuditService, logger *zap.Logger) *AuditHandler {
+func NewAuditHandler(service service.Reader, clk clock.Clock, logger *zap.Logger) *AuditHandler {
 	return &AuditHandler{
 		service: service,
+		clock:   clk,
 		logger:  logger,
 	}
 }
@@ -36,102 +46,1169 @@ func NewAuditHandler(service service.AuditService, logger *zap.Logger) *AuditHan
 // @Param sessionId path string true "Session ID"
 // @Param limit query int false "Number of items to return (default: 50, max: 100)"
 // @Param offset query int false "Number of items to skip (default: 0)"
+// @Param cursor query string false "Opaque cursor from a previous response's nextCursor"
 // @Param share_token query string false "Share token for reviewer access"
+// @Param If-None-Match header string false "ETag of a previous response; answered with 304 when unchanged"
+// @Param Consistency-Token header string false "Consistency token returned by event creation; waits until the events it names are readable"
 // @Security BearerAuth
 // @Success 200 {object} domain.AuditResponse
+// @Success 304 "Not modified"
 // @Failure 400 {object} domain.APIError
 // @Failure 401 {object} domain.APIError
 // @Failure 403 {object} domain.APIError
 // @Failure 404 {object} domain.APIError
 // @Failure 500 {object} domain.APIError
+// @Failure 503 {object} domain.APIError
 // @Router /sessions/{sessionId}/history [get]
 func (h *AuditHandler) GetHistory(c *gin.Context) {
 	requestID := middleware.GetRequestID(c)
 
 	// Extract session ID from path
-	sessionID := c.Param("sessionId")
-	if sessionID == "" {
-		c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", "Session ID is required", http.StatusBadRequest))
-		return
-	}
-
-	// Validate UUID format
-	if !isValidUUID(sessionID) {
-		c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", "Invalid session ID format", http.StatusBadRequest))
+	sessionID, ok := parseSessionID(c, c.Param("sessionId"))
+	if !ok {
 		return
 	}
 
 	// Parse pagination parameters
-	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
-	if err != nil || limit < 0 {
-		c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", "Invalid limit parameter", http.StatusBadRequest))
+	pagination, apiErr := parsePagination(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
 		return
 	}
 
-	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
-	if err != nil || offset < 0 {
-		c.JSON(http.StatusBadRequest, domain.NewAPIError("bad_request", "Invalid offset parameter", http.StatusBadRequest))
+	ctx, ok := readContext(c)
+	if !ok {
 		return
 	}
 
-	pagination := domain.PaginationParams{
-		Limit:  limit,
-		Offset: offset,
-	}
-
 	// Get auth info from context
-	userID := middleware.GetAuthUserID(c)
+	userID := authUserID(c)
 	tokenType := middleware.GetAuthTokenType(c)
 	isShareToken := tokenType == middleware.TokenTypeShare
 
 	h.logger.Debug("processing audit history request",
 		zap.String("request_id", requestID),
-		zap.String("session_id", sessionID),
-		zap.String("user_id", userID),
+		zap.Stringer("session_id", sessionID),
+		zap.Stringer("user_id", userID),
 		zap.Bool("share_token", isShareToken),
-		zap.Int("limit", limit),
-		zap.Int("offset", offset),
+		zap.Int("limit", pagination.Limit),
+		zap.Int("offset", pagination.Offset),
+		zap.Bool("cursor", pagination.Cursor != nil),
 	)
 
 	// Call service
-	response, err := h.service.GetAuditLogs(c.Request.Context(), sessionID, userID, isShareToken, pagination)
+	response, err := h.service.GetAuditLogs(ctx, sessionID, userID, isShareToken, pagination)
 	if err != nil {
 		// Handle specific errors
 		apiErr := domain.ToAPIError(err)
-		c.JSON(apiErr.Status, apiErr)
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	// Success response; pollers holding the current version get 304
+	respondWithETag(c, http.StatusOK, response)
+}
+
+// GetStats handles GET /sessions/{sessionId}/stats
+// @Summary Get audit statistics for a session
+// @Description Returns event counts by type, distinct contributors, first and last activity and edits per slide
+// @Tags Audit
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param share_token query string false "Share token for reviewer access"
+// @Param Consistency-Token header string false "Consistency token returned by event creation; waits until the events it names are readable"
+// @Security BearerAuth
+// @Success 200 {object} domain.SessionStats
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Failure 503 {object} domain.APIError
+// @Router /sessions/{sessionId}/stats [get]
+func (h *AuditHandler) GetStats(c *gin.Context) {
+	sessionID, ok := parseSessionID(c, c.Param("sessionId"))
+	if !ok {
+		return
+	}
+
+	ctx, ok := readContext(c)
+	if !ok {
+		return
+	}
+
+	userID := authUserID(c)
+	isShareToken := middleware.GetAuthTokenType(c) == middleware.TokenTypeShare
+
+	h.logger.Debug("processing session stats request",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.Stringer("session_id", sessionID),
+		zap.Stringer("user_id", userID),
+		zap.Bool("share_token", isShareToken),
+	)
+
+	stats, err := h.service.GetSessionStats(ctx, sessionID, userID, isShareToken)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetActivity handles GET /sessions/{sessionId}/activity
+// @Summary Get the activity timeline of a session
+// @Description Returns event counts per user and hour or day, served from rollups rebuilt on a schedule; events recorded since the last rollup run are not counted yet. Without a range the last 30 buckets are returned.
+// @Tags Audit
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param granularity query string false "Bucket length, day (default) or hour" Enums(day, hour)
+// @Param from query string false "Only buckets starting at or after this RFC3339 timestamp, rounded down to a bucket"
+// @Param to query string false "Only buckets starting before this RFC3339 timestamp, rounded up to a bucket"
+// @Param userId query string false "Only activity of this user"
+// @Param share_token query string false "Share token for reviewer access"
+// @Security BearerAuth
+// @Success 200 {object} domain.SessionActivity
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /sessions/{sessionId}/activity [get]
+func (h *AuditHandler) GetActivity(c *gin.Context) {
+	sessionID, ok := parseSessionID(c, c.Param("sessionId"))
+	if !ok {
+		return
+	}
+
+	query, apiErr := parseActivityQuery(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	userID := authUserID(c)
+	isShareToken := middleware.GetAuthTokenType(c) == middleware.TokenTypeShare
+
+	h.logger.Debug("processing session activity request",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.Stringer("session_id", sessionID),
+		zap.Stringer("user_id", userID),
+		zap.String("granularity", string(query.Granularity)),
+		zap.Bool("share_token", isShareToken),
+	)
+
+	activity, err := h.service.GetSessionActivity(c.Request.Context(), sessionID, userID, isShareToken, query)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, activity)
+}
+
+// GetHeatmap handles GET /sessions/{sessionId}/heatmap
+// @Summary Get the slide heatmap of a session
+// @Description Returns event counts per slide and UTC hour, ordered by slide index, aggregated from the events that reference a slide. Without a range the last 7 days are returned.
+// @Tags Audit
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param from query string false "Only hours starting at or after this RFC3339 timestamp, rounded down to an hour"
+// @Param to query string false "Only hours starting before this RFC3339 timestamp, rounded up to an hour"
+// @Param userId query string false "Only events of this user"
+// @Param share_token query string false "Share token for reviewer access"
+// @Security BearerAuth
+// @Success 200 {object} domain.SessionHeatmap
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /sessions/{sessionId}/heatmap [get]
+func (h *AuditHandler) GetHeatmap(c *gin.Context) {
+	sessionID, ok := parseSessionID(c, c.Param("sessionId"))
+	if !ok {
+		return
+	}
+
+	// The heatmap takes the range and user of an activity query; its buckets are always hours
+	activityQuery, apiErr := parseActivityQuery(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+	query := domain.HeatmapQuery{From: activityQuery.From, To: activityQuery.To, UserID: activityQuery.UserID}
+
+	userID := authUserID(c)
+	isShareToken := middleware.GetAuthTokenType(c) == middleware.TokenTypeShare
+
+	h.logger.Debug("processing session heatmap request",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.Stringer("session_id", sessionID),
+		zap.Stringer("user_id", userID),
+		zap.Bool("share_token", isShareToken),
+	)
+
+	heatmap, err := h.service.GetSessionHeatmap(c.Request.Context(), sessionID, userID, isShareToken, query)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, heatmap)
+}
+
+// GetContributors handles GET /sessions/{sessionId}/contributors
+// @Summary Get the contribution report of a session
+// @Description Returns per-user counts of edits, comments and merges with the time of their first and last activity, most active users first. Comments count comment and comment_created events. Events written by the service itself are left out. Without a range the whole history of the session is counted.
+// @Tags Audit
+// @Produce json
+// @Produce text/csv
+// @Param sessionId path string true "Session ID"
+// @Param format query string false "Response format, json (default) or csv" Enums(json, csv)
+// @Param from query string false "Only events at or after this RFC3339 timestamp"
+// @Param to query string false "Only events at or before this RFC3339 timestamp"
+// @Param share_token query string false "Share token for reviewer access"
+// @Security BearerAuth
+// @Success 200 {object} domain.SessionContributors
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /sessions/{sessionId}/contributors [get]
+func (h *AuditHandler) GetContributors(c *gin.Context) {
+	sessionID, ok := parseSessionID(c, c.Param("sessionId"))
+	if !ok {
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", exportFormatJSON))
+	if format != exportFormatCSV && format != exportFormatJSON {
+		middleware.WriteError(c, domain.NewAPIError("bad_request", "format must be csv or json", http.StatusBadRequest))
+		return
+	}
+
+	// The report takes the range of an activity query
+	activityQuery, apiErr := parseActivityQuery(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+	query := domain.ContributorQuery{From: activityQuery.From, To: activityQuery.To}
+
+	userID := authUserID(c)
+	isShareToken := middleware.GetAuthTokenType(c) == middleware.TokenTypeShare
+
+	h.logger.Debug("processing session contributors request",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.Stringer("session_id", sessionID),
+		zap.Stringer("user_id", userID),
+		zap.String("format", format),
+		zap.Bool("share_token", isShareToken),
+	)
+
+	report, err := h.service.GetSessionContributors(c.Request.Context(), sessionID, userID, isShareToken, query)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteError(c, apiErr)
 		return
 	}
 
-	// Success response
-	c.JSON(http.StatusOK, response)
+	if format == exportFormatJSON {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	filename := fmt.Sprintf("contributors-%s-%s.csv", sessionID, h.clock.Now().UTC().Format("20060102T150405Z"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := writeContributorsCSV(c.Writer, report.Contributors); err != nil {
+		h.logger.Warn("failed to write contributors report",
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.Error(err),
+		)
+	}
+}
+
+// contributorsCSVHeader is the first row of a CSV contribution report
+var contributorsCSVHeader = []string{"user_id", "edits", "comments", "merges", "total_events", "first_activity", "last_activity"}
+
+// writeContributorsCSV writes one row per contributor after a header row
+func writeContributorsCSV(w gin.ResponseWriter, contributors []domain.ContributorStats) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write(contributorsCSVHeader)
+	for _, contributor := range contributors {
+		record := []string{
+			escapeCSVFormula(contributor.UserID),
+			strconv.Itoa(contributor.Edits),
+			strconv.Itoa(contributor.Comments),
+			strconv.Itoa(contributor.Merges),
+			strconv.Itoa(contributor.TotalEvents),
+			contributor.FirstActivity.UTC().Format(time.RFC3339Nano),
+			contributor.LastActivity.UTC().Format(time.RFC3339Nano),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
 }
 
-// isValidUUID validates if a string is a valid UUID
-func isValidUUID(uuid string) bool {
-	// Allow test session IDs for testing purposes
-	if strings.HasPrefix(uuid, "test-") {
-		return true
+// GetAccessReview handles GET /sessions/{sessionId}/access-review
+// @Summary Get the access review of a session
+// @Description Takes a point-in-time snapshot of who opened a session, from its view events: every user with the access method (jwt or share_token) and share link they came through, their number of views, first and last access and client addresses, the most recent first. Views that do not record details.accessMethod are reported as unknown. Only the owner of the session and admins may take the snapshot; share tokens are rejected.
+// @Tags Audit
+// @Produce json
+// @Produce text/csv
+// @Param sessionId path string true "Session ID"
+// @Param format query string false "Response format, json (default) or csv" Enums(json, csv)
+// @Param from query string false "Only views at or after this RFC3339 timestamp"
+// @Param to query string false "Only views at or before this RFC3339 timestamp"
+// @Security BearerAuth
+// @Success 200 {object} domain.AccessReview
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Failure 503 {object} domain.APIError
+// @Router /sessions/{sessionId}/access-review [get]
+func (h *AuditHandler) GetAccessReview(c *gin.Context) {
+	sessionID, ok := parseSessionID(c, c.Param("sessionId"))
+	if !ok {
+		return
+	}
+
+	// Reviewers holding a share link must not see who else accessed the session
+	if middleware.GetAuthTokenType(c) == middleware.TokenTypeShare {
+		middleware.WriteError(c, domain.APIErrForbidden)
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", exportFormatJSON))
+	if format != exportFormatCSV && format != exportFormatJSON {
+		middleware.WriteError(c, domain.NewAPIError("bad_request", "format must be csv or json", http.StatusBadRequest))
+		return
+	}
+
+	// The review takes the range of an activity query
+	activityQuery, apiErr := parseActivityQuery(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+	query := domain.AccessReviewQuery{From: activityQuery.From, To: activityQuery.To}
+
+	userID := authUserID(c)
+	h.logger.Debug("processing access review request",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.Stringer("session_id", sessionID),
+		zap.Stringer("user_id", userID),
+		zap.String("format", format),
+	)
+
+	review, err := h.service.GetAccessReview(c.Request.Context(), sessionID, userID, query)
+	if err != nil {
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
 	}
 
-	// Simple UUID validation - check format
-	if len(uuid) != 36 {
-		return false
+	c.Header("Cache-Control", "no-store")
+	if format == exportFormatJSON {
+		c.JSON(http.StatusOK, review)
+		return
 	}
 
-	// Check for hyphens at correct positions
-	if uuid[8] != '-' || uuid[13] != '-' || uuid[18] != '-' || uuid[23] != '-' {
-		return false
+	filename := fmt.Sprintf("access-review-%s-%s.csv", sessionID, review.GeneratedAt.Format("20060102T150405Z"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := writeAccessReviewCSV(c.Writer, review.Accessors); err != nil {
+		h.logger.Warn("failed to write access review",
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.Error(err),
+		)
 	}
+}
+
+// accessReviewCSVHeader is the first row of a CSV access review
+var accessReviewCSVHeader = []string{"user_id", "access_method", "share_token_id", "views", "first_access", "last_access", "ip_addresses"}
 
-	// Check that all other characters are hex
-	for i, char := range uuid {
-		if i == 8 || i == 13 || i == 18 || i == 23 {
-			continue
+// writeAccessReviewCSV writes one row per accessor after a header row; the addresses of an
+// accessor are separated by spaces
+func writeAccessReviewCSV(w gin.ResponseWriter, accessors []domain.AccessReviewEntry) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write(accessReviewCSVHeader)
+	for _, accessor := range accessors {
+		record := []string{
+			escapeCSVFormula(accessor.UserID),
+			string(accessor.AccessMethod),
+			escapeCSVFormula(accessor.ShareTokenID),
+			strconv.Itoa(accessor.Views),
+			accessor.FirstAccess.UTC().Format(time.RFC3339Nano),
+			accessor.LastAccess.UTC().Format(time.RFC3339Nano),
+			strings.Join(accessor.IPAddresses, " "),
 		}
-		if !((char >= '0' && char <= '9') || (char >= 'a' && char <= 'f') || (char >= 'A' && char <= 'F')) {
-			return false
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// GetTMLeverage handles GET /sessions/{sessionId}/tm/leverage
+// @Summary Get the translation memory leverage of a session
+// @Description Counts the tm_hit and tm_override events of a session: TM matches offered, accepted and rejected, overrides of accepted matches, leveraged words and the hits per match band. Without a range the whole history of the session is counted; at most 100000 events are, and truncated is set when there were more.
+// @Tags Audit
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param from query string false "Only events at or after this RFC3339 timestamp"
+// @Param to query string false "Only events at or before this RFC3339 timestamp"
+// @Param share_token query string false "Share token for reviewer access"
+// @Security BearerAuth
+// @Success 200 {object} domain.SessionTMLeverage
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Failure 503 {object} domain.APIError
+// @Router /sessions/{sessionId}/tm/leverage [get]
+func (h *AuditHandler) GetTMLeverage(c *gin.Context) {
+	sessionID, ok := parseSessionID(c, c.Param("sessionId"))
+	if !ok {
+		return
+	}
+
+	// The report takes the range of an activity query
+	activityQuery, apiErr := parseActivityQuery(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+	query := domain.TMLeverageQuery{From: activityQuery.From, To: activityQuery.To}
+
+	userID := authUserID(c)
+	isShareToken := middleware.GetAuthTokenType(c) == middleware.TokenTypeShare
+
+	h.logger.Debug("processing TM leverage request",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.Stringer("session_id", sessionID),
+		zap.Stringer("user_id", userID),
+		zap.Bool("share_token", isShareToken),
+	)
+
+	report, err := h.service.GetTMLeverage(c.Request.Context(), sessionID, userID, isShareToken, query)
+	if err != nil {
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetMTCosts handles GET /sessions/{sessionId}/mt/costs
+// @Summary Get the machine translation costs of a session
+// @Description Rolls up the mt_request events of a session per provider, model and currency: requests, failed requests, characters sent and returned, latency and estimated cost, the most expensive first, with the cost totals per currency. Without a range the whole history of the session is counted; at most 100000 requests are, and truncated is set when there were more.
+// @Tags Audit
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param from query string false "Only requests at or after this RFC3339 timestamp"
+// @Param to query string false "Only requests at or before this RFC3339 timestamp"
+// @Param share_token query string false "Share token for reviewer access"
+// @Security BearerAuth
+// @Success 200 {object} domain.SessionMTCosts
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Failure 503 {object} domain.APIError
+// @Router /sessions/{sessionId}/mt/costs [get]
+func (h *AuditHandler) GetMTCosts(c *gin.Context) {
+	sessionID, ok := parseSessionID(c, c.Param("sessionId"))
+	if !ok {
+		return
+	}
+
+	// The rollup takes the range of an activity query
+	activityQuery, apiErr := parseActivityQuery(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+	query := domain.MTCostQuery{From: activityQuery.From, To: activityQuery.To}
+
+	userID := authUserID(c)
+	isShareToken := middleware.GetAuthTokenType(c) == middleware.TokenTypeShare
+
+	h.logger.Debug("processing MT costs request",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.Stringer("session_id", sessionID),
+		zap.Stringer("user_id", userID),
+		zap.Bool("share_token", isShareToken),
+	)
+
+	report, err := h.service.GetMTCosts(c.Request.Context(), sessionID, userID, isShareToken, query)
+	if err != nil {
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetTimeline handles GET /sessions/{sessionId}/timeline
+// @Summary Get the timeline of a session
+// @Description Merges the events of a session into a compacted, human-readable timeline, the oldest first. Consecutive edits, merges and reorders of a user form one burst, and consecutive views of a user one run, while each follows the previous within gapSeconds; any other event of the user ends them. Other events are single groups. Each group has a summary for display and an ID derived from its first event, which stays the same as later events join the group. At most the newest 10000 events of the range are merged, and truncated is set when there were more.
+// @Tags Audit
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param from query string false "Only events at or after this RFC3339 timestamp"
+// @Param to query string false "Only events at or before this RFC3339 timestamp"
+// @Param gapSeconds query int false "Longest pause within a burst of edits or a run of views, 1 to 86400 seconds (default 300)"
+// @Param share_token query string false "Share token for reviewer access"
+// @Security BearerAuth
+// @Success 200 {object} domain.SessionTimeline
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Failure 503 {object} domain.APIError
+// @Router /sessions/{sessionId}/timeline [get]
+func (h *AuditHandler) GetTimeline(c *gin.Context) {
+	sessionID, ok := parseSessionID(c, c.Param("sessionId"))
+	if !ok {
+		return
+	}
+
+	// The timeline takes the range of an activity query
+	activityQuery, apiErr := parseActivityQuery(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+	query := domain.TimelineQuery{From: activityQuery.From, To: activityQuery.To}
+	if gap := c.Query("gapSeconds"); gap != "" {
+		seconds, err := strconv.Atoi(gap)
+		if err != nil || seconds < 1 {
+			middleware.WriteError(c, domain.NewAPIError("bad_request", "Invalid gapSeconds parameter, expected a positive number of seconds", http.StatusBadRequest))
+			return
 		}
+		query.Gap = time.Duration(seconds) * time.Second
+	}
+
+	userID := authUserID(c)
+	isShareToken := middleware.GetAuthTokenType(c) == middleware.TokenTypeShare
+
+	h.logger.Debug("processing timeline request",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.Stringer("session_id", sessionID),
+		zap.Stringer("user_id", userID),
+		zap.Bool("share_token", isShareToken),
+	)
+
+	timeline, err := h.service.GetSessionTimeline(c.Request.Context(), sessionID, userID, isShareToken, query)
+	if err != nil {
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, timeline)
+}
+
+// VerifyChain handles GET /sessions/{sessionId}/events/verify and its admin variant
+// @Summary Verify the audit trail of a session
+// @Description Recomputes the per-session hash chain and reports entries that were modified, removed or re-linked. Entries recorded before hash chaining was enabled are not checked. Admins may verify any session under /admin.
+// @Tags Audit
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param share_token query string false "Share token for reviewer access"
+// @Security BearerAuth
+// @Success 200 {object} domain.ChainVerification
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /sessions/{sessionId}/events/verify [get]
+// @Router /admin/sessions/{sessionId}/events/verify [get]
+func (h *AuditHandler) VerifyChain(c *gin.Context) {
+	sessionID, ok := parseSessionID(c, c.Param("sessionId"))
+	if !ok {
+		return
 	}
 
-	return true
+	userID := authUserID(c)
+	isShareToken := middleware.GetAuthTokenType(c) == middleware.TokenTypeShare
+
+	result, err := h.service.VerifyChain(c.Request.Context(), sessionID, userID, isShareToken)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	h.logger.Info("verified audit log chain",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.Stringer("session_id", sessionID),
+		zap.Stringer("user_id", userID),
+		zap.Bool("valid", result.Valid),
+		zap.Int("checked_entries", result.CheckedEntries),
+	)
+
+	// Verification reflects the current state of storage and must not be served from caches
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, result)
+}
+
+// GetEventChain handles GET /events/{id}/chain
+// @Summary Get the causal chain of an audit event
+// @Description Returns the events of the same session that share the event's correlation ID, plus its ancestors reached through parentEventId, oldest first. Only the session owner may read it.
+// @Tags Audit
+// @Produce json
+// @Param id path string true "Event ID"
+// @Security BearerAuth
+// @Success 200 {object} domain.EventChain
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /events/{id}/chain [get]
+func (h *AuditHandler) GetEventChain(c *gin.Context) {
+	eventID, ok := parseEventID(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	userID := authUserID(c)
+
+	h.logger.Debug("processing event chain request",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.Stringer("event_id", eventID),
+		zap.Stringer("user_id", userID),
+	)
+
+	chain, err := h.service.GetEventChain(c.Request.Context(), eventID, userID)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, chain)
+}
+
+// GetRevertPayload handles GET /sessions/{sessionId}/events/{eventId}/revert-payload
+// @Summary Get the inverse of an edit event
+// @Description Returns the edit event that undoes an edit of the session: the recorded text.before and state.before become the after values. Send it to POST /events to record the undo. Only the session owner may read it; edits without a before value, or whose before value was redacted, answer 422.
+// @Tags Audit
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param eventId path string true "ID of the edit event to revert"
+// @Security BearerAuth
+// @Success 200 {object} domain.RevertPayload
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 422 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /sessions/{sessionId}/events/{eventId}/revert-payload [get]
+func (h *AuditHandler) GetRevertPayload(c *gin.Context) {
+	sessionID, ok := parseSessionID(c, c.Param("sessionId"))
+	if !ok {
+		return
+	}
+	eventID, ok := parseEventID(c, c.Param("eventId"))
+	if !ok {
+		return
+	}
+
+	userID := authUserID(c)
+	isShareToken := middleware.GetAuthTokenType(c) == middleware.TokenTypeShare
+
+	h.logger.Debug("processing revert payload request",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.Stringer("session_id", sessionID),
+		zap.Stringer("event_id", eventID),
+		zap.Stringer("user_id", userID),
+	)
+
+	payload, err := h.service.GetRevertPayload(c.Request.Context(), sessionID, eventID, userID, isShareToken)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, payload)
+}
+
+// GetEditDiff handles GET /sessions/{sessionId}/events/{eventId}/diff
+// @Summary Get the text diff of an edit event
+// @Description Returns the change an edit event made to the text, as spans of equal, inserted and deleted text ready to display. The diff computed when the event was recorded is used when present; older edits are diffed when read. Share tokens of the session may read it; events without text.before and text.after answer 422.
+// @Tags Audit
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param eventId path string true "ID of the edit event"
+// @Param share_token query string false "Share token for reviewer access"
+// @Security BearerAuth
+// @Success 200 {object} domain.EditDiff
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 422 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /sessions/{sessionId}/events/{eventId}/diff [get]
+func (h *AuditHandler) GetEditDiff(c *gin.Context) {
+	sessionID, ok := parseSessionID(c, c.Param("sessionId"))
+	if !ok {
+		return
+	}
+	eventID, ok := parseEventID(c, c.Param("eventId"))
+	if !ok {
+		return
+	}
+
+	userID := authUserID(c)
+	isShareToken := middleware.GetAuthTokenType(c) == middleware.TokenTypeShare
+
+	h.logger.Debug("processing edit diff request",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.Stringer("session_id", sessionID),
+		zap.Stringer("event_id", eventID),
+		zap.Stringer("user_id", userID),
+	)
+
+	diff, err := h.service.GetEditDiff(c.Request.Context(), sessionID, eventID, userID, isShareToken)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// GetEvents handles GET /sessions/{sessionId}/events
+// @Summary Query audit events for a session
+// @Description Retrieves audit log entries for a session filtered by type, user, time range and free-text search over details
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param type query string false "Comma-separated event types (e.g. edit,comment)"
+// @Param category query string false "Comma-separated categories: content, access, security, system or usage"
+// @Param severity query string false "Comma-separated severities: info, warning or critical"
+// @Param label query string false "Comma-separated labels; only events carrying one of them"
+// @Param userId query string false "Only events created by this user"
+// @Param from query string false "Only events at or after this RFC3339 timestamp"
+// @Param to query string false "Only events at or before this RFC3339 timestamp"
+// @Param q query string false "Free-text search over event details"
+// @Param correlationId query string false "Only events of this correlation ID"
+// @Param slideId query string false "Only events whose details reference this slide"
+// @Param shapeId query string false "Only events whose details reference this shape"
+// @Param commentId query string false "Only events whose details reference this comment"
+// @Param threadId query string false "Only events whose details reference this comment thread"
+// @Param limit query int false "Number of items to return (default: 50, max: 100)"
+// @Param offset query int false "Number of items to skip (default: 0)"
+// @Param cursor query string false "Opaque cursor from a previous response's nextCursor"
+// @Param share_token query string false "Share token for reviewer access"
+// @Param If-None-Match header string false "ETag of a previous response; answered with 304 when unchanged"
+// @Param Consistency-Token header string false "Consistency token returned by event creation; waits until the events it names are readable"
+// @Security BearerAuth
+// @Success 200 {object} domain.AuditResponse
+// @Success 304 "Not modified"
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Failure 503 {object} domain.APIError
+// @Router /sessions/{sessionId}/events [get]
+func (h *AuditHandler) GetEvents(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	sessionID, ok := parseSessionID(c, c.Param("sessionId"))
+	if !ok {
+		return
+	}
+
+	pagination, apiErr := parsePagination(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	filter, apiErr := parseEventFilter(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	ctx, ok := readContext(c)
+	if !ok {
+		return
+	}
+
+	userID := authUserID(c)
+	isShareToken := middleware.GetAuthTokenType(c) == middleware.TokenTypeShare
+
+	h.logger.Debug("processing audit events query",
+		zap.String("request_id", requestID),
+		zap.Stringer("session_id", sessionID),
+		zap.Stringer("user_id", userID),
+		zap.Strings("types", filter.Types),
+		zap.Bool("share_token", isShareToken),
+	)
+
+	response, err := h.service.QueryEvents(ctx, sessionID, userID, isShareToken, filter, pagination)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, response)
+}
+
+// GetSlideEvents handles GET /sessions/{sessionId}/slides/{slideId}/events
+// @Summary Query the audit events of a slide
+// @Description Retrieves the audit log entries of a session whose details reference the slide through slideId, newest first, read from an index. Takes the filters and pagination of the session events query; shapeId narrows the history to one shape of the slide.
+// @Tags Audit
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param slideId path string true "Slide ID as sent in details.slideId"
+// @Param shapeId query string false "Only events whose details reference this shape"
+// @Param type query string false "Comma-separated event types (e.g. edit,comment)"
+// @Param category query string false "Comma-separated categories: content, access, security, system or usage"
+// @Param severity query string false "Comma-separated severities: info, warning or critical"
+// @Param label query string false "Comma-separated labels; only events carrying one of them"
+// @Param userId query string false "Only events created by this user"
+// @Param from query string false "Only events at or after this RFC3339 timestamp"
+// @Param to query string false "Only events at or before this RFC3339 timestamp"
+// @Param limit query int false "Number of items to return (default: 50, max: 100)"
+// @Param offset query int false "Number of items to skip (default: 0)"
+// @Param cursor query string false "Opaque cursor from a previous response's nextCursor"
+// @Param share_token query string false "Share token for reviewer access"
+// @Param If-None-Match header string false "ETag of a previous response; answered with 304 when unchanged"
+// @Param Consistency-Token header string false "Consistency token returned by event creation; waits until the events it names are readable"
+// @Security BearerAuth
+// @Success 200 {object} domain.AuditResponse
+// @Success 304 "Not modified"
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Failure 503 {object} domain.APIError
+// @Router /sessions/{sessionId}/slides/{slideId}/events [get]
+func (h *AuditHandler) GetSlideEvents(c *gin.Context) {
+	sessionID, ok := parseSessionID(c, c.Param("sessionId"))
+	if !ok {
+		return
+	}
+
+	pagination, apiErr := parsePagination(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	filter, apiErr := parseEventFilter(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	ctx, ok := readContext(c)
+	if !ok {
+		return
+	}
+	// The slide of the path takes precedence over a slideId query parameter
+	filter.SlideID = c.Param("slideId")
+	if err := filter.Validate(); err != nil {
+		middleware.WriteError(c, domain.NewAPIError("bad_request", err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	userID := authUserID(c)
+	isShareToken := middleware.GetAuthTokenType(c) == middleware.TokenTypeShare
+
+	h.logger.Debug("processing slide events query",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.Stringer("session_id", sessionID),
+		zap.String("slide_id", filter.SlideID),
+		zap.Stringer("user_id", userID),
+		zap.Bool("share_token", isShareToken),
+	)
+
+	response, err := h.service.QueryEvents(ctx, sessionID, userID, isShareToken, filter, pagination)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, response)
+}
+
+// GetCommentEvents handles GET /sessions/{sessionId}/comments/events
+// @Summary Query the comment activity of a session
+// @Description Retrieves the comment lifecycle events of a session (comment_created, comment_edited, comment_resolved and comment_deleted), newest first, read from the comment and thread indexes. threadId and commentId narrow the activity to one thread or comment; type narrows it to some of the lifecycle types.
+// @Tags Audit
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param threadId query string false "Only events of this comment thread"
+// @Param commentId query string false "Only events of this comment"
+// @Param type query string false "Comma-separated comment lifecycle types (default: all four)"
+// @Param label query string false "Comma-separated labels; only events carrying one of them"
+// @Param userId query string false "Only events created by this user"
+// @Param from query string false "Only events at or after this RFC3339 timestamp"
+// @Param to query string false "Only events at or before this RFC3339 timestamp"
+// @Param limit query int false "Number of items to return (default: 50, max: 100)"
+// @Param offset query int false "Number of items to skip (default: 0)"
+// @Param cursor query string false "Opaque cursor from a previous response's nextCursor"
+// @Param share_token query string false "Share token for reviewer access"
+// @Param If-None-Match header string false "ETag of a previous response; answered with 304 when unchanged"
+// @Param Consistency-Token header string false "Consistency token returned by event creation; waits until the events it names are readable"
+// @Security BearerAuth
+// @Success 200 {object} domain.AuditResponse
+// @Success 304 "Not modified"
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Failure 503 {object} domain.APIError
+// @Router /sessions/{sessionId}/comments/events [get]
+func (h *AuditHandler) GetCommentEvents(c *gin.Context) {
+	sessionID, ok := parseSessionID(c, c.Param("sessionId"))
+	if !ok {
+		return
+	}
+
+	pagination, apiErr := parsePagination(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	filter, apiErr := parseEventFilter(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	ctx, ok := readContext(c)
+	if !ok {
+		return
+	}
+
+	// Only comment lifecycle events are served, all of them unless narrowed
+	for _, t := range filter.Types {
+		if !domain.IsCommentAction(t) {
+			middleware.WriteError(c, domain.NewAPIError("bad_request",
+				fmt.Sprintf("Event type %q is not a comment lifecycle type", t), http.StatusBadRequest))
+			return
+		}
+	}
+	if len(filter.Types) == 0 {
+		for _, action := range domain.CommentActions {
+			filter.Types = append(filter.Types, string(action))
+		}
+	}
+
+	userID := authUserID(c)
+	isShareToken := middleware.GetAuthTokenType(c) == middleware.TokenTypeShare
+
+	h.logger.Debug("processing comment events query",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.Stringer("session_id", sessionID),
+		zap.String("thread_id", filter.ThreadID),
+		zap.String("comment_id", filter.CommentID),
+		zap.Stringer("user_id", userID),
+		zap.Bool("share_token", isShareToken),
+	)
+
+	response, err := h.service.QueryEvents(ctx, sessionID, userID, isShareToken, filter, pagination)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, response)
+}
+
+// parsePagination reads limit, offset and cursor query parameters
+func parsePagination(c *gin.Context) (domain.PaginationParams, *domain.APIError) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 0 {
+		return domain.PaginationParams{}, domain.NewAPIError("bad_request", "Invalid limit parameter", http.StatusBadRequest)
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		return domain.PaginationParams{}, domain.NewAPIError("bad_request", "Invalid offset parameter", http.StatusBadRequest)
+	}
+
+	pagination := domain.PaginationParams{
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	// Cursor and offset describe different positions, so they cannot be combined
+	if value := c.Query("cursor"); value != "" {
+		if offset > 0 {
+			return domain.PaginationParams{}, domain.NewAPIError("bad_request", "The cursor and offset parameters cannot be combined", http.StatusBadRequest)
+		}
+
+		cursor, err := domain.DecodeCursor(value)
+		if err != nil {
+			return domain.PaginationParams{}, domain.NewAPIError("bad_request", "Invalid cursor parameter", http.StatusBadRequest)
+		}
+		pagination.Cursor = cursor
+	}
+
+	return pagination, nil
+}
+
+// parseEventFilter reads event filter query parameters
+func parseEventFilter(c *gin.Context) (domain.EventFilter, *domain.APIError) {
+	var filter domain.EventFilter
+
+	filter.Types = queryList(c, "type")
+	for _, category := range queryList(c, "category") {
+		filter.Categories = append(filter.Categories, domain.EventCategory(strings.ToLower(category)))
+	}
+	for _, severity := range queryList(c, "severity") {
+		filter.Severities = append(filter.Severities, domain.EntrySeverity(strings.ToLower(severity)))
+	}
+	for _, label := range queryList(c, "label") {
+		filter.Labels = append(filter.Labels, strings.ToLower(label))
+	}
+
+	filter.UserID = strings.TrimSpace(c.Query("userId"))
+	filter.Search = strings.TrimSpace(c.Query("q"))
+	filter.CorrelationID = c.Query("correlationId")
+	filter.SlideID = strings.TrimSpace(c.Query("slideId"))
+	filter.ShapeID = strings.TrimSpace(c.Query("shapeId"))
+	filter.CommentID = strings.TrimSpace(c.Query("commentId"))
+	filter.ThreadID = strings.TrimSpace(c.Query("threadId"))
+
+	if from := c.Query("from"); from != "" {
+		parsed, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return filter, domain.NewAPIError("bad_request", "Invalid from parameter, expected RFC3339 timestamp", http.StatusBadRequest)
+		}
+		filter.From = parsed.UTC()
+	}
+
+	if to := c.Query("to"); to != "" {
+		parsed, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return filter, domain.NewAPIError("bad_request", "Invalid to parameter, expected RFC3339 timestamp", http.StatusBadRequest)
+		}
+		filter.To = parsed.UTC()
+	}
+
+	if err := filter.Validate(); err != nil {
+		return filter, domain.NewAPIError("bad_request", err.Error(), http.StatusBadRequest)
+	}
+
+	return filter, nil
+}
+
+// queryList reads a query parameter that may be repeated (?type=a&type=b) or comma-separated (?type=a,b)
+func queryList(c *gin.Context, name string) []string {
+	var values []string
+	for _, value := range c.QueryArray(name) {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// parseActivityQuery reads activity query parameters; the range is validated by the service
+func parseActivityQuery(c *gin.Context) (domain.ActivityQuery, *domain.APIError) {
+	query := domain.ActivityQuery{
+		Granularity: domain.ActivityGranularity(strings.ToLower(strings.TrimSpace(c.Query("granularity")))),
+		UserID:      strings.TrimSpace(c.Query("userId")),
+	}
+
+	if from := c.Query("from"); from != "" {
+		parsed, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return query, domain.NewAPIError("bad_request", "Invalid from parameter, expected RFC3339 timestamp", http.StatusBadRequest)
+		}
+		query.From = parsed.UTC()
+	}
+
+	if to := c.Query("to"); to != "" {
+		parsed, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return query, domain.NewAPIError("bad_request", "Invalid to parameter, expected RFC3339 timestamp", http.StatusBadRequest)
+		}
+		query.To = parsed.UTC()
+	}
+
+	return query, nil
+}
+
+// readContext returns the context of a history read: the request context, carrying the
+// consistency token sent in the Consistency-Token header. It answers 400 for invalid tokens.
+func readContext(c *gin.Context) (context.Context, bool) {
+	ctx := c.Request.Context()
+	value := c.GetHeader(consistencyTokenHeader)
+	if value == "" {
+		return ctx, true
+	}
+
+	token, err := domain.DecodeConsistencyToken(value)
+	if err != nil {
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return nil, false
+	}
+	return service.WithConsistencyToken(ctx, token), true
+}
+
+// parseSessionID parses a session ID parameter, answering 400 when it is not a UUID
+func parseSessionID(c *gin.Context, value string) (domain.SessionID, bool) {
+	sessionID, err := domain.ParseSessionID(value)
+	if err != nil {
+		middleware.WriteError(c, domain.NewAPIError("bad_request", "Invalid session ID format", http.StatusBadRequest))
+		return "", false
+	}
+	return sessionID, true
+}
+
+// parseEventID parses an event ID parameter, answering 400 when it is not a UUID
+func parseEventID(c *gin.Context, value string) (domain.EventID, bool) {
+	eventID, err := domain.ParseEventID(value)
+	if err != nil {
+		middleware.WriteError(c, domain.NewAPIError("invalid_event_id", "Event ID must be a UUID", http.StatusBadRequest))
+		return "", false
+	}
+	return eventID, true
+}
+
+// authUserID returns the ID of the authenticated caller
+func authUserID(c *gin.Context) domain.UserID {
+	return domain.UserID(middleware.GetAuthUserID(c))
 }
@@ -3,40 +3,177 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"audit-service/internal/domain"
 	"audit-service/internal/middleware"
+	"audit-service/pkg/clock"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
-// MockAuditService implements the AuditService interface for testing
+// MockAuditService implements the Reader and Writer interfaces for testing; IDs are recorded
+// as strings
 type MockAuditService struct {
 	mock.Mock
 }
 
-func (m *MockAuditService) GetAuditLogs(ctx context.Context, sessionID, userID string, isShareToken bool, pagination domain.PaginationParams) (*domain.AuditResponse, error) {
-	args := m.Called(ctx, sessionID, userID, isShareToken, pagination)
+func (m *MockAuditService) GetAuditLogs(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, pagination domain.PaginationParams) (*domain.AuditResponse, error) {
+	args := m.Called(ctx, string(sessionID), string(userID), isShareToken, pagination)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.AuditResponse), args.Error(1)
 }
 
+func (m *MockAuditService) QueryEvents(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, filter domain.EventFilter, pagination domain.PaginationParams) (*domain.AuditResponse, error) {
+	args := m.Called(ctx, string(sessionID), string(userID), isShareToken, filter, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AuditResponse), args.Error(1)
+}
+
+func (m *MockAuditService) QueryAllEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, pagination domain.PaginationParams) (*domain.AuditResponse, error) {
+	args := m.Called(ctx, string(sessionID), filter, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AuditResponse), args.Error(1)
+}
+
+func (m *MockAuditService) CreateEvent(ctx context.Context, entry domain.AuditEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditService) CreateEvents(ctx context.Context, entries []domain.AuditEntry) error {
+	args := m.Called(ctx, entries)
+	return args.Error(0)
+}
+
+func (m *MockAuditService) GetSessionStats(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool) (*domain.SessionStats, error) {
+	args := m.Called(ctx, string(sessionID), string(userID), isShareToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SessionStats), args.Error(1)
+}
+
+func (m *MockAuditService) GetSessionActivity(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, query domain.ActivityQuery) (*domain.SessionActivity, error) {
+	args := m.Called(ctx, string(sessionID), string(userID), isShareToken, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SessionActivity), args.Error(1)
+}
+
+func (m *MockAuditService) VerifyChain(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool) (*domain.ChainVerification, error) {
+	args := m.Called(ctx, string(sessionID), string(userID), isShareToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ChainVerification), args.Error(1)
+}
+
+func (m *MockAuditService) GetEventChain(ctx context.Context, eventID domain.EventID, userID domain.UserID) (*domain.EventChain, error) {
+	args := m.Called(ctx, string(eventID), string(userID))
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.EventChain), args.Error(1)
+}
+
+func (m *MockAuditService) GetSessionHeatmap(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, query domain.HeatmapQuery) (*domain.SessionHeatmap, error) {
+	args := m.Called(ctx, string(sessionID), string(userID), isShareToken, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SessionHeatmap), args.Error(1)
+}
+
+func (m *MockAuditService) GetSessionContributors(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, query domain.ContributorQuery) (*domain.SessionContributors, error) {
+	args := m.Called(ctx, string(sessionID), string(userID), isShareToken, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SessionContributors), args.Error(1)
+}
+
+func (m *MockAuditService) GetTMLeverage(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, query domain.TMLeverageQuery) (*domain.SessionTMLeverage, error) {
+	args := m.Called(ctx, string(sessionID), string(userID), isShareToken, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SessionTMLeverage), args.Error(1)
+}
+
+func (m *MockAuditService) GetMTCosts(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, query domain.MTCostQuery) (*domain.SessionMTCosts, error) {
+	args := m.Called(ctx, string(sessionID), string(userID), isShareToken, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SessionMTCosts), args.Error(1)
+}
+
+func (m *MockAuditService) GetSessionTimeline(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, query domain.TimelineQuery) (*domain.SessionTimeline, error) {
+	args := m.Called(ctx, string(sessionID), string(userID), isShareToken, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SessionTimeline), args.Error(1)
+}
+
+func (m *MockAuditService) GetAccessReview(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, query domain.AccessReviewQuery) (*domain.AccessReview, error) {
+	args := m.Called(ctx, string(sessionID), string(userID), query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AccessReview), args.Error(1)
+}
+
+func (m *MockAuditService) GetRevertPayload(ctx context.Context, sessionID domain.SessionID, eventID domain.EventID, userID domain.UserID, isShareToken bool) (*domain.RevertPayload, error) {
+	args := m.Called(ctx, string(sessionID), string(eventID), string(userID), isShareToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RevertPayload), args.Error(1)
+}
+
+func (m *MockAuditService) GetEditDiff(ctx context.Context, sessionID domain.SessionID, eventID domain.EventID, userID domain.UserID, isShareToken bool) (*domain.EditDiff, error) {
+	args := m.Called(ctx, string(sessionID), string(eventID), string(userID), isShareToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.EditDiff), args.Error(1)
+}
+
+func (m *MockAuditService) ExportEvents(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, filter domain.EventFilter, maxRows int, emit func(entries []domain.AuditEntry, total int) error) error {
+	args := m.Called(ctx, string(sessionID), string(userID), isShareToken, filter, maxRows, emit)
+	return args.Error(0)
+}
+
+func (m *MockAuditService) AuthorizeSession(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool) error {
+	args := m.Called(ctx, string(sessionID), string(userID), isShareToken)
+	return args.Error(0)
+}
+
 func TestAuditHandler_GetHistory_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	// Setup mock service
 	mockService := new(MockAuditService)
 	logger := zap.NewNop()
-	handler := NewAuditHandler(mockService, logger)
+	handler := NewAuditHandler(mockService, clock.New(), logger)
 
 	// Use valid UUID for session ID
 	sessionID := "550e8400-e29b-41d4-a716-446655440000"
@@ -100,7 +237,7 @@ func TestAuditHandler_GetHistory_InvalidSessionID(t *testing.T) {
 
 	mockService := new(MockAuditService)
 	logger := zap.NewNop()
-	handler := NewAuditHandler(mockService, logger)
+	handler := NewAuditHandler(mockService, clock.New(), logger)
 
 	// Setup request with invalid session ID
 	w := httptest.NewRecorder()
@@ -129,7 +266,7 @@ func TestAuditHandler_GetHistory_ServiceError(t *testing.T) {
 
 	mockService := new(MockAuditService)
 	logger := zap.NewNop()
-	handler := NewAuditHandler(mockService, logger)
+	handler := NewAuditHandler(mockService, clock.New(), logger)
 
 	// Setup mock expectation with error
 	mockService.On("GetAuditLogs",
@@ -168,7 +305,7 @@ func TestAuditHandler_GetHistory_WithPagination(t *testing.T) {
 
 	mockService := new(MockAuditService)
 	logger := zap.NewNop()
-	handler := NewAuditHandler(mockService, logger)
+	handler := NewAuditHandler(mockService, clock.New(), logger)
 
 	expectedResponse := &domain.AuditResponse{
 		TotalCount: 100,
@@ -202,48 +339,1332 @@ func TestAuditHandler_GetHistory_WithPagination(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
-func TestIsValidUUID(t *testing.T) {
+func TestAuditHandler_GetHistory_WithCursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	cursor := &domain.Cursor{
+		Timestamp: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		ID:        "entry-50",
+	}
+
+	mockService := new(MockAuditService)
+	handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+	mockService.On("GetAuditLogs",
+		mock.Anything,
+		sessionID,
+		"user-456",
+		false,
+		domain.PaginationParams{Limit: 25, Offset: 0, Cursor: cursor},
+	).Return(&domain.AuditResponse{TotalCount: 100, Items: []domain.AuditEntry{}, NextCursor: "next"}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history?limit=25&cursor="+cursor.Encode(), nil)
+	c.Set(middleware.AuthUserIDKey, "user-456")
+	c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+	c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+	handler.GetHistory(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response domain.AuditResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "next", response.NextCursor)
+	mockService.AssertExpectations(t)
+}
+
+func TestAuditHandler_GetHistory_InvalidCursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	validCursor := (&domain.Cursor{Timestamp: time.Now(), ID: "entry-1"}).Encode()
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "malformed cursor", query: "cursor=not-a-cursor"},
+		{name: "cursor with offset", query: "offset=10&cursor=" + validCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history?"+tt.query, nil)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetHistory(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockService.AssertNotCalled(t, "GetAuditLogs")
+		})
+	}
+}
+
+func TestAuditHandler_GetHistory_ConsistencyToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	token := &domain.ConsistencyToken{EventID: "550e8400-e29b-41d4-a716-446655440001", IssuedAt: time.Now()}
+
+	performGetHistory := func(handler *AuditHandler, consistencyToken string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history", nil)
+		c.Request.Header.Set(consistencyTokenHeader, consistencyToken)
+		c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+		handler.GetHistory(c)
+		return w
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		mockService := new(MockAuditService)
+		mockService.On("GetAuditLogs", mock.Anything, sessionID, "", false, mock.Anything).
+			Return(&domain.AuditResponse{Items: []domain.AuditEntry{}}, nil)
+		handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+		w := performGetHistory(handler, token.Encode())
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		mockService := new(MockAuditService)
+		handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+		w := performGetHistory(handler, "not-a-token")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid_consistency_token")
+		mockService.AssertNotCalled(t, "GetAuditLogs")
+	})
+
+	t.Run("write_not_visible", func(t *testing.T) {
+		mockService := new(MockAuditService)
+		mockService.On("GetAuditLogs", mock.Anything, sessionID, "", false, mock.Anything).
+			Return(nil, domain.ErrWriteNotVisible)
+		handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+		w := performGetHistory(handler, token.Encode())
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), "write_not_visible")
+	})
+}
+
+func TestAuditHandler_GetHistory_ETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	history := &domain.AuditResponse{
+		TotalCount: 1,
+		Items: []domain.AuditEntry{
+			{ID: "entry-1", SessionID: sessionID, UserID: "user-456", Type: string(domain.ActionEdit), Timestamp: testNow},
+		},
+	}
+	changed := &domain.AuditResponse{
+		TotalCount: 2,
+		Items: []domain.AuditEntry{
+			{ID: "entry-2", SessionID: sessionID, UserID: "user-456", Type: string(domain.ActionView), Timestamp: testNow.Add(time.Second)},
+			history.Items[0],
+		},
+	}
+
+	mockService := new(MockAuditService)
+	mockService.On("GetAuditLogs", mock.Anything, sessionID, "user-456", false, mock.Anything).Return(history, nil).Twice()
+	mockService.On("GetAuditLogs", mock.Anything, sessionID, "user-456", false, mock.Anything).Return(changed, nil).Once()
+	handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/history", nil)
+		if ifNoneMatch != "" {
+			c.Request.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		c.Set(middleware.AuthUserIDKey, "user-456")
+		c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+		c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+		handler.GetHistory(c)
+		return w
+	}
+
+	first := get("")
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	assert.True(t, strings.HasPrefix(etag, `W/"`))
+	assert.Equal(t, "private, no-cache", first.Header().Get("Cache-Control"))
+
+	// Unchanged history is answered without a body
+	notModified := get(`"other", ` + etag)
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+	assert.Equal(t, etag, notModified.Header().Get("ETag"))
+
+	// A new event changes the tag
+	updated := get(etag)
+	assert.Equal(t, http.StatusOK, updated.Code)
+	assert.NotEqual(t, etag, updated.Header().Get("ETag"))
+	mockService.AssertExpectations(t)
+}
+
+func TestETagMatches(t *testing.T) {
+	etag := `W/"abc"`
+
+	assert.True(t, etagMatches(`W/"abc"`, etag))
+	// Weak comparison ignores the W/ prefix, which compression adds to strong tags
+	assert.True(t, etagMatches(`"abc"`, etag))
+	assert.True(t, etagMatches(`"x", W/"abc"`, etag))
+	assert.True(t, etagMatches(`*`, etag))
+	assert.False(t, etagMatches(``, etag))
+	assert.False(t, etagMatches(`W/"abd"`, etag))
+}
+
+func TestAuditHandler_GetEvents_ParsesFilters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockAuditService)
+	handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+
+	expectedFilter := domain.EventFilter{
+		Types:  []string{"edit", "comment", "merge"},
+		UserID: "user-789",
+		From:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2024, 1, 31, 21, 0, 0, 0, time.UTC),
+		Search: "title",
+	}
+
+	mockService.On("QueryEvents",
+		mock.Anything,
+		sessionID,
+		"user-456",
+		false,
+		expectedFilter,
+		domain.PaginationParams{Limit: 10, Offset: 0},
+	).Return(&domain.AuditResponse{TotalCount: 0, Items: []domain.AuditEntry{}}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+
+		"/events?type=edit,comment&type=merge&userId=user-789&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00%2B03:00&q=title&limit=10", nil)
+	c.Set(middleware.AuthUserIDKey, "user-456")
+	c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+	c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+	handler.GetEvents(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAuditHandler_GetEvents_InvalidParameters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
 	tests := []struct {
 		name  string
-		uuid  string
-		valid bool
+		query string
+	}{
+		{name: "invalid from", query: "from=yesterday"},
+		{name: "invalid to", query: "to=2024-13-01"},
+		{name: "inverted range", query: "from=2024-02-01T00:00:00Z&to=2024-01-01T00:00:00Z"},
+		{name: "invalid limit", query: "limit=abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/events?"+tt.query, nil)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetEvents(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockService.AssertNotCalled(t, "QueryEvents")
+		})
+	}
+}
+
+func TestAuditHandler_GetSlideEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+
+	tests := []struct {
+		name           string
+		slideID        string
+		query          string
+		setupMock      func(*MockAuditService)
+		expectedStatus int
 	}{
 		{
-			name:  "valid UUID",
-			uuid:  "550e8400-e29b-41d4-a716-446655440000",
-			valid: true,
+			name:    "success",
+			slideID: "slide-3",
+			// The slide of the path wins over the query
+			query: "?shapeId=shape-12&slideId=slide-9&type=edit&limit=10",
+			setupMock: func(m *MockAuditService) {
+				filter := domain.EventFilter{Types: []string{"edit"}, SlideID: "slide-3", ShapeID: "shape-12"}
+				m.On("QueryEvents", mock.Anything, sessionID, "user-456", false, filter, domain.PaginationParams{Limit: 10}).
+					Return(&domain.AuditResponse{TotalCount: 1, Items: []domain.AuditEntry{{ID: "event-1", SlideID: "slide-3"}}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "slide ID too long",
+			slideID:        strings.Repeat("s", domain.MaxSlideRefLength+1),
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
 		},
 		{
-			name:  "valid UUID with uppercase",
-			uuid:  "550E8400-E29B-41D4-A716-446655440000",
-			valid: true,
+			name:    "forbidden",
+			slideID: "slide-3",
+			setupMock: func(m *MockAuditService) {
+				m.On("QueryEvents", mock.Anything, sessionID, "user-456", false, domain.EventFilter{SlideID: "slide-3"}, domain.PaginationParams{Limit: 50}).
+					Return(nil, domain.ErrForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			tt.setupMock(mockService)
+			handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/slides/"+tt.slideID+"/events"+tt.query, nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}, {Key: "slideId", Value: tt.slideID}}
+
+			handler.GetSlideEvents(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetRevertPayload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	eventID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+
+	tests := []struct {
+		name           string
+		eventID        string
+		setupMock      func(*MockAuditService)
+		expectedStatus int
+		expectedCode   string
+	}{
 		{
-			name:  "invalid length",
-			uuid:  "550e8400-e29b-41d4-a716",
-			valid: false,
+			name:    "success",
+			eventID: eventID,
+			setupMock: func(m *MockAuditService) {
+				m.On("GetRevertPayload", mock.Anything, sessionID, eventID, "user-456", false).
+					Return(&domain.RevertPayload{SessionID: sessionID, Type: "edit", SchemaVersion: 2, ParentEventID: eventID,
+						Details: json.RawMessage(`{"action":"revert","text":{"before":"Hello World","after":"Hello"}}`)}, nil)
+			},
+			expectedStatus: http.StatusOK,
 		},
 		{
-			name:  "missing hyphens",
-			uuid:  "550e8400e29b41d4a716446655440000",
-			valid: false,
+			name:           "invalid event ID",
+			eventID:        "event-1",
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_event_id",
 		},
 		{
-			name:  "invalid characters",
-			uuid:  "550e8400-e29b-41d4-a716-44665544000g",
-			valid: false,
+			name:    "not reversible",
+			eventID: eventID,
+			setupMock: func(m *MockAuditService) {
+				m.On("GetRevertPayload", mock.Anything, sessionID, eventID, "user-456", false).
+					Return(nil, fmt.Errorf("%w: only edit events can be reverted", domain.ErrNotReversible))
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "not_reversible",
 		},
 		{
-			name:  "empty string",
-			uuid:  "",
-			valid: false,
+			name:    "event not found",
+			eventID: eventID,
+			setupMock: func(m *MockAuditService) {
+				m.On("GetRevertPayload", mock.Anything, sessionID, eventID, "user-456", false).Return(nil, domain.ErrEventNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := isValidUUID(tt.uuid)
-			assert.Equal(t, tt.valid, result)
+			mockService := new(MockAuditService)
+			tt.setupMock(mockService)
+			handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/events/"+tt.eventID+"/revert-payload", nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}, {Key: "eventId", Value: tt.eventID}}
+
+			handler.GetRevertPayload(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedCode != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedCode)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetEditDiff(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	eventID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+
+	tests := []struct {
+		name           string
+		eventID        string
+		tokenType      string
+		setupMock      func(*MockAuditService)
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:      "success",
+			eventID:   eventID,
+			tokenType: middleware.TokenTypeJWT,
+			setupMock: func(m *MockAuditService) {
+				m.On("GetEditDiff", mock.Anything, sessionID, eventID, "user-456", false).
+					Return(&domain.EditDiff{EventID: eventID, SessionID: sessionID, Stored: true, Inserted: 6,
+						Spans: []domain.RenderedDiffSpan{{Op: domain.DiffEqual, Text: "Hello "}, {Op: domain.DiffInsert, Text: "World!"}}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "share token",
+			eventID:   eventID,
+			tokenType: middleware.TokenTypeShare,
+			setupMock: func(m *MockAuditService) {
+				m.On("GetEditDiff", mock.Anything, sessionID, eventID, "user-456", true).
+					Return(&domain.EditDiff{EventID: eventID, SessionID: sessionID, Spans: []domain.RenderedDiffSpan{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid event ID",
+			eventID:        "event-1",
+			tokenType:      middleware.TokenTypeJWT,
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_event_id",
+		},
+		{
+			name:      "no text change",
+			eventID:   eventID,
+			tokenType: middleware.TokenTypeJWT,
+			setupMock: func(m *MockAuditService) {
+				m.On("GetEditDiff", mock.Anything, sessionID, eventID, "user-456", false).
+					Return(nil, fmt.Errorf("%w: only edit events record text changes", domain.ErrNoTextChange))
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "no_text_change",
+		},
+		{
+			name:      "event not found",
+			eventID:   eventID,
+			tokenType: middleware.TokenTypeJWT,
+			setupMock: func(m *MockAuditService) {
+				m.On("GetEditDiff", mock.Anything, sessionID, eventID, "user-456", false).Return(nil, domain.ErrEventNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			tt.setupMock(mockService)
+			handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/events/"+tt.eventID+"/diff", nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, tt.tokenType)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}, {Key: "eventId", Value: tt.eventID}}
+
+			handler.GetEditDiff(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedCode != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedCode)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetCommentEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	lifecycle := []string{"comment_created", "comment_edited", "comment_resolved", "comment_deleted"}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*MockAuditService)
+		expectedStatus int
+	}{
+		{
+			name:  "thread activity",
+			query: "?threadId=thread-2&limit=20",
+			setupMock: func(m *MockAuditService) {
+				filter := domain.EventFilter{Types: lifecycle, ThreadID: "thread-2"}
+				m.On("QueryEvents", mock.Anything, sessionID, "user-456", false, filter, domain.PaginationParams{Limit: 20}).
+					Return(&domain.AuditResponse{TotalCount: 1, Items: []domain.AuditEntry{{ID: "event-1", ThreadID: "thread-2"}}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "narrowed to resolutions of a comment",
+			query: "?commentId=comment-7&type=comment_resolved",
+			setupMock: func(m *MockAuditService) {
+				filter := domain.EventFilter{Types: []string{"comment_resolved"}, CommentID: "comment-7"}
+				m.On("QueryEvents", mock.Anything, sessionID, "user-456", false, filter, domain.PaginationParams{Limit: 50}).
+					Return(&domain.AuditResponse{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "other event types",
+			query:          "?type=comment_created,edit",
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "thread ID too long",
+			query:          "?threadId=" + strings.Repeat("t", domain.MaxCommentRefLength+1),
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			tt.setupMock(mockService)
+			handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/comments/events"+tt.query, nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetCommentEvents(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	first := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		sessionID      string
+		setupMock      func(*MockAuditService)
+		expectedStatus int
+	}{
+		{
+			name:      "success",
+			sessionID: sessionID,
+			setupMock: func(m *MockAuditService) {
+				m.On("GetSessionStats", mock.Anything, sessionID, "user-456", false).Return(&domain.SessionStats{
+					SessionID:     sessionID,
+					TotalEvents:   5,
+					Contributors:  2,
+					FirstActivity: &first,
+					ByType:        map[string]int{"edit": 4, "comment": 1},
+					EditsPerSlide: map[string]int{"slide-1": 4},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid session id",
+			sessionID:      "not-a-uuid",
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "forbidden",
+			sessionID: sessionID,
+			setupMock: func(m *MockAuditService) {
+				m.On("GetSessionStats", mock.Anything, sessionID, "user-456", false).Return(nil, domain.ErrForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			tt.setupMock(mockService)
+			handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+tt.sessionID+"/stats", nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: tt.sessionID}}
+
+			handler.GetStats(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var stats domain.SessionStats
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+				assert.Equal(t, 5, stats.TotalEvents)
+				assert.Equal(t, 4, stats.ByType["edit"])
+				assert.Equal(t, 4, stats.EditsPerSlide["slide-1"])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetHeatmap(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*MockAuditService)
+		expectedStatus int
+	}{
+		{
+			name:  "success",
+			query: "?from=2024-03-01T00:00:00Z&userId=user-789",
+			setupMock: func(m *MockAuditService) {
+				query := domain.HeatmapQuery{From: from, UserID: "user-789"}
+				m.On("GetSessionHeatmap", mock.Anything, sessionID, "user-456", false, query).Return(&domain.SessionHeatmap{
+					SessionID:   sessionID,
+					From:        from,
+					To:          from.Add(24 * time.Hour),
+					TotalEvents: 4,
+					Cells:       []domain.HeatmapCell{{SlideID: "slide-1", SlideIndex: 1, Hour: from, EventCount: 4}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid to",
+			query:          "?to=tomorrow",
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "range too long",
+			setupMock: func(m *MockAuditService) {
+				m.On("GetSessionHeatmap", mock.Anything, sessionID, "user-456", false, domain.HeatmapQuery{}).
+					Return(nil, domain.ErrInvalidActivityQuery)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			tt.setupMock(mockService)
+			handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/heatmap"+tt.query, nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetHeatmap(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetContributors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	first := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	report := &domain.SessionContributors{
+		SessionID: sessionID,
+		Contributors: []domain.ContributorStats{
+			{UserID: "user-789", Edits: 5, Comments: 2, Merges: 1, TotalEvents: 9, FirstActivity: first, LastActivity: first.Add(time.Hour)},
+			{UserID: "=cmd", Edits: 1, TotalEvents: 1, FirstActivity: first, LastActivity: first},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*MockAuditService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "json",
+			setupMock: func(m *MockAuditService) {
+				m.On("GetSessionContributors", mock.Anything, sessionID, "user-456", false, domain.ContributorQuery{}).Return(report, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"totalEvents":9`,
+		},
+		{
+			name:  "csv",
+			query: "?format=csv&from=2024-03-01T00:00:00Z",
+			setupMock: func(m *MockAuditService) {
+				query := domain.ContributorQuery{From: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+				m.On("GetSessionContributors", mock.Anything, sessionID, "user-456", false, query).Return(report, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: "user_id,edits,comments,merges,total_events,first_activity,last_activity\n" +
+				"user-789,5,2,1,9,2024-03-01T09:00:00Z,2024-03-01T10:00:00Z\n" +
+				"'=cmd,1,0,0,1,2024-03-01T09:00:00Z,2024-03-01T09:00:00Z\n",
+		},
+		{
+			name:           "unknown format",
+			query:          "?format=xlsx",
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid from",
+			query:          "?from=yesterday",
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "session not found",
+			setupMock: func(m *MockAuditService) {
+				m.On("GetSessionContributors", mock.Anything, sessionID, "user-456", false, domain.ContributorQuery{}).
+					Return(nil, domain.ErrSessionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			tt.setupMock(mockService)
+			handler := NewAuditHandler(mockService, clock.NewFakeClock(time.Date(2024, 4, 1, 8, 0, 0, 0, time.UTC)), zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/contributors"+tt.query, nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetContributors(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+				assert.Contains(t, w.Header().Get("Content-Disposition"), "contributors-"+sessionID+"-20240401T080000Z.csv")
+			} else if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetAccessReview(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	first := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	review := &domain.AccessReview{
+		SessionID:   sessionID,
+		GeneratedAt: time.Date(2024, 4, 1, 8, 0, 0, 0, time.UTC),
+		TotalViews:  4,
+		Users:       2,
+		Accessors: []domain.AccessReviewEntry{
+			{UserID: "reviewer-1", AccessMethod: domain.AccessShareToken, ShareTokenID: "share-91c2", Views: 1,
+				FirstAccess: first.Add(time.Hour), LastAccess: first.Add(time.Hour), IPAddresses: []string{"198.51.100.4"}},
+			{UserID: "user-456", AccessMethod: domain.AccessJWT, Views: 3, FirstAccess: first, LastAccess: first,
+				IPAddresses: []string{"203.0.113.7", "203.0.113.9"}},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		tokenType      string
+		setupMock      func(*MockAuditService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "json",
+			setupMock: func(m *MockAuditService) {
+				m.On("GetAccessReview", mock.Anything, sessionID, "user-456", domain.AccessReviewQuery{}).Return(review, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"accessMethod":"share_token"`,
+		},
+		{
+			name:  "csv",
+			query: "?format=csv&from=2024-03-01T00:00:00Z",
+			setupMock: func(m *MockAuditService) {
+				query := domain.AccessReviewQuery{From: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+				m.On("GetAccessReview", mock.Anything, sessionID, "user-456", query).Return(review, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: "user_id,access_method,share_token_id,views,first_access,last_access,ip_addresses\n" +
+				"reviewer-1,share_token,share-91c2,1,2024-03-01T10:00:00Z,2024-03-01T10:00:00Z,198.51.100.4\n" +
+				"user-456,jwt,,3,2024-03-01T09:00:00Z,2024-03-01T09:00:00Z,203.0.113.7 203.0.113.9\n",
+		},
+		{
+			name:           "share token rejected",
+			tokenType:      middleware.TokenTypeShare,
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "unknown format",
+			query:          "?format=pdf",
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "not the owner",
+			setupMock: func(m *MockAuditService) {
+				m.On("GetAccessReview", mock.Anything, sessionID, "user-456", domain.AccessReviewQuery{}).Return(nil, domain.ErrForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			tt.setupMock(mockService)
+			handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+			tokenType := tt.tokenType
+			if tokenType == "" {
+				tokenType = middleware.TokenTypeJWT
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/access-review"+tt.query, nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, tokenType)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetAccessReview(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+				assert.Contains(t, w.Header().Get("Content-Disposition"), "access-review-"+sessionID+"-20240401T080000Z.csv")
+			} else if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetTMLeverage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	report := domain.NewTMLeverageTally(sessionID, domain.TMLeverageQuery{}).Report()
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*MockAuditService)
+		expectedStatus int
+	}{
+		{
+			name:  "report",
+			query: "?from=2024-03-01T00:00:00Z",
+			setupMock: func(m *MockAuditService) {
+				query := domain.TMLeverageQuery{From: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+				m.On("GetTMLeverage", mock.Anything, sessionID, "user-456", false, query).Return(report, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid to",
+			query:          "?to=tomorrow",
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "forbidden",
+			setupMock: func(m *MockAuditService) {
+				m.On("GetTMLeverage", mock.Anything, sessionID, "user-456", false, domain.TMLeverageQuery{}).Return(nil, domain.ErrForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			tt.setupMock(mockService)
+			handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/tm/leverage"+tt.query, nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetTMLeverage(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus == http.StatusOK {
+				var body domain.SessionTMLeverage
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Len(t, body.Bands, 7)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetMTCosts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	tally := domain.NewMTCostTally(sessionID, domain.MTCostQuery{})
+	tally.Add(domain.AuditEntry{Type: "mt_request", Details: json.RawMessage(`{"provider":"deepl","sourceCharacters":1200,"costEstimate":0.024}`)})
+	report := tally.Report()
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*MockAuditService)
+		expectedStatus int
+	}{
+		{
+			name:  "rollup",
+			query: "?to=2024-03-31T23:59:59Z",
+			setupMock: func(m *MockAuditService) {
+				query := domain.MTCostQuery{To: time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)}
+				m.On("GetMTCosts", mock.Anything, sessionID, "user-456", false, query).Return(report, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid from",
+			query:          "?from=last-week",
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "session not found",
+			setupMock: func(m *MockAuditService) {
+				m.On("GetMTCosts", mock.Anything, sessionID, "user-456", false, domain.MTCostQuery{}).Return(nil, domain.ErrSessionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			tt.setupMock(mockService)
+			handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/mt/costs"+tt.query, nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetMTCosts(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus == http.StatusOK {
+				var body domain.SessionMTCosts
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				require.Len(t, body.Providers, 1)
+				assert.Equal(t, []domain.MTCurrencyCost{{Currency: "USD", CostEstimate: 0.024}}, body.Costs)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetTimeline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	builder := domain.NewTimelineBuilder(sessionID, domain.TimelineQuery{})
+	builder.Add(domain.AuditEntry{ID: "audit-001", Type: "view", UserID: "user-456", Timestamp: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)})
+	timeline := builder.Report()
+
+	tests := []struct {
+		name           string
+		query          string
+		isShareToken   bool
+		setupMock      func(*MockAuditService)
+		expectedStatus int
+	}{
+		{
+			name:  "timeline",
+			query: "?from=2024-03-01T00:00:00Z&gapSeconds=600",
+			setupMock: func(m *MockAuditService) {
+				query := domain.TimelineQuery{From: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Gap: 10 * time.Minute}
+				m.On("GetSessionTimeline", mock.Anything, sessionID, "user-456", false, query).Return(timeline, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:         "share token",
+			isShareToken: true,
+			setupMock: func(m *MockAuditService) {
+				m.On("GetSessionTimeline", mock.Anything, sessionID, "user-456", true, domain.TimelineQuery{}).Return(timeline, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid gap",
+			query:          "?gapSeconds=0",
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "gap out of range",
+			query: "?gapSeconds=100000",
+			setupMock: func(m *MockAuditService) {
+				m.On("GetSessionTimeline", mock.Anything, sessionID, "user-456", false, domain.TimelineQuery{Gap: 100000 * time.Second}).
+					Return(nil, domain.ErrInvalidFilter)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "session not found",
+			setupMock: func(m *MockAuditService) {
+				m.On("GetSessionTimeline", mock.Anything, sessionID, "user-456", false, domain.TimelineQuery{}).Return(nil, domain.ErrSessionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			tt.setupMock(mockService)
+			handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/timeline"+tt.query, nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			if tt.isShareToken {
+				c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeShare)
+			}
+			c.Params = []gin.Param{{Key: "sessionId", Value: sessionID}}
+
+			handler.GetTimeline(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus == http.StatusOK {
+				var body domain.SessionTimeline
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				require.Len(t, body.Groups, 1)
+				assert.Equal(t, "Session viewed", body.Groups[0].Summary)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetActivity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		sessionID      string
+		query          string
+		setupMock      func(*MockAuditService)
+		expectedStatus int
+	}{
+		{
+			name:      "success",
+			sessionID: sessionID,
+			query:     "?granularity=Hour&from=2024-03-01T00:00:00Z&userId=user-789",
+			setupMock: func(m *MockAuditService) {
+				query := domain.ActivityQuery{Granularity: domain.ActivityHour, From: from, UserID: "user-789"}
+				m.On("GetSessionActivity", mock.Anything, sessionID, "user-456", false, query).Return(&domain.SessionActivity{
+					SessionID:   sessionID,
+					Granularity: domain.ActivityHour,
+					From:        from,
+					To:          from.Add(3 * time.Hour),
+					Buckets: []domain.ActivityBucket{
+						{Start: from, UserID: "user-789", EventCount: 4, ByType: map[string]int{"edit": 4}},
+					},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid session id",
+			sessionID:      "not-a-uuid",
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid from",
+			sessionID:      sessionID,
+			query:          "?from=yesterday",
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "invalid granularity",
+			sessionID: sessionID,
+			query:     "?granularity=week",
+			setupMock: func(m *MockAuditService) {
+				m.On("GetSessionActivity", mock.Anything, sessionID, "user-456", false, domain.ActivityQuery{Granularity: "week"}).
+					Return(nil, domain.ErrInvalidActivityQuery)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			tt.setupMock(mockService)
+			handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+tt.sessionID+"/activity"+tt.query, nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: tt.sessionID}}
+
+			handler.GetActivity(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var activity domain.SessionActivity
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &activity))
+				require.Len(t, activity.Buckets, 1)
+				assert.Equal(t, 4, activity.Buckets[0].ByType["edit"])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_VerifyChain(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+
+	tests := []struct {
+		name           string
+		sessionID      string
+		setupMock      func(*MockAuditService)
+		expectedStatus int
+		expectedValid  bool
+	}{
+		{
+			name:      "intact chain",
+			sessionID: sessionID,
+			setupMock: func(m *MockAuditService) {
+				m.On("VerifyChain", mock.Anything, sessionID, "user-456", false).Return(&domain.ChainVerification{
+					SessionID:      sessionID,
+					Valid:          true,
+					CheckedEntries: 3,
+					Issues:         []domain.ChainIssue{},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedValid:  true,
+		},
+		{
+			name:      "broken chain",
+			sessionID: sessionID,
+			setupMock: func(m *MockAuditService) {
+				m.On("VerifyChain", mock.Anything, sessionID, "user-456", false).Return(&domain.ChainVerification{
+					SessionID:      sessionID,
+					CheckedEntries: 3,
+					Issues:         []domain.ChainIssue{{Seq: 2, Problem: domain.ChainContentMismatch}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid session id",
+			sessionID:      "not-a-uuid",
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "forbidden",
+			sessionID: sessionID,
+			setupMock: func(m *MockAuditService) {
+				m.On("VerifyChain", mock.Anything, sessionID, "user-456", false).Return(nil, domain.ErrForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			tt.setupMock(mockService)
+			handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+tt.sessionID+"/events/verify", nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			c.Params = []gin.Param{{Key: "sessionId", Value: tt.sessionID}}
+
+			handler.VerifyChain(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var result domain.ChainVerification
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+				assert.Equal(t, tt.expectedValid, result.Valid)
+				assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetEventChain(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	eventID := "550e8400-e29b-41d4-a716-446655440003"
+
+	tests := []struct {
+		name           string
+		eventID        string
+		setupMock      func(*MockAuditService)
+		expectedStatus int
+	}{
+		{
+			name:    "chain",
+			eventID: eventID,
+			setupMock: func(m *MockAuditService) {
+				m.On("GetEventChain", mock.Anything, eventID, "user-456").Return(&domain.EventChain{
+					EventID:       eventID,
+					CorrelationID: "export-7f3a",
+					Items: []domain.AuditEntry{
+						{ID: "550e8400-e29b-41d4-a716-446655440002", Type: "export", CorrelationID: "export-7f3a"},
+						{ID: eventID, Type: "export", CorrelationID: "export-7f3a", ParentEventID: "550e8400-e29b-41d4-a716-446655440002"},
+					},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid event id",
+			eventID:        "not-a-uuid",
+			setupMock:      func(m *MockAuditService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "not found",
+			eventID: eventID,
+			setupMock: func(m *MockAuditService) {
+				m.On("GetEventChain", mock.Anything, eventID, "user-456").Return(nil, domain.ErrEventNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:    "forbidden",
+			eventID: eventID,
+			setupMock: func(m *MockAuditService) {
+				m.On("GetEventChain", mock.Anything, eventID, "user-456").Return(nil, domain.ErrForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			tt.setupMock(mockService)
+			handler := NewAuditHandler(mockService, clock.New(), zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/events/"+tt.eventID+"/chain", nil)
+			c.Set(middleware.AuthUserIDKey, "user-456")
+			c.Params = []gin.Param{{Key: "id", Value: tt.eventID}}
+
+			handler.GetEventChain(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var chain domain.EventChain
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &chain))
+				assert.Equal(t, "export-7f3a", chain.CorrelationID)
+				require.Len(t, chain.Items, 2)
+				assert.Equal(t, "550e8400-e29b-41d4-a716-446655440002", chain.Items[1].ParentEventID)
+			}
+			mockService.AssertExpectations(t)
 		})
 	}
 }
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ConfigReporter reports the running configuration with its secrets removed
+type ConfigReporter interface {
+	Redacted() map[string]interface{}
+}
+
+// ConfigHandler serves the configuration self-report
+type ConfigHandler struct {
+	config ConfigReporter
+	logger *zap.Logger
+}
+
+// NewConfigHandler creates a new config handler
+func NewConfigHandler(config ConfigReporter, logger *zap.Logger) *ConfigHandler {
+	return &ConfigHandler{
+		config: config,
+		logger: logger,
+	}
+}
+
+// GetConfig handles GET /admin/config
+// @Summary Get the running configuration
+// @Description Returns the configuration of this instance keyed by field name. Secrets such as keys, tokens and passwords are reported as [REDACTED] when set and empty when not; connection URLs keep everything but their password. Admin only.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Router /admin/config [get]
+func (h *ConfigHandler) GetConfig(c *gin.Context) {
+	h.logger.Info("admin config report",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.String("admin_id", middleware.GetAuthUserID(c)),
+	)
+
+	c.JSON(http.StatusOK, h.config.Redacted())
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"audit-service/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestConfigHandler_GetConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		Port:                   "4006",
+		SupabaseURL:            "https://project.supabase.co",
+		SupabaseServiceRoleKey: "service-role-key",
+		DatabaseURL:            "postgres://audit:hunter22@db:5432/audit",
+		RedisURL:               "redis://cache:6379/0?password=hunter22",
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/admin/config", nil)
+	NewConfigHandler(cfg, zap.NewNop()).GetConfig(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "service-role-key")
+	assert.NotContains(t, w.Body.String(), "hunter22")
+
+	var report map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, "4006", report["Port"])
+	assert.Equal(t, "https://project.supabase.co", report["SupabaseURL"])
+	assert.Equal(t, "[REDACTED]", report["SupabaseServiceRoleKey"])
+	// Unset secrets are reported as empty
+	assert.Equal(t, "", report["SupabaseJWTSecret"])
+	assert.Equal(t, "postgres://audit:xxxxx@db:5432/audit", report["DatabaseURL"])
+	assert.Equal(t, "redis://cache:6379/0?password=xxxxx", report["RedisURL"])
+	assert.Equal(t, "0s", report["ShutdownTimeout"])
+}
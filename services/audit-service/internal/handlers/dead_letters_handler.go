@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxDeadLetters caps the dead letters returned per page
+const maxDeadLetters = 100
+
+// DeadLetters lists and requeues outbox messages that ran out of delivery attempts
+type DeadLetters interface {
+	ListDeadOutbox(ctx context.Context, limit int, beforeID int64) ([]domain.DeadLetter, error)
+	RetryDeadOutbox(ctx context.Context, id int64) error
+}
+
+// DeadLetterList defines the response listing dead letters
+type DeadLetterList struct {
+	Items []domain.DeadLetter `json:"items"`
+	// NextBefore is the before parameter of the next page; omitted on the last page
+	NextBefore int64 `json:"nextBefore,omitempty" example:"12"`
+}
+
+// DeadLetterRetryResult defines the response of a dead letter retry
+type DeadLetterRetryResult struct {
+	ID     int64  `json:"id" example:"42"`
+	Status string `json:"status" example:"queued"`
+}
+
+// DeadLettersHandler handles the inspection and requeueing of failed outbox deliveries
+type DeadLettersHandler struct {
+	letters DeadLetters
+	logger  *zap.Logger
+}
+
+// NewDeadLettersHandler creates a new dead letters handler; nil letters report the outbox as
+// not configured
+func NewDeadLettersHandler(letters DeadLetters, logger *zap.Logger) *DeadLettersHandler {
+	return &DeadLettersHandler{
+		letters: letters,
+		logger:  logger,
+	}
+}
+
+// ListDeadLetters handles GET /admin/dlq
+// @Summary List dead letters
+// @Description Lists outbox messages of the admin's organization that ran out of delivery attempts, newest first, with the payload posted to the webhooks and the failure of the last delivery. Admin only.
+// @Tags Admin
+// @Produce json
+// @Param limit query int false "Number of items to return (default: 50, max: 100)"
+// @Param before query int false "Only dead letters with a lower ID, from a previous response's nextBefore"
+// @Security BearerAuth
+// @Success 200 {object} DeadLetterList
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /admin/dlq [get]
+func (h *DeadLettersHandler) ListDeadLetters(c *gin.Context) {
+	if h.letters == nil {
+		middleware.WriteError(c, domain.NewAPIError("outbox_disabled", "The outbox is not configured", http.StatusConflict))
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > maxDeadLetters {
+		middleware.WriteError(c, domain.NewAPIError("bad_request", "limit must be between 1 and 100", http.StatusBadRequest))
+		return
+	}
+	var before int64
+	if value := c.Query("before"); value != "" {
+		if before, err = strconv.ParseInt(value, 10, 64); err != nil || before < 1 {
+			middleware.WriteError(c, domain.NewAPIError("bad_request", "Invalid before parameter", http.StatusBadRequest))
+			return
+		}
+	}
+
+	letters, err := h.letters.ListDeadOutbox(c.Request.Context(), limit, before)
+	if err != nil {
+		h.logger.Error("failed to list dead letters",
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.Error(err),
+		)
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+
+	response := DeadLetterList{Items: letters}
+	if len(letters) == limit {
+		response.NextBefore = letters[len(letters)-1].ID
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response)
+}
+
+// RetryDeadLetter handles POST /admin/dlq/{id}/retry
+// @Summary Retry a dead letter
+// @Description Makes one outbox message that ran out of delivery attempts due again with a fresh attempt count, so the relay redelivers it to every webhook. Admin only.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "Dead letter ID"
+// @Security BearerAuth
+// @Success 202 {object} DeadLetterRetryResult
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /admin/dlq/{id}/retry [post]
+func (h *DeadLettersHandler) RetryDeadLetter(c *gin.Context) {
+	if h.letters == nil {
+		middleware.WriteError(c, domain.NewAPIError("outbox_disabled", "The outbox is not configured", http.StatusConflict))
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		middleware.WriteError(c, domain.NewAPIError("bad_request", "Invalid dead letter ID", http.StatusBadRequest))
+		return
+	}
+
+	if err := h.letters.RetryDeadOutbox(c.Request.Context(), id); err != nil {
+		apiErr := domain.ToAPIError(err)
+		if apiErr.Status >= http.StatusInternalServerError {
+			h.logger.Error("failed to retry dead letter",
+				zap.String("request_id", middleware.GetRequestID(c)),
+				zap.Int64("dead_letter_id", id),
+				zap.Error(err),
+			)
+		}
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	h.logger.Info("dead letter requeued",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.String("admin_id", middleware.GetAuthUserID(c)),
+		zap.Int64("dead_letter_id", id),
+	)
+	c.JSON(http.StatusAccepted, DeadLetterRetryResult{ID: id, Status: "queued"})
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockDeadLetters is a mock implementation of DeadLetters
+type MockDeadLetters struct {
+	mock.Mock
+}
+
+func (m *MockDeadLetters) ListDeadOutbox(ctx context.Context, limit int, beforeID int64) ([]domain.DeadLetter, error) {
+	args := m.Called(limit, beforeID)
+	return args.Get(0).([]domain.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetters) RetryDeadOutbox(ctx context.Context, id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func performDeadLetters(handle gin.HandlerFunc, method, target string, params ...gin.Param) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, nil)
+	c.Params = params
+	c.Set(middleware.AuthUserIDKey, "admin-1")
+
+	handle(c)
+	return w
+}
+
+func TestDeadLettersHandler_ListDeadLetters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	letter := domain.DeadLetter{
+		ID:        42,
+		EventID:   "550e8400-e29b-41d4-a716-446655440002",
+		SessionID: "550e8400-e29b-41d4-a716-446655440000",
+		Type:      "share",
+		Payload:   json.RawMessage(`{"id":"550e8400-e29b-41d4-a716-446655440002"}`),
+		Attempts:  8,
+		LastError: "webhook responded with status 503",
+		CreatedAt: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		DeadAt:    time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name           string
+		target         string
+		limit          int
+		before         int64
+		letters        []domain.DeadLetter
+		err            error
+		expectedStatus int
+		expectedNext   int64
+	}{
+		{name: "first page", target: "/api/v1/admin/dlq", limit: 50, letters: []domain.DeadLetter{letter}, expectedStatus: http.StatusOK},
+		{
+			name: "full page", target: "/api/v1/admin/dlq?limit=1&before=50", limit: 1, before: 50,
+			letters: []domain.DeadLetter{letter}, expectedStatus: http.StatusOK, expectedNext: 42,
+		},
+		{name: "limit above maximum", target: "/api/v1/admin/dlq?limit=500", expectedStatus: http.StatusBadRequest},
+		{name: "invalid before", target: "/api/v1/admin/dlq?before=abc", expectedStatus: http.StatusBadRequest},
+		{
+			name: "storage failure", target: "/api/v1/admin/dlq", limit: 50, letters: []domain.DeadLetter(nil),
+			err: errors.New("connection refused"), expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			letters := new(MockDeadLetters)
+			if tt.limit > 0 {
+				letters.On("ListDeadOutbox", tt.limit, tt.before).Return(tt.letters, tt.err)
+			}
+
+			w := performDeadLetters(NewDeadLettersHandler(letters, zap.NewNop()).ListDeadLetters, "GET", tt.target)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+				var list DeadLetterList
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+				assert.Equal(t, tt.letters, list.Items)
+				assert.Equal(t, tt.expectedNext, list.NextBefore)
+			}
+			letters.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeadLettersHandler_RetryDeadLetter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	letters := new(MockDeadLetters)
+	letters.On("RetryDeadOutbox", int64(42)).Return(nil)
+	letters.On("RetryDeadOutbox", int64(43)).Return(domain.ErrDeadLetterNotFound)
+	handler := NewDeadLettersHandler(letters, zap.NewNop())
+
+	retry := func(id string) *httptest.ResponseRecorder {
+		return performDeadLetters(handler.RetryDeadLetter, "POST", "/api/v1/admin/dlq/"+id+"/retry", gin.Param{Key: "id", Value: id})
+	}
+
+	w := retry("42")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var result DeadLetterRetryResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, DeadLetterRetryResult{ID: 42, Status: "queued"}, result)
+
+	assert.Equal(t, http.StatusNotFound, retry("43").Code)
+	assert.Equal(t, http.StatusBadRequest, retry("abc").Code)
+	letters.AssertExpectations(t)
+}
+
+func TestDeadLettersHandler_OutboxDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewDeadLettersHandler(nil, zap.NewNop())
+
+	for _, w := range []*httptest.ResponseRecorder{
+		performDeadLetters(handler.ListDeadLetters, "GET", "/api/v1/admin/dlq"),
+		performDeadLetters(handler.RetryDeadLetter, "POST", "/api/v1/admin/dlq/42/retry", gin.Param{Key: "id", Value: "42"}),
+	} {
+		assert.Equal(t, http.StatusConflict, w.Code)
+		var apiErr domain.APIError
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+		assert.Equal(t, "outbox_disabled", apiErr.Code)
+	}
+}
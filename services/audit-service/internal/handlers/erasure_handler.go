@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ErasureJobs starts and tracks user erasure jobs
+type ErasureJobs interface {
+	Start(ctx context.Context, userID domain.UserID, mode domain.ErasureMode, requestedBy string, override *domain.HoldOverride) (domain.ErasureJob, error)
+	Get(ctx context.Context, id string) (domain.ErasureJob, error)
+}
+
+// ErasureHandler handles data-subject erasure requests
+type ErasureHandler struct {
+	jobs   ErasureJobs
+	logger *zap.Logger
+}
+
+// NewErasureHandler creates a new erasure handler
+func NewErasureHandler(jobs ErasureJobs, logger *zap.Logger) *ErasureHandler {
+	return &ErasureHandler{
+		jobs:   jobs,
+		logger: logger,
+	}
+}
+
+// EraseUserEvents handles DELETE /users/{userId}/events
+// @Summary Erase a user's audit entries
+// @Description Anonymizes or deletes every audit entry created by a user across all sessions. Entries under a legal hold are kept unless overrideHolds is set with a reason, which is recorded in the erasure events. The work runs in the background; poll the returned job for progress. Admin only.
+// @Tags Admin
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param mode query string false "Erasure mode: anonymize or delete (default: anonymize)"
+// @Param overrideHolds query bool false "Also erase entries under a legal hold (default: false)"
+// @Param overrideReason query string false "Why legal holds are overridden; required with overrideHolds"
+// @Security BearerAuth
+// @Success 202 {object} domain.ErasureJob
+// @Header 202 {string} Location "URL of the erasure job"
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 503 {object} domain.APIError
+// @Router /users/{userId}/events [delete]
+func (h *ErasureHandler) EraseUserEvents(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	// Anonymized entries carry domain.RedactedUserID, which is not a UUID
+	userID, err := domain.ParseUserID(c.Param("userId"))
+	if err != nil {
+		middleware.WriteError(c, domain.NewAPIError("bad_request", "Invalid user ID", http.StatusBadRequest))
+		return
+	}
+
+	mode := domain.ErasureMode(c.DefaultQuery("mode", string(domain.ErasureAnonymize)))
+	if !mode.Valid() {
+		middleware.WriteError(c, domain.NewAPIError("bad_request", "mode must be anonymize or delete", http.StatusBadRequest))
+		return
+	}
+
+	override, apiErr := parseHoldOverride(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	requestedBy := middleware.GetAuthUserID(c)
+	job, err := h.jobs.Start(c.Request.Context(), userID, mode, requestedBy, override)
+	if err != nil {
+		h.logger.Error("failed to start erasure job",
+			zap.String("request_id", requestID),
+			zap.Error(err),
+		)
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	h.logger.Info("erasure job accepted",
+		zap.String("request_id", requestID),
+		zap.String("job_id", job.ID),
+		zap.String("mode", string(mode)),
+		zap.String("requested_by", requestedBy),
+	)
+
+	c.Header("Location", "/api/v1/erasure-jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, job)
+}
+
+// parseHoldOverride reads the overrideHolds and overrideReason query parameters;
+// nil means legal holds are respected
+func parseHoldOverride(c *gin.Context) (*domain.HoldOverride, *domain.APIError) {
+	raw := c.Query("overrideHolds")
+	if raw == "" {
+		return nil, nil
+	}
+	override, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, domain.NewAPIError("bad_request", "overrideHolds must be true or false", http.StatusBadRequest)
+	}
+	if !override {
+		return nil, nil
+	}
+
+	reason := strings.TrimSpace(c.Query("overrideReason"))
+	if reason == "" || utf8.RuneCountInString(reason) > domain.MaxLegalHoldReasonLength {
+		return nil, domain.NewAPIError("bad_request",
+			fmt.Sprintf("overrideReason must have 1 to %d characters when overriding legal holds", domain.MaxLegalHoldReasonLength),
+			http.StatusBadRequest)
+	}
+	return &domain.HoldOverride{Reason: reason}, nil
+}
+
+// GetJob handles GET /erasure-jobs/{jobId}
+// @Summary Get an erasure job
+// @Description Returns the progress of a user erasure job. Finished jobs are kept for 24 hours. Admin only.
+// @Tags Admin
+// @Produce json
+// @Param jobId path string true "Erasure job ID"
+// @Security BearerAuth
+// @Success 200 {object} domain.ErasureJob
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Router /erasure-jobs/{jobId} [get]
+func (h *ErasureHandler) GetJob(c *gin.Context) {
+	job, err := h.jobs.Get(c.Request.Context(), c.Param("jobId"))
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockErasureJobs is a mock implementation of ErasureJobs
+type MockErasureJobs struct {
+	mock.Mock
+}
+
+func (m *MockErasureJobs) Start(ctx context.Context, userID domain.UserID, mode domain.ErasureMode, requestedBy string, override *domain.HoldOverride) (domain.ErasureJob, error) {
+	args := m.Called(userID, mode, requestedBy, override)
+	return args.Get(0).(domain.ErasureJob), args.Error(1)
+}
+
+func (m *MockErasureJobs) Get(ctx context.Context, id string) (domain.ErasureJob, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.ErasureJob), args.Error(1)
+}
+
+func performErasure(handler *ErasureHandler, userID, query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("DELETE", "/api/v1/users/"+userID+"/events"+query, nil)
+	c.Set(middleware.AuthUserIDKey, "admin-1")
+	c.Params = []gin.Param{{Key: "userId", Value: userID}}
+
+	handler.EraseUserEvents(c)
+	return w
+}
+
+func TestErasureHandler_EraseUserEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const erased domain.UserID = "550e8400-e29b-41d4-a716-446655440002"
+
+	tests := []struct {
+		name           string
+		userID         domain.UserID
+		query          string
+		expectedMode   domain.ErasureMode
+		expectedHold   *domain.HoldOverride
+		startErr       error
+		expectedStatus int
+	}{
+		{name: "defaults to anonymize", userID: erased, expectedMode: domain.ErasureAnonymize, expectedStatus: http.StatusAccepted},
+		{name: "delete mode", userID: erased, query: "?mode=delete", expectedMode: domain.ErasureDelete, expectedStatus: http.StatusAccepted},
+		{
+			name: "override holds", userID: erased, query: "?mode=delete&overrideHolds=true&overrideReason=Court+order+2024-031",
+			expectedMode: domain.ErasureDelete, expectedHold: &domain.HoldOverride{Reason: "Court order 2024-031"}, expectedStatus: http.StatusAccepted,
+		},
+		{name: "override without reason", userID: erased, query: "?overrideHolds=true", expectedStatus: http.StatusBadRequest},
+		{name: "invalid override flag", userID: erased, query: "?overrideHolds=yes", expectedStatus: http.StatusBadRequest},
+		{name: "unknown mode", userID: erased, query: "?mode=shred", expectedStatus: http.StatusBadRequest},
+		{name: "redacted placeholder", userID: domain.RedactedUserID, expectedStatus: http.StatusBadRequest},
+		{name: "not a UUID", userID: "user-1", expectedStatus: http.StatusBadRequest},
+		{name: "nil UUID", userID: "00000000-0000-0000-0000-000000000000", expectedStatus: http.StatusBadRequest},
+		{name: "shutting down", userID: erased, expectedMode: domain.ErasureAnonymize, startErr: domain.ErrServiceUnavailable, expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs := new(MockErasureJobs)
+			job := domain.ErasureJob{
+				ID: "job-1", UserID: tt.userID, Mode: tt.expectedMode, Status: domain.ErasurePending, RequestedBy: "admin-1",
+				HoldOverride: tt.expectedHold,
+			}
+			if tt.expectedMode != "" {
+				jobs.On("Start", tt.userID, tt.expectedMode, "admin-1", tt.expectedHold).Return(job, tt.startErr)
+			}
+
+			w := performErasure(NewErasureHandler(jobs, zap.NewNop()), string(tt.userID), tt.query)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusAccepted {
+				assert.Equal(t, "/api/v1/erasure-jobs/job-1", w.Header().Get("Location"))
+
+				var got domain.ErasureJob
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				assert.Equal(t, job, got)
+			}
+			jobs.AssertExpectations(t)
+		})
+	}
+}
+
+func TestErasureHandler_GetJob(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jobs := new(MockErasureJobs)
+	jobs.On("Get", "job-1").Return(domain.ErasureJob{ID: "job-1", Status: domain.ErasureCompleted, AffectedEvents: 3}, nil)
+	jobs.On("Get", "missing").Return(domain.ErasureJob{}, domain.ErrErasureJobNotFound)
+	handler := NewErasureHandler(jobs, zap.NewNop())
+
+	get := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/erasure-jobs/"+id, nil)
+		c.Params = []gin.Param{{Key: "jobId", Value: id}}
+		handler.GetJob(c)
+		return w
+	}
+
+	w := get("job-1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var job domain.ErasureJob
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+	assert.Equal(t, 3, job.AffectedEvents)
+
+	assert.Equal(t, http.StatusNotFound, get("missing").Code)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondWithETag writes a JSON response tagged with a weak ETag of its body, or 304 Not Modified
+// when the client already holds that version. Polling clients still cost a query, but unchanged
+// history is not sent again.
+func respondWithETag(c *gin.Context, status int, response interface{}) {
+	body, err := json.Marshal(response)
+	if err != nil {
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+
+	// The tag is weak because compression changes the bytes but not the content
+	sum := sha256.Sum256(body)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", etag)
+	// Clients may keep the response but must revalidate it, and shared caches must not store it
+	c.Header("Cache-Control", "private, no-cache")
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.AbortWithStatus(http.StatusNotModified)
+		return
+	}
+
+	c.Data(status, "application/json; charset=utf-8", body)
+}
+
+// etagMatches applies the weak comparison of If-None-Match to a list of entity tags
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
+
+// versionETag returns the entity tag of a version of a versioned record. The tag is strong: it
+// names the stored version, whatever the encoding of the response.
+func versionETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// respondWithVersion writes a versioned record as JSON, tagged with the ETag of its version that
+// updates send back in If-Match
+func respondWithVersion(c *gin.Context, status int, version int64, record interface{}) {
+	c.Header("ETag", versionETag(version))
+	c.JSON(status, record)
+}
+
+// ifMatchVersion returns the version an update of a versioned record was made against, from its
+// If-Match header: domain.ErrPreconditionRequired when the header is missing, and
+// domain.ErrVersionConflict for tags that cannot match a version, such as weak tags, "*" or lists.
+func ifMatchVersion(c *gin.Context) (int64, error) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" {
+		return 0, domain.ErrPreconditionRequired
+	}
+
+	quoted := len(ifMatch) > 2 && strings.HasPrefix(ifMatch, `"`) && strings.HasSuffix(ifMatch, `"`)
+	if !quoted {
+		return 0, fmt.Errorf("%w: If-Match %s does not name a version", domain.ErrVersionConflict, ifMatch)
+	}
+	version, err := strconv.ParseInt(ifMatch[1:len(ifMatch)-1], 10, 64)
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("%w: If-Match %s does not name a version", domain.ErrVersionConflict, ifMatch)
+	}
+	return version, nil
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"audit-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		ifMatch  string
+		expected int64
+		err      error
+	}{
+		{name: "version", ifMatch: `"3"`, expected: 3},
+		{name: "padded", ifMatch: ` "12" `, expected: 12},
+		{name: "missing", ifMatch: "", err: domain.ErrPreconditionRequired},
+		{name: "unquoted", ifMatch: "3", err: domain.ErrVersionConflict},
+		{name: "weak", ifMatch: `W/"3"`, err: domain.ErrVersionConflict},
+		{name: "any", ifMatch: "*", err: domain.ErrVersionConflict},
+		{name: "list", ifMatch: `"2", "3"`, err: domain.ErrVersionConflict},
+		{name: "never changed", ifMatch: `"0"`, expected: 0},
+		{name: "negative", ifMatch: `"-1"`, err: domain.ErrVersionConflict},
+		{name: "not a number", ifMatch: `"abc"`, err: domain.ErrVersionConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("PUT", "/", nil)
+			c.Request.Header.Set("If-Match", tt.ifMatch)
+
+			version, err := ifMatchVersion(c)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, version)
+		})
+	}
+}
+
+func TestRespondWithVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	respondWithVersion(c, http.StatusOK, 7, map[string]string{"label": "disputed"})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"7"`, w.Header().Get("ETag"))
+
+	// The tag of a response is what the next update sends back
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("PUT", "/", nil)
+	c.Request.Header.Set("If-Match", w.Header().Get("ETag"))
+	version, err := ifMatchVersion(c)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), version)
+}
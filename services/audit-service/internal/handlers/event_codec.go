@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/eventpb"
+	"audit-service/internal/middleware"
+	"audit-service/internal/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	// mimeNDJSON is newline-delimited JSON, one event per line, used for bulk ingestion
+	mimeNDJSON = "application/x-ndjson"
+
+	// maxBulkBatchSize limits the number of events in a single NDJSON or protobuf batch
+	maxBulkBatchSize = 5000
+)
+
+// isProtobuf reports whether a media type names a protobuf payload
+func isProtobuf(mediaType string) bool {
+	return mediaType == eventpb.ContentType || mediaType == eventpb.LegacyContentType
+}
+
+// invalidBody builds the error returned for an undecodable or oversized request body, or the
+// problem listing the fields of a decoded body that break validation rules
+func invalidBody(c *gin.Context, err error) *domain.APIError {
+	if apiErr, ok := middleware.BodyTooLarge(err); ok {
+		return apiErr
+	}
+	if apiErr, ok := validation.Problem(err, c.GetHeader("Accept-Language")); ok {
+		return apiErr
+	}
+	return domain.NewAPIError("invalid_request", "Invalid request body: "+err.Error(), http.StatusBadRequest)
+}
+
+// decodeEventRequest reads a single event in the format named by the Content-Type header
+func decodeEventRequest(c *gin.Context) (CreateEventRequest, *domain.APIError) {
+	if !isProtobuf(c.ContentType()) {
+		var req CreateEventRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return CreateEventRequest{}, invalidBody(c, err)
+		}
+		return req, nil
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return CreateEventRequest{}, invalidBody(c, err)
+	}
+	var event eventpb.Event
+	if err := event.Unmarshal(body); err != nil {
+		return CreateEventRequest{}, invalidBody(c, err)
+	}
+	req, err := requestFromProto(event)
+	if err != nil {
+		return CreateEventRequest{}, invalidBody(c, err)
+	}
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return CreateEventRequest{}, invalidBody(c, err)
+	}
+	return req, nil
+}
+
+// decodeEventBatch reads a batch of events in the format named by the Content-Type header.
+// It also returns the largest batch accepted in that format.
+func decodeEventBatch(c *gin.Context) ([]CreateEventRequest, int, *domain.APIError) {
+	switch mediaType := c.ContentType(); {
+	case mediaType == mimeNDJSON:
+		reqs, err := decodeNDJSON(c.Request.Body, maxBulkBatchSize)
+		if err != nil {
+			return nil, 0, invalidBody(c, err)
+		}
+		if err := validation.Each(reqs); err != nil {
+			return nil, 0, invalidBody(c, err)
+		}
+		return reqs, maxBulkBatchSize, nil
+
+	case isProtobuf(mediaType):
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return nil, 0, invalidBody(c, err)
+		}
+		var batch eventpb.EventBatch
+		if err := batch.Unmarshal(body); err != nil {
+			return nil, 0, invalidBody(c, err)
+		}
+		reqs := make([]CreateEventRequest, len(batch.Events))
+		for i, event := range batch.Events {
+			if reqs[i], err = requestFromProto(event); err != nil {
+				return nil, 0, invalidBody(c, fmt.Errorf("event %d: %w", i, err))
+			}
+		}
+		if err := validation.Each(reqs); err != nil {
+			return nil, 0, invalidBody(c, err)
+		}
+		return reqs, maxBulkBatchSize, nil
+
+	default:
+		// Events are validated one by one, so violations name the index of their event
+		var reqs []CreateEventRequest
+		if err := json.NewDecoder(c.Request.Body).Decode(&reqs); err != nil {
+			return nil, 0, invalidBody(c, err)
+		}
+		if err := validation.Each(reqs); err != nil {
+			return nil, 0, invalidBody(c, err)
+		}
+		return reqs, maxBatchSize, nil
+	}
+}
+
+// decodeNDJSON reads one event per line. It stops after limit+1 events so an oversized
+// stream is rejected without buffering all of it.
+func decodeNDJSON(r io.Reader, limit int) ([]CreateEventRequest, error) {
+	decoder := json.NewDecoder(r)
+	var reqs []CreateEventRequest
+	for len(reqs) <= limit {
+		var req CreateEventRequest
+		err := decoder.Decode(&req)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", len(reqs)+1, err)
+		}
+		if err := binding.Validator.ValidateStruct(&req); err != nil {
+			return nil, fmt.Errorf("line %d: %w", len(reqs)+1, err)
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+// requestFromProto converts a protobuf event into the request the JSON endpoints bind
+func requestFromProto(event eventpb.Event) (CreateEventRequest, error) {
+	req := CreateEventRequest{
+		ID:        event.ID,
+		SessionID: event.SessionID,
+		Type:      domain.AuditAction(event.Type),
+		Timestamp: event.Timestamp,
+		UserID:    event.UserID,
+		// Causal links of the event
+		CorrelationID: event.CorrelationID,
+		ParentEventID: event.ParentEventID,
+		SchemaVersion: int(event.SchemaVersion),
+		// Tenant a service acted for
+		OrganizationID: event.OrganizationID,
+	}
+	if len(event.Details) > 0 {
+		if err := json.Unmarshal(event.Details, &req.Details); err != nil {
+			return CreateEventRequest{}, fmt.Errorf("details must be JSON: %w", err)
+		}
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return CreateEventRequest{}, err
+	}
+	return req, nil
+}
+
+// encodeEventResponse encodes an events endpoint response in the format preferred by the
+// Accept header, defaulting to JSON
+func encodeEventResponse(c *gin.Context, response interface{}) (contentType string, body []byte, err error) {
+	format := c.NegotiateFormat(binding.MIMEJSON, eventpb.ContentType, eventpb.LegacyContentType)
+	if !isProtobuf(format) {
+		body, err = json.Marshal(response)
+		return "application/json; charset=utf-8", body, err
+	}
+
+	switch r := response.(type) {
+	case CreateEventResponse:
+		result := eventResultToProto(r)
+		return format, result.Marshal(), nil
+	case BatchCreateEventResponse:
+		result := eventpb.BatchResult{
+			Count:            int64(r.Count),
+			Events:           make([]eventpb.EventResult, len(r.Events)),
+			ConsistencyToken: r.ConsistencyToken,
+		}
+		for i, event := range r.Events {
+			result.Events[i] = eventResultToProto(event)
+		}
+		return format, result.Marshal(), nil
+	default:
+		return "", nil, fmt.Errorf("no protobuf encoding for %T", response)
+	}
+}
+
+// eventResultToProto converts a created event into its protobuf form
+func eventResultToProto(r CreateEventResponse) eventpb.EventResult {
+	return eventpb.EventResult{
+		ID:        r.ID,
+		SessionID: r.SessionID,
+		UserID:    r.UserID,
+		Type:      string(r.Type),
+		Timestamp: r.Timestamp,
+		Success:   r.Success,
+
+		ConsistencyToken: r.ConsistencyToken,
+	}
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"audit-service/internal/broadcast"
+	"audit-service/internal/domain"
+	"audit-service/internal/eventpb"
+	"audit-service/internal/middleware"
+	"audit-service/pkg/clock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func performEncodedRequest(handler gin.HandlerFunc, path, contentType, accept string, body []byte) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", path, bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", contentType)
+	if accept != "" {
+		c.Request.Header.Set("Accept", accept)
+	}
+	c.Set(middleware.AuthUserIDKey, "user-456")
+
+	handler(c)
+	return w
+}
+
+func TestEventsHandler_NDJSONBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	body := []byte(`{"sessionId":"` + sessionID + `","type":"edit","details":{"slide":1}}
+{"sessionId":"` + sessionID + `","type":"edit","details":{"slide":2}}
+
+{"sessionId":"` + sessionID + `","type":"comment","details":{"text":"Looks good"}}
+`)
+
+	for _, path := range []string{"/api/v1/events", "/api/v1/events/batch"} {
+		t.Run(path, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			mockService.On("CreateEvents", mock.Anything, mock.MatchedBy(func(entries []domain.AuditEntry) bool {
+				return len(entries) == 3 && entries[2].Type == string(domain.ActionComment)
+			})).Return(nil).Once()
+			handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+			// NDJSON sent to the single-event endpoint is ingested as a batch
+			create := handler.CreateEventsBatch
+			if path == "/api/v1/events" {
+				create = handler.CreateEvent
+			}
+			w := performEncodedRequest(create, path, mimeNDJSON, "", body)
+
+			require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+			var response BatchCreateEventResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, 3, response.Count)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEventsHandler_NDJSONBatch_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tooMany := strings.Repeat(`{"sessionId":"test-session-1","type":"edit"}`+"\n", maxBulkBatchSize+1)
+
+	tests := []struct {
+		name            string
+		body            string
+		expectedCode    string
+		expectedMessage string
+	}{
+		{
+			name:            "malformed line",
+			body:            `{"sessionId":"test-session-1","type":"edit"}` + "\n" + `{"sessionId":`,
+			expectedCode:    "invalid_request",
+			expectedMessage: "line 2",
+		},
+		{
+			name:            "missing required field",
+			body:            `{"sessionId":"test-session-1"}`,
+			expectedCode:    "validation_failed",
+			expectedMessage: "invalid",
+		},
+		{
+			name:            "too many events",
+			body:            tooMany,
+			expectedCode:    "batch_too_large",
+			expectedMessage: "5000 events",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewEventsHandler(new(MockAuditService), allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+			w := performEncodedRequest(handler.CreateEventsBatch, "/api/v1/events/batch", mimeNDJSON, "", []byte(tt.body))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response domain.APIError
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCode, response.Code)
+			assert.Contains(t, response.Message, tt.expectedMessage)
+		})
+	}
+}
+
+func TestEventsHandler_ProtobufEvent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvent", mock.Anything, mock.MatchedBy(func(entry domain.AuditEntry) bool {
+		return entry.SessionID == sessionID && string(entry.Details) == `{"slide":1}`
+	})).Return(nil).Once()
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	event := eventpb.Event{SessionID: sessionID, Type: "edit", Details: []byte(`{"slide":1}`)}
+	w := performEncodedRequest(handler.CreateEvent, "/api/v1/events", eventpb.ContentType, eventpb.ContentType, event.Marshal())
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, eventpb.ContentType, w.Header().Get("Content-Type"))
+
+	var result eventpb.EventResult
+	require.NoError(t, result.Unmarshal(w.Body.Bytes()))
+	assert.Equal(t, sessionID, result.SessionID)
+	assert.Equal(t, "user-456", result.UserID)
+	assert.True(t, result.Success)
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_ProtobufBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewEventsHandler(new(MockAuditService), allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+	handler.service.(*MockAuditService).On("CreateEvents", mock.Anything, []domain.AuditEntry(nil)).Return(nil).Once()
+
+	batch := eventpb.EventBatch{Events: []eventpb.Event{
+		{SessionID: "test-session-1", Type: "edit"},
+		{SessionID: "test-session-1", Type: "view"},
+	}}
+
+	// Without an Accept header the response stays JSON
+	w := performEncodedRequest(handler.CreateEventsBatch, "/api/v1/events/batch", eventpb.LegacyContentType, "", batch.Marshal())
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var response BatchCreateEventResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Count)
+}
+
+func TestEventsHandler_ProtobufInvalidBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewEventsHandler(new(MockAuditService), allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	tests := []struct {
+		name         string
+		event        eventpb.Event
+		body         []byte
+		expectedCode string
+	}{
+		{name: "truncated message", body: []byte{0x12, 0x05, 's'}, expectedCode: "invalid_request"},
+		{name: "details are not JSON", event: eventpb.Event{SessionID: "test-session-1", Type: "edit", Details: []byte("slide=1")}, expectedCode: "invalid_request"},
+		{name: "missing type", event: eventpb.Event{SessionID: "test-session-1"}, expectedCode: "validation_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := tt.body
+			if body == nil {
+				body = tt.event.Marshal()
+			}
+
+			w := performEncodedRequest(handler.CreateEvent, "/api/v1/events", eventpb.ContentType, eventpb.ContentType, body)
+
+			// Errors are reported as JSON whatever the Accept header says
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedCode)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// EventTypes registers and lists the event types accepted on ingestion
+type EventTypes interface {
+	Register(ctx context.Context, eventType domain.EventType, createdBy string) (domain.EventType, error)
+	List(ctx context.Context) ([]domain.EventType, error)
+}
+
+// CreateEventTypeRequest defines the request body for registering a custom event type
+type CreateEventTypeRequest struct {
+	Name        domain.AuditAction `json:"name" binding:"required,event_type" example:"terminology_approved"`
+	DisplayName string             `json:"displayName" binding:"required" example:"Terminology approved"`
+	// Severity defaults to info
+	Severity domain.EventSeverity `json:"severity,omitempty" example:"low"`
+	// Schema is a JSON schema for the details of events of this type; omit it to accept any details
+	Schema json.RawMessage `json:"schema,omitempty" swaggertype:"object"`
+}
+
+// EventTypeList defines the response listing event types
+type EventTypeList struct {
+	Items []domain.EventType `json:"items"`
+}
+
+// EventTypesHandler handles the registration of custom event types
+type EventTypesHandler struct {
+	types  EventTypes
+	logger *zap.Logger
+}
+
+// NewEventTypesHandler creates a new event types handler
+func NewEventTypesHandler(types EventTypes, logger *zap.Logger) *EventTypesHandler {
+	return &EventTypesHandler{
+		types:  types,
+		logger: logger,
+	}
+}
+
+// CreateEventType handles POST /event-types
+// @Summary Register a custom event type
+// @Description Registers an event type with a display name, severity and optional JSON schema for its details. Events of the type are accepted by POST /events from then on. Admin only.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body CreateEventTypeRequest true "Event type"
+// @Security BearerAuth
+// @Success 201 {object} domain.EventType
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /event-types [post]
+func (h *EventTypesHandler) CreateEventType(c *gin.Context) {
+	var req CreateEventTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.WriteError(c, invalidBody(c, err))
+		return
+	}
+
+	severity := req.Severity
+	if severity == "" {
+		severity = domain.SeverityInfo
+	}
+	eventType := domain.EventType{
+		Name:        req.Name,
+		DisplayName: req.DisplayName,
+		Severity:    severity,
+		Schema:      req.Schema,
+	}
+	// Reasons for an invalid definition are returned to the admin as is
+	if err := eventType.Validate(); err != nil {
+		middleware.WriteError(c, domain.NewAPIError("invalid_event_type", err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	created, err := h.types.Register(c.Request.Context(), eventType, middleware.GetAuthUserID(c))
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		if apiErr.Status >= http.StatusInternalServerError {
+			h.logger.Error("failed to register event type",
+				zap.String("request_id", middleware.GetRequestID(c)),
+				zap.String("name", string(req.Name)),
+				zap.Error(err),
+			)
+		}
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// ListEventTypes handles GET /event-types
+// @Summary List event types
+// @Description Lists the built-in event types and the custom types registered with POST /event-types. Events of any other type are rejected.
+// @Tags Audit
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} EventTypeList
+// @Failure 401 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /event-types [get]
+func (h *EventTypesHandler) ListEventTypes(c *gin.Context) {
+	types, err := h.types.List(c.Request.Context())
+	if err != nil {
+		h.logger.Error("failed to list event types",
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.Error(err),
+		)
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, EventTypeList{Items: types})
+}
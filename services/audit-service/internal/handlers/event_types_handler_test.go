@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockEventTypes is a mock implementation of EventTypes
+type MockEventTypes struct {
+	mock.Mock
+}
+
+func (m *MockEventTypes) Register(ctx context.Context, eventType domain.EventType, createdBy string) (domain.EventType, error) {
+	args := m.Called(ctx, eventType, createdBy)
+	return args.Get(0).(domain.EventType), args.Error(1)
+}
+
+func (m *MockEventTypes) List(ctx context.Context) ([]domain.EventType, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.EventType), args.Error(1)
+}
+
+func performCreateEventType(handler *EventTypesHandler, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/event-types", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.AuthUserIDKey, "admin-1")
+
+	handler.CreateEventType(c)
+	return w
+}
+
+func TestEventTypesHandler_CreateEventType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	types := new(MockEventTypes)
+	expected := domain.EventType{
+		Name: "terminology_approved", DisplayName: "Terminology approved", Severity: domain.SeverityLow,
+		Schema: json.RawMessage(`{"type":"object","required":["term"]}`),
+	}
+	created := expected
+	created.CreatedBy = "admin-1"
+	types.On("Register", mock.Anything, expected, "admin-1").Return(created, nil).Once()
+
+	w := performCreateEventType(NewEventTypesHandler(types, zap.NewNop()), `{
+		"name": "terminology_approved",
+		"displayName": "Terminology approved",
+		"severity": "low",
+		"schema": {"type":"object","required":["term"]}
+	}`)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var got domain.EventType
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, "admin-1", got.CreatedBy)
+	types.AssertExpectations(t)
+}
+
+func TestEventTypesHandler_CreateEventType_DefaultSeverity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	types := new(MockEventTypes)
+	expected := domain.EventType{Name: "glossary_synced", DisplayName: "Glossary synced", Severity: domain.SeverityInfo}
+	types.On("Register", mock.Anything, expected, "admin-1").Return(expected, nil).Once()
+
+	w := performCreateEventType(NewEventTypesHandler(types, zap.NewNop()), `{"name":"glossary_synced","displayName":"Glossary synced"}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	types.AssertExpectations(t)
+}
+
+func TestEventTypesHandler_CreateEventType_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		registerErr    error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "missing name", body: `{"displayName":"Approved"}`, expectedStatus: http.StatusBadRequest, expectedCode: "validation_failed"},
+		{name: "invalid name", body: `{"name":"Approved!","displayName":"Approved"}`, expectedStatus: http.StatusBadRequest, expectedCode: "validation_failed"},
+		{name: "unknown severity", body: `{"name":"approved","displayName":"Approved","severity":"critical"}`, expectedStatus: http.StatusBadRequest, expectedCode: "invalid_event_type"},
+		{name: "invalid schema", body: `{"name":"approved","displayName":"Approved","schema":{"type":42}}`, expectedStatus: http.StatusBadRequest, expectedCode: "invalid_event_type"},
+		{name: "already registered", body: `{"name":"edit","displayName":"Edit"}`, registerErr: domain.ErrEventTypeExists, expectedStatus: http.StatusConflict, expectedCode: "event_type_exists"},
+		{name: "storage failure", body: `{"name":"approved","displayName":"Approved"}`, registerErr: assert.AnError, expectedStatus: http.StatusInternalServerError, expectedCode: "internal_server_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			types := new(MockEventTypes)
+			if tt.registerErr != nil {
+				types.On("Register", mock.Anything, mock.Anything, "admin-1").Return(domain.EventType{}, tt.registerErr).Once()
+			}
+
+			w := performCreateEventType(NewEventTypesHandler(types, zap.NewNop()), tt.body)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var apiErr domain.APIError
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+			assert.Equal(t, tt.expectedCode, apiErr.Code)
+			if tt.registerErr == nil {
+				types.AssertNotCalled(t, "Register")
+			}
+		})
+	}
+}
+
+func TestEventTypesHandler_ListEventTypes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	types := new(MockEventTypes)
+	types.On("List", mock.Anything).Return(testSchemas.EventTypes(), nil).Once()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/event-types", nil)
+	NewEventTypesHandler(types, zap.NewNop()).ListEventTypes(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var got EventTypeList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	require.NotEmpty(t, got.Items)
+	assert.True(t, got.Items[0].BuiltIn)
+}
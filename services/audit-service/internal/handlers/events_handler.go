@@ -1,34 +1,91 @@
 package handlers
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
-	"strings"
 	"sync"
 	"time"
 
+	"audit-service/internal/broadcast"
 	"audit-service/internal/domain"
 	"audit-service/internal/middleware"
+	"audit-service/internal/quota"
+	"audit-service/internal/redact"
+	"audit-service/internal/review"
 	"audit-service/internal/service"
+	"audit-service/internal/viewdedup"
+	"audit-service/pkg/cache"
+	"audit-service/pkg/clock"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+const (
+	// maxBatchSize limits the number of events accepted in a single batch request
+	maxBatchSize = 100
+
+	// idempotencyKeyHeader lets clients retry event creation without duplicating entries
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	// idempotentReplayedHeader marks responses replayed from an earlier request
+	idempotentReplayedHeader = "Idempotent-Replayed"
+
+	// maxIdempotencyKeyLength bounds the size of client supplied idempotency keys
+	maxIdempotencyKeyLength = 255
+)
+
 // EventsHandler handles event-related HTTP requests
 type EventsHandler struct {
-	service    service.AuditService
-	logger     *zap.Logger
-	testEvents *TestEventStore
+	service     service.Writer
+	sessions    SessionAuthorizer
+	broker      *broadcast.Broker
+	schemas     *domain.SchemaRegistry
+	redactor    *redact.Redactor
+	idempotency *cache.IdempotencyCache
+	timestamps  domain.TimestampPolicy
+	maxDetails  int
+	quotas      *quota.Enforcer
+	reviews     *review.Workflow
+	views       *viewdedup.Deduplicator
+	clock       clock.Clock
+	logger      *zap.Logger
+	testEvents  *TestEventStore
+}
+
+// SessionAuthorizer checks that a user may access a session, which users signed in with a JWT
+// must to record events in it
+type SessionAuthorizer interface {
+	AuthorizeSession(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool) error
 }
 
-// NewEventsHandler creates a new events handler
-func NewEventsHandler(service service.AuditService, logger *zap.Logger) *EventsHandler {
+// NewEventsHandler creates a new events handler; a nil redactor stores details as sent, client
+// timestamps are checked against the clock with the timestamps policy, details larger than
+// maxDetails bytes are rejected unless it is 0, a nil quotas enforcer accepts any number of events,
+// a nil reviews workflow accepts review events in any order and a nil views deduplicator stores
+// every view
+func NewEventsHandler(service service.Writer, sessions SessionAuthorizer, broker *broadcast.Broker, schemas *domain.SchemaRegistry, redactor *redact.Redactor, idempotency *cache.IdempotencyCache, timestamps domain.TimestampPolicy, maxDetails int, quotas *quota.Enforcer, reviews *review.Workflow, views *viewdedup.Deduplicator, clk clock.Clock, logger *zap.Logger) *EventsHandler {
 	return &EventsHandler{
-		service:    service,
-		logger:     logger,
-		testEvents: NewTestEventStore(),
+		service:     service,
+		sessions:    sessions,
+		broker:      broker,
+		schemas:     schemas,
+		redactor:    redactor,
+		idempotency: idempotency,
+		timestamps:  timestamps,
+		maxDetails:  maxDetails,
+		quotas:      quotas,
+		reviews:     reviews,
+		views:       views,
+		clock:       clk,
+		logger:      logger,
+		testEvents:  NewTestEventStore(),
 	}
 }
 
@@ -83,10 +140,20 @@ func (s *TestEventStore) GetEvents(sessionID string, limit, offset int) ([]domai
 
 // CreateEventRequest defines the request body for creating an event
 type CreateEventRequest struct {
-	SessionID string             `json:"sessionId" binding:"required"`
-	Type      domain.AuditAction `json:"type" binding:"required"`
+	ID        string             `json:"id,omitempty" binding:"omitempty,uuid_id"` // Optional client-generated UUID, also used as idempotency key
+	SessionID string             `json:"sessionId" binding:"required,session_id"`
+	Type      domain.AuditAction `json:"type" binding:"required,event_type"`
 	Details   interface{}        `json:"details"`
-	Timestamp string             `json:"timestamp"`
+	Timestamp string             `json:"timestamp" binding:"omitempty,timestamp"`
+	UserID    string             `json:"userId,omitempty"` // User a service acted for; only honored for API-key callers
+	// OrganizationID is the organization a service acted for; only honored for API-key callers,
+	// users record events in the organization of their token
+	OrganizationID string `json:"organizationId,omitempty"`
+	// CorrelationID groups the events of one operation; ParentEventID is the UUID of the event that caused this one
+	CorrelationID string `json:"correlationId,omitempty"`
+	ParentEventID string `json:"parentEventId,omitempty" binding:"omitempty,uuid_id"`
+	// SchemaVersion selects the version of the details schema; requests without it use version 1
+	SchemaVersion int `json:"schemaVersion,omitempty"`
 }
 
 // CreateEventResponse defines the response for a created event
@@ -97,144 +164,642 @@ type CreateEventResponse struct {
 	Type      domain.AuditAction `json:"type"`
 	Timestamp string             `json:"timestamp"`
 	Success   bool               `json:"success"`
+	// RedactedFields lists the details values masked before the event was stored
+	RedactedFields []string `json:"redactedFields,omitempty"`
+	// ConsistencyToken makes history reads sent with it in the Consistency-Token header see the
+	// event; absent for test sessions and views held for deduplication
+	ConsistencyToken string `json:"consistencyToken,omitempty"`
+	// Collapsed is set on views counted into an identical view held for deduplication, whose ID
+	// is returned instead of their own
+	Collapsed bool `json:"collapsed,omitempty"`
 }
 
-// Helper function to check UUID validity - avoiding name conflict with audit_handler.go
-func checkValidSessionID(id string) bool {
-	// Allow test session IDs for testing purposes
-	if strings.HasPrefix(id, "test-") {
-		return true
-	}
-
-	// Simple UUID validation - check format
-	if len(id) != 36 {
-		return false
-	}
-
-	// Check for hyphens at correct positions
-	if id[8] != '-' || id[13] != '-' || id[18] != '-' || id[23] != '-' {
-		return false
-	}
+// BatchCreateEventResponse defines the response for a created batch of events
+type BatchCreateEventResponse struct {
+	Count  int                   `json:"count"`
+	Events []CreateEventResponse `json:"events"`
+	// ConsistencyToken makes history reads sent with it in the Consistency-Token header see every
+	// event of the batch but the views held for deduplication; absent when the batch only holds
+	// events of test sessions or held views
+	ConsistencyToken string `json:"consistencyToken,omitempty"`
+}
 
-	return true
+// BatchEventError defines the problem document of a batch rejected because of one of its events
+type BatchEventError struct {
+	*domain.APIError
+	// Index is the position of the rejected event in the batch
+	Index int `json:"index" example:"3"`
 }
 
 // CreateEvent handles POST /api/v1/events
 // @Summary Create a new audit event
-// @Description Creates a new audit event for a session
+// @Description Creates a new audit event for a session. Accepts a JSON or protobuf event; an NDJSON body is ingested as a batch. Events over the daily quota of their session or user are rejected with 429 and a Retry-After header. Review workflow events that the review state of their slide or shape does not allow are rejected with 409.
 // @Tags Audit
-// @Accept json
-// @Produce json
+// @Accept json,application/protobuf,application/x-ndjson
+// @Produce json,application/protobuf
 // @Param request body CreateEventRequest true "Event details"
+// @Param Idempotency-Key header string false "Key deduplicating retried submissions; defaults to the event id"
 // @Security BearerAuth
+// @Security APIKeyAuth
 // @Success 201 {object} CreateEventResponse
 // @Failure 400 {object} domain.APIError
 // @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Failure 413 {object} domain.APIError
+// @Failure 422 {object} domain.APIError
+// @Failure 429 {object} domain.APIError
 // @Failure 500 {object} domain.APIError
+// @Failure 503 {object} domain.APIError
 // @Router /events [post]
 func (h *EventsHandler) CreateEvent(c *gin.Context) {
-	var req CreateEventRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_request",
-			"message": "Invalid request body: " + err.Error(),
-		})
+	// NDJSON is a stream of events, so it is ingested as a batch
+	if c.ContentType() == mimeNDJSON {
+		h.CreateEventsBatch(c)
+		return
+	}
+
+	req, apiErr := decodeEventRequest(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	entry, apiErr := h.buildEntry(c, req)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
 		return
 	}
 
-	// Check session ID validity
-	if !checkValidSessionID(req.SessionID) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_session_id",
-			"message": "Invalid session ID format",
-		})
+	if _, err := h.authorizeSessions(c, []domain.AuditEntry{entry}); err != nil {
+		middleware.WriteError(c, domain.ToAPIError(err))
 		return
 	}
 
-	// Get user ID from authentication
+	// Retries reuse the event ID when the client did not send an explicit key
+	idempotencyKey := c.GetHeader(idempotencyKeyHeader)
+	if idempotencyKey == "" && req.ID != "" {
+		idempotencyKey = "event:" + req.ID
+	}
+
+	claim, done := h.beginIdempotent(c, idempotencyKey, req)
+	if done {
+		return
+	}
+
+	response := newCreateEventResponse(entry)
+
+	// For test sessions, store the event in memory
+	if domain.SessionID(entry.SessionID).IsTestSession() {
+		h.testEvents.AddEvent(entry)
+
+		h.logger.Info("created test event",
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.String("event_id", entry.ID),
+			zap.String("session_id", entry.SessionID),
+			zap.String("type", entry.Type),
+		)
+	} else {
+		// Review events must follow the review workflow of their slide or shape
+		if _, err := h.reviews.Check(c.Request.Context(), []domain.AuditEntry{entry}); err != nil {
+			h.releaseIdempotent(c, claim)
+			middleware.WriteError(c, domain.ToAPIError(err))
+			return
+		}
+
+		reservation, err := h.quotas.Reserve(c.Request.Context(), []domain.AuditEntry{entry})
+		if err != nil {
+			h.releaseIdempotent(c, claim)
+			writeQuotaExceeded(c, err)
+			return
+		}
+
+		// Views are held for the deduplication window, and stored when it closes
+		heldID, outcome := h.views.Add(entry)
+		switch outcome {
+		case viewdedup.Collapsed:
+			response.ID = heldID
+			response.Collapsed = true
+		case viewdedup.Passed:
+			if err := h.service.CreateEvent(c.Request.Context(), entry); err != nil {
+				h.logger.Error("failed to persist event",
+					zap.String("request_id", middleware.GetRequestID(c)),
+					zap.String("event_id", entry.ID),
+					zap.String("session_id", entry.SessionID),
+					zap.Error(err),
+				)
+				h.quotas.Release(c.Request.Context(), reservation)
+				h.releaseIdempotent(c, claim)
+				apiErr := domain.ToAPIError(err)
+				middleware.WriteError(c, apiErr)
+				return
+			}
+			response.ConsistencyToken = h.consistencyToken([]domain.AuditEntry{entry})
+		}
+	}
+
+	// Collapsed views are not published; the view they were counted into was
+	if !response.Collapsed {
+		h.broker.Publish(entry)
+	}
+
+	h.respondIdempotent(c, claim, http.StatusCreated, response)
+}
+
+// CreateEventsBatch handles POST /api/v1/events/batch
+// @Summary Create multiple audit events
+// @Description Creates up to 100 JSON events, or up to 5000 NDJSON or protobuf events, in a single request and persists them in one database call. Nothing is stored when an event is invalid; the error names the index of the first invalid event. Review workflow events are checked in order, so a batch may take a slide through several review states.
+// @Tags Audit
+// @Accept json,application/x-ndjson,application/protobuf
+// @Produce json,application/protobuf
+// @Param request body []CreateEventRequest true "Events to create"
+// @Param Idempotency-Key header string false "Key deduplicating retried submissions of the batch"
+// @Param Content-Encoding header string false "gzip or deflate when the body is compressed"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Success 201 {object} BatchCreateEventResponse
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Failure 413 {object} domain.APIError
+// @Failure 422 {object} BatchEventError
+// @Failure 429 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /events/batch [post]
+func (h *EventsHandler) CreateEventsBatch(c *gin.Context) {
+	reqs, limit, apiErr := decodeEventBatch(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	if len(reqs) == 0 {
+		middleware.WriteError(c, domain.NewAPIError("invalid_request", "Batch must contain at least one event", http.StatusBadRequest))
+		return
+	}
+
+	if len(reqs) > limit {
+		middleware.WriteError(c, domain.NewAPIError("batch_too_large", fmt.Sprintf("Batch must not contain more than %d events", limit), http.StatusBadRequest))
+		return
+	}
+
+	// Validate every event before persisting anything
+	entries := make([]domain.AuditEntry, 0, len(reqs))
+	seenIDs := make(map[string]bool, len(reqs))
+	for i, req := range reqs {
+		entry, apiErr := h.buildEntry(c, req)
+		if apiErr == nil && req.ID != "" && seenIDs[req.ID] {
+			apiErr = domain.NewAPIError("duplicate_event_id", "Event ID appears more than once in the batch", http.StatusBadRequest)
+		}
+		if apiErr != nil {
+			problem := middleware.Problem(c, apiErr)
+			problem.Message = fmt.Sprintf("Event %d: %s", i, apiErr.Message)
+			middleware.WriteProblem(c, apiErr.Status, BatchEventError{APIError: problem, Index: i})
+			return
+		}
+		seenIDs[req.ID] = true
+		entries = append(entries, entry)
+	}
+
+	// The whole batch is rejected when one of its sessions is not the caller's
+	if i, err := h.authorizeSessions(c, entries); err != nil {
+		apiErr := domain.ToAPIError(err)
+		if !errors.Is(err, domain.ErrForbidden) && !errors.Is(err, domain.ErrNotFound) {
+			middleware.WriteError(c, apiErr)
+			return
+		}
+		problem := middleware.Problem(c, apiErr)
+		problem.Message = fmt.Sprintf("Event %d: %s", i, apiErr.Message)
+		middleware.WriteProblem(c, apiErr.Status, BatchEventError{APIError: problem, Index: i})
+		return
+	}
+
+	claim, done := h.beginIdempotent(c, c.GetHeader(idempotencyKeyHeader), reqs)
+	if done {
+		return
+	}
+
+	// The whole batch is rejected when one of its review events breaks the review workflow
+	if i, err := h.reviews.Check(c.Request.Context(), entries); err != nil {
+		h.releaseIdempotent(c, claim)
+		apiErr := domain.ToAPIError(err)
+		if !errors.Is(err, domain.ErrInvalidReviewTransition) {
+			middleware.WriteError(c, apiErr)
+			return
+		}
+		problem := middleware.Problem(c, apiErr)
+		problem.Message = fmt.Sprintf("Event %d: %s", i, apiErr.Message)
+		middleware.WriteProblem(c, apiErr.Status, BatchEventError{APIError: problem, Index: i})
+		return
+	}
+
+	// Test sessions stay in memory, everything else goes to storage in one call
+	var stored []domain.AuditEntry
+	for _, entry := range entries {
+		if domain.SessionID(entry.SessionID).IsTestSession() {
+			h.testEvents.AddEvent(entry)
+			continue
+		}
+		stored = append(stored, entry)
+	}
+
+	// The whole batch is rejected when it does not fit the quotas of its sessions and users
+	reservation, err := h.quotas.Reserve(c.Request.Context(), stored)
+	if err != nil {
+		h.releaseIdempotent(c, claim)
+		writeQuotaExceeded(c, err)
+		return
+	}
+
+	// Views are held for the deduplication window first, so the rest of the batch is stored in
+	// one call; they are withdrawn when that call fails
+	written := stored
+	var views []domain.AuditEntry
+	collapsedInto := make(map[string]string)
+	if h.views != nil {
+		written = make([]domain.AuditEntry, 0, len(stored))
+		for _, entry := range stored {
+			if domain.AuditAction(entry.Type) != domain.ActionView {
+				written = append(written, entry)
+				continue
+			}
+			switch heldID, outcome := h.views.Add(entry); outcome {
+			case viewdedup.Collapsed:
+				collapsedInto[entry.ID] = heldID
+				views = append(views, entry)
+			case viewdedup.Held:
+				views = append(views, entry)
+			default:
+				written = append(written, entry)
+			}
+		}
+	}
+
+	if err := h.service.CreateEvents(c.Request.Context(), written); err != nil {
+		h.logger.Error("failed to persist event batch",
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.Int("count", len(written)),
+			zap.Error(err),
+		)
+		for i := len(views) - 1; i >= 0; i-- {
+			h.views.Withdraw(views[i])
+		}
+		h.quotas.Release(c.Request.Context(), reservation)
+		h.releaseIdempotent(c, claim)
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	response := BatchCreateEventResponse{
+		Count:            len(entries),
+		Events:           make([]CreateEventResponse, len(entries)),
+		ConsistencyToken: h.consistencyToken(written),
+	}
+	for i, entry := range entries {
+		response.Events[i] = newCreateEventResponse(entry)
+		if heldID, ok := collapsedInto[entry.ID]; ok {
+			response.Events[i].ID = heldID
+			response.Events[i].Collapsed = true
+			continue
+		}
+		h.broker.Publish(entry)
+	}
+
+	h.logger.Info("created event batch",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.Int("count", len(entries)),
+		zap.Int("stored", len(stored)),
+		zap.Int("collapsed", len(collapsedInto)),
+	)
+
+	h.respondIdempotent(c, claim, http.StatusCreated, response)
+}
+
+// authorizeSessions checks that a user signed in with a JWT may access the sessions of entries
+// before events are recorded in them, so users cannot forge the history of sessions of others.
+// Services authenticated with an API key record events in any session of their organization.
+// It returns the index of the first entry of a session that was refused.
+func (h *EventsHandler) authorizeSessions(c *gin.Context, entries []domain.AuditEntry) (int, error) {
+	if middleware.GetAuthTokenType(c) != middleware.TokenTypeJWT {
+		return 0, nil
+	}
+
+	checked := make(map[string]bool)
+	for i, entry := range entries {
+		sessionID := domain.SessionID(entry.SessionID)
+		if checked[entry.SessionID] || sessionID.IsTestSession() {
+			continue
+		}
+		checked[entry.SessionID] = true
+
+		if err := h.sessions.AuthorizeSession(c.Request.Context(), sessionID, domain.UserID(entry.UserID), false); err != nil {
+			h.logger.Warn("event write to session refused",
+				zap.String("request_id", middleware.GetRequestID(c)),
+				zap.String("session_id", entry.SessionID),
+				zap.String("user_id", entry.UserID),
+				zap.Error(err),
+			)
+			return i, err
+		}
+	}
+	return 0, nil
+}
+
+// idempotencyClaim identifies a request that claimed an idempotency key
+type idempotencyClaim struct {
+	key         string
+	fingerprint string
+}
+
+// beginIdempotent claims the idempotency key of a request. It returns nil when the request
+// has no key, and done when a response (replayed or error) has already been written.
+func (h *EventsHandler) beginIdempotent(c *gin.Context, key string, payload interface{}) (claim *idempotencyClaim, done bool) {
+	if key == "" {
+		return nil, false
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		apiErr := domain.NewAPIError("invalid_idempotency_key",
+			fmt.Sprintf("Idempotency key must not exceed %d characters", maxIdempotencyKeyLength), http.StatusBadRequest)
+		middleware.WriteError(c, apiErr)
+		return nil, true
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteError(c, apiErr)
+		return nil, true
+	}
+	sum := sha256.Sum256(body)
+
+	// Keys are scoped per organization, caller and endpoint
 	userID := middleware.GetAuthUserID(c)
 	if userID == "" {
-		// For test requests, create a mock user ID
-		if strings.HasPrefix(req.SessionID, "test-") {
-			userID = "test-user-" + uuid.New().String()
-		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": "Authentication required",
-			})
-			return
+		userID = "anonymous"
+	}
+
+	claim = &idempotencyClaim{
+		key:         middleware.GetAuthOrganizationID(c) + ":" + userID + ":" + c.FullPath() + ":" + key,
+		fingerprint: hex.EncodeToString(sum[:]),
+	}
+
+	result, err := h.idempotency.Begin(c.Request.Context(), claim.key, claim.fingerprint)
+	switch {
+	case errors.Is(err, cache.ErrIdempotencyInProgress):
+		apiErr := domain.NewAPIError("idempotency_in_progress", "A request with this idempotency key is still being processed", http.StatusConflict)
+		middleware.WriteError(c, apiErr)
+		return nil, true
+	case errors.Is(err, cache.ErrIdempotencyKeyReused):
+		apiErr := domain.NewAPIError("idempotency_key_reused", "Idempotency key was already used with a different request body", http.StatusUnprocessableEntity)
+		middleware.WriteError(c, apiErr)
+		return nil, true
+	case result != nil:
+		h.logger.Info("replaying idempotent response",
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.String("user_id", userID),
+		)
+		c.Header(idempotentReplayedHeader, "true")
+		c.Data(result.Status, result.ContentType, result.Body)
+		return nil, true
+	case err != nil:
+		// Without the shared store the request is processed once more rather than refused
+		h.logger.Warn("idempotency store unavailable",
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.Error(err),
+		)
+		return nil, false
+	}
+
+	return claim, false
+}
+
+// respondIdempotent writes the response in the negotiated format and stores it for retries of the claimed key
+func (h *EventsHandler) respondIdempotent(c *gin.Context, claim *idempotencyClaim, status int, response interface{}) {
+	contentType, body, err := encodeEventResponse(c, response)
+	if err != nil {
+		h.releaseIdempotent(c, claim)
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+
+	if claim != nil {
+		result := &cache.IdempotentResult{Status: status, ContentType: contentType, Body: body}
+		if err := h.idempotency.Complete(c.Request.Context(), claim.key, claim.fingerprint, result); err != nil {
+			h.logger.Warn("failed to store idempotent response", zap.Error(err))
 		}
 	}
 
-	// Parse timestamp or use current time
-	timestamp := time.Now().UTC()
-	if req.Timestamp != "" {
-		parsedTime, err := time.Parse(time.RFC3339, req.Timestamp)
-		if err == nil {
-			timestamp = parsedTime
+	c.Data(status, contentType, body)
+}
+
+// releaseIdempotent frees the claimed key so a failed request can be retried
+func (h *EventsHandler) releaseIdempotent(c *gin.Context, claim *idempotencyClaim) {
+	if claim == nil {
+		return
+	}
+	if err := h.idempotency.Release(c.Request.Context(), claim.key); err != nil {
+		// The key stays claimed until IDEMPOTENCY_TTL expires
+		h.logger.Warn("failed to release idempotency key", zap.Error(err))
+	}
+}
+
+// buildEntry validates an event request and converts it into an audit entry
+func (h *EventsHandler) buildEntry(c *gin.Context, req CreateEventRequest) (domain.AuditEntry, *domain.APIError) {
+	// Test sessions are kept in memory and need no UUID
+	sessionID := domain.SessionID(req.SessionID)
+	if !sessionID.IsTestSession() {
+		parsed, err := domain.ParseSessionID(req.SessionID)
+		if err != nil {
+			return domain.AuditEntry{}, domain.NewAPIError("invalid_session_id", "Invalid session ID format", http.StatusBadRequest)
 		}
+		sessionID = parsed
+	}
+
+	if req.Type == "" {
+		return domain.AuditEntry{}, domain.NewAPIError("invalid_request", "Event type is required", http.StatusBadRequest)
 	}
 
-	// Create response with generated ID
+	// Only built-in types and types registered through /event-types are recorded
+	if _, ok := h.schemas.LookupEventType(req.Type); !ok {
+		return domain.AuditEntry{}, domain.ToAPIError(domain.ErrUnknownEventType)
+	}
+
+	// Client-generated IDs make retries detectable
 	eventID := uuid.New().String()
-	response := CreateEventResponse{
-		ID:        eventID,
-		SessionID: req.SessionID,
-		UserID:    userID,
-		Type:      req.Type,
-		Timestamp: timestamp.Format(time.RFC3339),
-		Success:   true,
+	if req.ID != "" {
+		parsed, err := domain.ParseEventID(req.ID)
+		if err != nil {
+			return domain.AuditEntry{}, domain.NewAPIError("invalid_event_id", "Event ID must be a UUID", http.StatusBadRequest)
+		}
+		eventID = parsed.String()
 	}
 
-	// For test sessions, store the event in memory
-	if strings.HasPrefix(req.SessionID, "test-") {
-		// Convert the details to json.RawMessage
-		var detailsJSON json.RawMessage
-		if req.Details != nil {
-			// Convert details to JSON
-			detailsBytes, err := json.Marshal(req.Details)
+	if !domain.ValidCorrelationID(req.CorrelationID) {
+		return domain.AuditEntry{}, domain.NewAPIError("invalid_correlation_id",
+			fmt.Sprintf("Correlation ID must be at most %d printable characters without spaces", domain.MaxCorrelationIDLength),
+			http.StatusBadRequest)
+	}
+
+	var parentEventID string
+	if req.ParentEventID != "" {
+		parsed, err := domain.ParseEventID(req.ParentEventID)
+		if err != nil {
+			return domain.AuditEntry{}, domain.NewAPIError("invalid_parent_event_id", "Parent event ID must be a UUID", http.StatusBadRequest)
+		}
+		parentEventID = parsed.String()
+	}
+
+	// Get user and organization from authentication
+	userID := middleware.GetAuthUserID(c)
+	organizationID := middleware.GetAuthOrganizationID(c)
+	if middleware.GetAuthTokenType(c) == middleware.TokenTypeAPIKey {
+		// Services record actions on behalf of the user who triggered them
+		if req.UserID != "" {
+			userID = req.UserID
+		}
+		organizationID = req.OrganizationID
+	}
+	if userID == "" {
+		// For test requests, create a mock user ID
+		if !sessionID.IsTestSession() {
+			return domain.AuditEntry{}, domain.NewAPIError("unauthorized", "Authentication required", http.StatusUnauthorized)
+		}
+		userID = "test-user-" + uuid.New().String()
+	}
+
+	// The server time is recorded as the received time; the client timestamp, or the received
+	// time without one, must fall within the skew window of it
+	receivedAt := h.clock.Now()
+	timestamp := receivedAt
+	if req.Timestamp != "" {
+		parsedTime, err := time.Parse(time.RFC3339, req.Timestamp)
+		if err == nil {
+			timestamp, err = h.timestamps.Apply(parsedTime.UTC(), receivedAt)
 			if err != nil {
-				h.logger.Warn("failed to marshal details",
-					zap.String("session_id", req.SessionID),
-					zap.Error(err),
-				)
-				// Use empty JSON object if marshaling fails
-				detailsJSON = json.RawMessage("{}")
-			} else {
-				detailsJSON = detailsBytes
+				return domain.AuditEntry{}, domain.ToAPIError(err)
 			}
-		} else {
-			// Use empty JSON object if details is nil
-			detailsJSON = json.RawMessage("{}")
 		}
+	}
 
-		entry := domain.AuditEntry{
-			ID:        eventID,
-			SessionID: req.SessionID,
-			UserID:    userID,
-			Type:      string(req.Type),
-			Timestamp: timestamp,
-			Details:   detailsJSON,
+	// Convert the details to json.RawMessage, using an empty object if missing or invalid
+	detailsJSON := json.RawMessage("{}")
+	if req.Details != nil {
+		detailsBytes, err := domain.EncodeDetails(req.Details)
+		if err != nil {
+			h.logger.Warn("failed to marshal details",
+				zap.String("request_id", middleware.GetRequestID(c)),
+				zap.String("session_id", req.SessionID),
+				zap.Error(err),
+			)
+		} else {
+			detailsJSON = detailsBytes
 		}
-		h.testEvents.AddEvent(entry)
+	}
 
-		h.logger.Info("created test event",
-			zap.String("event_id", eventID),
-			zap.String("session_id", req.SessionID),
-			zap.String("type", string(req.Type)),
-		)
-	} else {
-		// For real sessions, we would store in the database
-		// But for now, just log it
-		h.logger.Info("created event",
-			zap.String("event_id", eventID),
+	// Reject details that downstream consumers cannot interpret
+	if req.SchemaVersion < 0 {
+		return domain.AuditEntry{}, domain.NewAPIError("invalid_schema_version", "Schema version must be positive", http.StatusBadRequest)
+	}
+	schemaVersion := max(req.SchemaVersion, 1)
+	if err := h.schemas.ValidateVersion(req.Type, schemaVersion, detailsJSON); err != nil {
+		return domain.AuditEntry{}, domain.ToAPIError(err)
+	}
+
+	// Personal data is masked after validation so schemas see the details as sent
+	detailsJSON, redactedFields, err := h.redactor.Redact(detailsJSON)
+	if err != nil {
+		h.logger.Error("failed to redact details",
+			zap.String("request_id", middleware.GetRequestID(c)),
 			zap.String("session_id", req.SessionID),
-			zap.String("user_id", userID),
-			zap.String("type", string(req.Type)),
+			zap.Error(err),
 		)
+		return domain.AuditEntry{}, domain.ToAPIError(err)
+	}
+
+	// Edits store the diff of their text in place of the texts when it is smaller, computed from
+	// the masked text so it discloses nothing the details do not; any diff sent by the client is
+	// dropped
+	detailsJSON = domain.WithEditDiff(req.Type, schemaVersion, detailsJSON)
+
+	// Oversized details are rejected with the size to cut rather than stored in the audit table.
+	// The size is that of the details as stored, masked and with edits diffed.
+	if h.maxDetails > 0 && len(detailsJSON) > h.maxDetails {
+		return domain.AuditEntry{}, domain.ToAPIError(domain.NewPayloadTooLargeError(domain.PayloadDetails, int64(len(detailsJSON)), int64(h.maxDetails)))
+	}
+
+	// Slide and shape references are indexed for slide histories
+	slideID, shapeID, ok := domain.SlideRef(detailsJSON)
+	if !ok {
+		return domain.AuditEntry{}, domain.NewAPIError("invalid_slide_ref",
+			fmt.Sprintf("Details slideId and shapeId must be strings of at most %d characters", domain.MaxSlideRefLength),
+			http.StatusBadRequest)
+	}
+
+	// Comment and thread references are indexed for the comments panel
+	commentID, threadID, ok := domain.CommentRef(detailsJSON)
+	if !ok {
+		return domain.AuditEntry{}, domain.NewAPIError("invalid_comment_ref",
+			fmt.Sprintf("Details commentId and threadId must be strings of at most %d characters", domain.MaxCommentRefLength),
+			http.StatusBadRequest)
 	}
 
-	c.JSON(http.StatusCreated, response)
+	// Share and unshare events are correlated by the token ID of their share link, which links
+	// them with the uses of the link recorded by the audit service
+	correlationID := req.CorrelationID
+	if tokenID := domain.ShareTokenRef(req.Type, detailsJSON); tokenID != "" {
+		correlationID = tokenID
+	}
+
+	return domain.AuditEntry{
+		ID:        eventID,
+		SessionID: sessionID.String(),
+		UserID:    userID,
+		Type:      string(req.Type),
+		Timestamp: timestamp,
+		Details:   detailsJSON,
+		IPAddress: middleware.GetClientIP(c),
+		UserAgent: middleware.GetUserAgent(c),
+		// Server time the event arrived at
+		ReceivedAt: &receivedAt,
+		// Causal links supplied by the client
+		CorrelationID: correlationID,
+		ParentEventID: parentEventID,
+		SchemaVersion: schemaVersion,
+		// Details masked by the redaction stage
+		RedactedFields: redactedFields,
+		OrganizationID: organizationID,
+		SlideID:        slideID,
+		ShapeID:        shapeID,
+		CommentID:      commentID,
+		ThreadID:       threadID,
+	}, nil
+}
+
+// consistencyToken returns the encoded consistency token of a write storing entries, or an
+// empty string when it stores none
+func (h *EventsHandler) consistencyToken(entries []domain.AuditEntry) string {
+	token := domain.NewConsistencyToken(entries, h.clock.Now())
+	if token == nil {
+		return ""
+	}
+	return token.Encode()
+}
+
+// newCreateEventResponse builds the API response for a created entry
+func newCreateEventResponse(entry domain.AuditEntry) CreateEventResponse {
+	return CreateEventResponse{
+		ID:        entry.ID,
+		SessionID: entry.SessionID,
+		UserID:    entry.UserID,
+		Type:      domain.AuditAction(entry.Type),
+		Timestamp: entry.Timestamp.Format(time.RFC3339),
+		Success:   true,
+
+		RedactedFields: entry.RedactedFields,
+	}
 }
 
 // RegisterRoutes registers the events handler routes
@@ -242,5 +807,6 @@ func (h *EventsHandler) RegisterRoutes(router *gin.Engine) {
 	api := router.Group("/api/v1")
 	{
 		api.POST("/events", h.CreateEvent)
+		api.POST("/events/batch", h.CreateEventsBatch)
 	}
 }
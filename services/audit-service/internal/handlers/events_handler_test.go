@@ -0,0 +1,1395 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"audit-service/internal/broadcast"
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+	"audit-service/internal/quota"
+	"audit-service/internal/redact"
+	"audit-service/internal/review"
+	"audit-service/internal/viewdedup"
+	"audit-service/pkg/cache"
+	"audit-service/pkg/clock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+var testSchemas = mustDefaultSchemas()
+
+func mustDefaultSchemas() *domain.SchemaRegistry {
+	schemas, err := domain.NewDefaultSchemaRegistry()
+	if err != nil {
+		panic(err)
+	}
+	return schemas
+}
+
+// allowSessions lets every user record events in every session
+type allowSessions struct{}
+
+func (allowSessions) AuthorizeSession(context.Context, domain.SessionID, domain.UserID, bool) error {
+	return nil
+}
+
+func performCreateEvent(t *testing.T, handler *EventsHandler, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/events", bytes.NewBuffer(payload))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.CreateEvent(c)
+	return w
+}
+
+func TestEventsHandler_CreateEvent_UsesClockForDefaultTimestamp(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	fakeClock := clock.NewFakeClock(testNow)
+	handler := NewEventsHandler(new(MockAuditService), allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, fakeClock, zap.NewNop())
+
+	w := performCreateEvent(t, handler, map[string]interface{}{
+		"sessionId": "test-session-1",
+		"type":      "edit",
+	})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response CreateEventResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, testNow.Format(time.RFC3339), response.Timestamp)
+
+	events, total := handler.testEvents.GetEvents("test-session-1", 10, 0)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, testNow, events[0].Timestamp)
+}
+
+func TestEventsHandler_CreateEvent_NormalizesTimestampToUTC(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	fakeClock := clock.NewFakeClock(testNow)
+	handler := NewEventsHandler(new(MockAuditService), allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, fakeClock, zap.NewNop())
+
+	w := performCreateEvent(t, handler, map[string]interface{}{
+		"sessionId": "test-session-1",
+		"type":      "edit",
+		"timestamp": "2024-01-15T12:30:00+03:00",
+	})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response CreateEventResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "2024-01-15T09:30:00Z", response.Timestamp)
+}
+
+func TestEventsHandler_CreateEvent_FutureTimestamp(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		timestamp      time.Time
+		expectedStatus int
+	}{
+		{
+			name:           "within allowed skew",
+			timestamp:      testNow.Add(domain.DefaultTimestampPolicy.MaxFutureSkew),
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "beyond allowed skew",
+			timestamp:      testNow.Add(domain.DefaultTimestampPolicy.MaxFutureSkew + time.Second),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "past timestamp",
+			timestamp:      testNow.Add(-24 * time.Hour),
+			expectedStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClock := clock.NewFakeClock(testNow)
+			handler := NewEventsHandler(new(MockAuditService), allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, fakeClock, zap.NewNop())
+
+			w := performCreateEvent(t, handler, map[string]interface{}{
+				"sessionId": "test-session-1",
+				"type":      "edit",
+				"timestamp": tt.timestamp.Format(time.RFC3339),
+			})
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestEventsHandler_CreateEvent_FutureTimestampBecomesValid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	fakeClock := clock.NewFakeClock(testNow)
+	handler := NewEventsHandler(new(MockAuditService), allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, fakeClock, zap.NewNop())
+	body := map[string]interface{}{
+		"sessionId": "test-session-1",
+		"type":      "edit",
+		"timestamp": testNow.Add(time.Hour).Format(time.RFC3339),
+	}
+
+	w := performCreateEvent(t, handler, body)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Once server time catches up, the same timestamp is accepted
+	fakeClock.Advance(time.Hour)
+
+	w = performCreateEvent(t, handler, body)
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestEventsHandler_CreateEvent_ClampsTimestamp(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	policy := domain.TimestampPolicy{MaxFutureSkew: time.Minute, MaxPastSkew: time.Hour, Mode: domain.SkewClamp}
+	fakeClock := clock.NewFakeClock(testNow)
+	handler := NewEventsHandler(new(MockAuditService), allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), policy, domain.DefaultMaxDetailsSize, nil, nil, nil, fakeClock, zap.NewNop())
+
+	tests := []struct {
+		name      string
+		timestamp time.Time
+		expected  time.Time
+	}{
+		{name: "ahead", timestamp: testNow.Add(time.Hour), expected: testNow.Add(time.Minute)},
+		{name: "behind", timestamp: testNow.Add(-24 * time.Hour), expected: testNow.Add(-time.Hour)},
+		{name: "within", timestamp: testNow.Add(-time.Minute), expected: testNow.Add(-time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performCreateEvent(t, handler, map[string]interface{}{
+				"sessionId": "test-session-clamp-" + tt.name,
+				"type":      "edit",
+				"timestamp": tt.timestamp.Format(time.RFC3339),
+			})
+			require.Equal(t, http.StatusCreated, w.Code)
+
+			entries, _ := handler.testEvents.GetEvents("test-session-clamp-"+tt.name, 10, 0)
+			require.Len(t, entries, 1)
+			assert.Equal(t, tt.expected, entries[0].Timestamp)
+			require.NotNil(t, entries[0].ReceivedAt)
+			assert.Equal(t, testNow, *entries[0].ReceivedAt)
+		})
+	}
+}
+
+func TestEventsHandler_CreateEvent_RejectsOversizedDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewEventsHandler(new(MockAuditService), allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, 64, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	w := performCreateEvent(t, handler, map[string]interface{}{
+		"sessionId": "test-session-details-limit",
+		"type":      "view",
+		"details":   map[string]string{"note": strings.Repeat("a", 100)},
+	})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	var problem domain.APIError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, "payload_too_large", problem.Code)
+	assert.Equal(t, &domain.PayloadLimit{Field: "details", LimitBytes: 64, SizeBytes: 111, ExcessBytes: 47}, problem.Limit)
+
+	w = performCreateEvent(t, handler, map[string]interface{}{
+		"sessionId": "test-session-details-limit",
+		"type":      "view",
+		"details":   map[string]string{"note": "short"},
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func performCreateEventsBatch(t *testing.T, handler *EventsHandler, body interface{}, userID string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/events/batch", bytes.NewBuffer(payload))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if userID != "" {
+		c.Set(middleware.AuthUserIDKey, userID)
+	}
+
+	handler.CreateEventsBatch(c)
+	return w
+}
+
+func TestEventsHandler_CreateEventsBatch_PersistsInSingleCall(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvents", mock.Anything, mock.MatchedBy(func(entries []domain.AuditEntry) bool {
+		return len(entries) == 3 &&
+			entries[0].UserID == "user-456" &&
+			entries[2].Type == string(domain.ActionComment)
+	})).Return(nil).Once()
+
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	w := performCreateEventsBatch(t, handler, []map[string]interface{}{
+		{"sessionId": sessionID, "type": "edit", "details": map[string]interface{}{"slide": 1}},
+		{"sessionId": sessionID, "type": "edit", "details": map[string]interface{}{"slide": 2}},
+		{"sessionId": sessionID, "type": "comment", "details": map[string]interface{}{"text": "Looks good"}},
+	}, "user-456")
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response BatchCreateEventResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 3, response.Count)
+	assert.Len(t, response.Events, 3)
+	for _, event := range response.Events {
+		assert.True(t, event.Success)
+		assert.NotEmpty(t, event.ID)
+	}
+
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_CreateEventsBatch_QuotaExceeded(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvents", mock.Anything, mock.Anything).Return(nil).Once()
+
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, newTestQuotas(quota.Config{SessionDaily: 3}), nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	batch := []map[string]interface{}{
+		{"sessionId": sessionID, "type": "edit", "details": map[string]interface{}{"slide": 1}},
+		{"sessionId": sessionID, "type": "edit", "details": map[string]interface{}{"slide": 2}},
+	}
+	w := performCreateEventsBatch(t, handler, batch, "user-456")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// The second batch does not fit in what is left of the quota and is rejected as a whole
+	w = performCreateEventsBatch(t, handler, batch, "user-456")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "50400", w.Header().Get("Retry-After"))
+
+	var problem domain.APIError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, "quota_exceeded", problem.Code)
+	assert.Equal(t, &domain.QuotaUsage{
+		Scope: "session", Limit: 3, Used: 2, Remaining: 1,
+		ResetAt: time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC),
+	}, problem.Quota)
+	mockService.AssertExpectations(t)
+}
+
+// emptyReviewStore holds no review events, so every slide and shape is in draft
+type emptyReviewStore struct{}
+
+func (emptyReviewStore) QueryEvents(context.Context, domain.SessionID, domain.EventFilter, domain.PaginationParams) ([]domain.AuditEntry, int, error) {
+	return nil, 0, nil
+}
+
+func TestEventsHandler_CreateEventsBatch_ReviewTransition(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvents", mock.Anything, mock.Anything).Return(nil).Once()
+
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, review.New(emptyReviewStore{}, zap.NewNop()), nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	submitted := map[string]interface{}{"sessionId": sessionID, "type": "submitted_for_review", "details": map[string]interface{}{"slideId": "slide-3"}}
+	approved := map[string]interface{}{"sessionId": sessionID, "type": "approved", "details": map[string]interface{}{"slideId": "slide-3"}}
+
+	w := performCreateEventsBatch(t, handler, []map[string]interface{}{submitted, approved}, "user-456")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// Approving a slide twice breaks the workflow and rejects the whole batch
+	w = performCreateEventsBatch(t, handler, []map[string]interface{}{submitted, approved, approved}, "user-456")
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	var problem BatchEventError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, "invalid_review_transition", problem.Code)
+	assert.Equal(t, 2, problem.Index)
+	assert.Equal(t, "Event 2: approved cannot be recorded for slide slide-3 in state approved; allowed events: reopened", problem.Message)
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_CreateEventsBatch_RedactsDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rules, err := redact.ParseRules("email,phone", "")
+	require.NoError(t, err)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvents", mock.Anything, mock.MatchedBy(func(entries []domain.AuditEntry) bool {
+		return len(entries) == 2 &&
+			string(entries[0].Details) == `{"text":"Mail [REDACTED:email]"}` &&
+			assert.ObjectsAreEqual([]string{"text"}, entries[0].RedactedFields) &&
+			string(entries[1].Details) == `{"text":"Looks good"}` &&
+			entries[1].RedactedFields == nil
+	})).Return(nil).Once()
+
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, redact.New(rules), newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	w := performCreateEventsBatch(t, handler, []map[string]interface{}{
+		{"sessionId": sessionID, "type": "comment", "details": map[string]interface{}{"text": "Mail jane.doe@example.com"}},
+		{"sessionId": sessionID, "type": "comment", "details": map[string]interface{}{"text": "Looks good"}},
+	}, "user-456")
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var response BatchCreateEventResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{"text"}, response.Events[0].RedactedFields)
+	assert.Empty(t, response.Events[1].RedactedFields)
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_CreateEventsBatch_TestSessionsStayInMemory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvents", mock.Anything, []domain.AuditEntry(nil)).Return(nil).Once()
+
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	w := performCreateEventsBatch(t, handler, []map[string]interface{}{
+		{"sessionId": "test-session-1", "type": "edit"},
+		{"sessionId": "test-session-1", "type": "view"},
+	}, "")
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	_, total := handler.testEvents.GetEvents("test-session-1", 10, 0)
+	assert.Equal(t, 2, total)
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_CreateEventsBatch_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tooMany := make([]map[string]interface{}, maxBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = map[string]interface{}{"sessionId": "test-session-1", "type": "edit"}
+	}
+
+	tests := []struct {
+		name           string
+		body           interface{}
+		userID         string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "not an array",
+			body:           map[string]interface{}{"sessionId": "test-session-1"},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_request",
+		},
+		{
+			name:           "empty batch",
+			body:           []map[string]interface{}{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_request",
+		},
+		{
+			name:           "batch too large",
+			body:           tooMany,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "batch_too_large",
+		},
+		{
+			name: "invalid session in batch",
+			body: []map[string]interface{}{
+				{"sessionId": "test-session-1", "type": "edit"},
+				{"sessionId": "not-a-uuid", "type": "edit"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "validation_failed",
+		},
+		{
+			name: "nil UUID session in batch",
+			body: []map[string]interface{}{
+				{"sessionId": "00000000-0000-0000-0000-000000000000", "type": "edit"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "validation_failed",
+		},
+		{
+			name: "unauthenticated real session",
+			body: []map[string]interface{}{
+				{"sessionId": "550e8400-e29b-41d4-a716-446655440000", "type": "edit"},
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "unauthorized",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+			w := performCreateEventsBatch(t, handler, tt.body, tt.userID)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedError, response["code"])
+
+			// Nothing is persisted when any event is rejected
+			mockService.AssertNotCalled(t, "CreateEvents", mock.Anything, mock.Anything)
+			_, total := handler.testEvents.GetEvents("test-session-1", 10, 0)
+			assert.Equal(t, 0, total)
+		})
+	}
+}
+
+func TestEventsHandler_CreateEventsBatch_ServiceError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvents", mock.Anything, mock.Anything).
+		Return(errors.New("insert failed"))
+
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	w := performCreateEventsBatch(t, handler, []map[string]interface{}{
+		{"sessionId": "550e8400-e29b-41d4-a716-446655440000", "type": "edit"},
+	}, "user-456")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestEventsHandler_CreateEvent_PersistsRealSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvent", mock.Anything, mock.MatchedBy(func(entry domain.AuditEntry) bool {
+		return entry.SessionID == sessionID &&
+			entry.UserID == "user-456" &&
+			entry.Type == string(domain.ActionEdit) &&
+			entry.Timestamp.Equal(testNow)
+	})).Return(nil).Once()
+
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	payload, _ := json.Marshal(map[string]interface{}{"sessionId": sessionID, "type": "edit"})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/events", bytes.NewBuffer(payload))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.AuthUserIDKey, "user-456")
+
+	handler.CreateEvent(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_CreateEvent_ConsistencyToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	payload, _ := json.Marshal(map[string]interface{}{"sessionId": "550e8400-e29b-41d4-a716-446655440000", "type": "edit"})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/events", bytes.NewBuffer(payload))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.AuthUserIDKey, "user-456")
+
+	handler.CreateEvent(c)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var response CreateEventResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	token, err := domain.DecodeConsistencyToken(response.ConsistencyToken)
+	require.NoError(t, err)
+	assert.Equal(t, response.ID, token.EventID)
+	assert.True(t, testNow.Equal(token.IssuedAt))
+
+	// Test sessions are answered from memory and need no token
+	w = performCreateEvent(t, handler, map[string]interface{}{"sessionId": "test-session-1", "type": "edit"})
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.NotContains(t, w.Body.String(), "consistencyToken")
+}
+
+func TestEventsHandler_CreateEvent_RequiresSessionAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	otherSession := "550e8400-e29b-41d4-a716-446655440099"
+
+	// user-456 owns sessionID; otherSession belongs to another user
+	sessions := new(MockAuditService)
+	sessions.On("AuthorizeSession", mock.Anything, sessionID, "user-456", false).Return(nil)
+	sessions.On("AuthorizeSession", mock.Anything, otherSession, "user-456", false).Return(domain.ErrForbidden)
+	mockService := new(MockAuditService)
+	handler := NewEventsHandler(mockService, sessions, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	payload, _ := json.Marshal(map[string]interface{}{"sessionId": otherSession, "type": "edit"})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/events", bytes.NewBuffer(payload))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.AuthUserIDKey, "user-456")
+	c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+
+	handler.CreateEvent(c)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	// A batch is refused as a whole, naming the first event of the other session
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	payload, _ = json.Marshal([]map[string]interface{}{
+		{"sessionId": sessionID, "type": "edit"},
+		{"sessionId": otherSession, "type": "edit"},
+	})
+	c.Request = httptest.NewRequest("POST", "/api/v1/events/batch", bytes.NewBuffer(payload))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.AuthUserIDKey, "user-456")
+	c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+
+	handler.CreateEventsBatch(c)
+	require.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	var problem BatchEventError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, 1, problem.Index)
+
+	// Nothing was stored
+	mockService.AssertNotCalled(t, "CreateEvent", mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "CreateEvents", mock.Anything, mock.Anything)
+}
+
+func TestEventsHandler_CreateEvent_CollapsesViews(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	clk := clock.NewFakeClock(testNow)
+	var written []domain.AuditEntry
+	views := viewdedup.New(func(_ context.Context, entries []domain.AuditEntry) error {
+		written = append(written, entries...)
+		return nil
+	}, viewdedup.Config{Window: time.Minute, MaxHeld: 100}, clk, zap.NewNop())
+
+	// Views are held rather than stored by the request
+	mockService := new(MockAuditService)
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, views, clk, zap.NewNop())
+	createView := func() CreateEventResponse {
+		payload, _ := json.Marshal(map[string]interface{}{"sessionId": sessionID, "type": "view", "details": map[string]interface{}{"accessMethod": "jwt"}})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/v1/events", bytes.NewBuffer(payload))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set(middleware.AuthUserIDKey, "user-456")
+
+		handler.CreateEvent(c)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var response CreateEventResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	held := createView()
+	assert.False(t, held.Collapsed)
+	assert.Empty(t, held.ConsistencyToken, "held views are not readable until their window closes")
+
+	repeat := createView()
+	assert.True(t, repeat.Collapsed)
+	assert.Equal(t, held.ID, repeat.ID)
+
+	clk.Advance(time.Minute)
+	views.Flush(context.Background())
+	require.Len(t, written, 1)
+	assert.Equal(t, held.ID, written[0].ID)
+	assert.Equal(t, 2, domain.ViewRepeatsOf(written[0]).Count)
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_CreateEventsBatch_CollapsesViews(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	clk := clock.NewFakeClock(testNow)
+	var written []domain.AuditEntry
+	views := viewdedup.New(func(_ context.Context, entries []domain.AuditEntry) error {
+		written = append(written, entries...)
+		return nil
+	}, viewdedup.Config{Window: time.Minute, MaxHeld: 100}, clk, zap.NewNop())
+
+	// Only the other events of the batch are stored by the request
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvents", mock.Anything, mock.MatchedBy(func(entries []domain.AuditEntry) bool {
+		return len(entries) == 1 && entries[0].Type == "edit"
+	})).Return(nil).Once()
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, views, clk, zap.NewNop())
+
+	w := performCreateEventsBatch(t, handler, []map[string]interface{}{
+		{"sessionId": sessionID, "type": "view"},
+		{"sessionId": sessionID, "type": "edit"},
+		{"sessionId": sessionID, "type": "view"},
+	}, "user-456")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var response BatchCreateEventResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Events, 3)
+	assert.False(t, response.Events[0].Collapsed)
+	assert.True(t, response.Events[2].Collapsed)
+	assert.Equal(t, response.Events[0].ID, response.Events[2].ID)
+	token, err := domain.DecodeConsistencyToken(response.ConsistencyToken)
+	require.NoError(t, err)
+	assert.Equal(t, response.Events[1].ID, token.EventID)
+
+	clk.Advance(time.Minute)
+	views.Flush(context.Background())
+	require.Len(t, written, 1)
+	assert.Equal(t, 2, domain.ViewRepeatsOf(written[0]).Count)
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_CreateEventsBatch_WithdrawsViewsOnFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	clk := clock.NewFakeClock(testNow)
+	var written []domain.AuditEntry
+	views := viewdedup.New(func(_ context.Context, entries []domain.AuditEntry) error {
+		written = append(written, entries...)
+		return nil
+	}, viewdedup.Config{Window: time.Minute, MaxHeld: 100}, clk, zap.NewNop())
+
+	// The batch is stored in one call; its views are not held when the call fails
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvents", mock.Anything, mock.MatchedBy(func(entries []domain.AuditEntry) bool {
+		return len(entries) == 1 && entries[0].Type == "edit"
+	})).Return(errors.New("storage unavailable")).Once()
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, views, clk, zap.NewNop())
+
+	w := performCreateEventsBatch(t, handler, []map[string]interface{}{
+		{"sessionId": sessionID, "type": "view"},
+		{"sessionId": sessionID, "type": "edit"},
+		{"sessionId": sessionID, "type": "view"},
+	}, "user-456")
+	assert.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+
+	clk.Advance(time.Minute)
+	assert.Zero(t, views.Flush(context.Background()))
+	assert.Empty(t, written)
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_CreateEvent_StorageErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "storage unavailable",
+			serviceErr:     fmt.Errorf("failed to create audit event: %w", domain.ErrServiceUnavailable),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedCode:   "service_unavailable",
+		},
+		{
+			name:           "event rejected",
+			serviceErr:     fmt.Errorf("failed to create audit event: %w", domain.ErrInvalidEvent),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "invalid_event",
+		},
+		{
+			name:           "unexpected failure",
+			serviceErr:     errors.New("boom"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "internal_server_error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			mockService.On("CreateEvent", mock.Anything, mock.Anything).Return(tt.serviceErr)
+
+			handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+			payload, _ := json.Marshal(map[string]interface{}{
+				"sessionId": "550e8400-e29b-41d4-a716-446655440000",
+				"type":      "edit",
+			})
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/events", bytes.NewBuffer(payload))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set(middleware.AuthUserIDKey, "user-456")
+
+			handler.CreateEvent(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response domain.APIError
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCode, response.Code)
+		})
+	}
+}
+
+func TestEventsHandler_CreateEvent_PublishesToSubscribers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	broker := broadcast.NewBroker(zap.NewNop())
+	sub := broker.Subscribe(broadcast.SessionTopic("test-session-1"))
+	defer sub.Close()
+
+	handler := NewEventsHandler(new(MockAuditService), allowSessions{}, broker, testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	w := performCreateEvent(t, handler, map[string]interface{}{
+		"sessionId": "test-session-1",
+		"type":      "edit",
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var response CreateEventResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	select {
+	case entry := <-sub.Events():
+		assert.Equal(t, response.ID, entry.ID)
+		assert.Equal(t, "edit", entry.Type)
+	default:
+		t.Fatal("expected the created event to be published")
+	}
+}
+
+func TestEventsHandler_CreateEvent_StorageErrorIsNotPublished(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	broker := broadcast.NewBroker(zap.NewNop())
+	sub := broker.Subscribe(broadcast.SessionTopic(sessionID))
+	defer sub.Close()
+
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvent", mock.Anything, mock.Anything).Return(domain.ErrServiceUnavailable)
+
+	handler := NewEventsHandler(mockService, allowSessions{}, broker, testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	payload, _ := json.Marshal(map[string]interface{}{"sessionId": sessionID, "type": "edit"})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/events", bytes.NewBuffer(payload))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.AuthUserIDKey, "user-456")
+
+	handler.CreateEvent(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Len(t, sub.Events(), 0)
+}
+
+func newTestIdempotencyCache() *cache.IdempotencyCache {
+	return cache.NewIdempotencyCache(time.Minute, clock.New())
+}
+
+func performIdempotentCreateEvent(t *testing.T, handler *EventsHandler, body interface{}, key string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/events", bytes.NewBuffer(payload))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		c.Request.Header.Set(idempotencyKeyHeader, key)
+	}
+	c.Set(middleware.AuthUserIDKey, "user-456")
+
+	handler.CreateEvent(c)
+	return w
+}
+
+func TestEventsHandler_CreateEvent_IdempotencyKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvent", mock.Anything, mock.Anything).Return(nil).Once()
+
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+	body := map[string]interface{}{"sessionId": sessionID, "type": "edit"}
+
+	first := performIdempotentCreateEvent(t, handler, body, "retry-1")
+	require.Equal(t, http.StatusCreated, first.Code)
+
+	// The retry is answered from the cache without writing again
+	retry := performIdempotentCreateEvent(t, handler, body, "retry-1")
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(idempotentReplayedHeader))
+	assert.JSONEq(t, first.Body.String(), retry.Body.String())
+
+	// Reusing the key for a different event is rejected
+	reused := performIdempotentCreateEvent(t, handler, map[string]interface{}{"sessionId": sessionID, "type": "view"}, "retry-1")
+	assert.Equal(t, http.StatusUnprocessableEntity, reused.Code)
+	assert.Contains(t, reused.Body.String(), "idempotency_key_reused")
+
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_CreateEvent_LowercasesSessionID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvent", mock.Anything, mock.MatchedBy(func(entry domain.AuditEntry) bool {
+		return entry.SessionID == "550e8400-e29b-41d4-a716-446655440000"
+	})).Return(nil).Once()
+
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+	body := map[string]interface{}{"sessionId": "550E8400-E29B-41D4-A716-446655440000", "type": "edit"}
+
+	w := performIdempotentCreateEvent(t, handler, body, "")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_CreateEvent_ClientEventID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	eventID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvent", mock.Anything, mock.MatchedBy(func(entry domain.AuditEntry) bool {
+		return entry.ID == eventID
+	})).Return(nil).Once()
+
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+	body := map[string]interface{}{"id": eventID, "sessionId": sessionID, "type": "edit"}
+
+	// Without a header the event ID deduplicates retries
+	for i := 0; i < 2; i++ {
+		w := performIdempotentCreateEvent(t, handler, body, "")
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var response CreateEventResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, eventID, response.ID)
+	}
+
+	w := performIdempotentCreateEvent(t, handler, map[string]interface{}{"id": "not-a-uuid", "sessionId": sessionID, "type": "edit"}, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"id"`)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_CreateEvent_CausalLinks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvent", mock.Anything, mock.MatchedBy(func(entry domain.AuditEntry) bool {
+		return entry.CorrelationID == "export-7f3a" && entry.ParentEventID == "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+	})).Return(nil).Once()
+
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	// Parent IDs are normalized like event IDs
+	w := performIdempotentCreateEvent(t, handler, map[string]interface{}{
+		"sessionId": sessionID, "type": "export",
+		"correlationId": "export-7f3a", "parentEventId": "7C9E6679-7425-40DE-944B-E07FC1F90AE7",
+	}, "")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	tests := []struct {
+		name         string
+		body         map[string]interface{}
+		expectedCode string
+	}{
+		{
+			name:         "parent is not a UUID",
+			body:         map[string]interface{}{"sessionId": sessionID, "type": "export", "parentEventId": "event-1"},
+			expectedCode: `"field":"parentEventId"`,
+		},
+		{
+			name:         "correlation ID with spaces",
+			body:         map[string]interface{}{"sessionId": sessionID, "type": "export", "correlationId": "export 7f3a"},
+			expectedCode: "invalid_correlation_id",
+		},
+		{
+			name:         "correlation ID too long",
+			body:         map[string]interface{}{"sessionId": sessionID, "type": "export", "correlationId": strings.Repeat("a", domain.MaxCorrelationIDLength+1)},
+			expectedCode: "invalid_correlation_id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performIdempotentCreateEvent(t, handler, tt.body, "")
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedCode)
+		})
+	}
+
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_CreateEvent_SlideRefs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvent", mock.Anything, mock.MatchedBy(func(entry domain.AuditEntry) bool {
+		return entry.SlideID == "slide-3" && entry.ShapeID == "shape-12"
+	})).Return(nil).Once()
+
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	w := performIdempotentCreateEvent(t, handler, map[string]interface{}{
+		"sessionId": sessionID, "type": "comment",
+		"details": map[string]interface{}{"text": "Check this translation", "slideId": "slide-3", "shapeId": "shape-12"},
+	}, "")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// References that cannot be indexed are rejected
+	w = performIdempotentCreateEvent(t, handler, map[string]interface{}{
+		"sessionId": sessionID, "type": "comment",
+		"details": map[string]interface{}{"text": "Check this translation", "slideId": "slide-3", "shapeId": strings.Repeat("s", domain.MaxSlideRefLength+1)},
+	}, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_slide_ref")
+
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_CreateEvent_StoresEditDiff(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rules, err := redact.ParseRules("email", "")
+	require.NoError(t, err)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	paragraph := "Quarterly revenue grew across every region, led by strong demand for the new product line. "
+	text := map[string]interface{}{"before": paragraph + "Write to us", "after": paragraph + "Write to jane.doe@example.com"}
+	sent, err := json.Marshal(map[string]interface{}{"text": text})
+	require.NoError(t, err)
+
+	// The diff is computed from the masked text and replaces both texts
+	stored := `{"text":{"diff":[{"op":"equal","text":"` + paragraph + `Write to "},{"op":"delete","text":"us"},` +
+		`{"op":"insert","text":"[REDACTED:email]"}]}}`
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvent", mock.Anything, mock.MatchedBy(func(entry domain.AuditEntry) bool {
+		return string(entry.Details) == stored
+	})).Return(nil).Once()
+
+	// The size limit applies to the details as stored, which fit where the details sent do not
+	require.Greater(t, len(sent), len(stored))
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, redact.New(rules), newTestIdempotencyCache(), domain.DefaultTimestampPolicy, len(stored), nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	w := performIdempotentCreateEvent(t, handler, map[string]interface{}{
+		"sessionId": sessionID, "type": "edit", "schemaVersion": 2, "details": map[string]interface{}{"text": text},
+	}, "")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// Edits whose stored details are still too large are rejected
+	text["after"] = "Completely rewritten: " + paragraph
+	w = performIdempotentCreateEvent(t, handler, map[string]interface{}{
+		"sessionId": sessionID, "type": "edit", "schemaVersion": 2, "details": map[string]interface{}{"text": text},
+	}, "")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_CreateEvent_CommentRefs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvent", mock.Anything, mock.MatchedBy(func(entry domain.AuditEntry) bool {
+		return entry.Type == "comment_created" && entry.CommentID == "comment-7" && entry.ThreadID == "thread-2" && entry.SlideID == "slide-3"
+	})).Return(nil).Once()
+
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	w := performIdempotentCreateEvent(t, handler, map[string]interface{}{
+		"sessionId": sessionID, "type": "comment_created",
+		"details": map[string]interface{}{"commentId": "comment-7", "threadId": "thread-2", "text": "Tone is off", "slideId": "slide-3"},
+	}, "")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// Lifecycle events name their thread
+	w = performIdempotentCreateEvent(t, handler, map[string]interface{}{
+		"sessionId": sessionID, "type": "comment_resolved",
+		"details": map[string]interface{}{"commentId": "comment-7"},
+	}, "")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// Other event types may reference comments, but only by string IDs
+	w = performIdempotentCreateEvent(t, handler, map[string]interface{}{
+		"sessionId": sessionID, "type": "view",
+		"details": map[string]interface{}{"commentId": 7},
+	}, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_comment_ref")
+
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_CreateEvent_ShareTokenRefs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvent", mock.Anything, mock.MatchedBy(func(entry domain.AuditEntry) bool {
+		return entry.Type == "share" && entry.CorrelationID == "share-91c2"
+	})).Return(nil).Once()
+	mockService.On("CreateEvent", mock.Anything, mock.MatchedBy(func(entry domain.AuditEntry) bool {
+		return entry.Type == "unshare" && entry.CorrelationID == "share-91c2"
+	})).Return(nil).Once()
+	mockService.On("CreateEvent", mock.Anything, mock.MatchedBy(func(entry domain.AuditEntry) bool {
+		return entry.Type == "export" && entry.CorrelationID == "req-1"
+	})).Return(nil).Once()
+
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	// The token ID of a share link takes precedence over the correlation ID of the request
+	for _, body := range []map[string]interface{}{
+		{"sessionId": sessionID, "type": "share", "correlationId": "req-1",
+			"details": map[string]interface{}{"tokenId": "share-91c2", "permissions": []string{"VIEW"}}},
+		{"sessionId": sessionID, "type": "unshare", "details": map[string]interface{}{"tokenId": "share-91c2"}},
+		// Other event types keep their correlation ID
+		{"sessionId": sessionID, "type": "export", "correlationId": "req-1", "details": map[string]interface{}{"tokenId": "share-91c2"}},
+	} {
+		w := performIdempotentCreateEvent(t, handler, body, "")
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_CreateEvent_SchemaVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+
+	tests := []struct {
+		name            string
+		body            map[string]interface{}
+		expectedStatus  int
+		expectedVersion int
+	}{
+		{
+			name:            "version 1 by default",
+			body:            map[string]interface{}{"sessionId": sessionID, "type": "edit", "details": map[string]interface{}{"before": "Hello", "after": "Hi"}},
+			expectedStatus:  http.StatusCreated,
+			expectedVersion: 1,
+		},
+		{
+			name: "version 2",
+			body: map[string]interface{}{"sessionId": sessionID, "type": "edit", "schemaVersion": 2,
+				"details": map[string]interface{}{"text": map[string]interface{}{"before": "Hello", "after": "Hi"}}},
+			expectedStatus:  http.StatusCreated,
+			expectedVersion: 2,
+		},
+		{
+			name: "version 1 details sent as version 2",
+			body: map[string]interface{}{"sessionId": sessionID, "type": "edit", "schemaVersion": 2,
+				"details": map[string]interface{}{"before": "Hello", "after": "Hi"}},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "unknown version",
+			body:           map[string]interface{}{"sessionId": sessionID, "type": "edit", "schemaVersion": 3},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "negative version",
+			body:           map[string]interface{}{"sessionId": sessionID, "type": "edit", "schemaVersion": -1},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			if tt.expectedStatus == http.StatusCreated {
+				mockService.On("CreateEvent", mock.Anything, mock.MatchedBy(func(entry domain.AuditEntry) bool {
+					return entry.SchemaVersion == tt.expectedVersion
+				})).Return(nil).Once()
+			}
+			handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+			w := performIdempotentCreateEvent(t, handler, tt.body, "")
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEventsHandler_CreateEvent_FailedRequestReleasesKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvent", mock.Anything, mock.Anything).
+		Return(fmt.Errorf("write failed: %w", domain.ErrServiceUnavailable)).Once()
+	mockService.On("CreateEvent", mock.Anything, mock.Anything).Return(nil).Once()
+
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+	body := map[string]interface{}{"sessionId": sessionID, "type": "edit"}
+
+	w := performIdempotentCreateEvent(t, handler, body, "retry-1")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	w = performIdempotentCreateEvent(t, handler, body, "retry-1")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get(idempotentReplayedHeader))
+
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_CreateEventsBatch_DuplicateEventID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewEventsHandler(new(MockAuditService), allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+	eventID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+
+	w := performCreateEventsBatch(t, handler, []map[string]interface{}{
+		{"id": eventID, "sessionId": "test-session-1", "type": "edit"},
+		{"id": eventID, "sessionId": "test-session-1", "type": "edit"},
+	}, "")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "duplicate_event_id")
+}
+
+func TestEventsHandler_CreateEvent_InvalidDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewEventsHandler(new(MockAuditService), allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	w := performCreateEvent(t, handler, map[string]interface{}{
+		"sessionId": "test-session-1",
+		"type":      "comment",
+		"details":   map[string]interface{}{"slideId": 7},
+	})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	var response domain.APIError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "invalid_event_details", response.Code)
+	assert.ElementsMatch(t, []string{"details", "details.slideId"}, []string{response.Violations[0].Field, response.Violations[1].Field})
+
+	_, total := handler.testEvents.GetEvents("test-session-1", 10, 0)
+	assert.Equal(t, 0, total)
+}
+
+func TestEventsHandler_CreateEvent_EventTypes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	schemas := mustDefaultSchemas()
+	require.NoError(t, schemas.DefineEventType(domain.EventType{
+		Name: "terminology_approved", DisplayName: "Terminology approved", Severity: domain.SeverityLow,
+		Schema: json.RawMessage(`{"type":"object","required":["term"]}`),
+	}))
+	handler := NewEventsHandler(new(MockAuditService), allowSessions{}, broadcast.NewBroker(zap.NewNop()), schemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	// Registered types are accepted and their schema enforced
+	w := performCreateEvent(t, handler, map[string]interface{}{
+		"sessionId": "test-session-1",
+		"type":      "terminology_approved",
+		"details":   map[string]interface{}{"term": "Folie"},
+	})
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = performCreateEvent(t, handler, map[string]interface{}{
+		"sessionId": "test-session-1",
+		"type":      "terminology_approved",
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_event_details")
+
+	// Unregistered types are rejected
+	w = performCreateEvent(t, handler, map[string]interface{}{
+		"sessionId": "test-session-1",
+		"type":      "terminology_rejected",
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "unknown_event_type")
+
+	_, total := handler.testEvents.GetEvents("test-session-1", 10, 0)
+	assert.Equal(t, 1, total)
+}
+
+func TestEventsHandler_CreateEventsBatch_InvalidDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewEventsHandler(new(MockAuditService), allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	w := performCreateEventsBatch(t, handler, []map[string]interface{}{
+		{"sessionId": "test-session-1", "type": "edit", "details": map[string]interface{}{"slideId": "slide-1"}},
+		{"sessionId": "test-session-1", "type": "reorder", "details": map[string]interface{}{"fromIndex": 1}},
+	}, "")
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	var response struct {
+		Type       string                   `json:"type"`
+		Code       string                   `json:"code"`
+		Detail     string                   `json:"detail"`
+		Index      int                      `json:"index"`
+		Violations []domain.SchemaViolation `json:"violations"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "urn:audit-service:problem:invalid_event_details", response.Type)
+	assert.Equal(t, "invalid_event_details", response.Code)
+	assert.Contains(t, response.Detail, "Event 1: ")
+	assert.Equal(t, 1, response.Index)
+	assert.Len(t, response.Violations, 1)
+}
+
+func TestEventsHandler_CreateEvent_RecordsClientInfo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockAuditService)
+	mockService.On("CreateEvent", mock.Anything, mock.MatchedBy(func(entry domain.AuditEntry) bool {
+		return entry.IPAddress == "198.51.100.1" && entry.UserAgent == "Mozilla/5.0"
+	})).Return(nil).Once()
+
+	handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+	router := gin.New()
+	router.Use(middleware.ClientInfo(nil, middleware.ForwardedHeaderXForwardedFor), func(c *gin.Context) {
+		c.Set(middleware.AuthUserIDKey, "user-456")
+	})
+	router.POST("/api/v1/events", handler.CreateEvent)
+
+	payload, _ := json.Marshal(map[string]interface{}{"sessionId": "550e8400-e29b-41d4-a716-446655440000", "type": "view"})
+	req := httptest.NewRequest("POST", "/api/v1/events", bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	req.RemoteAddr = "198.51.100.1:51234"
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestEventsHandler_CreateEvent_OnBehalfOfUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name                 string
+		tokenType            string
+		authUserID           string
+		authOrganizationID   string
+		bodyUserID           string
+		bodyOrganizationID   string
+		expectedUser         string
+		expectedOrganization string
+	}{
+		{
+			name:                 "api key with user",
+			tokenType:            middleware.TokenTypeAPIKey,
+			authUserID:           "service:pptx-processor",
+			bodyUserID:           "user-123",
+			bodyOrganizationID:   "acme",
+			expectedUser:         "user-123",
+			expectedOrganization: "acme",
+		},
+		{
+			name:         "api key without user",
+			tokenType:    middleware.TokenTypeAPIKey,
+			authUserID:   "service:pptx-processor",
+			expectedUser: "service:pptx-processor",
+		},
+		{
+			name:                 "jwt ignores user and organization fields",
+			tokenType:            "jwt",
+			authUserID:           "user-456",
+			authOrganizationID:   "acme",
+			bodyUserID:           "user-123",
+			bodyOrganizationID:   "other",
+			expectedUser:         "user-456",
+			expectedOrganization: "acme",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			mockService.On("CreateEvent", mock.Anything, mock.MatchedBy(func(entry domain.AuditEntry) bool {
+				return entry.UserID == tt.expectedUser && entry.OrganizationID == tt.expectedOrganization
+			})).Return(nil).Once()
+
+			handler := NewEventsHandler(mockService, allowSessions{}, broadcast.NewBroker(zap.NewNop()), testSchemas, nil, newTestIdempotencyCache(), domain.DefaultTimestampPolicy, domain.DefaultMaxDetailsSize, nil, nil, nil, clock.NewFakeClock(testNow), zap.NewNop())
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set(middleware.AuthUserIDKey, tt.authUserID)
+				c.Set(middleware.AuthTokenTypeKey, tt.tokenType)
+				c.Set(middleware.AuthOrganizationIDKey, tt.authOrganizationID)
+			})
+			router.POST("/api/v1/events", handler.CreateEvent)
+
+			payload, _ := json.Marshal(map[string]interface{}{
+				"sessionId":      "550e8400-e29b-41d4-a716-446655440000",
+				"type":           "thumbnail",
+				"userId":         tt.bodyUserID,
+				"organizationId": tt.bodyOrganizationID,
+				"details":        map[string]interface{}{"slideNumber": 1},
+			})
+			req := httptest.NewRequest("POST", "/api/v1/events", bytes.NewBuffer(payload))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusCreated, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
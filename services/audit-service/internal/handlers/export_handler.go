@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+	"audit-service/internal/ocsf"
+	"audit-service/internal/service"
+	"audit-service/pkg/clock"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Supported export formats
+const (
+	exportFormatCSV  = "csv"
+	exportFormatJSON = "json"
+	exportFormatOCSF = "ocsf"
+)
+
+// exportCSVHeader lists the CSV columns in order
+var exportCSVHeader = []string{"id", "session_id", "user_id", "type", "timestamp", "ip_address", "user_agent", "details", "category", "severity"}
+
+// ExportHandler handles audit history downloads
+type ExportHandler struct {
+	service service.Reader
+	maxRows int
+	clock   clock.Clock
+	logger  *zap.Logger
+}
+
+// NewExportHandler creates a new export handler that exports at most maxRows entries per request
+func NewExportHandler(service service.Reader, maxRows int, clk clock.Clock, logger *zap.Logger) *ExportHandler {
+	return &ExportHandler{
+		service: service,
+		maxRows: maxRows,
+		clock:   clk,
+		logger:  logger,
+	}
+}
+
+// ExportEvents handles GET /sessions/{sessionId}/events/export and its admin variant
+// @Summary Export audit history for a session
+// @Description Streams every audit entry matching the filters as a CSV or JSON download, newest first, up to the configured row cap. The ocsf format is a JSON array of OCSF 1.1.0 events. Admins may export any session under /admin.
+// @Tags Audit
+// @Produce json
+// @Produce text/csv
+// @Param sessionId path string true "Session ID"
+// @Param format query string false "Export format: csv, json or ocsf (default: json)"
+// @Param type query string false "Comma-separated event types (e.g. edit,comment)"
+// @Param category query string false "Comma-separated categories: content, access, security, system or usage"
+// @Param severity query string false "Comma-separated severities: info, warning or critical"
+// @Param label query string false "Comma-separated labels; only events carrying one of them"
+// @Param userId query string false "Only events created by this user"
+// @Param from query string false "Only events at or after this RFC3339 timestamp"
+// @Param to query string false "Only events at or before this RFC3339 timestamp"
+// @Param q query string false "Free-text search over event details"
+// @Param share_token query string false "Share token for reviewer access"
+// @Security BearerAuth
+// @Success 200 {array} domain.AuditEntry
+// @Header 200 {integer} X-Total-Count "Number of matching entries"
+// @Header 200 {boolean} X-Export-Truncated "Present when the row cap was reached"
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /sessions/{sessionId}/events/export [get]
+// @Router /admin/sessions/{sessionId}/events/export [get]
+func (h *ExportHandler) ExportEvents(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	sessionID, ok := parseSessionID(c, c.Param("sessionId"))
+	if !ok {
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", exportFormatJSON))
+	if format != exportFormatCSV && format != exportFormatJSON && format != exportFormatOCSF {
+		middleware.WriteError(c, domain.NewAPIError("bad_request", "format must be csv, json or ocsf", http.StatusBadRequest))
+		return
+	}
+
+	filter, apiErr := parseEventFilter(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	userID := authUserID(c)
+	isShareToken := middleware.GetAuthTokenType(c) == middleware.TokenTypeShare
+
+	var (
+		writer   exportWriter
+		exported int
+	)
+	err := h.service.ExportEvents(c.Request.Context(), sessionID, userID, isShareToken, filter, h.maxRows,
+		func(entries []domain.AuditEntry, total int) error {
+			if writer == nil {
+				writer = h.startExport(c, sessionID, format, total)
+			}
+			if err := writer.write(entries); err != nil {
+				return err
+			}
+			exported += len(entries)
+			c.Writer.Flush()
+			return nil
+		})
+
+	if err != nil {
+		// The client disconnected; nobody is left to receive an error
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+
+		h.logger.Error("audit export failed",
+			zap.String("request_id", requestID),
+			zap.Stringer("session_id", sessionID),
+			zap.Int("exported", exported),
+			zap.Error(err),
+		)
+
+		// Once streaming has started the status is already sent; the truncated body signals failure
+		if writer == nil {
+			apiErr := domain.ToAPIError(err)
+			middleware.WriteError(c, apiErr)
+		}
+		return
+	}
+
+	if err := writer.close(); err != nil {
+		h.logger.Warn("failed to finish audit export",
+			zap.String("request_id", requestID),
+			zap.Error(err),
+		)
+	}
+}
+
+// startExport writes the response headers and returns a writer for the requested format
+func (h *ExportHandler) startExport(c *gin.Context, sessionID domain.SessionID, format string, total int) exportWriter {
+	extension := format
+	if format == exportFormatOCSF {
+		extension = "ocsf.json"
+	}
+	filename := fmt.Sprintf("audit-%s-%s.%s", sessionID, h.clock.Now().UTC().Format("20060102T150405Z"), extension)
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	if total > h.maxRows {
+		c.Header("X-Export-Truncated", "true")
+	}
+
+	if format == exportFormatCSV {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+		return newCSVExportWriter(c.Writer)
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(http.StatusOK)
+	if format == exportFormatOCSF {
+		return &jsonExportWriter{w: c.Writer, encode: func(entry domain.AuditEntry) any { return ocsf.FromEntry(entry) }}
+	}
+	return &jsonExportWriter{w: c.Writer}
+}
+
+// exportWriter encodes export pages in a specific format
+type exportWriter interface {
+	write(entries []domain.AuditEntry) error
+	close() error
+}
+
+// csvExportWriter writes entries as CSV rows after a header row
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func newCSVExportWriter(w gin.ResponseWriter) *csvExportWriter {
+	cw := csv.NewWriter(w)
+	_ = cw.Write(exportCSVHeader)
+	return &csvExportWriter{w: cw}
+}
+
+func (e *csvExportWriter) write(entries []domain.AuditEntry) error {
+	for _, entry := range entries {
+		record := []string{
+			entry.ID,
+			entry.SessionID,
+			entry.UserID,
+			entry.Type,
+			entry.Timestamp.UTC().Format(time.RFC3339Nano),
+			entry.IPAddress,
+			entry.UserAgent,
+			string(entry.Details),
+			string(entry.Category),
+			string(entry.Severity),
+		}
+		for i := range record {
+			record[i] = escapeCSVFormula(record[i])
+		}
+		if err := e.w.Write(record); err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExportWriter) close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// escapeCSVFormula stops spreadsheet applications from evaluating client supplied values as formulas
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// jsonExportWriter writes entries as a single JSON array, converted by encode when set
+type jsonExportWriter struct {
+	w       gin.ResponseWriter
+	encode  func(entry domain.AuditEntry) any
+	started bool
+}
+
+func (e *jsonExportWriter) write(entries []domain.AuditEntry) error {
+	for _, entry := range entries {
+		prefix := ","
+		if !e.started {
+			prefix = "["
+			e.started = true
+		}
+		var value any = entry
+		if e.encode != nil {
+			value = e.encode(entry)
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if _, err := e.w.WriteString(prefix); err != nil {
+			return err
+		}
+		if _, err := e.w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *jsonExportWriter) close() error {
+	closing := "]"
+	if !e.started {
+		closing = "[]"
+	}
+	_, err := e.w.WriteString(closing)
+	return err
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+	"audit-service/internal/ocsf"
+	"audit-service/pkg/clock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const exportSessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+func exportEntries() []domain.AuditEntry {
+	return []domain.AuditEntry{
+		{
+			ID:        "event-2",
+			SessionID: exportSessionID,
+			UserID:    "user-456",
+			Type:      "comment",
+			Timestamp: time.Date(2024, 1, 1, 10, 5, 0, 0, time.UTC),
+			Details:   json.RawMessage(`{"text":"=HYPERLINK(\"x\")"}`),
+		},
+		{
+			ID:        "event-1",
+			SessionID: exportSessionID,
+			UserID:    "user-456",
+			Type:      "edit",
+			Timestamp: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+			Details:   json.RawMessage(`{"slideId":"slide-1"}`),
+			UserAgent: "=cmd|' /C calc'!A0",
+		},
+	}
+}
+
+// emitPages makes the mocked ExportEvents call emit once per page
+func emitPages(total int, pages ...[]domain.AuditEntry) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		emit := args.Get(6).(func([]domain.AuditEntry, int) error)
+		for _, page := range pages {
+			_ = emit(page, total)
+		}
+	}
+}
+
+func performExport(handler *ExportHandler, query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+exportSessionID+"/events/export"+query, nil)
+	c.Set(middleware.AuthUserIDKey, "user-456")
+	c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+	c.Params = []gin.Param{{Key: "sessionId", Value: exportSessionID}}
+
+	handler.ExportEvents(c)
+	return w
+}
+
+func TestExportHandler_JSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	entries := exportEntries()
+	mockService := new(MockAuditService)
+	mockService.On("ExportEvents", mock.Anything, exportSessionID, "user-456", false,
+		domain.EventFilter{Types: []string{"edit", "comment"}}, 100, mock.Anything).
+		Run(emitPages(2, entries[:1], entries[1:])).Return(nil)
+
+	clk := clock.NewFakeClock(time.Date(2024, 4, 1, 8, 0, 0, 0, time.UTC))
+	w := performExport(NewExportHandler(mockService, 100, clk, zap.NewNop()), "?type=edit,comment")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), `attachment; filename="audit-`+exportSessionID+`-20240401T080000Z.json"`)
+	assert.Equal(t, "2", w.Header().Get("X-Total-Count"))
+	assert.Empty(t, w.Header().Get("X-Export-Truncated"))
+
+	var exported []domain.AuditEntry
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &exported))
+	require.Len(t, exported, 2)
+	assert.Equal(t, "event-2", exported[0].ID)
+	assert.Equal(t, "event-1", exported[1].ID)
+	mockService.AssertExpectations(t)
+}
+
+func TestExportHandler_CSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockAuditService)
+	mockService.On("ExportEvents", mock.Anything, exportSessionID, "user-456", false, domain.EventFilter{}, 2, mock.Anything).
+		Run(emitPages(5, exportEntries())).Return(nil)
+
+	w := performExport(NewExportHandler(mockService, 2, clock.New(), zap.NewNop()), "?format=csv")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "5", w.Header().Get("X-Total-Count"))
+	assert.Equal(t, "true", w.Header().Get("X-Export-Truncated"))
+
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, exportCSVHeader, records[0])
+	assert.Equal(t, "event-2", records[1][0])
+	assert.Equal(t, "2024-01-01T10:05:00Z", records[1][4])
+	assert.Equal(t, `{"text":"=HYPERLINK(\"x\")"}`, records[1][7])
+	// Formula-like values are neutralised
+	assert.Equal(t, "'=cmd|' /C calc'!A0", records[2][6])
+}
+
+func TestExportHandler_CSVTaxonomy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	entries := exportEntries()[:1]
+	entries[0].Type = "share"
+	entries[0].Category = domain.CategoryAccess
+	entries[0].Severity = domain.EntrySeverityWarning
+	filter := domain.EventFilter{
+		Categories: []domain.EventCategory{domain.CategoryAccess},
+		Severities: []domain.EntrySeverity{domain.EntrySeverityWarning, domain.EntrySeverityCritical},
+		Labels:     []string{"disputed"},
+	}
+	mockService := new(MockAuditService)
+	mockService.On("ExportEvents", mock.Anything, exportSessionID, "user-456", false, filter, 10, mock.Anything).
+		Run(emitPages(1, entries)).Return(nil)
+
+	w := performExport(NewExportHandler(mockService, 10, clock.New(), zap.NewNop()), "?format=csv&category=access&severity=warning,CRITICAL&label=Disputed")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, []string{"access", "warning"}, records[1][8:])
+	mockService.AssertExpectations(t)
+}
+
+func TestExportHandler_RejectsUnknownCategory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := performExport(NewExportHandler(new(MockAuditService), 10, clock.New(), zap.NewNop()), "?category=billing")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestExportHandler_OCSF(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockAuditService)
+	mockService.On("ExportEvents", mock.Anything, exportSessionID, "user-456", false, domain.EventFilter{}, 100, mock.Anything).
+		Run(emitPages(2, exportEntries())).Return(nil)
+
+	w := performExport(NewExportHandler(mockService, 100, clock.New(), zap.NewNop()), "?format=OCSF")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), `.ocsf.json"`)
+
+	var exported []ocsf.Event
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &exported))
+	require.Len(t, exported, 2)
+	assert.Equal(t, "event-2", exported[0].Metadata.UID)
+	assert.Equal(t, 600101, exported[0].TypeUID)
+	assert.Equal(t, 600103, exported[1].TypeUID)
+	assert.Equal(t, "=cmd|' /C calc'!A0", exported[1].HTTPRequest.UserAgent)
+	mockService.AssertExpectations(t)
+}
+
+func TestExportHandler_EmptyJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockAuditService)
+	mockService.On("ExportEvents", mock.Anything, exportSessionID, "user-456", false, domain.EventFilter{}, 100, mock.Anything).
+		Run(emitPages(0, []domain.AuditEntry{})).Return(nil)
+
+	w := performExport(NewExportHandler(mockService, 100, clock.New(), zap.NewNop()), "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[]", w.Body.String())
+}
+
+func TestExportHandler_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		query          string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{name: "unknown format", query: "?format=xml", expectedStatus: http.StatusBadRequest},
+		{name: "invalid filter", query: "?from=yesterday", expectedStatus: http.StatusBadRequest},
+		{name: "forbidden", serviceErr: domain.ErrForbidden, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			if tt.serviceErr != nil {
+				mockService.On("ExportEvents", mock.Anything, exportSessionID, "user-456", false, mock.Anything, 100, mock.Anything).
+					Return(tt.serviceErr)
+			}
+
+			w := performExport(NewExportHandler(mockService, 100, clock.New(), zap.NewNop()), tt.query)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Empty(t, w.Header().Get("Content-Disposition"))
+			mockService.AssertExpectations(t)
+		})
+	}
+}
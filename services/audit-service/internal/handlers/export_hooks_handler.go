@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/exporthook"
+	"audit-service/internal/middleware"
+	"audit-service/pkg/clock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.uber.org/zap"
+)
+
+// Headers of the signed export callbacks, as sent with outbox deliveries
+const (
+	exportCallbackTimestampHeader = "X-Audit-Timestamp"
+	exportCallbackSignatureHeader = "X-Audit-Signature"
+)
+
+// ExportHooks records the export lifecycle reported by the export worker and serves the
+// stitched export records
+type ExportHooks interface {
+	Record(ctx context.Context, callback domain.ExportCallback) (domain.ExportRecord, bool, error)
+	Get(ctx context.Context, exportID string, userID domain.UserID) (domain.ExportRecord, error)
+}
+
+// ExportHooksHandler handles the export worker callbacks and the export audit records
+type ExportHooksHandler struct {
+	hooks     ExportHooks
+	secret    []byte
+	tolerance time.Duration
+	clock     clock.Clock
+	logger    *zap.Logger
+}
+
+// NewExportHooksHandler creates a new export hooks handler; callbacks must be signed with secret
+// at most tolerance before or after they arrive
+func NewExportHooksHandler(hooks ExportHooks, secret string, tolerance time.Duration, clk clock.Clock, logger *zap.Logger) *ExportHooksHandler {
+	return &ExportHooksHandler{
+		hooks:     hooks,
+		secret:    []byte(secret),
+		tolerance: tolerance,
+		clock:     clk,
+		logger:    logger,
+	}
+}
+
+// ReportExportStep handles POST /api/v1/exports/callbacks
+// @Summary Report an export step
+// @Description Records a lifecycle step (queued, rendering, uploaded, failed) of an export rendered by the export worker. The request is signed like outbox deliveries: X-Audit-Timestamp holds the Unix time and X-Audit-Signature "sha256=" and the hex HMAC-SHA256 of "timestamp.body" with EXPORT_CALLBACK_SECRET. Callbacks signed more than EXPORT_CALLBACK_TOLERANCE away from server time are rejected. A step already recorded for the export is answered with 200 and not stored again.
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Param request body domain.ExportCallback true "Export step"
+// @Param X-Audit-Timestamp header string true "Unix time the callback was signed at"
+// @Param X-Audit-Signature header string true "sha256= and the hex HMAC-SHA256 of timestamp.body"
+// @Success 200 {object} domain.ExportRecord
+// @Success 201 {object} domain.ExportRecord
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 413 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /exports/callbacks [post]
+func (h *ExportHooksHandler) ReportExportStep(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		middleware.WriteError(c, invalidBody(c, err))
+		return
+	}
+
+	// The signature is checked before the body is parsed
+	if err := exporthook.Verify(h.secret, c.GetHeader(exportCallbackTimestampHeader), c.GetHeader(exportCallbackSignatureHeader),
+		body, h.clock.Now(), h.tolerance); err != nil {
+		h.logger.Warn("rejected export callback",
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.String("client_ip", middleware.GetClientIP(c)),
+			zap.Error(err),
+		)
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+
+	var callback domain.ExportCallback
+	if err := binding.JSON.BindBody(body, &callback); err != nil {
+		middleware.WriteError(c, invalidBody(c, err))
+		return
+	}
+
+	record, created, err := h.hooks.Record(c.Request.Context(), callback)
+	if err != nil {
+		h.writeError(c, "failed to record export step", callback.ExportID, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, record)
+}
+
+// GetExportAudit handles GET /api/v1/exports/{exportId}/audit
+// @Summary Get the audit record of an export
+// @Description Returns the export record stitched from the export event of the frontend and the lifecycle steps reported by the export worker, correlated by the export ID. Only readers of the session of the export may read it.
+// @Tags Exports
+// @Produce json
+// @Param exportId path string true "Export ID"
+// @Security BearerAuth
+// @Success 200 {object} domain.ExportRecord
+// @Failure 401 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /exports/{exportId}/audit [get]
+func (h *ExportHooksHandler) GetExportAudit(c *gin.Context) {
+	record, err := h.hooks.Get(c.Request.Context(), c.Param("exportId"), domain.UserID(middleware.GetAuthUserID(c)))
+	if err != nil {
+		h.writeError(c, "failed to get export record", c.Param("exportId"), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+// writeError writes the API error of a failed export hook operation, logging server errors
+func (h *ExportHooksHandler) writeError(c *gin.Context, message, exportID string, err error) {
+	// Reasons for an invalid callback are returned to the worker as is
+	if errors.Is(err, domain.ErrInvalidExportCallback) {
+		middleware.WriteError(c, domain.NewAPIError("invalid_export_callback", err.Error(), http.StatusBadRequest))
+		return
+	}
+	if errors.Is(err, domain.ErrTimestampSkew) {
+		middleware.WriteError(c, domain.NewAPIError("invalid_timestamp", err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	apiErr := domain.ToAPIError(err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		h.logger.Error(message,
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.String("export_id", exportID),
+			zap.Error(err),
+		)
+	}
+	middleware.WriteError(c, apiErr)
+}
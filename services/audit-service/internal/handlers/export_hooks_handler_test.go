@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+	"audit-service/internal/outbox"
+	"audit-service/pkg/clock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockExportHooks is a mock implementation of ExportHooks
+type MockExportHooks struct {
+	mock.Mock
+}
+
+func (m *MockExportHooks) Record(ctx context.Context, callback domain.ExportCallback) (domain.ExportRecord, bool, error) {
+	args := m.Called(ctx, callback)
+	return args.Get(0).(domain.ExportRecord), args.Bool(1), args.Error(2)
+}
+
+func (m *MockExportHooks) Get(ctx context.Context, exportID string, userID domain.UserID) (domain.ExportRecord, error) {
+	args := m.Called(ctx, exportID, userID)
+	return args.Get(0).(domain.ExportRecord), args.Error(1)
+}
+
+const testExportCallbackSecret = "export-secret"
+
+var testExportRecord = domain.ExportRecord{
+	ExportID: "export-7f3a", SessionID: "550e8400-e29b-41d4-a716-446655440000", UserID: "user-123",
+	Status: domain.ExportQueued,
+	Steps:  []domain.ExportStep{{Status: domain.ExportQueued, Timestamp: testNow, EventID: "550e8400-e29b-41d4-a716-446655440001"}},
+}
+
+func performExportCallback(handler *ExportHooksHandler, body, secret string, signedAt time.Time) *httptest.ResponseRecorder {
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/exports/callbacks", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("X-Audit-Timestamp", timestamp)
+	c.Request.Header.Set("X-Audit-Signature", "sha256="+outbox.Sign([]byte(secret), timestamp, []byte(body)))
+
+	handler.ReportExportStep(c)
+	return w
+}
+
+func TestExportHooksHandler_ReportExportStep(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	body := `{"exportId":"export-7f3a","sessionId":"550e8400-e29b-41d4-a716-446655440000","userId":"user-123","status":"queued"}`
+	callback := domain.ExportCallback{ExportID: "export-7f3a", SessionID: "550e8400-e29b-41d4-a716-446655440000", UserID: "user-123", Status: domain.ExportQueued}
+
+	tests := []struct {
+		name           string
+		body           string
+		secret         string
+		signedAt       time.Time
+		recordErr      error
+		created        bool
+		expectRecord   bool
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name: "new step", body: body, secret: testExportCallbackSecret, signedAt: testNow,
+			created: true, expectRecord: true, expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "retried step", body: body, secret: testExportCallbackSecret, signedAt: testNow,
+			expectRecord: true, expectedStatus: http.StatusOK,
+		},
+		{
+			name: "wrong secret", body: body, secret: "guessed", signedAt: testNow,
+			expectedStatus: http.StatusUnauthorized, expectedCode: "unauthorized",
+		},
+		{
+			name: "stale signature", body: body, secret: testExportCallbackSecret, signedAt: testNow.Add(-10 * time.Minute),
+			expectedStatus: http.StatusUnauthorized, expectedCode: "unauthorized",
+		},
+		{
+			name: "missing fields", body: `{"exportId":"export-7f3a"}`, secret: testExportCallbackSecret, signedAt: testNow,
+			expectedStatus: http.StatusBadRequest, expectedCode: "validation_failed",
+		},
+		{
+			name: "invalid callback", body: body, secret: testExportCallbackSecret, signedAt: testNow,
+			recordErr:    fmt.Errorf("%w: sessionId must be a UUID", domain.ErrInvalidExportCallback),
+			expectRecord: true, expectedStatus: http.StatusBadRequest, expectedCode: "invalid_export_callback",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hooks := new(MockExportHooks)
+			if tt.expectRecord {
+				hooks.On("Record", mock.Anything, callback).Return(testExportRecord, tt.created, tt.recordErr).Once()
+			}
+			handler := NewExportHooksHandler(hooks, testExportCallbackSecret, 5*time.Minute, clock.NewFakeClock(testNow), zap.NewNop())
+
+			w := performExportCallback(handler, tt.body, tt.secret, tt.signedAt)
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+
+			if tt.expectedCode != "" {
+				var problem domain.APIError
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+				assert.Equal(t, tt.expectedCode, problem.Code)
+			} else {
+				var record domain.ExportRecord
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &record))
+				assert.Equal(t, "export-7f3a", record.ExportID)
+			}
+			hooks.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExportHooksHandler_GetExportAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		getErr         error
+		expectedStatus int
+	}{
+		{name: "readable export", expectedStatus: http.StatusOK},
+		{name: "unknown export", getErr: domain.ErrExportNotFound, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hooks := new(MockExportHooks)
+			hooks.On("Get", mock.Anything, "export-7f3a", domain.UserID("user-123")).Return(testExportRecord, tt.getErr).Once()
+			handler := NewExportHooksHandler(hooks, testExportCallbackSecret, 5*time.Minute, clock.NewFakeClock(testNow), zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/exports/export-7f3a/audit", nil)
+			c.Set(middleware.AuthUserIDKey, "user-123")
+			c.Params = gin.Params{{Key: "exportId", Value: "export-7f3a"}}
+
+			handler.GetExportAudit(c)
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.getErr == nil {
+				var record domain.ExportRecord
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &record))
+				assert.Equal(t, domain.ExportQueued, record.Status)
+				assert.Len(t, record.Steps, 1)
+			}
+			hooks.AssertExpectations(t)
+		})
+	}
+}
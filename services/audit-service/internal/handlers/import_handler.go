@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ImportJobs starts and tracks imports of historical audit events
+type ImportJobs interface {
+	Upload(ctx context.Context, file io.Reader, dryRun bool, requestedBy string) (domain.ImportJob, error)
+	Fetch(ctx context.Context, url string, dryRun bool, requestedBy string) (domain.ImportJob, error)
+	Get(ctx context.Context, id string) (domain.ImportJob, error)
+}
+
+// ImportRequest names the file to import when it is not uploaded
+type ImportRequest struct {
+	// URL is a signed HTTPS URL of a gzipped NDJSON file
+	URL string `json:"url" binding:"required" example:"https://storage.example.com/exports/audit-2021.ndjson.gz?signature=..."`
+}
+
+// ImportHandler handles imports of historical audit events
+type ImportHandler struct {
+	jobs   ImportJobs
+	logger *zap.Logger
+}
+
+// NewImportHandler creates a new import handler
+func NewImportHandler(jobs ImportJobs, logger *zap.Logger) *ImportHandler {
+	return &ImportHandler{
+		jobs:   jobs,
+		logger: logger,
+	}
+}
+
+// ImportEvents handles POST /events/import
+// @Summary Import historical audit events
+// @Description Imports a gzipped NDJSON file of historical events, one event per line with the fields of the events API and the time it happened, into the caller's organization. The file is uploaded as the multipart part "file", or fetched from the signed HTTPS URL of a JSON body. Lines are validated like events recorded through the API; invalid lines are skipped and reported on the job. Events whose ID is already stored, or repeated in the file, are skipped, so a failed import can be run again. With dryRun the file is only validated. The work runs in the background; poll the returned job for progress. Admin only.
+// @Tags Admin
+// @Accept multipart/form-data
+// @Accept json
+// @Produce json
+// @Param file formData file false "Gzipped NDJSON file"
+// @Param request body ImportRequest false "Signed URL of the file, instead of an upload"
+// @Param dryRun query bool false "Only validate the file and count duplicates (default: false)"
+// @Security BearerAuth
+// @Success 202 {object} domain.ImportJob
+// @Header 202 {string} Location "URL of the import job"
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 503 {object} domain.APIError
+// @Router /events/import [post]
+func (h *ImportHandler) ImportEvents(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	dryRun := false
+	if raw := c.Query("dryRun"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			middleware.WriteError(c, domain.NewAPIError("bad_request", "dryRun must be true or false", http.StatusBadRequest))
+			return
+		}
+		dryRun = parsed
+	}
+
+	requestedBy := middleware.GetAuthUserID(c)
+	var job domain.ImportJob
+	var err error
+	if c.ContentType() == "multipart/form-data" {
+		var file io.Reader
+		file, err = uploadedFile(c)
+		if err == nil {
+			job, err = h.jobs.Upload(c.Request.Context(), file, dryRun, requestedBy)
+		}
+	} else {
+		var req ImportRequest
+		if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+			middleware.WriteError(c, invalidBody(c, bindErr))
+			return
+		}
+		job, err = h.jobs.Fetch(c.Request.Context(), req.URL, dryRun, requestedBy)
+	}
+	if err != nil {
+		h.writeError(c, "failed to start import job", err)
+		return
+	}
+
+	h.logger.Info("import job accepted",
+		zap.String("request_id", requestID),
+		zap.String("job_id", job.ID),
+		zap.String("source", string(job.Source)),
+		zap.Bool("dry_run", dryRun),
+		zap.String("requested_by", requestedBy),
+	)
+
+	c.Header("Location", "/api/v1/import-jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, job)
+}
+
+// uploadedFile streams the "file" part of a multipart body, so large files are not buffered in memory
+func uploadedFile(c *gin.Context) (io.Reader, error) {
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidImport, err)
+	}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: multipart body has no file part", domain.ErrInvalidImport)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidImport, err)
+		}
+		if part.FormName() == "file" {
+			return part, nil
+		}
+	}
+}
+
+// GetJob handles GET /import-jobs/{jobId}
+// @Summary Get an import job
+// @Description Returns the progress of an import job, with the first rejected lines. Finished jobs are kept for 24 hours. Admin only.
+// @Tags Admin
+// @Produce json
+// @Param jobId path string true "Import job ID"
+// @Security BearerAuth
+// @Success 200 {object} domain.ImportJob
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Router /import-jobs/{jobId} [get]
+func (h *ImportHandler) GetJob(c *gin.Context) {
+	job, err := h.jobs.Get(c.Request.Context(), c.Param("jobId"))
+	if err != nil {
+		h.writeError(c, "failed to get import job", err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, job)
+}
+
+// writeError writes the API error of a failed import operation, logging server errors
+func (h *ImportHandler) writeError(c *gin.Context, message string, err error) {
+	// Reasons for an invalid import are returned to the admin as is
+	if errors.Is(err, domain.ErrInvalidImport) {
+		middleware.WriteError(c, domain.NewAPIError("invalid_import", err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	apiErr := domain.ToAPIError(err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		h.logger.Error(message,
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.Error(err),
+		)
+	}
+	middleware.WriteError(c, apiErr)
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockImportJobs is a mock implementation of ImportJobs
+type MockImportJobs struct {
+	mock.Mock
+}
+
+func (m *MockImportJobs) Upload(ctx context.Context, file io.Reader, dryRun bool, requestedBy string) (domain.ImportJob, error) {
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return domain.ImportJob{}, err
+	}
+	args := m.Called(string(content), dryRun, requestedBy)
+	return args.Get(0).(domain.ImportJob), args.Error(1)
+}
+
+func (m *MockImportJobs) Fetch(ctx context.Context, url string, dryRun bool, requestedBy string) (domain.ImportJob, error) {
+	args := m.Called(url, dryRun, requestedBy)
+	return args.Get(0).(domain.ImportJob), args.Error(1)
+}
+
+func (m *MockImportJobs) Get(ctx context.Context, id string) (domain.ImportJob, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.ImportJob), args.Error(1)
+}
+
+func performImport(handler *ImportHandler, query, contentType string, body io.Reader) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/events/import"+query, body)
+	c.Request.Header.Set("Content-Type", contentType)
+	c.Set(middleware.AuthUserIDKey, "admin-1")
+
+	handler.ImportEvents(c)
+	return w
+}
+
+// multipartBody builds a multipart body with a part per field name
+func multipartBody(t *testing.T, parts map[string]string) (string, io.Reader) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for name, content := range parts {
+		part, err := writer.CreateFormFile(name, "events.ndjson.gz")
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return writer.FormDataContentType(), &buf
+}
+
+func TestImportHandler_Upload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jobs := new(MockImportJobs)
+	job := domain.ImportJob{ID: "job-1", Source: domain.ImportUpload, Status: domain.ImportPending, DryRun: true, RequestedBy: "admin-1"}
+	jobs.On("Upload", "gzipped lines", true, "admin-1").Return(job, nil)
+
+	contentType, body := multipartBody(t, map[string]string{"file": "gzipped lines"})
+	w := performImport(NewImportHandler(jobs, zap.NewNop()), "?dryRun=true", contentType, body)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "/api/v1/import-jobs/job-1", w.Header().Get("Location"))
+	var got domain.ImportJob
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, job, got)
+	jobs.AssertExpectations(t)
+}
+
+func TestImportHandler_Fetch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const url = "https://storage.example.com/audit.ndjson.gz?signature=abc"
+	tests := []struct {
+		name           string
+		query          string
+		body           string
+		fetchErr       error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "signed url", body: `{"url":"` + url + `"}`, expectedStatus: http.StatusAccepted},
+		{name: "missing url", body: `{}`, expectedStatus: http.StatusBadRequest, expectedCode: "validation_failed"},
+		{name: "invalid dry run flag", query: "?dryRun=maybe", body: `{"url":"` + url + `"}`, expectedStatus: http.StatusBadRequest, expectedCode: "bad_request"},
+		{
+			name: "rejected url", body: `{"url":"` + url + `"}`, fetchErr: fmt.Errorf("%w: url must be an https URL", domain.ErrInvalidImport),
+			expectedStatus: http.StatusBadRequest, expectedCode: "invalid_import",
+		},
+		{name: "shutting down", body: `{"url":"` + url + `"}`, fetchErr: domain.ErrServiceUnavailable, expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs := new(MockImportJobs)
+			job := domain.ImportJob{ID: "job-1", Source: domain.ImportURL, Status: domain.ImportPending, RequestedBy: "admin-1"}
+			if tt.expectedCode == "" || tt.fetchErr != nil {
+				jobs.On("Fetch", url, false, "admin-1").Return(job, tt.fetchErr)
+			}
+
+			w := performImport(NewImportHandler(jobs, zap.NewNop()), tt.query, "application/json", strings.NewReader(tt.body))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedCode != "" {
+				var apiErr domain.APIError
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+				assert.Equal(t, tt.expectedCode, apiErr.Code)
+			}
+			jobs.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImportHandler_UploadWithoutFile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jobs := new(MockImportJobs)
+	contentType, body := multipartBody(t, map[string]string{"other": "content"})
+	w := performImport(NewImportHandler(jobs, zap.NewNop()), "", contentType, body)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var apiErr domain.APIError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+	assert.Equal(t, "invalid import: multipart body has no file part", apiErr.Message)
+	jobs.AssertExpectations(t)
+}
+
+func TestImportHandler_GetJob(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jobs := new(MockImportJobs)
+	jobs.On("Get", "job-1").Return(domain.ImportJob{ID: "job-1", Status: domain.ImportRunning, Imported: 500}, nil)
+	jobs.On("Get", "missing").Return(domain.ImportJob{}, domain.ErrImportJobNotFound)
+	handler := NewImportHandler(jobs, zap.NewNop())
+
+	get := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/import-jobs/"+id, nil)
+		c.Params = []gin.Param{{Key: "jobId", Value: id}}
+		handler.GetJob(c)
+		return w
+	}
+
+	w := get("job-1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var job domain.ImportJob
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+	assert.Equal(t, 500, job.Imported)
+
+	assert.Equal(t, http.StatusNotFound, get("missing").Code)
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// EventLabelService reads and changes the labels reviewers attach to audit events
+type EventLabelService interface {
+	Get(ctx context.Context, eventID, userID string) (domain.EventLabels, error)
+	Add(ctx context.Context, eventID, userID string, labels []string) (domain.EventLabels, error)
+	Remove(ctx context.Context, eventID, userID, label string) (domain.EventLabels, error)
+	Replace(ctx context.Context, eventID, userID string, labels []string, expectedVersion int64) (domain.EventLabels, error)
+}
+
+// EventLabelsRequest defines the request body for adding or replacing the labels of an event
+type EventLabelsRequest struct {
+	Labels []string `json:"labels" example:"billing-relevant,disputed"`
+}
+
+// LabelsHandler handles the labels of audit events
+type LabelsHandler struct {
+	labels EventLabelService
+	logger *zap.Logger
+}
+
+// NewLabelsHandler creates a new labels handler
+func NewLabelsHandler(labels EventLabelService, logger *zap.Logger) *LabelsHandler {
+	return &LabelsHandler{
+		labels: labels,
+		logger: logger,
+	}
+}
+
+// GetEventLabels handles GET /events/{id}/labels
+// @Summary Get the labels of an event
+// @Description Returns the labels attached to an audit event, with the version of the label set in the ETag header; version 0 and no labels for events never labeled. Reviewers, admins and the session owner may read them.
+// @Tags Labels
+// @Produce json
+// @Param id path string true "Event ID"
+// @Security BearerAuth
+// @Success 200 {object} domain.EventLabels
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /events/{id}/labels [get]
+func (h *LabelsHandler) GetEventLabels(c *gin.Context) {
+	labels, err := h.labels.Get(c.Request.Context(), c.Param("id"), middleware.GetAuthUserID(c))
+	if err != nil {
+		h.writeError(c, "failed to get event labels", err)
+		return
+	}
+
+	respondWithVersion(c, http.StatusOK, labels.Version, labels)
+}
+
+// AddEventLabels handles POST /events/{id}/labels
+// @Summary Label an event
+// @Description Attaches labels, such as "billing-relevant" or "disputed", to an audit event, keeping those it already carries. Labels are lowercase slugs of letters, digits, hyphens and underscores; an event carries at most 20. They are stored beside the event, which stays unchanged, and filter the event queries through their label parameter. Reviewers, admins and the session owner may label its events.
+// @Tags Labels
+// @Accept json
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param request body EventLabelsRequest true "Labels to add"
+// @Security BearerAuth
+// @Success 200 {object} domain.EventLabels
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 412 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /events/{id}/labels [post]
+func (h *LabelsHandler) AddEventLabels(c *gin.Context) {
+	var req EventLabelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.WriteError(c, invalidBody(c, err))
+		return
+	}
+
+	labels, err := h.labels.Add(c.Request.Context(), c.Param("id"), middleware.GetAuthUserID(c), req.Labels)
+	if err != nil {
+		h.writeError(c, "failed to add event labels", err)
+		return
+	}
+
+	respondWithVersion(c, http.StatusOK, labels.Version, labels)
+}
+
+// ReplaceEventLabels handles PUT /events/{id}/labels
+// @Summary Replace the labels of an event
+// @Description Replaces the labels of an audit event. The If-Match header must carry the ETag of the label set the replacement was made against; replacing labels another reviewer changed since is answered with 412. Reviewers, admins and the session owner may label its events.
+// @Tags Labels
+// @Accept json
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param If-Match header string true "ETag of the label set being replaced"
+// @Param request body EventLabelsRequest true "Labels"
+// @Security BearerAuth
+// @Success 200 {object} domain.EventLabels
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 412 {object} domain.APIError
+// @Failure 428 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /events/{id}/labels [put]
+func (h *LabelsHandler) ReplaceEventLabels(c *gin.Context) {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+	var req EventLabelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.WriteError(c, invalidBody(c, err))
+		return
+	}
+
+	labels, err := h.labels.Replace(c.Request.Context(), c.Param("id"), middleware.GetAuthUserID(c), req.Labels, version)
+	if err != nil {
+		h.writeError(c, "failed to replace event labels", err)
+		return
+	}
+
+	respondWithVersion(c, http.StatusOK, labels.Version, labels)
+}
+
+// RemoveEventLabel handles DELETE /events/{id}/labels/{label}
+// @Summary Remove a label from an event
+// @Description Detaches a label from an audit event and returns the remaining labels. Removing a label the event does not carry changes nothing. Reviewers, admins and the session owner may label its events.
+// @Tags Labels
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param label path string true "Label"
+// @Security BearerAuth
+// @Success 200 {object} domain.EventLabels
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 412 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /events/{id}/labels/{label} [delete]
+func (h *LabelsHandler) RemoveEventLabel(c *gin.Context) {
+	labels, err := h.labels.Remove(c.Request.Context(), c.Param("id"), middleware.GetAuthUserID(c), c.Param("label"))
+	if err != nil {
+		h.writeError(c, "failed to remove event label", err)
+		return
+	}
+
+	respondWithVersion(c, http.StatusOK, labels.Version, labels)
+}
+
+// writeError writes the API error of a failed label operation, logging server errors
+func (h *LabelsHandler) writeError(c *gin.Context, message string, err error) {
+	// Reasons for an invalid label are returned to the caller as is
+	if errors.Is(err, domain.ErrInvalidLabel) {
+		middleware.WriteError(c, domain.NewAPIError("invalid_label", err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	apiErr := domain.ToAPIError(err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		h.logger.Error(message,
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.String("event_id", c.Param("id")),
+			zap.Error(err),
+		)
+	}
+	middleware.WriteError(c, apiErr)
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/labels"
+	"audit-service/internal/middleware"
+	"audit-service/internal/repository"
+	"audit-service/internal/service"
+	"audit-service/mocks"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/jwt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockEventLabelService is a mock implementation of EventLabelService
+type MockEventLabelService struct {
+	mock.Mock
+}
+
+func (m *MockEventLabelService) Get(ctx context.Context, eventID, userID string) (domain.EventLabels, error) {
+	args := m.Called(ctx, eventID, userID)
+	return args.Get(0).(domain.EventLabels), args.Error(1)
+}
+
+func (m *MockEventLabelService) Add(ctx context.Context, eventID, userID string, labels []string) (domain.EventLabels, error) {
+	args := m.Called(ctx, eventID, userID, labels)
+	return args.Get(0).(domain.EventLabels), args.Error(1)
+}
+
+func (m *MockEventLabelService) Remove(ctx context.Context, eventID, userID, label string) (domain.EventLabels, error) {
+	args := m.Called(ctx, eventID, userID, label)
+	return args.Get(0).(domain.EventLabels), args.Error(1)
+}
+
+func (m *MockEventLabelService) Replace(ctx context.Context, eventID, userID string, labels []string, expectedVersion int64) (domain.EventLabels, error) {
+	args := m.Called(ctx, eventID, userID, labels, expectedVersion)
+	return args.Get(0).(domain.EventLabels), args.Error(1)
+}
+
+const testLabeledEvent = "550e8400-e29b-41d4-a716-446655440001"
+
+var testEventLabels = domain.EventLabels{
+	EventID: testLabeledEvent, SessionID: "550e8400-e29b-41d4-a716-446655440000",
+	Labels: []string{"billing-relevant", "disputed"}, Version: 2, UpdatedBy: "user-1",
+}
+
+func performLabelRequest(method, body string, header map[string]string, params gin.Params, handle func(c *gin.Context)) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/api/v1/events/"+testLabeledEvent+"/labels", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	for name, value := range header {
+		c.Request.Header.Set(name, value)
+	}
+	c.Set(middleware.AuthUserIDKey, "user-1")
+	c.Params = append(gin.Params{{Key: "id", Value: testLabeledEvent}}, params...)
+
+	handle(c)
+	return w
+}
+
+func TestLabelsHandler_GetAndAdd(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	labels := new(MockEventLabelService)
+	labels.On("Get", mock.Anything, testLabeledEvent, "user-1").Return(testEventLabels, nil).Once()
+	labels.On("Add", mock.Anything, testLabeledEvent, "user-1", []string{"disputed"}).Return(testEventLabels, nil).Once()
+	labels.On("Add", mock.Anything, testLabeledEvent, "user-1", []string{"not a slug"}).
+		Return(domain.EventLabels{}, fmt.Errorf("%w: %q must be a lowercase slug", domain.ErrInvalidLabel, "not a slug")).Once()
+	handler := NewLabelsHandler(labels, zap.NewNop())
+
+	w := performLabelRequest("GET", "", nil, nil, handler.GetEventLabels)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	var got domain.EventLabels
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, testEventLabels, got)
+
+	w = performLabelRequest("POST", `{"labels":["disputed"]}`, nil, nil, handler.AddEventLabels)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	w = performLabelRequest("POST", `{"labels":["not a slug"]}`, nil, nil, handler.AddEventLabels)
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	var apiErr domain.APIError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+	assert.Equal(t, "invalid_label", apiErr.Code)
+	assert.Contains(t, apiErr.Message, "not a slug")
+
+	w = performLabelRequest("POST", `{"labels":`, nil, nil, handler.AddEventLabels)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	labels.AssertExpectations(t)
+}
+
+func TestLabelsHandler_ReplaceEventLabels(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		ifMatch        string
+		replaceErr     error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "current version", ifMatch: `"1"`, expectedStatus: http.StatusOK},
+		{name: "stale version", ifMatch: `"1"`, replaceErr: domain.ErrVersionConflict, expectedStatus: http.StatusPreconditionFailed, expectedCode: "version_conflict"},
+		{name: "missing If-Match", expectedStatus: http.StatusPreconditionRequired, expectedCode: "precondition_required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := new(MockEventLabelService)
+			if tt.ifMatch != "" {
+				labels.On("Replace", mock.Anything, testLabeledEvent, "user-1", []string{"billing-relevant", "disputed"}, int64(1)).
+					Return(testEventLabels, tt.replaceErr).Once()
+			}
+			handler := NewLabelsHandler(labels, zap.NewNop())
+
+			w := performLabelRequest("PUT", `{"labels":["billing-relevant","disputed"]}`,
+				map[string]string{"If-Match": tt.ifMatch}, nil, handler.ReplaceEventLabels)
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedCode != "" {
+				var apiErr domain.APIError
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+				assert.Equal(t, tt.expectedCode, apiErr.Code)
+			} else {
+				assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+			}
+			labels.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLabelsHandler_RemoveEventLabel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	remaining := domain.EventLabels{EventID: testLabeledEvent, Labels: []string{"billing-relevant"}, Version: 3}
+	labels := new(MockEventLabelService)
+	labels.On("Remove", mock.Anything, testLabeledEvent, "user-1", "disputed").Return(remaining, nil).Once()
+	labels.On("Remove", mock.Anything, testLabeledEvent, "user-1", "reviewed").Return(domain.EventLabels{}, domain.ErrForbidden).Once()
+	handler := NewLabelsHandler(labels, zap.NewNop())
+
+	w := performLabelRequest("DELETE", "", nil, gin.Params{{Key: "label", Value: "disputed"}}, handler.RemoveEventLabel)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	w = performLabelRequest("DELETE", "", nil, gin.Params{{Key: "label", Value: "reviewed"}}, handler.RemoveEventLabel)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	labels.AssertExpectations(t)
+}
+
+// labelStore keeps labels in memory and serves the labeled event
+type labelStore struct {
+	event  domain.AuditEntry
+	labels map[string]domain.EventLabels
+}
+
+func (s *labelStore) FindEvent(ctx context.Context, eventID domain.EventID) (*domain.AuditEntry, error) {
+	if eventID.String() != s.event.ID {
+		return nil, domain.ErrEventNotFound
+	}
+	return &s.event, nil
+}
+
+func (s *labelStore) GetEventLabels(ctx context.Context, eventID string) (*domain.EventLabels, error) {
+	if stored, ok := s.labels[eventID]; ok {
+		return &stored, nil
+	}
+	return nil, nil
+}
+
+func (s *labelStore) SaveEventLabels(ctx context.Context, labels domain.EventLabels) error {
+	s.labels[labels.EventID] = labels
+	return nil
+}
+
+func TestLabelsHandler_ReviewerAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const sessionID = "550e8400-e29b-41d4-a716-446655440000"
+	tests := []struct {
+		name           string
+		userID         string
+		roles          []string
+		expectedStatus int
+	}{
+		{name: "reviewer allowed", userID: "reviewer-1", roles: []string{jwt.RoleReviewer}, expectedStatus: http.StatusOK},
+		{name: "admin role allowed", userID: "admin-1", roles: []string{jwt.RoleAdmin}, expectedStatus: http.StatusOK},
+		{name: "listed admin allowed", userID: "admin-2", expectedStatus: http.StatusOK},
+		{name: "session owner allowed", userID: "owner-1", expectedStatus: http.StatusOK},
+		{name: "other user forbidden", userID: "user-2", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewMockAuditRepository(t)
+			repo.On("GetSession", mock.Anything, domain.SessionID(sessionID)).
+				Return(&repository.Session{ID: sessionID, UserID: "owner-1"}, nil).Maybe()
+			store := &labelStore{
+				event:  domain.AuditEntry{ID: testLabeledEvent, SessionID: sessionID, Type: "edit"},
+				labels: map[string]domain.EventLabels{},
+			}
+			reader := service.NewReader(repo, nil, service.ReaderConfig{}, clock.New(), zap.NewNop())
+			handler := NewLabelsHandler(labels.New(store, store, reader, clock.New(), zap.NewNop()), zap.NewNop())
+
+			router := gin.New()
+			router.POST("/events/:id/labels", func(c *gin.Context) {
+				c.Set(middleware.AuthUserIDKey, tt.userID)
+				c.Set(middleware.AuthRolesKey, tt.roles)
+				c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+			}, middleware.GrantReviewerAccess([]string{"admin-2"}), handler.AddEventLabels)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/events/"+testLabeledEvent+"/labels", bytes.NewBufferString(`{"labels":["disputed"]}`))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, []string{"disputed"}, store.labels[testLabeledEvent].Labels)
+			} else {
+				assert.Empty(t, store.labels)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// LegalHolds places, releases and lists legal holds
+type LegalHolds interface {
+	Place(ctx context.Context, scope domain.LegalHoldScope, target, reason, placedBy string) (domain.LegalHold, error)
+	Release(ctx context.Context, id, releasedBy string) (domain.LegalHold, error)
+	List(ctx context.Context, activeOnly bool) ([]domain.LegalHold, error)
+}
+
+// CreateLegalHoldRequest defines the request body for placing a legal hold
+type CreateLegalHoldRequest struct {
+	Scope domain.LegalHoldScope `json:"scope" binding:"required" example:"session"`
+	// Target is a session ID for session holds and a user ID for user holds
+	Target string `json:"target" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Reason string `json:"reason" binding:"required" example:"Litigation 2024-017"`
+}
+
+// LegalHoldList defines the response listing legal holds
+type LegalHoldList struct {
+	Items []domain.LegalHold `json:"items"`
+}
+
+// LegalHoldsHandler handles the legal holds that exempt entries from retention and erasure
+type LegalHoldsHandler struct {
+	holds  LegalHolds
+	logger *zap.Logger
+}
+
+// NewLegalHoldsHandler creates a new legal holds handler
+func NewLegalHoldsHandler(holds LegalHolds, logger *zap.Logger) *LegalHoldsHandler {
+	return &LegalHoldsHandler{
+		holds:  holds,
+		logger: logger,
+	}
+}
+
+// CreateLegalHold handles POST /legal-holds
+// @Summary Place a legal hold
+// @Description Exempts every entry of a session, or every entry created by a user, from retention purges and erasure until the hold is released. Admin only.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body CreateLegalHoldRequest true "Legal hold"
+// @Security BearerAuth
+// @Success 201 {object} domain.LegalHold
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /legal-holds [post]
+func (h *LegalHoldsHandler) CreateLegalHold(c *gin.Context) {
+	var req CreateLegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.WriteError(c, invalidBody(c, err))
+		return
+	}
+
+	hold, err := h.holds.Place(c.Request.Context(), req.Scope, req.Target, req.Reason, middleware.GetAuthUserID(c))
+	if err != nil {
+		// Reasons for an invalid hold are returned to the admin as is
+		if errors.Is(err, domain.ErrInvalidLegalHold) {
+			middleware.WriteError(c, domain.NewAPIError("invalid_legal_hold", err.Error(), http.StatusBadRequest))
+			return
+		}
+		h.logger.Error("failed to place legal hold",
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.String("scope", string(req.Scope)),
+			zap.Error(err),
+		)
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, hold)
+}
+
+// ListLegalHolds handles GET /legal-holds
+// @Summary List legal holds
+// @Description Lists legal holds newest first, including released ones unless active is set. Admin only.
+// @Tags Admin
+// @Produce json
+// @Param active query bool false "Only holds that are still in force (default: false)"
+// @Security BearerAuth
+// @Success 200 {object} LegalHoldList
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /legal-holds [get]
+func (h *LegalHoldsHandler) ListLegalHolds(c *gin.Context) {
+	activeOnly := false
+	if raw := c.Query("active"); raw != "" {
+		var err error
+		if activeOnly, err = strconv.ParseBool(raw); err != nil {
+			middleware.WriteError(c, domain.NewAPIError("bad_request", "active must be true or false", http.StatusBadRequest))
+			return
+		}
+	}
+
+	holds, err := h.holds.List(c.Request.Context(), activeOnly)
+	if err != nil {
+		h.logger.Error("failed to list legal holds",
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.Error(err),
+		)
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, LegalHoldList{Items: holds})
+}
+
+// ReleaseLegalHold handles DELETE /legal-holds/{holdId}
+// @Summary Release a legal hold
+// @Description Ends a legal hold so retention and erasure apply to its entries again. The hold is kept with its release details. Admin only.
+// @Tags Admin
+// @Produce json
+// @Param holdId path string true "Legal hold ID"
+// @Security BearerAuth
+// @Success 200 {object} domain.LegalHold
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /legal-holds/{holdId} [delete]
+func (h *LegalHoldsHandler) ReleaseLegalHold(c *gin.Context) {
+	hold, err := h.holds.Release(c.Request.Context(), c.Param("holdId"), middleware.GetAuthUserID(c))
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		if apiErr.Status >= http.StatusInternalServerError {
+			h.logger.Error("failed to release legal hold",
+				zap.String("request_id", middleware.GetRequestID(c)),
+				zap.String("hold_id", c.Param("holdId")),
+				zap.Error(err),
+			)
+		}
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
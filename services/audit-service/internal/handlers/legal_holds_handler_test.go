@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockLegalHolds is a mock implementation of LegalHolds
+type MockLegalHolds struct {
+	mock.Mock
+}
+
+func (m *MockLegalHolds) Place(ctx context.Context, scope domain.LegalHoldScope, target, reason, placedBy string) (domain.LegalHold, error) {
+	args := m.Called(ctx, scope, target, reason, placedBy)
+	return args.Get(0).(domain.LegalHold), args.Error(1)
+}
+
+func (m *MockLegalHolds) Release(ctx context.Context, id, releasedBy string) (domain.LegalHold, error) {
+	args := m.Called(ctx, id, releasedBy)
+	return args.Get(0).(domain.LegalHold), args.Error(1)
+}
+
+func (m *MockLegalHolds) List(ctx context.Context, activeOnly bool) ([]domain.LegalHold, error) {
+	args := m.Called(ctx, activeOnly)
+	return args.Get(0).([]domain.LegalHold), args.Error(1)
+}
+
+var testLegalHold = domain.LegalHold{
+	ID: "0b8f5e0c-3c1d-4a57-9d0e-6f1b2c3d4e5f", Scope: domain.LegalHoldSession, Target: "550e8400-e29b-41d4-a716-446655440000",
+	Reason: "Litigation 2024-017", PlacedBy: "admin-1", PlacedAt: time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC),
+}
+
+func performLegalHoldRequest(method, target, body string, handle func(c *gin.Context), params ...gin.Param) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.AuthUserIDKey, "admin-1")
+	c.Params = params
+
+	handle(c)
+	return w
+}
+
+func TestLegalHoldsHandler_CreateLegalHold(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		placeErr       error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "placed",
+			body:           `{"scope":"session","target":"550e8400-e29b-41d4-a716-446655440000","reason":"Litigation 2024-017"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid hold",
+			body:           `{"scope":"session","target":"550e8400-e29b-41d4-a716-446655440000","reason":"Litigation 2024-017"}`,
+			placeErr:       fmt.Errorf("%w: target must be a session ID", domain.ErrInvalidLegalHold),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_legal_hold",
+		},
+		{
+			name:           "storage failure",
+			body:           `{"scope":"session","target":"550e8400-e29b-41d4-a716-446655440000","reason":"Litigation 2024-017"}`,
+			placeErr:       errors.New("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "internal_server_error",
+		},
+		{
+			name:           "missing reason",
+			body:           `{"scope":"user","target":"user-2"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "validation_failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			holds := new(MockLegalHolds)
+			if tt.expectedCode != "validation_failed" {
+				holds.On("Place", mock.Anything, domain.LegalHoldSession, testLegalHold.Target, testLegalHold.Reason, "admin-1").
+					Return(testLegalHold, tt.placeErr).Once()
+			}
+			handler := NewLegalHoldsHandler(holds, zap.NewNop())
+
+			w := performLegalHoldRequest("POST", "/api/v1/legal-holds", tt.body, handler.CreateLegalHold)
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedCode != "" {
+				var apiErr domain.APIError
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+				assert.Equal(t, tt.expectedCode, apiErr.Code)
+			} else {
+				var got domain.LegalHold
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				assert.Equal(t, testLegalHold, got)
+			}
+			holds.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLegalHoldsHandler_ListLegalHolds(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	holds := new(MockLegalHolds)
+	holds.On("List", mock.Anything, true).Return([]domain.LegalHold{testLegalHold}, nil).Once()
+	handler := NewLegalHoldsHandler(holds, zap.NewNop())
+
+	w := performLegalHoldRequest("GET", "/api/v1/legal-holds?active=true", "", handler.ListLegalHolds)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var got LegalHoldList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, []domain.LegalHold{testLegalHold}, got.Items)
+
+	w = performLegalHoldRequest("GET", "/api/v1/legal-holds?active=maybe", "", handler.ListLegalHolds)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	holds.AssertExpectations(t)
+}
+
+func TestLegalHoldsHandler_ReleaseLegalHold(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	releasedAt := time.Date(2024, 2, 2, 9, 0, 0, 0, time.UTC)
+	released := testLegalHold
+	released.ReleasedBy = "admin-1"
+	released.ReleasedAt = &releasedAt
+
+	tests := []struct {
+		name           string
+		releaseErr     error
+		expectedStatus int
+	}{
+		{name: "released", expectedStatus: http.StatusOK},
+		{name: "unknown hold", releaseErr: domain.ErrLegalHoldNotFound, expectedStatus: http.StatusNotFound},
+		{name: "already released", releaseErr: domain.ErrLegalHoldReleased, expectedStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			holds := new(MockLegalHolds)
+			holds.On("Release", mock.Anything, testLegalHold.ID, "admin-1").Return(released, tt.releaseErr).Once()
+			handler := NewLegalHoldsHandler(holds, zap.NewNop())
+
+			w := performLegalHoldRequest("DELETE", "/api/v1/legal-holds/"+testLegalHold.ID, "", handler.ReleaseLegalHold,
+				gin.Param{Key: "holdId", Value: testLegalHold.ID})
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.releaseErr == nil {
+				var got domain.LegalHold
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				assert.Equal(t, released, got)
+			}
+			holds.AssertExpectations(t)
+		})
+	}
+}
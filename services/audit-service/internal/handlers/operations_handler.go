@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+	"audit-service/pkg/clock"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RetentionRunner purges expired events on demand
+type RetentionRunner interface {
+	Run(ctx context.Context) error
+}
+
+// OutboxReplayer requeues outbox messages that ran out of delivery attempts
+type OutboxReplayer interface {
+	ReplayOutbox(ctx context.Context, since time.Time) (int, error)
+}
+
+// RollupRunner rebuilds activity rollups on demand; a zero from rebuilds the scheduled window
+type RollupRunner interface {
+	RunFrom(ctx context.Context, from time.Time) (int, error)
+}
+
+// RetentionRunResult defines the response of an on-demand retention run
+type RetentionRunResult struct {
+	Status      string    `json:"status" example:"completed"`
+	StartedAt   time.Time `json:"startedAt"`
+	CompletedAt time.Time `json:"completedAt"`
+}
+
+// OutboxReplayResult defines the response of an outbox replay
+type OutboxReplayResult struct {
+	// Replayed is the number of dead messages made due again
+	Replayed int `json:"replayed" example:"12"`
+}
+
+// RollupRunResult defines the response of an on-demand activity rollup run
+type RollupRunResult struct {
+	Status string `json:"status" example:"completed"`
+	// Rollups is the number of hourly and daily rollups written
+	Rollups     int       `json:"rollups" example:"240"`
+	StartedAt   time.Time `json:"startedAt"`
+	CompletedAt time.Time `json:"completedAt"`
+}
+
+// OperationsHandler handles the maintenance operations operators trigger by hand
+type OperationsHandler struct {
+	retention RetentionRunner
+	outbox    OutboxReplayer
+	rollups   RollupRunner
+	clock     clock.Clock
+	logger    *zap.Logger
+}
+
+// NewOperationsHandler creates a new operations handler; a nil runner or replayer reports
+// the operation as not configured
+func NewOperationsHandler(retention RetentionRunner, outbox OutboxReplayer, rollups RollupRunner, clk clock.Clock, logger *zap.Logger) *OperationsHandler {
+	return &OperationsHandler{
+		retention: retention,
+		outbox:    outbox,
+		rollups:   rollups,
+		clock:     clk,
+		logger:    logger,
+	}
+}
+
+// RunRetention handles POST /admin/retention/run
+// @Summary Run the retention purge
+// @Description Deletes expired events of every retention policy now instead of at the next scheduled run and records the usual retention_purge events. Waits for a scheduled run in progress. Admin only.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} RetentionRunResult
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /admin/retention/run [post]
+func (h *OperationsHandler) RunRetention(c *gin.Context) {
+	if h.retention == nil {
+		middleware.WriteError(c, domain.NewAPIError("retention_disabled", "No retention policies are configured", http.StatusConflict))
+		return
+	}
+
+	h.logger.Info("retention run requested",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.String("admin_id", middleware.GetAuthUserID(c)),
+	)
+
+	startedAt := h.clock.Now().UTC()
+	if err := h.retention.Run(c.Request.Context()); err != nil {
+		h.logger.Error("requested retention run failed",
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.Error(err),
+		)
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, RetentionRunResult{
+		Status:      "completed",
+		StartedAt:   startedAt,
+		CompletedAt: h.clock.Now().UTC(),
+	})
+}
+
+// ReplayOutbox handles POST /admin/outbox/replay
+// @Summary Replay dead outbox messages
+// @Description Makes outbox messages that ran out of delivery attempts due again with a fresh attempt count, so the relay redelivers them. Admin only.
+// @Tags Admin
+// @Produce json
+// @Param since query string false "Only messages created at or after this RFC3339 timestamp"
+// @Security BearerAuth
+// @Success 200 {object} OutboxReplayResult
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /admin/outbox/replay [post]
+func (h *OperationsHandler) ReplayOutbox(c *gin.Context) {
+	if h.outbox == nil {
+		middleware.WriteError(c, domain.NewAPIError("outbox_disabled", "The outbox is not configured", http.StatusConflict))
+		return
+	}
+
+	var since time.Time
+	if value := c.Query("since"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			middleware.WriteError(c, domain.NewAPIError("bad_request", "Invalid 'since' timestamp, expected RFC3339", http.StatusBadRequest))
+			return
+		}
+		since = parsed
+	}
+
+	replayed, err := h.outbox.ReplayOutbox(c.Request.Context(), since)
+	if err != nil {
+		h.logger.Error("failed to replay outbox",
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.Error(err),
+		)
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+
+	h.logger.Info("outbox replayed",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.String("admin_id", middleware.GetAuthUserID(c)),
+		zap.Time("since", since),
+		zap.Int("replayed", replayed),
+	)
+	c.JSON(http.StatusOK, OutboxReplayResult{Replayed: replayed})
+}
+
+// RunRollups handles POST /admin/rollups/run
+// @Summary Rebuild activity rollups
+// @Description Rebuilds the hourly and daily activity rollups from the recorded events now instead of at the next scheduled run. Pass from to backfill history older than the scheduled lookback; rollups are rebuilt from the start of its UTC day. Waits for a scheduled run in progress. Admin only.
+// @Tags Admin
+// @Produce json
+// @Param from query string false "Rebuild from this RFC3339 timestamp instead of the scheduled lookback"
+// @Security BearerAuth
+// @Success 200 {object} RollupRunResult
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /admin/rollups/run [post]
+func (h *OperationsHandler) RunRollups(c *gin.Context) {
+	if h.rollups == nil {
+		middleware.WriteError(c, domain.NewAPIError("rollups_disabled", "Activity rollups are not enabled", http.StatusConflict))
+		return
+	}
+
+	startedAt := h.clock.Now().UTC()
+	var from time.Time
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil || parsed.After(startedAt) {
+			middleware.WriteError(c, domain.NewAPIError("bad_request", "Invalid 'from' timestamp, expected a past RFC3339 timestamp", http.StatusBadRequest))
+			return
+		}
+		from = parsed
+	}
+
+	h.logger.Info("activity rollup requested",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.String("admin_id", middleware.GetAuthUserID(c)),
+		zap.Time("from", from),
+	)
+
+	written, err := h.rollups.RunFrom(c.Request.Context(), from)
+	if err != nil {
+		h.logger.Error("requested activity rollup failed",
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.Error(err),
+		)
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, RollupRunResult{
+		Status:      "completed",
+		Rollups:     written,
+		StartedAt:   startedAt,
+		CompletedAt: h.clock.Now().UTC(),
+	})
+}
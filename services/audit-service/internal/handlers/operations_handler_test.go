@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+	"audit-service/pkg/clock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeRetention advances the clock as a purge would take time
+type fakeRetention struct {
+	clock *clock.FakeClock
+	err   error
+	runs  int
+}
+
+func (f *fakeRetention) Run(context.Context) error {
+	f.runs++
+	f.clock.Advance(2 * time.Second)
+	return f.err
+}
+
+type fakeReplayer struct {
+	since    time.Time
+	replayed int
+}
+
+func (f *fakeReplayer) ReplayOutbox(_ context.Context, since time.Time) (int, error) {
+	f.since = since
+	return f.replayed, nil
+}
+
+type fakeRollups struct {
+	from    time.Time
+	written int
+}
+
+func (f *fakeRollups) RunFrom(_ context.Context, from time.Time) (int, error) {
+	f.from = from
+	return f.written, nil
+}
+
+func performOperation(handle gin.HandlerFunc, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", target, nil)
+	c.Set(middleware.AuthUserIDKey, "admin-1")
+
+	handle(c)
+	return w
+}
+
+func TestOperationsHandler_RunRetention(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(now)
+	retention := &fakeRetention{clock: fakeClock}
+	handler := NewOperationsHandler(retention, nil, nil, fakeClock, zap.NewNop())
+
+	w := performOperation(handler.RunRetention, "/api/v1/admin/retention/run")
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var result RetentionRunResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, RetentionRunResult{Status: "completed", StartedAt: now, CompletedAt: now.Add(2 * time.Second)}, result)
+	assert.Equal(t, 1, retention.runs)
+
+	retention.err = errors.New("database unavailable")
+	w = performOperation(handler.RunRetention, "/api/v1/admin/retention/run")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestOperationsHandler_ReplayOutbox(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	replayer := &fakeReplayer{replayed: 3}
+	handler := NewOperationsHandler(nil, replayer, nil, clock.New(), zap.NewNop())
+
+	w := performOperation(handler.ReplayOutbox, "/api/v1/admin/outbox/replay?since=2024-03-01T00:00:00Z")
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var result OutboxReplayResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, 3, result.Replayed)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), replayer.since)
+
+	w = performOperation(handler.ReplayOutbox, "/api/v1/admin/outbox/replay?since=yesterday")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestOperationsHandler_RunRollups(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Date(2024, 3, 10, 10, 0, 0, 0, time.UTC)
+	rollups := &fakeRollups{written: 48}
+	handler := NewOperationsHandler(nil, nil, rollups, clock.NewFakeClock(now), zap.NewNop())
+
+	w := performOperation(handler.RunRollups, "/api/v1/admin/rollups/run?from=2024-03-01T00:00:00Z")
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var result RollupRunResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, RollupRunResult{Status: "completed", Rollups: 48, StartedAt: now, CompletedAt: now}, result)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), rollups.from)
+
+	// Without from the scheduled window is rebuilt
+	w = performOperation(handler.RunRollups, "/api/v1/admin/rollups/run")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, rollups.from.IsZero())
+
+	w = performOperation(handler.RunRollups, "/api/v1/admin/rollups/run?from=2024-04-01T00:00:00Z")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestOperationsHandler_NotConfigured(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewOperationsHandler(nil, nil, nil, clock.New(), zap.NewNop())
+
+	tests := []struct {
+		name         string
+		handle       gin.HandlerFunc
+		target       string
+		expectedCode string
+	}{
+		{name: "retention", handle: handler.RunRetention, target: "/api/v1/admin/retention/run", expectedCode: "retention_disabled"},
+		{name: "outbox", handle: handler.ReplayOutbox, target: "/api/v1/admin/outbox/replay", expectedCode: "outbox_disabled"},
+		{name: "rollups", handle: handler.RunRollups, target: "/api/v1/admin/rollups/run", expectedCode: "rollups_disabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performOperation(tt.handle, tt.target)
+
+			assert.Equal(t, http.StatusConflict, w.Code)
+			var apiErr domain.APIError
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+			assert.Equal(t, tt.expectedCode, apiErr.Code)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+	"audit-service/internal/quota"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// QuotaHandler reports the use of the daily event quotas
+type QuotaHandler struct {
+	quotas *quota.Enforcer
+	logger *zap.Logger
+}
+
+// NewQuotaHandler creates a new quota handler
+func NewQuotaHandler(quotas *quota.Enforcer, logger *zap.Logger) *QuotaHandler {
+	return &QuotaHandler{
+		quotas: quotas,
+		logger: logger,
+	}
+}
+
+// GetSessionQuota handles GET /sessions/{sessionId}/quota
+// @Summary Get the event quotas of a session
+// @Description Returns how many events the session and the caller recorded today, out of their daily quotas, and when the quotas reset. Quotas reset at midnight UTC; a limit of 0 is unlimited and reports no use.
+// @Tags Audit
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Security BearerAuth
+// @Success 200 {object} domain.QuotaStatus
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /sessions/{sessionId}/quota [get]
+func (h *QuotaHandler) GetSessionQuota(c *gin.Context) {
+	status, err := h.quotas.Status(c.Request.Context(), c.Param("sessionId"), middleware.GetAuthUserID(c))
+	if err != nil {
+		h.logger.Error("failed to get quota status",
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.String("session_id", c.Param("sessionId")),
+			zap.Error(err),
+		)
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// writeQuotaExceeded answers a submission over a daily quota with 429, telling the client in
+// Retry-After how many seconds remain until the quota resets
+func writeQuotaExceeded(c *gin.Context, err error) {
+	var quotaErr *domain.QuotaExceededError
+	if errors.As(err, &quotaErr) {
+		c.Header("Retry-After", strconv.Itoa(max(int(math.Ceil(quotaErr.RetryAfter.Seconds())), 1)))
+	}
+	middleware.WriteError(c, domain.ToAPIError(err))
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/internal/middleware"
+	"audit-service/internal/quota"
+	"audit-service/pkg/clock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestQuotas(cfg quota.Config) *quota.Enforcer {
+	fakeClock := clock.NewFakeClock(testNow)
+	return quota.New(quota.NewMemoryStore(fakeClock), cfg, fakeClock, metrics.New(), zap.NewNop())
+}
+
+func TestQuotaHandler_GetSessionQuota(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "550e8400-e29b-41d4-a716-446655440000"
+	quotas := newTestQuotas(quota.Config{SessionDaily: 100})
+	_, err := quotas.Reserve(context.Background(), []domain.AuditEntry{
+		{SessionID: sessionID, UserID: "user-1", Type: "edit"},
+		{SessionID: sessionID, UserID: "user-1", Type: "edit"},
+	})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/sessions/"+sessionID+"/quota", nil)
+	c.Set(middleware.AuthUserIDKey, "user-1")
+	c.Params = gin.Params{{Key: "sessionId", Value: sessionID}}
+
+	NewQuotaHandler(quotas, zap.NewNop()).GetSessionQuota(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var status domain.QuotaStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	resetAt := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, domain.QuotaStatus{
+		SessionID: sessionID,
+		UserID:    "user-1",
+		Session:   domain.QuotaUsage{Scope: "session", Limit: 100, Used: 2, Remaining: 98, ResetAt: resetAt},
+		User:      domain.QuotaUsage{Scope: "user", ResetAt: resetAt},
+	}, status)
+}
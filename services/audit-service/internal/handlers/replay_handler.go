@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ReplayJobs starts and tracks replays of stored events to downstream consumers
+type ReplayJobs interface {
+	Start(ctx context.Context, request domain.ReplayJob) (domain.ReplayJob, error)
+	Get(ctx context.Context, id string) (domain.ReplayJob, error)
+}
+
+// ReplayRequest defines the request body for replaying events
+type ReplayRequest struct {
+	// From and To bound the event timestamps, inclusively
+	From time.Time `json:"from" binding:"required" example:"2024-01-01T00:00:00Z"`
+	To   time.Time `json:"to" binding:"required" example:"2024-01-02T00:00:00Z"`
+	// Types restricts the replay to these event types; omit it to replay every type
+	Types []string `json:"types,omitempty" example:"share,export"`
+	// SessionID restricts the replay to one session; omit it to replay every session
+	SessionID string `json:"sessionId,omitempty" binding:"omitempty,uuid_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Target is webhook, to post the events to WebhookURL, or outbox, to forward them to every outbox webhook
+	Target domain.ReplayTarget `json:"target" binding:"required" example:"webhook"`
+	// WebhookURL must be one of the configured outbox webhooks
+	WebhookURL string `json:"webhookUrl,omitempty" example:"https://notifications.example.com/audit"`
+	// RatePerSecond caps the events sent per second; defaults to REPLAY_MAX_RATE
+	RatePerSecond int `json:"ratePerSecond,omitempty" example:"50"`
+}
+
+// ReplayHandler handles replays of stored events
+type ReplayHandler struct {
+	jobs   ReplayJobs
+	logger *zap.Logger
+}
+
+// NewReplayHandler creates a new replay handler; nil jobs report replays as not configured
+func NewReplayHandler(jobs ReplayJobs, logger *zap.Logger) *ReplayHandler {
+	return &ReplayHandler{
+		jobs:   jobs,
+		logger: logger,
+	}
+}
+
+// ReplayEvents handles POST /replay
+// @Summary Replay events to downstream consumers
+// @Description Re-delivers the stored events of the caller's organization in a time range, optionally of some types or one session, to a consumer that lost data. The webhook target posts them to one of the configured outbox webhooks, the outbox target queues them in the outbox, which forwards them to every outbox webhook. Events are sent newest first at no more than ratePerSecond. The work runs in the background; poll the returned job for progress. Admin only.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body ReplayRequest true "Events to replay and where to send them"
+// @Security BearerAuth
+// @Success 202 {object} domain.ReplayJob
+// @Header 202 {string} Location "URL of the replay job"
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Failure 503 {object} domain.APIError
+// @Router /replay [post]
+func (h *ReplayHandler) ReplayEvents(c *gin.Context) {
+	if h.jobs == nil {
+		middleware.WriteError(c, domain.NewAPIError("outbox_disabled", "The outbox is not configured", http.StatusConflict))
+		return
+	}
+
+	var req ReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.WriteError(c, invalidBody(c, err))
+		return
+	}
+
+	requestedBy := middleware.GetAuthUserID(c)
+	job, err := h.jobs.Start(c.Request.Context(), domain.ReplayJob{
+		Target:        req.Target,
+		WebhookURL:    req.WebhookURL,
+		From:          req.From,
+		To:            req.To,
+		Types:         req.Types,
+		SessionID:     req.SessionID,
+		RatePerSecond: req.RatePerSecond,
+		RequestedBy:   requestedBy,
+	})
+	if err != nil {
+		h.writeError(c, "failed to start replay job", err)
+		return
+	}
+
+	h.logger.Info("replay job accepted",
+		zap.String("request_id", middleware.GetRequestID(c)),
+		zap.String("job_id", job.ID),
+		zap.String("target", string(job.Target)),
+		zap.Int("total", job.Total),
+		zap.String("requested_by", requestedBy),
+	)
+
+	c.Header("Location", "/api/v1/replay-jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetJob handles GET /replay-jobs/{jobId}
+// @Summary Get a replay job
+// @Description Returns the progress of a replay job. Finished jobs are kept for 24 hours. Admin only.
+// @Tags Admin
+// @Produce json
+// @Param jobId path string true "Replay job ID"
+// @Security BearerAuth
+// @Success 200 {object} domain.ReplayJob
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Router /replay-jobs/{jobId} [get]
+func (h *ReplayHandler) GetJob(c *gin.Context) {
+	if h.jobs == nil {
+		middleware.WriteError(c, domain.NewAPIError("outbox_disabled", "The outbox is not configured", http.StatusConflict))
+		return
+	}
+
+	job, err := h.jobs.Get(c.Request.Context(), c.Param("jobId"))
+	if err != nil {
+		h.writeError(c, "failed to get replay job", err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, job)
+}
+
+// writeError writes the API error of a failed replay operation, logging server errors
+func (h *ReplayHandler) writeError(c *gin.Context, message string, err error) {
+	// Reasons for an invalid replay are returned to the admin as is
+	if errors.Is(err, domain.ErrInvalidReplay) {
+		middleware.WriteError(c, domain.NewAPIError("invalid_replay", err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	apiErr := domain.ToAPIError(err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		h.logger.Error(message,
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.Error(err),
+		)
+	}
+	middleware.WriteError(c, apiErr)
+}
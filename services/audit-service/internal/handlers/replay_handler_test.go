@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockReplayJobs is a mock implementation of ReplayJobs
+type MockReplayJobs struct {
+	mock.Mock
+}
+
+func (m *MockReplayJobs) Start(ctx context.Context, request domain.ReplayJob) (domain.ReplayJob, error) {
+	args := m.Called(request)
+	return args.Get(0).(domain.ReplayJob), args.Error(1)
+}
+
+func (m *MockReplayJobs) Get(ctx context.Context, id string) (domain.ReplayJob, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.ReplayJob), args.Error(1)
+}
+
+func performReplay(handler *ReplayHandler, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/replay", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.AuthUserIDKey, "admin-1")
+
+	handler.ReplayEvents(c)
+	return w
+}
+
+func TestReplayHandler_ReplayEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const body = `{"from":"2024-01-01T00:00:00Z","to":"2024-01-02T00:00:00Z","types":["share"],
+		"target":"webhook","webhookUrl":"https://notifications.example.com/audit","ratePerSecond":50}`
+	request := domain.ReplayJob{
+		Target:        domain.ReplayWebhook,
+		WebhookURL:    "https://notifications.example.com/audit",
+		From:          time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:            time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Types:         []string{"share"},
+		RatePerSecond: 50,
+		RequestedBy:   "admin-1",
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		startErr       error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "webhook replay", body: body, expectedStatus: http.StatusAccepted},
+		{name: "missing range", body: `{"target":"outbox"}`, expectedStatus: http.StatusBadRequest, expectedCode: "validation_failed"},
+		{
+			name: "unregistered webhook", body: body, startErr: fmt.Errorf("%w: webhookUrl is not a registered webhook", domain.ErrInvalidReplay),
+			expectedStatus: http.StatusBadRequest, expectedCode: "invalid_replay",
+		},
+		{name: "shutting down", body: body, startErr: domain.ErrServiceUnavailable, expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs := new(MockReplayJobs)
+			job := request
+			job.ID = "job-1"
+			job.Status = domain.ReplayPending
+			job.Total = 40
+			if tt.body == body {
+				jobs.On("Start", request).Return(job, tt.startErr)
+			}
+
+			w := performReplay(NewReplayHandler(jobs, zap.NewNop()), tt.body)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusAccepted {
+				assert.Equal(t, "/api/v1/replay-jobs/job-1", w.Header().Get("Location"))
+				var got domain.ReplayJob
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				assert.Equal(t, job, got)
+			}
+			if tt.expectedCode != "" {
+				var apiErr domain.APIError
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+				assert.Equal(t, tt.expectedCode, apiErr.Code)
+			}
+			jobs.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReplayHandler_OutboxDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := performReplay(NewReplayHandler(nil, zap.NewNop()), `{}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var apiErr domain.APIError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+	assert.Equal(t, "outbox_disabled", apiErr.Code)
+}
+
+func TestReplayHandler_GetJob(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jobs := new(MockReplayJobs)
+	jobs.On("Get", "job-1").Return(domain.ReplayJob{ID: "job-1", Status: domain.ReplayRunning, Total: 40, Replayed: 12}, nil)
+	jobs.On("Get", "missing").Return(domain.ReplayJob{}, domain.ErrReplayJobNotFound)
+	handler := NewReplayHandler(jobs, zap.NewNop())
+
+	get := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/replay-jobs/"+id, nil)
+		c.Params = []gin.Param{{Key: "jobId", Value: id}}
+		handler.GetJob(c)
+		return w
+	}
+
+	w := get("job-1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var job domain.ReplayJob
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+	assert.Equal(t, 12, job.Replayed)
+
+	assert.Equal(t, http.StatusNotFound, get("missing").Code)
+}
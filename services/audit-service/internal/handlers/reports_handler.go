@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Reports manages the scheduled reports and runs them on demand
+type Reports interface {
+	Create(ctx context.Context, report domain.Report) (domain.Report, error)
+	Get(ctx context.Context, id string) (domain.Report, error)
+	List(ctx context.Context) ([]domain.Report, error)
+	Delete(ctx context.Context, id, deletedBy string) error
+	Run(ctx context.Context, id string, from, to time.Time) (domain.ReportRun, error)
+	Runs(ctx context.Context, id string, limit int) ([]domain.ReportRun, error)
+}
+
+// CreateReportRequest defines the request body for scheduling a report
+type CreateReportRequest struct {
+	Name string            `json:"name" binding:"required" example:"Weekly session activity"`
+	Kind domain.ReportKind `json:"kind" binding:"required" example:"session_activity"`
+	// SessionID restricts the report to one session; omit it to cover every session
+	SessionID string `json:"sessionId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Schedule is a five-field cron expression in UTC, or @daily, @weekly or @monthly
+	Schedule string                `json:"schedule" binding:"required" example:"0 6 * * 1"`
+	Format   domain.ReportFormat   `json:"format" binding:"required" example:"csv"`
+	Delivery domain.ReportDelivery `json:"delivery" binding:"required" example:"webhook"`
+	// Target is the webhook URL, or the storage path prefix of the uploaded reports
+	Target string `json:"target,omitempty" example:"https://reports.example.com/audit"`
+}
+
+// RunReportRequest defines the optional request body for running a report now
+type RunReportRequest struct {
+	// From defaults to the last scheduled run, or the creation of the report
+	From *time.Time `json:"from,omitempty" example:"2024-01-01T00:00:00Z"`
+	// To defaults to now
+	To *time.Time `json:"to,omitempty" example:"2024-01-08T00:00:00Z"`
+}
+
+// ReportList defines the response listing the scheduled reports
+type ReportList struct {
+	Items []domain.Report `json:"items"`
+}
+
+// ReportRunList defines the response listing the runs of a report
+type ReportRunList struct {
+	Items []domain.ReportRun `json:"items"`
+}
+
+// ReportsHandler handles the scheduled reports and their run history
+type ReportsHandler struct {
+	reports Reports
+	logger  *zap.Logger
+}
+
+// NewReportsHandler creates a new reports handler
+func NewReportsHandler(reports Reports, logger *zap.Logger) *ReportsHandler {
+	return &ReportsHandler{
+		reports: reports,
+		logger:  logger,
+	}
+}
+
+// CreateReport handles POST /reports
+// @Summary Schedule a report
+// @Description Schedules a recurring session activity or export summary report of the organization of the caller. Each run covers the events since the previous scheduled run and is delivered as CSV or table-shaped JSON to a webhook or Supabase Storage. Admin only.
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Param request body CreateReportRequest true "Report"
+// @Security BearerAuth
+// @Success 201 {object} domain.Report
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /reports [post]
+func (h *ReportsHandler) CreateReport(c *gin.Context) {
+	var req CreateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.WriteError(c, invalidBody(c, err))
+		return
+	}
+
+	report, err := h.reports.Create(c.Request.Context(), domain.Report{
+		Name:      req.Name,
+		Kind:      req.Kind,
+		SessionID: req.SessionID,
+		Schedule:  req.Schedule,
+		Format:    req.Format,
+		Delivery:  req.Delivery,
+		Target:    req.Target,
+		CreatedBy: middleware.GetAuthUserID(c),
+	})
+	if err != nil {
+		h.writeError(c, "failed to create report", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+// ListReports handles GET /reports
+// @Summary List reports
+// @Description Lists the scheduled reports of the organization of the caller by name. Admin only.
+// @Tags Reports
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ReportList
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /reports [get]
+func (h *ReportsHandler) ListReports(c *gin.Context) {
+	reports, err := h.reports.List(c.Request.Context())
+	if err != nil {
+		h.writeError(c, "failed to list reports", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ReportList{Items: reports})
+}
+
+// GetReport handles GET /reports/{reportId}
+// @Summary Get a report
+// @Description Returns a scheduled report with its next run. Admin only.
+// @Tags Reports
+// @Produce json
+// @Param reportId path string true "Report ID"
+// @Security BearerAuth
+// @Success 200 {object} domain.Report
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /reports/{reportId} [get]
+func (h *ReportsHandler) GetReport(c *gin.Context) {
+	report, err := h.reports.Get(c.Request.Context(), c.Param("reportId"))
+	if err != nil {
+		h.writeError(c, "failed to get report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// DeleteReport handles DELETE /reports/{reportId}
+// @Summary Delete a report
+// @Description Stops a scheduled report and deletes its run history. Delivered reports are kept. Admin only.
+// @Tags Reports
+// @Param reportId path string true "Report ID"
+// @Security BearerAuth
+// @Success 204
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /reports/{reportId} [delete]
+func (h *ReportsHandler) DeleteReport(c *gin.Context) {
+	if err := h.reports.Delete(c.Request.Context(), c.Param("reportId"), middleware.GetAuthUserID(c)); err != nil {
+		h.writeError(c, "failed to delete report", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RunReport handles POST /reports/{reportId}/runs
+// @Summary Run a report now
+// @Description Generates and delivers a report outside of its schedule, by default for the events since its last scheduled run. The schedule is not changed. A failed generation or delivery is returned as a run with status failed. Admin only.
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Param reportId path string true "Report ID"
+// @Param request body RunReportRequest false "Reported period"
+// @Security BearerAuth
+// @Success 201 {object} domain.ReportRun
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /reports/{reportId}/runs [post]
+func (h *ReportsHandler) RunReport(c *gin.Context) {
+	var req RunReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		middleware.WriteError(c, invalidBody(c, err))
+		return
+	}
+
+	var from, to time.Time
+	if req.From != nil {
+		from = *req.From
+	}
+	if req.To != nil {
+		to = *req.To
+	}
+
+	run, err := h.reports.Run(c.Request.Context(), c.Param("reportId"), from, to)
+	if err != nil {
+		h.writeError(c, "failed to run report", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, run)
+}
+
+// ListReportRuns handles GET /reports/{reportId}/runs
+// @Summary List report runs
+// @Description Lists the latest scheduled and manual runs of a report, newest first. Admin only.
+// @Tags Reports
+// @Produce json
+// @Param reportId path string true "Report ID"
+// @Param limit query int false "Number of runs (default: 20, max: 100)"
+// @Security BearerAuth
+// @Success 200 {object} ReportRunList
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /reports/{reportId}/runs [get]
+func (h *ReportsHandler) ListReportRuns(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		middleware.WriteError(c, domain.NewAPIError("bad_request", "Invalid limit parameter", http.StatusBadRequest))
+		return
+	}
+
+	runs, err := h.reports.Runs(c.Request.Context(), c.Param("reportId"), limit)
+	if err != nil {
+		h.writeError(c, "failed to list report runs", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ReportRunList{Items: runs})
+}
+
+// writeError writes the API error of a failed report operation, logging server errors
+func (h *ReportsHandler) writeError(c *gin.Context, message string, err error) {
+	// Reasons for an invalid report are returned to the admin as is
+	if errors.Is(err, domain.ErrInvalidReport) {
+		middleware.WriteError(c, domain.NewAPIError("invalid_report", err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	apiErr := domain.ToAPIError(err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		h.logger.Error(message,
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.String("report_id", c.Param("reportId")),
+			zap.Error(err),
+		)
+	}
+	middleware.WriteError(c, apiErr)
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockReports is a mock implementation of Reports
+type MockReports struct {
+	mock.Mock
+}
+
+func (m *MockReports) Create(ctx context.Context, report domain.Report) (domain.Report, error) {
+	args := m.Called(ctx, report)
+	return args.Get(0).(domain.Report), args.Error(1)
+}
+
+func (m *MockReports) Get(ctx context.Context, id string) (domain.Report, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.Report), args.Error(1)
+}
+
+func (m *MockReports) List(ctx context.Context) ([]domain.Report, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Report), args.Error(1)
+}
+
+func (m *MockReports) Delete(ctx context.Context, id, deletedBy string) error {
+	args := m.Called(ctx, id, deletedBy)
+	return args.Error(0)
+}
+
+func (m *MockReports) Run(ctx context.Context, id string, from, to time.Time) (domain.ReportRun, error) {
+	args := m.Called(ctx, id, from, to)
+	return args.Get(0).(domain.ReportRun), args.Error(1)
+}
+
+func (m *MockReports) Runs(ctx context.Context, id string, limit int) ([]domain.ReportRun, error) {
+	args := m.Called(ctx, id, limit)
+	return args.Get(0).([]domain.ReportRun), args.Error(1)
+}
+
+const testReportID = "0b8f5e0c-3c1d-4a57-9d0e-6f1b2c3d4e5f"
+
+var testReport = domain.Report{
+	ID: testReportID, Name: "Weekly session activity", Kind: domain.ReportSessionActivity, Schedule: "0 6 * * 1",
+	Format: domain.ReportCSV, Delivery: domain.ReportWebhook, Target: "https://reports.example.com/audit", CreatedBy: "admin-1",
+	CreatedAt: time.Date(2024, 1, 17, 10, 30, 0, 0, time.UTC), NextRunAt: time.Date(2024, 1, 22, 6, 0, 0, 0, time.UTC),
+}
+
+func performReportRequest(method, target, body string, handle func(c *gin.Context)) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.AuthUserIDKey, "admin-1")
+	c.Params = gin.Params{{Key: "reportId", Value: testReportID}}
+
+	handle(c)
+	c.Writer.WriteHeaderNow()
+	return w
+}
+
+func TestReportsHandler_CreateReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := `{"name":"Weekly session activity","kind":"session_activity","schedule":"0 6 * * 1","format":"csv",
+		"delivery":"webhook","target":"https://reports.example.com/audit"}`
+	requested := domain.Report{
+		Name: "Weekly session activity", Kind: domain.ReportSessionActivity, Schedule: "0 6 * * 1", Format: domain.ReportCSV,
+		Delivery: domain.ReportWebhook, Target: "https://reports.example.com/audit", CreatedBy: "admin-1",
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		createErr      error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "created", body: body, expectedStatus: http.StatusCreated},
+		{
+			name:           "invalid report",
+			body:           body,
+			createErr:      fmt.Errorf("%w: invalid cron schedule: expected 5 fields, got 3", domain.ErrInvalidReport),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_report",
+		},
+		{name: "missing schedule", body: `{"name":"Weekly","kind":"session_activity","format":"csv","delivery":"webhook"}`,
+			expectedStatus: http.StatusBadRequest, expectedCode: "validation_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := new(MockReports)
+			if tt.expectedCode != "validation_failed" {
+				reports.On("Create", mock.Anything, requested).Return(testReport, tt.createErr)
+			}
+			handler := NewReportsHandler(reports, zap.NewNop())
+
+			w := performReportRequest(http.MethodPost, "/api/v1/reports", tt.body, handler.CreateReport)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedCode != "" {
+				var apiErr domain.APIError
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+				assert.Equal(t, tt.expectedCode, apiErr.Code)
+			} else {
+				var report domain.Report
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+				assert.Equal(t, testReport, report)
+			}
+			reports.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReportsHandler_GetReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reports := new(MockReports)
+	reports.On("Get", mock.Anything, testReportID).Return(domain.Report{}, domain.ErrReportNotFound)
+	handler := NewReportsHandler(reports, zap.NewNop())
+
+	w := performReportRequest(http.MethodGet, "/api/v1/reports/"+testReportID, "", handler.GetReport)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	reports.AssertExpectations(t)
+}
+
+func TestReportsHandler_DeleteReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reports := new(MockReports)
+	reports.On("Delete", mock.Anything, testReportID, "admin-1").Return(nil)
+	handler := NewReportsHandler(reports, zap.NewNop())
+
+	w := performReportRequest(http.MethodDelete, "/api/v1/reports/"+testReportID, "", handler.DeleteReport)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	reports.AssertExpectations(t)
+}
+
+func TestReportsHandler_RunReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	run := domain.ReportRun{ID: "run-1", ReportID: testReportID, Trigger: domain.ReportTriggerManual, Status: domain.ReportRunSucceeded}
+
+	t.Run("default period", func(t *testing.T) {
+		reports := new(MockReports)
+		reports.On("Run", mock.Anything, testReportID, time.Time{}, time.Time{}).Return(run, nil)
+		handler := NewReportsHandler(reports, zap.NewNop())
+
+		w := performReportRequest(http.MethodPost, "/api/v1/reports/"+testReportID+"/runs", "", handler.RunReport)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		reports.AssertExpectations(t)
+	})
+
+	t.Run("explicit period", func(t *testing.T) {
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+		reports := new(MockReports)
+		reports.On("Run", mock.Anything, testReportID, from, to).Return(run, nil)
+		handler := NewReportsHandler(reports, zap.NewNop())
+
+		w := performReportRequest(http.MethodPost, "/api/v1/reports/"+testReportID+"/runs",
+			`{"from":"2024-01-01T00:00:00Z","to":"2024-01-08T00:00:00Z"}`, handler.RunReport)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		reports.AssertExpectations(t)
+	})
+}
+
+func TestReportsHandler_ListReportRuns(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("lists runs", func(t *testing.T) {
+		runs := []domain.ReportRun{{ID: "run-1", ReportID: testReportID, Status: domain.ReportRunFailed, Error: "status 502"}}
+		reports := new(MockReports)
+		reports.On("Runs", mock.Anything, testReportID, 5).Return(runs, nil)
+		handler := NewReportsHandler(reports, zap.NewNop())
+
+		w := performReportRequest(http.MethodGet, "/api/v1/reports/"+testReportID+"/runs?limit=5", "", handler.ListReportRuns)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var list ReportRunList
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		assert.Equal(t, runs, list.Items)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		handler := NewReportsHandler(new(MockReports), zap.NewNop())
+
+		w := performReportRequest(http.MethodGet, "/api/v1/reports/"+testReportID+"/runs?limit=x", "", handler.ListReportRuns)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Revocations adds, lifts and lists token revocations
+type Revocations interface {
+	Revoke(ctx context.Context, kind domain.RevocationKind, target, reason, revokedBy string, expiresAt *time.Time) (domain.Revocation, error)
+	Lift(ctx context.Context, id, liftedBy string) (domain.Revocation, error)
+	List(ctx context.Context, activeOnly bool) ([]domain.Revocation, error)
+}
+
+// CreateRevocationRequest defines the request body for revoking tokens
+type CreateRevocationRequest struct {
+	Kind domain.RevocationKind `json:"kind" binding:"required" example:"user"`
+	// Target is a user ID, a session_id claim or a jti claim, depending on the kind
+	Target string `json:"target" binding:"required" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Reason string `json:"reason" binding:"required" example:"Account compromised"`
+	// ExpiresAt ends the revocation on its own, e.g. when the revoked tokens have expired anyway
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" example:"2024-01-02T10:00:00Z"`
+}
+
+// RevocationList defines the response listing token revocations
+type RevocationList struct {
+	Items []domain.Revocation `json:"items"`
+}
+
+// RevocationsHandler handles the revocations that reject access tokens before they expire
+type RevocationsHandler struct {
+	revocations Revocations
+	logger      *zap.Logger
+}
+
+// NewRevocationsHandler creates a new revocations handler
+func NewRevocationsHandler(revocations Revocations, logger *zap.Logger) *RevocationsHandler {
+	return &RevocationsHandler{
+		revocations: revocations,
+		logger:      logger,
+	}
+}
+
+// CreateRevocation handles POST /revocations
+// @Summary Revoke access tokens
+// @Description Rejects every token of a user, the tokens of a login session (session_id claim) or a single token (jti claim) from the next request on, until the revocation is lifted or expires. Only tokens of the admin's organization are affected. Admin only.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body CreateRevocationRequest true "Revocation"
+// @Security BearerAuth
+// @Success 201 {object} domain.Revocation
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /revocations [post]
+func (h *RevocationsHandler) CreateRevocation(c *gin.Context) {
+	var req CreateRevocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.WriteError(c, invalidBody(c, err))
+		return
+	}
+
+	revocation, err := h.revocations.Revoke(c.Request.Context(), req.Kind, req.Target, req.Reason, middleware.GetAuthUserID(c), req.ExpiresAt)
+	if err != nil {
+		// Reasons for an invalid revocation are returned to the admin as is
+		if errors.Is(err, domain.ErrInvalidRevocation) {
+			middleware.WriteError(c, domain.NewAPIError("invalid_revocation", err.Error(), http.StatusBadRequest))
+			return
+		}
+		h.logger.Error("failed to revoke tokens",
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.String("kind", string(req.Kind)),
+			zap.Error(err),
+		)
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, revocation)
+}
+
+// ListRevocations handles GET /revocations
+// @Summary List token revocations
+// @Description Lists token revocations newest first, including lifted and expired ones unless active is set. Admin only.
+// @Tags Admin
+// @Produce json
+// @Param active query bool false "Only revocations that are still in force (default: false)"
+// @Security BearerAuth
+// @Success 200 {object} RevocationList
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /revocations [get]
+func (h *RevocationsHandler) ListRevocations(c *gin.Context) {
+	activeOnly := false
+	if raw := c.Query("active"); raw != "" {
+		var err error
+		if activeOnly, err = strconv.ParseBool(raw); err != nil {
+			middleware.WriteError(c, domain.NewAPIError("bad_request", "active must be true or false", http.StatusBadRequest))
+			return
+		}
+	}
+
+	revocations, err := h.revocations.List(c.Request.Context(), activeOnly)
+	if err != nil {
+		h.logger.Error("failed to list revocations",
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.Error(err),
+		)
+		middleware.WriteError(c, domain.ToAPIError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, RevocationList{Items: revocations})
+}
+
+// LiftRevocation handles DELETE /revocations/{revocationId}
+// @Summary Lift a token revocation
+// @Description Ends a revocation so the tokens it covered are accepted again until they expire. The revocation is kept with its lift details. Admin only.
+// @Tags Admin
+// @Produce json
+// @Param revocationId path string true "Revocation ID"
+// @Security BearerAuth
+// @Success 200 {object} domain.Revocation
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /revocations/{revocationId} [delete]
+func (h *RevocationsHandler) LiftRevocation(c *gin.Context) {
+	revocation, err := h.revocations.Lift(c.Request.Context(), c.Param("revocationId"), middleware.GetAuthUserID(c))
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		if apiErr.Status >= http.StatusInternalServerError {
+			h.logger.Error("failed to lift revocation",
+				zap.String("request_id", middleware.GetRequestID(c)),
+				zap.String("revocation_id", c.Param("revocationId")),
+				zap.Error(err),
+			)
+		}
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, revocation)
+}
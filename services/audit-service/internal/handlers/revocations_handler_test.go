@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockRevocations is a mock implementation of Revocations
+type MockRevocations struct {
+	mock.Mock
+}
+
+func (m *MockRevocations) Revoke(ctx context.Context, kind domain.RevocationKind, target, reason, revokedBy string, expiresAt *time.Time) (domain.Revocation, error) {
+	args := m.Called(ctx, kind, target, reason, revokedBy, expiresAt)
+	return args.Get(0).(domain.Revocation), args.Error(1)
+}
+
+func (m *MockRevocations) Lift(ctx context.Context, id, liftedBy string) (domain.Revocation, error) {
+	args := m.Called(ctx, id, liftedBy)
+	return args.Get(0).(domain.Revocation), args.Error(1)
+}
+
+func (m *MockRevocations) List(ctx context.Context, activeOnly bool) ([]domain.Revocation, error) {
+	args := m.Called(ctx, activeOnly)
+	return args.Get(0).([]domain.Revocation), args.Error(1)
+}
+
+var testRevocation = domain.Revocation{
+	ID: "7d9f2b1e-4c3a-4e8b-9f0a-1b2c3d4e5f60", Kind: domain.RevocationUser, Target: "user-2",
+	Reason: "Account compromised", RevokedBy: "admin-1", RevokedAt: time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC),
+}
+
+func TestRevocationsHandler_CreateRevocation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	expiresAt := time.Date(2024, 2, 2, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		body           string
+		expiresAt      *time.Time
+		revokeErr      error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "revoked",
+			body:           `{"kind":"user","target":"user-2","reason":"Account compromised"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "with expiry",
+			body:           `{"kind":"user","target":"user-2","reason":"Account compromised","expiresAt":"2024-02-02T09:00:00Z"}`,
+			expiresAt:      &expiresAt,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid revocation",
+			body:           `{"kind":"user","target":"user-2","reason":"Account compromised"}`,
+			revokeErr:      fmt.Errorf("%w: expiresAt must be in the future", domain.ErrInvalidRevocation),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_revocation",
+		},
+		{
+			name:           "missing reason",
+			body:           `{"kind":"user","target":"user-2"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "validation_failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revocations := new(MockRevocations)
+			if tt.expectedCode != "validation_failed" {
+				revocations.On("Revoke", mock.Anything, domain.RevocationUser, "user-2", "Account compromised", "admin-1", tt.expiresAt).
+					Return(testRevocation, tt.revokeErr).Once()
+			}
+			handler := NewRevocationsHandler(revocations, zap.NewNop())
+
+			w := performLegalHoldRequest("POST", "/api/v1/revocations", tt.body, handler.CreateRevocation)
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedCode != "" {
+				var apiErr domain.APIError
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+				assert.Equal(t, tt.expectedCode, apiErr.Code)
+			} else {
+				var got domain.Revocation
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				assert.Equal(t, testRevocation, got)
+			}
+			revocations.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRevocationsHandler_ListRevocations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	revocations := new(MockRevocations)
+	revocations.On("List", mock.Anything, true).Return([]domain.Revocation{testRevocation}, nil).Once()
+	handler := NewRevocationsHandler(revocations, zap.NewNop())
+
+	w := performLegalHoldRequest("GET", "/api/v1/revocations?active=true", "", handler.ListRevocations)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var got RevocationList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, []domain.Revocation{testRevocation}, got.Items)
+
+	w = performLegalHoldRequest("GET", "/api/v1/revocations?active=maybe", "", handler.ListRevocations)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	revocations.AssertExpectations(t)
+}
+
+func TestRevocationsHandler_LiftRevocation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	liftedAt := time.Date(2024, 2, 2, 9, 0, 0, 0, time.UTC)
+	lifted := testRevocation
+	lifted.LiftedBy = "admin-1"
+	lifted.LiftedAt = &liftedAt
+
+	tests := []struct {
+		name           string
+		liftErr        error
+		expectedStatus int
+	}{
+		{name: "lifted", expectedStatus: http.StatusOK},
+		{name: "unknown revocation", liftErr: domain.ErrRevocationNotFound, expectedStatus: http.StatusNotFound},
+		{name: "already lifted", liftErr: domain.ErrRevocationLifted, expectedStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revocations := new(MockRevocations)
+			revocations.On("Lift", mock.Anything, testRevocation.ID, "admin-1").Return(lifted, tt.liftErr).Once()
+			handler := NewRevocationsHandler(revocations, zap.NewNop())
+
+			w := performLegalHoldRequest("DELETE", "/api/v1/revocations/"+testRevocation.ID, "", handler.LiftRevocation,
+				gin.Param{Key: "revocationId", Value: testRevocation.ID})
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.liftErr == nil {
+				var got domain.Revocation
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				assert.Equal(t, lifted, got)
+			}
+			revocations.AssertExpectations(t)
+		})
+	}
+}
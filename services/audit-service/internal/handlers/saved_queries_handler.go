@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SavedQueries manages the saved queries of the users and runs them against a session
+type SavedQueries interface {
+	Create(ctx context.Context, query domain.SavedQuery) (domain.SavedQuery, error)
+	Get(ctx context.Context, id, userID string) (domain.SavedQuery, error)
+	List(ctx context.Context, userID string) ([]domain.SavedQuery, error)
+	Update(ctx context.Context, id, userID string, update domain.SavedQuery) (domain.SavedQuery, error)
+	Delete(ctx context.Context, id, userID string) error
+	Run(ctx context.Context, sessionID, id, userID string, isShareToken bool, pagination domain.PaginationParams) (*domain.AuditResponse, error)
+}
+
+// SavedQueryRequest defines the request body for creating or replacing a saved query
+type SavedQueryRequest struct {
+	Name        string `json:"name" binding:"required" example:"My edits this week"`
+	Description string `json:"description,omitempty" example:"Text edits and merges of the last 7 days"`
+	// Scope is private, the default, or organization to share the query with the organization
+	Scope  domain.SavedQueryScope  `json:"scope,omitempty" example:"private"`
+	Filter domain.SavedQueryFilter `json:"filter"`
+}
+
+// toSavedQuery converts the request into a saved query, private unless another scope is given
+func (r SavedQueryRequest) toSavedQuery() domain.SavedQuery {
+	scope := r.Scope
+	if scope == "" {
+		scope = domain.SavedQueryPrivate
+	}
+	return domain.SavedQuery{
+		Name:        r.Name,
+		Description: r.Description,
+		Scope:       scope,
+		Filter:      r.Filter,
+	}
+}
+
+// SavedQueryList defines the response listing the saved queries of a user
+type SavedQueryList struct {
+	Items []domain.SavedQuery `json:"items"`
+}
+
+// SavedQueriesHandler handles the saved queries behind the audit views of the frontend
+type SavedQueriesHandler struct {
+	queries SavedQueries
+	logger  *zap.Logger
+}
+
+// NewSavedQueriesHandler creates a new saved queries handler
+func NewSavedQueriesHandler(queries SavedQueries, logger *zap.Logger) *SavedQueriesHandler {
+	return &SavedQueriesHandler{
+		queries: queries,
+		logger:  logger,
+	}
+}
+
+// CreateSavedQuery handles POST /queries
+// @Summary Save a query
+// @Description Saves a named filter set of the event queries, such as "My edits this week". The filter takes the filters of the session events query; days restricts the results to the last days before each run and a userId of "me" stands for the user running the query. Private queries are seen by the caller only; organization queries by every user of the organization.
+// @Tags Saved Queries
+// @Accept json
+// @Produce json
+// @Param request body SavedQueryRequest true "Saved query"
+// @Security BearerAuth
+// @Success 201 {object} domain.SavedQuery
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /queries [post]
+func (h *SavedQueriesHandler) CreateSavedQuery(c *gin.Context) {
+	var req SavedQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.WriteError(c, invalidBody(c, err))
+		return
+	}
+
+	query := req.toSavedQuery()
+	query.CreatedBy = middleware.GetAuthUserID(c)
+	created, err := h.queries.Create(c.Request.Context(), query)
+	if err != nil {
+		h.writeError(c, "failed to create saved query", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// ListSavedQueries handles GET /queries
+// @Summary List saved queries
+// @Description Lists the saved queries of the caller and those shared with the organization, by name.
+// @Tags Saved Queries
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SavedQueryList
+// @Failure 401 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /queries [get]
+func (h *SavedQueriesHandler) ListSavedQueries(c *gin.Context) {
+	queries, err := h.queries.List(c.Request.Context(), middleware.GetAuthUserID(c))
+	if err != nil {
+		h.writeError(c, "failed to list saved queries", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SavedQueryList{Items: queries})
+}
+
+// GetSavedQuery handles GET /queries/{queryId}
+// @Summary Get a saved query
+// @Description Returns a saved query of the caller or one shared with the organization.
+// @Tags Saved Queries
+// @Produce json
+// @Param queryId path string true "Saved query ID"
+// @Security BearerAuth
+// @Success 200 {object} domain.SavedQuery
+// @Failure 401 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /queries/{queryId} [get]
+func (h *SavedQueriesHandler) GetSavedQuery(c *gin.Context) {
+	query, err := h.queries.Get(c.Request.Context(), c.Param("queryId"), middleware.GetAuthUserID(c))
+	if err != nil {
+		h.writeError(c, "failed to get saved query", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, query)
+}
+
+// UpdateSavedQuery handles PUT /queries/{queryId}
+// @Summary Replace a saved query
+// @Description Replaces the name, description, scope and filter of a saved query. Only the creator of a query may change it.
+// @Tags Saved Queries
+// @Accept json
+// @Produce json
+// @Param queryId path string true "Saved query ID"
+// @Param request body SavedQueryRequest true "Saved query"
+// @Security BearerAuth
+// @Success 200 {object} domain.SavedQuery
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /queries/{queryId} [put]
+func (h *SavedQueriesHandler) UpdateSavedQuery(c *gin.Context) {
+	var req SavedQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.WriteError(c, invalidBody(c, err))
+		return
+	}
+
+	query, err := h.queries.Update(c.Request.Context(), c.Param("queryId"), middleware.GetAuthUserID(c), req.toSavedQuery())
+	if err != nil {
+		h.writeError(c, "failed to update saved query", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, query)
+}
+
+// DeleteSavedQuery handles DELETE /queries/{queryId}
+// @Summary Delete a saved query
+// @Description Deletes a saved query. Only the creator of a query may delete it.
+// @Tags Saved Queries
+// @Param queryId path string true "Saved query ID"
+// @Security BearerAuth
+// @Success 204
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /queries/{queryId} [delete]
+func (h *SavedQueriesHandler) DeleteSavedQuery(c *gin.Context) {
+	if err := h.queries.Delete(c.Request.Context(), c.Param("queryId"), middleware.GetAuthUserID(c)); err != nil {
+		h.writeError(c, "failed to delete saved query", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RunSavedQuery handles GET /sessions/{sessionId}/queries/{queryId}/events
+// @Summary Run a saved query
+// @Description Retrieves the audit log entries of a session that match a saved query of the caller or one shared with the organization, newest first. The relative time range and the "me" user filter are resolved for the caller at the time of the request. Share tokens cannot run saved queries.
+// @Tags Saved Queries
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param queryId path string true "Saved query ID"
+// @Param limit query int false "Number of items to return (default: 50, max: 100)"
+// @Param offset query int false "Number of items to skip (default: 0)"
+// @Param cursor query string false "Opaque cursor from a previous response's nextCursor"
+// @Param If-None-Match header string false "ETag of a previous response; answered with 304 when unchanged"
+// @Param Consistency-Token header string false "Consistency token returned by event creation; waits until the events it names are readable"
+// @Security BearerAuth
+// @Success 200 {object} domain.AuditResponse
+// @Success 304 "Not modified"
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Failure 503 {object} domain.APIError
+// @Router /sessions/{sessionId}/queries/{queryId}/events [get]
+func (h *SavedQueriesHandler) RunSavedQuery(c *gin.Context) {
+	pagination, apiErr := parsePagination(c)
+	if apiErr != nil {
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	ctx, ok := readContext(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.queries.Run(ctx, c.Param("sessionId"), c.Param("queryId"), middleware.GetAuthUserID(c),
+		middleware.GetAuthTokenType(c) == middleware.TokenTypeShare, pagination)
+	if err != nil {
+		h.writeError(c, "failed to run saved query", err)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, response)
+}
+
+// writeError writes the API error of a failed saved query operation, logging server errors
+func (h *SavedQueriesHandler) writeError(c *gin.Context, message string, err error) {
+	// Reasons for an invalid saved query are returned to the caller as is
+	if errors.Is(err, domain.ErrInvalidSavedQuery) {
+		middleware.WriteError(c, domain.NewAPIError("invalid_saved_query", err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	apiErr := domain.ToAPIError(err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		h.logger.Error(message,
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.String("query_id", c.Param("queryId")),
+			zap.Error(err),
+		)
+	}
+	middleware.WriteError(c, apiErr)
+}
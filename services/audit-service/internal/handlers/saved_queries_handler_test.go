@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockSavedQueries is a mock implementation of SavedQueries
+type MockSavedQueries struct {
+	mock.Mock
+}
+
+func (m *MockSavedQueries) Create(ctx context.Context, query domain.SavedQuery) (domain.SavedQuery, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).(domain.SavedQuery), args.Error(1)
+}
+
+func (m *MockSavedQueries) Get(ctx context.Context, id, userID string) (domain.SavedQuery, error) {
+	args := m.Called(ctx, id, userID)
+	return args.Get(0).(domain.SavedQuery), args.Error(1)
+}
+
+func (m *MockSavedQueries) List(ctx context.Context, userID string) ([]domain.SavedQuery, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]domain.SavedQuery), args.Error(1)
+}
+
+func (m *MockSavedQueries) Update(ctx context.Context, id, userID string, update domain.SavedQuery) (domain.SavedQuery, error) {
+	args := m.Called(ctx, id, userID, update)
+	return args.Get(0).(domain.SavedQuery), args.Error(1)
+}
+
+func (m *MockSavedQueries) Delete(ctx context.Context, id, userID string) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+func (m *MockSavedQueries) Run(ctx context.Context, sessionID, id, userID string, isShareToken bool, pagination domain.PaginationParams) (*domain.AuditResponse, error) {
+	args := m.Called(ctx, sessionID, id, userID, isShareToken, pagination)
+	if response := args.Get(0); response != nil {
+		return response.(*domain.AuditResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+var testSavedQuery = domain.SavedQuery{
+	ID: "0b8f5e0c-3c1d-4a57-9d0e-6f1b2c3d4e5f", Name: "My edits this week", Scope: domain.SavedQueryPrivate,
+	Filter:    domain.SavedQueryFilter{Types: []string{"edit"}, UserID: domain.SavedQueryCurrentUser, Days: 7},
+	CreatedBy: "user-1", CreatedAt: time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC), UpdatedAt: time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC),
+}
+
+func performSavedQueryRequest(method, target, body string, tokenType string, handle func(c *gin.Context)) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.AuthUserIDKey, "user-1")
+	c.Set(middleware.AuthTokenTypeKey, tokenType)
+	c.Params = gin.Params{{Key: "sessionId", Value: "550e8400-e29b-41d4-a716-446655440000"}, {Key: "queryId", Value: testSavedQuery.ID}}
+
+	handle(c)
+	// The router writes the status of bodiless responses once the handler returns
+	c.Writer.WriteHeaderNow()
+	return w
+}
+
+func TestSavedQueriesHandler_CreateSavedQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		query          domain.SavedQuery
+		createErr      error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name: "private by default",
+			body: `{"name":"My edits this week","filter":{"types":["edit"],"userId":"me","days":7}}`,
+			query: domain.SavedQuery{Name: "My edits this week", Scope: domain.SavedQueryPrivate, CreatedBy: "user-1",
+				Filter: domain.SavedQueryFilter{Types: []string{"edit"}, UserID: domain.SavedQueryCurrentUser, Days: 7}},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid saved query",
+			body:           `{"name":"All","scope":"public"}`,
+			query:          domain.SavedQuery{Name: "All", Scope: "public", CreatedBy: "user-1"},
+			createErr:      fmt.Errorf("%w: scope must be private or organization", domain.ErrInvalidSavedQuery),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_saved_query",
+		},
+		{
+			name:           "storage failure",
+			body:           `{"name":"All"}`,
+			query:          domain.SavedQuery{Name: "All", Scope: domain.SavedQueryPrivate, CreatedBy: "user-1"},
+			createErr:      errors.New("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "internal_server_error",
+		},
+		{name: "missing name", body: `{"scope":"private"}`, expectedStatus: http.StatusBadRequest, expectedCode: "validation_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries := new(MockSavedQueries)
+			if tt.query.Name != "" {
+				queries.On("Create", mock.Anything, tt.query).Return(testSavedQuery, tt.createErr).Once()
+			}
+			handler := NewSavedQueriesHandler(queries, zap.NewNop())
+
+			w := performSavedQueryRequest("POST", "/api/v1/queries", tt.body, middleware.TokenTypeJWT, handler.CreateSavedQuery)
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedCode != "" {
+				var apiErr domain.APIError
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+				assert.Equal(t, tt.expectedCode, apiErr.Code)
+			} else {
+				var got domain.SavedQuery
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				assert.Equal(t, testSavedQuery, got)
+			}
+			queries.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSavedQueriesHandler_ListSavedQueries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	queries := new(MockSavedQueries)
+	queries.On("List", mock.Anything, "user-1").Return([]domain.SavedQuery{testSavedQuery}, nil).Once()
+	handler := NewSavedQueriesHandler(queries, zap.NewNop())
+
+	w := performSavedQueryRequest("GET", "/api/v1/queries", "", middleware.TokenTypeJWT, handler.ListSavedQueries)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var got SavedQueryList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, []domain.SavedQuery{testSavedQuery}, got.Items)
+	queries.AssertExpectations(t)
+}
+
+func TestSavedQueriesHandler_UpdateAndDelete(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	update := domain.SavedQuery{Name: "All exports", Scope: domain.SavedQueryOrganization,
+		Filter: domain.SavedQueryFilter{Types: []string{"export"}}}
+	queries := new(MockSavedQueries)
+	queries.On("Update", mock.Anything, testSavedQuery.ID, "user-1", update).Return(domain.SavedQuery{}, domain.ErrForbidden).Once()
+	queries.On("Delete", mock.Anything, testSavedQuery.ID, "user-1").Return(nil).Once()
+	queries.On("Get", mock.Anything, testSavedQuery.ID, "user-1").Return(domain.SavedQuery{}, domain.ErrSavedQueryNotFound).Once()
+	handler := NewSavedQueriesHandler(queries, zap.NewNop())
+
+	w := performSavedQueryRequest("PUT", "/api/v1/queries/"+testSavedQuery.ID,
+		`{"name":"All exports","scope":"organization","filter":{"types":["export"]}}`, middleware.TokenTypeJWT, handler.UpdateSavedQuery)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	w = performSavedQueryRequest("DELETE", "/api/v1/queries/"+testSavedQuery.ID, "", middleware.TokenTypeJWT, handler.DeleteSavedQuery)
+	assert.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	w = performSavedQueryRequest("GET", "/api/v1/queries/"+testSavedQuery.ID, "", middleware.TokenTypeJWT, handler.GetSavedQuery)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+	queries.AssertExpectations(t)
+}
+
+func TestSavedQueriesHandler_RunSavedQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	response := &domain.AuditResponse{Items: []domain.AuditEntry{}}
+	queries := new(MockSavedQueries)
+	queries.On("Run", mock.Anything, "550e8400-e29b-41d4-a716-446655440000", testSavedQuery.ID, "user-1", false,
+		domain.PaginationParams{Limit: 50}).Return(response, nil).Once()
+	queries.On("Run", mock.Anything, "550e8400-e29b-41d4-a716-446655440000", testSavedQuery.ID, "user-1", true,
+		domain.PaginationParams{Limit: 50}).Return(nil, domain.ErrForbidden).Once()
+	handler := NewSavedQueriesHandler(queries, zap.NewNop())
+
+	target := "/api/v1/sessions/550e8400-e29b-41d4-a716-446655440000/queries/" + testSavedQuery.ID + "/events"
+	w := performSavedQueryRequest("GET", target, "", middleware.TokenTypeJWT, handler.RunSavedQuery)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+
+	w = performSavedQueryRequest("GET", target, "", middleware.TokenTypeShare, handler.RunSavedQuery)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	queries.AssertExpectations(t)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ShareTrails serves the forensic trails of share links
+type ShareTrails interface {
+	Get(ctx context.Context, tokenID string, userID domain.UserID) (domain.ShareTrail, error)
+}
+
+// ShareTokensHandler handles the audit trails of share links
+type ShareTokensHandler struct {
+	trails ShareTrails
+	logger *zap.Logger
+}
+
+// NewShareTokensHandler creates a new share tokens handler
+func NewShareTokensHandler(trails ShareTrails, logger *zap.Logger) *ShareTokensHandler {
+	return &ShareTokensHandler{
+		trails: trails,
+		logger: logger,
+	}
+}
+
+// GetShareTokenAudit handles GET /api/v1/share-tokens/{tokenId}/audit
+// @Summary Get the audit trail of a share link
+// @Description Returns the lifecycle of a share link stitched from its events correlated by the token ID: the share event that created it, every redemption with the client IP, user agent, time and requested path, the first use after it expired and the unshare event that revoked it. Redemptions by the same client of the same path within five minutes are recorded once. Only readers of the session of the link may read it.
+// @Tags Sharing
+// @Produce json
+// @Param tokenId path string true "Token ID (jti) of the share link"
+// @Security BearerAuth
+// @Success 200 {object} domain.ShareTrail
+// @Failure 401 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /share-tokens/{tokenId}/audit [get]
+func (h *ShareTokensHandler) GetShareTokenAudit(c *gin.Context) {
+	tokenID := c.Param("tokenId")
+	trail, err := h.trails.Get(c.Request.Context(), tokenID, domain.UserID(middleware.GetAuthUserID(c)))
+	if err != nil {
+		apiErr := domain.ToAPIError(err)
+		if apiErr.Status >= http.StatusInternalServerError {
+			h.logger.Error("failed to get share trail",
+				zap.String("request_id", middleware.GetRequestID(c)),
+				zap.String("token_id", tokenID),
+				zap.Error(err),
+			)
+		}
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, trail)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockShareTrails is a mock implementation of ShareTrails
+type MockShareTrails struct {
+	mock.Mock
+}
+
+func (m *MockShareTrails) Get(ctx context.Context, tokenID string, userID domain.UserID) (domain.ShareTrail, error) {
+	args := m.Called(ctx, tokenID, userID)
+	return args.Get(0).(domain.ShareTrail), args.Error(1)
+}
+
+func TestShareTokensHandler_GetShareTokenAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	trail := domain.ShareTrail{
+		TokenID: "share-91c2", SessionID: "550e8400-e29b-41d4-a716-446655440000", Status: domain.ShareLinkActive,
+		Redemptions: []domain.ShareRedemption{{EventID: "550e8400-e29b-41d4-a716-446655440001", Timestamp: testNow, IPAddress: "198.51.100.4"}},
+	}
+
+	tests := []struct {
+		name           string
+		getErr         error
+		expectedStatus int
+	}{
+		{name: "readable link", expectedStatus: http.StatusOK},
+		{name: "unknown link", getErr: domain.ErrShareTrailNotFound, expectedStatus: http.StatusNotFound},
+		{name: "storage error", getErr: errors.New("connection refused"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trails := new(MockShareTrails)
+			trails.On("Get", mock.Anything, "share-91c2", domain.UserID("user-123")).Return(trail, tt.getErr).Once()
+			handler := NewShareTokensHandler(trails, zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/share-tokens/share-91c2/audit", nil)
+			c.Set(middleware.AuthUserIDKey, "user-123")
+			c.Params = gin.Params{{Key: "tokenId", Value: "share-91c2"}}
+
+			handler.GetShareTokenAudit(c)
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.getErr == nil {
+				var got domain.ShareTrail
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				assert.Equal(t, domain.ShareLinkActive, got.Status)
+				assert.Len(t, got.Redemptions, 1)
+			}
+			trails.AssertExpectations(t)
+		})
+	}
+}
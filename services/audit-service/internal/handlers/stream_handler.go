@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"audit-service/internal/broadcast"
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+	"audit-service/internal/service"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// defaultHeartbeatInterval keeps idle streams open through proxies and load balancers
+const defaultHeartbeatInterval = 15 * time.Second
+
+// StreamHandler handles live audit activity streams
+type StreamHandler struct {
+	service   service.Reader
+	broker    *broadcast.Broker
+	logger    *zap.Logger
+	heartbeat time.Duration
+}
+
+// NewStreamHandler creates a new stream handler
+func NewStreamHandler(service service.Reader, broker *broadcast.Broker, logger *zap.Logger) *StreamHandler {
+	return &StreamHandler{
+		service:   service,
+		broker:    broker,
+		logger:    logger,
+		heartbeat: defaultHeartbeatInterval,
+	}
+}
+
+// StreamEvents handles GET /sessions/{sessionId}/events/stream
+// @Summary Stream live audit events for a session
+// @Description Pushes audit entries as Server-Sent Events ("audit" event, JSON data) as soon as they are created
+// @Tags Audit
+// @Produce text/event-stream
+// @Param sessionId path string true "Session ID"
+// @Param share_token query string false "Share token for reviewer access"
+// @Security BearerAuth
+// @Success 200 {object} domain.AuditEntry
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Router /sessions/{sessionId}/events/stream [get]
+func (h *StreamHandler) StreamEvents(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+
+	sessionID, ok := parseSessionID(c, c.Param("sessionId"))
+	if !ok {
+		return
+	}
+
+	userID := authUserID(c)
+	isShareToken := middleware.GetAuthTokenType(c) == middleware.TokenTypeShare
+
+	ctx := c.Request.Context()
+	if err := h.service.AuthorizeSession(ctx, sessionID, userID, isShareToken); err != nil {
+		apiErr := domain.ToAPIError(err)
+		middleware.WriteError(c, apiErr)
+		return
+	}
+
+	sub := h.broker.Subscribe(broadcast.SessionTopic(sessionID.String()))
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // disable nginx response buffering
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	h.logger.Info("audit stream opened",
+		zap.String("request_id", requestID),
+		zap.Stringer("session_id", sessionID),
+		zap.Stringer("user_id", userID),
+		zap.Bool("share_token", isShareToken),
+	)
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.logger.Info("audit stream closed",
+				zap.String("request_id", requestID),
+				zap.Stringer("session_id", sessionID),
+			)
+			return
+
+		case entry, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if isShareToken {
+				entry = entry.WithoutClientInfo()
+			}
+			c.Render(-1, sse.Event{
+				Id:    entry.ID,
+				Event: "audit",
+				Data:  entry,
+			})
+			c.Writer.Flush()
+
+		case <-ticker.C:
+			// SSE comment line, ignored by clients
+			if _, err := c.Writer.WriteString(": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
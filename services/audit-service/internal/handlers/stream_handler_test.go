@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"audit-service/internal/broadcast"
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const streamSessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+func newStreamServer(handler *StreamHandler) *httptest.Server {
+	router := gin.New()
+	router.GET("/sessions/:sessionId/events/stream", func(c *gin.Context) {
+		c.Set(middleware.AuthUserIDKey, "user-456")
+		c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+		handler.StreamEvents(c)
+	})
+	return httptest.NewServer(router)
+}
+
+func TestStreamHandler_StreamEvents_DeliversPublishedEntries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockAuditService)
+	mockService.On("AuthorizeSession", mock.Anything, streamSessionID, "user-456", false).Return(nil)
+
+	broker := broadcast.NewBroker(zap.NewNop())
+	server := newStreamServer(NewStreamHandler(mockService, broker, zap.NewNop()))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/sessions/" + streamSessionID + "/events/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	require.Eventually(t, func() bool {
+		return broker.SubscriberCount(broadcast.SessionTopic(streamSessionID)) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	broker.Publish(domain.AuditEntry{ID: "event-1", SessionID: streamSessionID, Type: "edit"})
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	assert.Equal(t, "id:event-1", lines[0])
+	assert.Equal(t, "event:audit", lines[1])
+
+	var entry domain.AuditEntry
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data:")), &entry))
+	assert.Equal(t, "event-1", entry.ID)
+	assert.Equal(t, "edit", entry.Type)
+}
+
+func TestStreamHandler_StreamEvents_ClosesSubscriptionOnDisconnect(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockAuditService)
+	mockService.On("AuthorizeSession", mock.Anything, streamSessionID, "user-456", false).Return(nil)
+
+	broker := broadcast.NewBroker(zap.NewNop())
+	server := newStreamServer(NewStreamHandler(mockService, broker, zap.NewNop()))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/sessions/" + streamSessionID + "/events/stream")
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return broker.SubscriberCount(broadcast.SessionTopic(streamSessionID)) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	resp.Body.Close()
+
+	assert.Eventually(t, func() bool {
+		return broker.SubscriberCount(broadcast.SessionTopic(streamSessionID)) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestStreamHandler_StreamEvents_Forbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockAuditService)
+	mockService.On("AuthorizeSession", mock.Anything, streamSessionID, "user-456", false).Return(domain.ErrForbidden)
+
+	broker := broadcast.NewBroker(zap.NewNop())
+	server := newStreamServer(NewStreamHandler(mockService, broker, zap.NewNop()))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/sessions/" + streamSessionID + "/events/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, 0, broker.SubscriberCount(broadcast.SessionTopic(streamSessionID)))
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Watches subscribes users to the events of the sessions they can read
+type Watches interface {
+	Watch(ctx context.Context, sessionID, userID string, isShareToken bool, eventTypes []string) (domain.Watch, error)
+	Get(ctx context.Context, sessionID, userID string, isShareToken bool) (domain.Watch, error)
+	Unwatch(ctx context.Context, sessionID, userID string, isShareToken bool) error
+	List(ctx context.Context, userID string) ([]domain.Watch, error)
+}
+
+// WatchSessionRequest defines the optional request body for watching a session
+type WatchSessionRequest struct {
+	// EventTypes defaults to share, export, comment and comment_created
+	EventTypes []string `json:"eventTypes,omitempty" example:"share,export"`
+}
+
+// WatchList defines the response listing the watches of a user
+type WatchList struct {
+	Items []domain.Watch `json:"items"`
+}
+
+// WatchesHandler handles the session watches that drive notification digests
+type WatchesHandler struct {
+	watches Watches
+	logger  *zap.Logger
+}
+
+// NewWatchesHandler creates a new watches handler
+func NewWatchesHandler(watches Watches, logger *zap.Logger) *WatchesHandler {
+	return &WatchesHandler{
+		watches: watches,
+		logger:  logger,
+	}
+}
+
+// WatchSession handles PUT /sessions/{sessionId}/watch
+// @Summary Watch a session
+// @Description Subscribes the caller to share, export and comment events of a session, which are forwarded to the notification sink in digests. Events of the caller are not forwarded. Watching a session again replaces its event types. Share tokens cannot watch sessions.
+// @Tags Watches
+// @Accept json
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Param request body WatchSessionRequest false "Event types to watch"
+// @Security BearerAuth
+// @Success 200 {object} domain.Watch
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /sessions/{sessionId}/watch [put]
+func (h *WatchesHandler) WatchSession(c *gin.Context) {
+	var req WatchSessionRequest
+	// The body is optional; without one the default event types are watched
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		middleware.WriteError(c, invalidBody(c, err))
+		return
+	}
+
+	watch, err := h.watches.Watch(c.Request.Context(), c.Param("sessionId"), middleware.GetAuthUserID(c),
+		middleware.GetAuthTokenType(c) == middleware.TokenTypeShare, req.EventTypes)
+	if err != nil {
+		h.writeError(c, "failed to watch session", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, watch)
+}
+
+// GetSessionWatch handles GET /sessions/{sessionId}/watch
+// @Summary Get the watch of a session
+// @Description Returns the event types of the session the caller watches.
+// @Tags Watches
+// @Produce json
+// @Param sessionId path string true "Session ID"
+// @Security BearerAuth
+// @Success 200 {object} domain.Watch
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /sessions/{sessionId}/watch [get]
+func (h *WatchesHandler) GetSessionWatch(c *gin.Context) {
+	watch, err := h.watches.Get(c.Request.Context(), c.Param("sessionId"), middleware.GetAuthUserID(c),
+		middleware.GetAuthTokenType(c) == middleware.TokenTypeShare)
+	if err != nil {
+		h.writeError(c, "failed to get session watch", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, watch)
+}
+
+// UnwatchSession handles DELETE /sessions/{sessionId}/watch
+// @Summary Stop watching a session
+// @Description Unsubscribes the caller from a session. Events already collected for the next digest are still sent.
+// @Tags Watches
+// @Param sessionId path string true "Session ID"
+// @Security BearerAuth
+// @Success 204
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /sessions/{sessionId}/watch [delete]
+func (h *WatchesHandler) UnwatchSession(c *gin.Context) {
+	if err := h.watches.Unwatch(c.Request.Context(), c.Param("sessionId"), middleware.GetAuthUserID(c),
+		middleware.GetAuthTokenType(c) == middleware.TokenTypeShare); err != nil {
+		h.writeError(c, "failed to unwatch session", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListWatches handles GET /watches
+// @Summary List watched sessions
+// @Description Lists the sessions the caller watches, most recently watched first.
+// @Tags Watches
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} WatchList
+// @Failure 401 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /watches [get]
+func (h *WatchesHandler) ListWatches(c *gin.Context) {
+	watches, err := h.watches.List(c.Request.Context(), middleware.GetAuthUserID(c))
+	if err != nil {
+		h.writeError(c, "failed to list watches", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, WatchList{Items: watches})
+}
+
+// writeError writes the API error of a failed watch operation, logging server errors
+func (h *WatchesHandler) writeError(c *gin.Context, message string, err error) {
+	// Reasons for an invalid watch are returned to the caller as is
+	if errors.Is(err, domain.ErrInvalidWatch) {
+		middleware.WriteError(c, domain.NewAPIError("invalid_watch", err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	apiErr := domain.ToAPIError(err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		h.logger.Error(message,
+			zap.String("request_id", middleware.GetRequestID(c)),
+			zap.String("session_id", c.Param("sessionId")),
+			zap.Error(err),
+		)
+	}
+	middleware.WriteError(c, apiErr)
+}
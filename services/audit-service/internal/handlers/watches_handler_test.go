@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockWatches is a mock implementation of Watches
+type MockWatches struct {
+	mock.Mock
+}
+
+func (m *MockWatches) Watch(ctx context.Context, sessionID, userID string, isShareToken bool, eventTypes []string) (domain.Watch, error) {
+	args := m.Called(ctx, sessionID, userID, isShareToken, eventTypes)
+	return args.Get(0).(domain.Watch), args.Error(1)
+}
+
+func (m *MockWatches) Get(ctx context.Context, sessionID, userID string, isShareToken bool) (domain.Watch, error) {
+	args := m.Called(ctx, sessionID, userID, isShareToken)
+	return args.Get(0).(domain.Watch), args.Error(1)
+}
+
+func (m *MockWatches) Unwatch(ctx context.Context, sessionID, userID string, isShareToken bool) error {
+	args := m.Called(ctx, sessionID, userID, isShareToken)
+	return args.Error(0)
+}
+
+func (m *MockWatches) List(ctx context.Context, userID string) ([]domain.Watch, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]domain.Watch), args.Error(1)
+}
+
+var testWatch = domain.Watch{
+	SessionID: "550e8400-e29b-41d4-a716-446655440000", UserID: "user-1",
+	EventTypes: []string{"share", "export"}, CreatedAt: time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC),
+}
+
+func performWatchRequest(method, target, body string, handle func(c *gin.Context)) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.AuthUserIDKey, "user-1")
+	c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+	c.Params = gin.Params{{Key: "sessionId", Value: testWatch.SessionID}}
+
+	handle(c)
+	// The router writes the status of bodiless responses once the handler returns
+	c.Writer.WriteHeaderNow()
+	return w
+}
+
+func TestWatchesHandler_WatchSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		eventTypes     []string
+		watchErr       error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "watched", body: `{"eventTypes":["share","export"]}`, eventTypes: []string{"share", "export"}, expectedStatus: http.StatusOK},
+		{name: "default event types", body: "", expectedStatus: http.StatusOK},
+		{
+			name:           "invalid watch",
+			body:           `{"eventTypes":["edit"]}`,
+			eventTypes:     []string{"edit"},
+			watchErr:       fmt.Errorf("%w: edit events cannot be watched", domain.ErrInvalidWatch),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_watch",
+		},
+		{name: "forbidden", body: "{}", watchErr: domain.ErrForbidden, expectedStatus: http.StatusForbidden, expectedCode: "forbidden"},
+		{
+			name:           "storage failure",
+			body:           "{}",
+			watchErr:       errors.New("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "internal_server_error",
+		},
+		{name: "malformed body", body: `{"eventTypes":"share"}`, expectedStatus: http.StatusBadRequest, expectedCode: "invalid_request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			watches := new(MockWatches)
+			if tt.expectedCode != "invalid_request" {
+				watches.On("Watch", mock.Anything, testWatch.SessionID, "user-1", false, tt.eventTypes).
+					Return(testWatch, tt.watchErr).Once()
+			}
+			handler := NewWatchesHandler(watches, zap.NewNop())
+
+			w := performWatchRequest("PUT", "/api/v1/sessions/"+testWatch.SessionID+"/watch", tt.body, handler.WatchSession)
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedCode != "" {
+				var apiErr domain.APIError
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+				assert.Equal(t, tt.expectedCode, apiErr.Code)
+			} else {
+				var got domain.Watch
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				assert.Equal(t, testWatch, got)
+			}
+			watches.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWatchesHandler_GetSessionWatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	watches := new(MockWatches)
+	watches.On("Get", mock.Anything, testWatch.SessionID, "user-1", false).Return(testWatch, nil).Once()
+	watches.On("Get", mock.Anything, testWatch.SessionID, "user-1", false).Return(domain.Watch{}, domain.ErrWatchNotFound).Once()
+	handler := NewWatchesHandler(watches, zap.NewNop())
+
+	w := performWatchRequest("GET", "/api/v1/sessions/"+testWatch.SessionID+"/watch", "", handler.GetSessionWatch)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var got domain.Watch
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, testWatch, got)
+
+	w = performWatchRequest("GET", "/api/v1/sessions/"+testWatch.SessionID+"/watch", "", handler.GetSessionWatch)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	watches.AssertExpectations(t)
+}
+
+func TestWatchesHandler_UnwatchSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		unwatchErr     error
+		expectedStatus int
+	}{
+		{name: "unwatched", expectedStatus: http.StatusNoContent},
+		{name: "not watched", unwatchErr: domain.ErrWatchNotFound, expectedStatus: http.StatusNotFound},
+		{name: "invalid session ID", unwatchErr: domain.ErrInvalidSessionID, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			watches := new(MockWatches)
+			watches.On("Unwatch", mock.Anything, testWatch.SessionID, "user-1", false).Return(tt.unwatchErr).Once()
+			handler := NewWatchesHandler(watches, zap.NewNop())
+
+			w := performWatchRequest("DELETE", "/api/v1/sessions/"+testWatch.SessionID+"/watch", "", handler.UnwatchSession)
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			watches.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWatchesHandler_ListWatches(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	watches := new(MockWatches)
+	watches.On("List", mock.Anything, "user-1").Return([]domain.Watch{testWatch}, nil).Once()
+	handler := NewWatchesHandler(watches, zap.NewNop())
+
+	w := performWatchRequest("GET", "/api/v1/watches", "", handler.ListWatches)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var got WatchList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, []domain.Watch{testWatch}, got.Items)
+	watches.AssertExpectations(t)
+}
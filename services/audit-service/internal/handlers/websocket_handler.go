@@ -0,0 +1,383 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"audit-service/internal/broadcast"
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+	"audit-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	// wsWriteWait is the time allowed to write a message to the peer
+	wsWriteWait = 10 * time.Second
+
+	// wsPongWait is the time allowed to read the next pong from the peer
+	wsPongWait = 60 * time.Second
+
+	// wsPingPeriod must be shorter than wsPongWait so pings arrive before the read deadline
+	wsPingPeriod = (wsPongWait * 9) / 10
+
+	// wsMaxMessageSize limits the size of client messages
+	wsMaxMessageSize = 4096
+
+	// wsSendBufferSize is how many outgoing messages a connection may queue before it is dropped
+	wsSendBufferSize = 256
+
+	// wsMaxSubscriptions limits the topics a single connection may follow
+	wsMaxSubscriptions = 50
+)
+
+// WebSocket client actions
+const (
+	wsActionSubscribe   = "subscribe"
+	wsActionUnsubscribe = "unsubscribe"
+)
+
+// WebSocket server message types
+const (
+	wsMessageEvent        = "event"
+	wsMessageSubscribed   = "subscribed"
+	wsMessageUnsubscribed = "unsubscribed"
+	wsMessageError        = "error"
+)
+
+// WebSocketRequest is a message sent by a WebSocket client
+type WebSocketRequest struct {
+	Action    string `json:"action" example:"subscribe"`
+	SessionID string `json:"sessionId,omitempty" example:"550e8400-e29b-41d4-a716-446655440001"`
+	UserID    string `json:"userId,omitempty" example:"550e8400-e29b-41d4-a716-446655440002"`
+}
+
+// WebSocketMessage is a message sent to a WebSocket client
+type WebSocketMessage struct {
+	Type    string             `json:"type" example:"event"`
+	Topic   string             `json:"topic,omitempty" example:"session:550e8400-e29b-41d4-a716-446655440001"`
+	Event   *domain.AuditEntry `json:"event,omitempty"`
+	Error   string             `json:"error,omitempty"`
+	Message string             `json:"message,omitempty"`
+}
+
+// WebSocketHandler handles WebSocket subscriptions to live audit events
+type WebSocketHandler struct {
+	service  service.Reader
+	broker   *broadcast.Broker
+	upgrader websocket.Upgrader
+	logger   *zap.Logger
+
+	mu      sync.Mutex
+	clients map[*wsClient]struct{}
+}
+
+// NewWebSocketHandler creates a new WebSocket handler
+func NewWebSocketHandler(service service.Reader, broker *broadcast.Broker, allowedOrigin *middleware.CORSOrigin, logger *zap.Logger) *WebSocketHandler {
+	return &WebSocketHandler{
+		service: service,
+		broker:  broker,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin: func(r *http.Request) bool {
+				// Mirror the CORS policy: non-browser clients send no Origin header
+				origin := r.Header.Get("Origin")
+				return origin == "" || origin == allowedOrigin.Get() || gin.Mode() == gin.DebugMode
+			},
+		},
+		logger:  logger,
+		clients: make(map[*wsClient]struct{}),
+	}
+}
+
+// Connect handles GET /ws
+// @Summary Subscribe to live audit events over WebSocket
+// @Description Upgrades to a WebSocket. Clients send {"action":"subscribe","sessionId":"..."} or {"action":"subscribe","userId":"..."} and receive {"type":"event","topic":"...","event":{...}} messages.
+// @Tags Audit
+// @Param access_token query string false "JWT for clients that cannot set the Authorization header"
+// @Security BearerAuth
+// @Success 101
+// @Failure 401 {object} domain.APIError
+// @Router /ws [get]
+func (h *WebSocketHandler) Connect(c *gin.Context) {
+	requestID := middleware.GetRequestID(c)
+	userID := middleware.GetAuthUserID(c)
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already written an HTTP error response
+		h.logger.Warn("websocket upgrade failed",
+			zap.String("request_id", requestID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	client := &wsClient{
+		conn:    conn,
+		userID:  userID,
+		handler: h,
+		send:    make(chan WebSocketMessage, wsSendBufferSize),
+		done:    make(chan struct{}),
+		subs:    make(map[string]*broadcast.Subscription),
+	}
+
+	h.logger.Info("websocket connected",
+		zap.String("request_id", requestID),
+		zap.String("user_id", userID),
+	)
+
+	h.mu.Lock()
+	h.clients[client] = struct{}{}
+	h.mu.Unlock()
+
+	go client.writePump()
+	client.readPump(c.Request.Context())
+
+	h.mu.Lock()
+	delete(h.clients, client)
+	h.mu.Unlock()
+
+	h.logger.Info("websocket disconnected",
+		zap.String("request_id", requestID),
+		zap.String("user_id", userID),
+	)
+}
+
+// CloseAll disconnects every client; hijacked connections are not closed by http.Server.Shutdown
+func (h *WebSocketHandler) CloseAll() {
+	h.mu.Lock()
+	clients := make([]*wsClient, 0, len(h.clients))
+	for client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.mu.Unlock()
+
+	for _, client := range clients {
+		client.close(websocket.CloseGoingAway, "server shutting down")
+	}
+}
+
+// wsClient is a single WebSocket connection and its topic subscriptions
+type wsClient struct {
+	conn    *websocket.Conn
+	userID  string
+	handler *WebSocketHandler
+
+	send      chan WebSocketMessage
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu   sync.Mutex
+	subs map[string]*broadcast.Subscription
+}
+
+// readPump processes client messages until the connection fails or is closed
+func (cl *wsClient) readPump(ctx context.Context) {
+	defer cl.close(websocket.CloseNormalClosure, "")
+
+	cl.conn.SetReadLimit(wsMaxMessageSize)
+	_ = cl.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	cl.conn.SetPongHandler(func(string) error {
+		return cl.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, data, err := cl.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req WebSocketRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			cl.sendError(domain.NewAPIError("bad_request", "Invalid message: expected JSON", http.StatusBadRequest))
+			continue
+		}
+
+		cl.handle(ctx, req)
+	}
+}
+
+// writePump sends queued messages and keep-alive pings to the peer
+func (cl *wsClient) writePump() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-cl.done:
+			return
+
+		case msg := <-cl.send:
+			_ = cl.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := cl.conn.WriteJSON(msg); err != nil {
+				cl.close(websocket.CloseInternalServerErr, "write failed")
+				return
+			}
+
+		case <-ticker.C:
+			_ = cl.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := cl.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				cl.close(websocket.CloseGoingAway, "ping failed")
+				return
+			}
+		}
+	}
+}
+
+// handle executes a single client request
+func (cl *wsClient) handle(ctx context.Context, req WebSocketRequest) {
+	switch req.Action {
+	case wsActionSubscribe:
+		topic, apiErr := cl.authorizeTopic(ctx, req)
+		if apiErr != nil {
+			cl.sendError(apiErr)
+			return
+		}
+		cl.subscribe(topic)
+
+	case wsActionUnsubscribe:
+		topic, apiErr := cl.resolveTopic(req)
+		if apiErr != nil {
+			cl.sendError(apiErr)
+			return
+		}
+		cl.unsubscribe(topic)
+
+	default:
+		cl.sendError(domain.NewAPIError("bad_request", "Unknown action: expected subscribe or unsubscribe", http.StatusBadRequest))
+	}
+}
+
+// resolveTopic maps a request onto a broker topic
+func (cl *wsClient) resolveTopic(req WebSocketRequest) (string, *domain.APIError) {
+	if req.SessionID != "" && req.UserID != "" {
+		return "", domain.NewAPIError("bad_request", "Specify either sessionId or userId, not both", http.StatusBadRequest)
+	}
+
+	if req.SessionID != "" {
+		sessionID, err := domain.ParseSessionID(req.SessionID)
+		if err != nil {
+			return "", domain.NewAPIError("bad_request", "Invalid session ID format", http.StatusBadRequest)
+		}
+		return broadcast.SessionTopic(sessionID.String()), nil
+	}
+
+	// Without a session the connection follows the caller's own activity
+	userID := req.UserID
+	if userID == "" {
+		userID = cl.userID
+	}
+	return broadcast.UserTopic(userID), nil
+}
+
+// authorizeTopic resolves a topic and checks the caller may follow it
+func (cl *wsClient) authorizeTopic(ctx context.Context, req WebSocketRequest) (string, *domain.APIError) {
+	topic, apiErr := cl.resolveTopic(req)
+	if apiErr != nil {
+		return "", apiErr
+	}
+
+	if req.SessionID != "" {
+		// resolveTopic rejected session IDs that are not UUIDs
+		sessionID, _ := domain.ParseSessionID(req.SessionID)
+		if err := cl.handler.service.AuthorizeSession(ctx, sessionID, domain.UserID(cl.userID), false); err != nil {
+			return "", domain.ToAPIError(err)
+		}
+		return topic, nil
+	}
+
+	// Users may only follow their own activity
+	if topic != broadcast.UserTopic(cl.userID) {
+		return "", domain.APIErrForbidden
+	}
+	return topic, nil
+}
+
+// subscribe starts forwarding entries of a topic to the connection
+func (cl *wsClient) subscribe(topic string) {
+	cl.mu.Lock()
+	if cl.subs == nil {
+		cl.mu.Unlock()
+		return
+	}
+	if _, exists := cl.subs[topic]; !exists {
+		if len(cl.subs) >= wsMaxSubscriptions {
+			cl.mu.Unlock()
+			cl.sendError(domain.NewAPIError("too_many_subscriptions", "Subscription limit reached for this connection", http.StatusBadRequest))
+			return
+		}
+
+		sub := cl.handler.broker.Subscribe(topic)
+		cl.subs[topic] = sub
+		go cl.forward(topic, sub)
+	}
+	cl.mu.Unlock()
+
+	cl.enqueue(WebSocketMessage{Type: wsMessageSubscribed, Topic: topic})
+}
+
+// unsubscribe stops forwarding entries of a topic
+func (cl *wsClient) unsubscribe(topic string) {
+	cl.mu.Lock()
+	if sub, exists := cl.subs[topic]; exists {
+		sub.Close()
+		delete(cl.subs, topic)
+	}
+	cl.mu.Unlock()
+
+	cl.enqueue(WebSocketMessage{Type: wsMessageUnsubscribed, Topic: topic})
+}
+
+// forward relays entries from a subscription until it is closed
+func (cl *wsClient) forward(topic string, sub *broadcast.Subscription) {
+	for entry := range sub.Events() {
+		entry := entry
+		cl.enqueue(WebSocketMessage{Type: wsMessageEvent, Topic: topic, Event: &entry})
+	}
+}
+
+// sendError queues an error message for the client
+func (cl *wsClient) sendError(apiErr *domain.APIError) {
+	cl.enqueue(WebSocketMessage{Type: wsMessageError, Error: apiErr.Code, Message: apiErr.Message})
+}
+
+// enqueue queues a message, disconnecting clients that cannot keep up
+func (cl *wsClient) enqueue(msg WebSocketMessage) {
+	select {
+	case <-cl.done:
+	case cl.send <- msg:
+	default:
+		// The client can reconnect and catch up from the history endpoint
+		cl.handler.logger.Warn("closing slow websocket client",
+			zap.String("user_id", cl.userID),
+		)
+		cl.close(websocket.CloseTryAgainLater, "client too slow")
+	}
+}
+
+// close releases all subscriptions and closes the connection once
+func (cl *wsClient) close(code int, reason string) {
+	cl.closeOnce.Do(func() {
+		close(cl.done)
+
+		cl.mu.Lock()
+		for _, sub := range cl.subs {
+			sub.Close()
+		}
+		cl.subs = nil
+		cl.mu.Unlock()
+
+		// WriteControl may be called concurrently with the write pump
+		_ = cl.conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteWait))
+		_ = cl.conn.Close()
+	})
+}
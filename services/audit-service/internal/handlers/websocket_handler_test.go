@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"audit-service/internal/broadcast"
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const wsTestSessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+func dialWebSocket(t *testing.T, handler *WebSocketHandler) *websocket.Conn {
+	t.Helper()
+
+	router := gin.New()
+	router.GET("/ws", func(c *gin.Context) {
+		c.Set(middleware.AuthUserIDKey, "user-456")
+		c.Set(middleware.AuthTokenTypeKey, middleware.TokenTypeJWT)
+		handler.Connect(c)
+	})
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func readMessage(t *testing.T, conn *websocket.Conn) WebSocketMessage {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var msg WebSocketMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+func TestWebSocketHandler_SubscribeToSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockAuditService)
+	mockService.On("AuthorizeSession", mock.Anything, wsTestSessionID, "user-456", false).Return(nil)
+
+	broker := broadcast.NewBroker(zap.NewNop())
+	conn := dialWebSocket(t, NewWebSocketHandler(mockService, broker, middleware.NewCORSOrigin("http://localhost:3000"), zap.NewNop()))
+
+	require.NoError(t, conn.WriteJSON(WebSocketRequest{Action: "subscribe", SessionID: wsTestSessionID}))
+
+	msg := readMessage(t, conn)
+	assert.Equal(t, "subscribed", msg.Type)
+	assert.Equal(t, broadcast.SessionTopic(wsTestSessionID), msg.Topic)
+
+	broker.Publish(domain.AuditEntry{ID: "event-1", SessionID: wsTestSessionID, UserID: "user-789", Type: "edit"})
+
+	msg = readMessage(t, conn)
+	assert.Equal(t, "event", msg.Type)
+	require.NotNil(t, msg.Event)
+	assert.Equal(t, "event-1", msg.Event.ID)
+
+	require.NoError(t, conn.WriteJSON(WebSocketRequest{Action: "unsubscribe", SessionID: wsTestSessionID}))
+	msg = readMessage(t, conn)
+	assert.Equal(t, "unsubscribed", msg.Type)
+	assert.Equal(t, 0, broker.SubscriberCount(broadcast.SessionTopic(wsTestSessionID)))
+}
+
+func TestWebSocketHandler_SubscribeToOwnUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	broker := broadcast.NewBroker(zap.NewNop())
+	conn := dialWebSocket(t, NewWebSocketHandler(new(MockAuditService), broker, middleware.NewCORSOrigin("http://localhost:3000"), zap.NewNop()))
+
+	require.NoError(t, conn.WriteJSON(WebSocketRequest{Action: "subscribe"}))
+
+	msg := readMessage(t, conn)
+	assert.Equal(t, "subscribed", msg.Type)
+	assert.Equal(t, broadcast.UserTopic("user-456"), msg.Topic)
+
+	broker.Publish(domain.AuditEntry{ID: "event-1", SessionID: wsTestSessionID, UserID: "user-456"})
+
+	msg = readMessage(t, conn)
+	assert.Equal(t, "event", msg.Type)
+	assert.Equal(t, "event-1", msg.Event.ID)
+}
+
+func TestWebSocketHandler_SubscribeErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		request       interface{}
+		setupMocks    func(*MockAuditService)
+		expectedError string
+	}{
+		{
+			name:          "other_users_activity",
+			request:       WebSocketRequest{Action: "subscribe", UserID: "user-789"},
+			setupMocks:    func(m *MockAuditService) {},
+			expectedError: "forbidden",
+		},
+		{
+			name:    "session_not_owned",
+			request: WebSocketRequest{Action: "subscribe", SessionID: wsTestSessionID},
+			setupMocks: func(m *MockAuditService) {
+				m.On("AuthorizeSession", mock.Anything, wsTestSessionID, "user-456", false).Return(domain.ErrForbidden)
+			},
+			expectedError: "forbidden",
+		},
+		{
+			name:          "invalid_session_id",
+			request:       WebSocketRequest{Action: "subscribe", SessionID: "not-a-uuid"},
+			setupMocks:    func(m *MockAuditService) {},
+			expectedError: "bad_request",
+		},
+		{
+			name:          "unknown_action",
+			request:       WebSocketRequest{Action: "publish"},
+			setupMocks:    func(m *MockAuditService) {},
+			expectedError: "bad_request",
+		},
+		{
+			name:          "not_json",
+			request:       "hello",
+			setupMocks:    func(m *MockAuditService) {},
+			expectedError: "bad_request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditService)
+			tt.setupMocks(mockService)
+
+			broker := broadcast.NewBroker(zap.NewNop())
+			conn := dialWebSocket(t, NewWebSocketHandler(mockService, broker, middleware.NewCORSOrigin("http://localhost:3000"), zap.NewNop()))
+
+			if raw, ok := tt.request.(string); ok {
+				require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(raw)))
+			} else {
+				require.NoError(t, conn.WriteJSON(tt.request))
+			}
+
+			msg := readMessage(t, conn)
+			assert.Equal(t, "error", msg.Type)
+			assert.Equal(t, tt.expectedError, msg.Error)
+		})
+	}
+}
+
+func TestWebSocketHandler_ReleasesSubscriptionsOnDisconnect(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	broker := broadcast.NewBroker(zap.NewNop())
+	conn := dialWebSocket(t, NewWebSocketHandler(new(MockAuditService), broker, middleware.NewCORSOrigin("http://localhost:3000"), zap.NewNop()))
+
+	require.NoError(t, conn.WriteJSON(WebSocketRequest{Action: "subscribe"}))
+	assert.Equal(t, "subscribed", readMessage(t, conn).Type)
+	assert.Equal(t, 1, broker.SubscriberCount(broadcast.UserTopic("user-456")))
+
+	conn.Close()
+
+	assert.Eventually(t, func() bool {
+		return broker.SubscriberCount(broadcast.UserTopic("user-456")) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWebSocketHandler_RejectsForeignOrigin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewWebSocketHandler(new(MockAuditService), broadcast.NewBroker(zap.NewNop()), middleware.NewCORSOrigin("http://localhost:3000"), zap.NewNop())
+	router := gin.New()
+	router.GET("/ws", handler.Connect)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	header := map[string][]string{"Origin": {"http://evil.example"}}
+	_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", header)
+
+	assert.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, 403, resp.StatusCode)
+}
+
+func TestWSClient_SlowConsumerIsDisconnected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	broker := broadcast.NewBroker(zap.NewNop())
+	handler := NewWebSocketHandler(new(MockAuditService), broker, middleware.NewCORSOrigin("http://localhost:3000"), zap.NewNop())
+	conn := dialWebSocket(t, handler)
+
+	// No write pump drains this queue, so the second message overflows it
+	client := &wsClient{
+		conn:    conn,
+		userID:  "user-456",
+		handler: handler,
+		send:    make(chan WebSocketMessage, 1),
+		done:    make(chan struct{}),
+		subs:    map[string]*broadcast.Subscription{},
+	}
+	client.subscribe(broadcast.UserTopic("user-456"))
+	require.Equal(t, 1, broker.SubscriberCount(broadcast.UserTopic("user-456")))
+
+	client.enqueue(WebSocketMessage{Type: "event"})
+
+	select {
+	case <-client.done:
+	default:
+		t.Fatal("expected slow client to be closed")
+	}
+	assert.Equal(t, 0, broker.SubscriberCount(broadcast.UserTopic("user-456")))
+}
+
+func TestWebSocketHandler_CloseAll(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewWebSocketHandler(new(MockAuditService), broadcast.NewBroker(zap.NewNop()), middleware.NewCORSOrigin("http://localhost:3000"), zap.NewNop())
+	conn := dialWebSocket(t, handler)
+
+	// Wait until the subscription proves the connection is registered
+	require.NoError(t, conn.WriteJSON(WebSocketRequest{Action: "subscribe"}))
+	assert.Equal(t, "subscribed", readMessage(t, conn).Type)
+
+	handler.CloseAll()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error: %v", err)
+}
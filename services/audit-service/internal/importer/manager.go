@@ -0,0 +1,469 @@
+// Package importer backfills historical audit events from gzipped NDJSON files, such as the
+// exports of a legacy audit system, in background jobs whose progress can be polled.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/internal/redact"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/tenant"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// batchSize is the number of events checked for duplicates and stored per database call
+	batchSize = 500
+
+	// maxLineSize caps the length of a line of an import file
+	maxLineSize = 1 << 20
+
+	// maxRunningJobs caps the imports running at once; further jobs wait as pending
+	maxRunningJobs = 2
+
+	// jobRetention is how long finished jobs can still be looked up
+	jobRetention = 24 * time.Hour
+)
+
+// Repository stores imported events and tells which are already stored
+type Repository interface {
+	CreateEvents(ctx context.Context, entries []domain.AuditEntry) error
+	ExistingEventIDs(ctx context.Context, ids []string) ([]string, error)
+}
+
+// Config tunes the import jobs
+type Config struct {
+	// MaxFileSize caps the compressed size of an import file in bytes
+	MaxFileSize int64
+	// TempDir holds uploaded files until they are imported; empty uses the system temporary directory
+	TempDir string
+}
+
+// opener opens the file of a job and returns its size, or zero when unknown
+type opener func(ctx context.Context) (io.ReadCloser, int64, error)
+
+// Manager runs import jobs in the background and keeps their status in memory
+type Manager struct {
+	repo      Repository
+	validator validator
+	client    *http.Client
+	cfg       Config
+	clock     clock.Clock
+	metrics   *metrics.Metrics
+	logger    *zap.Logger
+
+	mu     sync.Mutex
+	jobs   map[string]*domain.ImportJob
+	closed bool
+	slots  chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager creates an import job manager. Imported events are validated against schemas and
+// redacted by redactor like events recorded through the API; files given by URL are fetched
+// with client.
+func NewManager(repo Repository, schemas *domain.SchemaRegistry, redactor *redact.Redactor, client *http.Client, cfg Config, clk clock.Clock, m *metrics.Metrics, logger *zap.Logger) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		repo:      repo,
+		validator: validator{schemas: schemas, redactor: redactor},
+		client:    client,
+		cfg:       cfg,
+		clock:     clk,
+		metrics:   m,
+		logger:    logger,
+		jobs:      map[string]*domain.ImportJob{},
+		slots:     make(chan struct{}, maxRunningJobs),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Upload keeps an uploaded file in the temporary directory and queues its import into the
+// organization ctx is scoped to. Dry runs only validate the file.
+func (m *Manager) Upload(ctx context.Context, file io.Reader, dryRun bool, requestedBy string) (domain.ImportJob, error) {
+	spool, err := os.CreateTemp(m.cfg.TempDir, "audit-import-*.ndjson.gz")
+	if err != nil {
+		return domain.ImportJob{}, fmt.Errorf("failed to create import file: %w", err)
+	}
+	// Reading one byte past the limit tells a file of exactly the limit from a larger one
+	size, err := io.Copy(spool, io.LimitReader(file, m.cfg.MaxFileSize+1))
+	if closeErr := spool.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && size > m.cfg.MaxFileSize {
+		err = fmt.Errorf("%w: file exceeds %d bytes", domain.ErrInvalidImport, m.cfg.MaxFileSize)
+	}
+	if err == nil && size == 0 {
+		err = fmt.Errorf("%w: file is empty", domain.ErrInvalidImport)
+	}
+	if err != nil {
+		_ = os.Remove(spool.Name())
+		return domain.ImportJob{}, err
+	}
+
+	path := spool.Name()
+	job, err := m.start(ctx, domain.ImportUpload, dryRun, requestedBy, func(context.Context) (io.ReadCloser, int64, error) {
+		file, err := os.Open(path)
+		return &removeOnClose{File: file}, size, err
+	})
+	if err != nil {
+		_ = os.Remove(path)
+	}
+	return job, err
+}
+
+// removeOnClose deletes an uploaded file once its import is done
+type removeOnClose struct {
+	*os.File
+}
+
+// Close closes and deletes the file
+func (f *removeOnClose) Close() error {
+	if f.File == nil {
+		return nil
+	}
+	err := f.File.Close()
+	if removeErr := os.Remove(f.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// Fetch queues the import of the file at a signed HTTPS URL into the organization ctx is scoped
+// to. The file is downloaded while it is imported. Dry runs only validate the file.
+func (m *Manager) Fetch(ctx context.Context, rawURL string, dryRun bool, requestedBy string) (domain.ImportJob, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return domain.ImportJob{}, fmt.Errorf("%w: url must be an https URL", domain.ErrInvalidImport)
+	}
+
+	return m.start(ctx, domain.ImportURL, dryRun, requestedBy, func(ctx context.Context) (io.ReadCloser, int64, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+		if err != nil {
+			return nil, 0, err
+		}
+		resp, err := m.client.Do(req)
+		if err != nil {
+			// The error names the URL, whose signature must not end up in the job
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+			return nil, 0, fmt.Errorf("failed to download file: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			_ = resp.Body.Close()
+			return nil, 0, fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+		}
+		if resp.ContentLength > m.cfg.MaxFileSize {
+			_ = resp.Body.Close()
+			return nil, 0, fmt.Errorf("file exceeds %d bytes", m.cfg.MaxFileSize)
+		}
+		return resp.Body, max(resp.ContentLength, 0), nil
+	})
+}
+
+// start records a pending job and runs it once a slot is free
+func (m *Manager) start(ctx context.Context, source domain.ImportSource, dryRun bool, requestedBy string, open opener) (domain.ImportJob, error) {
+	organizationID, scoped := tenant.FromContext(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return domain.ImportJob{}, domain.ErrServiceUnavailable
+	}
+
+	m.pruneLocked()
+	job := &domain.ImportJob{
+		ID:          uuid.New().String(),
+		Source:      source,
+		Status:      domain.ImportPending,
+		DryRun:      dryRun,
+		RequestedBy: requestedBy,
+		CreatedAt:   m.clock.Now(),
+
+		OrganizationID: organizationID,
+	}
+	m.jobs[job.ID] = job
+
+	m.wg.Add(1)
+	go m.run(job.ID, open, scoped)
+
+	return *job, nil
+}
+
+// Get returns the current state of a job; jobs of other organizations are reported as not found
+func (m *Manager) Get(ctx context.Context, id string) (domain.ImportJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok || !tenant.Allows(ctx, job.OrganizationID) {
+		return domain.ImportJob{}, domain.ErrImportJobNotFound
+	}
+	copied := *job
+	copied.Errors = append([]domain.ImportLineError(nil), job.Errors...)
+	return copied, nil
+}
+
+// Close cancels running jobs and waits for them to stop
+func (m *Manager) Close(ctx context.Context) error {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("import jobs did not stop: %w", ctx.Err())
+	}
+}
+
+// run imports the file of a job once a slot is free. Scoped jobs import into their organization.
+func (m *Manager) run(id string, open opener, scoped bool) {
+	defer m.wg.Done()
+
+	var err error
+	select {
+	case m.slots <- struct{}{}:
+		defer func() { <-m.slots }()
+		job := m.update(id, func(job *domain.ImportJob) { job.Status = domain.ImportRunning })
+
+		ctx := m.ctx
+		if scoped {
+			ctx = tenant.NewContext(ctx, job.OrganizationID)
+		}
+		err = m.importFile(ctx, job, open)
+	case <-m.ctx.Done():
+		err = m.ctx.Err()
+		// The file of a job that never ran is still removed
+		if file, _, openErr := open(m.ctx); openErr == nil {
+			_ = file.Close()
+		}
+	}
+
+	now := m.clock.Now()
+	job := m.update(id, func(job *domain.ImportJob) {
+		job.CompletedAt = &now
+		job.Status = domain.ImportCompleted
+		if err != nil {
+			job.Status = domain.ImportFailed
+			job.Error = err.Error()
+		}
+	})
+
+	fields := []zap.Field{
+		zap.String("job_id", id),
+		zap.String("source", string(job.Source)),
+		zap.Bool("dry_run", job.DryRun),
+		zap.Int("lines", job.Lines),
+		zap.Int("imported", job.Imported),
+		zap.Int("duplicates", job.Duplicates),
+		zap.Int("invalid", job.Invalid),
+	}
+	if err != nil {
+		m.logger.Error("import job failed", append(fields, zap.Error(err))...)
+		return
+	}
+	m.logger.Info("import job completed", append(fields, zap.String("requested_by", job.RequestedBy))...)
+}
+
+// importFile reads the lines of a gzipped, or plain, NDJSON file and stores the valid events
+// that are not stored yet in batches
+func (m *Manager) importFile(ctx context.Context, job domain.ImportJob, open opener) error {
+	file, size, err := open(ctx)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	m.update(job.ID, func(job *domain.ImportJob) { job.TotalBytes = size })
+
+	counted := &countingReader{r: io.LimitReader(file, m.cfg.MaxFileSize+1)}
+	buffered := bufio.NewReader(counted)
+	var lines io.Reader = buffered
+	if magic, _ := buffered.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		decompressed, err := gzip.NewReader(buffered)
+		if err != nil {
+			return fmt.Errorf("invalid gzip file: %w", err)
+		}
+		defer decompressed.Close()
+		lines = decompressed
+	}
+
+	scanner := bufio.NewScanner(lines)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	b := &batch{seen: map[string]bool{}}
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		b.lines++
+
+		entry, err := m.validator.entry(line, job.OrganizationID, m.clock.Now())
+		switch {
+		case err != nil:
+			b.invalid = append(b.invalid, domain.ImportLineError{Line: lineNumber, Message: err.Error()})
+		case b.seen[entry.ID]:
+			b.duplicates++
+		default:
+			b.seen[entry.ID] = true
+			b.entries = append(b.entries, entry)
+		}
+
+		if len(b.entries) >= batchSize {
+			if err := m.flush(ctx, job, b, counted.n.Load()); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			err = fmt.Errorf("line %d exceeds %d bytes", lineNumber+1, maxLineSize)
+		}
+		return m.flushThen(ctx, job, b, counted, err)
+	}
+	if counted.n.Load() > m.cfg.MaxFileSize {
+		return m.flushThen(ctx, job, b, counted, fmt.Errorf("file exceeds %d bytes", m.cfg.MaxFileSize))
+	}
+	return m.flush(ctx, job, b, counted.n.Load())
+}
+
+// flushThen reports the lines read before a file turned out unreadable and returns err
+func (m *Manager) flushThen(ctx context.Context, job domain.ImportJob, b *batch, counted *countingReader, err error) error {
+	if flushErr := m.flush(ctx, job, b, counted.n.Load()); flushErr != nil {
+		return flushErr
+	}
+	return err
+}
+
+// batch collects the lines read since the last flush
+type batch struct {
+	// seen holds the IDs of every valid event of the file, so repeated lines are skipped
+	seen       map[string]bool
+	entries    []domain.AuditEntry
+	lines      int
+	duplicates int
+	invalid    []domain.ImportLineError
+}
+
+// flush stores the events of a batch that are not stored yet and adds the batch to the job
+func (m *Manager) flush(ctx context.Context, job domain.ImportJob, b *batch, bytesRead int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	fresh := b.entries
+	if len(b.entries) > 0 {
+		ids := make([]string, len(b.entries))
+		for i, entry := range b.entries {
+			ids[i] = entry.ID
+		}
+		existing, err := m.repo.ExistingEventIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		stored := make(map[string]bool, len(existing))
+		for _, id := range existing {
+			stored[id] = true
+		}
+
+		fresh = make([]domain.AuditEntry, 0, len(b.entries))
+		for _, entry := range b.entries {
+			if !stored[entry.ID] {
+				fresh = append(fresh, entry)
+			}
+		}
+		if !job.DryRun {
+			if err := m.repo.CreateEvents(ctx, fresh); err != nil {
+				return err
+			}
+		}
+	}
+
+	duplicates := b.duplicates + len(b.entries) - len(fresh)
+	m.metrics.ObserveImportedEvents("imported", len(fresh))
+	m.metrics.ObserveImportedEvents("duplicate", duplicates)
+	m.metrics.ObserveImportedEvents("invalid", len(b.invalid))
+	m.update(job.ID, func(job *domain.ImportJob) {
+		job.BytesRead = bytesRead
+		job.Lines += b.lines
+		job.Imported += len(fresh)
+		job.Duplicates += duplicates
+		job.Invalid += len(b.invalid)
+		for _, invalid := range b.invalid {
+			if len(job.Errors) < domain.MaxImportErrors {
+				job.Errors = append(job.Errors, invalid)
+			}
+		}
+	})
+
+	b.entries = b.entries[:0]
+	b.lines, b.duplicates, b.invalid = 0, 0, nil
+	return nil
+}
+
+// countingReader counts the bytes read from a file for the progress of its job
+type countingReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+// Read reads from the file and counts the bytes
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// update applies a change to a job under the lock and returns a copy
+func (m *Manager) update(id string, apply func(job *domain.ImportJob)) domain.ImportJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job := m.jobs[id]
+	apply(job)
+	return *job
+}
+
+// pruneLocked forgets jobs that finished more than jobRetention ago
+func (m *Manager) pruneLocked() {
+	cutoff := m.clock.Now().Add(-jobRetention)
+	for id, job := range m.jobs {
+		if job.CompletedAt != nil && job.CompletedAt.Before(cutoff) {
+			delete(m.jobs, id)
+		}
+	}
+}
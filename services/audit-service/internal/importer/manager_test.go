@@ -0,0 +1,323 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/internal/redact"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+const testSession = "550e8400-e29b-41d4-a716-446655440000"
+
+// memoryRepository stores imported events in memory
+type memoryRepository struct {
+	mu      sync.Mutex
+	entries map[string]domain.AuditEntry
+	err     error
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{entries: map[string]domain.AuditEntry{}}
+}
+
+func (r *memoryRepository) CreateEvents(ctx context.Context, entries []domain.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	for _, entry := range entries {
+		r.entries[entry.ID] = entry
+	}
+	return nil
+}
+
+func (r *memoryRepository) ExistingEventIDs(ctx context.Context, ids []string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var existing []string
+	for _, id := range ids {
+		if _, ok := r.entries[id]; ok {
+			existing = append(existing, id)
+		}
+	}
+	return existing, nil
+}
+
+func (r *memoryRepository) stored() map[string]domain.AuditEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := make(map[string]domain.AuditEntry, len(r.entries))
+	for id, entry := range r.entries {
+		stored[id] = entry
+	}
+	return stored
+}
+
+func newTestManager(t *testing.T, repo Repository, client *http.Client) *Manager {
+	schemas, err := domain.NewDefaultSchemaRegistry()
+	require.NoError(t, err)
+	rules, err := redact.ParseRules("email", "")
+	require.NoError(t, err)
+	m := NewManager(repo, schemas, redact.New(rules), client, Config{MaxFileSize: 1 << 20, TempDir: t.TempDir()},
+		clock.NewFakeClock(testNow), metrics.New(), zap.NewNop())
+	t.Cleanup(func() { _ = m.Close(context.Background()) })
+	return m
+}
+
+// gzipLines compresses lines into an NDJSON file
+func gzipLines(t *testing.T, lines ...string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(strings.Join(lines, "\n") + "\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// viewLine is a valid line of an import file
+func viewLine(id string) string {
+	return fmt.Sprintf(`{"id":%q,"sessionId":%q,"userId":"user-1","type":"view","timestamp":"2021-03-04T05:06:07Z"}`, id, testSession)
+}
+
+// waitFor polls the job until it has finished
+func waitFor(t *testing.T, m *Manager, ctx context.Context, id string) domain.ImportJob {
+	var job domain.ImportJob
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = m.Get(ctx, id)
+		require.NoError(t, err)
+		return job.Status == domain.ImportCompleted || job.Status == domain.ImportFailed
+	}, time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestManager_ImportsUpload(t *testing.T) {
+	repo := newMemoryRepository()
+	m := newTestManager(t, repo, nil)
+	ctx := context.Background()
+
+	file := gzipLines(t,
+		viewLine("a1b2c3d4-0000-4000-8000-000000000001"),
+		"",
+		viewLine("legacy-42"),
+		viewLine("legacy-42"),
+		`{"sessionId":"`+testSession+`","userId":"user-2","type":"comment","timestamp":"2021-03-04T05:06:07Z","details":{"text":"ping bob@example.com"}}`,
+		`{"sessionId":"not-a-uuid","userId":"user-1","type":"view","timestamp":"2021-03-04T05:06:07Z"}`,
+		`{"sessionId":"`+testSession+`","userId":"user-1","type":"view","timestamp":"2030-01-01T00:00:00Z"}`,
+		`{"sessionId":"`+testSession+`","userId":"user-1","type":"view","timestamp":"2021-03-04T05:06:07Z","extra":true}`,
+		`not json`,
+	)
+
+	job, err := m.Upload(ctx, bytes.NewReader(file), false, "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ImportUpload, job.Source)
+	assert.Equal(t, testNow, job.CreatedAt)
+
+	job = waitFor(t, m, ctx, job.ID)
+	assert.Equal(t, domain.ImportCompleted, job.Status, job.Error)
+	assert.Equal(t, 8, job.Lines)
+	assert.Equal(t, 3, job.Imported)
+	assert.Equal(t, 1, job.Duplicates)
+	assert.Equal(t, 4, job.Invalid)
+	assert.Equal(t, int64(len(file)), job.BytesRead)
+	assert.Equal(t, int64(len(file)), job.TotalBytes)
+	require.Len(t, job.Errors, 4)
+	assert.Equal(t, domain.ImportLineError{Line: 6, Message: "sessionId must be a UUID"}, job.Errors[0])
+	assert.Equal(t, domain.ImportLineError{Line: 7, Message: "timestamp is in the future"}, job.Errors[1])
+	assert.Equal(t, 8, job.Errors[2].Line)
+	assert.Equal(t, 9, job.Errors[3].Line)
+
+	stored := repo.stored()
+	require.Len(t, stored, 3)
+	entry := stored["a1b2c3d4-0000-4000-8000-000000000001"]
+	assert.Equal(t, time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC), entry.Timestamp)
+	assert.Equal(t, "user-1", entry.UserID)
+	assert.Contains(t, stored, recordID("legacy-42", nil))
+	for _, entry := range stored {
+		if entry.Type == "comment" {
+			assert.Equal(t, []string{"text"}, entry.RedactedFields)
+		}
+	}
+
+	// The uploaded file is removed once imported
+	remaining, err := os.ReadDir(m.cfg.TempDir)
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+
+	// Importing the file again only finds duplicates
+	job, err = m.Upload(ctx, bytes.NewReader(file), false, "admin-1")
+	require.NoError(t, err)
+	job = waitFor(t, m, ctx, job.ID)
+	assert.Equal(t, 0, job.Imported)
+	assert.Equal(t, 4, job.Duplicates)
+	assert.Len(t, repo.stored(), 3)
+}
+
+func TestManager_DryRunStoresNothing(t *testing.T) {
+	repo := newMemoryRepository()
+	m := newTestManager(t, repo, nil)
+	ctx := context.Background()
+
+	// Plain NDJSON is accepted too
+	file := viewLine("legacy-1") + "\n" + viewLine("legacy-2") + "\n"
+	job, err := m.Upload(ctx, strings.NewReader(file), true, "admin-1")
+	require.NoError(t, err)
+	assert.True(t, job.DryRun)
+
+	job = waitFor(t, m, ctx, job.ID)
+	assert.Equal(t, domain.ImportCompleted, job.Status, job.Error)
+	assert.Equal(t, 2, job.Imported)
+	assert.Empty(t, repo.stored())
+}
+
+func TestManager_ImportsInBatches(t *testing.T) {
+	repo := newMemoryRepository()
+	m := newTestManager(t, repo, nil)
+	ctx := context.Background()
+
+	lines := make([]string, batchSize+10)
+	for i := range lines {
+		lines[i] = viewLine(fmt.Sprintf("legacy-%d", i))
+	}
+	job, err := m.Upload(ctx, bytes.NewReader(gzipLines(t, lines...)), false, "admin-1")
+	require.NoError(t, err)
+
+	job = waitFor(t, m, ctx, job.ID)
+	assert.Equal(t, domain.ImportCompleted, job.Status, job.Error)
+	assert.Equal(t, batchSize+10, job.Imported)
+	assert.Len(t, repo.stored(), batchSize+10)
+}
+
+func TestManager_ReportsFailure(t *testing.T) {
+	repo := newMemoryRepository()
+	repo.err = errors.New("database unavailable")
+	m := newTestManager(t, repo, nil)
+	ctx := context.Background()
+
+	job, err := m.Upload(ctx, bytes.NewReader(gzipLines(t, viewLine("legacy-1"))), false, "admin-1")
+	require.NoError(t, err)
+
+	job = waitFor(t, m, ctx, job.ID)
+	assert.Equal(t, domain.ImportFailed, job.Status)
+	assert.Equal(t, "database unavailable", job.Error)
+	assert.Equal(t, testNow, *job.CompletedAt)
+}
+
+func TestManager_RejectsCorruptGzip(t *testing.T) {
+	m := newTestManager(t, newMemoryRepository(), nil)
+	ctx := context.Background()
+
+	file := gzipLines(t, viewLine("legacy-1"))
+	job, err := m.Upload(ctx, bytes.NewReader(file[:len(file)-4]), false, "admin-1")
+	require.NoError(t, err)
+
+	job = waitFor(t, m, ctx, job.ID)
+	assert.Equal(t, domain.ImportFailed, job.Status)
+	assert.NotEmpty(t, job.Error)
+}
+
+func TestManager_RejectsInvalidUploads(t *testing.T) {
+	m := newTestManager(t, newMemoryRepository(), nil)
+	m.cfg.MaxFileSize = 16
+
+	_, err := m.Upload(context.Background(), strings.NewReader(strings.Repeat("x", 17)), false, "admin-1")
+	assert.ErrorIs(t, err, domain.ErrInvalidImport)
+
+	_, err = m.Upload(context.Background(), strings.NewReader(""), false, "admin-1")
+	assert.ErrorIs(t, err, domain.ErrInvalidImport)
+
+	remaining, err := os.ReadDir(m.cfg.TempDir)
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+}
+
+func TestManager_FetchesURL(t *testing.T) {
+	file := gzipLines(t, viewLine("legacy-1"), viewLine("legacy-2"))
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("signature") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write(file)
+	}))
+	defer server.Close()
+
+	repo := newMemoryRepository()
+	m := newTestManager(t, repo, server.Client())
+	ctx := context.Background()
+
+	job, err := m.Fetch(ctx, server.URL+"/export.ndjson.gz?signature=secret", false, "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ImportURL, job.Source)
+	job = waitFor(t, m, ctx, job.ID)
+	assert.Equal(t, domain.ImportCompleted, job.Status, job.Error)
+	assert.Equal(t, 2, job.Imported)
+	assert.Equal(t, int64(len(file)), job.TotalBytes)
+
+	job, err = m.Fetch(ctx, server.URL+"/export.ndjson.gz?signature=wrong", false, "admin-1")
+	require.NoError(t, err)
+	job = waitFor(t, m, ctx, job.ID)
+	assert.Equal(t, domain.ImportFailed, job.Status)
+	assert.Equal(t, "failed to download file: status 403", job.Error)
+
+	_, err = m.Fetch(ctx, "http://example.com/export.ndjson.gz", false, "admin-1")
+	assert.ErrorIs(t, err, domain.ErrInvalidImport)
+}
+
+func TestManager_ScopesJobsToOrganization(t *testing.T) {
+	repo := newMemoryRepository()
+	m := newTestManager(t, repo, nil)
+	acme := tenant.NewContext(context.Background(), "acme")
+
+	job, err := m.Upload(acme, bytes.NewReader(gzipLines(t, viewLine("legacy-1"))), false, "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", job.OrganizationID)
+	waitFor(t, m, acme, job.ID)
+
+	_, err = m.Get(tenant.NewContext(context.Background(), "globex"), job.ID)
+	assert.ErrorIs(t, err, domain.ErrImportJobNotFound)
+
+	for _, entry := range repo.stored() {
+		assert.Equal(t, "acme", entry.OrganizationID)
+	}
+}
+
+func TestManager_GetUnknownJob(t *testing.T) {
+	m := newTestManager(t, newMemoryRepository(), nil)
+
+	_, err := m.Get(context.Background(), "missing")
+	assert.ErrorIs(t, err, domain.ErrImportJobNotFound)
+}
+
+func TestManager_RejectsJobsAfterClose(t *testing.T) {
+	m := newTestManager(t, newMemoryRepository(), nil)
+	require.NoError(t, m.Close(context.Background()))
+
+	_, err := m.Upload(context.Background(), bytes.NewReader(gzipLines(t, viewLine("legacy-1"))), false, "admin-1")
+	assert.ErrorIs(t, err, domain.ErrServiceUnavailable)
+
+	remaining, err := os.ReadDir(m.cfg.TempDir)
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+}
@@ -0,0 +1,148 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/redact"
+
+	"github.com/google/uuid"
+)
+
+// idNamespace derives the IDs of imported events that do not carry a UUID, so importing a
+// file again finds the events of the first import as duplicates
+var idNamespace = uuid.MustParse("8d2f6c1e-5a7b-4e3c-9f1d-0b4a6e8c2d5f")
+
+// Record is a line of an import file: an event of the legacy system as it happened
+type Record struct {
+	// ID is the UUID of the event; other IDs, or none, are mapped to a UUID derived from them
+	ID        string          `json:"id"`
+	SessionID string          `json:"sessionId"`
+	UserID    string          `json:"userId"`
+	Type      string          `json:"type"`
+	Timestamp string          `json:"timestamp"`
+	Details   json.RawMessage `json:"details"`
+	IPAddress string          `json:"ipAddress"`
+	UserAgent string          `json:"userAgent"`
+
+	CorrelationID string `json:"correlationId"`
+	ParentEventID string `json:"parentEventId"`
+	SchemaVersion int    `json:"schemaVersion"`
+}
+
+// validator turns the lines of an import file into audit entries with the checks of the events API
+type validator struct {
+	schemas  *domain.SchemaRegistry
+	redactor *redact.Redactor
+}
+
+// entry parses and validates a line recorded at or before now
+func (v validator) entry(line []byte, organizationID string, now time.Time) (domain.AuditEntry, error) {
+	var record Record
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&record); err != nil {
+		return domain.AuditEntry{}, fmt.Errorf("invalid JSON: %v", err)
+	}
+
+	sessionID, err := domain.ParseSessionID(record.SessionID)
+	if err != nil {
+		return domain.AuditEntry{}, errors.New("sessionId must be a UUID")
+	}
+	if record.UserID == "" {
+		return domain.AuditEntry{}, errors.New("userId is required")
+	}
+
+	eventType := domain.AuditAction(record.Type)
+	if _, ok := v.schemas.LookupEventType(eventType); !ok {
+		return domain.AuditEntry{}, fmt.Errorf("event type %q is not registered", record.Type)
+	}
+
+	if record.Timestamp == "" {
+		return domain.AuditEntry{}, errors.New("timestamp is required")
+	}
+	timestamp, err := time.Parse(time.RFC3339, record.Timestamp)
+	if err != nil {
+		return domain.AuditEntry{}, errors.New("timestamp must be an RFC 3339 time")
+	}
+	if timestamp.After(now) {
+		return domain.AuditEntry{}, errors.New("timestamp is in the future")
+	}
+
+	if record.IPAddress != "" && net.ParseIP(record.IPAddress) == nil {
+		return domain.AuditEntry{}, errors.New("ipAddress must be an IP address")
+	}
+	if !domain.ValidCorrelationID(record.CorrelationID) {
+		return domain.AuditEntry{}, fmt.Errorf("correlationId must be at most %d printable characters without spaces", domain.MaxCorrelationIDLength)
+	}
+	var parentEventID string
+	if record.ParentEventID != "" {
+		parsed, err := domain.ParseEventID(record.ParentEventID)
+		if err != nil {
+			return domain.AuditEntry{}, errors.New("parentEventId must be a UUID")
+		}
+		parentEventID = parsed.String()
+	}
+
+	details := json.RawMessage("{}")
+	if len(record.Details) > 0 && !bytes.Equal(record.Details, []byte("null")) {
+		details = record.Details
+	}
+	if record.SchemaVersion < 0 {
+		return domain.AuditEntry{}, errors.New("schemaVersion must be positive")
+	}
+	schemaVersion := max(record.SchemaVersion, 1)
+	if err := v.schemas.ValidateVersion(eventType, schemaVersion, details); err != nil {
+		return domain.AuditEntry{}, err
+	}
+	details, redactedFields, err := v.redactor.Redact(details)
+	if err != nil {
+		return domain.AuditEntry{}, err
+	}
+
+	slideID, shapeID, ok := domain.SlideRef(details)
+	if !ok {
+		return domain.AuditEntry{}, fmt.Errorf("details slideId and shapeId must be strings of at most %d characters", domain.MaxSlideRefLength)
+	}
+	commentID, threadID, ok := domain.CommentRef(details)
+	if !ok {
+		return domain.AuditEntry{}, fmt.Errorf("details commentId and threadId must be strings of at most %d characters", domain.MaxCommentRefLength)
+	}
+
+	return domain.AuditEntry{
+		ID:             recordID(record.ID, line),
+		SessionID:      sessionID.String(),
+		UserID:         record.UserID,
+		Type:           record.Type,
+		Timestamp:      timestamp.UTC(),
+		Details:        details,
+		IPAddress:      record.IPAddress,
+		UserAgent:      record.UserAgent,
+		CorrelationID:  record.CorrelationID,
+		ParentEventID:  parentEventID,
+		SchemaVersion:  schemaVersion,
+		RedactedFields: redactedFields,
+		OrganizationID: organizationID,
+		SlideID:        slideID,
+		ShapeID:        shapeID,
+		CommentID:      commentID,
+		ThreadID:       threadID,
+	}, nil
+}
+
+// recordID returns the UUID of a record, or one derived from its legacy ID or, without an ID,
+// from the line itself
+func recordID(id string, line []byte) string {
+	if parsed, err := uuid.Parse(id); err == nil {
+		return parsed.String()
+	}
+	if id != "" {
+		return uuid.NewSHA1(idNamespace, []byte("id:"+id)).String()
+	}
+	return uuid.NewSHA1(idNamespace, append([]byte("line:"), line...)).String()
+}
@@ -0,0 +1,219 @@
+// Package labels keeps the labels reviewers attach to audit events, such as "billing-relevant" or
+// "disputed". Labels are stored beside the events, which stay unchanged, and the event queries
+// filter on them. Only users who may read the session of an event may see or change its labels:
+// its owner, and the reviewers and admins the routes give access to every session.
+package labels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/requestid"
+	"audit-service/pkg/tenant"
+
+	"go.uber.org/zap"
+)
+
+// maxAttempts bounds the retries of an addition or removal that raced with another change
+const maxAttempts = 3
+
+// Repository stores the label sets of the events
+type Repository interface {
+	GetEventLabels(ctx context.Context, eventID string) (*domain.EventLabels, error)
+	SaveEventLabels(ctx context.Context, labels domain.EventLabels) error
+}
+
+// Events looks up the events labels are attached to
+type Events interface {
+	FindEvent(ctx context.Context, eventID domain.EventID) (*domain.AuditEntry, error)
+}
+
+// Authorizer checks that a user may read the audit activity of a session
+type Authorizer interface {
+	AuthorizeSession(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool) error
+}
+
+// Service reads and changes the labels of audit events. Labels are looked up by event ID, so
+// share tokens, which are bound to a session, cannot use them.
+type Service struct {
+	repo       Repository
+	events     Events
+	authorizer Authorizer
+	clock      clock.Clock
+	logger     *zap.Logger
+}
+
+// New creates the label service
+func New(repo Repository, events Events, authorizer Authorizer, clk clock.Clock, logger *zap.Logger) *Service {
+	return &Service{
+		repo:       repo,
+		events:     events,
+		authorizer: authorizer,
+		clock:      clk,
+		logger:     logger,
+	}
+}
+
+// Get returns the labels of an event; version 0 and no labels for events never labeled
+func (s *Service) Get(ctx context.Context, eventID, userID string) (domain.EventLabels, error) {
+	entry, err := s.authorize(ctx, eventID, userID)
+	if err != nil {
+		return domain.EventLabels{}, err
+	}
+	return s.current(ctx, entry)
+}
+
+// Add attaches labels to an event, keeping those it already carries. Additions commute, so one
+// that raced with another change is applied again to the new label set.
+func (s *Service) Add(ctx context.Context, eventID, userID string, labels []string) (domain.EventLabels, error) {
+	added, err := domain.NormalizeLabels(labels)
+	if err != nil {
+		return domain.EventLabels{}, err
+	}
+	if len(added) == 0 {
+		return domain.EventLabels{}, fmt.Errorf("%w: no labels to add", domain.ErrInvalidLabel)
+	}
+	return s.change(ctx, eventID, userID, "event labels added", added, func(current []string) []string {
+		return append(slices.Clone(current), added...)
+	})
+}
+
+// Remove detaches a label from an event. Removing a label the event does not carry changes
+// nothing.
+func (s *Service) Remove(ctx context.Context, eventID, userID, label string) (domain.EventLabels, error) {
+	removed, err := domain.NormalizeLabels([]string{label})
+	if err != nil {
+		return domain.EventLabels{}, err
+	}
+	return s.change(ctx, eventID, userID, "event label removed", removed, func(current []string) []string {
+		return slices.DeleteFunc(slices.Clone(current), func(l string) bool { return l == removed[0] })
+	})
+}
+
+// Replace sets the labels of an event, provided expectedVersion is the current version of its
+// label set. Replacing labels another reviewer changed since they were read returns
+// domain.ErrVersionConflict.
+func (s *Service) Replace(ctx context.Context, eventID, userID string, labels []string, expectedVersion int64) (domain.EventLabels, error) {
+	replaced, err := domain.NormalizeLabels(labels)
+	if err != nil {
+		return domain.EventLabels{}, err
+	}
+	entry, err := s.authorize(ctx, eventID, userID)
+	if err != nil {
+		return domain.EventLabels{}, err
+	}
+	current, err := s.current(ctx, entry)
+	if err != nil {
+		return domain.EventLabels{}, err
+	}
+	if err := domain.CheckVersion(current.Version, expectedVersion); err != nil {
+		return domain.EventLabels{}, err
+	}
+
+	saved, err := s.save(ctx, current, userID, replaced)
+	if err != nil {
+		return domain.EventLabels{}, err
+	}
+	s.log(ctx, "event labels replaced", saved, replaced)
+	return saved, nil
+}
+
+// change applies an addition or removal to the current labels of an event, retrying when
+// another change was stored in between
+func (s *Service) change(ctx context.Context, eventID, userID, message string, changed []string, apply func([]string) []string) (domain.EventLabels, error) {
+	entry, err := s.authorize(ctx, eventID, userID)
+	if err != nil {
+		return domain.EventLabels{}, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		current, err := s.current(ctx, entry)
+		if err != nil {
+			return domain.EventLabels{}, err
+		}
+		labels, err := domain.NormalizeLabels(apply(current.Labels))
+		if err != nil {
+			return domain.EventLabels{}, err
+		}
+		// Changes that leave the labels as they are do not move the version
+		if slices.Equal(labels, current.Labels) {
+			return current, nil
+		}
+
+		saved, err := s.save(ctx, current, userID, labels)
+		if errors.Is(err, domain.ErrVersionConflict) && attempt < maxAttempts {
+			continue
+		}
+		if err != nil {
+			return domain.EventLabels{}, err
+		}
+		s.log(ctx, message, saved, changed)
+		return saved, nil
+	}
+}
+
+// save stores labels as the version after current
+func (s *Service) save(ctx context.Context, current domain.EventLabels, userID string, labels []string) (domain.EventLabels, error) {
+	now := s.clock.Now().UTC()
+	next := current
+	next.Labels = labels
+	next.Version = current.Version + 1
+	next.UpdatedBy = userID
+	next.UpdatedAt = &now
+
+	if err := s.repo.SaveEventLabels(ctx, next); err != nil {
+		return domain.EventLabels{}, err
+	}
+	return next, nil
+}
+
+// current returns the stored labels of an event, or the empty set at version 0
+func (s *Service) current(ctx context.Context, entry *domain.AuditEntry) (domain.EventLabels, error) {
+	stored, err := s.repo.GetEventLabels(ctx, entry.ID)
+	if err != nil {
+		return domain.EventLabels{}, err
+	}
+	if stored != nil {
+		return *stored, nil
+	}
+	organizationID, _ := tenant.FromContext(ctx)
+	return domain.EventLabels{
+		EventID:        entry.ID,
+		SessionID:      entry.SessionID,
+		Labels:         []string{},
+		OrganizationID: organizationID,
+	}, nil
+}
+
+// authorize returns an event of a session userID may read
+func (s *Service) authorize(ctx context.Context, eventID, userID string) (*domain.AuditEntry, error) {
+	parsed, err := domain.ParseEventID(eventID)
+	if err != nil {
+		return nil, domain.ErrEventNotFound
+	}
+	entry, err := s.events.FindEvent(ctx, parsed)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizer.AuthorizeSession(ctx, domain.SessionID(entry.SessionID), domain.UserID(userID), false); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// log records a change of the labels of an event
+func (s *Service) log(ctx context.Context, message string, labels domain.EventLabels, changed []string) {
+	s.logger.Info(message,
+		requestid.Field(ctx),
+		zap.String("event_id", labels.EventID),
+		zap.String("session_id", labels.SessionID),
+		zap.Strings("labels", changed),
+		zap.Int64("version", labels.Version),
+		zap.String("updated_by", labels.UpdatedBy),
+		tenant.Field(ctx),
+	)
+}
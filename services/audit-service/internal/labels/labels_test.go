@@ -0,0 +1,164 @@
+package labels
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2024, 2, 8, 12, 0, 0, 0, time.UTC)
+
+const (
+	testSession = "550e8400-e29b-41d4-a716-446655440000"
+	testEvent   = "550e8400-e29b-41d4-a716-446655440001"
+)
+
+// memoryLabels keeps label sets in memory like the audit_event_labels table
+type memoryLabels struct {
+	labels map[string]domain.EventLabels
+	// beforeSave runs before each save, standing in for a concurrent change
+	beforeSave func()
+}
+
+func (r *memoryLabels) GetEventLabels(_ context.Context, eventID string) (*domain.EventLabels, error) {
+	labels, ok := r.labels[eventID]
+	if !ok {
+		return nil, nil
+	}
+	return &labels, nil
+}
+
+func (r *memoryLabels) SaveEventLabels(_ context.Context, labels domain.EventLabels) error {
+	if r.beforeSave != nil {
+		r.beforeSave()
+	}
+	if r.labels[labels.EventID].Version != labels.Version-1 {
+		return domain.ErrVersionConflict
+	}
+	r.labels[labels.EventID] = labels
+	return nil
+}
+
+// memoryEvents serves one event of a session owned by user-1
+type memoryEvents struct{}
+
+func (memoryEvents) FindEvent(_ context.Context, eventID domain.EventID) (*domain.AuditEntry, error) {
+	if eventID != testEvent {
+		return nil, domain.ErrEventNotFound
+	}
+	return &domain.AuditEntry{ID: testEvent, SessionID: testSession}, nil
+}
+
+func (memoryEvents) AuthorizeSession(_ context.Context, _ domain.SessionID, userID domain.UserID, _ bool) error {
+	if userID != "user-1" {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+func newTestService() (*Service, *memoryLabels) {
+	repo := &memoryLabels{labels: map[string]domain.EventLabels{}}
+	return New(repo, memoryEvents{}, memoryEvents{}, clock.NewFakeClock(testNow), zap.NewNop()), repo
+}
+
+func TestService_AddAndRemove(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+
+	labels, err := svc.Get(ctx, testEvent, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), labels.Version)
+	assert.Equal(t, []string{}, labels.Labels)
+
+	labels, err = svc.Add(ctx, testEvent, "user-1", []string{"Disputed ", "billing-relevant"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"billing-relevant", "disputed"}, labels.Labels)
+	assert.Equal(t, int64(1), labels.Version)
+	assert.Equal(t, "user-1", labels.UpdatedBy)
+	assert.Equal(t, testNow, *labels.UpdatedAt)
+	assert.Equal(t, testSession, labels.SessionID)
+
+	// Adding labels the event carries already does not move the version
+	labels, err = svc.Add(ctx, testEvent, "user-1", []string{"disputed"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), labels.Version)
+
+	labels, err = svc.Remove(ctx, testEvent, "user-1", "disputed")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"billing-relevant"}, labels.Labels)
+	assert.Equal(t, int64(2), labels.Version)
+
+	labels, err = svc.Remove(ctx, testEvent, "user-1", "disputed")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), labels.Version)
+
+	_, err = svc.Add(ctx, testEvent, "user-1", []string{"not a slug"})
+	assert.ErrorIs(t, err, domain.ErrInvalidLabel)
+	_, err = svc.Add(ctx, testEvent, "user-1", nil)
+	assert.ErrorIs(t, err, domain.ErrInvalidLabel)
+}
+
+func TestService_AddRetriesConflicts(t *testing.T) {
+	svc, repo := newTestService()
+	ctx := context.Background()
+
+	// Another reviewer labels the event between the read and the save of the first attempt
+	repo.beforeSave = func() {
+		repo.beforeSave = nil
+		repo.labels[testEvent] = domain.EventLabels{EventID: testEvent, SessionID: testSession, Labels: []string{"disputed"}, Version: 1}
+	}
+
+	labels, err := svc.Add(ctx, testEvent, "user-1", []string{"billing-relevant"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"billing-relevant", "disputed"}, labels.Labels)
+	assert.Equal(t, int64(2), labels.Version)
+
+	// Attempts are bounded
+	repo.beforeSave = func() {
+		current := repo.labels[testEvent]
+		current.Version++
+		repo.labels[testEvent] = current
+	}
+	_, err = svc.Add(ctx, testEvent, "user-1", []string{"reviewed"})
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+}
+
+func TestService_Replace(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+
+	labels, err := svc.Replace(ctx, testEvent, "user-1", []string{"disputed"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), labels.Version)
+
+	// A replacement made against a version that is no longer current is refused
+	_, err = svc.Replace(ctx, testEvent, "user-1", []string{"reviewed"}, 0)
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+
+	labels, err = svc.Replace(ctx, testEvent, "user-1", nil, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, labels.Labels)
+	assert.Equal(t, int64(2), labels.Version)
+}
+
+func TestService_Access(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+
+	_, err := svc.Get(ctx, testEvent, "user-2")
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	_, err = svc.Add(ctx, testEvent, "user-2", []string{"disputed"})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	_, err = svc.Get(ctx, "550e8400-e29b-41d4-a716-446655440009", "user-1")
+	assert.ErrorIs(t, err, domain.ErrEventNotFound)
+	_, err = svc.Get(ctx, "not-an-id", "user-1")
+	assert.ErrorIs(t, err, domain.ErrEventNotFound)
+}
@@ -0,0 +1,139 @@
+package legalhold
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+
+// memoryRepository keeps legal holds in memory like the audit_legal_holds table
+type memoryRepository struct {
+	holds []domain.LegalHold
+	err   error
+}
+
+func (r *memoryRepository) CreateLegalHold(_ context.Context, hold domain.LegalHold) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.holds = append(r.holds, hold)
+	return nil
+}
+
+func (r *memoryRepository) ListLegalHolds(_ context.Context, activeOnly bool) ([]domain.LegalHold, error) {
+	var holds []domain.LegalHold
+	for i := len(r.holds) - 1; i >= 0; i-- {
+		if !activeOnly || r.holds[i].Active() {
+			holds = append(holds, r.holds[i])
+		}
+	}
+	return holds, r.err
+}
+
+func (r *memoryRepository) ReleaseLegalHold(_ context.Context, id, releasedBy string, at time.Time) (*domain.LegalHold, error) {
+	for i := range r.holds {
+		if r.holds[i].ID != id {
+			continue
+		}
+		if !r.holds[i].Active() {
+			return nil, domain.ErrLegalHoldReleased
+		}
+		r.holds[i].ReleasedBy = releasedBy
+		r.holds[i].ReleasedAt = &at
+		hold := r.holds[i]
+		return &hold, nil
+	}
+	return nil, domain.ErrLegalHoldNotFound
+}
+
+func TestManager_Place(t *testing.T) {
+	tests := []struct {
+		name           string
+		scope          domain.LegalHoldScope
+		target         string
+		reason         string
+		expectedTarget string
+		expectedErr    error
+	}{
+		{
+			name: "session", scope: domain.LegalHoldSession, target: "550E8400-E29B-41D4-A716-446655440000", reason: "Litigation 2024-017",
+			expectedTarget: "550e8400-e29b-41d4-a716-446655440000",
+		},
+		{name: "user", scope: domain.LegalHoldUser, target: " user-2 ", reason: "Investigation", expectedTarget: "user-2"},
+		{name: "unknown scope", scope: "tenant", target: "tenant-1", reason: "Investigation", expectedErr: domain.ErrInvalidLegalHold},
+		{name: "session target not a UUID", scope: domain.LegalHoldSession, target: "session-1", reason: "Investigation", expectedErr: domain.ErrInvalidLegalHold},
+		{name: "missing reason", scope: domain.LegalHoldUser, target: "user-2", reason: "  ", expectedErr: domain.ErrInvalidLegalHold},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryRepository{}
+			m := NewManager(repo, clock.NewFakeClock(testNow), zap.NewNop())
+
+			hold, err := m.Place(context.Background(), tt.scope, tt.target, tt.reason, "admin-1")
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, repo.holds)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, hold.ID)
+			assert.Equal(t, tt.expectedTarget, hold.Target)
+			assert.Equal(t, "admin-1", hold.PlacedBy)
+			assert.Equal(t, testNow, hold.PlacedAt)
+			assert.True(t, hold.Active())
+			assert.Equal(t, []domain.LegalHold{hold}, repo.holds)
+		})
+	}
+}
+
+func TestManager_Release(t *testing.T) {
+	repo := &memoryRepository{}
+	clk := clock.NewFakeClock(testNow)
+	m := NewManager(repo, clk, zap.NewNop())
+	ctx := context.Background()
+
+	hold, err := m.Place(ctx, domain.LegalHoldUser, "user-2", "Investigation", "admin-1")
+	require.NoError(t, err)
+
+	clk.Advance(time.Hour)
+	released, err := m.Release(ctx, hold.ID, "admin-2")
+	require.NoError(t, err)
+	assert.Equal(t, "admin-2", released.ReleasedBy)
+	assert.Equal(t, testNow.Add(time.Hour), *released.ReleasedAt)
+
+	_, err = m.Release(ctx, hold.ID, "admin-2")
+	assert.ErrorIs(t, err, domain.ErrLegalHoldReleased)
+	_, err = m.Release(ctx, "not-a-uuid", "admin-2")
+	assert.ErrorIs(t, err, domain.ErrLegalHoldNotFound)
+
+	// Released holds remain listed
+	all, err := m.List(ctx, false)
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+	active, err := m.List(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.LegalHold{}, active)
+}
+
+func TestManager_RepositoryError(t *testing.T) {
+	repo := &memoryRepository{err: errors.New("database unavailable")}
+	m := NewManager(repo, clock.NewFakeClock(testNow), zap.NewNop())
+
+	_, err := m.Place(context.Background(), domain.LegalHoldUser, "user-2", "Investigation", "admin-1")
+	assert.ErrorContains(t, err, "database unavailable")
+
+	_, err = m.List(context.Background(), true)
+	assert.ErrorContains(t, err, "database unavailable")
+}
@@ -0,0 +1,70 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Hook releases a resource during shutdown and must return once ctx is done
+type Hook func(ctx context.Context) error
+
+// namedHook pairs a hook with the name used in logs and errors
+type namedHook struct {
+	name string
+	hook Hook
+}
+
+// Shutdown runs registered hooks after the HTTP server has stopped accepting requests
+type Shutdown struct {
+	mu     sync.Mutex
+	hooks  []namedHook
+	logger *zap.Logger
+}
+
+// New creates a new shutdown coordinator
+func New(logger *zap.Logger) *Shutdown {
+	return &Shutdown{
+		logger: logger,
+	}
+}
+
+// Register adds a hook; hooks run in registration order
+func (s *Shutdown) Register(name string, hook Hook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hooks = append(s.hooks, namedHook{name: name, hook: hook})
+}
+
+// Run executes every hook, even when an earlier one fails, and returns the combined errors
+func (s *Shutdown) Run(ctx context.Context) error {
+	s.mu.Lock()
+	hooks := append([]namedHook(nil), s.hooks...)
+	s.mu.Unlock()
+
+	var errs []error
+	for _, h := range hooks {
+		start := time.Now()
+		if err := h.hook(ctx); err != nil {
+			s.logger.Error("shutdown hook failed",
+				zap.String("hook", h.name),
+				zap.Duration("duration", time.Since(start)),
+				zap.Error(err),
+			)
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+			continue
+		}
+
+		s.logger.Info("shutdown hook completed",
+			zap.String("hook", h.name),
+			zap.Duration("duration", time.Since(start)),
+		)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,61 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestShutdown_RunsHooksInOrder(t *testing.T) {
+	s := New(zap.NewNop())
+
+	var order []string
+	s.Register("first", func(ctx context.Context) error {
+		order = append(order, "first")
+		return nil
+	})
+	s.Register("second", func(ctx context.Context) error {
+		order = append(order, "second")
+		return nil
+	})
+
+	assert.NoError(t, s.Run(context.Background()))
+	assert.Equal(t, []string{"first", "second"}, order)
+}
+
+func TestShutdown_ContinuesAfterFailure(t *testing.T) {
+	s := New(zap.NewNop())
+
+	flushErr := errors.New("flush failed")
+	ran := false
+	s.Register("buffer", func(ctx context.Context) error {
+		return flushErr
+	})
+	s.Register("cache", func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+
+	err := s.Run(context.Background())
+
+	assert.True(t, ran)
+	assert.ErrorIs(t, err, flushErr)
+	assert.Contains(t, err.Error(), "buffer: flush failed")
+}
+
+func TestShutdown_PassesDeadline(t *testing.T) {
+	s := New(zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	assert.ErrorIs(t, s.Run(ctx), context.Canceled)
+}
@@ -0,0 +1,477 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"audit-service/pkg/breaker"
+	"audit-service/pkg/cache"
+	"audit-service/pkg/workpool"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric exposed by the service
+const namespace = "audit_service"
+
+// Metrics holds the Prometheus collectors of the service
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests     *prometheus.CounterVec
+	httpDuration     *prometheus.HistogramVec
+	supabaseRequests *prometheus.CounterVec
+	supabaseDuration *prometheus.HistogramVec
+	supabaseRetries  *prometheus.CounterVec
+	breakerChanges   *prometheus.CounterVec
+
+	writeBufferFlushes      *prometheus.CounterVec
+	writeBufferFlushed      prometheus.Counter
+	writeBufferDropped      *prometheus.CounterVec
+	writeBufferFlushLatency prometheus.Histogram
+
+	retentionRuns     *prometheus.CounterVec
+	retentionPurged   *prometheus.CounterVec
+	retentionArchived *prometheus.CounterVec
+
+	rollupRuns    *prometheus.CounterVec
+	rollupWritten prometheus.Counter
+
+	outboxDeliveries *prometheus.CounterVec
+
+	anomalyAlerts *prometheus.CounterVec
+
+	watchDigests *prometheus.CounterVec
+	siemMessages *prometheus.CounterVec
+	authFailures *prometheus.CounterVec
+	lockouts     *prometheus.CounterVec
+	reportRuns   *prometheus.CounterVec
+	importEvents *prometheus.CounterVec
+	replayEvents *prometheus.CounterVec
+
+	realtimeChanges *prometheus.CounterVec
+
+	quotaRejections *prometheus.CounterVec
+}
+
+// New creates the service metrics on a dedicated registry
+func New() *Metrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	m := &Metrics{
+		registry: registry,
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests processed, by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency, by method and route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		supabaseRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "supabase_requests_total",
+			Help:      "Requests sent to the Supabase REST API, by method, endpoint and status.",
+		}, []string{"method", "endpoint", "status"}),
+		supabaseDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "supabase_request_duration_seconds",
+			Help:      "Supabase REST API latency, by method and endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "endpoint"}),
+		supabaseRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "supabase_retries_total",
+			Help:      "Supabase requests repeated after a transient failure, by method and endpoint.",
+		}, []string{"method", "endpoint"}),
+		breakerChanges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "supabase_circuit_breaker_transitions_total",
+			Help:      "Supabase circuit breaker state changes, by new state.",
+		}, []string{"state"}),
+		writeBufferFlushes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "write_buffer_flushes_total",
+			Help:      "Batches flushed from the write buffer, by result.",
+		}, []string{"result"}),
+		writeBufferFlushed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "write_buffer_flushed_events_total",
+			Help:      "Audit events persisted by the write buffer.",
+		}),
+		writeBufferDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "write_buffer_dropped_events_total",
+			Help:      "Audit events dropped by the write buffer, by reason.",
+		}, []string{"reason"}),
+		writeBufferFlushLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "write_buffer_flush_duration_seconds",
+			Help:      "Time taken to flush a batch from the write buffer.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		retentionRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retention_runs_total",
+			Help:      "Retention purge runs, by result.",
+		}, []string{"result"}),
+		retentionPurged: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retention_purged_events_total",
+			Help:      "Audit events deleted by the retention policy, by event type.",
+		}, []string{"type"}),
+		retentionArchived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retention_archived_events_total",
+			Help:      "Audit events archived to cold storage before deletion, by event type.",
+		}, []string{"type"}),
+		rollupRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "activity_rollup_runs_total",
+			Help:      "Activity rollup runs, by result.",
+		}, []string{"result"}),
+		rollupWritten: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "activity_rollups_written_total",
+			Help:      "Hourly and daily activity rollups written by rollup runs.",
+		}),
+		outboxDeliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "outbox_deliveries_total",
+			Help:      "Outbox messages processed by the relay, by result (delivered, retry, dead).",
+		}, []string{"result"}),
+		anomalyAlerts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "anomaly_alerts_total",
+			Help:      "Security alerts raised by the anomaly detector, by rule.",
+		}, []string{"rule"}),
+		watchDigests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "watch_digests_total",
+			Help:      "Digests of watched session events sent to the notification sink, by result (sent, failed).",
+		}, []string{"result"}),
+		siemMessages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "siem_messages_total",
+			Help:      "Security events exported to the syslog endpoint, by result (sent, failed, dropped).",
+		}, []string{"result"}),
+		authFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_failure_events_total",
+			Help:      "Rejected requests recorded as auth_failure events, by result (recorded, failed, dropped).",
+		}, []string{"result"}),
+		lockouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "brute_force_lockouts_total",
+			Help:      "Clients and users throttled after repeated rejected requests, by subject (ip, user).",
+		}, []string{"subject"}),
+		reportRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "report_runs_total",
+			Help:      "Scheduled and manual report runs, by kind and status (succeeded, failed).",
+		}, []string{"kind", "status"}),
+		importEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "import_events_total",
+			Help:      "Lines of historical import files, by result (imported, duplicate, invalid).",
+		}, []string{"result"}),
+		replayEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "replay_events_total",
+			Help:      "Events of replay jobs, by target (webhook, outbox) and result (replayed, skipped, failed).",
+		}, []string{"target", "result"}),
+		realtimeChanges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "realtime_changes_total",
+			Help:      "Changes received from Supabase Realtime, by table and result (recorded, matched, skipped, failed).",
+		}, []string{"table", "result"}),
+		quotaRejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "quota_rejections_total",
+			Help:      "Event submissions rejected for exceeding a daily quota, by scope (session, user).",
+		}, []string{"scope"}),
+	}
+
+	registry.MustRegister(
+		m.httpRequests, m.httpDuration,
+		m.supabaseRequests, m.supabaseDuration, m.supabaseRetries, m.breakerChanges,
+		m.writeBufferFlushes, m.writeBufferFlushed, m.writeBufferDropped, m.writeBufferFlushLatency,
+		m.retentionRuns, m.retentionPurged, m.retentionArchived,
+		m.rollupRuns, m.rollupWritten,
+		m.outboxDeliveries,
+		m.anomalyAlerts,
+		m.watchDigests,
+		m.siemMessages,
+		m.authFailures,
+		m.lockouts,
+		m.reportRuns,
+		m.importEvents,
+		m.replayEvents,
+		m.realtimeChanges,
+		m.quotaRejections,
+	)
+
+	return m
+}
+
+// Registry returns the registry so other components can add their own collectors
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler returns the HTTP handler serving the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveHTTPRequest records a completed HTTP request
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveSupabaseRequest records a completed Supabase request
+func (m *Metrics) ObserveSupabaseRequest(method, endpoint, status string, duration time.Duration) {
+	m.supabaseRequests.WithLabelValues(method, endpoint, status).Inc()
+	m.supabaseDuration.WithLabelValues(method, endpoint).Observe(duration.Seconds())
+}
+
+// ObserveSupabaseRetry records a Supabase request that is repeated after a transient failure
+func (m *Metrics) ObserveSupabaseRetry(method, endpoint string) {
+	m.supabaseRetries.WithLabelValues(method, endpoint).Inc()
+}
+
+// RegisterCircuitBreaker exposes the state of the Supabase circuit breaker
+func (m *Metrics) RegisterCircuitBreaker(state func() breaker.State) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "supabase_circuit_breaker_state",
+		Help:      "State of the Supabase circuit breaker: 0 closed, 1 half-open, 2 open.",
+	}, func() float64 {
+		return float64(state())
+	}))
+}
+
+// ObserveCircuitBreakerTransition records a state change of the Supabase circuit breaker
+func (m *Metrics) ObserveCircuitBreakerTransition(to breaker.State) {
+	m.breakerChanges.WithLabelValues(to.String()).Inc()
+}
+
+// RegisterTokenCache exposes hit, miss and size statistics of the token cache
+func (m *Metrics) RegisterTokenCache(tc *cache.TokenCache) {
+	lookup := func(kind, result string, value func(cache.HitStats) uint64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "token_cache_lookups_total",
+			Help:        "Token cache lookups, by token kind and result.",
+			ConstLabels: prometheus.Labels{"kind": kind, "result": result},
+		}, func() float64 {
+			return float64(value(tc.HitStats()))
+		})
+	}
+
+	m.registry.MustRegister(
+		lookup("jwt", "hit", func(s cache.HitStats) uint64 { return s.JWTHits }),
+		lookup("jwt", "miss", func(s cache.HitStats) uint64 { return s.JWTMisses }),
+		lookup("share", "hit", func(s cache.HitStats) uint64 { return s.ShareHits }),
+		lookup("share", "miss", func(s cache.HitStats) uint64 { return s.ShareMisses }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "token_cache_items",
+			Help:      "Entries currently held by the token cache.",
+		}, func() float64 {
+			return float64(tc.ItemCount())
+		}),
+	)
+}
+
+// LRUCache is an LRU cache whose lookups are exposed as metrics
+type LRUCache interface {
+	Stats() cache.LRUStats
+	Len() int
+}
+
+// RegisterSessionCache exposes hit, miss and size statistics of the session cache. Lookups
+// that shared the storage request of a concurrent lookup are counted with result shared.
+func (m *Metrics) RegisterSessionCache(sessions LRUCache) {
+	m.registerLRU("session_cache", "Session cache", sessions)
+}
+
+// RegisterQueryCache exposes hit, miss and size statistics of the history and stats query
+// cache, counted like those of the session cache
+func (m *Metrics) RegisterQueryCache(queries LRUCache) {
+	m.registerLRU("query_cache", "Query cache", queries)
+}
+
+// registerLRU exposes the lookups and entries of an LRU cache under the metric name prefix
+func (m *Metrics) registerLRU(prefix, title string, c LRUCache) {
+	lookup := func(result string, value func(cache.LRUStats) uint64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        prefix + "_lookups_total",
+			Help:        title + " lookups, by result.",
+			ConstLabels: prometheus.Labels{"result": result},
+		}, func() float64 {
+			return float64(value(c.Stats()))
+		})
+	}
+
+	m.registry.MustRegister(
+		lookup("hit", func(s cache.LRUStats) uint64 { return s.Hits }),
+		lookup("miss", func(s cache.LRUStats) uint64 { return s.Misses }),
+		lookup("shared", func(s cache.LRUStats) uint64 { return s.Shared }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      prefix + "_items",
+			Help:      "Entries currently held by the " + strings.ToLower(title) + ".",
+		}, func() float64 {
+			return float64(c.Len())
+		}),
+	)
+}
+
+// ObserveWriteBufferFlush records a batch flushed from the write buffer
+func (m *Metrics) ObserveWriteBufferFlush(size int, duration time.Duration, err error) {
+	m.writeBufferFlushLatency.Observe(duration.Seconds())
+	if err != nil {
+		m.writeBufferFlushes.WithLabelValues("error").Inc()
+		return
+	}
+	m.writeBufferFlushes.WithLabelValues("ok").Inc()
+	m.writeBufferFlushed.Add(float64(size))
+}
+
+// ObserveWriteBufferDrop records events the write buffer could not persist
+func (m *Metrics) ObserveWriteBufferDrop(reason string, count int) {
+	m.writeBufferDropped.WithLabelValues(reason).Add(float64(count))
+}
+
+// RegisterWriteBuffer exposes the number of events waiting in the write buffer
+func (m *Metrics) RegisterWriteBuffer(depth func() int) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "write_buffer_depth",
+		Help:      "Audit events waiting in the write buffer.",
+	}, func() float64 {
+		return float64(depth())
+	}))
+}
+
+// RegisterWorkPool exposes the size, running operations and queue depth of a worker pool,
+// labelled with its name
+func (m *Metrics) RegisterWorkPool(name string, pool *workpool.Pool) {
+	gauge := func(metric, help string, value func(workpool.Stats) int) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        metric,
+			Help:        help,
+			ConstLabels: prometheus.Labels{"pool": name},
+		}, func() float64 {
+			return float64(value(pool.Stats()))
+		})
+	}
+
+	m.registry.MustRegister(
+		gauge("work_pool_size", "Operations a worker pool runs at once; zero is unlimited.",
+			func(s workpool.Stats) int { return s.Size }),
+		gauge("work_pool_running", "Operations currently running in a worker pool.",
+			func(s workpool.Stats) int { return s.Running }),
+		gauge("work_pool_queued", "Operations waiting for a slot in a worker pool.",
+			func(s workpool.Stats) int { return s.Queued }),
+	)
+}
+
+// ObserveRetentionRun records the outcome of a retention purge run
+func (m *Metrics) ObserveRetentionRun(err error) {
+	if err != nil {
+		m.retentionRuns.WithLabelValues("error").Inc()
+		return
+	}
+	m.retentionRuns.WithLabelValues("ok").Inc()
+}
+
+// ObserveRetentionPurge records events of one type deleted by the retention policy
+func (m *Metrics) ObserveRetentionPurge(eventType string, count int) {
+	m.retentionPurged.WithLabelValues(eventType).Add(float64(count))
+}
+
+// ObserveActivityRollup records the outcome of an activity rollup run and the rollups it wrote
+func (m *Metrics) ObserveActivityRollup(written int, err error) {
+	m.rollupWritten.Add(float64(written))
+	if err != nil {
+		m.rollupRuns.WithLabelValues("error").Inc()
+		return
+	}
+	m.rollupRuns.WithLabelValues("ok").Inc()
+}
+
+// ObserveRetentionArchive records events of one type archived before deletion
+func (m *Metrics) ObserveRetentionArchive(eventType string, count int) {
+	m.retentionArchived.WithLabelValues(eventType).Add(float64(count))
+}
+
+// ObserveOutboxDelivery records the outcome of forwarding an outbox message
+func (m *Metrics) ObserveOutboxDelivery(result string) {
+	m.outboxDeliveries.WithLabelValues(result).Inc()
+}
+
+// ObserveAnomalyAlert records a security alert raised by the anomaly detector
+func (m *Metrics) ObserveAnomalyAlert(rule string) {
+	m.anomalyAlerts.WithLabelValues(rule).Inc()
+}
+
+// ObserveWatchDigest records the outcome of sending a digest to the notification sink
+func (m *Metrics) ObserveWatchDigest(result string) {
+	m.watchDigests.WithLabelValues(result).Inc()
+}
+
+// ObserveSIEMMessage records the outcome of exporting a security event to the syslog endpoint
+func (m *Metrics) ObserveSIEMMessage(result string) {
+	m.siemMessages.WithLabelValues(result).Inc()
+}
+
+// ObserveAuthFailureEvents records the outcome of recording rejected requests as auth_failure events
+func (m *Metrics) ObserveAuthFailureEvents(result string, count int) {
+	m.authFailures.WithLabelValues(result).Add(float64(count))
+}
+
+// ObserveBruteForceLockout records a client IP or user throttled after repeated rejected requests
+func (m *Metrics) ObserveBruteForceLockout(subject string) {
+	m.lockouts.WithLabelValues(subject).Inc()
+}
+
+// ObserveReportRun records the outcome of generating and delivering a report
+func (m *Metrics) ObserveReportRun(kind, status string) {
+	m.reportRuns.WithLabelValues(kind, status).Inc()
+}
+
+// ObserveImportedEvents records the outcome of lines of a historical import file
+func (m *Metrics) ObserveImportedEvents(result string, count int) {
+	m.importEvents.WithLabelValues(result).Add(float64(count))
+}
+
+// ObserveReplayedEvents records the outcome of events re-delivered by a replay job
+func (m *Metrics) ObserveReplayedEvents(target, result string, count int) {
+	m.replayEvents.WithLabelValues(target, result).Add(float64(count))
+}
+
+// ObserveRealtimeChange records how a change received from Supabase Realtime was handled
+func (m *Metrics) ObserveRealtimeChange(table, result string) {
+	m.realtimeChanges.WithLabelValues(table, result).Inc()
+}
+
+// ObserveQuotaRejection records a submission rejected for exceeding a daily event quota
+func (m *Metrics) ObserveQuotaRejection(scope string) {
+	m.quotaRejections.WithLabelValues(scope).Inc()
+}
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"audit-service/pkg/cache"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/workpool"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_ObserveHTTPRequest(t *testing.T) {
+	m := New()
+
+	m.ObserveHTTPRequest("GET", "/api/v1/sessions/:sessionId/history", 200, 20*time.Millisecond)
+	m.ObserveHTTPRequest("GET", "/api/v1/sessions/:sessionId/history", 200, 30*time.Millisecond)
+	m.ObserveHTTPRequest("GET", "/api/v1/sessions/:sessionId/history", 403, 5*time.Millisecond)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/api/v1/sessions/:sessionId/history", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/api/v1/sessions/:sessionId/history", "403")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.httpDuration))
+}
+
+func TestMetrics_ObserveSupabaseRequest(t *testing.T) {
+	m := New()
+
+	m.ObserveSupabaseRequest("POST", "/audit_logs", "ok", 10*time.Millisecond)
+	m.ObserveSupabaseRequest("POST", "/audit_logs", "503", 10*time.Millisecond)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.supabaseRequests.WithLabelValues("POST", "/audit_logs", "503")))
+}
+
+func TestMetrics_RegisterTokenCache(t *testing.T) {
+	m := New()
+	tc := cache.NewTokenCache(5*time.Minute, time.Minute, 10*time.Minute, clock.New())
+	m.RegisterTokenCache(tc)
+
+	tc.SetJWT("token", &cache.CachedTokenInfo{UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)})
+	tc.GetJWT("token")
+	tc.GetJWT("other")
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := w.Body.String()
+	assert.Contains(t, body, `audit_service_token_cache_lookups_total{kind="jwt",result="hit"} 1`)
+	assert.Contains(t, body, `audit_service_token_cache_lookups_total{kind="jwt",result="miss"} 1`)
+	assert.Contains(t, body, `audit_service_token_cache_items 1`)
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func TestMetrics_RegisterSessionCache(t *testing.T) {
+	m := New()
+	sessions := cache.NewLRU[string](10, time.Minute, clock.New())
+	m.RegisterSessionCache(sessions)
+
+	_, _ = sessions.Load(context.Background(), "session-1", func(ctx context.Context) (string, error) { return "owner-1", nil })
+	_, _ = sessions.Load(context.Background(), "session-1", func(ctx context.Context) (string, error) { return "owner-1", nil })
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := w.Body.String()
+	assert.Contains(t, body, `audit_service_session_cache_lookups_total{result="hit"} 1`)
+	assert.Contains(t, body, `audit_service_session_cache_lookups_total{result="miss"} 1`)
+	assert.Contains(t, body, `audit_service_session_cache_lookups_total{result="shared"} 0`)
+	assert.Contains(t, body, `audit_service_session_cache_items 1`)
+}
+
+func TestMetrics_RegisterQueryCache(t *testing.T) {
+	m := New()
+	queries := cache.NewLRU[int](10, time.Minute, clock.New())
+	m.RegisterQueryCache(queries)
+
+	_, _ = queries.Load(context.Background(), "stats", func(ctx context.Context) (int, error) { return 1, nil })
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := w.Body.String()
+	assert.Contains(t, body, `audit_service_query_cache_lookups_total{result="miss"} 1`)
+	assert.Contains(t, body, `audit_service_query_cache_items 1`)
+	assert.Contains(t, body, `# HELP audit_service_query_cache_items Entries currently held by the query cache.`)
+}
+
+func TestMetrics_WriteBuffer(t *testing.T) {
+	m := New()
+	m.RegisterWriteBuffer(func() int { return 7 })
+
+	m.ObserveWriteBufferFlush(100, 15*time.Millisecond, nil)
+	m.ObserveWriteBufferFlush(50, 15*time.Millisecond, errors.New("unavailable"))
+	m.ObserveWriteBufferDrop("overflow", 3)
+
+	assert.Equal(t, 100.0, testutil.ToFloat64(m.writeBufferFlushed))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.writeBufferFlushes.WithLabelValues("error")))
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.writeBufferDropped.WithLabelValues("overflow")))
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, w.Body.String(), "audit_service_write_buffer_depth 7")
+}
+
+func TestMetrics_RegisterWorkPool(t *testing.T) {
+	m := New()
+	pool := workpool.New(4)
+	m.RegisterWorkPool("export", pool)
+
+	release, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer release()
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, w.Body.String(), `audit_service_work_pool_size{pool="export"} 4`)
+	assert.Contains(t, w.Body.String(), `audit_service_work_pool_running{pool="export"} 1`)
+	assert.Contains(t, w.Body.String(), `audit_service_work_pool_queued{pool="export"} 0`)
+}
+
+func TestMetrics_Retention(t *testing.T) {
+	m := New()
+
+	m.ObserveRetentionRun(nil)
+	m.ObserveRetentionRun(errors.New("unavailable"))
+	m.ObserveRetentionPurge("view", 12)
+	m.ObserveRetentionPurge("view", 3)
+	m.ObserveRetentionArchive("view", 15)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.retentionRuns.WithLabelValues("ok")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.retentionRuns.WithLabelValues("error")))
+	assert.Equal(t, 15.0, testutil.ToFloat64(m.retentionPurged.WithLabelValues("view")))
+	assert.Equal(t, 15.0, testutil.ToFloat64(m.retentionArchived.WithLabelValues("view")))
+}
+
+func TestMetrics_ActivityRollup(t *testing.T) {
+	m := New()
+
+	m.ObserveActivityRollup(12, nil)
+	m.ObserveActivityRollup(3, errors.New("unavailable"))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.rollupRuns.WithLabelValues("ok")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.rollupRuns.WithLabelValues("error")))
+	assert.Equal(t, 15.0, testutil.ToFloat64(m.rollupWritten))
+}
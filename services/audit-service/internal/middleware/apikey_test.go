@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/apikey"
+	"audit-service/pkg/clock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestAPIKeyAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := apikey.NewStore([]apikey.Key{
+		{Name: "pptx-processor", Hash: apikey.Hash("processor-secret"), Scopes: []string{apikey.ScopeEventsWrite}},
+		{Name: "reporting", Hash: apikey.Hash("reporting-secret")},
+	})
+
+	tests := []struct {
+		name           string
+		apiKey         string
+		expectedStatus int
+		expectedUserID string
+		expectedType   string
+		expectedReason domain.AuthFailureReason
+	}{
+		{name: "no_key_passes_through", expectedStatus: http.StatusOK},
+		{name: "valid_key", apiKey: "processor-secret", expectedStatus: http.StatusOK, expectedUserID: "service:pptx-processor", expectedType: TokenTypeAPIKey},
+		{name: "invalid_key", apiKey: "wrong-secret", expectedStatus: http.StatusUnauthorized, expectedReason: domain.AuthReasonInvalidAPIKey},
+		{name: "missing_scope", apiKey: "reporting-secret", expectedStatus: http.StatusForbidden, expectedReason: domain.AuthReasonAPIKeyScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var userID, tokenType string
+			reporter := &recordingReporter{}
+			router := gin.New()
+			router.Use(AuthFailures(clock.New(), reporter))
+			router.POST("/events",
+				APIKeyAuth(store, zap.NewNop()),
+				RequireScope(apikey.ScopeEventsWrite, zap.NewNop()),
+				func(c *gin.Context) {
+					userID = GetAuthUserID(c)
+					tokenType = GetAuthTokenType(c)
+					c.Status(http.StatusOK)
+				})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/events", nil)
+			if tt.apiKey != "" {
+				req.Header.Set(APIKeyHeader, tt.apiKey)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedUserID, userID)
+			assert.Equal(t, tt.expectedType, tokenType)
+			if tt.expectedReason != "" && assert.Len(t, reporter.failures, 1) {
+				assert.Equal(t, tt.expectedReason, reporter.failures[0].Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthFailureReasonKey holds the reason set by the auth middleware that rejected a request
+const AuthFailureReasonKey = "auth_failure_reason"
+
+// AuthFailureReporter receives the requests rejected by authentication or authorization
+type AuthFailureReporter interface {
+	ReportAuthFailure(failure domain.AuthFailure)
+}
+
+// AuthFailures reports every request answered with 401 or 403 to each reporter once it has been
+// handled, whichever middleware or handler rejected it, stamped with the time of clk
+func AuthFailures(clk clock.Clock, reporters ...AuthFailureReporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		status := c.Writer.Status()
+		if status != http.StatusUnauthorized && status != http.StatusForbidden {
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		reason := GetAuthFailureReason(c)
+		if reason == "" {
+			reason = domain.DefaultAuthFailureReason(status)
+		}
+		failure := domain.AuthFailure{
+			Time:      clk.Now().UTC(),
+			Method:    c.Request.Method,
+			Route:     route,
+			Status:    status,
+			Reason:    reason,
+			ClientIP:  GetClientIP(c),
+			UserAgent: GetUserAgent(c),
+			UserID:    GetAuthUserID(c),
+			RequestID: GetRequestID(c),
+		}
+		for _, reporter := range reporters {
+			reporter.ReportAuthFailure(failure)
+		}
+	}
+}
+
+// setAuthFailureReason records why the request is rejected, for AuthFailures
+func setAuthFailureReason(c *gin.Context, reason domain.AuthFailureReason) {
+	c.Set(AuthFailureReasonKey, reason)
+}
+
+// GetAuthFailureReason retrieves the reason set by the auth middleware that rejected the request
+func GetAuthFailureReason(c *gin.Context) domain.AuthFailureReason {
+	if value, exists := c.Get(AuthFailureReasonKey); exists {
+		if reason, ok := value.(domain.AuthFailureReason); ok {
+			return reason
+		}
+	}
+	return ""
+}
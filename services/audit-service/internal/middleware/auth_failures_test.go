@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingReporter struct {
+	failures []domain.AuthFailure
+}
+
+func (r *recordingReporter) ReportAuthFailure(failure domain.AuthFailure) {
+	r.failures = append(r.failures, failure)
+}
+
+func TestAuthFailures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	clk := clock.NewFakeClock(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC))
+	reporter, other := &recordingReporter{}, &recordingReporter{}
+	router := gin.New()
+	router.Use(ClientInfo(nil, ForwardedHeaderXForwardedFor), AuthFailures(clk, reporter, other))
+	router.GET("/sessions/:sessionId/history", func(c *gin.Context) {
+		switch c.Param("sessionId") {
+		case "unauthorized":
+			WriteError(c, domain.APIErrUnauthorized)
+		case "forbidden":
+			c.Set(AuthUserIDKey, "user-1")
+			setAuthFailureReason(c, domain.AuthReasonNotAdmin)
+			WriteError(c, domain.APIErrForbidden)
+		default:
+			c.Status(http.StatusOK)
+		}
+	})
+
+	for _, path := range []string{"/sessions/ok/history", "/sessions/unauthorized/history", "/sessions/forbidden/history"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "203.0.113.7:4711"
+		req.Header.Set("User-Agent", "curl/8.0")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	require.Len(t, reporter.failures, 2)
+	unauthorized := reporter.failures[0]
+	assert.Equal(t, http.MethodGet, unauthorized.Method)
+	assert.Equal(t, "/sessions/:sessionId/history", unauthorized.Route)
+	assert.Equal(t, http.StatusUnauthorized, unauthorized.Status)
+	assert.Equal(t, "203.0.113.7", unauthorized.ClientIP)
+	assert.Equal(t, "curl/8.0", unauthorized.UserAgent)
+	assert.Empty(t, unauthorized.UserID)
+	// Failures without a reason set by the auth middleware get the default of their status
+	assert.Equal(t, domain.AuthReasonUnauthenticated, unauthorized.Reason)
+	assert.Equal(t, clk.Now(), unauthorized.Time)
+
+	assert.Equal(t, http.StatusForbidden, reporter.failures[1].Status)
+	assert.Equal(t, "user-1", reporter.failures[1].UserID)
+	assert.Equal(t, domain.AuthReasonNotAdmin, reporter.failures[1].Reason)
+
+	// Every reporter receives every failure
+	assert.Equal(t, reporter.failures, other.failures)
+}
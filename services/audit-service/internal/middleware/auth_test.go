@@ -2,25 +2,32 @@ package middleware
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"audit-service/internal/domain"
 	"audit-service/mocks"
 	"audit-service/pkg/cache"
+	"audit-service/pkg/clock"
 	"audit-service/pkg/jwt"
+	"audit-service/pkg/tenant"
 
 	"github.com/gin-gonic/gin"
 	jwtlib "github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
 // Test constants
 const (
-	testUserID = "test-user-456"
+	testUserID       = "test-user-456"
+	testShareJTI     = "share-jti-789"
+	testShareSession = "550e8400-e29b-41d4-a716-446655440000"
 )
 
 // Helper function to create test JWT claims
@@ -34,6 +41,28 @@ func createTestJWTClaims() *jwt.Claims {
 	}
 }
 
+// Helper function to create share token claims for a session
+func createTestShareClaims(sessionID string) *jwt.ShareClaims {
+	return &jwt.ShareClaims{
+		RegisteredClaims: jwtlib.RegisteredClaims{
+			ID:        testShareJTI,
+			ExpiresAt: jwtlib.NewNumericDate(time.Now().Add(1 * time.Hour)),
+		},
+		SessionID:   sessionID,
+		Permissions: []string{"VIEW"},
+	}
+}
+
+// Helper function to create an active share link
+func createTestShare(permissions ...domain.SharePermission) *domain.Share {
+	return &domain.Share{
+		ID:          "share-1",
+		SessionID:   testShareSession,
+		TokenJTI:    testShareJTI,
+		Permissions: permissions,
+	}
+}
+
 func TestAuth(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -41,18 +70,19 @@ func TestAuth(t *testing.T) {
 		name           string
 		setupRequest   func(*http.Request)
 		setupPath      string
-		setupMocks     func(*mocks.MockTokenValidator, *mocks.MockAuditRepository, *cache.TokenCache)
+		setupMocks     func(*mocks.MockTokenValidator, *mocks.MockShareTokenValidator, *mocks.MockAuditRepository, *cache.TokenCache)
 		expectedStatus int
 		expectedUserID string
 		expectedType   string
+		expectedReason domain.AuthFailureReason
 	}{
 		{
 			name:      "success_jwt_token",
-			setupPath: "/sessions/test-session/history",
+			setupPath: "/sessions/" + testShareSession + "/history",
 			setupRequest: func(req *http.Request) {
 				req.Header.Set("Authorization", "Bearer valid-jwt-token")
 			},
-			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
 				claims := createTestJWTClaims()
 				mockValidator.On("ValidateToken", mock.Anything, "valid-jwt-token").
 					Return(claims, nil)
@@ -63,15 +93,17 @@ func TestAuth(t *testing.T) {
 		},
 		{
 			name:      "success_share_token",
-			setupPath: "/sessions/test-session/history",
+			setupPath: "/sessions/" + testShareSession + "/history",
 			setupRequest: func(req *http.Request) {
 				q := req.URL.Query()
 				q.Add("share_token", "valid-share-token")
 				req.URL.RawQuery = q.Encode()
 			},
-			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
-				mockRepo.On("ValidateShareToken", mock.Anything, "valid-share-token", "test-session").
-					Return(true, nil)
+			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+				mockShare.On("ValidateShareToken", mock.Anything, "valid-share-token").
+					Return(createTestShareClaims(testShareSession), nil)
+				mockRepo.On("GetActiveShare", mock.Anything, testShareJTI, domain.SessionID(testShareSession)).
+					Return(createTestShare(domain.SharePermissionView), nil)
 			},
 			expectedStatus: 200,
 			expectedUserID: "",
@@ -79,11 +111,11 @@ func TestAuth(t *testing.T) {
 		},
 		{
 			name:      "success_jwt_cached",
-			setupPath: "/sessions/test-session/history",
+			setupPath: "/sessions/" + testShareSession + "/history",
 			setupRequest: func(req *http.Request) {
 				req.Header.Set("Authorization", "Bearer cached-jwt-token")
 			},
-			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
 				// Pre-cache the token
 				tokenCache.SetJWT("cached-jwt-token", &cache.CachedTokenInfo{
 					UserID:    testUserID,
@@ -96,18 +128,21 @@ func TestAuth(t *testing.T) {
 		},
 		{
 			name:      "success_share_token_cached",
-			setupPath: "/sessions/test-session/history",
+			setupPath: "/sessions/" + testShareSession + "/history",
 			setupRequest: func(req *http.Request) {
 				q := req.URL.Query()
 				q.Add("share_token", "cached-share-token")
 				req.URL.RawQuery = q.Encode()
 			},
-			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
-				// Pre-cache the share token
-				tokenCache.SetShareToken("cached-share-token", "test-session", &cache.CachedTokenInfo{
-					SessionID: "test-session",
+			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+				// Pre-cache the share token; its link is still looked up
+				tokenCache.SetShareToken("cached-share-token", testShareSession, &cache.CachedTokenInfo{
+					SessionID: testShareSession,
+					TokenID:   testShareJTI,
 					ExpiresAt: time.Now().Add(1 * time.Hour),
 				})
+				mockRepo.On("GetActiveShare", mock.Anything, testShareJTI, domain.SessionID(testShareSession)).
+					Return(createTestShare(domain.SharePermissionView), nil)
 			},
 			expectedStatus: 200,
 			expectedUserID: "",
@@ -119,84 +154,92 @@ func TestAuth(t *testing.T) {
 			setupRequest: func(req *http.Request) {
 				req.Header.Set("Authorization", "Bearer valid-jwt-token")
 			},
-			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
 				// No mocks needed, should fail before validation
 			},
 			expectedStatus: 401,
 			expectedUserID: "",
 			expectedType:   "",
+			expectedReason: domain.AuthReasonUnauthenticated,
 		},
 		{
 			name:      "error_missing_authorization",
-			setupPath: "/sessions/test-session/history",
+			setupPath: "/sessions/" + testShareSession + "/history",
 			setupRequest: func(req *http.Request) {
 				// No authorization header
 			},
-			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
 				// No mocks needed
 			},
 			expectedStatus: 401,
 			expectedUserID: "",
 			expectedType:   "",
+			expectedReason: domain.AuthReasonMissingToken,
 		},
 		{
 			name:      "error_invalid_bearer_format",
-			setupPath: "/sessions/test-session/history",
+			setupPath: "/sessions/" + testShareSession + "/history",
 			setupRequest: func(req *http.Request) {
 				req.Header.Set("Authorization", "InvalidFormat token")
 			},
-			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
 				// No mocks needed
 			},
 			expectedStatus: 401,
 			expectedUserID: "",
 			expectedType:   "",
+			expectedReason: domain.AuthReasonMalformedHeader,
 		},
 		{
 			name:      "error_jwt_validation_failed",
-			setupPath: "/sessions/test-session/history",
+			setupPath: "/sessions/" + testShareSession + "/history",
 			setupRequest: func(req *http.Request) {
 				req.Header.Set("Authorization", "Bearer invalid-jwt-token")
 			},
-			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
 				mockValidator.On("ValidateToken", mock.Anything, "invalid-jwt-token").
 					Return(nil, errors.New("invalid token"))
 			},
 			expectedStatus: 401,
 			expectedUserID: "",
 			expectedType:   "",
+			expectedReason: domain.AuthReasonInvalidToken,
 		},
 		{
 			name:      "error_invalid_share_token",
-			setupPath: "/sessions/test-session/history",
+			setupPath: "/sessions/" + testShareSession + "/history",
 			setupRequest: func(req *http.Request) {
 				q := req.URL.Query()
 				q.Add("share_token", "invalid-share-token")
 				req.URL.RawQuery = q.Encode()
 			},
-			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
-				mockRepo.On("ValidateShareToken", mock.Anything, "invalid-share-token", "test-session").
-					Return(false, nil)
+			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+				mockShare.On("ValidateShareToken", mock.Anything, "invalid-share-token").
+					Return(nil, errors.New("invalid signature"))
 			},
 			expectedStatus: 403,
 			expectedUserID: "",
 			expectedType:   "",
+			expectedReason: domain.AuthReasonInvalidShareToken,
 		},
 		{
 			name:      "error_share_token_validation_error",
-			setupPath: "/sessions/test-session/history",
+			setupPath: "/sessions/" + testShareSession + "/history",
 			setupRequest: func(req *http.Request) {
 				q := req.URL.Query()
 				q.Add("share_token", "error-share-token")
 				req.URL.RawQuery = q.Encode()
 			},
-			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
-				mockRepo.On("ValidateShareToken", mock.Anything, "error-share-token", "test-session").
-					Return(false, errors.New("database error"))
+			setupMocks: func(mockValidator *mocks.MockTokenValidator, mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+				mockShare.On("ValidateShareToken", mock.Anything, "error-share-token").
+					Return(createTestShareClaims(testShareSession), nil)
+				mockRepo.On("GetActiveShare", mock.Anything, testShareJTI, domain.SessionID(testShareSession)).
+					Return(nil, errors.New("database error"))
 			},
 			expectedStatus: 403,
 			expectedUserID: "",
 			expectedType:   "",
+			expectedReason: domain.AuthReasonShareNotActive,
 		},
 	}
 
@@ -204,21 +247,24 @@ func TestAuth(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockValidator := mocks.NewMockTokenValidator(t)
+			mockShare := mocks.NewMockShareTokenValidator(t)
 			mockRepo := mocks.NewMockAuditRepository(t)
 			tokenCache := cache.NewTokenCache(
 				5*time.Minute,
 				1*time.Minute,
 				10*time.Minute,
+				clock.New(),
 			)
 			logger := zap.NewNop()
 
 			// Configure mocks
-			tt.setupMocks(mockValidator, mockRepo, tokenCache)
+			tt.setupMocks(mockValidator, mockShare, mockRepo, tokenCache)
 
 			// Create router and middleware
+			reporter := &recordingReporter{}
 			router := gin.New()
-			router.Use(RequestID())
-			router.Use(Auth(mockValidator, tokenCache, mockRepo, logger))
+			router.Use(RequestID(), AuthFailures(clock.New(), reporter))
+			router.Use(Auth(mockValidator, mockShare, tokenCache, mockRepo, clock.New(), logger))
 
 			// Test endpoint
 			router.GET("/sessions/:sessionId/history", func(c *gin.Context) {
@@ -235,6 +281,9 @@ func TestAuth(t *testing.T) {
 
 			// Assert
 			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedReason != "" && assert.Len(t, reporter.failures, 1) {
+				assert.Equal(t, tt.expectedReason, reporter.failures[0].Reason)
+			}
 
 			if tt.expectedStatus == 200 {
 				// Check context values were set correctly
@@ -245,6 +294,7 @@ func TestAuth(t *testing.T) {
 
 			// Verify all expectations were met
 			mockValidator.AssertExpectations(t)
+			mockShare.AssertExpectations(t)
 			mockRepo.AssertExpectations(t)
 		})
 	}
@@ -296,6 +346,424 @@ func TestExtractBearerToken(t *testing.T) {
 	}
 }
 
+func TestOptionalAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		setupMocks     func(*mocks.MockTokenValidator)
+		expectedStatus int
+		expectedUserID string
+		expectedType   string
+	}{
+		{
+			name:           "anonymous_request_passes_through",
+			authHeader:     "",
+			setupMocks:     func(mockValidator *mocks.MockTokenValidator) {},
+			expectedStatus: http.StatusOK,
+			expectedUserID: "",
+			expectedType:   "",
+		},
+		{
+			name:       "valid_jwt_sets_user",
+			authHeader: "Bearer valid-jwt-token",
+			setupMocks: func(mockValidator *mocks.MockTokenValidator) {
+				mockValidator.On("ValidateToken", mock.Anything, "valid-jwt-token").
+					Return(createTestJWTClaims(), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedUserID: testUserID,
+			expectedType:   TokenTypeJWT,
+		},
+		{
+			name:       "invalid_jwt_rejected",
+			authHeader: "Bearer invalid-jwt-token",
+			setupMocks: func(mockValidator *mocks.MockTokenValidator) {
+				mockValidator.On("ValidateToken", mock.Anything, "invalid-jwt-token").
+					Return(nil, errors.New("invalid token"))
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "malformed_header_rejected",
+			authHeader:     "Basic abc123",
+			setupMocks:     func(mockValidator *mocks.MockTokenValidator) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockValidator := mocks.NewMockTokenValidator(t)
+			tokenCache := cache.NewTokenCache(
+				5*time.Minute,
+				1*time.Minute,
+				10*time.Minute,
+				clock.New(),
+			)
+			tt.setupMocks(mockValidator)
+
+			var userID, tokenType string
+			router := gin.New()
+			router.POST("/events", OptionalAuth(mockValidator, tokenCache, zap.NewNop()), func(c *gin.Context) {
+				userID = GetAuthUserID(c)
+				tokenType = GetAuthTokenType(c)
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/events", nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedUserID, userID)
+			assert.Equal(t, tt.expectedType, tokenType)
+		})
+	}
+}
+
+func TestWebSocketAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		query          string
+		setupMocks     func(*mocks.MockTokenValidator)
+		expectedStatus int
+	}{
+		{
+			name:           "missing_token_rejected",
+			setupMocks:     func(mockValidator *mocks.MockTokenValidator) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "bearer_header_accepted",
+			authHeader: "Bearer valid-jwt-token",
+			setupMocks: func(mockValidator *mocks.MockTokenValidator) {
+				mockValidator.On("ValidateToken", mock.Anything, "valid-jwt-token").
+					Return(createTestJWTClaims(), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "query_token_accepted",
+			query: "?access_token=valid-jwt-token",
+			setupMocks: func(mockValidator *mocks.MockTokenValidator) {
+				mockValidator.On("ValidateToken", mock.Anything, "valid-jwt-token").
+					Return(createTestJWTClaims(), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "invalid_token_rejected",
+			query: "?access_token=invalid-jwt-token",
+			setupMocks: func(mockValidator *mocks.MockTokenValidator) {
+				mockValidator.On("ValidateToken", mock.Anything, "invalid-jwt-token").
+					Return(nil, errors.New("invalid token"))
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockValidator := mocks.NewMockTokenValidator(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute, clock.New())
+			tt.setupMocks(mockValidator)
+
+			var userID string
+			router := gin.New()
+			router.GET("/ws", WebSocketAuth(mockValidator, tokenCache, zap.NewNop()), func(c *gin.Context) {
+				userID = GetAuthUserID(c)
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/ws"+tt.query, nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, testUserID, userID)
+			}
+		})
+	}
+}
+
+func TestJWTAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		setupMocks     func(*mocks.MockTokenValidator)
+		expectedStatus int
+	}{
+		{
+			name:           "missing_header_rejected",
+			setupMocks:     func(mockValidator *mocks.MockTokenValidator) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "valid_token_accepted",
+			authHeader: "Bearer valid-jwt-token",
+			setupMocks: func(mockValidator *mocks.MockTokenValidator) {
+				mockValidator.On("ValidateToken", mock.Anything, "valid-jwt-token").
+					Return(createTestJWTClaims(), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid_token_rejected",
+			authHeader: "Bearer invalid-jwt-token",
+			setupMocks: func(mockValidator *mocks.MockTokenValidator) {
+				mockValidator.On("ValidateToken", mock.Anything, "invalid-jwt-token").
+					Return(nil, errors.New("invalid token"))
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockValidator := mocks.NewMockTokenValidator(t)
+			tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute, clock.New())
+			tt.setupMocks(mockValidator)
+
+			var userID, tokenType string
+			router := gin.New()
+			router.GET("/admin", JWTAuth(mockValidator, tokenCache, zap.NewNop()), func(c *gin.Context) {
+				userID = GetAuthUserID(c)
+				tokenType = GetAuthTokenType(c)
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/admin", nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, testUserID, userID)
+				assert.Equal(t, TokenTypeJWT, tokenType)
+			}
+		})
+	}
+}
+
+func TestJWTAuth_ScopesOrganization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockValidator := mocks.NewMockTokenValidator(t)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute, clock.New())
+	claims := createTestJWTClaims()
+	claims.AppMetadata.OrganizationID = "org-1"
+	mockValidator.On("ValidateToken", mock.Anything, "org-token").Return(claims, nil).Once()
+
+	type scope struct {
+		organizationID string
+		scoped         bool
+		fromGin        string
+	}
+	var scopes []scope
+	router := gin.New()
+	router.GET("/admin", JWTAuth(mockValidator, tokenCache, zap.NewNop()), func(c *gin.Context) {
+		organizationID, scoped := tenant.FromContext(c.Request.Context())
+		scopes = append(scopes, scope{organizationID, scoped, GetAuthOrganizationID(c)})
+		c.Status(http.StatusOK)
+	})
+
+	// The second request is served from the token cache
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/admin", nil)
+		req.Header.Set("Authorization", "Bearer org-token")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	assert.Equal(t, []scope{{"org-1", true, "org-1"}, {"org-1", true, "org-1"}}, scopes)
+}
+
+func TestJWTAuth_RejectsRevokedTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockValidator := mocks.NewMockTokenValidator(t)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute, clock.New())
+	claims := createTestJWTClaims()
+	claims.SessionID = "550e8400-e29b-41d4-a716-446655440010"
+	claims.ID = "token-1"
+	mockValidator.On("ValidateToken", mock.Anything, "valid-token").Return(claims, nil).Once()
+
+	router := gin.New()
+	router.GET("/admin", JWTAuth(mockValidator, tokenCache, zap.NewNop()), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	request := func() int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/admin", nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request())
+
+	// Revocations take effect for tokens already in the cache
+	tests := []struct{ kind, target string }{
+		{cache.RevokedUser, claims.UserID},
+		{cache.RevokedSession, claims.SessionID},
+		{cache.RevokedToken, claims.ID},
+	}
+	for _, tt := range tests {
+		tokenCache.SetRevoked(tt.kind, "", tt.target, time.Minute)
+		assert.Equal(t, http.StatusUnauthorized, request(), tt.kind)
+		tokenCache.ClearRevoked(tt.kind, "", tt.target)
+		assert.Equal(t, http.StatusOK, request(), tt.kind)
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		admins         []string
+		userID         string
+		roles          []string
+		tokenType      string
+		expectedStatus int
+	}{
+		{
+			name:           "admin_allowed",
+			admins:         []string{"admin-1", testUserID},
+			userID:         testUserID,
+			tokenType:      TokenTypeJWT,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "non_admin_forbidden",
+			admins:         []string{"admin-1"},
+			userID:         testUserID,
+			tokenType:      TokenTypeJWT,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "no_admins_configured",
+			userID:         testUserID,
+			tokenType:      TokenTypeJWT,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "share_token_forbidden",
+			admins:         []string{testUserID},
+			userID:         testUserID,
+			tokenType:      TokenTypeShare,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "empty_user_forbidden",
+			admins:         []string{""},
+			tokenType:      TokenTypeJWT,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "admin_role_claim_allowed",
+			userID:         testUserID,
+			roles:          []string{"support", jwt.RoleAdmin},
+			tokenType:      TokenTypeJWT,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "other_role_forbidden",
+			userID:         testUserID,
+			roles:          []string{"support"},
+			tokenType:      TokenTypeJWT,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "admin_role_with_share_token_forbidden",
+			userID:         testUserID,
+			roles:          []string{jwt.RoleAdmin},
+			tokenType:      TokenTypeShare,
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/admin", func(c *gin.Context) {
+				c.Set(AuthUserIDKey, tt.userID)
+				c.Set(AuthRolesKey, tt.roles)
+				c.Set(AuthTokenTypeKey, tt.tokenType)
+			}, RequireAdmin(tt.admins, zap.NewNop()), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/admin", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestRequireReviewer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		admins         []string
+		roles          []string
+		tokenType      string
+		expectedStatus int
+	}{
+		{name: "reviewer_allowed", roles: []string{jwt.RoleReviewer}, tokenType: TokenTypeJWT, expectedStatus: http.StatusOK},
+		{name: "admin_role_allowed", roles: []string{jwt.RoleAdmin}, tokenType: TokenTypeJWT, expectedStatus: http.StatusOK},
+		{name: "listed_admin_allowed", admins: []string{testUserID}, tokenType: TokenTypeJWT, expectedStatus: http.StatusOK},
+		{name: "session_owner_forbidden", tokenType: TokenTypeJWT, expectedStatus: http.StatusForbidden},
+		{name: "reviewer_with_share_token_forbidden", roles: []string{jwt.RoleReviewer}, tokenType: TokenTypeShare, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reason domain.AuthFailureReason
+			router := gin.New()
+			router.GET("/annotations", func(c *gin.Context) {
+				c.Set(AuthUserIDKey, testUserID)
+				c.Set(AuthRolesKey, tt.roles)
+				c.Set(AuthTokenTypeKey, tt.tokenType)
+				c.Next()
+				reason = GetAuthFailureReason(c)
+			}, RequireReviewer(tt.admins, zap.NewNop()), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/annotations", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusForbidden {
+				assert.Equal(t, domain.AuthReasonNotReviewer, reason)
+			}
+		})
+	}
+}
+
 func TestValidateJWTToken(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -305,6 +773,7 @@ func TestValidateJWTToken(t *testing.T) {
 		setupMocks     func(*mocks.MockTokenValidator, *cache.TokenCache)
 		expectedResult bool
 		expectedUserID string
+		expectedReason domain.AuthFailureReason
 	}{
 		{
 			name:  "success_valid_token",
@@ -338,6 +807,30 @@ func TestValidateJWTToken(t *testing.T) {
 			},
 			expectedResult: false,
 			expectedUserID: "",
+			expectedReason: domain.AuthReasonInvalidToken,
+		},
+		{
+			name:  "error_expired_token",
+			token: "expired-token",
+			setupMocks: func(mockValidator *mocks.MockTokenValidator, tokenCache *cache.TokenCache) {
+				mockValidator.On("ValidateToken", mock.Anything, "expired-token").
+					Return(nil, fmt.Errorf("failed to parse token: %w", jwtlib.ErrTokenExpired))
+			},
+			expectedResult: false,
+			expectedReason: domain.AuthReasonExpiredToken,
+		},
+		{
+			name:  "error_revoked_token",
+			token: "revoked-token",
+			setupMocks: func(mockValidator *mocks.MockTokenValidator, tokenCache *cache.TokenCache) {
+				tokenCache.SetJWT("revoked-token", &cache.CachedTokenInfo{
+					UserID:    testUserID,
+					ExpiresAt: time.Now().Add(1 * time.Hour),
+				})
+				tokenCache.SetRevoked(cache.RevokedUser, "", testUserID, time.Minute)
+			},
+			expectedResult: false,
+			expectedReason: domain.AuthReasonRevokedToken,
 		},
 	}
 
@@ -349,6 +842,7 @@ func TestValidateJWTToken(t *testing.T) {
 				5*time.Minute,
 				1*time.Minute,
 				10*time.Minute,
+				clock.New(),
 			)
 			logger := zap.NewNop()
 
@@ -366,6 +860,7 @@ func TestValidateJWTToken(t *testing.T) {
 
 			// Assert
 			assert.Equal(t, tt.expectedResult, result)
+			assert.Equal(t, tt.expectedReason, GetAuthFailureReason(c))
 
 			if tt.expectedResult {
 				userID := GetAuthUserID(c)
@@ -382,69 +877,156 @@ func TestValidateShareToken(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	tests := []struct {
-		name           string
-		token          string
-		sessionID      string
-		setupMocks     func(*mocks.MockAuditRepository, *cache.TokenCache)
-		expectedResult bool
+		name                string
+		token               string
+		sessionID           string
+		setupMocks          func(*mocks.MockShareTokenValidator, *mocks.MockAuditRepository, *cache.TokenCache)
+		expectedResult      bool
+		expectedPermissions []domain.SharePermission
+		expectedTokenID     string
+		expectedExpired     bool
+		expectedReason      domain.AuthFailureReason
 	}{
 		{
 			name:      "success_valid_token",
 			token:     "valid-share-token",
-			sessionID: "test-session",
-			setupMocks: func(mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
-				mockRepo.On("ValidateShareToken", mock.Anything, "valid-share-token", "test-session").
-					Return(true, nil)
+			sessionID: testShareSession,
+			setupMocks: func(mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+				mockShare.On("ValidateShareToken", mock.Anything, "valid-share-token").
+					Return(createTestShareClaims(testShareSession), nil)
+				mockRepo.On("GetActiveShare", mock.Anything, testShareJTI, domain.SessionID(testShareSession)).
+					Return(createTestShare(domain.SharePermissionComment), nil)
 			},
-			expectedResult: true,
+			expectedResult:      true,
+			expectedPermissions: []domain.SharePermission{domain.SharePermissionComment},
+			expectedTokenID:     testShareJTI,
 		},
 		{
 			name:      "success_cached_token",
 			token:     "cached-share-token",
-			sessionID: "test-session",
-			setupMocks: func(mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
-				tokenCache.SetShareToken("cached-share-token", "test-session", &cache.CachedTokenInfo{
-					SessionID: "test-session",
+			sessionID: testShareSession,
+			setupMocks: func(mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+				tokenCache.SetShareToken("cached-share-token", testShareSession, &cache.CachedTokenInfo{
+					SessionID: testShareSession,
+					TokenID:   testShareJTI,
 					ExpiresAt: time.Now().Add(1 * time.Hour),
 				})
+				mockRepo.On("GetActiveShare", mock.Anything, testShareJTI, domain.SessionID(testShareSession)).
+					Return(createTestShare(domain.SharePermissionView), nil)
 			},
-			expectedResult: true,
+			expectedResult:      true,
+			expectedPermissions: []domain.SharePermission{domain.SharePermissionView},
+			expectedTokenID:     testShareJTI,
 		},
 		{
 			name:      "error_invalid_token",
 			token:     "invalid-share-token",
-			sessionID: "test-session",
-			setupMocks: func(mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
-				mockRepo.On("ValidateShareToken", mock.Anything, "invalid-share-token", "test-session").
-					Return(false, nil)
+			sessionID: testShareSession,
+			setupMocks: func(mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+				mockShare.On("ValidateShareToken", mock.Anything, "invalid-share-token").
+					Return(nil, errors.New("invalid signature"))
+			},
+			expectedResult: false,
+			expectedReason: domain.AuthReasonInvalidShareToken,
+		},
+		{
+			name:      "error_expired_token",
+			token:     "expired-share-token",
+			sessionID: testShareSession,
+			setupMocks: func(mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+				mockShare.On("ValidateShareToken", mock.Anything, "expired-share-token").
+					Return(nil, fmt.Errorf("failed to parse share token: %w", jwtlib.ErrTokenExpired))
+			},
+			expectedResult: false,
+			expectedReason: domain.AuthReasonShareExpired,
+		},
+		{
+			name:      "error_other_session",
+			token:     "other-share-token",
+			sessionID: testShareSession,
+			setupMocks: func(mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+				mockShare.On("ValidateShareToken", mock.Anything, "other-share-token").
+					Return(createTestShareClaims("other-session"), nil)
+			},
+			expectedResult: false,
+			expectedReason: domain.AuthReasonShareSessionMismatch,
+		},
+		{
+			name:      "error_session_not_a_uuid",
+			token:     "legacy-share-token",
+			sessionID: "session-1",
+			setupMocks: func(mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+				mockShare.On("ValidateShareToken", mock.Anything, "legacy-share-token").
+					Return(createTestShareClaims("session-1"), nil)
 			},
 			expectedResult: false,
+			expectedReason: domain.AuthReasonShareSessionMismatch,
 		},
 		{
-			name:      "error_validation_failure",
-			token:     "error-share-token",
-			sessionID: "test-session",
-			setupMocks: func(mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
-				mockRepo.On("ValidateShareToken", mock.Anything, "error-share-token", "test-session").
-					Return(false, errors.New("database error"))
+			name:      "error_revoked_share",
+			token:     "revoked-share-token",
+			sessionID: testShareSession,
+			setupMocks: func(mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+				mockShare.On("ValidateShareToken", mock.Anything, "revoked-share-token").
+					Return(createTestShareClaims(testShareSession), nil)
+				mockRepo.On("GetActiveShare", mock.Anything, testShareJTI, domain.SessionID(testShareSession)).
+					Return(nil, domain.ErrShareNotFound)
+			},
+			expectedResult: false,
+			expectedReason: domain.AuthReasonShareNotActive,
+		},
+		{
+			name:      "error_cached_token_of_revoked_share",
+			token:     "cached-share-token",
+			sessionID: testShareSession,
+			setupMocks: func(mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+				tokenCache.SetShareToken("cached-share-token", testShareSession, &cache.CachedTokenInfo{
+					SessionID: testShareSession,
+					TokenID:   testShareJTI,
+					ExpiresAt: time.Now().Add(1 * time.Hour),
+				})
+				mockRepo.On("GetActiveShare", mock.Anything, testShareJTI, domain.SessionID(testShareSession)).
+					Return(nil, domain.ErrShareNotFound)
 			},
 			expectedResult: false,
+			expectedReason: domain.AuthReasonShareNotActive,
+		},
+		{
+			name:      "error_share_expired",
+			token:     "expired-share-token",
+			sessionID: testShareSession,
+			setupMocks: func(mockShare *mocks.MockShareTokenValidator, mockRepo *mocks.MockAuditRepository, tokenCache *cache.TokenCache) {
+				share := createTestShare(domain.SharePermissionView)
+				expired := time.Now().Add(-time.Minute)
+				share.ExpiresAt = &expired
+				mockShare.On("ValidateShareToken", mock.Anything, "expired-share-token").
+					Return(createTestShareClaims(testShareSession), nil)
+				mockRepo.On("GetActiveShare", mock.Anything, testShareJTI, domain.SessionID(testShareSession)).
+					Return(share, nil)
+			},
+			expectedResult: false,
+			// Uses of expired links are recorded in the trail of the link
+			expectedTokenID: testShareJTI,
+			expectedExpired: true,
+			expectedReason:  domain.AuthReasonShareExpired,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
+			mockShare := mocks.NewMockShareTokenValidator(t)
 			mockRepo := mocks.NewMockAuditRepository(t)
 			tokenCache := cache.NewTokenCache(
 				5*time.Minute,
 				1*time.Minute,
 				10*time.Minute,
+				clock.New(),
 			)
 			logger := zap.NewNop()
 
 			// Configure mocks
-			tt.setupMocks(mockRepo, tokenCache)
+			tt.setupMocks(mockShare, mockRepo, tokenCache)
 
 			// Create gin context
 			w := httptest.NewRecorder()
@@ -453,17 +1035,91 @@ func TestValidateShareToken(t *testing.T) {
 			c.Set("request_id", "test-request-id")
 
 			// Execute
-			result := validateShareToken(c, tt.token, tt.sessionID, tokenCache, mockRepo, logger)
+			result := validateShareToken(c, tt.token, tt.sessionID, mockShare, tokenCache, mockRepo, clock.New(), logger)
 
 			// Assert
 			assert.Equal(t, tt.expectedResult, result)
+			assert.Equal(t, tt.expectedPermissions, GetSharePermissions(c))
+			assert.Equal(t, tt.expectedTokenID, GetShareTokenID(c))
+			assert.Equal(t, tt.expectedExpired, c.GetBool(AuthShareExpiredKey))
+			assert.Equal(t, tt.expectedReason, GetAuthFailureReason(c))
 
 			// Verify all expectations were met
+			mockShare.AssertExpectations(t)
 			mockRepo.AssertExpectations(t)
 		})
 	}
 }
 
+func TestValidateShareToken_ExpiresCachedToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	clk := clock.NewFakeClock(time.Now())
+	share := createTestShare(domain.SharePermissionView)
+	expiresAt := clk.Now().Add(time.Minute)
+	share.ExpiresAt = &expiresAt
+
+	mockShare := mocks.NewMockShareTokenValidator(t)
+	mockShare.On("ValidateShareToken", mock.Anything, "share-token").
+		Return(createTestShareClaims(testShareSession), nil).Once()
+	mockRepo := mocks.NewMockAuditRepository(t)
+	mockRepo.On("GetActiveShare", mock.Anything, testShareJTI, domain.SessionID(testShareSession)).Return(share, nil)
+	tokenCache := cache.NewTokenCache(5*time.Minute, 10*time.Minute, 10*time.Minute, clk)
+
+	validate := func() (bool, *gin.Context) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request, _ = http.NewRequest("GET", "/", nil)
+		return validateShareToken(c, "share-token", testShareSession, mockShare, tokenCache, mockRepo, clk, zap.NewNop()), c
+	}
+
+	ok, _ := validate()
+	require.True(t, ok)
+
+	// The link expires while its token is still cached
+	clk.Advance(time.Minute)
+	ok, c := validate()
+	assert.False(t, ok)
+	assert.True(t, c.GetBool(AuthShareExpiredKey))
+	assert.Equal(t, domain.AuthReasonShareExpired, GetAuthFailureReason(c))
+}
+
+func TestRequireSharePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		tokenType      string
+		permissions    []domain.SharePermission
+		expectedStatus int
+	}{
+		{name: "jwt_passes", tokenType: TokenTypeJWT, expectedStatus: http.StatusOK},
+		{name: "view_share_passes", tokenType: TokenTypeShare, permissions: []domain.SharePermission{domain.SharePermissionView}, expectedStatus: http.StatusOK},
+		{name: "comment_share_passes", tokenType: TokenTypeShare, permissions: []domain.SharePermission{domain.SharePermissionComment}, expectedStatus: http.StatusOK},
+		{name: "unknown_role_forbidden", tokenType: TokenTypeShare, permissions: []domain.SharePermission{"EDIT"}, expectedStatus: http.StatusForbidden},
+		{name: "no_permissions_forbidden", tokenType: TokenTypeShare, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/sessions/:sessionId/history", func(c *gin.Context) {
+				c.Set(AuthTokenTypeKey, tt.tokenType)
+				if tt.permissions != nil {
+					c.Set(AuthSharePermissionsKey, tt.permissions)
+				}
+			}, RequireSharePermission(domain.SharePermissionView, zap.NewNop()), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/sessions/"+testShareSession+"/history", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
 func TestGetAuthUserID(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
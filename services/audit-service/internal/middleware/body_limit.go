@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"audit-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimits bounds the size of request bodies, overall and per route
+type BodyLimits struct {
+	// Default applies to routes without their own limit; 0 is unlimited
+	Default int64
+	// Routes maps a route pattern, optionally prefixed with the method ("POST /api/v1/events/batch"), to a limit
+	Routes map[string]int64
+}
+
+// limit returns the body limit of a route, preferring a method-specific entry
+func (l BodyLimits) limit(method, route string) int64 {
+	if limit, ok := l.Routes[method+" "+route]; ok {
+		return limit
+	}
+	if limit, ok := l.Routes[route]; ok {
+		return limit
+	}
+	return l.Default
+}
+
+// BodyLimit middleware rejects request bodies over the limit of their route with
+// 413 payload_too_large. A body declaring a larger Content-Length is rejected before it is read;
+// other bodies fail once the handler reads past the limit, see BodyTooLarge.
+func BodyLimit(limits BodyLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := limits.limit(c.Request.Method, c.FullPath())
+		if limit <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			WriteError(c, domain.ToAPIError(domain.NewPayloadTooLargeError(domain.PayloadBody, c.Request.ContentLength, limit)))
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// BodyTooLarge returns the problem of a request body read past the limit set by BodyLimit, and
+// whether err is caused by one
+func BodyTooLarge(err error) (*domain.APIError, bool) {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return nil, false
+	}
+	return domain.ToAPIError(domain.NewPayloadTooLargeError(domain.PayloadBody, 0, maxBytesErr.Limit)), true
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"audit-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyLimits_Limit(t *testing.T) {
+	limits := BodyLimits{Default: 100, Routes: map[string]int64{
+		"/batch":      1000,
+		"POST /batch": 2000,
+		"/import":     0,
+	}}
+
+	assert.Equal(t, int64(100), limits.limit("POST", "/events"))
+	assert.Equal(t, int64(2000), limits.limit("POST", "/batch"))
+	assert.Equal(t, int64(1000), limits.limit("PUT", "/batch"))
+	assert.Equal(t, int64(0), limits.limit("POST", "/import"))
+}
+
+func TestBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		streamed       bool
+		expectedStatus int
+		expectedLimit  *domain.PayloadLimit
+	}{
+		{name: "within limit", path: "/events", body: strings.Repeat("a", 10), expectedStatus: http.StatusOK},
+		{name: "at limit", path: "/events", body: strings.Repeat("a", 16), expectedStatus: http.StatusOK},
+		{
+			name: "declared length over limit", path: "/events", body: strings.Repeat("a", 20),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedLimit:  &domain.PayloadLimit{Field: "body", LimitBytes: 16, SizeBytes: 20, ExcessBytes: 4},
+		},
+		{
+			name: "streamed body over limit", path: "/events", body: strings.Repeat("a", 20), streamed: true,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedLimit:  &domain.PayloadLimit{Field: "body", LimitBytes: 16},
+		},
+		{name: "route limit", path: "/batch", body: strings.Repeat("a", 20), expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(BodyLimit(BodyLimits{Default: 16, Routes: map[string]int64{"POST /batch": 64}}))
+			handler := func(c *gin.Context) {
+				if _, err := io.ReadAll(c.Request.Body); err != nil {
+					apiErr, ok := BodyTooLarge(err)
+					require.True(t, ok)
+					WriteError(c, apiErr)
+					return
+				}
+				c.Status(http.StatusOK)
+			}
+			router.POST("/events", handler)
+			router.POST("/batch", handler)
+
+			var body io.Reader = strings.NewReader(tt.body)
+			if tt.streamed {
+				body = io.MultiReader(body)
+			}
+			req := httptest.NewRequest("POST", tt.path, body)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedLimit != nil {
+				var problem domain.APIError
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+				assert.Equal(t, "payload_too_large", problem.Code)
+				assert.Equal(t, tt.expectedLimit, problem.Limit)
+			}
+		})
+	}
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	_, ok := BodyTooLarge(io.ErrUnexpectedEOF)
+	assert.False(t, ok)
+
+	apiErr, ok := BodyTooLarge(&http.MaxBytesError{Limit: 1024})
+	require.True(t, ok)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, apiErr.Status)
+	assert.Equal(t, &domain.PayloadLimit{Field: "body", LimitBytes: 1024}, apiErr.Limit)
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ClientIPKey  = "client_ip"
+	UserAgentKey = "client_user_agent"
+
+	// maxUserAgentLength bounds the User-Agent stored with audit events
+	maxUserAgentLength = 512
+)
+
+// Forwarding headers the client IP can be read from behind trusted proxies
+const (
+	ForwardedHeaderXForwardedFor = "x-forwarded-for"
+	ForwardedHeaderForwarded     = "forwarded"
+)
+
+// ClientInfo middleware records the client IP and User-Agent of each request.
+// forwardedHeader names the one header, X-Forwarded-For or Forwarded (RFC 7239), that the
+// trusted proxies append to; it is only honored when the connection comes from a trusted proxy,
+// and the other header, which they pass through untouched, is ignored.
+func ClientInfo(trustedProxies []netip.Prefix, forwardedHeader string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hops := forwardedHops(c.Request.Header, forwardedHeader)
+		c.Set(ClientIPKey, resolveClientIP(c.Request.RemoteAddr, hops, trustedProxies))
+
+		userAgent := c.Request.UserAgent()
+		if len(userAgent) > maxUserAgentLength {
+			userAgent = userAgent[:maxUserAgentLength]
+		}
+		c.Set(UserAgentKey, userAgent)
+
+		c.Next()
+	}
+}
+
+// GetClientIP retrieves the client IP from context
+func GetClientIP(c *gin.Context) string {
+	return c.GetString(ClientIPKey)
+}
+
+// GetUserAgent retrieves the client User-Agent from context
+func GetUserAgent(c *gin.Context) string {
+	return c.GetString(UserAgentKey)
+}
+
+// forwardedHops returns the addresses a request was forwarded for in forwardedHeader, client
+// first
+func forwardedHops(header http.Header, forwardedHeader string) []string {
+	if forwardedHeader == ForwardedHeaderForwarded {
+		var hops []string
+		for _, value := range header.Values("Forwarded") {
+			for _, element := range strings.Split(value, ",") {
+				hops = append(hops, forwardedFor(element))
+			}
+		}
+		return hops
+	}
+
+	var hops []string
+	for _, value := range header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	return hops
+}
+
+// forwardedFor returns the address of the for parameter of a Forwarded element, without
+// brackets and port. Obfuscated identifiers such as "unknown" or "_hidden" are returned as
+// they are and do not parse as an address.
+func forwardedFor(element string) string {
+	for _, pair := range strings.Split(element, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || !strings.EqualFold(key, "for") {
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		if strings.HasPrefix(value, "[") {
+			// [2001:db8::17]:4711
+			if end := strings.Index(value, "]"); end > 0 {
+				return value[1:end]
+			}
+			return value
+		}
+		if host, _, err := net.SplitHostPort(value); err == nil {
+			return host
+		}
+		return value
+	}
+	return ""
+}
+
+// resolveClientIP walks the forwarded addresses from the nearest hop and returns the first
+// address that is not a trusted proxy
+func resolveClientIP(remoteAddr string, hops []string, trustedProxies []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	client, err := netip.ParseAddr(host)
+	if err != nil {
+		return host
+	}
+	client = client.Unmap()
+
+	if !isTrustedProxy(client, trustedProxies) {
+		return client.String()
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// Anything left of a malformed entry cannot be trusted
+			break
+		}
+		client = hop.Unmap()
+		if !isTrustedProxy(client, trustedProxies) {
+			break
+		}
+	}
+
+	return client.String()
+}
+
+// isTrustedProxy reports whether addr belongs to one of the trusted proxy ranges
+func isTrustedProxy(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientInfo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	trusted := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("127.0.0.1/32"),
+	}
+
+	tests := []struct {
+		name          string
+		remoteAddr    string
+		forwardedFor  []string
+		forwarded     []string
+		header        string
+		expectedIP    string
+		trustedRanges []netip.Prefix
+	}{
+		{
+			name:          "direct connection",
+			remoteAddr:    "203.0.113.7:51234",
+			expectedIP:    "203.0.113.7",
+			trustedRanges: trusted,
+		},
+		{
+			name:          "forwarded header from untrusted peer is ignored",
+			remoteAddr:    "203.0.113.7:51234",
+			forwardedFor:  []string{"198.51.100.1"},
+			expectedIP:    "203.0.113.7",
+			trustedRanges: trusted,
+		},
+		{
+			name:          "client behind trusted proxy",
+			remoteAddr:    "10.1.2.3:443",
+			forwardedFor:  []string{"198.51.100.1"},
+			expectedIP:    "198.51.100.1",
+			trustedRanges: trusted,
+		},
+		{
+			name:          "spoofed leftmost entry is skipped",
+			remoteAddr:    "10.1.2.3:443",
+			forwardedFor:  []string{"1.2.3.4, 198.51.100.1, 10.4.5.6"},
+			expectedIP:    "198.51.100.1",
+			trustedRanges: trusted,
+		},
+		{
+			name:          "multiple headers",
+			remoteAddr:    "127.0.0.1:8080",
+			forwardedFor:  []string{"198.51.100.1", "10.4.5.6"},
+			expectedIP:    "198.51.100.1",
+			trustedRanges: trusted,
+		},
+		{
+			name:          "malformed entry stops the walk",
+			remoteAddr:    "10.1.2.3:443",
+			forwardedFor:  []string{"198.51.100.1, garbage, 10.4.5.6"},
+			expectedIP:    "10.4.5.6",
+			trustedRanges: trusted,
+		},
+		{
+			name:         "no trusted proxies configured",
+			remoteAddr:   "10.1.2.3:443",
+			forwardedFor: []string{"198.51.100.1"},
+			expectedIP:   "10.1.2.3",
+		},
+		{
+			name:          "forwarded header",
+			remoteAddr:    "10.1.2.3:443",
+			forwarded:     []string{`for=198.51.100.1;proto=https;by=10.1.2.3`},
+			header:        ForwardedHeaderForwarded,
+			expectedIP:    "198.51.100.1",
+			trustedRanges: trusted,
+		},
+		{
+			name:          "x-forwarded-for is ignored when forwarded is trusted",
+			remoteAddr:    "10.1.2.3:443",
+			forwardedFor:  []string{"1.2.3.4"},
+			forwarded:     []string{`for=198.51.100.1`},
+			header:        ForwardedHeaderForwarded,
+			expectedIP:    "198.51.100.1",
+			trustedRanges: trusted,
+		},
+		{
+			name:          "untrusted forwarded header passed through the proxy is ignored",
+			remoteAddr:    "10.1.2.3:443",
+			forwarded:     []string{`for=6.6.6.6`},
+			forwardedFor:  []string{"198.51.100.1"},
+			expectedIP:    "198.51.100.1",
+			trustedRanges: trusted,
+		},
+		{
+			name:          "forwarded header with quoted ipv6 and ports",
+			remoteAddr:    "10.1.2.3:443",
+			forwarded:     []string{`For="[2001:db8:cafe::17]:4711", for=10.4.5.6:8080`},
+			header:        ForwardedHeaderForwarded,
+			expectedIP:    "2001:db8:cafe::17",
+			trustedRanges: trusted,
+		},
+		{
+			name:          "spoofed forwarded element is skipped",
+			remoteAddr:    "10.1.2.3:443",
+			forwarded:     []string{"for=1.2.3.4", "for=198.51.100.1;proto=https"},
+			header:        ForwardedHeaderForwarded,
+			expectedIP:    "198.51.100.1",
+			trustedRanges: trusted,
+		},
+		{
+			name:          "obfuscated forwarded identifier stops the walk",
+			remoteAddr:    "10.1.2.3:443",
+			forwarded:     []string{"for=198.51.100.1, for=_hidden, for=10.4.5.6"},
+			header:        ForwardedHeaderForwarded,
+			expectedIP:    "10.4.5.6",
+			trustedRanges: trusted,
+		},
+		{
+			name:          "forwarded header of untrusted peer is ignored",
+			remoteAddr:    "203.0.113.7:51234",
+			forwarded:     []string{"for=198.51.100.1"},
+			header:        ForwardedHeaderForwarded,
+			expectedIP:    "203.0.113.7",
+			trustedRanges: trusted,
+		},
+		{
+			name:          "ipv6 peer",
+			remoteAddr:    "[2001:db8::1]:443",
+			expectedIP:    "2001:db8::1",
+			trustedRanges: trusted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotIP, gotUA string
+			router := gin.New()
+			header := tt.header
+			if header == "" {
+				header = ForwardedHeaderXForwardedFor
+			}
+			router.Use(ClientInfo(tt.trustedRanges, header))
+			router.GET("/test", func(c *gin.Context) {
+				gotIP = GetClientIP(c)
+				gotUA = GetUserAgent(c)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("User-Agent", "Mozilla/5.0")
+			for _, value := range tt.forwardedFor {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			for _, value := range tt.forwarded {
+				req.Header.Add("Forwarded", value)
+			}
+
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.expectedIP, gotIP)
+			assert.Equal(t, "Mozilla/5.0", gotUA)
+		})
+	}
+}
+
+func TestClientInfo_TruncatesUserAgent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var gotUA string
+	router := gin.New()
+	router.Use(ClientInfo(nil, ForwardedHeaderXForwardedFor))
+	router.GET("/test", func(c *gin.Context) {
+		gotUA = GetUserAgent(c)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("User-Agent", strings.Repeat("a", 2000))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Len(t, gotUA, maxUserAgentLength)
+}
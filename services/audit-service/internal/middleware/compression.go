@@ -0,0 +1,289 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"audit-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Content codings supported in both directions. "deflate" is the zlib format of RFC 9110.
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+)
+
+// MaxDecompressedBodyBytes bounds a decompressed request body, so a small compressed
+// upload cannot expand without limit
+const MaxDecompressedBodyBytes = 64 << 20
+
+// errDecompressedBodyTooLarge is returned by reads past MaxDecompressedBodyBytes
+var errDecompressedBodyTooLarge = fmt.Errorf("decompressed request body exceeds %d bytes", MaxDecompressedBodyBytes)
+
+var (
+	gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(io.Discard) }}
+	zlibWriters = sync.Pool{New: func() any { return zlib.NewWriter(io.Discard) }}
+)
+
+// Compress middleware compresses responses with gzip or deflate when the client accepts it.
+// Responses smaller than minSize, event streams and responses that are already encoded are sent as-is.
+func Compress(minSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minSize: minSize}
+		c.Writer = cw
+		defer func() {
+			cw.finish()
+			c.Writer = cw.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
+
+// DecompressRequest middleware transparently inflates gzip and deflate request bodies
+func DecompressRequest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+		if encoding == "" || encoding == "identity" {
+			c.Next()
+			return
+		}
+
+		var (
+			reader io.ReadCloser
+			err    error
+		)
+		switch encoding {
+		case encodingGzip, "x-gzip":
+			reader, err = gzip.NewReader(c.Request.Body)
+		case encodingDeflate:
+			reader, err = zlib.NewReader(c.Request.Body)
+		default:
+			WriteError(c, domain.NewAPIError("unsupported_content_encoding",
+				"Content-Encoding must be gzip or deflate", http.StatusUnsupportedMediaType))
+			c.Abort()
+			return
+		}
+		if err != nil {
+			WriteError(c, domain.NewAPIError("invalid_request", "Invalid compressed request body: "+err.Error(), http.StatusBadRequest))
+			c.Abort()
+			return
+		}
+		defer reader.Close()
+
+		c.Request.Body = &limitedBody{reader: reader, remaining: MaxDecompressedBodyBytes}
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Del("Content-Length")
+		c.Request.ContentLength = -1
+
+		c.Next()
+	}
+}
+
+// limitedBody fails reads once more than the allowed number of bytes were decompressed
+type limitedBody struct {
+	reader    io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		// One more byte tells a body of exactly the limit apart from a larger one
+		var probe [1]byte
+		if n, _ := b.reader.Read(probe[:]); n > 0 {
+			return 0, errDecompressedBodyTooLarge
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.reader.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.reader.Close()
+}
+
+// negotiateEncoding picks gzip or deflate from an Accept-Encoding header, preferring gzip on
+// equal weights. It returns an empty string when neither is acceptable.
+func negotiateEncoding(header string) string {
+	if header == "" {
+		return ""
+	}
+
+	weights := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		weight := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			weight = parsed
+		}
+		weights[coding] = weight
+	}
+
+	best, bestWeight := "", 0.0
+	for _, coding := range []string{encodingGzip, encodingDeflate} {
+		weight, ok := weights[coding]
+		if !ok {
+			weight, ok = weights["*"]
+		}
+		if ok && weight > bestWeight {
+			best, bestWeight = coding, weight
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the start of a response until it knows whether compressing it pays off
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+
+	buf     []byte
+	decided bool
+	encoder io.WriteCloser
+}
+
+// Write buffers until minSize bytes were written, then switches to compressed or plain output
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		if !w.compressible() {
+			w.decide(false)
+		} else if len(w.buf)+len(data) < w.minSize {
+			w.buf = append(w.buf, data...)
+			return len(data), nil
+		} else {
+			w.decide(true)
+		}
+	}
+
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString implements io.StringWriter, which gin uses to render some responses
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow sends the headers of a response without a body uncompressed
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		w.decide(false)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush starts compressing a streamed response so every flushed chunk reaches the client
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(w.compressible())
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// finish writes a short buffered response and completes the compressed stream
+func (w *compressWriter) finish() {
+	if !w.decided {
+		if len(w.buf) == 0 {
+			// Nothing was written; leave the response to gin
+			return
+		}
+		w.decide(false)
+	}
+	if w.encoder != nil {
+		w.encoder.Close()
+		w.release()
+	}
+}
+
+// compressible reports whether the response may be compressed at all
+func (w *compressWriter) compressible() bool {
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	// Server-sent events are flushed message by message and must not be delayed by the encoder
+	if strings.HasPrefix(header.Get("Content-Type"), "text/event-stream") {
+		return false
+	}
+	status := w.Status()
+	return status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+// decide fixes the encoding of the response and writes out what was buffered
+func (w *compressWriter) decide(compress bool) {
+	w.decided = true
+	if compress {
+		header := w.Header()
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		// A weak validator stays valid for the compressed representation
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+		w.encoder = w.acquire()
+	}
+
+	if len(w.buf) > 0 {
+		buf := w.buf
+		w.buf = nil
+		if w.encoder != nil {
+			w.encoder.Write(buf)
+		} else {
+			w.ResponseWriter.Write(buf)
+		}
+	}
+}
+
+// acquire takes a pooled encoder writing to the response
+func (w *compressWriter) acquire() io.WriteCloser {
+	if w.encoding == encodingGzip {
+		gz := gzipWriters.Get().(*gzip.Writer)
+		gz.Reset(w.ResponseWriter)
+		return gz
+	}
+	zw := zlibWriters.Get().(*zlib.Writer)
+	zw.Reset(w.ResponseWriter)
+	return zw
+}
+
+// release returns the encoder to its pool
+func (w *compressWriter) release() {
+	switch encoder := w.encoder.(type) {
+	case *gzip.Writer:
+		gzipWriters.Put(encoder)
+	case *zlib.Writer:
+		zlibWriters.Put(encoder)
+	}
+	w.encoder = nil
+}
@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{header: "", expected: ""},
+		{header: "gzip", expected: "gzip"},
+		{header: "gzip, deflate, br", expected: "gzip"},
+		{header: "deflate", expected: "deflate"},
+		{header: "gzip;q=0.5, deflate", expected: "deflate"},
+		{header: "gzip;q=0, deflate;q=0", expected: ""},
+		{header: "*", expected: "gzip"},
+		{header: "br, identity", expected: ""},
+		{header: "GZIP ; q=0.8", expected: "gzip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.expected, negotiateEncoding(tt.header))
+		})
+	}
+}
+
+func performCompressed(handler gin.HandlerFunc, acceptEncoding string) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Use(Compress(1024))
+	router.GET("/test", handler)
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCompress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	large := strings.Repeat(`{"type":"edit","sessionId":"550e8400-e29b-41d4-a716-446655440000"}`, 100)
+	small := `{"status":"ok"}`
+
+	tests := []struct {
+		name             string
+		acceptEncoding   string
+		handler          gin.HandlerFunc
+		expectedEncoding string
+		expectedBody     string
+	}{
+		{
+			name:             "gzips large responses",
+			acceptEncoding:   "gzip, deflate",
+			handler:          func(c *gin.Context) { c.String(http.StatusOK, large) },
+			expectedEncoding: "gzip",
+			expectedBody:     large,
+		},
+		{
+			name:             "deflates large responses",
+			acceptEncoding:   "deflate",
+			handler:          func(c *gin.Context) { c.String(http.StatusOK, large) },
+			expectedEncoding: "deflate",
+			expectedBody:     large,
+		},
+		{
+			name:           "small responses stay plain",
+			acceptEncoding: "gzip",
+			handler:        func(c *gin.Context) { c.String(http.StatusOK, small) },
+			expectedBody:   small,
+		},
+		{
+			name:         "clients without Accept-Encoding get plain responses",
+			handler:      func(c *gin.Context) { c.String(http.StatusOK, large) },
+			expectedBody: large,
+		},
+		{
+			name:           "event streams are never compressed",
+			acceptEncoding: "gzip",
+			handler: func(c *gin.Context) {
+				c.Header("Content-Type", "text/event-stream")
+				c.Writer.Flush()
+				c.Writer.WriteString(large)
+			},
+			expectedBody: large,
+		},
+		{
+			name:             "flushed chunks are compressed as they go",
+			acceptEncoding:   "gzip",
+			expectedEncoding: "gzip",
+			handler: func(c *gin.Context) {
+				c.Header("Content-Type", "text/csv")
+				c.Writer.WriteString("id,type\n")
+				c.Writer.Flush()
+				c.Writer.WriteString("1,edit\n")
+			},
+			expectedBody: "id,type\n1,edit\n",
+		},
+		{
+			name:           "bodyless responses pass through",
+			acceptEncoding: "gzip",
+			handler:        func(c *gin.Context) { c.Status(http.StatusNotModified) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performCompressed(tt.handler, tt.acceptEncoding)
+
+			assert.Equal(t, tt.expectedEncoding, w.Header().Get("Content-Encoding"))
+			assert.Contains(t, w.Header().Values("Vary"), "Accept-Encoding")
+
+			var body io.Reader = w.Body
+			switch tt.expectedEncoding {
+			case "gzip":
+				reader, err := gzip.NewReader(w.Body)
+				require.NoError(t, err)
+				body = reader
+			case "deflate":
+				reader, err := zlib.NewReader(w.Body)
+				require.NoError(t, err)
+				body = reader
+			}
+			decoded, err := io.ReadAll(body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedBody, string(decoded))
+		})
+	}
+}
+
+func TestDecompressRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	payload := `[{"sessionId":"test-session-1","type":"edit"}]`
+	var gzipped, deflated bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	gz.Write([]byte(payload))
+	gz.Close()
+	zw := zlib.NewWriter(&deflated)
+	zw.Write([]byte(payload))
+	zw.Close()
+
+	tests := []struct {
+		name           string
+		encoding       string
+		body           []byte
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "gzip", encoding: "gzip", body: gzipped.Bytes(), expectedStatus: http.StatusOK, expectedBody: payload},
+		{name: "deflate", encoding: "deflate", body: deflated.Bytes(), expectedStatus: http.StatusOK, expectedBody: payload},
+		{name: "uncompressed", body: []byte(payload), expectedStatus: http.StatusOK, expectedBody: payload},
+		{name: "corrupt gzip", encoding: "gzip", body: []byte(payload), expectedStatus: http.StatusBadRequest},
+		{name: "unsupported coding", encoding: "br", body: []byte(payload), expectedStatus: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(DecompressRequest())
+			router.POST("/test", func(c *gin.Context) {
+				body, err := io.ReadAll(c.Request.Body)
+				require.NoError(t, err)
+				assert.Empty(t, c.GetHeader("Content-Encoding"))
+				c.String(http.StatusOK, string(body))
+			})
+
+			req := httptest.NewRequest("POST", "/test", bytes.NewReader(tt.body))
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestLimitedBody(t *testing.T) {
+	exact := &limitedBody{reader: io.NopCloser(strings.NewReader("12345")), remaining: 5}
+	data, err := io.ReadAll(exact)
+	require.NoError(t, err)
+	assert.Equal(t, "12345", string(data))
+
+	over := &limitedBody{reader: io.NopCloser(strings.NewReader("123456")), remaining: 5}
+	_, err = io.ReadAll(over)
+	assert.ErrorIs(t, err, errDecompressedBodyTooLarge)
+}
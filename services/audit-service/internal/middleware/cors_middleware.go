@@ -2,16 +2,39 @@ package middleware
 
 import (
 	"net/http"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+// CORSOrigin holds the origin allowed to call the API, which can be changed while serving
+type CORSOrigin struct {
+	value atomic.Pointer[string]
+}
+
+// NewCORSOrigin creates a holder allowing origin
+func NewCORSOrigin(origin string) *CORSOrigin {
+	o := &CORSOrigin{}
+	o.Set(origin)
+	return o
+}
+
+// Get returns the allowed origin
+func (o *CORSOrigin) Get() string {
+	return *o.value.Load()
+}
+
+// Set changes the allowed origin for subsequent requests
+func (o *CORSOrigin) Set(origin string) {
+	o.value.Store(&origin)
+}
+
 // CORSMiddleware adds CORS headers to allow cross-origin requests
-func CORSMiddleware(corsOrigin string, logger *zap.Logger) gin.HandlerFunc {
+func CORSMiddleware(corsOrigin *CORSOrigin, logger *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Use the provided CORS origin or default to localhost:3000
-		allowedOrigin := corsOrigin
+		allowedOrigin := corsOrigin.Get()
 		if allowedOrigin == "" {
 			allowedOrigin = "http://localhost:3000" // Default to Next.js development server
 		}
@@ -43,7 +66,8 @@ func CORSMiddleware(corsOrigin string, logger *zap.Logger) gin.HandlerFunc {
 
 		// Always set these headers
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Request-ID")
+		c.Header("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Request-ID, Idempotency-Key, If-None-Match, If-Match, Consistency-Token")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID, ETag")
 		c.Header("Access-Control-Allow-Credentials", "true")
 		c.Header("Vary", "Origin") // Important for caching
 
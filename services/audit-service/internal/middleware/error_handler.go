@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 
 	"audit-service/internal/domain"
@@ -28,14 +29,14 @@ func ErrorHandler(logger *zap.Logger) gin.HandlerFunc {
 			)
 
 			// Check if it's already an API error
-			if apiErr, ok := err.Err.(*domain.APIError); ok {
-				c.JSON(apiErr.Status, apiErr)
+			var apiErr *domain.APIError
+			if errors.As(err.Err, &apiErr) {
+				WriteError(c, apiErr)
 				return
 			}
 
 			// Convert to API error
-			apiErr := domain.ToAPIError(err.Err)
-			c.JSON(apiErr.Status, apiErr)
+			WriteError(c, domain.ToAPIError(err.Err))
 		} else {
 			// Log server errors even when no errors in c.Errors
 			status := c.Writer.Status()
@@ -51,16 +52,46 @@ func ErrorHandler(logger *zap.Logger) gin.HandlerFunc {
 	}
 }
 
+// ProblemContentType is the media type of error responses (RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// Problem returns an API error as the problem document of the current request, carrying the
+// request ID so a client can quote it when reporting the failure. The shared API errors are
+// copied rather than modified.
+func Problem(c *gin.Context, apiErr *domain.APIError) *domain.APIError {
+	return apiErr.ForRequest(c.Request.URL.Path, GetRequestID(c))
+}
+
+// WriteError writes an API error as a problem document
+func WriteError(c *gin.Context, apiErr *domain.APIError) {
+	WriteProblem(c, apiErr.Status, Problem(c, apiErr))
+}
+
+// WriteProblem writes a problem document, such as one extending an API error with members of
+// its own
+func WriteProblem(c *gin.Context, status int, problem interface{}) {
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(status, problem)
+}
+
+// Recovery returns a middleware that turns panics into internal server error problems
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, _ any) {
+		WriteError(c, domain.APIErrInternalServer)
+		c.Abort()
+	})
+}
+
 // HandleNotFound returns a handler for 404 errors
 func HandleNotFound() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.JSON(http.StatusNotFound, domain.NewAPIError("not_found", "The requested resource was not found", http.StatusNotFound))
+		WriteError(c, domain.NewAPIError("not_found", "The requested resource was not found", http.StatusNotFound))
 	}
 }
 
 // HandleMethodNotAllowed returns a handler for 405 errors
 func HandleMethodNotAllowed() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.JSON(http.StatusMethodNotAllowed, domain.NewAPIError("method_not_allowed", "HTTP method not allowed for this resource", http.StatusMethodNotAllowed))
+		WriteError(c, domain.NewAPIError("method_not_allowed", "HTTP method not allowed for this resource", http.StatusMethodNotAllowed))
 	}
 }
@@ -41,60 +41,64 @@ func TestErrorHandler(t *testing.T) {
 		{
 			name: "handles_client_error_400",
 			setupHandler: func(c *gin.Context) {
-				c.JSON(400, domain.APIErrInvalidRequest)
+				WriteError(c, domain.APIErrInvalidRequest)
 			},
 			expectedStatus: 400,
 			expectedBody: map[string]interface{}{
-				"error":   "invalid_request",
-				"message": "Invalid request parameters",
+				"code":   "invalid_request",
+				"detail": "Invalid request parameters",
 			},
 			expectLogs: false, // Client errors shouldn't be logged as server errors
 		},
 		{
 			name: "handles_unauthorized_401",
 			setupHandler: func(c *gin.Context) {
-				c.JSON(401, domain.APIErrUnauthorized)
+				WriteError(c, domain.APIErrUnauthorized)
 			},
 			expectedStatus: 401,
 			expectedBody: map[string]interface{}{
-				"error":   "unauthorized",
-				"message": "Authentication required",
+				"code":   "unauthorized",
+				"detail": "Authentication required",
 			},
 			expectLogs: false,
 		},
 		{
 			name: "handles_forbidden_403",
 			setupHandler: func(c *gin.Context) {
-				c.JSON(403, domain.APIErrForbidden)
+				WriteError(c, domain.APIErrForbidden)
 			},
 			expectedStatus: 403,
 			expectedBody: map[string]interface{}{
-				"error":   "forbidden",
-				"message": "Access denied to this resource",
+				"code":   "forbidden",
+				"detail": "Access denied to this resource",
 			},
 			expectLogs: false,
 		},
 		{
 			name: "handles_not_found_404",
 			setupHandler: func(c *gin.Context) {
-				c.JSON(404, domain.APIErrNotFound)
+				WriteError(c, domain.APIErrNotFound)
 			},
 			expectedStatus: 404,
 			expectedBody: map[string]interface{}{
-				"error":   "not_found",
-				"message": "The requested resource was not found",
+				"type":     "urn:audit-service:problem:not_found",
+				"title":    "Not Found",
+				"status":   float64(404),
+				"code":     "not_found",
+				"detail":   "The requested resource was not found",
+				"instance": "/test",
 			},
 			expectLogs: false,
 		},
 		{
 			name: "logs_server_error_500",
 			setupHandler: func(c *gin.Context) {
-				c.JSON(500, domain.APIErrInternalServer)
+				WriteError(c, domain.APIErrInternalServer)
 			},
 			expectedStatus: 500,
 			expectedBody: map[string]interface{}{
-				"error":   "internal_server_error",
-				"message": "An internal server error occurred",
+				"code":   "internal_server_error",
+				"detail": "An internal server error occurred",
 			},
 			expectLogs:     true,
 			expectedLogMsg: "server error response",
@@ -210,7 +214,7 @@ func TestErrorHandler_WithAbort(t *testing.T) {
 
 	// Middleware that aborts with error
 	router.Use(func(c *gin.Context) {
-		c.JSON(401, domain.APIErrUnauthorized)
+		WriteError(c, domain.APIErrUnauthorized)
 		c.Abort()
 	})
 
@@ -230,7 +234,7 @@ func TestErrorHandler_WithAbort(t *testing.T) {
 	var responseBody map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &responseBody)
 	assert.NoError(t, err)
-	assert.Equal(t, "unauthorized", responseBody["error"])
+	assert.Equal(t, "unauthorized", responseBody["code"])
 }
 
 func TestErrorHandler_WithPanic(t *testing.T) {
@@ -245,7 +249,7 @@ func TestErrorHandler_WithPanic(t *testing.T) {
 	// Setup router with recovery and error handler
 	router := gin.New()
 	router.Use(RequestID())
-	router.Use(gin.Recovery()) // Recovery middleware should handle panics
+	router.Use(Recovery()) // Recovery middleware should handle panics
 	router.Use(ErrorHandler(logger))
 
 	// Handler that panics
@@ -260,6 +264,11 @@ func TestErrorHandler_WithPanic(t *testing.T) {
 
 	// Assert that recovery middleware handled the panic
 	assert.Equal(t, 500, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+	var responseBody map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &responseBody))
+	assert.Equal(t, "internal_server_error", responseBody["code"])
 }
 
 func TestErrorHandler_ChainedMiddleware(t *testing.T) {
@@ -317,8 +326,33 @@ func TestHandleNotFound(t *testing.T) {
 	var responseBody map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &responseBody)
 	assert.NoError(t, err)
-	assert.Equal(t, "not_found", responseBody["error"])
-	assert.Equal(t, "The requested resource was not found", responseBody["message"])
+	assert.Equal(t, "not_found", responseBody["code"])
+	assert.Equal(t, "The requested resource was not found", responseBody["detail"])
+}
+
+func TestErrorHandler_IncludesRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RequestID())
+	router.Use(ErrorHandler(zap.NewNop()))
+	router.NoRoute(HandleNotFound())
+	router.GET("/test", func(c *gin.Context) {
+		c.Error(domain.ErrSessionNotFound)
+	})
+
+	for _, path := range []string{"/test", "/non-existent"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set(RequestIDKey, "req-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 404, w.Code)
+		assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+		var responseBody map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &responseBody))
+		assert.Equal(t, "req-1", responseBody["requestId"])
+	}
 }
 
 func TestHandleMethodNotAllowed(t *testing.T) {
@@ -346,6 +380,6 @@ func TestHandleMethodNotAllowed(t *testing.T) {
 	var responseBody map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &responseBody)
 	assert.NoError(t, err)
-	assert.Equal(t, "method_not_allowed", responseBody["error"])
-	assert.Equal(t, "HTTP method not allowed for this resource", responseBody["message"])
+	assert.Equal(t, "method_not_allowed", responseBody["code"])
+	assert.Equal(t, "HTTP method not allowed for this resource", responseBody["detail"])
 }
@@ -1,14 +1,48 @@
 package middleware
 
 import (
+	"math/rand/v2"
 	"time"
 
+	"audit-service/pkg/mtls"
+
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-// Logger returns a gin middleware for structured logging
+// AccessLogSampling sets the share of successful requests that are logged.
+// Requests answered with a 4xx or 5xx status are always logged.
+type AccessLogSampling struct {
+	// Default applies to routes without their own rate; 1 logs every request
+	Default float64
+	// Routes maps a route pattern, optionally prefixed with the method ("GET /health"), to a rate
+	Routes map[string]float64
+}
+
+// rate returns the sampling rate of a route, preferring a method-specific entry
+func (s AccessLogSampling) rate(method, route string) float64 {
+	if rate, ok := s.Routes[method+" "+route]; ok {
+		return rate
+	}
+	if rate, ok := s.Routes[route]; ok {
+		return rate
+	}
+	return s.Default
+}
+
+// Logger returns a gin middleware for structured logging of every request
 func Logger(logger *zap.Logger) gin.HandlerFunc {
+	return AccessLog(logger, AccessLogSampling{Default: 1})
+}
+
+// AccessLog returns a gin middleware writing one structured access log entry per request,
+// sampling successful requests per route
+func AccessLog(logger *zap.Logger, sampling AccessLogSampling) gin.HandlerFunc {
+	return accessLog(logger, sampling, rand.Float64)
+}
+
+// accessLog is AccessLog with a replaceable random source for the sampling decision
+func accessLog(logger *zap.Logger, sampling AccessLogSampling, random func() float64) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Start timer
 		start := time.Now()
@@ -20,11 +54,28 @@ func Logger(logger *zap.Logger) gin.HandlerFunc {
 
 		// Log only after request is processed
 		latency := time.Since(start)
-		clientIP := c.ClientIP()
+		// Resolved by ClientInfo from the trusted proxy headers
+		clientIP := GetClientIP(c)
+		if clientIP == "" {
+			clientIP = c.ClientIP()
+		}
 		method := c.Request.Method
 		statusCode := c.Writer.Status()
 		errorMessage := c.Errors.ByType(gin.ErrorTypePrivate).String()
 
+		// The route pattern groups requests for the same endpoint
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		// Successful requests of busy routes are only logged in part
+		rate := sampling.rate(method, route)
+		sampled := statusCode < 400 && errorMessage == "" && rate < 1
+		if sampled && random() >= rate {
+			return
+		}
+
 		// Get request ID from context
 		requestID := GetRequestID(c)
 
@@ -32,13 +83,25 @@ func Logger(logger *zap.Logger) gin.HandlerFunc {
 		fields := []zap.Field{
 			zap.String("request_id", requestID),
 			zap.String("method", method),
+			zap.String("route", route),
 			zap.String("path", path),
 			zap.String("ip", clientIP),
 			zap.Int("status", statusCode),
 			zap.Duration("latency", latency),
+			zap.Int64("request_bytes", max(c.Request.ContentLength, 0)),
+			zap.Int("response_bytes", max(c.Writer.Size(), 0)),
 			zap.String("user_agent", c.Request.UserAgent()),
 		}
 
+		if userID := GetAuthUserID(c); userID != "" {
+			fields = append(fields, zap.String("user_id", userID))
+		}
+
+		// Clients of the internal listener are identified by their certificate
+		if c.Request.TLS != nil && len(c.Request.TLS.PeerCertificates) > 0 {
+			fields = append(fields, zap.String("peer", mtls.PeerIdentity(c.Request.TLS.PeerCertificates[0])))
+		}
+
 		if raw != "" {
 			fields = append(fields, zap.String("query", raw))
 		}
@@ -47,6 +110,11 @@ func Logger(logger *zap.Logger) gin.HandlerFunc {
 			fields = append(fields, zap.String("error", errorMessage))
 		}
 
+		// Each sampled entry stands for 1/rate requests when reconstructing traffic
+		if sampled {
+			fields = append(fields, zap.Float64("sample_rate", rate))
+		}
+
 		// Log based on status code
 		switch {
 		case statusCode >= 500:
@@ -2,10 +2,13 @@ package middleware
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -116,6 +119,23 @@ func TestLogger(t *testing.T) {
 				"200",
 			},
 		},
+		{
+			name: "with_client_certificate",
+			setupRequest: func(req *http.Request) {
+				req.Method = "POST"
+				req.URL.Path = "/api/v1/events"
+				peer := &x509.Certificate{URIs: []*url.URL{{Scheme: "spiffe", Host: "cluster.local", Path: "/ns/pptx/sa/translator"}}}
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{peer}}
+			},
+			setupHandler: func(c *gin.Context) {
+				c.Status(201)
+			},
+			expectedStatus: 201,
+			expectedLogs: []string{
+				"request completed",
+				`"peer":"spiffe://cluster.local/ns/pptx/sa/translator"`,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -230,3 +250,91 @@ func TestLogger_Performance(t *testing.T) {
 		assert.Equal(t, 200, w.Code)
 	}
 }
+
+func TestAccessLog_Fields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var logBuffer bytes.Buffer
+	encoder := zapcore.NewJSONEncoder(zap.NewDevelopmentEncoderConfig())
+	logger := zap.New(zapcore.NewCore(encoder, zapcore.AddSync(&logBuffer), zapcore.DebugLevel))
+
+	router := gin.New()
+	router.Use(RequestID())
+	router.Use(AccessLog(logger, AccessLogSampling{Default: 1}))
+	router.POST("/sessions/:sessionId/events", func(c *gin.Context) {
+		c.Set(AuthUserIDKey, "user-123")
+		c.String(http.StatusCreated, "created")
+	})
+
+	req, _ := http.NewRequest(http.MethodPost, "/sessions/session-1/events", bytes.NewBufferString(`{"type":"edit"}`))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(bytes.TrimSpace(logBuffer.Bytes()), &entry))
+	assert.Equal(t, "/sessions/:sessionId/events", entry["route"])
+	assert.Equal(t, "/sessions/session-1/events", entry["path"])
+	assert.Equal(t, "user-123", entry["user_id"])
+	assert.Equal(t, float64(15), entry["request_bytes"])
+	assert.Equal(t, float64(7), entry["response_bytes"])
+	assert.NotContains(t, entry, "sample_rate")
+}
+
+func TestAccessLog_Sampling(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sampling := AccessLogSampling{
+		Default: 1,
+		Routes: map[string]float64{
+			"GET /health":                      0,
+			"/sessions/:sessionId/history":     0.25,
+			"POST /sessions/:sessionId/events": 1,
+		},
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		status     int
+		random     float64
+		expectLog  bool
+		expectRate bool
+	}{
+		{"route_disabled", http.MethodGet, "/health", http.StatusOK, 0, false, false},
+		{"errors_always_logged", http.MethodGet, "/health", http.StatusServiceUnavailable, 0.9, true, false},
+		{"sampled_in", http.MethodGet, "/sessions/s1/history", http.StatusOK, 0.1, true, true},
+		{"sampled_out", http.MethodGet, "/sessions/s1/history", http.StatusOK, 0.3, false, false},
+		{"method_specific_rate", http.MethodPost, "/sessions/s1/events", http.StatusOK, 0.99, true, false},
+		{"default_rate", http.MethodGet, "/other", http.StatusOK, 0.99, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logBuffer bytes.Buffer
+			encoder := zapcore.NewJSONEncoder(zap.NewDevelopmentEncoderConfig())
+			logger := zap.New(zapcore.NewCore(encoder, zapcore.AddSync(&logBuffer), zapcore.DebugLevel))
+
+			router := gin.New()
+			router.Use(accessLog(logger, sampling, func() float64 { return tt.random }))
+			handler := func(c *gin.Context) { c.Status(tt.status) }
+			router.GET("/health", handler)
+			router.GET("/sessions/:sessionId/history", handler)
+			router.POST("/sessions/:sessionId/events", handler)
+			router.GET("/other", handler)
+
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if !tt.expectLog {
+				assert.Empty(t, logBuffer.String())
+				return
+			}
+			assert.NotEmpty(t, logBuffer.String())
+			if tt.expectRate {
+				assert.Contains(t, logBuffer.String(), `"sample_rate":0.25`)
+			} else {
+				assert.NotContains(t, logBuffer.String(), "sample_rate")
+			}
+		})
+	}
+}
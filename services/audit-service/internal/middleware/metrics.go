@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"time"
+
+	"audit-service/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that did not match a registered route, keeping label cardinality bounded
+const unmatchedRoute = "unmatched"
+
+// Metrics returns a gin middleware that records request counts and latencies
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		m.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"audit-service/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	m := metrics.New()
+	router := gin.New()
+	router.Use(Metrics(m))
+	router.GET("/sessions/:sessionId/history", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/metrics", gin.WrapH(m.Handler()))
+
+	for _, path := range []string{"/sessions/a/history", "/sessions/b/history", "/unknown"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := w.Body.String()
+	assert.Contains(t, body, `audit_service_http_requests_total{method="GET",route="/sessions/:sessionId/history",status="200"} 2`)
+	assert.Contains(t, body, `audit_service_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+}
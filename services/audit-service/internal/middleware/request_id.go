@@ -1,24 +1,28 @@
 package middleware
 
 import (
+	"audit-service/pkg/requestid"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-const RequestIDKey = "X-Request-ID"
+const RequestIDKey = requestid.Header
 
 // RequestID middleware generates a unique request ID for each request
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Check if request ID already exists in headers
+		// Reuse the caller's request ID unless it is missing or unsafe to log
 		requestID := c.GetHeader(RequestIDKey)
-		if requestID == "" {
+		if !requestid.Valid(requestID) {
 			// Generate new UUID
 			requestID = uuid.New().String()
 		}
 
 		// Set request ID in context
 		c.Set(RequestIDKey, requestID)
+		// Carry it on the request context so services and the Supabase client can log and forward it
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), requestID))
 
 		// Set request ID in response header
 		c.Header(RequestIDKey, requestID)
@@ -3,8 +3,11 @@ package middleware
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"audit-service/pkg/requestid"
+
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
@@ -99,6 +102,47 @@ func TestRequestID(t *testing.T) {
 	}
 }
 
+func TestRequestID_ReplacesUnsafeHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RequestID())
+
+	var capturedRequestID string
+	router.GET("/test", func(c *gin.Context) {
+		capturedRequestID = GetRequestID(c)
+		c.Status(200)
+	})
+
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Request-ID", strings.Repeat("a", 200))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Len(t, capturedRequestID, 36)
+	assert.Equal(t, capturedRequestID, w.Header().Get("X-Request-ID"))
+}
+
+func TestRequestID_PropagatesToRequestContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RequestID())
+
+	var contextRequestID string
+	router.GET("/test", func(c *gin.Context) {
+		contextRequestID = requestid.FromContext(c.Request.Context())
+		c.Status(200)
+	})
+
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Request-ID", "existing-request-id")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "existing-request-id", contextRequestID)
+}
+
 func TestRequestID_UniqueValues(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+
+	"audit-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShareTokenUseReporter receives the requests that presented the token of an existing share link
+type ShareTokenUseReporter interface {
+	ReportShareTokenUse(ctx context.Context, use domain.ShareTokenUse)
+}
+
+// ShareTokenUses reports every request that redeemed a share link, or presented the token of an
+// expired one, once it has been handled. It wraps Auth, which identifies the link.
+func ShareTokenUses(reporter ShareTokenUseReporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		tokenID := GetShareTokenID(c)
+		if tokenID == "" {
+			return
+		}
+		expired := c.GetBool(AuthShareExpiredKey)
+		if !expired && GetAuthTokenType(c) != TokenTypeShare {
+			return
+		}
+
+		reporter.ReportShareTokenUse(c.Request.Context(), domain.ShareTokenUse{
+			TokenID:        tokenID,
+			SessionID:      c.Param("sessionId"),
+			OrganizationID: GetAuthOrganizationID(c),
+			Expired:        expired,
+			Method:         c.Request.Method,
+			Path:           c.Request.URL.Path,
+			Status:         c.Writer.Status(),
+			ClientIP:       GetClientIP(c),
+			UserAgent:      GetUserAgent(c),
+			RequestID:      GetRequestID(c),
+		})
+	}
+}
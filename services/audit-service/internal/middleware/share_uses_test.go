@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"audit-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingUseReporter struct {
+	uses []domain.ShareTokenUse
+}
+
+func (r *recordingUseReporter) ReportShareTokenUse(_ context.Context, use domain.ShareTokenUse) {
+	r.uses = append(r.uses, use)
+}
+
+func TestShareTokenUses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reporter := &recordingUseReporter{}
+	router := gin.New()
+	router.Use(ClientInfo(nil, ForwardedHeaderXForwardedFor), ShareTokenUses(reporter))
+	// Stands in for Auth, which sets the share link of the token presented
+	router.GET("/sessions/:sessionId/history", func(c *gin.Context) {
+		switch c.Query("share_token") {
+		case "valid":
+			c.Set(AuthShareTokenIDKey, testShareJTI)
+			c.Set(AuthTokenTypeKey, TokenTypeShare)
+			c.Set(AuthOrganizationIDKey, "org-acme")
+			c.Status(http.StatusOK)
+		case "expired":
+			c.Set(AuthShareTokenIDKey, testShareJTI)
+			c.Set(AuthShareExpiredKey, true)
+			WriteError(c, domain.APIErrForbidden)
+		case "revoked":
+			WriteError(c, domain.APIErrForbidden)
+		default:
+			c.Set(AuthTokenTypeKey, TokenTypeJWT)
+			c.Status(http.StatusOK)
+		}
+	})
+
+	for _, query := range []string{"?share_token=valid", "?share_token=expired", "?share_token=revoked", ""} {
+		req := httptest.NewRequest(http.MethodGet, "/sessions/"+testShareSession+"/history"+query, nil)
+		req.RemoteAddr = "203.0.113.7:4711"
+		req.Header.Set("User-Agent", "curl/8.0")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	require.Len(t, reporter.uses, 2)
+	assert.Equal(t, domain.ShareTokenUse{
+		TokenID:        testShareJTI,
+		SessionID:      testShareSession,
+		OrganizationID: "org-acme",
+		Method:         http.MethodGet,
+		Path:           "/sessions/" + testShareSession + "/history",
+		Status:         http.StatusOK,
+		ClientIP:       "203.0.113.7",
+		UserAgent:      "curl/8.0",
+	}, reporter.uses[0])
+
+	expired := reporter.uses[1]
+	assert.True(t, expired.Expired)
+	assert.Equal(t, http.StatusForbidden, expired.Status)
+}
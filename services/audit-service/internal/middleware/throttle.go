@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthThrottler reports how long a client IP or user stays locked out after repeated rejected requests
+type AuthThrottler interface {
+	Throttled(ctx context.Context, clientIP, userID string) time.Duration
+}
+
+// Throttle rejects the requests of locked out client IPs and users with 429 before they are
+// authenticated. The user is taken from the token cache, so a token not validated yet is only
+// throttled by its client IP.
+func Throttle(throttler AuthThrottler, tokenCache *cache.TokenCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var userID string
+		if token := extractBearerToken(c.GetHeader("Authorization")); token != "" && tokenCache != nil {
+			if info, found := tokenCache.PeekJWT(token); found {
+				userID = info.UserID
+			}
+		}
+
+		remaining := throttler.Throttled(c.Request.Context(), GetClientIP(c), userID)
+		if remaining <= 0 {
+			c.Next()
+			return
+		}
+		c.Header("Retry-After", strconv.Itoa(max(int(math.Ceil(remaining.Seconds())), 1)))
+		WriteError(c, domain.APIErrTooManyAuthFailures)
+		c.Abort()
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"audit-service/pkg/cache"
+	"audit-service/pkg/clock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// lockouts throttles the listed client IPs and users
+type lockouts map[string]time.Duration
+
+func (l lockouts) Throttled(_ context.Context, clientIP, userID string) time.Duration {
+	return max(l["ip:"+clientIP], l["user:"+userID])
+}
+
+func TestThrottle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	clk := clock.NewFakeClock(time.Now())
+	tokenCache := cache.NewTokenCache(time.Minute, time.Minute, time.Minute, clk)
+	tokenCache.SetJWT("locked-user-token", &cache.CachedTokenInfo{UserID: "user-1", ExpiresAt: clk.Now().Add(time.Hour)})
+
+	router := gin.New()
+	router.Use(ClientInfo(nil, ForwardedHeaderXForwardedFor), Throttle(lockouts{"ip:203.0.113.7": 1500 * time.Millisecond, "user:user-1": time.Minute}, tokenCache))
+	router.GET("/events", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		token      string
+		status     int
+		retryAfter string
+	}{
+		{name: "not throttled", remoteAddr: "198.51.100.1:4711", status: http.StatusOK},
+		{name: "throttled client IP", remoteAddr: "203.0.113.7:4711", status: http.StatusTooManyRequests, retryAfter: "2"},
+		{name: "throttled user", remoteAddr: "198.51.100.1:4711", token: "locked-user-token", status: http.StatusTooManyRequests, retryAfter: "60"},
+		{name: "token not cached", remoteAddr: "198.51.100.1:4711", token: "other-token", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/events", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.retryAfter, w.Header().Get("Retry-After"))
+			if tt.status == http.StatusTooManyRequests {
+				assert.Contains(t, w.Body.String(), "too_many_auth_failures")
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"audit-service/internal/broadcast"
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/internal/retention"
+	"audit-service/pkg/clock"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// queueSize is how many published entries may wait for the notifier before new ones are dropped
+	queueSize = 4096
+
+	// closeFlushTimeout bounds sending the collected digests when the notifier stops
+	closeFlushTimeout = 10 * time.Second
+)
+
+// SessionWatches lists the watchers of a session
+type SessionWatches interface {
+	ListSessionWatches(ctx context.Context, sessionID string) ([]domain.Watch, error)
+}
+
+// Config controls how events are batched into digests
+type Config struct {
+	// DigestInterval is how often the collected events are sent
+	DigestInterval time.Duration
+	// MaxDigestEvents sends the digest of a watcher early once it holds this many events
+	MaxDigestEvents int
+}
+
+// Notifier collects the events of watched sessions per watcher and sends them to the sink in
+// digests. Digests are kept in memory: each replica notifies the events it ingested, and digests
+// that were not sent are lost when the process is killed.
+type Notifier struct {
+	broker  *broadcast.Broker
+	watches SessionWatches
+	sink    Sink
+	cfg     Config
+	clock   clock.Clock
+	metrics *metrics.Metrics
+	logger  *zap.Logger
+
+	// pending holds the digest being collected per watcher; only accessed by the notifier goroutine
+	pending map[string]*domain.WatchDigest
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// New creates a notifier; call Start to begin watching the broker
+func New(broker *broadcast.Broker, watches SessionWatches, sink Sink, cfg Config, clk clock.Clock, m *metrics.Metrics, logger *zap.Logger) *Notifier {
+	return &Notifier{
+		broker:  broker,
+		watches: watches,
+		sink:    sink,
+		cfg:     cfg,
+		clock:   clk,
+		metrics: m,
+		logger:  logger,
+		pending: make(map[string]*domain.WatchDigest),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Start subscribes to every published entry; entries created after Start returns are notified
+func (n *Notifier) Start() {
+	sub := n.broker.SubscribeBuffered(broadcast.AllTopic, queueSize)
+	go n.run(sub)
+}
+
+// Close stops the notifier after sending the collected digests
+func (n *Notifier) Close(ctx context.Context) error {
+	n.closeOnce.Do(func() { close(n.stop) })
+
+	select {
+	case <-n.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("watch notifier did not stop: %w", ctx.Err())
+	}
+}
+
+// run collects entries and sends digests on the interval until Close is called or the broker
+// shuts down
+func (n *Notifier) run(sub *broadcast.Subscription) {
+	defer close(n.stopped)
+	defer sub.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-n.stop
+		cancel()
+	}()
+
+	ticker := time.NewTicker(n.cfg.DigestInterval)
+	defer ticker.Stop()
+
+	defer func() {
+		flushCtx, cancelFlush := context.WithTimeout(context.Background(), closeFlushTimeout)
+		defer cancelFlush()
+		n.Flush(flushCtx)
+	}()
+
+	for {
+		select {
+		case <-n.stop:
+			return
+		case <-ticker.C:
+			n.Flush(ctx)
+		case entry, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			n.Observe(ctx, entry)
+		}
+	}
+}
+
+// Observe adds an entry to the digests of the watchers of its session and sends the digests
+// that are full. It is not safe for concurrent use.
+func (n *Notifier) Observe(ctx context.Context, entry domain.AuditEntry) {
+	// Most events are not watchable, so they are dropped before the watchers are looked up
+	if !slices.Contains(domain.WatchableEventTypes, domain.AuditAction(entry.Type)) || entry.UserID == retention.SystemUserID {
+		return
+	}
+
+	watches, err := n.watches.ListSessionWatches(ctx, entry.SessionID)
+	if err != nil {
+		n.logger.Error("failed to look up session watches",
+			zap.String("session_id", entry.SessionID),
+			zap.String("event_id", entry.ID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	// Watchers receive the stored event without the client IP and user agent, like outbox consumers
+	entry.IPAddress = ""
+	entry.UserAgent = ""
+	entry.Timestamp = entry.Timestamp.UTC()
+
+	for _, watch := range watches {
+		if !watch.Matches(entry) {
+			continue
+		}
+
+		digest, ok := n.pending[watch.UserID]
+		if !ok {
+			digest = &domain.WatchDigest{ID: uuid.New().String(), UserID: watch.UserID, From: entry.Timestamp}
+			n.pending[watch.UserID] = digest
+		}
+		digest.Events = append(digest.Events, entry)
+		if entry.Timestamp.Before(digest.From) {
+			digest.From = entry.Timestamp
+		}
+		if entry.Timestamp.After(digest.To) {
+			digest.To = entry.Timestamp
+		}
+
+		if n.cfg.MaxDigestEvents > 0 && len(digest.Events) >= n.cfg.MaxDigestEvents {
+			delete(n.pending, watch.UserID)
+			n.send(ctx, *digest)
+		}
+	}
+}
+
+// Flush sends the digests collected so far. It is not safe for concurrent use.
+func (n *Notifier) Flush(ctx context.Context) {
+	for userID, digest := range n.pending {
+		delete(n.pending, userID)
+		n.send(ctx, *digest)
+	}
+}
+
+// send delivers one digest. Digests are sent once; a failed digest is logged and dropped, and
+// the events stay available in the audit history.
+func (n *Notifier) send(ctx context.Context, digest domain.WatchDigest) {
+	if err := n.sink.Send(ctx, digest); err != nil {
+		n.metrics.ObserveWatchDigest("failed")
+		n.logger.Error("failed to send watch digest",
+			zap.String("digest_id", digest.ID),
+			zap.String("user_id", digest.UserID),
+			zap.Int("events", len(digest.Events)),
+			zap.Error(err),
+		)
+		return
+	}
+
+	n.metrics.ObserveWatchDigest("sent")
+	n.logger.Debug("watch digest sent",
+		zap.String("digest_id", digest.ID),
+		zap.String("user_id", digest.UserID),
+		zap.Int("events", len(digest.Events)),
+	)
+}
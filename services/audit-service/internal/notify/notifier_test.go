@@ -0,0 +1,184 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"audit-service/internal/broadcast"
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/internal/retention"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testSession = "550e8400-e29b-41d4-a716-446655440000"
+
+var testNow = time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+
+// fakeWatches returns fixed watchers for every session
+type fakeWatches struct {
+	watches []domain.Watch
+	err     error
+	lookups int
+	// lookedUp, when set, receives the session of every lookup
+	lookedUp chan string
+}
+
+func (f *fakeWatches) ListSessionWatches(_ context.Context, sessionID string) ([]domain.Watch, error) {
+	f.lookups++
+	if f.lookedUp != nil {
+		f.lookedUp <- sessionID
+	}
+	return f.watches, f.err
+}
+
+// fakeSink records the digests it is sent
+type fakeSink struct {
+	digests chan domain.WatchDigest
+	err     error
+}
+
+func (s *fakeSink) Send(_ context.Context, digest domain.WatchDigest) error {
+	s.digests <- digest
+	return s.err
+}
+
+func newTestNotifier(cfg Config, watches *fakeWatches, sink *fakeSink) *Notifier {
+	return New(broadcast.NewBroker(zap.NewNop()), watches, sink, cfg, clock.NewFakeClock(testNow), metrics.New(), zap.NewNop())
+}
+
+func event(id, userID, eventType string, at time.Time) domain.AuditEntry {
+	return domain.AuditEntry{
+		ID: id, SessionID: testSession, UserID: userID, Type: eventType, Timestamp: at,
+		IPAddress: "203.0.113.7", UserAgent: "editor/1.0",
+	}
+}
+
+func TestNotifier_CollectsDigestsPerWatcher(t *testing.T) {
+	watches := &fakeWatches{watches: []domain.Watch{
+		{SessionID: testSession, UserID: "user-1", EventTypes: []string{"share", "comment"}},
+		{SessionID: testSession, UserID: "user-2", EventTypes: []string{"export"}},
+	}}
+	sink := &fakeSink{digests: make(chan domain.WatchDigest, 2)}
+	notifier := newTestNotifier(Config{DigestInterval: time.Hour}, watches, sink)
+	ctx := context.Background()
+
+	notifier.Observe(ctx, event("event-1", "user-3", "comment", testNow.Add(time.Minute)))
+	notifier.Observe(ctx, event("event-2", "user-3", "share", testNow))
+	// Watchers are not notified of their own events
+	notifier.Observe(ctx, event("event-3", "user-1", "share", testNow))
+	notifier.Observe(ctx, event("event-4", "user-1", "export", testNow.Add(2*time.Minute)))
+	notifier.Flush(ctx)
+
+	digests := map[string]domain.WatchDigest{}
+	for len(sink.digests) > 0 {
+		digest := <-sink.digests
+		digests[digest.UserID] = digest
+	}
+	require.Len(t, digests, 2)
+
+	first := digests["user-1"]
+	require.Len(t, first.Events, 2)
+	assert.Equal(t, "event-1", first.Events[0].ID)
+	assert.Equal(t, "event-2", first.Events[1].ID)
+	assert.Equal(t, testNow, first.From)
+	assert.Equal(t, testNow.Add(time.Minute), first.To)
+	assert.Empty(t, first.Events[0].IPAddress)
+	assert.Empty(t, first.Events[0].UserAgent)
+
+	second := digests["user-2"]
+	require.Len(t, second.Events, 1)
+	assert.Equal(t, "event-4", second.Events[0].ID)
+	assert.NotEqual(t, first.ID, second.ID)
+
+	// Sent digests are not sent again
+	notifier.Flush(ctx)
+	assert.Empty(t, sink.digests)
+}
+
+func TestNotifier_SkipsUnwatchableEvents(t *testing.T) {
+	watches := &fakeWatches{watches: []domain.Watch{
+		{SessionID: testSession, UserID: "user-1", EventTypes: []string{"share"}},
+	}}
+	sink := &fakeSink{digests: make(chan domain.WatchDigest, 1)}
+	notifier := newTestNotifier(Config{DigestInterval: time.Hour}, watches, sink)
+	ctx := context.Background()
+
+	notifier.Observe(ctx, event("event-1", "user-2", "edit", testNow))
+	notifier.Observe(ctx, event("event-2", retention.SystemUserID, "share", testNow))
+	notifier.Flush(ctx)
+
+	assert.Zero(t, watches.lookups)
+	assert.Empty(t, sink.digests)
+}
+
+func TestNotifier_SendsFullDigests(t *testing.T) {
+	watches := &fakeWatches{watches: []domain.Watch{
+		{SessionID: testSession, UserID: "user-1", EventTypes: []string{"export"}},
+	}}
+	sink := &fakeSink{digests: make(chan domain.WatchDigest, 2)}
+	notifier := newTestNotifier(Config{DigestInterval: time.Hour, MaxDigestEvents: 2}, watches, sink)
+	ctx := context.Background()
+
+	for _, id := range []string{"event-1", "event-2", "event-3"} {
+		notifier.Observe(ctx, event(id, "user-2", "export", testNow))
+	}
+
+	require.Len(t, sink.digests, 1)
+	assert.Len(t, (<-sink.digests).Events, 2)
+	notifier.Flush(ctx)
+	assert.Len(t, (<-sink.digests).Events, 1)
+}
+
+func TestNotifier_DropsFailedDigests(t *testing.T) {
+	watches := &fakeWatches{watches: []domain.Watch{
+		{SessionID: testSession, UserID: "user-1", EventTypes: []string{"export"}},
+	}}
+	sink := &fakeSink{digests: make(chan domain.WatchDigest, 2), err: errors.New("sink unavailable")}
+	notifier := newTestNotifier(Config{DigestInterval: time.Hour}, watches, sink)
+	ctx := context.Background()
+
+	notifier.Observe(ctx, event("event-1", "user-2", "export", testNow))
+	notifier.Flush(ctx)
+	notifier.Flush(ctx)
+
+	assert.Len(t, sink.digests, 1)
+}
+
+func TestNotifier_FlushesOnClose(t *testing.T) {
+	watches := &fakeWatches{
+		watches:  []domain.Watch{{SessionID: testSession, UserID: "user-1", EventTypes: []string{"share"}}},
+		lookedUp: make(chan string, 1),
+	}
+	sink := &fakeSink{digests: make(chan domain.WatchDigest, 1)}
+	notifier := newTestNotifier(Config{DigestInterval: time.Hour}, watches, sink)
+
+	notifier.Start()
+	notifier.broker.Publish(event("event-1", "user-2", "share", testNow))
+
+	// The entry is collected once its watchers were looked up; the digest is only sent on close
+	select {
+	case <-watches.lookedUp:
+	case <-time.After(time.Second):
+		t.Fatal("watchers were not looked up")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, notifier.Close(ctx))
+	require.NoError(t, notifier.Close(ctx)) // closing twice is safe
+
+	select {
+	case digest := <-sink.digests:
+		assert.Equal(t, "user-1", digest.UserID)
+	default:
+		t.Fatal("digest was not sent on close")
+	}
+	assert.Equal(t, 0, notifier.broker.SubscriberCount(broadcast.AllTopic))
+}
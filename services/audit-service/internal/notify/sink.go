@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/outbox"
+)
+
+// DigestType is the type of digest notifications, sent in the X-Audit-Event-Type header of
+// webhooks and the type column of notification rows
+const DigestType = "watch_digest"
+
+// Sink delivers digests to the notification service
+type Sink interface {
+	Send(ctx context.Context, digest domain.WatchDigest) error
+}
+
+// WebhookSink posts digests through an outbox webhook sink, so they carry the X-Audit-* headers
+// and signature of outbox deliveries with the digest ID as event ID
+type WebhookSink struct {
+	webhook outbox.Sink
+}
+
+// NewWebhookSink creates a sink posting digests to a webhook
+func NewWebhookSink(webhook outbox.Sink) *WebhookSink {
+	return &WebhookSink{webhook: webhook}
+}
+
+// Send posts the digest as JSON body
+func (s *WebhookSink) Send(ctx context.Context, digest domain.WatchDigest) error {
+	payload, err := json.Marshal(digest)
+	if err != nil {
+		return fmt.Errorf("failed to encode watch digest: %w", err)
+	}
+	return s.webhook.Deliver(ctx, domain.OutboxMessage{
+		EventID:   digest.ID,
+		Type:      DigestType,
+		Payload:   payload,
+		Attempts:  1,
+		CreatedAt: digest.To,
+	})
+}
+
+// Poster inserts rows through the Supabase REST API
+type Poster interface {
+	Post(ctx context.Context, endpoint string, payload interface{}) ([]byte, error)
+}
+
+// TableSink inserts digests as rows of a Supabase table read by the notification service
+type TableSink struct {
+	client Poster
+	table  string
+}
+
+// NewTableSink creates a sink inserting digests into the given table
+func NewTableSink(client Poster, table string) *TableSink {
+	return &TableSink{client: client, table: table}
+}
+
+// notificationRow is a row of the notifications table
+type notificationRow struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"user_id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt string          `json:"created_at"`
+}
+
+// Send inserts the digest with its events in the payload column
+func (s *TableSink) Send(ctx context.Context, digest domain.WatchDigest) error {
+	payload, err := json.Marshal(digest)
+	if err != nil {
+		return fmt.Errorf("failed to encode watch digest: %w", err)
+	}
+	row := notificationRow{
+		ID:        digest.ID,
+		UserID:    digest.UserID,
+		Type:      DigestType,
+		Payload:   payload,
+		CreatedAt: digest.To.UTC().Format(time.RFC3339Nano),
+	}
+	if _, err := s.client.Post(ctx, "/"+s.table, row); err != nil {
+		return fmt.Errorf("failed to insert notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/outbox"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testDigest = domain.WatchDigest{
+	ID:     "7d9f2b1e-4c3a-4e8b-9f0a-1b2c3d4e5f60",
+	UserID: "user-1",
+	From:   testNow,
+	To:     testNow.Add(time.Minute),
+	Events: []domain.AuditEntry{{ID: "event-1", SessionID: testSession, UserID: "user-2", Type: "share", Timestamp: testNow}},
+}
+
+func TestWebhookSink_Send(t *testing.T) {
+	var headers http.Header
+	var body domain.WatchDigest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(outbox.NewWebhookSink([]string{server.URL}, "secret", server.Client(), clock.NewFakeClock(testNow)))
+
+	require.NoError(t, sink.Send(context.Background(), testDigest))
+	assert.Equal(t, testDigest.ID, headers.Get(outbox.HeaderEventID))
+	assert.Equal(t, DigestType, headers.Get(outbox.HeaderEventType))
+	assert.NotEmpty(t, headers.Get(outbox.HeaderSignature))
+	assert.Equal(t, "user-1", body.UserID)
+	assert.Len(t, body.Events, 1)
+}
+
+// fakePoster records the rows posted to the REST API
+type fakePoster struct {
+	endpoint string
+	payload  interface{}
+}
+
+func (p *fakePoster) Post(_ context.Context, endpoint string, payload interface{}) ([]byte, error) {
+	p.endpoint = endpoint
+	p.payload = payload
+	return nil, nil
+}
+
+func TestTableSink_Send(t *testing.T) {
+	poster := &fakePoster{}
+	sink := NewTableSink(poster, "notifications")
+
+	require.NoError(t, sink.Send(context.Background(), testDigest))
+	assert.Equal(t, "/notifications", poster.endpoint)
+
+	encoded, err := json.Marshal(poster.payload)
+	require.NoError(t, err)
+	var row struct {
+		ID        string             `json:"id"`
+		UserID    string             `json:"user_id"`
+		Type      string             `json:"type"`
+		Payload   domain.WatchDigest `json:"payload"`
+		CreatedAt string             `json:"created_at"`
+	}
+	require.NoError(t, json.Unmarshal(encoded, &row))
+	assert.Equal(t, testDigest.ID, row.ID)
+	assert.Equal(t, "user-1", row.UserID)
+	assert.Equal(t, DigestType, row.Type)
+	assert.Equal(t, "2024-02-01T09:01:00Z", row.CreatedAt)
+	assert.Equal(t, "event-1", row.Payload.Events[0].ID)
+}
@@ -0,0 +1,168 @@
+// Package notify forwards the share, export and comment events of watched sessions to a
+// notification sink. Users watch sessions through the API; a notifier collects the matching
+// events of every watcher and sends them in periodic digests.
+package notify
+
+import (
+	"context"
+	"slices"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/requestid"
+	"audit-service/pkg/tenant"
+
+	"go.uber.org/zap"
+)
+
+// WatchRepository stores the watches
+type WatchRepository interface {
+	PutWatch(ctx context.Context, watch domain.Watch) (*domain.Watch, error)
+	GetWatch(ctx context.Context, sessionID, userID string) (*domain.Watch, error)
+	DeleteWatch(ctx context.Context, sessionID, userID string) error
+	ListUserWatches(ctx context.Context, userID string) ([]domain.Watch, error)
+}
+
+// Authorizer checks that a user may read a session
+type Authorizer interface {
+	AuthorizeSession(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool) error
+}
+
+// Watches lets users watch the sessions they can read. Share tokens are not tied to an account
+// that could be notified, so they cannot watch sessions.
+type Watches struct {
+	repo   WatchRepository
+	auth   Authorizer
+	clock  clock.Clock
+	logger *zap.Logger
+}
+
+// NewWatches creates the watch manager
+func NewWatches(repo WatchRepository, auth Authorizer, clk clock.Clock, logger *zap.Logger) *Watches {
+	return &Watches{
+		repo:   repo,
+		auth:   auth,
+		clock:  clk,
+		logger: logger,
+	}
+}
+
+// Watch subscribes a user to the given event types of a session, or to the
+// domain.DefaultWatchedEventTypes when none are given. Watching a session again replaces the
+// event types.
+func (w *Watches) Watch(ctx context.Context, sessionID, userID string, isShareToken bool, eventTypes []string) (domain.Watch, error) {
+	sessionID, err := w.authorize(ctx, sessionID, userID, isShareToken)
+	if err != nil {
+		return domain.Watch{}, err
+	}
+
+	organizationID, _ := tenant.FromContext(ctx)
+	watch := domain.Watch{
+		SessionID:  sessionID,
+		UserID:     userID,
+		EventTypes: normalizeEventTypes(eventTypes),
+		CreatedAt:  w.clock.Now(),
+
+		OrganizationID: organizationID,
+	}
+	if err := watch.Validate(); err != nil {
+		return domain.Watch{}, err
+	}
+
+	stored, err := w.repo.PutWatch(ctx, watch)
+	if err != nil {
+		return domain.Watch{}, err
+	}
+
+	w.logger.Info("session watched",
+		requestid.Field(ctx),
+		zap.String("session_id", sessionID),
+		zap.String("user_id", userID),
+		zap.Strings("event_types", stored.EventTypes),
+	)
+	return *stored, nil
+}
+
+// Get returns the watch of a user on a session, or domain.ErrWatchNotFound
+func (w *Watches) Get(ctx context.Context, sessionID, userID string, isShareToken bool) (domain.Watch, error) {
+	sessionID, err := w.authorize(ctx, sessionID, userID, isShareToken)
+	if err != nil {
+		return domain.Watch{}, err
+	}
+
+	watch, err := w.repo.GetWatch(ctx, sessionID, userID)
+	if err != nil {
+		return domain.Watch{}, err
+	}
+	return *watch, nil
+}
+
+// Unwatch ends the watch of a user on a session; events already collected are still sent
+func (w *Watches) Unwatch(ctx context.Context, sessionID, userID string, isShareToken bool) error {
+	if isShareToken {
+		return domain.ErrForbidden
+	}
+	parsed, err := domain.ParseSessionID(sessionID)
+	if err != nil {
+		return err
+	}
+
+	// Users may stop watching sessions they can no longer read
+	if err := w.repo.DeleteWatch(ctx, parsed.String(), userID); err != nil {
+		return err
+	}
+
+	w.logger.Info("session unwatched",
+		requestid.Field(ctx),
+		zap.String("session_id", parsed.String()),
+		zap.String("user_id", userID),
+	)
+	return nil
+}
+
+// List returns the watches of a user, newest first
+func (w *Watches) List(ctx context.Context, userID string) ([]domain.Watch, error) {
+	watches, err := w.repo.ListUserWatches(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if watches == nil {
+		watches = []domain.Watch{}
+	}
+	return watches, nil
+}
+
+// authorize checks that the user may read the session and returns its canonical ID, the form
+// stored in audit_logs.session_id
+func (w *Watches) authorize(ctx context.Context, sessionID, userID string, isShareToken bool) (string, error) {
+	if isShareToken {
+		return "", domain.ErrForbidden
+	}
+	parsed, err := domain.ParseSessionID(sessionID)
+	if err != nil {
+		return "", err
+	}
+	if err := w.auth.AuthorizeSession(ctx, parsed, domain.UserID(userID), false); err != nil {
+		return "", err
+	}
+	return parsed.String(), nil
+}
+
+// normalizeEventTypes drops duplicate event types and applies the defaults when none are given
+func normalizeEventTypes(eventTypes []string) []string {
+	if len(eventTypes) == 0 {
+		defaults := make([]string, len(domain.DefaultWatchedEventTypes))
+		for i, eventType := range domain.DefaultWatchedEventTypes {
+			defaults[i] = string(eventType)
+		}
+		return defaults
+	}
+
+	normalized := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		if !slices.Contains(normalized, eventType) {
+			normalized = append(normalized, eventType)
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,154 @@
+package notify
+
+import (
+	"context"
+	"testing"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryWatches keeps watches in memory like the audit_session_watches table
+type memoryWatches struct {
+	watches []domain.Watch
+}
+
+func (r *memoryWatches) PutWatch(_ context.Context, watch domain.Watch) (*domain.Watch, error) {
+	for i := range r.watches {
+		if r.watches[i].SessionID == watch.SessionID && r.watches[i].UserID == watch.UserID {
+			r.watches[i].EventTypes = watch.EventTypes
+			stored := r.watches[i]
+			return &stored, nil
+		}
+	}
+	r.watches = append(r.watches, watch)
+	return &watch, nil
+}
+
+func (r *memoryWatches) GetWatch(_ context.Context, sessionID, userID string) (*domain.Watch, error) {
+	for _, watch := range r.watches {
+		if watch.SessionID == sessionID && watch.UserID == userID {
+			return &watch, nil
+		}
+	}
+	return nil, domain.ErrWatchNotFound
+}
+
+func (r *memoryWatches) DeleteWatch(_ context.Context, sessionID, userID string) error {
+	for i, watch := range r.watches {
+		if watch.SessionID == sessionID && watch.UserID == userID {
+			r.watches = append(r.watches[:i], r.watches[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrWatchNotFound
+}
+
+func (r *memoryWatches) ListUserWatches(_ context.Context, userID string) ([]domain.Watch, error) {
+	var watches []domain.Watch
+	for i := len(r.watches) - 1; i >= 0; i-- {
+		if r.watches[i].UserID == userID {
+			watches = append(watches, r.watches[i])
+		}
+	}
+	return watches, nil
+}
+
+// ownerAuthorizer lets only the owner read the test session
+type ownerAuthorizer struct{}
+
+func (ownerAuthorizer) AuthorizeSession(_ context.Context, sessionID domain.SessionID, userID domain.UserID, _ bool) error {
+	if userID != "owner" {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+func newTestWatches() (*Watches, *memoryWatches) {
+	repo := &memoryWatches{}
+	return NewWatches(repo, ownerAuthorizer{}, clock.NewFakeClock(testNow), zap.NewNop()), repo
+}
+
+func TestWatches_Watch(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		watches, _ := newTestWatches()
+
+		watch, err := watches.Watch(tenant.NewContext(context.Background(), "acme"), "550E8400-E29B-41D4-A716-446655440000", "owner", false, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.Watch{
+			SessionID:      testSession,
+			UserID:         "owner",
+			EventTypes:     []string{"share", "export", "comment", "comment_created"},
+			CreatedAt:      testNow,
+			OrganizationID: "acme",
+		}, watch)
+	})
+
+	t.Run("replaces event types", func(t *testing.T) {
+		watches, repo := newTestWatches()
+		ctx := context.Background()
+
+		_, err := watches.Watch(ctx, testSession, "owner", false, nil)
+		require.NoError(t, err)
+		watch, err := watches.Watch(ctx, testSession, "owner", false, []string{"export", "export", "unshare"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"export", "unshare"}, watch.EventTypes)
+		assert.Len(t, repo.watches, 1)
+	})
+
+	tests := []struct {
+		name         string
+		sessionID    string
+		userID       string
+		isShareToken bool
+		eventTypes   []string
+		expected     error
+	}{
+		{name: "unwatchable event type", sessionID: testSession, userID: "owner", eventTypes: []string{"edit"}, expected: domain.ErrInvalidWatch},
+		{name: "session not readable", sessionID: testSession, userID: "stranger", expected: domain.ErrForbidden},
+		{name: "share token", sessionID: testSession, userID: "owner", isShareToken: true, expected: domain.ErrForbidden},
+		{name: "invalid session ID", sessionID: "test-session", userID: "owner", expected: domain.ErrInvalidSessionID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			watches, repo := newTestWatches()
+
+			_, err := watches.Watch(context.Background(), tt.sessionID, tt.userID, tt.isShareToken, tt.eventTypes)
+
+			assert.ErrorIs(t, err, tt.expected)
+			assert.Empty(t, repo.watches)
+		})
+	}
+}
+
+func TestWatches_Unwatch(t *testing.T) {
+	watches, repo := newTestWatches()
+	ctx := context.Background()
+	_, err := watches.Watch(ctx, testSession, "owner", false, nil)
+	require.NoError(t, err)
+
+	_, err = watches.Get(ctx, testSession, "owner", false)
+	require.NoError(t, err)
+	require.NoError(t, watches.Unwatch(ctx, testSession, "owner", false))
+	assert.Empty(t, repo.watches)
+
+	assert.ErrorIs(t, watches.Unwatch(ctx, testSession, "owner", false), domain.ErrWatchNotFound)
+	_, err = watches.Get(ctx, testSession, "owner", false)
+	assert.ErrorIs(t, err, domain.ErrWatchNotFound)
+}
+
+func TestWatches_List(t *testing.T) {
+	watches, _ := newTestWatches()
+
+	list, err := watches.List(context.Background(), "owner")
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Watch{}, list)
+}
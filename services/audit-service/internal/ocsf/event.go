@@ -0,0 +1,287 @@
+// Package ocsf renders audit entries as events of the Open Cybersecurity Schema Framework (OCSF
+// 1.1.0) for exports and webhook deliveries. Session activity maps to Web Resources Activity,
+// security alerts to Detection Finding and configuration changes to Application Lifecycle.
+package ocsf
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/retention"
+)
+
+// Version is the OCSF schema version of the rendered events
+const Version = "1.1.0"
+
+// OCSF categories and classes the audit actions map to
+const (
+	CategoryFindings    = 2
+	CategoryApplication = 6
+
+	ClassDetectionFinding     = 2004
+	ClassWebResourcesActivity = 6001
+	ClassApplicationLifecycle = 6002
+)
+
+// Activities of the Web Resources Activity class; other classes use their own IDs
+const (
+	ActivityCreate = 1
+	ActivityRead   = 2
+	ActivityUpdate = 3
+	ActivityDelete = 4
+	ActivityExport = 7
+	ActivityShare  = 8
+	ActivityOther  = 99
+)
+
+// Severity IDs
+const (
+	SeverityInformational = 1
+	SeverityLow           = 2
+	SeverityMedium        = 3
+	SeverityHigh          = 4
+)
+
+// Event is an OCSF event; only the attributes the audit entries fill are modeled
+type Event struct {
+	ActivityID   int    `json:"activity_id"`
+	ActivityName string `json:"activity_name"`
+	CategoryUID  int    `json:"category_uid"`
+	CategoryName string `json:"category_name"`
+	ClassUID     int    `json:"class_uid"`
+	ClassName    string `json:"class_name"`
+	TypeUID      int    `json:"type_uid"`
+	TypeName     string `json:"type_name"`
+	SeverityID   int    `json:"severity_id"`
+	Severity     string `json:"severity"`
+	// Time is the time of the entry in milliseconds since the epoch
+	Time     int64    `json:"time"`
+	Message  string   `json:"message,omitempty"`
+	Metadata Metadata `json:"metadata"`
+
+	Actor        *Actor        `json:"actor,omitempty"`
+	SrcEndpoint  *Endpoint     `json:"src_endpoint,omitempty"`
+	HTTPRequest  *HTTPRequest  `json:"http_request,omitempty"`
+	WebResources []WebResource `json:"web_resources,omitempty"`
+	FindingInfo  *FindingInfo  `json:"finding_info,omitempty"`
+	// Unmapped holds the attributes of the entry that have no OCSF counterpart
+	Unmapped *Unmapped `json:"unmapped,omitempty"`
+}
+
+// Metadata describes the entry and the product that recorded it
+type Metadata struct {
+	Version        string  `json:"version"`
+	Product        Product `json:"product"`
+	UID            string  `json:"uid"`
+	CorrelationUID string  `json:"correlation_uid,omitempty"`
+	TenantUID      string  `json:"tenant_uid,omitempty"`
+}
+
+// Product identifies the audit service
+type Product struct {
+	Name       string `json:"name"`
+	VendorName string `json:"vendor_name"`
+}
+
+// Actor is the user who performed the action
+type Actor struct {
+	User User `json:"user"`
+}
+
+// User is a user by ID; TypeID is 1 for users and 3 for the service itself
+type User struct {
+	UID    string `json:"uid"`
+	TypeID int    `json:"type_id"`
+	Type   string `json:"type"`
+}
+
+// Endpoint is the client address of the request
+type Endpoint struct {
+	IP string `json:"ip"`
+}
+
+// HTTPRequest carries the user agent of the request
+type HTTPRequest struct {
+	UserAgent string `json:"user_agent"`
+}
+
+// WebResource is the session, slide, shape, comment thread or comment the action applied to
+type WebResource struct {
+	UID  string `json:"uid"`
+	Type string `json:"type"`
+}
+
+// FindingInfo describes a security alert
+type FindingInfo struct {
+	UID   string   `json:"uid"`
+	Title string   `json:"title"`
+	Desc  string   `json:"desc,omitempty"`
+	Types []string `json:"types,omitempty"`
+}
+
+// Unmapped holds the audit specific attributes of an entry
+type Unmapped struct {
+	Type           string          `json:"type"`
+	Details        json.RawMessage `json:"details,omitempty"`
+	ParentEventID  string          `json:"parent_event_id,omitempty"`
+	SchemaVersion  int             `json:"schema_version,omitempty"`
+	RedactedFields []string        `json:"redacted_fields,omitempty"`
+}
+
+// mapping places an audit action in an OCSF class
+type mapping struct {
+	class        int
+	activityID   int
+	activityName string
+}
+
+// webActivity maps an action to a Web Resources Activity
+func webActivity(activityID int, activityName string) mapping {
+	return mapping{class: ClassWebResourcesActivity, activityID: activityID, activityName: activityName}
+}
+
+// mappings places the built-in actions; other event types are Web Resources Activity of the
+// Other activity, named by their type
+var mappings = map[domain.AuditAction]mapping{
+	domain.ActionCreate:             webActivity(ActivityCreate, "Create"),
+	domain.ActionView:               webActivity(ActivityRead, "Read"),
+	domain.ActionEdit:               webActivity(ActivityUpdate, "Update"),
+	domain.ActionMerge:              webActivity(ActivityUpdate, "Update"),
+	domain.ActionReorder:            webActivity(ActivityUpdate, "Update"),
+	domain.ActionComment:            webActivity(ActivityCreate, "Create"),
+	domain.ActionCommentCreated:     webActivity(ActivityCreate, "Create"),
+	domain.ActionCommentEdited:      webActivity(ActivityUpdate, "Update"),
+	domain.ActionCommentResolved:    webActivity(ActivityUpdate, "Update"),
+	domain.ActionCommentDeleted:     webActivity(ActivityDelete, "Delete"),
+	domain.ActionAnnotationCreated:  webActivity(ActivityCreate, "Create"),
+	domain.ActionAnnotationEdited:   webActivity(ActivityUpdate, "Update"),
+	domain.ActionAnnotationDeleted:  webActivity(ActivityDelete, "Delete"),
+	domain.ActionSubmittedForReview: webActivity(ActivityUpdate, "Update"),
+	domain.ActionApproved:           webActivity(ActivityUpdate, "Update"),
+	domain.ActionRejected:           webActivity(ActivityUpdate, "Update"),
+	domain.ActionReopened:           webActivity(ActivityUpdate, "Update"),
+	domain.ActionTMHit:              webActivity(ActivityUpdate, "Update"),
+	domain.ActionTMOverride:         webActivity(ActivityUpdate, "Update"),
+	domain.ActionThumbnail:          webActivity(ActivityCreate, "Create"),
+	domain.ActionExport:             webActivity(ActivityExport, "Export"),
+	domain.ActionExportLifecycle:    webActivity(ActivityExport, "Export"),
+	domain.ActionShare:              webActivity(ActivityShare, "Share"),
+	domain.ActionUnshare:            webActivity(ActivityOther, "Unshare"),
+	domain.ActionShareRedeemed:      webActivity(ActivityRead, "Read"),
+	domain.ActionShareExpired:       webActivity(ActivityOther, "Expire"),
+	domain.ActionMTRequest:          webActivity(ActivityOther, "Translate"),
+	domain.ActionExternalChange:     webActivity(ActivityUpdate, "Update"),
+	domain.ActionRetentionPurge:     webActivity(ActivityDelete, "Delete"),
+	domain.ActionUserErasure:        webActivity(ActivityDelete, "Delete"),
+	domain.ActionAuthFailure:        webActivity(ActivityOther, "Deny"),
+
+	domain.ActionSecurityAlert: {class: ClassDetectionFinding, activityID: 1, activityName: "Create"},
+	// Application Lifecycle has no activity for configuration changes; Update is the closest
+	domain.ActionConfigChanged: {class: ClassApplicationLifecycle, activityID: 8, activityName: "Update"},
+}
+
+var (
+	classNames = map[int]string{
+		ClassDetectionFinding:     "Detection Finding",
+		ClassWebResourcesActivity: "Web Resources Activity",
+		ClassApplicationLifecycle: "Application Lifecycle",
+	}
+	categoryNames = map[int]string{
+		CategoryFindings:    "Findings",
+		CategoryApplication: "Application Activity",
+	}
+	severityNames = map[int]string{
+		SeverityInformational: "Informational",
+		SeverityLow:           "Low",
+		SeverityMedium:        "Medium",
+		SeverityHigh:          "High",
+	}
+)
+
+// alertDetails are the details of a security_alert event read into the finding
+type alertDetails struct {
+	Rule        string `json:"rule"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+}
+
+// FromEntry renders an audit entry as OCSF event
+func FromEntry(entry domain.AuditEntry) Event {
+	m, ok := mappings[domain.AuditAction(entry.Type)]
+	if !ok {
+		m = webActivity(ActivityOther, entry.Type)
+	}
+	category := m.class / 1000
+
+	event := Event{
+		ActivityID:   m.activityID,
+		ActivityName: m.activityName,
+		CategoryUID:  category,
+		CategoryName: categoryNames[category],
+		ClassUID:     m.class,
+		ClassName:    classNames[m.class],
+		TypeUID:      m.class*100 + m.activityID,
+		TypeName:     fmt.Sprintf("%s: %s", classNames[m.class], m.activityName),
+		SeverityID:   SeverityInformational,
+		Time:         entry.Timestamp.UnixMilli(),
+		Metadata: Metadata{
+			Version:        Version,
+			Product:        Product{Name: "audit-service", VendorName: "pptxTrans"},
+			UID:            entry.ID,
+			CorrelationUID: entry.CorrelationID,
+			TenantUID:      entry.OrganizationID,
+		},
+		Actor: &Actor{User: User{UID: entry.UserID, TypeID: 1, Type: "User"}},
+		Unmapped: &Unmapped{
+			Type:           entry.Type,
+			Details:        entry.Details,
+			ParentEventID:  entry.ParentEventID,
+			SchemaVersion:  entry.SchemaVersion,
+			RedactedFields: entry.RedactedFields,
+		},
+	}
+	if entry.UserID == retention.SystemUserID {
+		event.Actor.User.TypeID, event.Actor.User.Type = 3, "System"
+	}
+	if entry.IPAddress != "" {
+		event.SrcEndpoint = &Endpoint{IP: entry.IPAddress}
+	}
+	if entry.UserAgent != "" {
+		event.HTTPRequest = &HTTPRequest{UserAgent: entry.UserAgent}
+	}
+
+	switch m.class {
+	case ClassWebResourcesActivity:
+		event.WebResources = []WebResource{{UID: entry.SessionID, Type: "session"}}
+		for _, resource := range []WebResource{
+			{UID: entry.SlideID, Type: "slide"},
+			{UID: entry.ShapeID, Type: "shape"},
+			{UID: entry.ThreadID, Type: "thread"},
+			{UID: entry.CommentID, Type: "comment"},
+		} {
+			if resource.UID != "" {
+				event.WebResources = append(event.WebResources, resource)
+			}
+		}
+	case ClassDetectionFinding:
+		alert, _ := domain.DecodeDetails[alertDetails](entry)
+		event.FindingInfo = &FindingInfo{UID: entry.ID, Title: alert.Rule, Desc: alert.Description}
+		if alert.Rule != "" {
+			event.FindingInfo.Types = []string{alert.Rule}
+		} else {
+			event.FindingInfo.Title = entry.Type
+		}
+		event.Message = alert.Description
+		switch alert.Severity {
+		case "low":
+			event.SeverityID = SeverityLow
+		case "medium":
+			event.SeverityID = SeverityMedium
+		case "high":
+			event.SeverityID = SeverityHigh
+		}
+	}
+	event.Severity = severityNames[event.SeverityID]
+	return event
+}
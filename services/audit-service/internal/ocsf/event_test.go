@@ -0,0 +1,122 @@
+package ocsf
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTime = time.Date(2024, 2, 1, 9, 30, 0, 0, time.UTC)
+
+func TestFromEntry(t *testing.T) {
+	entry := domain.AuditEntry{
+		ID:             "550e8400-e29b-41d4-a716-446655440009",
+		SessionID:      "550e8400-e29b-41d4-a716-446655440000",
+		UserID:         "user-1",
+		Type:           "edit",
+		Timestamp:      testTime,
+		Details:        json.RawMessage(`{"slideId":"slide-3","shapeId":"shape-12"}`),
+		IPAddress:      "203.0.113.7",
+		UserAgent:      "Mozilla/5.0",
+		CorrelationID:  "export-7f3a",
+		SchemaVersion:  2,
+		SlideID:        "slide-3",
+		ShapeID:        "shape-12",
+		OrganizationID: "acme",
+	}
+
+	data, err := json.Marshal(FromEntry(entry))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"activity_id": 3,
+		"activity_name": "Update",
+		"category_uid": 6,
+		"category_name": "Application Activity",
+		"class_uid": 6001,
+		"class_name": "Web Resources Activity",
+		"type_uid": 600103,
+		"type_name": "Web Resources Activity: Update",
+		"severity_id": 1,
+		"severity": "Informational",
+		"time": 1706779800000,
+		"metadata": {
+			"version": "1.1.0",
+			"product": {"name": "audit-service", "vendor_name": "pptxTrans"},
+			"uid": "550e8400-e29b-41d4-a716-446655440009",
+			"correlation_uid": "export-7f3a",
+			"tenant_uid": "acme"
+		},
+		"actor": {"user": {"uid": "user-1", "type_id": 1, "type": "User"}},
+		"src_endpoint": {"ip": "203.0.113.7"},
+		"http_request": {"user_agent": "Mozilla/5.0"},
+		"web_resources": [
+			{"uid": "550e8400-e29b-41d4-a716-446655440000", "type": "session"},
+			{"uid": "slide-3", "type": "slide"},
+			{"uid": "shape-12", "type": "shape"}
+		],
+		"unmapped": {
+			"type": "edit",
+			"details": {"slideId": "slide-3", "shapeId": "shape-12"},
+			"schema_version": 2
+		}
+	}`, string(data))
+}
+
+func TestFromEntry_Classes(t *testing.T) {
+	tests := []struct {
+		eventType    string
+		typeUID      int
+		activityName string
+	}{
+		{eventType: "view", typeUID: 600102, activityName: "Read"},
+		{eventType: "comment_created", typeUID: 600101, activityName: "Create"},
+		{eventType: "comment_deleted", typeUID: 600104, activityName: "Delete"},
+		{eventType: "export", typeUID: 600107, activityName: "Export"},
+		{eventType: "share", typeUID: 600108, activityName: "Share"},
+		{eventType: "unshare", typeUID: 600199, activityName: "Unshare"},
+		{eventType: "retention_purge", typeUID: 600104, activityName: "Delete"},
+		{eventType: "config_changed", typeUID: 600208, activityName: "Update"},
+		{eventType: "slide_translated", typeUID: 600199, activityName: "slide_translated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.eventType, func(t *testing.T) {
+			event := FromEntry(domain.AuditEntry{ID: "event-1", SessionID: "session-1", UserID: "user-1", Type: tt.eventType, Timestamp: testTime})
+
+			assert.Equal(t, tt.typeUID, event.TypeUID)
+			assert.Equal(t, tt.typeUID/100, event.ClassUID)
+			assert.Equal(t, tt.typeUID%100, event.ActivityID)
+			assert.Equal(t, tt.activityName, event.ActivityName)
+			assert.Equal(t, tt.eventType, event.Unmapped.Type)
+			if event.ClassUID == ClassWebResourcesActivity {
+				assert.Equal(t, []WebResource{{UID: "session-1", Type: "session"}}, event.WebResources)
+			}
+		})
+	}
+}
+
+func TestFromEntry_SecurityAlert(t *testing.T) {
+	event := FromEntry(domain.AuditEntry{
+		ID:        "alert-1",
+		SessionID: "session-1",
+		UserID:    "system",
+		Type:      "security_alert",
+		Timestamp: testTime,
+		Details:   json.RawMessage(`{"rule":"mass_deletion","severity":"high","userId":"user-2","description":"25 deletions within 10m0s","eventIds":["event-1"]}`),
+	})
+
+	assert.Equal(t, ClassDetectionFinding, event.ClassUID)
+	assert.Equal(t, CategoryFindings, event.CategoryUID)
+	assert.Equal(t, "Findings", event.CategoryName)
+	assert.Equal(t, 200401, event.TypeUID)
+	assert.Equal(t, SeverityHigh, event.SeverityID)
+	assert.Equal(t, "High", event.Severity)
+	assert.Equal(t, &FindingInfo{UID: "alert-1", Title: "mass_deletion", Desc: "25 deletions within 10m0s", Types: []string{"mass_deletion"}}, event.FindingInfo)
+	assert.Equal(t, "25 deletions within 10m0s", event.Message)
+	assert.Equal(t, User{UID: "system", TypeID: 3, Type: "System"}, event.Actor.User)
+	assert.Empty(t, event.WebResources)
+}
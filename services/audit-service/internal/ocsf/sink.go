@@ -0,0 +1,35 @@
+package ocsf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/outbox"
+)
+
+// Sink delivers outbox messages with their audit entry rendered as OCSF event
+type Sink struct {
+	next outbox.Sink
+}
+
+// NewSink wraps a sink, such as an outbox.WebhookSink, which then signs and posts the OCSF event
+func NewSink(next outbox.Sink) *Sink {
+	return &Sink{next: next}
+}
+
+// Deliver converts the message payload and hands the message to the wrapped sink
+func (s *Sink) Deliver(ctx context.Context, message domain.OutboxMessage) error {
+	var entry domain.AuditEntry
+	if err := json.Unmarshal(message.Payload, &entry); err != nil {
+		return fmt.Errorf("failed to decode outbox payload: %w", err)
+	}
+
+	payload, err := json.Marshal(FromEntry(entry))
+	if err != nil {
+		return fmt.Errorf("failed to encode OCSF event: %w", err)
+	}
+	message.Payload = payload
+	return s.next.Deliver(ctx, message)
+}
@@ -0,0 +1,49 @@
+package ocsf
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"audit-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink keeps the delivered messages
+type recordingSink struct {
+	messages []domain.OutboxMessage
+}
+
+func (s *recordingSink) Deliver(_ context.Context, message domain.OutboxMessage) error {
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+func TestSink_Deliver(t *testing.T) {
+	payload, err := domain.NewOutboxPayload(domain.AuditEntry{
+		ID: "event-1", SessionID: "session-1", UserID: "user-1", Type: "share", Timestamp: testTime, IPAddress: "203.0.113.7",
+	})
+	require.NoError(t, err)
+	next := &recordingSink{}
+
+	require.NoError(t, NewSink(next).Deliver(context.Background(), domain.OutboxMessage{
+		ID: 7, EventID: "event-1", SessionID: "session-1", Type: "share", Payload: payload, Attempts: 2,
+	}))
+
+	require.Len(t, next.messages, 1)
+	message := next.messages[0]
+	assert.Equal(t, "event-1", message.EventID)
+	assert.Equal(t, 2, message.Attempts)
+	var event Event
+	require.NoError(t, json.Unmarshal(message.Payload, &event))
+	assert.Equal(t, 600108, event.TypeUID)
+	assert.Equal(t, "event-1", event.Metadata.UID)
+	// Consumers never receive the client IP
+	assert.Nil(t, event.SrcEndpoint)
+
+	err = NewSink(next).Deliver(context.Background(), domain.OutboxMessage{EventID: "event-2", Payload: json.RawMessage(`[]`)})
+	assert.Error(t, err)
+	assert.Len(t, next.messages, 1)
+}
@@ -0,0 +1,289 @@
+// Package openapi converts the Swagger 2.0 document generated by swag from the handler
+// annotations into an OpenAPI 3.0 document, which client generators such as
+// openapi-typescript expect.
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Version is the OpenAPI version of converted documents
+const Version = "3.0.3"
+
+// parameterSchemaFields are the fields of a Swagger 2.0 non-body parameter that describe its
+// value and move into the schema of an OpenAPI 3 parameter
+var parameterSchemaFields = []string{
+	"type", "format", "items", "enum", "default", "minimum", "maximum",
+	"exclusiveMinimum", "exclusiveMaximum", "minLength", "maxLength", "pattern",
+	"minItems", "maxItems", "uniqueItems", "multipleOf",
+}
+
+// Convert turns a Swagger 2.0 JSON document into an indented OpenAPI 3.0 JSON document
+func Convert(swagger []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(swagger, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse swagger document: %w", err)
+	}
+	if doc["swagger"] != "2.0" {
+		return nil, errors.New("not a swagger 2.0 document")
+	}
+
+	consumes := stringList(doc["consumes"])
+	produces := stringList(doc["produces"])
+
+	out := map[string]interface{}{
+		"openapi": Version,
+		"info":    doc["info"],
+	}
+	if basePath, _ := doc["basePath"].(string); basePath != "" {
+		// Relative to the host serving the document, so the spec works in every deployment
+		out["servers"] = []interface{}{map[string]interface{}{"url": basePath}}
+	}
+	if tags, ok := doc["tags"]; ok {
+		out["tags"] = tags
+	}
+	if security, ok := doc["security"]; ok {
+		out["security"] = security
+	}
+
+	components := map[string]interface{}{}
+	if definitions, ok := doc["definitions"].(map[string]interface{}); ok {
+		components["schemas"] = definitions
+	}
+	if schemes, ok := doc["securityDefinitions"].(map[string]interface{}); ok {
+		components["securitySchemes"] = convertSecuritySchemes(schemes)
+	}
+	out["components"] = components
+
+	paths := map[string]interface{}{}
+	if swaggerPaths, ok := doc["paths"].(map[string]interface{}); ok {
+		for path, item := range swaggerPaths {
+			operations, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			converted := map[string]interface{}{}
+			for method, operation := range operations {
+				op, ok := operation.(map[string]interface{})
+				if !ok {
+					converted[method] = operation
+					continue
+				}
+				converted[method] = convertOperation(op, consumes, produces)
+			}
+			paths[path] = converted
+		}
+	}
+	out["paths"] = paths
+
+	rewriteRefs(out)
+	return json.MarshalIndent(out, "", "    ")
+}
+
+// convertOperation moves body parameters into a request body and response schemas into
+// content keyed by media type
+func convertOperation(op map[string]interface{}, consumes, produces []string) map[string]interface{} {
+	if list := stringList(op["consumes"]); len(list) > 0 {
+		consumes = list
+	}
+	if list := stringList(op["produces"]); len(list) > 0 {
+		produces = list
+	}
+	if len(consumes) == 0 {
+		consumes = []string{"application/json"}
+	}
+	if len(produces) == 0 {
+		produces = []string{"application/json"}
+	}
+
+	out := map[string]interface{}{}
+	for key, value := range op {
+		switch key {
+		case "consumes", "produces", "parameters", "responses":
+		default:
+			out[key] = value
+		}
+	}
+
+	var parameters []interface{}
+	for _, raw := range list(op["parameters"]) {
+		param, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if param["in"] == "body" {
+			body := map[string]interface{}{
+				"content": content(consumes, param["schema"]),
+			}
+			copyFields(body, param, "description", "required")
+			out["requestBody"] = body
+			continue
+		}
+		parameters = append(parameters, convertParameter(param))
+	}
+	if len(parameters) > 0 {
+		out["parameters"] = parameters
+	}
+
+	responses := map[string]interface{}{}
+	if swaggerResponses, ok := op["responses"].(map[string]interface{}); ok {
+		for status, raw := range swaggerResponses {
+			response, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			converted := map[string]interface{}{"description": response["description"]}
+			if schema, ok := response["schema"]; ok {
+				converted["content"] = content(produces, schema)
+			}
+			if headers, ok := response["headers"].(map[string]interface{}); ok {
+				convertedHeaders := map[string]interface{}{}
+				for name, header := range headers {
+					convertedHeaders[name] = convertHeader(header)
+				}
+				converted["headers"] = convertedHeaders
+			}
+			responses[status] = converted
+		}
+	}
+	out["responses"] = responses
+	return out
+}
+
+// convertParameter moves the value description of a path, query or header parameter into its schema
+func convertParameter(param map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	copyFields(out, param, "name", "in", "description", "required")
+	if param["in"] == "path" {
+		out["required"] = true
+	}
+
+	schema := map[string]interface{}{}
+	copyFields(schema, param, parameterSchemaFields...)
+	out["schema"] = schema
+
+	switch param["collectionFormat"] {
+	case "csv":
+		out["style"], out["explode"] = "form", false
+	case "multi":
+		out["style"], out["explode"] = "form", true
+	}
+	return out
+}
+
+// convertHeader moves the value description of a response header into its schema
+func convertHeader(raw interface{}) interface{} {
+	header, ok := raw.(map[string]interface{})
+	if !ok {
+		return raw
+	}
+	out := map[string]interface{}{}
+	copyFields(out, header, "description")
+	schema := map[string]interface{}{}
+	copyFields(schema, header, parameterSchemaFields...)
+	out["schema"] = schema
+	return out
+}
+
+// content describes a body in each media type. Only JSON bodies follow the model; CSV, NDJSON,
+// event streams and protobuf are documented as text or binary.
+func content(mediaTypes []string, schema interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	for _, mediaType := range mediaTypes {
+		switch {
+		case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+			out[mediaType] = map[string]interface{}{"schema": schema}
+		case mediaType == "application/protobuf" || mediaType == "application/octet-stream":
+			out[mediaType] = map[string]interface{}{"schema": map[string]interface{}{"type": "string", "format": "binary"}}
+		default:
+			out[mediaType] = map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}
+		}
+	}
+	return out
+}
+
+// convertSecuritySchemes maps Swagger 2.0 security definitions to OpenAPI 3 schemes
+func convertSecuritySchemes(schemes map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	for name, raw := range schemes {
+		scheme, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		converted := map[string]interface{}{}
+		switch scheme["type"] {
+		case "basic":
+			converted["type"], converted["scheme"] = "http", "basic"
+		case "oauth2":
+			converted["type"] = "oauth2"
+			flow := map[string]interface{}{}
+			copyFields(flow, scheme, "authorizationUrl", "tokenUrl", "scopes")
+			flowName := scheme["flow"]
+			if flowName == "application" {
+				flowName = "clientCredentials"
+			} else if flowName == "accessCode" {
+				flowName = "authorizationCode"
+			}
+			if name, ok := flowName.(string); ok {
+				converted["flows"] = map[string]interface{}{name: flow}
+			}
+		case "apiKey":
+			// Swagger 2.0 cannot express bearer tokens, so swag documents them as an API key
+			// in the Authorization header
+			if scheme["in"] == "header" && scheme["name"] == "Authorization" {
+				converted["type"], converted["scheme"], converted["bearerFormat"] = "http", "bearer", "JWT"
+				break
+			}
+			copyFields(converted, scheme, "type", "name", "in")
+		default:
+			copyFields(converted, scheme, "type", "name", "in")
+		}
+		copyFields(converted, scheme, "description")
+		out[name] = converted
+	}
+	return out
+}
+
+// rewriteRefs points definition references at the component schemas
+func rewriteRefs(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				v[key] = strings.Replace(ref, "#/definitions/", "#/components/schemas/", 1)
+				continue
+			}
+			rewriteRefs(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			rewriteRefs(child)
+		}
+	}
+}
+
+func copyFields(dst, src map[string]interface{}, fields ...string) {
+	for _, field := range fields {
+		if value, ok := src[field]; ok {
+			dst[field] = value
+		}
+	}
+}
+
+func list(value interface{}) []interface{} {
+	items, _ := value.([]interface{})
+	return items
+}
+
+func stringList(value interface{}) []string {
+	var out []string
+	for _, item := range list(value) {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
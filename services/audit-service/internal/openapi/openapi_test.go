@@ -0,0 +1,143 @@
+package openapi
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSwagger = `{
+    "swagger": "2.0",
+    "info": {"title": "Audit Service API", "version": "1.0.0"},
+    "basePath": "/api/v1",
+    "securityDefinitions": {
+        "APIKeyAuth": {"type": "apiKey", "name": "X-API-Key", "in": "header"},
+        "BearerAuth": {"type": "apiKey", "name": "Authorization", "in": "header"}
+    },
+    "paths": {
+        "/events/batch": {
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "consumes": ["application/json", "application/protobuf"],
+                "produces": ["application/json"],
+                "parameters": [
+                    {"description": "Events to create", "name": "request", "in": "body", "required": true,
+                     "schema": {"type": "array", "items": {"$ref": "#/definitions/handlers.CreateEventRequest"}}},
+                    {"type": "string", "description": "Deduplication key", "name": "Idempotency-Key", "in": "header"}
+                ],
+                "responses": {
+                    "201": {"description": "Created", "schema": {"$ref": "#/definitions/handlers.BatchCreateEventResponse"},
+                            "headers": {"ETag": {"type": "string", "description": "Entity tag"}}},
+                    "400": {"description": "Bad Request", "schema": {"$ref": "#/definitions/domain.APIError"}},
+                    "204": {"description": "No Content"}
+                }
+            }
+        },
+        "/sessions/{sessionId}/events/export": {
+            "get": {
+                "produces": ["application/json", "text/csv"],
+                "parameters": [
+                    {"type": "string", "name": "sessionId", "in": "path", "required": true},
+                    {"type": "array", "items": {"type": "string"}, "collectionFormat": "csv", "name": "type", "in": "query"},
+                    {"type": "integer", "default": 50, "maximum": 100, "name": "limit", "in": "query"}
+                ],
+                "responses": {"200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/domain.AuditEntry"}}}}
+            }
+        }
+    },
+    "definitions": {
+        "domain.APIError": {"type": "object", "properties": {"error": {"type": "string"}}},
+        "domain.AuditResponse": {"type": "object", "properties": {"items": {"type": "array", "items": {"$ref": "#/definitions/domain.AuditEntry"}}}}
+    }
+}`
+
+func convertTest(t *testing.T, swagger string) map[string]interface{} {
+	t.Helper()
+	spec, err := Convert([]byte(swagger))
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(spec, &doc))
+	return doc
+}
+
+func TestConvert(t *testing.T) {
+	doc := convertTest(t, testSwagger)
+
+	assert.Equal(t, Version, doc["openapi"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"url": "/api/v1"}}, doc["servers"])
+
+	components := doc["components"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"APIKeyAuth": map[string]interface{}{"type": "apiKey", "name": "X-API-Key", "in": "header"},
+		"BearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+	}, components["securitySchemes"])
+	// References point at the component schemas
+	schemas := components["schemas"].(map[string]interface{})
+	response := schemas["domain.AuditResponse"].(map[string]interface{})
+	items := response["properties"].(map[string]interface{})["items"].(map[string]interface{})
+	assert.Equal(t, "#/components/schemas/domain.AuditEntry", items["items"].(map[string]interface{})["$ref"])
+
+	paths := doc["paths"].(map[string]interface{})
+	batch := paths["/events/batch"].(map[string]interface{})["post"].(map[string]interface{})
+	assert.Equal(t, []interface{}{map[string]interface{}{"BearerAuth": []interface{}{}}}, batch["security"])
+	assert.Equal(t, map[string]interface{}{
+		"description": "Events to create",
+		"required":    true,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": map[string]interface{}{
+				"type": "array", "items": map[string]interface{}{"$ref": "#/components/schemas/handlers.CreateEventRequest"},
+			}},
+			"application/protobuf": map[string]interface{}{"schema": map[string]interface{}{"type": "string", "format": "binary"}},
+		},
+	}, batch["requestBody"])
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"name": "Idempotency-Key", "in": "header", "description": "Deduplication key",
+		"schema": map[string]interface{}{"type": "string"},
+	}}, batch["parameters"])
+
+	responses := batch["responses"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"description": "Bad Request",
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": map[string]interface{}{"$ref": "#/components/schemas/domain.APIError"}},
+		},
+	}, responses["400"])
+	assert.Equal(t, map[string]interface{}{"description": "Entity tag", "schema": map[string]interface{}{"type": "string"}},
+		responses["201"].(map[string]interface{})["headers"].(map[string]interface{})["ETag"])
+	assert.Equal(t, map[string]interface{}{"description": "No Content"}, responses["204"])
+
+	export := paths["/sessions/{sessionId}/events/export"].(map[string]interface{})["get"].(map[string]interface{})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "sessionId", "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"}},
+		map[string]interface{}{"name": "type", "in": "query", "style": "form", "explode": false, "schema": map[string]interface{}{
+			"type": "array", "items": map[string]interface{}{"type": "string"},
+		}},
+		map[string]interface{}{"name": "limit", "in": "query", "schema": map[string]interface{}{"type": "integer", "default": float64(50), "maximum": float64(100)}},
+	}, export["parameters"])
+	// Non-JSON bodies are documented as text
+	content := export["responses"].(map[string]interface{})["200"].(map[string]interface{})["content"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}, content["text/csv"])
+}
+
+func TestConvert_RejectsOtherDocuments(t *testing.T) {
+	_, err := Convert([]byte(`{"openapi": "3.0.3"}`))
+	assert.Error(t, err)
+
+	_, err = Convert([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+// The served document must match the handler annotations; run make docs after changing them
+func TestGeneratedDocumentIsCurrent(t *testing.T) {
+	swagger, err := os.ReadFile("../../docs/swagger.json")
+	require.NoError(t, err)
+	generated, err := os.ReadFile("../../docs/openapi.json")
+	require.NoError(t, err)
+
+	spec, err := Convert(swagger)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(spec), string(generated), "docs/openapi.json is stale, run make docs")
+}
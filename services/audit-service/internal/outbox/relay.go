@@ -0,0 +1,217 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/pkg/clock"
+
+	"go.uber.org/zap"
+)
+
+// Delivery results recorded in the outbox metrics
+const (
+	ResultDelivered = "delivered"
+	ResultRetry     = "retry"
+	ResultDead      = "dead"
+)
+
+// ClaimLease is how long a claimed message is hidden from other relays while it is delivered
+const ClaimLease = 5 * time.Minute
+
+// Backoff bounds for redelivering failed messages
+const (
+	minBackoff = 5 * time.Second
+	maxBackoff = time.Hour
+)
+
+// Repository gives access to the messages waiting in the outbox
+type Repository interface {
+	ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error)
+	CompleteOutbox(ctx context.Context, ids []int64) error
+	FailOutbox(ctx context.Context, id int64, retryAt time.Time, lastError string, dead bool) error
+}
+
+// Sink forwards a message to its consumers
+type Sink interface {
+	Deliver(ctx context.Context, message domain.OutboxMessage) error
+}
+
+// Config controls how often the outbox is polled and how often a message is retried
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+}
+
+// Relay forwards outbox messages with at-least-once delivery.
+// A message is removed only after the sink accepted it, so a crash between delivery and
+// completion redelivers it; consumers deduplicate on the event ID.
+type Relay struct {
+	repo    Repository
+	sink    Sink
+	cfg     Config
+	clock   clock.Clock
+	metrics *metrics.Metrics
+	logger  *zap.Logger
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// New creates a relay; call Start to begin polling
+func New(repo Repository, sink Sink, cfg Config, clk clock.Clock, m *metrics.Metrics, logger *zap.Logger) *Relay {
+	return &Relay{
+		repo:    repo,
+		sink:    sink,
+		cfg:     cfg,
+		clock:   clk,
+		metrics: m,
+		logger:  logger,
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Start drains the outbox immediately and then once per poll interval
+func (r *Relay) Start() {
+	go r.run()
+}
+
+// Close stops polling and waits for a running delivery to be cancelled
+func (r *Relay) Close(ctx context.Context) error {
+	r.closeOnce.Do(func() { close(r.stop) })
+
+	select {
+	case <-r.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("outbox relay did not stop: %w", ctx.Err())
+	}
+}
+
+// run drains the outbox on the configured interval until Close is called
+func (r *Relay) run() {
+	defer close(r.stopped)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-r.stop
+		cancel()
+	}()
+
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Run(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Error("outbox relay failed", zap.Error(err))
+		}
+
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run delivers due messages in batches until the outbox has none left
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		messages, err := r.repo.ClaimOutbox(ctx, r.cfg.BatchSize, ClaimLease)
+		if err != nil {
+			return err
+		}
+
+		if err := r.deliverBatch(ctx, messages); err != nil {
+			return err
+		}
+
+		// A short batch means nothing else is due
+		if len(messages) < r.cfg.BatchSize {
+			return nil
+		}
+	}
+}
+
+// deliverBatch forwards claimed messages and records the outcome of each
+func (r *Relay) deliverBatch(ctx context.Context, messages []domain.OutboxMessage) error {
+	var delivered []int64
+	for _, message := range messages {
+		err := r.sink.Deliver(ctx, message)
+		if err == nil {
+			delivered = append(delivered, message.ID)
+			continue
+		}
+		if ctx.Err() != nil {
+			// Shutting down; the lease expires and another run picks the message up
+			break
+		}
+
+		if err := r.fail(ctx, message, err); err != nil {
+			return err
+		}
+	}
+
+	if len(delivered) == 0 {
+		return nil
+	}
+
+	// The context may be cancelled by now; completing delivered messages avoids redelivering them
+	if err := r.repo.CompleteOutbox(context.WithoutCancel(ctx), delivered); err != nil {
+		return err
+	}
+	for range delivered {
+		r.metrics.ObserveOutboxDelivery(ResultDelivered)
+	}
+	return nil
+}
+
+// fail schedules a failed message for redelivery, giving up after the maximum number of attempts
+func (r *Relay) fail(ctx context.Context, message domain.OutboxMessage, deliveryErr error) error {
+	dead := message.Attempts >= r.cfg.MaxAttempts
+	retryAt := r.clock.Now().Add(Backoff(message.Attempts))
+
+	if err := r.repo.FailOutbox(ctx, message.ID, retryAt, deliveryErr.Error(), dead); err != nil {
+		return err
+	}
+
+	if dead {
+		r.metrics.ObserveOutboxDelivery(ResultDead)
+		r.logger.Error("giving up on outbox message",
+			zap.String("event_id", message.EventID),
+			zap.Int("attempts", message.Attempts),
+			zap.Error(deliveryErr),
+		)
+		return nil
+	}
+
+	r.metrics.ObserveOutboxDelivery(ResultRetry)
+	r.logger.Warn("outbox delivery failed, retrying",
+		zap.String("event_id", message.EventID),
+		zap.Int("attempts", message.Attempts),
+		zap.Time("retry_at", retryAt),
+		zap.Error(deliveryErr),
+	)
+	return nil
+}
+
+// Backoff returns the delay before redelivering a message that failed the given number of attempts
+func Backoff(attempts int) time.Duration {
+	delay := minBackoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
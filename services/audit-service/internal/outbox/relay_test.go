@@ -0,0 +1,145 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// failure records a FailOutbox call
+type failure struct {
+	id      int64
+	retryAt time.Time
+	dead    bool
+}
+
+// fakeRepository hands out queued batches and records how messages were settled
+type fakeRepository struct {
+	batches   [][]domain.OutboxMessage
+	claimErr  error
+	completed []int64
+	failed    []failure
+}
+
+func (f *fakeRepository) ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error) {
+	if f.claimErr != nil {
+		return nil, f.claimErr
+	}
+	if len(f.batches) == 0 {
+		return nil, nil
+	}
+	batch := f.batches[0]
+	f.batches = f.batches[1:]
+	return batch, nil
+}
+
+func (f *fakeRepository) CompleteOutbox(ctx context.Context, ids []int64) error {
+	f.completed = append(f.completed, ids...)
+	return nil
+}
+
+func (f *fakeRepository) FailOutbox(ctx context.Context, id int64, retryAt time.Time, lastError string, dead bool) error {
+	f.failed = append(f.failed, failure{id: id, retryAt: retryAt, dead: dead})
+	return nil
+}
+
+// fakeSink fails the deliveries of the listed event IDs
+type fakeSink struct {
+	failing   map[string]bool
+	delivered []string
+}
+
+func (s *fakeSink) Deliver(ctx context.Context, message domain.OutboxMessage) error {
+	if s.failing[message.EventID] {
+		return errors.New("connection refused")
+	}
+	s.delivered = append(s.delivered, message.EventID)
+	return nil
+}
+
+func newTestRelay(repo Repository, sink Sink) *Relay {
+	return New(repo, sink, Config{PollInterval: time.Second, BatchSize: 2, MaxAttempts: 3},
+		clock.NewFakeClock(testNow), metrics.New(), zap.NewNop())
+}
+
+func message(id int64, eventID string, attempts int) domain.OutboxMessage {
+	return domain.OutboxMessage{ID: id, EventID: eventID, Type: "edit", Attempts: attempts}
+}
+
+func TestRelay_RunDrainsFullBatches(t *testing.T) {
+	repo := &fakeRepository{batches: [][]domain.OutboxMessage{
+		{message(1, "event-1", 1), message(2, "event-2", 1)},
+		{message(3, "event-3", 1)},
+	}}
+	sink := &fakeSink{}
+
+	require.NoError(t, newTestRelay(repo, sink).Run(context.Background()))
+
+	assert.Equal(t, []string{"event-1", "event-2", "event-3"}, sink.delivered)
+	assert.Equal(t, []int64{1, 2, 3}, repo.completed)
+	assert.Empty(t, repo.failed)
+	assert.Empty(t, repo.batches)
+}
+
+func TestRelay_RunRetriesFailedDeliveries(t *testing.T) {
+	repo := &fakeRepository{batches: [][]domain.OutboxMessage{
+		{message(1, "event-1", 2), message(2, "event-2", 1)},
+		{},
+	}}
+	sink := &fakeSink{failing: map[string]bool{"event-1": true}}
+
+	require.NoError(t, newTestRelay(repo, sink).Run(context.Background()))
+
+	// A failed message does not hold back the others
+	assert.Equal(t, []int64{2}, repo.completed)
+	assert.Equal(t, []failure{{id: 1, retryAt: testNow.Add(10 * time.Second)}}, repo.failed)
+}
+
+func TestRelay_RunGivesUpAfterMaxAttempts(t *testing.T) {
+	repo := &fakeRepository{batches: [][]domain.OutboxMessage{{message(1, "event-1", 3)}}}
+	sink := &fakeSink{failing: map[string]bool{"event-1": true}}
+
+	require.NoError(t, newTestRelay(repo, sink).Run(context.Background()))
+
+	require.Len(t, repo.failed, 1)
+	assert.True(t, repo.failed[0].dead)
+	assert.Empty(t, repo.completed)
+}
+
+func TestRelay_RunClaimError(t *testing.T) {
+	repo := &fakeRepository{claimErr: errors.New("database unavailable")}
+
+	err := newTestRelay(repo, &fakeSink{}).Run(context.Background())
+	assert.EqualError(t, err, "database unavailable")
+}
+
+func TestRelay_StartAndClose(t *testing.T) {
+	repo := &fakeRepository{batches: [][]domain.OutboxMessage{{message(1, "event-1", 1)}}}
+	relay := newTestRelay(repo, &fakeSink{})
+
+	relay.Start()
+	assert.Eventually(t, func() bool {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		return relay.Close(ctx) == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 5*time.Second, Backoff(0))
+	assert.Equal(t, 5*time.Second, Backoff(1))
+	assert.Equal(t, 10*time.Second, Backoff(2))
+	assert.Equal(t, 40*time.Second, Backoff(4))
+	assert.Equal(t, time.Hour, Backoff(20))
+}
@@ -0,0 +1,98 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+)
+
+// Headers sent with every webhook delivery
+const (
+	HeaderEventID   = "X-Audit-Event-ID"
+	HeaderEventType = "X-Audit-Event-Type"
+	HeaderAttempt   = "X-Audit-Delivery-Attempt"
+	HeaderTimestamp = "X-Audit-Timestamp"
+	HeaderSignature = "X-Audit-Signature"
+)
+
+// WebhookSink posts each message to a fixed list of URLs.
+// A message counts as delivered only when every URL answered with a 2xx status; otherwise it is
+// redelivered to all of them, so receivers deduplicate on the X-Audit-Event-ID header.
+type WebhookSink struct {
+	urls   []string
+	secret []byte
+	client *http.Client
+	clock  clock.Clock
+}
+
+// NewWebhookSink creates a sink for the given URLs; deliveries are signed when secret is set
+func NewWebhookSink(urls []string, secret string, client *http.Client, clk clock.Clock) *WebhookSink {
+	return &WebhookSink{
+		urls:   urls,
+		secret: []byte(secret),
+		client: client,
+		clock:  clk,
+	}
+}
+
+// Deliver posts the message payload to every URL
+func (s *WebhookSink) Deliver(ctx context.Context, message domain.OutboxMessage) error {
+	var errs []error
+	for _, url := range s.urls {
+		if err := s.post(ctx, url, message); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// post sends the message to one URL
+func (s *WebhookSink) post(ctx context.Context, url string, message domain.OutboxMessage) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(message.Payload))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", message.EventID)
+	req.Header.Set(HeaderEventID, message.EventID)
+	req.Header.Set(HeaderEventType, message.Type)
+	req.Header.Set(HeaderAttempt, strconv.Itoa(message.Attempts))
+
+	if len(s.secret) > 0 {
+		timestamp := strconv.FormatInt(s.clock.Now().Unix(), 10)
+		req.Header.Set(HeaderTimestamp, timestamp)
+		req.Header.Set(HeaderSignature, "sha256="+Sign(s.secret, timestamp, message.Payload))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook %s failed: %w", url, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s responded with status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "timestamp.payload", as sent in the X-Audit-Signature header
+func Sign(secret []byte, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,78 @@
+package outbox
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSink_Deliver(t *testing.T) {
+	payload := []byte(`{"id":"event-1","type":"edit"}`)
+
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink([]string{server.URL}, "webhook-secret", server.Client(), clock.NewFakeClock(testNow))
+	err := sink.Deliver(context.Background(), domain.OutboxMessage{
+		ID: 7, EventID: "event-1", Type: "edit", Payload: payload, Attempts: 2,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, payload, body)
+	assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	assert.Equal(t, "event-1", received.Header.Get("Idempotency-Key"))
+	assert.Equal(t, "event-1", received.Header.Get(HeaderEventID))
+	assert.Equal(t, "edit", received.Header.Get(HeaderEventType))
+	assert.Equal(t, "2", received.Header.Get(HeaderAttempt))
+
+	timestamp := strconv.FormatInt(testNow.Unix(), 10)
+	assert.Equal(t, timestamp, received.Header.Get(HeaderTimestamp))
+	assert.Equal(t, "sha256="+Sign([]byte("webhook-secret"), timestamp, payload), received.Header.Get(HeaderSignature))
+}
+
+func TestWebhookSink_DeliverUnsigned(t *testing.T) {
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(HeaderSignature)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink([]string{server.URL}, "", server.Client(), clock.NewFakeClock(testNow))
+	require.NoError(t, sink.Deliver(context.Background(), domain.OutboxMessage{EventID: "event-1", Payload: []byte(`{}`)}))
+	assert.Empty(t, signature)
+}
+
+func TestWebhookSink_DeliverFailsWhenAnyURLFails(t *testing.T) {
+	calls := 0
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer ok.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	sink := NewWebhookSink([]string{ok.URL, failing.URL}, "", http.DefaultClient, clock.NewFakeClock(testNow))
+	err := sink.Deliver(context.Background(), domain.OutboxMessage{EventID: "event-1", Payload: []byte(`{}`)})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "responded with status 503")
+	// The healthy URL still received the message and will see it again on the retry
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,151 @@
+// Package preflight checks a configuration before rollout: it validates the configuration,
+// probes the storage, cache and key endpoints it points at, and reports the outcome with the
+// redacted effective configuration.
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"audit-service/internal/config"
+	"audit-service/internal/metrics"
+	"audit-service/internal/repository"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/jwt"
+	"audit-service/pkg/mtls"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// probeTimeout bounds each probe
+const probeTimeout = 10 * time.Second
+
+// Check statuses
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// Check is the outcome of one check
+type Check struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"durationMs"`
+}
+
+// Report is the outcome of the self-check
+type Report struct {
+	// OK is false when any check failed
+	OK     bool    `json:"ok"`
+	Checks []Check `json:"checks"`
+	// Config is the redacted effective configuration; it is missing when it could not be read
+	Config map[string]interface{} `json:"config,omitempty"`
+}
+
+// Probe tests the connection to one dependency
+type Probe struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Run validates the configuration and, when it is valid, runs the probes one after the other.
+// A configuration that could not be read is reported as a failed config check.
+func Run(ctx context.Context, cfg *config.Config, readErr error, probes []Probe, clk clock.Clock) Report {
+	if readErr != nil {
+		return Report{Checks: []Check{{Name: "config", Status: StatusFailed, Error: readErr.Error()}}}
+	}
+
+	report := Report{OK: true, Config: cfg.Redacted()}
+	validation := Check{Name: "config", Status: StatusOK}
+	if err := cfg.Validate(); err != nil {
+		validation.Status = StatusFailed
+		validation.Error = err.Error()
+		report.OK = false
+	}
+	report.Checks = append(report.Checks, validation)
+
+	for _, probe := range probes {
+		// Probes of an invalid configuration would only repeat its errors
+		if validation.Status != StatusOK {
+			report.Checks = append(report.Checks, Check{Name: probe.Name, Status: StatusSkipped})
+			continue
+		}
+		check := runProbe(ctx, probe, clk)
+		if check.Status == StatusFailed {
+			report.OK = false
+		}
+		report.Checks = append(report.Checks, check)
+	}
+	return report
+}
+
+// runProbe runs one probe within probeTimeout
+func runProbe(ctx context.Context, probe Probe, clk clock.Clock) Check {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	start := clk.Now()
+	err := probe.Run(ctx)
+	check := Check{Name: probe.Name, Status: StatusOK, DurationMS: clk.Now().Sub(start).Milliseconds()}
+	if err != nil {
+		check.Status = StatusFailed
+		check.Error = err.Error()
+	}
+	return check
+}
+
+// Probes returns the probes of the dependencies the configuration uses: the storage backend,
+// Redis when it backs the cache, the Supabase JWKS endpoint and the certificates of the
+// internal listener
+func Probes(cfg *config.Config, clk clock.Clock, logger *zap.Logger) []Probe {
+	probes := []Probe{{Name: "storage", Run: func(ctx context.Context) error {
+		return probeStorage(ctx, cfg, clk, logger)
+	}}}
+
+	if cfg.CacheBackend == "redis" {
+		probes = append(probes, Probe{Name: "cache", Run: func(ctx context.Context) error {
+			opts, err := redis.ParseURL(cfg.RedisURL)
+			if err != nil {
+				return fmt.Errorf("invalid REDIS_URL: %w", err)
+			}
+			client := redis.NewClient(opts)
+			defer client.Close()
+			return client.Ping(ctx).Err()
+		}})
+	}
+
+	if cfg.SupabaseJWKSURL != "" {
+		probes = append(probes, Probe{Name: "jwks", Run: func(ctx context.Context) error {
+			keySet := jwt.NewKeySet(cfg.SupabaseJWKSURL, &http.Client{Timeout: cfg.HTTPTimeout}, cfg.SupabaseJWKSRefreshInterval, clk, logger)
+			return keySet.Refresh(ctx)
+		}})
+	}
+
+	if cfg.InternalMTLSEnabled() {
+		probes = append(probes, Probe{Name: "internal_tls", Run: func(context.Context) error {
+			_, err := mtls.ServerConfig(cfg.InternalTLSCertFile, cfg.InternalTLSKeyFile, cfg.InternalTLSCAFile, cfg.InternalTLSAllowedSANs)
+			return err
+		}})
+	}
+	return probes
+}
+
+// probeStorage opens the storage backend and reads the custom event types, which reaches
+// Supabase or the database with the configured credentials and checks the schema is in place
+func probeStorage(ctx context.Context, cfg *config.Config, clk clock.Clock, logger *zap.Logger) error {
+	store, err := repository.OpenStorage(ctx, cfg, metrics.New(), clk, logger)
+	if err != nil {
+		return err
+	}
+	defer store.Close(ctx)
+
+	if _, err := store.ListEventTypes(ctx); err != nil {
+		return fmt.Errorf("failed to query %s storage: %w", cfg.StorageBackend, err)
+	}
+	return nil
+}
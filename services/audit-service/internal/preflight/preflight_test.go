@@ -0,0 +1,83 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"audit-service/internal/config"
+	"audit-service/pkg/clock"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRun_ReadError(t *testing.T) {
+	report := Run(context.Background(), nil, errors.New("invalid BRUTE_FORCE_WINDOW"), nil, clock.New())
+
+	assert.False(t, report.OK)
+	assert.Nil(t, report.Config)
+	assert.Equal(t, []Check{{Name: "config", Status: StatusFailed, Error: "invalid BRUTE_FORCE_WINDOW"}}, report.Checks)
+}
+
+func TestRun_InvalidConfigSkipsProbes(t *testing.T) {
+	probed := false
+	probes := []Probe{{Name: "storage", Run: func(context.Context) error {
+		probed = true
+		return nil
+	}}}
+
+	report := Run(context.Background(), &config.Config{SupabaseServiceRoleKey: "service-role-key"}, nil, probes, clock.New())
+
+	assert.False(t, report.OK)
+	assert.False(t, probed)
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, "config", report.Checks[0].Name)
+	assert.Equal(t, StatusFailed, report.Checks[0].Status)
+	assert.NotEmpty(t, report.Checks[0].Error)
+	assert.Equal(t, Check{Name: "storage", Status: StatusSkipped}, report.Checks[1])
+	// The configuration is reported redacted
+	assert.Equal(t, "[REDACTED]", report.Config["SupabaseServiceRoleKey"])
+}
+
+func TestRunProbe(t *testing.T) {
+	clk := clock.NewFakeClock(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC))
+
+	check := runProbe(context.Background(), Probe{Name: "cache", Run: func(ctx context.Context) error {
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline)
+		clk.Advance(25 * time.Millisecond)
+		return nil
+	}}, clk)
+	assert.Equal(t, Check{Name: "cache", Status: StatusOK, DurationMS: 25}, check)
+
+	check = runProbe(context.Background(), Probe{Name: "cache", Run: func(context.Context) error {
+		return errors.New("connection refused")
+	}}, clk)
+	assert.Equal(t, Check{Name: "cache", Status: StatusFailed, Error: "connection refused"}, check)
+}
+
+func TestProbes(t *testing.T) {
+	server := miniredis.RunT(t)
+	cfg := &config.Config{
+		StorageBackend: "memory",
+		CacheBackend:   "redis",
+		RedisURL:       "redis://" + server.Addr(),
+	}
+	clk := clock.New()
+
+	probes := Probes(cfg, clk, zap.NewNop())
+	require.Len(t, probes, 2)
+	for _, probe := range probes {
+		assert.Equal(t, StatusOK, runProbe(context.Background(), probe, clk).Status, probe.Name)
+	}
+
+	// The cache probe fails once Redis is gone
+	server.Close()
+	check := runProbe(context.Background(), probes[1], clk)
+	assert.Equal(t, "cache", check.Name)
+	assert.Equal(t, StatusFailed, check.Status)
+}
@@ -0,0 +1,201 @@
+// Package quota enforces daily limits on the events recorded per session and per user, so
+// runaway automation cannot flood storage. Counts are kept per UTC day and reset at midnight.
+package quota
+
+import (
+	"context"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/pkg/clock"
+
+	"go.uber.org/zap"
+)
+
+// counterTTL keeps the counts of a day until the day has passed everywhere
+const counterTTL = 48 * time.Hour
+
+// Store holds the event counts of each quota and day
+type Store interface {
+	// Add adds n, which may be negative, to the count under key and returns the new count. A
+	// count created by Add expires after ttl.
+	Add(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error)
+	// Get returns the count under key, 0 when there is none
+	Get(ctx context.Context, key string) (int64, error)
+}
+
+// Config holds the daily limits; zero disables a limit
+type Config struct {
+	SessionDaily int64
+	UserDaily    int64
+}
+
+// Enforcer counts ingested events against the daily quotas. Events are only counted against
+// quotas with a limit. Reserve and Release of a nil Enforcer do nothing.
+type Enforcer struct {
+	store Store
+	// cfg holds the limits, which can be changed while the enforcer is in use
+	cfg     atomic.Pointer[Config]
+	clock   clock.Clock
+	metrics *metrics.Metrics
+	logger  *zap.Logger
+}
+
+// New creates an enforcer counting in store
+func New(store Store, cfg Config, clk clock.Clock, m *metrics.Metrics, logger *zap.Logger) *Enforcer {
+	e := &Enforcer{
+		store:   store,
+		clock:   clk,
+		metrics: m,
+		logger:  logger,
+	}
+	e.SetConfig(cfg)
+	return e
+}
+
+// SetConfig changes the daily limits; counts already taken are kept
+func (e *Enforcer) SetConfig(cfg Config) {
+	e.cfg.Store(&cfg)
+}
+
+// Reservation holds the counts taken by Reserve, so they can be given back with Release when
+// the events are not stored
+type Reservation struct {
+	counts []count
+}
+
+// count is the number of events taken from one quota
+type count struct {
+	key string
+	n   int64
+}
+
+// usage is the events a batch takes from one quota
+type usage struct {
+	scope string
+	id    string
+	limit int64
+	n     int64
+}
+
+// Reserve counts entries against the quotas of their sessions and users. When one would be
+// exceeded nothing is counted and a *domain.QuotaExceededError is returned. Storage failures
+// are logged and let the entries through, as losing audit events is worse than a missed limit.
+func (e *Enforcer) Reserve(ctx context.Context, entries []domain.AuditEntry) (Reservation, error) {
+	if e == nil || len(entries) == 0 {
+		return Reservation{}, nil
+	}
+
+	now := e.clock.Now().UTC()
+	var reservation Reservation
+	for _, u := range e.usages(entries) {
+		key := counterKey(u.scope, u.id, now)
+		used, err := e.store.Add(ctx, key, u.n, counterTTL)
+		if err != nil {
+			e.logger.Warn("failed to count events against quota",
+				zap.String("scope", u.scope),
+				zap.String("id", u.id),
+				zap.Error(err),
+			)
+			continue
+		}
+		reservation.counts = append(reservation.counts, count{key: key, n: u.n})
+
+		if used > u.limit {
+			e.Release(ctx, reservation)
+			e.metrics.ObserveQuotaRejection(u.scope)
+			usage := newUsage(u.scope, u.limit, used-u.n, now)
+			return Reservation{}, &domain.QuotaExceededError{
+				Usage:      usage,
+				Requested:  u.n,
+				RetryAfter: usage.ResetAt.Sub(now),
+			}
+		}
+	}
+	return reservation, nil
+}
+
+// Release gives back the counts of a reservation whose events were not stored
+func (e *Enforcer) Release(ctx context.Context, reservation Reservation) {
+	if e == nil {
+		return
+	}
+	for _, c := range reservation.counts {
+		if _, err := e.store.Add(ctx, c.key, -c.n, counterTTL); err != nil {
+			e.logger.Warn("failed to release quota", zap.String("key", c.key), zap.Error(err))
+		}
+	}
+}
+
+// Status returns today's use of the quotas of a session and a user; unlimited quotas report no use
+func (e *Enforcer) Status(ctx context.Context, sessionID, userID string) (domain.QuotaStatus, error) {
+	now := e.clock.Now().UTC()
+	sessionUsed, err := e.store.Get(ctx, counterKey(domain.QuotaScopeSession, sessionID, now))
+	if err != nil {
+		return domain.QuotaStatus{}, err
+	}
+	userUsed, err := e.store.Get(ctx, counterKey(domain.QuotaScopeUser, userID, now))
+	if err != nil {
+		return domain.QuotaStatus{}, err
+	}
+
+	cfg := e.cfg.Load()
+	status := domain.QuotaStatus{SessionID: sessionID, UserID: userID}
+	status.Session = newUsage(domain.QuotaScopeSession, cfg.SessionDaily, sessionUsed, now)
+	status.User = newUsage(domain.QuotaScopeUser, cfg.UserDaily, userUsed, now)
+	return status, nil
+}
+
+// usages groups entries by the limited quotas they count against, in a stable order so
+// concurrent batches take counts in the same order
+func (e *Enforcer) usages(entries []domain.AuditEntry) []usage {
+	sessions := map[string]int64{}
+	users := map[string]int64{}
+	for _, entry := range entries {
+		sessions[entry.SessionID]++
+		users[entry.UserID]++
+	}
+
+	cfg := e.cfg.Load()
+	var usages []usage
+	if cfg.SessionDaily > 0 {
+		usages = append(usages, grouped(domain.QuotaScopeSession, cfg.SessionDaily, sessions)...)
+	}
+	if cfg.UserDaily > 0 {
+		usages = append(usages, grouped(domain.QuotaScopeUser, cfg.UserDaily, users)...)
+	}
+	return usages
+}
+
+// grouped returns the usage of each ID of one scope, sorted by ID
+func grouped(scope string, limit int64, counts map[string]int64) []usage {
+	usages := make([]usage, 0, len(counts))
+	for id, n := range counts {
+		usages = append(usages, usage{scope: scope, id: id, limit: limit, n: n})
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].id < usages[j].id })
+	return usages
+}
+
+// counterKey names the count of a quota on the UTC day of now
+func counterKey(scope, id string, now time.Time) string {
+	return scope + ":" + id + ":" + now.Format(time.DateOnly)
+}
+
+// newUsage describes a quota with used events on the UTC day of now
+func newUsage(scope string, limit, used int64, now time.Time) domain.QuotaUsage {
+	remaining := int64(0)
+	if limit > 0 {
+		remaining = max(limit-used, 0)
+	}
+	return domain.QuotaUsage{
+		Scope:     scope,
+		Limit:     limit,
+		Used:      used,
+		Remaining: remaining,
+		ResetAt:   now.Truncate(24 * time.Hour).Add(24 * time.Hour),
+	}
+}
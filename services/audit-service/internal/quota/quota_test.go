@@ -0,0 +1,161 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/pkg/clock"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2024, 1, 15, 22, 30, 0, 0, time.UTC)
+
+var testReset = time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+
+func testEntries(sessionID, userID string, n int) []domain.AuditEntry {
+	entries := make([]domain.AuditEntry, n)
+	for i := range entries {
+		entries[i] = domain.AuditEntry{SessionID: sessionID, UserID: userID, Type: "edit"}
+	}
+	return entries
+}
+
+func newTestEnforcer(cfg Config) (*Enforcer, *clock.FakeClock) {
+	fakeClock := clock.NewFakeClock(testNow)
+	return New(NewMemoryStore(fakeClock), cfg, fakeClock, metrics.New(), zap.NewNop()), fakeClock
+}
+
+func TestEnforcer_SessionQuota(t *testing.T) {
+	enforcer, _ := newTestEnforcer(Config{SessionDaily: 5})
+	ctx := context.Background()
+
+	_, err := enforcer.Reserve(ctx, testEntries("session-1", "user-1", 3))
+	require.NoError(t, err)
+
+	// A batch that does not fit is rejected as a whole
+	_, err = enforcer.Reserve(ctx, testEntries("session-1", "user-2", 3))
+	var quotaErr *domain.QuotaExceededError
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, domain.QuotaUsage{Scope: "session", Limit: 5, Used: 3, Remaining: 2, ResetAt: testReset}, quotaErr.Usage)
+	assert.Equal(t, int64(3), quotaErr.Requested)
+	assert.Equal(t, 90*time.Minute, quotaErr.RetryAfter)
+
+	// Other sessions have quotas of their own
+	_, err = enforcer.Reserve(ctx, testEntries("session-2", "user-1", 5))
+	require.NoError(t, err)
+
+	_, err = enforcer.Reserve(ctx, testEntries("session-1", "user-2", 2))
+	require.NoError(t, err)
+}
+
+func TestEnforcer_UserQuotaRollsBackSessionCount(t *testing.T) {
+	enforcer, _ := newTestEnforcer(Config{SessionDaily: 10, UserDaily: 4})
+	ctx := context.Background()
+
+	_, err := enforcer.Reserve(ctx, testEntries("session-1", "user-1", 4))
+	require.NoError(t, err)
+
+	_, err = enforcer.Reserve(ctx, testEntries("session-2", "user-1", 1))
+	var quotaErr *domain.QuotaExceededError
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, "user", quotaErr.Usage.Scope)
+
+	// The rejected event was not counted against its session
+	status, err := enforcer.Status(ctx, "session-2", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), status.Session.Used)
+	assert.Equal(t, domain.QuotaUsage{Scope: "user", Limit: 4, Used: 4, Remaining: 0, ResetAt: testReset}, status.User)
+}
+
+func TestEnforcer_ReleaseAndDailyReset(t *testing.T) {
+	enforcer, fakeClock := newTestEnforcer(Config{SessionDaily: 3})
+	ctx := context.Background()
+
+	reservation, err := enforcer.Reserve(ctx, testEntries("session-1", "user-1", 3))
+	require.NoError(t, err)
+
+	// Events that were not stored give their count back
+	enforcer.Release(ctx, reservation)
+	status, err := enforcer.Status(ctx, "session-1", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), status.Session.Used)
+	assert.Equal(t, int64(3), status.Session.Remaining)
+
+	_, err = enforcer.Reserve(ctx, testEntries("session-1", "user-1", 3))
+	require.NoError(t, err)
+	_, err = enforcer.Reserve(ctx, testEntries("session-1", "user-1", 1))
+	require.ErrorIs(t, err, domain.ErrQuotaExceeded)
+
+	// The quota resets at midnight UTC
+	fakeClock.Advance(2 * time.Hour)
+	_, err = enforcer.Reserve(ctx, testEntries("session-1", "user-1", 1))
+	require.NoError(t, err)
+}
+
+func TestEnforcer_Unlimited(t *testing.T) {
+	enforcer, _ := newTestEnforcer(Config{})
+	ctx := context.Background()
+
+	_, err := enforcer.Reserve(ctx, testEntries("session-1", "user-1", 1000))
+	require.NoError(t, err)
+
+	status, err := enforcer.Status(ctx, "session-1", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.QuotaUsage{Scope: "session", ResetAt: testReset}, status.Session)
+
+	var nilEnforcer *Enforcer
+	_, err = nilEnforcer.Reserve(ctx, testEntries("session-1", "user-1", 1))
+	require.NoError(t, err)
+}
+
+// failingStore fails every command
+type failingStore struct{}
+
+func (failingStore) Add(context.Context, string, int64, time.Duration) (int64, error) {
+	return 0, errors.New("unavailable")
+}
+
+func (failingStore) Get(context.Context, string) (int64, error) {
+	return 0, errors.New("unavailable")
+}
+
+func TestEnforcer_StoreFailureAcceptsEvents(t *testing.T) {
+	enforcer := New(failingStore{}, Config{SessionDaily: 1}, clock.NewFakeClock(testNow), metrics.New(), zap.NewNop())
+
+	_, err := enforcer.Reserve(context.Background(), testEntries("session-1", "user-1", 5))
+	require.NoError(t, err)
+}
+
+func TestRedisStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	store := NewRedisStore(client, "audit:quota:", time.Second)
+	ctx := context.Background()
+
+	count, err := store.Get(ctx, "session:s1:2024-01-15")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	count, err = store.Add(ctx, "session:s1:2024-01-15", 3, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.Equal(t, time.Hour, server.TTL("audit:quota:session:s1:2024-01-15"))
+
+	count, err = store.Add(ctx, "session:s1:2024-01-15", -1, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	count, err = store.Get(ctx, "session:s1:2024-01-15")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
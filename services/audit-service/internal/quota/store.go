@@ -0,0 +1,115 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"audit-service/pkg/clock"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MemoryStore keeps counts in process memory, so each replica enforces the quotas on its own
+type MemoryStore struct {
+	mu     sync.Mutex
+	counts map[string]*memoryCount
+	// lastPrune is when expired counts were last dropped
+	lastPrune time.Time
+	clock     clock.Clock
+}
+
+// memoryCount is one count and when it expires
+type memoryCount struct {
+	n         int64
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates an empty in-process store whose counts expire by clk
+func NewMemoryStore(clk clock.Clock) *MemoryStore {
+	return &MemoryStore{counts: map[string]*memoryCount{}, clock: clk}
+}
+
+// Add adds n to the count under key and returns the new count
+func (s *MemoryStore) Add(_ context.Context, key string, n int64, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	s.prune(now)
+	c, ok := s.counts[key]
+	if !ok || !now.Before(c.expiresAt) {
+		c = &memoryCount{expiresAt: now.Add(ttl)}
+		s.counts[key] = c
+	}
+	c.n += n
+	return c.n, nil
+}
+
+// Get returns the count under key
+func (s *MemoryStore) Get(_ context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.counts[key]
+	if !ok || !s.clock.Now().Before(c.expiresAt) {
+		return 0, nil
+	}
+	return c.n, nil
+}
+
+// prune drops expired counts at most once an hour
+func (s *MemoryStore) prune(now time.Time) {
+	if now.Sub(s.lastPrune) < time.Hour {
+		return
+	}
+	s.lastPrune = now
+	for key, c := range s.counts {
+		if !now.Before(c.expiresAt) {
+			delete(s.counts, key)
+		}
+	}
+}
+
+// RedisStore shares counts between service replicas through Redis
+type RedisStore struct {
+	client  redis.UniversalClient
+	prefix  string
+	timeout time.Duration
+}
+
+// NewRedisStore creates a store that keeps counts under prefix, giving each command timeout to complete
+func NewRedisStore(client redis.UniversalClient, prefix string, timeout time.Duration) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix, timeout: timeout}
+}
+
+// Add adds n to the count under key and returns the new count
+func (r *RedisStore) Add(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	count, err := r.client.IncrBy(ctx, r.prefix+key, n).Result()
+	if err != nil {
+		return 0, err
+	}
+	// The first increment of a day creates the count
+	if count == n {
+		if err := r.client.Expire(ctx, r.prefix+key, ttl).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// Get returns the count under key
+func (r *RedisStore) Get(ctx context.Context, key string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	count, err := r.client.Get(ctx, r.prefix+key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return count, err
+}
@@ -0,0 +1,305 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/repository"
+	"audit-service/internal/retention"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Results of handling a change, as counted in the metrics
+const (
+	ResultRecorded = "recorded"
+	ResultMatched  = "matched"
+	ResultSkipped  = "skipped"
+	ResultFailed   = "failed"
+)
+
+const (
+	// flushInterval is how often changes whose match window has passed are handled
+	flushInterval = time.Second
+
+	// maxPending bounds the changes waiting for their match window; the oldest is handled early
+	maxPending = 10000
+
+	// maxCached bounds the remembered slide sessions and session organizations
+	maxCached = 10000
+
+	// activityLimit is how many events around a change are inspected for API activity
+	activityLimit = 50
+)
+
+// Details is the details payload of an external_change event
+type Details struct {
+	Source    string `json:"source"`
+	Table     string `json:"table"`
+	Operation string `json:"operation"`
+	RecordID  string `json:"recordId,omitempty"`
+	SlideID   string `json:"slideId,omitempty"`
+	// ChangedColumns lists the updated columns; only known when the table has REPLICA IDENTITY FULL
+	ChangedColumns  []string  `json:"changedColumns,omitempty"`
+	CommitTimestamp time.Time `json:"commitTimestamp"`
+}
+
+// pendingChange is a change waiting until events recorded for it through the API can be seen
+type pendingChange struct {
+	change Change
+	due    time.Time
+}
+
+// process handles received changes once their match window has passed, until Close is called
+func (c *Consumer) process(ctx context.Context) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case change := <-c.changes:
+			c.enqueue(ctx, change)
+		case <-ticker.C:
+			c.flush(ctx)
+		}
+	}
+}
+
+// enqueue schedules a change to be handled once its match window has passed
+func (c *Consumer) enqueue(ctx context.Context, change Change) {
+	if c.cfg.MatchWindow <= 0 {
+		c.Handle(ctx, change)
+		return
+	}
+	if len(c.pending) >= maxPending {
+		oldest := c.pending[0]
+		c.pending = c.pending[1:]
+		c.Handle(ctx, oldest.change)
+	}
+	c.pending = append(c.pending, pendingChange{change: change, due: c.clock.Now().Add(c.cfg.MatchWindow)})
+}
+
+// flush handles the pending changes that are due
+func (c *Consumer) flush(ctx context.Context) {
+	now := c.clock.Now()
+	due := 0
+	for due < len(c.pending) && !c.pending[due].due.After(now) {
+		c.Handle(ctx, c.pending[due].change)
+		due++
+	}
+	c.pending = c.pending[due:]
+}
+
+// Handle records a change as an external_change event in its session unless an event recorded
+// through the API within the match window accounts for it. It returns the result counted in
+// the metrics; failures are logged.
+func (c *Consumer) Handle(ctx context.Context, change Change) string {
+	result, err := c.handle(ctx, change)
+	if err != nil && ctx.Err() == nil {
+		c.logger.Error("failed to record realtime change",
+			zap.String("table", change.Table),
+			zap.String("operation", change.Type),
+			zap.Error(err),
+		)
+	}
+	c.metrics.ObserveRealtimeChange(change.Table, result)
+	return result
+}
+
+func (c *Consumer) handle(ctx context.Context, change Change) (string, error) {
+	committedAt, err := time.Parse(time.RFC3339Nano, change.CommitTimestamp)
+	if err != nil {
+		committedAt = c.clock.Now()
+	}
+
+	sessionID, slideID, err := c.sessionOf(ctx, change)
+	if err != nil {
+		return ResultFailed, err
+	}
+	parsed, err := domain.ParseSessionID(sessionID)
+	if err != nil {
+		c.logger.Debug("skipping realtime change without a session",
+			zap.String("table", change.Table),
+			zap.String("operation", change.Type),
+		)
+		return ResultSkipped, nil
+	}
+	sessionID = parsed.String()
+
+	if c.cfg.MatchWindow > 0 {
+		matched, err := c.recordedThroughAPI(ctx, sessionID, committedAt)
+		if err != nil {
+			return ResultFailed, err
+		}
+		if matched {
+			return ResultMatched, nil
+		}
+	}
+
+	organizationID, err := c.organizationOf(ctx, sessionID)
+	if err != nil {
+		return ResultFailed, err
+	}
+
+	details, _ := domain.EncodeDetails(Details{
+		Source:          "realtime",
+		Table:           change.Table,
+		Operation:       strings.ToLower(change.Type),
+		RecordID:        stringValue(change, "id"),
+		SlideID:         slideID,
+		ChangedColumns:  changedColumns(change),
+		CommitTimestamp: committedAt,
+	})
+	entry := domain.AuditEntry{
+		ID:             uuid.New().String(),
+		SessionID:      sessionID,
+		UserID:         retention.SystemUserID,
+		Type:           string(domain.ActionExternalChange),
+		Timestamp:      committedAt,
+		Details:        details,
+		OrganizationID: organizationID,
+		SlideID:        slideID,
+	}
+	if err := c.record(ctx, []domain.AuditEntry{entry}); err != nil {
+		return ResultFailed, err
+	}
+
+	c.logger.Info("recorded change made outside the API",
+		zap.String("session_id", sessionID),
+		zap.String("table", change.Table),
+		zap.String("operation", change.Type),
+	)
+	return ResultRecorded, nil
+}
+
+// sessionOf returns the session a change belongs to. Deleted shapes can only be attributed
+// when the table sends the old row in full.
+func (c *Consumer) sessionOf(ctx context.Context, change Change) (sessionID, slideID string, err error) {
+	switch change.Table {
+	case TableSessions:
+		return stringValue(change, "id"), "", nil
+	case TableShapes:
+		slideID = stringValue(change, "slide_id")
+		if slideID == "" {
+			return "", "", nil
+		}
+		sessionID, err = c.slideSession(ctx, slideID)
+		return sessionID, slideID, err
+	}
+	return "", "", nil
+}
+
+// slideSession looks up the session of a slide; slides never move between sessions
+func (c *Consumer) slideSession(ctx context.Context, slideID string) (string, error) {
+	if sessionID, ok := c.slideSessions[slideID]; ok {
+		return sessionID, nil
+	}
+
+	data, _, err := c.client.Get(ctx, "/slides", repository.NewQuery("session_id").
+		Where(repository.Eq("id", slideID)).
+		Limit(1))
+	if err != nil {
+		return "", fmt.Errorf("failed to look up slide %s: %w", slideID, err)
+	}
+	var slides []struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal(data, &slides); err != nil {
+		return "", fmt.Errorf("failed to parse slide %s: %w", slideID, err)
+	}
+	// Shapes of deleted slides cannot be attributed
+	if len(slides) == 0 {
+		return "", nil
+	}
+
+	remember(c.slideSessions, slideID, slides[0].SessionID)
+	return slides[0].SessionID, nil
+}
+
+// recordedThroughAPI reports whether a user or service recorded an event in the session within
+// the match window around the change
+func (c *Consumer) recordedThroughAPI(ctx context.Context, sessionID string, at time.Time) (bool, error) {
+	entries, _, err := c.repo.QueryEvents(ctx, domain.SessionID(sessionID), domain.EventFilter{
+		From: at.Add(-c.cfg.MatchWindow),
+		To:   at.Add(c.cfg.MatchWindow),
+	}, domain.PaginationParams{Limit: activityLimit})
+	if err != nil {
+		return false, fmt.Errorf("failed to query events of session %s: %w", sessionID, err)
+	}
+	for _, entry := range entries {
+		if entry.UserID != retention.SystemUserID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// organizationOf returns the organization of the session's recorded events, empty for the
+// default organization and for sessions without events
+func (c *Consumer) organizationOf(ctx context.Context, sessionID string) (string, error) {
+	if organizationID, ok := c.organizations[sessionID]; ok {
+		return organizationID, nil
+	}
+
+	entries, _, err := c.repo.QueryEvents(ctx, domain.SessionID(sessionID), domain.EventFilter{}, domain.PaginationParams{Limit: 1})
+	if err != nil {
+		return "", fmt.Errorf("failed to query events of session %s: %w", sessionID, err)
+	}
+	if len(entries) == 0 {
+		return "", nil
+	}
+
+	remember(c.organizations, sessionID, entries[0].OrganizationID)
+	return entries[0].OrganizationID, nil
+}
+
+// remember caches a value, starting over once the cache is full
+func remember(cache map[string]string, key, value string) {
+	if len(cache) >= maxCached {
+		clear(cache)
+	}
+	cache[key] = value
+}
+
+// stringValue returns a column of the new row, or of the old row for deletions
+func stringValue(change Change, column string) string {
+	value, ok := change.Record[column]
+	if !ok || value == nil {
+		value = change.OldRecord[column]
+	}
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// changedColumns lists the columns an update changed, ignoring updated_at. Without REPLICA
+// IDENTITY FULL the old row only holds the primary key and nothing is reported.
+func changedColumns(change Change) []string {
+	if change.Type != "UPDATE" || len(change.OldRecord) <= 1 {
+		return nil
+	}
+
+	var columns []string
+	for column, value := range change.Record {
+		if column == "updated_at" {
+			continue
+		}
+		if old, ok := change.OldRecord[column]; !ok || !reflect.DeepEqual(old, value) {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
@@ -0,0 +1,334 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/internal/repository"
+	"audit-service/pkg/clock"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// Tables whose changes are recorded
+const (
+	TableSessions = "translation_sessions"
+	TableShapes   = "slide_shapes"
+)
+
+const (
+	// heartbeatInterval is how often the socket is kept alive; Realtime closes it after 60s of silence
+	heartbeatInterval = 25 * time.Second
+
+	// readTimeout is how long the socket may stay silent before it is considered dead
+	readTimeout = 2 * heartbeatInterval
+
+	// minBackoff and maxBackoff bound the delay between reconnection attempts
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+
+	// queueSize is how many received changes may wait for processing before the socket blocks
+	queueSize = 1024
+
+	// channelTopic is the Realtime channel joined for both tables
+	channelTopic = "realtime:audit-service"
+)
+
+// RecordFunc persists the external_change events
+type RecordFunc func(ctx context.Context, entries []domain.AuditEntry) error
+
+// Repository looks up recorded events to tell changes made through the API apart
+type Repository interface {
+	QueryEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, page domain.PaginationParams) ([]domain.AuditEntry, int, error)
+}
+
+// Client reads the slides table to resolve the session of changed shapes
+type Client interface {
+	Get(ctx context.Context, endpoint string, query *repository.Query) ([]byte, int, error)
+}
+
+// Config configures the connection to Supabase Realtime
+type Config struct {
+	// URL is the Supabase project URL, such as https://xyz.supabase.co
+	URL string
+	// Key is the service role key, which receives changes regardless of row level security
+	Key string
+	// MatchWindow is how close to a change an event recorded through the API must be for the
+	// change to be attributed to it; zero records every change
+	MatchWindow time.Duration
+}
+
+// Change is a row change delivered by Supabase Realtime
+type Change struct {
+	Table string `json:"table"`
+	// Type is INSERT, UPDATE or DELETE
+	Type      string                 `json:"type"`
+	Record    map[string]interface{} `json:"record"`
+	OldRecord map[string]interface{} `json:"old_record"`
+	// CommitTimestamp is when the transaction of the change committed
+	CommitTimestamp string `json:"commit_timestamp"`
+}
+
+// message is a frame of the Phoenix channel protocol spoken by Realtime
+type message struct {
+	Topic   string          `json:"topic"`
+	Event   string          `json:"event"`
+	Payload json.RawMessage `json:"payload"`
+	Ref     *string         `json:"ref"`
+}
+
+// Consumer subscribes to Supabase Realtime and records changes to sessions and slide shapes
+// that were not made through the API, such as direct edits and migrations. Every subscribed
+// instance receives every change, so the consumer should run on a single instance.
+type Consumer struct {
+	cfg     Config
+	client  Client
+	repo    Repository
+	record  RecordFunc
+	clock   clock.Clock
+	metrics *metrics.Metrics
+	logger  *zap.Logger
+	dialer  *websocket.Dialer
+
+	changes chan Change
+
+	// pending and the caches are only accessed by the processing goroutine
+	pending       []pendingChange
+	slideSessions map[string]string
+	organizations map[string]string
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// New creates a consumer; call Start to connect
+func New(cfg Config, client Client, repo Repository, record RecordFunc, clk clock.Clock, m *metrics.Metrics, logger *zap.Logger) *Consumer {
+	return &Consumer{
+		cfg:           cfg,
+		client:        client,
+		repo:          repo,
+		record:        record,
+		clock:         clk,
+		metrics:       m,
+		logger:        logger,
+		dialer:        websocket.DefaultDialer,
+		changes:       make(chan Change, queueSize),
+		slideSessions: make(map[string]string),
+		organizations: make(map[string]string),
+		stop:          make(chan struct{}),
+	}
+}
+
+// Start connects to Realtime in the background and keeps reconnecting until Close is called
+func (c *Consumer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-c.stop
+		cancel()
+	}()
+
+	c.wg.Add(2)
+	go c.listen(ctx)
+	go c.process(ctx)
+}
+
+// Close disconnects and waits for changes being recorded to be cancelled. Changes still
+// waiting for their match window are not recorded.
+func (c *Consumer) Close(ctx context.Context) error {
+	c.closeOnce.Do(func() { close(c.stop) })
+
+	stopped := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("realtime consumer did not stop: %w", ctx.Err())
+	}
+}
+
+// listen keeps a subscription open, reconnecting with exponential backoff
+func (c *Consumer) listen(ctx context.Context) {
+	defer c.wg.Done()
+
+	backoff := minBackoff
+	for {
+		subscribed, err := c.subscribe(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if subscribed {
+			backoff = minBackoff
+		}
+		c.logger.Warn("supabase realtime connection lost, reconnecting",
+			zap.Duration("retry_in", backoff),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// subscribe opens a socket, joins the channel and forwards changes until the socket fails.
+// subscribed reports whether the join was accepted.
+func (c *Consumer) subscribe(ctx context.Context) (subscribed bool, err error) {
+	socketURL, err := SocketURL(c.cfg.URL, c.cfg.Key)
+	if err != nil {
+		return false, err
+	}
+
+	conn, _, err := c.dialer.DialContext(ctx, socketURL, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	// Closing the socket unblocks the read below when the consumer stops
+	stopRead := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stopRead()
+
+	var writeMu sync.Mutex
+	var ref int
+	send := func(topic, event string, payload interface{}) (string, error) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		ref++
+		id := strconv.Itoa(ref)
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return "", err
+		}
+		return id, conn.WriteJSON(message{Topic: topic, Event: event, Payload: data, Ref: &id})
+	}
+
+	joinRef, err := send(channelTopic, "phx_join", joinPayload(c.cfg.Key))
+	if err != nil {
+		return false, fmt.Errorf("failed to join channel: %w", err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if _, err := send("phoenix", "heartbeat", struct{}{}); err != nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(readTimeout)); err != nil {
+			return subscribed, err
+		}
+		var msg message
+		if err := conn.ReadJSON(&msg); err != nil {
+			return subscribed, fmt.Errorf("failed to read message: %w", err)
+		}
+		if msg.Topic != channelTopic {
+			continue
+		}
+
+		switch msg.Event {
+		case "phx_reply":
+			if msg.Ref == nil || *msg.Ref != joinRef {
+				continue
+			}
+			var reply struct {
+				Status   string          `json:"status"`
+				Response json.RawMessage `json:"response"`
+			}
+			if err := json.Unmarshal(msg.Payload, &reply); err != nil || reply.Status != "ok" {
+				return false, fmt.Errorf("join rejected: %s", msg.Payload)
+			}
+			subscribed = true
+			c.logger.Info("subscribed to supabase realtime", zap.Strings("tables", []string{TableSessions, TableShapes}))
+		case "system":
+			var status struct {
+				Status  string `json:"status"`
+				Message string `json:"message"`
+			}
+			if err := json.Unmarshal(msg.Payload, &status); err == nil && status.Status == "error" {
+				return subscribed, fmt.Errorf("realtime error: %s", status.Message)
+			}
+		case "postgres_changes":
+			var payload struct {
+				Data Change `json:"data"`
+			}
+			if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+				c.logger.Warn("skipping malformed realtime change", zap.Error(err))
+				continue
+			}
+			select {
+			case c.changes <- payload.Data:
+			case <-ctx.Done():
+				return subscribed, ctx.Err()
+			}
+		case "phx_error", "phx_close":
+			return subscribed, fmt.Errorf("channel closed by server: %s", msg.Event)
+		}
+	}
+}
+
+// joinPayload subscribes the channel to every change of the watched tables
+func joinPayload(key string) map[string]interface{} {
+	changes := make([]map[string]string, 0, 2)
+	for _, table := range []string{TableSessions, TableShapes} {
+		changes = append(changes, map[string]string{"event": "*", "schema": "public", "table": table})
+	}
+	return map[string]interface{}{
+		"config": map[string]interface{}{
+			"broadcast":        map[string]bool{"self": false},
+			"presence":         map[string]string{"key": ""},
+			"postgres_changes": changes,
+		},
+		"access_token": key,
+	}
+}
+
+// SocketURL returns the Realtime websocket endpoint of a Supabase project
+func SocketURL(projectURL, key string) (string, error) {
+	parsed, err := url.Parse(projectURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid Supabase URL: %w", err)
+	}
+	switch parsed.Scheme {
+	case "https":
+		parsed.Scheme = "wss"
+	case "http":
+		parsed.Scheme = "ws"
+	default:
+		return "", errors.New("invalid Supabase URL: scheme must be http or https")
+	}
+
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/") + "/realtime/v1/websocket"
+	parsed.RawQuery = url.Values{"apikey": {key}, "vsn": {"1.0.0"}}.Encode()
+	return parsed.String(), nil
+}
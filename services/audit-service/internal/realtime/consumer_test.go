@@ -0,0 +1,243 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/internal/repository"
+	"audit-service/internal/retention"
+	"audit-service/mocks"
+	"audit-service/pkg/clock"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+const testSessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+// recorder collects the external_change events
+type recorder struct {
+	mu      sync.Mutex
+	entries []domain.AuditEntry
+}
+
+func (r *recorder) record(_ context.Context, entries []domain.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entries...)
+	return nil
+}
+
+func (r *recorder) recorded() []domain.AuditEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]domain.AuditEntry(nil), r.entries...)
+}
+
+// fakeSlides serves the slides table
+type fakeSlides struct {
+	sessions map[string]string
+	calls    int
+}
+
+func (f *fakeSlides) Get(_ context.Context, endpoint string, query *repository.Query) ([]byte, int, error) {
+	f.calls++
+	slideID := query.Params()["id"][len("eq."):]
+	sessionID, ok := f.sessions[slideID]
+	if endpoint != "/slides" || !ok {
+		return []byte(`[]`), 0, nil
+	}
+	data, _ := json.Marshal([]map[string]string{{"session_id": sessionID}})
+	return data, 1, nil
+}
+
+func details(t *testing.T, entry domain.AuditEntry) Details {
+	t.Helper()
+	var d Details
+	require.NoError(t, json.Unmarshal(entry.Details, &d))
+	return d
+}
+
+func TestConsumer_RecordsSessionChange(t *testing.T) {
+	repo := mocks.NewMockAuditRepository(t)
+	rec := &recorder{}
+	c := New(Config{}, &fakeSlides{}, repo, rec.record, clock.NewFakeClock(testNow), metrics.New(), zap.NewNop())
+
+	repo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), domain.EventFilter{}, domain.PaginationParams{Limit: 1}).
+		Return([]domain.AuditEntry{{ID: "event-1", OrganizationID: "acme"}}, 1, nil).Once()
+
+	result := c.Handle(context.Background(), Change{
+		Table:           TableSessions,
+		Type:            "UPDATE",
+		Record:          map[string]interface{}{"id": testSessionID, "status": "completed", "name": "Deck", "updated_at": "b"},
+		OldRecord:       map[string]interface{}{"id": testSessionID, "status": "processing", "name": "Deck", "updated_at": "a"},
+		CommitTimestamp: "2024-03-01T09:59:58.5Z",
+	})
+
+	assert.Equal(t, ResultRecorded, result)
+	entries := rec.recorded()
+	require.Len(t, entries, 1)
+	assert.Equal(t, testSessionID, entries[0].SessionID)
+	assert.Equal(t, retention.SystemUserID, entries[0].UserID)
+	assert.Equal(t, string(domain.ActionExternalChange), entries[0].Type)
+	assert.Equal(t, "acme", entries[0].OrganizationID)
+	assert.Equal(t, time.Date(2024, 3, 1, 9, 59, 58, 500000000, time.UTC), entries[0].Timestamp)
+	assert.Equal(t, Details{
+		Source:          "realtime",
+		Table:           TableSessions,
+		Operation:       "update",
+		RecordID:        testSessionID,
+		ChangedColumns:  []string{"status"},
+		CommitTimestamp: entries[0].Timestamp,
+	}, details(t, entries[0]))
+}
+
+func TestConsumer_ResolvesShapeSessions(t *testing.T) {
+	repo := mocks.NewMockAuditRepository(t)
+	rec := &recorder{}
+	slides := &fakeSlides{sessions: map[string]string{"slide-1": testSessionID}}
+	c := New(Config{}, slides, repo, rec.record, clock.NewFakeClock(testNow), metrics.New(), zap.NewNop())
+
+	repo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), domain.EventFilter{}, domain.PaginationParams{Limit: 1}).
+		Return(nil, 0, nil)
+
+	insert := Change{Table: TableShapes, Type: "INSERT", Record: map[string]interface{}{"id": "shape-1", "slide_id": "slide-1"}}
+	assert.Equal(t, ResultRecorded, c.Handle(context.Background(), insert))
+	assert.Equal(t, ResultRecorded, c.Handle(context.Background(), insert))
+
+	// Deletions only carry the primary key unless the table has REPLICA IDENTITY FULL
+	deletion := Change{Table: TableShapes, Type: "DELETE", OldRecord: map[string]interface{}{"id": "shape-1"}}
+	assert.Equal(t, ResultSkipped, c.Handle(context.Background(), deletion))
+
+	// Shapes of unknown slides cannot be attributed
+	orphan := Change{Table: TableShapes, Type: "INSERT", Record: map[string]interface{}{"id": "shape-2", "slide_id": "slide-2"}}
+	assert.Equal(t, ResultSkipped, c.Handle(context.Background(), orphan))
+
+	entries := rec.recorded()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "slide-1", details(t, entries[0]).SlideID)
+	assert.Equal(t, "shape-1", details(t, entries[0]).RecordID)
+	// The session of a slide is looked up once
+	assert.Equal(t, 2, slides.calls)
+}
+
+func TestConsumer_SkipsChangesMadeThroughAPI(t *testing.T) {
+	repo := mocks.NewMockAuditRepository(t)
+	rec := &recorder{}
+	fakeClock := clock.NewFakeClock(testNow)
+	c := New(Config{MatchWindow: 30 * time.Second}, &fakeSlides{}, repo, rec.record, fakeClock, metrics.New(), zap.NewNop())
+
+	committed := testNow.Add(-time.Second)
+	window := domain.EventFilter{From: committed.Add(-30 * time.Second), To: committed.Add(30 * time.Second)}
+	apiSession := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+	repo.On("QueryEvents", mock.Anything, domain.SessionID(apiSession), window, domain.PaginationParams{Limit: activityLimit}).
+		Return([]domain.AuditEntry{{ID: "event-1", UserID: "user-1", Type: "edit"}}, 1, nil).Once()
+	repo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), window, domain.PaginationParams{Limit: activityLimit}).
+		Return([]domain.AuditEntry{{ID: "event-2", UserID: retention.SystemUserID, Type: "external_change"}}, 1, nil).Once()
+	repo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), domain.EventFilter{}, domain.PaginationParams{Limit: 1}).
+		Return(nil, 0, nil).Once()
+
+	for _, sessionID := range []string{apiSession, testSessionID} {
+		c.enqueue(context.Background(), Change{
+			Table:           TableSessions,
+			Type:            "UPDATE",
+			Record:          map[string]interface{}{"id": sessionID},
+			CommitTimestamp: committed.Format(time.RFC3339Nano),
+		})
+	}
+
+	// Changes wait for their match window so events recorded after the commit are seen
+	c.flush(context.Background())
+	assert.Empty(t, rec.recorded())
+	assert.Len(t, c.pending, 2)
+
+	fakeClock.Advance(30 * time.Second)
+	c.flush(context.Background())
+
+	assert.Empty(t, c.pending)
+	entries := rec.recorded()
+	require.Len(t, entries, 1)
+	assert.Equal(t, testSessionID, entries[0].SessionID)
+}
+
+func TestConsumer_Subscribes(t *testing.T) {
+	joined := make(chan map[string]interface{}, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/realtime/v1/websocket", r.URL.Path)
+		assert.Equal(t, "service-key", r.URL.Query().Get("apikey"))
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		var join message
+		require.NoError(t, conn.ReadJSON(&join))
+		assert.Equal(t, channelTopic, join.Topic)
+		assert.Equal(t, "phx_join", join.Event)
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal(join.Payload, &payload))
+		joined <- payload
+
+		require.NoError(t, conn.WriteJSON(message{
+			Topic: channelTopic, Event: "phx_reply", Ref: join.Ref,
+			Payload: json.RawMessage(`{"status":"ok","response":{"postgres_changes":[]}}`),
+		}))
+		require.NoError(t, conn.WriteJSON(message{
+			Topic: channelTopic, Event: "postgres_changes",
+			Payload: json.RawMessage(`{"ids":[1],"data":{"schema":"public","table":"translation_sessions","type":"DELETE",
+				"commit_timestamp":"2024-03-01T09:00:00Z","record":{},"old_record":{"id":"` + testSessionID + `"}}}`),
+		}))
+
+		// Keep the socket open until the consumer disconnects
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	repo := mocks.NewMockAuditRepository(t)
+	repo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), domain.EventFilter{}, domain.PaginationParams{Limit: 1}).
+		Return(nil, 0, nil)
+	rec := &recorder{}
+	c := New(Config{URL: server.URL, Key: "service-key"}, &fakeSlides{}, repo, rec.record, clock.NewFakeClock(testNow), metrics.New(), zap.NewNop())
+	c.Start()
+
+	payload := <-joined
+	assert.Equal(t, "service-key", payload["access_token"])
+	changes := payload["config"].(map[string]interface{})["postgres_changes"].([]interface{})
+	require.Len(t, changes, 2)
+	assert.Equal(t, TableSessions, changes[0].(map[string]interface{})["table"])
+	assert.Equal(t, TableShapes, changes[1].(map[string]interface{})["table"])
+
+	require.Eventually(t, func() bool { return len(rec.recorded()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "delete", details(t, rec.recorded()[0]).Operation)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, c.Close(ctx))
+}
+
+func TestSocketURL(t *testing.T) {
+	socketURL, err := SocketURL("https://xyz.supabase.co/", "key")
+	require.NoError(t, err)
+	assert.Equal(t, "wss://xyz.supabase.co/realtime/v1/websocket?apikey=key&vsn=1.0.0", socketURL)
+
+	_, err = SocketURL("xyz.supabase.co", "key")
+	assert.Error(t, err)
+}
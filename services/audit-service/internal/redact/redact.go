@@ -0,0 +1,153 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Rule masks the matches of a pattern in string values
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// builtinRules are the rules that can be enabled by name
+var builtinRules = map[string]*regexp.Regexp{
+	"email": regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`),
+	// Requires separators between digit groups so dates, counts and IDs are left alone
+	"phone": regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{2,4}\)[ .-]?|\d{2,4}[ .-])\d{3,4}[ .-]?\d{3,4}\b`),
+}
+
+// ruleNamePattern matches rule names, which become part of the mask
+var ruleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// ParseRules builds the built-in rules named in builtins, a comma-separated list such as
+// "email,phone", followed by the custom rules in patterns, a semicolon-separated list of
+// name=regex pairs such as "iban=[A-Z]{2}\d{2}[A-Z0-9]{11,30}"
+func ParseRules(builtins, patterns string) ([]Rule, error) {
+	var rules []Rule
+	seen := make(map[string]bool)
+	add := func(name string, pattern *regexp.Regexp) error {
+		if seen[name] {
+			return fmt.Errorf("duplicate rule %q", name)
+		}
+		seen[name] = true
+		rules = append(rules, Rule{Name: name, Pattern: pattern})
+		return nil
+	}
+
+	for _, name := range strings.Split(builtins, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		pattern, ok := builtinRules[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+		if err := add(name, pattern); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, item := range strings.Split(patterns, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, expr, ok := strings.Cut(item, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !ruleNamePattern.MatchString(name) || expr == "" {
+			return nil, fmt.Errorf("invalid pattern %q: must be name=regex with a lowercase name", item)
+		}
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", name, err)
+		}
+		if err := add(name, pattern); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// Redactor masks personal data in event details before they are stored
+type Redactor struct {
+	rules []Rule
+}
+
+// New creates a redactor applying rules in order; a nil redactor leaves details unchanged
+func New(rules []Rule) *Redactor {
+	return &Redactor{rules: rules}
+}
+
+// Redact replaces every match of a rule in the string values of details with
+// [REDACTED:<rule>]. It returns the redacted details and the sorted paths of the values that
+// were changed, such as "text" or "shapes[2].comment"; details without matches are returned as is.
+func (r *Redactor) Redact(details json.RawMessage) (json.RawMessage, []string, error) {
+	if r == nil || len(r.rules) == 0 || len(bytes.TrimSpace(details)) == 0 {
+		return details, nil, nil
+	}
+
+	// Numbers are kept as written so redaction does not change them
+	decoder := json.NewDecoder(bytes.NewReader(details))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, nil, fmt.Errorf("invalid details: %w", err)
+	}
+
+	var paths []string
+	value = r.walk(value, "", &paths)
+	if len(paths) == 0 {
+		return details, nil, nil
+	}
+
+	redacted, err := json.Marshal(value)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(paths)
+	return redacted, paths, nil
+}
+
+// walk redacts the strings in value, appending the path of each changed string to paths
+func (r *Redactor) walk(value interface{}, path string, paths *[]string) interface{} {
+	switch v := value.(type) {
+	case string:
+		masked := r.mask(v)
+		if masked != v {
+			*paths = append(*paths, path)
+		}
+		return masked
+	case map[string]interface{}:
+		for key, item := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			v[key] = r.walk(item, childPath, paths)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.walk(item, path+"["+strconv.Itoa(i)+"]", paths)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// mask applies every rule to s
+func (r *Redactor) mask(s string) string {
+	for _, rule := range r.rules {
+		s = rule.Pattern.ReplaceAllLiteralString(s, "[REDACTED:"+rule.Name+"]")
+	}
+	return s
+}
@@ -0,0 +1,90 @@
+package redact
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactor_Redact(t *testing.T) {
+	rules, err := ParseRules("email,phone", `ticket=TCK-\d{6}`)
+	require.NoError(t, err)
+	redactor := New(rules)
+
+	tests := []struct {
+		name     string
+		details  string
+		expected string
+		paths    []string
+	}{
+		{
+			name:     "nested values",
+			details:  `{"slideId":"slide-1","after":"Contact jane.doe@example.com or +1 555 123 4567","shapes":[{"text":"TCK-123456"},{"text":"Title"}]}`,
+			expected: `{"slideId":"slide-1","after":"Contact [REDACTED:email] or [REDACTED:phone]","shapes":[{"text":"[REDACTED:ticket]"},{"text":"Title"}]}`,
+			paths:    []string{"after", "shapes[0].text"},
+		},
+		{
+			name:     "phone formats",
+			details:  `{"a":"(555) 123-4567","b":"555.123.4567","c":"+44 20 7946 0958"}`,
+			expected: `{"a":"[REDACTED:phone]","b":"[REDACTED:phone]","c":"[REDACTED:phone]"}`,
+			paths:    []string{"a", "b", "c"},
+		},
+		{
+			name:    "dates, IDs and numbers are kept",
+			details: `{"date":"2024-01-15","time":"10:30:00","id":"550e8400-e29b-41d4-a716-446655440000","count":12345678901,"ratio":0.1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redacted, paths, err := redactor.Redact(json.RawMessage(tt.details))
+			require.NoError(t, err)
+
+			if tt.expected == "" {
+				// Details without matches are returned byte for byte
+				assert.Equal(t, tt.details, string(redacted))
+				assert.Empty(t, paths)
+				return
+			}
+			assert.JSONEq(t, tt.expected, string(redacted))
+			assert.Equal(t, tt.paths, paths)
+		})
+	}
+}
+
+func TestRedactor_Nil(t *testing.T) {
+	var redactor *Redactor
+	details := json.RawMessage(`{"email":"jane.doe@example.com"}`)
+
+	redacted, paths, err := redactor.Redact(details)
+	require.NoError(t, err)
+	assert.Equal(t, details, redacted)
+	assert.Empty(t, paths)
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules(" email ", "iban=[A-Z]{2}\\d{2}[A-Z0-9]{11,30}; ticket=TCK-\\d+")
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+	assert.Equal(t, []string{"email", "iban", "ticket"}, []string{rules[0].Name, rules[1].Name, rules[2].Name})
+
+	invalid := []struct {
+		name     string
+		builtins string
+		patterns string
+	}{
+		{name: "unknown built-in rule", builtins: "ssn"},
+		{name: "missing regex", patterns: "ticket"},
+		{name: "upper case name", patterns: "Ticket=TCK"},
+		{name: "invalid regex", patterns: "ticket=TCK-("},
+		{name: "duplicate rule", builtins: "email", patterns: "email=@"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRules(tt.builtins, tt.patterns)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,325 @@
+// Package reload applies configuration changes to a running service. On SIGHUP, or when the .env
+// file changes, the configuration is loaded again and the fields that can change at runtime are
+// applied without a restart, which would drop every SSE and WebSocket subscriber.
+package reload
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"audit-service/internal/bruteforce"
+	"audit-service/internal/config"
+	"audit-service/internal/domain"
+	"audit-service/internal/middleware"
+	"audit-service/internal/quota"
+	"audit-service/internal/replay"
+	"audit-service/internal/retention"
+	"audit-service/pkg/cache"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/logger"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Sources of a reload, as recorded in the config_changed event
+const (
+	SourceSignal = "sighup"
+	SourceFile   = "file"
+)
+
+// Reloadable are the configuration fields applied without a restart. Changes to any other field,
+// including BruteForceEnabled, which adds or removes a middleware, are reported and take effect
+// on the next start.
+var Reloadable = []string{
+	"LogLevel",
+	"CORSOrigin",
+	"CacheJWTTTL",
+	"CacheShareTokenTTL",
+	"IdempotencyTTL",
+	"SystemSessionID",
+	"QuotaSessionDaily",
+	"QuotaUserDaily",
+	"BruteForceThreshold",
+	"BruteForceWindow",
+	"BruteForceLockout",
+	"BruteForceMaxLockout",
+	"ReplayMaxRate",
+	"ReplayMaxEvents",
+}
+
+// LoadFunc loads the configuration again
+type LoadFunc func() (*config.Config, error)
+
+// RecordFunc persists the config_changed events
+type RecordFunc func(ctx context.Context, entries []domain.AuditEntry) error
+
+// Targets are the running components that take the reloadable settings
+type Targets struct {
+	LogLevel         zap.AtomicLevel
+	CORSOrigin       *middleware.CORSOrigin
+	TokenCache       *cache.TokenCache
+	IdempotencyCache *cache.IdempotencyCache
+	Quotas           *quota.Enforcer
+	// BruteForce and Replays are nil when brute-force throttling or the outbox is disabled
+	BruteForce *bruteforce.Guard
+	Replays    *replay.Manager
+}
+
+// Change is the old and new value of a changed field, with secrets redacted
+type Change struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Details is the details payload of a config_changed event
+type Details struct {
+	Source  string            `json:"source"`
+	Changed map[string]Change `json:"changed"`
+	// RestartRequired lists the changed fields that take effect on the next start
+	RestartRequired []string `json:"restartRequired,omitempty"`
+}
+
+// Reloader keeps the running configuration and applies changes to it
+type Reloader struct {
+	current atomic.Pointer[config.Config]
+	load    LoadFunc
+	targets Targets
+	record  RecordFunc
+	clock   clock.Clock
+	logger  *zap.Logger
+
+	// mu serializes reloads
+	mu sync.Mutex
+
+	// requests carries reloads triggered by file changes, coalescing bursts of writes
+	requests  chan string
+	signals   chan os.Signal
+	closeOnce sync.Once
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// New creates a reloader for the configuration the service started with; call Start to
+// react to SIGHUP and .env changes
+func New(cfg *config.Config, load LoadFunc, targets Targets, record RecordFunc, clk clock.Clock, logger *zap.Logger) *Reloader {
+	r := &Reloader{
+		load:     load,
+		targets:  targets,
+		record:   record,
+		clock:    clk,
+		logger:   logger,
+		requests: make(chan string, 1),
+		signals:  make(chan os.Signal, 1),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	r.current.Store(cfg)
+	return r
+}
+
+// Config returns the running configuration
+func (r *Reloader) Config() *config.Config {
+	return r.current.Load()
+}
+
+// Redacted reports the running configuration for the config self-report
+func (r *Reloader) Redacted() map[string]interface{} {
+	return r.current.Load().Redacted()
+}
+
+// Start reloads on SIGHUP and, when a .env file was read, whenever it changes
+func (r *Reloader) Start() {
+	signal.Notify(r.signals, syscall.SIGHUP)
+	if config.WatchEnvFile(func() { r.request(SourceFile) }) {
+		r.logger.Info("watching .env file for configuration changes")
+	}
+	go r.run()
+}
+
+// Close stops reacting to SIGHUP and file changes
+func (r *Reloader) Close(ctx context.Context) error {
+	r.closeOnce.Do(func() {
+		signal.Stop(r.signals)
+		close(r.stop)
+	})
+
+	select {
+	case <-r.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("config reloader did not stop: %w", ctx.Err())
+	}
+}
+
+// request queues a reload unless one is already waiting
+func (r *Reloader) request(source string) {
+	select {
+	case r.requests <- source:
+	default:
+	}
+}
+
+func (r *Reloader) run() {
+	defer close(r.stopped)
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-r.signals:
+			r.reloadLogged(SourceSignal)
+		case source := <-r.requests:
+			r.reloadLogged(source)
+		}
+	}
+}
+
+func (r *Reloader) reloadLogged(source string) {
+	if _, err := r.Reload(context.Background(), source); err != nil {
+		r.logger.Error("configuration reload failed, keeping the running configuration",
+			zap.String("source", source),
+			zap.Error(err),
+		)
+	}
+}
+
+// Reload loads the configuration, applies the reloadable fields and records a config_changed
+// event in the system session when anything changed. It returns the changed field names. When
+// the configuration cannot be loaded or is invalid, the running configuration is kept.
+func (r *Reloader) Reload(ctx context.Context, source string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	loaded, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	old := r.current.Load()
+	changed := changedFields(old, loaded)
+	if len(changed) == 0 {
+		r.logger.Info("configuration reloaded without changes", zap.String("source", source))
+		return nil, nil
+	}
+
+	// Fields that cannot change at runtime keep their running value, so the self-report
+	// shows what the service actually uses
+	next := *old
+	loadedValue := reflect.ValueOf(loaded).Elem()
+	nextValue := reflect.ValueOf(&next).Elem()
+	var applied, restartRequired []string
+	for _, field := range changed {
+		if slices.Contains(Reloadable, field) {
+			nextValue.FieldByName(field).Set(loadedValue.FieldByName(field))
+			applied = append(applied, field)
+		} else {
+			restartRequired = append(restartRequired, field)
+		}
+	}
+	r.apply(&next)
+	r.current.Store(&next)
+
+	if len(restartRequired) > 0 {
+		r.logger.Warn("configuration changes take effect after a restart",
+			zap.Strings("fields", restartRequired),
+		)
+	}
+	r.logger.Info("configuration reloaded",
+		zap.String("source", source),
+		zap.Strings("applied", applied),
+	)
+
+	r.recordChange(ctx, &next, Details{
+		Source:          source,
+		Changed:         changes(old, loaded, changed),
+		RestartRequired: restartRequired,
+	})
+	return changed, nil
+}
+
+// apply hands the reloadable settings to the running components
+func (r *Reloader) apply(cfg *config.Config) {
+	r.targets.LogLevel.SetLevel(logger.NewLevel(cfg.LogLevel).Level())
+	if r.targets.CORSOrigin != nil {
+		r.targets.CORSOrigin.Set(cfg.CORSOrigin)
+	}
+	if r.targets.TokenCache != nil {
+		r.targets.TokenCache.SetTTLs(cfg.CacheJWTTTL, cfg.CacheShareTokenTTL)
+	}
+	if r.targets.IdempotencyCache != nil {
+		r.targets.IdempotencyCache.SetTTL(cfg.IdempotencyTTL)
+	}
+	if r.targets.Quotas != nil {
+		r.targets.Quotas.SetConfig(quota.Config{
+			SessionDaily: int64(cfg.QuotaSessionDaily),
+			UserDaily:    int64(cfg.QuotaUserDaily),
+		})
+	}
+	if r.targets.BruteForce != nil {
+		r.targets.BruteForce.SetConfig(bruteforce.Config{
+			Threshold:  cfg.BruteForceThreshold,
+			Window:     cfg.BruteForceWindow,
+			Lockout:    cfg.BruteForceLockout,
+			MaxLockout: cfg.BruteForceMaxLockout,
+		})
+	}
+	if r.targets.Replays != nil {
+		r.targets.Replays.SetLimits(cfg.ReplayMaxRate, cfg.ReplayMaxEvents)
+	}
+}
+
+// recordChange stores the config_changed event; without a system session it is only logged
+func (r *Reloader) recordChange(ctx context.Context, cfg *config.Config, details Details) {
+	if cfg.SystemSessionID == "" || r.record == nil {
+		return
+	}
+
+	payload, err := domain.EncodeDetails(details)
+	if err != nil {
+		r.logger.Error("failed to encode config_changed event", zap.Error(err))
+		return
+	}
+	entry := domain.AuditEntry{
+		ID:        uuid.New().String(),
+		SessionID: cfg.SystemSessionID,
+		UserID:    retention.SystemUserID,
+		Type:      string(domain.ActionConfigChanged),
+		Timestamp: r.clock.Now(),
+		Details:   payload,
+	}
+	if err := r.record(ctx, []domain.AuditEntry{entry}); err != nil {
+		r.logger.Error("failed to record config_changed event", zap.Error(err))
+	}
+}
+
+// changedFields returns the names of the fields that differ, in declaration order
+func changedFields(old, loaded *config.Config) []string {
+	oldValue := reflect.ValueOf(old).Elem()
+	loadedValue := reflect.ValueOf(loaded).Elem()
+	var changed []string
+	for i := 0; i < oldValue.NumField(); i++ {
+		if !reflect.DeepEqual(oldValue.Field(i).Interface(), loadedValue.Field(i).Interface()) {
+			changed = append(changed, oldValue.Type().Field(i).Name)
+		}
+	}
+	return changed
+}
+
+// changes describes the changed fields with their redacted values; a changed secret shows as
+// [REDACTED] on both sides
+func changes(old, loaded *config.Config, fields []string) map[string]Change {
+	oldReport := old.Redacted()
+	loadedReport := loaded.Redacted()
+	out := make(map[string]Change, len(fields))
+	for _, field := range fields {
+		out[field] = Change{Old: oldReport[field], New: loadedReport[field]}
+	}
+	return out
+}
@@ -0,0 +1,200 @@
+package reload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"audit-service/internal/config"
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/internal/middleware"
+	"audit-service/internal/quota"
+	"audit-service/internal/retention"
+	"audit-service/pkg/cache"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var testNow = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+const testSessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Port:               "4006",
+		LogLevel:           "info",
+		CORSOrigin:         "http://localhost:3000",
+		CacheJWTTTL:        5 * time.Minute,
+		CacheShareTokenTTL: time.Minute,
+		IdempotencyTTL:     time.Hour,
+		SupabaseJWTSecret:  "jwt-secret",
+		SystemSessionID:    testSessionID,
+	}
+}
+
+type fixture struct {
+	reloader *Reloader
+	level    zap.AtomicLevel
+	origin   *middleware.CORSOrigin
+	tokens   *cache.TokenCache
+	quotas   *quota.Enforcer
+	next     *config.Config
+	loadErr  error
+	recorded []domain.AuditEntry
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	cfg := testConfig()
+	f := &fixture{
+		level:  zap.NewAtomicLevelAt(zapcore.InfoLevel),
+		origin: middleware.NewCORSOrigin(cfg.CORSOrigin),
+		tokens: cache.NewTokenCache(cfg.CacheJWTTTL, cfg.CacheShareTokenTTL, time.Minute, clock.New()),
+		quotas: quota.New(quota.NewMemoryStore(clock.New()), quota.Config{}, clock.New(), metrics.New(), zap.NewNop()),
+	}
+	load := func() (*config.Config, error) {
+		if f.loadErr != nil {
+			return nil, f.loadErr
+		}
+		next := *f.next
+		return &next, nil
+	}
+	record := func(_ context.Context, entries []domain.AuditEntry) error {
+		f.recorded = append(f.recorded, entries...)
+		return nil
+	}
+	f.reloader = New(cfg, load, Targets{
+		LogLevel:         f.level,
+		CORSOrigin:       f.origin,
+		TokenCache:       f.tokens,
+		IdempotencyCache: cache.NewIdempotencyCache(cfg.IdempotencyTTL, clock.New()),
+		Quotas:           f.quotas,
+	}, record, clock.NewFakeClock(testNow), zap.NewNop())
+	f.next = testConfig()
+	return f
+}
+
+func TestReload_AppliesReloadableFields(t *testing.T) {
+	f := newFixture(t)
+	f.next.LogLevel = "debug"
+	f.next.CORSOrigin = "https://app.example.com"
+	f.next.CacheJWTTTL = time.Minute
+
+	changed, err := f.reloader.Reload(context.Background(), SourceSignal)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"LogLevel", "CORSOrigin", "CacheJWTTTL"}, changed)
+	assert.Equal(t, zapcore.DebugLevel, f.level.Level())
+	assert.Equal(t, "https://app.example.com", f.origin.Get())
+	assert.Equal(t, "https://app.example.com", f.reloader.Config().CORSOrigin)
+	assert.Equal(t, "https://app.example.com", f.reloader.Redacted()["CORSOrigin"])
+
+	require.Len(t, f.recorded, 1)
+	entry := f.recorded[0]
+	assert.Equal(t, string(domain.ActionConfigChanged), entry.Type)
+	assert.Equal(t, testSessionID, entry.SessionID)
+	assert.Equal(t, retention.SystemUserID, entry.UserID)
+	assert.Equal(t, testNow, entry.Timestamp)
+
+	var details Details
+	require.NoError(t, json.Unmarshal(entry.Details, &details))
+	assert.Equal(t, SourceSignal, details.Source)
+	assert.Equal(t, Change{Old: "info", New: "debug"}, details.Changed["LogLevel"])
+	assert.Empty(t, details.RestartRequired)
+}
+
+func TestReload_ReportsFieldsRequiringRestart(t *testing.T) {
+	f := newFixture(t)
+	f.next.Port = "5000"
+	f.next.SupabaseJWTSecret = "rotated-secret"
+
+	changed, err := f.reloader.Reload(context.Background(), SourceFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Port", "SupabaseJWTSecret"}, changed)
+
+	// The running values are kept until the next start
+	assert.Equal(t, "4006", f.reloader.Config().Port)
+	assert.Equal(t, "jwt-secret", f.reloader.Config().SupabaseJWTSecret)
+
+	require.Len(t, f.recorded, 1)
+	var details Details
+	require.NoError(t, json.Unmarshal(f.recorded[0].Details, &details))
+	assert.Equal(t, []string{"Port", "SupabaseJWTSecret"}, details.RestartRequired)
+	// Secrets are never recorded
+	assert.Equal(t, Change{Old: "[REDACTED]", New: "[REDACTED]"}, details.Changed["SupabaseJWTSecret"])
+}
+
+func TestReload_AppliesQuotas(t *testing.T) {
+	f := newFixture(t)
+	f.next.QuotaUserDaily = 2
+	f.next.BruteForceEnabled = true
+
+	changed, err := f.reloader.Reload(context.Background(), SourceSignal)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"QuotaUserDaily", "BruteForceEnabled"}, changed)
+	assert.Equal(t, 2, f.reloader.Config().QuotaUserDaily)
+	assert.False(t, f.reloader.Config().BruteForceEnabled)
+
+	// The enforcer counts against the new limit
+	entries := []domain.AuditEntry{
+		{SessionID: testSessionID, UserID: "user-1"},
+		{SessionID: testSessionID, UserID: "user-1"},
+		{SessionID: testSessionID, UserID: "user-1"},
+	}
+	_, err = f.quotas.Reserve(context.Background(), entries)
+	var exceeded *domain.QuotaExceededError
+	require.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, int64(2), exceeded.Usage.Limit)
+
+	require.Len(t, f.recorded, 1)
+	var details Details
+	require.NoError(t, json.Unmarshal(f.recorded[0].Details, &details))
+	assert.Equal(t, []string{"BruteForceEnabled"}, details.RestartRequired)
+}
+
+func TestReload_Unchanged(t *testing.T) {
+	f := newFixture(t)
+
+	changed, err := f.reloader.Reload(context.Background(), SourceSignal)
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+	assert.Empty(t, f.recorded)
+}
+
+func TestReload_WithoutSystemSession(t *testing.T) {
+	f := newFixture(t)
+	f.next.SystemSessionID = ""
+	f.next.LogLevel = "warn"
+
+	_, err := f.reloader.Reload(context.Background(), SourceSignal)
+	require.NoError(t, err)
+	assert.Equal(t, zapcore.WarnLevel, f.level.Level())
+	assert.Empty(t, f.recorded)
+}
+
+func TestReload_KeepsConfigurationOnError(t *testing.T) {
+	f := newFixture(t)
+	f.loadErr = errors.New("SUPABASE_JWT_SECRET is required")
+
+	_, err := f.reloader.Reload(context.Background(), SourceFile)
+	assert.Error(t, err)
+	assert.Equal(t, "http://localhost:3000", f.origin.Get())
+	assert.Equal(t, "info", f.reloader.Config().LogLevel)
+	assert.Empty(t, f.recorded)
+}
+
+func TestReloader_Close(t *testing.T) {
+	f := newFixture(t)
+	f.reloader.Start()
+
+	require.NoError(t, f.reloader.Close(context.Background()))
+	// Closing twice is safe
+	require.NoError(t, f.reloader.Close(context.Background()))
+}
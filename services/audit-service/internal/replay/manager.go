@@ -0,0 +1,404 @@
+// Package replay re-delivers a filtered slice of stored events to an outbox webhook, or queues
+// it in the outbox again, for consumers that lost data. Replays run in throttled background jobs.
+package replay
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/internal/outbox"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/tenant"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// pageSize is the number of events read per storage request
+	pageSize = 100
+
+	// maxAttempts bounds the deliveries of an event to the webhook before the job fails
+	maxAttempts = 3
+
+	// jobRetention is how long finished jobs can still be looked up
+	jobRetention = 24 * time.Hour
+)
+
+// Repository reads the events to replay and queues them in the outbox
+type Repository interface {
+	QueryEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, page domain.PaginationParams) ([]domain.AuditEntry, int, error)
+	EnqueueOutbox(ctx context.Context, entries []domain.AuditEntry) (int, error)
+}
+
+// Config lists the webhooks events can be replayed to and limits each replay
+type Config struct {
+	// Webhooks maps the registered outbox webhook URLs to the sinks posting to them
+	Webhooks map[string]outbox.Sink
+	// MaxRate caps the events per second of a replay; it is also the default rate
+	MaxRate int
+	// MaxEvents caps the events a replay may cover
+	MaxEvents int
+}
+
+// Manager runs replay jobs in the background, one at a time, and keeps their status in memory
+type Manager struct {
+	repo    Repository
+	cfg     Config
+	clock   clock.Clock
+	metrics *metrics.Metrics
+	logger  *zap.Logger
+
+	// retryDelay is the wait before the second delivery of an event, doubled for each further one
+	retryDelay time.Duration
+
+	mu     sync.Mutex
+	jobs   map[string]*domain.ReplayJob
+	closed bool
+	// slot is held by the running job so the throttles of several replays do not add up
+	slot chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager creates a replay job manager
+func NewManager(repo Repository, cfg Config, clk clock.Clock, m *metrics.Metrics, logger *zap.Logger) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		repo:       repo,
+		cfg:        cfg,
+		clock:      clk,
+		metrics:    m,
+		logger:     logger,
+		retryDelay: time.Second,
+		jobs:       map[string]*domain.ReplayJob{},
+		slot:       make(chan struct{}, 1),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// SetLimits changes the limits of replays started from now on; running replays keep their rate
+func (m *Manager) SetLimits(maxRate, maxEvents int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cfg.MaxRate = maxRate
+	m.cfg.MaxEvents = maxEvents
+}
+
+// limits returns the rate and event limits of a new replay
+func (m *Manager) limits() (int, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.cfg.MaxRate, m.cfg.MaxEvents
+}
+
+// Start validates a replay, counts the events it covers in the organization ctx is scoped to
+// and queues it
+func (m *Manager) Start(ctx context.Context, request domain.ReplayJob) (domain.ReplayJob, error) {
+	if err := request.Validate(); err != nil {
+		return domain.ReplayJob{}, err
+	}
+	if request.Target == domain.ReplayWebhook {
+		if _, ok := m.cfg.Webhooks[request.WebhookURL]; !ok {
+			return domain.ReplayJob{}, fmt.Errorf("%w: webhookUrl is not a registered webhook", domain.ErrInvalidReplay)
+		}
+	}
+	maxRate, maxEvents := m.limits()
+	if request.RatePerSecond == 0 {
+		request.RatePerSecond = maxRate
+	}
+	if request.RatePerSecond > maxRate {
+		return domain.ReplayJob{}, fmt.Errorf("%w: ratePerSecond must be at most %d", domain.ErrInvalidReplay, maxRate)
+	}
+
+	_, total, err := m.repo.QueryEvents(ctx, domain.SessionID(request.SessionID), filter(request), domain.PaginationParams{Limit: 1})
+	if err != nil {
+		return domain.ReplayJob{}, err
+	}
+	if total > maxEvents {
+		return domain.ReplayJob{}, fmt.Errorf("%w: %d events match, more than the limit of %d; narrow the filter",
+			domain.ErrInvalidReplay, total, maxEvents)
+	}
+
+	organizationID, scoped := tenant.FromContext(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return domain.ReplayJob{}, domain.ErrServiceUnavailable
+	}
+
+	m.pruneLocked()
+	job := &domain.ReplayJob{
+		ID:            uuid.New().String(),
+		Target:        request.Target,
+		WebhookURL:    request.WebhookURL,
+		From:          request.From.UTC(),
+		To:            request.To.UTC(),
+		Types:         slices.Clone(request.Types),
+		SessionID:     request.SessionID,
+		RatePerSecond: request.RatePerSecond,
+		Status:        domain.ReplayPending,
+		Total:         total,
+		RequestedBy:   request.RequestedBy,
+		CreatedAt:     m.clock.Now(),
+
+		OrganizationID: organizationID,
+	}
+	m.jobs[job.ID] = job
+
+	m.wg.Add(1)
+	go m.run(job.ID, scoped)
+
+	return *job, nil
+}
+
+// filter selects the events of a replay
+func filter(job domain.ReplayJob) domain.EventFilter {
+	return domain.EventFilter{Types: job.Types, From: job.From, To: job.To}
+}
+
+// Get returns the current state of a job; jobs of other organizations are reported as not found
+func (m *Manager) Get(ctx context.Context, id string) (domain.ReplayJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok || !tenant.Allows(ctx, job.OrganizationID) {
+		return domain.ReplayJob{}, domain.ErrReplayJobNotFound
+	}
+	copied := *job
+	copied.Types = slices.Clone(job.Types)
+	return copied, nil
+}
+
+// Close cancels running jobs and waits for them to stop
+func (m *Manager) Close(ctx context.Context) error {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("replay jobs did not stop: %w", ctx.Err())
+	}
+}
+
+// run replays the events of a job once no other job runs. Scoped jobs read their organization.
+func (m *Manager) run(id string, scoped bool) {
+	defer m.wg.Done()
+
+	var err error
+	select {
+	case m.slot <- struct{}{}:
+		defer func() { <-m.slot }()
+		job := m.update(id, func(job *domain.ReplayJob) { job.Status = domain.ReplayRunning })
+
+		ctx := m.ctx
+		if scoped {
+			ctx = tenant.NewContext(ctx, job.OrganizationID)
+		}
+		err = m.replay(ctx, job)
+	case <-m.ctx.Done():
+		err = m.ctx.Err()
+	}
+
+	now := m.clock.Now()
+	job := m.update(id, func(job *domain.ReplayJob) {
+		job.CompletedAt = &now
+		job.Status = domain.ReplayCompleted
+		if err != nil {
+			job.Status = domain.ReplayFailed
+			job.Error = err.Error()
+		}
+	})
+
+	fields := []zap.Field{
+		zap.String("job_id", id),
+		zap.String("target", string(job.Target)),
+		zap.Int("total", job.Total),
+		zap.Int("replayed", job.Replayed),
+		zap.Int("skipped", job.Skipped),
+	}
+	if err != nil {
+		m.logger.Error("replay job failed", append(fields, zap.Error(err))...)
+		return
+	}
+	m.logger.Info("replay job completed", append(fields, zap.String("requested_by", job.RequestedBy))...)
+}
+
+// replay pages through the matching events, newest first, and sends each page at the job's rate
+func (m *Manager) replay(ctx context.Context, job domain.ReplayJob) error {
+	throttle := newPacer(job.RatePerSecond, m.clock)
+	page := domain.PaginationParams{Limit: pageSize}
+	for {
+		entries, _, err := m.repo.QueryEvents(ctx, domain.SessionID(job.SessionID), filter(job), page)
+		if err != nil {
+			return err
+		}
+
+		switch job.Target {
+		case domain.ReplayWebhook:
+			err = m.post(ctx, job, entries, throttle)
+		case domain.ReplayOutbox:
+			err = m.enqueue(ctx, job, entries, throttle)
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(entries) < page.Limit {
+			return nil
+		}
+		page.Cursor = domain.NewCursor(entries[len(entries)-1])
+	}
+}
+
+// post delivers events to the webhook of the job one by one, retrying failed deliveries
+func (m *Manager) post(ctx context.Context, job domain.ReplayJob, entries []domain.AuditEntry, throttle *pacer) error {
+	sink := m.cfg.Webhooks[job.WebhookURL]
+	for _, entry := range entries {
+		if err := throttle.wait(ctx, 1); err != nil {
+			return err
+		}
+
+		payload, err := domain.NewOutboxPayload(entry)
+		if err != nil {
+			return fmt.Errorf("failed to prepare event %s: %w", entry.ID, err)
+		}
+		message := domain.OutboxMessage{
+			EventID:   entry.ID,
+			SessionID: entry.SessionID,
+			Type:      entry.Type,
+			Payload:   payload,
+			CreatedAt: m.clock.Now(),
+		}
+		if err := m.deliver(ctx, sink, message); err != nil {
+			m.metrics.ObserveReplayedEvents(string(job.Target), "failed", 1)
+			return fmt.Errorf("failed to deliver event %s: %w", entry.ID, err)
+		}
+
+		m.metrics.ObserveReplayedEvents(string(job.Target), "replayed", 1)
+		m.update(job.ID, func(job *domain.ReplayJob) { job.Replayed++ })
+	}
+	return nil
+}
+
+// deliver posts a message, waiting longer after each failed attempt
+func (m *Manager) deliver(ctx context.Context, sink outbox.Sink, message domain.OutboxMessage) error {
+	delay := m.retryDelay
+	for attempt := 1; ; attempt++ {
+		message.Attempts = attempt
+		err := sink.Deliver(ctx, message)
+		if err == nil || attempt == maxAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// enqueue queues a page of events in the outbox
+func (m *Manager) enqueue(ctx context.Context, job domain.ReplayJob, entries []domain.AuditEntry, throttle *pacer) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := throttle.wait(ctx, len(entries)); err != nil {
+		return err
+	}
+
+	queued, err := m.repo.EnqueueOutbox(ctx, entries)
+	if err != nil {
+		return err
+	}
+
+	skipped := len(entries) - queued
+	m.metrics.ObserveReplayedEvents(string(job.Target), "replayed", queued)
+	m.metrics.ObserveReplayedEvents(string(job.Target), "skipped", skipped)
+	m.update(job.ID, func(job *domain.ReplayJob) {
+		job.Replayed += queued
+		job.Skipped += skipped
+	})
+	return nil
+}
+
+// update applies a change to a job under the lock and returns a copy
+func (m *Manager) update(id string, apply func(job *domain.ReplayJob)) domain.ReplayJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job := m.jobs[id]
+	apply(job)
+	return *job
+}
+
+// pruneLocked forgets jobs that finished more than jobRetention ago
+func (m *Manager) pruneLocked() {
+	cutoff := m.clock.Now().Add(-jobRetention)
+	for id, job := range m.jobs {
+		if job.CompletedAt != nil && job.CompletedAt.Before(cutoff) {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// pacer spaces out events to a rate per second, measured on the manager's clock
+type pacer struct {
+	clock    clock.Clock
+	interval time.Duration
+	next     time.Time
+}
+
+// newPacer creates a pacer for rate events per second
+func newPacer(rate int, clk clock.Clock) *pacer {
+	return &pacer{clock: clk, interval: time.Second / time.Duration(rate)}
+}
+
+// wait blocks until n more events may be sent
+func (p *pacer) wait(ctx context.Context, n int) error {
+	now := p.clock.Now()
+	if p.next.Before(now) {
+		p.next = now
+	}
+	delay := p.next.Sub(now)
+	p.next = p.next.Add(time.Duration(n) * p.interval)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,305 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/internal/outbox"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+const (
+	testWebhook = "https://notifications.example.com/audit"
+	testSession = "550e8400-e29b-41d4-a716-446655440000"
+)
+
+// memoryRepository serves events newest first with keyset pagination like the storage
+// backends and records the events queued in the outbox
+type memoryRepository struct {
+	mu      sync.Mutex
+	entries []domain.AuditEntry
+	pending map[string]bool
+}
+
+func newMemoryRepository(count int) *memoryRepository {
+	repo := &memoryRepository{pending: map[string]bool{}}
+	for i := range count {
+		eventType := "share"
+		if i%2 == 1 {
+			eventType = "view"
+		}
+		repo.entries = append(repo.entries, domain.AuditEntry{
+			ID:        fmt.Sprintf("00000000-0000-0000-0000-%012d", i),
+			SessionID: testSession,
+			UserID:    "user-1",
+			Type:      eventType,
+			Timestamp: testNow.Add(-time.Duration(i+1) * time.Minute),
+		})
+	}
+	return repo
+}
+
+func (r *memoryRepository) QueryEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, page domain.PaginationParams) ([]domain.AuditEntry, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matched []domain.AuditEntry
+	for _, entry := range r.entries {
+		if (sessionID == "" || entry.SessionID == sessionID.String()) &&
+			!entry.Timestamp.Before(filter.From) && !entry.Timestamp.After(filter.To) &&
+			(len(filter.Types) == 0 || slices.Contains(filter.Types, entry.Type)) {
+			matched = append(matched, entry)
+		}
+	}
+	slices.SortFunc(matched, func(a, b domain.AuditEntry) int { return b.Timestamp.Compare(a.Timestamp) })
+	total := len(matched)
+
+	if page.Cursor != nil {
+		for i, entry := range matched {
+			if entry.ID == page.Cursor.ID {
+				matched = matched[i+1:]
+				break
+			}
+		}
+	}
+	return matched[:min(page.Limit, len(matched))], total, nil
+}
+
+func (r *memoryRepository) EnqueueOutbox(ctx context.Context, entries []domain.AuditEntry) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	queued := 0
+	for _, entry := range entries {
+		if !r.pending[entry.ID] {
+			r.pending[entry.ID] = true
+			queued++
+		}
+	}
+	return queued, nil
+}
+
+// recordingSink records delivered messages and fails the first deliveries when asked to
+type recordingSink struct {
+	mu       sync.Mutex
+	messages []domain.OutboxMessage
+	failures int
+}
+
+func (s *recordingSink) Deliver(ctx context.Context, message domain.OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("webhook responded with status 503")
+	}
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+func (s *recordingSink) delivered() []domain.OutboxMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]domain.OutboxMessage(nil), s.messages...)
+}
+
+func newTestManager(t *testing.T, repo Repository, sink outbox.Sink) *Manager {
+	m := NewManager(repo, Config{
+		Webhooks:  map[string]outbox.Sink{testWebhook: sink},
+		MaxRate:   10000,
+		MaxEvents: 1000,
+	}, clock.NewFakeClock(testNow), metrics.New(), zap.NewNop())
+	m.retryDelay = time.Millisecond
+	t.Cleanup(func() { _ = m.Close(context.Background()) })
+	return m
+}
+
+// waitFor polls the job until it has finished
+func waitFor(t *testing.T, m *Manager, ctx context.Context, id string) domain.ReplayJob {
+	var job domain.ReplayJob
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = m.Get(ctx, id)
+		require.NoError(t, err)
+		return job.Status == domain.ReplayCompleted || job.Status == domain.ReplayFailed
+	}, 5*time.Second, 5*time.Millisecond)
+	return job
+}
+
+func webhookReplay() domain.ReplayJob {
+	return domain.ReplayJob{
+		Target:      domain.ReplayWebhook,
+		WebhookURL:  testWebhook,
+		From:        testNow.Add(-24 * time.Hour),
+		To:          testNow,
+		RequestedBy: "admin-1",
+	}
+}
+
+func TestManager_ReplaysToWebhook(t *testing.T) {
+	repo := newMemoryRepository(pageSize + 50)
+	sink := &recordingSink{failures: 1}
+	m := newTestManager(t, repo, sink)
+	ctx := context.Background()
+
+	request := webhookReplay()
+	request.Types = []string{"share"}
+	job, err := m.Start(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReplayPending, job.Status)
+	assert.Equal(t, 75, job.Total)
+	assert.Equal(t, 10000, job.RatePerSecond)
+	assert.Equal(t, testNow, job.CreatedAt)
+
+	job = waitFor(t, m, ctx, job.ID)
+	assert.Equal(t, domain.ReplayCompleted, job.Status, job.Error)
+	assert.Equal(t, 75, job.Replayed)
+
+	// Failed deliveries are retried with the next attempt number
+	delivered := sink.delivered()
+	require.Len(t, delivered, 75)
+	assert.Equal(t, repo.entries[0].ID, delivered[0].EventID)
+	assert.Equal(t, 2, delivered[0].Attempts)
+	assert.Equal(t, 1, delivered[1].Attempts)
+	for _, message := range delivered {
+		assert.Equal(t, "share", message.Type)
+	}
+}
+
+func TestManager_FailsWhenWebhookKeepsFailing(t *testing.T) {
+	sink := &recordingSink{failures: maxAttempts}
+	m := newTestManager(t, newMemoryRepository(3), sink)
+	ctx := context.Background()
+
+	job, err := m.Start(ctx, webhookReplay())
+	require.NoError(t, err)
+
+	job = waitFor(t, m, ctx, job.ID)
+	assert.Equal(t, domain.ReplayFailed, job.Status)
+	assert.Contains(t, job.Error, "webhook responded with status 503")
+	assert.Zero(t, job.Replayed)
+	assert.Equal(t, testNow, *job.CompletedAt)
+}
+
+func TestManager_ReplaysToOutbox(t *testing.T) {
+	repo := newMemoryRepository(pageSize + 10)
+	repo.pending[repo.entries[3].ID] = true
+	m := newTestManager(t, repo, &recordingSink{})
+	ctx := context.Background()
+
+	request := webhookReplay()
+	request.Target = domain.ReplayOutbox
+	request.WebhookURL = ""
+	job, err := m.Start(ctx, request)
+	require.NoError(t, err)
+
+	job = waitFor(t, m, ctx, job.ID)
+	assert.Equal(t, domain.ReplayCompleted, job.Status, job.Error)
+	assert.Equal(t, pageSize+10, job.Total)
+	assert.Equal(t, pageSize+9, job.Replayed)
+	assert.Equal(t, 1, job.Skipped)
+	assert.Len(t, repo.pending, pageSize+10)
+}
+
+func TestManager_ThrottlesDeliveries(t *testing.T) {
+	sink := &recordingSink{}
+	m := newTestManager(t, newMemoryRepository(5), sink)
+	ctx := context.Background()
+
+	request := webhookReplay()
+	request.RatePerSecond = 20
+	started := time.Now()
+	job, err := m.Start(ctx, request)
+	require.NoError(t, err)
+
+	job = waitFor(t, m, ctx, job.ID)
+	assert.Equal(t, 5, job.Replayed)
+	// The first event is sent right away and each further one 50ms after the previous one
+	assert.GreaterOrEqual(t, time.Since(started), 200*time.Millisecond)
+}
+
+func TestManager_RejectsInvalidReplays(t *testing.T) {
+	m := newTestManager(t, newMemoryRepository(20), &recordingSink{})
+	m.SetLimits(10000, 10)
+
+	tests := []struct {
+		name    string
+		modify  func(job *domain.ReplayJob)
+		message string
+	}{
+		{name: "unknown target", modify: func(job *domain.ReplayJob) { job.Target = "kafka" }, message: "target must be webhook or outbox"},
+		{name: "missing webhook", modify: func(job *domain.ReplayJob) { job.WebhookURL = "" }, message: "webhookUrl is required"},
+		{
+			name: "unregistered webhook", modify: func(job *domain.ReplayJob) { job.WebhookURL = "https://attacker.example.com" },
+			message: "webhookUrl is not a registered webhook",
+		},
+		{name: "missing range", modify: func(job *domain.ReplayJob) { job.From = time.Time{} }, message: "from and to are required"},
+		{name: "reversed range", modify: func(job *domain.ReplayJob) { job.From = job.To.Add(time.Hour) }, message: "from must not be after to"},
+		{name: "invalid session", modify: func(job *domain.ReplayJob) { job.SessionID = "session-1" }, message: "sessionId must be a session ID"},
+		{name: "rate above limit", modify: func(job *domain.ReplayJob) { job.RatePerSecond = 20000 }, message: "ratePerSecond must be at most 10000"},
+		{name: "too many events", modify: func(job *domain.ReplayJob) {}, message: "20 events match, more than the limit of 10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := webhookReplay()
+			tt.modify(&request)
+
+			_, err := m.Start(context.Background(), request)
+			assert.ErrorIs(t, err, domain.ErrInvalidReplay)
+			assert.ErrorContains(t, err, tt.message)
+		})
+	}
+}
+
+func TestManager_ScopesJobsToOrganization(t *testing.T) {
+	m := newTestManager(t, newMemoryRepository(1), &recordingSink{})
+	acme := tenant.NewContext(context.Background(), "acme")
+
+	job, err := m.Start(acme, webhookReplay())
+	require.NoError(t, err)
+	assert.Equal(t, "acme", job.OrganizationID)
+	waitFor(t, m, acme, job.ID)
+
+	_, err = m.Get(tenant.NewContext(context.Background(), "globex"), job.ID)
+	assert.ErrorIs(t, err, domain.ErrReplayJobNotFound)
+}
+
+func TestManager_RejectsJobsAfterClose(t *testing.T) {
+	m := newTestManager(t, newMemoryRepository(1), &recordingSink{})
+	require.NoError(t, m.Close(context.Background()))
+
+	_, err := m.Start(context.Background(), webhookReplay())
+	assert.ErrorIs(t, err, domain.ErrServiceUnavailable)
+
+	_, err = m.Get(context.Background(), "missing")
+	assert.ErrorIs(t, err, domain.ErrReplayJobNotFound)
+}
+
+func TestPacer_MeasuresClock(t *testing.T) {
+	clk := clock.NewFakeClock(testNow)
+	throttle := newPacer(1, clk)
+	require.NoError(t, throttle.wait(context.Background(), 1))
+
+	// The next event is due a second later on the clock
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, throttle.wait(cancelled, 1), context.Canceled)
+
+	clk.Advance(2 * time.Second)
+	assert.NoError(t, throttle.wait(cancelled, 1))
+}
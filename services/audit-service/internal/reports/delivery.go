@@ -0,0 +1,126 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/outbox"
+	"audit-service/pkg/clock"
+)
+
+// ReportType is the type of report deliveries, sent in the X-Audit-Event-Type header of webhooks
+const ReportType = "report"
+
+// Deliverer delivers a rendered report and returns where it was stored, if anywhere
+type Deliverer interface {
+	Deliver(ctx context.Context, report domain.Report, run domain.ReportRun, body []byte) (string, error)
+}
+
+// WebhookDeliverer posts reports to the URL in their target with the X-Audit-* headers of outbox
+// deliveries, using the run ID as event ID. Deliveries are signed when a secret is set.
+type WebhookDeliverer struct {
+	secret []byte
+	client *http.Client
+	clock  clock.Clock
+}
+
+// NewWebhookDeliverer creates a deliverer posting reports to webhooks
+func NewWebhookDeliverer(secret string, client *http.Client, clk clock.Clock) *WebhookDeliverer {
+	return &WebhookDeliverer{
+		secret: []byte(secret),
+		client: client,
+		clock:  clk,
+	}
+}
+
+// Deliver posts the report body with the content type of its format
+func (d *WebhookDeliverer) Deliver(ctx context.Context, report domain.Report, run domain.ReportRun, body []byte) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, report.Target, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", report.Format.ContentType())
+	req.Header.Set("Idempotency-Key", run.ID)
+	req.Header.Set(outbox.HeaderEventID, run.ID)
+	req.Header.Set(outbox.HeaderEventType, ReportType)
+	req.Header.Set(outbox.HeaderAttempt, "1")
+	if len(d.secret) > 0 {
+		timestamp := strconv.FormatInt(d.clock.Now().Unix(), 10)
+		req.Header.Set(outbox.HeaderTimestamp, timestamp)
+		req.Header.Set(outbox.HeaderSignature, "sha256="+outbox.Sign(d.secret, timestamp, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("webhook %s failed: %w", report.Target, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("webhook %s returned status %d", report.Target, resp.StatusCode)
+	}
+	return "", nil
+}
+
+// StorageDeliverer uploads reports to a Supabase Storage bucket. Objects are named after the end
+// of the reported period under the path prefix in the report target, or the report ID.
+type StorageDeliverer struct {
+	baseURL string
+	key     string
+	bucket  string
+	client  *http.Client
+}
+
+// NewStorageDeliverer creates a deliverer uploading reports to the bucket of the Supabase
+// project at baseURL with the service role key
+func NewStorageDeliverer(baseURL, key, bucket string, client *http.Client) *StorageDeliverer {
+	return &StorageDeliverer{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		key:     key,
+		bucket:  bucket,
+		client:  client,
+	}
+}
+
+// Deliver uploads the report body, replacing an object of the same name, and returns the
+// bucket and object name
+func (d *StorageDeliverer) Deliver(ctx context.Context, report domain.Report, run domain.ReportRun, body []byte) (string, error) {
+	prefix := strings.Trim(report.Target, "/")
+	if prefix == "" {
+		prefix = report.ID
+	}
+	object := path.Join(prefix, run.PeriodTo.UTC().Format("2006-01-02T15-04-05Z")+"."+string(report.Format))
+
+	endpoint := d.baseURL + "/storage/v1/object/" + url.PathEscape(d.bucket) + "/" + (&url.URL{Path: object}).EscapedPath()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create storage request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+d.key)
+	req.Header.Set("apikey", d.key)
+	req.Header.Set("Content-Type", report.Format.ContentType())
+	req.Header.Set("x-upsert", "true")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("storage upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("storage upload returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return d.bucket + "/" + object, nil
+}
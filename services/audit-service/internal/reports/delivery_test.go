@@ -0,0 +1,78 @@
+package reports
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/outbox"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliverer_Deliver(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	deliverer := NewWebhookDeliverer("secret", server.Client(), clock.NewFakeClock(testNow))
+	report := domain.Report{ID: "report-1", Format: domain.ReportCSV, Target: server.URL}
+	run := domain.ReportRun{ID: "run-1"}
+
+	location, err := deliverer.Deliver(context.Background(), report, run, []byte("Date\n"))
+	require.NoError(t, err)
+	assert.Empty(t, location)
+
+	assert.Equal(t, "Date\n", string(body))
+	assert.Equal(t, "text/csv; charset=utf-8", received.Header.Get("Content-Type"))
+	assert.Equal(t, "run-1", received.Header.Get(outbox.HeaderEventID))
+	assert.Equal(t, ReportType, received.Header.Get(outbox.HeaderEventType))
+	timestamp := strconv.FormatInt(testNow.Unix(), 10)
+	assert.Equal(t, timestamp, received.Header.Get(outbox.HeaderTimestamp))
+	assert.Equal(t, "sha256="+outbox.Sign([]byte("secret"), timestamp, []byte("Date\n")), received.Header.Get(outbox.HeaderSignature))
+}
+
+func TestWebhookDeliverer_DeliverFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	deliverer := NewWebhookDeliverer("", server.Client(), clock.NewFakeClock(testNow))
+	_, err := deliverer.Deliver(context.Background(), domain.Report{Target: server.URL}, domain.ReportRun{}, nil)
+	assert.ErrorContains(t, err, "returned status 502")
+}
+
+func TestStorageDeliverer_Deliver(t *testing.T) {
+	var received *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		_, _ = w.Write([]byte(`{"Key":"audit-reports/weekly/2024-01-22T06-00-00Z.json"}`))
+	}))
+	defer server.Close()
+
+	deliverer := NewStorageDeliverer(server.URL+"/", "service-key", "audit-reports", server.Client())
+	report := domain.Report{ID: "report-1", Format: domain.ReportJSON, Target: "weekly/"}
+	run := domain.ReportRun{PeriodTo: time.Date(2024, 1, 22, 6, 0, 0, 0, time.UTC)}
+
+	location, err := deliverer.Deliver(context.Background(), report, run, []byte(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, "audit-reports/weekly/2024-01-22T06-00-00Z.json", location)
+
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "/storage/v1/object/audit-reports/weekly/2024-01-22T06-00-00Z.json", received.URL.Path)
+	assert.Equal(t, "Bearer service-key", received.Header.Get("Authorization"))
+	assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	assert.Equal(t, "true", received.Header.Get("x-upsert"))
+}
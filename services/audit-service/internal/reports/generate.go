@@ -0,0 +1,214 @@
+package reports
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"audit-service/internal/domain"
+)
+
+// pageSize is the number of events fetched per storage request while generating a report
+const pageSize = 500
+
+// Columns of the generated reports
+var (
+	sessionActivityColumns = []domain.ReportColumn{
+		{Key: "date", Title: "Date"},
+		{Key: "session_id", Title: "Session"},
+		{Key: "event_type", Title: "Event type"},
+		{Key: "events", Title: "Events"},
+		{Key: "users", Title: "Users"},
+	}
+	exportSummaryColumns = []domain.ReportColumn{
+		{Key: "session_id", Title: "Session"},
+		{Key: "user_id", Title: "User"},
+		{Key: "exports", Title: "Exports"},
+		{Key: "first_export_at", Title: "First export"},
+		{Key: "last_export_at", Title: "Last export"},
+	}
+)
+
+// activityKey groups the events of a session activity report
+type activityKey struct {
+	date, sessionID, eventType string
+}
+
+// activityCount is a row of a session activity report
+type activityCount struct {
+	events int
+	users  map[string]struct{}
+}
+
+// exportKey groups the events of an export summary report
+type exportKey struct {
+	sessionID, userID string
+}
+
+// exportCount is a row of an export summary report
+type exportCount struct {
+	exports     int
+	first, last time.Time
+}
+
+// generate aggregates the events of the report recorded in [from, to). At most maxEvents
+// events are scanned; the document is marked truncated when the period held more.
+func generate(ctx context.Context, events EventSource, report domain.Report, from, to, now time.Time, maxEvents int) (domain.ReportDocument, error) {
+	doc := domain.ReportDocument{
+		ReportID:    report.ID,
+		Name:        report.Name,
+		Kind:        report.Kind,
+		SessionID:   report.SessionID,
+		PeriodFrom:  from,
+		PeriodTo:    to,
+		GeneratedAt: now,
+		Rows:        [][]any{},
+	}
+
+	// Stored timestamps have microsecond precision and the filter bounds are inclusive
+	filter := domain.EventFilter{From: from, To: to.Add(-time.Microsecond)}
+	if report.Kind == domain.ReportExportSummary {
+		filter.Types = []string{string(domain.ActionExport)}
+	}
+
+	activity := map[activityKey]*activityCount{}
+	exports := map[exportKey]*exportCount{}
+
+	page := domain.PaginationParams{Limit: min(pageSize, maxEvents)}
+	scanned := 0
+	for {
+		entries, _, err := events.QueryEvents(ctx, domain.SessionID(report.SessionID), filter, page)
+		if err != nil {
+			return domain.ReportDocument{}, fmt.Errorf("failed to query events: %w", err)
+		}
+
+		for _, entry := range entries {
+			switch report.Kind {
+			case domain.ReportSessionActivity:
+				key := activityKey{entry.Timestamp.UTC().Format(time.DateOnly), entry.SessionID, entry.Type}
+				count, ok := activity[key]
+				if !ok {
+					count = &activityCount{users: map[string]struct{}{}}
+					activity[key] = count
+				}
+				count.events++
+				count.users[entry.UserID] = struct{}{}
+			case domain.ReportExportSummary:
+				key := exportKey{entry.SessionID, entry.UserID}
+				count, ok := exports[key]
+				if !ok {
+					count = &exportCount{first: entry.Timestamp, last: entry.Timestamp}
+					exports[key] = count
+				}
+				count.exports++
+				if entry.Timestamp.Before(count.first) {
+					count.first = entry.Timestamp
+				}
+				if entry.Timestamp.After(count.last) {
+					count.last = entry.Timestamp
+				}
+			}
+		}
+		scanned += len(entries)
+
+		if len(entries) < page.Limit {
+			break
+		}
+		if scanned >= maxEvents {
+			doc.Truncated = true
+			break
+		}
+		page.Cursor = domain.NewCursor(entries[len(entries)-1])
+		page.Limit = min(pageSize, maxEvents-scanned)
+	}
+
+	switch report.Kind {
+	case domain.ReportSessionActivity:
+		doc.Columns = sessionActivityColumns
+		keys := make([]activityKey, 0, len(activity))
+		for key := range activity {
+			keys = append(keys, key)
+		}
+		slices.SortFunc(keys, func(a, b activityKey) int {
+			return cmp.Or(strings.Compare(a.date, b.date), strings.Compare(a.sessionID, b.sessionID),
+				strings.Compare(a.eventType, b.eventType))
+		})
+		for _, key := range keys {
+			count := activity[key]
+			doc.Rows = append(doc.Rows, []any{key.date, key.sessionID, key.eventType, count.events, len(count.users)})
+		}
+	case domain.ReportExportSummary:
+		doc.Columns = exportSummaryColumns
+		keys := make([]exportKey, 0, len(exports))
+		for key := range exports {
+			keys = append(keys, key)
+		}
+		slices.SortFunc(keys, func(a, b exportKey) int {
+			return cmp.Or(cmp.Compare(exports[b].exports, exports[a].exports), strings.Compare(a.sessionID, b.sessionID),
+				strings.Compare(a.userID, b.userID))
+		})
+		for _, key := range keys {
+			count := exports[key]
+			doc.Rows = append(doc.Rows, []any{key.sessionID, key.userID, count.exports, count.first.UTC(), count.last.UTC()})
+		}
+	}
+	return doc, nil
+}
+
+// render encodes a report document in the format of the report. CSV reports hold the column
+// titles and rows only; JSON reports are the document itself.
+func render(doc domain.ReportDocument, format domain.ReportFormat) ([]byte, error) {
+	if format == domain.ReportJSON {
+		body, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode report: %w", err)
+		}
+		return body, nil
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := make([]string, len(doc.Columns))
+	for i, column := range doc.Columns {
+		header[i] = column.Title
+	}
+	if err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+	for _, row := range doc.Rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			switch value := value.(type) {
+			case time.Time:
+				record[i] = value.Format(time.RFC3339)
+			case string:
+				record[i] = escapeCSVFormula(value)
+			default:
+				record[i] = fmt.Sprint(value)
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return nil, fmt.Errorf("failed to encode report: %w", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// escapeCSVFormula stops spreadsheet applications from evaluating client supplied values as
+// formulas, like the CSV exports of the events API
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,68 @@
+package reports
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate_ExportSummary(t *testing.T) {
+	at := func(h int) time.Time { return testNow.Add(time.Duration(h) * time.Hour) }
+	events := &memoryEvents{entries: []domain.AuditEntry{
+		{ID: "e1", SessionID: testSession, UserID: "user-1", Type: "export", Timestamp: at(1)},
+		{ID: "e2", SessionID: testSession, UserID: "user-2", Type: "export", Timestamp: at(2)},
+		{ID: "e3", SessionID: testSession, UserID: "user-2", Type: "export", Timestamp: at(3)},
+		{ID: "e4", SessionID: testSession, UserID: "user-1", Type: "edit", Timestamp: at(4)},
+	}}
+	report := domain.Report{ID: "report-1", Name: "Monthly exports", Kind: domain.ReportExportSummary}
+
+	doc, err := generate(context.Background(), events, report, testNow, at(24), at(25), 1000)
+	require.NoError(t, err)
+	assert.Equal(t, exportSummaryColumns, doc.Columns)
+	// Most exports first
+	assert.Equal(t, [][]any{
+		{testSession, "user-2", 2, at(2), at(3)},
+		{testSession, "user-1", 1, at(1), at(1)},
+	}, doc.Rows)
+	assert.False(t, doc.Truncated)
+
+	body, err := render(doc, domain.ReportJSON)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	assert.Equal(t, "export_summary", decoded["kind"])
+	assert.Equal(t, []any{testSession, "user-2", float64(2), "2024-01-17T12:30:00Z", "2024-01-17T13:30:00Z"},
+		decoded["rows"].([]any)[0])
+}
+
+func TestGenerate_Truncated(t *testing.T) {
+	events := &memoryEvents{}
+	for i := range 5 {
+		events.entries = append(events.entries, domain.AuditEntry{ID: string(rune('a' + i)), SessionID: testSession,
+			UserID: "user-1", Type: "edit", Timestamp: testNow.Add(time.Duration(i) * time.Minute)})
+	}
+	report := domain.Report{Kind: domain.ReportSessionActivity}
+
+	doc, err := generate(context.Background(), events, report, testNow, testNow.Add(time.Hour), testNow, 3)
+	require.NoError(t, err)
+	assert.True(t, doc.Truncated)
+	assert.Equal(t, [][]any{{"2024-01-17", testSession, "edit", 3, 1}}, doc.Rows)
+}
+
+func TestRender_CSVEscapesFormulas(t *testing.T) {
+	doc := domain.ReportDocument{
+		Columns: exportSummaryColumns,
+		Rows:    [][]any{{testSession, "=HYPERLINK(\"x\")", 1, testNow, testNow}},
+	}
+
+	body, err := render(doc, domain.ReportCSV)
+	require.NoError(t, err)
+	assert.Equal(t, "Session,User,Exports,First export,Last export\n"+
+		testSession+",\"'=HYPERLINK(\"\"x\"\")\",1,2024-01-17T10:30:00Z,2024-01-17T10:30:00Z\n", string(body))
+}
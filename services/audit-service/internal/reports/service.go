@@ -0,0 +1,400 @@
+// Package reports generates recurring reports of the audit events of an organization, such as
+// the weekly session activity or the monthly export summary. Reports run on a cron schedule,
+// are rendered to CSV or table-shaped JSON and are delivered to a webhook or Supabase Storage;
+// every run is recorded in the run history of its report.
+package reports
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/cron"
+	"audit-service/pkg/requestid"
+	"audit-service/pkg/tenant"
+	"audit-service/pkg/workpool"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Repository stores the reports and their runs
+type Repository interface {
+	CreateReport(ctx context.Context, report domain.Report) error
+	GetReport(ctx context.Context, id string) (*domain.Report, error)
+	ListReports(ctx context.Context) ([]domain.Report, error)
+	DeleteReport(ctx context.Context, id string) error
+	ListDueReports(ctx context.Context, at time.Time, limit int) ([]domain.Report, error)
+	AdvanceReport(ctx context.Context, id string, scheduledAt, nextRunAt time.Time) (bool, error)
+	CreateReportRun(ctx context.Context, run domain.ReportRun) error
+	ListReportRuns(ctx context.Context, reportID string, limit int) ([]domain.ReportRun, error)
+}
+
+// EventSource searches the events of a session, or of all sessions when sessionID is empty,
+// in the organization ctx is scoped to
+type EventSource interface {
+	QueryEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, page domain.PaginationParams) ([]domain.AuditEntry, int, error)
+}
+
+// Config controls how often due reports are looked up and how much a run scans
+type Config struct {
+	PollInterval time.Duration
+	// MaxEvents caps the events scanned per run; reports of busier periods are marked truncated
+	MaxEvents int
+	// Pool bounds the reports generated at once, together with the exports sharing it; nil is
+	// unlimited
+	Pool *workpool.Pool
+}
+
+const (
+	// dueBatchSize is the number of due reports claimed per poll
+	dueBatchSize = 100
+
+	// MaxRunHistory is the number of runs returned with the history of a report
+	MaxRunHistory = 100
+)
+
+// Service manages the reports and runs them when they are due
+type Service struct {
+	repo       Repository
+	events     EventSource
+	deliverers map[domain.ReportDelivery]Deliverer
+	cfg        Config
+	clock      clock.Clock
+	metrics    *metrics.Metrics
+	logger     *zap.Logger
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// New creates a report service delivering through the given deliverers; reports cannot be
+// created for deliveries without one. Call Start to run the scheduled reports.
+func New(repo Repository, events EventSource, deliverers map[domain.ReportDelivery]Deliverer, cfg Config, clk clock.Clock,
+	m *metrics.Metrics, logger *zap.Logger) *Service {
+	if cfg.Pool == nil {
+		cfg.Pool = workpool.New(0)
+	}
+	return &Service{
+		repo:       repo,
+		events:     events,
+		deliverers: deliverers,
+		cfg:        cfg,
+		clock:      clk,
+		metrics:    m,
+		logger:     logger,
+		stop:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+}
+
+// Create validates and stores a report of the organization ctx is scoped to; its first run is
+// the first time after now that matches the schedule
+func (s *Service) Create(ctx context.Context, report domain.Report) (domain.Report, error) {
+	organizationID, _ := tenant.FromContext(ctx)
+	now := s.clock.Now()
+	report.ID = uuid.New().String()
+	report.Name = strings.TrimSpace(report.Name)
+	report.Schedule = strings.TrimSpace(report.Schedule)
+	report.Target = strings.TrimSpace(report.Target)
+	report.CreatedAt = now
+	report.LastRunAt = nil
+	report.OrganizationID = organizationID
+	// Session IDs are stored in canonical form so they match audit_logs.session_id
+	if parsed, err := uuid.Parse(report.SessionID); err == nil {
+		report.SessionID = parsed.String()
+	}
+
+	if err := report.Validate(); err != nil {
+		return domain.Report{}, err
+	}
+	if _, ok := s.deliverers[report.Delivery]; !ok {
+		return domain.Report{}, fmt.Errorf("%w: %s delivery is not configured", domain.ErrInvalidReport, report.Delivery)
+	}
+	schedule, err := cron.Parse(report.Schedule)
+	if err != nil {
+		return domain.Report{}, fmt.Errorf("%w: %v", domain.ErrInvalidReport, err)
+	}
+	if report.NextRunAt = schedule.Next(now); report.NextRunAt.IsZero() {
+		return domain.Report{}, fmt.Errorf("%w: schedule never matches", domain.ErrInvalidReport)
+	}
+
+	if err := s.repo.CreateReport(ctx, report); err != nil {
+		return domain.Report{}, err
+	}
+
+	s.logger.Info("report created",
+		requestid.Field(ctx),
+		zap.String("report_id", report.ID),
+		zap.String("kind", string(report.Kind)),
+		zap.String("schedule", report.Schedule),
+		zap.String("delivery", string(report.Delivery)),
+		zap.String("created_by", report.CreatedBy),
+		tenant.Field(ctx),
+	)
+	return report, nil
+}
+
+// Get returns a report of the organization ctx is scoped to
+func (s *Service) Get(ctx context.Context, id string) (domain.Report, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.Report{}, domain.ErrReportNotFound
+	}
+	report, err := s.repo.GetReport(ctx, id)
+	if err != nil {
+		return domain.Report{}, err
+	}
+	return *report, nil
+}
+
+// List returns the reports of the organization ctx is scoped to by name
+func (s *Service) List(ctx context.Context) ([]domain.Report, error) {
+	reports, err := s.repo.ListReports(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if reports == nil {
+		reports = []domain.Report{}
+	}
+	return reports, nil
+}
+
+// Delete removes a report with its run history
+func (s *Service) Delete(ctx context.Context, id, deletedBy string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.ErrReportNotFound
+	}
+	if err := s.repo.DeleteReport(ctx, id); err != nil {
+		return err
+	}
+
+	s.logger.Info("report deleted",
+		requestid.Field(ctx),
+		zap.String("report_id", id),
+		zap.String("deleted_by", deletedBy),
+		tenant.Field(ctx),
+	)
+	return nil
+}
+
+// Runs returns the latest runs of a report, newest first
+func (s *Service) Runs(ctx context.Context, id string, limit int) ([]domain.ReportRun, error) {
+	// Looking the report up first scopes the history to the organization of ctx
+	report, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > MaxRunHistory {
+		limit = MaxRunHistory
+	}
+
+	runs, err := s.repo.ListReportRuns(ctx, report.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+	if runs == nil {
+		runs = []domain.ReportRun{}
+	}
+	return runs, nil
+}
+
+// Run generates and delivers a report now, outside of its schedule. The period defaults to
+// the time since the last scheduled run until now; the schedule is not changed. A failed
+// generation or delivery is reported in the returned run.
+func (s *Service) Run(ctx context.Context, id string, from, to time.Time) (domain.ReportRun, error) {
+	report, err := s.Get(ctx, id)
+	if err != nil {
+		return domain.ReportRun{}, err
+	}
+	if from.IsZero() {
+		from = report.PeriodStart()
+	}
+	if to.IsZero() {
+		to = s.clock.Now()
+	}
+	if !from.Before(to) {
+		return domain.ReportRun{}, fmt.Errorf("%w: from must be before to", domain.ErrInvalidReport)
+	}
+
+	return s.execute(ctx, report, domain.ReportTriggerManual, from.UTC(), to.UTC())
+}
+
+// Start looks up due reports once per poll interval until Close is called
+func (s *Service) Start() {
+	go s.run()
+}
+
+// Close stops the schedule and waits for running reports to be cancelled
+func (s *Service) Close(ctx context.Context) error {
+	s.closeOnce.Do(func() { close(s.stop) })
+
+	select {
+	case <-s.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("report scheduler did not stop: %w", ctx.Err())
+	}
+}
+
+// run runs the due reports on the configured interval until Close is called
+func (s *Service) run() {
+	defer close(s.stopped)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-s.stop
+		cancel()
+	}()
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunDue(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("scheduled reports failed", zap.Error(err))
+		}
+
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue runs the reports of every organization that are due and returns how many ran. Each
+// run is claimed by moving the schedule of its report on first, so replicas polling at the
+// same time run it once; runs missed while the service was down are merged into one.
+func (s *Service) RunDue(ctx context.Context) (int, error) {
+	now := s.clock.Now()
+	due, err := s.repo.ListDueReports(ctx, now, dueBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list due reports: %w", err)
+	}
+
+	ran := 0
+	for _, report := range due {
+		if ctx.Err() != nil {
+			break
+		}
+
+		schedule, err := cron.Parse(report.Schedule)
+		if err != nil {
+			s.logger.Error("invalid report schedule", zap.String("report_id", report.ID), zap.Error(err))
+			continue
+		}
+		next := schedule.Next(now)
+		if next.IsZero() {
+			// Keep the report listed once a year rather than every poll
+			next = now.AddDate(1, 0, 0)
+		}
+
+		scheduledAt := report.NextRunAt
+		claimed, err := s.repo.AdvanceReport(ctx, report.ID, scheduledAt, next)
+		if err != nil {
+			s.logger.Error("failed to advance report schedule", zap.String("report_id", report.ID), zap.Error(err))
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		reportCtx := tenant.NewContext(ctx, report.OrganizationID)
+		if _, err := s.execute(reportCtx, report, domain.ReportTriggerSchedule, report.PeriodStart(), scheduledAt); err != nil {
+			s.logger.Error("failed to record report run", zap.String("report_id", report.ID), zap.Error(err))
+		}
+		ran++
+	}
+	return ran, nil
+}
+
+// execute generates and delivers a report for [from, to) and records the run
+func (s *Service) execute(ctx context.Context, report domain.Report, trigger domain.ReportTrigger, from, to time.Time) (domain.ReportRun, error) {
+	run := domain.ReportRun{
+		ID:         uuid.New().String(),
+		ReportID:   report.ID,
+		Trigger:    trigger,
+		PeriodFrom: from,
+		PeriodTo:   to,
+		StartedAt:  s.clock.Now(),
+
+		OrganizationID: report.OrganizationID,
+	}
+
+	location, rows, err := s.generateAndDeliver(ctx, report, run)
+	run.FinishedAt = s.clock.Now()
+	run.Rows = rows
+	run.Location = location
+	run.Status = domain.ReportRunSucceeded
+	if err != nil {
+		run.Status = domain.ReportRunFailed
+		run.Error = err.Error()
+	}
+	s.metrics.ObserveReportRun(string(report.Kind), string(run.Status))
+
+	if err != nil {
+		s.logger.Warn("report run failed",
+			requestid.Field(ctx),
+			zap.String("report_id", report.ID),
+			zap.String("run_id", run.ID),
+			zap.String("trigger", string(trigger)),
+			tenant.Field(ctx),
+			zap.Error(err),
+		)
+	} else {
+		s.logger.Info("report delivered",
+			requestid.Field(ctx),
+			zap.String("report_id", report.ID),
+			zap.String("run_id", run.ID),
+			zap.String("trigger", string(trigger)),
+			zap.Time("period_from", from),
+			zap.Time("period_to", to),
+			zap.Int("rows", rows),
+			tenant.Field(ctx),
+		)
+	}
+
+	// The run is recorded even when the scheduler is stopping, so the history stays complete
+	if err := s.repo.CreateReportRun(context.WithoutCancel(ctx), run); err != nil {
+		return run, err
+	}
+	return run, nil
+}
+
+// generateAndDeliver renders the report for the period of the run and delivers it, returning
+// where it was stored and the number of rows
+func (s *Service) generateAndDeliver(ctx context.Context, report domain.Report, run domain.ReportRun) (string, int, error) {
+	deliverer, ok := s.deliverers[report.Delivery]
+	if !ok {
+		return "", 0, fmt.Errorf("%s delivery is not configured", report.Delivery)
+	}
+
+	var (
+		doc  domain.ReportDocument
+		body []byte
+	)
+	err := s.cfg.Pool.Run(ctx, func(ctx context.Context) error {
+		var err error
+		if doc, err = generate(ctx, s.events, report, run.PeriodFrom, run.PeriodTo, run.StartedAt, s.cfg.MaxEvents); err != nil {
+			return err
+		}
+		body, err = render(doc, report.Format)
+		return err
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	location, err := deliverer.Deliver(ctx, report, run, body)
+	if err != nil {
+		return "", len(doc.Rows), err
+	}
+	return location, len(doc.Rows), nil
+}
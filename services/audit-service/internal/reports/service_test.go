@@ -0,0 +1,295 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// A Wednesday
+var testNow = time.Date(2024, 1, 17, 10, 30, 0, 0, time.UTC)
+
+const testSession = "550e8400-e29b-41d4-a716-446655440000"
+
+// memoryRepository keeps reports and runs in memory like the audit_reports tables
+type memoryRepository struct {
+	reports []domain.Report
+	runs    []domain.ReportRun
+}
+
+func (r *memoryRepository) CreateReport(_ context.Context, report domain.Report) error {
+	r.reports = append(r.reports, report)
+	return nil
+}
+
+func (r *memoryRepository) GetReport(ctx context.Context, id string) (*domain.Report, error) {
+	for _, report := range r.reports {
+		if report.ID == id && tenant.Allows(ctx, report.OrganizationID) {
+			return &report, nil
+		}
+	}
+	return nil, domain.ErrReportNotFound
+}
+
+func (r *memoryRepository) ListReports(ctx context.Context) ([]domain.Report, error) {
+	var reports []domain.Report
+	for _, report := range r.reports {
+		if tenant.Allows(ctx, report.OrganizationID) {
+			reports = append(reports, report)
+		}
+	}
+	return reports, nil
+}
+
+func (r *memoryRepository) DeleteReport(ctx context.Context, id string) error {
+	for i, report := range r.reports {
+		if report.ID == id && tenant.Allows(ctx, report.OrganizationID) {
+			r.reports = slices.Delete(r.reports, i, i+1)
+			return nil
+		}
+	}
+	return domain.ErrReportNotFound
+}
+
+func (r *memoryRepository) ListDueReports(_ context.Context, at time.Time, limit int) ([]domain.Report, error) {
+	var due []domain.Report
+	for _, report := range r.reports {
+		if !report.NextRunAt.After(at) && len(due) < limit {
+			due = append(due, report)
+		}
+	}
+	return due, nil
+}
+
+func (r *memoryRepository) AdvanceReport(_ context.Context, id string, scheduledAt, nextRunAt time.Time) (bool, error) {
+	for i, report := range r.reports {
+		if report.ID == id && report.NextRunAt.Equal(scheduledAt) {
+			r.reports[i].LastRunAt = &scheduledAt
+			r.reports[i].NextRunAt = nextRunAt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryRepository) CreateReportRun(_ context.Context, run domain.ReportRun) error {
+	r.runs = append(r.runs, run)
+	return nil
+}
+
+func (r *memoryRepository) ListReportRuns(_ context.Context, reportID string, limit int) ([]domain.ReportRun, error) {
+	var runs []domain.ReportRun
+	for i := len(r.runs) - 1; i >= 0 && len(runs) < limit; i-- {
+		if r.runs[i].ReportID == reportID {
+			runs = append(runs, r.runs[i])
+		}
+	}
+	return runs, nil
+}
+
+// memoryEvents serves events newest first with keyset pagination like the storage backends
+type memoryEvents struct {
+	entries []domain.AuditEntry
+	queries int
+}
+
+func (e *memoryEvents) QueryEvents(_ context.Context, sessionID domain.SessionID, filter domain.EventFilter, page domain.PaginationParams) ([]domain.AuditEntry, int, error) {
+	e.queries++
+	var matched []domain.AuditEntry
+	for _, entry := range e.entries {
+		if (sessionID == "" || entry.SessionID == sessionID.String()) &&
+			!entry.Timestamp.Before(filter.From) && !entry.Timestamp.After(filter.To) &&
+			(len(filter.Types) == 0 || slices.Contains(filter.Types, entry.Type)) {
+			matched = append(matched, entry)
+		}
+	}
+	slices.SortFunc(matched, func(a, b domain.AuditEntry) int { return b.Timestamp.Compare(a.Timestamp) })
+
+	if page.Cursor != nil {
+		for i, entry := range matched {
+			if entry.ID == page.Cursor.ID {
+				matched = matched[i+1:]
+				break
+			}
+		}
+	}
+	return matched[:min(page.Limit, len(matched))], len(matched), nil
+}
+
+// recordingDeliverer records the delivered reports
+type recordingDeliverer struct {
+	bodies []string
+	err    error
+}
+
+func (d *recordingDeliverer) Deliver(_ context.Context, _ domain.Report, _ domain.ReportRun, body []byte) (string, error) {
+	d.bodies = append(d.bodies, string(body))
+	return "audit-reports/report.csv", d.err
+}
+
+func newTestService(repo *memoryRepository, events *memoryEvents, deliverer *recordingDeliverer) (*Service, *clock.FakeClock) {
+	clk := clock.NewFakeClock(testNow)
+	return New(repo, events, map[domain.ReportDelivery]Deliverer{domain.ReportWebhook: deliverer},
+		Config{PollInterval: time.Minute, MaxEvents: 1000}, clk, metrics.New(), zap.NewNop()), clk
+}
+
+func weeklyReport() domain.Report {
+	return domain.Report{
+		Name:      "Weekly session activity",
+		Kind:      domain.ReportSessionActivity,
+		Schedule:  "0 6 * * 1",
+		Format:    domain.ReportCSV,
+		Delivery:  domain.ReportWebhook,
+		Target:    "https://reports.example.com/audit",
+		CreatedBy: "admin-1",
+	}
+}
+
+func TestService_Create(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(r *domain.Report)
+		wantErr string
+	}{
+		{name: "valid", modify: func(r *domain.Report) {}},
+		{name: "invalid schedule", modify: func(r *domain.Report) { r.Schedule = "every monday" }, wantErr: "expected 5 fields"},
+		{name: "schedule never matches", modify: func(r *domain.Report) { r.Schedule = "0 0 30 2 *" }, wantErr: "never matches"},
+		{name: "unknown kind", modify: func(r *domain.Report) { r.Kind = "revenue" }, wantErr: "kind must be"},
+		{name: "webhook without URL", modify: func(r *domain.Report) { r.Target = "reports" }, wantErr: "http or https URL"},
+		{name: "storage not configured", modify: func(r *domain.Report) {
+			r.Delivery = domain.ReportStorage
+			r.Target = "weekly"
+		}, wantErr: "storage delivery is not configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryRepository{}
+			service, _ := newTestService(repo, &memoryEvents{}, &recordingDeliverer{})
+			report := weeklyReport()
+			tt.modify(&report)
+
+			created, err := service.Create(tenant.NewContext(context.Background(), "acme"), report)
+			if tt.wantErr != "" {
+				assert.ErrorIs(t, err, domain.ErrInvalidReport)
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Empty(t, repo.reports)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, created.ID)
+			assert.Equal(t, "acme", created.OrganizationID)
+			assert.Equal(t, testNow, created.CreatedAt)
+			// The following Monday at 06:00
+			assert.Equal(t, time.Date(2024, 1, 22, 6, 0, 0, 0, time.UTC), created.NextRunAt)
+			assert.Equal(t, []domain.Report{created}, repo.reports)
+		})
+	}
+}
+
+func TestService_RunDue(t *testing.T) {
+	repo := &memoryRepository{}
+	events := &memoryEvents{entries: []domain.AuditEntry{
+		{ID: "e1", SessionID: testSession, UserID: "user-1", Type: "edit", Timestamp: testNow.Add(time.Hour)},
+		{ID: "e2", SessionID: testSession, UserID: "user-2", Type: "edit", Timestamp: testNow.Add(2 * time.Hour)},
+		{ID: "e3", SessionID: testSession, UserID: "user-1", Type: "share", Timestamp: testNow.AddDate(0, 0, 1)},
+		// Recorded after the period of the run
+		{ID: "e4", SessionID: testSession, UserID: "user-1", Type: "edit", Timestamp: time.Date(2024, 1, 22, 6, 0, 0, 0, time.UTC)},
+	}}
+	deliverer := &recordingDeliverer{}
+	service, clk := newTestService(repo, events, deliverer)
+	ctx := context.Background()
+
+	report, err := service.Create(ctx, weeklyReport())
+	require.NoError(t, err)
+
+	// Nothing is due before the first run
+	ran, err := service.RunDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, ran)
+
+	clk.Set(time.Date(2024, 1, 22, 6, 0, 30, 0, time.UTC))
+	ran, err = service.RunDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, ran)
+
+	require.Len(t, deliverer.bodies, 1)
+	assert.Equal(t, "Date,Session,Event type,Events,Users\n"+
+		"2024-01-17,"+testSession+",edit,2,2\n"+
+		"2024-01-18,"+testSession+",share,1,1\n", deliverer.bodies[0])
+
+	require.Len(t, repo.runs, 1)
+	run := repo.runs[0]
+	assert.Equal(t, domain.ReportTriggerSchedule, run.Trigger)
+	assert.Equal(t, domain.ReportRunSucceeded, run.Status)
+	assert.Equal(t, testNow, run.PeriodFrom)
+	assert.Equal(t, time.Date(2024, 1, 22, 6, 0, 0, 0, time.UTC), run.PeriodTo)
+	assert.Equal(t, 2, run.Rows)
+
+	// The schedule moved on a week and the next period starts where this one ended
+	stored, err := service.Get(ctx, report.ID)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 29, 6, 0, 0, 0, time.UTC), stored.NextRunAt)
+	assert.Equal(t, run.PeriodTo, stored.PeriodStart())
+
+	// A run is claimed once
+	ran, err = service.RunDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, ran)
+}
+
+func TestService_Run(t *testing.T) {
+	t.Run("records failed deliveries", func(t *testing.T) {
+		repo := &memoryRepository{}
+		deliverer := &recordingDeliverer{err: errors.New("webhook returned status 502")}
+		service, _ := newTestService(repo, &memoryEvents{}, deliverer)
+		ctx := context.Background()
+		report, err := service.Create(ctx, weeklyReport())
+		require.NoError(t, err)
+
+		run, err := service.Run(ctx, report.ID, testNow.Add(-time.Hour), time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, domain.ReportTriggerManual, run.Trigger)
+		assert.Equal(t, domain.ReportRunFailed, run.Status)
+		assert.Equal(t, "webhook returned status 502", run.Error)
+		assert.Equal(t, testNow, run.PeriodTo)
+		assert.Equal(t, []domain.ReportRun{run}, repo.runs)
+
+		// Manual runs leave the schedule alone
+		stored, err := service.Get(ctx, report.ID)
+		require.NoError(t, err)
+		assert.Equal(t, report.NextRunAt, stored.NextRunAt)
+		assert.Nil(t, stored.LastRunAt)
+	})
+
+	t.Run("rejects empty periods", func(t *testing.T) {
+		service, _ := newTestService(&memoryRepository{}, &memoryEvents{}, &recordingDeliverer{})
+		report, err := service.Create(context.Background(), weeklyReport())
+		require.NoError(t, err)
+
+		_, err = service.Run(context.Background(), report.ID, testNow, testNow)
+		assert.ErrorIs(t, err, domain.ErrInvalidReport)
+	})
+
+	t.Run("reports of other organizations are not found", func(t *testing.T) {
+		service, _ := newTestService(&memoryRepository{}, &memoryEvents{}, &recordingDeliverer{})
+		report, err := service.Create(tenant.NewContext(context.Background(), "acme"), weeklyReport())
+		require.NoError(t, err)
+
+		_, err = service.Run(tenant.NewContext(context.Background(), "globex"), report.ID, time.Time{}, time.Time{})
+		assert.ErrorIs(t, err, domain.ErrReportNotFound)
+		_, err = service.Runs(tenant.NewContext(context.Background(), "globex"), report.ID, 10)
+		assert.ErrorIs(t, err, domain.ErrReportNotFound)
+	})
+}
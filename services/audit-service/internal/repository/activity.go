@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"audit-service/internal/domain"
+)
+
+// ActivityRepository maintains the activity rollups, the hourly and daily event counts per
+// session and user read by AuditRepository.GetSessionActivity
+type ActivityRepository interface {
+	// RollupActivity replaces the rollups starting in [from, to) with the aggregates of the events
+	// recorded in that range and returns the number of rollups written. from must be the start of
+	// a UTC day; ctx must not be scoped to an organization.
+	RollupActivity(ctx context.Context, from, to time.Time) (int, error)
+}
+
+// activityRow represents an audit_activity_rollups row
+type activityRow struct {
+	BucketStart time.Time      `json:"bucket_start"`
+	UserID      string         `json:"user_id"`
+	EventCount  int            `json:"event_count"`
+	ByType      map[string]int `json:"by_type"`
+}
+
+// toActivityBucket converts a database row into a domain bucket
+func (row activityRow) toActivityBucket() domain.ActivityBucket {
+	bucket := domain.ActivityBucket{
+		Start:      row.BucketStart.UTC(),
+		UserID:     row.UserID,
+		EventCount: row.EventCount,
+		ByType:     row.ByType,
+	}
+	if bucket.ByType == nil {
+		bucket.ByType = map[string]int{}
+	}
+	return bucket
+}
+
+// heatmapRow represents a row returned by the session_slide_heatmap function
+type heatmapRow struct {
+	SlideID string `json:"slide_id"`
+	// Null for slides missing from the slides table
+	SlideIndex *int      `json:"slide_index"`
+	Hour       time.Time `json:"hour"`
+	EventCount int       `json:"event_count"`
+}
+
+// toHeatmapCell converts a database row into a domain cell
+func (row heatmapRow) toHeatmapCell() domain.HeatmapCell {
+	cell := domain.HeatmapCell{
+		SlideID:    row.SlideID,
+		Hour:       row.Hour.UTC(),
+		EventCount: row.EventCount,
+	}
+	if row.SlideIndex != nil {
+		cell.SlideIndex = *row.SlideIndex
+	}
+	return cell
+}
+
+// contributorRow represents a row returned by the session_contributors function
+type contributorRow struct {
+	UserID        string    `json:"user_id"`
+	Edits         int       `json:"edits"`
+	Comments      int       `json:"comments"`
+	Merges        int       `json:"merges"`
+	TotalEvents   int       `json:"total_events"`
+	FirstActivity time.Time `json:"first_activity"`
+	LastActivity  time.Time `json:"last_activity"`
+}
+
+// toContributorStats converts a database row into domain stats
+func (row contributorRow) toContributorStats() domain.ContributorStats {
+	return domain.ContributorStats{
+		UserID:        row.UserID,
+		Edits:         row.Edits,
+		Comments:      row.Comments,
+		Merges:        row.Merges,
+		TotalEvents:   row.TotalEvents,
+		FirstActivity: row.FirstActivity.UTC(),
+		LastActivity:  row.LastActivity.UTC(),
+	}
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"audit-service/internal/domain"
+)
+
+// AnnotationRepository stores the annotations reviewers attach to audit events, in a table beside
+// the events so the entries themselves stay unchanged. Lookups are scoped to the organization of
+// ctx.
+type AnnotationRepository interface {
+	// CreateAnnotation stores a new annotation at version 1
+	CreateAnnotation(ctx context.Context, annotation domain.EventAnnotation) error
+	// GetAnnotation returns an annotation, or domain.ErrAnnotationNotFound
+	GetAnnotation(ctx context.Context, id string) (*domain.EventAnnotation, error)
+	// ListAnnotations returns the annotations of an event, oldest first
+	ListAnnotations(ctx context.Context, eventID string) ([]domain.EventAnnotation, error)
+	// UpdateAnnotation stores the body of an annotation at annotation.Version, provided the stored
+	// annotation is at the version before. It returns domain.ErrVersionConflict when another edit
+	// was stored first or the annotation was deleted.
+	UpdateAnnotation(ctx context.Context, annotation domain.EventAnnotation) error
+	// DeleteAnnotation removes an annotation at version. It returns domain.ErrVersionConflict
+	// when the annotation was edited since or is gone.
+	DeleteAnnotation(ctx context.Context, id string, version int64) error
+}
+
+// annotationRow represents an audit_event_annotations row
+type annotationRow struct {
+	ID        string    `json:"id"`
+	EventID   string    `json:"event_id"`
+	SessionID string    `json:"session_id"`
+	AuthorID  string    `json:"author_id"`
+	Body      string    `json:"body"`
+	Version   int64     `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Omitted, and stored as null, for annotations of events of the default organization
+	OrganizationID string `json:"organization_id,omitempty"`
+}
+
+// newAnnotationRow converts a domain annotation into a database row
+func newAnnotationRow(annotation domain.EventAnnotation) annotationRow {
+	return annotationRow{
+		ID:        annotation.ID,
+		EventID:   annotation.EventID,
+		SessionID: annotation.SessionID,
+		AuthorID:  annotation.AuthorID,
+		Body:      annotation.Body,
+		Version:   annotation.Version,
+		CreatedAt: annotation.CreatedAt.UTC(),
+		UpdatedAt: annotation.UpdatedAt.UTC(),
+
+		OrganizationID: annotation.OrganizationID,
+	}
+}
+
+// toAnnotation converts a database row into a domain annotation
+func (row annotationRow) toAnnotation() domain.EventAnnotation {
+	return domain.EventAnnotation{
+		ID:        row.ID,
+		EventID:   row.EventID,
+		SessionID: row.SessionID,
+		AuthorID:  row.AuthorID,
+		Body:      row.Body,
+		Version:   row.Version,
+		CreatedAt: row.CreatedAt.UTC(),
+		UpdatedAt: row.UpdatedAt.UTC(),
+
+		OrganizationID: row.OrganizationID,
+	}
+}
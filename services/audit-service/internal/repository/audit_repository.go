@@ -3,77 +3,235 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
-	"strings"
+	"time"
 
 	"audit-service/internal/domain"
+	"audit-service/pkg/requestid"
 
 	"go.uber.org/zap"
 )
 
 // AuditRepository defines the interface for audit data access
 type AuditRepository interface {
-	FindBySessionID(ctx context.Context, sessionID string, limit, offset int) ([]domain.AuditEntry, int, error)
-	GetSession(ctx context.Context, sessionID string) (*Session, error)
-	ValidateShareToken(ctx context.Context, token, sessionID string) (bool, error)
+	FindBySessionID(ctx context.Context, sessionID domain.SessionID, page domain.PaginationParams) ([]domain.AuditEntry, int, error)
+	GetSession(ctx context.Context, sessionID domain.SessionID) (*Session, error)
+	GetActiveShare(ctx context.Context, tokenJTI string, sessionID domain.SessionID) (*domain.Share, error)
+	CreateEvents(ctx context.Context, entries []domain.AuditEntry) error
+	// QueryEvents searches the events of a session, or of all sessions when sessionID is empty
+	QueryEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, page domain.PaginationParams) ([]domain.AuditEntry, int, error)
+	GetSessionStats(ctx context.Context, sessionID domain.SessionID) (*domain.SessionStats, error)
+	// GetSessionActivity returns the rolled-up activity of a session in the normalized query range,
+	// ordered by bucket start and user
+	GetSessionActivity(ctx context.Context, sessionID domain.SessionID, query domain.ActivityQuery) ([]domain.ActivityBucket, error)
+	// GetSlideHeatmap returns the hourly event counts per slide of a session in the normalized
+	// query range
+	GetSlideHeatmap(ctx context.Context, sessionID domain.SessionID, query domain.HeatmapQuery) ([]domain.HeatmapCell, error)
+	// GetSessionContributors returns the contribution of every user who recorded events in the
+	// query range of a session, most events first
+	GetSessionContributors(ctx context.Context, sessionID domain.SessionID, query domain.ContributorQuery) ([]domain.ContributorStats, error)
+	PurgeEvents(ctx context.Context, eventType string, before time.Time, limit int) ([]domain.PurgedEvents, error)
+	FindChain(ctx context.Context, sessionID domain.SessionID, afterSeq int64, limit int) ([]domain.ChainedEntry, error)
+	// FindEvent returns the event with the given ID, or domain.ErrEventNotFound
+	FindEvent(ctx context.Context, eventID domain.EventID) (*domain.AuditEntry, error)
+	// ExistingEventIDs returns those of the IDs that are already stored, in any organization
+	ExistingEventIDs(ctx context.Context, ids []string) ([]string, error)
+	FindExpiredEvents(ctx context.Context, eventType string, before time.Time, limit int) ([]domain.AuditEntry, error)
+	DeleteEvents(ctx context.Context, ids []string) ([]domain.PurgedEvents, error)
+	// EraseUserEvents skips entries under a legal hold unless overrideHolds is set
+	EraseUserEvents(ctx context.Context, userID domain.UserID, mode domain.ErasureMode, overrideHolds bool, limit int) ([]domain.PurgedEvents, error)
+	// DeleteUserActivity deletes the activity rollups of a user whose entries were erased
+	DeleteUserActivity(ctx context.Context, userID domain.UserID) error
 }
 
 // auditRepository implements the AuditRepository interface
 type auditRepository struct {
 	client SupabaseClientInterface
 	logger *zap.Logger
+	// outbox makes CreateEvents queue every entry for forwarding by the relay worker
+	outbox bool
 }
 
 // NewAuditRepository creates a new audit repository instance
 func NewAuditRepository(client SupabaseClientInterface, logger *zap.Logger) AuditRepository {
+	return newAuditRepository(client, logger, false)
+}
+
+// newAuditRepository creates an audit repository, optionally writing outbox messages with each entry
+func newAuditRepository(client SupabaseClientInterface, logger *zap.Logger, outbox bool) *auditRepository {
 	return &auditRepository{
 		client: client,
 		logger: logger,
+		outbox: outbox,
 	}
 }
 
 // Session represents a session from the database
 type Session struct {
-	ID     string `json:"id"`
-	UserID string `json:"user_id"`
+	ID     domain.SessionID `json:"id"`
+	UserID domain.UserID    `json:"user_id"`
+}
+
+// shareRow represents a session_shares row as read from the database
+type shareRow struct {
+	ID            string     `json:"id"`
+	SessionID     string     `json:"session_id"`
+	ShareTokenJTI string     `json:"share_token_jti"`
+	Permissions   []string   `json:"permissions"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+}
+
+// auditLogRow represents an audit_logs row as written to the database
+type auditLogRow struct {
+	ID          string          `json:"id"`
+	SessionID   string          `json:"session_id"`
+	UserID      string          `json:"user_id"`
+	Type        string          `json:"type"`
+	Timestamp   string          `json:"timestamp"`
+	Details     json.RawMessage `json:"details,omitempty"`
+	IPAddress   string          `json:"ip_address,omitempty"`
+	UserAgent   string          `json:"user_agent,omitempty"`
+	ContentHash string          `json:"content_hash,omitempty"`
+	// Omitted, and stored as null, when the event is not part of a chain
+	CorrelationID string `json:"correlation_id,omitempty"`
+	ParentEventID string `json:"parent_event_id,omitempty"`
+	// Omitted, and stored as null, for events without a schema version
+	SchemaVersion int `json:"schema_version,omitempty"`
+	// Omitted, and stored as null, when no details were redacted
+	RedactedFields []string `json:"redacted_fields,omitempty"`
+	// Omitted, and stored as null, for entries of the default organization
+	OrganizationID string `json:"organization_id,omitempty"`
+	// Omitted, and stored as null, when the details reference no slide or shape
+	SlideID string `json:"slide_id,omitempty"`
+	ShapeID string `json:"shape_id,omitempty"`
+	// Omitted, and stored as null, when the details reference no comment or thread
+	CommentID string `json:"comment_id,omitempty"`
+	ThreadID  string `json:"thread_id,omitempty"`
+	// Omitted, and stored as null, for entries without a received time
+	ReceivedAt string `json:"received_at,omitempty"`
+}
+
+// chainedLogRow is an audit_logs row including the columns set by the audit_logs_chain trigger
+type chainedLogRow struct {
+	auditLogRow
+	Seq        int64      `json:"seq"`
+	PrevHash   string     `json:"prev_hash"`
+	ChainHash  string     `json:"chain_hash"`
+	RedactedAt *time.Time `json:"redacted_at"`
+}
+
+// newAuditLogRow converts a domain entry into its database representation
+func newAuditLogRow(entry domain.AuditEntry) (auditLogRow, error) {
+	// Postgres keeps microseconds; truncating here keeps the stored value equal to the hashed one
+	entry.Timestamp = entry.Timestamp.UTC().Truncate(time.Microsecond)
+
+	var receivedAt string
+	if entry.ReceivedAt != nil {
+		receivedAt = entry.ReceivedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+	}
+
+	contentHash, err := domain.ContentHash(entry)
+	if err != nil {
+		return auditLogRow{}, err
+	}
+
+	return auditLogRow{
+		ID:          entry.ID,
+		SessionID:   entry.SessionID,
+		UserID:      entry.UserID,
+		Type:        entry.Type,
+		Timestamp:   entry.Timestamp.Format(time.RFC3339Nano),
+		Details:     entry.Details,
+		IPAddress:   entry.IPAddress,
+		UserAgent:   entry.UserAgent,
+		ContentHash: contentHash,
+		// Causal links of the event
+		CorrelationID: entry.CorrelationID,
+		ParentEventID: entry.ParentEventID,
+		SchemaVersion: entry.SchemaVersion,
+		// Details masked on ingestion
+		RedactedFields: entry.RedactedFields,
+		OrganizationID: entry.OrganizationID,
+		SlideID:        entry.SlideID,
+		ShapeID:        entry.ShapeID,
+		CommentID:      entry.CommentID,
+		ThreadID:       entry.ThreadID,
+		ReceivedAt:     receivedAt,
+	}, nil
 }
 
-// ShareToken represents a share token from the database
-type ShareToken struct {
-	Token     string `json:"token"`
-	SessionID string `json:"session_id"`
-	ExpiresAt string `json:"expires_at,omitempty"`
+// toEntry converts a database row into a domain entry
+func (row auditLogRow) toEntry() (domain.AuditEntry, error) {
+	timestamp, err := time.Parse(time.RFC3339Nano, row.Timestamp)
+	if err != nil {
+		return domain.AuditEntry{}, fmt.Errorf("invalid timestamp for audit log %s: %w", row.ID, err)
+	}
+	var receivedAt *time.Time
+	if row.ReceivedAt != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, row.ReceivedAt)
+		if err != nil {
+			return domain.AuditEntry{}, fmt.Errorf("invalid received time for audit log %s: %w", row.ID, err)
+		}
+		parsed = parsed.UTC()
+		receivedAt = &parsed
+	}
+
+	return domain.AuditEntry{
+		ID:        row.ID,
+		SessionID: row.SessionID,
+		UserID:    row.UserID,
+		Type:      row.Type,
+		Timestamp: timestamp.UTC(),
+		Details:   row.Details,
+		IPAddress: row.IPAddress,
+		UserAgent: row.UserAgent,
+		// Server time the event arrived at
+		ReceivedAt: receivedAt,
+		// Causal links of the event
+		CorrelationID: row.CorrelationID,
+		ParentEventID: row.ParentEventID,
+		SchemaVersion: row.SchemaVersion,
+		// Details masked on ingestion
+		RedactedFields: row.RedactedFields,
+		OrganizationID: row.OrganizationID,
+		SlideID:        row.SlideID,
+		ShapeID:        row.ShapeID,
+		CommentID:      row.CommentID,
+		ThreadID:       row.ThreadID,
+	}, nil
 }
 
 // FindBySessionID retrieves audit logs for a specific session
-func (r *auditRepository) FindBySessionID(ctx context.Context, sessionID string, limit, offset int) ([]domain.AuditEntry, int, error) {
+func (r *auditRepository) FindBySessionID(ctx context.Context, sessionID domain.SessionID, page domain.PaginationParams) ([]domain.AuditEntry, int, error) {
 	// For test session IDs, return empty results
 	// In a real implementation, we would inject a test event store here
 	// and fetch test events from it
-	if strings.HasPrefix(sessionID, "test-") {
+	if sessionID.IsTestSession() {
 		r.logger.Debug("test session ID detected, returning empty audit logs",
-			zap.String("session_id", sessionID),
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
 		)
 
 		// Return empty results for now - test events are handled separately
 		return []domain.AuditEntry{}, 0, nil
 	}
 
-	// Build query parameters
-	queryParams := map[string]string{
-		"session_id": fmt.Sprintf("eq.%s", sessionID),
-		"order":      "timestamp.desc",
-		"limit":      strconv.Itoa(limit),
-		"offset":     strconv.Itoa(offset),
-		"select":     "*",
-	}
+	// Build query
+	query := NewQuery().
+		Where(Eq("session_id", sessionID.String())).
+		Count(CountExact)
+	applyPage(query, page)
+	applyOrganization(ctx, query)
 
 	// Make request to Supabase
-	data, count, err := r.client.Get(ctx, "/audit_logs", queryParams)
+	data, count, err := r.client.Get(ctx, "/audit_logs", query)
 	if err != nil {
 		r.logger.Error("failed to fetch audit logs",
-			zap.String("session_id", sessionID),
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
 			zap.Error(err),
 		)
 		return nil, 0, fmt.Errorf("failed to fetch audit logs: %w", err)
@@ -83,14 +241,16 @@ func (r *auditRepository) FindBySessionID(ctx context.Context, sessionID string,
 	var entries []domain.AuditEntry
 	if err := json.Unmarshal(data, &entries); err != nil {
 		r.logger.Error("failed to parse audit logs",
-			zap.String("session_id", sessionID),
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
 			zap.Error(err),
 		)
 		return nil, 0, fmt.Errorf("failed to parse audit logs: %w", err)
 	}
 
 	r.logger.Debug("fetched audit logs",
-		zap.String("session_id", sessionID),
+		requestid.Field(ctx),
+		zap.Stringer("session_id", sessionID),
 		zap.Int("count", len(entries)),
 		zap.Int("total", count),
 	)
@@ -98,20 +258,419 @@ func (r *auditRepository) FindBySessionID(ctx context.Context, sessionID string,
 	return entries, count, nil
 }
 
-// GetSession retrieves session information
-func (r *auditRepository) GetSession(ctx context.Context, sessionID string) (*Session, error) {
-	// Build query parameters
-	queryParams := map[string]string{
-		"id":     fmt.Sprintf("eq.%s", sessionID),
-		"select": "id,user_id",
-		"limit":  "1",
+// QueryEvents retrieves audit logs for a session, or for all sessions when sessionID is empty, matching the given filter
+func (r *auditRepository) QueryEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, page domain.PaginationParams) ([]domain.AuditEntry, int, error) {
+	// Test sessions have no persisted events
+	if sessionID.IsTestSession() {
+		return []domain.AuditEntry{}, 0, nil
+	}
+
+	query := NewQuery().Count(CountExact)
+	if sessionID != "" {
+		query.Where(Eq("session_id", sessionID.String()))
+	}
+	applyPage(query, page)
+	applyEventFilter(query, filter)
+	applyOrganization(ctx, query)
+
+	data, count, err := r.client.Get(ctx, "/audit_logs", query)
+	if err != nil {
+		r.logger.Error("failed to query audit logs",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, 0, fmt.Errorf("failed to query audit logs: %w", err)
+	}
+
+	var entries []domain.AuditEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		r.logger.Error("failed to parse audit logs",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, 0, fmt.Errorf("failed to parse audit logs: %w", err)
+	}
+
+	r.logger.Debug("queried audit logs",
+		requestid.Field(ctx),
+		zap.Stringer("session_id", sessionID),
+		zap.Int("count", len(entries)),
+		zap.Int("total", count),
+	)
+
+	return entries, count, nil
+}
+
+// sessionStatsRow is the JSON object returned by the session_audit_stats function
+type sessionStatsRow struct {
+	TotalEvents   int            `json:"total_events"`
+	Contributors  int            `json:"contributors"`
+	FirstActivity *time.Time     `json:"first_activity"`
+	LastActivity  *time.Time     `json:"last_activity"`
+	ByType        map[string]int `json:"by_type"`
+	EditsPerSlide map[string]int `json:"edits_per_slide"`
+}
+
+// GetSessionStats aggregates the audit activity of a session in the database
+func (r *auditRepository) GetSessionStats(ctx context.Context, sessionID domain.SessionID) (*domain.SessionStats, error) {
+	stats := &domain.SessionStats{
+		SessionID:     sessionID.String(),
+		ByType:        map[string]int{},
+		EditsPerSlide: map[string]int{},
+	}
+
+	// Test sessions have no persisted events
+	if sessionID.IsTestSession() {
+		return stats, nil
+	}
+
+	// Aggregates run in the session_audit_stats function (migrations/013_audit_log_organizations.sql)
+	data, err := r.client.Post(ctx, "/rpc/session_audit_stats", map[string]interface{}{
+		"p_session_id":      sessionID.String(),
+		"p_organization_id": organizationArg(ctx),
+	})
+	if err != nil {
+		r.logger.Error("failed to fetch session stats",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch session stats: %w", err)
+	}
+
+	var row sessionStatsRow
+	if err := json.Unmarshal(data, &row); err != nil {
+		r.logger.Error("failed to parse session stats",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to parse session stats: %w", err)
+	}
+
+	row.apply(stats)
+
+	return stats, nil
+}
+
+// apply copies the aggregates into stats, keeping its empty maps when none were returned
+func (row sessionStatsRow) apply(stats *domain.SessionStats) {
+	stats.TotalEvents = row.TotalEvents
+	stats.Contributors = row.Contributors
+	stats.FirstActivity = row.FirstActivity
+	stats.LastActivity = row.LastActivity
+	if row.ByType != nil {
+		stats.ByType = row.ByType
+	}
+	if row.EditsPerSlide != nil {
+		stats.EditsPerSlide = row.EditsPerSlide
+	}
+}
+
+// PurgeEvents deletes up to limit events of a type recorded before the cutoff
+func (r *auditRepository) PurgeEvents(ctx context.Context, eventType string, before time.Time, limit int) ([]domain.PurgedEvents, error) {
+	// Deletion runs in the purge_audit_logs function (migrations/002_purge_audit_logs.sql)
+	data, err := r.client.Post(ctx, "/rpc/purge_audit_logs", map[string]interface{}{
+		"p_type":   eventType,
+		"p_before": before.UTC().Format(time.RFC3339Nano),
+		"p_limit":  limit,
+	})
+	if err != nil {
+		r.logger.Error("failed to purge audit logs",
+			requestid.Field(ctx),
+			zap.String("type", eventType),
+			zap.Time("before", before),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to purge audit logs: %w", err)
+	}
+
+	var purged []domain.PurgedEvents
+	if err := json.Unmarshal(data, &purged); err != nil {
+		r.logger.Error("failed to parse purge result",
+			requestid.Field(ctx),
+			zap.String("type", eventType),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to parse purge result: %w", err)
+	}
+
+	return purged, nil
+}
+
+// FindChain returns up to limit chained entries of a session with a sequence number above afterSeq
+func (r *auditRepository) FindChain(ctx context.Context, sessionID domain.SessionID, afterSeq int64, limit int) ([]domain.ChainedEntry, error) {
+	// Test sessions have no persisted events
+	if sessionID.IsTestSession() {
+		return []domain.ChainedEntry{}, nil
+	}
+
+	query := NewQuery().
+		Where(Eq("session_id", sessionID.String()), Gt("seq", strconv.FormatInt(afterSeq, 10))).
+		Order(Asc("seq")).
+		Limit(limit)
+	applyOrganization(ctx, query)
+
+	data, _, err := r.client.Get(ctx, "/audit_logs", query)
+	if err != nil {
+		r.logger.Error("failed to fetch audit log chain",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch audit log chain: %w", err)
+	}
+
+	var rows []chainedLogRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse audit log chain: %w", err)
+	}
+
+	entries := make([]domain.ChainedEntry, len(rows))
+	for i, row := range rows {
+		entry, err := row.toEntry()
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = domain.ChainedEntry{
+			AuditEntry:  entry,
+			Seq:         row.Seq,
+			ContentHash: row.ContentHash,
+			PrevHash:    row.PrevHash,
+			ChainHash:   row.ChainHash,
+			RedactedAt:  row.RedactedAt,
+		}
+	}
+
+	return entries, nil
+}
+
+// existingIDsPerRequest caps the IDs looked up per request, since they are sent in the query string
+const existingIDsPerRequest = 100
+
+// ExistingEventIDs returns those of the IDs that are already stored, in any organization
+func (r *auditRepository) ExistingEventIDs(ctx context.Context, ids []string) ([]string, error) {
+	var existing []string
+	for start := 0; start < len(ids); start += existingIDsPerRequest {
+		chunk := ids[start:min(start+existingIDsPerRequest, len(ids))]
+		data, _, err := r.client.Get(ctx, "/audit_logs", NewQuery("id").Where(In("id", chunk...)))
+		if err != nil {
+			r.logger.Error("failed to look up audit log IDs",
+				requestid.Field(ctx),
+				zap.Int("count", len(chunk)),
+				zap.Error(err),
+			)
+			return nil, fmt.Errorf("failed to look up audit log IDs: %w", err)
+		}
+
+		var rows []struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log IDs: %w", err)
+		}
+		for _, row := range rows {
+			existing = append(existing, row.ID)
+		}
+	}
+	return existing, nil
+}
+
+// FindEvent returns the event with the given ID
+func (r *auditRepository) FindEvent(ctx context.Context, eventID domain.EventID) (*domain.AuditEntry, error) {
+	query := NewQuery().Where(Eq("id", eventID.String())).Limit(1)
+	// Events of other organizations are reported as not found
+	applyOrganization(ctx, query)
+
+	data, _, err := r.client.Get(ctx, "/audit_logs", query)
+	if err != nil {
+		r.logger.Error("failed to fetch audit log",
+			requestid.Field(ctx),
+			zap.Stringer("event_id", eventID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch audit log: %w", err)
+	}
+
+	var rows []auditLogRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse audit log: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return nil, domain.ErrEventNotFound
+	}
+
+	entry, err := rows[0].toEntry()
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// FindExpiredEvents returns up to limit of the oldest events of a type recorded before the cutoff
+// that are not under a legal hold
+func (r *auditRepository) FindExpiredEvents(ctx context.Context, eventType string, before time.Time, limit int) ([]domain.AuditEntry, error) {
+	// Holds are checked by the find_expired_audit_logs function (migrations/011_audit_legal_holds.sql)
+	data, err := r.client.Post(ctx, "/rpc/find_expired_audit_logs", map[string]interface{}{
+		"p_type":   eventType,
+		"p_before": before.UTC().Format(time.RFC3339Nano),
+		"p_limit":  limit,
+	})
+	if err != nil {
+		r.logger.Error("failed to fetch expired audit logs",
+			requestid.Field(ctx),
+			zap.String("type", eventType),
+			zap.Time("before", before),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch expired audit logs: %w", err)
+	}
+
+	var rows []auditLogRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse expired audit logs: %w", err)
+	}
+
+	entries := make([]domain.AuditEntry, len(rows))
+	for i, row := range rows {
+		if entries[i], err = row.toEntry(); err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+// DeleteEvents deletes the events with the given IDs
+func (r *auditRepository) DeleteEvents(ctx context.Context, ids []string) ([]domain.PurgedEvents, error) {
+	if len(ids) == 0 {
+		return nil, nil
 	}
 
+	// Deletion runs in the delete_audit_logs function (migrations/003_delete_audit_logs.sql)
+	data, err := r.client.Post(ctx, "/rpc/delete_audit_logs", map[string]interface{}{"p_ids": ids})
+	if err != nil {
+		r.logger.Error("failed to delete audit logs",
+			requestid.Field(ctx),
+			zap.Int("count", len(ids)),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to delete audit logs: %w", err)
+	}
+
+	var deleted []domain.PurgedEvents
+	if err := json.Unmarshal(data, &deleted); err != nil {
+		return nil, fmt.Errorf("failed to parse delete result: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// EraseUserEvents anonymizes or deletes up to limit entries of a user across all sessions
+func (r *auditRepository) EraseUserEvents(ctx context.Context, userID domain.UserID, mode domain.ErasureMode, overrideHolds bool, limit int) ([]domain.PurgedEvents, error) {
+	// Erasure runs in the erase_user_audit_logs function (migrations/013_audit_log_organizations.sql)
+	data, err := r.client.Post(ctx, "/rpc/erase_user_audit_logs", map[string]interface{}{
+		"p_user_id":         userID.String(),
+		"p_mode":            string(mode),
+		"p_limit":           limit,
+		"p_override_holds":  overrideHolds,
+		"p_organization_id": organizationArg(ctx),
+	})
+	if err != nil {
+		r.logger.Error("failed to erase user audit logs",
+			requestid.Field(ctx),
+			zap.String("mode", string(mode)),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to erase user audit logs: %w", err)
+	}
+
+	var affected []domain.PurgedEvents
+	if err := json.Unmarshal(data, &affected); err != nil {
+		return nil, fmt.Errorf("failed to parse erasure result: %w", err)
+	}
+
+	return affected, nil
+}
+
+// applyPage translates pagination into PostgREST ordering, limit and keyset conditions
+func applyPage(query *Query, page domain.PaginationParams) {
+	// The id tie-breaker keeps the order stable for entries sharing a timestamp
+	query.Order(Desc("timestamp"), Desc("id")).Limit(page.Limit)
+
+	if page.Cursor == nil {
+		query.Offset(page.Offset)
+		return
+	}
+
+	// Keyset condition: strictly older than the cursor, or same timestamp with a lower id
+	timestamp := page.Cursor.Timestamp.UTC().Format(time.RFC3339Nano)
+	query.Where(Or(
+		Lt("timestamp", timestamp),
+		And(Eq("timestamp", timestamp), Lt("id", page.Cursor.ID)),
+	))
+}
+
+// applyEventFilter translates an event filter into PostgREST conditions
+func applyEventFilter(query *Query, filter domain.EventFilter) {
+	if len(filter.Types) == 1 {
+		query.Where(Eq("type", filter.Types[0]))
+	} else if len(filter.Types) > 1 {
+		query.Where(In("type", filter.Types...))
+	}
+
+	if filter.UserID != "" {
+		query.Where(Eq("user_id", filter.UserID))
+	}
+
+	if !filter.From.IsZero() {
+		query.Where(Gte("timestamp", filter.From.UTC().Format(time.RFC3339Nano)))
+	}
+	if !filter.To.IsZero() {
+		query.Where(Lte("timestamp", filter.To.UTC().Format(time.RFC3339Nano)))
+	}
+
+	// Full-text search over the string values of the JSONB details column
+	if filter.Search != "" {
+		query.Where(PlainFTS("details", filter.Search))
+	}
+
+	if filter.CorrelationID != "" {
+		query.Where(Eq("correlation_id", filter.CorrelationID))
+	}
+	if filter.SlideID != "" {
+		query.Where(Eq("slide_id", filter.SlideID))
+	}
+	if filter.ShapeID != "" {
+		query.Where(Eq("shape_id", filter.ShapeID))
+	}
+	if filter.CommentID != "" {
+		query.Where(Eq("comment_id", filter.CommentID))
+	}
+	if filter.ThreadID != "" {
+		query.Where(Eq("thread_id", filter.ThreadID))
+	}
+	// Labels live in audit_event_labels, which references the events
+	if len(filter.Labels) > 0 {
+		query.InnerJoin("audit_event_labels").Where(Ov("audit_event_labels.labels", filter.Labels...))
+	}
+}
+
+// GetSession retrieves session information
+func (r *auditRepository) GetSession(ctx context.Context, sessionID domain.SessionID) (*Session, error) {
+	// Build query
+	query := NewQuery("id", "user_id").Where(Eq("id", sessionID.String())).Limit(1)
+
 	// Make request to Supabase
-	data, _, err := r.client.Get(ctx, "/sessions", queryParams)
+	data, _, err := r.client.Get(ctx, "/sessions", query)
 	if err != nil {
 		r.logger.Error("failed to fetch session",
-			zap.String("session_id", sessionID),
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
 			zap.Error(err),
 		)
 		return nil, fmt.Errorf("failed to fetch session: %w", err)
@@ -121,7 +680,8 @@ func (r *auditRepository) GetSession(ctx context.Context, sessionID string) (*Se
 	var sessions []Session
 	if err := json.Unmarshal(data, &sessions); err != nil {
 		r.logger.Error("failed to parse session",
-			zap.String("session_id", sessionID),
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
 			zap.Error(err),
 		)
 		return nil, fmt.Errorf("failed to parse session: %w", err)
@@ -134,41 +694,1200 @@ func (r *auditRepository) GetSession(ctx context.Context, sessionID string) (*Se
 	return &sessions[0], nil
 }
 
-// ValidateShareToken checks if a share token is valid for a session
-func (r *auditRepository) ValidateShareToken(ctx context.Context, token, sessionID string) (bool, error) {
-	// Build query parameters
-	queryParams := map[string]string{
-		"token":      fmt.Sprintf("eq.%s", token),
-		"session_id": fmt.Sprintf("eq.%s", sessionID),
-		"select":     "token,session_id,expires_at",
-		"limit":      "1",
-	}
+// GetActiveShare returns the unrevoked share link issued with the token ID for a session
+func (r *auditRepository) GetActiveShare(ctx context.Context, tokenJTI string, sessionID domain.SessionID) (*domain.Share, error) {
+	// Build query
+	query := NewQuery("id", "session_id", "share_token_jti", "permissions", "expires_at").
+		Where(Eq("share_token_jti", tokenJTI), Eq("session_id", sessionID.String()), IsNull("revoked_at")).
+		Limit(1)
 
 	// Make request to Supabase
-	data, _, err := r.client.Get(ctx, "/session_shares", queryParams)
+	data, _, err := r.client.Get(ctx, "/session_shares", query)
 	if err != nil {
-		r.logger.Error("failed to validate share token",
-			zap.String("session_id", sessionID),
+		r.logger.Error("failed to look up share link",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
 			zap.Error(err),
 		)
-		return false, fmt.Errorf("failed to validate share token: %w", err)
+		return nil, fmt.Errorf("failed to look up share link: %w", err)
 	}
 
 	// Parse response
-	var shares []ShareToken
+	var shares []shareRow
 	if err := json.Unmarshal(data, &shares); err != nil {
-		r.logger.Error("failed to parse share token",
-			zap.String("session_id", sessionID),
+		r.logger.Error("failed to parse share link",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
 			zap.Error(err),
 		)
-		return false, fmt.Errorf("failed to parse share token: %w", err)
+		return nil, fmt.Errorf("failed to parse share link: %w", err)
 	}
 
 	if len(shares) == 0 {
-		return false, nil
+		return nil, domain.ErrShareNotFound
+	}
+
+	return shares[0].toShare(), nil
+}
+
+// toShare converts a database row into a domain share
+func (row shareRow) toShare() *domain.Share {
+	permissions := make([]domain.SharePermission, len(row.Permissions))
+	for i, p := range row.Permissions {
+		permissions[i] = domain.SharePermission(p)
+	}
+
+	return &domain.Share{
+		ID:          row.ID,
+		SessionID:   row.SessionID,
+		TokenJTI:    row.ShareTokenJTI,
+		Permissions: permissions,
+		ExpiresAt:   row.ExpiresAt,
+	}
+}
+
+// CreateEvents inserts audit entries in a single bulk request
+func (r *auditRepository) CreateEvents(ctx context.Context, entries []domain.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := checkOrganization(ctx, entries); err != nil {
+		return err
+	}
+
+	rows := make([]auditLogRow, len(entries))
+	for i, entry := range entries {
+		row, err := newAuditLogRow(entry)
+		if err != nil {
+			return fmt.Errorf("failed to prepare audit log %s: %w", entry.ID, err)
+		}
+		rows[i] = row
+	}
+
+	// PostgREST inserts all rows of a JSON array in one statement
+	var err error
+	if r.outbox {
+		err = r.insertWithOutbox(ctx, entries, rows)
+	} else {
+		_, err = r.client.Post(ctx, "/audit_logs", rows)
+	}
+	if err != nil {
+		r.logger.Error("failed to insert audit logs",
+			requestid.Field(ctx),
+			zap.Int("count", len(entries)),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to insert audit logs: %w", err)
+	}
+
+	r.logger.Debug("inserted audit logs",
+		requestid.Field(ctx),
+		zap.Int("count", len(entries)),
+	)
+
+	return nil
+}
+
+// insertWithOutbox stores the rows and their outbox messages in one transaction
+// through the insert_audit_logs function (migrations/006_audit_outbox.sql)
+func (r *auditRepository) insertWithOutbox(ctx context.Context, entries []domain.AuditEntry, rows []auditLogRow) error {
+	messages, err := newOutboxRows(entries)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.Post(ctx, "/rpc/insert_audit_logs", map[string]interface{}{
+		"p_logs":   rows,
+		"p_outbox": messages,
+	})
+	return err
+}
+
+// ClaimOutbox leases up to limit due messages for the given duration and counts the attempt
+func (r *auditRepository) ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error) {
+	data, err := r.client.Post(ctx, "/rpc/claim_audit_outbox", map[string]interface{}{
+		"p_limit":         limit,
+		"p_lease_seconds": int(lease.Seconds()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+
+	return parseClaimedOutbox(data)
+}
+
+// CompleteOutbox removes delivered messages
+func (r *auditRepository) CompleteOutbox(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if _, err := r.client.Post(ctx, "/rpc/complete_audit_outbox", map[string]interface{}{"p_ids": ids}); err != nil {
+		return fmt.Errorf("failed to complete outbox messages: %w", err)
+	}
+	return nil
+}
+
+// FailOutbox releases a message for a retry at retryAt, or for good when dead is set
+func (r *auditRepository) FailOutbox(ctx context.Context, id int64, retryAt time.Time, lastError string, dead bool) error {
+	_, err := r.client.Post(ctx, "/rpc/fail_audit_outbox", map[string]interface{}{
+		"p_id":       id,
+		"p_retry_at": retryAt.UTC().Format(time.RFC3339Nano),
+		"p_error":    lastError,
+		"p_dead":     dead,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release outbox message %d: %w", id, err)
+	}
+	return nil
+}
+
+// ReplayOutbox makes dead messages created at or after since due again
+// (replay_audit_outbox in migrations/014_audit_outbox_replay.sql)
+func (r *auditRepository) ReplayOutbox(ctx context.Context, since time.Time) (int, error) {
+	var sinceArg interface{}
+	if !since.IsZero() {
+		sinceArg = since.UTC().Format(time.RFC3339Nano)
+	}
+
+	data, err := r.client.Post(ctx, "/rpc/replay_audit_outbox", map[string]interface{}{
+		"p_since": sinceArg,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to replay outbox messages: %w", err)
+	}
+
+	var replayed int
+	if err := json.Unmarshal(data, &replayed); err != nil {
+		return 0, fmt.Errorf("failed to parse replayed outbox count: %w", err)
+	}
+	return replayed, nil
+}
+
+// EnqueueOutbox queues stored events for forwarding again
+// (enqueue_audit_outbox in migrations/023_audit_outbox_enqueue.sql)
+func (r *auditRepository) EnqueueOutbox(ctx context.Context, entries []domain.AuditEntry) (int, error) {
+	if len(entries) == 0 {
+		return 0, nil
+	}
+	messages, err := newOutboxRows(entries)
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := r.client.Post(ctx, "/rpc/enqueue_audit_outbox", map[string]interface{}{
+		"p_outbox": messages,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue outbox messages: %w", err)
+	}
+
+	var queued int
+	if err := json.Unmarshal(data, &queued); err != nil {
+		return 0, fmt.Errorf("failed to parse queued outbox count: %w", err)
+	}
+	return queued, nil
+}
+
+// ListDeadOutbox returns dead messages newest first
+// (list_dead_audit_outbox in migrations/024_audit_outbox_dead_letters.sql)
+func (r *auditRepository) ListDeadOutbox(ctx context.Context, limit int, beforeID int64) ([]domain.DeadLetter, error) {
+	var beforeArg interface{}
+	if beforeID > 0 {
+		beforeArg = beforeID
+	}
+
+	data, err := r.client.Post(ctx, "/rpc/list_dead_audit_outbox", map[string]interface{}{
+		"p_limit":           limit,
+		"p_before_id":       beforeArg,
+		"p_organization_id": organizationArg(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead outbox messages: %w", err)
 	}
 
-	// TODO: Check expiration if expires_at is set
-	// For now, assume valid if found
-	return true, nil
+	return parseDeadOutbox(data)
+}
+
+// RetryDeadOutbox makes one dead message due again
+// (retry_dead_audit_outbox in migrations/024_audit_outbox_dead_letters.sql)
+func (r *auditRepository) RetryDeadOutbox(ctx context.Context, id int64) error {
+	data, err := r.client.Post(ctx, "/rpc/retry_dead_audit_outbox", map[string]interface{}{
+		"p_id":              id,
+		"p_organization_id": organizationArg(ctx),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to retry outbox message %d: %w", id, err)
+	}
+
+	var retried bool
+	if err := json.Unmarshal(data, &retried); err != nil {
+		return fmt.Errorf("failed to parse outbox retry result: %w", err)
+	}
+	if !retried {
+		return domain.ErrDeadLetterNotFound
+	}
+	return nil
+}
+
+// ListEventTypes returns every custom event type ordered by name
+func (r *auditRepository) ListEventTypes(ctx context.Context) ([]domain.EventType, error) {
+	query := NewQuery("name", "display_name", "severity", "schema", "created_by", "created_at").
+		Order(Asc("name"))
+
+	data, _, err := r.client.Get(ctx, "/audit_event_types", query)
+	if err != nil {
+		r.logger.Error("failed to fetch event types",
+			requestid.Field(ctx),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch event types: %w", err)
+	}
+
+	var rows []eventTypeRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse event types: %w", err)
+	}
+
+	types := make([]domain.EventType, len(rows))
+	for i, row := range rows {
+		types[i] = row.toEventType()
+	}
+	return types, nil
+}
+
+// CreateEventType stores a custom event type, or returns domain.ErrEventTypeExists
+func (r *auditRepository) CreateEventType(ctx context.Context, eventType domain.EventType) error {
+	_, err := r.client.Post(ctx, "/audit_event_types", newEventTypeRow(eventType))
+	if err != nil {
+		// PostgREST answers a primary key violation with 409 Conflict
+		var supErr *SupabaseError
+		if errors.As(err, &supErr) && supErr.StatusCode == http.StatusConflict {
+			return fmt.Errorf("%w: %s", domain.ErrEventTypeExists, eventType.Name)
+		}
+		r.logger.Error("failed to insert event type",
+			requestid.Field(ctx),
+			zap.String("name", string(eventType.Name)),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to insert event type: %w", err)
+	}
+	return nil
+}
+
+// legalHoldColumns are the audit_legal_holds columns selected by the REST API
+const legalHoldColumns = "id,scope,target,reason,placed_by,placed_at,released_by,released_at,organization_id"
+
+// CreateLegalHold stores a new active hold
+func (r *auditRepository) CreateLegalHold(ctx context.Context, hold domain.LegalHold) error {
+	if _, err := r.client.Post(ctx, "/audit_legal_holds", newLegalHoldRow(hold)); err != nil {
+		r.logger.Error("failed to insert legal hold",
+			requestid.Field(ctx),
+			zap.String("scope", string(hold.Scope)),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to insert legal hold: %w", err)
+	}
+	return nil
+}
+
+// ListLegalHolds returns the holds newest first, only the active ones when activeOnly is set
+func (r *auditRepository) ListLegalHolds(ctx context.Context, activeOnly bool) ([]domain.LegalHold, error) {
+	query := NewQuery(legalHoldColumns).Order(Desc("placed_at"), Asc("id"))
+	if activeOnly {
+		query.Where(IsNull("released_at"))
+	}
+	applyOrganization(ctx, query)
+
+	data, _, err := r.client.Get(ctx, "/audit_legal_holds", query)
+	if err != nil {
+		r.logger.Error("failed to fetch legal holds",
+			requestid.Field(ctx),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch legal holds: %w", err)
+	}
+
+	return parseLegalHolds(data)
+}
+
+// ReleaseLegalHold marks an active hold released and returns it
+func (r *auditRepository) ReleaseLegalHold(ctx context.Context, id, releasedBy string, at time.Time) (*domain.LegalHold, error) {
+	// The REST client cannot PATCH; the release_audit_legal_hold function (migrations/013_audit_log_organizations.sql) updates the row
+	data, err := r.client.Post(ctx, "/rpc/release_audit_legal_hold", map[string]interface{}{
+		"p_id":              id,
+		"p_released_by":     releasedBy,
+		"p_released_at":     at.UTC().Format(time.RFC3339Nano),
+		"p_organization_id": organizationArg(ctx),
+	})
+	if err != nil {
+		r.logger.Error("failed to release legal hold",
+			requestid.Field(ctx),
+			zap.String("hold_id", id),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to release legal hold: %w", err)
+	}
+
+	released, err := parseLegalHolds(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(released) > 0 {
+		return &released[0], nil
+	}
+
+	// Nothing was updated: the hold is unknown or was released before
+	query := NewQuery("id").Where(Eq("id", id))
+	applyOrganization(ctx, query)
+	data, _, err = r.client.Get(ctx, "/audit_legal_holds", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch legal hold: %w", err)
+	}
+	var existing []legalHoldRow
+	if err := json.Unmarshal(data, &existing); err != nil {
+		return nil, fmt.Errorf("failed to parse legal hold: %w", err)
+	}
+	if len(existing) == 0 {
+		return nil, domain.ErrLegalHoldNotFound
+	}
+	return nil, domain.ErrLegalHoldReleased
+}
+
+// parseLegalHolds decodes audit_legal_holds rows returned by the REST API
+func parseLegalHolds(data []byte) ([]domain.LegalHold, error) {
+	var rows []legalHoldRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse legal holds: %w", err)
+	}
+
+	holds := make([]domain.LegalHold, len(rows))
+	for i, row := range rows {
+		holds[i] = row.toLegalHold()
+	}
+	return holds, nil
+}
+
+// revocationColumns are the audit_token_revocations columns selected by the REST API
+const revocationColumns = "id,kind,target,reason,revoked_by,revoked_at,expires_at,lifted_by,lifted_at,organization_id"
+
+// CreateRevocation stores a new revocation
+func (r *auditRepository) CreateRevocation(ctx context.Context, revocation domain.Revocation) error {
+	if _, err := r.client.Post(ctx, "/audit_token_revocations", newRevocationRow(revocation)); err != nil {
+		r.logger.Error("failed to insert revocation",
+			requestid.Field(ctx),
+			zap.String("kind", string(revocation.Kind)),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to insert revocation: %w", err)
+	}
+	return nil
+}
+
+// ListRevocations returns the revocations newest first, only the ones not lifted when notLiftedOnly is set
+func (r *auditRepository) ListRevocations(ctx context.Context, notLiftedOnly bool) ([]domain.Revocation, error) {
+	query := NewQuery(revocationColumns).Order(Desc("revoked_at"), Asc("id"))
+	if notLiftedOnly {
+		query.Where(IsNull("lifted_at"))
+	}
+	applyOrganization(ctx, query)
+
+	data, _, err := r.client.Get(ctx, "/audit_token_revocations", query)
+	if err != nil {
+		r.logger.Error("failed to fetch revocations",
+			requestid.Field(ctx),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch revocations: %w", err)
+	}
+
+	return parseRevocations(data)
+}
+
+// LiftRevocation marks a revocation lifted and returns it
+func (r *auditRepository) LiftRevocation(ctx context.Context, id, liftedBy string, at time.Time) (*domain.Revocation, error) {
+	// The REST client cannot PATCH; the lift_audit_token_revocation function (migrations/015_audit_token_revocations.sql) updates the row
+	data, err := r.client.Post(ctx, "/rpc/lift_audit_token_revocation", map[string]interface{}{
+		"p_id":              id,
+		"p_lifted_by":       liftedBy,
+		"p_lifted_at":       at.UTC().Format(time.RFC3339Nano),
+		"p_organization_id": organizationArg(ctx),
+	})
+	if err != nil {
+		r.logger.Error("failed to lift revocation",
+			requestid.Field(ctx),
+			zap.String("revocation_id", id),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to lift revocation: %w", err)
+	}
+
+	lifted, err := parseRevocations(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(lifted) > 0 {
+		return &lifted[0], nil
+	}
+
+	// Nothing was updated: the revocation is unknown or was lifted before
+	query := NewQuery("id").Where(Eq("id", id))
+	applyOrganization(ctx, query)
+	data, _, err = r.client.Get(ctx, "/audit_token_revocations", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch revocation: %w", err)
+	}
+	var existing []revocationRow
+	if err := json.Unmarshal(data, &existing); err != nil {
+		return nil, fmt.Errorf("failed to parse revocation: %w", err)
+	}
+	if len(existing) == 0 {
+		return nil, domain.ErrRevocationNotFound
+	}
+	return nil, domain.ErrRevocationLifted
+}
+
+// parseRevocations decodes audit_token_revocations rows returned by the REST API
+func parseRevocations(data []byte) ([]domain.Revocation, error) {
+	var rows []revocationRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse revocations: %w", err)
+	}
+
+	revocations := make([]domain.Revocation, len(rows))
+	for i, row := range rows {
+		revocations[i] = row.toRevocation()
+	}
+	return revocations, nil
+}
+
+// watchColumns are the audit_session_watches columns selected by the REST API
+const watchColumns = "session_id,user_id,event_types,created_at,organization_id"
+
+// PutWatch creates or replaces the watch of a user on a session
+func (r *auditRepository) PutWatch(ctx context.Context, watch domain.Watch) (*domain.Watch, error) {
+	// The REST client cannot upsert; the put_audit_session_watch function (migrations/021_audit_session_watches.sql) does
+	row := newWatchRow(watch)
+	data, err := r.client.Post(ctx, "/rpc/put_audit_session_watch", map[string]interface{}{
+		"p_session_id":      row.SessionID,
+		"p_user_id":         row.UserID,
+		"p_event_types":     row.EventTypes,
+		"p_created_at":      row.CreatedAt.Format(time.RFC3339Nano),
+		"p_organization_id": nullIfEmpty(row.OrganizationID),
+	})
+	if err != nil {
+		r.logger.Error("failed to store watch",
+			requestid.Field(ctx),
+			zap.String("session_id", watch.SessionID),
+			zap.String("user_id", watch.UserID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to store watch: %w", err)
+	}
+
+	watches, err := parseWatches(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(watches) == 0 {
+		return nil, fmt.Errorf("failed to store watch: no row returned")
+	}
+	return &watches[0], nil
+}
+
+// GetWatch returns the watch of a user on a session
+func (r *auditRepository) GetWatch(ctx context.Context, sessionID, userID string) (*domain.Watch, error) {
+	data, _, err := r.client.Get(ctx, "/audit_session_watches", NewQuery(watchColumns).
+		Where(Eq("session_id", sessionID), Eq("user_id", userID)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch watch: %w", err)
+	}
+
+	watches, err := parseWatches(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(watches) == 0 {
+		return nil, domain.ErrWatchNotFound
+	}
+	return &watches[0], nil
+}
+
+// DeleteWatch removes the watch of a user on a session
+func (r *auditRepository) DeleteWatch(ctx context.Context, sessionID, userID string) error {
+	// The REST client cannot DELETE; the delete_audit_session_watch function (migrations/021_audit_session_watches.sql) does
+	data, err := r.client.Post(ctx, "/rpc/delete_audit_session_watch", map[string]interface{}{
+		"p_session_id": sessionID,
+		"p_user_id":    userID,
+	})
+	if err != nil {
+		r.logger.Error("failed to delete watch",
+			requestid.Field(ctx),
+			zap.String("session_id", sessionID),
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to delete watch: %w", err)
+	}
+
+	deleted, err := parseWatches(data)
+	if err != nil {
+		return err
+	}
+	if len(deleted) == 0 {
+		return domain.ErrWatchNotFound
+	}
+	return nil
+}
+
+// ListUserWatches returns the watches of a user, newest first
+func (r *auditRepository) ListUserWatches(ctx context.Context, userID string) ([]domain.Watch, error) {
+	data, _, err := r.client.Get(ctx, "/audit_session_watches", NewQuery(watchColumns).
+		Where(Eq("user_id", userID)).
+		Order(Desc("created_at"), Asc("session_id")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch watches: %w", err)
+	}
+	return parseWatches(data)
+}
+
+// ListSessionWatches returns the watches on a session
+func (r *auditRepository) ListSessionWatches(ctx context.Context, sessionID string) ([]domain.Watch, error) {
+	data, _, err := r.client.Get(ctx, "/audit_session_watches", NewQuery(watchColumns).
+		Where(Eq("session_id", sessionID)).
+		Order(Asc("user_id")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch watches: %w", err)
+	}
+	return parseWatches(data)
+}
+
+// parseWatches decodes audit_session_watches rows returned by the REST API
+func parseWatches(data []byte) ([]domain.Watch, error) {
+	var rows []watchRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse watches: %w", err)
+	}
+
+	watches := make([]domain.Watch, len(rows))
+	for i, row := range rows {
+		watches[i] = row.toWatch()
+	}
+	return watches, nil
+}
+
+// reportColumns are the audit_reports columns selected by the REST API
+const reportColumns = "id,name,kind,session_id,schedule,format,delivery,target,created_by,created_at,next_run_at,last_run_at,organization_id"
+
+// reportRunColumns are the audit_report_runs columns selected by the REST API
+const reportRunColumns = "id,report_id,triggered_by,status,period_from,period_to,started_at,finished_at,row_count,location,error,organization_id"
+
+// CreateReport stores a new report
+func (r *auditRepository) CreateReport(ctx context.Context, report domain.Report) error {
+	if _, err := r.client.Post(ctx, "/audit_reports", newReportRow(report)); err != nil {
+		r.logger.Error("failed to insert report",
+			requestid.Field(ctx),
+			zap.String("report_id", report.ID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to insert report: %w", err)
+	}
+	return nil
+}
+
+// GetReport returns a report of the organization ctx is scoped to
+func (r *auditRepository) GetReport(ctx context.Context, id string) (*domain.Report, error) {
+	query := NewQuery(reportColumns).Where(Eq("id", id))
+	applyOrganization(ctx, query)
+
+	data, _, err := r.client.Get(ctx, "/audit_reports", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch report: %w", err)
+	}
+
+	reports, err := parseReports(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, domain.ErrReportNotFound
+	}
+	return &reports[0], nil
+}
+
+// ListReports returns the reports of the organization ctx is scoped to by name
+func (r *auditRepository) ListReports(ctx context.Context) ([]domain.Report, error) {
+	query := NewQuery(reportColumns).Order(Asc("name"), Asc("id"))
+	applyOrganization(ctx, query)
+
+	data, _, err := r.client.Get(ctx, "/audit_reports", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reports: %w", err)
+	}
+	return parseReports(data)
+}
+
+// DeleteReport removes a report with its runs
+func (r *auditRepository) DeleteReport(ctx context.Context, id string) error {
+	// The REST client cannot DELETE; the delete_audit_report function (migrations/022_audit_reports.sql) does
+	data, err := r.client.Post(ctx, "/rpc/delete_audit_report", map[string]interface{}{
+		"p_id":              id,
+		"p_organization_id": organizationArg(ctx),
+	})
+	if err != nil {
+		r.logger.Error("failed to delete report",
+			requestid.Field(ctx),
+			zap.String("report_id", id),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to delete report: %w", err)
+	}
+
+	deleted, err := parseReports(data)
+	if err != nil {
+		return err
+	}
+	if len(deleted) == 0 {
+		return domain.ErrReportNotFound
+	}
+	return nil
+}
+
+// ListDueReports returns the reports of every organization due at the given time
+func (r *auditRepository) ListDueReports(ctx context.Context, at time.Time, limit int) ([]domain.Report, error) {
+	data, _, err := r.client.Get(ctx, "/audit_reports", NewQuery(reportColumns).
+		Where(Lte("next_run_at", at.UTC().Format(time.RFC3339Nano))).
+		Order(Asc("next_run_at"), Asc("id")).
+		Limit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch due reports: %w", err)
+	}
+	return parseReports(data)
+}
+
+// AdvanceReport claims the run of a report due at scheduledAt
+func (r *auditRepository) AdvanceReport(ctx context.Context, id string, scheduledAt, nextRunAt time.Time) (bool, error) {
+	// The REST client cannot PATCH; the advance_audit_report function (migrations/022_audit_reports.sql) updates the row
+	data, err := r.client.Post(ctx, "/rpc/advance_audit_report", map[string]interface{}{
+		"p_id":           id,
+		"p_scheduled_at": scheduledAt.UTC().Format(time.RFC3339Nano),
+		"p_next_run_at":  nextRunAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to advance report: %w", err)
+	}
+
+	advanced, err := parseReports(data)
+	if err != nil {
+		return false, err
+	}
+	return len(advanced) > 0, nil
+}
+
+// CreateReportRun records a run of a report
+func (r *auditRepository) CreateReportRun(ctx context.Context, run domain.ReportRun) error {
+	if _, err := r.client.Post(ctx, "/audit_report_runs", newReportRunRow(run)); err != nil {
+		r.logger.Error("failed to insert report run",
+			requestid.Field(ctx),
+			zap.String("report_id", run.ReportID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to insert report run: %w", err)
+	}
+	return nil
+}
+
+// ListReportRuns returns the latest runs of a report, newest first
+func (r *auditRepository) ListReportRuns(ctx context.Context, reportID string, limit int) ([]domain.ReportRun, error) {
+	data, _, err := r.client.Get(ctx, "/audit_report_runs", NewQuery(reportRunColumns).
+		Where(Eq("report_id", reportID)).
+		Order(Desc("started_at"), Asc("id")).
+		Limit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch report runs: %w", err)
+	}
+
+	var rows []reportRunRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse report runs: %w", err)
+	}
+	runs := make([]domain.ReportRun, len(rows))
+	for i, row := range rows {
+		runs[i] = row.toReportRun()
+	}
+	return runs, nil
+}
+
+// parseReports decodes audit_reports rows returned by the REST API
+func parseReports(data []byte) ([]domain.Report, error) {
+	var rows []reportRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse reports: %w", err)
+	}
+
+	reports := make([]domain.Report, len(rows))
+	for i, row := range rows {
+		reports[i] = row.toReport()
+	}
+	return reports, nil
+}
+
+// savedQueryColumns are the audit_saved_queries columns selected by the REST API
+const savedQueryColumns = "id,name,description,scope,filter,created_by,created_at,updated_at,organization_id"
+
+// CreateSavedQuery stores a new saved query
+func (r *auditRepository) CreateSavedQuery(ctx context.Context, query domain.SavedQuery) error {
+	if _, err := r.client.Post(ctx, "/audit_saved_queries", newSavedQueryRow(query)); err != nil {
+		r.logger.Error("failed to insert saved query",
+			requestid.Field(ctx),
+			zap.String("query_id", query.ID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to insert saved query: %w", err)
+	}
+	return nil
+}
+
+// GetSavedQuery returns a saved query of the organization ctx is scoped to
+func (r *auditRepository) GetSavedQuery(ctx context.Context, id string) (*domain.SavedQuery, error) {
+	query := NewQuery(savedQueryColumns).Where(Eq("id", id))
+	applyOrganization(ctx, query)
+
+	data, _, err := r.client.Get(ctx, "/audit_saved_queries", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch saved query: %w", err)
+	}
+
+	queries, err := parseSavedQueries(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(queries) == 0 {
+		return nil, domain.ErrSavedQueryNotFound
+	}
+	return &queries[0], nil
+}
+
+// ListSavedQueries returns the saved queries of the organization ctx is scoped to that a user can see, by name
+func (r *auditRepository) ListSavedQueries(ctx context.Context, userID string) ([]domain.SavedQuery, error) {
+	query := NewQuery(savedQueryColumns).
+		Where(Or(Eq("created_by", userID), Eq("scope", string(domain.SavedQueryOrganization)))).
+		Order(Asc("name"), Asc("id"))
+	applyOrganization(ctx, query)
+
+	data, _, err := r.client.Get(ctx, "/audit_saved_queries", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch saved queries: %w", err)
+	}
+	return parseSavedQueries(data)
+}
+
+// UpdateSavedQuery replaces the name, description, scope and filter of a saved query
+func (r *auditRepository) UpdateSavedQuery(ctx context.Context, query domain.SavedQuery) error {
+	row := newSavedQueryRow(query)
+	// The REST client cannot PATCH; the update_audit_saved_query function (migrations/026_audit_saved_queries.sql) updates the row
+	data, err := r.client.Post(ctx, "/rpc/update_audit_saved_query", map[string]interface{}{
+		"p_id":              row.ID,
+		"p_name":            row.Name,
+		"p_description":     row.Description,
+		"p_scope":           row.Scope,
+		"p_filter":          row.Filter,
+		"p_updated_at":      row.UpdatedAt.Format(time.RFC3339Nano),
+		"p_organization_id": organizationArg(ctx),
+	})
+	if err != nil {
+		r.logger.Error("failed to update saved query",
+			requestid.Field(ctx),
+			zap.String("query_id", query.ID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to update saved query: %w", err)
+	}
+
+	updated, err := parseSavedQueries(data)
+	if err != nil {
+		return err
+	}
+	if len(updated) == 0 {
+		return domain.ErrSavedQueryNotFound
+	}
+	return nil
+}
+
+// DeleteSavedQuery removes a saved query
+func (r *auditRepository) DeleteSavedQuery(ctx context.Context, id string) error {
+	// The REST client cannot DELETE; the delete_audit_saved_query function (migrations/026_audit_saved_queries.sql) does
+	data, err := r.client.Post(ctx, "/rpc/delete_audit_saved_query", map[string]interface{}{
+		"p_id":              id,
+		"p_organization_id": organizationArg(ctx),
+	})
+	if err != nil {
+		r.logger.Error("failed to delete saved query",
+			requestid.Field(ctx),
+			zap.String("query_id", id),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to delete saved query: %w", err)
+	}
+
+	deleted, err := parseSavedQueries(data)
+	if err != nil {
+		return err
+	}
+	if len(deleted) == 0 {
+		return domain.ErrSavedQueryNotFound
+	}
+	return nil
+}
+
+// parseSavedQueries decodes audit_saved_queries rows returned by the REST API
+func parseSavedQueries(data []byte) ([]domain.SavedQuery, error) {
+	var rows []savedQueryRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse saved queries: %w", err)
+	}
+
+	queries := make([]domain.SavedQuery, len(rows))
+	for i, row := range rows {
+		queries[i] = row.toSavedQuery()
+	}
+	return queries, nil
+}
+
+// eventLabelsColumns are the audit_event_labels columns selected by the REST API
+const eventLabelsColumns = "event_id,session_id,labels,version,updated_by,updated_at,organization_id"
+
+// GetEventLabels returns the label set of an event of the organization ctx is scoped to
+func (r *auditRepository) GetEventLabels(ctx context.Context, eventID string) (*domain.EventLabels, error) {
+	query := NewQuery(eventLabelsColumns).Where(Eq("event_id", eventID))
+	applyOrganization(ctx, query)
+
+	data, _, err := r.client.Get(ctx, "/audit_event_labels", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch event labels: %w", err)
+	}
+
+	labels, err := parseEventLabels(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	return &labels[0], nil
+}
+
+// SaveEventLabels stores the label set of an event when the stored set is at the version before
+func (r *auditRepository) SaveEventLabels(ctx context.Context, labels domain.EventLabels) error {
+	row := newEventLabelsRow(labels)
+	// The REST client cannot PATCH; the put_audit_event_labels function (migrations/027_audit_event_labels.sql) writes the row
+	data, err := r.client.Post(ctx, "/rpc/put_audit_event_labels", map[string]interface{}{
+		"p_event_id":        row.EventID,
+		"p_session_id":      row.SessionID,
+		"p_labels":          row.Labels,
+		"p_version":         row.Version,
+		"p_updated_by":      row.UpdatedBy,
+		"p_updated_at":      row.UpdatedAt.Format(time.RFC3339Nano),
+		"p_organization_id": nullIfEmpty(row.OrganizationID),
+	})
+	if err != nil {
+		r.logger.Error("failed to save event labels",
+			requestid.Field(ctx),
+			zap.String("event_id", row.EventID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to save event labels: %w", err)
+	}
+
+	saved, err := parseEventLabels(data)
+	if err != nil {
+		return err
+	}
+	if len(saved) == 0 {
+		return fmt.Errorf("%w: labels of event %s changed before version %d was stored", domain.ErrVersionConflict, row.EventID, row.Version)
+	}
+	return nil
+}
+
+// parseEventLabels decodes audit_event_labels rows returned by the REST API
+func parseEventLabels(data []byte) ([]domain.EventLabels, error) {
+	var rows []eventLabelsRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse event labels: %w", err)
+	}
+
+	labels := make([]domain.EventLabels, len(rows))
+	for i, row := range rows {
+		labels[i] = row.toEventLabels()
+	}
+	return labels, nil
+}
+
+// annotationColumns are the audit_event_annotations columns selected by the REST API
+const annotationColumns = "id,event_id,session_id,author_id,body,version,created_at,updated_at,organization_id"
+
+// CreateAnnotation stores a new annotation
+func (r *auditRepository) CreateAnnotation(ctx context.Context, annotation domain.EventAnnotation) error {
+	if _, err := r.client.Post(ctx, "/audit_event_annotations", newAnnotationRow(annotation)); err != nil {
+		r.logger.Error("failed to insert annotation",
+			requestid.Field(ctx),
+			zap.String("annotation_id", annotation.ID),
+			zap.String("event_id", annotation.EventID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to insert annotation: %w", err)
+	}
+	return nil
+}
+
+// GetAnnotation returns an annotation of the organization ctx is scoped to
+func (r *auditRepository) GetAnnotation(ctx context.Context, id string) (*domain.EventAnnotation, error) {
+	query := NewQuery(annotationColumns).Where(Eq("id", id))
+	applyOrganization(ctx, query)
+
+	data, _, err := r.client.Get(ctx, "/audit_event_annotations", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch annotation: %w", err)
+	}
+
+	annotations, err := parseAnnotations(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(annotations) == 0 {
+		return nil, domain.ErrAnnotationNotFound
+	}
+	return &annotations[0], nil
+}
+
+// ListAnnotations returns the annotations of an event of the organization ctx is scoped to, oldest first
+func (r *auditRepository) ListAnnotations(ctx context.Context, eventID string) ([]domain.EventAnnotation, error) {
+	query := NewQuery(annotationColumns).
+		Where(Eq("event_id", eventID)).
+		Order(Asc("created_at"), Asc("id"))
+	applyOrganization(ctx, query)
+
+	data, _, err := r.client.Get(ctx, "/audit_event_annotations", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch annotations: %w", err)
+	}
+	return parseAnnotations(data)
+}
+
+// UpdateAnnotation stores the body of an annotation when the stored annotation is at the version before
+func (r *auditRepository) UpdateAnnotation(ctx context.Context, annotation domain.EventAnnotation) error {
+	row := newAnnotationRow(annotation)
+	// The REST client cannot PATCH; the update_audit_event_annotation function (migrations/028_audit_event_annotations.sql) updates the row
+	data, err := r.client.Post(ctx, "/rpc/update_audit_event_annotation", map[string]interface{}{
+		"p_id":              row.ID,
+		"p_body":            row.Body,
+		"p_version":         row.Version,
+		"p_updated_at":      row.UpdatedAt.Format(time.RFC3339Nano),
+		"p_organization_id": organizationArg(ctx),
+	})
+	if err != nil {
+		r.logger.Error("failed to update annotation",
+			requestid.Field(ctx),
+			zap.String("annotation_id", row.ID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to update annotation: %w", err)
+	}
+
+	updated, err := parseAnnotations(data)
+	if err != nil {
+		return err
+	}
+	if len(updated) == 0 {
+		return fmt.Errorf("%w: annotation %s changed before version %d was stored", domain.ErrVersionConflict, row.ID, row.Version)
+	}
+	return nil
+}
+
+// DeleteAnnotation removes an annotation at version
+func (r *auditRepository) DeleteAnnotation(ctx context.Context, id string, version int64) error {
+	// The REST client cannot DELETE; the delete_audit_event_annotation function (migrations/028_audit_event_annotations.sql) does
+	data, err := r.client.Post(ctx, "/rpc/delete_audit_event_annotation", map[string]interface{}{
+		"p_id":              id,
+		"p_version":         version,
+		"p_organization_id": organizationArg(ctx),
+	})
+	if err != nil {
+		r.logger.Error("failed to delete annotation",
+			requestid.Field(ctx),
+			zap.String("annotation_id", id),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to delete annotation: %w", err)
+	}
+
+	deleted, err := parseAnnotations(data)
+	if err != nil {
+		return err
+	}
+	if len(deleted) == 0 {
+		return fmt.Errorf("%w: annotation %s changed after version %d", domain.ErrVersionConflict, id, version)
+	}
+	return nil
+}
+
+// parseAnnotations decodes audit_event_annotations rows returned by the REST API
+func parseAnnotations(data []byte) ([]domain.EventAnnotation, error) {
+	var rows []annotationRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse annotations: %w", err)
+	}
+
+	annotations := make([]domain.EventAnnotation, len(rows))
+	for i, row := range rows {
+		annotations[i] = row.toAnnotation()
+	}
+	return annotations, nil
+}
+
+// activityColumns are the audit_activity_rollups columns selected by the REST API
+const activityColumns = "bucket_start,user_id,event_count,by_type"
+
+// GetSessionActivity returns the rolled-up activity of a session
+func (r *auditRepository) GetSessionActivity(ctx context.Context, sessionID domain.SessionID, query domain.ActivityQuery) ([]domain.ActivityBucket, error) {
+	// Test sessions have no persisted events
+	if sessionID.IsTestSession() {
+		return []domain.ActivityBucket{}, nil
+	}
+
+	rollups := NewQuery(activityColumns).
+		Where(
+			Eq("session_id", sessionID.String()),
+			Eq("granularity", string(query.Granularity)),
+			Gte("bucket_start", query.From.UTC().Format(time.RFC3339Nano)),
+			Lt("bucket_start", query.To.UTC().Format(time.RFC3339Nano)),
+		).
+		Order(Asc("bucket_start"), Asc("user_id"))
+	if query.UserID != "" {
+		rollups.Where(Eq("user_id", query.UserID))
+	}
+	applyOrganization(ctx, rollups)
+
+	data, _, err := r.client.Get(ctx, "/audit_activity_rollups", rollups)
+	if err != nil {
+		r.logger.Error("failed to fetch session activity",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch session activity: %w", err)
+	}
+
+	var rows []activityRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse session activity: %w", err)
+	}
+	buckets := make([]domain.ActivityBucket, len(rows))
+	for i, row := range rows {
+		buckets[i] = row.toActivityBucket()
+	}
+	return buckets, nil
+}
+
+// GetSlideHeatmap returns the hourly event counts per slide of a session
+func (r *auditRepository) GetSlideHeatmap(ctx context.Context, sessionID domain.SessionID, query domain.HeatmapQuery) ([]domain.HeatmapCell, error) {
+	// Test sessions have no persisted events
+	if sessionID.IsTestSession() {
+		return []domain.HeatmapCell{}, nil
+	}
+
+	// Aggregates run in the session_slide_heatmap function (migrations/019_audit_slide_heatmap.sql)
+	data, err := r.client.Post(ctx, "/rpc/session_slide_heatmap", map[string]interface{}{
+		"p_session_id":      sessionID.String(),
+		"p_from":            query.From.UTC().Format(time.RFC3339Nano),
+		"p_to":              query.To.UTC().Format(time.RFC3339Nano),
+		"p_user_id":         nullIfEmpty(query.UserID),
+		"p_organization_id": organizationArg(ctx),
+	})
+	if err != nil {
+		r.logger.Error("failed to fetch slide heatmap",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch slide heatmap: %w", err)
+	}
+
+	var rows []heatmapRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse slide heatmap: %w", err)
+	}
+	cells := make([]domain.HeatmapCell, len(rows))
+	for i, row := range rows {
+		cells[i] = row.toHeatmapCell()
+	}
+	return cells, nil
+}
+
+// GetSessionContributors returns the per-user contribution to a session
+func (r *auditRepository) GetSessionContributors(ctx context.Context, sessionID domain.SessionID, query domain.ContributorQuery) ([]domain.ContributorStats, error) {
+	// Test sessions have no persisted events
+	if sessionID.IsTestSession() {
+		return []domain.ContributorStats{}, nil
+	}
+
+	// Aggregates run in the session_contributors function (migrations/020_audit_session_contributors.sql)
+	data, err := r.client.Post(ctx, "/rpc/session_contributors", map[string]interface{}{
+		"p_session_id":      sessionID.String(),
+		"p_from":            nullIfZeroTime(query.From),
+		"p_to":              nullIfZeroTime(query.To),
+		"p_organization_id": organizationArg(ctx),
+	})
+	if err != nil {
+		r.logger.Error("failed to fetch session contributors",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch session contributors: %w", err)
+	}
+
+	var rows []contributorRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse session contributors: %w", err)
+	}
+	contributors := make([]domain.ContributorStats, len(rows))
+	for i, row := range rows {
+		contributors[i] = row.toContributorStats()
+	}
+	return contributors, nil
+}
+
+// RollupActivity replaces the rollups starting in [from, to) with the aggregates of their events
+func (r *auditRepository) RollupActivity(ctx context.Context, from, to time.Time) (int, error) {
+	// Aggregation runs in the rollup_audit_activity function (migrations/016_audit_activity_rollups.sql)
+	data, err := r.client.Post(ctx, "/rpc/rollup_audit_activity", map[string]interface{}{
+		"p_from": from.UTC().Format(time.RFC3339Nano),
+		"p_to":   to.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to roll up activity: %w", err)
+	}
+
+	var written int
+	if err := json.Unmarshal(data, &written); err != nil {
+		return 0, fmt.Errorf("failed to parse rollup result: %w", err)
+	}
+	return written, nil
+}
+
+// DeleteUserActivity deletes the activity rollups of a user
+func (r *auditRepository) DeleteUserActivity(ctx context.Context, userID domain.UserID) error {
+	// The REST client cannot DELETE; the delete_user_audit_activity function (migrations/016_audit_activity_rollups.sql) removes the rows
+	if _, err := r.client.Post(ctx, "/rpc/delete_user_audit_activity", map[string]interface{}{
+		"p_user_id":         userID.String(),
+		"p_organization_id": organizationArg(ctx),
+	}); err != nil {
+		r.logger.Error("failed to delete user activity",
+			requestid.Field(ctx),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to delete user activity: %w", err)
+	}
+	return nil
 }
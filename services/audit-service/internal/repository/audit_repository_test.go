@@ -5,13 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"audit-service/internal/domain"
+	"audit-service/pkg/tenant"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -81,8 +84,8 @@ type MockSupabaseClient struct {
 	mock.Mock
 }
 
-func (m *MockSupabaseClient) Get(ctx context.Context, endpoint string, params map[string]string) ([]byte, int, error) {
-	args := m.Called(ctx, endpoint, params)
+func (m *MockSupabaseClient) Get(ctx context.Context, endpoint string, query *Query) ([]byte, int, error) {
+	args := m.Called(ctx, endpoint, query.Params())
 	return args.Get(0).([]byte), args.Int(1), args.Error(2)
 }
 
@@ -113,7 +116,7 @@ func TestAuditRepository_FindBySessionID(t *testing.T) {
 
 				expectedParams := map[string]string{
 					"session_id": "eq." + testSessionID,
-					"order":      "timestamp.desc",
+					"order":      "timestamp.desc,id.desc",
 					"limit":      "10",
 					"offset":     "0",
 					"select":     "*",
@@ -137,7 +140,7 @@ func TestAuditRepository_FindBySessionID(t *testing.T) {
 
 				expectedParams := map[string]string{
 					"session_id": "eq." + testSessionID,
-					"order":      "timestamp.desc",
+					"order":      "timestamp.desc,id.desc",
 					"limit":      "50",
 					"offset":     "20",
 					"select":     "*",
@@ -160,7 +163,7 @@ func TestAuditRepository_FindBySessionID(t *testing.T) {
 
 				expectedParams := map[string]string{
 					"session_id": "eq." + testSessionID,
-					"order":      "timestamp.desc",
+					"order":      "timestamp.desc,id.desc",
 					"limit":      "10",
 					"offset":     "0",
 					"select":     "*",
@@ -181,7 +184,7 @@ func TestAuditRepository_FindBySessionID(t *testing.T) {
 			setupMocks: func(mockClient *MockSupabaseClient) {
 				expectedParams := map[string]string{
 					"session_id": "eq." + testSessionID,
-					"order":      "timestamp.desc",
+					"order":      "timestamp.desc,id.desc",
 					"limit":      "10",
 					"offset":     "0",
 					"select":     "*",
@@ -204,7 +207,7 @@ func TestAuditRepository_FindBySessionID(t *testing.T) {
 
 				expectedParams := map[string]string{
 					"session_id": "eq." + testSessionID,
-					"order":      "timestamp.desc",
+					"order":      "timestamp.desc,id.desc",
 					"limit":      "10",
 					"offset":     "0",
 					"select":     "*",
@@ -230,7 +233,7 @@ func TestAuditRepository_FindBySessionID(t *testing.T) {
 			tt.setupMocks(mockClient)
 
 			// Execute
-			result, count, err := repo.FindBySessionID(context.Background(), tt.sessionID, tt.limit, tt.offset)
+			result, count, err := repo.FindBySessionID(context.Background(), domain.SessionID(tt.sessionID), domain.PaginationParams{Limit: tt.limit, Offset: tt.offset})
 
 			// Assert
 			if tt.expectedError != nil {
@@ -343,7 +346,7 @@ func TestAuditRepository_GetSession(t *testing.T) {
 			tt.setupMocks(mockClient)
 
 			// Execute
-			result, err := repo.GetSession(context.Background(), tt.sessionID)
+			result, err := repo.GetSession(context.Background(), domain.SessionID(tt.sessionID))
 
 			// Assert
 			if tt.expectedError != nil {
@@ -367,97 +370,61 @@ func TestAuditRepository_GetSession(t *testing.T) {
 	}
 }
 
-func TestAuditRepository_ValidateShareToken(t *testing.T) {
+func TestAuditRepository_GetActiveShare(t *testing.T) {
+	expectedParams := map[string]string{
+		"share_token_jti": "eq." + testShareToken,
+		"session_id":      "eq." + testSessionID,
+		"revoked_at":      "is.null",
+		"select":          "id,session_id,share_token_jti,permissions,expires_at",
+		"limit":           "1",
+	}
+	expiresAt := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
 	tests := []struct {
 		name          string
-		token         string
-		sessionID     string
 		setupMocks    func(*MockSupabaseClient)
-		expectedValid bool
+		expectedShare *domain.Share
 		expectedError error
 	}{
 		{
-			name:      "success_valid_token",
-			token:     testShareToken,
-			sessionID: testSessionID,
+			name: "success_active_share",
 			setupMocks: func(mockClient *MockSupabaseClient) {
-				shareToken := ShareToken{
-					Token:     testShareToken,
-					SessionID: testSessionID,
-				}
-				shares := []ShareToken{shareToken}
-				data, _ := json.Marshal(shares)
-
-				expectedParams := map[string]string{
-					"token":      "eq." + testShareToken,
-					"session_id": "eq." + testSessionID,
-					"select":     "token,session_id,expires_at",
-					"limit":      "1",
-				}
-
+				data := []byte(`[{"id":"share-1","session_id":"` + testSessionID + `","share_token_jti":"` + testShareToken +
+					`","permissions":["VIEW","COMMENT"],"expires_at":"2024-02-01T00:00:00+00:00"}]`)
 				mockClient.On("Get", mock.Anything, "/session_shares", expectedParams).
 					Return(data, 1, nil)
 			},
-			expectedValid: true,
-			expectedError: nil,
+			expectedShare: &domain.Share{
+				ID:          "share-1",
+				SessionID:   testSessionID,
+				TokenJTI:    testShareToken,
+				Permissions: []domain.SharePermission{domain.SharePermissionView, domain.SharePermissionComment},
+				ExpiresAt:   &expiresAt,
+			},
 		},
 		{
-			name:      "invalid_token_not_found",
-			token:     "invalid-token",
-			sessionID: testSessionID,
+			name: "not_found_or_revoked",
 			setupMocks: func(mockClient *MockSupabaseClient) {
-				data, _ := json.Marshal([]ShareToken{})
-
-				expectedParams := map[string]string{
-					"token":      "eq.invalid-token",
-					"session_id": "eq." + testSessionID,
-					"select":     "token,session_id,expires_at",
-					"limit":      "1",
-				}
-
 				mockClient.On("Get", mock.Anything, "/session_shares", expectedParams).
-					Return(data, 0, nil)
+					Return([]byte(`[]`), 0, nil)
 			},
-			expectedValid: false,
-			expectedError: nil,
+			expectedError: domain.ErrShareNotFound,
 		},
 		{
-			name:      "error_client_failure",
-			token:     testShareToken,
-			sessionID: testSessionID,
+			name: "error_client_failure",
 			setupMocks: func(mockClient *MockSupabaseClient) {
-				expectedParams := map[string]string{
-					"token":      "eq." + testShareToken,
-					"session_id": "eq." + testSessionID,
-					"select":     "token,session_id,expires_at",
-					"limit":      "1",
-				}
-
 				mockClient.On("Get", mock.Anything, "/session_shares", expectedParams).
 					Return([]byte{}, 0, errors.New("network error"))
 			},
-			expectedValid: false,
-			expectedError: errors.New("failed to validate share token: network error"),
+			expectedError: errors.New("failed to look up share link: network error"),
 		},
 		{
-			name:      "error_json_parse_failure",
-			token:     testShareToken,
-			sessionID: testSessionID,
+			name: "error_json_parse_failure",
 			setupMocks: func(mockClient *MockSupabaseClient) {
-				invalidJSON := []byte(`{"invalid": json}`)
-
-				expectedParams := map[string]string{
-					"token":      "eq." + testShareToken,
-					"session_id": "eq." + testSessionID,
-					"select":     "token,session_id,expires_at",
-					"limit":      "1",
-				}
-
 				mockClient.On("Get", mock.Anything, "/session_shares", expectedParams).
-					Return(invalidJSON, 0, nil)
+					Return([]byte(`{"invalid": json}`), 0, nil)
 			},
-			expectedValid: false,
-			expectedError: errors.New("failed to parse share token"),
+			expectedError: errors.New("failed to parse share link"),
 		},
 	}
 
@@ -465,31 +432,379 @@ func TestAuditRepository_ValidateShareToken(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockClient := &MockSupabaseClient{}
-			logger := zap.NewNop()
-			repo := NewAuditRepository(mockClient, logger)
-
-			// Configure mocks
+			repo := NewAuditRepository(mockClient, zap.NewNop())
 			tt.setupMocks(mockClient)
 
 			// Execute
-			valid, err := repo.ValidateShareToken(context.Background(), tt.token, tt.sessionID)
+			share, err := repo.GetActiveShare(context.Background(), testShareToken, testSessionID)
 
 			// Assert
 			if tt.expectedError != nil {
 				assert.Error(t, err)
-				assert.False(t, valid)
+				assert.Nil(t, share)
 				assert.Contains(t, err.Error(), tt.expectedError.Error())
 			} else {
-				assert.NoError(t, err)
-				assert.Equal(t, tt.expectedValid, valid)
+				require.NoError(t, err)
+				require.NotNil(t, share.ExpiresAt)
+				assert.True(t, tt.expectedShare.ExpiresAt.Equal(*share.ExpiresAt))
+				share.ExpiresAt = tt.expectedShare.ExpiresAt
+				assert.Equal(t, tt.expectedShare, share)
 			}
 
-			// Verify all expectations were met
 			mockClient.AssertExpectations(t)
 		})
 	}
 }
 
+func TestAuditRepository_CreateEvents(t *testing.T) {
+	entries := createTestAuditEntries()
+
+	t.Run("success_single_bulk_insert", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Post", mock.Anything, "/audit_logs", mock.MatchedBy(func(rows []auditLogRow) bool {
+			return len(rows) == 2 &&
+				rows[0].ID == "audit-001" &&
+				rows[0].SessionID == testSessionID &&
+				rows[0].Timestamp == "2024-01-01T11:50:00Z" &&
+				len(rows[0].ContentHash) == 64 &&
+				rows[1].Type == "merge"
+		})).Return([]byte{}, nil).Once()
+
+		err := repo.CreateEvents(context.Background(), entries)
+
+		assert.NoError(t, err)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("empty_batch_skips_request", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		err := repo.CreateEvents(context.Background(), nil)
+
+		assert.NoError(t, err)
+		mockClient.AssertNotCalled(t, "Post", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("error_client_failure", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Post", mock.Anything, "/audit_logs", mock.Anything).
+			Return([]byte{}, errors.New("network error"))
+
+		err := repo.CreateEvents(context.Background(), entries)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to insert audit logs: network error")
+	})
+}
+
+func TestAuditRepository_CreateEventsWithOutbox(t *testing.T) {
+	entries := createTestAuditEntries()
+	mockClient := &MockSupabaseClient{}
+	repo := newAuditRepository(mockClient, zap.NewNop(), true)
+
+	// Entries and their outbox messages are written by one function call, in one transaction
+	mockClient.On("Post", mock.Anything, "/rpc/insert_audit_logs", mock.MatchedBy(func(payload map[string]interface{}) bool {
+		rows, _ := payload["p_logs"].([]auditLogRow)
+		messages, _ := payload["p_outbox"].([]outboxRow)
+		return len(rows) == 2 && len(messages) == 2 &&
+			messages[0].EventID == "audit-001" &&
+			messages[0].SessionID == testSessionID &&
+			messages[1].EventType == "merge" &&
+			!strings.Contains(string(messages[0].Payload), "ipAddress")
+	})).Return([]byte{}, nil).Once()
+
+	require.NoError(t, repo.CreateEvents(context.Background(), entries))
+	mockClient.AssertExpectations(t)
+}
+
+func TestAuditRepository_ClaimOutbox(t *testing.T) {
+	mockClient := &MockSupabaseClient{}
+	repo := newAuditRepository(mockClient, zap.NewNop(), true)
+
+	mockClient.On("Post", mock.Anything, "/rpc/claim_audit_outbox", map[string]interface{}{
+		"p_limit":         50,
+		"p_lease_seconds": 300,
+	}).Return([]byte(`[{"id":3,"event_id":"audit-001","session_id":"`+testSessionID+`","event_type":"edit",
+		"payload":{"id":"audit-001"},"attempts":1,"created_at":"2024-01-01T12:00:00.5+00:00"}]`), nil).Once()
+
+	messages, err := repo.ClaimOutbox(context.Background(), 50, 5*time.Minute)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, domain.OutboxMessage{
+		ID:        3,
+		EventID:   "audit-001",
+		SessionID: testSessionID,
+		Type:      "edit",
+		Payload:   json.RawMessage(`{"id":"audit-001"}`),
+		Attempts:  1,
+		CreatedAt: time.Date(2024, 1, 1, 12, 0, 0, 500000000, time.UTC),
+	}, messages[0])
+}
+
+func TestAuditRepository_ReplayOutbox(t *testing.T) {
+	mockClient := &MockSupabaseClient{}
+	repo := newAuditRepository(mockClient, zap.NewNop(), true)
+
+	mockClient.On("Post", mock.Anything, "/rpc/replay_audit_outbox", map[string]interface{}{
+		"p_since": "2024-01-01T12:00:00Z",
+	}).Return([]byte(`4`), nil).Once()
+	mockClient.On("Post", mock.Anything, "/rpc/replay_audit_outbox", map[string]interface{}{
+		"p_since": nil,
+	}).Return([]byte(`7`), nil).Once()
+
+	replayed, err := repo.ReplayOutbox(context.Background(), time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 4, replayed)
+
+	replayed, err = repo.ReplayOutbox(context.Background(), time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, 7, replayed)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAuditRepository_EnqueueOutbox(t *testing.T) {
+	entries := createTestAuditEntries()
+	mockClient := &MockSupabaseClient{}
+	repo := newAuditRepository(mockClient, zap.NewNop(), true)
+
+	mockClient.On("Post", mock.Anything, "/rpc/enqueue_audit_outbox", mock.MatchedBy(func(payload map[string]interface{}) bool {
+		messages, _ := payload["p_outbox"].([]outboxRow)
+		return len(messages) == 2 &&
+			messages[0].EventID == "audit-001" &&
+			messages[1].EventType == "merge" &&
+			!strings.Contains(string(messages[0].Payload), "ipAddress")
+	})).Return([]byte(`1`), nil).Once()
+
+	queued, err := repo.EnqueueOutbox(context.Background(), entries)
+	require.NoError(t, err)
+	assert.Equal(t, 1, queued)
+
+	// Nothing to queue needs no request
+	queued, err = repo.EnqueueOutbox(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Zero(t, queued)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAuditRepository_DeadOutbox(t *testing.T) {
+	mockClient := &MockSupabaseClient{}
+	repo := newAuditRepository(mockClient, zap.NewNop(), true)
+	ctx := tenant.NewContext(context.Background(), "acme")
+
+	mockClient.On("Post", mock.Anything, "/rpc/list_dead_audit_outbox", map[string]interface{}{
+		"p_limit":           50,
+		"p_before_id":       int64(40),
+		"p_organization_id": "acme",
+	}).Return([]byte(`[{"id":12,"event_id":"audit-001","session_id":"`+testSessionID+`","event_type":"share",
+		"payload":{"id":"audit-001"},"attempts":8,"last_error":"webhook responded with status 503",
+		"created_at":"2024-01-01T12:00:00+00:00","dead_at":"2024-01-01T14:00:00+00:00"}]`), nil).Once()
+	mockClient.On("Post", mock.Anything, "/rpc/retry_dead_audit_outbox", map[string]interface{}{
+		"p_id":              int64(12),
+		"p_organization_id": "acme",
+	}).Return([]byte(`true`), nil).Once()
+	mockClient.On("Post", mock.Anything, "/rpc/retry_dead_audit_outbox", map[string]interface{}{
+		"p_id":              int64(13),
+		"p_organization_id": "acme",
+	}).Return([]byte(`false`), nil).Once()
+
+	letters, err := repo.ListDeadOutbox(ctx, 50, 40)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, domain.DeadLetter{
+		ID:        12,
+		EventID:   "audit-001",
+		SessionID: testSessionID,
+		Type:      "share",
+		Payload:   json.RawMessage(`{"id":"audit-001"}`),
+		Attempts:  8,
+		LastError: "webhook responded with status 503",
+		CreatedAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		DeadAt:    time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC),
+	}, letters[0])
+
+	require.NoError(t, repo.RetryDeadOutbox(ctx, 12))
+	assert.ErrorIs(t, repo.RetryDeadOutbox(ctx, 13), domain.ErrDeadLetterNotFound)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAuditRepository_QueryEvents(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		filter         domain.EventFilter
+		expectedParams map[string]string
+	}{
+		{
+			name:   "no_filters",
+			filter: domain.EventFilter{},
+			expectedParams: map[string]string{
+				"session_id": "eq." + testSessionID,
+				"order":      "timestamp.desc,id.desc",
+				"limit":      "10",
+				"offset":     "0",
+				"select":     "*",
+			},
+		},
+		{
+			name: "all_filters",
+			filter: domain.EventFilter{
+				Types:  []string{"edit", "comment"},
+				UserID: testUserID,
+				From:   from,
+				To:     to,
+				Search: "title",
+			},
+			expectedParams: map[string]string{
+				"session_id": "eq." + testSessionID,
+				"order":      "timestamp.desc,id.desc",
+				"limit":      "10",
+				"offset":     "0",
+				"select":     "*",
+				"type":       "in.(edit,comment)",
+				"user_id":    "eq." + testUserID,
+				"and":        `(timestamp.gte."2024-01-01T00:00:00Z",timestamp.lte."2024-01-31T23:59:59Z")`,
+				"details":    "plfts.title",
+			},
+		},
+		{
+			name: "single_type_and_lower_bound",
+			filter: domain.EventFilter{
+				Types: []string{"edit"},
+				From:  from,
+			},
+			expectedParams: map[string]string{
+				"session_id": "eq." + testSessionID,
+				"order":      "timestamp.desc,id.desc",
+				"limit":      "10",
+				"offset":     "0",
+				"select":     "*",
+				"type":       "eq.edit",
+				"timestamp":  "gte.2024-01-01T00:00:00Z",
+			},
+		},
+		{
+			name:   "slide_and_shape",
+			filter: domain.EventFilter{SlideID: "slide-3", ShapeID: "shape-12"},
+			expectedParams: map[string]string{
+				"session_id": "eq." + testSessionID,
+				"order":      "timestamp.desc,id.desc",
+				"limit":      "10",
+				"offset":     "0",
+				"select":     "*",
+				"slide_id":   "eq.slide-3",
+				"shape_id":   "eq.shape-12",
+			},
+		},
+		{
+			name:   "comment_and_thread",
+			filter: domain.EventFilter{CommentID: "comment-7", ThreadID: "thread-2"},
+			expectedParams: map[string]string{
+				"session_id": "eq." + testSessionID,
+				"order":      "timestamp.desc,id.desc",
+				"limit":      "10",
+				"offset":     "0",
+				"select":     "*",
+				"comment_id": "eq.comment-7",
+				"thread_id":  "eq.thread-2",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockSupabaseClient{}
+			repo := NewAuditRepository(mockClient, zap.NewNop())
+
+			data, _ := json.Marshal(createTestAuditEntries())
+			mockClient.On("Get", mock.Anything, "/audit_logs", tt.expectedParams).
+				Return(data, 2, nil)
+
+			entries, count, err := repo.QueryEvents(context.Background(), testSessionID, tt.filter, domain.PaginationParams{Limit: 10})
+
+			assert.NoError(t, err)
+			assert.Len(t, entries, 2)
+			assert.Equal(t, 2, count)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditRepository_QueryEvents_AllSessions(t *testing.T) {
+	mockClient := &MockSupabaseClient{}
+	repo := NewAuditRepository(mockClient, zap.NewNop())
+
+	// Without a session ID no session_id condition is sent
+	expectedParams := map[string]string{
+		"order":   "timestamp.desc,id.desc",
+		"limit":   "10",
+		"offset":  "0",
+		"select":  "*",
+		"user_id": "eq." + testUserID,
+	}
+
+	data, _ := json.Marshal(createTestAuditEntries())
+	mockClient.On("Get", mock.Anything, "/audit_logs", expectedParams).
+		Return(data, 2, nil)
+
+	entries, count, err := repo.QueryEvents(context.Background(), "", domain.EventFilter{UserID: testUserID}, domain.PaginationParams{Limit: 10})
+
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, 2, count)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAuditRepository_FindBySessionID_WithCursor(t *testing.T) {
+	mockClient := &MockSupabaseClient{}
+	repo := NewAuditRepository(mockClient, zap.NewNop())
+
+	cursor := &domain.Cursor{
+		Timestamp: time.Date(2024, 1, 1, 12, 0, 0, 500000000, time.UTC),
+		ID:        "entry-42",
+	}
+
+	// Offset is never sent alongside a keyset condition
+	expectedParams := map[string]string{
+		"session_id": "eq." + testSessionID,
+		"order":      "timestamp.desc,id.desc",
+		"limit":      "10",
+		"select":     "*",
+		"or":         `(timestamp.lt."2024-01-01T12:00:00.5Z",and(timestamp.eq."2024-01-01T12:00:00.5Z",id.lt.entry-42))`,
+	}
+
+	data, _ := json.Marshal(createTestAuditEntries())
+	mockClient.On("Get", mock.Anything, "/audit_logs", expectedParams).
+		Return(data, 2, nil)
+
+	entries, _, err := repo.FindBySessionID(context.Background(), testSessionID, domain.PaginationParams{Limit: 10, Cursor: cursor})
+
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAuditRepository_QueryEvents_ClientError(t *testing.T) {
+	mockClient := &MockSupabaseClient{}
+	repo := NewAuditRepository(mockClient, zap.NewNop())
+
+	mockClient.On("Get", mock.Anything, "/audit_logs", mock.Anything).
+		Return([]byte{}, 0, errors.New("network error"))
+
+	entries, count, err := repo.QueryEvents(context.Background(), testSessionID, domain.EventFilter{}, domain.PaginationParams{Limit: 10})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to query audit logs: network error")
+	assert.Nil(t, entries)
+	assert.Equal(t, 0, count)
+}
+
 func TestNewAuditRepository(t *testing.T) {
 	mockClient := &MockSupabaseClient{}
 	logger := zap.NewNop()
@@ -499,3 +814,815 @@ func TestNewAuditRepository(t *testing.T) {
 	assert.NotNil(t, repo)
 	assert.Implements(t, (*AuditRepository)(nil), repo)
 }
+
+func TestAuditRepository_GetSessionStats(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Post", mock.Anything, "/rpc/session_audit_stats", map[string]interface{}{
+			"p_session_id":      testSessionID,
+			"p_organization_id": nil,
+		}).
+			Return([]byte(`{
+				"total_events": 7,
+				"contributors": 2,
+				"first_activity": "2024-01-01T09:00:00+00:00",
+				"last_activity": "2024-01-02T17:30:00+00:00",
+				"by_type": {"edit": 5, "comment": 2},
+				"edits_per_slide": {"slide-1": 3, "slide-2": 2}
+			}`), nil).Once()
+
+		stats, err := repo.GetSessionStats(context.Background(), testSessionID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, testSessionID, stats.SessionID)
+		assert.Equal(t, 7, stats.TotalEvents)
+		assert.Equal(t, 2, stats.Contributors)
+		assert.True(t, stats.FirstActivity.Equal(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)))
+		assert.True(t, stats.LastActivity.Equal(time.Date(2024, 1, 2, 17, 30, 0, 0, time.UTC)))
+		assert.Equal(t, map[string]int{"edit": 5, "comment": 2}, stats.ByType)
+		assert.Equal(t, map[string]int{"slide-1": 3, "slide-2": 2}, stats.EditsPerSlide)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("session_without_events", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Post", mock.Anything, "/rpc/session_audit_stats", mock.Anything).
+			Return([]byte(`{"total_events": 0, "contributors": 0, "first_activity": null, "last_activity": null, "by_type": {}, "edits_per_slide": {}}`), nil)
+
+		stats, err := repo.GetSessionStats(context.Background(), testSessionID)
+
+		assert.NoError(t, err)
+		assert.Zero(t, stats.TotalEvents)
+		assert.Nil(t, stats.FirstActivity)
+		assert.NotNil(t, stats.ByType)
+	})
+
+	t.Run("test_session_skips_request", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		stats, err := repo.GetSessionStats(context.Background(), "test-session-1")
+
+		assert.NoError(t, err)
+		assert.Zero(t, stats.TotalEvents)
+		mockClient.AssertNotCalled(t, "Post", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("error_client_failure", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Post", mock.Anything, "/rpc/session_audit_stats", mock.Anything).
+			Return([]byte{}, errors.New("network error"))
+
+		_, err := repo.GetSessionStats(context.Background(), testSessionID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fetch session stats: network error")
+	})
+}
+
+func TestAuditRepository_PurgeEvents(t *testing.T) {
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Post", mock.Anything, "/rpc/purge_audit_logs", map[string]interface{}{
+			"p_type":   "view",
+			"p_before": "2024-01-01T00:00:00Z",
+			"p_limit":  500,
+		}).Return([]byte(`[{"session_id": "session-1", "deleted": 3}, {"session_id": "session-2", "deleted": 1}]`), nil).Once()
+
+		purged, err := repo.PurgeEvents(context.Background(), "view", before, 500)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.PurgedEvents{
+			{SessionID: "session-1", Deleted: 3},
+			{SessionID: "session-2", Deleted: 1},
+		}, purged)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("error_client_failure", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Post", mock.Anything, "/rpc/purge_audit_logs", mock.Anything).
+			Return([]byte{}, errors.New("network error"))
+
+		_, err := repo.PurgeEvents(context.Background(), "view", before, 500)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to purge audit logs: network error")
+	})
+}
+
+func TestAuditRepository_FindExpiredEvents(t *testing.T) {
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Post", mock.Anything, "/rpc/find_expired_audit_logs", map[string]interface{}{
+			"p_type":   "view",
+			"p_before": "2024-01-01T00:00:00Z",
+			"p_limit":  100,
+		}).Return([]byte(`[{
+			"id": "audit-1",
+			"session_id": "session-1",
+			"user_id": "user-1",
+			"type": "view",
+			"timestamp": "2023-12-01T10:30:00.5+00:00",
+			"details": {"slideId": "slide-1"},
+			"ip_address": "203.0.113.7"
+		}]`), nil).Once()
+
+		entries, err := repo.FindExpiredEvents(context.Background(), "view", before, 100)
+
+		assert.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "audit-1", entries[0].ID)
+		assert.Equal(t, "session-1", entries[0].SessionID)
+		assert.Equal(t, "user-1", entries[0].UserID)
+		assert.Equal(t, time.Date(2023, 12, 1, 10, 30, 0, 5e8, time.UTC), entries[0].Timestamp)
+		assert.JSONEq(t, `{"slideId": "slide-1"}`, string(entries[0].Details))
+		assert.Equal(t, "203.0.113.7", entries[0].IPAddress)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("error_client_failure", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Post", mock.Anything, "/rpc/find_expired_audit_logs", mock.Anything).
+			Return([]byte{}, errors.New("network error"))
+
+		_, err := repo.FindExpiredEvents(context.Background(), "view", before, 100)
+
+		assert.ErrorContains(t, err, "failed to fetch expired audit logs: network error")
+	})
+}
+
+func TestAuditRepository_FindEvent(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Get", mock.Anything, "/audit_logs", map[string]string{
+			"id":     "eq.audit-2",
+			"select": "*",
+			"limit":  "1",
+		}).Return([]byte(`[{
+			"id": "audit-2",
+			"session_id": "session-1",
+			"user_id": "user-1",
+			"type": "export",
+			"timestamp": "2023-12-01T10:30:00+00:00",
+			"correlation_id": "export-7f3a",
+			"parent_event_id": "audit-1"
+		}]`), 1, nil).Once()
+
+		entry, err := repo.FindEvent(context.Background(), "audit-2")
+
+		require.NoError(t, err)
+		assert.Equal(t, "session-1", entry.SessionID)
+		assert.Equal(t, "export-7f3a", entry.CorrelationID)
+		assert.Equal(t, "audit-1", entry.ParentEventID)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("scoped to organization", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Get", mock.Anything, "/audit_logs", map[string]string{
+			"id":              "eq.audit-2",
+			"select":          "*",
+			"limit":           "1",
+			"organization_id": "eq.org-1",
+		}).Return([]byte(`[]`), 0, nil).Once()
+
+		_, err := repo.FindEvent(tenant.NewContext(context.Background(), "org-1"), "audit-2")
+
+		assert.ErrorIs(t, err, domain.ErrEventNotFound)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Get", mock.Anything, "/audit_logs", mock.Anything).Return([]byte(`[]`), 0, nil).Once()
+
+		_, err := repo.FindEvent(context.Background(), "audit-2")
+
+		assert.ErrorIs(t, err, domain.ErrEventNotFound)
+	})
+}
+
+func TestAuditRepository_DeleteEvents(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Post", mock.Anything, "/rpc/delete_audit_logs", map[string]interface{}{"p_ids": []string{"audit-1", "audit-2"}}).
+			Return([]byte(`[{"session_id": "session-1", "deleted": 2}]`), nil).Once()
+
+		deleted, err := repo.DeleteEvents(context.Background(), []string{"audit-1", "audit-2"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.PurgedEvents{{SessionID: "session-1", Deleted: 2}}, deleted)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("no_ids_skips_request", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		deleted, err := repo.DeleteEvents(context.Background(), nil)
+
+		assert.NoError(t, err)
+		assert.Empty(t, deleted)
+		mockClient.AssertNotCalled(t, "Post", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuditRepository_ExistingEventIDs(t *testing.T) {
+	mockClient := &MockSupabaseClient{}
+	repo := NewAuditRepository(mockClient, zap.NewNop())
+
+	ids := make([]string, existingIDsPerRequest+1)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("00000000-0000-0000-0000-%012d", i)
+	}
+	// The IDs are looked up in chunks, unscoped since IDs are unique across organizations
+	mockClient.On("Get", mock.Anything, "/audit_logs", map[string]string{
+		"id":     "in.(" + strings.Join(ids[:existingIDsPerRequest], ",") + ")",
+		"select": "id",
+	}).Return([]byte(`[{"id": "00000000-0000-0000-0000-000000000007"}]`), 1, nil).Once()
+	mockClient.On("Get", mock.Anything, "/audit_logs", map[string]string{
+		"id":     "in.(" + ids[existingIDsPerRequest] + ")",
+		"select": "id",
+	}).Return([]byte(`[]`), 0, nil).Once()
+
+	existing, err := repo.ExistingEventIDs(tenant.NewContext(context.Background(), "org-1"), ids)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"00000000-0000-0000-0000-000000000007"}, existing)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAuditRepository_FindChain(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Get", mock.Anything, "/audit_logs", map[string]string{
+			"select":     "*",
+			"session_id": "eq." + testSessionID,
+			"seq":        "gt.10",
+			"order":      "seq.asc",
+			"limit":      "100",
+		}).Return([]byte(`[{
+			"id": "audit-11",
+			"session_id": "`+testSessionID+`",
+			"user_id": "user-1",
+			"type": "edit",
+			"timestamp": "2024-01-01T10:00:00.123456+00:00",
+			"details": {"slideId": "slide-1"},
+			"seq": 11,
+			"content_hash": "abc",
+			"prev_hash": "def",
+			"chain_hash": "123"
+		}]`), 1, nil).Once()
+
+		chain, err := repo.FindChain(context.Background(), testSessionID, 10, 100)
+
+		assert.NoError(t, err)
+		require.Len(t, chain, 1)
+		assert.Equal(t, "audit-11", chain[0].ID)
+		assert.Equal(t, int64(11), chain[0].Seq)
+		assert.Equal(t, "abc", chain[0].ContentHash)
+		assert.Equal(t, "def", chain[0].PrevHash)
+		assert.Equal(t, "123", chain[0].ChainHash)
+		assert.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, 123456000, time.UTC), chain[0].Timestamp)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("test_session_skips_request", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		chain, err := repo.FindChain(context.Background(), "test-session-1", 0, 100)
+
+		assert.NoError(t, err)
+		assert.Empty(t, chain)
+		mockClient.AssertNotCalled(t, "Get", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestNewAuditLogRow_HashMatchesStoredEntry(t *testing.T) {
+	entry := domain.AuditEntry{
+		ID:        "audit-1",
+		SessionID: testSessionID,
+		UserID:    "user-1",
+		Type:      "edit",
+		Timestamp: time.Date(2024, 1, 1, 10, 0, 0, 123456789, time.UTC),
+		Details:   json.RawMessage(`{"slideId":"slide-1"}`),
+	}
+
+	row, err := newAuditLogRow(entry)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-01T10:00:00.123456Z", row.Timestamp)
+
+	// Reading the row back must reproduce the stored content hash
+	stored, err := row.toEntry()
+	require.NoError(t, err)
+	hash, err := domain.ContentHash(stored)
+	require.NoError(t, err)
+	assert.Equal(t, row.ContentHash, hash)
+}
+
+func TestAuditRepository_EraseUserEvents(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Post", mock.Anything, "/rpc/erase_user_audit_logs", map[string]interface{}{
+			"p_user_id":         "user-1",
+			"p_mode":            "anonymize",
+			"p_limit":           500,
+			"p_override_holds":  false,
+			"p_organization_id": "org-1",
+		}).Return([]byte(`[{"session_id": "session-1", "organization_id": "org-1", "deleted": 4}]`), nil).Once()
+
+		ctx := tenant.NewContext(context.Background(), "org-1")
+		affected, err := repo.EraseUserEvents(ctx, "user-1", domain.ErasureAnonymize, false, 500)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.PurgedEvents{{SessionID: "session-1", OrganizationID: "org-1", Deleted: 4}}, affected)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("error_client_failure", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Post", mock.Anything, "/rpc/erase_user_audit_logs", mock.Anything).
+			Return([]byte{}, errors.New("network error"))
+
+		_, err := repo.EraseUserEvents(context.Background(), "user-1", domain.ErasureDelete, true, 500)
+
+		assert.ErrorContains(t, err, "failed to erase user audit logs: network error")
+	})
+}
+
+func TestAuditRepository_EventTypes(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := newAuditRepository(mockClient, zap.NewNop(), false)
+
+		mockClient.On("Get", mock.Anything, "/audit_event_types", map[string]string{
+			"select": "name,display_name,severity,schema,created_by,created_at",
+			"order":  "name.asc",
+		}).Return([]byte(`[
+			{"name": "glossary_synced", "display_name": "Glossary synced", "severity": "info", "schema": null,
+			 "created_by": "admin-1", "created_at": "2024-01-10T09:00:00+00:00"},
+			{"name": "terminology_approved", "display_name": "Terminology approved", "severity": "low",
+			 "schema": {"type": "object"}, "created_by": "admin-1", "created_at": "2024-01-11T09:00:00+00:00"}
+		]`), 2, nil).Once()
+
+		types, err := repo.ListEventTypes(context.Background())
+
+		require.NoError(t, err)
+		require.Len(t, types, 2)
+		assert.Equal(t, domain.AuditAction("glossary_synced"), types[0].Name)
+		assert.Nil(t, types[0].Schema)
+		assert.Equal(t, domain.SeverityLow, types[1].Severity)
+		assert.JSONEq(t, `{"type": "object"}`, string(types[1].Schema))
+		assert.Equal(t, time.Date(2024, 1, 11, 9, 0, 0, 0, time.UTC), *types[1].CreatedAt)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := newAuditRepository(mockClient, zap.NewNop(), false)
+		createdAt := time.Date(2024, 1, 11, 9, 0, 0, 0, time.UTC)
+
+		mockClient.On("Post", mock.Anything, "/audit_event_types", eventTypeRow{
+			Name: "terminology_approved", DisplayName: "Terminology approved", Severity: "low",
+			CreatedBy: "admin-1", CreatedAt: createdAt,
+		}).Return([]byte(`[]`), nil).Once()
+
+		err := repo.CreateEventType(context.Background(), domain.EventType{
+			Name: "terminology_approved", DisplayName: "Terminology approved", Severity: domain.SeverityLow,
+			CreatedBy: "admin-1", CreatedAt: &createdAt,
+		})
+
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("create existing", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := newAuditRepository(mockClient, zap.NewNop(), false)
+
+		mockClient.On("Post", mock.Anything, "/audit_event_types", mock.Anything).
+			Return([]byte(nil), &SupabaseError{Message: "duplicate key value", Code: "23505", StatusCode: 409}).Once()
+
+		err := repo.CreateEventType(context.Background(), domain.EventType{Name: "terminology_approved"})
+
+		assert.ErrorIs(t, err, domain.ErrEventTypeExists)
+	})
+}
+
+func TestAuditRepository_Watches(t *testing.T) {
+	createdAt := time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+	row := `{"session_id": "` + testSessionID + `", "user_id": "user-1", "event_types": ["share", "export"],
+		"created_at": "2024-02-01T09:00:00+00:00", "organization_id": null}`
+	watch := domain.Watch{SessionID: testSessionID, UserID: "user-1", EventTypes: []string{"share", "export"}, CreatedAt: createdAt}
+
+	t.Run("put", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := newAuditRepository(mockClient, zap.NewNop(), false)
+
+		mockClient.On("Post", mock.Anything, "/rpc/put_audit_session_watch", map[string]interface{}{
+			"p_session_id":      testSessionID,
+			"p_user_id":         "user-1",
+			"p_event_types":     []string{"share", "export"},
+			"p_created_at":      "2024-02-01T09:00:00Z",
+			"p_organization_id": nil,
+		}).Return([]byte(`[`+row+`]`), nil).Once()
+
+		stored, err := repo.PutWatch(context.Background(), watch)
+
+		require.NoError(t, err)
+		assert.Equal(t, watch, *stored)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("list session watches", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := newAuditRepository(mockClient, zap.NewNop(), false)
+
+		mockClient.On("Get", mock.Anything, "/audit_session_watches", map[string]string{
+			"select":     "session_id,user_id,event_types,created_at,organization_id",
+			"session_id": "eq." + testSessionID,
+			"order":      "user_id.asc",
+		}).Return([]byte(`[`+row+`]`), 1, nil).Once()
+
+		watches, err := repo.ListSessionWatches(context.Background(), testSessionID)
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Watch{watch}, watches)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("delete unknown watch", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := newAuditRepository(mockClient, zap.NewNop(), false)
+
+		mockClient.On("Post", mock.Anything, "/rpc/delete_audit_session_watch", map[string]interface{}{
+			"p_session_id": testSessionID,
+			"p_user_id":    "user-1",
+		}).Return([]byte(`[]`), nil).Once()
+
+		err := repo.DeleteWatch(context.Background(), testSessionID, "user-1")
+
+		assert.ErrorIs(t, err, domain.ErrWatchNotFound)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestAuditRepository_Reports(t *testing.T) {
+	reportID := "0b8f5e0c-3c1d-4a57-9d0e-6f1b2c3d4e5f"
+	scheduledAt := time.Date(2024, 1, 22, 6, 0, 0, 0, time.UTC)
+	row := `{"id": "` + reportID + `", "name": "Weekly", "kind": "session_activity", "session_id": null,
+		"schedule": "0 6 * * 1", "format": "csv", "delivery": "webhook", "target": "https://reports.example.com",
+		"created_by": "admin-1", "created_at": "2024-01-17T10:30:00+00:00", "next_run_at": "2024-01-22T06:00:00+00:00",
+		"last_run_at": null, "organization_id": "acme"}`
+	report := domain.Report{ID: reportID, Name: "Weekly", Kind: domain.ReportSessionActivity, Schedule: "0 6 * * 1",
+		Format: domain.ReportCSV, Delivery: domain.ReportWebhook, Target: "https://reports.example.com", CreatedBy: "admin-1",
+		CreatedAt: time.Date(2024, 1, 17, 10, 30, 0, 0, time.UTC), NextRunAt: scheduledAt, OrganizationID: "acme"}
+
+	t.Run("list due reports of every organization", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := newAuditRepository(mockClient, zap.NewNop(), false)
+
+		mockClient.On("Get", mock.Anything, "/audit_reports", map[string]string{
+			"select":      reportColumns,
+			"next_run_at": "lte.2024-01-22T06:00:30Z",
+			"order":       "next_run_at.asc,id.asc",
+			"limit":       "100",
+		}).Return([]byte(`[`+row+`]`), 1, nil).Once()
+
+		reports, err := repo.ListDueReports(context.Background(), scheduledAt.Add(30*time.Second), 100)
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Report{report}, reports)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("advance claimed by another replica", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := newAuditRepository(mockClient, zap.NewNop(), false)
+
+		mockClient.On("Post", mock.Anything, "/rpc/advance_audit_report", map[string]interface{}{
+			"p_id":           reportID,
+			"p_scheduled_at": "2024-01-22T06:00:00Z",
+			"p_next_run_at":  "2024-01-29T06:00:00Z",
+		}).Return([]byte(`[]`), nil).Once()
+
+		claimed, err := repo.AdvanceReport(context.Background(), reportID, scheduledAt, scheduledAt.AddDate(0, 0, 7))
+
+		require.NoError(t, err)
+		assert.False(t, claimed)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("get report of another organization", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := newAuditRepository(mockClient, zap.NewNop(), false)
+
+		mockClient.On("Get", mock.Anything, "/audit_reports", map[string]string{
+			"select":          reportColumns,
+			"id":              "eq." + reportID,
+			"organization_id": "eq.globex",
+		}).Return([]byte(`[]`), 0, nil).Once()
+
+		_, err := repo.GetReport(tenant.NewContext(context.Background(), "globex"), reportID)
+
+		assert.ErrorIs(t, err, domain.ErrReportNotFound)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestAuditRepository_SavedQueries(t *testing.T) {
+	queryID := "0b8f5e0c-3c1d-4a57-9d0e-6f1b2c3d4e5f"
+	query := domain.SavedQuery{ID: queryID, Name: "My edits this week", Scope: domain.SavedQueryPrivate,
+		Filter:    domain.SavedQueryFilter{Types: []string{"edit"}, UserID: domain.SavedQueryCurrentUser, Days: 7},
+		CreatedBy: "user-1", CreatedAt: time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 2, 2, 9, 0, 0, 0, time.UTC)}
+	row := `{"id": "` + queryID + `", "name": "My edits this week", "description": "", "scope": "private",
+		"filter": {"types": ["edit"], "userId": "me", "days": 7}, "created_by": "user-1",
+		"created_at": "2024-02-01T09:00:00+00:00", "updated_at": "2024-02-02T09:00:00+00:00", "organization_id": null}`
+
+	t.Run("list queries visible to a user", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := newAuditRepository(mockClient, zap.NewNop(), false)
+
+		mockClient.On("Get", mock.Anything, "/audit_saved_queries", map[string]string{
+			"select":          savedQueryColumns,
+			"or":              "(created_by.eq.user-1,scope.eq.organization)",
+			"order":           "name.asc,id.asc",
+			"organization_id": "is.null",
+		}).Return([]byte(`[`+row+`]`), 1, nil).Once()
+
+		queries, err := repo.ListSavedQueries(tenant.NewContext(context.Background(), ""), "user-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.SavedQuery{query}, queries)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("update query of another organization", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := newAuditRepository(mockClient, zap.NewNop(), false)
+
+		mockClient.On("Post", mock.Anything, "/rpc/update_audit_saved_query", map[string]interface{}{
+			"p_id":              queryID,
+			"p_name":            "My edits this week",
+			"p_description":     "",
+			"p_scope":           "private",
+			"p_filter":          query.Filter,
+			"p_updated_at":      "2024-02-02T09:00:00Z",
+			"p_organization_id": "globex",
+		}).Return([]byte(`[]`), nil).Once()
+
+		err := repo.UpdateSavedQuery(tenant.NewContext(context.Background(), "globex"), query)
+
+		assert.ErrorIs(t, err, domain.ErrSavedQueryNotFound)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := newAuditRepository(mockClient, zap.NewNop(), false)
+
+		mockClient.On("Post", mock.Anything, "/rpc/delete_audit_saved_query", map[string]interface{}{
+			"p_id":              queryID,
+			"p_organization_id": nil,
+		}).Return([]byte(`[`+row+`]`), nil).Once()
+
+		require.NoError(t, repo.DeleteSavedQuery(context.Background(), queryID))
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestAuditRepository_LegalHolds(t *testing.T) {
+	t.Run("list active", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := newAuditRepository(mockClient, zap.NewNop(), false)
+
+		mockClient.On("Get", mock.Anything, "/audit_legal_holds", map[string]string{
+			"select":      "id,scope,target,reason,placed_by,placed_at,released_by,released_at,organization_id",
+			"order":       "placed_at.desc,id.asc",
+			"released_at": "is.null",
+		}).Return([]byte(`[
+			{"id": "hold-1", "scope": "user", "target": "user-2", "reason": "Investigation",
+			 "placed_by": "admin-1", "placed_at": "2024-02-01T09:00:00+00:00", "released_by": null, "released_at": null}
+		]`), 1, nil).Once()
+
+		holds, err := repo.ListLegalHolds(context.Background(), true)
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.LegalHold{{
+			ID: "hold-1", Scope: domain.LegalHoldUser, Target: "user-2", Reason: "Investigation",
+			PlacedBy: "admin-1", PlacedAt: time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC),
+		}}, holds)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("release", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := newAuditRepository(mockClient, zap.NewNop(), false)
+		releasedAt := time.Date(2024, 2, 2, 9, 0, 0, 0, time.UTC)
+
+		mockClient.On("Post", mock.Anything, "/rpc/release_audit_legal_hold", map[string]interface{}{
+			"p_id":              "hold-1",
+			"p_released_by":     "admin-2",
+			"p_released_at":     "2024-02-02T09:00:00Z",
+			"p_organization_id": nil,
+		}).Return([]byte(`[
+			{"id": "hold-1", "scope": "session", "target": "550e8400-e29b-41d4-a716-446655440000", "reason": "Litigation",
+			 "placed_by": "admin-1", "placed_at": "2024-02-01T09:00:00+00:00", "released_by": "admin-2", "released_at": "2024-02-02T09:00:00+00:00"}
+		]`), nil).Once()
+
+		hold, err := repo.ReleaseLegalHold(context.Background(), "hold-1", "admin-2", releasedAt)
+
+		require.NoError(t, err)
+		assert.Equal(t, "admin-2", hold.ReleasedBy)
+		assert.Equal(t, releasedAt, *hold.ReleasedAt)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("release without active hold", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			existing string
+			expected error
+		}{
+			{name: "unknown", existing: `[]`, expected: domain.ErrLegalHoldNotFound},
+			{name: "already released", existing: `[{"id": "hold-1"}]`, expected: domain.ErrLegalHoldReleased},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockClient := &MockSupabaseClient{}
+				repo := newAuditRepository(mockClient, zap.NewNop(), false)
+
+				mockClient.On("Post", mock.Anything, "/rpc/release_audit_legal_hold", mock.Anything).
+					Return([]byte(`[]`), nil).Once()
+				mockClient.On("Get", mock.Anything, "/audit_legal_holds", map[string]string{
+					"select": "id",
+					"id":     "eq.hold-1",
+				}).Return([]byte(tt.existing), 0, nil).Once()
+
+				_, err := repo.ReleaseLegalHold(context.Background(), "hold-1", "admin-2", time.Now())
+
+				assert.ErrorIs(t, err, tt.expected)
+				mockClient.AssertExpectations(t)
+			})
+		}
+	})
+}
+
+func TestAuditRepository_CreateEvents_OtherOrganization(t *testing.T) {
+	mockClient := &MockSupabaseClient{}
+	repo := NewAuditRepository(mockClient, zap.NewNop())
+
+	entries := []domain.AuditEntry{{
+		ID: "audit-1", SessionID: "session-1", UserID: "user-1", Type: "edit",
+		Timestamp: time.Now(), OrganizationID: "org-2",
+	}}
+	err := repo.CreateEvents(tenant.NewContext(context.Background(), "org-1"), entries)
+
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	mockClient.AssertNotCalled(t, "Post", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuditRepository_Activity(t *testing.T) {
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	t.Run("reads_rollups", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Get", mock.Anything, "/audit_activity_rollups", map[string]string{
+			"select":          "bucket_start,user_id,event_count,by_type",
+			"session_id":      "eq." + testSessionID,
+			"granularity":     "eq.day",
+			"and":             `(bucket_start.gte."2024-01-15T00:00:00Z",bucket_start.lt."2024-01-17T00:00:00Z")`,
+			"order":           "bucket_start.asc,user_id.asc",
+			"user_id":         "eq.user-1",
+			"organization_id": "eq.acme",
+		}).Return([]byte(`[{"bucket_start":"2024-01-15T00:00:00+00:00","user_id":"user-1","event_count":3,"by_type":{"edit":3}}]`), 1, nil).Once()
+
+		buckets, err := repo.GetSessionActivity(tenant.NewContext(context.Background(), "acme"), testSessionID, domain.ActivityQuery{
+			Granularity: domain.ActivityDay, From: day, To: day.AddDate(0, 0, 2), UserID: "user-1",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.ActivityBucket{{Start: day, UserID: "user-1", EventCount: 3, ByType: map[string]int{"edit": 3}}}, buckets)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("rolls_up_in_the_database", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop()).(*auditRepository)
+
+		mockClient.On("Post", mock.Anything, "/rpc/rollup_audit_activity", map[string]interface{}{
+			"p_from": "2024-01-15T00:00:00Z",
+			"p_to":   "2024-01-15T09:30:00Z",
+		}).Return([]byte(`12`), nil).Once()
+
+		written, err := repo.RollupActivity(context.Background(), day, day.Add(9*time.Hour+30*time.Minute))
+
+		require.NoError(t, err)
+		assert.Equal(t, 12, written)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("deletes_user_rollups", func(t *testing.T) {
+		mockClient := &MockSupabaseClient{}
+		repo := NewAuditRepository(mockClient, zap.NewNop())
+
+		mockClient.On("Post", mock.Anything, "/rpc/delete_user_audit_activity", map[string]interface{}{
+			"p_user_id":         "user-1",
+			"p_organization_id": nil,
+		}).Return([]byte(``), nil).Once()
+
+		require.NoError(t, repo.DeleteUserActivity(context.Background(), "user-1"))
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestAuditRepository_GetSessionContributors(t *testing.T) {
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	mockClient := &MockSupabaseClient{}
+	repo := NewAuditRepository(mockClient, zap.NewNop())
+
+	// An open range passes null bounds
+	mockClient.On("Post", mock.Anything, "/rpc/session_contributors", map[string]interface{}{
+		"p_session_id":      testSessionID,
+		"p_from":            day,
+		"p_to":              nil,
+		"p_organization_id": nil,
+	}).Return([]byte(`[
+		{"user_id":"user-1","edits":12,"comments":3,"merges":1,"total_events":20,
+			"first_activity":"2024-01-15T09:00:00+00:00","last_activity":"2024-01-16T17:30:00+00:00"}
+	]`), nil).Once()
+
+	contributors, err := repo.GetSessionContributors(context.Background(), testSessionID, domain.ContributorQuery{From: day})
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ContributorStats{{
+		UserID: "user-1", Edits: 12, Comments: 3, Merges: 1, TotalEvents: 20,
+		FirstActivity: day.Add(9 * time.Hour), LastActivity: day.Add(41*time.Hour + 30*time.Minute),
+	}}, contributors)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAuditRepository_GetSlideHeatmap(t *testing.T) {
+	hour := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	mockClient := &MockSupabaseClient{}
+	repo := NewAuditRepository(mockClient, zap.NewNop())
+
+	mockClient.On("Post", mock.Anything, "/rpc/session_slide_heatmap", map[string]interface{}{
+		"p_session_id":      testSessionID,
+		"p_from":            "2024-01-15T09:00:00Z",
+		"p_to":              "2024-01-15T11:00:00Z",
+		"p_user_id":         nil,
+		"p_organization_id": "acme",
+	}).Return([]byte(`[
+		{"slide_id":"slide-b","slide_index":1,"hour":"2024-01-15T09:00:00+00:00","event_count":4},
+		{"slide_id":"slide-gone","slide_index":null,"hour":"2024-01-15T10:00:00+00:00","event_count":1}
+	]`), nil).Once()
+
+	cells, err := repo.GetSlideHeatmap(tenant.NewContext(context.Background(), "acme"), testSessionID,
+		domain.HeatmapQuery{From: hour, To: hour.Add(2 * time.Hour)})
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.HeatmapCell{
+		{SlideID: "slide-b", SlideIndex: 1, Hour: hour, EventCount: 4},
+		{SlideID: "slide-gone", Hour: hour.Add(time.Hour), EventCount: 1},
+	}, cells)
+	mockClient.AssertExpectations(t)
+}
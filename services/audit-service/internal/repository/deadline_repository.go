@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"audit-service/internal/domain"
+)
+
+// deadlineRepository bounds each storage request made while serving an API request, so a
+// stalled backend fails the request with a timeout instead of holding it until the client gives
+// up. The deadline only ever shortens the caller's context: a request whose client disconnected
+// still cancels its storage calls at once. Maintenance calls, such as purges and erasure, pass
+// through unbounded; their workers size and cancel their own batches.
+type deadlineRepository struct {
+	AuditRepository
+	timeout time.Duration
+}
+
+// NewDeadlineRepository wraps a repository so every request-path call runs within timeout.
+// A timeout that is not positive returns repo unchanged.
+func NewDeadlineRepository(repo AuditRepository, timeout time.Duration) AuditRepository {
+	if timeout <= 0 {
+		return repo
+	}
+	return &deadlineRepository{
+		AuditRepository: repo,
+		timeout:         timeout,
+	}
+}
+
+// FindBySessionID reads a page of a session history within the operation timeout
+func (r *deadlineRepository) FindBySessionID(ctx context.Context, sessionID domain.SessionID, page domain.PaginationParams) ([]domain.AuditEntry, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.AuditRepository.FindBySessionID(ctx, sessionID, page)
+}
+
+// GetSession looks up a session within the operation timeout
+func (r *deadlineRepository) GetSession(ctx context.Context, sessionID domain.SessionID) (*Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.AuditRepository.GetSession(ctx, sessionID)
+}
+
+// GetActiveShare looks up a share within the operation timeout
+func (r *deadlineRepository) GetActiveShare(ctx context.Context, tokenJTI string, sessionID domain.SessionID) (*domain.Share, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.AuditRepository.GetActiveShare(ctx, tokenJTI, sessionID)
+}
+
+// CreateEvents stores entries within the operation timeout; callers that retry get a fresh
+// deadline per attempt
+func (r *deadlineRepository) CreateEvents(ctx context.Context, entries []domain.AuditEntry) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.AuditRepository.CreateEvents(ctx, entries)
+}
+
+// QueryEvents searches events within the operation timeout. Exports call it once per page, so
+// each page gets its own deadline.
+func (r *deadlineRepository) QueryEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, page domain.PaginationParams) ([]domain.AuditEntry, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.AuditRepository.QueryEvents(ctx, sessionID, filter, page)
+}
+
+// GetSessionStats computes session statistics within the operation timeout
+func (r *deadlineRepository) GetSessionStats(ctx context.Context, sessionID domain.SessionID) (*domain.SessionStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.AuditRepository.GetSessionStats(ctx, sessionID)
+}
+
+// GetSessionActivity reads activity rollups within the operation timeout
+func (r *deadlineRepository) GetSessionActivity(ctx context.Context, sessionID domain.SessionID, query domain.ActivityQuery) ([]domain.ActivityBucket, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.AuditRepository.GetSessionActivity(ctx, sessionID, query)
+}
+
+// GetSlideHeatmap reads the slide heatmap within the operation timeout
+func (r *deadlineRepository) GetSlideHeatmap(ctx context.Context, sessionID domain.SessionID, query domain.HeatmapQuery) ([]domain.HeatmapCell, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.AuditRepository.GetSlideHeatmap(ctx, sessionID, query)
+}
+
+// GetSessionContributors reads the contributor report within the operation timeout
+func (r *deadlineRepository) GetSessionContributors(ctx context.Context, sessionID domain.SessionID, query domain.ContributorQuery) ([]domain.ContributorStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.AuditRepository.GetSessionContributors(ctx, sessionID, query)
+}
+
+// FindChain reads a page of the hash chain within the operation timeout
+func (r *deadlineRepository) FindChain(ctx context.Context, sessionID domain.SessionID, afterSeq int64, limit int) ([]domain.ChainedEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.AuditRepository.FindChain(ctx, sessionID, afterSeq, limit)
+}
+
+// FindEvent looks up an event within the operation timeout
+func (r *deadlineRepository) FindEvent(ctx context.Context, eventID domain.EventID) (*domain.AuditEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.AuditRepository.FindEvent(ctx, eventID)
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingRepository waits for the context of each session lookup to end
+type blockingRepository struct {
+	AuditRepository
+}
+
+func (blockingRepository) GetSession(ctx context.Context, _ domain.SessionID) (*Session, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (blockingRepository) PurgeEvents(ctx context.Context, _ string, _ time.Time, _ int) ([]domain.PurgedEvents, error) {
+	if _, ok := ctx.Deadline(); ok {
+		return nil, context.DeadlineExceeded
+	}
+	return nil, nil
+}
+
+func TestDeadlineRepository(t *testing.T) {
+	repo := NewDeadlineRepository(blockingRepository{}, 10*time.Millisecond)
+
+	_, err := repo.GetSession(context.Background(), testSQLiteSession)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// A cancelled caller ends the call before the timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = repo.GetSession(ctx, testSQLiteSession)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Maintenance calls are not bounded
+	_, err = repo.PurgeEvents(context.Background(), "slide_viewed", time.Now(), 100)
+	require.NoError(t, err)
+}
+
+func TestNewDeadlineRepository_Disabled(t *testing.T) {
+	repo := blockingRepository{}
+	assert.Equal(t, AuditRepository(repo), NewDeadlineRepository(repo, 0))
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/fieldcrypt"
+	"audit-service/pkg/requestid"
+
+	"go.uber.org/zap"
+)
+
+// DetailsCipher seals and opens the details of audit entries
+type DetailsCipher interface {
+	// Encrypt seals details bound to the additional data, such as the entry ID
+	Encrypt(ctx context.Context, details, additionalData []byte) ([]byte, error)
+	// Decrypt opens sealed details and returns other details unchanged
+	Decrypt(ctx context.Context, details, additionalData []byte) ([]byte, error)
+}
+
+// encryptedRepository encrypts the details of configured event types before they are stored
+// and decrypts them for the entries returned to API readers. Entries read for hash chain
+// verification and archival keep their stored form, since their hashes cover the ciphertext.
+type encryptedRepository struct {
+	AuditRepository
+	cipher DetailsCipher
+	types  map[string]bool
+	logger *zap.Logger
+}
+
+// NewEncryptedRepository wraps a repository so the details of the given event types are
+// encrypted at rest
+func NewEncryptedRepository(repo AuditRepository, cipher DetailsCipher, types []string, logger *zap.Logger) AuditRepository {
+	encrypted := make(map[string]bool, len(types))
+	for _, eventType := range types {
+		encrypted[eventType] = true
+	}
+	return &encryptedRepository{
+		AuditRepository: repo,
+		cipher:          cipher,
+		types:           encrypted,
+		logger:          logger,
+	}
+}
+
+// CreateEvents encrypts the details of configured event types and stores the entries
+func (r *encryptedRepository) CreateEvents(ctx context.Context, entries []domain.AuditEntry) error {
+	sealed := make([]domain.AuditEntry, len(entries))
+	for i, entry := range entries {
+		sealed[i] = entry
+		// Details that are already sealed, such as entries copied from an archive, are stored as they are
+		if !r.types[entry.Type] || len(entry.Details) == 0 || fieldcrypt.IsEncrypted(entry.Details) {
+			continue
+		}
+
+		details, err := r.cipher.Encrypt(ctx, entry.Details, entryAAD(entry))
+		if err != nil {
+			return err
+		}
+		sealed[i].Details = details
+	}
+	return r.AuditRepository.CreateEvents(ctx, sealed)
+}
+
+// FindBySessionID returns a page of session entries with decrypted details
+func (r *encryptedRepository) FindBySessionID(ctx context.Context, sessionID domain.SessionID, page domain.PaginationParams) ([]domain.AuditEntry, int, error) {
+	entries, total, err := r.AuditRepository.FindBySessionID(ctx, sessionID, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	return r.open(ctx, entries), total, nil
+}
+
+// QueryEvents returns matching entries with decrypted details
+func (r *encryptedRepository) QueryEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, page domain.PaginationParams) ([]domain.AuditEntry, int, error) {
+	entries, total, err := r.AuditRepository.QueryEvents(ctx, sessionID, filter, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	return r.open(ctx, entries), total, nil
+}
+
+// FindEvent returns an entry with decrypted details
+func (r *encryptedRepository) FindEvent(ctx context.Context, eventID domain.EventID) (*domain.AuditEntry, error) {
+	entry, err := r.AuditRepository.FindEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	opened := r.open(ctx, []domain.AuditEntry{*entry})[0]
+	return &opened, nil
+}
+
+// open decrypts the details of entries in place. Entries that cannot be decrypted, for example
+// because their key was removed, are returned as stored so the rest of a history stays readable.
+func (r *encryptedRepository) open(ctx context.Context, entries []domain.AuditEntry) []domain.AuditEntry {
+	for i, entry := range entries {
+		if len(entry.Details) == 0 {
+			continue
+		}
+		details, err := r.cipher.Decrypt(ctx, entry.Details, entryAAD(entry))
+		if err != nil {
+			r.logger.Error("failed to decrypt audit entry details",
+				requestid.Field(ctx),
+				zap.String("event_id", entry.ID),
+				zap.String("type", entry.Type),
+				zap.Error(err),
+			)
+			continue
+		}
+		entries[i].Details = details
+	}
+	return entries
+}
+
+// entryAAD binds sealed details to their entry; IDs are lower-cased as the database returns them
+func entryAAD(entry domain.AuditEntry) []byte {
+	return []byte(strings.ToLower(entry.ID))
+}
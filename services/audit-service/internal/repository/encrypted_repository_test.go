@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/fieldcrypt"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestEncryptor(t *testing.T, keyID string) *fieldcrypt.Encryptor {
+	t.Helper()
+	keyring, err := fieldcrypt.NewKeyring([]fieldcrypt.Key{{ID: keyID, Secret: []byte(strings.Repeat(keyID[:1], fieldcrypt.KeySize))}})
+	require.NoError(t, err)
+	return fieldcrypt.New(keyring, time.Hour, clock.New())
+}
+
+func TestEncryptedRepository(t *testing.T) {
+	plain, _ := newTestSQLiteRepository(t)
+	repo := NewEncryptedRepository(plain, newTestEncryptor(t, "k1"), []string{"edit"}, zap.NewNop())
+	ctx := context.Background()
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	edit := sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "edit", base, `{"slideId":"slide-1","after":"Confidential"}`)
+	view := sqliteEntry("00000000-0000-0000-0000-000000000002", "user-1", "view", base.Add(time.Minute), `{"slideId":"slide-1"}`)
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{edit, view}))
+
+	// Only the configured type is sealed at rest
+	stored, _, err := plain.FindBySessionID(ctx, testSQLiteSession, domain.PaginationParams{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	assert.True(t, fieldcrypt.IsEncrypted(stored[1].Details))
+	assert.NotContains(t, string(stored[1].Details), "Confidential")
+	assert.JSONEq(t, `{"slideId":"slide-1"}`, string(stored[0].Details))
+
+	// Readers get the original details
+	entries, _, err := repo.FindBySessionID(ctx, testSQLiteSession, domain.PaginationParams{Limit: 10})
+	require.NoError(t, err)
+	assert.JSONEq(t, string(edit.Details), string(entries[1].Details))
+	entries, _, err = repo.QueryEvents(ctx, "", domain.EventFilter{Types: []string{"edit"}}, domain.PaginationParams{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.JSONEq(t, string(edit.Details), string(entries[0].Details))
+	found, err := repo.FindEvent(ctx, domain.EventID(edit.ID))
+	require.NoError(t, err)
+	assert.JSONEq(t, string(edit.Details), string(found.Details))
+
+	// The chain covers the stored ciphertext
+	chain, err := repo.FindChain(ctx, testSQLiteSession, 0, 10)
+	require.NoError(t, err)
+	verifier := domain.NewChainVerifier(testSQLiteSession)
+	for _, entry := range chain {
+		verifier.Add(entry)
+	}
+	result := verifier.Result()
+	assert.True(t, result.Valid, "issues: %v", result.Issues)
+
+	// Details sealed with a key that is no longer configured are returned as stored
+	rotatedOut := NewEncryptedRepository(plain, newTestEncryptor(t, "k2"), []string{"edit"}, zap.NewNop())
+	found, err = rotatedOut.FindEvent(ctx, domain.EventID(edit.ID))
+	require.NoError(t, err)
+	assert.True(t, fieldcrypt.IsEncrypted(found.Details))
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"audit-service/internal/domain"
+)
+
+// EventTypeRepository stores the custom event types registered through the API
+type EventTypeRepository interface {
+	// ListEventTypes returns every custom event type ordered by name
+	ListEventTypes(ctx context.Context) ([]domain.EventType, error)
+	// CreateEventType stores a custom event type, or returns domain.ErrEventTypeExists
+	CreateEventType(ctx context.Context, eventType domain.EventType) error
+}
+
+// eventTypeRow represents an audit_event_types row
+type eventTypeRow struct {
+	Name        string          `json:"name"`
+	DisplayName string          `json:"display_name"`
+	Severity    string          `json:"severity"`
+	Schema      json.RawMessage `json:"schema,omitempty"`
+	CreatedBy   string          `json:"created_by"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// newEventTypeRow converts a domain event type into a database row
+func newEventTypeRow(eventType domain.EventType) eventTypeRow {
+	row := eventTypeRow{
+		Name:        string(eventType.Name),
+		DisplayName: eventType.DisplayName,
+		Severity:    string(eventType.Severity),
+		Schema:      eventType.Schema,
+		CreatedBy:   eventType.CreatedBy,
+	}
+	if eventType.CreatedAt != nil {
+		row.CreatedAt = eventType.CreatedAt.UTC()
+	}
+	return row
+}
+
+// toEventType converts a database row into a domain event type
+func (row eventTypeRow) toEventType() domain.EventType {
+	createdAt := row.CreatedAt.UTC()
+	eventType := domain.EventType{
+		Name:        domain.AuditAction(row.Name),
+		DisplayName: row.DisplayName,
+		Severity:    domain.EventSeverity(row.Severity),
+		CreatedBy:   row.CreatedBy,
+		CreatedAt:   &createdAt,
+	}
+	// A JSON null schema is stored for types that accept any details
+	if len(row.Schema) > 0 && string(row.Schema) != "null" {
+		eventType.Schema = row.Schema
+	}
+	return eventType
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"audit-service/internal/metrics"
+)
+
+// instrumentedClient records metrics for every request made by the wrapped client
+type instrumentedClient struct {
+	client  SupabaseClientInterface
+	metrics *metrics.Metrics
+}
+
+// NewInstrumentedClient wraps a Supabase client with request metrics
+func NewInstrumentedClient(client SupabaseClientInterface, m *metrics.Metrics) SupabaseClientInterface {
+	return &instrumentedClient{
+		client:  client,
+		metrics: m,
+	}
+}
+
+// Get performs an instrumented GET request
+func (c *instrumentedClient) Get(ctx context.Context, endpoint string, query *Query) ([]byte, int, error) {
+	start := time.Now()
+	data, count, err := c.client.Get(ctx, endpoint, query)
+	c.metrics.ObserveSupabaseRequest(http.MethodGet, endpoint, requestStatus(err), time.Since(start))
+	return data, count, err
+}
+
+// Post performs an instrumented POST request
+func (c *instrumentedClient) Post(ctx context.Context, endpoint string, payload interface{}) ([]byte, error) {
+	start := time.Now()
+	data, err := c.client.Post(ctx, endpoint, payload)
+	c.metrics.ObserveSupabaseRequest(http.MethodPost, endpoint, requestStatus(err), time.Since(start))
+	return data, err
+}
+
+// requestStatus classifies a request outcome into a low-cardinality label
+func requestStatus(err error) string {
+	var supErr *SupabaseError
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.As(err, &supErr) && supErr.StatusCode != 0:
+		return strconv.Itoa(supErr.StatusCode)
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"audit-service/internal/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInstrumentedClient_RecordsRequests(t *testing.T) {
+	m := metrics.New()
+	mockClient := &MockSupabaseClient{}
+	client := NewInstrumentedClient(mockClient, m)
+
+	mockClient.On("Get", mock.Anything, "/audit_logs", mock.Anything).Return([]byte("[]"), 0, nil)
+	mockClient.On("Post", mock.Anything, "/audit_logs", mock.Anything).
+		Return([]byte{}, &SupabaseError{Message: "unavailable", StatusCode: 503})
+
+	_, _, err := client.Get(context.Background(), "/audit_logs", nil)
+	assert.NoError(t, err)
+	_, err = client.Post(context.Background(), "/audit_logs", []string{})
+	assert.Error(t, err)
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := w.Body.String()
+	assert.Contains(t, body, `audit_service_supabase_requests_total{endpoint="/audit_logs",method="GET",status="ok"} 1`)
+	assert.Contains(t, body, `audit_service_supabase_requests_total{endpoint="/audit_logs",method="POST",status="503"} 1`)
+}
+
+func TestRequestStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{name: "success", err: nil, expected: "ok"},
+		{name: "supabase error", err: fmt.Errorf("wrapped: %w", &SupabaseError{StatusCode: 409}), expected: "409"},
+		{name: "timeout", err: context.DeadlineExceeded, expected: "timeout"},
+		{name: "canceled", err: context.Canceled, expected: "canceled"},
+		{name: "other", err: errors.New("boom"), expected: "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, requestStatus(tt.err))
+		})
+	}
+}
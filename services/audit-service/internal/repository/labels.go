@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"audit-service/internal/domain"
+)
+
+// LabelRepository stores the labels reviewers keep on audit events, in a table beside the events
+// so the entries themselves stay unchanged. Lookups are scoped to the organization of ctx.
+type LabelRepository interface {
+	// GetEventLabels returns the label set of an event, or nil when the event was never labeled
+	GetEventLabels(ctx context.Context, eventID string) (*domain.EventLabels, error)
+	// SaveEventLabels stores the label set of an event at labels.Version, provided the stored set
+	// is at the version before, no set standing for version 0. It returns
+	// domain.ErrVersionConflict when another change was stored first.
+	SaveEventLabels(ctx context.Context, labels domain.EventLabels) error
+}
+
+// eventLabelsRow represents an audit_event_labels row
+type eventLabelsRow struct {
+	EventID   string    `json:"event_id"`
+	SessionID string    `json:"session_id"`
+	Labels    []string  `json:"labels"`
+	Version   int64     `json:"version"`
+	UpdatedBy string    `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Omitted, and stored as null, for labels of events of the default organization
+	OrganizationID string `json:"organization_id,omitempty"`
+}
+
+// newEventLabelsRow converts a domain label set into a database row
+func newEventLabelsRow(labels domain.EventLabels) eventLabelsRow {
+	row := eventLabelsRow{
+		EventID:   labels.EventID,
+		SessionID: labels.SessionID,
+		Labels:    labels.Labels,
+		Version:   labels.Version,
+		UpdatedBy: labels.UpdatedBy,
+
+		OrganizationID: labels.OrganizationID,
+	}
+	if row.Labels == nil {
+		row.Labels = []string{}
+	}
+	if labels.UpdatedAt != nil {
+		row.UpdatedAt = labels.UpdatedAt.UTC()
+	}
+	return row
+}
+
+// toEventLabels converts a database row into a domain label set
+func (row eventLabelsRow) toEventLabels() domain.EventLabels {
+	updatedAt := row.UpdatedAt.UTC()
+	labels := domain.EventLabels{
+		EventID:   row.EventID,
+		SessionID: row.SessionID,
+		Labels:    row.Labels,
+		Version:   row.Version,
+		UpdatedBy: row.UpdatedBy,
+		UpdatedAt: &updatedAt,
+
+		OrganizationID: row.OrganizationID,
+	}
+	if labels.Labels == nil {
+		labels.Labels = []string{}
+	}
+	return labels
+}
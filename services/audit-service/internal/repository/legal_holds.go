@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"audit-service/internal/domain"
+)
+
+// LegalHoldRepository stores the legal holds that exempt entries from retention and erasure
+type LegalHoldRepository interface {
+	// CreateLegalHold stores a new active hold
+	CreateLegalHold(ctx context.Context, hold domain.LegalHold) error
+	// ListLegalHolds returns the holds newest first, only the active ones when activeOnly is set.
+	// Like the other reads, it only returns holds of the organization ctx is scoped to.
+	ListLegalHolds(ctx context.Context, activeOnly bool) ([]domain.LegalHold, error)
+	// ReleaseLegalHold marks an active hold released and returns it. It returns
+	// domain.ErrLegalHoldNotFound for unknown IDs and domain.ErrLegalHoldReleased for released holds.
+	ReleaseLegalHold(ctx context.Context, id, releasedBy string, at time.Time) (*domain.LegalHold, error)
+}
+
+// legalHoldRow represents an audit_legal_holds row
+type legalHoldRow struct {
+	ID         string     `json:"id"`
+	Scope      string     `json:"scope"`
+	Target     string     `json:"target"`
+	Reason     string     `json:"reason"`
+	PlacedBy   string     `json:"placed_by"`
+	PlacedAt   time.Time  `json:"placed_at"`
+	ReleasedBy *string    `json:"released_by,omitempty"`
+	ReleasedAt *time.Time `json:"released_at,omitempty"`
+	// Omitted, and stored as null, for holds of the default organization
+	OrganizationID string `json:"organization_id,omitempty"`
+}
+
+// newLegalHoldRow converts a domain legal hold into a database row
+func newLegalHoldRow(hold domain.LegalHold) legalHoldRow {
+	return legalHoldRow{
+		ID:       hold.ID,
+		Scope:    string(hold.Scope),
+		Target:   hold.Target,
+		Reason:   hold.Reason,
+		PlacedBy: hold.PlacedBy,
+		PlacedAt: hold.PlacedAt.UTC(),
+
+		OrganizationID: hold.OrganizationID,
+	}
+}
+
+// toLegalHold converts a database row into a domain legal hold
+func (row legalHoldRow) toLegalHold() domain.LegalHold {
+	hold := domain.LegalHold{
+		ID:       row.ID,
+		Scope:    domain.LegalHoldScope(row.Scope),
+		Target:   row.Target,
+		Reason:   row.Reason,
+		PlacedBy: row.PlacedBy,
+		PlacedAt: row.PlacedAt.UTC(),
+
+		OrganizationID: row.OrganizationID,
+	}
+	if row.ReleasedBy != nil {
+		hold.ReleasedBy = *row.ReleasedBy
+	}
+	if row.ReleasedAt != nil {
+		releasedAt := row.ReleasedAt.UTC()
+		hold.ReleasedAt = &releasedAt
+	}
+	return hold
+}
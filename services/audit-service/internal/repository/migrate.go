@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"audit-service/migrations"
+	"audit-service/pkg/clock"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationTracker records which migrations have been applied to a database
+type migrationTracker interface {
+	// applied reports whether the migration version has run
+	applied(ctx context.Context, version string) (bool, error)
+	// apply runs a migration script and records its version atomically
+	apply(ctx context.Context, version, script string) error
+}
+
+// runMigrations applies the *.sql files of files in name order, skipping those already applied
+func runMigrations(ctx context.Context, files fs.FS, tracker migrationTracker) ([]string, error) {
+	names, err := fs.Glob(files, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	var applied []string
+	for _, name := range names {
+		version := strings.TrimSuffix(name, ".sql")
+
+		done, err := tracker.applied(ctx, version)
+		if err != nil {
+			return applied, fmt.Errorf("failed to check migration %s: %w", version, err)
+		}
+		if done {
+			continue
+		}
+
+		script, err := fs.ReadFile(files, name)
+		if err != nil {
+			return applied, err
+		}
+		if err := tracker.apply(ctx, version, string(script)); err != nil {
+			return applied, fmt.Errorf("failed to apply migration %s: %w", version, err)
+		}
+
+		applied = append(applied, version)
+	}
+
+	return applied, nil
+}
+
+// postgresTracker keeps applied migrations in the schema_migrations table of a Postgres database
+type postgresTracker struct {
+	pool  *pgxpool.Pool
+	clock clock.Clock
+}
+
+func (t postgresTracker) applied(ctx context.Context, version string) (bool, error) {
+	var exists bool
+	err := t.pool.QueryRow(ctx, "select exists(select 1 from schema_migrations where version = $1)", version).Scan(&exists)
+	return exists, err
+}
+
+func (t postgresTracker) apply(ctx context.Context, version, script string) error {
+	return pgx.BeginFunc(ctx, t.pool, func(tx pgx.Tx) error {
+		// Without arguments the script runs over the simple protocol, which allows several statements
+		if _, err := tx.Exec(ctx, script); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, "insert into schema_migrations (version, applied_at) values ($1, $2)", version, t.clock.Now().UTC())
+		return err
+	})
+}
+
+// MigratePostgres applies the pending migrations in migrations/, stamped with the time of clk,
+// and returns their versions.
+// Migrations applied by hand before are run again, which is safe as they are idempotent.
+func MigratePostgres(ctx context.Context, pool *pgxpool.Pool, clk clock.Clock) ([]string, error) {
+	if _, err := pool.Exec(ctx, `create table if not exists schema_migrations (
+		version text primary key,
+		applied_at timestamptz not null
+	)`); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	return runMigrations(ctx, migrations.Files, postgresTracker{pool: pool, clock: clk})
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"audit-service/internal/domain"
+)
+
+// OutboxRepository gives the relay worker access to audit events waiting to be forwarded
+type OutboxRepository interface {
+	// ClaimOutbox leases up to limit due messages for the given duration and counts the attempt
+	ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error)
+	// CompleteOutbox removes delivered messages
+	CompleteOutbox(ctx context.Context, ids []int64) error
+	// FailOutbox releases a message for a retry at retryAt, or for good when dead is set
+	FailOutbox(ctx context.Context, id int64, retryAt time.Time, lastError string, dead bool) error
+	// ReplayOutbox makes dead messages created at or after since due again with a fresh attempt
+	// count and returns how many were requeued; a zero since replays every dead message
+	ReplayOutbox(ctx context.Context, since time.Time) (int, error)
+	// EnqueueOutbox queues stored events for forwarding again: events without a message get one
+	// and dead messages are made due with a fresh attempt count. Messages still waiting are left
+	// alone. It returns how many were queued.
+	EnqueueOutbox(ctx context.Context, entries []domain.AuditEntry) (int, error)
+	// ListDeadOutbox returns up to limit dead messages of the organization ctx is scoped to,
+	// newest first, starting below beforeID; a zero beforeID starts with the newest
+	ListDeadOutbox(ctx context.Context, limit int, beforeID int64) ([]domain.DeadLetter, error)
+	// RetryDeadOutbox makes one dead message due again with a fresh attempt count. It returns
+	// domain.ErrDeadLetterNotFound when the organization has no dead message with the ID.
+	RetryDeadOutbox(ctx context.Context, id int64) error
+}
+
+// outboxRow represents an audit_outbox row as written together with its audit_logs row
+type outboxRow struct {
+	EventID   string          `json:"event_id"`
+	SessionID string          `json:"session_id"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// claimedOutboxRow is an audit_outbox row as returned by claim_audit_outbox
+type claimedOutboxRow struct {
+	outboxRow
+	ID        int64     `json:"id"`
+	Attempts  int       `json:"attempts"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// deadOutboxRow is an audit_outbox row as returned by list_dead_audit_outbox
+type deadOutboxRow struct {
+	claimedOutboxRow
+	LastError string    `json:"last_error"`
+	DeadAt    time.Time `json:"dead_at"`
+}
+
+// newOutboxRow converts a domain entry into the outbox message forwarding it
+func newOutboxRow(entry domain.AuditEntry) (outboxRow, error) {
+	payload, err := domain.NewOutboxPayload(entry)
+	if err != nil {
+		return outboxRow{}, fmt.Errorf("failed to prepare outbox message %s: %w", entry.ID, err)
+	}
+
+	return outboxRow{
+		EventID:   entry.ID,
+		SessionID: entry.SessionID,
+		EventType: entry.Type,
+		Payload:   payload,
+	}, nil
+}
+
+// newOutboxRows converts domain entries into the outbox messages forwarding them
+func newOutboxRows(entries []domain.AuditEntry) ([]outboxRow, error) {
+	messages := make([]outboxRow, len(entries))
+	for i, entry := range entries {
+		message, err := newOutboxRow(entry)
+		if err != nil {
+			return nil, err
+		}
+		messages[i] = message
+	}
+	return messages, nil
+}
+
+// toMessage converts a claimed row into a domain message
+func (row claimedOutboxRow) toMessage() domain.OutboxMessage {
+	return domain.OutboxMessage{
+		ID:        row.ID,
+		EventID:   row.EventID,
+		SessionID: row.SessionID,
+		Type:      row.EventType,
+		Payload:   row.Payload,
+		Attempts:  row.Attempts,
+		CreatedAt: row.CreatedAt.UTC(),
+	}
+}
+
+// parseClaimedOutbox decodes the JSON array returned by claim_audit_outbox
+func parseClaimedOutbox(data []byte) ([]domain.OutboxMessage, error) {
+	var rows []claimedOutboxRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse outbox messages: %w", err)
+	}
+
+	messages := make([]domain.OutboxMessage, len(rows))
+	for i, row := range rows {
+		messages[i] = row.toMessage()
+	}
+	return messages, nil
+}
+
+// toDeadLetter converts a dead row into a domain dead letter
+func (row deadOutboxRow) toDeadLetter() domain.DeadLetter {
+	return domain.DeadLetter{
+		ID:        row.ID,
+		EventID:   row.EventID,
+		SessionID: row.SessionID,
+		Type:      row.EventType,
+		Payload:   row.Payload,
+		Attempts:  row.Attempts,
+		LastError: row.LastError,
+		CreatedAt: row.CreatedAt.UTC(),
+		DeadAt:    row.DeadAt.UTC(),
+	}
+}
+
+// parseDeadOutbox decodes the JSON array returned by list_dead_audit_outbox
+func parseDeadOutbox(data []byte) ([]domain.DeadLetter, error) {
+	var rows []deadOutboxRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse dead outbox messages: %w", err)
+	}
+
+	letters := make([]domain.DeadLetter, len(rows))
+	for i, row := range rows {
+		letters[i] = row.toDeadLetter()
+	}
+	return letters, nil
+}
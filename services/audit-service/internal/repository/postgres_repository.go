@@ -0,0 +1,1386 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/requestid"
+	"audit-service/pkg/tenant"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// auditLogColumns selects an audit_logs row in the order scanned by scanAuditEntry
+const auditLogColumns = `id::text, session_id::text, user_id::text, type, "timestamp", details,
+	coalesce(ip_address::text, ''), coalesce(user_agent, ''), coalesce(correlation_id, ''), coalesce(parent_event_id::text, ''),
+	coalesce(schema_version, 0), redacted_fields, coalesce(organization_id, ''), coalesce(slide_id, ''), coalesce(shape_id, ''),
+	coalesce(comment_id, ''), coalesce(thread_id, ''), received_at`
+
+// postgresRepository implements the AuditRepository interface over a direct Postgres connection
+type postgresRepository struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+	// outbox makes CreateEvents queue every entry for forwarding by the relay worker
+	outbox bool
+}
+
+// NewPostgresRepository creates an audit repository that queries Postgres without the REST API
+func NewPostgresRepository(pool *pgxpool.Pool, logger *zap.Logger) AuditRepository {
+	return newPostgresRepository(pool, logger, false)
+}
+
+// newPostgresRepository creates a Postgres repository, optionally writing outbox messages with each entry
+func newPostgresRepository(pool *pgxpool.Pool, logger *zap.Logger, outbox bool) *postgresRepository {
+	return &postgresRepository{
+		pool:   pool,
+		logger: logger,
+		outbox: outbox,
+	}
+}
+
+// FindBySessionID retrieves audit logs for a specific session
+func (r *postgresRepository) FindBySessionID(ctx context.Context, sessionID domain.SessionID, page domain.PaginationParams) ([]domain.AuditEntry, int, error) {
+	return r.QueryEvents(ctx, sessionID, domain.EventFilter{}, page)
+}
+
+// QueryEvents retrieves audit logs for a session, or for all sessions when sessionID is empty, matching the given filter
+func (r *postgresRepository) QueryEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, page domain.PaginationParams) ([]domain.AuditEntry, int, error) {
+	// Test sessions have no persisted events
+	if sessionID.IsTestSession() {
+		return []domain.AuditEntry{}, 0, nil
+	}
+
+	where, args := buildEventConditions(ctx, sessionID, filter, page)
+
+	var total int
+	if err := r.pool.QueryRow(ctx, "select count(*) from audit_logs where "+where, args...).Scan(&total); err != nil {
+		r.logger.Error("failed to count audit logs",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, 0, fmt.Errorf("failed to query audit logs: %w", err)
+	}
+
+	// The id tie-breaker keeps the order stable for entries sharing a timestamp
+	query := fmt.Sprintf(`select %s from audit_logs where %s order by "timestamp" desc, id desc limit %d offset %d`,
+		auditLogColumns, where, page.Limit, page.Offset)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("failed to query audit logs",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, 0, fmt.Errorf("failed to query audit logs: %w", err)
+	}
+
+	entries, err := pgx.CollectRows(rows, scanAuditEntry)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse audit logs: %w", err)
+	}
+
+	r.logger.Debug("queried audit logs",
+		requestid.Field(ctx),
+		zap.Stringer("session_id", sessionID),
+		zap.Int("count", len(entries)),
+		zap.Int("total", total),
+	)
+
+	return entries, total, nil
+}
+
+// buildEventConditions translates a session, filter and keyset cursor into a SQL condition and its arguments.
+// An empty session ID matches every session; only entries of the organization ctx is scoped to match.
+func buildEventConditions(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, page domain.PaginationParams) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	add := func(condition string, values ...interface{}) {
+		for _, value := range values {
+			args = append(args, value)
+			condition = strings.Replace(condition, "?", fmt.Sprintf("$%d", len(args)), 1)
+		}
+		conditions = append(conditions, condition)
+	}
+
+	if sessionID != "" {
+		add("session_id = ?", sessionID.String())
+	}
+	// Entries of the default organization store no organization
+	if organizationID, ok := tenant.FromContext(ctx); ok {
+		add("coalesce(organization_id, '') = ?", organizationID)
+	}
+
+	if len(filter.Types) > 0 {
+		add("type = any(?)", filter.Types)
+	}
+	if filter.UserID != "" {
+		add("user_id = ?", filter.UserID)
+	}
+	if !filter.From.IsZero() {
+		add(`"timestamp" >= ?`, filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		add(`"timestamp" <= ?`, filter.To.UTC())
+	}
+	// Full-text search over the string values of the JSONB details column
+	if filter.Search != "" {
+		add("to_tsvector(details) @@ plainto_tsquery(?)", filter.Search)
+	}
+	if filter.CorrelationID != "" {
+		add("correlation_id = ?", filter.CorrelationID)
+	}
+	if filter.SlideID != "" {
+		add("slide_id = ?", filter.SlideID)
+	}
+	if filter.ShapeID != "" {
+		add("shape_id = ?", filter.ShapeID)
+	}
+	if filter.CommentID != "" {
+		add("comment_id = ?", filter.CommentID)
+	}
+	if filter.ThreadID != "" {
+		add("thread_id = ?", filter.ThreadID)
+	}
+	if len(filter.Labels) > 0 {
+		add("id in (select event_id from audit_event_labels where labels && ?)", filter.Labels)
+	}
+
+	// Keyset condition: strictly older than the cursor, or same timestamp with a lower id
+	if page.Cursor != nil {
+		add(`("timestamp", id) < (?, ?::uuid)`, page.Cursor.Timestamp.UTC(), page.Cursor.ID)
+	}
+
+	if len(conditions) == 0 {
+		return "true", args
+	}
+	return strings.Join(conditions, " and "), args
+}
+
+// scanAuditEntry reads a row selected with auditLogColumns
+func scanAuditEntry(row pgx.CollectableRow) (domain.AuditEntry, error) {
+	var entry domain.AuditEntry
+	err := row.Scan(
+		&entry.ID,
+		&entry.SessionID,
+		&entry.UserID,
+		&entry.Type,
+		&entry.Timestamp,
+		&entry.Details,
+		&entry.IPAddress,
+		&entry.UserAgent,
+		&entry.CorrelationID,
+		&entry.ParentEventID,
+		&entry.SchemaVersion,
+		&entry.RedactedFields,
+		&entry.OrganizationID,
+		&entry.SlideID,
+		&entry.ShapeID,
+		&entry.CommentID,
+		&entry.ThreadID,
+		&entry.ReceivedAt,
+	)
+	entry.Timestamp = entry.Timestamp.UTC()
+	entry.ReceivedAt = utcTime(entry.ReceivedAt)
+	return entry, err
+}
+
+// GetSessionStats aggregates the audit activity of a session in the database
+func (r *postgresRepository) GetSessionStats(ctx context.Context, sessionID domain.SessionID) (*domain.SessionStats, error) {
+	stats := &domain.SessionStats{
+		SessionID:     sessionID.String(),
+		ByType:        map[string]int{},
+		EditsPerSlide: map[string]int{},
+	}
+
+	// Test sessions have no persisted events
+	if sessionID.IsTestSession() {
+		return stats, nil
+	}
+
+	// Aggregates run in the session_audit_stats function (migrations/013_audit_log_organizations.sql)
+	var data []byte
+	if err := r.pool.QueryRow(ctx, "select public.session_audit_stats($1, $2::text)", sessionID.String(), organizationArg(ctx)).Scan(&data); err != nil {
+		r.logger.Error("failed to fetch session stats",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch session stats: %w", err)
+	}
+
+	var row sessionStatsRow
+	if err := json.Unmarshal(data, &row); err != nil {
+		return nil, fmt.Errorf("failed to parse session stats: %w", err)
+	}
+	row.apply(stats)
+
+	return stats, nil
+}
+
+// PurgeEvents deletes up to limit events of a type recorded before the cutoff
+func (r *postgresRepository) PurgeEvents(ctx context.Context, eventType string, before time.Time, limit int) ([]domain.PurgedEvents, error) {
+	// Deletion runs in the purge_audit_logs function (migrations/002_purge_audit_logs.sql)
+	purged, err := r.callCounts(ctx, "select public.purge_audit_logs($1, $2, $3)", eventType, before.UTC(), limit)
+	if err != nil {
+		r.logger.Error("failed to purge audit logs",
+			requestid.Field(ctx),
+			zap.String("type", eventType),
+			zap.Time("before", before),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to purge audit logs: %w", err)
+	}
+
+	return purged, nil
+}
+
+// FindChain returns up to limit chained entries of a session with a sequence number above afterSeq
+func (r *postgresRepository) FindChain(ctx context.Context, sessionID domain.SessionID, afterSeq int64, limit int) ([]domain.ChainedEntry, error) {
+	// Test sessions have no persisted events
+	if sessionID.IsTestSession() {
+		return []domain.ChainedEntry{}, nil
+	}
+
+	rows, err := r.pool.Query(ctx, `select `+auditLogColumns+`,
+		seq, coalesce(content_hash, ''), coalesce(prev_hash, ''), coalesce(chain_hash, ''), redacted_at
+		from audit_logs
+		where session_id = $1 and seq > $2 and ($4::text is null or coalesce(organization_id, '') = $4)
+		order by seq asc
+		limit $3`, sessionID.String(), afterSeq, limit, organizationArg(ctx))
+	if err != nil {
+		r.logger.Error("failed to fetch audit log chain",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch audit log chain: %w", err)
+	}
+
+	entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.ChainedEntry, error) {
+		var entry domain.ChainedEntry
+		err := row.Scan(
+			&entry.ID,
+			&entry.SessionID,
+			&entry.UserID,
+			&entry.Type,
+			&entry.Timestamp,
+			&entry.Details,
+			&entry.IPAddress,
+			&entry.UserAgent,
+			&entry.CorrelationID,
+			&entry.ParentEventID,
+			&entry.SchemaVersion,
+			&entry.RedactedFields,
+			&entry.OrganizationID,
+			&entry.SlideID,
+			&entry.ShapeID,
+			&entry.CommentID,
+			&entry.ThreadID,
+			&entry.ReceivedAt,
+			&entry.Seq,
+			&entry.ContentHash,
+			&entry.PrevHash,
+			&entry.ChainHash,
+			&entry.RedactedAt,
+		)
+		entry.Timestamp = entry.Timestamp.UTC()
+		entry.ReceivedAt = utcTime(entry.ReceivedAt)
+		return entry, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse audit log chain: %w", err)
+	}
+
+	return entries, nil
+}
+
+// FindEvent returns the event with the given ID
+func (r *postgresRepository) FindEvent(ctx context.Context, eventID domain.EventID) (*domain.AuditEntry, error) {
+	// Events of other organizations are reported as not found
+	rows, err := r.pool.Query(ctx, `select `+auditLogColumns+` from audit_logs
+		where id = $1 and ($2::text is null or coalesce(organization_id, '') = $2)`, eventID.String(), organizationArg(ctx))
+	if err != nil {
+		r.logger.Error("failed to fetch audit log",
+			requestid.Field(ctx),
+			zap.Stringer("event_id", eventID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch audit log: %w", err)
+	}
+
+	entry, err := pgx.CollectExactlyOneRow(rows, scanAuditEntry)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrEventNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse audit log: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// ExistingEventIDs returns those of the IDs that are already stored, in any organization
+func (r *postgresRepository) ExistingEventIDs(ctx context.Context, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	rows, err := r.pool.Query(ctx, `select id::text from audit_logs where id = any($1::text[]::uuid[])`, ids)
+	if err != nil {
+		r.logger.Error("failed to look up audit log IDs",
+			requestid.Field(ctx),
+			zap.Int("count", len(ids)),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to look up audit log IDs: %w", err)
+	}
+
+	existing, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse audit log IDs: %w", err)
+	}
+	return existing, nil
+}
+
+// FindExpiredEvents returns up to limit of the oldest events of a type recorded before the cutoff
+// that are not under a legal hold
+func (r *postgresRepository) FindExpiredEvents(ctx context.Context, eventType string, before time.Time, limit int) ([]domain.AuditEntry, error) {
+	rows, err := r.pool.Query(ctx, `select `+auditLogColumns+`
+		from audit_logs
+		where type = $1 and "timestamp" < $2 and not public.audit_log_on_hold(session_id, user_id)
+		order by "timestamp" asc, id asc
+		limit $3`, eventType, before.UTC(), limit)
+	if err != nil {
+		r.logger.Error("failed to fetch expired audit logs",
+			requestid.Field(ctx),
+			zap.String("type", eventType),
+			zap.Time("before", before),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch expired audit logs: %w", err)
+	}
+
+	entries, err := pgx.CollectRows(rows, scanAuditEntry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse expired audit logs: %w", err)
+	}
+
+	return entries, nil
+}
+
+// DeleteEvents deletes the events with the given IDs
+func (r *postgresRepository) DeleteEvents(ctx context.Context, ids []string) ([]domain.PurgedEvents, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	// Deletion runs in the delete_audit_logs function (migrations/003_delete_audit_logs.sql)
+	deleted, err := r.callCounts(ctx, "select public.delete_audit_logs($1::text[]::uuid[])", ids)
+	if err != nil {
+		r.logger.Error("failed to delete audit logs",
+			requestid.Field(ctx),
+			zap.Int("count", len(ids)),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to delete audit logs: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// EraseUserEvents anonymizes or deletes up to limit entries of a user across all sessions
+func (r *postgresRepository) EraseUserEvents(ctx context.Context, userID domain.UserID, mode domain.ErasureMode, overrideHolds bool, limit int) ([]domain.PurgedEvents, error) {
+	// Erasure runs in the erase_user_audit_logs function (migrations/013_audit_log_organizations.sql)
+	affected, err := r.callCounts(ctx, "select public.erase_user_audit_logs($1, $2, $3, $4, $5::text)",
+		userID.String(), string(mode), limit, overrideHolds, organizationArg(ctx))
+	if err != nil {
+		r.logger.Error("failed to erase user audit logs",
+			requestid.Field(ctx),
+			zap.String("mode", string(mode)),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to erase user audit logs: %w", err)
+	}
+
+	return affected, nil
+}
+
+// callCounts runs a function returning per-session counts as a JSON array
+func (r *postgresRepository) callCounts(ctx context.Context, query string, args ...interface{}) ([]domain.PurgedEvents, error) {
+	var data []byte
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&data); err != nil {
+		return nil, err
+	}
+
+	var counts []domain.PurgedEvents
+	if err := json.Unmarshal(data, &counts); err != nil {
+		return nil, fmt.Errorf("failed to parse result: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetSession retrieves session information
+func (r *postgresRepository) GetSession(ctx context.Context, sessionID domain.SessionID) (*Session, error) {
+	var session Session
+	err := r.pool.QueryRow(ctx, "select id::text, user_id::text from sessions where id = $1", sessionID.String()).
+		Scan(&session.ID, &session.UserID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrSessionNotFound
+	}
+	if err != nil {
+		r.logger.Error("failed to fetch session",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// GetActiveShare returns the unrevoked share link issued with the token ID for a session
+func (r *postgresRepository) GetActiveShare(ctx context.Context, tokenJTI string, sessionID domain.SessionID) (*domain.Share, error) {
+	var row shareRow
+	err := r.pool.QueryRow(ctx, `select id::text, session_id::text, share_token_jti, permissions::text[], expires_at
+		from session_shares
+		where share_token_jti = $1 and session_id = $2 and revoked_at is null
+		limit 1`, tokenJTI, sessionID.String()).
+		Scan(&row.ID, &row.SessionID, &row.ShareTokenJTI, &row.Permissions, &row.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrShareNotFound
+	}
+	if err != nil {
+		r.logger.Error("failed to look up share link",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to look up share link: %w", err)
+	}
+
+	return row.toShare(), nil
+}
+
+// CreateEvents inserts audit entries in a single transaction
+func (r *postgresRepository) CreateEvents(ctx context.Context, entries []domain.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := checkOrganization(ctx, entries); err != nil {
+		return err
+	}
+
+	batch := &pgx.Batch{}
+	for _, entry := range entries {
+		row, err := newAuditLogRow(entry)
+		if err != nil {
+			return fmt.Errorf("failed to prepare audit log %s: %w", entry.ID, err)
+		}
+
+		var details interface{}
+		if len(row.Details) > 0 {
+			details = string(row.Details)
+		}
+
+		batch.Queue(`insert into audit_logs
+			(id, session_id, user_id, type, "timestamp", details, ip_address, user_agent, content_hash,
+				correlation_id, parent_event_id, schema_version, redacted_fields, organization_id, slide_id, shape_id,
+				comment_id, thread_id, received_at)
+			values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)`,
+			row.ID, row.SessionID, row.UserID, row.Type, row.Timestamp, details,
+			nullIfEmpty(row.IPAddress), nullIfEmpty(row.UserAgent), row.ContentHash,
+			nullIfEmpty(row.CorrelationID), nullIfEmpty(row.ParentEventID), nullIfZero(row.SchemaVersion),
+			nullIfNone(row.RedactedFields), nullIfEmpty(row.OrganizationID), nullIfEmpty(row.SlideID), nullIfEmpty(row.ShapeID),
+			nullIfEmpty(row.CommentID), nullIfEmpty(row.ThreadID), nullIfEmpty(row.ReceivedAt))
+
+		if r.outbox {
+			message, err := newOutboxRow(entry)
+			if err != nil {
+				return err
+			}
+			batch.Queue(`insert into audit_outbox (event_id, session_id, event_type, payload)
+				values ($1, $2, $3, $4)
+				on conflict (event_id) do nothing`,
+				message.EventID, message.SessionID, message.EventType, string(message.Payload))
+		}
+	}
+
+	// Either every entry of the batch is stored or none is
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	if err != nil {
+		r.logger.Error("failed to insert audit logs",
+			requestid.Field(ctx),
+			zap.Int("count", len(entries)),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to insert audit logs: %w", err)
+	}
+
+	r.logger.Debug("inserted audit logs",
+		requestid.Field(ctx),
+		zap.Int("count", len(entries)),
+	)
+
+	return nil
+}
+
+// ClaimOutbox leases up to limit due messages for the given duration and counts the attempt
+func (r *postgresRepository) ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error) {
+	var data []byte
+	err := r.pool.QueryRow(ctx, "select public.claim_audit_outbox($1, $2)", limit, int(lease.Seconds())).Scan(&data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+
+	return parseClaimedOutbox(data)
+}
+
+// CompleteOutbox removes delivered messages
+func (r *postgresRepository) CompleteOutbox(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if _, err := r.pool.Exec(ctx, "select public.complete_audit_outbox($1)", ids); err != nil {
+		return fmt.Errorf("failed to complete outbox messages: %w", err)
+	}
+	return nil
+}
+
+// FailOutbox releases a message for a retry at retryAt, or for good when dead is set
+func (r *postgresRepository) FailOutbox(ctx context.Context, id int64, retryAt time.Time, lastError string, dead bool) error {
+	if _, err := r.pool.Exec(ctx, "select public.fail_audit_outbox($1, $2, $3, $4)", id, retryAt.UTC(), lastError, dead); err != nil {
+		return fmt.Errorf("failed to release outbox message %d: %w", id, err)
+	}
+	return nil
+}
+
+// ReplayOutbox makes dead messages created at or after since due again
+func (r *postgresRepository) ReplayOutbox(ctx context.Context, since time.Time) (int, error) {
+	var replayed int
+	if err := r.pool.QueryRow(ctx, "select public.replay_audit_outbox($1)", nullIfZeroTime(since)).Scan(&replayed); err != nil {
+		return 0, fmt.Errorf("failed to replay outbox messages: %w", err)
+	}
+	return replayed, nil
+}
+
+// EnqueueOutbox queues stored events for forwarding again
+func (r *postgresRepository) EnqueueOutbox(ctx context.Context, entries []domain.AuditEntry) (int, error) {
+	if len(entries) == 0 {
+		return 0, nil
+	}
+	messages, err := newOutboxRows(entries)
+	if err != nil {
+		return 0, err
+	}
+	encoded, err := json.Marshal(messages)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode outbox messages: %w", err)
+	}
+
+	var queued int
+	if err := r.pool.QueryRow(ctx, "select public.enqueue_audit_outbox($1)", string(encoded)).Scan(&queued); err != nil {
+		return 0, fmt.Errorf("failed to enqueue outbox messages: %w", err)
+	}
+	return queued, nil
+}
+
+// ListDeadOutbox returns dead messages newest first
+func (r *postgresRepository) ListDeadOutbox(ctx context.Context, limit int, beforeID int64) ([]domain.DeadLetter, error) {
+	var beforeArg interface{}
+	if beforeID > 0 {
+		beforeArg = beforeID
+	}
+
+	var data []byte
+	err := r.pool.QueryRow(ctx, "select public.list_dead_audit_outbox($1, $2, $3)", limit, beforeArg, organizationArg(ctx)).Scan(&data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead outbox messages: %w", err)
+	}
+
+	return parseDeadOutbox(data)
+}
+
+// RetryDeadOutbox makes one dead message due again
+func (r *postgresRepository) RetryDeadOutbox(ctx context.Context, id int64) error {
+	var retried bool
+	if err := r.pool.QueryRow(ctx, "select public.retry_dead_audit_outbox($1, $2)", id, organizationArg(ctx)).Scan(&retried); err != nil {
+		return fmt.Errorf("failed to retry outbox message %d: %w", id, err)
+	}
+	if !retried {
+		return domain.ErrDeadLetterNotFound
+	}
+	return nil
+}
+
+// nullIfEmpty stores empty optional columns as NULL, matching the omitted JSON fields of the REST API
+func nullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// nullIfZero stores unset numeric columns as NULL, like nullIfEmpty
+func nullIfZero(value int) interface{} {
+	if value == 0 {
+		return nil
+	}
+	return value
+}
+
+// nullIfZeroTime stores unset timestamps as NULL, like nullIfEmpty
+func nullIfZeroTime(value time.Time) interface{} {
+	if value.IsZero() {
+		return nil
+	}
+	return value.UTC()
+}
+
+// utcTime converts an optional scanned time to UTC
+func utcTime(value *time.Time) *time.Time {
+	if value == nil {
+		return nil
+	}
+	utc := value.UTC()
+	return &utc
+}
+
+// nullIfNone stores unset array columns as NULL, like nullIfEmpty
+func nullIfNone(values []string) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// ListEventTypes returns every custom event type ordered by name
+func (r *postgresRepository) ListEventTypes(ctx context.Context) ([]domain.EventType, error) {
+	rows, err := r.pool.Query(ctx, `select name, display_name, severity, schema, created_by, created_at
+		from audit_event_types
+		order by name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch event types: %w", err)
+	}
+
+	types, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.EventType, error) {
+		var et eventTypeRow
+		var schema []byte
+		if err := row.Scan(&et.Name, &et.DisplayName, &et.Severity, &schema, &et.CreatedBy, &et.CreatedAt); err != nil {
+			return domain.EventType{}, err
+		}
+		et.Schema = schema
+		return et.toEventType(), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse event types: %w", err)
+	}
+	return types, nil
+}
+
+// CreateEventType stores a custom event type, or returns domain.ErrEventTypeExists
+func (r *postgresRepository) CreateEventType(ctx context.Context, eventType domain.EventType) error {
+	row := newEventTypeRow(eventType)
+	var schema interface{}
+	if len(row.Schema) > 0 {
+		schema = string(row.Schema)
+	}
+
+	tag, err := r.pool.Exec(ctx, `insert into audit_event_types (name, display_name, severity, schema, created_by, created_at)
+		values ($1, $2, $3, $4, $5, $6)
+		on conflict (name) do nothing`,
+		row.Name, row.DisplayName, row.Severity, schema, row.CreatedBy, row.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert event type: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrEventTypeExists, eventType.Name)
+	}
+	return nil
+}
+
+// legalHoldSelect selects the columns scanned by scanLegalHold
+const legalHoldSelect = `select id::text, scope, target, reason, placed_by, placed_at, released_by, released_at,
+	coalesce(organization_id, '') from audit_legal_holds`
+
+// scanLegalHold reads a row selected with legalHoldSelect
+func scanLegalHold(row pgx.Row) (domain.LegalHold, error) {
+	var lh legalHoldRow
+	if err := row.Scan(&lh.ID, &lh.Scope, &lh.Target, &lh.Reason, &lh.PlacedBy, &lh.PlacedAt, &lh.ReleasedBy, &lh.ReleasedAt,
+		&lh.OrganizationID); err != nil {
+		return domain.LegalHold{}, err
+	}
+	return lh.toLegalHold(), nil
+}
+
+// CreateLegalHold stores a new active hold
+func (r *postgresRepository) CreateLegalHold(ctx context.Context, hold domain.LegalHold) error {
+	row := newLegalHoldRow(hold)
+	if _, err := r.pool.Exec(ctx, `insert into audit_legal_holds (id, scope, target, reason, placed_by, placed_at, organization_id)
+		values ($1, $2, $3, $4, $5, $6, $7)`,
+		row.ID, row.Scope, row.Target, row.Reason, row.PlacedBy, row.PlacedAt, nullIfEmpty(row.OrganizationID)); err != nil {
+		return fmt.Errorf("failed to insert legal hold: %w", err)
+	}
+	return nil
+}
+
+// ListLegalHolds returns the holds newest first, only the active ones when activeOnly is set
+func (r *postgresRepository) ListLegalHolds(ctx context.Context, activeOnly bool) ([]domain.LegalHold, error) {
+	rows, err := r.pool.Query(ctx, legalHoldSelect+`
+		where (not $1 or released_at is null)
+			and ($2::text is null or coalesce(organization_id, '') = $2)
+		order by placed_at desc, id`, activeOnly, organizationArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch legal holds: %w", err)
+	}
+
+	holds, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.LegalHold, error) {
+		return scanLegalHold(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse legal holds: %w", err)
+	}
+	return holds, nil
+}
+
+// ReleaseLegalHold marks an active hold released and returns it
+func (r *postgresRepository) ReleaseLegalHold(ctx context.Context, id, releasedBy string, at time.Time) (*domain.LegalHold, error) {
+	hold, err := scanLegalHold(r.pool.QueryRow(ctx, `select id::text, scope, target, reason, placed_by, placed_at, released_by, released_at,
+		coalesce(organization_id, '') from public.release_audit_legal_hold($1, $2, $3, $4::text)`,
+		id, releasedBy, at.UTC(), organizationArg(ctx)))
+	if err == nil {
+		return &hold, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to release legal hold: %w", err)
+	}
+
+	// Nothing was updated: the hold is unknown or was released before
+	var exists bool
+	if err := r.pool.QueryRow(ctx, `select exists (select 1 from audit_legal_holds
+		where id = $1 and ($2::text is null or coalesce(organization_id, '') = $2))`, id, organizationArg(ctx)).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to fetch legal hold: %w", err)
+	}
+	if !exists {
+		return nil, domain.ErrLegalHoldNotFound
+	}
+	return nil, domain.ErrLegalHoldReleased
+}
+
+// revocationSelect selects the columns scanned by scanRevocation
+const revocationSelect = `select id::text, kind, target, reason, revoked_by, revoked_at, expires_at, lifted_by, lifted_at,
+	coalesce(organization_id, '') from audit_token_revocations`
+
+// scanRevocation reads a row selected with revocationSelect
+func scanRevocation(row pgx.Row) (domain.Revocation, error) {
+	var rr revocationRow
+	if err := row.Scan(&rr.ID, &rr.Kind, &rr.Target, &rr.Reason, &rr.RevokedBy, &rr.RevokedAt, &rr.ExpiresAt, &rr.LiftedBy,
+		&rr.LiftedAt, &rr.OrganizationID); err != nil {
+		return domain.Revocation{}, err
+	}
+	return rr.toRevocation(), nil
+}
+
+// CreateRevocation stores a new revocation
+func (r *postgresRepository) CreateRevocation(ctx context.Context, revocation domain.Revocation) error {
+	row := newRevocationRow(revocation)
+	if _, err := r.pool.Exec(ctx, `insert into audit_token_revocations (id, kind, target, reason, revoked_by, revoked_at, expires_at,
+		organization_id) values ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		row.ID, row.Kind, row.Target, row.Reason, row.RevokedBy, row.RevokedAt, row.ExpiresAt, nullIfEmpty(row.OrganizationID)); err != nil {
+		return fmt.Errorf("failed to insert revocation: %w", err)
+	}
+	return nil
+}
+
+// ListRevocations returns the revocations newest first, only the ones not lifted when notLiftedOnly is set
+func (r *postgresRepository) ListRevocations(ctx context.Context, notLiftedOnly bool) ([]domain.Revocation, error) {
+	rows, err := r.pool.Query(ctx, revocationSelect+`
+		where (not $1 or lifted_at is null)
+			and ($2::text is null or coalesce(organization_id, '') = $2)
+		order by revoked_at desc, id`, notLiftedOnly, organizationArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch revocations: %w", err)
+	}
+
+	revocations, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Revocation, error) {
+		return scanRevocation(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse revocations: %w", err)
+	}
+	return revocations, nil
+}
+
+// LiftRevocation marks a revocation lifted and returns it
+func (r *postgresRepository) LiftRevocation(ctx context.Context, id, liftedBy string, at time.Time) (*domain.Revocation, error) {
+	revocation, err := scanRevocation(r.pool.QueryRow(ctx, `select id::text, kind, target, reason, revoked_by, revoked_at, expires_at,
+		lifted_by, lifted_at, coalesce(organization_id, '') from public.lift_audit_token_revocation($1, $2, $3, $4::text)`,
+		id, liftedBy, at.UTC(), organizationArg(ctx)))
+	if err == nil {
+		return &revocation, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to lift revocation: %w", err)
+	}
+
+	// Nothing was updated: the revocation is unknown or was lifted before
+	var exists bool
+	if err := r.pool.QueryRow(ctx, `select exists (select 1 from audit_token_revocations
+		where id = $1 and ($2::text is null or coalesce(organization_id, '') = $2))`, id, organizationArg(ctx)).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to fetch revocation: %w", err)
+	}
+	if !exists {
+		return nil, domain.ErrRevocationNotFound
+	}
+	return nil, domain.ErrRevocationLifted
+}
+
+// watchSelectColumns selects the columns scanned by scanWatch
+const watchSelectColumns = `session_id::text, user_id, event_types, created_at, coalesce(organization_id, '')`
+
+// scanWatch reads a row selected with watchSelectColumns
+func scanWatch(row pgx.Row) (domain.Watch, error) {
+	var wr watchRow
+	if err := row.Scan(&wr.SessionID, &wr.UserID, &wr.EventTypes, &wr.CreatedAt, &wr.OrganizationID); err != nil {
+		return domain.Watch{}, err
+	}
+	return wr.toWatch(), nil
+}
+
+// PutWatch creates or replaces the watch of a user on a session
+func (r *postgresRepository) PutWatch(ctx context.Context, watch domain.Watch) (*domain.Watch, error) {
+	row := newWatchRow(watch)
+	stored, err := scanWatch(r.pool.QueryRow(ctx, `select `+watchSelectColumns+`
+		from public.put_audit_session_watch($1, $2, $3, $4, $5::text)`,
+		row.SessionID, row.UserID, row.EventTypes, row.CreatedAt, nullIfEmpty(row.OrganizationID)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to store watch: %w", err)
+	}
+	return &stored, nil
+}
+
+// GetWatch returns the watch of a user on a session
+func (r *postgresRepository) GetWatch(ctx context.Context, sessionID, userID string) (*domain.Watch, error) {
+	watch, err := scanWatch(r.pool.QueryRow(ctx, `select `+watchSelectColumns+` from audit_session_watches
+		where session_id = $1 and user_id = $2`, sessionID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrWatchNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch watch: %w", err)
+	}
+	return &watch, nil
+}
+
+// DeleteWatch removes the watch of a user on a session
+func (r *postgresRepository) DeleteWatch(ctx context.Context, sessionID, userID string) error {
+	tag, err := r.pool.Exec(ctx, `delete from audit_session_watches where session_id = $1 and user_id = $2`, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete watch: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWatchNotFound
+	}
+	return nil
+}
+
+// ListUserWatches returns the watches of a user, newest first
+func (r *postgresRepository) ListUserWatches(ctx context.Context, userID string) ([]domain.Watch, error) {
+	return r.listWatches(ctx, `where user_id = $1 order by created_at desc, session_id`, userID)
+}
+
+// ListSessionWatches returns the watches on a session
+func (r *postgresRepository) ListSessionWatches(ctx context.Context, sessionID string) ([]domain.Watch, error) {
+	return r.listWatches(ctx, `where session_id = $1 order by user_id`, sessionID)
+}
+
+// listWatches returns the watches selected by the where and order clause
+func (r *postgresRepository) listWatches(ctx context.Context, clause, arg string) ([]domain.Watch, error) {
+	rows, err := r.pool.Query(ctx, `select `+watchSelectColumns+` from audit_session_watches `+clause, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch watches: %w", err)
+	}
+
+	watches, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Watch, error) {
+		return scanWatch(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse watches: %w", err)
+	}
+	return watches, nil
+}
+
+// reportSelectColumns selects the columns scanned by scanReport
+const reportSelectColumns = `id::text, name, kind, session_id::text, schedule, format, delivery, target, created_by, created_at,
+	next_run_at, last_run_at, coalesce(organization_id, '')`
+
+// scanReport reads a row selected with reportSelectColumns
+func scanReport(row pgx.Row) (domain.Report, error) {
+	var rr reportRow
+	if err := row.Scan(&rr.ID, &rr.Name, &rr.Kind, &rr.SessionID, &rr.Schedule, &rr.Format, &rr.Delivery, &rr.Target,
+		&rr.CreatedBy, &rr.CreatedAt, &rr.NextRunAt, &rr.LastRunAt, &rr.OrganizationID); err != nil {
+		return domain.Report{}, err
+	}
+	return rr.toReport(), nil
+}
+
+// CreateReport stores a new report
+func (r *postgresRepository) CreateReport(ctx context.Context, report domain.Report) error {
+	row := newReportRow(report)
+	if _, err := r.pool.Exec(ctx, `insert into audit_reports (id, name, kind, session_id, schedule, format, delivery, target,
+		created_by, created_at, next_run_at, last_run_at, organization_id) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		row.ID, row.Name, row.Kind, row.SessionID, row.Schedule, row.Format, row.Delivery, row.Target, row.CreatedBy, row.CreatedAt,
+		row.NextRunAt, row.LastRunAt, nullIfEmpty(row.OrganizationID)); err != nil {
+		return fmt.Errorf("failed to insert report: %w", err)
+	}
+	return nil
+}
+
+// GetReport returns a report of the organization ctx is scoped to
+func (r *postgresRepository) GetReport(ctx context.Context, id string) (*domain.Report, error) {
+	report, err := scanReport(r.pool.QueryRow(ctx, `select `+reportSelectColumns+` from audit_reports
+		where id = $1 and ($2::text is null or coalesce(organization_id, '') = $2)`, id, organizationArg(ctx)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrReportNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch report: %w", err)
+	}
+	return &report, nil
+}
+
+// ListReports returns the reports of the organization ctx is scoped to by name
+func (r *postgresRepository) ListReports(ctx context.Context) ([]domain.Report, error) {
+	return r.listReports(ctx, `where ($1::text is null or coalesce(organization_id, '') = $1) order by name, id`,
+		organizationArg(ctx))
+}
+
+// DeleteReport removes a report with its runs
+func (r *postgresRepository) DeleteReport(ctx context.Context, id string) error {
+	tag, err := r.pool.Exec(ctx, `delete from audit_reports where id = $1 and ($2::text is null or coalesce(organization_id, '') = $2)`,
+		id, organizationArg(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to delete report: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrReportNotFound
+	}
+	return nil
+}
+
+// ListDueReports returns the reports of every organization due at the given time
+func (r *postgresRepository) ListDueReports(ctx context.Context, at time.Time, limit int) ([]domain.Report, error) {
+	return r.listReports(ctx, `where next_run_at <= $1 order by next_run_at, id limit $2`, at.UTC(), limit)
+}
+
+// listReports returns the reports selected by the where and order clause
+func (r *postgresRepository) listReports(ctx context.Context, clause string, args ...interface{}) ([]domain.Report, error) {
+	rows, err := r.pool.Query(ctx, `select `+reportSelectColumns+` from audit_reports `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reports: %w", err)
+	}
+
+	reports, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Report, error) {
+		return scanReport(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse reports: %w", err)
+	}
+	return reports, nil
+}
+
+// AdvanceReport claims the run of a report due at scheduledAt
+func (r *postgresRepository) AdvanceReport(ctx context.Context, id string, scheduledAt, nextRunAt time.Time) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `update audit_reports set last_run_at = $2, next_run_at = $3
+		where id = $1 and next_run_at = $2`, id, scheduledAt.UTC(), nextRunAt.UTC())
+	if err != nil {
+		return false, fmt.Errorf("failed to advance report: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// CreateReportRun records a run of a report
+func (r *postgresRepository) CreateReportRun(ctx context.Context, run domain.ReportRun) error {
+	row := newReportRunRow(run)
+	if _, err := r.pool.Exec(ctx, `insert into audit_report_runs (id, report_id, triggered_by, status, period_from, period_to,
+		started_at, finished_at, row_count, location, error, organization_id) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		row.ID, row.ReportID, row.TriggeredBy, row.Status, row.PeriodFrom, row.PeriodTo, row.StartedAt, row.FinishedAt, row.RowCount,
+		row.Location, row.Error, nullIfEmpty(row.OrganizationID)); err != nil {
+		return fmt.Errorf("failed to insert report run: %w", err)
+	}
+	return nil
+}
+
+// ListReportRuns returns the latest runs of a report, newest first
+func (r *postgresRepository) ListReportRuns(ctx context.Context, reportID string, limit int) ([]domain.ReportRun, error) {
+	rows, err := r.pool.Query(ctx, `select id::text, report_id::text, triggered_by, status, period_from, period_to, started_at,
+		finished_at, row_count, location, error, coalesce(organization_id, '')
+		from audit_report_runs where report_id = $1 order by started_at desc, id limit $2`, reportID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch report runs: %w", err)
+	}
+
+	runs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.ReportRun, error) {
+		var rr reportRunRow
+		if err := row.Scan(&rr.ID, &rr.ReportID, &rr.TriggeredBy, &rr.Status, &rr.PeriodFrom, &rr.PeriodTo, &rr.StartedAt,
+			&rr.FinishedAt, &rr.RowCount, &rr.Location, &rr.Error, &rr.OrganizationID); err != nil {
+			return domain.ReportRun{}, err
+		}
+		return rr.toReportRun(), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse report runs: %w", err)
+	}
+	return runs, nil
+}
+
+// savedQuerySelectColumns selects the columns scanned by scanSavedQuery
+const savedQuerySelectColumns = `id::text, name, description, scope, filter, created_by, created_at, updated_at,
+	coalesce(organization_id, '')`
+
+// scanSavedQuery reads a row selected with savedQuerySelectColumns
+func scanSavedQuery(row pgx.Row) (domain.SavedQuery, error) {
+	var qr savedQueryRow
+	if err := row.Scan(&qr.ID, &qr.Name, &qr.Description, &qr.Scope, &qr.Filter, &qr.CreatedBy, &qr.CreatedAt, &qr.UpdatedAt,
+		&qr.OrganizationID); err != nil {
+		return domain.SavedQuery{}, err
+	}
+	return qr.toSavedQuery(), nil
+}
+
+// CreateSavedQuery stores a new saved query
+func (r *postgresRepository) CreateSavedQuery(ctx context.Context, query domain.SavedQuery) error {
+	row := newSavedQueryRow(query)
+	if _, err := r.pool.Exec(ctx, `insert into audit_saved_queries (id, name, description, scope, filter, created_by, created_at,
+		updated_at, organization_id) values ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		row.ID, row.Name, row.Description, row.Scope, row.Filter, row.CreatedBy, row.CreatedAt, row.UpdatedAt,
+		nullIfEmpty(row.OrganizationID)); err != nil {
+		return fmt.Errorf("failed to insert saved query: %w", err)
+	}
+	return nil
+}
+
+// GetSavedQuery returns a saved query of the organization ctx is scoped to
+func (r *postgresRepository) GetSavedQuery(ctx context.Context, id string) (*domain.SavedQuery, error) {
+	query, err := scanSavedQuery(r.pool.QueryRow(ctx, `select `+savedQuerySelectColumns+` from audit_saved_queries
+		where id = $1 and ($2::text is null or coalesce(organization_id, '') = $2)`, id, organizationArg(ctx)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrSavedQueryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch saved query: %w", err)
+	}
+	return &query, nil
+}
+
+// ListSavedQueries returns the saved queries of the organization ctx is scoped to that a user can see, by name
+func (r *postgresRepository) ListSavedQueries(ctx context.Context, userID string) ([]domain.SavedQuery, error) {
+	rows, err := r.pool.Query(ctx, `select `+savedQuerySelectColumns+` from audit_saved_queries
+		where (created_by = $1 or scope = $2) and ($3::text is null or coalesce(organization_id, '') = $3)
+		order by name, id`, userID, string(domain.SavedQueryOrganization), organizationArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch saved queries: %w", err)
+	}
+
+	queries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.SavedQuery, error) {
+		return scanSavedQuery(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse saved queries: %w", err)
+	}
+	return queries, nil
+}
+
+// UpdateSavedQuery replaces the name, description, scope and filter of a saved query
+func (r *postgresRepository) UpdateSavedQuery(ctx context.Context, query domain.SavedQuery) error {
+	row := newSavedQueryRow(query)
+	tag, err := r.pool.Exec(ctx, `update audit_saved_queries set name = $2, description = $3, scope = $4, filter = $5, updated_at = $6
+		where id = $1 and ($7::text is null or coalesce(organization_id, '') = $7)`,
+		row.ID, row.Name, row.Description, row.Scope, row.Filter, row.UpdatedAt, organizationArg(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to update saved query: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSavedQueryNotFound
+	}
+	return nil
+}
+
+// DeleteSavedQuery removes a saved query
+func (r *postgresRepository) DeleteSavedQuery(ctx context.Context, id string) error {
+	tag, err := r.pool.Exec(ctx, `delete from audit_saved_queries where id = $1 and ($2::text is null or coalesce(organization_id, '') = $2)`,
+		id, organizationArg(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to delete saved query: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSavedQueryNotFound
+	}
+	return nil
+}
+
+// GetEventLabels returns the label set of an event of the organization ctx is scoped to
+func (r *postgresRepository) GetEventLabels(ctx context.Context, eventID string) (*domain.EventLabels, error) {
+	var row eventLabelsRow
+	err := r.pool.QueryRow(ctx, `select event_id::text, session_id::text, labels, version, updated_by, updated_at,
+		coalesce(organization_id, '') from audit_event_labels
+		where event_id = $1 and ($2::text is null or coalesce(organization_id, '') = $2)`, eventID, organizationArg(ctx)).
+		Scan(&row.EventID, &row.SessionID, &row.Labels, &row.Version, &row.UpdatedBy, &row.UpdatedAt, &row.OrganizationID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch event labels: %w", err)
+	}
+	labels := row.toEventLabels()
+	return &labels, nil
+}
+
+// SaveEventLabels stores the label set of an event when the stored set is at the version before
+func (r *postgresRepository) SaveEventLabels(ctx context.Context, labels domain.EventLabels) error {
+	row := newEventLabelsRow(labels)
+	var tag pgconn.CommandTag
+	var err error
+	if row.Version == 1 {
+		tag, err = r.pool.Exec(ctx, `insert into audit_event_labels (event_id, session_id, labels, version, updated_by,
+			updated_at, organization_id) values ($1, $2, $3, 1, $4, $5, $6) on conflict (event_id) do nothing`,
+			row.EventID, row.SessionID, row.Labels, row.UpdatedBy, row.UpdatedAt, nullIfEmpty(row.OrganizationID))
+	} else {
+		tag, err = r.pool.Exec(ctx, `update audit_event_labels set labels = $2, version = $3, updated_by = $4, updated_at = $5
+			where event_id = $1 and version = $3 - 1`,
+			row.EventID, row.Labels, row.Version, row.UpdatedBy, row.UpdatedAt)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save event labels: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: labels of event %s changed before version %d was stored", domain.ErrVersionConflict, row.EventID, row.Version)
+	}
+	return nil
+}
+
+// annotationSelectColumns selects the columns scanned by scanAnnotation
+const annotationSelectColumns = `id::text, event_id::text, session_id::text, author_id, body, version, created_at, updated_at,
+	coalesce(organization_id, '')`
+
+// scanAnnotation reads a row selected with annotationSelectColumns
+func scanAnnotation(row pgx.Row) (domain.EventAnnotation, error) {
+	var ar annotationRow
+	if err := row.Scan(&ar.ID, &ar.EventID, &ar.SessionID, &ar.AuthorID, &ar.Body, &ar.Version, &ar.CreatedAt, &ar.UpdatedAt,
+		&ar.OrganizationID); err != nil {
+		return domain.EventAnnotation{}, err
+	}
+	return ar.toAnnotation(), nil
+}
+
+// CreateAnnotation stores a new annotation
+func (r *postgresRepository) CreateAnnotation(ctx context.Context, annotation domain.EventAnnotation) error {
+	row := newAnnotationRow(annotation)
+	if _, err := r.pool.Exec(ctx, `insert into audit_event_annotations (id, event_id, session_id, author_id, body, version,
+		created_at, updated_at, organization_id) values ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		row.ID, row.EventID, row.SessionID, row.AuthorID, row.Body, row.Version, row.CreatedAt, row.UpdatedAt,
+		nullIfEmpty(row.OrganizationID)); err != nil {
+		return fmt.Errorf("failed to insert annotation: %w", err)
+	}
+	return nil
+}
+
+// GetAnnotation returns an annotation of the organization ctx is scoped to
+func (r *postgresRepository) GetAnnotation(ctx context.Context, id string) (*domain.EventAnnotation, error) {
+	annotation, err := scanAnnotation(r.pool.QueryRow(ctx, `select `+annotationSelectColumns+` from audit_event_annotations
+		where id = $1 and ($2::text is null or coalesce(organization_id, '') = $2)`, id, organizationArg(ctx)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrAnnotationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch annotation: %w", err)
+	}
+	return &annotation, nil
+}
+
+// ListAnnotations returns the annotations of an event of the organization ctx is scoped to, oldest first
+func (r *postgresRepository) ListAnnotations(ctx context.Context, eventID string) ([]domain.EventAnnotation, error) {
+	rows, err := r.pool.Query(ctx, `select `+annotationSelectColumns+` from audit_event_annotations
+		where event_id = $1 and ($2::text is null or coalesce(organization_id, '') = $2)
+		order by created_at, id`, eventID, organizationArg(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch annotations: %w", err)
+	}
+
+	annotations, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.EventAnnotation, error) {
+		return scanAnnotation(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse annotations: %w", err)
+	}
+	return annotations, nil
+}
+
+// UpdateAnnotation stores the body of an annotation when the stored annotation is at the version before
+func (r *postgresRepository) UpdateAnnotation(ctx context.Context, annotation domain.EventAnnotation) error {
+	row := newAnnotationRow(annotation)
+	tag, err := r.pool.Exec(ctx, `update audit_event_annotations set body = $2, version = $3, updated_at = $4
+		where id = $1 and version = $3 - 1 and ($5::text is null or coalesce(organization_id, '') = $5)`,
+		row.ID, row.Body, row.Version, row.UpdatedAt, organizationArg(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to update annotation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: annotation %s changed before version %d was stored", domain.ErrVersionConflict, row.ID, row.Version)
+	}
+	return nil
+}
+
+// DeleteAnnotation removes an annotation at version
+func (r *postgresRepository) DeleteAnnotation(ctx context.Context, id string, version int64) error {
+	tag, err := r.pool.Exec(ctx, `delete from audit_event_annotations
+		where id = $1 and version = $2 and ($3::text is null or coalesce(organization_id, '') = $3)`,
+		id, version, organizationArg(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to delete annotation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: annotation %s changed after version %d", domain.ErrVersionConflict, id, version)
+	}
+	return nil
+}
+
+// GetSessionActivity returns the rolled-up activity of a session
+func (r *postgresRepository) GetSessionActivity(ctx context.Context, sessionID domain.SessionID, query domain.ActivityQuery) ([]domain.ActivityBucket, error) {
+	// Test sessions have no persisted events
+	if sessionID.IsTestSession() {
+		return []domain.ActivityBucket{}, nil
+	}
+
+	rows, err := r.pool.Query(ctx, `select bucket_start, user_id, event_count, by_type
+		from audit_activity_rollups
+		where session_id = $1 and granularity = $2 and bucket_start >= $3 and bucket_start < $4
+			and ($5 = '' or user_id = $5)
+			and ($6::text is null or coalesce(organization_id, '') = $6)
+		order by bucket_start, user_id`,
+		sessionID, string(query.Granularity), query.From.UTC(), query.To.UTC(), query.UserID, organizationArg(ctx))
+	if err != nil {
+		r.logger.Error("failed to fetch session activity",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch session activity: %w", err)
+	}
+
+	buckets, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.ActivityBucket, error) {
+		var ar activityRow
+		if err := row.Scan(&ar.BucketStart, &ar.UserID, &ar.EventCount, &ar.ByType); err != nil {
+			return domain.ActivityBucket{}, err
+		}
+		return ar.toActivityBucket(), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse session activity: %w", err)
+	}
+	return buckets, nil
+}
+
+// GetSlideHeatmap returns the hourly event counts per slide of a session
+func (r *postgresRepository) GetSlideHeatmap(ctx context.Context, sessionID domain.SessionID, query domain.HeatmapQuery) ([]domain.HeatmapCell, error) {
+	// Test sessions have no persisted events
+	if sessionID.IsTestSession() {
+		return []domain.HeatmapCell{}, nil
+	}
+
+	// Aggregates run in the session_slide_heatmap function (migrations/019_audit_slide_heatmap.sql)
+	rows, err := r.pool.Query(ctx, `select slide_id, slide_index, hour, event_count
+		from public.session_slide_heatmap($1, $2, $3, $4::text, $5::text)`,
+		sessionID, query.From.UTC(), query.To.UTC(), nullIfEmpty(query.UserID), organizationArg(ctx))
+	if err != nil {
+		r.logger.Error("failed to fetch slide heatmap",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch slide heatmap: %w", err)
+	}
+
+	cells, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.HeatmapCell, error) {
+		var hr heatmapRow
+		if err := row.Scan(&hr.SlideID, &hr.SlideIndex, &hr.Hour, &hr.EventCount); err != nil {
+			return domain.HeatmapCell{}, err
+		}
+		return hr.toHeatmapCell(), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse slide heatmap: %w", err)
+	}
+	return cells, nil
+}
+
+// GetSessionContributors returns the per-user contribution to a session
+func (r *postgresRepository) GetSessionContributors(ctx context.Context, sessionID domain.SessionID, query domain.ContributorQuery) ([]domain.ContributorStats, error) {
+	// Test sessions have no persisted events
+	if sessionID.IsTestSession() {
+		return []domain.ContributorStats{}, nil
+	}
+
+	// Aggregates run in the session_contributors function (migrations/020_audit_session_contributors.sql)
+	rows, err := r.pool.Query(ctx, `select user_id, edits, comments, merges, total_events, first_activity, last_activity
+		from public.session_contributors($1, $2::timestamptz, $3::timestamptz, $4::text)`,
+		sessionID, nullIfZeroTime(query.From), nullIfZeroTime(query.To), organizationArg(ctx))
+	if err != nil {
+		r.logger.Error("failed to fetch session contributors",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch session contributors: %w", err)
+	}
+
+	contributors, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.ContributorStats, error) {
+		var cr contributorRow
+		if err := row.Scan(&cr.UserID, &cr.Edits, &cr.Comments, &cr.Merges, &cr.TotalEvents,
+			&cr.FirstActivity, &cr.LastActivity); err != nil {
+			return domain.ContributorStats{}, err
+		}
+		return cr.toContributorStats(), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse session contributors: %w", err)
+	}
+	return contributors, nil
+}
+
+// RollupActivity replaces the rollups starting in [from, to) with the aggregates of their events
+func (r *postgresRepository) RollupActivity(ctx context.Context, from, to time.Time) (int, error) {
+	// Aggregation runs in the rollup_audit_activity function (migrations/016_audit_activity_rollups.sql)
+	var written int
+	if err := r.pool.QueryRow(ctx, "select public.rollup_audit_activity($1, $2)", from.UTC(), to.UTC()).Scan(&written); err != nil {
+		return 0, fmt.Errorf("failed to roll up activity: %w", err)
+	}
+	return written, nil
+}
+
+// DeleteUserActivity deletes the activity rollups of a user
+func (r *postgresRepository) DeleteUserActivity(ctx context.Context, userID domain.UserID) error {
+	if _, err := r.pool.Exec(ctx, "select public.delete_user_audit_activity($1, $2::text)", userID.String(), organizationArg(ctx)); err != nil {
+		r.logger.Error("failed to delete user activity",
+			requestid.Field(ctx),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to delete user activity: %w", err)
+	}
+	return nil
+}
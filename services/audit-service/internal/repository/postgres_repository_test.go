@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildEventConditions(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	cursorTime := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		filter        domain.EventFilter
+		page          domain.PaginationParams
+		wantCondition string
+		wantArgs      []interface{}
+	}{
+		{
+			name:          "session only",
+			wantCondition: "session_id = $1",
+			wantArgs:      []interface{}{"session-1"},
+		},
+		{
+			name: "all filters",
+			filter: domain.EventFilter{
+				Types:  []string{"edit", "comment"},
+				UserID: "user-1",
+				From:   from,
+				To:     to,
+				Search: "hello",
+			},
+			wantCondition: `session_id = $1 and type = any($2) and user_id = $3 and "timestamp" >= $4` +
+				` and "timestamp" <= $5 and to_tsvector(details) @@ plainto_tsquery($6)`,
+			wantArgs: []interface{}{"session-1", []string{"edit", "comment"}, "user-1", from, to, "hello"},
+		},
+		{
+			name:          "correlation ID",
+			filter:        domain.EventFilter{CorrelationID: "export-7f3a"},
+			wantCondition: "session_id = $1 and correlation_id = $2",
+			wantArgs:      []interface{}{"session-1", "export-7f3a"},
+		},
+		{
+			name:          "slide and shape",
+			filter:        domain.EventFilter{SlideID: "slide-3", ShapeID: "shape-12"},
+			wantCondition: "session_id = $1 and slide_id = $2 and shape_id = $3",
+			wantArgs:      []interface{}{"session-1", "slide-3", "shape-12"},
+		},
+		{
+			name:          "comment thread",
+			filter:        domain.EventFilter{Types: []string{"comment_created", "comment_resolved"}, ThreadID: "thread-2"},
+			wantCondition: "session_id = $1 and type = any($2) and thread_id = $3",
+			wantArgs:      []interface{}{"session-1", []string{"comment_created", "comment_resolved"}, "thread-2"},
+		},
+		{
+			name:          "labels",
+			filter:        domain.EventFilter{Labels: []string{"disputed", "billing-relevant"}},
+			wantCondition: "session_id = $1 and id in (select event_id from audit_event_labels where labels && $2)",
+			wantArgs:      []interface{}{"session-1", []string{"disputed", "billing-relevant"}},
+		},
+		{
+			name:          "keyset cursor",
+			filter:        domain.EventFilter{UserID: "user-1"},
+			page:          domain.PaginationParams{Cursor: &domain.Cursor{Timestamp: cursorTime, ID: "entry-1"}},
+			wantCondition: `session_id = $1 and user_id = $2 and ("timestamp", id) < ($3, $4::uuid)`,
+			wantArgs:      []interface{}{"session-1", "user-1", cursorTime, "entry-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, args := buildEventConditions(context.Background(), "session-1", tt.filter, tt.page)
+			assert.Equal(t, tt.wantCondition, condition)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestNullIfEmpty(t *testing.T) {
+	assert.Nil(t, nullIfEmpty(""))
+	assert.Equal(t, "198.51.100.1", nullIfEmpty("198.51.100.1"))
+}
+
+func TestNullIfZero(t *testing.T) {
+	assert.Nil(t, nullIfZero(0))
+	assert.Equal(t, 2, nullIfZero(2))
+}
+
+func TestBuildEventConditions_AllSessions(t *testing.T) {
+	condition, args := buildEventConditions(context.Background(), "", domain.EventFilter{}, domain.PaginationParams{})
+	assert.Equal(t, "true", condition)
+	assert.Empty(t, args)
+
+	condition, args = buildEventConditions(context.Background(), "", domain.EventFilter{UserID: "user-1", Types: []string{"export"}}, domain.PaginationParams{})
+	assert.Equal(t, "type = any($1) and user_id = $2", condition)
+	assert.Equal(t, []interface{}{[]string{"export"}, "user-1"}, args)
+}
+
+func TestBuildEventConditions_Organization(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "org-1")
+	condition, args := buildEventConditions(ctx, "session-1", domain.EventFilter{UserID: "user-1"}, domain.PaginationParams{})
+	assert.Equal(t, "session_id = $1 and coalesce(organization_id, '') = $2 and user_id = $3", condition)
+	assert.Equal(t, []interface{}{"session-1", "org-1", "user-1"}, args)
+
+	// The default organization only matches entries without one
+	condition, args = buildEventConditions(tenant.NewContext(context.Background(), ""), "", domain.EventFilter{}, domain.PaginationParams{})
+	assert.Equal(t, "coalesce(organization_id, '') = $1", condition)
+	assert.Equal(t, []interface{}{""}, args)
+}
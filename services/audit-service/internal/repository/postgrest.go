@@ -0,0 +1,328 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CountMode selects how PostgREST counts the rows matching a query, reported as the total of
+// the Content-Range header
+type CountMode string
+
+const (
+	// CountNone skips counting, which spares the database a second scan
+	CountNone CountMode = ""
+	// CountExact counts every matching row
+	CountExact CountMode = "exact"
+	// CountPlanned uses the row estimate of the query planner
+	CountPlanned CountMode = "planned"
+	// CountEstimated counts exactly up to the configured maximum and estimates above it
+	CountEstimated CountMode = "estimated"
+)
+
+// Filter is a condition of a PostgREST query: a column compared with an operator, or an
+// and/or group of conditions. Build filters with the functions named after their operator.
+type Filter struct {
+	column   string
+	operator string
+	values   []string
+	group    []Filter
+}
+
+// Eq matches rows whose column equals value
+func Eq(column, value string) Filter { return compare(column, "eq", value) }
+
+// Neq matches rows whose column differs from value
+func Neq(column, value string) Filter { return compare(column, "neq", value) }
+
+// Gt matches rows whose column is greater than value
+func Gt(column, value string) Filter { return compare(column, "gt", value) }
+
+// Gte matches rows whose column is greater than or equal to value
+func Gte(column, value string) Filter { return compare(column, "gte", value) }
+
+// Lt matches rows whose column is less than value
+func Lt(column, value string) Filter { return compare(column, "lt", value) }
+
+// Lte matches rows whose column is less than or equal to value
+func Lte(column, value string) Filter { return compare(column, "lte", value) }
+
+// Like matches rows whose column matches the pattern, with * as the wildcard
+func Like(column, pattern string) Filter { return compare(column, "like", pattern) }
+
+// ILike matches rows whose column matches the pattern regardless of case
+func ILike(column, pattern string) Filter { return compare(column, "ilike", pattern) }
+
+// PlainFTS matches rows whose text search column matches the plain text query
+func PlainFTS(column, query string) Filter { return compare(column, "plfts", query) }
+
+// In matches rows whose column equals one of the values
+func In(column string, values ...string) Filter {
+	return Filter{column: column, operator: "in", values: values}
+}
+
+// Ov matches rows whose array column shares at least one element with values
+func Ov(column string, values ...string) Filter {
+	return Filter{column: column, operator: "ov", values: values}
+}
+
+// IsNull matches rows whose column is null
+func IsNull(column string) Filter {
+	return Filter{column: column, operator: "is", values: []string{"null"}}
+}
+
+// And matches rows matching all of the filters
+func And(filters ...Filter) Filter { return Filter{operator: "and", group: filters} }
+
+// Or matches rows matching any of the filters
+func Or(filters ...Filter) Filter { return Filter{operator: "or", group: filters} }
+
+func compare(column, operator, value string) Filter {
+	return Filter{column: column, operator: operator, values: []string{value}}
+}
+
+// isGroup reports whether the filter is an and/or group
+func (f Filter) isGroup() bool {
+	return f.column == ""
+}
+
+// key returns the query parameter the filter is sent in at the top level of a query
+func (f Filter) key() string {
+	if f.isGroup() {
+		return f.operator
+	}
+	return f.column
+}
+
+// param renders the filter as the value of its top-level query parameter. The value of a
+// single comparison is taken literally by PostgREST; lists and groups quote their values.
+func (f Filter) param() string {
+	switch {
+	case f.isGroup():
+		return f.groupItems()
+	case f.operator == "in":
+		return "in." + quoteList(f.values)
+	case f.operator == "ov":
+		return "ov." + arrayLiteral(f.values)
+	default:
+		return f.operator + "." + f.values[0]
+	}
+}
+
+// condition renders the filter inside an and/or group
+func (f Filter) condition() string {
+	switch {
+	case f.isGroup():
+		return f.operator + f.groupItems()
+	case f.operator == "in":
+		return f.column + ".in." + quoteList(f.values)
+	case f.operator == "ov":
+		return f.column + ".ov." + arrayLiteral(f.values)
+	default:
+		return f.column + "." + f.operator + "." + quote(f.values[0])
+	}
+}
+
+// groupItems renders the conditions of a group as a parenthesized list
+func (f Filter) groupItems() string {
+	conditions := make([]string, len(f.group))
+	for i, filter := range f.group {
+		conditions[i] = filter.condition()
+	}
+	return "(" + strings.Join(conditions, ",") + ")"
+}
+
+// quoteList renders values as a parenthesized list
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quote(value)
+	}
+	return "(" + strings.Join(quoted, ",") + ")"
+}
+
+// arrayLiteral renders values as a Postgres array literal, double-quoting the elements that
+// hold characters the literal reserves
+func arrayLiteral(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = value
+		if value == "" || strings.ContainsAny(value, `,{}"\ `) {
+			quoted[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+		}
+	}
+	return "{" + strings.Join(quoted, ",") + "}"
+}
+
+// quote double-quotes a value of a list or group when it holds characters that PostgREST
+// reserves there, escaping backslashes and double quotes inside, so the value matches literally
+func quote(value string) string {
+	if value != "" && !strings.ContainsAny(value, `,.:()"\ `) {
+		return value
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+	return `"` + escaped + `"`
+}
+
+// Order is a column a query is sorted by
+type Order struct {
+	column     string
+	descending bool
+}
+
+// Asc sorts by the column in ascending order
+func Asc(column string) Order { return Order{column: column} }
+
+// Desc sorts by the column in descending order
+func Desc(column string) Order { return Order{column: column, descending: true} }
+
+// Query builds a PostgREST read: the columns, filters, ordering and paging sent in the query
+// string, and the row range and count mode sent as headers
+type Query struct {
+	columns string
+	filters []Filter
+	order   []Order
+	limit   int
+	offset  int
+	// limited and skipping record which of limit and offset are sent
+	limited  bool
+	skipping bool
+	// rangeFrom and rangeTo select rows through the Range header when ranged is set
+	rangeFrom int
+	rangeTo   int
+	ranged    bool
+	count     CountMode
+}
+
+// NewQuery starts a query returning the given columns, or every column when none are given
+func NewQuery(columns ...string) *Query {
+	q := &Query{columns: "*"}
+	if len(columns) > 0 {
+		q.columns = strings.Join(columns, ",")
+	}
+	return q
+}
+
+// InnerJoin restricts the rows to those with a related row in table, reached through a foreign
+// key, without returning it. Conditions on the related rows name their columns table.column.
+func (q *Query) InnerJoin(table string) *Query {
+	q.columns += "," + table + "!inner()"
+	return q
+}
+
+// Where adds conditions that rows must all match
+func (q *Query) Where(filters ...Filter) *Query {
+	q.filters = append(q.filters, filters...)
+	return q
+}
+
+// Order sorts the rows by the given columns, replacing any earlier ordering
+func (q *Query) Order(order ...Order) *Query {
+	q.order = order
+	return q
+}
+
+// Limit returns at most n rows
+func (q *Query) Limit(n int) *Query {
+	q.limit = n
+	q.limited = true
+	return q
+}
+
+// Offset skips the first n rows
+func (q *Query) Offset(n int) *Query {
+	q.offset = n
+	q.skipping = true
+	return q
+}
+
+// Range returns the rows from the zero-based position from to to, both included, selected
+// through the Range header
+func (q *Query) Range(from, to int) *Query {
+	q.rangeFrom, q.rangeTo, q.ranged = from, to, true
+	return q
+}
+
+// Count asks PostgREST to count the matching rows
+func (q *Query) Count(mode CountMode) *Query {
+	q.count = mode
+	return q
+}
+
+// Params renders the query string parameters. Conditions that would share a parameter, such
+// as two on one column, are combined in the top-level and() group. A nil query has none.
+func (q *Query) Params() map[string]string {
+	if q == nil {
+		return nil
+	}
+	params := map[string]string{"select": q.columns}
+
+	seen := make(map[string]int, len(q.filters))
+	for _, filter := range q.filters {
+		seen[filter.key()]++
+	}
+	var combined []Filter
+	for _, filter := range q.filters {
+		switch {
+		case filter.isGroup() && filter.operator == "and":
+			combined = append(combined, filter.group...)
+		case seen[filter.key()] > 1:
+			combined = append(combined, filter)
+		default:
+			params[filter.key()] = filter.param()
+		}
+	}
+	if len(combined) > 0 {
+		params["and"] = And(combined...).param()
+	}
+
+	if len(q.order) > 0 {
+		terms := make([]string, len(q.order))
+		for i, order := range q.order {
+			terms[i] = order.column + ".asc"
+			if order.descending {
+				terms[i] = order.column + ".desc"
+			}
+		}
+		params["order"] = strings.Join(terms, ",")
+	}
+
+	if q.limited {
+		params["limit"] = strconv.Itoa(q.limit)
+	}
+	if q.skipping {
+		params["offset"] = strconv.Itoa(q.offset)
+	}
+	return params
+}
+
+// Headers renders the Range and Prefer headers of the query. A nil query has none.
+func (q *Query) Headers() map[string]string {
+	if q == nil {
+		return nil
+	}
+	headers := map[string]string{}
+	if q.ranged {
+		headers["Range-Unit"] = "items"
+		headers["Range"] = fmt.Sprintf("%d-%d", q.rangeFrom, q.rangeTo)
+	}
+	if q.count != CountNone {
+		headers["Prefer"] = "count=" + string(q.count)
+	}
+	return headers
+}
+
+// parseContentRange returns the total of a Content-Range header such as 0-9/100 or */0, or
+// zero when the response carries no count
+func parseContentRange(contentRange string) int {
+	_, total, found := strings.Cut(contentRange, "/")
+	if !found {
+		return 0
+	}
+	count, err := strconv.Atoi(total)
+	if err != nil {
+		return 0
+	}
+	return count
+}
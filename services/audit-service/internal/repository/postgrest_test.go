@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_Params(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    *Query
+		expected map[string]string
+	}{
+		{
+			name:     "select_all",
+			query:    NewQuery(),
+			expected: map[string]string{"select": "*"},
+		},
+		{
+			name: "columns_order_and_paging",
+			query: NewQuery("id", "user_id").
+				Where(Eq("session_id", "session-1"), IsNull("revoked_at")).
+				Order(Desc("timestamp"), Asc("id")).
+				Limit(10).
+				Offset(20),
+			expected: map[string]string{
+				"select":     "id,user_id",
+				"session_id": "eq.session-1",
+				"revoked_at": "is.null",
+				"order":      "timestamp.desc,id.asc",
+				"limit":      "10",
+				"offset":     "20",
+			},
+		},
+		{
+			// A single comparison is taken literally, whatever characters the value holds
+			name:     "top_level_value_is_literal",
+			query:    NewQuery().Where(Eq("user_id", "doe, john (admin)")),
+			expected: map[string]string{"select": "*", "user_id": "eq.doe, john (admin)"},
+		},
+		{
+			name:     "list_values_with_commas",
+			query:    NewQuery().Where(In("type", "edit", "slide,moved", "plain")),
+			expected: map[string]string{"select": "*", "type": `in.(edit,"slide,moved",plain)`},
+		},
+		{
+			name:     "list_values_with_quotes_and_backslashes",
+			query:    NewQuery().Where(In("user_id", `say "hi"`, `C:\users`, "")),
+			expected: map[string]string{"select": "*", "user_id": `in.("say \"hi\"","C:\\users","")`},
+		},
+		{
+			name: "same_column_combined",
+			query: NewQuery().Where(
+				Gte("timestamp", "2024-01-01T00:00:00Z"),
+				Lt("timestamp", "2024-02-01T00:00:00Z"),
+				Eq("type", "edit"),
+			),
+			expected: map[string]string{
+				"select": "*",
+				"type":   "eq.edit",
+				"and":    `(timestamp.gte."2024-01-01T00:00:00Z",timestamp.lt."2024-02-01T00:00:00Z")`,
+			},
+		},
+		{
+			name: "and_groups_flattened",
+			query: NewQuery().Where(
+				And(Gte("seq", "1"), Lte("seq", "9")),
+				And(Neq("user_id", "a,b")),
+			),
+			expected: map[string]string{
+				"select": "*",
+				"and":    `(seq.gte.1,seq.lte.9,user_id.neq."a,b")`,
+			},
+		},
+		{
+			name: "nested_groups",
+			query: NewQuery().Where(Or(
+				Lt("timestamp", "2024-01-01T12:00:00.5Z"),
+				And(Eq("timestamp", "2024-01-01T12:00:00.5Z"), Lt("id", "entry-42")),
+			)),
+			expected: map[string]string{
+				"select": "*",
+				"or":     `(timestamp.lt."2024-01-01T12:00:00.5Z",and(timestamp.eq."2024-01-01T12:00:00.5Z",id.lt.entry-42))`,
+			},
+		},
+		{
+			name:     "group_values_with_commas_and_parentheses",
+			query:    NewQuery().Where(Or(Eq("created_by", "smith, j (ops)"), ILike("name", "*report*"))),
+			expected: map[string]string{"select": "*", "or": `(created_by.eq."smith, j (ops)",name.ilike.*report*)`},
+		},
+		{
+			name:     "array_overlap",
+			query:    NewQuery().Where(Ov("labels", "disputed", "q3 review", `a"b`)),
+			expected: map[string]string{"select": "*", "labels": `ov.{disputed,"q3 review","a\"b"}`},
+		},
+		{
+			name: "inner_join",
+			query: NewQuery().InnerJoin("audit_event_labels").
+				Where(Eq("session_id", "session-1"), Ov("audit_event_labels.labels", "disputed")),
+			expected: map[string]string{
+				"select":                    "*,audit_event_labels!inner()",
+				"session_id":                "eq.session-1",
+				"audit_event_labels.labels": "ov.{disputed}",
+			},
+		},
+		{
+			name:     "nil",
+			query:    nil,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.query.Params())
+		})
+	}
+}
+
+func TestQuery_Headers(t *testing.T) {
+	assert.Empty(t, NewQuery().Headers())
+	assert.Nil(t, (*Query)(nil).Headers())
+
+	assert.Equal(t, map[string]string{
+		"Range-Unit": "items",
+		"Range":      "0-24",
+		"Prefer":     "count=planned",
+	}, NewQuery().Range(0, 24).Count(CountPlanned).Headers())
+
+	// The range is sent as a header, not in the query string
+	assert.Equal(t, map[string]string{"select": "*"}, NewQuery().Range(0, 24).Params())
+}
+
+func TestParseContentRange(t *testing.T) {
+	tests := map[string]int{
+		"0-9/100": 100,
+		"*/0":     0,
+		"*/25":    25,
+		"0-9/*":   0,
+		"":        0,
+	}
+	for contentRange, expected := range tests {
+		assert.Equal(t, expected, parseContentRange(contentRange), contentRange)
+	}
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/cache"
+	"audit-service/pkg/tenant"
+)
+
+// maxCachedPageLimit is the largest page cached; it matches the page size cap of the API, so
+// the pages walked by exports and reports are read from storage without evicting the rest
+const maxCachedPageLimit = 100
+
+// QueryResult is a history page or the stats of a session held by the query cache
+type QueryResult struct {
+	Entries []domain.AuditEntry
+	Total   int
+	Stats   *domain.SessionStats
+}
+
+// freshReadsKey marks the contexts of reads that must not be answered from cached queries
+type freshReadsKey struct{}
+
+// WithFreshReads returns a copy of ctx whose reads skip the query cache, for readers that must
+// see writes made through other replicas. The queries they make are cached anew.
+func WithFreshReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshReadsKey{}, true)
+}
+
+// freshReads reports whether ctx was marked with WithFreshReads
+func freshReads(ctx context.Context) bool {
+	fresh, _ := ctx.Value(freshReadsKey{}).(bool)
+	return fresh
+}
+
+// queryCachingRepository answers repeated history and stats queries of a session from an
+// in-memory cache. Dashboards poll the same pages many times a minute; every query of a session
+// is dropped as soon as events of that session are written or deleted through this replica, and
+// writes made elsewhere show once the TTL of the cache has passed.
+//
+// Invalidation works through generations: each cached query is keyed by the generation of its
+// session, and writes move the session to a new generation, so a query still loading while the
+// session is written is cached under a key no later lookup uses.
+type queryCachingRepository struct {
+	AuditRepository
+	results *cache.LRU[QueryResult]
+	// generations holds the current generation of the sessions recently queried or written;
+	// a session without one gets a fresh generation, never an earlier one
+	generations *cache.LRU[uint64]
+	next        atomic.Uint64
+}
+
+// NewQueryCachingRepository wraps a repository so history pages and stats are served through
+// the results cache, with concurrent identical queries sharing one storage request. The
+// generations cache should hold at least as many sessions as results holds queries, for as long.
+func NewQueryCachingRepository(repo AuditRepository, results *cache.LRU[QueryResult], generations *cache.LRU[uint64]) AuditRepository {
+	return &queryCachingRepository{
+		AuditRepository: repo,
+		results:         results,
+		generations:     generations,
+	}
+}
+
+// FindBySessionID returns the cached page of the session history, or reads and caches it
+func (r *queryCachingRepository) FindBySessionID(ctx context.Context, sessionID domain.SessionID, page domain.PaginationParams) ([]domain.AuditEntry, int, error) {
+	if page.Limit > maxCachedPageLimit {
+		return r.AuditRepository.FindBySessionID(ctx, sessionID, page)
+	}
+	r.refresh(ctx, sessionID)
+
+	result, err := r.results.Load(ctx, r.key(ctx, sessionID, "history", page), func(ctx context.Context) (QueryResult, error) {
+		entries, total, err := r.AuditRepository.FindBySessionID(ctx, sessionID, page)
+		return QueryResult{Entries: entries, Total: total}, err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	// Readers classify and upgrade the entries they get in place
+	return slices.Clone(result.Entries), result.Total, nil
+}
+
+// QueryEvents returns the cached page of a session search, or runs and caches it. Searches
+// across sessions are not cached, since no single session write invalidates them, and neither
+// are searches by label, since labels are not written through this repository.
+func (r *queryCachingRepository) QueryEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, page domain.PaginationParams) ([]domain.AuditEntry, int, error) {
+	if sessionID == "" || len(filter.Labels) > 0 || page.Limit > maxCachedPageLimit {
+		return r.AuditRepository.QueryEvents(ctx, sessionID, filter, page)
+	}
+	r.refresh(ctx, sessionID)
+
+	result, err := r.results.Load(ctx, r.key(ctx, sessionID, "query", filter, page), func(ctx context.Context) (QueryResult, error) {
+		entries, total, err := r.AuditRepository.QueryEvents(ctx, sessionID, filter, page)
+		return QueryResult{Entries: entries, Total: total}, err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return slices.Clone(result.Entries), result.Total, nil
+}
+
+// GetSessionStats returns the cached stats of the session, or computes and caches them
+func (r *queryCachingRepository) GetSessionStats(ctx context.Context, sessionID domain.SessionID) (*domain.SessionStats, error) {
+	r.refresh(ctx, sessionID)
+	result, err := r.results.Load(ctx, r.key(ctx, sessionID, "stats"), func(ctx context.Context) (QueryResult, error) {
+		stats, err := r.AuditRepository.GetSessionStats(ctx, sessionID)
+		return QueryResult{Stats: stats}, err
+	})
+	if err != nil || result.Stats == nil {
+		return nil, err
+	}
+	stats := *result.Stats
+	return &stats, nil
+}
+
+// CreateEvents stores the entries and drops the cached queries of their sessions
+func (r *queryCachingRepository) CreateEvents(ctx context.Context, entries []domain.AuditEntry) error {
+	// Entries may be stored even when the call fails, such as on a timeout
+	defer func() {
+		for _, entry := range entries {
+			r.invalidate(domain.SessionID(entry.SessionID))
+		}
+	}()
+	return r.AuditRepository.CreateEvents(ctx, entries)
+}
+
+// PurgeEvents deletes events and drops the cached queries of the sessions they belonged to
+func (r *queryCachingRepository) PurgeEvents(ctx context.Context, eventType string, before time.Time, limit int) ([]domain.PurgedEvents, error) {
+	purged, err := r.AuditRepository.PurgeEvents(ctx, eventType, before, limit)
+	r.invalidatePurged(purged)
+	return purged, err
+}
+
+// DeleteEvents deletes events and drops the cached queries of the sessions they belonged to
+func (r *queryCachingRepository) DeleteEvents(ctx context.Context, ids []string) ([]domain.PurgedEvents, error) {
+	purged, err := r.AuditRepository.DeleteEvents(ctx, ids)
+	r.invalidatePurged(purged)
+	return purged, err
+}
+
+// EraseUserEvents erases the events of a user and drops the cached queries of their sessions
+func (r *queryCachingRepository) EraseUserEvents(ctx context.Context, userID domain.UserID, mode domain.ErasureMode, overrideHolds bool, limit int) ([]domain.PurgedEvents, error) {
+	purged, err := r.AuditRepository.EraseUserEvents(ctx, userID, mode, overrideHolds, limit)
+	r.invalidatePurged(purged)
+	return purged, err
+}
+
+// key identifies a query of a session in the organization ctx is scoped to, at the current
+// generation of the session
+func (r *queryCachingRepository) key(ctx context.Context, sessionID domain.SessionID, kind string, params ...interface{}) string {
+	scope := "*"
+	if organizationID, ok := tenant.FromContext(ctx); ok {
+		scope = "org:" + organizationID
+	}
+	session := strings.ToLower(sessionID.String())
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s|%s|%d|%s", scope, session, r.generation(session), kind)
+	for _, param := range params {
+		// Filters and pages are plain values, which always encode
+		encoded, _ := json.Marshal(param)
+		b.WriteByte('|')
+		b.Write(encoded)
+	}
+	return b.String()
+}
+
+// generation returns the current generation of a session, starting a fresh one when the
+// session has none cached
+func (r *queryCachingRepository) generation(session string) uint64 {
+	if generation, ok := r.generations.Get(session); ok {
+		return generation
+	}
+	generation := r.next.Add(1)
+	r.generations.Set(session, generation)
+	return generation
+}
+
+// invalidate moves a session to a new generation, so its cached queries are no longer used
+func (r *queryCachingRepository) invalidate(sessionID domain.SessionID) {
+	r.generations.Set(strings.ToLower(sessionID.String()), r.next.Add(1))
+}
+
+// refresh invalidates the session for reads marked with WithFreshReads, so they and the
+// reads after them load from storage
+func (r *queryCachingRepository) refresh(ctx context.Context, sessionID domain.SessionID) {
+	if freshReads(ctx) {
+		r.invalidate(sessionID)
+	}
+}
+
+// invalidatePurged invalidates the sessions events were deleted from
+func (r *queryCachingRepository) invalidatePurged(purged []domain.PurgedEvents) {
+	for _, p := range purged {
+		r.invalidate(domain.SessionID(p.SessionID))
+	}
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/cache"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCachingRepository(t *testing.T) {
+	plain, _ := newTestSQLiteRepository(t)
+	clk := clock.NewFakeClock(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC))
+	results := cache.NewLRU[QueryResult](100, 5*time.Second, clk)
+	repo := NewQueryCachingRepository(plain, results, cache.NewLRU[uint64](100, 5*time.Second, clk))
+	ctx := context.Background()
+	base := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	page := domain.PaginationParams{Limit: 50}
+
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "edit", base, ""),
+	}))
+	entries, total, err := repo.FindBySessionID(ctx, testSQLiteSession, page)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, entries, 1)
+
+	// Callers get their own copy of a cached page
+	entries[0].Category = domain.CategoryAccess
+
+	// Writes that bypass this replica show once the entry expires
+	require.NoError(t, plain.CreateEvents(ctx, []domain.AuditEntry{
+		sqliteEntry("00000000-0000-0000-0000-000000000002", "user-1", "edit", base.Add(time.Minute), ""),
+	}))
+	entries, total, err = repo.FindBySessionID(ctx, testSQLiteSession, page)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Empty(t, entries[0].Category)
+
+	clk.Advance(5 * time.Second)
+	_, total, err = repo.FindBySessionID(ctx, testSQLiteSession, page)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+
+	// Writes through the cache show at once, in searches and stats too
+	_, total, err = repo.QueryEvents(ctx, testSQLiteSession, domain.EventFilter{UserID: "user-1"}, page)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	stats, err := repo.GetSessionStats(ctx, testSQLiteSession)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.TotalEvents)
+
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		sqliteEntry("00000000-0000-0000-0000-000000000003", "user-1", "edit", base.Add(2*time.Minute), ""),
+	}))
+	_, total, err = repo.FindBySessionID(ctx, testSQLiteSession, page)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	_, total, err = repo.QueryEvents(ctx, testSQLiteSession, domain.EventFilter{UserID: "user-1"}, page)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	stats, err = repo.GetSessionStats(ctx, testSQLiteSession)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.TotalEvents)
+
+	hits := results.Stats().Hits
+	// Other organizations and export-sized pages do not share cached pages
+	_, _, err = repo.FindBySessionID(tenant.NewContext(ctx, "org-2"), testSQLiteSession, page)
+	require.NoError(t, err)
+	_, _, err = repo.FindBySessionID(ctx, testSQLiteSession, domain.PaginationParams{Limit: 500})
+	require.NoError(t, err)
+	_, _, err = repo.FindBySessionID(ctx, testSQLiteSession, domain.PaginationParams{Limit: 500})
+	require.NoError(t, err)
+	assert.Equal(t, hits, results.Stats().Hits)
+
+	// Deleted events show at once
+	_, err = repo.DeleteEvents(ctx, []string{"00000000-0000-0000-0000-000000000003"})
+	require.NoError(t, err)
+	_, total, err = repo.FindBySessionID(ctx, testSQLiteSession, page)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+
+	// Searches by label see label changes at once
+	labeled := domain.EventFilter{Labels: []string{"disputed"}}
+	_, total, err = repo.QueryEvents(ctx, testSQLiteSession, labeled, page)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	labeledAt := base.Add(time.Hour)
+	require.NoError(t, plain.(LabelRepository).SaveEventLabels(ctx, domain.EventLabels{EventID: "00000000-0000-0000-0000-000000000001",
+		SessionID: testSQLiteSession, Labels: []string{"disputed"}, Version: 1, UpdatedBy: "user-2", UpdatedAt: &labeledAt}))
+	_, total, err = repo.QueryEvents(ctx, testSQLiteSession, labeled, page)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+
+	// Fresh reads see writes that bypassed this replica, and so do the reads after them
+	require.NoError(t, plain.CreateEvents(ctx, []domain.AuditEntry{
+		sqliteEntry("00000000-0000-0000-0000-000000000004", "user-1", "edit", base.Add(3*time.Minute), ""),
+	}))
+	_, total, err = repo.FindBySessionID(ctx, testSQLiteSession, page)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	_, total, err = repo.FindBySessionID(WithFreshReads(ctx), testSQLiteSession, page)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	_, total, err = repo.FindBySessionID(ctx, testSQLiteSession, page)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"audit-service/internal/domain"
+)
+
+// ReportRepository stores the scheduled reports and their run history. Report lookups are
+// scoped to the organization of ctx; the scheduler lists and advances due reports of every
+// organization.
+type ReportRepository interface {
+	// CreateReport stores a new report
+	CreateReport(ctx context.Context, report domain.Report) error
+	// GetReport returns a report, or domain.ErrReportNotFound
+	GetReport(ctx context.Context, id string) (*domain.Report, error)
+	// ListReports returns the reports by name
+	ListReports(ctx context.Context) ([]domain.Report, error)
+	// DeleteReport removes a report with its runs, or returns domain.ErrReportNotFound
+	DeleteReport(ctx context.Context, id string) error
+	// ListDueReports returns the reports whose next run is at or before the given time, most
+	// overdue first
+	ListDueReports(ctx context.Context, at time.Time, limit int) ([]domain.Report, error)
+	// AdvanceReport claims the run of a report due at scheduledAt by moving its next run to
+	// nextRunAt. It returns false when the run was claimed before, by another replica.
+	AdvanceReport(ctx context.Context, id string, scheduledAt, nextRunAt time.Time) (bool, error)
+	// CreateReportRun records a run of a report
+	CreateReportRun(ctx context.Context, run domain.ReportRun) error
+	// ListReportRuns returns the latest runs of a report, newest first
+	ListReportRuns(ctx context.Context, reportID string, limit int) ([]domain.ReportRun, error)
+}
+
+// reportRow represents an audit_reports row
+type reportRow struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Kind      string     `json:"kind"`
+	SessionID *string    `json:"session_id"`
+	Schedule  string     `json:"schedule"`
+	Format    string     `json:"format"`
+	Delivery  string     `json:"delivery"`
+	Target    string     `json:"target"`
+	CreatedBy string     `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	NextRunAt time.Time  `json:"next_run_at"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	// Omitted, and stored as null, for reports of the default organization
+	OrganizationID string `json:"organization_id,omitempty"`
+}
+
+// newReportRow converts a domain report into a database row
+func newReportRow(report domain.Report) reportRow {
+	row := reportRow{
+		ID:        report.ID,
+		Name:      report.Name,
+		Kind:      string(report.Kind),
+		Schedule:  report.Schedule,
+		Format:    string(report.Format),
+		Delivery:  string(report.Delivery),
+		Target:    report.Target,
+		CreatedBy: report.CreatedBy,
+		CreatedAt: report.CreatedAt.UTC(),
+		NextRunAt: report.NextRunAt.UTC(),
+
+		OrganizationID: report.OrganizationID,
+	}
+	if report.SessionID != "" {
+		row.SessionID = &report.SessionID
+	}
+	if report.LastRunAt != nil {
+		lastRunAt := report.LastRunAt.UTC()
+		row.LastRunAt = &lastRunAt
+	}
+	return row
+}
+
+// toReport converts a database row into a domain report
+func (row reportRow) toReport() domain.Report {
+	report := domain.Report{
+		ID:        row.ID,
+		Name:      row.Name,
+		Kind:      domain.ReportKind(row.Kind),
+		Schedule:  row.Schedule,
+		Format:    domain.ReportFormat(row.Format),
+		Delivery:  domain.ReportDelivery(row.Delivery),
+		Target:    row.Target,
+		CreatedBy: row.CreatedBy,
+		CreatedAt: row.CreatedAt.UTC(),
+		NextRunAt: row.NextRunAt.UTC(),
+
+		OrganizationID: row.OrganizationID,
+	}
+	if row.SessionID != nil {
+		report.SessionID = *row.SessionID
+	}
+	if row.LastRunAt != nil {
+		lastRunAt := row.LastRunAt.UTC()
+		report.LastRunAt = &lastRunAt
+	}
+	return report
+}
+
+// reportRunRow represents an audit_report_runs row
+type reportRunRow struct {
+	ID          string    `json:"id"`
+	ReportID    string    `json:"report_id"`
+	TriggeredBy string    `json:"triggered_by"`
+	Status      string    `json:"status"`
+	PeriodFrom  time.Time `json:"period_from"`
+	PeriodTo    time.Time `json:"period_to"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	RowCount    int       `json:"row_count"`
+	Location    *string   `json:"location,omitempty"`
+	Error       *string   `json:"error,omitempty"`
+	// Omitted, and stored as null, for runs of the default organization
+	OrganizationID string `json:"organization_id,omitempty"`
+}
+
+// newReportRunRow converts a domain report run into a database row
+func newReportRunRow(run domain.ReportRun) reportRunRow {
+	row := reportRunRow{
+		ID:          run.ID,
+		ReportID:    run.ReportID,
+		TriggeredBy: string(run.Trigger),
+		Status:      string(run.Status),
+		PeriodFrom:  run.PeriodFrom.UTC(),
+		PeriodTo:    run.PeriodTo.UTC(),
+		StartedAt:   run.StartedAt.UTC(),
+		FinishedAt:  run.FinishedAt.UTC(),
+		RowCount:    run.Rows,
+
+		OrganizationID: run.OrganizationID,
+	}
+	if run.Location != "" {
+		row.Location = &run.Location
+	}
+	if run.Error != "" {
+		row.Error = &run.Error
+	}
+	return row
+}
+
+// toReportRun converts a database row into a domain report run
+func (row reportRunRow) toReportRun() domain.ReportRun {
+	run := domain.ReportRun{
+		ID:         row.ID,
+		ReportID:   row.ReportID,
+		Trigger:    domain.ReportTrigger(row.TriggeredBy),
+		Status:     domain.ReportRunStatus(row.Status),
+		PeriodFrom: row.PeriodFrom.UTC(),
+		PeriodTo:   row.PeriodTo.UTC(),
+		StartedAt:  row.StartedAt.UTC(),
+		FinishedAt: row.FinishedAt.UTC(),
+		Rows:       row.RowCount,
+
+		OrganizationID: row.OrganizationID,
+	}
+	if row.Location != nil {
+		run.Location = *row.Location
+	}
+	if row.Error != nil {
+		run.Error = *row.Error
+	}
+	return run
+}
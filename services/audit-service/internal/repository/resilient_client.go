@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/pkg/breaker"
+	"audit-service/pkg/requestid"
+
+	"go.uber.org/zap"
+)
+
+// ErrCircuitOpen is returned without calling Supabase while the circuit breaker is open
+var ErrCircuitOpen = fmt.Errorf("supabase circuit breaker is open: %w", domain.ErrServiceUnavailable)
+
+// idempotentRPCs are the read-only functions that are safe to call again after a failure
+var idempotentRPCs = map[string]bool{
+	"/rpc/session_audit_stats": true,
+}
+
+// RetryPolicy controls how idempotent Supabase calls are repeated after transient failures
+type RetryPolicy struct {
+	// MaxAttempts includes the first call
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Delay returns a random backoff before the given retry, growing exponentially up to MaxDelay
+func (p RetryPolicy) Delay(retry int) time.Duration {
+	ceiling := p.BaseDelay
+	for i := 1; i < retry && ceiling < p.MaxDelay; i++ {
+		ceiling *= 2
+	}
+	ceiling = min(ceiling, p.MaxDelay)
+	if ceiling <= 0 {
+		return 0
+	}
+
+	// Full jitter spreads the retries of concurrent requests instead of repeating them in lockstep
+	return rand.N(ceiling + 1)
+}
+
+// resilientClient guards the wrapped client with a circuit breaker and retries idempotent calls
+type resilientClient struct {
+	client  SupabaseClientInterface
+	breaker *breaker.Breaker
+	retry   RetryPolicy
+	metrics *metrics.Metrics
+	logger  *zap.Logger
+}
+
+// NewResilientClient wraps a Supabase client with a circuit breaker and a retry policy.
+// GET requests and read-only functions are retried; other writes are attempted once, since
+// a request that timed out may still have been applied.
+func NewResilientClient(client SupabaseClientInterface, cb *breaker.Breaker, retry RetryPolicy, m *metrics.Metrics, logger *zap.Logger) SupabaseClientInterface {
+	return &resilientClient{
+		client:  client,
+		breaker: cb,
+		retry:   retry,
+		metrics: m,
+		logger:  logger,
+	}
+}
+
+// Get performs a GET request, retrying transient failures
+func (c *resilientClient) Get(ctx context.Context, endpoint string, query *Query) ([]byte, int, error) {
+	var data []byte
+	var count int
+	err := c.call(ctx, http.MethodGet, endpoint, true, func() error {
+		var err error
+		data, count, err = c.client.Get(ctx, endpoint, query)
+		return err
+	})
+	return data, count, err
+}
+
+// Post performs a POST request, retrying transient failures of read-only functions only
+func (c *resilientClient) Post(ctx context.Context, endpoint string, payload interface{}) ([]byte, error) {
+	var data []byte
+	err := c.call(ctx, http.MethodPost, endpoint, idempotentRPCs[endpoint], func() error {
+		var err error
+		data, err = c.client.Post(ctx, endpoint, payload)
+		return err
+	})
+	return data, err
+}
+
+// call runs fn through the breaker, repeating it with backoff when it is idempotent
+func (c *resilientClient) call(ctx context.Context, method, endpoint string, idempotent bool, fn func() error) error {
+	attempts := 1
+	if idempotent {
+		attempts = max(c.retry.MaxAttempts, 1)
+	}
+
+	for attempt := 1; ; attempt++ {
+		done, err := c.breaker.Allow()
+		if err != nil {
+			return ErrCircuitOpen
+		}
+
+		err = fn()
+		// Only failures pointing at Supabase count against the breaker, not rejected payloads or cancellations
+		done(IsRetryable(err))
+
+		if err == nil || !IsRetryable(err) || attempt >= attempts {
+			return err
+		}
+
+		delay := c.retry.Delay(attempt)
+		c.metrics.ObserveSupabaseRetry(method, endpoint)
+		c.logger.Warn("retrying supabase request",
+			requestid.Field(ctx),
+			zap.String("method", method),
+			zap.String("endpoint", endpoint),
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
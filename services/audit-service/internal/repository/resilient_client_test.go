@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/pkg/breaker"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestResilientClient(inner SupabaseClientInterface, threshold int) (SupabaseClientInterface, *breaker.Breaker) {
+	cb := breaker.New(breaker.Config{FailureThreshold: threshold, OpenTimeout: time.Minute, HalfOpenRequests: 1},
+		clock.New(), nil)
+	client := NewResilientClient(inner, cb, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond},
+		metrics.New(), zap.NewNop())
+	return client, cb
+}
+
+func TestResilientClient_RetriesIdempotentCalls(t *testing.T) {
+	inner := &MockSupabaseClient{}
+	client, _ := newTestResilientClient(inner, 10)
+
+	unavailable := &SupabaseError{Message: "unavailable", StatusCode: http.StatusServiceUnavailable}
+	inner.On("Get", mock.Anything, "/audit_logs", mock.Anything).Return([]byte(nil), 0, unavailable).Twice()
+	inner.On("Get", mock.Anything, "/audit_logs", mock.Anything).Return([]byte("[]"), 0, nil).Once()
+
+	data, _, err := client.Get(context.Background(), "/audit_logs", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("[]"), data)
+	inner.AssertExpectations(t)
+}
+
+func TestResilientClient_GivesUpAfterMaxAttempts(t *testing.T) {
+	inner := &MockSupabaseClient{}
+	client, _ := newTestResilientClient(inner, 10)
+
+	unavailable := &SupabaseError{Message: "unavailable", StatusCode: http.StatusBadGateway}
+	inner.On("Post", mock.Anything, "/rpc/session_audit_stats", mock.Anything).Return([]byte(nil), unavailable).Times(3)
+
+	_, err := client.Post(context.Background(), "/rpc/session_audit_stats", nil)
+	assert.ErrorIs(t, err, unavailable)
+	inner.AssertExpectations(t)
+}
+
+func TestResilientClient_DoesNotRetryWrites(t *testing.T) {
+	inner := &MockSupabaseClient{}
+	client, _ := newTestResilientClient(inner, 10)
+
+	inner.On("Post", mock.Anything, "/audit_logs", mock.Anything).
+		Return([]byte(nil), &SupabaseError{Message: "timeout", StatusCode: http.StatusGatewayTimeout}).Once()
+
+	_, err := client.Post(context.Background(), "/audit_logs", nil)
+	assert.Error(t, err)
+	inner.AssertExpectations(t)
+}
+
+func TestResilientClient_DoesNotRetryRejectedRequests(t *testing.T) {
+	inner := &MockSupabaseClient{}
+	client, cb := newTestResilientClient(inner, 1)
+
+	inner.On("Get", mock.Anything, "/sessions", mock.Anything).
+		Return([]byte(nil), 0, &SupabaseError{Message: "bad filter", StatusCode: http.StatusBadRequest}).Once()
+
+	_, _, err := client.Get(context.Background(), "/sessions", nil)
+	assert.Error(t, err)
+	// Client errors say nothing about Supabase health
+	assert.Equal(t, breaker.StateClosed, cb.State())
+	inner.AssertExpectations(t)
+}
+
+func TestResilientClient_FailsFastWhileOpen(t *testing.T) {
+	inner := &MockSupabaseClient{}
+	client, cb := newTestResilientClient(inner, 2)
+
+	inner.On("Get", mock.Anything, "/audit_logs", mock.Anything).
+		Return([]byte(nil), 0, &SupabaseError{Message: "down", StatusCode: http.StatusServiceUnavailable}).Twice()
+
+	_, _, err := client.Get(context.Background(), "/audit_logs", nil)
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, breaker.StateOpen, cb.State())
+
+	// No request reaches Supabase while the breaker is open
+	_, _, err = client.Get(context.Background(), "/audit_logs", nil)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.ErrorIs(t, err, domain.ErrServiceUnavailable)
+	inner.AssertExpectations(t)
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 250 * time.Millisecond}
+
+	for i := 0; i < 20; i++ {
+		assert.LessOrEqual(t, policy.Delay(1), 100*time.Millisecond)
+		assert.LessOrEqual(t, policy.Delay(2), 200*time.Millisecond)
+		assert.LessOrEqual(t, policy.Delay(5), 250*time.Millisecond)
+	}
+	assert.Zero(t, RetryPolicy{}.Delay(1))
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"audit-service/internal/domain"
+)
+
+// SavedQueryRepository stores the saved queries of the users. Lookups are scoped to the
+// organization of ctx.
+type SavedQueryRepository interface {
+	// CreateSavedQuery stores a new saved query
+	CreateSavedQuery(ctx context.Context, query domain.SavedQuery) error
+	// GetSavedQuery returns a saved query, or domain.ErrSavedQueryNotFound
+	GetSavedQuery(ctx context.Context, id string) (*domain.SavedQuery, error)
+	// ListSavedQueries returns the saved queries a user can see, by name: the user's own
+	// and those shared with the organization
+	ListSavedQueries(ctx context.Context, userID string) ([]domain.SavedQuery, error)
+	// UpdateSavedQuery replaces the name, description, scope and filter of a saved query, or
+	// returns domain.ErrSavedQueryNotFound
+	UpdateSavedQuery(ctx context.Context, query domain.SavedQuery) error
+	// DeleteSavedQuery removes a saved query, or returns domain.ErrSavedQueryNotFound
+	DeleteSavedQuery(ctx context.Context, id string) error
+}
+
+// savedQueryRow represents an audit_saved_queries row
+type savedQueryRow struct {
+	ID          string                  `json:"id"`
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	Scope       string                  `json:"scope"`
+	Filter      domain.SavedQueryFilter `json:"filter"`
+	CreatedBy   string                  `json:"created_by"`
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+	// Omitted, and stored as null, for queries of the default organization
+	OrganizationID string `json:"organization_id,omitempty"`
+}
+
+// newSavedQueryRow converts a domain saved query into a database row
+func newSavedQueryRow(query domain.SavedQuery) savedQueryRow {
+	return savedQueryRow{
+		ID:          query.ID,
+		Name:        query.Name,
+		Description: query.Description,
+		Scope:       string(query.Scope),
+		Filter:      query.Filter,
+		CreatedBy:   query.CreatedBy,
+		CreatedAt:   query.CreatedAt.UTC(),
+		UpdatedAt:   query.UpdatedAt.UTC(),
+
+		OrganizationID: query.OrganizationID,
+	}
+}
+
+// toSavedQuery converts a database row into a domain saved query
+func (row savedQueryRow) toSavedQuery() domain.SavedQuery {
+	return domain.SavedQuery{
+		ID:          row.ID,
+		Name:        row.Name,
+		Description: row.Description,
+		Scope:       domain.SavedQueryScope(row.Scope),
+		Filter:      row.Filter,
+		CreatedBy:   row.CreatedBy,
+		CreatedAt:   row.CreatedAt.UTC(),
+		UpdatedAt:   row.UpdatedAt.UTC(),
+
+		OrganizationID: row.OrganizationID,
+	}
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/cache"
+)
+
+// sessionCachingRepository answers session lookups of access checks from an in-memory cache.
+// Sessions are looked up on nearly every history request, and the owner of a session rarely
+// changes, so a stale owner is served for at most the TTL of the cache. Unknown sessions are
+// not cached, so a session is readable as soon as it is created.
+type sessionCachingRepository struct {
+	AuditRepository
+	sessions *cache.LRU[Session]
+}
+
+// NewSessionCachingRepository wraps a repository so sessions are looked up through the cache,
+// with concurrent lookups of the same session sharing one storage request
+func NewSessionCachingRepository(repo AuditRepository, sessions *cache.LRU[Session]) AuditRepository {
+	return &sessionCachingRepository{
+		AuditRepository: repo,
+		sessions:        sessions,
+	}
+}
+
+// GetSession returns the cached session, or fetches and caches it
+func (r *sessionCachingRepository) GetSession(ctx context.Context, sessionID domain.SessionID) (*Session, error) {
+	// Session IDs are UUIDs, which the storage backends match regardless of case
+	session, err := r.sessions.Load(ctx, strings.ToLower(sessionID.String()), func(ctx context.Context) (Session, error) {
+		session, err := r.AuditRepository.GetSession(ctx, sessionID)
+		if err != nil {
+			return Session{}, err
+		}
+		return *session, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/cache"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionCachingRepository(t *testing.T) {
+	plain, db := newTestSQLiteRepository(t)
+	clk := clock.NewFakeClock(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC))
+	sessions := cache.NewLRU[Session](100, time.Minute, clk)
+	repo := NewSessionCachingRepository(plain, sessions)
+	ctx := context.Background()
+
+	// Unknown sessions are not cached
+	_, err := repo.GetSession(ctx, testSQLiteSession)
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+	_, err = db.Exec("insert into sessions (id, user_id) values (?, ?)", testSQLiteSession, "owner-1")
+	require.NoError(t, err)
+
+	session, err := repo.GetSession(ctx, testSQLiteSession)
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserID("owner-1"), session.UserID)
+
+	// The owner is served from the cache until the entry expires
+	_, err = db.Exec("update sessions set user_id = 'owner-2' where id = ?", testSQLiteSession)
+	require.NoError(t, err)
+	session, err = repo.GetSession(ctx, domain.SessionID(strings.ToUpper(testSQLiteSession)))
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserID("owner-1"), session.UserID)
+
+	clk.Advance(time.Minute)
+	session, err = repo.GetSession(ctx, testSQLiteSession)
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserID("owner-2"), session.UserID)
+
+	assert.Equal(t, cache.LRUStats{Hits: 1, Misses: 3}, sessions.Stats())
+}
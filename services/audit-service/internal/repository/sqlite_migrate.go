@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"audit-service/pkg/clock"
+)
+
+// sqliteMigrations holds the schema of the SQLite backend, applied in file name order
+//
+//go:embed sqlite_migrations/*.sql
+var sqliteMigrations embed.FS
+
+// sqliteTracker keeps applied migrations in the schema_migrations table of a SQLite database
+type sqliteTracker struct {
+	db    *sql.DB
+	clock clock.Clock
+}
+
+func (t sqliteTracker) applied(ctx context.Context, version string) (bool, error) {
+	var exists int
+	err := t.db.QueryRowContext(ctx, "select count(*) from schema_migrations where version = ?", version).Scan(&exists)
+	return exists > 0, err
+}
+
+func (t sqliteTracker) apply(ctx context.Context, version, script string) error {
+	// Each migration and its bookkeeping row are committed together
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "insert into schema_migrations (version, applied_at) values (?, ?)",
+		version, formatSQLiteTime(t.clock.Now())); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// MigrateSQLite applies the embedded migrations that have not run yet and returns their names,
+// stamping each with the time of clk
+func MigrateSQLite(ctx context.Context, db *sql.DB, clk clock.Clock) ([]string, error) {
+	if _, err := db.ExecContext(ctx, `create table if not exists schema_migrations (
+		version text primary key,
+		applied_at text not null
+	)`); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	files, err := fs.Sub(sqliteMigrations, "sqlite_migrations")
+	if err != nil {
+		return nil, err
+	}
+	return runMigrations(ctx, files, sqliteTracker{db: db, clock: clk})
+}
@@ -0,0 +1,39 @@
+-- Schema of the embedded SQLite backend, mirroring the Supabase tables the service reads.
+-- Timestamps are stored as fixed-width UTC text so they sort chronologically.
+create table if not exists sessions (
+  id text primary key,
+  user_id text not null
+);
+
+create table if not exists session_shares (
+  id text primary key,
+  session_id text not null references sessions (id),
+  share_token_jti text not null,
+  -- JSON array of VIEW/COMMENT
+  permissions text not null default '["VIEW"]',
+  expires_at text,
+  revoked_at text
+);
+
+create index if not exists session_shares_jti_idx on session_shares (share_token_jti, session_id);
+
+create table if not exists audit_logs (
+  id text primary key,
+  session_id text not null,
+  user_id text not null,
+  type text not null,
+  "timestamp" text not null,
+  details text,
+  ip_address text,
+  user_agent text,
+  seq integer,
+  content_hash text,
+  prev_hash text,
+  chain_hash text,
+  redacted_at text
+);
+
+create index if not exists audit_logs_session_timestamp_idx on audit_logs (session_id, "timestamp", id);
+create unique index if not exists audit_logs_session_seq_idx on audit_logs (session_id, seq);
+create index if not exists audit_logs_type_timestamp_idx on audit_logs (type, "timestamp");
+create index if not exists audit_logs_user_id_idx on audit_logs (user_id);
@@ -0,0 +1,16 @@
+-- Outbox of audit events waiting to be forwarded, mirroring migrations/006_audit_outbox.sql
+create table if not exists audit_outbox (
+  id integer primary key autoincrement,
+  event_id text not null unique,
+  session_id text not null,
+  event_type text not null,
+  payload text not null,
+  attempts integer not null default 0,
+  available_at text not null,
+  locked_until text,
+  last_error text,
+  dead_at text,
+  created_at text not null
+);
+
+create index if not exists audit_outbox_pending_idx on audit_outbox (available_at, id) where dead_at is null;
@@ -0,0 +1,3 @@
+-- Indexes for cross-session admin queries, which have no session_id condition
+create index if not exists audit_logs_timestamp_idx on audit_logs ("timestamp", id);
+create index if not exists audit_logs_user_timestamp_idx on audit_logs (user_id, "timestamp");
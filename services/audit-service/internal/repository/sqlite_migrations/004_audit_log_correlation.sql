@@ -0,0 +1,5 @@
+-- Correlation and causal links between events, as in migrations/008_audit_log_correlation.sql
+alter table audit_logs add column correlation_id text;
+alter table audit_logs add column parent_event_id text;
+
+create index if not exists audit_logs_session_correlation_idx on audit_logs (session_id, correlation_id, "timestamp");
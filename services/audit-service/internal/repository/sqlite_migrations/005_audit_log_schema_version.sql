@@ -0,0 +1,2 @@
+-- Details schema version of each event, as in migrations/009_audit_log_schema_version.sql
+alter table audit_logs add column schema_version integer;
@@ -0,0 +1,9 @@
+-- Custom event types, mirroring migrations/010_audit_event_types.sql
+create table if not exists audit_event_types (
+  name text primary key,
+  display_name text not null,
+  severity text not null,
+  schema text,
+  created_by text not null,
+  created_at text not null
+);
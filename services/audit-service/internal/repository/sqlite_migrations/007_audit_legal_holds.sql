@@ -0,0 +1,13 @@
+-- Legal holds, mirroring migrations/011_audit_legal_holds.sql
+create table if not exists audit_legal_holds (
+  id text primary key,
+  scope text not null,
+  target text not null,
+  reason text not null,
+  placed_by text not null,
+  placed_at text not null,
+  released_by text,
+  released_at text
+);
+
+create index if not exists audit_legal_holds_active_idx on audit_legal_holds (scope, target) where released_at is null;
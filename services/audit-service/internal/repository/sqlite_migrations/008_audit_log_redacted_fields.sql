@@ -0,0 +1,2 @@
+-- Paths of redacted details values as a JSON array, as in migrations/012_audit_log_redacted_fields.sql
+alter table audit_logs add column redacted_fields text;
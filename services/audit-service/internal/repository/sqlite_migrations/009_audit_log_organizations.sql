@@ -0,0 +1,6 @@
+-- Organization of entries and legal holds, as in migrations/013_audit_log_organizations.sql
+alter table audit_logs add column organization_id text;
+
+create index if not exists audit_logs_organization_timestamp_idx on audit_logs (organization_id, "timestamp" desc, id desc);
+
+alter table audit_legal_holds add column organization_id text;
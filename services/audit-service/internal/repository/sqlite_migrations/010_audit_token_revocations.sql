@@ -0,0 +1,15 @@
+-- Token revocations, mirroring migrations/015_audit_token_revocations.sql
+create table if not exists audit_token_revocations (
+  id text primary key,
+  kind text not null,
+  target text not null,
+  reason text not null,
+  revoked_by text not null,
+  revoked_at text not null,
+  expires_at text,
+  lifted_by text,
+  lifted_at text,
+  organization_id text
+);
+
+create index if not exists audit_token_revocations_active_idx on audit_token_revocations (kind, target) where lifted_at is null;
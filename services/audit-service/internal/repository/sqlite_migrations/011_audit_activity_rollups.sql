@@ -0,0 +1,15 @@
+-- Activity rollups, mirroring migrations/016_audit_activity_rollups.sql
+create table if not exists audit_activity_rollups (
+  granularity text not null,
+  bucket_start text not null,
+  session_id text not null,
+  user_id text not null,
+  event_count integer not null,
+  -- JSON object of event counts by type
+  by_type text not null default '{}',
+  organization_id text
+);
+
+create index if not exists audit_activity_rollups_session_idx on audit_activity_rollups (session_id, granularity, bucket_start);
+create index if not exists audit_activity_rollups_bucket_idx on audit_activity_rollups (bucket_start);
+create index if not exists audit_activity_rollups_user_idx on audit_activity_rollups (user_id);
@@ -0,0 +1,14 @@
+-- Slide and shape references of entries, as in migrations/017_audit_log_slide_refs.sql
+alter table audit_logs add column slide_id text;
+alter table audit_logs add column shape_id text;
+
+create index if not exists audit_logs_session_slide_idx on audit_logs (session_id, slide_id, "timestamp" desc, id desc)
+  where slide_id is not null;
+create index if not exists audit_logs_session_shape_idx on audit_logs (session_id, shape_id, "timestamp" desc, id desc)
+  where shape_id is not null;
+
+update audit_logs
+set slide_id = case when json_type(details, '$.slideId') = 'text' then json_extract(details, '$.slideId') end,
+    shape_id = case when json_type(details, '$.shapeId') = 'text' then json_extract(details, '$.shapeId') end
+where json_valid(details)
+  and (json_type(details, '$.slideId') = 'text' or json_type(details, '$.shapeId') = 'text');
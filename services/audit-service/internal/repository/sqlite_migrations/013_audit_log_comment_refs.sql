@@ -0,0 +1,14 @@
+-- Comment and thread references of entries, as in migrations/018_audit_log_comment_refs.sql
+alter table audit_logs add column comment_id text;
+alter table audit_logs add column thread_id text;
+
+create index if not exists audit_logs_session_thread_idx on audit_logs (session_id, thread_id, "timestamp" desc, id desc)
+  where thread_id is not null;
+create index if not exists audit_logs_session_comment_idx on audit_logs (session_id, comment_id, "timestamp" desc, id desc)
+  where comment_id is not null;
+
+update audit_logs
+set comment_id = case when json_type(details, '$.commentId') = 'text' then json_extract(details, '$.commentId') end,
+    thread_id = case when json_type(details, '$.threadId') = 'text' then json_extract(details, '$.threadId') end
+where json_valid(details)
+  and (json_type(details, '$.commentId') = 'text' or json_type(details, '$.threadId') = 'text');
@@ -0,0 +1,9 @@
+-- Slides of the editor, mirroring the Supabase slides table that numbers the slides of the
+-- heatmap, as in migrations/019_audit_slide_heatmap.sql
+create table if not exists slides (
+  id text primary key,
+  session_id text not null,
+  slide_number integer not null
+);
+
+create index if not exists slides_session_idx on slides (session_id);
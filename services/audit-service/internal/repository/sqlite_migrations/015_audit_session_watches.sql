@@ -0,0 +1,12 @@
+-- Session watches, mirroring migrations/021_audit_session_watches.sql
+create table if not exists audit_session_watches (
+  session_id text not null,
+  user_id text not null,
+  -- JSON array of the watched event types
+  event_types text not null,
+  created_at text not null,
+  organization_id text,
+  primary key (session_id, user_id)
+);
+
+create index if not exists audit_session_watches_user_idx on audit_session_watches (user_id, created_at desc);
@@ -0,0 +1,35 @@
+-- Scheduled reports and their runs, mirroring migrations/022_audit_reports.sql
+create table if not exists audit_reports (
+  id text primary key,
+  name text not null,
+  kind text not null,
+  session_id text,
+  schedule text not null,
+  format text not null,
+  delivery text not null,
+  target text not null,
+  created_by text not null,
+  created_at text not null,
+  next_run_at text not null,
+  last_run_at text,
+  organization_id text
+);
+
+create index if not exists audit_reports_next_run_idx on audit_reports (next_run_at);
+
+create table if not exists audit_report_runs (
+  id text primary key,
+  report_id text not null references audit_reports (id) on delete cascade,
+  triggered_by text not null,
+  status text not null,
+  period_from text not null,
+  period_to text not null,
+  started_at text not null,
+  finished_at text not null,
+  row_count integer not null default 0,
+  location text,
+  error text,
+  organization_id text
+);
+
+create index if not exists audit_report_runs_report_idx on audit_report_runs (report_id, started_at desc);
@@ -0,0 +1,2 @@
+-- Server time each entry arrived at, as in migrations/025_audit_log_received_at.sql
+alter table audit_logs add column received_at text;
@@ -0,0 +1,14 @@
+-- Saved queries, mirroring migrations/026_audit_saved_queries.sql
+create table if not exists audit_saved_queries (
+  id text primary key,
+  name text not null,
+  description text not null default '',
+  scope text not null,
+  filter text not null default '{}',
+  created_by text not null,
+  created_at text not null,
+  updated_at text not null,
+  organization_id text
+);
+
+create index if not exists audit_saved_queries_created_by_idx on audit_saved_queries (created_by);
@@ -0,0 +1,13 @@
+-- Event labels, mirroring migrations/027_audit_event_labels.sql
+create table if not exists audit_event_labels (
+  event_id text primary key references audit_logs (id) on delete cascade,
+  session_id text not null,
+  -- JSON array of the sorted labels
+  labels text not null default '[]',
+  version integer not null,
+  updated_by text not null,
+  updated_at text not null,
+  organization_id text
+);
+
+create index if not exists audit_event_labels_session_idx on audit_event_labels (session_id);
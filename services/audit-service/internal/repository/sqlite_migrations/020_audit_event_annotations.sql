@@ -0,0 +1,14 @@
+-- Event annotations, mirroring migrations/028_audit_event_annotations.sql
+create table if not exists audit_event_annotations (
+  id text primary key,
+  event_id text not null references audit_logs (id) on delete cascade,
+  session_id text not null,
+  author_id text not null,
+  body text not null,
+  version integer not null,
+  created_at text not null,
+  updated_at text not null,
+  organization_id text
+);
+
+create index if not exists audit_event_annotations_event_idx on audit_event_annotations (event_id, created_at);
@@ -0,0 +1,2084 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/requestid"
+	"audit-service/pkg/tenant"
+
+	_ "github.com/mattn/go-sqlite3" // Registers the sqlite3 driver
+	"go.uber.org/zap"
+)
+
+// sqliteTimeFormat is fixed-width UTC so stored timestamps sort chronologically as text
+const sqliteTimeFormat = "2006-01-02T15:04:05.000000Z"
+
+// sqliteNotOnHold matches audit_logs rows that no active legal hold covers
+const sqliteNotOnHold = `not exists (select 1 from audit_legal_holds h
+	where h.released_at is null
+		and ((h.scope = 'session' and h.target = audit_logs.session_id) or (h.scope = 'user' and h.target = audit_logs.user_id)))`
+
+// sqliteColumns selects an audit_logs row in the order scanned by scanSQLiteEntry
+const sqliteColumns = `id, session_id, user_id, type, "timestamp", details,
+	coalesce(ip_address, ''), coalesce(user_agent, ''), coalesce(correlation_id, ''), coalesce(parent_event_id, ''),
+	coalesce(schema_version, 0), redacted_fields, coalesce(organization_id, ''), coalesce(slide_id, ''), coalesce(shape_id, ''),
+	coalesce(comment_id, ''), coalesce(thread_id, ''), received_at`
+
+// sqliteOrganizationCondition restricts a query to the organization ctx is scoped to; unscoped
+// contexts match every organization. Entries of the default organization store no organization.
+func sqliteOrganizationCondition(ctx context.Context) (string, []interface{}) {
+	organizationID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return "true", nil
+	}
+	return "coalesce(organization_id, '') = ?", []interface{}{organizationID}
+}
+
+// erasedDetailFields are removed from details when a user is anonymized, as in migrations/005_erase_user_audit_logs.sql
+var erasedDetailFields = []string{"text", "comment", "email", "name", "userName", "userEmail"}
+
+// sqliteRepository implements the AuditRepository interface over an embedded SQLite database
+type sqliteRepository struct {
+	db     *sql.DB
+	clock  clock.Clock
+	logger *zap.Logger
+	// outbox makes CreateEvents queue every entry for forwarding by the relay worker
+	outbox bool
+}
+
+// NewSQLiteRepository creates an audit repository backed by a migrated SQLite database
+func NewSQLiteRepository(db *sql.DB, clk clock.Clock, logger *zap.Logger) AuditRepository {
+	return newSQLiteRepository(db, clk, logger, false)
+}
+
+// newSQLiteRepository creates a SQLite repository, optionally writing outbox messages with each entry
+func newSQLiteRepository(db *sql.DB, clk clock.Clock, logger *zap.Logger, outbox bool) *sqliteRepository {
+	return &sqliteRepository{
+		db:     db,
+		clock:  clk,
+		logger: logger,
+		outbox: outbox,
+	}
+}
+
+// OpenSQLite opens the database file at path, creating it if needed, and applies pending migrations
+func OpenSQLite(ctx context.Context, path string, clk clock.Clock, logger *zap.Logger) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL", path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	// SQLite allows a single writer; one connection also keeps in-memory databases alive
+	db.SetMaxOpenConns(1)
+
+	applied, err := MigrateSQLite(ctx, db, clk)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if len(applied) > 0 {
+		logger.Info("applied SQLite migrations", zap.Strings("versions", applied))
+	}
+
+	return db, nil
+}
+
+// formatSQLiteTime converts a time into its stored representation
+func formatSQLiteTime(t time.Time) string {
+	return t.UTC().Truncate(time.Microsecond).Format(sqliteTimeFormat)
+}
+
+// parseSQLiteTime converts a stored timestamp back into a time
+func parseSQLiteTime(value string) (time.Time, error) {
+	return time.Parse(sqliteTimeFormat, value)
+}
+
+// FindBySessionID retrieves audit logs for a specific session
+func (r *sqliteRepository) FindBySessionID(ctx context.Context, sessionID domain.SessionID, page domain.PaginationParams) ([]domain.AuditEntry, int, error) {
+	return r.QueryEvents(ctx, sessionID, domain.EventFilter{}, page)
+}
+
+// QueryEvents retrieves audit logs for a session, or for all sessions when sessionID is empty, matching the given filter
+func (r *sqliteRepository) QueryEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, page domain.PaginationParams) ([]domain.AuditEntry, int, error) {
+	// Test sessions have no persisted events
+	if sessionID.IsTestSession() {
+		return []domain.AuditEntry{}, 0, nil
+	}
+
+	where, args := buildSQLiteConditions(ctx, sessionID, filter, page)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "select count(*) from audit_logs where "+where, args...).Scan(&total); err != nil {
+		r.logger.Error("failed to count audit logs",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, 0, fmt.Errorf("failed to query audit logs: %w", err)
+	}
+
+	// The id tie-breaker keeps the order stable for entries sharing a timestamp
+	query := fmt.Sprintf(`select %s from audit_logs where %s order by "timestamp" desc, id desc limit %d offset %d`,
+		sqliteColumns, where, page.Limit, page.Offset)
+
+	entries, err := r.queryEntries(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("failed to query audit logs",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, 0, fmt.Errorf("failed to query audit logs: %w", err)
+	}
+
+	return entries, total, nil
+}
+
+// buildSQLiteConditions translates a session, filter and keyset cursor into a SQL condition and its arguments.
+// An empty session ID matches every session; only entries of the organization ctx is scoped to match.
+func buildSQLiteConditions(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, page domain.PaginationParams) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if sessionID != "" {
+		conditions = append(conditions, "session_id = ?")
+		args = append(args, strings.ToLower(sessionID.String()))
+	}
+	if condition, organizationArgs := sqliteOrganizationCondition(ctx); organizationArgs != nil {
+		conditions = append(conditions, condition)
+		args = append(args, organizationArgs...)
+	}
+
+	if len(filter.Types) > 0 {
+		conditions = append(conditions, fmt.Sprintf("type in (%s)", placeholders(len(filter.Types))))
+		for _, t := range filter.Types {
+			args = append(args, t)
+		}
+	}
+	if filter.UserID != "" {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, `"timestamp" >= ?`)
+		args = append(args, formatSQLiteTime(filter.From))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, `"timestamp" <= ?`)
+		args = append(args, formatSQLiteTime(filter.To))
+	}
+	// SQLite has no JSONB full-text search, so the raw details are matched as text
+	if filter.Search != "" {
+		conditions = append(conditions, "details like '%' || ? || '%'")
+		args = append(args, filter.Search)
+	}
+	if filter.CorrelationID != "" {
+		conditions = append(conditions, "correlation_id = ?")
+		args = append(args, filter.CorrelationID)
+	}
+	if filter.SlideID != "" {
+		conditions = append(conditions, "slide_id = ?")
+		args = append(args, filter.SlideID)
+	}
+	if filter.ShapeID != "" {
+		conditions = append(conditions, "shape_id = ?")
+		args = append(args, filter.ShapeID)
+	}
+	if filter.CommentID != "" {
+		conditions = append(conditions, "comment_id = ?")
+		args = append(args, filter.CommentID)
+	}
+	if filter.ThreadID != "" {
+		conditions = append(conditions, "thread_id = ?")
+		args = append(args, filter.ThreadID)
+	}
+	if len(filter.Labels) > 0 {
+		conditions = append(conditions, fmt.Sprintf(`id in (select l.event_id from audit_event_labels l, json_each(l.labels) label
+			where label.value in (%s))`, placeholders(len(filter.Labels))))
+		for _, label := range filter.Labels {
+			args = append(args, label)
+		}
+	}
+
+	// Keyset condition: strictly older than the cursor, or same timestamp with a lower id
+	if page.Cursor != nil {
+		timestamp := formatSQLiteTime(page.Cursor.Timestamp)
+		conditions = append(conditions, `("timestamp" < ? or ("timestamp" = ? and id < ?))`)
+		args = append(args, timestamp, timestamp, strings.ToLower(page.Cursor.ID))
+	}
+
+	if len(conditions) == 0 {
+		return "true", args
+	}
+	return strings.Join(conditions, " and "), args
+}
+
+// placeholders returns n comma-separated query placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// queryEntries runs a query selecting sqliteColumns
+func (r *sqliteRepository) queryEntries(ctx context.Context, query string, args ...interface{}) ([]domain.AuditEntry, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []domain.AuditEntry{}
+	for rows.Next() {
+		entry, err := scanSQLiteEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// scanSQLiteEntry reads a row selected with sqliteColumns followed by any extra destinations
+func scanSQLiteEntry(rows *sql.Rows, extra ...interface{}) (domain.AuditEntry, error) {
+	var (
+		entry     domain.AuditEntry
+		timestamp string
+		details   sql.NullString
+		redacted  sql.NullString
+		received  sql.NullString
+	)
+
+	dest := append([]interface{}{
+		&entry.ID,
+		&entry.SessionID,
+		&entry.UserID,
+		&entry.Type,
+		&timestamp,
+		&details,
+		&entry.IPAddress,
+		&entry.UserAgent,
+		&entry.CorrelationID,
+		&entry.ParentEventID,
+		&entry.SchemaVersion,
+		&redacted,
+		&entry.OrganizationID,
+		&entry.SlideID,
+		&entry.ShapeID,
+		&entry.CommentID,
+		&entry.ThreadID,
+		&received,
+	}, extra...)
+	if err := rows.Scan(dest...); err != nil {
+		return domain.AuditEntry{}, err
+	}
+
+	parsed, err := parseSQLiteTime(timestamp)
+	if err != nil {
+		return domain.AuditEntry{}, fmt.Errorf("invalid timestamp for audit log %s: %w", entry.ID, err)
+	}
+	entry.Timestamp = parsed
+	if received.Valid {
+		receivedAt, err := parseSQLiteTime(received.String)
+		if err != nil {
+			return domain.AuditEntry{}, fmt.Errorf("invalid received time for audit log %s: %w", entry.ID, err)
+		}
+		entry.ReceivedAt = &receivedAt
+	}
+	if details.Valid {
+		entry.Details = json.RawMessage(details.String)
+	}
+	// Redacted fields are stored as a JSON array
+	if redacted.Valid {
+		if err := json.Unmarshal([]byte(redacted.String), &entry.RedactedFields); err != nil {
+			return domain.AuditEntry{}, fmt.Errorf("invalid redacted fields for audit log %s: %w", entry.ID, err)
+		}
+	}
+
+	return entry, nil
+}
+
+// GetSessionStats aggregates the audit activity of a session in the database
+func (r *sqliteRepository) GetSessionStats(ctx context.Context, sessionID domain.SessionID) (*domain.SessionStats, error) {
+	stats := &domain.SessionStats{
+		SessionID:     sessionID.String(),
+		ByType:        map[string]int{},
+		EditsPerSlide: map[string]int{},
+	}
+
+	// Test sessions have no persisted events
+	if sessionID.IsTestSession() {
+		return stats, nil
+	}
+
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	args := append([]interface{}{strings.ToLower(sessionID.String())}, organizationArgs...)
+
+	var first, last sql.NullString
+	if err := r.db.QueryRowContext(ctx, `select count(*), count(distinct user_id), min("timestamp"), max("timestamp")
+		from audit_logs where session_id = ? and `+organization, args...).
+		Scan(&stats.TotalEvents, &stats.Contributors, &first, &last); err != nil {
+		r.logger.Error("failed to fetch session stats",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch session stats: %w", err)
+	}
+
+	for _, bound := range []struct {
+		value sql.NullString
+		dest  **time.Time
+	}{{first, &stats.FirstActivity}, {last, &stats.LastActivity}} {
+		if !bound.value.Valid {
+			continue
+		}
+		parsed, err := parseSQLiteTime(bound.value.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse session stats: %w", err)
+		}
+		*bound.dest = &parsed
+	}
+
+	if err := r.countInto(ctx, stats.ByType,
+		"select type, count(*) from audit_logs where session_id = ? and "+organization+" group by type", args...); err != nil {
+		return nil, fmt.Errorf("failed to fetch session stats: %w", err)
+	}
+	if err := r.countInto(ctx, stats.EditsPerSlide, `select cast(json_extract(details, '$.slideId') as text), count(*)
+		from audit_logs
+		where session_id = ? and `+organization+` and type = 'edit' and json_extract(details, '$.slideId') is not null
+		group by 1`, args...); err != nil {
+		return nil, fmt.Errorf("failed to fetch session stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// countInto runs a query returning key and count pairs and stores them in counts
+func (r *sqliteRepository) countInto(ctx context.Context, counts map[string]int, query string, args ...interface{}) error {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		var n int
+		if err := rows.Scan(&key, &n); err != nil {
+			return err
+		}
+		counts[key] = n
+	}
+
+	return rows.Err()
+}
+
+// PurgeEvents deletes up to limit events of a type recorded before the cutoff
+func (r *sqliteRepository) PurgeEvents(ctx context.Context, eventType string, before time.Time, limit int) ([]domain.PurgedEvents, error) {
+	purged, err := r.deleteWhere(ctx, sqliteNotOnHold+` and type = ? and "timestamp" < ? limit ?`, eventType, formatSQLiteTime(before), limit)
+	if err != nil {
+		r.logger.Error("failed to purge audit logs",
+			requestid.Field(ctx),
+			zap.String("type", eventType),
+			zap.Time("before", before),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to purge audit logs: %w", err)
+	}
+
+	return purged, nil
+}
+
+// FindChain returns up to limit chained entries of a session with a sequence number above afterSeq
+func (r *sqliteRepository) FindChain(ctx context.Context, sessionID domain.SessionID, afterSeq int64, limit int) ([]domain.ChainedEntry, error) {
+	// Test sessions have no persisted events
+	if sessionID.IsTestSession() {
+		return []domain.ChainedEntry{}, nil
+	}
+
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	args := append([]interface{}{strings.ToLower(sessionID.String()), afterSeq}, organizationArgs...)
+	rows, err := r.db.QueryContext(ctx, `select `+sqliteColumns+`,
+		seq, coalesce(content_hash, ''), coalesce(prev_hash, ''), coalesce(chain_hash, ''), redacted_at
+		from audit_logs
+		where session_id = ? and seq > ? and `+organization+`
+		order by seq asc
+		limit ?`, append(args, limit)...)
+	if err != nil {
+		r.logger.Error("failed to fetch audit log chain",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch audit log chain: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []domain.ChainedEntry{}
+	for rows.Next() {
+		var chained domain.ChainedEntry
+		var redactedAt sql.NullString
+		entry, err := scanSQLiteEntry(rows, &chained.Seq, &chained.ContentHash, &chained.PrevHash, &chained.ChainHash, &redactedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse audit log chain: %w", err)
+		}
+		chained.AuditEntry = entry
+		if redactedAt.Valid {
+			parsed, err := parseSQLiteTime(redactedAt.String)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse audit log chain: %w", err)
+			}
+			chained.RedactedAt = &parsed
+		}
+		entries = append(entries, chained)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to parse audit log chain: %w", err)
+	}
+
+	return entries, nil
+}
+
+// FindEvent returns the event with the given ID
+func (r *sqliteRepository) FindEvent(ctx context.Context, eventID domain.EventID) (*domain.AuditEntry, error) {
+	// Events of other organizations are reported as not found
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	entries, err := r.queryEntries(ctx, `select `+sqliteColumns+` from audit_logs where id = ? and `+organization,
+		append([]interface{}{strings.ToLower(eventID.String())}, organizationArgs...)...)
+	if err != nil {
+		r.logger.Error("failed to fetch audit log",
+			requestid.Field(ctx),
+			zap.Stringer("event_id", eventID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch audit log: %w", err)
+	}
+
+	if len(entries) == 0 {
+		return nil, domain.ErrEventNotFound
+	}
+
+	return &entries[0], nil
+}
+
+// ExistingEventIDs returns those of the IDs that are already stored, in any organization
+func (r *sqliteRepository) ExistingEventIDs(ctx context.Context, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = strings.ToLower(id)
+	}
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf("select id from audit_logs where id in (%s)", placeholders(len(ids))), args...)
+	if err != nil {
+		r.logger.Error("failed to look up audit log IDs",
+			requestid.Field(ctx),
+			zap.Int("count", len(ids)),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to look up audit log IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var existing []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log IDs: %w", err)
+		}
+		existing = append(existing, id)
+	}
+	return existing, rows.Err()
+}
+
+// FindExpiredEvents returns up to limit of the oldest events of a type recorded before the cutoff
+// that are not under a legal hold
+func (r *sqliteRepository) FindExpiredEvents(ctx context.Context, eventType string, before time.Time, limit int) ([]domain.AuditEntry, error) {
+	entries, err := r.queryEntries(ctx, `select `+sqliteColumns+`
+		from audit_logs
+		where type = ? and "timestamp" < ? and `+sqliteNotOnHold+`
+		order by "timestamp" asc, id asc
+		limit ?`, eventType, formatSQLiteTime(before), limit)
+	if err != nil {
+		r.logger.Error("failed to fetch expired audit logs",
+			requestid.Field(ctx),
+			zap.String("type", eventType),
+			zap.Time("before", before),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch expired audit logs: %w", err)
+	}
+
+	return entries, nil
+}
+
+// DeleteEvents deletes the events with the given IDs
+func (r *sqliteRepository) DeleteEvents(ctx context.Context, ids []string) ([]domain.PurgedEvents, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = strings.ToLower(id)
+	}
+
+	// Entries placed on hold after they were archived are kept
+	deleted, err := r.deleteWhere(ctx, fmt.Sprintf("id in (%s) and %s", placeholders(len(ids)), sqliteNotOnHold), args...)
+	if err != nil {
+		r.logger.Error("failed to delete audit logs",
+			requestid.Field(ctx),
+			zap.Int("count", len(ids)),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to delete audit logs: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// EraseUserEvents anonymizes or deletes up to limit entries of a user across all sessions
+func (r *sqliteRepository) EraseUserEvents(ctx context.Context, userID domain.UserID, mode domain.ErasureMode, overrideHolds bool, limit int) ([]domain.PurgedEvents, error) {
+	var (
+		affected []domain.PurgedEvents
+		err      error
+	)
+
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	condition := "user_id = ? and " + organization
+	if !overrideHolds {
+		condition += " and " + sqliteNotOnHold
+	}
+	args := append(append([]interface{}{userID.String()}, organizationArgs...), limit)
+
+	switch mode {
+	case domain.ErasureDelete:
+		affected, err = r.deleteWhere(ctx, condition+" limit ?", args...)
+	case domain.ErasureAnonymize:
+		affected, err = r.anonymize(ctx, condition+" limit ?", args...)
+	default:
+		err = fmt.Errorf("unknown erasure mode %s", mode)
+	}
+	if err != nil {
+		r.logger.Error("failed to erase user audit logs",
+			requestid.Field(ctx),
+			zap.String("mode", string(mode)),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to erase user audit logs: %w", err)
+	}
+
+	return affected, nil
+}
+
+// anonymize blanks the personal data of the entries matching condition, keeping their hashes
+func (r *sqliteRepository) anonymize(ctx context.Context, condition string, args ...interface{}) ([]domain.PurgedEvents, error) {
+	paths := make([]string, len(erasedDetailFields))
+	for i, field := range erasedDetailFields {
+		paths[i] = fmt.Sprintf("'$.%s'", field)
+	}
+
+	var affected []domain.PurgedEvents
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		ids, counts, err := selectTargets(ctx, tx, condition, args...)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		updateArgs := append([]interface{}{formatSQLiteTime(r.clock.Now())}, ids...)
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`update audit_logs
+			set user_id = 'redacted',
+				ip_address = null,
+				user_agent = null,
+				details = json_remove(coalesce(details, '{}'), %s),
+				redacted_at = ?
+			where id in (%s)`, strings.Join(paths, ", "), placeholders(len(ids))), updateArgs...); err != nil {
+			return err
+		}
+
+		affected = counts
+		return nil
+	})
+
+	return affected, err
+}
+
+// deleteWhere deletes the entries matching condition and returns the number removed per session
+func (r *sqliteRepository) deleteWhere(ctx context.Context, condition string, args ...interface{}) ([]domain.PurgedEvents, error) {
+	var deleted []domain.PurgedEvents
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		ids, counts, err := selectTargets(ctx, tx, condition, args...)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("delete from audit_logs where id in (%s)", placeholders(len(ids))), ids...); err != nil {
+			return err
+		}
+
+		deleted = counts
+		return nil
+	})
+
+	return deleted, err
+}
+
+// selectTargets returns the IDs of the entries matching condition and their count per session and organization
+func selectTargets(ctx context.Context, tx *sql.Tx, condition string, args ...interface{}) ([]interface{}, []domain.PurgedEvents, error) {
+	rows, err := tx.QueryContext(ctx, "select id, session_id, coalesce(organization_id, '') from audit_logs where "+condition, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	type group struct{ sessionID, organizationID string }
+	var ids []interface{}
+	perGroup := map[group]int{}
+	for rows.Next() {
+		var id string
+		var g group
+		if err := rows.Scan(&id, &g.sessionID, &g.organizationID); err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, id)
+		perGroup[g]++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	counts := make([]domain.PurgedEvents, 0, len(perGroup))
+	for g, n := range perGroup {
+		counts = append(counts, domain.PurgedEvents{SessionID: g.sessionID, OrganizationID: g.organizationID, Deleted: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].SessionID != counts[j].SessionID {
+			return counts[i].SessionID < counts[j].SessionID
+		}
+		return counts[i].OrganizationID < counts[j].OrganizationID
+	})
+
+	return ids, counts, nil
+}
+
+// inTx runs fn in a transaction that is committed when fn succeeds
+func (r *sqliteRepository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetSession retrieves session information
+func (r *sqliteRepository) GetSession(ctx context.Context, sessionID domain.SessionID) (*Session, error) {
+	var session Session
+	err := r.db.QueryRowContext(ctx, "select id, user_id from sessions where id = ?", strings.ToLower(sessionID.String())).
+		Scan(&session.ID, &session.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrSessionNotFound
+	}
+	if err != nil {
+		r.logger.Error("failed to fetch session",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// GetActiveShare returns the unrevoked share link issued with the token ID for a session
+func (r *sqliteRepository) GetActiveShare(ctx context.Context, tokenJTI string, sessionID domain.SessionID) (*domain.Share, error) {
+	var (
+		row         shareRow
+		permissions string
+		expiresAt   sql.NullString
+	)
+	err := r.db.QueryRowContext(ctx, `select id, session_id, share_token_jti, permissions, expires_at
+		from session_shares
+		where share_token_jti = ? and session_id = ? and revoked_at is null
+		limit 1`, tokenJTI, strings.ToLower(sessionID.String())).
+		Scan(&row.ID, &row.SessionID, &row.ShareTokenJTI, &permissions, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrShareNotFound
+	}
+	if err != nil {
+		r.logger.Error("failed to look up share link",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to look up share link: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(permissions), &row.Permissions); err != nil {
+		return nil, fmt.Errorf("failed to parse share link: %w", err)
+	}
+	if expiresAt.Valid {
+		parsed, err := parseSQLiteTime(expiresAt.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse share link: %w", err)
+		}
+		row.ExpiresAt = &parsed
+	}
+
+	return row.toShare(), nil
+}
+
+// CreateEvents inserts audit entries in a single transaction, extending the hash chain of each session
+func (r *sqliteRepository) CreateEvents(ctx context.Context, entries []domain.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := checkOrganization(ctx, entries); err != nil {
+		return err
+	}
+
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		// Chain heads of the sessions touched by this batch
+		type head struct {
+			seq  int64
+			hash string
+		}
+		heads := map[string]*head{}
+
+		for _, entry := range entries {
+			entry.ID = strings.ToLower(entry.ID)
+			entry.SessionID = strings.ToLower(entry.SessionID)
+			entry.ParentEventID = strings.ToLower(entry.ParentEventID)
+
+			row, err := newAuditLogRow(entry)
+			if err != nil {
+				return fmt.Errorf("failed to prepare audit log %s: %w", entry.ID, err)
+			}
+
+			// Same numbering and hashing as the audit_logs_chain trigger in migrations/004_audit_log_hash_chain.sql
+			h, ok := heads[row.SessionID]
+			if !ok {
+				h = &head{}
+				err := tx.QueryRowContext(ctx, `select seq, chain_hash from audit_logs
+					where session_id = ? and seq is not null
+					order by seq desc limit 1`, row.SessionID).Scan(&h.seq, &h.hash)
+				if err != nil && !errors.Is(err, sql.ErrNoRows) {
+					return err
+				}
+				heads[row.SessionID] = h
+			}
+			prevHash := h.hash
+			h.seq++
+			h.hash = domain.ChainHash(prevHash, row.ContentHash)
+
+			var details interface{}
+			if len(row.Details) > 0 {
+				details = string(row.Details)
+			}
+			var redacted interface{}
+			if len(row.RedactedFields) > 0 {
+				encoded, err := json.Marshal(row.RedactedFields)
+				if err != nil {
+					return err
+				}
+				redacted = string(encoded)
+			}
+			var receivedAt interface{}
+			if entry.ReceivedAt != nil {
+				receivedAt = formatSQLiteTime(*entry.ReceivedAt)
+			}
+
+			if _, err := tx.ExecContext(ctx, `insert into audit_logs
+				(id, session_id, user_id, type, "timestamp", details, ip_address, user_agent, correlation_id, parent_event_id,
+					schema_version, redacted_fields, organization_id, slide_id, shape_id, comment_id, thread_id, received_at,
+					seq, content_hash, prev_hash, chain_hash)
+				values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				row.ID, row.SessionID, row.UserID, row.Type, formatSQLiteTime(entry.Timestamp), details,
+				nullIfEmpty(row.IPAddress), nullIfEmpty(row.UserAgent), nullIfEmpty(row.CorrelationID), nullIfEmpty(row.ParentEventID),
+				nullIfZero(row.SchemaVersion), redacted, nullIfEmpty(row.OrganizationID), nullIfEmpty(row.SlideID), nullIfEmpty(row.ShapeID),
+				nullIfEmpty(row.CommentID), nullIfEmpty(row.ThreadID), receivedAt, h.seq, row.ContentHash, prevHash, h.hash); err != nil {
+				return err
+			}
+
+			if r.outbox {
+				message, err := newOutboxRow(entry)
+				if err != nil {
+					return err
+				}
+				now := formatSQLiteTime(r.clock.Now())
+				if _, err := tx.ExecContext(ctx, `insert into audit_outbox
+					(event_id, session_id, event_type, payload, available_at, created_at)
+					values (?, ?, ?, ?, ?, ?)
+					on conflict (event_id) do nothing`,
+					message.EventID, message.SessionID, message.EventType, string(message.Payload), now, now); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("failed to insert audit logs",
+			requestid.Field(ctx),
+			zap.Int("count", len(entries)),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to insert audit logs: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimOutbox leases up to limit due messages for the given duration and counts the attempt
+func (r *sqliteRepository) ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error) {
+	var messages []domain.OutboxMessage
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		now := r.clock.Now()
+		rows, err := tx.QueryContext(ctx, `select id, event_id, session_id, event_type, payload, attempts, created_at
+			from audit_outbox
+			where dead_at is null and available_at <= ? and (locked_until is null or locked_until < ?)
+			order by id
+			limit ?`, formatSQLiteTime(now), formatSQLiteTime(now), limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		var ids []interface{}
+		for rows.Next() {
+			var message domain.OutboxMessage
+			var payload, createdAt string
+			if err := rows.Scan(&message.ID, &message.EventID, &message.SessionID, &message.Type, &payload, &message.Attempts, &createdAt); err != nil {
+				return err
+			}
+			if message.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+				return fmt.Errorf("invalid created_at for outbox message %d: %w", message.ID, err)
+			}
+			message.Payload = json.RawMessage(payload)
+			message.Attempts++
+			messages = append(messages, message)
+			ids = append(ids, message.ID)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		rows.Close()
+		if len(ids) == 0 {
+			return nil
+		}
+
+		args := append([]interface{}{formatSQLiteTime(now.Add(lease))}, ids...)
+		_, err = tx.ExecContext(ctx, `update audit_outbox set locked_until = ?, attempts = attempts + 1
+			where id in (`+placeholders(len(ids))+`)`, args...)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// CompleteOutbox removes delivered messages
+func (r *sqliteRepository) CompleteOutbox(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	if _, err := r.db.ExecContext(ctx, `delete from audit_outbox where id in (`+placeholders(len(ids))+`)`, args...); err != nil {
+		return fmt.Errorf("failed to complete outbox messages: %w", err)
+	}
+	return nil
+}
+
+// FailOutbox releases a message for a retry at retryAt, or for good when dead is set
+func (r *sqliteRepository) FailOutbox(ctx context.Context, id int64, retryAt time.Time, lastError string, dead bool) error {
+	var deadAt interface{}
+	if dead {
+		deadAt = formatSQLiteTime(r.clock.Now())
+	}
+
+	_, err := r.db.ExecContext(ctx, `update audit_outbox
+		set locked_until = null, available_at = ?, last_error = ?, dead_at = ?
+		where id = ?`, formatSQLiteTime(retryAt), lastError, deadAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to release outbox message %d: %w", id, err)
+	}
+	return nil
+}
+
+// ReplayOutbox makes dead messages created at or after since due again
+func (r *sqliteRepository) ReplayOutbox(ctx context.Context, since time.Time) (int, error) {
+	query := `update audit_outbox
+		set attempts = 0, available_at = ?, locked_until = null, last_error = null, dead_at = null
+		where dead_at is not null`
+	args := []interface{}{formatSQLiteTime(r.clock.Now())}
+	if !since.IsZero() {
+		query += ` and created_at >= ?`
+		args = append(args, formatSQLiteTime(since))
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to replay outbox messages: %w", err)
+	}
+	replayed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to replay outbox messages: %w", err)
+	}
+	return int(replayed), nil
+}
+
+// EnqueueOutbox queues stored events for forwarding again
+func (r *sqliteRepository) EnqueueOutbox(ctx context.Context, entries []domain.AuditEntry) (int, error) {
+	messages, err := newOutboxRows(entries)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	err = r.inTx(ctx, func(tx *sql.Tx) error {
+		now := formatSQLiteTime(r.clock.Now())
+		for _, message := range messages {
+			result, err := tx.ExecContext(ctx, `insert into audit_outbox
+				(event_id, session_id, event_type, payload, available_at, created_at)
+				values (?, ?, ?, ?, ?, ?)
+				on conflict (event_id) do update
+				set attempts = 0, available_at = excluded.available_at, locked_until = null, last_error = null, dead_at = null
+				where audit_outbox.dead_at is not null`,
+				message.EventID, message.SessionID, message.EventType, string(message.Payload), now, now)
+			if err != nil {
+				return err
+			}
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			queued += int(affected)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue outbox messages: %w", err)
+	}
+	return queued, nil
+}
+
+// ListDeadOutbox returns dead messages newest first. Payloads of the default organization
+// carry no organizationId.
+func (r *sqliteRepository) ListDeadOutbox(ctx context.Context, limit int, beforeID int64) ([]domain.DeadLetter, error) {
+	query := `select id, event_id, session_id, event_type, payload, attempts, coalesce(last_error, ''), created_at, dead_at
+		from audit_outbox
+		where dead_at is not null`
+	var args []interface{}
+	if beforeID > 0 {
+		query += ` and id < ?`
+		args = append(args, beforeID)
+	}
+	if organizationID, ok := tenant.FromContext(ctx); ok {
+		query += ` and coalesce(json_extract(payload, '$.organizationId'), '') = ?`
+		args = append(args, organizationID)
+	}
+	query += ` order by id desc limit ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	letters := []domain.DeadLetter{}
+	for rows.Next() {
+		var letter domain.DeadLetter
+		var payload, createdAt, deadAt string
+		if err := rows.Scan(&letter.ID, &letter.EventID, &letter.SessionID, &letter.Type, &payload, &letter.Attempts,
+			&letter.LastError, &createdAt, &deadAt); err != nil {
+			return nil, fmt.Errorf("failed to scan dead outbox message: %w", err)
+		}
+		if letter.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+			return nil, fmt.Errorf("invalid created_at for outbox message %d: %w", letter.ID, err)
+		}
+		if letter.DeadAt, err = parseSQLiteTime(deadAt); err != nil {
+			return nil, fmt.Errorf("invalid dead_at for outbox message %d: %w", letter.ID, err)
+		}
+		letter.Payload = json.RawMessage(payload)
+		letters = append(letters, letter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list dead outbox messages: %w", err)
+	}
+	return letters, nil
+}
+
+// RetryDeadOutbox makes one dead message due again
+func (r *sqliteRepository) RetryDeadOutbox(ctx context.Context, id int64) error {
+	query := `update audit_outbox
+		set attempts = 0, available_at = ?, locked_until = null, last_error = null, dead_at = null
+		where id = ? and dead_at is not null`
+	args := []interface{}{formatSQLiteTime(r.clock.Now()), id}
+	if organizationID, ok := tenant.FromContext(ctx); ok {
+		query += ` and coalesce(json_extract(payload, '$.organizationId'), '') = ?`
+		args = append(args, organizationID)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to retry outbox message %d: %w", id, err)
+	}
+	retried, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to retry outbox message %d: %w", id, err)
+	}
+	if retried == 0 {
+		return domain.ErrDeadLetterNotFound
+	}
+	return nil
+}
+
+// ListEventTypes returns every custom event type ordered by name
+func (r *sqliteRepository) ListEventTypes(ctx context.Context) ([]domain.EventType, error) {
+	rows, err := r.db.QueryContext(ctx, `select name, display_name, severity, schema, created_by, created_at
+		from audit_event_types
+		order by name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch event types: %w", err)
+	}
+	defer rows.Close()
+
+	var types []domain.EventType
+	for rows.Next() {
+		var row eventTypeRow
+		var schema sql.NullString
+		var createdAt string
+		if err := rows.Scan(&row.Name, &row.DisplayName, &row.Severity, &schema, &row.CreatedBy, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to parse event types: %w", err)
+		}
+		if row.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+			return nil, fmt.Errorf("invalid created_at for event type %s: %w", row.Name, err)
+		}
+		if schema.Valid {
+			row.Schema = json.RawMessage(schema.String)
+		}
+		types = append(types, row.toEventType())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch event types: %w", err)
+	}
+	return types, nil
+}
+
+// CreateEventType stores a custom event type, or returns domain.ErrEventTypeExists
+func (r *sqliteRepository) CreateEventType(ctx context.Context, eventType domain.EventType) error {
+	row := newEventTypeRow(eventType)
+	var schema interface{}
+	if len(row.Schema) > 0 {
+		schema = string(row.Schema)
+	}
+
+	result, err := r.db.ExecContext(ctx, `insert into audit_event_types (name, display_name, severity, schema, created_by, created_at)
+		values (?, ?, ?, ?, ?, ?)
+		on conflict (name) do nothing`,
+		row.Name, row.DisplayName, row.Severity, schema, row.CreatedBy, formatSQLiteTime(row.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to insert event type: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrEventTypeExists, eventType.Name)
+	}
+	return nil
+}
+
+// sqliteLegalHoldColumns selects an audit_legal_holds row in the order scanned by scanSQLiteLegalHold
+const sqliteLegalHoldColumns = `id, scope, target, reason, placed_by, placed_at, released_by, released_at, coalesce(organization_id, '')`
+
+// scanSQLiteLegalHold reads a row selected with sqliteLegalHoldColumns
+func scanSQLiteLegalHold(scan func(dest ...interface{}) error) (domain.LegalHold, error) {
+	var row legalHoldRow
+	var placedAt string
+	var releasedBy, releasedAt sql.NullString
+	if err := scan(&row.ID, &row.Scope, &row.Target, &row.Reason, &row.PlacedBy, &placedAt, &releasedBy, &releasedAt,
+		&row.OrganizationID); err != nil {
+		return domain.LegalHold{}, err
+	}
+
+	var err error
+	if row.PlacedAt, err = parseSQLiteTime(placedAt); err != nil {
+		return domain.LegalHold{}, fmt.Errorf("invalid placed_at for legal hold %s: %w", row.ID, err)
+	}
+	if releasedAt.Valid {
+		at, err := parseSQLiteTime(releasedAt.String)
+		if err != nil {
+			return domain.LegalHold{}, fmt.Errorf("invalid released_at for legal hold %s: %w", row.ID, err)
+		}
+		row.ReleasedAt = &at
+		row.ReleasedBy = &releasedBy.String
+	}
+	return row.toLegalHold(), nil
+}
+
+// CreateLegalHold stores a new active hold
+func (r *sqliteRepository) CreateLegalHold(ctx context.Context, hold domain.LegalHold) error {
+	row := newLegalHoldRow(hold)
+	if _, err := r.db.ExecContext(ctx, `insert into audit_legal_holds (id, scope, target, reason, placed_by, placed_at, organization_id)
+		values (?, ?, ?, ?, ?, ?, ?)`,
+		row.ID, row.Scope, row.Target, row.Reason, row.PlacedBy, formatSQLiteTime(row.PlacedAt), nullIfEmpty(row.OrganizationID)); err != nil {
+		return fmt.Errorf("failed to insert legal hold: %w", err)
+	}
+	return nil
+}
+
+// ListLegalHolds returns the holds newest first, only the active ones when activeOnly is set
+func (r *sqliteRepository) ListLegalHolds(ctx context.Context, activeOnly bool) ([]domain.LegalHold, error) {
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	rows, err := r.db.QueryContext(ctx, `select `+sqliteLegalHoldColumns+`
+		from audit_legal_holds
+		where (not ? or released_at is null) and `+organization+`
+		order by placed_at desc, id`, append([]interface{}{activeOnly}, organizationArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch legal holds: %w", err)
+	}
+	defer rows.Close()
+
+	var holds []domain.LegalHold
+	for rows.Next() {
+		hold, err := scanSQLiteLegalHold(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse legal holds: %w", err)
+		}
+		holds = append(holds, hold)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch legal holds: %w", err)
+	}
+	return holds, nil
+}
+
+// ReleaseLegalHold marks an active hold released and returns it
+func (r *sqliteRepository) ReleaseLegalHold(ctx context.Context, id, releasedBy string, at time.Time) (*domain.LegalHold, error) {
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	args := append([]interface{}{releasedBy, formatSQLiteTime(at), id}, organizationArgs...)
+	hold, err := scanSQLiteLegalHold(r.db.QueryRowContext(ctx, `update audit_legal_holds
+		set released_by = ?, released_at = ?
+		where id = ? and released_at is null and `+organization+`
+		returning `+sqliteLegalHoldColumns, args...).Scan)
+	if err == nil {
+		return &hold, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to release legal hold: %w", err)
+	}
+
+	// Nothing was updated: the hold is unknown or was released before
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `select exists (select 1 from audit_legal_holds where id = ? and `+organization+`)`,
+		append([]interface{}{id}, organizationArgs...)...).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to fetch legal hold: %w", err)
+	}
+	if !exists {
+		return nil, domain.ErrLegalHoldNotFound
+	}
+	return nil, domain.ErrLegalHoldReleased
+}
+
+// sqliteRevocationColumns selects an audit_token_revocations row in the order scanned by scanSQLiteRevocation
+const sqliteRevocationColumns = `id, kind, target, reason, revoked_by, revoked_at, expires_at, lifted_by, lifted_at,
+	coalesce(organization_id, '')`
+
+// scanSQLiteRevocation reads a row selected with sqliteRevocationColumns
+func scanSQLiteRevocation(scan func(dest ...interface{}) error) (domain.Revocation, error) {
+	var row revocationRow
+	var revokedAt string
+	var expiresAt, liftedBy, liftedAt sql.NullString
+	if err := scan(&row.ID, &row.Kind, &row.Target, &row.Reason, &row.RevokedBy, &revokedAt, &expiresAt, &liftedBy, &liftedAt,
+		&row.OrganizationID); err != nil {
+		return domain.Revocation{}, err
+	}
+
+	var err error
+	if row.RevokedAt, err = parseSQLiteTime(revokedAt); err != nil {
+		return domain.Revocation{}, fmt.Errorf("invalid revoked_at for revocation %s: %w", row.ID, err)
+	}
+	if expiresAt.Valid {
+		at, err := parseSQLiteTime(expiresAt.String)
+		if err != nil {
+			return domain.Revocation{}, fmt.Errorf("invalid expires_at for revocation %s: %w", row.ID, err)
+		}
+		row.ExpiresAt = &at
+	}
+	if liftedAt.Valid {
+		at, err := parseSQLiteTime(liftedAt.String)
+		if err != nil {
+			return domain.Revocation{}, fmt.Errorf("invalid lifted_at for revocation %s: %w", row.ID, err)
+		}
+		row.LiftedAt = &at
+		row.LiftedBy = &liftedBy.String
+	}
+	return row.toRevocation(), nil
+}
+
+// CreateRevocation stores a new revocation
+func (r *sqliteRepository) CreateRevocation(ctx context.Context, revocation domain.Revocation) error {
+	row := newRevocationRow(revocation)
+	var expiresAt interface{}
+	if row.ExpiresAt != nil {
+		expiresAt = formatSQLiteTime(*row.ExpiresAt)
+	}
+	if _, err := r.db.ExecContext(ctx, `insert into audit_token_revocations (id, kind, target, reason, revoked_by, revoked_at,
+		expires_at, organization_id) values (?, ?, ?, ?, ?, ?, ?, ?)`,
+		row.ID, row.Kind, row.Target, row.Reason, row.RevokedBy, formatSQLiteTime(row.RevokedAt), expiresAt,
+		nullIfEmpty(row.OrganizationID)); err != nil {
+		return fmt.Errorf("failed to insert revocation: %w", err)
+	}
+	return nil
+}
+
+// ListRevocations returns the revocations newest first, only the ones not lifted when notLiftedOnly is set
+func (r *sqliteRepository) ListRevocations(ctx context.Context, notLiftedOnly bool) ([]domain.Revocation, error) {
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	rows, err := r.db.QueryContext(ctx, `select `+sqliteRevocationColumns+`
+		from audit_token_revocations
+		where (not ? or lifted_at is null) and `+organization+`
+		order by revoked_at desc, id`, append([]interface{}{notLiftedOnly}, organizationArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch revocations: %w", err)
+	}
+	defer rows.Close()
+
+	var revocations []domain.Revocation
+	for rows.Next() {
+		revocation, err := scanSQLiteRevocation(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse revocations: %w", err)
+		}
+		revocations = append(revocations, revocation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch revocations: %w", err)
+	}
+	return revocations, nil
+}
+
+// LiftRevocation marks a revocation lifted and returns it
+func (r *sqliteRepository) LiftRevocation(ctx context.Context, id, liftedBy string, at time.Time) (*domain.Revocation, error) {
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	args := append([]interface{}{liftedBy, formatSQLiteTime(at), id}, organizationArgs...)
+	revocation, err := scanSQLiteRevocation(r.db.QueryRowContext(ctx, `update audit_token_revocations
+		set lifted_by = ?, lifted_at = ?
+		where id = ? and lifted_at is null and `+organization+`
+		returning `+sqliteRevocationColumns, args...).Scan)
+	if err == nil {
+		return &revocation, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to lift revocation: %w", err)
+	}
+
+	// Nothing was updated: the revocation is unknown or was lifted before
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `select exists (select 1 from audit_token_revocations where id = ? and `+organization+`)`,
+		append([]interface{}{id}, organizationArgs...)...).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to fetch revocation: %w", err)
+	}
+	if !exists {
+		return nil, domain.ErrRevocationNotFound
+	}
+	return nil, domain.ErrRevocationLifted
+}
+
+// sqliteWatchColumns selects an audit_session_watches row in the order scanned by scanSQLiteWatch
+const sqliteWatchColumns = `session_id, user_id, event_types, created_at, coalesce(organization_id, '')`
+
+// scanSQLiteWatch reads a row selected with sqliteWatchColumns
+func scanSQLiteWatch(scan func(dest ...interface{}) error) (domain.Watch, error) {
+	var row watchRow
+	var eventTypes, createdAt string
+	if err := scan(&row.SessionID, &row.UserID, &eventTypes, &createdAt, &row.OrganizationID); err != nil {
+		return domain.Watch{}, err
+	}
+
+	if err := json.Unmarshal([]byte(eventTypes), &row.EventTypes); err != nil {
+		return domain.Watch{}, fmt.Errorf("invalid event_types for watch of %s on %s: %w", row.UserID, row.SessionID, err)
+	}
+	var err error
+	if row.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return domain.Watch{}, fmt.Errorf("invalid created_at for watch of %s on %s: %w", row.UserID, row.SessionID, err)
+	}
+	return row.toWatch(), nil
+}
+
+// PutWatch creates or replaces the watch of a user on a session
+func (r *sqliteRepository) PutWatch(ctx context.Context, watch domain.Watch) (*domain.Watch, error) {
+	row := newWatchRow(watch)
+	eventTypes, err := json.Marshal(row.EventTypes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode watched event types: %w", err)
+	}
+
+	stored, err := scanSQLiteWatch(r.db.QueryRowContext(ctx, `insert into audit_session_watches
+			(session_id, user_id, event_types, created_at, organization_id)
+		values (?, ?, ?, ?, ?)
+		on conflict (session_id, user_id) do update set event_types = excluded.event_types
+		returning `+sqliteWatchColumns,
+		strings.ToLower(row.SessionID), row.UserID, string(eventTypes), formatSQLiteTime(row.CreatedAt),
+		nullIfEmpty(row.OrganizationID)).Scan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store watch: %w", err)
+	}
+	return &stored, nil
+}
+
+// GetWatch returns the watch of a user on a session
+func (r *sqliteRepository) GetWatch(ctx context.Context, sessionID, userID string) (*domain.Watch, error) {
+	watch, err := scanSQLiteWatch(r.db.QueryRowContext(ctx, `select `+sqliteWatchColumns+` from audit_session_watches
+		where session_id = ? and user_id = ?`, strings.ToLower(sessionID), userID).Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrWatchNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch watch: %w", err)
+	}
+	return &watch, nil
+}
+
+// DeleteWatch removes the watch of a user on a session
+func (r *sqliteRepository) DeleteWatch(ctx context.Context, sessionID, userID string) error {
+	result, err := r.db.ExecContext(ctx, `delete from audit_session_watches where session_id = ? and user_id = ?`,
+		strings.ToLower(sessionID), userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete watch: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return domain.ErrWatchNotFound
+	}
+	return nil
+}
+
+// ListUserWatches returns the watches of a user, newest first
+func (r *sqliteRepository) ListUserWatches(ctx context.Context, userID string) ([]domain.Watch, error) {
+	return r.listWatches(ctx, `where user_id = ? order by created_at desc, session_id`, userID)
+}
+
+// ListSessionWatches returns the watches on a session
+func (r *sqliteRepository) ListSessionWatches(ctx context.Context, sessionID string) ([]domain.Watch, error) {
+	return r.listWatches(ctx, `where session_id = ? order by user_id`, strings.ToLower(sessionID))
+}
+
+// listWatches returns the watches selected by the where and order clause
+func (r *sqliteRepository) listWatches(ctx context.Context, clause, arg string) ([]domain.Watch, error) {
+	rows, err := r.db.QueryContext(ctx, `select `+sqliteWatchColumns+` from audit_session_watches `+clause, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch watches: %w", err)
+	}
+	defer rows.Close()
+
+	watches := []domain.Watch{}
+	for rows.Next() {
+		watch, err := scanSQLiteWatch(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse watches: %w", err)
+		}
+		watches = append(watches, watch)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch watches: %w", err)
+	}
+	return watches, nil
+}
+
+// sqliteReportColumns selects an audit_reports row in the order scanned by scanSQLiteReport
+const sqliteReportColumns = `id, name, kind, session_id, schedule, format, delivery, target, created_by, created_at, next_run_at,
+	last_run_at, coalesce(organization_id, '')`
+
+// scanSQLiteReport reads a row selected with sqliteReportColumns
+func scanSQLiteReport(scan func(dest ...interface{}) error) (domain.Report, error) {
+	var row reportRow
+	var sessionID, lastRunAt sql.NullString
+	var createdAt, nextRunAt string
+	if err := scan(&row.ID, &row.Name, &row.Kind, &sessionID, &row.Schedule, &row.Format, &row.Delivery, &row.Target,
+		&row.CreatedBy, &createdAt, &nextRunAt, &lastRunAt, &row.OrganizationID); err != nil {
+		return domain.Report{}, err
+	}
+
+	if sessionID.Valid {
+		row.SessionID = &sessionID.String
+	}
+	var err error
+	if row.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return domain.Report{}, fmt.Errorf("invalid created_at for report %s: %w", row.ID, err)
+	}
+	if row.NextRunAt, err = parseSQLiteTime(nextRunAt); err != nil {
+		return domain.Report{}, fmt.Errorf("invalid next_run_at for report %s: %w", row.ID, err)
+	}
+	if lastRunAt.Valid {
+		at, err := parseSQLiteTime(lastRunAt.String)
+		if err != nil {
+			return domain.Report{}, fmt.Errorf("invalid last_run_at for report %s: %w", row.ID, err)
+		}
+		row.LastRunAt = &at
+	}
+	return row.toReport(), nil
+}
+
+// CreateReport stores a new report
+func (r *sqliteRepository) CreateReport(ctx context.Context, report domain.Report) error {
+	row := newReportRow(report)
+	var sessionID, lastRunAt interface{}
+	if row.SessionID != nil {
+		sessionID = strings.ToLower(*row.SessionID)
+	}
+	if row.LastRunAt != nil {
+		lastRunAt = formatSQLiteTime(*row.LastRunAt)
+	}
+	if _, err := r.db.ExecContext(ctx, `insert into audit_reports (id, name, kind, session_id, schedule, format, delivery, target,
+		created_by, created_at, next_run_at, last_run_at, organization_id) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		row.ID, row.Name, row.Kind, sessionID, row.Schedule, row.Format, row.Delivery, row.Target, row.CreatedBy,
+		formatSQLiteTime(row.CreatedAt), formatSQLiteTime(row.NextRunAt), lastRunAt, nullIfEmpty(row.OrganizationID)); err != nil {
+		return fmt.Errorf("failed to insert report: %w", err)
+	}
+	return nil
+}
+
+// GetReport returns a report of the organization ctx is scoped to
+func (r *sqliteRepository) GetReport(ctx context.Context, id string) (*domain.Report, error) {
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	report, err := scanSQLiteReport(r.db.QueryRowContext(ctx, `select `+sqliteReportColumns+` from audit_reports
+		where id = ? and `+organization, append([]interface{}{id}, organizationArgs...)...).Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrReportNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch report: %w", err)
+	}
+	return &report, nil
+}
+
+// ListReports returns the reports of the organization ctx is scoped to by name
+func (r *sqliteRepository) ListReports(ctx context.Context) ([]domain.Report, error) {
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	return r.listReports(ctx, `where `+organization+` order by name, id`, organizationArgs...)
+}
+
+// DeleteReport removes a report with its runs
+func (r *sqliteRepository) DeleteReport(ctx context.Context, id string) error {
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	result, err := r.db.ExecContext(ctx, `delete from audit_reports where id = ? and `+organization,
+		append([]interface{}{id}, organizationArgs...)...)
+	if err != nil {
+		return fmt.Errorf("failed to delete report: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return domain.ErrReportNotFound
+	}
+	return nil
+}
+
+// ListDueReports returns the reports of every organization due at the given time
+func (r *sqliteRepository) ListDueReports(ctx context.Context, at time.Time, limit int) ([]domain.Report, error) {
+	return r.listReports(ctx, `where next_run_at <= ? order by next_run_at, id limit ?`, formatSQLiteTime(at), limit)
+}
+
+// listReports returns the reports selected by the where and order clause
+func (r *sqliteRepository) listReports(ctx context.Context, clause string, args ...interface{}) ([]domain.Report, error) {
+	rows, err := r.db.QueryContext(ctx, `select `+sqliteReportColumns+` from audit_reports `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []domain.Report{}
+	for rows.Next() {
+		report, err := scanSQLiteReport(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse reports: %w", err)
+		}
+		reports = append(reports, report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch reports: %w", err)
+	}
+	return reports, nil
+}
+
+// AdvanceReport claims the run of a report due at scheduledAt
+func (r *sqliteRepository) AdvanceReport(ctx context.Context, id string, scheduledAt, nextRunAt time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `update audit_reports set last_run_at = ?, next_run_at = ?
+		where id = ? and next_run_at = ?`,
+		formatSQLiteTime(scheduledAt), formatSQLiteTime(nextRunAt), id, formatSQLiteTime(scheduledAt))
+	if err != nil {
+		return false, fmt.Errorf("failed to advance report: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to advance report: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// CreateReportRun records a run of a report
+func (r *sqliteRepository) CreateReportRun(ctx context.Context, run domain.ReportRun) error {
+	row := newReportRunRow(run)
+	if _, err := r.db.ExecContext(ctx, `insert into audit_report_runs (id, report_id, triggered_by, status, period_from, period_to,
+		started_at, finished_at, row_count, location, error, organization_id) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		row.ID, row.ReportID, row.TriggeredBy, row.Status, formatSQLiteTime(row.PeriodFrom), formatSQLiteTime(row.PeriodTo),
+		formatSQLiteTime(row.StartedAt), formatSQLiteTime(row.FinishedAt), row.RowCount, row.Location, row.Error,
+		nullIfEmpty(row.OrganizationID)); err != nil {
+		return fmt.Errorf("failed to insert report run: %w", err)
+	}
+	return nil
+}
+
+// ListReportRuns returns the latest runs of a report, newest first
+func (r *sqliteRepository) ListReportRuns(ctx context.Context, reportID string, limit int) ([]domain.ReportRun, error) {
+	rows, err := r.db.QueryContext(ctx, `select id, report_id, triggered_by, status, period_from, period_to, started_at,
+		finished_at, row_count, location, error, coalesce(organization_id, '')
+		from audit_report_runs where report_id = ? order by started_at desc, id limit ?`, reportID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch report runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []domain.ReportRun{}
+	for rows.Next() {
+		var row reportRunRow
+		var periodFrom, periodTo, startedAt, finishedAt string
+		var location, runErr sql.NullString
+		if err := rows.Scan(&row.ID, &row.ReportID, &row.TriggeredBy, &row.Status, &periodFrom, &periodTo, &startedAt,
+			&finishedAt, &row.RowCount, &location, &runErr, &row.OrganizationID); err != nil {
+			return nil, fmt.Errorf("failed to parse report runs: %w", err)
+		}
+		for _, column := range []struct {
+			value string
+			dest  *time.Time
+		}{{periodFrom, &row.PeriodFrom}, {periodTo, &row.PeriodTo}, {startedAt, &row.StartedAt}, {finishedAt, &row.FinishedAt}} {
+			if *column.dest, err = parseSQLiteTime(column.value); err != nil {
+				return nil, fmt.Errorf("invalid time for report run %s: %w", row.ID, err)
+			}
+		}
+		if location.Valid {
+			row.Location = &location.String
+		}
+		if runErr.Valid {
+			row.Error = &runErr.String
+		}
+		runs = append(runs, row.toReportRun())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch report runs: %w", err)
+	}
+	return runs, nil
+}
+
+// sqliteActivityBuckets derive the bucket start of each granularity from the fixed-width timestamps
+var sqliteActivityBuckets = map[domain.ActivityGranularity]string{
+	domain.ActivityHour: `substr("timestamp", 1, 13) || ':00:00.000000Z'`,
+	domain.ActivityDay:  `substr("timestamp", 1, 10) || 'T00:00:00.000000Z'`,
+}
+
+// sqliteSavedQueryColumns selects an audit_saved_queries row in the order scanned by scanSQLiteSavedQuery
+const sqliteSavedQueryColumns = `id, name, description, scope, filter, created_by, created_at, updated_at, coalesce(organization_id, '')`
+
+// scanSQLiteSavedQuery reads a row selected with sqliteSavedQueryColumns
+func scanSQLiteSavedQuery(scan func(dest ...interface{}) error) (domain.SavedQuery, error) {
+	var row savedQueryRow
+	var filter, createdAt, updatedAt string
+	if err := scan(&row.ID, &row.Name, &row.Description, &row.Scope, &filter, &row.CreatedBy, &createdAt, &updatedAt,
+		&row.OrganizationID); err != nil {
+		return domain.SavedQuery{}, err
+	}
+
+	if err := json.Unmarshal([]byte(filter), &row.Filter); err != nil {
+		return domain.SavedQuery{}, fmt.Errorf("invalid filter for saved query %s: %w", row.ID, err)
+	}
+	var err error
+	if row.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return domain.SavedQuery{}, fmt.Errorf("invalid created_at for saved query %s: %w", row.ID, err)
+	}
+	if row.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+		return domain.SavedQuery{}, fmt.Errorf("invalid updated_at for saved query %s: %w", row.ID, err)
+	}
+	return row.toSavedQuery(), nil
+}
+
+// CreateSavedQuery stores a new saved query
+func (r *sqliteRepository) CreateSavedQuery(ctx context.Context, query domain.SavedQuery) error {
+	row := newSavedQueryRow(query)
+	filter, err := json.Marshal(row.Filter)
+	if err != nil {
+		return fmt.Errorf("failed to encode saved query filter: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, `insert into audit_saved_queries (id, name, description, scope, filter, created_by,
+		created_at, updated_at, organization_id) values (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		row.ID, row.Name, row.Description, row.Scope, string(filter), row.CreatedBy, formatSQLiteTime(row.CreatedAt),
+		formatSQLiteTime(row.UpdatedAt), nullIfEmpty(row.OrganizationID)); err != nil {
+		return fmt.Errorf("failed to insert saved query: %w", err)
+	}
+	return nil
+}
+
+// GetSavedQuery returns a saved query of the organization ctx is scoped to
+func (r *sqliteRepository) GetSavedQuery(ctx context.Context, id string) (*domain.SavedQuery, error) {
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	query, err := scanSQLiteSavedQuery(r.db.QueryRowContext(ctx, `select `+sqliteSavedQueryColumns+` from audit_saved_queries
+		where id = ? and `+organization, append([]interface{}{id}, organizationArgs...)...).Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrSavedQueryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch saved query: %w", err)
+	}
+	return &query, nil
+}
+
+// ListSavedQueries returns the saved queries of the organization ctx is scoped to that a user can see, by name
+func (r *sqliteRepository) ListSavedQueries(ctx context.Context, userID string) ([]domain.SavedQuery, error) {
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	rows, err := r.db.QueryContext(ctx, `select `+sqliteSavedQueryColumns+` from audit_saved_queries
+		where (created_by = ? or scope = ?) and `+organization+` order by name, id`,
+		append([]interface{}{userID, string(domain.SavedQueryOrganization)}, organizationArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch saved queries: %w", err)
+	}
+	defer rows.Close()
+
+	queries := []domain.SavedQuery{}
+	for rows.Next() {
+		query, err := scanSQLiteSavedQuery(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse saved queries: %w", err)
+		}
+		queries = append(queries, query)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch saved queries: %w", err)
+	}
+	return queries, nil
+}
+
+// UpdateSavedQuery replaces the name, description, scope and filter of a saved query
+func (r *sqliteRepository) UpdateSavedQuery(ctx context.Context, query domain.SavedQuery) error {
+	row := newSavedQueryRow(query)
+	filter, err := json.Marshal(row.Filter)
+	if err != nil {
+		return fmt.Errorf("failed to encode saved query filter: %w", err)
+	}
+
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	result, err := r.db.ExecContext(ctx, `update audit_saved_queries set name = ?, description = ?, scope = ?, filter = ?,
+		updated_at = ? where id = ? and `+organization,
+		append([]interface{}{row.Name, row.Description, row.Scope, string(filter), formatSQLiteTime(row.UpdatedAt), row.ID},
+			organizationArgs...)...)
+	if err != nil {
+		return fmt.Errorf("failed to update saved query: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return domain.ErrSavedQueryNotFound
+	}
+	return nil
+}
+
+// DeleteSavedQuery removes a saved query
+func (r *sqliteRepository) DeleteSavedQuery(ctx context.Context, id string) error {
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	result, err := r.db.ExecContext(ctx, `delete from audit_saved_queries where id = ? and `+organization,
+		append([]interface{}{id}, organizationArgs...)...)
+	if err != nil {
+		return fmt.Errorf("failed to delete saved query: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return domain.ErrSavedQueryNotFound
+	}
+	return nil
+}
+
+// GetEventLabels returns the label set of an event of the organization ctx is scoped to
+func (r *sqliteRepository) GetEventLabels(ctx context.Context, eventID string) (*domain.EventLabels, error) {
+	var row eventLabelsRow
+	var labels, updatedAt string
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	err := r.db.QueryRowContext(ctx, `select event_id, session_id, labels, version, updated_by, updated_at,
+		coalesce(organization_id, '') from audit_event_labels where event_id = ? and `+organization,
+		append([]interface{}{strings.ToLower(eventID)}, organizationArgs...)...).
+		Scan(&row.EventID, &row.SessionID, &labels, &row.Version, &row.UpdatedBy, &updatedAt, &row.OrganizationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch event labels: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(labels), &row.Labels); err != nil {
+		return nil, fmt.Errorf("invalid labels for event %s: %w", row.EventID, err)
+	}
+	if row.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+		return nil, fmt.Errorf("invalid updated_at for labels of event %s: %w", row.EventID, err)
+	}
+	result := row.toEventLabels()
+	return &result, nil
+}
+
+// SaveEventLabels stores the label set of an event when the stored set is at the version before
+func (r *sqliteRepository) SaveEventLabels(ctx context.Context, labels domain.EventLabels) error {
+	row := newEventLabelsRow(labels)
+	encoded, err := json.Marshal(row.Labels)
+	if err != nil {
+		return fmt.Errorf("failed to encode event labels: %w", err)
+	}
+
+	var result sql.Result
+	if row.Version == 1 {
+		result, err = r.db.ExecContext(ctx, `insert into audit_event_labels (event_id, session_id, labels, version, updated_by,
+			updated_at, organization_id) values (?, ?, ?, 1, ?, ?, ?) on conflict (event_id) do nothing`,
+			strings.ToLower(row.EventID), strings.ToLower(row.SessionID), string(encoded), row.UpdatedBy,
+			formatSQLiteTime(row.UpdatedAt), nullIfEmpty(row.OrganizationID))
+	} else {
+		result, err = r.db.ExecContext(ctx, `update audit_event_labels set labels = ?, version = ?, updated_by = ?, updated_at = ?
+			where event_id = ? and version = ?`,
+			string(encoded), row.Version, row.UpdatedBy, formatSQLiteTime(row.UpdatedAt), strings.ToLower(row.EventID), row.Version-1)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save event labels: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("%w: labels of event %s changed before version %d was stored", domain.ErrVersionConflict, row.EventID, row.Version)
+	}
+	return nil
+}
+
+// sqliteAnnotationColumns selects the columns scanned by scanSQLiteAnnotation
+const sqliteAnnotationColumns = `id, event_id, session_id, author_id, body, version, created_at, updated_at, coalesce(organization_id, '')`
+
+// scanSQLiteAnnotation reads a row selected with sqliteAnnotationColumns
+func scanSQLiteAnnotation(scan func(dest ...interface{}) error) (domain.EventAnnotation, error) {
+	var row annotationRow
+	var createdAt, updatedAt string
+	if err := scan(&row.ID, &row.EventID, &row.SessionID, &row.AuthorID, &row.Body, &row.Version, &createdAt, &updatedAt,
+		&row.OrganizationID); err != nil {
+		return domain.EventAnnotation{}, err
+	}
+
+	var err error
+	if row.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return domain.EventAnnotation{}, fmt.Errorf("invalid created_at for annotation %s: %w", row.ID, err)
+	}
+	if row.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+		return domain.EventAnnotation{}, fmt.Errorf("invalid updated_at for annotation %s: %w", row.ID, err)
+	}
+	return row.toAnnotation(), nil
+}
+
+// CreateAnnotation stores a new annotation
+func (r *sqliteRepository) CreateAnnotation(ctx context.Context, annotation domain.EventAnnotation) error {
+	row := newAnnotationRow(annotation)
+	if _, err := r.db.ExecContext(ctx, `insert into audit_event_annotations (id, event_id, session_id, author_id, body, version,
+		created_at, updated_at, organization_id) values (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		strings.ToLower(row.ID), strings.ToLower(row.EventID), strings.ToLower(row.SessionID), row.AuthorID, row.Body, row.Version,
+		formatSQLiteTime(row.CreatedAt), formatSQLiteTime(row.UpdatedAt), nullIfEmpty(row.OrganizationID)); err != nil {
+		return fmt.Errorf("failed to insert annotation: %w", err)
+	}
+	return nil
+}
+
+// GetAnnotation returns an annotation of the organization ctx is scoped to
+func (r *sqliteRepository) GetAnnotation(ctx context.Context, id string) (*domain.EventAnnotation, error) {
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	annotation, err := scanSQLiteAnnotation(r.db.QueryRowContext(ctx, `select `+sqliteAnnotationColumns+` from audit_event_annotations
+		where id = ? and `+organization, append([]interface{}{strings.ToLower(id)}, organizationArgs...)...).Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrAnnotationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch annotation: %w", err)
+	}
+	return &annotation, nil
+}
+
+// ListAnnotations returns the annotations of an event of the organization ctx is scoped to, oldest first
+func (r *sqliteRepository) ListAnnotations(ctx context.Context, eventID string) ([]domain.EventAnnotation, error) {
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	rows, err := r.db.QueryContext(ctx, `select `+sqliteAnnotationColumns+` from audit_event_annotations
+		where event_id = ? and `+organization+` order by created_at, id`,
+		append([]interface{}{strings.ToLower(eventID)}, organizationArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch annotations: %w", err)
+	}
+	defer rows.Close()
+
+	annotations := []domain.EventAnnotation{}
+	for rows.Next() {
+		annotation, err := scanSQLiteAnnotation(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse annotations: %w", err)
+		}
+		annotations = append(annotations, annotation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch annotations: %w", err)
+	}
+	return annotations, nil
+}
+
+// UpdateAnnotation stores the body of an annotation when the stored annotation is at the version before
+func (r *sqliteRepository) UpdateAnnotation(ctx context.Context, annotation domain.EventAnnotation) error {
+	row := newAnnotationRow(annotation)
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	result, err := r.db.ExecContext(ctx, `update audit_event_annotations set body = ?, version = ?, updated_at = ?
+		where id = ? and version = ? and `+organization,
+		append([]interface{}{row.Body, row.Version, formatSQLiteTime(row.UpdatedAt), strings.ToLower(row.ID), row.Version - 1},
+			organizationArgs...)...)
+	if err != nil {
+		return fmt.Errorf("failed to update annotation: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("%w: annotation %s changed before version %d was stored", domain.ErrVersionConflict, row.ID, row.Version)
+	}
+	return nil
+}
+
+// DeleteAnnotation removes an annotation at version
+func (r *sqliteRepository) DeleteAnnotation(ctx context.Context, id string, version int64) error {
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	result, err := r.db.ExecContext(ctx, `delete from audit_event_annotations where id = ? and version = ? and `+organization,
+		append([]interface{}{strings.ToLower(id), version}, organizationArgs...)...)
+	if err != nil {
+		return fmt.Errorf("failed to delete annotation: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("%w: annotation %s changed after version %d", domain.ErrVersionConflict, id, version)
+	}
+	return nil
+}
+
+// GetSessionActivity returns the rolled-up activity of a session
+func (r *sqliteRepository) GetSessionActivity(ctx context.Context, sessionID domain.SessionID, query domain.ActivityQuery) ([]domain.ActivityBucket, error) {
+	// Test sessions have no persisted events
+	if sessionID.IsTestSession() {
+		return []domain.ActivityBucket{}, nil
+	}
+
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	args := append([]interface{}{strings.ToLower(sessionID.String()), string(query.Granularity), formatSQLiteTime(query.From),
+		formatSQLiteTime(query.To), query.UserID, query.UserID}, organizationArgs...)
+	rows, err := r.db.QueryContext(ctx, `select bucket_start, user_id, event_count, by_type
+		from audit_activity_rollups
+		where session_id = ? and granularity = ? and bucket_start >= ? and bucket_start < ?
+			and (? = '' or user_id = ?) and `+organization+`
+		order by bucket_start, user_id`, args...)
+	if err != nil {
+		r.logger.Error("failed to fetch session activity",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch session activity: %w", err)
+	}
+	defer rows.Close()
+
+	buckets := []domain.ActivityBucket{}
+	for rows.Next() {
+		var row activityRow
+		var bucketStart, byType string
+		if err := rows.Scan(&bucketStart, &row.UserID, &row.EventCount, &byType); err != nil {
+			return nil, fmt.Errorf("failed to parse session activity: %w", err)
+		}
+		if row.BucketStart, err = parseSQLiteTime(bucketStart); err != nil {
+			return nil, fmt.Errorf("failed to parse session activity: %w", err)
+		}
+		if err := json.Unmarshal([]byte(byType), &row.ByType); err != nil {
+			return nil, fmt.Errorf("failed to parse session activity: %w", err)
+		}
+		buckets = append(buckets, row.toActivityBucket())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch session activity: %w", err)
+	}
+	return buckets, nil
+}
+
+// GetSlideHeatmap returns the hourly event counts per slide of a session, like the
+// session_slide_heatmap function of migrations/019_audit_slide_heatmap.sql
+func (r *sqliteRepository) GetSlideHeatmap(ctx context.Context, sessionID domain.SessionID, query domain.HeatmapQuery) ([]domain.HeatmapCell, error) {
+	// Test sessions have no persisted events
+	if sessionID.IsTestSession() {
+		return []domain.HeatmapCell{}, nil
+	}
+
+	sessionID = domain.SessionID(strings.ToLower(sessionID.String()))
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	args := append([]interface{}{sessionID.String(), formatSQLiteTime(query.From), formatSQLiteTime(query.To),
+		query.UserID, query.UserID}, organizationArgs...)
+	args = append(args, sessionID.String())
+	// Stored timestamps are fixed-width UTC text, so the hour is their first 13 characters
+	rows, err := r.db.QueryContext(ctx, `with counts as (
+			select slide_id, substr("timestamp", 1, 13) || ':00:00Z' as hour, count(*) as event_count
+			from audit_logs
+			where session_id = ? and slide_id is not null and "timestamp" >= ? and "timestamp" < ?
+				and (? = '' or user_id = ?) and `+organization+`
+			group by 1, 2
+		)
+		select c.slide_id, s.slide_number, c.hour, c.event_count
+		from counts c
+		left join slides s on s.id = c.slide_id and s.session_id = ?
+		order by s.slide_number is null, s.slide_number, c.slide_id, c.hour`, args...)
+	if err != nil {
+		r.logger.Error("failed to fetch slide heatmap",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch slide heatmap: %w", err)
+	}
+	defer rows.Close()
+
+	cells := []domain.HeatmapCell{}
+	for rows.Next() {
+		var row heatmapRow
+		var hour string
+		if err := rows.Scan(&row.SlideID, &row.SlideIndex, &hour, &row.EventCount); err != nil {
+			return nil, fmt.Errorf("failed to parse slide heatmap: %w", err)
+		}
+		if row.Hour, err = time.Parse(time.RFC3339, hour); err != nil {
+			return nil, fmt.Errorf("failed to parse slide heatmap: %w", err)
+		}
+		cells = append(cells, row.toHeatmapCell())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch slide heatmap: %w", err)
+	}
+	return cells, nil
+}
+
+// GetSessionContributors returns the per-user contribution to a session, like the
+// session_contributors function of migrations/020_audit_session_contributors.sql
+func (r *sqliteRepository) GetSessionContributors(ctx context.Context, sessionID domain.SessionID, query domain.ContributorQuery) ([]domain.ContributorStats, error) {
+	// Test sessions have no persisted events
+	if sessionID.IsTestSession() {
+		return []domain.ContributorStats{}, nil
+	}
+
+	sessionID = domain.SessionID(strings.ToLower(sessionID.String()))
+	conditions := []string{"session_id = ?"}
+	args := []interface{}{sessionID.String()}
+	if !query.From.IsZero() {
+		conditions = append(conditions, `"timestamp" >= ?`)
+		args = append(args, formatSQLiteTime(query.From))
+	}
+	if !query.To.IsZero() {
+		conditions = append(conditions, `"timestamp" <= ?`)
+		args = append(args, formatSQLiteTime(query.To))
+	}
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	conditions = append(conditions, organization)
+	args = append(args, organizationArgs...)
+
+	rows, err := r.db.QueryContext(ctx, `select user_id,
+			sum(type = 'edit'), sum(type in ('comment', 'comment_created')), sum(type = 'merge'),
+			count(*), min("timestamp"), max("timestamp")
+		from audit_logs
+		where `+strings.Join(conditions, " and ")+`
+		group by user_id
+		order by count(*) desc, user_id`, args...)
+	if err != nil {
+		r.logger.Error("failed to fetch session contributors",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch session contributors: %w", err)
+	}
+	defer rows.Close()
+
+	contributors := []domain.ContributorStats{}
+	for rows.Next() {
+		var row contributorRow
+		var first, last string
+		if err := rows.Scan(&row.UserID, &row.Edits, &row.Comments, &row.Merges, &row.TotalEvents, &first, &last); err != nil {
+			return nil, fmt.Errorf("failed to parse session contributors: %w", err)
+		}
+		if row.FirstActivity, err = parseSQLiteTime(first); err != nil {
+			return nil, fmt.Errorf("failed to parse session contributors: %w", err)
+		}
+		if row.LastActivity, err = parseSQLiteTime(last); err != nil {
+			return nil, fmt.Errorf("failed to parse session contributors: %w", err)
+		}
+		contributors = append(contributors, row.toContributorStats())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch session contributors: %w", err)
+	}
+	return contributors, nil
+}
+
+// RollupActivity replaces the rollups starting in [from, to) with the aggregates of their events,
+// like the rollup_audit_activity function of migrations/016_audit_activity_rollups.sql
+func (r *sqliteRepository) RollupActivity(ctx context.Context, from, to time.Time) (int, error) {
+	written := 0
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `delete from audit_activity_rollups where bucket_start >= ? and bucket_start < ?`,
+			formatSQLiteTime(from), formatSQLiteTime(to)); err != nil {
+			return err
+		}
+
+		for _, granularity := range []domain.ActivityGranularity{domain.ActivityHour, domain.ActivityDay} {
+			result, err := tx.ExecContext(ctx, `insert into audit_activity_rollups
+				(granularity, bucket_start, session_id, user_id, event_count, by_type, organization_id)
+				select ?, bucket_start, session_id, user_id, sum(n), json_group_object(type, n), organization_id
+				from (
+					select `+sqliteActivityBuckets[granularity]+` as bucket_start, session_id, user_id, organization_id, type, count(*) as n
+					from audit_logs
+					where "timestamp" >= ? and "timestamp" < ?
+					group by 1, session_id, user_id, organization_id, type
+				)
+				group by bucket_start, session_id, user_id, organization_id`,
+				string(granularity), formatSQLiteTime(from), formatSQLiteTime(to))
+			if err != nil {
+				return err
+			}
+			n, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			written += int(n)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to roll up activity: %w", err)
+	}
+	return written, nil
+}
+
+// DeleteUserActivity deletes the activity rollups of a user
+func (r *sqliteRepository) DeleteUserActivity(ctx context.Context, userID domain.UserID) error {
+	organization, organizationArgs := sqliteOrganizationCondition(ctx)
+	if _, err := r.db.ExecContext(ctx, `delete from audit_activity_rollups where user_id = ? and `+organization,
+		append([]interface{}{userID.String()}, organizationArgs...)...); err != nil {
+		r.logger.Error("failed to delete user activity",
+			requestid.Field(ctx),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to delete user activity: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,1309 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testSQLiteSession = "550e8400-e29b-41d4-a716-446655440001"
+
+func newTestSQLiteRepository(t *testing.T) (AuditRepository, *sql.DB) {
+	t.Helper()
+
+	db, err := OpenSQLite(context.Background(), filepath.Join(t.TempDir(), "audit.db"), clock.New(), zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return NewSQLiteRepository(db, clock.New(), zap.NewNop()), db
+}
+
+func sqliteEntry(id, userID, eventType string, timestamp time.Time, details string) domain.AuditEntry {
+	entry := domain.AuditEntry{
+		ID:        id,
+		SessionID: testSQLiteSession,
+		UserID:    userID,
+		Type:      eventType,
+		Timestamp: timestamp,
+		IPAddress: "198.51.100.1",
+	}
+	if details != "" {
+		entry.Details = json.RawMessage(details)
+	}
+	return entry
+}
+
+func TestMigrateSQLite_IsIdempotent(t *testing.T) {
+	_, db := newTestSQLiteRepository(t)
+
+	applied, err := MigrateSQLite(context.Background(), db, clock.New())
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+}
+
+func TestMigrateSQLite_StampsClockTime(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	db, err := OpenSQLite(context.Background(), filepath.Join(t.TempDir(), "audit.db"), clock.NewFakeClock(now), zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	var appliedAt string
+	require.NoError(t, db.QueryRow("select applied_at from schema_migrations order by version limit 1").Scan(&appliedAt))
+	parsed, err := parseSQLiteTime(appliedAt)
+	require.NoError(t, err)
+	assert.True(t, now.Equal(parsed))
+}
+
+func TestSQLiteRepository_CreateAndQueryEvents(t *testing.T) {
+	repo, _ := newTestSQLiteRepository(t)
+	ctx := context.Background()
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "edit", base, `{"slideId":"slide-1","after":"Hello"}`),
+		sqliteEntry("00000000-0000-0000-0000-000000000002", "user-2", "comment", base.Add(time.Minute), `{"text":"Looks good"}`),
+		sqliteEntry("00000000-0000-0000-0000-000000000003", "user-1", "edit", base.Add(2*time.Minute), `{"slideId":"slide-2"}`),
+	}))
+
+	entries, total, err := repo.FindBySessionID(ctx, testSQLiteSession, domain.PaginationParams{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000003", entries[0].ID)
+	assert.Equal(t, base.Add(2*time.Minute), entries[0].Timestamp)
+	assert.Equal(t, "198.51.100.1", entries[0].IPAddress)
+	assert.JSONEq(t, `{"slideId":"slide-2"}`, string(entries[0].Details))
+
+	// Continue after the last entry of the first page
+	entries, _, err = repo.FindBySessionID(ctx, testSQLiteSession, domain.PaginationParams{Limit: 2, Cursor: domain.NewCursor(entries[1])})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000001", entries[0].ID)
+
+	entries, total, err = repo.QueryEvents(ctx, testSQLiteSession, domain.EventFilter{
+		Types:  []string{"edit"},
+		UserID: "user-1",
+		From:   base.Add(time.Minute),
+	}, domain.PaginationParams{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000003", entries[0].ID)
+
+	entries, _, err = repo.QueryEvents(ctx, testSQLiteSession, domain.EventFilter{Search: "Looks"}, domain.PaginationParams{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "comment", entries[0].Type)
+
+	stats, err := repo.GetSessionStats(ctx, testSQLiteSession)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.TotalEvents)
+	assert.Equal(t, 2, stats.Contributors)
+	assert.Equal(t, map[string]int{"edit": 2, "comment": 1}, stats.ByType)
+	assert.Equal(t, map[string]int{"slide-1": 1, "slide-2": 1}, stats.EditsPerSlide)
+	require.NotNil(t, stats.FirstActivity)
+	assert.Equal(t, base, *stats.FirstActivity)
+}
+
+func TestSQLiteRepository_QueryEventsAcrossSessions(t *testing.T) {
+	repo, _ := newTestSQLiteRepository(t)
+	ctx := context.Background()
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	other := sqliteEntry("00000000-0000-0000-0000-000000000002", "user-1", "export", base.Add(time.Minute), "")
+	other.SessionID = "550e8400-e29b-41d4-a716-446655440099"
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "export", base, ""),
+		other,
+		sqliteEntry("00000000-0000-0000-0000-000000000003", "user-2", "export", base.Add(2*time.Minute), ""),
+	}))
+
+	entries, total, err := repo.QueryEvents(ctx, "", domain.EventFilter{UserID: "user-1"}, domain.PaginationParams{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, entries, 2)
+	assert.Equal(t, other.SessionID, entries[0].SessionID)
+	assert.Equal(t, testSQLiteSession, entries[1].SessionID)
+}
+
+func TestSQLiteRepository_CreateEventsIsAtomic(t *testing.T) {
+	repo, _ := newTestSQLiteRepository(t)
+	ctx := context.Background()
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	entry := sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "edit", now, "")
+	err := repo.CreateEvents(ctx, []domain.AuditEntry{entry, entry})
+	assert.Error(t, err)
+
+	_, total, err := repo.FindBySessionID(ctx, testSQLiteSession, domain.PaginationParams{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 0, total)
+}
+
+func TestSQLiteRepository_ExistingEventIDs(t *testing.T) {
+	repo, _ := newTestSQLiteRepository(t)
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	entry := sqliteEntry("00000000-0000-0000-0000-00000000000a", "user-1", "edit", now, "")
+	entry.OrganizationID = "acme"
+	require.NoError(t, repo.CreateEvents(tenant.NewContext(context.Background(), "acme"), []domain.AuditEntry{entry}))
+
+	// IDs of every organization count
+	existing, err := repo.ExistingEventIDs(tenant.NewContext(context.Background(), "globex"),
+		[]string{"00000000-0000-0000-0000-00000000000A", "00000000-0000-0000-0000-00000000000b"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"00000000-0000-0000-0000-00000000000a"}, existing)
+}
+
+func TestSQLiteRepository_HashChainSurvivesAnonymization(t *testing.T) {
+	repo, _ := newTestSQLiteRepository(t)
+	ctx := context.Background()
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "comment", now, `{"text":"secret","slideId":"slide-1"}`),
+	}))
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		sqliteEntry("00000000-0000-0000-0000-000000000002", "user-2", "edit", now.Add(time.Second), ""),
+	}))
+
+	affected, err := repo.EraseUserEvents(ctx, "user-1", domain.ErasureAnonymize, false, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.PurgedEvents{{SessionID: testSQLiteSession, Deleted: 1}}, affected)
+
+	chain, err := repo.FindChain(ctx, testSQLiteSession, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, chain, 2)
+	assert.Equal(t, "redacted", chain[0].UserID)
+	assert.Empty(t, chain[0].IPAddress)
+	assert.JSONEq(t, `{"slideId":"slide-1"}`, string(chain[0].Details))
+	assert.NotNil(t, chain[0].RedactedAt)
+
+	verifier := domain.NewChainVerifier(testSQLiteSession)
+	for _, entry := range chain {
+		verifier.Add(entry)
+	}
+	result := verifier.Result()
+	assert.True(t, result.Valid, "issues: %v", result.Issues)
+	assert.Equal(t, int64(2), result.LastSeq)
+	assert.Equal(t, 1, result.RedactedEntries)
+}
+
+func TestSQLiteRepository_SlideEvents(t *testing.T) {
+	repo, _ := newTestSQLiteRepository(t)
+	ctx := context.Background()
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	edited := sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "edit", now, `{"slideId":"slide-3","shapeId":"shape-12"}`)
+	edited.SlideID, edited.ShapeID = "slide-3", "shape-12"
+	commented := sqliteEntry("00000000-0000-0000-0000-000000000002", "user-2", "comment", now.Add(time.Minute), `{"slideId":"slide-3"}`)
+	commented.SlideID = "slide-3"
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		edited,
+		commented,
+		sqliteEntry("00000000-0000-0000-0000-000000000003", "user-1", "edit", now, `{"slideId":"slide-4"}`),
+	}))
+
+	entries, total, err := repo.QueryEvents(ctx, testSQLiteSession, domain.EventFilter{SlideID: "slide-3"}, domain.PaginationParams{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, entries, 2)
+	assert.Equal(t, commented.ID, entries[0].ID)
+	assert.Equal(t, "slide-3", entries[0].SlideID)
+	assert.Equal(t, "shape-12", entries[1].ShapeID)
+
+	entries, total, err = repo.QueryEvents(ctx, testSQLiteSession, domain.EventFilter{SlideID: "slide-3", ShapeID: "shape-12"}, domain.PaginationParams{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, edited.ID, entries[0].ID)
+
+	// The references are not part of the hashed content
+	chain, err := repo.FindChain(ctx, testSQLiteSession, 0, 100)
+	require.NoError(t, err)
+	verifier := domain.NewChainVerifier(testSQLiteSession)
+	for _, entry := range chain {
+		verifier.Add(entry)
+	}
+	assert.True(t, verifier.Result().Valid)
+}
+
+func TestSQLiteRepository_CommentEvents(t *testing.T) {
+	repo, _ := newTestSQLiteRepository(t)
+	ctx := context.Background()
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	created := sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "comment_created", now, `{"commentId":"comment-7","threadId":"thread-2","text":"Tone"}`)
+	created.CommentID, created.ThreadID = "comment-7", "thread-2"
+	resolved := sqliteEntry("00000000-0000-0000-0000-000000000002", "user-2", "comment_resolved", now.Add(time.Minute), `{"commentId":"comment-7","threadId":"thread-2"}`)
+	resolved.CommentID, resolved.ThreadID = "comment-7", "thread-2"
+	other := sqliteEntry("00000000-0000-0000-0000-000000000003", "user-1", "comment_created", now, `{"commentId":"comment-8","threadId":"thread-3","text":"Typo"}`)
+	other.CommentID, other.ThreadID = "comment-8", "thread-3"
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{created, resolved, other}))
+
+	entries, total, err := repo.QueryEvents(ctx, testSQLiteSession, domain.EventFilter{ThreadID: "thread-2"}, domain.PaginationParams{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, entries, 2)
+	assert.Equal(t, resolved.ID, entries[0].ID)
+	assert.Equal(t, "comment-7", entries[0].CommentID)
+	assert.Equal(t, "thread-2", entries[1].ThreadID)
+
+	entries, total, err = repo.QueryEvents(ctx, testSQLiteSession,
+		domain.EventFilter{Types: []string{"comment_created"}, CommentID: "comment-7"}, domain.PaginationParams{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, created.ID, entries[0].ID)
+}
+
+func TestSQLiteRepository_CorrelatedEvents(t *testing.T) {
+	repo, _ := newTestSQLiteRepository(t)
+	ctx := context.Background()
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	requested := sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "export", now, "")
+	requested.CorrelationID = "export-7f3a"
+	downloaded := sqliteEntry("00000000-0000-0000-0000-000000000002", "user-1", "export", now.Add(time.Minute), "")
+	downloaded.CorrelationID = "export-7f3a"
+	downloaded.ParentEventID = "00000000-0000-0000-0000-000000000001"
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		requested,
+		downloaded,
+		sqliteEntry("00000000-0000-0000-0000-000000000003", "user-1", "export", now, ""),
+	}))
+
+	entry, err := repo.FindEvent(ctx, "00000000-0000-0000-0000-000000000002")
+	require.NoError(t, err)
+	assert.Equal(t, "export-7f3a", entry.CorrelationID)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000001", entry.ParentEventID)
+
+	_, err = repo.FindEvent(ctx, "00000000-0000-0000-0000-000000000009")
+	assert.ErrorIs(t, err, domain.ErrEventNotFound)
+
+	entries, total, err := repo.QueryEvents(ctx, testSQLiteSession, domain.EventFilter{CorrelationID: "export-7f3a"}, domain.PaginationParams{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, entries, 2)
+	assert.Equal(t, downloaded.ID, entries[0].ID)
+	assert.Equal(t, requested.ID, entries[1].ID)
+
+	// The links are part of the hashed content
+	chain, err := repo.FindChain(ctx, testSQLiteSession, 0, 100)
+	require.NoError(t, err)
+	verifier := domain.NewChainVerifier(testSQLiteSession)
+	for _, entry := range chain {
+		verifier.Add(entry)
+	}
+	result := verifier.Result()
+	assert.True(t, result.Valid, "issues: %v", result.Issues)
+}
+
+func TestSQLiteRepository_RedactedFields(t *testing.T) {
+	repo, _ := newTestSQLiteRepository(t)
+	ctx := context.Background()
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	redacted := sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "comment", now, `{"text":"[REDACTED:email]"}`)
+	redacted.RedactedFields = []string{"text"}
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		redacted,
+		sqliteEntry("00000000-0000-0000-0000-000000000002", "user-1", "comment", now, `{"text":"Looks good"}`),
+	}))
+
+	entry, err := repo.FindEvent(ctx, domain.EventID(redacted.ID))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"text"}, entry.RedactedFields)
+	entry, err = repo.FindEvent(ctx, "00000000-0000-0000-0000-000000000002")
+	require.NoError(t, err)
+	assert.Nil(t, entry.RedactedFields)
+
+	// The redacted fields are part of the hashed content
+	chain, err := repo.FindChain(ctx, testSQLiteSession, 0, 100)
+	require.NoError(t, err)
+	verifier := domain.NewChainVerifier(testSQLiteSession)
+	for _, entry := range chain {
+		verifier.Add(entry)
+	}
+	result := verifier.Result()
+	assert.True(t, result.Valid, "issues: %v", result.Issues)
+}
+
+func TestSQLiteRepository_ReceivedTime(t *testing.T) {
+	repo, _ := newTestSQLiteRepository(t)
+	ctx := context.Background()
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	received := now.Add(2*time.Second + 123456789)
+
+	stamped := sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "edit", now, "")
+	stamped.ReceivedAt = &received
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		stamped,
+		sqliteEntry("00000000-0000-0000-0000-000000000002", "user-1", "edit", now, ""),
+	}))
+
+	entry, err := repo.FindEvent(ctx, domain.EventID(stamped.ID))
+	require.NoError(t, err)
+	require.NotNil(t, entry.ReceivedAt)
+	assert.Equal(t, received.Truncate(time.Microsecond), *entry.ReceivedAt)
+	assert.Equal(t, now, entry.Timestamp)
+	entry, err = repo.FindEvent(ctx, "00000000-0000-0000-0000-000000000002")
+	require.NoError(t, err)
+	assert.Nil(t, entry.ReceivedAt)
+
+	// The received time is part of the hashed content
+	chain, err := repo.FindChain(ctx, testSQLiteSession, 0, 100)
+	require.NoError(t, err)
+	verifier := domain.NewChainVerifier(testSQLiteSession)
+	for _, entry := range chain {
+		verifier.Add(entry)
+	}
+	result := verifier.Result()
+	assert.True(t, result.Valid, "issues: %v", result.Issues)
+}
+
+func TestSQLiteRepository_OrganizationIsolation(t *testing.T) {
+	_, db := newTestSQLiteRepository(t)
+	repo := newSQLiteRepository(db, clock.New(), zap.NewNop(), false)
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	acme := tenant.NewContext(context.Background(), "acme")
+	globex := tenant.NewContext(context.Background(), "globex")
+	defaultOrg := tenant.NewContext(context.Background(), "")
+
+	acmeEntry := sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "edit", now, `{"slideId":"slide-1"}`)
+	acmeEntry.OrganizationID = "acme"
+	globexEntry := sqliteEntry("00000000-0000-0000-0000-000000000002", "user-1", "edit", now.Add(time.Minute), `{"slideId":"slide-1"}`)
+	globexEntry.OrganizationID = "globex"
+	legacyEntry := sqliteEntry("00000000-0000-0000-0000-000000000003", "user-1", "view", now.Add(2*time.Minute), "")
+	require.NoError(t, repo.CreateEvents(acme, []domain.AuditEntry{acmeEntry}))
+	require.NoError(t, repo.CreateEvents(globex, []domain.AuditEntry{globexEntry}))
+	require.NoError(t, repo.CreateEvents(context.Background(), []domain.AuditEntry{legacyEntry}))
+
+	// Writes for another organization are rejected
+	err := repo.CreateEvents(acme, []domain.AuditEntry{sqliteEntry("00000000-0000-0000-0000-000000000004", "user-1", "view", now, "")})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	page := domain.PaginationParams{Limit: 10}
+	for _, tt := range []struct {
+		name string
+		ctx  context.Context
+		want []string
+	}{
+		{name: "acme", ctx: acme, want: []string{acmeEntry.ID}},
+		{name: "globex", ctx: globex, want: []string{globexEntry.ID}},
+		{name: "default organization", ctx: defaultOrg, want: []string{legacyEntry.ID}},
+		{name: "unscoped", ctx: context.Background(), want: []string{legacyEntry.ID, globexEntry.ID, acmeEntry.ID}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, total, err := repo.QueryEvents(tt.ctx, "", domain.EventFilter{}, page)
+			require.NoError(t, err)
+			assert.Equal(t, len(tt.want), total)
+			ids := make([]string, len(entries))
+			for i, entry := range entries {
+				ids[i] = entry.ID
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+
+	_, err = repo.FindEvent(globex, domain.EventID(acmeEntry.ID))
+	assert.ErrorIs(t, err, domain.ErrEventNotFound)
+	entry, err := repo.FindEvent(acme, domain.EventID(acmeEntry.ID))
+	require.NoError(t, err)
+	assert.Equal(t, "acme", entry.OrganizationID)
+
+	stats, err := repo.GetSessionStats(acme, testSQLiteSession)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.TotalEvents)
+	assert.Equal(t, map[string]int{"slide-1": 1}, stats.EditsPerSlide)
+
+	// The organization is part of the hashed content, and a scoped chain only holds its own entries
+	chain, err := repo.FindChain(acme, testSQLiteSession, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, chain, 1)
+	hash, err := domain.ContentHash(chain[0].AuditEntry)
+	require.NoError(t, err)
+	assert.Equal(t, chain[0].ContentHash, hash)
+
+	// Erasure only touches the entries of the organization
+	erased, err := repo.EraseUserEvents(acme, "user-1", domain.ErasureDelete, false, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.PurgedEvents{{SessionID: testSQLiteSession, OrganizationID: "acme", Deleted: 1}}, erased)
+	_, total, err := repo.QueryEvents(context.Background(), "", domain.EventFilter{}, page)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+
+	// Holds are listed and released within their organization only
+	hold := domain.LegalHold{
+		ID: "hold-1", Scope: domain.LegalHoldUser, Target: "user-9",
+		Reason: "Investigation", PlacedBy: "admin-1", PlacedAt: now, OrganizationID: "acme",
+	}
+	require.NoError(t, repo.CreateLegalHold(acme, hold))
+	holds, err := repo.ListLegalHolds(globex, false)
+	require.NoError(t, err)
+	assert.Empty(t, holds)
+	_, err = repo.ReleaseLegalHold(globex, hold.ID, "admin-2", now)
+	assert.ErrorIs(t, err, domain.ErrLegalHoldNotFound)
+	holds, err = repo.ListLegalHolds(acme, false)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.LegalHold{hold}, holds)
+}
+
+func TestSQLiteRepository_RetentionAndErasure(t *testing.T) {
+	repo, _ := newTestSQLiteRepository(t)
+	ctx := context.Background()
+	old := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	cutoff := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "view", old, ""),
+		sqliteEntry("00000000-0000-0000-0000-000000000002", "user-1", "view", old.Add(time.Hour), ""),
+		sqliteEntry("00000000-0000-0000-0000-000000000003", "user-2", "view", cutoff.Add(time.Hour), ""),
+		sqliteEntry("00000000-0000-0000-0000-000000000004", "user-2", "edit", old, ""),
+	}))
+
+	expired, err := repo.FindExpiredEvents(ctx, "view", cutoff, 10)
+	require.NoError(t, err)
+	require.Len(t, expired, 2)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000001", expired[0].ID)
+
+	deleted, err := repo.DeleteEvents(ctx, []string{expired[0].ID})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.PurgedEvents{{SessionID: testSQLiteSession, Deleted: 1}}, deleted)
+
+	purged, err := repo.PurgeEvents(ctx, "view", cutoff, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.PurgedEvents{{SessionID: testSQLiteSession, Deleted: 1}}, purged)
+
+	erased, err := repo.EraseUserEvents(ctx, "user-2", domain.ErasureDelete, false, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.PurgedEvents{{SessionID: testSQLiteSession, Deleted: 2}}, erased)
+
+	_, total, err := repo.FindBySessionID(ctx, testSQLiteSession, domain.PaginationParams{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 0, total)
+}
+
+func TestSQLiteRepository_SessionsAndShares(t *testing.T) {
+	repo, db := newTestSQLiteRepository(t)
+	ctx := context.Background()
+
+	_, err := repo.GetSession(ctx, testSQLiteSession)
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+
+	_, err = db.Exec("insert into sessions (id, user_id) values (?, ?)", testSQLiteSession, "owner-1")
+	require.NoError(t, err)
+	_, err = db.Exec(`insert into session_shares (id, session_id, share_token_jti, permissions, expires_at)
+		values ('share-1', ?, 'jti-1', '["VIEW","COMMENT"]', '2030-01-01T00:00:00.000000Z')`, testSQLiteSession)
+	require.NoError(t, err)
+	_, err = db.Exec(`insert into session_shares (id, session_id, share_token_jti, revoked_at)
+		values ('share-2', ?, 'jti-2', '2024-01-01T00:00:00.000000Z')`, testSQLiteSession)
+	require.NoError(t, err)
+
+	session, err := repo.GetSession(ctx, testSQLiteSession)
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserID("owner-1"), session.UserID)
+
+	share, err := repo.GetActiveShare(ctx, "jti-1", testSQLiteSession)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SharePermission{domain.SharePermissionView, domain.SharePermissionComment}, share.Permissions)
+	require.NotNil(t, share.ExpiresAt)
+	assert.Equal(t, 2030, share.ExpiresAt.Year())
+
+	_, err = repo.GetActiveShare(ctx, "jti-2", testSQLiteSession)
+	assert.ErrorIs(t, err, domain.ErrShareNotFound)
+}
+
+func TestSQLiteRepository_Outbox(t *testing.T) {
+	_, db := newTestSQLiteRepository(t)
+	repo := newSQLiteRepository(db, clock.New(), zap.NewNop(), true)
+	ctx := context.Background()
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "edit", base, `{"slideId":"slide-1"}`),
+		sqliteEntry("00000000-0000-0000-0000-000000000002", "user-1", "view", base, ""),
+	}))
+
+	claimed, err := repo.ClaimOutbox(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000001", claimed[0].EventID)
+	assert.Equal(t, "edit", claimed[0].Type)
+	assert.Equal(t, 1, claimed[0].Attempts)
+
+	// The payload is the stored event without the client IP
+	var payload domain.AuditEntry
+	require.NoError(t, json.Unmarshal(claimed[0].Payload, &payload))
+	assert.Equal(t, "user-1", payload.UserID)
+	assert.Equal(t, base, payload.Timestamp)
+	assert.Empty(t, payload.IPAddress)
+
+	// Leased messages are not handed out twice
+	again, err := repo.ClaimOutbox(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+
+	require.NoError(t, repo.CompleteOutbox(ctx, []int64{claimed[0].ID}))
+	require.NoError(t, repo.FailOutbox(ctx, claimed[1].ID, time.Now().Add(-time.Second), "timeout", false))
+
+	retried, err := repo.ClaimOutbox(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, retried, 1)
+	assert.Equal(t, claimed[1].ID, retried[0].ID)
+	assert.Equal(t, 2, retried[0].Attempts)
+
+	// Dead messages stay in the table but are never claimed again
+	require.NoError(t, repo.FailOutbox(ctx, retried[0].ID, time.Now().Add(-time.Second), "timeout", true))
+	dead, err := repo.ClaimOutbox(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, dead)
+}
+
+func TestSQLiteRepository_OutboxUsesClock(t *testing.T) {
+	_, db := newTestSQLiteRepository(t)
+	clk := clock.NewFakeClock(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC))
+	repo := newSQLiteRepository(db, clk, zap.NewNop(), true)
+	ctx := context.Background()
+
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "edit", clk.Now(), ""),
+	}))
+
+	claimed, err := repo.ClaimOutbox(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	// The lease runs on the repository clock, not the wall clock
+	clk.Advance(30 * time.Second)
+	leased, err := repo.ClaimOutbox(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, leased)
+
+	clk.Advance(time.Minute)
+	expired, err := repo.ClaimOutbox(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, 2, expired[0].Attempts)
+
+	require.NoError(t, repo.FailOutbox(ctx, expired[0].ID, clk.Now(), "timeout", true))
+	letters, err := repo.ListDeadOutbox(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.True(t, letters[0].DeadAt.Equal(clk.Now()))
+}
+
+func TestSQLiteRepository_ReplayOutbox(t *testing.T) {
+	_, db := newTestSQLiteRepository(t)
+	repo := newSQLiteRepository(db, clock.New(), zap.NewNop(), true)
+	ctx := context.Background()
+
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "edit", time.Now(), ""),
+		sqliteEntry("00000000-0000-0000-0000-000000000002", "user-1", "view", time.Now(), ""),
+	}))
+
+	claimed, err := repo.ClaimOutbox(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	require.NoError(t, repo.FailOutbox(ctx, claimed[0].ID, time.Now(), "timeout", true))
+
+	// Messages created later are left alone
+	replayed, err := repo.ReplayOutbox(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, replayed)
+
+	// Only dead messages are replayed, with a fresh attempt count
+	replayed, err = repo.ReplayOutbox(ctx, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+
+	due, err := repo.ClaimOutbox(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, claimed[0].ID, due[0].ID)
+	assert.Equal(t, 1, due[0].Attempts)
+}
+
+func TestSQLiteRepository_EnqueueOutbox(t *testing.T) {
+	_, db := newTestSQLiteRepository(t)
+	repo := newSQLiteRepository(db, clock.New(), zap.NewNop(), true)
+	ctx := context.Background()
+
+	first := sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "edit", time.Now(), "")
+	second := sqliteEntry("00000000-0000-0000-0000-000000000002", "user-1", "view", time.Now(), "")
+	third := sqliteEntry("00000000-0000-0000-0000-000000000003", "user-1", "view", time.Now(), "")
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{first, second, third}))
+
+	// The first message is delivered, the second is dead and the third still waits
+	claimed, err := repo.ClaimOutbox(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 3)
+	require.NoError(t, repo.CompleteOutbox(ctx, []int64{claimed[0].ID}))
+	require.NoError(t, repo.FailOutbox(ctx, claimed[1].ID, time.Now(), "timeout", true))
+	require.NoError(t, repo.FailOutbox(ctx, claimed[2].ID, time.Now().Add(time.Hour), "timeout", false))
+
+	queued, err := repo.EnqueueOutbox(ctx, []domain.AuditEntry{first, second, third})
+	require.NoError(t, err)
+	assert.Equal(t, 2, queued)
+
+	due, err := repo.ClaimOutbox(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, second.ID, due[0].EventID)
+	assert.Equal(t, 1, due[0].Attempts)
+	assert.Equal(t, first.ID, due[1].EventID)
+}
+
+func TestSQLiteRepository_DeadOutbox(t *testing.T) {
+	_, db := newTestSQLiteRepository(t)
+	repo := newSQLiteRepository(db, clock.New(), zap.NewNop(), true)
+	ctx := context.Background()
+
+	acme := sqliteEntry("00000000-0000-0000-0000-000000000003", "user-1", "share", time.Now(), "")
+	acme.OrganizationID = "acme"
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "edit", time.Now(), ""),
+		sqliteEntry("00000000-0000-0000-0000-000000000002", "user-1", "view", time.Now(), ""),
+		acme,
+	}))
+
+	// The first two messages run out of attempts, the third is still retried
+	claimed, err := repo.ClaimOutbox(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 3)
+	require.NoError(t, repo.FailOutbox(ctx, claimed[0].ID, time.Now(), "connection refused", true))
+	require.NoError(t, repo.FailOutbox(ctx, claimed[1].ID, time.Now(), "webhook responded with status 503", true))
+	require.NoError(t, repo.FailOutbox(ctx, claimed[2].ID, time.Now().Add(time.Hour), "timeout", false))
+
+	letters, err := repo.ListDeadOutbox(ctx, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, claimed[1].ID, letters[0].ID)
+	assert.Equal(t, "view", letters[0].Type)
+	assert.Equal(t, 1, letters[0].Attempts)
+	assert.Equal(t, "webhook responded with status 503", letters[0].LastError)
+	assert.False(t, letters[0].DeadAt.IsZero())
+	assert.JSONEq(t, string(claimed[1].Payload), string(letters[0].Payload))
+
+	letters, err = repo.ListDeadOutbox(ctx, 10, letters[0].ID)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, claimed[0].ID, letters[0].ID)
+
+	// Admins of an organization only see and retry its messages
+	require.NoError(t, repo.FailOutbox(ctx, claimed[2].ID, time.Now(), "timeout", true))
+	scoped := tenant.NewContext(ctx, "acme")
+	letters, err = repo.ListDeadOutbox(scoped, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, claimed[2].ID, letters[0].ID)
+	assert.ErrorIs(t, repo.RetryDeadOutbox(scoped, claimed[0].ID), domain.ErrDeadLetterNotFound)
+
+	// A retried message is due again with a fresh attempt count and no longer dead
+	require.NoError(t, repo.RetryDeadOutbox(ctx, claimed[0].ID))
+	assert.ErrorIs(t, repo.RetryDeadOutbox(ctx, claimed[0].ID), domain.ErrDeadLetterNotFound)
+
+	due, err := repo.ClaimOutbox(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, claimed[0].ID, due[0].ID)
+	assert.Equal(t, 1, due[0].Attempts)
+}
+
+func TestSQLiteRepository_OutboxDisabled(t *testing.T) {
+	_, db := newTestSQLiteRepository(t)
+	repo := newSQLiteRepository(db, clock.New(), zap.NewNop(), false)
+	ctx := context.Background()
+
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "edit", time.Now(), ""),
+	}))
+
+	claimed, err := repo.ClaimOutbox(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func TestSQLiteRepository_EventTypes(t *testing.T) {
+	_, db := newTestSQLiteRepository(t)
+	repo := newSQLiteRepository(db, clock.New(), zap.NewNop(), false)
+	ctx := context.Background()
+	createdAt := time.Date(2024, 1, 11, 9, 0, 0, 0, time.UTC)
+
+	types, err := repo.ListEventTypes(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, types)
+
+	approved := domain.EventType{
+		Name: "terminology_approved", DisplayName: "Terminology approved", Severity: domain.SeverityLow,
+		Schema: json.RawMessage(`{"type":"object"}`), CreatedBy: "admin-1", CreatedAt: &createdAt,
+	}
+	require.NoError(t, repo.CreateEventType(ctx, approved))
+	require.NoError(t, repo.CreateEventType(ctx, domain.EventType{
+		Name: "glossary_synced", DisplayName: "Glossary synced", Severity: domain.SeverityInfo,
+		CreatedBy: "admin-1", CreatedAt: &createdAt,
+	}))
+	assert.ErrorIs(t, repo.CreateEventType(ctx, approved), domain.ErrEventTypeExists)
+
+	types, err = repo.ListEventTypes(ctx)
+	require.NoError(t, err)
+	require.Len(t, types, 2)
+	assert.Equal(t, domain.AuditAction("glossary_synced"), types[0].Name)
+	assert.Nil(t, types[0].Schema)
+	assert.Equal(t, approved, types[1])
+}
+
+func TestSQLiteRepository_LegalHolds(t *testing.T) {
+	_, db := newTestSQLiteRepository(t)
+	repo := newSQLiteRepository(db, clock.New(), zap.NewNop(), false)
+	ctx := context.Background()
+	old := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	cutoff := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	placedAt := time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "view", old, ""),
+		sqliteEntry("00000000-0000-0000-0000-000000000002", "user-2", "view", old, ""),
+	}))
+
+	sessionHold := domain.LegalHold{
+		ID: "hold-1", Scope: domain.LegalHoldSession, Target: testSQLiteSession,
+		Reason: "Litigation 2024-017", PlacedBy: "admin-1", PlacedAt: placedAt,
+	}
+	userHold := domain.LegalHold{
+		ID: "hold-2", Scope: domain.LegalHoldUser, Target: "user-2",
+		Reason: "Investigation", PlacedBy: "admin-1", PlacedAt: placedAt.Add(time.Hour),
+	}
+	require.NoError(t, repo.CreateLegalHold(ctx, sessionHold))
+	require.NoError(t, repo.CreateLegalHold(ctx, userHold))
+
+	// Held entries are neither expired nor erased without an override
+	expired, err := repo.FindExpiredEvents(ctx, "view", cutoff, 10)
+	require.NoError(t, err)
+	assert.Empty(t, expired)
+	purged, err := repo.PurgeEvents(ctx, "view", cutoff, 10)
+	require.NoError(t, err)
+	assert.Empty(t, purged)
+	erased, err := repo.EraseUserEvents(ctx, "user-1", domain.ErasureDelete, false, 10)
+	require.NoError(t, err)
+	assert.Empty(t, erased)
+
+	holds, err := repo.ListLegalHolds(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.LegalHold{userHold, sessionHold}, holds)
+
+	releasedAt := placedAt.Add(24 * time.Hour)
+	released, err := repo.ReleaseLegalHold(ctx, "hold-1", "admin-2", releasedAt)
+	require.NoError(t, err)
+	assert.Equal(t, "admin-2", released.ReleasedBy)
+	assert.Equal(t, releasedAt, *released.ReleasedAt)
+
+	_, err = repo.ReleaseLegalHold(ctx, "hold-1", "admin-2", releasedAt)
+	assert.ErrorIs(t, err, domain.ErrLegalHoldReleased)
+	_, err = repo.ReleaseLegalHold(ctx, "hold-3", "admin-2", releasedAt)
+	assert.ErrorIs(t, err, domain.ErrLegalHoldNotFound)
+
+	// Released holds stay listed but no longer protect entries
+	holds, err = repo.ListLegalHolds(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, holds, 2)
+	assert.False(t, holds[1].Active())
+	holds, err = repo.ListLegalHolds(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.LegalHold{userHold}, holds)
+
+	purged, err = repo.PurgeEvents(ctx, "view", cutoff, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.PurgedEvents{{SessionID: testSQLiteSession, Deleted: 1}}, purged)
+
+	// The user hold is only bypassed by an override
+	erased, err = repo.EraseUserEvents(ctx, "user-2", domain.ErasureAnonymize, false, 10)
+	require.NoError(t, err)
+	assert.Empty(t, erased)
+	erased, err = repo.EraseUserEvents(ctx, "user-2", domain.ErasureAnonymize, true, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.PurgedEvents{{SessionID: testSQLiteSession, Deleted: 1}}, erased)
+}
+
+func TestSQLiteRepository_Revocations(t *testing.T) {
+	_, db := newTestSQLiteRepository(t)
+	repo := newSQLiteRepository(db, clock.New(), zap.NewNop(), false)
+	ctx := context.Background()
+	revokedAt := time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+	expiresAt := revokedAt.Add(24 * time.Hour)
+
+	user := domain.Revocation{
+		ID: "revocation-1", Kind: domain.RevocationUser, Target: "user-1",
+		Reason: "Banned", RevokedBy: "admin-1", RevokedAt: revokedAt,
+	}
+	session := domain.Revocation{
+		ID: "revocation-2", Kind: domain.RevocationSession, Target: testSQLiteSession,
+		Reason: "Forced logout", RevokedBy: "admin-1", RevokedAt: revokedAt.Add(time.Hour), ExpiresAt: &expiresAt,
+		OrganizationID: "acme",
+	}
+	require.NoError(t, repo.CreateRevocation(ctx, user))
+	require.NoError(t, repo.CreateRevocation(ctx, session))
+
+	revocations, err := repo.ListRevocations(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Revocation{session, user}, revocations)
+
+	// Revocations are only listed and lifted in their organization
+	acme := tenant.NewContext(ctx, "acme")
+	revocations, err = repo.ListRevocations(acme, false)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Revocation{session}, revocations)
+	_, err = repo.LiftRevocation(acme, "revocation-1", "admin-2", revokedAt)
+	assert.ErrorIs(t, err, domain.ErrRevocationNotFound)
+
+	liftedAt := revokedAt.Add(2 * time.Hour)
+	lifted, err := repo.LiftRevocation(ctx, "revocation-1", "admin-2", liftedAt)
+	require.NoError(t, err)
+	assert.Equal(t, "admin-2", lifted.LiftedBy)
+	assert.Equal(t, liftedAt, *lifted.LiftedAt)
+	assert.False(t, lifted.Active(liftedAt))
+
+	_, err = repo.LiftRevocation(ctx, "revocation-1", "admin-2", liftedAt)
+	assert.ErrorIs(t, err, domain.ErrRevocationLifted)
+	_, err = repo.LiftRevocation(ctx, "revocation-3", "admin-2", liftedAt)
+	assert.ErrorIs(t, err, domain.ErrRevocationNotFound)
+
+	// Lifted revocations stay listed
+	revocations, err = repo.ListRevocations(ctx, false)
+	require.NoError(t, err)
+	assert.Len(t, revocations, 2)
+	revocations, err = repo.ListRevocations(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Revocation{session}, revocations)
+}
+
+func TestSQLiteRepository_Watches(t *testing.T) {
+	_, db := newTestSQLiteRepository(t)
+	repo := newSQLiteRepository(db, clock.New(), zap.NewNop(), false)
+	ctx := context.Background()
+	createdAt := time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+	otherSession := "550e8400-e29b-41d4-a716-446655440009"
+
+	first := domain.Watch{SessionID: testSQLiteSession, UserID: "user-1", EventTypes: []string{"share"}, CreatedAt: createdAt}
+	second := domain.Watch{SessionID: otherSession, UserID: "user-1", EventTypes: []string{"export"}, CreatedAt: createdAt.Add(time.Hour),
+		OrganizationID: "acme"}
+	other := domain.Watch{SessionID: testSQLiteSession, UserID: "user-2", EventTypes: []string{"comment"}, CreatedAt: createdAt}
+	for _, watch := range []domain.Watch{first, second, other} {
+		stored, err := repo.PutWatch(ctx, watch)
+		require.NoError(t, err)
+		assert.Equal(t, watch, *stored)
+	}
+
+	// Watching again replaces the event types and keeps the creation time
+	replaced, err := repo.PutWatch(ctx, domain.Watch{SessionID: testSQLiteSession, UserID: "user-1",
+		EventTypes: []string{"share", "comment_created"}, CreatedAt: createdAt.Add(2 * time.Hour)})
+	require.NoError(t, err)
+	first.EventTypes = []string{"share", "comment_created"}
+	assert.Equal(t, first, *replaced)
+
+	watch, err := repo.GetWatch(ctx, testSQLiteSession, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, first, *watch)
+
+	watches, err := repo.ListUserWatches(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Watch{second, first}, watches)
+	watches, err = repo.ListSessionWatches(ctx, testSQLiteSession)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Watch{first, other}, watches)
+
+	require.NoError(t, repo.DeleteWatch(ctx, testSQLiteSession, "user-1"))
+	assert.ErrorIs(t, repo.DeleteWatch(ctx, testSQLiteSession, "user-1"), domain.ErrWatchNotFound)
+	_, err = repo.GetWatch(ctx, testSQLiteSession, "user-1")
+	assert.ErrorIs(t, err, domain.ErrWatchNotFound)
+}
+
+func TestSQLiteRepository_Reports(t *testing.T) {
+	_, db := newTestSQLiteRepository(t)
+	repo := newSQLiteRepository(db, clock.New(), zap.NewNop(), false)
+	ctx := context.Background()
+	createdAt := time.Date(2024, 1, 17, 10, 30, 0, 0, time.UTC)
+	scheduledAt := time.Date(2024, 1, 22, 6, 0, 0, 0, time.UTC)
+
+	weekly := domain.Report{ID: "0b8f5e0c-3c1d-4a57-9d0e-6f1b2c3d4e5f", Name: "Weekly", Kind: domain.ReportSessionActivity,
+		SessionID: testSQLiteSession, Schedule: "0 6 * * 1", Format: domain.ReportCSV, Delivery: domain.ReportWebhook,
+		Target: "https://reports.example.com", CreatedBy: "admin-1", CreatedAt: createdAt, NextRunAt: scheduledAt}
+	monthly := domain.Report{ID: "1c9f6f1d-4d2e-4b68-8e1f-7a2c3d4e5f60", Name: "Monthly exports", Kind: domain.ReportExportSummary,
+		Schedule: "@monthly", Format: domain.ReportJSON, Delivery: domain.ReportStorage, CreatedBy: "admin-2",
+		CreatedAt: createdAt, NextRunAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), OrganizationID: "acme"}
+	require.NoError(t, repo.CreateReport(ctx, weekly))
+	require.NoError(t, repo.CreateReport(ctx, monthly))
+
+	reports, err := repo.ListReports(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Report{monthly, weekly}, reports)
+	reports, err = repo.ListReports(tenant.NewContext(ctx, "acme"))
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Report{monthly}, reports)
+	_, err = repo.GetReport(tenant.NewContext(ctx, "acme"), weekly.ID)
+	assert.ErrorIs(t, err, domain.ErrReportNotFound)
+
+	due, err := repo.ListDueReports(ctx, scheduledAt, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Report{weekly}, due)
+
+	// The run is claimed once
+	next := scheduledAt.AddDate(0, 0, 7)
+	claimed, err := repo.AdvanceReport(ctx, weekly.ID, scheduledAt, next)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	claimed, err = repo.AdvanceReport(ctx, weekly.ID, scheduledAt, next)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	stored, err := repo.GetReport(ctx, weekly.ID)
+	require.NoError(t, err)
+	weekly.NextRunAt = next
+	weekly.LastRunAt = &scheduledAt
+	assert.Equal(t, weekly, *stored)
+
+	succeeded := domain.ReportRun{ID: "5d2c1b0a-9e8f-4a7b-8c6d-5e4f3a2b1c0d", ReportID: weekly.ID, Trigger: domain.ReportTriggerSchedule,
+		Status: domain.ReportRunSucceeded, PeriodFrom: createdAt, PeriodTo: scheduledAt, StartedAt: scheduledAt.Add(time.Second),
+		FinishedAt: scheduledAt.Add(2 * time.Second), Rows: 12}
+	failed := domain.ReportRun{ID: "6e3d2c1b-0f9e-4b8c-9d7e-6f5e4d3c2b1a", ReportID: weekly.ID, Trigger: domain.ReportTriggerManual,
+		Status: domain.ReportRunFailed, PeriodFrom: scheduledAt, PeriodTo: scheduledAt.Add(time.Hour), StartedAt: scheduledAt.Add(time.Hour),
+		FinishedAt: scheduledAt.Add(time.Hour), Error: "webhook returned status 502"}
+	require.NoError(t, repo.CreateReportRun(ctx, succeeded))
+	require.NoError(t, repo.CreateReportRun(ctx, failed))
+
+	runs, err := repo.ListReportRuns(ctx, weekly.ID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ReportRun{failed, succeeded}, runs)
+
+	// Deleting a report deletes its runs
+	require.NoError(t, repo.DeleteReport(ctx, weekly.ID))
+	assert.ErrorIs(t, repo.DeleteReport(ctx, weekly.ID), domain.ErrReportNotFound)
+	runs, err = repo.ListReportRuns(ctx, weekly.ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
+
+func TestSQLiteRepository_SavedQueries(t *testing.T) {
+	_, db := newTestSQLiteRepository(t)
+	repo := newSQLiteRepository(db, clock.New(), zap.NewNop(), false)
+	ctx := context.Background()
+	createdAt := time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+
+	mine := domain.SavedQuery{ID: "0b8f5e0c-3c1d-4a57-9d0e-6f1b2c3d4e5f", Name: "My edits this week", Scope: domain.SavedQueryPrivate,
+		Filter:    domain.SavedQueryFilter{Types: []string{"edit", "merge"}, UserID: domain.SavedQueryCurrentUser, Days: 7},
+		CreatedBy: "user-1", CreatedAt: createdAt, UpdatedAt: createdAt}
+	shared := domain.SavedQuery{ID: "1c9f6f1d-4d2e-4b68-8e1f-7a2c3d4e5f60", Name: "All exports", Description: "Every export",
+		Scope: domain.SavedQueryOrganization, Filter: domain.SavedQueryFilter{Types: []string{"export"}}, CreatedBy: "user-2",
+		CreatedAt: createdAt, UpdatedAt: createdAt}
+	private := domain.SavedQuery{ID: "2d0a7a2e-5e3f-4c79-9f20-8b3d4e5f6071", Name: "Critical", Scope: domain.SavedQueryPrivate,
+		Filter: domain.SavedQueryFilter{Severities: []domain.EntrySeverity{domain.EntrySeverityCritical}}, CreatedBy: "user-2",
+		CreatedAt: createdAt, UpdatedAt: createdAt}
+	acme := domain.SavedQuery{ID: "3e1b8b3f-6f40-4d8a-a031-9c4e5f607182", Name: "Acme shares", Scope: domain.SavedQueryOrganization,
+		Filter: domain.SavedQueryFilter{Types: []string{"share"}}, CreatedBy: "user-3", CreatedAt: createdAt, UpdatedAt: createdAt,
+		OrganizationID: "acme"}
+	for _, query := range []domain.SavedQuery{mine, shared, private, acme} {
+		require.NoError(t, repo.CreateSavedQuery(ctx, query))
+	}
+
+	// Users see their own queries and the shared ones of their organization
+	queries, err := repo.ListSavedQueries(tenant.NewContext(ctx, ""), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SavedQuery{shared, mine}, queries)
+	queries, err = repo.ListSavedQueries(tenant.NewContext(ctx, "acme"), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SavedQuery{acme}, queries)
+
+	query, err := repo.GetSavedQuery(ctx, mine.ID)
+	require.NoError(t, err)
+	assert.Equal(t, mine, *query)
+	_, err = repo.GetSavedQuery(tenant.NewContext(ctx, "acme"), mine.ID)
+	assert.ErrorIs(t, err, domain.ErrSavedQueryNotFound)
+
+	mine.Name = "My edits this month"
+	mine.Scope = domain.SavedQueryOrganization
+	mine.Filter.Days = 30
+	mine.UpdatedAt = createdAt.Add(time.Hour)
+	require.NoError(t, repo.UpdateSavedQuery(ctx, mine))
+	query, err = repo.GetSavedQuery(ctx, mine.ID)
+	require.NoError(t, err)
+	assert.Equal(t, mine, *query)
+	assert.ErrorIs(t, repo.UpdateSavedQuery(tenant.NewContext(ctx, "acme"), mine), domain.ErrSavedQueryNotFound)
+
+	require.NoError(t, repo.DeleteSavedQuery(ctx, mine.ID))
+	assert.ErrorIs(t, repo.DeleteSavedQuery(ctx, mine.ID), domain.ErrSavedQueryNotFound)
+	_, err = repo.GetSavedQuery(ctx, mine.ID)
+	assert.ErrorIs(t, err, domain.ErrSavedQueryNotFound)
+}
+
+func TestSQLiteRepository_EventLabels(t *testing.T) {
+	plain, db := newTestSQLiteRepository(t)
+	repo := newSQLiteRepository(db, clock.New(), zap.NewNop(), false)
+	ctx := context.Background()
+	base := time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+	page := domain.PaginationParams{Limit: 50}
+
+	first := "00000000-0000-0000-0000-000000000001"
+	second := "00000000-0000-0000-0000-000000000002"
+	require.NoError(t, plain.CreateEvents(ctx, []domain.AuditEntry{
+		sqliteEntry(first, "user-1", "edit", base, ""),
+		sqliteEntry(second, "user-1", "export", base.Add(time.Minute), ""),
+	}))
+
+	labels, err := repo.GetEventLabels(ctx, first)
+	require.NoError(t, err)
+	assert.Nil(t, labels)
+
+	updatedAt := base.Add(time.Hour)
+	disputed := domain.EventLabels{EventID: first, SessionID: testSQLiteSession, Labels: []string{"disputed"}, Version: 1,
+		UpdatedBy: "user-1", UpdatedAt: &updatedAt}
+	require.NoError(t, repo.SaveEventLabels(ctx, disputed))
+	labels, err = repo.GetEventLabels(ctx, first)
+	require.NoError(t, err)
+	assert.Equal(t, disputed, *labels)
+
+	// A change made against an older version is refused
+	assert.ErrorIs(t, repo.SaveEventLabels(ctx, disputed), domain.ErrVersionConflict)
+	stale := disputed
+	stale.Version = 3
+	assert.ErrorIs(t, repo.SaveEventLabels(ctx, stale), domain.ErrVersionConflict)
+
+	both := disputed
+	both.Labels = []string{"billing-relevant", "disputed"}
+	both.Version = 2
+	require.NoError(t, repo.SaveEventLabels(ctx, both))
+	require.NoError(t, repo.SaveEventLabels(ctx, domain.EventLabels{EventID: second, SessionID: testSQLiteSession,
+		Labels: []string{"billing-relevant"}, Version: 1, UpdatedBy: "user-1", UpdatedAt: &updatedAt}))
+
+	// Labels of the default organization are not seen from other organizations
+	labels, err = repo.GetEventLabels(tenant.NewContext(ctx, "acme"), first)
+	require.NoError(t, err)
+	assert.Nil(t, labels)
+
+	// Events carrying any of the labels match
+	entries, total, err := repo.QueryEvents(ctx, testSQLiteSession, domain.EventFilter{Labels: []string{"disputed"}}, page)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, first, entries[0].ID)
+	_, total, err = repo.QueryEvents(ctx, testSQLiteSession, domain.EventFilter{Labels: []string{"disputed", "billing-relevant"}}, page)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	_, total, err = repo.QueryEvents(ctx, "", domain.EventFilter{Labels: []string{"unknown"}}, page)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+
+	// The labels of an event are deleted with it
+	_, err = repo.DeleteEvents(ctx, []string{first})
+	require.NoError(t, err)
+	labels, err = repo.GetEventLabels(ctx, first)
+	require.NoError(t, err)
+	assert.Nil(t, labels)
+}
+
+func TestSQLiteRepository_Annotations(t *testing.T) {
+	plain, db := newTestSQLiteRepository(t)
+	repo := newSQLiteRepository(db, clock.New(), zap.NewNop(), false)
+	ctx := context.Background()
+	base := time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+
+	event := "00000000-0000-0000-0000-000000000001"
+	require.NoError(t, plain.CreateEvents(ctx, []domain.AuditEntry{sqliteEntry(event, "user-1", "export", base, "")}))
+
+	annotations, err := repo.ListAnnotations(ctx, event)
+	require.NoError(t, err)
+	assert.Empty(t, annotations)
+
+	finding := domain.EventAnnotation{ID: "6f1b2c3d-4e5f-4a57-9d0e-0b8f5e0c3c1d", EventID: event, SessionID: testSQLiteSession,
+		AuthorID: "admin-1", Body: "Export made from an unrecognized IP", Version: 1,
+		CreatedAt: base.Add(time.Hour), UpdatedAt: base.Add(time.Hour)}
+	reply := domain.EventAnnotation{ID: "0b8f5e0c-3c1d-4a57-9d0e-6f1b2c3d4e5f", EventID: event, SessionID: testSQLiteSession,
+		AuthorID: "user-1", Body: "That was me, travelling", Version: 1,
+		CreatedAt: base.Add(2 * time.Hour), UpdatedAt: base.Add(2 * time.Hour)}
+	require.NoError(t, repo.CreateAnnotation(ctx, reply))
+	require.NoError(t, repo.CreateAnnotation(ctx, finding))
+
+	// The thread is read oldest first
+	annotations, err = repo.ListAnnotations(ctx, event)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.EventAnnotation{finding, reply}, annotations)
+
+	edited := finding
+	edited.Body = "Export made from an unrecognized IP; confirmed with the owner"
+	edited.Version = 2
+	edited.UpdatedAt = base.Add(3 * time.Hour)
+	require.NoError(t, repo.UpdateAnnotation(ctx, edited))
+	got, err := repo.GetAnnotation(ctx, finding.ID)
+	require.NoError(t, err)
+	assert.Equal(t, edited, *got)
+
+	// Edits and deletions made against an older version are refused
+	assert.ErrorIs(t, repo.UpdateAnnotation(ctx, edited), domain.ErrVersionConflict)
+	assert.ErrorIs(t, repo.DeleteAnnotation(ctx, finding.ID, 1), domain.ErrVersionConflict)
+
+	// Annotations of the default organization are not seen from other organizations
+	_, err = repo.GetAnnotation(tenant.NewContext(ctx, "acme"), finding.ID)
+	assert.ErrorIs(t, err, domain.ErrAnnotationNotFound)
+	assert.ErrorIs(t, repo.DeleteAnnotation(tenant.NewContext(ctx, "acme"), finding.ID, 2), domain.ErrVersionConflict)
+
+	require.NoError(t, repo.DeleteAnnotation(ctx, finding.ID, 2))
+	_, err = repo.GetAnnotation(ctx, finding.ID)
+	assert.ErrorIs(t, err, domain.ErrAnnotationNotFound)
+
+	// The annotations of an event are deleted with it
+	_, err = repo.DeleteEvents(ctx, []string{event})
+	require.NoError(t, err)
+	annotations, err = repo.ListAnnotations(ctx, event)
+	require.NoError(t, err)
+	assert.Empty(t, annotations)
+}
+
+func TestSQLiteRepository_SlideHeatmap(t *testing.T) {
+	repo, db := newTestSQLiteRepository(t)
+	ctx := context.Background()
+	hour := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	_, err := db.Exec(`insert into slides (id, session_id, slide_number) values (?, ?, 2), (?, ?, 1)`,
+		"slide-a", testSQLiteSession, "slide-b", testSQLiteSession)
+	require.NoError(t, err)
+
+	slideEvent := func(id, userID, slideID string, at time.Time) domain.AuditEntry {
+		entry := sqliteEntry(id, userID, "edit", at, "")
+		entry.SlideID = slideID
+		return entry
+	}
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		slideEvent("00000000-0000-0000-0000-000000000001", "user-1", "slide-a", hour.Add(5*time.Minute)),
+		slideEvent("00000000-0000-0000-0000-000000000002", "user-2", "slide-a", hour.Add(55*time.Minute)),
+		slideEvent("00000000-0000-0000-0000-000000000003", "user-1", "slide-a", hour.Add(time.Hour)),
+		slideEvent("00000000-0000-0000-0000-000000000004", "user-1", "slide-b", hour),
+		// Deleted slides keep their events but lose their index
+		slideEvent("00000000-0000-0000-0000-000000000005", "user-1", "slide-gone", hour),
+		// Events without a slide and outside the range are not counted
+		sqliteEntry("00000000-0000-0000-0000-000000000006", "user-1", "view", hour, ""),
+		slideEvent("00000000-0000-0000-0000-000000000007", "user-1", "slide-a", hour.Add(-time.Minute)),
+	}))
+
+	query := domain.HeatmapQuery{From: hour, To: hour.Add(2 * time.Hour)}
+	cells, err := repo.GetSlideHeatmap(ctx, testSQLiteSession, query)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.HeatmapCell{
+		{SlideID: "slide-b", SlideIndex: 1, Hour: hour, EventCount: 1},
+		{SlideID: "slide-a", SlideIndex: 2, Hour: hour, EventCount: 2},
+		{SlideID: "slide-a", SlideIndex: 2, Hour: hour.Add(time.Hour), EventCount: 1},
+		{SlideID: "slide-gone", Hour: hour, EventCount: 1},
+	}, cells)
+
+	query.UserID = "user-2"
+	cells, err = repo.GetSlideHeatmap(ctx, testSQLiteSession, query)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.HeatmapCell{{SlideID: "slide-a", SlideIndex: 2, Hour: hour, EventCount: 1}}, cells)
+}
+
+func TestSQLiteRepository_SessionContributors(t *testing.T) {
+	repo, _ := newTestSQLiteRepository(t)
+	ctx := context.Background()
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "edit", day.Add(9*time.Hour), ""),
+		sqliteEntry("00000000-0000-0000-0000-000000000002", "user-1", "edit", day.Add(10*time.Hour), ""),
+		sqliteEntry("00000000-0000-0000-0000-000000000003", "user-1", "comment_created", day.Add(11*time.Hour), ""),
+		sqliteEntry("00000000-0000-0000-0000-000000000004", "user-1", "view", day.Add(12*time.Hour), ""),
+		sqliteEntry("00000000-0000-0000-0000-000000000005", "user-2", "merge", day.Add(8*time.Hour), ""),
+		sqliteEntry("00000000-0000-0000-0000-000000000006", "user-2", "comment", day.Add(13*time.Hour), ""),
+		// Resolving a comment is not a comment of its own
+		sqliteEntry("00000000-0000-0000-0000-000000000007", "user-2", "comment_resolved", day.Add(14*time.Hour), ""),
+	}))
+
+	contributors, err := repo.GetSessionContributors(ctx, testSQLiteSession, domain.ContributorQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ContributorStats{
+		{UserID: "user-1", Edits: 2, Comments: 1, TotalEvents: 4, FirstActivity: day.Add(9 * time.Hour), LastActivity: day.Add(12 * time.Hour)},
+		{UserID: "user-2", Comments: 1, Merges: 1, TotalEvents: 3, FirstActivity: day.Add(8 * time.Hour), LastActivity: day.Add(14 * time.Hour)},
+	}, contributors)
+
+	// Both bounds are inclusive
+	contributors, err = repo.GetSessionContributors(ctx, testSQLiteSession,
+		domain.ContributorQuery{From: day.Add(10 * time.Hour), To: day.Add(13 * time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ContributorStats{
+		{UserID: "user-1", Edits: 1, Comments: 1, TotalEvents: 3, FirstActivity: day.Add(10 * time.Hour), LastActivity: day.Add(12 * time.Hour)},
+		{UserID: "user-2", Comments: 1, TotalEvents: 1, FirstActivity: day.Add(13 * time.Hour), LastActivity: day.Add(13 * time.Hour)},
+	}, contributors)
+}
+
+func TestSQLiteRepository_ActivityRollups(t *testing.T) {
+	_, db := newTestSQLiteRepository(t)
+	repo := newSQLiteRepository(db, clock.New(), zap.NewNop(), false)
+	ctx := context.Background()
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	acme := sqliteEntry("00000000-0000-0000-0000-000000000004", "user-2", "comment", day.Add(10*time.Hour), "")
+	acme.OrganizationID = "acme"
+	require.NoError(t, repo.CreateEvents(ctx, []domain.AuditEntry{
+		sqliteEntry("00000000-0000-0000-0000-000000000001", "user-1", "edit", day.Add(9*time.Hour), ""),
+		sqliteEntry("00000000-0000-0000-0000-000000000002", "user-1", "edit", day.Add(9*time.Hour+30*time.Minute), ""),
+		sqliteEntry("00000000-0000-0000-0000-000000000003", "user-1", "view", day.Add(11*time.Hour), ""),
+		acme,
+		// Outside the rolled-up range
+		sqliteEntry("00000000-0000-0000-0000-000000000005", "user-1", "edit", day.Add(-time.Hour), ""),
+	}))
+
+	written, err := repo.RollupActivity(ctx, day, day.Add(24*time.Hour))
+	require.NoError(t, err)
+	// Two hours of user-1 and one of user-2, plus their days
+	assert.Equal(t, 5, written)
+
+	days, err := repo.GetSessionActivity(ctx, testSQLiteSession, domain.ActivityQuery{
+		Granularity: domain.ActivityDay, From: day.AddDate(0, 0, -1), To: day.AddDate(0, 0, 1),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ActivityBucket{
+		{Start: day, UserID: "user-1", EventCount: 3, ByType: map[string]int{"edit": 2, "view": 1}},
+		{Start: day, UserID: "user-2", EventCount: 1, ByType: map[string]int{"comment": 1}},
+	}, days)
+
+	hours, err := repo.GetSessionActivity(ctx, testSQLiteSession, domain.ActivityQuery{
+		Granularity: domain.ActivityHour, From: day, To: day.AddDate(0, 0, 1), UserID: "user-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ActivityBucket{
+		{Start: day.Add(9 * time.Hour), UserID: "user-1", EventCount: 2, ByType: map[string]int{"edit": 2}},
+		{Start: day.Add(11 * time.Hour), UserID: "user-1", EventCount: 1, ByType: map[string]int{"view": 1}},
+	}, hours)
+
+	// Organizations only see their own rollups
+	scoped, err := repo.GetSessionActivity(tenant.NewContext(ctx, "acme"), testSQLiteSession, domain.ActivityQuery{
+		Granularity: domain.ActivityDay, From: day, To: day.AddDate(0, 0, 1),
+	})
+	require.NoError(t, err)
+	require.Len(t, scoped, 1)
+	assert.Equal(t, "user-2", scoped[0].UserID)
+
+	// Rolling up again replaces the rollups instead of adding to them
+	written, err = repo.RollupActivity(ctx, day, day.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 5, written)
+	days, err = repo.GetSessionActivity(ctx, testSQLiteSession, domain.ActivityQuery{
+		Granularity: domain.ActivityDay, From: day, To: day.AddDate(0, 0, 1), UserID: "user-1",
+	})
+	require.NoError(t, err)
+	require.Len(t, days, 1)
+	assert.Equal(t, 3, days[0].EventCount)
+
+	require.NoError(t, repo.DeleteUserActivity(ctx, "user-1"))
+	days, err = repo.GetSessionActivity(ctx, testSQLiteSession, domain.ActivityQuery{
+		Granularity: domain.ActivityDay, From: day, To: day.AddDate(0, 0, 1),
+	})
+	require.NoError(t, err)
+	require.Len(t, days, 1)
+	assert.Equal(t, "user-2", days[0].UserID)
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"audit-service/internal/config"
+	"audit-service/internal/metrics"
+	"audit-service/pkg/breaker"
+	"audit-service/pkg/clock"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// Storage backends selectable with STORAGE_BACKEND
+const (
+	BackendSupabase = "supabase"
+	BackendPostgres = "postgres"
+	BackendSQLite   = "sqlite"
+	BackendMemory   = "memory"
+)
+
+// ErrMigrationUnsupported is returned when a backend cannot change its own schema
+var ErrMigrationUnsupported = errors.New("storage backend does not support migrations")
+
+// Storage is an audit data backend that manages its own schema
+type Storage interface {
+	AuditRepository
+	OutboxRepository
+	EventTypeRepository
+	LegalHoldRepository
+	RevocationRepository
+	ActivityRepository
+	WatchRepository
+	ReportRepository
+	SavedQueryRepository
+	LabelRepository
+	AnnotationRepository
+	// Migrate applies pending schema migrations and returns their versions
+	Migrate(ctx context.Context) ([]string, error)
+	// Close releases the connections of the backend
+	Close(ctx context.Context) error
+	// SetServiceRoleKey replaces the Supabase service role key after it was rotated; backends
+	// that do not use it ignore it
+	SetServiceRoleKey(key string)
+}
+
+// backendRepository is implemented by the repository of every backend
+type backendRepository interface {
+	AuditRepository
+	OutboxRepository
+	EventTypeRepository
+	LegalHoldRepository
+	RevocationRepository
+	ActivityRepository
+	WatchRepository
+	ReportRepository
+	SavedQueryRepository
+	LabelRepository
+	AnnotationRepository
+}
+
+// storage pairs a repository with the schema and lifecycle operations of its backend
+type storage struct {
+	backendRepository
+	migrate func(ctx context.Context) ([]string, error)
+	close   func() error
+	// setKey is set by the backends that authenticate with the service role key
+	setKey func(key string)
+}
+
+// Migrate applies pending schema migrations and returns their versions
+func (s *storage) Migrate(ctx context.Context) ([]string, error) {
+	return s.migrate(ctx)
+}
+
+// Close releases the connections of the backend
+func (s *storage) Close(ctx context.Context) error {
+	return s.close()
+}
+
+// SetServiceRoleKey replaces the Supabase service role key
+func (s *storage) SetServiceRoleKey(key string) {
+	if s.setKey != nil {
+		s.setKey(key)
+	}
+}
+
+// OpenStorage connects to the backend selected by cfg.StorageBackend.
+// When the outbox is enabled every stored entry is also queued for the relay worker.
+func OpenStorage(ctx context.Context, cfg *config.Config, m *metrics.Metrics, clk clock.Clock, logger *zap.Logger) (Storage, error) {
+	outbox := cfg.OutboxEnabled()
+
+	switch cfg.StorageBackend {
+	case BackendSupabase:
+		rest := NewSupabaseClient(cfg, logger)
+		return &storage{
+			backendRepository: newAuditRepository(newSupabaseStorageClient(cfg, rest, m, clk, logger), logger, outbox),
+			// The REST API cannot run DDL; migrate through the postgres backend instead
+			migrate: func(ctx context.Context) ([]string, error) {
+				return nil, fmt.Errorf("%w: run migrate with STORAGE_BACKEND=postgres and DATABASE_URL set to the Supabase database", ErrMigrationUnsupported)
+			},
+			close:  func() error { return nil },
+			setKey: rest.SetServiceRoleKey,
+		}, nil
+
+	case BackendPostgres:
+		pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DATABASE_URL: %w", err)
+		}
+		if err := pool.Ping(ctx); err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to connect to Postgres: %w", err)
+		}
+		return &storage{
+			backendRepository: newPostgresRepository(pool, logger, outbox),
+			migrate: func(ctx context.Context) ([]string, error) {
+				return MigratePostgres(ctx, pool, clk)
+			},
+			close: func() error {
+				pool.Close()
+				return nil
+			},
+		}, nil
+
+	case BackendSQLite, BackendMemory:
+		// The memory backend is a SQLite database that lives as long as the process
+		path := cfg.SQLitePath
+		if cfg.StorageBackend == BackendMemory {
+			path = ":memory:"
+		}
+		db, err := OpenSQLite(ctx, path, clk, logger)
+		if err != nil {
+			return nil, err
+		}
+		return &storage{
+			backendRepository: newSQLiteRepository(db, clk, logger, outbox),
+			migrate: func(ctx context.Context) ([]string, error) {
+				return MigrateSQLite(ctx, db, clk)
+			},
+			close: db.Close,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown storage backend %q", cfg.StorageBackend)
+}
+
+// newSupabaseStorageClient builds the REST client of the supabase backend: every attempt is
+// measured, and attempts go through a circuit breaker so an outage fails fast
+func newSupabaseStorageClient(cfg *config.Config, rest *SupabaseClient, m *metrics.Metrics, clk clock.Clock, logger *zap.Logger) SupabaseClientInterface {
+	cb := breaker.New(breaker.Config{
+		FailureThreshold: cfg.SupabaseBreakerFailureThreshold,
+		OpenTimeout:      cfg.SupabaseBreakerOpenTimeout,
+		HalfOpenRequests: cfg.SupabaseBreakerHalfOpenRequests,
+	}, clk, func(from, to breaker.State) {
+		m.ObserveCircuitBreakerTransition(to)
+		logger.Warn("supabase circuit breaker changed state",
+			zap.Stringer("from", from),
+			zap.Stringer("to", to),
+		)
+	})
+	m.RegisterCircuitBreaker(cb.State)
+
+	return NewResilientClient(NewInstrumentedClient(rest, m), cb, RetryPolicy{
+		MaxAttempts: cfg.SupabaseRetryMaxAttempts,
+		BaseDelay:   cfg.SupabaseRetryBaseDelay,
+		MaxDelay:    cfg.SupabaseRetryMaxDelay,
+	}, m, logger)
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"audit-service/internal/config"
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestOpenStorage_Memory(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenStorage(ctx, &config.Config{StorageBackend: BackendMemory}, metrics.New(), clock.New(), zap.NewNop())
+	require.NoError(t, err)
+	defer store.Close(ctx)
+
+	// The schema is created when the backend is opened
+	applied, err := store.Migrate(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	require.NoError(t, store.CreateEvents(ctx, []domain.AuditEntry{{
+		ID:        "00000000-0000-0000-0000-000000000001",
+		SessionID: testSQLiteSession,
+		UserID:    "user-1",
+		Type:      "edit",
+		Timestamp: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+	}}))
+
+	_, total, err := store.FindBySessionID(ctx, testSQLiteSession, domain.PaginationParams{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+}
+
+func TestOpenStorage_SupabaseCannotMigrate(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenStorage(ctx, &config.Config{
+		StorageBackend:         BackendSupabase,
+		SupabaseURL:            "http://localhost:54321",
+		SupabaseServiceRoleKey: "service-role-key",
+	}, metrics.New(), clock.New(), zap.NewNop())
+	require.NoError(t, err)
+
+	_, err = store.Migrate(ctx)
+	assert.ErrorIs(t, err, ErrMigrationUnsupported)
+	assert.NoError(t, store.Close(ctx))
+}
+
+func TestOpenStorage_UnknownBackend(t *testing.T) {
+	_, err := OpenStorage(context.Background(), &config.Config{StorageBackend: "mysql"}, metrics.New(), clock.New(), zap.NewNop())
+	assert.Error(t, err)
+}
+
+// fakeTracker records migrations in memory
+type fakeTracker struct {
+	done    map[string]bool
+	failOn  string
+	scripts []string
+}
+
+func (f *fakeTracker) applied(ctx context.Context, version string) (bool, error) {
+	return f.done[version], nil
+}
+
+func (f *fakeTracker) apply(ctx context.Context, version, script string) error {
+	if version == f.failOn {
+		return errors.New("syntax error")
+	}
+	f.done[version] = true
+	f.scripts = append(f.scripts, script)
+	return nil
+}
+
+func TestRunMigrations(t *testing.T) {
+	files := fstest.MapFS{
+		"002_second.sql": {Data: []byte("second")},
+		"001_first.sql":  {Data: []byte("first")},
+		"003_third.sql":  {Data: []byte("third")},
+		"README.md":      {Data: []byte("not a migration")},
+	}
+
+	t.Run("applies pending migrations in order", func(t *testing.T) {
+		tracker := &fakeTracker{done: map[string]bool{"002_second": true}}
+
+		applied, err := runMigrations(context.Background(), files, tracker)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"001_first", "003_third"}, applied)
+		assert.Equal(t, []string{"first", "third"}, tracker.scripts)
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		tracker := &fakeTracker{done: map[string]bool{}, failOn: "002_second"}
+
+		applied, err := runMigrations(context.Background(), files, tracker)
+		assert.ErrorContains(t, err, "002_second")
+		assert.Equal(t, []string{"001_first"}, applied)
+	})
+}
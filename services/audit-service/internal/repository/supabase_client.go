@@ -4,19 +4,22 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
 
 	"audit-service/internal/config"
+	"audit-service/pkg/requestid"
 
 	"go.uber.org/zap"
 )
 
 // SupabaseClientInterface defines the interface for Supabase client operations
 type SupabaseClientInterface interface {
-	Get(ctx context.Context, endpoint string, queryParams map[string]string) ([]byte, int, error)
+	Get(ctx context.Context, endpoint string, query *Query) ([]byte, int, error)
 	Post(ctx context.Context, endpoint string, payload interface{}) ([]byte, error)
 }
 
@@ -24,8 +27,11 @@ type SupabaseClientInterface interface {
 type SupabaseClient struct {
 	baseURL    string
 	httpClient *http.Client
-	headers    map[string]string
 	logger     *zap.Logger
+
+	// headersMu guards headers, which change when the service role key is rotated
+	headersMu sync.RWMutex
+	headers   map[string]string
 }
 
 // NewSupabaseClient creates a new Supabase REST API client
@@ -57,10 +63,11 @@ type SupabaseResponse struct {
 
 // SupabaseError represents an error from Supabase
 type SupabaseError struct {
-	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
-	Hint    string `json:"hint,omitempty"`
-	Code    string `json:"code,omitempty"`
+	Message    string `json:"message"`
+	Details    string `json:"details,omitempty"`
+	Hint       string `json:"hint,omitempty"`
+	Code       string `json:"code,omitempty"`
+	StatusCode int    `json:"-"`
 }
 
 // Error implements the error interface
@@ -68,10 +75,39 @@ func (e *SupabaseError) Error() string {
 	return e.Message
 }
 
-// Get performs a GET request to Supabase
-func (c *SupabaseClient) Get(ctx context.Context, endpoint string, queryParams map[string]string) ([]byte, int, error) {
+// newSupabaseError builds an error from a failed Supabase response
+func newSupabaseError(status int, body []byte) *SupabaseError {
+	var supErr SupabaseError
+	if err := json.Unmarshal(body, &supErr); err != nil || supErr.Message == "" {
+		supErr = SupabaseError{
+			Message: fmt.Sprintf("request failed with status %d: %s", status, string(body)),
+		}
+	}
+	supErr.StatusCode = status
+	return &supErr
+}
+
+// IsRetryable reports whether a failed Supabase call may succeed if repeated
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var supErr *SupabaseError
+	if errors.As(err, &supErr) {
+		return supErr.StatusCode >= 500 || supErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	// Transport level failures (connection refused, timeouts, resets)
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// Get performs a GET request to Supabase, returning the rows and, when the query asks for a
+// count, the total number of matching rows. A nil query reads the endpoint as is.
+func (c *SupabaseClient) Get(ctx context.Context, endpoint string, query *Query) ([]byte, int, error) {
 	// Build URL with query parameters
-	fullURL, err := c.buildURL(endpoint, queryParams)
+	fullURL, err := c.buildURL(endpoint, query.Params())
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to build URL: %w", err)
 	}
@@ -83,12 +119,14 @@ func (c *SupabaseClient) Get(ctx context.Context, endpoint string, queryParams m
 	}
 
 	// Add headers
-	for key, value := range c.headers {
+	c.setHeaders(req)
+	for key, value := range query.Headers() {
 		req.Header.Set(key, value)
 	}
 
 	// Log request
 	c.logger.Debug("making supabase request",
+		requestid.Field(ctx),
 		zap.String("method", "GET"),
 		zap.String("url", fullURL),
 	)
@@ -108,28 +146,18 @@ func (c *SupabaseClient) Get(ctx context.Context, endpoint string, queryParams m
 
 	// Log response
 	c.logger.Debug("supabase response",
+		requestid.Field(ctx),
 		zap.Int("status", resp.StatusCode),
 		zap.Int("body_size", len(body)),
 	)
 
 	// Check for errors
 	if resp.StatusCode >= 400 {
-		var supErr SupabaseError
-		if err := json.Unmarshal(body, &supErr); err == nil && supErr.Message != "" {
-			return nil, resp.StatusCode, &supErr
-		}
-		return nil, resp.StatusCode, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, resp.StatusCode, newSupabaseError(resp.StatusCode, body)
 	}
 
 	// Extract count from headers if available
-	count := 0
-	if contentRange := resp.Header.Get("Content-Range"); contentRange != "" {
-		// Parse count from Content-Range header (e.g., "0-9/100")
-		var rangeStart, rangeEnd int
-		fmt.Sscanf(contentRange, "%d-%d/%d", &rangeStart, &rangeEnd, &count)
-	}
-
-	return body, count, nil
+	return body, parseContentRange(resp.Header.Get("Content-Range")), nil
 }
 
 // Post performs a POST request to Supabase
@@ -150,9 +178,7 @@ func (c *SupabaseClient) Post(ctx context.Context, endpoint string, payload inte
 	}
 
 	// Add headers
-	for key, value := range c.headers {
-		req.Header.Set(key, value)
-	}
+	c.setHeaders(req)
 
 	// Execute request
 	resp, err := c.httpClient.Do(req)
@@ -169,16 +195,33 @@ func (c *SupabaseClient) Post(ctx context.Context, endpoint string, payload inte
 
 	// Check for errors
 	if resp.StatusCode >= 400 {
-		var supErr SupabaseError
-		if err := json.Unmarshal(body, &supErr); err == nil && supErr.Message != "" {
-			return nil, &supErr
-		}
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newSupabaseError(resp.StatusCode, body)
 	}
 
 	return body, nil
 }
 
+// setHeaders adds the client headers and forwards the request ID, so Supabase logs can be
+// matched with the request that caused them
+func (c *SupabaseClient) setHeaders(req *http.Request) {
+	c.headersMu.RLock()
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+	c.headersMu.RUnlock()
+	if requestID := requestid.FromContext(req.Context()); requestID != "" {
+		req.Header.Set(requestid.Header, requestID)
+	}
+}
+
+// SetServiceRoleKey replaces the key requests authenticate with, taking effect with the next request
+func (c *SupabaseClient) SetServiceRoleKey(key string) {
+	c.headersMu.Lock()
+	defer c.headersMu.Unlock()
+	c.headers["apikey"] = key
+	c.headers["Authorization"] = "Bearer " + key
+}
+
 // buildURL constructs the full URL with query parameters
 func (c *SupabaseClient) buildURL(endpoint string, queryParams map[string]string) (string, error) {
 	baseURL := fmt.Sprintf("%s%s", c.baseURL, endpoint)
@@ -3,12 +3,16 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	"audit-service/internal/config"
+	"audit-service/pkg/requestid"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
@@ -18,7 +22,7 @@ func TestSupabaseClient_Get(t *testing.T) {
 	tests := []struct {
 		name          string
 		endpoint      string
-		queryParams   map[string]string
+		query         *Query
 		setupServer   func() *httptest.Server
 		expectedData  []byte
 		expectedCount int
@@ -27,16 +31,14 @@ func TestSupabaseClient_Get(t *testing.T) {
 		{
 			name:     "success_simple_get",
 			endpoint: "/audit_logs",
-			queryParams: map[string]string{
-				"session_id": "eq.test-session",
-				"limit":      "10",
-			},
+			query:    NewQuery().Where(Eq("session_id", "test-session")).Limit(10).Count(CountExact),
 			setupServer: func() *httptest.Server {
 				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 					// Verify request
 					assert.Equal(t, "/rest/v1/audit_logs", r.URL.Path)
 					assert.Equal(t, "eq.test-session", r.URL.Query().Get("session_id"))
 					assert.Equal(t, "10", r.URL.Query().Get("limit"))
+					assert.Equal(t, "count=exact", r.Header.Get("Prefer"))
 					assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
 					assert.Equal(t, "test-key", r.Header.Get("apikey"))
 
@@ -58,13 +60,14 @@ func TestSupabaseClient_Get(t *testing.T) {
 			expectedError: "",
 		},
 		{
-			name:        "success_no_params",
-			endpoint:    "/sessions",
-			queryParams: nil,
+			name:     "success_no_params",
+			endpoint: "/sessions",
+			query:    nil,
 			setupServer: func() *httptest.Server {
 				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 					assert.Equal(t, "/rest/v1/sessions", r.URL.Path)
 					assert.Empty(t, r.URL.RawQuery)
+					assert.Empty(t, r.Header.Get("Prefer"))
 
 					data := []map[string]interface{}{
 						{"id": "session-1", "user_id": "user-1"},
@@ -83,9 +86,7 @@ func TestSupabaseClient_Get(t *testing.T) {
 		{
 			name:     "success_empty_result",
 			endpoint: "/audit_logs",
-			queryParams: map[string]string{
-				"session_id": "eq.non-existent",
-			},
+			query:    NewQuery().Where(Eq("session_id", "non-existent")).Count(CountExact),
 			setupServer: func() *httptest.Server {
 				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 					w.Header().Set("Content-Range", "*/0")
@@ -101,9 +102,7 @@ func TestSupabaseClient_Get(t *testing.T) {
 		{
 			name:     "error_400_bad_request",
 			endpoint: "/audit_logs",
-			queryParams: map[string]string{
-				"invalid": "eq.bad-param",
-			},
+			query:    NewQuery().Where(Eq("invalid", "bad-param")),
 			setupServer: func() *httptest.Server {
 				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 					supErr := SupabaseError{
@@ -123,9 +122,9 @@ func TestSupabaseClient_Get(t *testing.T) {
 			expectedError: "Invalid query parameter",
 		},
 		{
-			name:        "error_401_unauthorized",
-			endpoint:    "/audit_logs",
-			queryParams: nil,
+			name:     "error_401_unauthorized",
+			endpoint: "/audit_logs",
+			query:    nil,
 			setupServer: func() *httptest.Server {
 				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 					w.WriteHeader(http.StatusUnauthorized)
@@ -137,9 +136,9 @@ func TestSupabaseClient_Get(t *testing.T) {
 			expectedError: "request failed with status 401",
 		},
 		{
-			name:        "error_500_server_error",
-			endpoint:    "/audit_logs",
-			queryParams: nil,
+			name:     "error_500_server_error",
+			endpoint: "/audit_logs",
+			query:    nil,
 			setupServer: func() *httptest.Server {
 				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 					supErr := SupabaseError{
@@ -179,7 +178,7 @@ func TestSupabaseClient_Get(t *testing.T) {
 			client := NewSupabaseClient(cfg, logger)
 
 			// Execute
-			data, count, err := client.Get(context.Background(), tt.endpoint, tt.queryParams)
+			data, count, err := client.Get(context.Background(), tt.endpoint, tt.query)
 
 			// Assert
 			if tt.expectedError != "" {
@@ -379,6 +378,98 @@ func TestSupabaseClient_buildURL(t *testing.T) {
 	}
 }
 
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil error", err: nil, expected: false},
+		{name: "server error", err: &SupabaseError{Message: "boom", StatusCode: 500}, expected: true},
+		{name: "rate limited", err: &SupabaseError{Message: "slow down", StatusCode: 429}, expected: true},
+		{name: "client error", err: &SupabaseError{Message: "bad", StatusCode: 400}, expected: false},
+		{name: "wrapped server error", err: fmt.Errorf("insert: %w", &SupabaseError{StatusCode: 503}), expected: true},
+		{name: "transport error", err: fmt.Errorf("request failed: %w", &url.Error{Op: "Post", URL: "http://x", Err: errors.New("connection refused")}), expected: true},
+		{name: "context canceled", err: fmt.Errorf("request failed: %w", context.Canceled), expected: false},
+		{name: "plain error", err: errors.New("marshal failed"), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsRetryable(tt.err))
+		})
+	}
+}
+
+func TestSupabaseClient_Post_StatusCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("upstream down"))
+	}))
+	defer server.Close()
+
+	client := NewSupabaseClient(&config.Config{
+		SupabaseURL:            server.URL,
+		SupabaseServiceRoleKey: "test-key",
+		HTTPTimeout:            10 * time.Second,
+	}, zap.NewNop())
+
+	_, err := client.Post(context.Background(), "/audit_logs", map[string]string{})
+
+	var supErr *SupabaseError
+	assert.True(t, errors.As(err, &supErr))
+	assert.Equal(t, http.StatusServiceUnavailable, supErr.StatusCode)
+	assert.Contains(t, err.Error(), "request failed with status 503")
+}
+
+func TestSupabaseClient_ForwardsRequestID(t *testing.T) {
+	var forwarded []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = append(forwarded, r.Header.Get(requestid.Header))
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	client := NewSupabaseClient(&config.Config{
+		SupabaseURL:            server.URL,
+		SupabaseServiceRoleKey: "test-key",
+		HTTPTimeout:            10 * time.Second,
+	}, zap.NewNop())
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	_, _, err := client.Get(ctx, "/audit_logs", nil)
+	assert.NoError(t, err)
+	_, err = client.Post(ctx, "/audit_logs", map[string]string{})
+	assert.NoError(t, err)
+	_, err = client.Post(context.Background(), "/audit_logs", map[string]string{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"req-1", "req-1", ""}, forwarded)
+}
+
+func TestSupabaseClient_SetServiceRoleKey(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("apikey")+" "+r.Header.Get("Authorization"))
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	client := NewSupabaseClient(&config.Config{
+		SupabaseURL:            server.URL,
+		SupabaseServiceRoleKey: "old-key",
+		HTTPTimeout:            10 * time.Second,
+	}, zap.NewNop())
+
+	_, _, err := client.Get(context.Background(), "/audit_logs", nil)
+	assert.NoError(t, err)
+	client.SetServiceRoleKey("new-key")
+	_, _, err = client.Get(context.Background(), "/audit_logs", nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"old-key Bearer old-key", "new-key Bearer new-key"}, keys)
+}
+
 func TestSupabaseError_Error(t *testing.T) {
 	err := &SupabaseError{
 		Message: "Test error message",
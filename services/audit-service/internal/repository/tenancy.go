@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/tenant"
+)
+
+// checkOrganization rejects entries of organizations other than the one ctx is scoped to
+func checkOrganization(ctx context.Context, entries []domain.AuditEntry) error {
+	for _, entry := range entries {
+		if !tenant.Allows(ctx, entry.OrganizationID) {
+			return fmt.Errorf("%w: event %s belongs to another organization", domain.ErrForbidden, entry.ID)
+		}
+	}
+	return nil
+}
+
+// organizationArg returns the organization argument of the database functions: nil for unscoped
+// contexts, which match every organization, and the empty string for the default organization
+func organizationArg(ctx context.Context) interface{} {
+	organizationID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return organizationID
+}
+
+// applyOrganization restricts a PostgREST query to the organization ctx is scoped to
+func applyOrganization(ctx context.Context, query *Query) {
+	organizationID, ok := tenant.FromContext(ctx)
+	switch {
+	case !ok:
+	case organizationID == "":
+		// Entries of the default organization store no organization
+		query.Where(IsNull("organization_id"))
+	default:
+		query.Where(Eq("organization_id", organizationID))
+	}
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"audit-service/internal/domain"
+)
+
+// WatchRepository stores the watches that subscribe users to the events of a session. Watches
+// belong to their user, so they are looked up by user and session and not by organization.
+type WatchRepository interface {
+	// PutWatch creates the watch, or replaces the event types of an existing watch of the user
+	// on the session, and returns the stored watch
+	PutWatch(ctx context.Context, watch domain.Watch) (*domain.Watch, error)
+	// GetWatch returns the watch of a user on a session, or domain.ErrWatchNotFound
+	GetWatch(ctx context.Context, sessionID, userID string) (*domain.Watch, error)
+	// DeleteWatch removes the watch of a user on a session, or returns domain.ErrWatchNotFound
+	DeleteWatch(ctx context.Context, sessionID, userID string) error
+	// ListUserWatches returns the watches of a user, newest first
+	ListUserWatches(ctx context.Context, userID string) ([]domain.Watch, error)
+	// ListSessionWatches returns the watches on a session
+	ListSessionWatches(ctx context.Context, sessionID string) ([]domain.Watch, error)
+}
+
+// watchRow represents an audit_session_watches row
+type watchRow struct {
+	SessionID  string    `json:"session_id"`
+	UserID     string    `json:"user_id"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+	// Omitted, and stored as null, for watches of the default organization
+	OrganizationID string `json:"organization_id,omitempty"`
+}
+
+// newWatchRow converts a domain watch into a database row
+func newWatchRow(watch domain.Watch) watchRow {
+	return watchRow{
+		SessionID:  watch.SessionID,
+		UserID:     watch.UserID,
+		EventTypes: watch.EventTypes,
+		CreatedAt:  watch.CreatedAt.UTC(),
+
+		OrganizationID: watch.OrganizationID,
+	}
+}
+
+// toWatch converts a database row into a domain watch
+func (row watchRow) toWatch() domain.Watch {
+	watch := domain.Watch{
+		SessionID:  row.SessionID,
+		UserID:     row.UserID,
+		EventTypes: row.EventTypes,
+		CreatedAt:  row.CreatedAt.UTC(),
+
+		OrganizationID: row.OrganizationID,
+	}
+	if watch.EventTypes == nil {
+		watch.EventTypes = []string{}
+	}
+	return watch
+}
@@ -0,0 +1,286 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/pkg/clock"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// SystemUserID is the user recorded on events written by the retention job
+const SystemUserID = "system"
+
+// Repository deletes expired audit events
+type Repository interface {
+	PurgeEvents(ctx context.Context, eventType string, before time.Time, limit int) ([]domain.PurgedEvents, error)
+	FindExpiredEvents(ctx context.Context, eventType string, before time.Time, limit int) ([]domain.AuditEntry, error)
+	DeleteEvents(ctx context.Context, ids []string) ([]domain.PurgedEvents, error)
+}
+
+// Archiver copies expired events to long-term storage before they are deleted
+type Archiver interface {
+	Archive(ctx context.Context, eventType string, entries []domain.AuditEntry) error
+}
+
+// RecordFunc persists the purge summary events
+type RecordFunc func(ctx context.Context, entries []domain.AuditEntry) error
+
+// Config controls how often and in which batch sizes expired events are purged
+type Config struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+// sessionKey identifies a session within its organization
+type sessionKey struct {
+	sessionID      string
+	organizationID string
+}
+
+// PurgeSummary describes the events of one type removed from a session
+type PurgeSummary struct {
+	EventType string    `json:"eventType"`
+	Deleted   int       `json:"deleted"`
+	Before    time.Time `json:"before"`
+	Retention string    `json:"retention"`
+	Archived  bool      `json:"archived,omitempty"`
+}
+
+// Purger periodically deletes audit events older than the retention period of their type
+type Purger struct {
+	repo     Repository
+	archiver Archiver
+	record   RecordFunc
+	policies map[string]time.Duration
+	types    []string
+	cfg      Config
+	clock    clock.Clock
+	metrics  *metrics.Metrics
+	logger   *zap.Logger
+
+	// runMu serializes scheduled purges and purges requested by operators
+	runMu sync.Mutex
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// New creates a purger for the given retention period per event type; call Start to schedule it.
+// When archiver is nil expired events are deleted without being archived.
+func New(repo Repository, archiver Archiver, record RecordFunc, policies map[string]time.Duration, cfg Config, clk clock.Clock, m *metrics.Metrics, logger *zap.Logger) *Purger {
+	types := make([]string, 0, len(policies))
+	for eventType := range policies {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+
+	return &Purger{
+		repo:     repo,
+		archiver: archiver,
+		record:   record,
+		policies: policies,
+		types:    types,
+		cfg:      cfg,
+		clock:    clk,
+		metrics:  m,
+		logger:   logger,
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// Start runs a purge immediately and then once per interval
+func (p *Purger) Start() {
+	go p.run()
+}
+
+// Close stops the schedule and waits for a running purge to be cancelled
+func (p *Purger) Close(ctx context.Context) error {
+	p.closeOnce.Do(func() { close(p.stop) })
+
+	select {
+	case <-p.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("retention purge did not stop: %w", ctx.Err())
+	}
+}
+
+// run purges on the configured interval until Close is called
+func (p *Purger) run() {
+	defer close(p.stopped)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-p.stop
+		cancel()
+	}()
+
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Run(ctx); err != nil && ctx.Err() == nil {
+			p.logger.Error("retention purge failed", zap.Error(err))
+		}
+
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run deletes expired events of every policy and records a summary event per affected session.
+// A run waits for one already in progress to finish.
+func (p *Purger) Run(ctx context.Context) error {
+	p.runMu.Lock()
+	defer p.runMu.Unlock()
+
+	now := p.clock.Now()
+
+	var errs []error
+	var summaries []domain.AuditEntry
+	for _, eventType := range p.types {
+		retention := p.policies[eventType]
+		cutoff := now.Add(-retention)
+
+		perSession, err := p.purgeType(ctx, eventType, cutoff)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", eventType, err))
+		}
+
+		// Summaries are recorded for whatever was deleted, even when a later batch failed
+		for _, key := range sortedKeys(perSession) {
+			details, _ := domain.EncodeDetails(PurgeSummary{
+				EventType: eventType,
+				Deleted:   perSession[key],
+				Before:    cutoff,
+				Retention: retention.String(),
+				Archived:  p.archiver != nil,
+			})
+			summaries = append(summaries, domain.AuditEntry{
+				ID:        uuid.New().String(),
+				SessionID: key.sessionID,
+				UserID:    SystemUserID,
+				Type:      string(domain.ActionRetentionPurge),
+				Timestamp: now,
+				Details:   details,
+				// Recorded in the organization of the purged events
+				OrganizationID: key.organizationID,
+			})
+		}
+	}
+
+	if len(summaries) > 0 {
+		if err := p.record(ctx, summaries); err != nil {
+			errs = append(errs, fmt.Errorf("failed to record purge summary: %w", err))
+		}
+	}
+
+	err := errors.Join(errs...)
+	p.metrics.ObserveRetentionRun(err)
+	return err
+}
+
+// purgeType deletes expired events of one type in batches and returns the deleted count per session
+func (p *Purger) purgeType(ctx context.Context, eventType string, cutoff time.Time) (map[sessionKey]int, error) {
+	perSession := map[sessionKey]int{}
+	total := 0
+	defer func() {
+		if total > 0 {
+			p.metrics.ObserveRetentionPurge(eventType, total)
+			p.logger.Info("purged expired audit events",
+				zap.String("type", eventType),
+				zap.Time("before", cutoff),
+				zap.Int("count", total),
+				zap.Int("sessions", len(perSession)),
+			)
+		}
+	}()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return perSession, err
+		}
+
+		purged, found, err := p.purgeBatch(ctx, eventType, cutoff)
+		if err != nil {
+			return perSession, err
+		}
+
+		for _, item := range purged {
+			perSession[sessionKey{item.SessionID, item.OrganizationID}] += item.Deleted
+			total += item.Deleted
+		}
+
+		// A short batch means nothing expired is left
+		if found < p.cfg.BatchSize {
+			return perSession, nil
+		}
+	}
+}
+
+// purgeBatch deletes one batch of expired events, archiving them first when an archiver is set.
+// It returns the deleted counts and the number of expired events the batch covered.
+func (p *Purger) purgeBatch(ctx context.Context, eventType string, cutoff time.Time) ([]domain.PurgedEvents, int, error) {
+	if p.archiver == nil {
+		purged, err := p.repo.PurgeEvents(ctx, eventType, cutoff, p.cfg.BatchSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		deleted := 0
+		for _, item := range purged {
+			deleted += item.Deleted
+		}
+		return purged, deleted, nil
+	}
+
+	entries, err := p.repo.FindExpiredEvents(ctx, eventType, cutoff, p.cfg.BatchSize)
+	if err != nil || len(entries) == 0 {
+		return nil, 0, err
+	}
+
+	// Events are only deleted once their archive has been written
+	if err := p.archiver.Archive(ctx, eventType, entries); err != nil {
+		return nil, 0, err
+	}
+	p.metrics.ObserveRetentionArchive(eventType, len(entries))
+
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+	purged, err := p.repo.DeleteEvents(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	return purged, len(entries), nil
+}
+
+// sortedKeys returns the sessions in a stable order
+func sortedKeys(counts map[sessionKey]int) []sessionKey {
+	keys := make([]sessionKey, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].sessionID != keys[j].sessionID {
+			return keys[i].sessionID < keys[j].sessionID
+		}
+		return keys[i].organizationID < keys[j].organizationID
+	})
+	return keys
+}
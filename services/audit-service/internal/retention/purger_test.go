@@ -0,0 +1,192 @@
+package retention
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/mocks"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// recorder collects the summary events written by the purger
+type recorder struct {
+	entries []domain.AuditEntry
+	err     error
+}
+
+func (r *recorder) record(ctx context.Context, entries []domain.AuditEntry) error {
+	r.entries = append(r.entries, entries...)
+	return r.err
+}
+
+func newTestPurger(repo Repository, rec *recorder, policies map[string]time.Duration) *Purger {
+	return New(repo, nil, rec.record, policies, Config{Interval: time.Hour, BatchSize: 2},
+		clock.NewFakeClock(testNow), metrics.New(), zap.NewNop())
+}
+
+func TestPurger_Run(t *testing.T) {
+	repo := mocks.NewMockAuditRepository(t)
+	rec := &recorder{}
+	purger := newTestPurger(repo, rec, map[string]time.Duration{
+		"view": 30 * 24 * time.Hour,
+		"edit": 730 * 24 * time.Hour,
+	})
+
+	viewCutoff := testNow.Add(-30 * 24 * time.Hour)
+	editCutoff := testNow.Add(-730 * 24 * time.Hour)
+
+	// Full batches are repeated until a short one shows nothing expired is left
+	repo.On("PurgeEvents", mock.Anything, "view", viewCutoff, 2).
+		Return([]domain.PurgedEvents{{SessionID: "session-b", Deleted: 1}, {SessionID: "session-a", Deleted: 1}}, nil).Once()
+	repo.On("PurgeEvents", mock.Anything, "view", viewCutoff, 2).
+		Return([]domain.PurgedEvents{{SessionID: "session-a", Deleted: 1}}, nil).Once()
+	repo.On("PurgeEvents", mock.Anything, "edit", editCutoff, 2).
+		Return([]domain.PurgedEvents{}, nil).Once()
+
+	require.NoError(t, purger.Run(context.Background()))
+
+	require.Len(t, rec.entries, 2)
+	assert.Equal(t, "session-a", rec.entries[0].SessionID)
+	assert.Equal(t, "session-b", rec.entries[1].SessionID)
+
+	summary := rec.entries[0]
+	assert.Equal(t, string(domain.ActionRetentionPurge), summary.Type)
+	assert.Equal(t, SystemUserID, summary.UserID)
+	assert.Equal(t, testNow, summary.Timestamp)
+	assert.NotEmpty(t, summary.ID)
+
+	var details PurgeSummary
+	require.NoError(t, json.Unmarshal(summary.Details, &details))
+	assert.Equal(t, PurgeSummary{EventType: "view", Deleted: 2, Before: viewCutoff, Retention: "720h0m0s"}, details)
+}
+
+func TestPurger_RunNothingExpired(t *testing.T) {
+	repo := mocks.NewMockAuditRepository(t)
+	rec := &recorder{}
+	purger := newTestPurger(repo, rec, map[string]time.Duration{"view": time.Hour})
+
+	repo.On("PurgeEvents", mock.Anything, "view", mock.Anything, 2).Return(nil, nil).Once()
+
+	require.NoError(t, purger.Run(context.Background()))
+	assert.Empty(t, rec.entries)
+}
+
+func TestPurger_RunContinuesAfterFailure(t *testing.T) {
+	repo := mocks.NewMockAuditRepository(t)
+	rec := &recorder{}
+	purger := newTestPurger(repo, rec, map[string]time.Duration{
+		"edit": 48 * time.Hour,
+		"view": time.Hour,
+	})
+
+	repo.On("PurgeEvents", mock.Anything, "edit", mock.Anything, 2).
+		Return([]domain.PurgedEvents{{SessionID: "session-a", Deleted: 2}}, nil).Once()
+	repo.On("PurgeEvents", mock.Anything, "edit", mock.Anything, 2).
+		Return(nil, errors.New("network error")).Once()
+	repo.On("PurgeEvents", mock.Anything, "view", mock.Anything, 2).
+		Return([]domain.PurgedEvents{{SessionID: "session-b", Deleted: 1}}, nil).Once()
+
+	err := purger.Run(context.Background())
+
+	assert.ErrorContains(t, err, "edit: network error")
+	// Events deleted before the failure are still summarized
+	require.Len(t, rec.entries, 2)
+	assert.Equal(t, "session-a", rec.entries[0].SessionID)
+	assert.Equal(t, "session-b", rec.entries[1].SessionID)
+}
+
+func TestPurger_StartAndClose(t *testing.T) {
+	repo := mocks.NewMockAuditRepository(t)
+	rec := &recorder{}
+	purger := newTestPurger(repo, rec, map[string]time.Duration{"view": time.Hour})
+
+	ran := make(chan struct{})
+	repo.On("PurgeEvents", mock.Anything, "view", mock.Anything, 2).
+		Run(func(mock.Arguments) { close(ran) }).
+		Return(nil, nil).Once()
+
+	purger.Start()
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("purge did not run on start")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, purger.Close(ctx))
+	assert.NoError(t, purger.Close(ctx))
+}
+
+// fakeArchiver records archived batches
+type fakeArchiver struct {
+	batches [][]domain.AuditEntry
+	err     error
+}
+
+func (a *fakeArchiver) Archive(ctx context.Context, eventType string, entries []domain.AuditEntry) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.batches = append(a.batches, entries)
+	return nil
+}
+
+func TestPurger_RunArchivesBeforeDeleting(t *testing.T) {
+	repo := mocks.NewMockAuditRepository(t)
+	archiver := &fakeArchiver{}
+	rec := &recorder{}
+	purger := New(repo, archiver, rec.record, map[string]time.Duration{"view": time.Hour},
+		Config{Interval: time.Hour, BatchSize: 2}, clock.NewFakeClock(testNow), metrics.New(), zap.NewNop())
+
+	cutoff := testNow.Add(-time.Hour)
+	first := []domain.AuditEntry{{ID: "audit-1", SessionID: "session-a"}, {ID: "audit-2", SessionID: "session-a"}}
+	second := []domain.AuditEntry{{ID: "audit-3", SessionID: "session-b"}}
+
+	repo.On("FindExpiredEvents", mock.Anything, "view", cutoff, 2).Return(first, nil).Once()
+	repo.On("DeleteEvents", mock.Anything, []string{"audit-1", "audit-2"}).
+		Return([]domain.PurgedEvents{{SessionID: "session-a", Deleted: 2}}, nil).Once()
+	repo.On("FindExpiredEvents", mock.Anything, "view", cutoff, 2).Return(second, nil).Once()
+	repo.On("DeleteEvents", mock.Anything, []string{"audit-3"}).
+		Return([]domain.PurgedEvents{{SessionID: "session-b", Deleted: 1}}, nil).Once()
+
+	require.NoError(t, purger.Run(context.Background()))
+
+	assert.Equal(t, [][]domain.AuditEntry{first, second}, archiver.batches)
+	require.Len(t, rec.entries, 2)
+
+	var details PurgeSummary
+	require.NoError(t, json.Unmarshal(rec.entries[0].Details, &details))
+	assert.Equal(t, 2, details.Deleted)
+	assert.True(t, details.Archived)
+}
+
+func TestPurger_RunKeepsEventsWhenArchiveFails(t *testing.T) {
+	repo := mocks.NewMockAuditRepository(t)
+	archiver := &fakeArchiver{err: errors.New("bucket unavailable")}
+	rec := &recorder{}
+	purger := New(repo, archiver, rec.record, map[string]time.Duration{"view": time.Hour},
+		Config{Interval: time.Hour, BatchSize: 2}, clock.NewFakeClock(testNow), metrics.New(), zap.NewNop())
+
+	repo.On("FindExpiredEvents", mock.Anything, "view", mock.Anything, 2).
+		Return([]domain.AuditEntry{{ID: "audit-1", SessionID: "session-a"}}, nil).Once()
+
+	err := purger.Run(context.Background())
+
+	assert.ErrorContains(t, err, "view: bucket unavailable")
+	repo.AssertNotCalled(t, "DeleteEvents", mock.Anything, mock.Anything)
+	assert.Empty(t, rec.entries)
+}
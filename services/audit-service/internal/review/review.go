@@ -0,0 +1,128 @@
+// Package review validates the translation review workflow events of slides and shapes. A slide,
+// or a shape reviewed on its own, is submitted for review, then approved or rejected; rejected
+// translations are submitted again and approved ones may be reopened. The state of a target is
+// given by its latest stored review event, so events breaking the workflow, or older than the
+// latest review event of their target, are rejected before they are stored.
+package review
+
+import (
+	"context"
+
+	"audit-service/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// lookupPageSize is the number of review events of a slide read at once while looking for the
+// latest event of one of its targets
+const lookupPageSize = 50
+
+// Store queries the stored events
+type Store interface {
+	QueryEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, page domain.PaginationParams) ([]domain.AuditEntry, int, error)
+}
+
+// Workflow checks review events against the review state of their slide or shape. Check of a nil
+// Workflow accepts every event.
+type Workflow struct {
+	store  Store
+	logger *zap.Logger
+}
+
+// New creates a workflow reading the review state from store
+func New(store Store, logger *zap.Logger) *Workflow {
+	return &Workflow{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// targetKey identifies a review target within a session
+type targetKey struct {
+	sessionID string
+	target    domain.ReviewTarget
+}
+
+// targetState is the review state of a target and the time of its latest review event
+type targetState struct {
+	state  domain.ReviewState
+	latest *domain.AuditEntry
+}
+
+// Check validates the review events among entries, in order, so a batch may move a target
+// through several states. It returns the index of the first rejected entry with a
+// *domain.ReviewTransitionError, or -1 and nil when all entries are accepted. Events of test
+// sessions and events outside the review workflow are not checked.
+func (w *Workflow) Check(ctx context.Context, entries []domain.AuditEntry) (int, error) {
+	if w == nil {
+		return -1, nil
+	}
+
+	states := map[targetKey]targetState{}
+	for i, entry := range entries {
+		if !domain.IsReviewAction(entry.Type) || domain.SessionID(entry.SessionID).IsTestSession() {
+			continue
+		}
+
+		key := targetKey{
+			sessionID: entry.SessionID,
+			target:    domain.ReviewTarget{SlideID: entry.SlideID, ShapeID: entry.ShapeID},
+		}
+		current, ok := states[key]
+		if !ok {
+			latest, err := w.latest(ctx, key)
+			if err != nil {
+				return i, err
+			}
+			current = targetState{state: domain.ReviewDraft, latest: latest}
+			if latest != nil {
+				current.state = domain.ReviewStateAfter(domain.AuditAction(latest.Type))
+			}
+		}
+
+		action := domain.AuditAction(entry.Type)
+		if current.latest != nil && entry.Timestamp.Before(current.latest.Timestamp) {
+			return i, &domain.ReviewTransitionError{Target: key.target, State: current.state, Action: action, OutOfOrder: true}
+		}
+		next, err := domain.NextReviewState(key.target, current.state, action)
+		if err != nil {
+			return i, err
+		}
+		states[key] = targetState{state: next, latest: &entries[i]}
+	}
+	return -1, nil
+}
+
+// latest returns the latest stored review event of a target, nil when there is none. Events of a
+// whole slide are told apart from those of its shapes by their empty shape ID.
+func (w *Workflow) latest(ctx context.Context, key targetKey) (*domain.AuditEntry, error) {
+	types := make([]string, len(domain.ReviewActions))
+	for i, action := range domain.ReviewActions {
+		types[i] = string(action)
+	}
+	filter := domain.EventFilter{Types: types, SlideID: key.target.SlideID, ShapeID: key.target.ShapeID}
+
+	page := domain.PaginationParams{Limit: lookupPageSize}
+	for {
+		entries, total, err := w.store.QueryEvents(ctx, domain.SessionID(key.sessionID), filter, page)
+		if err != nil {
+			w.logger.Error("failed to read review state",
+				zap.String("session_id", key.sessionID),
+				zap.String("slide_id", key.target.SlideID),
+				zap.String("shape_id", key.target.ShapeID),
+				zap.Error(err),
+			)
+			return nil, err
+		}
+		// Entries are returned newest first
+		for i := range entries {
+			if entries[i].ShapeID == key.target.ShapeID {
+				return &entries[i], nil
+			}
+		}
+		page.Offset += len(entries)
+		if len(entries) == 0 || page.Offset >= total {
+			return nil, nil
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+// memoryStore returns its events newest first, filtered like the storage backends: an empty
+// shape ID filter matches the events of the slide and of all its shapes
+type memoryStore struct {
+	entries []domain.AuditEntry
+	queries int
+	err     error
+}
+
+func (s *memoryStore) QueryEvents(_ context.Context, sessionID domain.SessionID, filter domain.EventFilter, page domain.PaginationParams) ([]domain.AuditEntry, int, error) {
+	s.queries++
+	if s.err != nil {
+		return nil, 0, s.err
+	}
+	var matched []domain.AuditEntry
+	for _, entry := range s.entries {
+		if entry.SessionID != string(sessionID) || !slices.Contains(filter.Types, entry.Type) || entry.SlideID != filter.SlideID {
+			continue
+		}
+		if filter.ShapeID != "" && entry.ShapeID != filter.ShapeID {
+			continue
+		}
+		matched = append(matched, entry)
+	}
+	slices.SortFunc(matched, func(a, b domain.AuditEntry) int { return b.Timestamp.Compare(a.Timestamp) })
+
+	start := min(page.Offset, len(matched))
+	end := min(start+page.Limit, len(matched))
+	return matched[start:end], len(matched), nil
+}
+
+func reviewEvent(action domain.AuditAction, slideID, shapeID string, minutes int) domain.AuditEntry {
+	return domain.AuditEntry{
+		SessionID: "session-1",
+		UserID:    "reviewer-1",
+		Type:      string(action),
+		Timestamp: testNow.Add(time.Duration(minutes) * time.Minute),
+		SlideID:   slideID,
+		ShapeID:   shapeID,
+	}
+}
+
+func TestWorkflow_AcceptsWorkflowFromStoredState(t *testing.T) {
+	store := &memoryStore{entries: []domain.AuditEntry{
+		reviewEvent(domain.ActionSubmittedForReview, "slide-1", "", 0),
+		reviewEvent(domain.ActionRejected, "slide-1", "", 5),
+	}}
+	workflow := New(store, zap.NewNop())
+
+	// A rejected slide is submitted again and approved within one batch
+	index, err := workflow.Check(context.Background(), []domain.AuditEntry{
+		reviewEvent(domain.ActionSubmittedForReview, "slide-1", "", 10),
+		{SessionID: "session-1", Type: string(domain.ActionEdit), Timestamp: testNow.Add(11 * time.Minute)},
+		reviewEvent(domain.ActionApproved, "slide-1", "", 12),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, -1, index)
+	// The stored state is read once per target
+	assert.Equal(t, 1, store.queries)
+}
+
+func TestWorkflow_RejectsIllegalTransition(t *testing.T) {
+	store := &memoryStore{entries: []domain.AuditEntry{
+		reviewEvent(domain.ActionSubmittedForReview, "slide-1", "", 0),
+	}}
+	workflow := New(store, zap.NewNop())
+
+	index, err := workflow.Check(context.Background(), []domain.AuditEntry{
+		reviewEvent(domain.ActionApproved, "slide-1", "", 5),
+		reviewEvent(domain.ActionReopened, "slide-1", "", 6),
+		reviewEvent(domain.ActionRejected, "slide-1", "", 7),
+	})
+	assert.Equal(t, 2, index)
+	var transitionErr *domain.ReviewTransitionError
+	require.ErrorAs(t, err, &transitionErr)
+	assert.Equal(t, domain.ReviewReopened, transitionErr.State)
+	assert.Equal(t, domain.ActionRejected, transitionErr.Action)
+	assert.False(t, transitionErr.OutOfOrder)
+}
+
+func TestWorkflow_RejectsOutOfOrderEvents(t *testing.T) {
+	store := &memoryStore{entries: []domain.AuditEntry{
+		reviewEvent(domain.ActionSubmittedForReview, "slide-1", "", 10),
+	}}
+	workflow := New(store, zap.NewNop())
+
+	index, err := workflow.Check(context.Background(), []domain.AuditEntry{
+		reviewEvent(domain.ActionApproved, "slide-1", "", 5),
+	})
+	assert.Equal(t, 0, index)
+	var transitionErr *domain.ReviewTransitionError
+	require.ErrorAs(t, err, &transitionErr)
+	assert.True(t, transitionErr.OutOfOrder)
+}
+
+func TestWorkflow_ReviewsShapesApartFromSlides(t *testing.T) {
+	store := &memoryStore{entries: []domain.AuditEntry{
+		reviewEvent(domain.ActionSubmittedForReview, "slide-1", "", 0),
+		reviewEvent(domain.ActionApproved, "slide-1", "", 1),
+		// The latest review event of the slide is older than those of its shape
+		reviewEvent(domain.ActionSubmittedForReview, "slide-1", "shape-1", 2),
+	}}
+	workflow := New(store, zap.NewNop())
+
+	index, err := workflow.Check(context.Background(), []domain.AuditEntry{
+		reviewEvent(domain.ActionReopened, "slide-1", "", 3),
+		reviewEvent(domain.ActionRejected, "slide-1", "shape-1", 3),
+		reviewEvent(domain.ActionSubmittedForReview, "slide-2", "shape-1", 3),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, -1, index)
+}
+
+func TestWorkflow_PagesThroughShapeEvents(t *testing.T) {
+	store := &memoryStore{entries: []domain.AuditEntry{
+		reviewEvent(domain.ActionSubmittedForReview, "slide-1", "", 0),
+	}}
+	for i := range lookupPageSize + 5 {
+		store.entries = append(store.entries, reviewEvent(domain.ActionSubmittedForReview, "slide-1", fmt.Sprintf("shape-%d", i), 1))
+	}
+	workflow := New(store, zap.NewNop())
+
+	_, err := workflow.Check(context.Background(), []domain.AuditEntry{
+		reviewEvent(domain.ActionApproved, "slide-1", "", 2),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, store.queries)
+}
+
+func TestWorkflow_SkipsTestSessionsAndNil(t *testing.T) {
+	store := &memoryStore{}
+	entry := reviewEvent(domain.ActionApproved, "slide-1", "", 0)
+	entry.SessionID = domain.TestSessionPrefix + "session"
+
+	index, err := New(store, zap.NewNop()).Check(context.Background(), []domain.AuditEntry{entry})
+	require.NoError(t, err)
+	assert.Equal(t, -1, index)
+	assert.Zero(t, store.queries)
+
+	var workflow *Workflow
+	index, err = workflow.Check(context.Background(), []domain.AuditEntry{reviewEvent(domain.ActionApproved, "slide-1", "", 0)})
+	require.NoError(t, err)
+	assert.Equal(t, -1, index)
+}
+
+func TestWorkflow_StoreFailure(t *testing.T) {
+	storeErr := errors.New("connection refused")
+	workflow := New(&memoryStore{err: storeErr}, zap.NewNop())
+
+	index, err := workflow.Check(context.Background(), []domain.AuditEntry{
+		{SessionID: "session-1", Type: string(domain.ActionEdit)},
+		reviewEvent(domain.ActionSubmittedForReview, "slide-1", "", 0),
+	})
+	assert.ErrorIs(t, err, storeErr)
+	assert.Equal(t, 1, index)
+}
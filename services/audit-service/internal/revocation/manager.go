@@ -0,0 +1,268 @@
+// Package revocation rejects access tokens before they expire. Revocations are stored in the
+// audit_token_revocations table and mirrored as markers in the token cache, which the auth
+// middleware checks on every request. With the Redis cache backend the markers are shared, so a
+// revocation takes effect on every instance at once; otherwise each instance picks it up with
+// its next refresh.
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/cache"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/requestid"
+	"audit-service/pkg/tenant"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Repository stores the revocations
+type Repository interface {
+	CreateRevocation(ctx context.Context, revocation domain.Revocation) error
+	ListRevocations(ctx context.Context, notLiftedOnly bool) ([]domain.Revocation, error)
+	LiftRevocation(ctx context.Context, id, liftedBy string, at time.Time) (*domain.Revocation, error)
+}
+
+// marker identifies the token cache marker of a revocation
+type marker struct {
+	kind           domain.RevocationKind
+	organizationID string
+	target         string
+}
+
+func markerOf(r domain.Revocation) marker {
+	return marker{kind: r.Kind, organizationID: r.OrganizationID, target: r.Target}
+}
+
+// Manager adds, lifts and lists revocations and keeps the token cache markers in sync with them
+type Manager struct {
+	repo            Repository
+	tokens          *cache.TokenCache
+	refreshInterval time.Duration
+	clock           clock.Clock
+	logger          *zap.Logger
+
+	// mu serializes marker updates, so a refresh cannot restore the marker of a revocation
+	// lifted while it ran
+	mu sync.Mutex
+	// marked are the markers set by the last refresh, cleared when their revocation is gone
+	marked map[marker]struct{}
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// NewManager creates a revocation manager; call Refresh to load the stored revocations and Start
+// to keep the markers in sync with the table
+func NewManager(repo Repository, tokens *cache.TokenCache, refreshInterval time.Duration, clk clock.Clock, logger *zap.Logger) *Manager {
+	return &Manager{
+		repo:            repo,
+		tokens:          tokens,
+		refreshInterval: refreshInterval,
+		clock:           clk,
+		logger:          logger,
+		marked:          make(map[marker]struct{}),
+		stop:            make(chan struct{}),
+		stopped:         make(chan struct{}),
+	}
+}
+
+// Start refreshes the markers every refresh interval until Close
+func (m *Manager) Start() {
+	go func() {
+		defer close(m.stopped)
+		if m.refreshInterval <= 0 {
+			<-m.stop
+			return
+		}
+
+		ticker := time.NewTicker(m.refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				if err := m.Refresh(context.Background()); err != nil {
+					// Markers outlive two failed refreshes, so revocations stay in force meanwhile
+					m.logger.Warn("failed to refresh token revocations", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Close stops the periodic refresh
+func (m *Manager) Close(ctx context.Context) error {
+	m.closeOnce.Do(func() { close(m.stop) })
+
+	select {
+	case <-m.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("revocation refresh did not stop: %w", ctx.Err())
+	}
+}
+
+// Refresh sets the markers of every active revocation and clears those of revocations lifted
+// since the last refresh, also by other instances. ctx must not be scoped to an organization.
+func (m *Manager) Refresh(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	revocations, err := m.repo.ListRevocations(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	now := m.clock.Now()
+	active := make(map[marker]struct{}, len(revocations))
+	for _, r := range revocations {
+		if !r.Active(now) {
+			continue
+		}
+		m.mark(r, now)
+		active[markerOf(r)] = struct{}{}
+	}
+	for k := range m.marked {
+		if _, ok := active[k]; !ok {
+			m.tokens.ClearRevoked(string(k.kind), k.organizationID, k.target)
+		}
+	}
+	m.marked = active
+	return nil
+}
+
+// Revoke validates and stores a revocation in the organization ctx is scoped to; tokens it
+// covers are rejected from the next request on
+func (m *Manager) Revoke(ctx context.Context, kind domain.RevocationKind, target, reason, revokedBy string, expiresAt *time.Time) (domain.Revocation, error) {
+	organizationID, _ := tenant.FromContext(ctx)
+	revocation := domain.Revocation{
+		ID:        uuid.New().String(),
+		Kind:      kind,
+		Target:    strings.TrimSpace(target),
+		Reason:    strings.TrimSpace(reason),
+		RevokedBy: revokedBy,
+		RevokedAt: m.clock.Now(),
+		ExpiresAt: expiresAt,
+
+		OrganizationID: organizationID,
+	}
+	// Session IDs are stored in canonical form so they match the session_id claim
+	if kind == domain.RevocationSession {
+		if parsed, err := uuid.Parse(revocation.Target); err == nil {
+			revocation.Target = parsed.String()
+		}
+	}
+
+	if err := revocation.Validate(); err != nil {
+		return domain.Revocation{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.repo.CreateRevocation(ctx, revocation); err != nil {
+		return domain.Revocation{}, err
+	}
+	m.mark(revocation, revocation.RevokedAt)
+
+	m.logger.Info("tokens revoked",
+		requestid.Field(ctx),
+		zap.String("revocation_id", revocation.ID),
+		zap.String("kind", string(revocation.Kind)),
+		zap.String("target", revocation.Target),
+		zap.String("revoked_by", revokedBy),
+		tenant.Field(ctx),
+	)
+	return revocation, nil
+}
+
+// Lift ends a revocation; the tokens it covered are accepted again unless another revocation
+// covers them
+func (m *Manager) Lift(ctx context.Context, id, liftedBy string) (domain.Revocation, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.Revocation{}, domain.ErrRevocationNotFound
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	revocation, err := m.repo.LiftRevocation(ctx, id, liftedBy, m.clock.Now())
+	if err != nil {
+		return domain.Revocation{}, err
+	}
+	if err := m.unmark(ctx, *revocation); err != nil {
+		// The marker is cleared by the next refresh
+		m.logger.Warn("failed to clear revocation marker",
+			requestid.Field(ctx),
+			zap.String("revocation_id", revocation.ID),
+			zap.Error(err),
+		)
+	}
+
+	m.logger.Info("token revocation lifted",
+		requestid.Field(ctx),
+		zap.String("revocation_id", revocation.ID),
+		zap.String("kind", string(revocation.Kind)),
+		zap.String("target", revocation.Target),
+		zap.String("lifted_by", liftedBy),
+	)
+	return *revocation, nil
+}
+
+// List returns the revocations newest first, only the ones still in force when activeOnly is set
+func (m *Manager) List(ctx context.Context, activeOnly bool) ([]domain.Revocation, error) {
+	revocations, err := m.repo.ListRevocations(ctx, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.clock.Now()
+	out := make([]domain.Revocation, 0, len(revocations))
+	for _, r := range revocations {
+		if !activeOnly || r.Active(now) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// mark sets the marker of an active revocation. Markers expire with their revocation, and
+// otherwise after two refresh intervals so markers of revocations lifted while no instance
+// ran do not linger in a shared cache.
+func (m *Manager) mark(r domain.Revocation, now time.Time) {
+	var ttl time.Duration
+	if m.refreshInterval > 0 {
+		ttl = 2 * m.refreshInterval
+	}
+	if r.ExpiresAt != nil {
+		if until := r.ExpiresAt.Sub(now); ttl == 0 || until < ttl {
+			ttl = until
+		}
+	}
+	m.tokens.SetRevoked(string(r.Kind), r.OrganizationID, r.Target, ttl)
+}
+
+// unmark clears the marker of a lifted revocation unless another active revocation of the
+// organization covers the same target
+func (m *Manager) unmark(ctx context.Context, lifted domain.Revocation) error {
+	revocations, err := m.repo.ListRevocations(ctx, true)
+	if err != nil {
+		return err
+	}
+	now := m.clock.Now()
+	for _, r := range revocations {
+		if r.ID != lifted.ID && markerOf(r) == markerOf(lifted) && r.Active(now) {
+			return nil
+		}
+	}
+	m.tokens.ClearRevoked(string(lifted.Kind), lifted.OrganizationID, lifted.Target)
+	delete(m.marked, markerOf(lifted))
+	return nil
+}
@@ -0,0 +1,198 @@
+package revocation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/cache"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+
+// memoryRepository keeps revocations in memory like the audit_token_revocations table
+type memoryRepository struct {
+	revocations []domain.Revocation
+	err         error
+}
+
+func (r *memoryRepository) CreateRevocation(_ context.Context, revocation domain.Revocation) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.revocations = append(r.revocations, revocation)
+	return nil
+}
+
+func (r *memoryRepository) ListRevocations(ctx context.Context, notLiftedOnly bool) ([]domain.Revocation, error) {
+	var revocations []domain.Revocation
+	for i := len(r.revocations) - 1; i >= 0; i-- {
+		rev := r.revocations[i]
+		if (!notLiftedOnly || rev.LiftedAt == nil) && tenant.Allows(ctx, rev.OrganizationID) {
+			revocations = append(revocations, rev)
+		}
+	}
+	return revocations, r.err
+}
+
+func (r *memoryRepository) LiftRevocation(_ context.Context, id, liftedBy string, at time.Time) (*domain.Revocation, error) {
+	for i := range r.revocations {
+		if r.revocations[i].ID != id {
+			continue
+		}
+		if r.revocations[i].LiftedAt != nil {
+			return nil, domain.ErrRevocationLifted
+		}
+		r.revocations[i].LiftedBy = liftedBy
+		r.revocations[i].LiftedAt = &at
+		revocation := r.revocations[i]
+		return &revocation, nil
+	}
+	return nil, domain.ErrRevocationNotFound
+}
+
+func newTestManager(repo *memoryRepository, clk clock.Clock) (*Manager, *cache.TokenCache) {
+	tokens := cache.NewTokenCache(5*time.Minute, time.Minute, time.Minute, clk)
+	return NewManager(repo, tokens, 30*time.Second, clk, zap.NewNop()), tokens
+}
+
+func TestManager_Revoke(t *testing.T) {
+	tests := []struct {
+		name           string
+		kind           domain.RevocationKind
+		target         string
+		expectedTarget string
+		expectedErr    error
+	}{
+		{name: "user", kind: domain.RevocationUser, target: " user-2 ", expectedTarget: "user-2"},
+		{
+			name: "session", kind: domain.RevocationSession, target: "550E8400-E29B-41D4-A716-446655440010",
+			expectedTarget: "550e8400-e29b-41d4-a716-446655440010",
+		},
+		{name: "token", kind: domain.RevocationToken, target: "jti-1", expectedTarget: "jti-1"},
+		{name: "unknown kind", kind: "device", target: "device-1", expectedErr: domain.ErrInvalidRevocation},
+		{name: "session target not a UUID", kind: domain.RevocationSession, target: "session-1", expectedErr: domain.ErrInvalidRevocation},
+		{name: "missing target", kind: domain.RevocationUser, target: " ", expectedErr: domain.ErrInvalidRevocation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryRepository{}
+			manager, tokens := newTestManager(repo, clock.NewFakeClock(testNow))
+			ctx := tenant.NewContext(context.Background(), "acme")
+
+			revocation, err := manager.Revoke(ctx, tt.kind, tt.target, "Account compromised", "admin-1", nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, repo.revocations)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTarget, revocation.Target)
+			assert.Equal(t, "acme", revocation.OrganizationID)
+			assert.Equal(t, testNow, revocation.RevokedAt)
+			assert.Equal(t, []domain.Revocation{revocation}, repo.revocations)
+
+			// The marker rejects tokens of the organization right away
+			info := &cache.CachedTokenInfo{OrganizationID: "acme"}
+			switch tt.kind {
+			case domain.RevocationUser:
+				info.UserID = tt.expectedTarget
+			case domain.RevocationSession:
+				info.AuthSessionID = tt.expectedTarget
+			case domain.RevocationToken:
+				info.TokenID = tt.expectedTarget
+			}
+			assert.True(t, tokens.IsRevoked(info))
+			info.OrganizationID = ""
+			assert.False(t, tokens.IsRevoked(info))
+		})
+	}
+}
+
+func TestManager_RevokeRejectsPastExpiry(t *testing.T) {
+	manager, _ := newTestManager(&memoryRepository{}, clock.NewFakeClock(testNow))
+	expiresAt := testNow.Add(-time.Minute)
+
+	_, err := manager.Revoke(context.Background(), domain.RevocationUser, "user-2", "Banned", "admin-1", &expiresAt)
+	assert.ErrorIs(t, err, domain.ErrInvalidRevocation)
+}
+
+func TestManager_Lift(t *testing.T) {
+	repo := &memoryRepository{}
+	manager, tokens := newTestManager(repo, clock.NewFakeClock(testNow))
+	ctx := context.Background()
+	info := &cache.CachedTokenInfo{UserID: "user-2"}
+
+	first, err := manager.Revoke(ctx, domain.RevocationUser, "user-2", "Banned", "admin-1", nil)
+	require.NoError(t, err)
+	second, err := manager.Revoke(ctx, domain.RevocationUser, "user-2", "Still banned", "admin-1", nil)
+	require.NoError(t, err)
+
+	// Another revocation of the user keeps the marker
+	lifted, err := manager.Lift(ctx, first.ID, "admin-2")
+	require.NoError(t, err)
+	assert.Equal(t, "admin-2", lifted.LiftedBy)
+	assert.True(t, tokens.IsRevoked(info))
+
+	_, err = manager.Lift(ctx, second.ID, "admin-2")
+	require.NoError(t, err)
+	assert.False(t, tokens.IsRevoked(info))
+
+	_, err = manager.Lift(ctx, second.ID, "admin-2")
+	assert.ErrorIs(t, err, domain.ErrRevocationLifted)
+	_, err = manager.Lift(ctx, "not-a-uuid", "admin-2")
+	assert.ErrorIs(t, err, domain.ErrRevocationNotFound)
+}
+
+func TestManager_Refresh(t *testing.T) {
+	fakeClock := clock.NewFakeClock(testNow)
+	repo := &memoryRepository{}
+	manager, tokens := newTestManager(repo, fakeClock)
+	ctx := context.Background()
+	expiresAt := testNow.Add(time.Hour)
+
+	// Revocations stored by another instance are picked up by the refresh
+	repo.revocations = []domain.Revocation{
+		{ID: "r1", Kind: domain.RevocationUser, Target: "user-1", Reason: "Banned", RevokedAt: testNow, OrganizationID: "acme"},
+		{ID: "r2", Kind: domain.RevocationToken, Target: "jti-1", Reason: "Leaked", RevokedAt: testNow, ExpiresAt: &expiresAt},
+	}
+	banned := &cache.CachedTokenInfo{UserID: "user-1", OrganizationID: "acme"}
+	leaked := &cache.CachedTokenInfo{UserID: "user-2", TokenID: "jti-1"}
+	assert.False(t, tokens.IsRevoked(banned))
+
+	require.NoError(t, manager.Refresh(ctx))
+	assert.True(t, tokens.IsRevoked(banned))
+	assert.True(t, tokens.IsRevoked(leaked))
+
+	// Revocations lifted by another instance are cleared
+	_, err := repo.LiftRevocation(ctx, "r1", "admin-2", testNow)
+	require.NoError(t, err)
+	require.NoError(t, manager.Refresh(ctx))
+	assert.False(t, tokens.IsRevoked(banned))
+	assert.True(t, tokens.IsRevoked(leaked))
+
+	// Expired revocations are neither marked nor listed as active
+	fakeClock.Advance(2 * time.Hour)
+	require.NoError(t, manager.Refresh(ctx))
+	active, err := manager.List(ctx, true)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+	all, err := manager.List(ctx, false)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+}
+
+func TestManager_Close(t *testing.T) {
+	manager, _ := newTestManager(&memoryRepository{}, clock.New())
+	manager.Start()
+	require.NoError(t, manager.Close(context.Background()))
+	require.NoError(t, manager.Close(context.Background()))
+}
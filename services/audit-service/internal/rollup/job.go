@@ -0,0 +1,155 @@
+// Package rollup maintains the activity rollups, the hourly and daily event counts per session and
+// user served by GET /sessions/:sessionId/activity. Every run rebuilds the rollups of the last days
+// from the raw events, so late writes and deleted events are reflected once they fall into a run.
+package rollup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+	"audit-service/pkg/clock"
+
+	"go.uber.org/zap"
+)
+
+// Repository rebuilds the rollups of a time range from the events recorded in it
+type Repository interface {
+	RollupActivity(ctx context.Context, from, to time.Time) (int, error)
+}
+
+// Config controls how often and how far back the rollups are rebuilt
+type Config struct {
+	Interval time.Duration
+	// Lookback is how far back each scheduled run rebuilds the rollups, rounded down to the start
+	// of a UTC day
+	Lookback time.Duration
+}
+
+// Job periodically rebuilds the activity rollups of the lookback window
+type Job struct {
+	repo    Repository
+	cfg     Config
+	clock   clock.Clock
+	metrics *metrics.Metrics
+	logger  *zap.Logger
+
+	// runMu serializes scheduled runs and runs requested by operators
+	runMu sync.Mutex
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// New creates a rollup job; call Start to schedule it
+func New(repo Repository, cfg Config, clk clock.Clock, m *metrics.Metrics, logger *zap.Logger) *Job {
+	return &Job{
+		repo:    repo,
+		cfg:     cfg,
+		clock:   clk,
+		metrics: m,
+		logger:  logger,
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Start runs the rollup immediately and then once per interval
+func (j *Job) Start() {
+	go j.run()
+}
+
+// Close stops the schedule and waits for a running rollup to be cancelled
+func (j *Job) Close(ctx context.Context) error {
+	j.closeOnce.Do(func() { close(j.stop) })
+
+	select {
+	case <-j.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("activity rollup did not stop: %w", ctx.Err())
+	}
+}
+
+// run rebuilds the rollups on the configured interval until Close is called
+func (j *Job) run() {
+	defer close(j.stopped)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-j.stop
+		cancel()
+	}()
+
+	ticker := time.NewTicker(j.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.Run(ctx); err != nil && ctx.Err() == nil {
+			j.logger.Error("activity rollup failed", zap.Error(err))
+		}
+
+		select {
+		case <-j.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run rebuilds the rollups of the lookback window
+func (j *Job) Run(ctx context.Context) error {
+	_, err := j.RunFrom(ctx, time.Time{})
+	return err
+}
+
+// RunFrom rebuilds the rollups from the start of the UTC day of from, or of the lookback window
+// when from is zero, until now. Each day is a separate storage call so backfills of long
+// histories run in short transactions. It returns the number of rollups written; a run waits
+// for one already in progress to finish.
+func (j *Job) RunFrom(ctx context.Context, from time.Time) (int, error) {
+	j.runMu.Lock()
+	defer j.runMu.Unlock()
+
+	now := j.clock.Now()
+	if from.IsZero() {
+		from = now.Add(-j.cfg.Lookback)
+	}
+	start := domain.ActivityDay.Truncate(from)
+
+	written := 0
+	var err error
+	for dayStart := start; dayStart.Before(now); dayStart = dayStart.Add(24 * time.Hour) {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+
+		dayEnd := dayStart.Add(24 * time.Hour)
+		if dayEnd.After(now) {
+			dayEnd = now
+		}
+		var n int
+		if n, err = j.repo.RollupActivity(ctx, dayStart, dayEnd); err != nil {
+			err = fmt.Errorf("failed to roll up %s: %w", dayStart.Format(time.DateOnly), err)
+			break
+		}
+		written += n
+	}
+
+	j.metrics.ObserveActivityRollup(written, err)
+	if err != nil {
+		return written, err
+	}
+
+	j.logger.Info("activity rolled up",
+		zap.Time("from", start),
+		zap.Time("to", now),
+		zap.Int("rollups", written),
+	)
+	return written, nil
+}
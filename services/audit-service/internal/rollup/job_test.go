@@ -0,0 +1,87 @@
+package rollup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"audit-service/internal/metrics"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2024, 6, 3, 10, 30, 0, 0, time.UTC)
+
+// recordingRepository records the ranges rolled up
+type recordingRepository struct {
+	ranges [][2]time.Time
+	failAt int
+}
+
+func (r *recordingRepository) RollupActivity(_ context.Context, from, to time.Time) (int, error) {
+	r.ranges = append(r.ranges, [2]time.Time{from, to})
+	if r.failAt > 0 && len(r.ranges) == r.failAt {
+		return 0, errors.New("unavailable")
+	}
+	return 3, nil
+}
+
+func newTestJob(repo Repository) *Job {
+	return New(repo, Config{Interval: time.Minute, Lookback: 48 * time.Hour},
+		clock.NewFakeClock(testNow), metrics.New(), zap.NewNop())
+}
+
+func TestJob_Run(t *testing.T) {
+	repo := &recordingRepository{}
+
+	require.NoError(t, newTestJob(repo).Run(context.Background()))
+
+	// The lookback starts at the beginning of its day and ends now
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 0, 0, 0, 0, time.UTC) }
+	assert.Equal(t, [][2]time.Time{
+		{day(1), day(2)},
+		{day(2), day(3)},
+		{day(3), testNow},
+	}, repo.ranges)
+}
+
+func TestJob_RunFrom(t *testing.T) {
+	t.Run("backfills day by day", func(t *testing.T) {
+		repo := &recordingRepository{}
+
+		written, err := newTestJob(repo).RunFrom(context.Background(), testNow.AddDate(0, 0, -9))
+		require.NoError(t, err)
+		assert.Equal(t, 30, written)
+		assert.Len(t, repo.ranges, 10)
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		repo := &recordingRepository{failAt: 2}
+
+		written, err := newTestJob(repo).RunFrom(context.Background(), testNow.AddDate(0, 0, -9))
+		assert.ErrorContains(t, err, "2024-05-26")
+		assert.Equal(t, 3, written)
+		assert.Len(t, repo.ranges, 2)
+	})
+
+	t.Run("nothing to roll up from the future", func(t *testing.T) {
+		repo := &recordingRepository{}
+
+		written, err := newTestJob(repo).RunFrom(context.Background(), testNow.AddDate(0, 0, 2))
+		require.NoError(t, err)
+		assert.Zero(t, written)
+		assert.Empty(t, repo.ranges)
+	})
+}
+
+func TestJob_Close(t *testing.T) {
+	job := New(&recordingRepository{}, Config{Interval: time.Minute, Lookback: time.Hour},
+		clock.New(), metrics.New(), zap.NewNop())
+	job.Start()
+	require.NoError(t, job.Close(context.Background()))
+	require.NoError(t, job.Close(context.Background()))
+}
@@ -0,0 +1,215 @@
+// Package savedquery keeps the named filter sets users save to query audit events again, such
+// as "My edits this week" or "All exports". Queries are private to their creator or shared with
+// the organization; only the creator may change or delete them. Running a saved query applies
+// its filters to the events of a session.
+package savedquery
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/requestid"
+	"audit-service/pkg/tenant"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Repository stores the saved queries
+type Repository interface {
+	CreateSavedQuery(ctx context.Context, query domain.SavedQuery) error
+	GetSavedQuery(ctx context.Context, id string) (*domain.SavedQuery, error)
+	ListSavedQueries(ctx context.Context, userID string) ([]domain.SavedQuery, error)
+	UpdateSavedQuery(ctx context.Context, query domain.SavedQuery) error
+	DeleteSavedQuery(ctx context.Context, id string) error
+}
+
+// EventReader queries the events of a session the user may read
+type EventReader interface {
+	QueryEvents(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, filter domain.EventFilter, pagination domain.PaginationParams) (*domain.AuditResponse, error)
+}
+
+// Service manages the saved queries of the users and runs them. Share tokens are not tied to an
+// account that could own queries, so they cannot use saved queries.
+type Service struct {
+	repo   Repository
+	events EventReader
+	clock  clock.Clock
+	logger *zap.Logger
+}
+
+// New creates the saved query service
+func New(repo Repository, events EventReader, clk clock.Clock, logger *zap.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		events: events,
+		clock:  clk,
+		logger: logger,
+	}
+}
+
+// Create validates and stores a saved query of the organization ctx is scoped to, created by
+// query.CreatedBy
+func (s *Service) Create(ctx context.Context, query domain.SavedQuery) (domain.SavedQuery, error) {
+	organizationID, _ := tenant.FromContext(ctx)
+	now := s.clock.Now().UTC()
+	query.ID = uuid.New().String()
+	query.CreatedAt = now
+	query.UpdatedAt = now
+	query.OrganizationID = organizationID
+	query = normalize(query)
+
+	if err := query.Validate(); err != nil {
+		return domain.SavedQuery{}, err
+	}
+	if err := s.repo.CreateSavedQuery(ctx, query); err != nil {
+		return domain.SavedQuery{}, err
+	}
+
+	s.logger.Info("saved query created",
+		requestid.Field(ctx),
+		zap.String("query_id", query.ID),
+		zap.String("scope", string(query.Scope)),
+		zap.String("created_by", query.CreatedBy),
+		tenant.Field(ctx),
+	)
+	return query, nil
+}
+
+// Get returns a saved query the user can see
+func (s *Service) Get(ctx context.Context, id, userID string) (domain.SavedQuery, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.SavedQuery{}, domain.ErrSavedQueryNotFound
+	}
+	query, err := s.repo.GetSavedQuery(ctx, id)
+	if err != nil {
+		return domain.SavedQuery{}, err
+	}
+	// Private queries of other users are not disclosed
+	if !query.VisibleTo(userID) {
+		return domain.SavedQuery{}, domain.ErrSavedQueryNotFound
+	}
+	return *query, nil
+}
+
+// List returns the saved queries the user can see by name: the user's own and those shared
+// with the organization
+func (s *Service) List(ctx context.Context, userID string) ([]domain.SavedQuery, error) {
+	queries, err := s.repo.ListSavedQueries(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if queries == nil {
+		queries = []domain.SavedQuery{}
+	}
+	return queries, nil
+}
+
+// Update replaces the name, description, scope and filter of a saved query of userID. Queries
+// shared by other users are answered with domain.ErrForbidden.
+func (s *Service) Update(ctx context.Context, id, userID string, update domain.SavedQuery) (domain.SavedQuery, error) {
+	query, err := s.owned(ctx, id, userID)
+	if err != nil {
+		return domain.SavedQuery{}, err
+	}
+
+	query.Name = update.Name
+	query.Description = update.Description
+	query.Scope = update.Scope
+	query.Filter = update.Filter
+	query.UpdatedAt = s.clock.Now().UTC()
+	query = normalize(query)
+
+	if err := query.Validate(); err != nil {
+		return domain.SavedQuery{}, err
+	}
+	if err := s.repo.UpdateSavedQuery(ctx, query); err != nil {
+		return domain.SavedQuery{}, err
+	}
+
+	s.logger.Info("saved query updated",
+		requestid.Field(ctx),
+		zap.String("query_id", query.ID),
+		zap.String("scope", string(query.Scope)),
+		zap.String("updated_by", userID),
+		tenant.Field(ctx),
+	)
+	return query, nil
+}
+
+// Delete removes a saved query of userID. Queries shared by other users are answered with
+// domain.ErrForbidden.
+func (s *Service) Delete(ctx context.Context, id, userID string) error {
+	if _, err := s.owned(ctx, id, userID); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteSavedQuery(ctx, id); err != nil {
+		return err
+	}
+
+	s.logger.Info("saved query deleted",
+		requestid.Field(ctx),
+		zap.String("query_id", id),
+		zap.String("deleted_by", userID),
+		tenant.Field(ctx),
+	)
+	return nil
+}
+
+// Run applies the filters of a saved query the user can see to the events of a session. The
+// relative time range and the "me" user filter are resolved for the user at the time of the run.
+func (s *Service) Run(ctx context.Context, sessionID, id, userID string, isShareToken bool, pagination domain.PaginationParams) (*domain.AuditResponse, error) {
+	if isShareToken {
+		return nil, domain.ErrForbidden
+	}
+	parsed, err := domain.ParseSessionID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	query, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := query.Filter.EventFilter(userID, s.clock.Now())
+	return s.events.QueryEvents(ctx, parsed, domain.UserID(userID), false, filter, pagination)
+}
+
+// owned returns a saved query userID created
+func (s *Service) owned(ctx context.Context, id, userID string) (domain.SavedQuery, error) {
+	query, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return domain.SavedQuery{}, err
+	}
+	if query.CreatedBy != userID {
+		return domain.SavedQuery{}, domain.ErrForbidden
+	}
+	return query, nil
+}
+
+// normalize trims the name and drops duplicate filter values
+func normalize(query domain.SavedQuery) domain.SavedQuery {
+	query.Name = strings.TrimSpace(query.Name)
+	query.Description = strings.TrimSpace(query.Description)
+	query.Filter.Types = dedupe(query.Filter.Types)
+	query.Filter.Categories = dedupe(query.Filter.Categories)
+	query.Filter.Severities = dedupe(query.Filter.Severities)
+	return query
+}
+
+// dedupe drops repeated values, keeping the first of each
+func dedupe[T comparable](values []T) []T {
+	if len(values) == 0 {
+		return nil
+	}
+	deduped := make([]T, 0, len(values))
+	for _, value := range values {
+		if !slices.Contains(deduped, value) {
+			deduped = append(deduped, value)
+		}
+	}
+	return deduped
+}
@@ -0,0 +1,167 @@
+package savedquery
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2024, 2, 8, 12, 0, 0, 0, time.UTC)
+
+const testSession = "550e8400-e29b-41d4-a716-446655440000"
+
+// memoryQueries keeps saved queries in memory like the audit_saved_queries table
+type memoryQueries struct {
+	queries []domain.SavedQuery
+}
+
+func (r *memoryQueries) CreateSavedQuery(_ context.Context, query domain.SavedQuery) error {
+	r.queries = append(r.queries, query)
+	return nil
+}
+
+func (r *memoryQueries) GetSavedQuery(_ context.Context, id string) (*domain.SavedQuery, error) {
+	for _, query := range r.queries {
+		if query.ID == id {
+			return &query, nil
+		}
+	}
+	return nil, domain.ErrSavedQueryNotFound
+}
+
+func (r *memoryQueries) ListSavedQueries(_ context.Context, userID string) ([]domain.SavedQuery, error) {
+	var queries []domain.SavedQuery
+	for _, query := range r.queries {
+		if query.VisibleTo(userID) {
+			queries = append(queries, query)
+		}
+	}
+	return queries, nil
+}
+
+func (r *memoryQueries) UpdateSavedQuery(_ context.Context, query domain.SavedQuery) error {
+	for i := range r.queries {
+		if r.queries[i].ID == query.ID {
+			r.queries[i] = query
+			return nil
+		}
+	}
+	return domain.ErrSavedQueryNotFound
+}
+
+func (r *memoryQueries) DeleteSavedQuery(_ context.Context, id string) error {
+	for i, query := range r.queries {
+		if query.ID == id {
+			r.queries = slices.Delete(r.queries, i, i+1)
+			return nil
+		}
+	}
+	return domain.ErrSavedQueryNotFound
+}
+
+// recordingReader records the filter of the last event query
+type recordingReader struct {
+	filter domain.EventFilter
+}
+
+func (r *recordingReader) QueryEvents(_ context.Context, _ domain.SessionID, _ domain.UserID, _ bool, filter domain.EventFilter, _ domain.PaginationParams) (*domain.AuditResponse, error) {
+	r.filter = filter
+	return &domain.AuditResponse{Items: []domain.AuditEntry{}}, nil
+}
+
+func newTestService() (*Service, *memoryQueries, *recordingReader) {
+	repo := &memoryQueries{}
+	events := &recordingReader{}
+	return New(repo, events, clock.NewFakeClock(testNow), zap.NewNop()), repo, events
+}
+
+func TestService_Create(t *testing.T) {
+	service, repo, _ := newTestService()
+
+	query, err := service.Create(tenant.NewContext(context.Background(), "acme"), domain.SavedQuery{
+		Name:      " My edits this week ",
+		Scope:     domain.SavedQueryPrivate,
+		Filter:    domain.SavedQueryFilter{Types: []string{"edit", "merge", "edit"}, UserID: domain.SavedQueryCurrentUser, Days: 7},
+		CreatedBy: "user-1",
+	})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, query.ID)
+	assert.Equal(t, "My edits this week", query.Name)
+	assert.Equal(t, []string{"edit", "merge"}, query.Filter.Types)
+	assert.Equal(t, testNow, query.CreatedAt)
+	assert.Equal(t, testNow, query.UpdatedAt)
+	assert.Equal(t, "acme", query.OrganizationID)
+	assert.Equal(t, []domain.SavedQuery{query}, repo.queries)
+
+	_, err = service.Create(context.Background(), domain.SavedQuery{Name: "Public", Scope: "public", CreatedBy: "user-1"})
+	assert.ErrorIs(t, err, domain.ErrInvalidSavedQuery)
+	assert.Len(t, repo.queries, 1)
+}
+
+func TestService_Access(t *testing.T) {
+	service, repo, _ := newTestService()
+	ctx := context.Background()
+	private, err := service.Create(ctx, domain.SavedQuery{Name: "Mine", Scope: domain.SavedQueryPrivate, CreatedBy: "user-1"})
+	require.NoError(t, err)
+	shared, err := service.Create(ctx, domain.SavedQuery{Name: "All exports", Scope: domain.SavedQueryOrganization,
+		Filter: domain.SavedQueryFilter{Types: []string{"export"}}, CreatedBy: "user-1"})
+	require.NoError(t, err)
+
+	// Private queries of other users are not disclosed
+	_, err = service.Get(ctx, private.ID, "user-2")
+	assert.ErrorIs(t, err, domain.ErrSavedQueryNotFound)
+	_, err = service.Get(ctx, "not-a-uuid", "user-1")
+	assert.ErrorIs(t, err, domain.ErrSavedQueryNotFound)
+	got, err := service.Get(ctx, shared.ID, "user-2")
+	require.NoError(t, err)
+	assert.Equal(t, shared, got)
+
+	list, err := service.List(ctx, "user-2")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SavedQuery{shared}, list)
+
+	// Shared queries are changed by their creator only
+	_, err = service.Update(ctx, shared.ID, "user-2", domain.SavedQuery{Name: "Renamed", Scope: domain.SavedQueryPrivate})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	assert.ErrorIs(t, service.Delete(ctx, shared.ID, "user-2"), domain.ErrForbidden)
+	assert.ErrorIs(t, service.Delete(ctx, private.ID, "user-2"), domain.ErrSavedQueryNotFound)
+
+	updated, err := service.Update(ctx, shared.ID, "user-1", domain.SavedQuery{Name: "Exports this month",
+		Scope: domain.SavedQueryOrganization, Filter: domain.SavedQueryFilter{Types: []string{"export"}, Days: 30}})
+	require.NoError(t, err)
+	assert.Equal(t, shared.CreatedAt, updated.CreatedAt)
+	assert.Equal(t, "user-1", updated.CreatedBy)
+	assert.Equal(t, 30, updated.Filter.Days)
+
+	require.NoError(t, service.Delete(ctx, shared.ID, "user-1"))
+	assert.Equal(t, []domain.SavedQuery{private}, repo.queries)
+}
+
+func TestService_Run(t *testing.T) {
+	service, _, events := newTestService()
+	ctx := context.Background()
+	query, err := service.Create(ctx, domain.SavedQuery{Name: "My edits this week", Scope: domain.SavedQueryOrganization,
+		Filter:    domain.SavedQueryFilter{Types: []string{"edit"}, UserID: domain.SavedQueryCurrentUser, Days: 7},
+		CreatedBy: "user-1"})
+	require.NoError(t, err)
+
+	// The "me" user and the relative range are resolved for the user running the query
+	_, err = service.Run(ctx, testSession, query.ID, "user-2", false, domain.PaginationParams{Limit: 50})
+	require.NoError(t, err)
+	assert.Equal(t, domain.EventFilter{Types: []string{"edit"}, UserID: "user-2", From: testNow.AddDate(0, 0, -7)}, events.filter)
+
+	_, err = service.Run(ctx, testSession, query.ID, "user-1", true, domain.PaginationParams{Limit: 50})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	_, err = service.Run(ctx, "test-session", query.ID, "user-1", false, domain.PaginationParams{Limit: 50})
+	assert.ErrorIs(t, err, domain.ErrInvalidSessionID)
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"audit-service/internal/config"
+	"audit-service/internal/diagnostics"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/mtls"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// listeners are the HTTP servers of the service
+type listeners struct {
+	// public serves the frontend routes, and every route without an internal listener
+	public *http.Server
+	// internal serves the admin routes, service callbacks, metrics and profiles; nil without INTERNAL_ADDR
+	internal *http.Server
+	// diagnostics serves profiles and runtime stats; nil without DIAGNOSTICS_ADDR
+	diagnostics *http.Server
+
+	cfg    *config.Config
+	logger *zap.Logger
+}
+
+// newListeners creates the listeners of the configured addresses, each serving the router that
+// router builds for its surface
+func newListeners(cfg *config.Config, router func(surface listenerSurface) *gin.Engine, startedAt time.Time, clk clock.Clock, logger *zap.Logger) (*listeners, error) {
+	l := &listeners{cfg: cfg, logger: logger}
+
+	// With an internal listener the admin routes are only served there, so the public ingress
+	// never exposes them
+	surface := surfaceAll
+	if cfg.InternalAddr != "" {
+		surface = surfacePublic
+	}
+	l.public = &http.Server{
+		Addr:    fmt.Sprintf(":%s", cfg.Port),
+		Handler: router(surface),
+	}
+
+	// Other pptxTrans services and operators reach the admin routes, service callbacks, metrics
+	// and profiles on the internal listener, authenticated by their client certificates when
+	// mTLS is configured
+	if cfg.InternalAddr != "" {
+		internalRouter := router(surfaceInternal)
+		internalRouter.Any("/debug/*path", gin.WrapH(diagnostics.NewHandler(startedAt, clk)))
+		l.internal = &http.Server{
+			Addr:    cfg.InternalAddr,
+			Handler: internalRouter,
+		}
+		if cfg.InternalMTLSEnabled() {
+			tlsConfig, err := mtls.ServerConfig(cfg.InternalTLSCertFile, cfg.InternalTLSKeyFile, cfg.InternalTLSCAFile, cfg.InternalTLSAllowedSANs)
+			if err != nil {
+				return nil, fmt.Errorf("invalid internal TLS configuration: %w", err)
+			}
+			l.internal.TLSConfig = tlsConfig
+		}
+	}
+
+	// Profiles and runtime stats are served on a separate listener that is never exposed publicly
+	if cfg.DiagnosticsAddr != "" {
+		l.diagnostics = &http.Server{
+			Addr:    cfg.DiagnosticsAddr,
+			Handler: diagnostics.NewHandler(startedAt, clk),
+		}
+	}
+
+	return l, nil
+}
+
+// start serves each listener in the background; the process exits when the public or internal
+// listener cannot start
+func (l *listeners) start() {
+	go func() {
+		l.logger.Info("server starting", zap.String("addr", l.public.Addr))
+		if err := l.public.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			l.logger.Fatal("failed to start server", zap.Error(err))
+		}
+	}()
+
+	if l.internal != nil {
+		go func() {
+			l.logger.Info("internal server starting",
+				zap.String("addr", l.internal.Addr),
+				zap.Bool("mtls", l.cfg.InternalMTLSEnabled()),
+				zap.Strings("allowed_sans", l.cfg.InternalTLSAllowedSANs),
+			)
+			var err error
+			if l.internal.TLSConfig != nil {
+				err = l.internal.ListenAndServeTLS("", "")
+			} else {
+				err = l.internal.ListenAndServe()
+			}
+			if err != nil && err != http.ErrServerClosed {
+				l.logger.Fatal("failed to start internal server", zap.Error(err))
+			}
+		}()
+	}
+
+	if l.diagnostics != nil {
+		go func() {
+			l.logger.Info("diagnostics server starting", zap.String("addr", l.diagnostics.Addr))
+			if err := l.diagnostics.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				l.logger.Error("diagnostics server failed", zap.Error(err))
+			}
+		}()
+	}
+}
+
+// drain stops accepting connections on the public and internal listeners and waits for
+// in-flight requests to finish. The diagnostics listener is closed by its shutdown hook.
+func (l *listeners) drain(ctx context.Context) {
+	var internalDrained chan error
+	if l.internal != nil {
+		internalDrained = make(chan error, 1)
+		go func() { internalDrained <- l.internal.Shutdown(ctx) }()
+	}
+	if err := l.public.Shutdown(ctx); err != nil {
+		l.logger.Error("server did not drain in-flight requests before the timeout", zap.Error(err))
+	}
+	if internalDrained != nil {
+		if err := <-internalDrained; err != nil {
+			l.logger.Error("internal server did not drain in-flight requests before the timeout", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,817 @@
+// Package server runs the audit service: it wires the storage, caches and background workers
+// from the configuration, serves the public and internal listeners until it receives SIGINT or
+// SIGTERM, and drains them on shutdown.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"audit-service/docs" // Registers the generated swagger docs
+	"audit-service/internal/annotations"
+	"audit-service/internal/broadcast"
+	"audit-service/internal/config"
+	"audit-service/internal/domain"
+	"audit-service/internal/eventtypes"
+	"audit-service/internal/exporthook"
+	"audit-service/internal/handlers"
+	"audit-service/internal/labels"
+	"audit-service/internal/legalhold"
+	"audit-service/internal/lifecycle"
+	"audit-service/internal/metrics"
+	"audit-service/internal/middleware"
+	"audit-service/internal/ocsf"
+	"audit-service/internal/outbox"
+	"audit-service/internal/preflight"
+	"audit-service/internal/quota"
+	"audit-service/internal/redact"
+	"audit-service/internal/reload"
+	"audit-service/internal/repository"
+	"audit-service/internal/review"
+	"audit-service/internal/savedquery"
+	"audit-service/internal/service"
+	"audit-service/internal/sharetrail"
+	"audit-service/pkg/apikey"
+	"audit-service/pkg/cache"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/jwt"
+	"audit-service/pkg/logger"
+	"audit-service/pkg/workpool"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.uber.org/zap"
+)
+
+// serviceVersion is reported by the health check and the startup banner
+const serviceVersion = "1.0.0"
+
+// Server is the audit service wired from its configuration: its storage, background workers and
+// listeners
+type Server struct {
+	cfg       *config.Config
+	listeners *listeners
+	// shutdown holds the hooks run once in-flight requests have completed
+	shutdown *lifecycle.Shutdown
+	// shuttingDown is flipped on the first shutdown signal so the health check takes the pod out
+	// of rotation
+	shuttingDown atomic.Bool
+	logger       *zap.Logger
+}
+
+// New wires the service from cfg and starts its background workers, exiting when a component
+// cannot be created. Run serves the listeners.
+func New(cfg *config.Config, logLevel zap.AtomicLevel, zapLogger *zap.Logger) *Server {
+	zapLogger.Info("starting audit service",
+		zap.String("version", serviceVersion),
+		zap.String("go_version", runtime.Version()),
+		zap.String("port", cfg.Port),
+		zap.String("internal_addr", cfg.InternalAddr),
+		zap.String("service_role", cfg.ServiceRole),
+		zap.String("storage_backend", cfg.StorageBackend),
+		zap.String("cache_backend", cfg.CacheBackend),
+		zap.String("log_level", cfg.LogLevel),
+	)
+
+	// Set Gin mode based on log level
+	if cfg.LogLevel == "debug" {
+		gin.SetMode(gin.DebugMode)
+	} else {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	clk := clock.New()
+	startedAt := clk.Now()
+	s := &Server{cfg: cfg, shutdown: lifecycle.New(zapLogger), logger: zapLogger}
+
+	auth := newAuth(cfg, clk, zapLogger)
+	caches := newCaches(cfg, clk, zapLogger)
+
+	// JSON schemas for the details of each event type
+	eventSchemas, err := domain.NewDefaultSchemaRegistry()
+	if err != nil {
+		zapLogger.Fatal("failed to load event schemas", zap.Error(err))
+	}
+
+	// Masks personal data in the details of ingested events
+	var redactor *redact.Redactor
+	if len(cfg.RedactionRules) > 0 {
+		redactor = redact.New(cfg.RedactionRules)
+	}
+
+	// Bounds the clock skew of client timestamps
+	timestampPolicy := domain.TimestampPolicy{
+		MaxFutureSkew: cfg.TimestampMaxFutureSkew,
+		MaxPastSkew:   cfg.TimestampMaxPastSkew,
+		Mode:          domain.SkewMode(cfg.TimestampSkewMode),
+	}
+
+	appMetrics := metrics.New()
+	appMetrics.RegisterTokenCache(caches.tokens)
+
+	// Daily event quotas are counted in Redis when it is configured, so all replicas share them
+	var quotaStore quota.Store = quota.NewMemoryStore(clk)
+	if caches.redisClient != nil {
+		quotaStore = quota.NewRedisStore(caches.redisClient, "audit-service:quota:", cfg.CacheRedisTimeout)
+	}
+	quotas := quota.New(quotaStore, quota.Config{
+		SessionDaily: int64(cfg.QuotaSessionDaily),
+		UserDaily:    int64(cfg.QuotaUserDaily),
+	}, clk, appMetrics, zapLogger)
+
+	// Audit data lives in the backend selected by STORAGE_BACKEND
+	store, err := OpenStorage(context.Background(), cfg, appMetrics, clk, zapLogger)
+	if err != nil {
+		zapLogger.Fatal("failed to open storage", zap.String("backend", cfg.StorageBackend), zap.Error(err))
+	}
+	storage, err := NewStorage(store, cfg, appMetrics, clk, zapLogger)
+	if err != nil {
+		zapLogger.Fatal("failed to open storage", zap.String("backend", cfg.StorageBackend), zap.Error(err))
+	}
+
+	// Custom event types are defined next to the built-in ones before events are accepted
+	eventTypes := eventtypes.New(store, eventSchemas, cfg.EventTypesRefreshInterval, clk, zapLogger)
+	if err := eventTypes.Load(context.Background()); err != nil {
+		// Types are loaded again on the next refresh and when they are listed
+		zapLogger.Warn("failed to load custom event types", zap.Error(err))
+	}
+
+	// Categories and severities of the event types, given to the entries read
+	taxonomy, err := domain.ParseTaxonomy(cfg.EventTaxonomy)
+	if err != nil {
+		zapLogger.Fatal("invalid event taxonomy", zap.Error(err))
+	}
+
+	// Exports, chain verifications and counted reports take a slot of a bounded pool
+	exportPool := workpool.New(cfg.ExportMaxConcurrent)
+	appMetrics.RegisterWorkPool("export", exportPool)
+
+	// Queries and exports are served apart from ingestion, so heavy exports cannot hold up writes
+	requestRepo := storage.RequestRepo
+	reader := service.NewReader(requestRepo, eventSchemas, service.ReaderConfig{
+		ExportPageSize:  cfg.ExportPageSize,
+		Pool:            exportPool,
+		Taxonomy:        taxonomy,
+		ConsistencyWait: cfg.ConsistencyWait,
+	}, clk, zapLogger)
+	writer := service.NewWriter(requestRepo, service.WriterConfig{
+		WriteAttempts: cfg.WriteRetryAttempts,
+		WriteBackoff:  cfg.WriteRetryBackoff,
+	}, clk, zapLogger)
+	broker := broadcast.NewBroker(zapLogger)
+	// Review events are checked against the review state of their slide or shape in storage
+	reviews := review.New(requestRepo, zapLogger)
+	zapLogger.Info("serving audit events", zap.String("role", cfg.ServiceRole))
+
+	w := newWorkers(cfg, workerDeps{
+		storage:        storage,
+		writer:         writer,
+		reader:         reader,
+		broker:         broker,
+		schemas:        eventSchemas,
+		redactor:       redactor,
+		eventTypes:     eventTypes,
+		keySet:         auth.keySet,
+		tokenValidator: auth.tokens,
+		tokenCache:     caches.tokens,
+		redisClient:    caches.redisClient,
+		exportPool:     exportPool,
+		clock:          clk,
+		metrics:        appMetrics,
+		logger:         zapLogger,
+	}, s.shutdown)
+
+	// SIGHUP and changes to the .env file reload the settings that can change without a restart
+	corsOrigin := middleware.NewCORSOrigin(cfg.CORSOrigin)
+	reloader := reload.New(cfg, config.Reload, reload.Targets{
+		LogLevel:         logLevel,
+		CORSOrigin:       corsOrigin,
+		TokenCache:       caches.tokens,
+		IdempotencyCache: caches.idempotency,
+		Quotas:           quotas,
+		BruteForce:       w.guard,
+		Replays:          w.replays,
+	}, storage.Repo.CreateEvents, clk, zapLogger)
+	reloader.Start()
+	s.shutdown.Register("config reload", reloader.Close)
+
+	// Storage is closed once nothing writes to it anymore
+	if caches.redisCache != nil {
+		s.shutdown.Register("token cache", caches.redisCache.Close)
+	}
+	s.shutdown.Register("storage", store.Close)
+
+	// Uses of share links are recorded by the instance that authenticates them
+	shareTrails := sharetrail.New(w.eventWriter, reader, clk, zapLogger)
+
+	routes := routeHandlers{
+		audit:   handlers.NewAuditHandler(reader, clk, zapLogger),
+		events:  handlers.NewEventsHandler(w.eventWriter, reader, broker, eventSchemas, redactor, caches.idempotency, timestampPolicy, cfg.MaxDetailsSize, quotas, reviews, w.views, clk, zapLogger),
+		export:  handlers.NewExportHandler(reader, cfg.ExportMaxRows, clk, zapLogger),
+		stream:  handlers.NewStreamHandler(reader, broker, zapLogger),
+		ws:      handlers.NewWebSocketHandler(reader, broker, corsOrigin, zapLogger),
+		erasure: handlers.NewErasureHandler(w.erasure, zapLogger),
+		imports: handlers.NewImportHandler(w.imports, zapLogger),
+		replays: handlers.NewReplayHandler(w.replayJobs, zapLogger),
+		admin:   handlers.NewAdminHandler(reader, zapLogger),
+		types:   handlers.NewEventTypesHandler(eventTypes, zapLogger),
+		// Legal holds are enforced by the storage backend in retention purges and erasure
+		holds:   handlers.NewLegalHoldsHandler(legalhold.NewManager(store, clk, zapLogger), zapLogger),
+		revoked: handlers.NewRevocationsHandler(w.revocations, zapLogger),
+		config:  handlers.NewConfigHandler(reloader, zapLogger),
+		ops:     handlers.NewOperationsHandler(w.retention, w.outboxReplayer, w.rollups, clk, zapLogger),
+		dlq:     handlers.NewDeadLettersHandler(w.deadLetters, zapLogger),
+		watches: handlers.NewWatchesHandler(w.watches, zapLogger),
+		reports: handlers.NewReportsHandler(w.reports, zapLogger),
+		quota:   handlers.NewQuotaHandler(quotas, zapLogger),
+		queries: handlers.NewSavedQueriesHandler(savedquery.New(store, reader, clk, zapLogger), zapLogger),
+		exports: handlers.NewExportHooksHandler(exporthook.New(w.eventWriter, reader, timestampPolicy, clk, zapLogger),
+			cfg.ExportCallbackSecret, cfg.ExportCallbackTolerance, clk, zapLogger),
+		shares: handlers.NewShareTokensHandler(shareTrails, zapLogger),
+		labels: handlers.NewLabelsHandler(labels.New(store, requestRepo, reader, clk, zapLogger), zapLogger),
+		notes:  handlers.NewAnnotationsHandler(annotations.New(store, requestRepo, reader, w.eventWriter, clk, zapLogger), zapLogger),
+	}
+
+	s.listeners, err = newListeners(cfg, func(surface listenerSurface) *gin.Engine {
+		return setupRouter(cfg, corsOrigin, clk, auth.tokens, auth.shares, auth.apiKeys, caches.tokens, requestRepo, shareTrails,
+			routes, appMetrics, w.authFailures, w.throttler, &s.shuttingDown, surface, zapLogger)
+	}, startedAt, clk, zapLogger)
+	if err != nil {
+		zapLogger.Fatal("failed to create listeners", zap.Error(err))
+	}
+	// Long-lived streams would otherwise hold Shutdown until the timeout expires
+	s.listeners.public.RegisterOnShutdown(broker.Close)
+	s.listeners.public.RegisterOnShutdown(routes.ws.CloseAll)
+	if s.listeners.diagnostics != nil {
+		s.shutdown.Register("diagnostics server", s.listeners.diagnostics.Shutdown)
+	}
+
+	return s
+}
+
+// Run serves the listeners until the process receives SIGINT or SIGTERM, then drains them and
+// runs the shutdown hooks
+func (s *Server) Run() {
+	s.listeners.start()
+
+	// Wait for interrupt signal to gracefully shutdown the server
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	s.shuttingDown.Store(true)
+	s.logger.Info("shutting down server...",
+		zap.Duration("timeout", s.cfg.ShutdownTimeout),
+	)
+
+	// Graceful shutdown with timeout shared by request draining and the shutdown hooks
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	defer cancel()
+
+	s.listeners.drain(ctx)
+
+	// Flush pending audit writes even if request draining timed out
+	if err := s.shutdown.Run(ctx); err != nil {
+		s.logger.Error("shutdown hooks failed", zap.Error(err))
+	}
+
+	s.logger.Info("server exited")
+}
+
+// auth are the validators of the credentials accepted by the service
+type auth struct {
+	// keySet holds the keys of the Supabase JWKS; nil without SUPABASE_JWKS_URL
+	keySet  *jwt.KeySet
+	tokens  jwt.TokenValidator
+	shares  jwt.ShareTokenValidator
+	apiKeys *apikey.Store
+}
+
+// newAuth creates the validators of user tokens, share-link tokens and API keys
+func newAuth(cfg *config.Config, clk clock.Clock, zapLogger *zap.Logger) auth {
+	// Share-link tokens are issued by the share service
+	a := auth{shares: jwt.NewShareTokenValidator(cfg.ShareTokenSecret)}
+	if cfg.ShareTokenSecret == "" {
+		zapLogger.Warn("SHARE_TOKEN_SECRET is not set, share-link access is disabled")
+	}
+
+	// Hashed API keys for service-to-service writes
+	a.apiKeys = apikey.NewStore(cfg.APIKeys)
+
+	// Tokens are verified with the keys of the Supabase JWKS, fetched again when the auth server
+	// rotates them, and with the HMAC secret of projects that still use one
+	if cfg.SupabaseJWKSURL != "" {
+		a.keySet = jwt.NewKeySet(cfg.SupabaseJWKSURL, &http.Client{Timeout: cfg.HTTPTimeout}, cfg.SupabaseJWKSRefreshInterval, clk, zapLogger)
+		if err := a.keySet.Refresh(context.Background()); err != nil {
+			// Keys are fetched again when the first token arrives
+			zapLogger.Warn("failed to fetch JWKS", zap.String("url", cfg.SupabaseJWKSURL), zap.Error(err))
+		}
+	}
+	tokens, err := jwt.NewValidator(jwt.Options{
+		Secret:         cfg.SupabaseJWTSecret,
+		PreviousSecret: cfg.SupabaseJWTPreviousSecret,
+		KeySet:         a.keySet,
+		Issuer:         cfg.SupabaseJWTIssuer,
+		Audience:       cfg.SupabaseJWTAudience,
+		Clock:          clk,
+	})
+	if err != nil {
+		zapLogger.Fatal("failed to initialize token validator", zap.Error(err))
+	}
+	a.tokens = tokens
+	return a
+}
+
+// caches are the caches shared by the request handlers and the workers
+type caches struct {
+	// redisClient and redisCache are nil unless CACHE_BACKEND is redis
+	redisClient *redis.Client
+	redisCache  *cache.RedisCache
+	tokens      *cache.TokenCache
+	idempotency *cache.IdempotencyCache
+}
+
+// newCaches creates the token and idempotency caches, kept in Redis when it is configured
+func newCaches(cfg *config.Config, clk clock.Clock, zapLogger *zap.Logger) caches {
+	var c caches
+
+	// Validated tokens are cached in memory, or in Redis to share them between replicas
+	var tokenBackend cache.Cache = cache.NewMemoryCache(cfg.CacheCleanupInterval)
+	if cfg.CacheBackend == "redis" {
+		redisOpts, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			zapLogger.Fatal("invalid REDIS_URL", zap.Error(err))
+		}
+		c.redisClient = redis.NewClient(redisOpts)
+		c.redisCache = cache.NewRedisCache(c.redisClient, cfg.CacheRedisPrefix, cfg.CacheRedisTimeout, zapLogger)
+		tokenBackend = c.redisCache
+	}
+	c.tokens = cache.NewTokenCacheWithBackend(tokenBackend, cfg.CacheJWTTTL, cfg.CacheShareTokenTTL, clk)
+
+	// Remembers responses so retried event submissions are not stored twice; the keys are
+	// kept in Redis when it is configured, so a retry is recognized by every replica
+	var idempotencyStore cache.IdempotencyStore = cache.NewMemoryIdempotencyStore(clk)
+	if c.redisClient != nil {
+		idempotencyStore = cache.NewRedisIdempotencyStore(c.redisClient, "audit-service:idempotency:", cfg.CacheRedisTimeout)
+	}
+	c.idempotency = cache.NewIdempotencyCacheWithStore(idempotencyStore, cfg.IdempotencyTTL)
+	return c
+}
+
+// Load reads the configuration and creates the logger, exiting when either fails. Configured
+// secrets are masked in the log output; the level can be changed by a configuration reload.
+func Load() (*config.Config, zap.AtomicLevel, *zap.Logger) {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	logLevel := logger.NewLevel(cfg.LogLevel)
+	zapLogger, err := logger.NewWithLevel(logLevel, cfg.Secrets()...)
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	return cfg, logLevel, zapLogger
+}
+
+// CheckConfig validates the configuration and the connections it configures, prints the
+// self-check report as JSON and returns the exit code: 0 when every check passed, 1 otherwise
+func CheckConfig() int {
+	clk := clock.New()
+	cfg, err := config.Read()
+	var probes []preflight.Probe
+	if err == nil {
+		// Failed probes are reported, not logged
+		probes = preflight.Probes(cfg, clk, zap.NewNop())
+	}
+	report := preflight.Run(context.Background(), cfg, err, probes, clk)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Printf("Failed to write the configuration report: %v", err)
+		return 1
+	}
+	if !report.OK {
+		return 1
+	}
+	return 0
+}
+
+// webhookSink posts outbox messages to the URLs, rendered as OCSF events when WEBHOOK_FORMAT is ocsf
+func webhookSink(cfg *config.Config, urls []string, secret string, clk clock.Clock) outbox.Sink {
+	var sink outbox.Sink = outbox.NewWebhookSink(urls, secret, &http.Client{Timeout: cfg.HTTPTimeout}, clk)
+	if cfg.WebhookFormat == "ocsf" {
+		sink = ocsf.NewSink(sink)
+	}
+	return sink
+}
+
+// listenerSurface selects the routes a router serves
+type listenerSurface int
+
+const (
+	// surfaceAll serves every route on the public listener when no internal listener is configured
+	surfaceAll listenerSurface = iota
+	// surfacePublic serves the frontend routes, leaving the admin routes to the internal listener
+	surfacePublic
+	// surfaceInternal serves the admin routes, service callbacks, ingestion, metrics and profiles
+	surfaceInternal
+)
+
+// public reports whether the frontend routes are served
+func (s listenerSurface) public() bool { return s != surfaceInternal }
+
+// internal reports whether the admin routes, service callbacks and metrics are served
+func (s listenerSurface) internal() bool { return s != surfacePublic }
+
+// routeHandlers groups the HTTP handlers mounted by setupRouter
+type routeHandlers struct {
+	audit   *handlers.AuditHandler
+	events  *handlers.EventsHandler
+	export  *handlers.ExportHandler
+	stream  *handlers.StreamHandler
+	ws      *handlers.WebSocketHandler
+	erasure *handlers.ErasureHandler
+	imports *handlers.ImportHandler
+	replays *handlers.ReplayHandler
+	admin   *handlers.AdminHandler
+	types   *handlers.EventTypesHandler
+	holds   *handlers.LegalHoldsHandler
+	revoked *handlers.RevocationsHandler
+	config  *handlers.ConfigHandler
+	ops     *handlers.OperationsHandler
+	dlq     *handlers.DeadLettersHandler
+	watches *handlers.WatchesHandler
+	reports *handlers.ReportsHandler
+	quota   *handlers.QuotaHandler
+	queries *handlers.SavedQueriesHandler
+	exports *handlers.ExportHooksHandler
+	shares  *handlers.ShareTokensHandler
+	labels  *handlers.LabelsHandler
+	notes   *handlers.AnnotationsHandler
+}
+
+func setupRouter(
+	cfg *config.Config,
+	corsOrigin *middleware.CORSOrigin,
+	clk clock.Clock,
+	tokenValidator jwt.TokenValidator,
+	shareValidator jwt.ShareTokenValidator,
+	apiKeys *apikey.Store,
+	tokenCache *cache.TokenCache,
+	auditRepo repository.AuditRepository,
+	shareUses middleware.ShareTokenUseReporter,
+	routes routeHandlers,
+	appMetrics *metrics.Metrics,
+	authFailures []middleware.AuthFailureReporter,
+	throttler middleware.AuthThrottler,
+	shuttingDown *atomic.Bool,
+	surface listenerSurface,
+	zapLogger *zap.Logger,
+) *gin.Engine {
+	router := gin.New()
+
+	// gin resolves Context.ClientIP from X-Forwarded-For of the trusted proxies only, like
+	// ClientInfo does for the IP recorded with audit events. It cannot read Forwarded, so with
+	// that header it falls back to the peer address rather than trusting X-Forwarded-For.
+	trustedProxies := make([]string, 0, len(cfg.TrustedProxies))
+	for _, prefix := range cfg.TrustedProxies {
+		trustedProxies = append(trustedProxies, prefix.String())
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		zapLogger.Fatal("invalid trusted proxies", zap.Error(err))
+	}
+	router.RemoteIPHeaders = []string{"X-Forwarded-For"}
+	if cfg.ForwardedHeader != middleware.ForwardedHeaderXForwardedFor {
+		router.RemoteIPHeaders = nil
+	}
+
+	// Apply CORS middleware first to ensure headers are set for all responses; browsers only
+	// reach the public listener
+	if surface.public() {
+		router.Use(middleware.CORSMiddleware(corsOrigin, zapLogger))
+	}
+
+	// Import files are bounded by IMPORT_MAX_FILE_SIZE_MB unless MAX_BODY_SIZE_ROUTES sets a limit
+	bodyLimits := middleware.BodyLimits{Default: int64(cfg.MaxBodySize), Routes: map[string]int64{
+		"POST /api/v1/events/import": int64(cfg.ImportMaxFileSizeMB) << 20,
+	}}
+	for route, limit := range cfg.MaxBodySizeRoutes {
+		bodyLimits.Routes[route] = int64(limit)
+	}
+
+	// Other global middleware
+	global := []gin.HandlerFunc{
+		middleware.RequestID(),
+		middleware.Recovery(),
+		middleware.ClientInfo(cfg.TrustedProxies, cfg.ForwardedHeader),
+		middleware.AccessLog(zapLogger, middleware.AccessLogSampling{
+			Default: cfg.AccessLogSampleRate,
+			Routes:  cfg.AccessLogRouteSampling,
+		}),
+		middleware.Metrics(appMetrics),
+		middleware.BodyLimit(bodyLimits),
+	}
+	if throttler != nil {
+		global = append(global, middleware.Throttle(throttler, tokenCache))
+	}
+	// Rejected requests are reported after ErrorHandler has written their response
+	if len(authFailures) > 0 {
+		global = append(global, middleware.AuthFailures(clk, authFailures...))
+	}
+	router.Use(append(global, middleware.ErrorHandler(zapLogger))...)
+
+	// Health check endpoint
+	router.GET("/health", handleHealth(clk, shuttingDown))
+
+	// Prometheus scrape endpoint
+	if surface.internal() {
+		router.GET("/metrics", gin.WrapH(appMetrics.Handler()))
+	}
+
+	// API v1 routes
+	v1 := router.Group("/api/v1")
+
+	// Ingestion is served on both listeners: to the frontend and to other services
+	if cfg.ServesWrites() {
+		events := v1.Group("/events")
+		events.Use(
+			middleware.DecompressRequest(),
+			middleware.APIKeyAuth(apiKeys, zapLogger),
+			middleware.OptionalAuth(tokenValidator, tokenCache, zapLogger),
+			middleware.RequireScope(apikey.ScopeEventsWrite, zapLogger),
+		)
+		{
+			events.POST("", routes.events.CreateEvent)
+			events.POST("/batch", routes.events.CreateEventsBatch)
+		}
+	}
+
+	if surface.public() {
+		setupPublicRoutes(router, v1, cfg, tokenValidator, shareValidator, tokenCache, auditRepo, shareUses, routes, clk, zapLogger)
+	}
+	if surface.internal() {
+		setupInternalRoutes(v1, cfg, tokenValidator, tokenCache, routes, zapLogger)
+	}
+
+	// 404 handler
+	router.NoRoute(middleware.HandleNotFound())
+	router.NoMethod(middleware.HandleMethodNotAllowed())
+
+	return router
+}
+
+// setupPublicRoutes mounts the routes of the frontend: the API docs, session history and live
+// streams, and the routes any user may call
+func setupPublicRoutes(
+	router *gin.Engine,
+	v1 *gin.RouterGroup,
+	cfg *config.Config,
+	tokenValidator jwt.TokenValidator,
+	shareValidator jwt.ShareTokenValidator,
+	tokenCache *cache.TokenCache,
+	auditRepo repository.AuditRepository,
+	shareUses middleware.ShareTokenUseReporter,
+	routes routeHandlers,
+	clk clock.Clock,
+	zapLogger *zap.Logger,
+) {
+	// Custom wrapper for Swagger UI that handles redirects
+	router.GET("/docs/*any", func(c *gin.Context) {
+		// Check if the path is exactly /docs/ or /docs
+		path := c.Param("any")
+		if path == "" || path == "/" {
+			c.Redirect(http.StatusMovedPermanently, "/docs/index.html")
+			return
+		}
+		// Otherwise use the standard handler
+		ginSwagger.WrapHandler(swaggerFiles.Handler)(c)
+	})
+
+	// OpenAPI 3 document for client generators
+	v1.GET("/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", docs.OpenAPI)
+	})
+	{
+		// Protected routes
+		sessions := v1.Group("/sessions")
+		sessions.Use(
+			middleware.Compress(cfg.CompressionMinSize),
+			middleware.ShareTokenUses(shareUses),
+			middleware.Auth(tokenValidator, shareValidator, tokenCache, auditRepo, clk, zapLogger),
+			middleware.RequireSharePermission(domain.SharePermissionView, zapLogger),
+		)
+
+		// Live streams are fed by the events ingested by the same instance
+		if cfg.ServesWrites() {
+			// WebSocket subscriptions to live audit events, authenticated before the upgrade
+			v1.GET("/ws", middleware.WebSocketAuth(tokenValidator, tokenCache, zapLogger), routes.ws.Connect)
+
+			sessions.GET("/:sessionId/events/stream", routes.stream.StreamEvents)
+			sessions.GET("/:sessionId/quota", routes.quota.GetSessionQuota)
+		}
+
+		// History queries, stats, activity, verification and exports
+		if cfg.ServesReads() {
+			sessions.GET("/:sessionId/history", routes.audit.GetHistory)
+			sessions.GET("/:sessionId/events", routes.audit.GetEvents)
+			sessions.GET("/:sessionId/slides/:slideId/events", routes.audit.GetSlideEvents)
+			sessions.GET("/:sessionId/comments/events", routes.audit.GetCommentEvents)
+			sessions.GET("/:sessionId/events/:eventId/revert-payload", routes.audit.GetRevertPayload)
+			sessions.GET("/:sessionId/events/:eventId/diff", routes.audit.GetEditDiff)
+			sessions.GET("/:sessionId/stats", routes.audit.GetStats)
+			sessions.GET("/:sessionId/activity", routes.audit.GetActivity)
+			sessions.GET("/:sessionId/heatmap", routes.audit.GetHeatmap)
+			sessions.GET("/:sessionId/contributors", routes.audit.GetContributors)
+			sessions.GET("/:sessionId/tm/leverage", routes.audit.GetTMLeverage)
+			sessions.GET("/:sessionId/mt/costs", routes.audit.GetMTCosts)
+			sessions.GET("/:sessionId/access-review", routes.audit.GetAccessReview)
+			sessions.GET("/:sessionId/timeline", routes.audit.GetTimeline)
+			sessions.GET("/:sessionId/events/export", routes.export.ExportEvents)
+			sessions.GET("/:sessionId/events/verify", routes.audit.VerifyChain)
+			sessions.GET("/:sessionId/queries/:queryId/events", routes.queries.RunSavedQuery)
+
+			// Event chains are looked up by event ID, so share tokens, which are bound to a session, do not apply
+			v1.GET("/events/:id/chain",
+				middleware.Compress(cfg.CompressionMinSize),
+				middleware.JWTAuth(tokenValidator, tokenCache, zapLogger),
+				routes.audit.GetEventChain,
+			)
+
+			// Export records are looked up by export ID, so share tokens, which are bound to a session, do not apply
+			v1.GET("/exports/:exportId/audit",
+				middleware.Compress(cfg.CompressionMinSize),
+				middleware.JWTAuth(tokenValidator, tokenCache, zapLogger),
+				routes.exports.GetExportAudit,
+			)
+
+			// Share links are looked up by token ID; their trails are read by the session owner, not with the link itself
+			v1.GET("/share-tokens/:tokenId/audit",
+				middleware.Compress(cfg.CompressionMinSize),
+				middleware.JWTAuth(tokenValidator, tokenCache, zapLogger),
+				routes.shares.GetShareTokenAudit,
+			)
+		}
+
+		// Watches are managed by any role; digests are sent by the instances that ingest events
+		sessions.PUT("/:sessionId/watch", routes.watches.WatchSession)
+		sessions.GET("/:sessionId/watch", routes.watches.GetSessionWatch)
+		sessions.DELETE("/:sessionId/watch", routes.watches.UnwatchSession)
+		v1.GET("/watches", middleware.JWTAuth(tokenValidator, tokenCache, zapLogger), routes.watches.ListWatches)
+
+		// Saved queries belong to accounts, so share tokens do not apply
+		queriesGroup := v1.Group("/queries", middleware.JWTAuth(tokenValidator, tokenCache, zapLogger))
+		{
+			queriesGroup.POST("", routes.queries.CreateSavedQuery)
+			queriesGroup.GET("", routes.queries.ListSavedQueries)
+			queriesGroup.GET("/:queryId", routes.queries.GetSavedQuery)
+			queriesGroup.PUT("/:queryId", routes.queries.UpdateSavedQuery)
+			queriesGroup.DELETE("/:queryId", routes.queries.DeleteSavedQuery)
+		}
+
+		// Labels are looked up by event ID, so share tokens, which are bound to a session, do not
+		// apply. Reviewers and admins label the events of every session, owners those of theirs.
+		labelsGroup := v1.Group("/events/:id/labels",
+			middleware.JWTAuth(tokenValidator, tokenCache, zapLogger),
+			middleware.GrantReviewerAccess(cfg.AdminUserIDs),
+		)
+		{
+			labelsGroup.GET("", routes.labels.GetEventLabels)
+			labelsGroup.POST("", routes.labels.AddEventLabels)
+			labelsGroup.PUT("", routes.labels.ReplaceEventLabels)
+			labelsGroup.DELETE("/:label", routes.labels.RemoveEventLabel)
+		}
+
+		// Only reviewers and admins annotate events, of any session; session owners, often the
+		// subject of an investigation, do not see the findings
+		annotationsGroup := v1.Group("/events/:id/annotations",
+			middleware.JWTAuth(tokenValidator, tokenCache, zapLogger),
+			middleware.RequireReviewer(cfg.AdminUserIDs, zapLogger),
+		)
+		registerAnnotationRoutes(annotationsGroup, routes.notes)
+
+		// Any user may list the accepted event types; registering one is admin only
+		v1.GET("/event-types", middleware.JWTAuth(tokenValidator, tokenCache, zapLogger), routes.types.ListEventTypes)
+	}
+}
+
+// setupInternalRoutes mounts the routes only other services and operators call: service
+// callbacks and the admin routes
+func setupInternalRoutes(
+	v1 *gin.RouterGroup,
+	cfg *config.Config,
+	tokenValidator jwt.TokenValidator,
+	tokenCache *cache.TokenCache,
+	routes routeHandlers,
+	zapLogger *zap.Logger,
+) {
+	// The export worker authenticates its callbacks with the shared secret they are signed with
+	if cfg.ServesWrites() && cfg.ExportCallbacksEnabled() {
+		v1.POST("/exports/callbacks", routes.exports.ReportExportStep)
+	}
+
+	// Admin routes
+	admin := []gin.HandlerFunc{
+		middleware.JWTAuth(tokenValidator, tokenCache, zapLogger),
+		middleware.RequireAdmin(cfg.AdminUserIDs, zapLogger),
+	}
+	v1.Group("/event-types", admin...).POST("", routes.types.CreateEventType)
+	v1.Group("/users", admin...).DELETE("/:userId/events", routes.erasure.EraseUserEvents)
+	v1.Group("/erasure-jobs", admin...).GET("/:jobId", routes.erasure.GetJob)
+
+	// Imports store events, so they run on the instances that ingest them
+	if cfg.ServesWrites() {
+		v1.Group("/events/import", admin...).POST("", routes.imports.ImportEvents)
+		v1.Group("/import-jobs", admin...).GET("/:jobId", routes.imports.GetJob)
+	}
+
+	// Replays are answered with 409 unless the outbox is configured
+	v1.Group("/replay", admin...).POST("", routes.replays.ReplayEvents)
+	v1.Group("/replay-jobs", admin...).GET("/:jobId", routes.replays.GetJob)
+
+	holdsGroup := v1.Group("/legal-holds", admin...)
+	{
+		holdsGroup.POST("", routes.holds.CreateLegalHold)
+		holdsGroup.GET("", routes.holds.ListLegalHolds)
+		holdsGroup.DELETE("/:holdId", routes.holds.ReleaseLegalHold)
+	}
+
+	revocationsGroup := v1.Group("/revocations", admin...)
+	{
+		revocationsGroup.POST("", routes.revoked.CreateRevocation)
+		revocationsGroup.GET("", routes.revoked.ListRevocations)
+		revocationsGroup.DELETE("/:revocationId", routes.revoked.LiftRevocation)
+	}
+
+	reportsGroup := v1.Group("/reports", admin...)
+	{
+		reportsGroup.POST("", routes.reports.CreateReport)
+		reportsGroup.GET("", routes.reports.ListReports)
+		reportsGroup.GET("/:reportId", routes.reports.GetReport)
+		reportsGroup.DELETE("/:reportId", routes.reports.DeleteReport)
+		reportsGroup.POST("/:reportId/runs", routes.reports.RunReport)
+		reportsGroup.GET("/:reportId/runs", routes.reports.ListReportRuns)
+	}
+
+	adminGroup := v1.Group("/admin", admin...)
+	adminGroup.Use(middleware.Compress(cfg.CompressionMinSize))
+	{
+		adminGroup.GET("/config", routes.config.GetConfig)
+		adminGroup.POST("/retention/run", routes.ops.RunRetention)
+		adminGroup.POST("/outbox/replay", routes.ops.ReplayOutbox)
+		adminGroup.GET("/dlq", routes.dlq.ListDeadLetters)
+		adminGroup.POST("/dlq/:id/retry", routes.dlq.RetryDeadLetter)
+		adminGroup.POST("/rollups/run", routes.ops.RunRollups)
+
+		if cfg.ServesReads() {
+			adminGroup.GET("/events", routes.admin.QueryEvents)
+
+			// Admins read any session through the session handlers
+			adminGroup.GET("/sessions/:sessionId/events/export", routes.export.ExportEvents)
+			adminGroup.GET("/sessions/:sessionId/events/verify", routes.audit.VerifyChain)
+
+			// Reviewers annotate the events of the sessions they investigate
+			registerAnnotationRoutes(adminGroup.Group("/events/:id/annotations"), routes.notes)
+		}
+	}
+}
+
+// registerAnnotationRoutes serves the annotation threads of audit events under group
+func registerAnnotationRoutes(group *gin.RouterGroup, annotations *handlers.AnnotationsHandler) {
+	group.GET("", annotations.ListEventAnnotations)
+	group.POST("", annotations.CreateEventAnnotation)
+	group.GET("/:annotationId", annotations.GetEventAnnotation)
+	group.PUT("/:annotationId", annotations.EditEventAnnotation)
+	group.DELETE("/:annotationId", annotations.DeleteEventAnnotation)
+}
+
+func handleHealth(clk clock.Clock, shuttingDown *atomic.Bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if shuttingDown.Load() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status":  "shutting_down",
+				"service": "audit-service",
+				"time":    clk.Now().Format(time.RFC3339),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "healthy",
+			"service": "audit-service",
+			"version": serviceVersion,
+			"time":    clk.Now().Format(time.RFC3339),
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"audit-service/internal/config"
+	"audit-service/internal/metrics"
+	"audit-service/internal/repository"
+	"audit-service/pkg/cache"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/fieldcrypt"
+
+	"go.uber.org/zap"
+)
+
+// Storage is the storage backend with the repositories layered over it
+type Storage struct {
+	// Store is the backend itself, for the features that keep their own tables
+	Store repository.Storage
+	// Repo seals the details of sensitive event types and caches sessions and queries
+	Repo repository.AuditRepository
+	// RequestRepo is Repo with the storage deadline of API requests
+	RequestRepo repository.AuditRepository
+}
+
+// OpenStorage connects to the backend selected by STORAGE_BACKEND
+func OpenStorage(ctx context.Context, cfg *config.Config, m *metrics.Metrics, clk clock.Clock, logger *zap.Logger) (repository.Storage, error) {
+	store, err := repository.OpenStorage(ctx, cfg, m, clk, logger)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.StorageBackend == repository.BackendSQLite || cfg.StorageBackend == repository.BackendMemory {
+		logger.Warn("using an embedded storage backend, intended for local development only",
+			zap.String("backend", cfg.StorageBackend),
+		)
+	}
+	return store, nil
+}
+
+// NewStorage layers details encryption, the session and query caches and the request deadline
+// over store, as configured
+func NewStorage(store repository.Storage, cfg *config.Config, m *metrics.Metrics, clk clock.Clock, logger *zap.Logger) (*Storage, error) {
+	var repo repository.AuditRepository = store
+
+	// Details of sensitive event types are sealed before they are stored
+	if cfg.DetailsEncryptionEnabled() {
+		var keys fieldcrypt.KeyProvider
+		var err error
+		if cfg.DetailsEncryptionProvider == "kms" {
+			keys, err = fieldcrypt.NewKMS(fieldcrypt.KMSConfig{
+				KeyID:           cfg.DetailsEncryptionKMSKeyID,
+				Region:          cfg.DetailsEncryptionKMSRegion,
+				Endpoint:        cfg.DetailsEncryptionKMSEndpoint,
+				AccessKeyID:     cfg.DetailsEncryptionKMSAccessKeyID,
+				SecretAccessKey: cfg.DetailsEncryptionKMSSecretAccessKey,
+			}, &http.Client{Timeout: cfg.HTTPTimeout}, clk)
+		} else {
+			keys, err = fieldcrypt.NewKeyring(cfg.DetailsEncryptionKeys)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize details encryption: %w", err)
+		}
+		encryptor := fieldcrypt.New(keys, cfg.DetailsEncryptionDataKeyTTL, clk)
+		repo = repository.NewEncryptedRepository(store, encryptor, cfg.DetailsEncryptionTypes, logger)
+		logger.Info("details encryption enabled",
+			zap.String("provider", cfg.DetailsEncryptionProvider),
+			zap.Strings("types", cfg.DetailsEncryptionTypes),
+		)
+	}
+
+	// Session owners checked on each history request are kept in memory for a short while
+	if cfg.SessionCacheEnabled() {
+		sessions := cache.NewLRU[repository.Session](cfg.SessionCacheSize, cfg.SessionCacheTTL, clk)
+		m.RegisterSessionCache(sessions)
+		repo = repository.NewSessionCachingRepository(repo, sessions)
+	}
+
+	// Pages and stats polled by dashboards are answered from memory until their session is written
+	if cfg.QueryCacheEnabled() {
+		results := cache.NewLRU[repository.QueryResult](cfg.QueryCacheSize, cfg.QueryCacheTTL, clk)
+		m.RegisterQueryCache(results)
+		repo = repository.NewQueryCachingRepository(repo, results,
+			cache.NewLRU[uint64](cfg.QueryCacheSize, cfg.QueryCacheTTL, clk))
+	}
+
+	return &Storage{
+		Store: store,
+		Repo:  repo,
+		// Storage calls made for API requests fail with a timeout instead of outliving their client
+		RequestRepo: repository.NewDeadlineRepository(repo, cfg.OperationTimeout()),
+	}, nil
+}
@@ -0,0 +1,378 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"audit-service/internal/anomaly"
+	"audit-service/internal/archive"
+	"audit-service/internal/authaudit"
+	"audit-service/internal/broadcast"
+	"audit-service/internal/bruteforce"
+	"audit-service/internal/config"
+	"audit-service/internal/domain"
+	"audit-service/internal/erasure"
+	"audit-service/internal/eventtypes"
+	"audit-service/internal/handlers"
+	"audit-service/internal/importer"
+	"audit-service/internal/lifecycle"
+	"audit-service/internal/metrics"
+	"audit-service/internal/middleware"
+	"audit-service/internal/notify"
+	"audit-service/internal/outbox"
+	"audit-service/internal/realtime"
+	"audit-service/internal/redact"
+	"audit-service/internal/replay"
+	"audit-service/internal/reports"
+	"audit-service/internal/repository"
+	"audit-service/internal/retention"
+	"audit-service/internal/revocation"
+	"audit-service/internal/rollup"
+	"audit-service/internal/service"
+	"audit-service/internal/siem"
+	"audit-service/internal/viewdedup"
+	"audit-service/internal/writebuffer"
+	"audit-service/pkg/cache"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/jwt"
+	"audit-service/pkg/secrets"
+	"audit-service/pkg/workpool"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// workerDeps are the components the background workers are built on
+type workerDeps struct {
+	storage        *Storage
+	writer         service.Writer
+	reader         service.Reader
+	broker         *broadcast.Broker
+	schemas        *domain.SchemaRegistry
+	redactor       *redact.Redactor
+	eventTypes     *eventtypes.Registry
+	keySet         *jwt.KeySet
+	tokenValidator jwt.TokenValidator
+	tokenCache     *cache.TokenCache
+	redisClient    *redis.Client
+	exportPool     *workpool.Pool
+	clock          clock.Clock
+	metrics        *metrics.Metrics
+	logger         *zap.Logger
+}
+
+// workers are the background jobs of the service, and the handles the routes reach them by.
+// Handles of disabled jobs are nil.
+type workers struct {
+	// eventWriter stores ingested events, through the write buffer when it is enabled
+	eventWriter    service.Writer
+	views          *viewdedup.Deduplicator
+	retention      handlers.RetentionRunner
+	rollups        handlers.RollupRunner
+	erasure        *erasure.Manager
+	imports        *importer.Manager
+	revocations    *revocation.Manager
+	outboxReplayer handlers.OutboxReplayer
+	deadLetters    handlers.DeadLetters
+	replayJobs     handlers.ReplayJobs
+	replays        *replay.Manager
+	watches        *notify.Watches
+	reports        *reports.Service
+	authFailures   []middleware.AuthFailureReporter
+	throttler      middleware.AuthThrottler
+	guard          *bruteforce.Guard
+}
+
+// newWorkers starts the background jobs enabled by cfg and registers their shutdown hooks, in
+// the order they are closed. Jobs that write events only run on instances that serve writes.
+func newWorkers(cfg *config.Config, d workerDeps, shutdown *lifecycle.Shutdown) *workers {
+	w := &workers{}
+	w.startWriters(cfg, d, shutdown)
+	w.startMaintenance(cfg, d, shutdown)
+	w.startOutbox(cfg, d, shutdown)
+	w.startNotifications(cfg, d, shutdown)
+	w.startSecurity(cfg, d, shutdown)
+	w.startRefreshes(cfg, d, shutdown)
+	return w
+}
+
+// startWriters starts the write-behind buffer and the view deduplication
+func (w *workers) startWriters(cfg *config.Config, d workerDeps, shutdown *lifecycle.Shutdown) {
+	// Event writes go through the write-behind buffer when enabled
+	w.eventWriter = d.writer
+	var buffer *writebuffer.Writer
+	if cfg.WriteBufferEnabled && cfg.ServesWrites() {
+		buffer = writebuffer.New(d.writer.CreateEvents, writebuffer.Config{
+			Capacity:      cfg.WriteBufferCapacity,
+			BatchSize:     cfg.WriteBufferBatchSize,
+			FlushInterval: cfg.WriteBufferFlushInterval,
+			FlushTimeout:  cfg.HTTPTimeout,
+			Overflow:      writebuffer.OverflowPolicy(cfg.WriteBufferOverflow),
+		}, d.metrics, d.logger)
+		buffer.Start()
+
+		w.eventWriter = service.NewBufferedWriter(buffer, d.logger)
+	}
+
+	// Repeated identical views are collapsed on ingestion when a deduplication window is set. Held
+	// views are written like other events, and closed before the write buffer.
+	if cfg.ViewDedupWindow > 0 && cfg.ServesWrites() {
+		w.views = viewdedup.New(w.eventWriter.CreateEvents, viewdedup.Config{
+			Window:  cfg.ViewDedupWindow,
+			MaxHeld: cfg.ViewDedupMaxHeld,
+		}, d.clock, d.logger)
+		w.views.Start()
+		shutdown.Register("view deduplication", w.views.Close)
+	}
+	if buffer != nil {
+		shutdown.Register("write buffer", buffer.Close)
+	}
+}
+
+// startMaintenance starts the retention purge, the activity rollups and the erasure, import and
+// revocation jobs
+func (w *workers) startMaintenance(cfg *config.Config, d workerDeps, shutdown *lifecycle.Shutdown) {
+	repo := d.storage.Repo
+
+	// Expired events are deleted in the background when retention periods are configured
+	if len(cfg.RetentionPolicies) > 0 && cfg.ServesWrites() {
+		// Expired events are copied to cold storage first when archival is enabled
+		var archiver retention.Archiver
+		if cfg.ArchiveEnabled {
+			store, err := archive.NewS3Store(archive.S3Config{
+				Endpoint:        cfg.ArchiveS3Endpoint,
+				Region:          cfg.ArchiveS3Region,
+				Bucket:          cfg.ArchiveS3Bucket,
+				AccessKeyID:     cfg.ArchiveS3AccessKeyID,
+				SecretAccessKey: cfg.ArchiveS3SecretAccessKey,
+				PathStyle:       cfg.ArchiveS3PathStyle,
+			}, &http.Client{Timeout: cfg.HTTPTimeout}, d.clock)
+			if err != nil {
+				d.logger.Fatal("failed to initialize archive store", zap.Error(err))
+			}
+			archiver = archive.NewArchiver(store, cfg.ArchivePrefix, d.clock, d.logger)
+		}
+
+		purger := retention.New(repo, archiver, repo.CreateEvents, cfg.RetentionPolicies, retention.Config{
+			Interval:  cfg.RetentionInterval,
+			BatchSize: cfg.RetentionBatchSize,
+		}, d.clock, d.metrics, d.logger)
+		purger.Start()
+		shutdown.Register("retention purge", purger.Close)
+		w.retention = purger
+	}
+
+	// Session activity is served from rollups rebuilt on a schedule
+	if cfg.RollupEnabled && cfg.ServesWrites() {
+		rollups := rollup.New(d.storage.Store, rollup.Config{
+			Interval: cfg.RollupInterval,
+			Lookback: cfg.RollupLookback,
+		}, d.clock, d.metrics, d.logger)
+		rollups.Start()
+		shutdown.Register("activity rollup", rollups.Close)
+		w.rollups = rollups
+	}
+
+	// Data-subject erasure requests run in the background
+	w.erasure = erasure.NewManager(repo, repo.CreateEvents, d.clock, d.logger)
+	shutdown.Register("erasure jobs", w.erasure.Close)
+
+	// Historical events are imported from files in the background; downloads of large files
+	// may take longer than HTTP_TIMEOUT, which only bounds the wait for the response
+	importTransport := http.DefaultTransport.(*http.Transport).Clone()
+	importTransport.ResponseHeaderTimeout = cfg.HTTPTimeout
+	w.imports = importer.NewManager(repo, d.schemas, d.redactor, &http.Client{Transport: importTransport}, importer.Config{
+		MaxFileSize: int64(cfg.ImportMaxFileSizeMB) << 20,
+		TempDir:     cfg.ImportTempDir,
+	}, d.clock, d.metrics, d.logger)
+	shutdown.Register("import jobs", w.imports.Close)
+
+	// Revoked tokens are rejected by the auth middleware through markers in the token cache
+	w.revocations = revocation.NewManager(d.storage.Store, d.tokenCache, cfg.RevocationRefreshInterval, d.clock, d.logger)
+	if err := w.revocations.Refresh(context.Background()); err != nil {
+		// Revocations are loaded again on the next refresh; with Redis the markers set by other replicas apply meanwhile
+		d.logger.Warn("failed to load token revocations", zap.Error(err))
+	}
+	w.revocations.Start()
+	shutdown.Register("revocation refresh", w.revocations.Close)
+}
+
+// startOutbox starts the outbox relay and the replay jobs when webhook destinations are configured
+func (w *workers) startOutbox(cfg *config.Config, d workerDeps, shutdown *lifecycle.Shutdown) {
+	if !cfg.OutboxEnabled() || !cfg.ServesWrites() {
+		return
+	}
+	store := d.storage.Store
+
+	// Stored events are forwarded to webhooks from the outbox
+	relay := outbox.New(store, webhookSink(cfg, cfg.OutboxWebhookURLs, cfg.OutboxWebhookSecret, d.clock), outbox.Config{
+		PollInterval: cfg.OutboxPollInterval,
+		BatchSize:    cfg.OutboxBatchSize,
+		MaxAttempts:  cfg.OutboxMaxAttempts,
+	}, d.clock, d.metrics, d.logger)
+	relay.Start()
+	shutdown.Register("outbox relay", relay.Close)
+	w.outboxReplayer = store
+	w.deadLetters = store
+
+	// Stored events are replayed as stored, so encrypted details stay encrypted like in the outbox
+	webhooks := make(map[string]outbox.Sink, len(cfg.OutboxWebhookURLs))
+	for _, webhookURL := range cfg.OutboxWebhookURLs {
+		webhooks[webhookURL] = webhookSink(cfg, []string{webhookURL}, cfg.OutboxWebhookSecret, d.clock)
+	}
+	w.replays = replay.NewManager(store, replay.Config{
+		Webhooks:  webhooks,
+		MaxRate:   cfg.ReplayMaxRate,
+		MaxEvents: cfg.ReplayMaxEvents,
+	}, d.clock, d.metrics, d.logger)
+	shutdown.Register("replay jobs", w.replays.Close)
+	w.replayJobs = w.replays
+}
+
+// startNotifications starts the anomaly detector, the watch notifier and the report scheduler
+func (w *workers) startNotifications(cfg *config.Config, d workerDeps, shutdown *lifecycle.Shutdown) {
+	store := d.storage.Store
+
+	// Suspicious activity is flagged with security_alert events when anomaly detection is enabled
+	if cfg.AnomalyDetectionEnabled && cfg.ServesWrites() {
+		var notifier outbox.Sink
+		if cfg.AnomalyWebhookURL != "" {
+			notifier = webhookSink(cfg, []string{cfg.AnomalyWebhookURL}, cfg.AnomalyWebhookSecret, d.clock)
+		}
+		detector := anomaly.New(d.broker, d.storage.Repo.CreateEvents, notifier, anomaly.Config{
+			Window:            cfg.AnomalyWindow,
+			DeletionThreshold: cfg.AnomalyDeletionThreshold,
+			ExportThreshold:   cfg.AnomalyExportThreshold,
+			WorkdayStart:      cfg.AnomalyWorkdayStart,
+			WorkdayEnd:        cfg.AnomalyWorkdayEnd,
+			Location:          cfg.AnomalyTimezone,
+		}, d.clock, d.metrics, d.logger)
+		detector.Start()
+		shutdown.Register("anomaly detector", detector.Close)
+	}
+
+	// Events of watched sessions are sent to the notification sink in digests when a sink is configured
+	w.watches = notify.NewWatches(store, d.reader, d.clock, d.logger)
+	if cfg.NotificationsEnabled() && cfg.ServesWrites() {
+		var sink notify.Sink
+		if cfg.NotifySink == "supabase" {
+			sink = notify.NewTableSink(repository.NewSupabaseClient(cfg, d.logger), cfg.NotifyTable)
+		} else {
+			sink = notify.NewWebhookSink(outbox.NewWebhookSink([]string{cfg.NotifyWebhookURL}, cfg.NotifyWebhookSecret,
+				&http.Client{Timeout: cfg.HTTPTimeout}, d.clock))
+		}
+		watchNotifier := notify.New(d.broker, store, sink, notify.Config{
+			DigestInterval:  cfg.NotifyDigestInterval,
+			MaxDigestEvents: cfg.NotifyDigestMaxEvents,
+		}, d.clock, d.metrics, d.logger)
+		watchNotifier.Start()
+		shutdown.Register("watch notifier", watchNotifier.Close)
+	}
+
+	// Recurring reports are generated from stored events by the instances that serve reads
+	deliverers := map[domain.ReportDelivery]reports.Deliverer{
+		domain.ReportWebhook: reports.NewWebhookDeliverer(cfg.ReportsWebhookSecret, &http.Client{Timeout: cfg.HTTPTimeout}, d.clock),
+	}
+	if cfg.ReportStorageEnabled() {
+		deliverers[domain.ReportStorage] = reports.NewStorageDeliverer(cfg.SupabaseURL, cfg.SupabaseServiceRoleKey,
+			cfg.ReportsStorageBucket, &http.Client{Timeout: cfg.HTTPTimeout})
+	}
+	w.reports = reports.New(store, store, deliverers, reports.Config{
+		PollInterval: cfg.ReportsPollInterval,
+		MaxEvents:    cfg.ReportsMaxEvents,
+		Pool:         d.exportPool,
+	}, d.clock, d.metrics, d.logger)
+	if cfg.ReportsEnabled && cfg.ServesReads() {
+		w.reports.Start()
+		shutdown.Register("report scheduler", w.reports.Close)
+	}
+}
+
+// startSecurity starts the reporters of rejected requests: the SIEM export, the auth_failure
+// recorder and the brute-force guard
+func (w *workers) startSecurity(cfg *config.Config, d workerDeps, shutdown *lifecycle.Shutdown) {
+	repo := d.storage.Repo
+
+	// Shares, exports and rejected requests are exported to a SIEM when a syslog endpoint is
+	// configured; rejected requests are reported by every role
+	if cfg.SIEMEnabled() {
+		hostname, _ := os.Hostname()
+		exporter := siem.New(d.broker, siem.Config{
+			Network:  cfg.SIEMSyslogNetwork,
+			Address:  cfg.SIEMSyslogAddress,
+			Format:   siem.Format(cfg.SIEMFormat),
+			Hostname: hostname,
+		}, d.metrics, d.logger)
+		exporter.Start()
+		shutdown.Register("siem exporter", exporter.Close)
+		w.authFailures = append(w.authFailures, exporter)
+	}
+	// Rejected requests are also recorded as auth_failure events of the system session, for admins
+	// to query by the security category
+	if cfg.SystemSessionID != "" {
+		recorder := authaudit.New(cfg.SystemSessionID, repo.CreateEvents, d.clock, d.metrics, d.logger)
+		recorder.Start()
+		shutdown.Register("auth failure recorder", recorder.Close)
+		w.authFailures = append(w.authFailures, recorder)
+	}
+	// Client IPs and users behind repeated rejected requests are throttled; counts are shared
+	// between replicas through Redis when it is configured
+	if cfg.BruteForceEnabled {
+		var counters cache.Counters = cache.NewMemoryCounters(d.clock)
+		if d.redisClient != nil {
+			counters = cache.NewRedisCounters(d.redisClient, "audit-service:bruteforce:", cfg.CacheRedisTimeout)
+		}
+		var record bruteforce.RecordFunc
+		if cfg.SystemSessionID != "" {
+			record = repo.CreateEvents
+		}
+		w.guard = bruteforce.New(counters, cfg.SystemSessionID, record, bruteforce.Config{
+			Threshold:  cfg.BruteForceThreshold,
+			Window:     cfg.BruteForceWindow,
+			Lockout:    cfg.BruteForceLockout,
+			MaxLockout: cfg.BruteForceMaxLockout,
+		}, d.clock, d.metrics, d.logger)
+		w.authFailures = append(w.authFailures, w.guard)
+		w.throttler = w.guard
+	}
+}
+
+// startRefreshes starts the Realtime consumer and the refreshes of the JWKS, the custom event
+// types and the managed secrets
+func (w *workers) startRefreshes(cfg *config.Config, d workerDeps, shutdown *lifecycle.Shutdown) {
+	// Changes to session data made outside the API are recorded when the Realtime consumer is enabled
+	if cfg.RealtimeEnabled && cfg.ServesWrites() {
+		consumer := realtime.New(realtime.Config{
+			URL:         cfg.SupabaseURL,
+			Key:         cfg.SupabaseServiceRoleKey,
+			MatchWindow: cfg.RealtimeMatchWindow,
+		}, repository.NewSupabaseClient(cfg, d.logger), d.storage.Repo, d.storage.Repo.CreateEvents, d.clock, d.metrics, d.logger)
+		consumer.Start()
+		shutdown.Register("realtime consumer", consumer.Close)
+	}
+	if d.keySet != nil {
+		d.keySet.Start()
+		shutdown.Register("jwks refresh", d.keySet.Close)
+	}
+	if cfg.EventTypesRefreshInterval > 0 {
+		d.eventTypes.Start()
+		shutdown.Register("event type refresh", d.eventTypes.Close)
+	}
+
+	// Rotated Supabase credentials are read from the secrets manager without a restart. The report
+	// uploads and the Realtime consumer keep the key they started with until the next restart.
+	if cfg.SecretsManagerEnabled() && cfg.SecretsRefreshInterval > 0 {
+		rotator, _ := d.tokenValidator.(jwt.SecretRotator)
+		refresher := secrets.NewRefresher(cfg.NewSecretsProvider(d.clock), cfg.ManagedSecrets, cfg.SecretsRefreshInterval, func(values map[string]string) {
+			if key := values[config.SecretServiceRoleKey]; key != "" {
+				d.storage.Store.SetServiceRoleKey(key)
+			}
+			if secret := values[config.SecretJWTSecret]; secret != "" && rotator != nil {
+				rotator.RotateSecret(secret)
+			}
+		}, d.logger)
+		refresher.Start()
+		shutdown.Register("secrets refresh", refresher.Close)
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/requestid"
+
+	"go.uber.org/zap"
+)
+
+// EventQueue accepts audit entries for asynchronous persistence
+type EventQueue interface {
+	Enqueue(ctx context.Context, entries []domain.AuditEntry) error
+}
+
+// bufferedWriter queues event writes instead of persisting them within the request
+type bufferedWriter struct {
+	queue  EventQueue
+	logger *zap.Logger
+}
+
+// NewBufferedWriter creates a Writer that hands created events to a write-behind queue, which
+// persists them through the Writer it was created with
+func NewBufferedWriter(queue EventQueue, logger *zap.Logger) Writer {
+	return &bufferedWriter{
+		queue:  queue,
+		logger: logger,
+	}
+}
+
+// CreateEvent queues a single audit entry
+func (s *bufferedWriter) CreateEvent(ctx context.Context, entry domain.AuditEntry) error {
+	return s.enqueue(ctx, []domain.AuditEntry{entry})
+}
+
+// CreateEvents queues a batch of audit entries
+func (s *bufferedWriter) CreateEvents(ctx context.Context, entries []domain.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return s.enqueue(ctx, entries)
+}
+
+// enqueue hands entries to the queue, reporting a full or closed queue as unavailable
+func (s *bufferedWriter) enqueue(ctx context.Context, entries []domain.AuditEntry) error {
+	if err := s.queue.Enqueue(ctx, entries); err != nil {
+		s.logger.Warn("failed to queue audit events",
+			requestid.Field(ctx),
+			zap.Int("count", len(entries)),
+			zap.Error(err),
+		)
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("%w: %v", domain.ErrTimeout, err)
+		}
+		return fmt.Errorf("%w: %v", domain.ErrServiceUnavailable, err)
+	}
+
+	s.logger.Debug("audit events queued", requestid.Field(ctx), zap.Int("count", len(entries)))
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"audit-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// stubQueue records enqueued entries
+type stubQueue struct {
+	entries []domain.AuditEntry
+	err     error
+}
+
+func (q *stubQueue) Enqueue(ctx context.Context, entries []domain.AuditEntry) error {
+	if q.err != nil {
+		return q.err
+	}
+	q.entries = append(q.entries, entries...)
+	return nil
+}
+
+func TestBufferedWriter_CreateEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		queueErr error
+		entries  []domain.AuditEntry
+		wantErr  error
+		wantLen  int
+	}{
+		{
+			name:    "queues entries",
+			entries: createSampleAuditEntries(),
+			wantLen: 2,
+		},
+		{
+			name:    "empty batch",
+			entries: nil,
+		},
+		{
+			name:     "full queue is unavailable",
+			queueErr: errors.New("write buffer is full"),
+			entries:  createSampleAuditEntries(),
+			wantErr:  domain.ErrServiceUnavailable,
+		},
+		{
+			name:     "deadline while waiting is a timeout",
+			queueErr: context.DeadlineExceeded,
+			entries:  createSampleAuditEntries(),
+			wantErr:  domain.ErrTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := &stubQueue{err: tt.queueErr}
+			svc := NewBufferedWriter(queue, zap.NewNop())
+
+			err := svc.CreateEvents(context.Background(), tt.entries)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Len(t, queue.entries, tt.wantLen)
+		})
+	}
+}
+
+func TestBufferedWriter_CreateEvent(t *testing.T) {
+	queue := &stubQueue{}
+	svc := NewBufferedWriter(queue, zap.NewNop())
+
+	entry := createSampleAuditEntries()[0]
+	assert.NoError(t, svc.CreateEvent(context.Background(), entry))
+	assert.Equal(t, []domain.AuditEntry{entry}, queue.entries)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/repository"
+	"audit-service/pkg/requestid"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultConsistencyWait is how long a read made with a consistency token waits for its write
+	DefaultConsistencyWait = 5 * time.Second
+
+	// maxConsistencyTokenAge is the age past which a token is taken as satisfied: by then its write
+	// was flushed from the write buffer or dropped, and cached queries older than it have expired
+	maxConsistencyTokenAge = time.Minute
+
+	// Polls for the write of a token start fast and back off up to the maximum
+	consistencyPollInterval    = 20 * time.Millisecond
+	maxConsistencyPollInterval = 500 * time.Millisecond
+)
+
+// consistencyTokenKey carries the consistency token of a read in its context
+type consistencyTokenKey struct{}
+
+// WithConsistencyToken returns a copy of ctx whose history reads see the write of the token,
+// waiting for it when it is still buffered or on its way to storage
+func WithConsistencyToken(ctx context.Context, token *domain.ConsistencyToken) context.Context {
+	return context.WithValue(ctx, consistencyTokenKey{}, token)
+}
+
+// awaitWrite waits until the write of the consistency token of ctx is readable, and returns the
+// context to read with, which skips cached queries when there is a token to honor
+func (s *reader) awaitWrite(ctx context.Context) (context.Context, error) {
+	token, _ := ctx.Value(consistencyTokenKey{}).(*domain.ConsistencyToken)
+	if token == nil || s.clock.Now().Sub(token.IssuedAt) > maxConsistencyTokenAge {
+		return ctx, nil
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, s.consistencyWait)
+	defer cancel()
+
+	for delay := consistencyPollInterval; ; delay = min(2*delay, maxConsistencyPollInterval) {
+		_, err := s.repo.FindEvent(waitCtx, domain.EventID(token.EventID))
+		if err == nil {
+			return repository.WithFreshReads(ctx), nil
+		}
+		if !errors.Is(err, domain.ErrEventNotFound) && waitCtx.Err() == nil {
+			return nil, fmt.Errorf("failed to look up the write of the consistency token: %w", err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-waitCtx.Done():
+			timer.Stop()
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			s.logger.Warn("write of consistency token not readable in time",
+				requestid.Field(ctx),
+				zap.String("event_id", token.EventID),
+				zap.Duration("wait", s.consistencyWait),
+			)
+			return nil, domain.ErrWriteNotVisible
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/mocks"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestReader_ConsistencyToken(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	const eventID = "550e8400-e29b-41d4-a716-446655440000"
+	token := &domain.ConsistencyToken{EventID: eventID, IssuedAt: now.Add(-time.Second)}
+	page := domain.PaginationParams{Limit: 10}
+
+	t.Run("waits_for_the_write", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		reader := NewReader(mockRepo, nil, ReaderConfig{}, clock.NewFakeClock(now), zap.NewNop())
+
+		// The write is still buffered on the first look
+		mockRepo.On("FindEvent", mock.Anything, domain.EventID(eventID)).Return(nil, domain.ErrEventNotFound).Once()
+		mockRepo.On("FindEvent", mock.Anything, domain.EventID(eventID)).Return(&domain.AuditEntry{ID: eventID}, nil).Once()
+		mockRepo.On("FindBySessionID", mock.Anything, domain.SessionID(testSessionID), page).
+			Return([]domain.AuditEntry{{ID: eventID, SessionID: testSessionID}}, 1, nil)
+
+		ctx := WithConsistencyToken(context.Background(), token)
+		response, err := reader.GetAuditLogs(ctx, testSessionID, testUserID, true, page)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, response.TotalCount)
+	})
+
+	t.Run("gives_up_after_the_wait", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		reader := NewReader(mockRepo, nil, ReaderConfig{ConsistencyWait: 50 * time.Millisecond}, clock.NewFakeClock(now), zap.NewNop())
+
+		mockRepo.On("FindEvent", mock.Anything, domain.EventID(eventID)).Return(nil, domain.ErrEventNotFound)
+
+		ctx := WithConsistencyToken(context.Background(), token)
+		_, err := reader.QueryEvents(ctx, testSessionID, testUserID, true, domain.EventFilter{}, page)
+
+		assert.ErrorIs(t, err, domain.ErrWriteNotVisible)
+		mockRepo.AssertNotCalled(t, "QueryEvents", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("old_tokens_are_satisfied", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		reader := NewReader(mockRepo, nil, ReaderConfig{}, clock.NewFakeClock(now.Add(time.Hour)), zap.NewNop())
+
+		expected := &domain.SessionStats{SessionID: testSessionID, TotalEvents: 3}
+		mockRepo.On("GetSessionStats", mock.Anything, domain.SessionID(testSessionID)).Return(expected, nil)
+
+		ctx := WithConsistencyToken(context.Background(), token)
+		stats, err := reader.GetSessionStats(ctx, testSessionID, "", true)
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, stats)
+	})
+}
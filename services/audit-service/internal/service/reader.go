@@ -0,0 +1,934 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/repository"
+	"audit-service/internal/retention"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/requestid"
+	"audit-service/pkg/workpool"
+
+	"go.uber.org/zap"
+)
+
+// Reader serves the audit history: queries, stats, chain verification and exports. It is
+// split from Writer so heavy reads can be tuned, and deployed, apart from ingestion.
+type Reader interface {
+	GetAuditLogs(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, pagination domain.PaginationParams) (*domain.AuditResponse, error)
+	QueryEvents(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, filter domain.EventFilter, pagination domain.PaginationParams) (*domain.AuditResponse, error)
+	QueryAllEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, pagination domain.PaginationParams) (*domain.AuditResponse, error)
+	GetSessionStats(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool) (*domain.SessionStats, error)
+	GetSessionActivity(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, query domain.ActivityQuery) (*domain.SessionActivity, error)
+	GetSessionHeatmap(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, query domain.HeatmapQuery) (*domain.SessionHeatmap, error)
+	GetSessionContributors(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, query domain.ContributorQuery) (*domain.SessionContributors, error)
+	GetTMLeverage(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, query domain.TMLeverageQuery) (*domain.SessionTMLeverage, error)
+	GetMTCosts(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, query domain.MTCostQuery) (*domain.SessionMTCosts, error)
+	GetAccessReview(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, query domain.AccessReviewQuery) (*domain.AccessReview, error)
+	GetSessionTimeline(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, query domain.TimelineQuery) (*domain.SessionTimeline, error)
+	ExportEvents(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, filter domain.EventFilter, maxRows int, emit func(entries []domain.AuditEntry, total int) error) error
+	VerifyChain(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool) (*domain.ChainVerification, error)
+	GetEventChain(ctx context.Context, eventID domain.EventID, userID domain.UserID) (*domain.EventChain, error)
+	GetRevertPayload(ctx context.Context, sessionID domain.SessionID, eventID domain.EventID, userID domain.UserID, isShareToken bool) (*domain.RevertPayload, error)
+	GetEditDiff(ctx context.Context, sessionID domain.SessionID, eventID domain.EventID, userID domain.UserID, isShareToken bool) (*domain.EditDiff, error)
+	AuthorizeSession(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool) error
+}
+
+const (
+	// DefaultExportPageSize is the number of entries fetched per storage request during exports
+	DefaultExportPageSize = 500
+
+	// verifyPageSize is the number of chained entries fetched per storage request during verification
+	verifyPageSize = 1000
+
+	// maxEventChainLength caps the correlated events and ancestors returned for an event chain
+	maxEventChainLength = 100
+)
+
+// ReaderConfig tunes the reads that walk whole session histories
+type ReaderConfig struct {
+	// ExportPageSize is the number of entries fetched per storage request during exports
+	ExportPageSize int
+	// Pool runs the exports, chain verifications and reports counted from event details, which
+	// are bounded in number so they cannot starve ingestion of storage connections and
+	// goroutines; further ones queue for a slot. Nil is unlimited.
+	Pool *workpool.Pool
+	// Taxonomy classifies the entries read; nil uses the default taxonomy
+	Taxonomy *domain.Taxonomy
+	// ConsistencyWait is how long a history read made with a consistency token waits for the
+	// write of the token to become readable; zero uses DefaultConsistencyWait
+	ConsistencyWait time.Duration
+}
+
+// reader implements the Reader interface
+type reader struct {
+	repo     repository.AuditRepository
+	schemas  *domain.SchemaRegistry
+	taxonomy *domain.Taxonomy
+	clock    clock.Clock
+	logger   *zap.Logger
+
+	exportPageSize  int
+	consistencyWait time.Duration
+	// pool holds a slot per running export, verification or counted report
+	pool *workpool.Pool
+}
+
+// NewReader creates the read side of the audit service. Entries read through it are upgraded to
+// the current details schema version of schemas; a nil registry returns them as stored.
+func NewReader(repo repository.AuditRepository, schemas *domain.SchemaRegistry, cfg ReaderConfig, clk clock.Clock, logger *zap.Logger) Reader {
+	r := &reader{
+		repo:            repo,
+		schemas:         schemas,
+		taxonomy:        cfg.Taxonomy,
+		clock:           clk,
+		logger:          logger,
+		exportPageSize:  cfg.ExportPageSize,
+		consistencyWait: cfg.ConsistencyWait,
+		pool:            cfg.Pool,
+	}
+	if r.exportPageSize <= 0 {
+		r.exportPageSize = DefaultExportPageSize
+	}
+	if r.consistencyWait <= 0 {
+		r.consistencyWait = DefaultConsistencyWait
+	}
+	if r.taxonomy == nil {
+		r.taxonomy = domain.DefaultTaxonomy()
+	}
+	if r.pool == nil {
+		r.pool = workpool.New(0)
+	}
+	return r
+}
+
+// acquireExportSlot waits for a free slot in the pool; the returned func releases it
+func (s *reader) acquireExportSlot(ctx context.Context) (func(), error) {
+	release, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: waiting for an export slot: %v", domain.ErrServiceUnavailable, err)
+	}
+	return release, nil
+}
+
+// GetAuditLogs retrieves audit logs for a session with permission validation
+func (s *reader) GetAuditLogs(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, pagination domain.PaginationParams) (*domain.AuditResponse, error) {
+	// Validate pagination
+	pagination.Validate()
+
+	// If not using share token, validate ownership
+	if !isShareToken {
+		if err := s.validateOwnership(ctx, sessionID, userID); err != nil {
+			return nil, err
+		}
+	}
+	// Share token validation is already done in the auth middleware
+
+	ctx, err := s.awaitWrite(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch audit logs
+	entries, totalCount, err := s.repo.FindBySessionID(ctx, sessionID, pagination)
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		s.logger.Error("failed to fetch audit logs",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Stringer("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch audit logs: %w", err)
+	}
+
+	// Build response
+	response := &domain.AuditResponse{
+		TotalCount: totalCount,
+		Items:      entriesForReader(s.prepareEntries(ctx, entries), isShareToken),
+		NextCursor: nextCursor(entries, pagination.Limit),
+	}
+
+	s.logger.Info("audit logs retrieved",
+		requestid.Field(ctx),
+		zap.Stringer("session_id", sessionID),
+		zap.Stringer("user_id", userID),
+		zap.Int("count", len(entries)),
+		zap.Int("total", totalCount),
+		zap.Bool("share_token", isShareToken),
+	)
+
+	return response, nil
+}
+
+// QueryEvents retrieves filtered audit logs for a session with permission validation
+func (s *reader) QueryEvents(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, filter domain.EventFilter, pagination domain.PaginationParams) (*domain.AuditResponse, error) {
+	pagination.Validate()
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	if !isShareToken {
+		if err := s.validateOwnership(ctx, sessionID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	filter, ok := s.classifiedFilter(filter)
+	if !ok {
+		return &domain.AuditResponse{Items: []domain.AuditEntry{}}, nil
+	}
+
+	ctx, err := s.awaitWrite(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, totalCount, err := s.repo.QueryEvents(ctx, sessionID, filter, pagination)
+	if err != nil {
+		s.logger.Error("failed to query audit events",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Stringer("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to query audit events: %w", err)
+	}
+
+	s.logger.Info("audit events queried",
+		requestid.Field(ctx),
+		zap.Stringer("session_id", sessionID),
+		zap.Stringer("user_id", userID),
+		zap.Strings("types", filter.Types),
+		zap.Int("count", len(entries)),
+		zap.Int("total", totalCount),
+	)
+
+	return &domain.AuditResponse{
+		TotalCount: totalCount,
+		Items:      entriesForReader(s.prepareEntries(ctx, entries), isShareToken),
+		NextCursor: nextCursor(entries, pagination.Limit),
+	}, nil
+}
+
+// QueryAllEvents retrieves filtered audit logs across all sessions, or of one session when sessionID
+// is set, without ownership checks. Only admin routes may call it.
+func (s *reader) QueryAllEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, pagination domain.PaginationParams) (*domain.AuditResponse, error) {
+	pagination.Validate()
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	filter, ok := s.classifiedFilter(filter)
+	if !ok {
+		return &domain.AuditResponse{Items: []domain.AuditEntry{}}, nil
+	}
+
+	entries, totalCount, err := s.repo.QueryEvents(ctx, sessionID, filter, pagination)
+	if err != nil {
+		s.logger.Error("failed to query audit events across sessions",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to query audit events: %w", err)
+	}
+
+	s.logger.Info("audit events queried across sessions",
+		requestid.Field(ctx),
+		zap.Stringer("session_id", sessionID),
+		zap.String("filter_user_id", filter.UserID),
+		zap.Strings("types", filter.Types),
+		zap.Int("count", len(entries)),
+		zap.Int("total", totalCount),
+	)
+
+	return &domain.AuditResponse{
+		TotalCount: totalCount,
+		Items:      s.prepareEntries(ctx, entries),
+		NextCursor: nextCursor(entries, pagination.Limit),
+	}, nil
+}
+
+// GetSessionStats returns aggregated audit statistics for a session with permission validation
+func (s *reader) GetSessionStats(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool) (*domain.SessionStats, error) {
+	if err := s.AuthorizeSession(ctx, sessionID, userID, isShareToken); err != nil {
+		return nil, err
+	}
+
+	ctx, err := s.awaitWrite(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.repo.GetSessionStats(ctx, sessionID)
+	if err != nil {
+		s.logger.Error("failed to fetch session stats",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Stringer("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch session stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetSessionActivity returns the rolled-up activity of a session with permission validation.
+// Activity recorded since the last rollup run is not counted yet.
+func (s *reader) GetSessionActivity(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, query domain.ActivityQuery) (*domain.SessionActivity, error) {
+	if err := query.Normalize(s.clock.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.AuthorizeSession(ctx, sessionID, userID, isShareToken); err != nil {
+		return nil, err
+	}
+
+	buckets, err := s.repo.GetSessionActivity(ctx, sessionID, query)
+	if err != nil {
+		s.logger.Error("failed to fetch session activity",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Stringer("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch session activity: %w", err)
+	}
+
+	return &domain.SessionActivity{
+		SessionID:   sessionID.String(),
+		Granularity: query.Granularity,
+		From:        query.From,
+		To:          query.To,
+		Buckets:     buckets,
+	}, nil
+}
+
+// GetSessionHeatmap returns the hourly event counts per slide of a session with permission
+// validation. Unlike activity, the counts are aggregated from the events on every request.
+func (s *reader) GetSessionHeatmap(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, query domain.HeatmapQuery) (*domain.SessionHeatmap, error) {
+	if err := query.Normalize(s.clock.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.AuthorizeSession(ctx, sessionID, userID, isShareToken); err != nil {
+		return nil, err
+	}
+
+	cells, err := s.repo.GetSlideHeatmap(ctx, sessionID, query)
+	if err != nil {
+		s.logger.Error("failed to fetch slide heatmap",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Stringer("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch slide heatmap: %w", err)
+	}
+
+	return domain.NewSessionHeatmap(sessionID.String(), query, cells), nil
+}
+
+// GetSessionContributors returns the per-user contribution to a session with permission
+// validation. Events written by the service itself are not a contribution and are left out.
+func (s *reader) GetSessionContributors(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, query domain.ContributorQuery) (*domain.SessionContributors, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.AuthorizeSession(ctx, sessionID, userID, isShareToken); err != nil {
+		return nil, err
+	}
+
+	stats, err := s.repo.GetSessionContributors(ctx, sessionID, query)
+	if err != nil {
+		s.logger.Error("failed to fetch session contributors",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Stringer("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch session contributors: %w", err)
+	}
+
+	report := &domain.SessionContributors{SessionID: sessionID.String(), Contributors: make([]domain.ContributorStats, 0, len(stats))}
+	if !query.From.IsZero() {
+		report.From = &query.From
+	}
+	if !query.To.IsZero() {
+		report.To = &query.To
+	}
+	for _, contributor := range stats {
+		if contributor.UserID != retention.SystemUserID {
+			report.Contributors = append(report.Contributors, contributor)
+		}
+	}
+	return report, nil
+}
+
+// GetTMLeverage counts the translation memory events of a session with permission validation
+func (s *reader) GetTMLeverage(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, query domain.TMLeverageQuery) (*domain.SessionTMLeverage, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.AuthorizeSession(ctx, sessionID, userID, isShareToken); err != nil {
+		return nil, err
+	}
+
+	filter := domain.EventFilter{From: query.From, To: query.To}
+	for _, action := range domain.TMActions {
+		filter.Types = append(filter.Types, string(action))
+	}
+
+	tally := domain.NewTMLeverageTally(sessionID.String(), query)
+	truncated, err := s.countEvents(ctx, sessionID, filter, domain.MaxTMLeverageEvents, tally.Add)
+	if err != nil {
+		return nil, err
+	}
+
+	report := tally.Report()
+	report.Truncated = truncated
+	return report, nil
+}
+
+// GetMTCosts rolls up the machine translation requests of a session with permission validation
+func (s *reader) GetMTCosts(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, query domain.MTCostQuery) (*domain.SessionMTCosts, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.AuthorizeSession(ctx, sessionID, userID, isShareToken); err != nil {
+		return nil, err
+	}
+
+	filter := domain.EventFilter{Types: []string{string(domain.ActionMTRequest)}, From: query.From, To: query.To}
+	tally := domain.NewMTCostTally(sessionID.String(), query)
+	truncated, err := s.countEvents(ctx, sessionID, filter, domain.MaxMTCostEvents, tally.Add)
+	if err != nil {
+		return nil, err
+	}
+
+	report := tally.Report()
+	report.Truncated = truncated
+	return report, nil
+}
+
+// GetAccessReview snapshots who viewed a session, how and when. It lists the users and addresses
+// of everyone who opened the session, so only its owner and admins may take one.
+func (s *reader) GetAccessReview(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, query domain.AccessReviewQuery) (*domain.AccessReview, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.AuthorizeSession(ctx, sessionID, userID, false); err != nil {
+		return nil, err
+	}
+
+	filter := domain.EventFilter{Types: []string{string(domain.ActionView)}, From: query.From, To: query.To}
+	tally := domain.NewAccessReviewTally(sessionID.String(), query)
+	truncated, err := s.countEvents(ctx, sessionID, filter, domain.MaxAccessReviewEvents, tally.Add)
+	if err != nil {
+		return nil, err
+	}
+
+	review := tally.Report(s.clock.Now().UTC())
+	review.Truncated = truncated
+
+	s.logger.Info("access review generated",
+		requestid.Field(ctx),
+		zap.Stringer("session_id", sessionID),
+		zap.Stringer("user_id", userID),
+		zap.Int("accessors", len(review.Accessors)),
+		zap.Int("views", review.TotalViews),
+	)
+	return review, nil
+}
+
+// GetSessionTimeline merges the events of a session into its compacted timeline with permission
+// validation. The newest MaxTimelineEvents events of the range are merged.
+func (s *reader) GetSessionTimeline(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, query domain.TimelineQuery) (*domain.SessionTimeline, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.AuthorizeSession(ctx, sessionID, userID, isShareToken); err != nil {
+		return nil, err
+	}
+
+	filter := domain.EventFilter{From: query.From, To: query.To}
+	builder := domain.NewTimelineBuilder(sessionID.String(), query)
+	truncated, err := s.countEvents(ctx, sessionID, filter, domain.MaxTimelineEvents, builder.Add)
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := builder.Report()
+	timeline.Truncated = truncated
+	return timeline, nil
+}
+
+// countEvents passes the events of a session matching filter to add, page by page, up to
+// maxEvents of them; truncated reports whether the session had more. Details may be encrypted
+// at rest, so reports over them are counted here rather than in storage, and take an export slot.
+func (s *reader) countEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, maxEvents int, add func(domain.AuditEntry)) (truncated bool, err error) {
+	release, err := s.acquireExportSlot(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	page := domain.PaginationParams{Limit: min(s.exportPageSize, maxEvents)}
+	total := -1
+	counted := 0
+	for {
+		// A client that disconnected stops the walk before another page is read
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		entries, count, err := s.repo.QueryEvents(ctx, sessionID, filter, page)
+		if err != nil {
+			s.logger.Error("failed to count audit events",
+				requestid.Field(ctx),
+				zap.Stringer("session_id", sessionID),
+				zap.Strings("types", filter.Types),
+				zap.Int("counted", counted),
+				zap.Error(err),
+			)
+			return false, fmt.Errorf("failed to count audit events: %w", err)
+		}
+
+		// Later pages count only the entries after the cursor
+		if total < 0 {
+			total = count
+		}
+
+		for _, entry := range s.prepareEntries(ctx, entries) {
+			add(entry)
+		}
+		counted += len(entries)
+
+		if len(entries) < page.Limit || counted >= maxEvents {
+			break
+		}
+		page.Cursor = domain.NewCursor(entries[len(entries)-1])
+		page.Limit = min(s.exportPageSize, maxEvents-counted)
+	}
+	return total > counted, nil
+}
+
+// VerifyChain recomputes the hash chain of a session and reports every entry that fails to link
+func (s *reader) VerifyChain(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool) (*domain.ChainVerification, error) {
+	if err := s.AuthorizeSession(ctx, sessionID, userID, isShareToken); err != nil {
+		return nil, err
+	}
+
+	release, err := s.acquireExportSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	verifier := domain.NewChainVerifier(sessionID.String())
+	var afterSeq int64
+	for {
+		// A client that disconnected stops the walk before another page is read
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		entries, err := s.repo.FindChain(ctx, sessionID, afterSeq, verifyPageSize)
+		if err != nil {
+			s.logger.Error("failed to fetch audit log chain",
+				requestid.Field(ctx),
+				zap.Stringer("session_id", sessionID),
+				zap.Int64("after_seq", afterSeq),
+				zap.Error(err),
+			)
+			return nil, fmt.Errorf("failed to verify audit log chain: %w", err)
+		}
+
+		for _, entry := range entries {
+			verifier.Add(entry)
+		}
+
+		if len(entries) < verifyPageSize {
+			break
+		}
+		afterSeq = entries[len(entries)-1].Seq
+	}
+
+	result := verifier.Result()
+	if !result.Valid {
+		s.logger.Warn("audit log chain verification failed",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Stringer("user_id", userID),
+			zap.Int("issues", len(result.Issues)),
+		)
+	}
+
+	return result, nil
+}
+
+// GetEventChain returns the causally related events of an event: every event of its session
+// sharing its correlation ID, plus the ancestors reached through parent links. Only the
+// session owner may read it.
+func (s *reader) GetEventChain(ctx context.Context, eventID domain.EventID, userID domain.UserID) (*domain.EventChain, error) {
+	entry, err := s.repo.FindEvent(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, domain.ErrEventNotFound) {
+			return nil, err
+		}
+		s.logger.Error("failed to fetch audit event",
+			requestid.Field(ctx),
+			zap.Stringer("event_id", eventID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch audit event: %w", err)
+	}
+
+	if err := s.validateOwnership(ctx, domain.SessionID(entry.SessionID), userID); err != nil {
+		return nil, err
+	}
+
+	chain := map[string]domain.AuditEntry{entry.ID: *entry}
+	if entry.CorrelationID != "" {
+		correlated, _, err := s.repo.QueryEvents(ctx, domain.SessionID(entry.SessionID),
+			domain.EventFilter{CorrelationID: entry.CorrelationID},
+			domain.PaginationParams{Limit: maxEventChainLength})
+		if err != nil {
+			s.logger.Error("failed to fetch correlated audit events",
+				requestid.Field(ctx),
+				zap.Stringer("event_id", eventID),
+				zap.String("correlation_id", entry.CorrelationID),
+				zap.Error(err),
+			)
+			return nil, fmt.Errorf("failed to fetch correlated audit events: %w", err)
+		}
+		for _, e := range correlated {
+			chain[e.ID] = e
+		}
+	}
+
+	// Ancestors may predate the correlation ID or lack one; the walk is bounded so a cycle
+	// of parent links cannot loop forever
+	parentID := entry.ParentEventID
+	for steps := 0; parentID != "" && steps < maxEventChainLength; steps++ {
+		if parent, ok := chain[parentID]; ok {
+			parentID = parent.ParentEventID
+			continue
+		}
+
+		parent, err := s.repo.FindEvent(ctx, domain.EventID(parentID))
+		if errors.Is(err, domain.ErrEventNotFound) {
+			// Purged by retention or never recorded
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch parent audit event: %w", err)
+		}
+		// Parent links never cross into sessions the caller was not authorized for
+		if parent.SessionID != entry.SessionID {
+			break
+		}
+		chain[parent.ID] = *parent
+		parentID = parent.ParentEventID
+	}
+
+	items := make([]domain.AuditEntry, 0, len(chain))
+	for _, e := range chain {
+		items = append(items, e)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].Timestamp.Equal(items[j].Timestamp) {
+			return items[i].Timestamp.Before(items[j].Timestamp)
+		}
+		return items[i].ID < items[j].ID
+	})
+
+	return &domain.EventChain{
+		EventID:       entry.ID,
+		CorrelationID: entry.CorrelationID,
+		Items:         s.prepareEntries(ctx, items),
+	}, nil
+}
+
+// GetRevertPayload returns the edit that undoes an edit event of the session. Reverting changes
+// the session, so share-link reviewers, who cannot edit, are refused.
+func (s *reader) GetRevertPayload(ctx context.Context, sessionID domain.SessionID, eventID domain.EventID, userID domain.UserID, isShareToken bool) (*domain.RevertPayload, error) {
+	if isShareToken {
+		return nil, domain.ErrForbidden
+	}
+	if err := s.AuthorizeSession(ctx, sessionID, userID, false); err != nil {
+		return nil, err
+	}
+
+	entry, err := s.sessionEvent(ctx, sessionID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.InverseEdit(*entry)
+}
+
+// GetEditDiff returns the diff of the text replaced by an edit event of the session, rendered for
+// display. Share-link reviewers may read it, as they may read the edit itself.
+func (s *reader) GetEditDiff(ctx context.Context, sessionID domain.SessionID, eventID domain.EventID, userID domain.UserID, isShareToken bool) (*domain.EditDiff, error) {
+	if err := s.AuthorizeSession(ctx, sessionID, userID, isShareToken); err != nil {
+		return nil, err
+	}
+
+	entry, err := s.sessionEvent(ctx, sessionID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.RenderEditDiff(*entry)
+}
+
+// sessionEvent returns an event of the session, upgraded to the current schema version of its type
+func (s *reader) sessionEvent(ctx context.Context, sessionID domain.SessionID, eventID domain.EventID) (*domain.AuditEntry, error) {
+	entry, err := s.repo.FindEvent(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, domain.ErrEventNotFound) {
+			return nil, err
+		}
+		s.logger.Error("failed to fetch audit event",
+			requestid.Field(ctx),
+			zap.Stringer("event_id", eventID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch audit event: %w", err)
+	}
+	// Events of other sessions are not revealed through this session
+	if entry.SessionID != sessionID.String() {
+		return nil, domain.ErrEventNotFound
+	}
+
+	upgraded := s.prepareEntries(ctx, []domain.AuditEntry{*entry})
+	return &upgraded[0], nil
+}
+
+// ExportEvents walks every entry matching the filter, newest first, and hands them to emit page
+// by page together with the total number of matches, which exceeds maxRows when the export is
+// capped. emit is called at least once, so callers can write headers even for empty exports.
+func (s *reader) ExportEvents(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool, filter domain.EventFilter, maxRows int, emit func(entries []domain.AuditEntry, total int) error) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.AuthorizeSession(ctx, sessionID, userID, isShareToken); err != nil {
+		return err
+	}
+
+	release, err := s.acquireExportSlot(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	filter, ok := s.classifiedFilter(filter)
+	if !ok {
+		return emit(nil, 0)
+	}
+
+	page := domain.PaginationParams{Limit: min(s.exportPageSize, maxRows)}
+	total := -1
+	exported := 0
+	for {
+		// Browsers give up on long exports; stop reading storage for a client that is gone
+		if err := ctx.Err(); err != nil {
+			s.logger.Info("audit export cancelled",
+				requestid.Field(ctx),
+				zap.Stringer("session_id", sessionID),
+				zap.Int("exported", exported),
+				zap.Error(err),
+			)
+			return err
+		}
+
+		entries, count, err := s.repo.QueryEvents(ctx, sessionID, filter, page)
+		if err != nil {
+			if ctx.Err() != nil {
+				// Reported as a cancellation at the top of the loop
+				continue
+			}
+			s.logger.Error("failed to export audit events",
+				requestid.Field(ctx),
+				zap.Stringer("session_id", sessionID),
+				zap.Int("exported", exported),
+				zap.Error(err),
+			)
+			return fmt.Errorf("failed to export audit events: %w", err)
+		}
+
+		// Later pages count only the entries after the cursor
+		if total < 0 {
+			total = count
+		}
+
+		if err := emit(entriesForReader(s.prepareEntries(ctx, entries), isShareToken), total); err != nil {
+			return err
+		}
+		exported += len(entries)
+
+		if len(entries) < page.Limit || exported >= maxRows {
+			break
+		}
+		page.Cursor = domain.NewCursor(entries[len(entries)-1])
+		page.Limit = min(s.exportPageSize, maxRows-exported)
+	}
+
+	s.logger.Info("audit events exported",
+		requestid.Field(ctx),
+		zap.Stringer("session_id", sessionID),
+		zap.Stringer("user_id", userID),
+		zap.Int("exported", exported),
+		zap.Int("total", total),
+	)
+
+	return nil
+}
+
+// prepareEntries classifies entries in the taxonomy, restores the texts of edits stored as a
+// diff and converts them to the current details schema version of their action. Entries that
+// cannot be converted are returned as stored, so one malformed payload does not hide the rest of
+// a history.
+func (s *reader) prepareEntries(ctx context.Context, entries []domain.AuditEntry) []domain.AuditEntry {
+	for i, entry := range entries {
+		class := s.taxonomy.Classify(s.eventType(entry.Type))
+		entries[i] = domain.ExpandEditDiff(entry)
+		entries[i].Category = class.Category
+		entries[i].Severity = class.Severity
+	}
+	if s.schemas == nil {
+		return entries
+	}
+
+	for i, entry := range entries {
+		upgraded, err := s.schemas.Upgrade(entry)
+		if err != nil {
+			s.logger.Warn("failed to upgrade audit entry details",
+				requestid.Field(ctx),
+				zap.String("event_id", entry.ID),
+				zap.String("type", entry.Type),
+				zap.Int("schema_version", entry.SchemaVersion),
+				zap.Error(err),
+			)
+			continue
+		}
+		entries[i] = upgraded
+	}
+	return entries
+}
+
+// eventType returns the definition of an event type, a bare type when it is not defined
+func (s *reader) eventType(name string) domain.EventType {
+	if s.schemas != nil {
+		if eventType, ok := s.schemas.LookupEventType(domain.AuditAction(name)); ok {
+			return eventType
+		}
+	}
+	return domain.EventType{Name: domain.AuditAction(name)}
+}
+
+// classifiedFilter narrows the types of a filter down to those classified with its categories
+// and severities. It returns false when no type is left, so nothing can match.
+func (s *reader) classifiedFilter(filter domain.EventFilter) (domain.EventFilter, bool) {
+	if len(filter.Categories) == 0 && len(filter.Severities) == 0 {
+		return filter, true
+	}
+
+	var defined []domain.EventType
+	if s.schemas != nil {
+		defined = s.schemas.EventTypes()
+	}
+	types := s.taxonomy.Types(defined, filter.Categories, filter.Severities)
+	if len(filter.Types) > 0 {
+		types = slices.DeleteFunc(types, func(t string) bool { return !slices.Contains(filter.Types, t) })
+	}
+	filter.Types = types
+	return filter, len(types) > 0
+}
+
+// nextCursor returns the cursor for the following page, or empty when this page is the last
+func nextCursor(entries []domain.AuditEntry, limit int) string {
+	if len(entries) == 0 || len(entries) < limit {
+		return ""
+	}
+	return domain.NewCursor(entries[len(entries)-1]).Encode()
+}
+
+// entriesForReader hides other users' client details from share-link readers
+func entriesForReader(entries []domain.AuditEntry, isShareToken bool) []domain.AuditEntry {
+	if !isShareToken {
+		return entries
+	}
+	redacted := make([]domain.AuditEntry, len(entries))
+	for i, entry := range entries {
+		redacted[i] = entry.WithoutClientInfo()
+	}
+	return redacted
+}
+
+// adminAccessKey marks the contexts of requests made by admins
+type adminAccessKey struct{}
+
+// WithAdminAccess returns a copy of ctx that passes the ownership checks of AuthorizeSession.
+// Only the admin and reviewer middleware may use it; reads stay limited to the admin's organization.
+func WithAdminAccess(ctx context.Context) context.Context {
+	return context.WithValue(ctx, adminAccessKey{}, true)
+}
+
+// AuthorizeSession checks that the caller may read the session's audit activity
+func (s *reader) AuthorizeSession(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool) error {
+	// Share token validation is already done in the auth middleware
+	if isShareToken {
+		return nil
+	}
+	// Admins may read every session
+	if admin, _ := ctx.Value(adminAccessKey{}).(bool); admin {
+		return nil
+	}
+	return s.validateOwnership(ctx, sessionID, userID)
+}
+
+// validateOwnership checks if the user owns the session
+func (s *reader) validateOwnership(ctx context.Context, sessionID domain.SessionID, userID domain.UserID) error {
+	// Skip validation for test session IDs
+	if sessionID.IsTestSession() {
+		s.logger.Info("bypassing ownership validation for test session",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Stringer("user_id", userID),
+		)
+		return nil
+	}
+
+	// Get session info
+	session, err := s.repo.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+
+	// Check ownership
+	if session.UserID != userID {
+		s.logger.Warn("unauthorized access attempt",
+			requestid.Field(ctx),
+			zap.Stringer("session_id", sessionID),
+			zap.Stringer("user_id", userID),
+			zap.Stringer("owner_id", session.UserID),
+		)
+		return domain.ErrForbidden
+	}
+
+	return nil
+}
@@ -0,0 +1,1427 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/repository"
+	"audit-service/mocks"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/workpool"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// Test constants
+const (
+	testSessionID   = "session-123"
+	testUserID      = "user-456"
+	testOwnerID     = "owner-789"
+	testOtherUserID = "other-user-999"
+)
+
+// Helper functions to create test data
+func createSampleAuditEntries() []domain.AuditEntry {
+	now := time.Now()
+	details1, _ := json.Marshal(map[string]interface{}{"slide": 1, "text": "updated"})
+	details2, _ := json.Marshal(map[string]interface{}{"slide": 2, "action": "merged"})
+
+	return []domain.AuditEntry{
+		{
+			ID:        "audit-001",
+			SessionID: testSessionID,
+			UserID:    testUserID,
+			Type:      "edit",
+			Timestamp: now.Add(-10 * time.Minute),
+			Details:   details1,
+		},
+		{
+			ID:        "audit-002",
+			SessionID: testSessionID,
+			UserID:    testUserID,
+			Type:      "merge",
+			Timestamp: now.Add(-5 * time.Minute),
+			Details:   details2,
+		},
+	}
+}
+
+func createSampleSession() *repository.Session {
+	return &repository.Session{
+		ID:     testSessionID,
+		UserID: testUserID,
+	}
+}
+
+func createSampleAuditResponse() *domain.AuditResponse {
+	return &domain.AuditResponse{
+		TotalCount: 4,
+		Items:      createSampleAuditEntries(),
+	}
+}
+
+func createSamplePaginationParams() domain.PaginationParams {
+	return domain.PaginationParams{
+		Limit:  10,
+		Offset: 0,
+	}
+}
+
+func createLargePaginationParams() domain.PaginationParams {
+	return domain.PaginationParams{
+		Limit:  50,
+		Offset: 20,
+	}
+}
+
+func generateAuditEntries(count int, sessionID, userID string) []domain.AuditEntry {
+	entries := make([]domain.AuditEntry, count)
+	now := time.Now()
+
+	for i := 0; i < count; i++ {
+		details, _ := json.Marshal(map[string]interface{}{"slide": i + 1})
+		entries[i] = domain.AuditEntry{
+			ID:        fmt.Sprintf("audit-%03d", i+1),
+			SessionID: sessionID,
+			UserID:    userID,
+			Type:      "edit",
+			Timestamp: now.Add(-time.Duration(i) * time.Minute),
+			Details:   details,
+		}
+	}
+
+	return entries
+}
+
+func TestReader_GetAuditLogs(t *testing.T) {
+	tests := []struct {
+		name           string
+		sessionID      domain.SessionID
+		userID         domain.UserID
+		isShareToken   bool
+		pagination     domain.PaginationParams
+		setupMocks     func(*mocks.MockAuditRepository)
+		expectedResult *domain.AuditResponse
+		expectedError  error
+	}{
+		{
+			name:         "success_with_jwt_token",
+			sessionID:    testSessionID,
+			userID:       testUserID,
+			isShareToken: false,
+			pagination:   createSamplePaginationParams(),
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				// Mock session ownership validation
+				mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).
+					Return(createSampleSession(), nil)
+
+				// Mock audit logs retrieval
+				entries := createSampleAuditEntries()
+				mockRepo.On("FindBySessionID", mock.Anything, domain.SessionID(testSessionID), domain.PaginationParams{Limit: 10, Offset: 0}).
+					Return(entries, 4, nil)
+			},
+			expectedResult: createSampleAuditResponse(),
+			expectedError:  nil,
+		},
+		{
+			name:         "success_with_share_token",
+			sessionID:    testSessionID,
+			userID:       testUserID,
+			isShareToken: true,
+			pagination:   createSamplePaginationParams(),
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				// Share token - no ownership validation needed
+				entries := createSampleAuditEntries()
+				mockRepo.On("FindBySessionID", mock.Anything, domain.SessionID(testSessionID), domain.PaginationParams{Limit: 10, Offset: 0}).
+					Return(entries, 4, nil)
+			},
+			expectedResult: createSampleAuditResponse(),
+			expectedError:  nil,
+		},
+		{
+			name:         "success_with_pagination",
+			sessionID:    testSessionID,
+			userID:       testUserID,
+			isShareToken: false,
+			pagination:   createLargePaginationParams(),
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				// Mock session ownership validation
+				mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).
+					Return(createSampleSession(), nil)
+
+				// Mock paginated audit logs retrieval
+				entries := generateAuditEntries(30, testSessionID, testUserID)
+				mockRepo.On("FindBySessionID", mock.Anything, domain.SessionID(testSessionID), domain.PaginationParams{Limit: 50, Offset: 20}).
+					Return(entries[20:], 100, nil)
+			},
+			expectedResult: &domain.AuditResponse{
+				TotalCount: 100,
+				Items:      generateAuditEntries(30, testSessionID, testUserID)[20:],
+			},
+			expectedError: nil,
+		},
+		{
+			name:         "error_forbidden_access",
+			sessionID:    testSessionID,
+			userID:       testOtherUserID,
+			isShareToken: false,
+			pagination:   createSamplePaginationParams(),
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				// Mock session with different owner
+				session := &repository.Session{
+					ID:     testSessionID,
+					UserID: testUserID, // Owner is testUserID, but requester is testOtherUserID
+				}
+				mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).
+					Return(session, nil)
+			},
+			expectedResult: nil,
+			expectedError:  domain.ErrForbidden,
+		},
+		{
+			name:         "error_session_not_found_ownership",
+			sessionID:    "non-existent-session",
+			userID:       testUserID,
+			isShareToken: false,
+			pagination:   createSamplePaginationParams(),
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("GetSession", mock.Anything, domain.SessionID("non-existent-session")).
+					Return(nil, domain.ErrSessionNotFound)
+			},
+			expectedResult: nil,
+			expectedError:  domain.ErrNotFound,
+		},
+		{
+			name:         "error_session_not_found_audit_logs",
+			sessionID:    "non-existent-session",
+			userID:       testUserID,
+			isShareToken: true,
+			pagination:   createSamplePaginationParams(),
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("FindBySessionID", mock.Anything, domain.SessionID("non-existent-session"), domain.PaginationParams{Limit: 10, Offset: 0}).
+					Return(nil, 0, domain.ErrSessionNotFound)
+			},
+			expectedResult: nil,
+			expectedError:  domain.ErrNotFound,
+		},
+		{
+			name:         "error_repository_failure",
+			sessionID:    testSessionID,
+			userID:       testUserID,
+			isShareToken: false,
+			pagination:   createSamplePaginationParams(),
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).
+					Return(createSampleSession(), nil)
+
+				mockRepo.On("FindBySessionID", mock.Anything, domain.SessionID(testSessionID), domain.PaginationParams{Limit: 10, Offset: 0}).
+					Return(nil, 0, errors.New("database connection failed"))
+			},
+			expectedResult: nil,
+			expectedError:  errors.New("failed to fetch audit logs: database connection failed"),
+		},
+		{
+			name:         "error_ownership_validation_failure",
+			sessionID:    testSessionID,
+			userID:       testUserID,
+			isShareToken: false,
+			pagination:   createSamplePaginationParams(),
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).
+					Return(nil, errors.New("database connection failed"))
+			},
+			expectedResult: nil,
+			expectedError:  errors.New("failed to get session: database connection failed"),
+		},
+		{
+			name:         "success_empty_results",
+			sessionID:    testSessionID,
+			userID:       testUserID,
+			isShareToken: true,
+			pagination:   createSamplePaginationParams(),
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("FindBySessionID", mock.Anything, domain.SessionID(testSessionID), domain.PaginationParams{Limit: 10, Offset: 0}).
+					Return([]domain.AuditEntry{}, 0, nil)
+			},
+			expectedResult: &domain.AuditResponse{
+				TotalCount: 0,
+				Items:      []domain.AuditEntry{},
+			},
+			expectedError: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			mockRepo := mocks.NewMockAuditRepository(t)
+			logger := zap.NewNop()
+
+			service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), logger)
+
+			// Configure mocks
+			tt.setupMocks(mockRepo)
+
+			// Execute
+			result, err := service.GetAuditLogs(
+				context.Background(),
+				tt.sessionID,
+				tt.userID,
+				tt.isShareToken,
+				tt.pagination,
+			)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				// Check error message or type based on test case
+				if tt.expectedError == domain.ErrForbidden {
+					assert.Equal(t, domain.ErrForbidden, err)
+				} else if tt.expectedError == domain.ErrNotFound {
+					assert.Equal(t, domain.ErrNotFound, err)
+				} else {
+					assert.Contains(t, err.Error(), tt.expectedError.Error())
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, result)
+				assert.Equal(t, tt.expectedResult.TotalCount, result.TotalCount)
+				assert.Equal(t, len(tt.expectedResult.Items), len(result.Items))
+
+				// Verify items if they exist
+				if len(tt.expectedResult.Items) > 0 {
+					assert.Equal(t, tt.expectedResult.Items[0].ID, result.Items[0].ID)
+					assert.Equal(t, tt.expectedResult.Items[0].SessionID, result.Items[0].SessionID)
+					assert.Equal(t, tt.expectedResult.Items[0].Type, result.Items[0].Type)
+				}
+			}
+
+			// Verify all expectations were met
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReader_validateOwnership(t *testing.T) {
+	tests := []struct {
+		name          string
+		sessionID     domain.SessionID
+		userID        domain.UserID
+		setupMocks    func(*mocks.MockAuditRepository)
+		expectedError error
+	}{
+		{
+			name:      "success_valid_owner",
+			sessionID: testSessionID,
+			userID:    testUserID,
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).
+					Return(createSampleSession(), nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:      "error_forbidden_different_owner",
+			sessionID: testSessionID,
+			userID:    testOtherUserID,
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).
+					Return(createSampleSession(), nil)
+			},
+			expectedError: domain.ErrForbidden,
+		},
+		{
+			name:      "error_session_not_found",
+			sessionID: "non-existent-session",
+			userID:    testUserID,
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("GetSession", mock.Anything, domain.SessionID("non-existent-session")).
+					Return(nil, domain.ErrSessionNotFound)
+			},
+			expectedError: domain.ErrNotFound,
+		},
+		{
+			name:      "error_repository_failure",
+			sessionID: testSessionID,
+			userID:    testUserID,
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).
+					Return(nil, errors.New("database connection failed"))
+			},
+			expectedError: errors.New("failed to get session: database connection failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			mockRepo := mocks.NewMockAuditRepository(t)
+			logger := zap.NewNop()
+
+			service := &reader{
+				repo:   mockRepo,
+				logger: logger,
+			}
+
+			// Configure mocks
+			tt.setupMocks(mockRepo)
+
+			// Execute
+			err := service.validateOwnership(context.Background(), tt.sessionID, tt.userID)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				if tt.expectedError == domain.ErrForbidden {
+					assert.Equal(t, domain.ErrForbidden, err)
+				} else if tt.expectedError == domain.ErrNotFound {
+					assert.Equal(t, domain.ErrNotFound, err)
+				} else {
+					assert.Contains(t, err.Error(), tt.expectedError.Error())
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			// Verify all expectations were met
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReader_GetAuditLogs_NextCursor(t *testing.T) {
+	entries := generateAuditEntries(3, testSessionID, testUserID)
+	cursor := domain.NewCursor(entries[2])
+
+	t.Run("full_page_returns_cursor_of_last_entry", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("FindBySessionID", mock.Anything, domain.SessionID(testSessionID), domain.PaginationParams{Limit: 3}).
+			Return(entries, 10, nil)
+
+		result, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, true, domain.PaginationParams{Limit: 3})
+
+		assert.NoError(t, err)
+		assert.Equal(t, cursor.Encode(), result.NextCursor)
+	})
+
+	t.Run("cursor_is_passed_to_repository", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		// Offset is dropped once a cursor is supplied
+		mockRepo.On("FindBySessionID", mock.Anything, domain.SessionID(testSessionID), domain.PaginationParams{Limit: 5, Cursor: cursor}).
+			Return(entries[:1], 1, nil)
+
+		result, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, true,
+			domain.PaginationParams{Limit: 5, Offset: 40, Cursor: cursor})
+
+		assert.NoError(t, err)
+		assert.Empty(t, result.NextCursor)
+	})
+}
+
+func TestReader_AuthorizeSession(t *testing.T) {
+	t.Run("share_token_skips_ownership_check", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		assert.NoError(t, service.AuthorizeSession(context.Background(), testSessionID, "", true))
+	})
+
+	t.Run("owner_allowed", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+
+		assert.NoError(t, service.AuthorizeSession(context.Background(), testSessionID, testUserID, false))
+	})
+
+	t.Run("other_user_forbidden", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+
+		err := service.AuthorizeSession(context.Background(), testSessionID, testOtherUserID, false)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("admin_allowed", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		assert.NoError(t, service.AuthorizeSession(WithAdminAccess(context.Background()), testSessionID, testOtherUserID, false))
+	})
+}
+
+func TestReader_ShareReadersDoNotSeeClientInfo(t *testing.T) {
+	entries := createSampleAuditEntries()
+	for i := range entries {
+		entries[i].IPAddress = "203.0.113.7"
+		entries[i].UserAgent = "Mozilla/5.0"
+	}
+
+	t.Run("share_token_redacted", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), domain.EventFilter{}, mock.Anything).
+			Return(entries, 2, nil)
+
+		result, err := service.QueryEvents(context.Background(), testSessionID, "", true, domain.EventFilter{}, domain.PaginationParams{Limit: 10})
+
+		assert.NoError(t, err)
+		for _, item := range result.Items {
+			assert.Empty(t, item.IPAddress)
+			assert.Empty(t, item.UserAgent)
+		}
+		// The repository's entries are left untouched
+		assert.Equal(t, "203.0.113.7", entries[0].IPAddress)
+	})
+
+	t.Run("owner_sees_everything", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+		mockRepo.On("FindBySessionID", mock.Anything, domain.SessionID(testSessionID), mock.Anything).Return(entries, 2, nil)
+
+		result, err := service.GetAuditLogs(context.Background(), testSessionID, testUserID, false, domain.PaginationParams{Limit: 10})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "203.0.113.7", result.Items[0].IPAddress)
+		assert.Equal(t, "Mozilla/5.0", result.Items[0].UserAgent)
+	})
+}
+
+func TestReader_QueryEvents(t *testing.T) {
+	filter := domain.EventFilter{Types: []string{"edit"}}
+
+	t.Run("success_owner", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), filter, domain.PaginationParams{Limit: 10, Offset: 0}).
+			Return(createSampleAuditEntries(), 2, nil)
+
+		result, err := service.QueryEvents(context.Background(), testSessionID, testUserID, false, filter, createSamplePaginationParams())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.TotalCount)
+		assert.Len(t, result.Items, 2)
+	})
+
+	t.Run("upgrades_old_schema_versions", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		schemas, err := domain.NewDefaultSchemaRegistry()
+		require.NoError(t, err)
+		service := NewReader(mockRepo, schemas, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		stored := []domain.AuditEntry{
+			{ID: "audit-001", SessionID: testSessionID, Type: "edit", Details: json.RawMessage(`{"before":"Hello","after":"Hi"}`)},
+			// Details that cannot be upgraded are returned as stored
+			{ID: "audit-002", SessionID: testSessionID, Type: "edit", Details: json.RawMessage(`["not an object"]`)},
+		}
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), filter, domain.PaginationParams{Limit: 10, Offset: 0}).
+			Return(stored, 2, nil)
+
+		result, err := service.QueryEvents(context.Background(), testSessionID, testUserID, false, filter, createSamplePaginationParams())
+
+		require.NoError(t, err)
+		require.Len(t, result.Items, 2)
+		assert.Equal(t, 2, result.Items[0].SchemaVersion)
+		assert.JSONEq(t, `{"text":{"before":"Hello","after":"Hi"}}`, string(result.Items[0].Details))
+		assert.Zero(t, result.Items[1].SchemaVersion)
+		assert.JSONEq(t, `["not an object"]`, string(result.Items[1].Details))
+	})
+
+	t.Run("classifies_and_filters_by_taxonomy", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		taxonomy, err := domain.ParseTaxonomy("view=access:warning")
+		require.NoError(t, err)
+		service := NewReader(mockRepo, nil, ReaderConfig{Taxonomy: taxonomy}, clock.New(), zap.NewNop())
+
+		accessWarnings := domain.EventFilter{
+			Categories: []domain.EventCategory{domain.CategoryAccess},
+			Severities: []domain.EntrySeverity{domain.EntrySeverityWarning},
+		}
+		resolved := accessWarnings
+		resolved.Types = []string{"export", "share", "share_expired", "unshare", "view"}
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), resolved, domain.PaginationParams{Limit: 10, Offset: 0}).
+			Return([]domain.AuditEntry{{ID: "audit-001", SessionID: testSessionID, Type: "view"}}, 1, nil)
+
+		result, err := service.QueryEvents(context.Background(), testSessionID, testUserID, false, accessWarnings, createSamplePaginationParams())
+
+		require.NoError(t, err)
+		require.Len(t, result.Items, 1)
+		assert.Equal(t, domain.CategoryAccess, result.Items[0].Category)
+		assert.Equal(t, domain.EntrySeverityWarning, result.Items[0].Severity)
+
+		// Edits are content, so no type is left to query
+		accessWarnings.Types = []string{"edit"}
+		result, err = service.QueryEvents(context.Background(), testSessionID, testUserID, false, accessWarnings, createSamplePaginationParams())
+
+		require.NoError(t, err)
+		assert.Zero(t, result.TotalCount)
+		assert.Empty(t, result.Items)
+	})
+
+	t.Run("error_not_owner", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+
+		result, err := service.QueryEvents(context.Background(), testSessionID, testOtherUserID, false, filter, createSamplePaginationParams())
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, result)
+	})
+
+	t.Run("error_invalid_filter", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		invalid := domain.EventFilter{
+			From: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+
+		result, err := service.QueryEvents(context.Background(), testSessionID, testUserID, true, invalid, createSamplePaginationParams())
+
+		assert.ErrorIs(t, err, domain.ErrInvalidFilter)
+		assert.Nil(t, result)
+	})
+
+	t.Run("error_repository_failure", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), filter, domain.PaginationParams{Limit: 10, Offset: 0}).
+			Return(nil, 0, errors.New("network error"))
+
+		result, err := service.QueryEvents(context.Background(), testSessionID, testUserID, true, filter, createSamplePaginationParams())
+
+		assert.EqualError(t, err, "failed to query audit events: network error")
+		assert.Nil(t, result)
+	})
+}
+
+func TestReader_QueryAllEvents(t *testing.T) {
+	filter := domain.EventFilter{UserID: testUserID}
+
+	t.Run("success_without_ownership_check", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		// GetSession is never called; admin routes are authorized by the middleware
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(""), filter, domain.PaginationParams{Limit: 10, Offset: 0}).
+			Return(createSampleAuditEntries(), 2, nil)
+
+		result, err := service.QueryAllEvents(context.Background(), "", filter, createSamplePaginationParams())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.TotalCount)
+		assert.Len(t, result.Items, 2)
+	})
+
+	t.Run("error_invalid_filter", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		result, err := service.QueryAllEvents(context.Background(), "", domain.EventFilter{
+			From: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}, createSamplePaginationParams())
+
+		assert.ErrorIs(t, err, domain.ErrInvalidFilter)
+		assert.Nil(t, result)
+	})
+
+	t.Run("error_repository_failure", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), filter, domain.PaginationParams{Limit: 10, Offset: 0}).
+			Return(nil, 0, errors.New("network error"))
+
+		result, err := service.QueryAllEvents(context.Background(), testSessionID, filter, createSamplePaginationParams())
+
+		assert.EqualError(t, err, "failed to query audit events: network error")
+		assert.Nil(t, result)
+	})
+}
+
+func TestReader_GetSessionStats(t *testing.T) {
+	t.Run("owner_gets_stats", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		expected := &domain.SessionStats{SessionID: testSessionID, TotalEvents: 3}
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+		mockRepo.On("GetSessionStats", mock.Anything, domain.SessionID(testSessionID)).Return(expected, nil)
+
+		stats, err := service.GetSessionStats(context.Background(), testSessionID, testUserID, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, stats)
+	})
+
+	t.Run("other_user_forbidden", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+
+		_, err := service.GetSessionStats(context.Background(), testSessionID, testOtherUserID, false)
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("repository_error", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("GetSessionStats", mock.Anything, domain.SessionID(testSessionID)).Return(nil, errors.New("rpc failed"))
+
+		_, err := service.GetSessionStats(context.Background(), testSessionID, "", true)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fetch session stats")
+	})
+}
+
+func TestReader_GetSessionActivity(t *testing.T) {
+	now := time.Date(2024, 3, 10, 14, 25, 0, 0, time.UTC)
+	query := domain.ActivityQuery{
+		Granularity: domain.ActivityDay,
+		From:        time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:          time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("owner_gets_activity", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.NewFakeClock(now), zap.NewNop())
+
+		buckets := []domain.ActivityBucket{
+			{Start: query.From, UserID: testUserID, EventCount: 3, ByType: map[string]int{"edit": 3}},
+		}
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+		mockRepo.On("GetSessionActivity", mock.Anything, domain.SessionID(testSessionID), query).Return(buckets, nil)
+
+		// The range is aligned to days
+		activity, err := service.GetSessionActivity(context.Background(), testSessionID, testUserID, false, domain.ActivityQuery{
+			From: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+			To:   time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC),
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, &domain.SessionActivity{
+			SessionID:   testSessionID,
+			Granularity: domain.ActivityDay,
+			From:        query.From,
+			To:          query.To,
+			Buckets:     buckets,
+		}, activity)
+	})
+
+	t.Run("invalid_query", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.NewFakeClock(now), zap.NewNop())
+
+		_, err := service.GetSessionActivity(context.Background(), testSessionID, testUserID, false,
+			domain.ActivityQuery{Granularity: "week"})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidActivityQuery)
+	})
+
+	t.Run("other_user_forbidden", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.NewFakeClock(now), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+
+		_, err := service.GetSessionActivity(context.Background(), testSessionID, testOtherUserID, false, query)
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("repository_error", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.NewFakeClock(now), zap.NewNop())
+
+		mockRepo.On("GetSessionActivity", mock.Anything, domain.SessionID(testSessionID), query).Return(nil, errors.New("rpc failed"))
+
+		_, err := service.GetSessionActivity(context.Background(), testSessionID, "", true, query)
+
+		assert.ErrorContains(t, err, "failed to fetch session activity")
+	})
+}
+
+func TestReader_ExportEvents(t *testing.T) {
+	newPage := func(start, n int) []domain.AuditEntry {
+		base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		entries := make([]domain.AuditEntry, n)
+		for i := range entries {
+			entries[i] = domain.AuditEntry{
+				ID:        fmt.Sprintf("audit-%04d", start+i),
+				SessionID: testSessionID,
+				Timestamp: base.Add(-time.Duration(start+i) * time.Second),
+			}
+		}
+		return entries
+	}
+
+	t.Run("pages_through_with_cursor", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		first := newPage(0, DefaultExportPageSize)
+		second := newPage(DefaultExportPageSize, 20)
+
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), domain.EventFilter{}, domain.PaginationParams{Limit: DefaultExportPageSize}).
+			Return(first, DefaultExportPageSize+20, nil).Once()
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), domain.EventFilter{}, mock.MatchedBy(func(p domain.PaginationParams) bool {
+			return p.Limit == DefaultExportPageSize && p.Cursor != nil && p.Cursor.ID == first[len(first)-1].ID
+		})).Return(second, 20, nil).Once()
+
+		var exported, totals []int
+		err := service.ExportEvents(context.Background(), testSessionID, "", true, domain.EventFilter{}, 10000,
+			func(entries []domain.AuditEntry, total int) error {
+				exported = append(exported, len(entries))
+				totals = append(totals, total)
+				return nil
+			})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{DefaultExportPageSize, 20}, exported)
+		assert.Equal(t, []int{DefaultExportPageSize + 20, DefaultExportPageSize + 20}, totals)
+	})
+
+	t.Run("stops_at_row_cap", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), domain.EventFilter{}, domain.PaginationParams{Limit: 30}).
+			Return(newPage(0, 30), 1000, nil).Once()
+
+		rows := 0
+		err := service.ExportEvents(context.Background(), testSessionID, "", true, domain.EventFilter{}, 30,
+			func(entries []domain.AuditEntry, total int) error {
+				rows += len(entries)
+				return nil
+			})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 30, rows)
+	})
+
+	t.Run("checks_ownership_before_reading", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+
+		err := service.ExportEvents(context.Background(), testSessionID, testOtherUserID, false, domain.EventFilter{}, 100,
+			func([]domain.AuditEntry, int) error {
+				t.Fatal("emit must not be called")
+				return nil
+			})
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("uses_configured_page_size", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{ExportPageSize: 50}, clock.New(), zap.NewNop())
+
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), domain.EventFilter{}, domain.PaginationParams{Limit: 50}).
+			Return(newPage(0, 10), 10, nil).Once()
+
+		err := service.ExportEvents(context.Background(), testSessionID, "", true, domain.EventFilter{}, 1000,
+			func([]domain.AuditEntry, int) error { return nil })
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("waits_for_export_slot", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{Pool: workpool.New(1)}, clock.New(), zap.NewNop())
+
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), domain.EventFilter{}, mock.Anything).
+			Return(newPage(0, 10), 10, nil).Once()
+
+		// The first export holds the only slot while it emits
+		emitting := make(chan struct{})
+		resume := make(chan struct{})
+		done := make(chan error, 1)
+		go func() {
+			done <- service.ExportEvents(context.Background(), testSessionID, "", true, domain.EventFilter{}, 100,
+				func([]domain.AuditEntry, int) error {
+					close(emitting)
+					<-resume
+					return nil
+				})
+		}()
+		<-emitting
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := service.ExportEvents(ctx, testSessionID, "", true, domain.EventFilter{}, 100,
+			func([]domain.AuditEntry, int) error {
+				t.Fatal("emit must not be called")
+				return nil
+			})
+		assert.ErrorIs(t, err, domain.ErrServiceUnavailable)
+
+		close(resume)
+		require.NoError(t, <-done)
+	})
+
+	t.Run("stops_when_client_disconnects", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{ExportPageSize: 10}, clock.New(), zap.NewNop())
+
+		// Only the first page is read; the client is gone before the second
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), domain.EventFilter{}, domain.PaginationParams{Limit: 10}).
+			Return(newPage(0, 10), 100, nil).Once()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		pages := 0
+		err := service.ExportEvents(ctx, testSessionID, "", true, domain.EventFilter{}, 1000,
+			func([]domain.AuditEntry, int) error {
+				pages++
+				cancel()
+				return nil
+			})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, pages)
+	})
+}
+
+func TestReader_VerifyChain(t *testing.T) {
+	// chain builds n linked entries starting at sequence number from
+	chain := func(from, n int, prev string) []domain.ChainedEntry {
+		entries := make([]domain.ChainedEntry, n)
+		for i := range entries {
+			entry := domain.AuditEntry{
+				ID:        fmt.Sprintf("audit-%04d", from+i),
+				SessionID: testSessionID,
+				Type:      "edit",
+				Timestamp: time.Date(2024, 1, 1, 12, 0, from+i, 0, time.UTC),
+			}
+			hash, err := domain.ContentHash(entry)
+			require.NoError(t, err)
+			entries[i] = domain.ChainedEntry{
+				AuditEntry:  entry,
+				Seq:         int64(from + i),
+				ContentHash: hash,
+				PrevHash:    prev,
+				ChainHash:   domain.ChainHash(prev, hash),
+			}
+			prev = entries[i].ChainHash
+		}
+		return entries
+	}
+
+	t.Run("pages_through_chain", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		first := chain(1, verifyPageSize, "")
+		second := chain(verifyPageSize+1, 5, first[len(first)-1].ChainHash)
+
+		mockRepo.On("FindChain", mock.Anything, domain.SessionID(testSessionID), int64(0), verifyPageSize).Return(first, nil).Once()
+		mockRepo.On("FindChain", mock.Anything, domain.SessionID(testSessionID), int64(verifyPageSize), verifyPageSize).Return(second, nil).Once()
+
+		result, err := service.VerifyChain(context.Background(), testSessionID, "", true)
+
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+		assert.Equal(t, verifyPageSize+5, result.CheckedEntries)
+		assert.Equal(t, second[4].ChainHash, result.HeadHash)
+	})
+
+	t.Run("reports_tampered_entry", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		entries := chain(1, 3, "")
+		entries[1].UserID = "someone-else"
+		mockRepo.On("FindChain", mock.Anything, domain.SessionID(testSessionID), int64(0), verifyPageSize).Return(entries, nil).Once()
+
+		result, err := service.VerifyChain(context.Background(), testSessionID, "", true)
+
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		require.Len(t, result.Issues, 1)
+		assert.Equal(t, "audit-0002", result.Issues[0].EventID)
+	})
+
+	t.Run("other_user_forbidden", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+
+		_, err := service.VerifyChain(context.Background(), testSessionID, testOtherUserID, false)
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("repository_error", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("FindChain", mock.Anything, domain.SessionID(testSessionID), int64(0), verifyPageSize).Return(nil, errors.New("network error"))
+
+		_, err := service.VerifyChain(context.Background(), testSessionID, "", true)
+
+		assert.ErrorContains(t, err, "failed to verify audit log chain")
+	})
+}
+
+func TestReader_GetSessionHeatmap(t *testing.T) {
+	now := time.Date(2024, 3, 10, 14, 25, 0, 0, time.UTC)
+	query := domain.HeatmapQuery{From: time.Date(2024, 3, 3, 15, 0, 0, 0, time.UTC), To: time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)}
+
+	t.Run("share_token_gets_heatmap", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.NewFakeClock(now), zap.NewNop())
+
+		mockRepo.On("GetSlideHeatmap", mock.Anything, domain.SessionID(testSessionID), query).Return([]domain.HeatmapCell{
+			{SlideID: "slide-2", SlideIndex: 2, Hour: query.From, EventCount: 5},
+			{SlideID: "slide-1", SlideIndex: 1, Hour: query.From, EventCount: 2},
+		}, nil)
+
+		// The range defaults to the last week
+		heatmap, err := service.GetSessionHeatmap(context.Background(), testSessionID, "", true, domain.HeatmapQuery{})
+
+		require.NoError(t, err)
+		assert.Equal(t, query.From, heatmap.From)
+		assert.Equal(t, 7, heatmap.TotalEvents)
+		assert.Equal(t, "slide-1", heatmap.Cells[0].SlideID)
+	})
+
+	t.Run("other_user_forbidden", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.NewFakeClock(now), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+
+		_, err := service.GetSessionHeatmap(context.Background(), testSessionID, "other-user", false, domain.HeatmapQuery{})
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+}
+
+func TestReader_GetSessionContributors(t *testing.T) {
+	first := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("system_events_left_out", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		query := domain.ContributorQuery{From: first}
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+		mockRepo.On("GetSessionContributors", mock.Anything, domain.SessionID(testSessionID), query).Return([]domain.ContributorStats{
+			{UserID: testUserID, Edits: 3, TotalEvents: 3, FirstActivity: first, LastActivity: first.Add(time.Hour)},
+			{UserID: "system", TotalEvents: 1, FirstActivity: first, LastActivity: first},
+		}, nil)
+
+		report, err := service.GetSessionContributors(context.Background(), testSessionID, testUserID, false, query)
+
+		require.NoError(t, err)
+		assert.Equal(t, &first, report.From)
+		assert.Nil(t, report.To)
+		require.Len(t, report.Contributors, 1)
+		assert.Equal(t, testUserID, report.Contributors[0].UserID)
+	})
+
+	t.Run("reversed_range", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		_, err := service.GetSessionContributors(context.Background(), testSessionID, testUserID, false,
+			domain.ContributorQuery{From: first, To: first.Add(-time.Hour)})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidFilter)
+	})
+
+	t.Run("other_user_forbidden", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+
+		_, err := service.GetSessionContributors(context.Background(), testSessionID, "other-user", false, domain.ContributorQuery{})
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+}
+
+func TestReader_GetTMLeverage(t *testing.T) {
+	tmFilter := domain.EventFilter{Types: []string{"tm_hit", "tm_override"}}
+	hit := func(id string, accepted bool) domain.AuditEntry {
+		return domain.AuditEntry{ID: id, SessionID: testSessionID, Type: "tm_hit",
+			Details: json.RawMessage(fmt.Sprintf(`{"segmentSource":"Segment %s","matchScore":98,"accepted":%t}`, id, accepted))}
+	}
+
+	t.Run("pages_through_tm_events", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{ExportPageSize: 2}, clock.New(), zap.NewNop())
+
+		first := []domain.AuditEntry{hit("audit-001", true), hit("audit-002", false)}
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), tmFilter, domain.PaginationParams{Limit: 2}).
+			Return(first, 3, nil).Once()
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), tmFilter, mock.MatchedBy(func(p domain.PaginationParams) bool {
+			return p.Cursor != nil && p.Cursor.ID == "audit-002"
+		})).Return([]domain.AuditEntry{hit("audit-003", true)}, 1, nil).Once()
+
+		report, err := service.GetTMLeverage(context.Background(), testSessionID, testUserID, false, domain.TMLeverageQuery{})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.Hits)
+		assert.Equal(t, 2, report.Accepted)
+		assert.Equal(t, 3, report.Segments)
+		assert.False(t, report.Truncated)
+	})
+
+	t.Run("reversed_range", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		from := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+		_, err := service.GetTMLeverage(context.Background(), testSessionID, testUserID, false,
+			domain.TMLeverageQuery{From: from, To: from.Add(-time.Hour)})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidFilter)
+	})
+
+	t.Run("other_user_forbidden", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+
+		_, err := service.GetTMLeverage(context.Background(), testSessionID, "other-user", false, domain.TMLeverageQuery{})
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+}
+
+func TestReader_GetMTCosts(t *testing.T) {
+	mtFilter := domain.EventFilter{Types: []string{"mt_request"}}
+	request := func(id string) domain.AuditEntry {
+		return domain.AuditEntry{ID: id, SessionID: testSessionID, Type: "mt_request",
+			Details: json.RawMessage(`{"provider":"deepl","sourceCharacters":100,"costEstimate":0.002}`)}
+	}
+
+	t.Run("share_token_reads_rollup", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), mtFilter, domain.PaginationParams{Limit: DefaultExportPageSize}).
+			Return([]domain.AuditEntry{request("audit-001"), request("audit-002")}, 2, nil).Once()
+
+		report, err := service.GetMTCosts(context.Background(), testSessionID, "", true, domain.MTCostQuery{})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Requests)
+		assert.Equal(t, []domain.MTCurrencyCost{{Currency: "USD", CostEstimate: 0.004}}, report.Costs)
+	})
+
+	t.Run("storage_error", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), mtFilter, mock.Anything).
+			Return(nil, 0, errors.New("connection refused")).Once()
+
+		_, err := service.GetMTCosts(context.Background(), testSessionID, "", true, domain.MTCostQuery{})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestReader_GetSessionTimeline(t *testing.T) {
+	base := time.Date(2024, 4, 1, 8, 0, 0, 0, time.UTC)
+	edit := func(id string, minutes int) domain.AuditEntry {
+		return domain.AuditEntry{ID: id, SessionID: testSessionID, UserID: "user-1", Type: "edit", SlideID: "slide-1",
+			Timestamp: base.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	t.Run("share_token_reads_timeline", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), domain.EventFilter{}, domain.PaginationParams{Limit: DefaultExportPageSize}).
+			Return([]domain.AuditEntry{edit("audit-003", 3), edit("audit-002", 2), edit("audit-001", 0)}, 3, nil).Once()
+
+		timeline, err := service.GetSessionTimeline(context.Background(), testSessionID, "", true, domain.TimelineQuery{})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, timeline.Events)
+		require.Len(t, timeline.Groups, 1)
+		assert.Equal(t, "3 edits on slide-1", timeline.Groups[0].Summary)
+		assert.False(t, timeline.Truncated)
+	})
+
+	t.Run("invalid_gap", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		_, err := service.GetSessionTimeline(context.Background(), testSessionID, "", true, domain.TimelineQuery{Gap: time.Millisecond})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidFilter)
+	})
+}
+
+func TestReader_GetAccessReview(t *testing.T) {
+	viewFilter := domain.EventFilter{Types: []string{"view"}}
+	now := time.Date(2024, 4, 1, 8, 0, 0, 0, time.UTC)
+
+	t.Run("owner_takes_snapshot", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.NewFakeClock(now), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), viewFilter, domain.PaginationParams{Limit: DefaultExportPageSize}).
+			Return([]domain.AuditEntry{
+				{ID: "audit-002", SessionID: testSessionID, UserID: "reviewer-1", Type: "view", Timestamp: now.Add(-time.Hour),
+					Details: json.RawMessage(`{"accessMethod":"share_token","shareTokenId":"share-91c2"}`)},
+				{ID: "audit-001", SessionID: testSessionID, UserID: testUserID, Type: "view", Timestamp: now.Add(-2 * time.Hour)},
+			}, 2, nil).Once()
+
+		review, err := service.GetAccessReview(context.Background(), testSessionID, testUserID, domain.AccessReviewQuery{})
+
+		require.NoError(t, err)
+		assert.Equal(t, now, review.GeneratedAt)
+		assert.Equal(t, 2, review.TotalViews)
+		require.Len(t, review.Accessors, 2)
+		assert.Equal(t, domain.AccessShareToken, review.Accessors[0].AccessMethod)
+		assert.Equal(t, domain.AccessUnknown, review.Accessors[1].AccessMethod)
+	})
+
+	t.Run("other_user_forbidden", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.NewFakeClock(now), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+
+		_, err := service.GetAccessReview(context.Background(), testSessionID, testOtherUserID, domain.AccessReviewQuery{})
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+}
+
+func TestReader_GetRevertPayload(t *testing.T) {
+	edit := domain.AuditEntry{ID: "audit-001", SessionID: testSessionID, UserID: testUserID, Type: "edit",
+		Details: json.RawMessage(`{"slideId":"slide-1","before":"Hola","after":"Hello"}`)}
+
+	t.Run("version_1_edit", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		schemas, err := domain.NewDefaultSchemaRegistry()
+		require.NoError(t, err)
+		service := NewReader(mockRepo, schemas, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+		mockRepo.On("FindEvent", mock.Anything, domain.EventID("audit-001")).Return(&edit, nil)
+
+		payload, err := service.GetRevertPayload(context.Background(), testSessionID, "audit-001", testUserID, false)
+
+		require.NoError(t, err)
+		assert.Equal(t, "audit-001", payload.ParentEventID)
+		assert.JSONEq(t, `{"action":"revert","slideId":"slide-1","text":{"before":"Hello","after":"Hola"}}`, string(payload.Details))
+	})
+
+	t.Run("event_of_other_session", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		foreign := edit
+		foreign.SessionID = "session-other"
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+		mockRepo.On("FindEvent", mock.Anything, domain.EventID("audit-001")).Return(&foreign, nil)
+
+		_, err := service.GetRevertPayload(context.Background(), testSessionID, "audit-001", testUserID, false)
+
+		assert.ErrorIs(t, err, domain.ErrEventNotFound)
+	})
+
+	t.Run("share_token_forbidden", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		_, err := service.GetRevertPayload(context.Background(), testSessionID, "audit-001", "", true)
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+}
+
+func TestReader_GetEditDiff(t *testing.T) {
+	edit := domain.AuditEntry{ID: "audit-001", SessionID: testSessionID, UserID: testUserID, Type: "edit",
+		Details: json.RawMessage(`{"slideId":"slide-1","before":"Hola mundo","after":"Hello mundo"}`)}
+
+	t.Run("version_1_edit_diffed_when_read", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		schemas, err := domain.NewDefaultSchemaRegistry()
+		require.NoError(t, err)
+		service := NewReader(mockRepo, schemas, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+		mockRepo.On("FindEvent", mock.Anything, domain.EventID("audit-001")).Return(&edit, nil)
+
+		diff, err := service.GetEditDiff(context.Background(), testSessionID, "audit-001", testUserID, false)
+
+		require.NoError(t, err)
+		assert.Equal(t, "audit-001", diff.EventID)
+		assert.False(t, diff.Stored)
+		assert.Equal(t, []domain.RenderedDiffSpan{
+			{Op: domain.DiffDelete, Text: "Hola"},
+			{Op: domain.DiffInsert, Text: "Hello"},
+			{Op: domain.DiffEqual, Text: " mundo"},
+		}, diff.Spans)
+	})
+
+	t.Run("share_token_reads_stored_diff", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		schemas, err := domain.NewDefaultSchemaRegistry()
+		require.NoError(t, err)
+		service := NewReader(mockRepo, schemas, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		// The texts of a long edit are stored as its diff and restored when read
+		paragraph := " mundo, con un texto lo bastante largo para que el diff ocupe menos que el texto de antes y el de después"
+		stored := edit
+		stored.Details = domain.WithEditDiff(domain.ActionEdit, 1,
+			json.RawMessage(`{"slideId":"slide-1","before":"Hola`+paragraph+`","after":"Hello`+paragraph+`"}`))
+		require.NotContains(t, string(stored.Details), `"before"`)
+		mockRepo.On("FindEvent", mock.Anything, domain.EventID("audit-001")).Return(&stored, nil)
+
+		diff, err := service.GetEditDiff(context.Background(), testSessionID, "audit-001", "", true)
+
+		require.NoError(t, err)
+		assert.True(t, diff.Stored)
+		assert.Equal(t, 5, diff.Inserted)
+		assert.Equal(t, 4, diff.Deleted)
+	})
+
+	t.Run("event_of_other_session", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		foreign := edit
+		foreign.SessionID = "session-other"
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+		mockRepo.On("FindEvent", mock.Anything, domain.EventID("audit-001")).Return(&foreign, nil)
+
+		_, err := service.GetEditDiff(context.Background(), testSessionID, "audit-001", testUserID, false)
+
+		assert.ErrorIs(t, err, domain.ErrEventNotFound)
+	})
+}
+
+func TestReader_GetEventChain(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	// Exports are classified as access events of warning severity when read
+	requested := domain.AuditEntry{ID: "audit-001", SessionID: testSessionID, UserID: testUserID, Type: "export",
+		Timestamp: base, Category: domain.CategoryAccess, Severity: domain.EntrySeverityWarning}
+	processed := domain.AuditEntry{ID: "audit-002", SessionID: testSessionID, UserID: "system", Type: "export",
+		Timestamp: base.Add(time.Minute), CorrelationID: "export-1", ParentEventID: "audit-001",
+		Category: domain.CategoryAccess, Severity: domain.EntrySeverityWarning}
+	downloaded := domain.AuditEntry{ID: "audit-003", SessionID: testSessionID, UserID: testUserID, Type: "export",
+		Timestamp: base.Add(2 * time.Minute), CorrelationID: "export-1", ParentEventID: "audit-002",
+		Category: domain.CategoryAccess, Severity: domain.EntrySeverityWarning}
+	correlated := domain.EventFilter{CorrelationID: "export-1"}
+	chainPage := domain.PaginationParams{Limit: maxEventChainLength}
+
+	t.Run("correlated_events_and_ancestors", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("FindEvent", mock.Anything, domain.EventID("audit-003")).Return(&downloaded, nil)
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+		mockRepo.On("QueryEvents", mock.Anything, domain.SessionID(testSessionID), correlated, chainPage).
+			Return([]domain.AuditEntry{downloaded, processed}, 2, nil)
+		// The request preceded the correlation ID and is reached through the parent link
+		mockRepo.On("FindEvent", mock.Anything, domain.EventID("audit-001")).Return(&requested, nil)
+
+		chain, err := service.GetEventChain(context.Background(), "audit-003", testUserID)
+
+		require.NoError(t, err)
+		assert.Equal(t, "audit-003", chain.EventID)
+		assert.Equal(t, "export-1", chain.CorrelationID)
+		assert.Equal(t, []domain.AuditEntry{requested, processed, downloaded}, chain.Items)
+	})
+
+	t.Run("uncorrelated_event", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("FindEvent", mock.Anything, domain.EventID("audit-001")).Return(&requested, nil)
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+
+		chain, err := service.GetEventChain(context.Background(), "audit-001", testUserID)
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.AuditEntry{requested}, chain.Items)
+	})
+
+	t.Run("parent_in_other_session_is_skipped", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		child := domain.AuditEntry{ID: "audit-004", SessionID: testSessionID, Type: "edit", Timestamp: base, ParentEventID: "audit-900",
+			Category: domain.CategoryContent, Severity: domain.EntrySeverityInfo}
+		foreign := domain.AuditEntry{ID: "audit-900", SessionID: "session-other", Timestamp: base}
+		mockRepo.On("FindEvent", mock.Anything, domain.EventID("audit-004")).Return(&child, nil)
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+		mockRepo.On("FindEvent", mock.Anything, domain.EventID("audit-900")).Return(&foreign, nil)
+
+		chain, err := service.GetEventChain(context.Background(), "audit-004", testUserID)
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.AuditEntry{child}, chain.Items)
+	})
+
+	t.Run("other_user_forbidden", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("FindEvent", mock.Anything, domain.EventID("audit-003")).Return(&downloaded, nil)
+		mockRepo.On("GetSession", mock.Anything, domain.SessionID(testSessionID)).Return(createSampleSession(), nil)
+
+		_, err := service.GetEventChain(context.Background(), "audit-003", testOtherUserID)
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("event_not_found", func(t *testing.T) {
+		mockRepo := mocks.NewMockAuditRepository(t)
+		service := NewReader(mockRepo, nil, ReaderConfig{}, clock.New(), zap.NewNop())
+
+		mockRepo.On("FindEvent", mock.Anything, domain.EventID("audit-404")).Return(nil, domain.ErrEventNotFound)
+
+		_, err := service.GetEventChain(context.Background(), "audit-404", testUserID)
+
+		assert.ErrorIs(t, err, domain.ErrEventNotFound)
+	})
+}
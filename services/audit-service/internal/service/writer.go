@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/repository"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/requestid"
+
+	"go.uber.org/zap"
+)
+
+// Writer persists audit entries. Ingestion goes through it, directly or through the write
+// buffer, apart from the Reader so writes can be tuned and deployed on their own.
+type Writer interface {
+	CreateEvent(ctx context.Context, entry domain.AuditEntry) error
+	CreateEvents(ctx context.Context, entries []domain.AuditEntry) error
+}
+
+const (
+	// DefaultWriteAttempts is how many times a storage write is tried before giving up
+	DefaultWriteAttempts = 3
+
+	// DefaultWriteBackoff is the base delay between write attempts, multiplied by the attempt number
+	DefaultWriteBackoff = 100 * time.Millisecond
+)
+
+// WriterConfig tunes how storage writes are retried
+type WriterConfig struct {
+	// WriteAttempts is how many times a storage write is tried, including the first
+	WriteAttempts int
+	// WriteBackoff is the base delay between attempts, multiplied by the attempt number
+	WriteBackoff time.Duration
+}
+
+// writer implements the Writer interface
+type writer struct {
+	repo   repository.AuditRepository
+	clock  clock.Clock
+	logger *zap.Logger
+
+	writeAttempts int
+	writeBackoff  time.Duration
+}
+
+// NewWriter creates the write side of the audit service
+func NewWriter(repo repository.AuditRepository, cfg WriterConfig, clk clock.Clock, logger *zap.Logger) Writer {
+	w := &writer{
+		repo:          repo,
+		clock:         clk,
+		logger:        logger,
+		writeAttempts: cfg.WriteAttempts,
+		writeBackoff:  cfg.WriteBackoff,
+	}
+	if w.writeAttempts <= 0 {
+		w.writeAttempts = DefaultWriteAttempts
+	}
+	return w
+}
+
+// CreateEvent persists a single audit entry
+func (s *writer) CreateEvent(ctx context.Context, entry domain.AuditEntry) error {
+	if err := s.persistEvents(ctx, []domain.AuditEntry{entry}); err != nil {
+		s.logger.Error("failed to create audit event",
+			requestid.Field(ctx),
+			zap.String("event_id", entry.ID),
+			zap.String("session_id", entry.SessionID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to create audit event: %w", err)
+	}
+
+	s.logger.Info("audit event created",
+		requestid.Field(ctx),
+		zap.String("event_id", entry.ID),
+		zap.String("session_id", entry.SessionID),
+		zap.String("user_id", entry.UserID),
+		zap.String("type", entry.Type),
+	)
+
+	return nil
+}
+
+// CreateEvents persists a batch of audit entries
+func (s *writer) CreateEvents(ctx context.Context, entries []domain.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if err := s.persistEvents(ctx, entries); err != nil {
+		s.logger.Error("failed to create audit events",
+			requestid.Field(ctx),
+			zap.Int("count", len(entries)),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to create audit events: %w", err)
+	}
+
+	s.logger.Info("audit events created",
+		requestid.Field(ctx),
+		zap.Int("count", len(entries)),
+	)
+
+	return nil
+}
+
+// persistEvents writes entries to the repository, retrying transient failures
+func (s *writer) persistEvents(ctx context.Context, entries []domain.AuditEntry) error {
+	var err error
+	for attempt := 1; attempt <= s.writeAttempts; attempt++ {
+		if err = s.repo.CreateEvents(ctx, entries); err == nil {
+			return nil
+		}
+
+		if !repository.IsRetryable(err) || attempt == s.writeAttempts {
+			break
+		}
+
+		s.logger.Warn("retrying audit event write",
+			requestid.Field(ctx),
+			zap.Int("attempt", attempt),
+			zap.Int("count", len(entries)),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return mapWriteError(ctx.Err())
+		case <-time.After(s.writeBackoff * time.Duration(attempt)):
+		}
+	}
+
+	return mapWriteError(err)
+}
+
+// mapWriteError converts storage failures into domain errors
+func mapWriteError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %v", domain.ErrTimeout, err)
+	}
+
+	if repository.IsRetryable(err) {
+		return fmt.Errorf("%w: %v", domain.ErrServiceUnavailable, err)
+	}
+
+	// Storage rejected the payload itself (constraint or type violations)
+	var supErr *repository.SupabaseError
+	if errors.As(err, &supErr) && supErr.StatusCode >= 400 && supErr.StatusCode < 500 &&
+		supErr.StatusCode != http.StatusUnauthorized && supErr.StatusCode != http.StatusForbidden {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidEvent, err)
+	}
+
+	return err
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/repository"
+	"audit-service/mocks"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestWriter_CreateEvents(t *testing.T) {
+	tests := []struct {
+		name          string
+		entries       []domain.AuditEntry
+		setupMocks    func(*mocks.MockAuditRepository)
+		expectedError string
+	}{
+		{
+			name:    "success_batch",
+			entries: createSampleAuditEntries(),
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("CreateEvents", mock.Anything, mock.MatchedBy(func(entries []domain.AuditEntry) bool {
+					return len(entries) == 2
+				})).Return(nil).Once()
+			},
+		},
+		{
+			name:       "empty_batch",
+			entries:    nil,
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {},
+		},
+		{
+			name:    "error_repository_failure",
+			entries: createSampleAuditEntries(),
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("CreateEvents", mock.Anything, mock.Anything).
+					Return(errors.New("insert failed"))
+			},
+			expectedError: "failed to create audit events: insert failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockAuditRepository(t)
+			service := NewWriter(mockRepo, WriterConfig{}, clock.New(), zap.NewNop())
+
+			tt.setupMocks(mockRepo)
+
+			err := service.CreateEvents(context.Background(), tt.entries)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestWriter_CreateEvent(t *testing.T) {
+	entry := createSampleAuditEntries()[0]
+	unavailable := &repository.SupabaseError{Message: "upstream unavailable", StatusCode: 503}
+	rejected := &repository.SupabaseError{Message: "invalid input syntax", StatusCode: 400}
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*mocks.MockAuditRepository)
+		expectedError error
+	}{
+		{
+			name: "success_first_attempt",
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("CreateEvents", mock.Anything, []domain.AuditEntry{entry}).
+					Return(nil).Once()
+			},
+		},
+		{
+			name: "success_after_transient_failure",
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("CreateEvents", mock.Anything, []domain.AuditEntry{entry}).
+					Return(unavailable).Once()
+				mockRepo.On("CreateEvents", mock.Anything, []domain.AuditEntry{entry}).
+					Return(nil).Once()
+			},
+		},
+		{
+			name: "error_retries_exhausted",
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("CreateEvents", mock.Anything, []domain.AuditEntry{entry}).
+					Return(unavailable).Times(DefaultWriteAttempts)
+			},
+			expectedError: domain.ErrServiceUnavailable,
+		},
+		{
+			name: "error_rejected_without_retry",
+			setupMocks: func(mockRepo *mocks.MockAuditRepository) {
+				mockRepo.On("CreateEvents", mock.Anything, []domain.AuditEntry{entry}).
+					Return(rejected).Once()
+			},
+			expectedError: domain.ErrInvalidEvent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockAuditRepository(t)
+			service := NewWriter(mockRepo, WriterConfig{}, clock.New(), zap.NewNop())
+
+			tt.setupMocks(mockRepo)
+
+			err := service.CreateEvent(context.Background(), entry)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
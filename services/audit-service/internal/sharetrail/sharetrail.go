@@ -0,0 +1,214 @@
+// Package sharetrail records the uses of share links and serves the forensic trail of a link. The
+// share service records the share and unshare events of a link with its token ID as correlation
+// ID; the audit service records, correlated the same way, a share_redeemed event when a share
+// token grants access and a share_expired event when the token of an expired link is presented.
+package sharetrail
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+	"audit-service/pkg/requestid"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// RedemptionWindow is the time within which the uses of a link by one client, for one method
+	// and path, are recorded once, so a reader paging through a history records one redemption
+	RedemptionWindow = 5 * time.Minute
+
+	// maxRecentUses is the number of recorded uses remembered before the expired ones are dropped
+	maxRecentUses = 10000
+
+	// writeTimeout bounds the write of a use, which outlives the request once it is answered
+	writeTimeout = 5 * time.Second
+
+	// trailPageSize is the number of correlated events read per query
+	trailPageSize = 100
+)
+
+// Writer stores the use events
+type Writer interface {
+	CreateEvent(ctx context.Context, entry domain.AuditEntry) error
+}
+
+// EventReader reads the correlated events of a share link and authorizes its readers
+type EventReader interface {
+	QueryAllEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, pagination domain.PaginationParams) (*domain.AuditResponse, error)
+	AuthorizeSession(ctx context.Context, sessionID domain.SessionID, userID domain.UserID, isShareToken bool) error
+}
+
+// Service records the uses of share links and serves their trails
+type Service struct {
+	writer Writer
+	reader EventReader
+	clock  clock.Clock
+	logger *zap.Logger
+
+	mu sync.Mutex
+	// recent holds the time each use was last recorded at
+	recent map[string]time.Time
+}
+
+// New creates the share trail service
+func New(writer Writer, reader EventReader, clk clock.Clock, logger *zap.Logger) *Service {
+	return &Service{
+		writer: writer,
+		reader: reader,
+		clock:  clk,
+		logger: logger,
+		recent: make(map[string]time.Time),
+	}
+}
+
+// ReportShareTokenUse records the use of a share link as a share_redeemed or share_expired event.
+// Uses repeated within RedemptionWindow are not recorded again; failures are logged, as the
+// request was answered already.
+func (s *Service) ReportShareTokenUse(ctx context.Context, use domain.ShareTokenUse) {
+	now := s.clock.Now()
+	key := useKey(use)
+	if !s.claim(key, now) {
+		return
+	}
+
+	action := domain.ActionShareRedeemed
+	if use.Expired {
+		action = domain.ActionShareExpired
+	}
+	details, err := domain.EncodeDetails(use.Details())
+	if err != nil {
+		s.logger.Error("failed to encode share link use", zap.String("request_id", use.RequestID), zap.Error(err))
+		s.release(key)
+		return
+	}
+	// Auth accepted the session ID of the path, which may differ in case from the stored one
+	sessionID := use.SessionID
+	if parsed, err := domain.ParseSessionID(sessionID); err == nil {
+		sessionID = parsed.String()
+	}
+	entry := domain.AuditEntry{
+		ID:             uuid.New().String(),
+		SessionID:      sessionID,
+		UserID:         domain.ShareUserID(use.TokenID),
+		Type:           string(action),
+		Timestamp:      now,
+		Details:        details,
+		IPAddress:      use.ClientIP,
+		UserAgent:      use.UserAgent,
+		ReceivedAt:     &now,
+		CorrelationID:  use.TokenID,
+		OrganizationID: use.OrganizationID,
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), writeTimeout)
+	defer cancel()
+	if err := s.writer.CreateEvent(ctx, entry); err != nil {
+		s.logger.Error("failed to record share link use",
+			zap.String("request_id", use.RequestID),
+			zap.String("session_id", use.SessionID),
+			zap.String("type", entry.Type),
+			zap.Error(err),
+		)
+		// The next use is recorded instead
+		s.release(key)
+	}
+}
+
+// useKey identifies the uses recorded once per window
+func useKey(use domain.ShareTokenUse) string {
+	return strings.Join([]string{use.TokenID, strconv.FormatBool(use.Expired), use.ClientIP, use.UserAgent, use.Method, use.Path}, "\x00")
+}
+
+// claim reports whether the use is to be recorded, as it was not within the window
+func (s *Service) claim(key string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if recordedAt, ok := s.recent[key]; ok && now.Sub(recordedAt) < RedemptionWindow {
+		return false
+	}
+	if len(s.recent) >= maxRecentUses {
+		for k, recordedAt := range s.recent {
+			if now.Sub(recordedAt) >= RedemptionWindow {
+				delete(s.recent, k)
+			}
+		}
+	}
+	s.recent[key] = now
+	return true
+}
+
+// release forgets a use that could not be recorded
+func (s *Service) release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.recent, key)
+}
+
+// Get returns the trail of a share link of a session the user may read
+func (s *Service) Get(ctx context.Context, tokenID string, userID domain.UserID) (domain.ShareTrail, error) {
+	if tokenID == "" || !domain.ValidCorrelationID(tokenID) {
+		return domain.ShareTrail{}, domain.ErrShareTrailNotFound
+	}
+
+	entries, truncated, err := s.correlated(ctx, tokenID)
+	if err != nil {
+		return domain.ShareTrail{}, err
+	}
+	trail, ok := domain.NewShareTrail(tokenID, entries, s.clock.Now())
+	if !ok {
+		return domain.ShareTrail{}, domain.ErrShareTrailNotFound
+	}
+
+	// Links of sessions the user cannot read are not disclosed
+	if err := s.reader.AuthorizeSession(ctx, domain.SessionID(trail.SessionID), userID, false); err != nil {
+		if domain.ToAPIError(err).Status < 500 {
+			return domain.ShareTrail{}, domain.ErrShareTrailNotFound
+		}
+		return domain.ShareTrail{}, err
+	}
+
+	s.logger.Info("share trail read",
+		requestid.Field(ctx),
+		zap.String("session_id", trail.SessionID),
+		zap.Stringer("user_id", userID),
+		zap.Int("redemptions", len(trail.Redemptions)),
+	)
+
+	trail.Truncated = truncated
+	return trail, nil
+}
+
+// correlated returns the lifecycle events carrying the token ID, across sessions, up to
+// MaxShareTrailEvents; truncated is set when there are more
+func (s *Service) correlated(ctx context.Context, tokenID string) (entries []domain.AuditEntry, truncated bool, err error) {
+	filter := domain.EventFilter{CorrelationID: tokenID}
+	for _, action := range domain.ShareTrailActions {
+		filter.Types = append(filter.Types, string(action))
+	}
+
+	page := domain.PaginationParams{Limit: trailPageSize}
+	for {
+		response, err := s.reader.QueryAllEvents(ctx, "", filter, page)
+		if err != nil {
+			return nil, false, err
+		}
+		entries = append(entries, response.Items...)
+		if response.NextCursor == "" {
+			return entries, false, nil
+		}
+		if len(entries) >= domain.MaxShareTrailEvents {
+			return entries, true, nil
+		}
+		if page.Cursor, err = domain.DecodeCursor(response.NextCursor); err != nil {
+			return nil, false, err
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package sharetrail
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testSessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+var testNow = time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+// memoryEvents stores the written entries and answers the queries of the service from them
+type memoryEvents struct {
+	entries    []domain.AuditEntry
+	authorized map[string]bool
+	writeErr   error
+}
+
+func (m *memoryEvents) CreateEvent(_ context.Context, entry domain.AuditEntry) error {
+	if m.writeErr != nil {
+		return m.writeErr
+	}
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *memoryEvents) QueryAllEvents(_ context.Context, sessionID domain.SessionID, filter domain.EventFilter, _ domain.PaginationParams) (*domain.AuditResponse, error) {
+	items := []domain.AuditEntry{}
+	for _, entry := range m.entries {
+		if (sessionID == "" || entry.SessionID == string(sessionID)) && entry.CorrelationID == filter.CorrelationID {
+			items = append(items, entry)
+		}
+	}
+	return &domain.AuditResponse{Items: items, TotalCount: len(items)}, nil
+}
+
+func (m *memoryEvents) AuthorizeSession(_ context.Context, sessionID domain.SessionID, userID domain.UserID, _ bool) error {
+	if !m.authorized[string(userID)+"/"+string(sessionID)] {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+func newTestService() (*Service, *memoryEvents, *clock.FakeClock) {
+	events := &memoryEvents{authorized: map[string]bool{"user-123/" + testSessionID: true}}
+	fakeClock := clock.NewFakeClock(testNow)
+	return New(events, events, fakeClock, zap.NewNop()), events, fakeClock
+}
+
+func use(path string) domain.ShareTokenUse {
+	return domain.ShareTokenUse{
+		TokenID: "share-91c2", SessionID: "550E8400-E29B-41D4-A716-446655440000", Method: "GET", Path: path, Status: 200,
+		ClientIP: "198.51.100.4", UserAgent: "Mozilla/5.0",
+	}
+}
+
+func TestService_ReportShareTokenUse(t *testing.T) {
+	service, events, fakeClock := newTestService()
+	ctx := context.Background()
+
+	service.ReportShareTokenUse(ctx, use("/api/v1/sessions/"+testSessionID+"/history"))
+	require.Len(t, events.entries, 1)
+	entry := events.entries[0]
+	assert.Equal(t, string(domain.ActionShareRedeemed), entry.Type)
+	assert.Equal(t, testSessionID, entry.SessionID)
+	assert.Equal(t, "share:share-91c2", entry.UserID)
+	assert.Equal(t, "share-91c2", entry.CorrelationID)
+	assert.Equal(t, "198.51.100.4", entry.IPAddress)
+	assert.Equal(t, testNow, entry.Timestamp)
+	assert.JSONEq(t, `{"tokenId":"share-91c2","method":"GET","path":"/api/v1/sessions/`+testSessionID+`/history","status":200}`, string(entry.Details))
+
+	// Repeated uses are recorded once per window, other paths and expired uses each
+	service.ReportShareTokenUse(ctx, use("/api/v1/sessions/"+testSessionID+"/history"))
+	service.ReportShareTokenUse(ctx, use("/api/v1/sessions/"+testSessionID+"/events"))
+	expired := use("/api/v1/sessions/" + testSessionID + "/history")
+	expired.Expired = true
+	service.ReportShareTokenUse(ctx, expired)
+	require.Len(t, events.entries, 3)
+	assert.Equal(t, string(domain.ActionShareExpired), events.entries[2].Type)
+
+	fakeClock.Advance(RedemptionWindow)
+	service.ReportShareTokenUse(ctx, use("/api/v1/sessions/"+testSessionID+"/history"))
+	assert.Len(t, events.entries, 4)
+}
+
+func TestService_ReportShareTokenUse_RetriesFailedWrites(t *testing.T) {
+	service, events, _ := newTestService()
+	ctx := context.Background()
+
+	events.writeErr = errors.New("connection refused")
+	service.ReportShareTokenUse(ctx, use("/api/v1/sessions/"+testSessionID+"/history"))
+	assert.Empty(t, events.entries)
+
+	events.writeErr = nil
+	service.ReportShareTokenUse(ctx, use("/api/v1/sessions/"+testSessionID+"/history"))
+	assert.Len(t, events.entries, 1)
+}
+
+func TestService_Get(t *testing.T) {
+	service, events, fakeClock := newTestService()
+	ctx := context.Background()
+
+	events.entries = append(events.entries, domain.AuditEntry{
+		ID: "shared", SessionID: testSessionID, UserID: "user-123", Type: string(domain.ActionShare),
+		Timestamp: testNow.Add(-time.Hour), CorrelationID: "share-91c2", Details: []byte(`{"tokenId":"share-91c2","permissions":["COMMENT"]}`),
+	})
+	service.ReportShareTokenUse(ctx, use("/api/v1/sessions/"+testSessionID+"/history"))
+	fakeClock.Advance(time.Minute)
+
+	trail, err := service.Get(ctx, "share-91c2", "user-123")
+	require.NoError(t, err)
+	assert.Equal(t, testSessionID, trail.SessionID)
+	assert.Equal(t, domain.ShareLinkActive, trail.Status)
+	assert.Equal(t, []domain.SharePermission{domain.SharePermissionComment}, trail.Permissions)
+	require.Len(t, trail.Redemptions, 1)
+	assert.Equal(t, "198.51.100.4", trail.Redemptions[0].IPAddress)
+
+	// Links of sessions the user cannot read are not disclosed
+	_, err = service.Get(ctx, "share-91c2", "user-456")
+	assert.ErrorIs(t, err, domain.ErrShareTrailNotFound)
+
+	_, err = service.Get(ctx, "share-unknown", "user-123")
+	assert.ErrorIs(t, err, domain.ErrShareTrailNotFound)
+
+	_, err = service.Get(ctx, "share with spaces", "user-123")
+	assert.ErrorIs(t, err, domain.ErrShareTrailNotFound)
+}
@@ -0,0 +1,227 @@
+// Package siem forwards security relevant activity to a syslog endpoint in CEF or LEEF format,
+// for SIEMs such as Splunk and QRadar: share, unshare and export events, and requests rejected
+// with 401 or 403.
+package siem
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"audit-service/internal/broadcast"
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// queueSize is how many events may wait for the exporter before new ones are dropped
+	queueSize = 4096
+
+	// dialTimeout and writeTimeout bound the network calls, so an unreachable endpoint cannot
+	// hold up the export of later events for long
+	dialTimeout  = 5 * time.Second
+	writeTimeout = 5 * time.Second
+
+	// idleProbeAfter is how long a stream connection may be idle before it is checked for having
+	// been closed by the endpoint, and probeTimeout how long the check waits for its answer
+	idleProbeAfter = time.Second
+	probeTimeout   = time.Millisecond
+)
+
+// Syslog transports
+const (
+	NetworkUDP = "udp"
+	NetworkTCP = "tcp"
+	NetworkTLS = "tls"
+)
+
+// Config selects the syslog endpoint and the message format
+type Config struct {
+	// Network is udp, tcp or tls. Stream transports separate messages with a newline.
+	Network string
+	Address string
+	Format  Format
+	// Hostname is reported in the syslog header
+	Hostname string
+	// TLSConfig is used by the tls network; nil verifies the endpoint against the system roots
+	TLSConfig *tls.Config
+}
+
+// Exporter writes security events to the syslog endpoint. Events are sent once: an event that
+// cannot be written after reconnecting is dropped, and events stay available in the audit history.
+type Exporter struct {
+	broker  *broadcast.Broker
+	cfg     Config
+	metrics *metrics.Metrics
+	logger  *zap.Logger
+
+	failures chan domain.AuthFailure
+	// conn is the open connection to the endpoint and lastWrite the time of the last message
+	// written to it; only accessed by the exporter goroutine
+	conn      net.Conn
+	lastWrite time.Time
+	idleProbe time.Duration
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// New creates an exporter; call Start to begin forwarding events
+func New(broker *broadcast.Broker, cfg Config, m *metrics.Metrics, logger *zap.Logger) *Exporter {
+	return &Exporter{
+		broker:    broker,
+		cfg:       cfg,
+		metrics:   m,
+		logger:    logger,
+		failures:  make(chan domain.AuthFailure, queueSize),
+		idleProbe: idleProbeAfter,
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+}
+
+// Start subscribes to every published entry; entries created after Start returns are exported
+func (e *Exporter) Start() {
+	sub := e.broker.SubscribeBuffered(broadcast.AllTopic, queueSize)
+	go e.run(sub)
+}
+
+// Close stops the exporter and closes the connection to the endpoint
+func (e *Exporter) Close(ctx context.Context) error {
+	e.closeOnce.Do(func() { close(e.stop) })
+
+	select {
+	case <-e.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("siem exporter did not stop: %w", ctx.Err())
+	}
+}
+
+// ReportAuthFailure queues a rejected request for export. It never blocks the request; failures
+// reported while the queue is full are dropped.
+func (e *Exporter) ReportAuthFailure(failure domain.AuthFailure) {
+	select {
+	case e.failures <- failure:
+	default:
+		e.metrics.ObserveSIEMMessage("dropped")
+	}
+}
+
+// run exports entries and rejected requests until Close is called or the broker shuts down
+func (e *Exporter) run(sub *broadcast.Subscription) {
+	defer close(e.stopped)
+	defer sub.Close()
+	defer e.closeConn()
+
+	for {
+		select {
+		case <-e.stop:
+			return
+		case entry, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if slices.Contains(ExportedEventTypes, domain.AuditAction(entry.Type)) {
+				e.send(entryRecord(entry))
+			}
+		case failure := <-e.failures:
+			e.send(authFailureRecord(failure))
+		}
+	}
+}
+
+// send formats and writes one event, reconnecting once since stream endpoints may close idle
+// connections
+func (e *Exporter) send(r record) {
+	var msg string
+	if e.cfg.Format == FormatLEEF {
+		msg = formatLEEF(r)
+	} else {
+		msg = formatCEF(r)
+	}
+	msg = syslogMessage(r, e.cfg.Hostname, msg)
+
+	err := e.write(msg)
+	if err != nil {
+		e.closeConn()
+		err = e.write(msg)
+	}
+	if err != nil {
+		e.closeConn()
+		e.metrics.ObserveSIEMMessage("failed")
+		e.logger.Error("failed to export security event",
+			zap.String("class", r.class),
+			zap.String("event_id", r.eventID),
+			zap.Error(err),
+		)
+		return
+	}
+	e.metrics.ObserveSIEMMessage("sent")
+}
+
+// write sends a message over the open connection, dialing the endpoint first when needed
+func (e *Exporter) write(msg string) error {
+	if e.conn != nil && e.cfg.Network != NetworkUDP && time.Since(e.lastWrite) >= e.idleProbe && !peerOpen(e.conn) {
+		e.closeConn()
+	}
+	if e.conn == nil {
+		conn, err := e.dial()
+		if err != nil {
+			return fmt.Errorf("failed to connect to %s: %w", e.cfg.Address, err)
+		}
+		e.conn = conn
+	}
+
+	// Datagrams carry one message each; streams use non-transparent framing
+	if e.cfg.Network != NetworkUDP {
+		msg += "\n"
+	}
+	if err := e.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	if _, err := e.conn.Write([]byte(msg)); err != nil {
+		return err
+	}
+	e.lastWrite = time.Now()
+	return nil
+}
+
+// dial opens a connection to the endpoint
+func (e *Exporter) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	if e.cfg.Network == NetworkTLS {
+		return tls.DialWithDialer(dialer, "tcp", e.cfg.Address, e.cfg.TLSConfig)
+	}
+	return dialer.Dial(e.cfg.Network, e.cfg.Address)
+}
+
+// peerOpen reports whether the endpoint has not closed an idle stream connection. Writes to a
+// closed connection succeed until the peer resets it, so a message written after the endpoint
+// went away would be lost. Syslog receivers never send data, so a read only returns at its
+// deadline while the connection is open.
+func peerOpen(conn net.Conn) bool {
+	if err := conn.SetReadDeadline(time.Now().Add(probeTimeout)); err != nil {
+		return false
+	}
+	var buf [1]byte
+	_, err := conn.Read(buf[:])
+	return errors.Is(err, os.ErrDeadlineExceeded)
+}
+
+// closeConn closes the connection so the next event dials again
+func (e *Exporter) closeConn() {
+	if e.conn != nil {
+		_ = e.conn.Close()
+		e.conn = nil
+	}
+}
@@ -0,0 +1,81 @@
+package siem
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"audit-service/internal/broadcast"
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestExporter(t *testing.T, cfg Config) (*Exporter, *broadcast.Broker) {
+	t.Helper()
+	broker := broadcast.NewBroker(zap.NewNop())
+	exporter := New(broker, cfg, metrics.New(), zap.NewNop())
+	exporter.Start()
+	t.Cleanup(func() {
+		require.NoError(t, exporter.Close(context.Background()))
+	})
+	return exporter, broker
+}
+
+func TestExporter_UDP(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	_, broker := newTestExporter(t, Config{Network: NetworkUDP, Address: listener.LocalAddr().String(), Format: FormatCEF, Hostname: "audit-1"})
+
+	// Only share, unshare and export events are exported
+	broker.Publish(domain.AuditEntry{ID: "edit-1", SessionID: testExport.SessionID, UserID: "user-1", Type: "edit", Timestamp: testTime})
+	broker.Publish(testExport)
+
+	require.NoError(t, listener.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 4096)
+	n, _, err := listener.ReadFrom(buf)
+	require.NoError(t, err)
+	msg := string(buf[:n])
+	assert.True(t, strings.HasPrefix(msg, "<108>1 2024-02-01T09:30:00.000Z audit-1 audit-service - export - CEF:0|"), msg)
+	assert.Contains(t, msg, "externalId="+testExport.ID)
+}
+
+func TestExporter_TCPReconnects(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	exporter, _ := newTestExporter(t, Config{Network: NetworkTCP, Address: listener.Addr().String(), Format: FormatLEEF})
+	// Every write checks whether the endpoint closed the connection
+	exporter.idleProbe = 0
+
+	readLine := func() string {
+		require.NoError(t, listener.(*net.TCPListener).SetDeadline(time.Now().Add(5*time.Second)))
+		conn, err := listener.Accept()
+		require.NoError(t, err)
+		defer conn.Close()
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		require.NoError(t, err)
+		return line
+	}
+
+	exporter.ReportAuthFailure(testFailure)
+	line := readLine()
+	assert.True(t, strings.HasPrefix(line, "<108>1 2024-02-01T09:30:00.000Z - audit-service - auth_failure - LEEF:2.0|"), line)
+	assert.True(t, strings.HasSuffix(line, "\tstatus=401\n"), line)
+
+	// The endpoint closed the first connection, so the next event is sent over a new one
+	failure := testFailure
+	failure.RequestID = "req-2"
+	exporter.ReportAuthFailure(failure)
+	assert.Contains(t, readLine(), "requestId=req-2")
+}
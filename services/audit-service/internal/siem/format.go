@@ -0,0 +1,231 @@
+package siem
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"audit-service/internal/domain"
+)
+
+// Format is the event format of the exported syslog messages
+type Format string
+
+// Supported formats
+const (
+	// FormatCEF is the ArcSight Common Event Format, read by Splunk and most SIEMs
+	FormatCEF Format = "cef"
+	// FormatLEEF is the IBM QRadar Log Event Extended Format 2.0
+	FormatLEEF Format = "leef"
+)
+
+// Event classes of rejected requests; audit events are classed by their type
+const (
+	ClassAuthFailure  = "auth_failure"
+	ClassAccessDenied = "access_denied"
+)
+
+const (
+	vendor         = "pptxTrans"
+	product        = "audit-service"
+	productVersion = "1.0"
+
+	// facilityLogAudit is the syslog facility of the messages
+	facilityLogAudit = 13
+
+	// leefTimeFormat is the default devTime format of LEEF, MMM dd yyyy HH:mm:ss.SSS zzz
+	leefTimeFormat = "Jan 02 2006 15:04:05.000 MST"
+)
+
+// ExportedEventTypes are the audit event types forwarded besides the rejected requests
+var ExportedEventTypes = []domain.AuditAction{domain.ActionShare, domain.ActionUnshare, domain.ActionExport}
+
+// record is a security event independent of its format. Severity ranges from 0 to 10 like in CEF.
+type record struct {
+	class    string
+	name     string
+	severity int
+	time     time.Time
+
+	userID         string
+	sourceIP       string
+	userAgent      string
+	sessionID      string
+	eventID        string
+	organizationID string
+
+	requestID string
+	method    string
+	route     string
+	status    int
+	reason    string
+}
+
+// entryRecord describes a share, unshare or export event
+func entryRecord(entry domain.AuditEntry) record {
+	r := record{
+		class:          entry.Type,
+		name:           "Session " + entry.Type,
+		severity:       3,
+		time:           entry.Timestamp.UTC(),
+		userID:         entry.UserID,
+		sourceIP:       entry.IPAddress,
+		userAgent:      entry.UserAgent,
+		sessionID:      entry.SessionID,
+		eventID:        entry.ID,
+		organizationID: entry.OrganizationID,
+	}
+	switch domain.AuditAction(entry.Type) {
+	case domain.ActionShare:
+		r.name = "Session shared"
+	case domain.ActionUnshare:
+		r.name = "Session unshared"
+	case domain.ActionExport:
+		// Exports take data out of the platform
+		r.name, r.severity = "Session exported", 5
+	}
+	return r
+}
+
+// authFailureRecord describes a request rejected with 401 or 403
+func authFailureRecord(failure domain.AuthFailure) record {
+	r := record{
+		class:     ClassAuthFailure,
+		name:      "Authentication failed",
+		severity:  5,
+		time:      failure.Time.UTC(),
+		userID:    failure.UserID,
+		sourceIP:  failure.ClientIP,
+		userAgent: failure.UserAgent,
+		requestID: failure.RequestID,
+		method:    failure.Method,
+		route:     failure.Route,
+		status:    failure.Status,
+		reason:    string(failure.Reason),
+	}
+	if failure.Status == http.StatusForbidden {
+		r.class, r.name, r.severity = ClassAccessDenied, "Access denied", 4
+	}
+	return r
+}
+
+// field is an extension key and value; empty values are left out of messages
+type field struct {
+	key   string
+	value string
+}
+
+// formatCEF renders a record as CEF:0 message
+func formatCEF(r record) string {
+	fields := []field{
+		{"rt", strconv.FormatInt(r.time.UnixMilli(), 10)},
+		{"act", r.class},
+		{"suser", r.userID},
+		{"src", r.sourceIP},
+		{"requestClientApplication", r.userAgent},
+		{"externalId", r.eventID},
+		{"requestMethod", r.method},
+		{"request", r.route},
+		{"reason", r.reason},
+	}
+	if r.sessionID != "" {
+		fields = append(fields, field{"cs1Label", "sessionId"}, field{"cs1", r.sessionID})
+	}
+	if r.organizationID != "" {
+		fields = append(fields, field{"cs2Label", "organizationId"}, field{"cs2", r.organizationID})
+	}
+	if r.requestID != "" {
+		fields = append(fields, field{"cs3Label", "requestId"}, field{"cs3", r.requestID})
+	}
+	if r.status != 0 {
+		fields = append(fields, field{"cn1Label", "httpStatus"}, field{"cn1", strconv.Itoa(r.status)}, field{"outcome", "failure"})
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "CEF:0|%s|%s|%s|%s|%s|%d|", vendor, product, productVersion,
+		cefHeaderEscaper.Replace(r.class), cefHeaderEscaper.Replace(r.name), r.severity)
+	first := true
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		if !first {
+			b.WriteByte(' ')
+		}
+		first = false
+		b.WriteString(f.key)
+		b.WriteByte('=')
+		b.WriteString(cefValueEscaper.Replace(f.value))
+	}
+	return b.String()
+}
+
+// formatLEEF renders a record as LEEF:2.0 message with tab separated attributes
+func formatLEEF(r record) string {
+	fields := []field{
+		{"devTime", r.time.Format(leefTimeFormat)},
+		{"cat", r.class},
+		{"sev", strconv.Itoa(r.severity)},
+		{"usrName", r.userID},
+		{"src", r.sourceIP},
+		{"userAgent", r.userAgent},
+		{"sessionId", r.sessionID},
+		{"eventId", r.eventID},
+		{"organizationId", r.organizationID},
+		{"requestId", r.requestID},
+		{"method", r.method},
+		{"url", r.route},
+		{"reason", r.reason},
+	}
+	if r.status != 0 {
+		fields = append(fields, field{"status", strconv.Itoa(r.status)})
+	}
+
+	var b strings.Builder
+	// LEEF has no escape sequences, so header pipes and attribute tabs are replaced
+	fmt.Fprintf(&b, "LEEF:2.0|%s|%s|%s|%s|", vendor, product, productVersion, strings.ReplaceAll(r.class, "|", "_"))
+	first := true
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		if !first {
+			b.WriteByte('\t')
+		}
+		first = false
+		b.WriteString(f.key)
+		b.WriteByte('=')
+		b.WriteString(leefValueEscaper.Replace(f.value))
+	}
+	return b.String()
+}
+
+var (
+	cefHeaderEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\n", " ", "\r", " ")
+	cefValueEscaper  = strings.NewReplacer(`\`, `\\`, "=", `\=`, "\n", `\n`, "\r", `\r`)
+	leefValueEscaper = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+)
+
+// syslogMessage frames a formatted event as RFC 5424 message of the log audit facility
+func syslogMessage(r record, hostname, msg string) string {
+	if hostname == "" {
+		hostname = "-"
+	}
+	priority := facilityLogAudit*8 + syslogSeverity(r.severity)
+	return fmt.Sprintf("<%d>1 %s %s %s - %s - %s", priority, r.time.Format("2006-01-02T15:04:05.000Z07:00"),
+		hostname, product, r.class, msg)
+}
+
+// syslogSeverity maps the 0 to 10 event severity to critical, warning or notice
+func syslogSeverity(severity int) int {
+	switch {
+	case severity >= 8:
+		return 2
+	case severity >= 5:
+		return 4
+	default:
+		return 5
+	}
+}
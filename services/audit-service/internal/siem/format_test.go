@@ -0,0 +1,89 @@
+package siem
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testTime = time.Date(2024, 2, 1, 9, 30, 0, 0, time.UTC)
+
+var testExport = domain.AuditEntry{
+	ID:             "550e8400-e29b-41d4-a716-446655440009",
+	SessionID:      "550e8400-e29b-41d4-a716-446655440000",
+	UserID:         "user-1",
+	Type:           "export",
+	Timestamp:      testTime,
+	IPAddress:      "203.0.113.7",
+	UserAgent:      "Mozilla/5.0 (X11; Linux x86_64)",
+	OrganizationID: "acme",
+}
+
+var testFailure = domain.AuthFailure{
+	Time:      testTime,
+	Method:    http.MethodGet,
+	Route:     "/api/v1/sessions/:sessionId/history",
+	Status:    http.StatusUnauthorized,
+	Reason:    domain.AuthReasonExpiredToken,
+	ClientIP:  "198.51.100.4",
+	UserAgent: "curl/8.0",
+	RequestID: "req-1",
+}
+
+func TestFormatCEF(t *testing.T) {
+	assert.Equal(t,
+		"CEF:0|pptxTrans|audit-service|1.0|export|Session exported|5|rt=1706779800000 act=export suser=user-1 "+
+			"src=203.0.113.7 requestClientApplication=Mozilla/5.0 (X11; Linux x86_64) externalId=550e8400-e29b-41d4-a716-446655440009 "+
+			"cs1Label=sessionId cs1=550e8400-e29b-41d4-a716-446655440000 cs2Label=organizationId cs2=acme",
+		formatCEF(entryRecord(testExport)))
+
+	assert.Equal(t,
+		"CEF:0|pptxTrans|audit-service|1.0|auth_failure|Authentication failed|5|rt=1706779800000 act=auth_failure "+
+			"src=198.51.100.4 requestClientApplication=curl/8.0 requestMethod=GET request=/api/v1/sessions/:sessionId/history "+
+			"reason=expired_token cs3Label=requestId cs3=req-1 cn1Label=httpStatus cn1=401 outcome=failure",
+		formatCEF(authFailureRecord(testFailure)))
+}
+
+func TestFormatCEF_Escaping(t *testing.T) {
+	entry := testExport
+	entry.UserAgent = "a=b\\c\nd"
+	entry.IPAddress = ""
+	entry.OrganizationID = ""
+
+	msg := formatCEF(entryRecord(entry))
+
+	assert.Contains(t, msg, `requestClientApplication=a\=b\\c\nd externalId=`)
+	assert.NotContains(t, msg, "src=")
+	assert.NotContains(t, msg, "cs2")
+}
+
+func TestFormatLEEF(t *testing.T) {
+	assert.Equal(t,
+		"LEEF:2.0|pptxTrans|audit-service|1.0|export|devTime=Feb 01 2024 09:30:00.000 UTC\tcat=export\tsev=5\t"+
+			"usrName=user-1\tsrc=203.0.113.7\tuserAgent=Mozilla/5.0 (X11; Linux x86_64)\t"+
+			"sessionId=550e8400-e29b-41d4-a716-446655440000\teventId=550e8400-e29b-41d4-a716-446655440009\torganizationId=acme",
+		formatLEEF(entryRecord(testExport)))
+
+	failure := testFailure
+	failure.Status = http.StatusForbidden
+	failure.Reason = domain.AuthReasonSessionAccessDenied
+	failure.UserID = "user-2"
+	failure.UserAgent = "tab\there"
+	assert.Equal(t,
+		"LEEF:2.0|pptxTrans|audit-service|1.0|access_denied|devTime=Feb 01 2024 09:30:00.000 UTC\tcat=access_denied\tsev=4\t"+
+			"usrName=user-2\tsrc=198.51.100.4\tuserAgent=tab here\trequestId=req-1\tmethod=GET\t"+
+			"url=/api/v1/sessions/:sessionId/history\treason=session_access_denied\tstatus=403",
+		formatLEEF(authFailureRecord(failure)))
+}
+
+func TestSyslogMessage(t *testing.T) {
+	r := entryRecord(testExport)
+	assert.Equal(t, "<108>1 2024-02-01T09:30:00.000Z audit-1 audit-service - export - msg", syslogMessage(r, "audit-1", "msg"))
+
+	r = entryRecord(domain.AuditEntry{Type: "share", Timestamp: testTime})
+	assert.Equal(t, "<109>1 2024-02-01T09:30:00.000Z - audit-service - share - msg", syslogMessage(r, "", "msg"))
+}
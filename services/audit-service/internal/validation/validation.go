@@ -0,0 +1,232 @@
+// Package validation adds the service's rules to the validator gin checks request bodies with
+// and turns its errors into field-level violations, worded in English or Russian following the
+// Accept-Language header of the request.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"audit-service/internal/domain"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/ru"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	rutranslations "github.com/go-playground/validator/v10/translations/ru"
+	"golang.org/x/text/language"
+)
+
+// Rules added to the binding tags of request structs
+const (
+	// RuleUUID accepts an RFC 4122 UUID in its canonical form
+	RuleUUID = "uuid_id"
+	// RuleSessionID accepts a session UUID or the ID of a test session, which starts with test-
+	RuleSessionID = "session_id"
+	// RuleEventType accepts the name of a built-in or custom event type
+	RuleEventType = "event_type"
+	// RuleTimestamp accepts an RFC 3339 timestamp string
+	RuleTimestamp = "timestamp"
+)
+
+// messages words the custom rules per language; {0} is the field name
+var messages = map[string]map[string]string{
+	"en": {
+		RuleUUID:      "{0} must be a UUID",
+		RuleSessionID: "{0} must be a session UUID",
+		RuleEventType: fmt.Sprintf("{0} must be lowercase letters, digits and underscores of up to %d characters, starting with a letter",
+			domain.MaxEventTypeNameLength),
+		RuleTimestamp: "{0} must be an RFC 3339 timestamp such as 2024-01-15T10:30:00Z",
+	},
+	"ru": {
+		RuleUUID:      "{0} должен быть UUID",
+		RuleSessionID: "{0} должен быть UUID сессии",
+		RuleEventType: fmt.Sprintf("{0} должен состоять из строчных латинских букв, цифр и подчёркиваний, начинаться с буквы и быть не длиннее %d символов",
+			domain.MaxEventTypeNameLength),
+		RuleTimestamp: "{0} должен быть меткой времени RFC 3339, например 2024-01-15T10:30:00Z",
+	},
+}
+
+// details is the detail of a validation_failed problem per language
+var details = map[string]string{
+	"en": "The request body is invalid",
+	"ru": "Тело запроса содержит ошибки",
+}
+
+var (
+	// matcher picks the supported language closest to an Accept-Language header; English comes
+	// first so it is the fallback
+	matcher = language.NewMatcher([]language.Tag{language.English, language.Russian})
+
+	translators = map[string]ut.Translator{}
+)
+
+// The rules are installed in gin's validator when the package is loaded, so every ShouldBind
+// call of a handler applies them
+func init() {
+	engine := binding.Validator.Engine().(*validator.Validate)
+	if err := register(engine); err != nil {
+		panic(fmt.Sprintf("failed to register validation rules: %v", err))
+	}
+}
+
+// register adds the custom rules and the messages of every language to a validator
+func register(engine *validator.Validate) error {
+	// Violations name fields as they appear in the JSON body
+	engine.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	rules := map[string]validator.Func{
+		RuleUUID:      func(fl validator.FieldLevel) bool { return validUUID(fl.Field().String()) },
+		RuleSessionID: func(fl validator.FieldLevel) bool { return validSessionID(fl.Field().String()) },
+		RuleEventType: func(fl validator.FieldLevel) bool { return domain.ValidEventTypeName(fl.Field().String()) },
+		RuleTimestamp: func(fl validator.FieldLevel) bool {
+			_, err := time.Parse(time.RFC3339, fl.Field().String())
+			return err == nil
+		},
+	}
+	for tag, rule := range rules {
+		if err := engine.RegisterValidation(tag, rule); err != nil {
+			return err
+		}
+	}
+
+	universal := ut.New(en.New(), en.New(), ru.New())
+	defaults := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": entranslations.RegisterDefaultTranslations,
+		"ru": rutranslations.RegisterDefaultTranslations,
+	}
+	for lang, registerDefaults := range defaults {
+		translator, _ := universal.GetTranslator(lang)
+		if err := registerDefaults(engine, translator); err != nil {
+			return err
+		}
+		for tag, message := range messages[lang] {
+			if err := engine.RegisterTranslation(tag, translator, addMessage(tag, message), translate(tag)); err != nil {
+				return err
+			}
+		}
+		translators[lang] = translator
+	}
+	return nil
+}
+
+// addMessage registers the message of a custom rule with a translator
+func addMessage(tag, message string) validator.RegisterTranslationsFunc {
+	return func(translator ut.Translator) error {
+		return translator.Add(tag, message, true)
+	}
+}
+
+// translate words a failed custom rule
+func translate(tag string) validator.TranslationFunc {
+	return func(translator ut.Translator, fe validator.FieldError) string {
+		message, err := translator.T(tag, fe.Field())
+		if err != nil {
+			return fe.Error()
+		}
+		return message
+	}
+}
+
+// validUUID reports whether id is an ID in the form domain.ParseEventID accepts
+func validUUID(id string) bool {
+	_, err := domain.ParseEventID(id)
+	return err == nil
+}
+
+// validSessionID reports whether id is a session UUID or the ID of a test session
+func validSessionID(id string) bool {
+	return domain.SessionID(id).IsTestSession() || validUUID(id)
+}
+
+// ElementError is a failed validation of one element of a list
+type ElementError struct {
+	Index int
+	Err   error
+}
+
+// Error implements the error interface
+func (e *ElementError) Error() string {
+	return fmt.Sprintf("[%d]: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the validation error of the element
+func (e *ElementError) Unwrap() error {
+	return e.Err
+}
+
+// Each validates every element of a list with the binding rules and joins the errors of the
+// failing elements, keeping their index
+func Each[T any](items []T) error {
+	var errs []error
+	for i, item := range items {
+		if err := binding.Validator.ValidateStruct(item); err != nil {
+			errs = append(errs, &ElementError{Index: i, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Language returns the supported language closest to an Accept-Language header: en or ru
+func Language(acceptLanguage string) string {
+	tag, _ := language.MatchStrings(matcher, acceptLanguage)
+	base, _ := tag.Base()
+	if base.String() == "ru" {
+		return "ru"
+	}
+	return "en"
+}
+
+// Problem returns the validation_failed problem listing every field that broke a rule, worded
+// in the language of acceptLanguage. ok is false when err holds no validation errors.
+func Problem(err error, acceptLanguage string) (apiErr *domain.APIError, ok bool) {
+	lang := Language(acceptLanguage)
+	violations := appendViolations(nil, err, "", translators[lang])
+	if len(violations) == 0 {
+		return nil, false
+	}
+
+	apiErr = domain.NewAPIError("validation_failed", details[lang], http.StatusBadRequest)
+	apiErr.Violations = violations
+	return apiErr, true
+}
+
+// appendViolations appends a violation for every field error in err, prefixing field names
+// with the index of their list element
+func appendViolations(violations []domain.SchemaViolation, err error, prefix string, translator ut.Translator) []domain.SchemaViolation {
+	var fieldErrors validator.ValidationErrors
+	var elementErr *ElementError
+	switch joined, isJoined := err.(interface{ Unwrap() []error }); {
+	case isJoined:
+		for _, err := range joined.Unwrap() {
+			violations = appendViolations(violations, err, prefix, translator)
+		}
+	case errors.As(err, &elementErr):
+		violations = appendViolations(violations, elementErr.Err, fmt.Sprintf("%s[%d].", prefix, elementErr.Index), translator)
+	case errors.As(err, &fieldErrors):
+		for _, fieldErr := range fieldErrors {
+			// The namespace starts with the name of the validated struct
+			_, field, _ := strings.Cut(fieldErr.Namespace(), ".")
+			violations = append(violations, domain.SchemaViolation{
+				Field:   prefix + field,
+				Message: fieldErr.Translate(translator),
+			})
+		}
+	}
+	return violations
+}
@@ -0,0 +1,135 @@
+package validation
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"audit-service/internal/domain"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEvent struct {
+	ID        string `json:"id,omitempty" binding:"omitempty,uuid_id"`
+	SessionID string `json:"sessionId" binding:"required,session_id"`
+	Type      string `json:"type" binding:"required,event_type"`
+	Timestamp string `json:"timestamp" binding:"omitempty,timestamp"`
+}
+
+func TestRules(t *testing.T) {
+	valid := testEvent{
+		ID:        "7c9e6679-7425-40de-944b-e07fc1f90ae7",
+		SessionID: "550e8400-e29b-41d4-a716-446655440000",
+		Type:      "terminology_approved",
+		Timestamp: "2024-01-15T10:30:00Z",
+	}
+	require.NoError(t, binding.Validator.ValidateStruct(valid))
+
+	testSession := testEvent{SessionID: "test-session-1", Type: "edit"}
+	require.NoError(t, binding.Validator.ValidateStruct(testSession))
+
+	tests := []struct {
+		name  string
+		event func(e *testEvent)
+		field string
+	}{
+		{name: "ID is not a UUID", event: func(e *testEvent) { e.ID = "event-1" }, field: "id"},
+		{name: "ID without hyphens", event: func(e *testEvent) { e.ID = "7c9e6679742540de944be07fc1f90ae7" }, field: "id"},
+		{name: "missing session", event: func(e *testEvent) { e.SessionID = "" }, field: "sessionId"},
+		{name: "session is not a UUID", event: func(e *testEvent) { e.SessionID = "session-1" }, field: "sessionId"},
+		{name: "uppercase type", event: func(e *testEvent) { e.Type = "Edit" }, field: "type"},
+		{name: "type starting with a digit", event: func(e *testEvent) { e.Type = "1edit" }, field: "type"},
+		{name: "timestamp without zone", event: func(e *testEvent) { e.Timestamp = "2024-01-15 10:30:00" }, field: "timestamp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := valid
+			tt.event(&event)
+
+			apiErr, ok := Problem(binding.Validator.ValidateStruct(event), "")
+			require.True(t, ok)
+			require.Len(t, apiErr.Violations, 1)
+			assert.Equal(t, tt.field, apiErr.Violations[0].Field)
+		})
+	}
+}
+
+func TestProblem_Languages(t *testing.T) {
+	err := binding.Validator.ValidateStruct(testEvent{SessionID: "session-1"})
+
+	apiErr, ok := Problem(err, "en-US,en;q=0.9")
+	require.True(t, ok)
+	assert.Equal(t, "validation_failed", apiErr.Code)
+	assert.Equal(t, http.StatusBadRequest, apiErr.Status)
+	assert.Equal(t, "The request body is invalid", apiErr.Message)
+	assert.Equal(t, []domain.SchemaViolation{
+		{Field: "sessionId", Message: "sessionId must be a session UUID"},
+		{Field: "type", Message: "type is a required field"},
+	}, apiErr.Violations)
+
+	apiErr, ok = Problem(err, "ru-RU,ru;q=0.9,en;q=0.8")
+	require.True(t, ok)
+	assert.Equal(t, "Тело запроса содержит ошибки", apiErr.Message)
+	assert.Equal(t, []domain.SchemaViolation{
+		{Field: "sessionId", Message: "sessionId должен быть UUID сессии"},
+		{Field: "type", Message: "type обязательное поле"},
+	}, apiErr.Violations)
+}
+
+func TestEach(t *testing.T) {
+	events := []testEvent{
+		{SessionID: "test-session-1", Type: "edit"},
+		{SessionID: "test-session-1", Type: "Edit"},
+		{SessionID: "test-session-1", Type: "edit"},
+		{SessionID: "session-1", Type: "edit"},
+	}
+
+	err := Each(events)
+	require.Error(t, err)
+
+	var elementErr *ElementError
+	require.ErrorAs(t, err, &elementErr)
+	assert.Equal(t, 1, elementErr.Index)
+
+	apiErr, ok := Problem(err, "")
+	require.True(t, ok)
+	require.Len(t, apiErr.Violations, 2)
+	assert.Equal(t, "[1].type", apiErr.Violations[0].Field)
+	assert.Equal(t, "[3].sessionId", apiErr.Violations[1].Field)
+
+	assert.NoError(t, Each(events[:1]))
+	assert.NoError(t, Each([]testEvent(nil)))
+}
+
+func TestProblem_NotValidationError(t *testing.T) {
+	_, ok := Problem(errors.New("unexpected EOF"), "")
+	assert.False(t, ok)
+
+	_, ok = Problem(nil, "")
+	assert.False(t, ok)
+}
+
+func TestLanguage(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		expected       string
+	}{
+		{acceptLanguage: "", expected: "en"},
+		{acceptLanguage: "ru", expected: "ru"},
+		{acceptLanguage: "ru-RU", expected: "ru"},
+		{acceptLanguage: "de-DE,ru;q=0.8", expected: "ru"},
+		{acceptLanguage: "en-GB,ru;q=0.5", expected: "en"},
+		{acceptLanguage: "fr", expected: "en"},
+		{acceptLanguage: "not a header", expected: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Language(tt.acceptLanguage))
+		})
+	}
+}
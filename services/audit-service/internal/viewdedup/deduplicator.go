@@ -0,0 +1,315 @@
+// Package viewdedup collapses repeated identical view events into one entry with a counter on
+// ingestion, so views of sessions left open and reloaded do not drown out the rest of their
+// history. The first view of a user in a session is held for the deduplication window; identical
+// views arriving within it are counted into it instead of being stored, and the held view is
+// written with their count once the window closes; views that fail to be written are retried.
+// Held views are kept in memory: each replica collapses the views it ingested, and views still
+// held are lost when the process is killed.
+package viewdedup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// maxSweepInterval bounds how long a held view may wait past the close of its window
+	maxSweepInterval = time.Second
+
+	// closeFlushTimeout bounds writing the held views when the deduplicator stops
+	closeFlushTimeout = 10 * time.Second
+)
+
+// WriteFunc persists the views whose window closed
+type WriteFunc func(ctx context.Context, entries []domain.AuditEntry) error
+
+// Config controls how views are collapsed
+type Config struct {
+	// Window is how long a view is held for identical views to be collapsed into it
+	Window time.Duration
+	// MaxHeld caps the views held at once; beyond it the view closest to the close of its window
+	// is written early to make room. While as many views wait to be retried, new views are passed.
+	MaxHeld int
+}
+
+// Outcome is what became of an entry added to the deduplicator
+type Outcome int
+
+const (
+	// Passed entries are not held and are stored as usual
+	Passed Outcome = iota
+	// Held views are stored when their window closes
+	Held
+	// Collapsed views were counted into a held view and are not stored
+	Collapsed
+)
+
+// heldView is a view waiting for the close of its window, with the views collapsed into it
+type heldView struct {
+	entry    domain.AuditEntry
+	count    int
+	last     time.Time
+	closesAt time.Time
+}
+
+// Deduplicator holds view events for the deduplication window and collapses identical views
+// into them. Its methods are safe for concurrent use; a nil Deduplicator passes every entry.
+type Deduplicator struct {
+	write  WriteFunc
+	cfg    Config
+	clock  clock.Clock
+	logger *zap.Logger
+
+	mu     sync.Mutex
+	held   map[string]*heldView
+	due    []*heldView
+	closed bool
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// New creates a deduplicator writing the views whose window closed through write; call Start to
+// begin writing them
+func New(write WriteFunc, cfg Config, clk clock.Clock, logger *zap.Logger) *Deduplicator {
+	return &Deduplicator{
+		write:   write,
+		cfg:     cfg,
+		clock:   clk,
+		logger:  logger,
+		held:    make(map[string]*heldView),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Start launches the loop writing the views whose window closed
+func (d *Deduplicator) Start() {
+	go d.run()
+}
+
+// Close stops holding views and writes every view still held
+func (d *Deduplicator) Close(ctx context.Context) error {
+	d.closeOnce.Do(func() { close(d.stop) })
+
+	select {
+	case <-d.stopped:
+	case <-ctx.Done():
+		return fmt.Errorf("view deduplicator did not stop: %w", ctx.Err())
+	}
+
+	d.mu.Lock()
+	d.closed = true
+	for key, view := range d.held {
+		delete(d.held, key)
+		d.due = append(d.due, view)
+	}
+	d.mu.Unlock()
+
+	if remaining := d.Flush(ctx); remaining > 0 {
+		return fmt.Errorf("%d held view events were not written", remaining)
+	}
+	return nil
+}
+
+// run writes the views whose window closed until Close is called
+func (d *Deduplicator) run() {
+	defer close(d.stopped)
+
+	ticker := time.NewTicker(min(d.cfg.Window, maxSweepInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), closeFlushTimeout)
+			d.Flush(ctx)
+			cancel()
+		}
+	}
+}
+
+// Add offers an entry for deduplication. A view identical to a held one, recorded by the same
+// user in the same session with the same details, client and links, is collapsed into it, and
+// heldID names the held view; any other view is held, unless the deduplicator is closed. Entries
+// of other types are passed.
+func (d *Deduplicator) Add(entry domain.AuditEntry) (heldID string, outcome Outcome) {
+	if d == nil || domain.AuditAction(entry.Type) != domain.ActionView {
+		return entry.ID, Passed
+	}
+
+	key, err := viewKey(entry)
+	if err != nil {
+		return entry.ID, Passed
+	}
+	now := d.clock.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return entry.ID, Passed
+	}
+	if view, ok := d.held[key]; ok {
+		if now.Before(view.closesAt) {
+			view.count++
+			if entry.Timestamp.After(view.last) {
+				view.last = entry.Timestamp
+			}
+			return view.entry.ID, Collapsed
+		}
+		// The window closed before the view was written; the new view opens the next one
+		delete(d.held, key)
+		d.due = append(d.due, view)
+	}
+	if d.cfg.MaxHeld > 0 {
+		// Views are stored as usual while storage is failing to take the views already due
+		if len(d.due) >= d.cfg.MaxHeld {
+			return entry.ID, Passed
+		}
+		if len(d.held) >= d.cfg.MaxHeld {
+			d.evict()
+		}
+	}
+
+	d.held[key] = &heldView{entry: entry, count: 1, last: entry.Timestamp, closesAt: now.Add(d.cfg.Window)}
+	return entry.ID, Held
+}
+
+// Withdraw undoes the addition of a view that was held or collapsed but could not be
+// acknowledged, such as the views of a batch whose other events failed to be stored. Views of a
+// batch are withdrawn in the reverse order they were added. A view whose window already closed
+// is not withdrawn.
+func (d *Deduplicator) Withdraw(entry domain.AuditEntry) {
+	if d == nil || domain.AuditAction(entry.Type) != domain.ActionView {
+		return
+	}
+
+	key, err := viewKey(entry)
+	if err != nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	view, ok := d.held[key]
+	if !ok {
+		return
+	}
+	if view.entry.ID == entry.ID && view.count == 1 {
+		delete(d.held, key)
+		return
+	}
+	// A held view others were collapsed into stays held for them
+	if view.count > 1 {
+		view.count--
+	}
+}
+
+// evict moves the held view closest to the close of its window to the views due to be written.
+// The caller holds d.mu.
+func (d *Deduplicator) evict() {
+	var oldest string
+	for key, view := range d.held {
+		if oldest == "" || view.closesAt.Before(d.held[oldest].closesAt) {
+			oldest = key
+		}
+	}
+	if oldest != "" {
+		d.due = append(d.due, d.held[oldest])
+		delete(d.held, oldest)
+	}
+}
+
+// Flush writes the views whose window closed, oldest first, and returns how many could not be
+// written. Views that failed to be written are logged and retried on the next flush.
+func (d *Deduplicator) Flush(ctx context.Context) int {
+	now := d.clock.Now()
+
+	d.mu.Lock()
+	views := d.due
+	d.due = nil
+	for key, view := range d.held {
+		if !now.Before(view.closesAt) {
+			delete(d.held, key)
+			views = append(views, view)
+		}
+	}
+	d.mu.Unlock()
+
+	if len(views) == 0 {
+		return 0
+	}
+
+	sort.Slice(views, func(i, j int) bool {
+		a, b := views[i].entry, views[j].entry
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.Before(b.Timestamp)
+		}
+		return a.ID < b.ID
+	})
+
+	entries := make([]domain.AuditEntry, 0, len(views))
+	collapsed := 0
+	for _, view := range views {
+		entry := view.entry
+		if view.count > 1 {
+			entry.Details = domain.WithViewRepeats(entry.Details, domain.ViewRepeats{Count: view.count, LastTimestamp: view.last})
+			collapsed += view.count - 1
+		}
+		entries = append(entries, entry)
+	}
+	if err := d.write(ctx, entries); err != nil {
+		d.logger.Error("failed to write held view events, retrying on the next flush",
+			zap.Int("count", len(entries)),
+			zap.String("first_event_id", entries[0].ID),
+			zap.Error(err),
+		)
+		d.mu.Lock()
+		d.due = append(views, d.due...)
+		d.mu.Unlock()
+		return len(entries)
+	}
+
+	d.logger.Debug("held view events written",
+		zap.Int("count", len(entries)),
+		zap.Int("collapsed", collapsed),
+	)
+	return 0
+}
+
+// viewKey identifies the views that are identical to each other
+func viewKey(entry domain.AuditEntry) (string, error) {
+	details, err := domain.DecodeDetails[interface{}](entry)
+	if err != nil {
+		return "", err
+	}
+	// Details are re-encoded with sorted keys, so the order they were sent in does not matter
+	encoded, err := domain.EncodeDetails(details)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	for _, field := range []string{entry.OrganizationID, entry.SessionID, entry.UserID, entry.IPAddress,
+		entry.UserAgent, entry.CorrelationID, entry.ParentEventID, fmt.Sprint(entry.SchemaVersion)} {
+		hash.Write([]byte(field))
+		hash.Write([]byte{0})
+	}
+	hash.Write(encoded)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,205 @@
+package viewdedup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testSession = "550e8400-e29b-41d4-a716-446655440000"
+
+var testNow = time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+
+// fakeStore records the entries it is asked to write
+type fakeStore struct {
+	writes [][]domain.AuditEntry
+	err    error
+}
+
+func (s *fakeStore) write(_ context.Context, entries []domain.AuditEntry) error {
+	s.writes = append(s.writes, entries)
+	return s.err
+}
+
+func newTestDeduplicator(cfg Config) (*Deduplicator, *fakeStore, *clock.FakeClock) {
+	store := &fakeStore{}
+	clk := clock.NewFakeClock(testNow)
+	return New(store.write, cfg, clk, zap.NewNop()), store, clk
+}
+
+func view(id, userID string, at time.Time, details string) domain.AuditEntry {
+	return domain.AuditEntry{
+		ID: id, SessionID: testSession, UserID: userID, Type: "view", Timestamp: at,
+		Details: json.RawMessage(details), IPAddress: "203.0.113.7", UserAgent: "editor/1.0",
+	}
+}
+
+func TestDeduplicator_CollapsesIdenticalViews(t *testing.T) {
+	dedup, store, clk := newTestDeduplicator(Config{Window: time.Minute, MaxHeld: 100})
+	ctx := context.Background()
+
+	heldID, outcome := dedup.Add(view("view-1", "user-1", testNow, `{"accessMethod":"jwt","slide":1}`))
+	assert.Equal(t, Held, outcome)
+	assert.Equal(t, "view-1", heldID)
+
+	// The order of the details does not matter
+	clk.Advance(10 * time.Second)
+	heldID, outcome = dedup.Add(view("view-2", "user-1", testNow.Add(10*time.Second), `{"slide":1,"accessMethod":"jwt"}`))
+	assert.Equal(t, Collapsed, outcome)
+	assert.Equal(t, "view-1", heldID)
+	clk.Advance(20 * time.Second)
+	_, outcome = dedup.Add(view("view-3", "user-1", testNow.Add(30*time.Second), `{"accessMethod":"jwt","slide":1}`))
+	assert.Equal(t, Collapsed, outcome)
+
+	// Views of other users, or with other details, are held on their own
+	_, outcome = dedup.Add(view("view-4", "user-2", testNow.Add(30*time.Second), `{"accessMethod":"jwt","slide":1}`))
+	assert.Equal(t, Held, outcome)
+	_, outcome = dedup.Add(view("view-5", "user-1", testNow.Add(30*time.Second), `{"accessMethod":"share_token","slide":1}`))
+	assert.Equal(t, Held, outcome)
+
+	// Nothing is written before the window closes
+	assert.Zero(t, dedup.Flush(ctx))
+	assert.Empty(t, store.writes)
+
+	clk.Advance(30 * time.Second)
+	assert.Zero(t, dedup.Flush(ctx))
+	require.Len(t, store.writes, 1)
+	written := store.writes[0]
+	require.Len(t, written, 1)
+	assert.Equal(t, "view-1", written[0].ID)
+	assert.Equal(t, testNow, written[0].Timestamp)
+	assert.JSONEq(t, `{"accessMethod":"jwt","slide":1,"repeats":{"count":3,"lastTimestamp":"2024-02-01T09:00:30Z"}}`, string(written[0].Details))
+
+	clk.Advance(30 * time.Second)
+	assert.Zero(t, dedup.Flush(ctx))
+	require.Len(t, store.writes, 2)
+	written = store.writes[1]
+	require.Len(t, written, 2)
+	// Views held alone are written as they were sent
+	for _, entry := range written {
+		assert.NotContains(t, string(entry.Details), "repeats")
+	}
+}
+
+func TestDeduplicator_WindowStartsAtTheHeldView(t *testing.T) {
+	dedup, store, clk := newTestDeduplicator(Config{Window: time.Minute, MaxHeld: 100})
+	ctx := context.Background()
+
+	_, outcome := dedup.Add(view("view-1", "user-1", testNow, `{}`))
+	assert.Equal(t, Held, outcome)
+
+	// A view after the close of the window opens the next one, even before the first is written
+	clk.Advance(time.Minute)
+	heldID, outcome := dedup.Add(view("view-2", "user-1", testNow.Add(time.Minute), `{}`))
+	assert.Equal(t, Held, outcome)
+	assert.Equal(t, "view-2", heldID)
+
+	assert.Zero(t, dedup.Flush(ctx))
+	require.Len(t, store.writes, 1)
+	require.Len(t, store.writes[0], 1)
+	assert.Equal(t, "view-1", store.writes[0][0].ID)
+}
+
+func TestDeduplicator_PassesOtherEvents(t *testing.T) {
+	dedup, _, _ := newTestDeduplicator(Config{Window: time.Minute, MaxHeld: 100})
+
+	entry := view("edit-1", "user-1", testNow, `{}`)
+	entry.Type = "edit"
+	heldID, outcome := dedup.Add(entry)
+	assert.Equal(t, Passed, outcome)
+	assert.Equal(t, "edit-1", heldID)
+
+	// Without a deduplicator every view is stored as it comes
+	var disabled *Deduplicator
+	_, outcome = disabled.Add(view("view-1", "user-1", testNow, `{}`))
+	assert.Equal(t, Passed, outcome)
+}
+
+func TestDeduplicator_WritesOldestViewWhenFull(t *testing.T) {
+	dedup, store, clk := newTestDeduplicator(Config{Window: time.Minute, MaxHeld: 2})
+	ctx := context.Background()
+
+	dedup.Add(view("view-1", "user-1", testNow, `{}`))
+	clk.Advance(time.Second)
+	dedup.Add(view("view-2", "user-2", testNow.Add(time.Second), `{}`))
+	clk.Advance(time.Second)
+	_, outcome := dedup.Add(view("view-3", "user-3", testNow.Add(2*time.Second), `{}`))
+	assert.Equal(t, Held, outcome)
+
+	assert.Zero(t, dedup.Flush(ctx))
+	require.Len(t, store.writes, 1)
+	require.Len(t, store.writes[0], 1)
+	assert.Equal(t, "view-1", store.writes[0][0].ID)
+}
+
+func TestDeduplicator_Close(t *testing.T) {
+	dedup, store, clk := newTestDeduplicator(Config{Window: time.Hour, MaxHeld: 100})
+	dedup.Start()
+
+	dedup.Add(view("view-1", "user-1", testNow, `{}`))
+	clk.Advance(time.Second)
+	dedup.Add(view("view-2", "user-1", testNow.Add(time.Second), `{}`))
+
+	require.NoError(t, dedup.Close(context.Background()))
+	require.Len(t, store.writes, 1)
+	require.Len(t, store.writes[0], 1)
+	assert.Contains(t, string(store.writes[0][0].Details), `"count":2`)
+
+	// Views arriving after Close are stored as they come
+	_, outcome := dedup.Add(view("view-3", "user-1", testNow.Add(2*time.Second), `{}`))
+	assert.Equal(t, Passed, outcome)
+}
+
+func TestDeduplicator_FailedWrite(t *testing.T) {
+	dedup, store, clk := newTestDeduplicator(Config{Window: time.Minute, MaxHeld: 1})
+	store.err = errors.New("storage unavailable")
+
+	dedup.Add(view("view-1", "user-1", testNow, `{}`))
+	clk.Advance(time.Minute)
+	assert.Equal(t, 1, dedup.Flush(context.Background()))
+
+	// Views are stored as usual while the ones due cannot be written
+	_, outcome := dedup.Add(view("view-2", "user-2", testNow.Add(time.Minute), `{}`))
+	assert.Equal(t, Passed, outcome)
+
+	// Views that failed to be written are retried
+	store.err = nil
+	assert.Zero(t, dedup.Flush(context.Background()))
+	require.Len(t, store.writes, 2)
+	require.Len(t, store.writes[1], 1)
+	assert.Equal(t, "view-1", store.writes[1][0].ID)
+}
+
+func TestDeduplicator_Withdraw(t *testing.T) {
+	dedup, store, clk := newTestDeduplicator(Config{Window: time.Minute, MaxHeld: 100})
+
+	first := view("view-1", "user-1", testNow, `{}`)
+	repeat := view("view-2", "user-1", testNow, `{}`)
+	dedup.Add(first)
+	dedup.Add(repeat)
+
+	// Withdrawing the repeat leaves the first view held on its own
+	dedup.Withdraw(repeat)
+	clk.Advance(time.Minute)
+	assert.Zero(t, dedup.Flush(context.Background()))
+	require.Len(t, store.writes, 1)
+	require.Len(t, store.writes[0], 1)
+	assert.NotContains(t, string(store.writes[0][0].Details), "repeats")
+
+	// A withdrawn view is not written at all
+	other := view("view-3", "user-1", testNow.Add(time.Minute), `{}`)
+	dedup.Add(other)
+	dedup.Withdraw(other)
+	clk.Advance(time.Minute)
+	assert.Zero(t, dedup.Flush(context.Background()))
+	assert.Len(t, store.writes, 1)
+}
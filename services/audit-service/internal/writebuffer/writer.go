@@ -0,0 +1,339 @@
+package writebuffer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"audit-service/internal/domain"
+	"audit-service/internal/metrics"
+
+	"go.uber.org/zap"
+)
+
+// OverflowPolicy decides what happens when an enqueue would exceed the buffer capacity
+type OverflowPolicy string
+
+// Supported overflow policies
+const (
+	// OverflowReject fails the enqueue so the client can retry later
+	OverflowReject OverflowPolicy = "reject"
+	// OverflowBlock waits for free space until the caller's context is done
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropOldest evicts the oldest queued entries to make room
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+)
+
+// Drop reasons reported in metrics
+const (
+	dropReasonOverflow = "overflow"
+	dropReasonEvicted  = "evicted"
+	dropReasonRejected = "rejected"
+)
+
+const (
+	// DefaultRetryBackoff is the wait after the first failed flush, doubled after each further one
+	DefaultRetryBackoff = time.Second
+
+	// DefaultMaxRetryBackoff caps the wait between failed flushes
+	DefaultMaxRetryBackoff = 30 * time.Second
+)
+
+var (
+	// ErrBufferFull is returned when the buffer has no room and the policy rejects new entries
+	ErrBufferFull = errors.New("write buffer is full")
+	// ErrClosed is returned when entries are enqueued after Close
+	ErrClosed = errors.New("write buffer is closed")
+)
+
+// FlushFunc persists a batch of entries
+type FlushFunc func(ctx context.Context, entries []domain.AuditEntry) error
+
+// Config controls buffering thresholds
+type Config struct {
+	Capacity      int
+	BatchSize     int
+	FlushInterval time.Duration
+	FlushTimeout  time.Duration
+	Overflow      OverflowPolicy
+	// RetryBackoff and MaxRetryBackoff bound the wait before a failed batch is written again
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+}
+
+// Writer queues audit entries in memory and flushes them in batches on size or time thresholds
+type Writer struct {
+	cfg     Config
+	flush   FlushFunc
+	metrics *metrics.Metrics
+	logger  *zap.Logger
+
+	mu     sync.Mutex
+	queue  []domain.AuditEntry
+	space  chan struct{} // closed and replaced whenever entries leave the queue
+	closed bool
+
+	full    chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// New creates a new write-behind buffer; call Start to begin flushing
+func New(flush FlushFunc, cfg Config, m *metrics.Metrics, logger *zap.Logger) *Writer {
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = DefaultRetryBackoff
+	}
+	if cfg.MaxRetryBackoff < cfg.RetryBackoff {
+		cfg.MaxRetryBackoff = max(DefaultMaxRetryBackoff, cfg.RetryBackoff)
+	}
+
+	w := &Writer{
+		cfg:     cfg,
+		flush:   flush,
+		metrics: m,
+		logger:  logger,
+		queue:   make([]domain.AuditEntry, 0, cfg.Capacity),
+		space:   make(chan struct{}),
+		full:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	m.RegisterWriteBuffer(w.Len)
+	return w
+}
+
+// Start launches the background flush loop
+func (w *Writer) Start() {
+	go w.run()
+}
+
+// Len returns the number of queued entries
+func (w *Writer) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return len(w.queue)
+}
+
+// Enqueue adds entries to the buffer, applying the overflow policy when it is full
+func (w *Writer) Enqueue(ctx context.Context, entries []domain.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	w.mu.Lock()
+	for {
+		if w.closed {
+			w.mu.Unlock()
+			return ErrClosed
+		}
+
+		overflow := len(w.queue) + len(entries) - w.cfg.Capacity
+		if overflow <= 0 {
+			break
+		}
+
+		// A request larger than the whole buffer can never fit
+		if len(entries) > w.cfg.Capacity || w.cfg.Overflow == OverflowReject {
+			w.mu.Unlock()
+			w.metrics.ObserveWriteBufferDrop(dropReasonOverflow, len(entries))
+			return ErrBufferFull
+		}
+
+		if w.cfg.Overflow == OverflowDropOldest {
+			evicted := w.queue[:overflow]
+			w.logger.Warn("write buffer full, evicting oldest entries",
+				zap.Int("count", overflow),
+				zap.String("first_event_id", evicted[0].ID),
+			)
+			w.queue = append(w.queue[:0], w.queue[overflow:]...)
+			w.metrics.ObserveWriteBufferDrop(dropReasonEvicted, overflow)
+			break
+		}
+
+		// OverflowBlock: wait until a flush frees space
+		space := w.space
+		w.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			w.metrics.ObserveWriteBufferDrop(dropReasonOverflow, len(entries))
+			return fmt.Errorf("%w: %w", ErrBufferFull, ctx.Err())
+		case <-space:
+		}
+		w.mu.Lock()
+	}
+
+	w.queue = append(w.queue, entries...)
+	reachedBatch := len(w.queue) >= w.cfg.BatchSize
+	w.mu.Unlock()
+
+	if reachedBatch {
+		select {
+		case w.full <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Close stops accepting entries and flushes everything still queued, retrying failed batches
+// until ctx is done
+func (w *Writer) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.space) // release blocked producers so they observe ErrClosed
+	w.mu.Unlock()
+
+	close(w.stop)
+	select {
+	case <-w.stopped:
+	case <-ctx.Done():
+		return fmt.Errorf("flush loop did not stop: %w", ctx.Err())
+	}
+
+	var backoff time.Duration
+	for w.flushAll(ctx) != nil && ctx.Err() == nil {
+		backoff = w.nextBackoff(backoff)
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+	}
+
+	if remaining := w.Len(); remaining > 0 {
+		return fmt.Errorf("%d audit entries were not flushed", remaining)
+	}
+	return nil
+}
+
+// run flushes queued entries until Close is called. After a failed flush it waits with an
+// exponential backoff before writing again, so an outage is not hammered with retries.
+func (w *Writer) run() {
+	defer close(w.stopped)
+
+	ticker := time.NewTicker(w.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	var backoff time.Duration
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-w.full:
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), w.cfg.FlushTimeout)
+		err := w.flushAll(ctx)
+		cancel()
+		if err == nil {
+			backoff = 0
+			continue
+		}
+
+		backoff = w.nextBackoff(backoff)
+		select {
+		case <-w.stop:
+			return
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// nextBackoff doubles the previous wait between failed flushes, within the configured bounds
+func (w *Writer) nextBackoff(previous time.Duration) time.Duration {
+	return min(max(2*previous, w.cfg.RetryBackoff), w.cfg.MaxRetryBackoff)
+}
+
+// flushAll writes queued entries in batches until the queue is empty or ctx is done. A batch
+// that cannot be written is put back at the front of the queue and the error returned.
+func (w *Writer) flushAll(ctx context.Context) error {
+	for ctx.Err() == nil {
+		batch := w.take()
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if pending, err := w.write(ctx, batch); err != nil {
+			w.logger.Error("failed to flush buffered audit entries, retrying",
+				zap.Int("count", len(pending)),
+				zap.String("first_event_id", pending[0].ID),
+				zap.Error(err),
+			)
+			w.requeue(pending)
+			return err
+		}
+	}
+	return nil
+}
+
+// write persists a batch and returns the entries left unwritten by a failure. When storage
+// rejects the batch as invalid it is split in halves, so only the offending entries are dropped
+// and the rest of the batch is still written.
+func (w *Writer) write(ctx context.Context, batch []domain.AuditEntry) ([]domain.AuditEntry, error) {
+	start := time.Now()
+	err := w.flush(ctx, batch)
+	w.metrics.ObserveWriteBufferFlush(len(batch), time.Since(start), err)
+
+	switch {
+	case err == nil:
+		return nil, nil
+	case !errors.Is(err, domain.ErrInvalidEvent):
+		return batch, err
+	case len(batch) == 1:
+		w.logger.Error("dropping buffered audit entry rejected by storage",
+			zap.String("event_id", batch[0].ID),
+			zap.String("session_id", batch[0].SessionID),
+			zap.String("type", batch[0].Type),
+			zap.Error(err),
+		)
+		w.metrics.ObserveWriteBufferDrop(dropReasonRejected, 1)
+		return nil, nil
+	}
+
+	half := len(batch) / 2
+	if pending, err := w.write(ctx, batch[:half]); err != nil {
+		return slices.Concat(pending, batch[half:]), err
+	}
+	return w.write(ctx, batch[half:])
+}
+
+// requeue puts entries that failed to flush back at the front of the queue. The queue may
+// briefly exceed its capacity when producers have taken the space the entries left.
+func (w *Writer) requeue(entries []domain.AuditEntry) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.queue = slices.Concat(entries, w.queue)
+}
+
+// take removes up to one batch from the front of the queue
+func (w *Writer) take() []domain.AuditEntry {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := min(len(w.queue), w.cfg.BatchSize)
+	if n == 0 {
+		return nil
+	}
+
+	batch := make([]domain.AuditEntry, n)
+	copy(batch, w.queue[:n])
+	w.queue = append(w.queue[:0], w.queue[n:]...)
+
+	// Wake producers blocked on a full buffer
+	if !w.closed {
+		close(w.space)
+		w.space = make(chan struct{})
+	}
+
+	return batch
+}
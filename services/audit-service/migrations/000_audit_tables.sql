@@ -0,0 +1,31 @@
+-- Tables the service reads and writes, for databases not provisioned by Supabase.
+-- On Supabase they already exist and these statements do nothing.
+create table if not exists sessions (
+  id uuid primary key default gen_random_uuid(),
+  user_id text not null
+);
+
+create table if not exists session_shares (
+  id uuid primary key default gen_random_uuid(),
+  session_id uuid not null references sessions (id) on delete cascade,
+  share_token_jti text not null,
+  permissions text[] not null default array['VIEW'],
+  expires_at timestamptz,
+  revoked_at timestamptz,
+  created_by text
+);
+
+create index if not exists session_shares_jti_idx on session_shares (share_token_jti, session_id);
+
+create table if not exists audit_logs (
+  id uuid primary key default gen_random_uuid(),
+  session_id uuid not null,
+  user_id text not null,
+  type text not null,
+  "timestamp" timestamptz not null default now(),
+  details jsonb,
+  ip_address text,
+  user_agent text
+);
+
+create index if not exists audit_logs_session_timestamp_idx on audit_logs (session_id, "timestamp" desc, id desc);
@@ -0,0 +1,39 @@
+-- Aggregated audit statistics for a single session, used by GET /sessions/:sessionId/stats.
+-- Computing the numbers in the database avoids transferring every event to the service.
+create or replace function public.session_audit_stats(p_session_id uuid)
+returns jsonb
+language sql
+stable
+as $$
+  select jsonb_build_object(
+    'total_events', count(*),
+    'contributors', count(distinct user_id),
+    'first_activity', min("timestamp"),
+    'last_activity', max("timestamp"),
+    'by_type', coalesce((
+      select jsonb_object_agg(type, n)
+      from (
+        select type, count(*) as n
+        from audit_logs
+        where session_id = p_session_id
+        group by type
+      ) counts
+    ), '{}'::jsonb),
+    'edits_per_slide', coalesce((
+      select jsonb_object_agg(slide_id, n)
+      from (
+        select details->>'slideId' as slide_id, count(*) as n
+        from audit_logs
+        where session_id = p_session_id
+          and type = 'edit'
+          and details ? 'slideId'
+        group by details->>'slideId'
+      ) edits
+    ), '{}'::jsonb)
+  )
+  from audit_logs
+  where session_id = p_session_id;
+$$;
+
+-- Supports the per-session aggregates above
+create index if not exists audit_logs_session_type_idx on audit_logs (session_id, type);
@@ -0,0 +1,41 @@
+-- Deletes audit events of one type that are older than the cutoff, used by the retention job.
+-- At most p_limit rows are removed per call so large backlogs are purged in short transactions.
+-- Returns the number of deleted rows per session so the service can record a purge summary.
+create or replace function public.purge_audit_logs(p_type text, p_before timestamptz, p_limit integer)
+returns jsonb
+language plpgsql
+as $$
+declare
+  result jsonb;
+begin
+  -- Only one service replica purges at a time; the others see an empty result
+  if not pg_try_advisory_xact_lock(hashtext('purge_audit_logs')) then
+    return '[]'::jsonb;
+  end if;
+
+  with expired as (
+    select id
+    from audit_logs
+    where type = p_type
+      and "timestamp" < p_before
+    limit p_limit
+  ), deleted as (
+    delete from audit_logs a
+    using expired e
+    where a.id = e.id
+    returning a.session_id
+  )
+  select coalesce(jsonb_agg(jsonb_build_object('session_id', session_id, 'deleted', n)), '[]'::jsonb)
+  into result
+  from (
+    select session_id, count(*) as n
+    from deleted
+    group by session_id
+  ) counts;
+
+  return result;
+end;
+$$;
+
+-- Lets the purge find expired rows of a type without scanning the table
+create index if not exists audit_logs_type_timestamp_idx on audit_logs (type, "timestamp");
@@ -0,0 +1,18 @@
+-- Deletes specific audit events by id, used by the retention job once they have been archived.
+-- Returns the number of deleted rows per session in the same shape as purge_audit_logs.
+create or replace function public.delete_audit_logs(p_ids uuid[])
+returns jsonb
+language sql
+as $$
+  with deleted as (
+    delete from audit_logs
+    where id = any(p_ids)
+    returning session_id
+  )
+  select coalesce(jsonb_agg(jsonb_build_object('session_id', session_id, 'deleted', n)), '[]'::jsonb)
+  from (
+    select session_id, count(*) as n
+    from deleted
+    group by session_id
+  ) counts;
+$$;
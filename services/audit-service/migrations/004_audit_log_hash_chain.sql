@@ -0,0 +1,58 @@
+-- Tamper-evident hash chain per session, verified by GET /sessions/:sessionId/events/verify.
+-- The service stores content_hash, a SHA-256 of the canonical entry payload. This trigger
+-- numbers the entries of each session and sets
+--   chain_hash = sha256(prev_hash || content_hash)
+-- where prev_hash is the chain_hash of the previous entry in the session.
+-- Entries written before this migration keep a null seq and are not part of the chain.
+alter table audit_logs
+  add column if not exists seq bigint,
+  add column if not exists content_hash text,
+  add column if not exists prev_hash text,
+  add column if not exists chain_hash text;
+
+create unique index if not exists audit_logs_session_seq_idx on audit_logs (session_id, seq);
+
+create or replace function public.audit_logs_chain()
+returns trigger
+language plpgsql
+as $$
+declare
+  last_seq bigint;
+  last_hash text;
+begin
+  -- Serializes inserts per session so concurrent writers cannot fork the chain
+  perform pg_advisory_xact_lock(hashtext('audit_logs_chain:' || new.session_id::text));
+
+  select seq, chain_hash into last_seq, last_hash
+  from audit_logs
+  where session_id = new.session_id
+    and seq is not null
+  order by seq desc
+  limit 1;
+
+  new.seq := coalesce(last_seq, 0) + 1;
+  new.prev_hash := coalesce(last_hash, '');
+  new.chain_hash := encode(sha256(convert_to(new.prev_hash || coalesce(new.content_hash, ''), 'UTF8')), 'hex');
+  return new;
+end;
+$$;
+
+drop trigger if exists audit_logs_chain on audit_logs;
+create trigger audit_logs_chain
+  before insert on audit_logs
+  for each row execute function public.audit_logs_chain();
+
+-- Stored entries are never edited; changes have to bypass this trigger and then fail verification
+create or replace function public.audit_logs_immutable()
+returns trigger
+language plpgsql
+as $$
+begin
+  raise exception 'audit_logs entries cannot be modified';
+end;
+$$;
+
+drop trigger if exists audit_logs_immutable on audit_logs;
+create trigger audit_logs_immutable
+  before update on audit_logs
+  for each row execute function public.audit_logs_immutable();
@@ -0,0 +1,79 @@
+-- Data-subject erasure for DELETE /users/:userId/events.
+-- Anonymized entries keep their original hashes and are marked with redacted_at, so chain
+-- verification still checks their links but skips the content comparison.
+alter table audit_logs add column if not exists redacted_at timestamptz;
+
+-- Replaces the function from 004: erase_user_audit_logs may blank personal data, nothing else
+create or replace function public.audit_logs_immutable()
+returns trigger
+language plpgsql
+as $$
+begin
+  if current_setting('audit.allow_redaction', true) = 'on'
+    and new.redacted_at is not null
+    and new.id = old.id
+    and new.session_id = old.session_id
+    and new.type = old.type
+    and new."timestamp" = old."timestamp"
+    and new.seq is not distinct from old.seq
+    and new.content_hash is not distinct from old.content_hash
+    and new.prev_hash is not distinct from old.prev_hash
+    and new.chain_hash is not distinct from old.chain_hash then
+    return new;
+  end if;
+
+  raise exception 'audit_logs entries cannot be modified';
+end;
+$$;
+
+-- Anonymizes or deletes up to p_limit entries of a user and returns the affected count per session
+create or replace function public.erase_user_audit_logs(p_user_id text, p_mode text, p_limit integer)
+returns jsonb
+language plpgsql
+as $$
+declare
+  result jsonb;
+begin
+  if p_mode = 'delete' then
+    with target as (
+      select id from audit_logs where user_id = p_user_id limit p_limit
+    ), affected as (
+      delete from audit_logs a
+      using target t
+      where a.id = t.id
+      returning a.session_id
+    )
+    select coalesce(jsonb_agg(jsonb_build_object('session_id', session_id, 'deleted', n)), '[]'::jsonb)
+    into result
+    from (select session_id, count(*) as n from affected group by session_id) counts;
+
+  elsif p_mode = 'anonymize' then
+    -- Transaction-local, lets audit_logs_immutable accept the redaction below
+    perform set_config('audit.allow_redaction', 'on', true);
+
+    with target as (
+      select id from audit_logs where user_id = p_user_id limit p_limit
+    ), affected as (
+      update audit_logs a
+      set user_id = 'redacted',
+          ip_address = null,
+          user_agent = null,
+          details = coalesce(a.details, '{}'::jsonb) - array['text', 'comment', 'email', 'name', 'userName', 'userEmail'],
+          redacted_at = now()
+      from target t
+      where a.id = t.id
+      returning a.session_id
+    )
+    select coalesce(jsonb_agg(jsonb_build_object('session_id', session_id, 'deleted', n)), '[]'::jsonb)
+    into result
+    from (select session_id, count(*) as n from affected group by session_id) counts;
+
+  else
+    raise exception 'unknown erasure mode %', p_mode;
+  end if;
+
+  return result;
+end;
+$$;
+
+create index if not exists audit_logs_user_id_idx on audit_logs (user_id);
@@ -0,0 +1,80 @@
+-- Transactional outbox for forwarding audit events to webhooks and the notification service.
+-- When OUTBOX_WEBHOOK_URLS is set the service writes each event through insert_audit_logs, which
+-- stores the audit_logs row and its outbox message in one transaction. The relay worker claims
+-- pending messages with a lease, delivers them and deletes them once every destination accepted.
+create table if not exists audit_outbox (
+  id bigserial primary key,
+  event_id uuid not null unique,
+  session_id uuid not null,
+  event_type text not null,
+  payload jsonb not null,
+  attempts integer not null default 0,
+  available_at timestamptz not null default now(),
+  locked_until timestamptz,
+  last_error text,
+  dead_at timestamptz,
+  created_at timestamptz not null default now()
+);
+
+create index if not exists audit_outbox_pending_idx on audit_outbox (available_at, id) where dead_at is null;
+
+-- Inserts audit entries and their outbox messages atomically.
+-- A message that already exists for an event is kept, so retried writes do not duplicate deliveries.
+create or replace function public.insert_audit_logs(p_logs jsonb, p_outbox jsonb)
+returns void
+language sql
+as $$
+  insert into audit_logs (id, session_id, user_id, type, "timestamp", details, ip_address, user_agent, content_hash)
+  select id, session_id, user_id, type, "timestamp", details, ip_address, user_agent, content_hash
+  from jsonb_populate_recordset(null::audit_logs, p_logs);
+
+  insert into audit_outbox (event_id, session_id, event_type, payload)
+  select (m->>'event_id')::uuid, (m->>'session_id')::uuid, m->>'event_type', m->'payload'
+  from jsonb_array_elements(p_outbox) as m
+  on conflict (event_id) do nothing;
+$$;
+
+-- Leases up to p_limit due messages to the caller and counts the delivery attempt.
+-- Messages leased by a relay that stopped become due again once the lease expires.
+create or replace function public.claim_audit_outbox(p_limit integer, p_lease_seconds integer)
+returns jsonb
+language sql
+as $$
+  with claimed as (
+    update audit_outbox o
+    set locked_until = now() + make_interval(secs => p_lease_seconds),
+        attempts = o.attempts + 1
+    where o.id in (
+      select id from audit_outbox
+      where dead_at is null
+        and available_at <= now()
+        and (locked_until is null or locked_until < now())
+      order by id
+      limit p_limit
+      for update skip locked
+    )
+    returning o.id, o.event_id, o.session_id, o.event_type, o.payload, o.attempts, o.created_at
+  )
+  select coalesce(jsonb_agg(to_jsonb(claimed) order by id), '[]'::jsonb) from claimed;
+$$;
+
+-- Removes delivered messages
+create or replace function public.complete_audit_outbox(p_ids bigint[])
+returns void
+language sql
+as $$
+  delete from audit_outbox where id = any(p_ids);
+$$;
+
+-- Releases a message after a failed delivery, either for a retry at p_retry_at or for good when p_dead is set
+create or replace function public.fail_audit_outbox(p_id bigint, p_retry_at timestamptz, p_error text, p_dead boolean)
+returns void
+language sql
+as $$
+  update audit_outbox
+  set locked_until = null,
+      available_at = p_retry_at,
+      last_error = p_error,
+      dead_at = case when p_dead then now() end
+  where id = p_id;
+$$;
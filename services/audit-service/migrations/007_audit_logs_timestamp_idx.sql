@@ -0,0 +1,4 @@
+-- Cross-session admin queries list events newest first without a session_id condition, and
+-- most of them filter by user. These indexes keep both from scanning the whole table.
+create index if not exists audit_logs_timestamp_idx on audit_logs ("timestamp" desc, id desc);
+create index if not exists audit_logs_user_timestamp_idx on audit_logs (user_id, "timestamp" desc);
@@ -0,0 +1,26 @@
+-- Correlation IDs group the events of one operation (export requested, processed, downloaded)
+-- and parent_event_id links an event to the one that caused it. GET /events/:id/chain reads
+-- a chain through the session/correlation index.
+alter table audit_logs
+  add column if not exists correlation_id text,
+  add column if not exists parent_event_id uuid;
+
+create index if not exists audit_logs_session_correlation_idx on audit_logs (session_id, correlation_id, "timestamp")
+  where correlation_id is not null;
+
+-- Replaces the function from 006 so transactional writes store the new columns
+create or replace function public.insert_audit_logs(p_logs jsonb, p_outbox jsonb)
+returns void
+language sql
+as $$
+  insert into audit_logs (id, session_id, user_id, type, "timestamp", details, ip_address, user_agent, content_hash,
+    correlation_id, parent_event_id)
+  select id, session_id, user_id, type, "timestamp", details, ip_address, user_agent, content_hash,
+    correlation_id, parent_event_id
+  from jsonb_populate_recordset(null::audit_logs, p_logs);
+
+  insert into audit_outbox (event_id, session_id, event_type, payload)
+  select (m->>'event_id')::uuid, (m->>'session_id')::uuid, m->>'event_type', m->'payload'
+  from jsonb_array_elements(p_outbox) as m
+  on conflict (event_id) do nothing;
+$$;
@@ -0,0 +1,20 @@
+-- Version of the details schema an event was recorded with. Null for events stored before
+-- versioning, which the service reads as version 1 and upgrades like any other old version.
+alter table audit_logs add column if not exists schema_version integer;
+
+-- Replaces the function from 008 so transactional writes store the schema version
+create or replace function public.insert_audit_logs(p_logs jsonb, p_outbox jsonb)
+returns void
+language sql
+as $$
+  insert into audit_logs (id, session_id, user_id, type, "timestamp", details, ip_address, user_agent, content_hash,
+    correlation_id, parent_event_id, schema_version)
+  select id, session_id, user_id, type, "timestamp", details, ip_address, user_agent, content_hash,
+    correlation_id, parent_event_id, schema_version
+  from jsonb_populate_recordset(null::audit_logs, p_logs);
+
+  insert into audit_outbox (event_id, session_id, event_type, payload)
+  select (m->>'event_id')::uuid, (m->>'session_id')::uuid, m->>'event_type', m->'payload'
+  from jsonb_array_elements(p_outbox) as m
+  on conflict (event_id) do nothing;
+$$;
@@ -0,0 +1,10 @@
+-- Custom event types registered with POST /api/v1/event-types. Every instance loads them at
+-- startup and refreshes them periodically; events of unregistered types are rejected.
+create table if not exists audit_event_types (
+  name text primary key,
+  display_name text not null,
+  severity text not null check (severity in ('info', 'low', 'medium', 'high')),
+  schema jsonb,
+  created_by text not null,
+  created_at timestamptz not null default now()
+);
@@ -0,0 +1,172 @@
+-- Legal holds exempt the entries of a session or user from retention purges and erasure.
+-- Releasing a hold sets released_at; rows are never deleted so every hold can be reviewed.
+create table if not exists audit_legal_holds (
+  id uuid primary key default gen_random_uuid(),
+  scope text not null check (scope in ('session', 'user')),
+  target text not null,
+  reason text not null,
+  placed_by text not null,
+  placed_at timestamptz not null default now(),
+  released_by text,
+  released_at timestamptz
+);
+
+create index if not exists audit_legal_holds_active_idx on audit_legal_holds (scope, target) where released_at is null;
+
+-- Reports whether an entry of the session and user is under an active legal hold
+create or replace function public.audit_log_on_hold(p_session_id uuid, p_user_id text)
+returns boolean
+language sql
+stable
+as $$
+  select exists (
+    select 1
+    from audit_legal_holds h
+    where h.released_at is null
+      and ((h.scope = 'session' and h.target = p_session_id::text)
+        or (h.scope = 'user' and h.target = p_user_id))
+  );
+$$;
+
+-- Releases an active hold and returns it, or nothing when no active hold has the ID
+create or replace function public.release_audit_legal_hold(p_id uuid, p_released_by text, p_released_at timestamptz)
+returns setof audit_legal_holds
+language sql
+as $$
+  update audit_legal_holds
+  set released_by = p_released_by,
+      released_at = p_released_at
+  where id = p_id
+    and released_at is null
+  returning *;
+$$;
+
+-- Replaces the function from 002: held entries are skipped
+create or replace function public.purge_audit_logs(p_type text, p_before timestamptz, p_limit integer)
+returns jsonb
+language plpgsql
+as $$
+declare
+  result jsonb;
+begin
+  -- Only one service replica purges at a time; the others see an empty result
+  if not pg_try_advisory_xact_lock(hashtext('purge_audit_logs')) then
+    return '[]'::jsonb;
+  end if;
+
+  with expired as (
+    select id
+    from audit_logs
+    where type = p_type
+      and "timestamp" < p_before
+      and not public.audit_log_on_hold(session_id, user_id)
+    limit p_limit
+  ), deleted as (
+    delete from audit_logs a
+    using expired e
+    where a.id = e.id
+    returning a.session_id
+  )
+  select coalesce(jsonb_agg(jsonb_build_object('session_id', session_id, 'deleted', n)), '[]'::jsonb)
+  into result
+  from (
+    select session_id, count(*) as n
+    from deleted
+    group by session_id
+  ) counts;
+
+  return result;
+end;
+$$;
+
+-- Returns the oldest expired entries of a type that are not held, for archival before deletion
+create or replace function public.find_expired_audit_logs(p_type text, p_before timestamptz, p_limit integer)
+returns setof audit_logs
+language sql
+stable
+as $$
+  select *
+  from audit_logs
+  where type = p_type
+    and "timestamp" < p_before
+    and not public.audit_log_on_hold(session_id, user_id)
+  order by "timestamp" asc, id asc
+  limit p_limit;
+$$;
+
+-- Replaces the function from 003: entries placed on hold after they were archived are kept
+create or replace function public.delete_audit_logs(p_ids uuid[])
+returns jsonb
+language sql
+as $$
+  with deleted as (
+    delete from audit_logs
+    where id = any(p_ids)
+      and not public.audit_log_on_hold(session_id, user_id)
+    returning session_id
+  )
+  select coalesce(jsonb_agg(jsonb_build_object('session_id', session_id, 'deleted', n)), '[]'::jsonb)
+  from (
+    select session_id, count(*) as n
+    from deleted
+    group by session_id
+  ) counts;
+$$;
+
+-- Replaces the function from 005: held entries are only erased when p_override_holds is set
+drop function if exists public.erase_user_audit_logs(text, text, integer);
+
+create or replace function public.erase_user_audit_logs(p_user_id text, p_mode text, p_limit integer, p_override_holds boolean default false)
+returns jsonb
+language plpgsql
+as $$
+declare
+  result jsonb;
+begin
+  if p_mode = 'delete' then
+    with target as (
+      select id from audit_logs
+      where user_id = p_user_id
+        and (p_override_holds or not public.audit_log_on_hold(session_id, user_id))
+      limit p_limit
+    ), affected as (
+      delete from audit_logs a
+      using target t
+      where a.id = t.id
+      returning a.session_id
+    )
+    select coalesce(jsonb_agg(jsonb_build_object('session_id', session_id, 'deleted', n)), '[]'::jsonb)
+    into result
+    from (select session_id, count(*) as n from affected group by session_id) counts;
+
+  elsif p_mode = 'anonymize' then
+    -- Transaction-local, lets audit_logs_immutable accept the redaction below
+    perform set_config('audit.allow_redaction', 'on', true);
+
+    with target as (
+      select id from audit_logs
+      where user_id = p_user_id
+        and (p_override_holds or not public.audit_log_on_hold(session_id, user_id))
+      limit p_limit
+    ), affected as (
+      update audit_logs a
+      set user_id = 'redacted',
+          ip_address = null,
+          user_agent = null,
+          details = coalesce(a.details, '{}'::jsonb) - array['text', 'comment', 'email', 'name', 'userName', 'userEmail'],
+          redacted_at = now()
+      from target t
+      where a.id = t.id
+      returning a.session_id
+    )
+    select coalesce(jsonb_agg(jsonb_build_object('session_id', session_id, 'deleted', n)), '[]'::jsonb)
+    into result
+    from (select session_id, count(*) as n from affected group by session_id) counts;
+
+  else
+    raise exception 'unknown erasure mode %', p_mode;
+  end if;
+
+  return result;
+end;
+$$;
@@ -0,0 +1,20 @@
+-- Paths of the details values masked by the redaction stage on ingestion, e.g. shapes[0].text.
+-- Null for events stored without redaction.
+alter table audit_logs add column if not exists redacted_fields text[];
+
+-- Replaces the function from 009 so transactional writes store the redacted fields
+create or replace function public.insert_audit_logs(p_logs jsonb, p_outbox jsonb)
+returns void
+language sql
+as $$
+  insert into audit_logs (id, session_id, user_id, type, "timestamp", details, ip_address, user_agent, content_hash,
+    correlation_id, parent_event_id, schema_version, redacted_fields)
+  select id, session_id, user_id, type, "timestamp", details, ip_address, user_agent, content_hash,
+    correlation_id, parent_event_id, schema_version, redacted_fields
+  from jsonb_populate_recordset(null::audit_logs, p_logs);
+
+  insert into audit_outbox (event_id, session_id, event_type, payload)
+  select (m->>'event_id')::uuid, (m->>'session_id')::uuid, m->>'event_type', m->'payload'
+  from jsonb_array_elements(p_outbox) as m
+  on conflict (event_id) do nothing;
+$$;
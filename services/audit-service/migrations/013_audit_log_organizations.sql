@@ -0,0 +1,208 @@
+-- Organization (tenant) of each entry, taken from the organization_id app_metadata claim of the
+-- caller. Null for entries of the default organization, including all entries written before.
+alter table audit_logs add column if not exists organization_id text;
+
+create index if not exists audit_logs_organization_timestamp_idx on audit_logs (organization_id, "timestamp" desc, id desc);
+
+-- Holds are only listed and released by admins of the organization that placed them
+alter table audit_legal_holds add column if not exists organization_id text;
+
+-- Replaces the function from 012 so transactional writes store the organization
+create or replace function public.insert_audit_logs(p_logs jsonb, p_outbox jsonb)
+returns void
+language sql
+as $$
+  insert into audit_logs (id, session_id, user_id, type, "timestamp", details, ip_address, user_agent, content_hash,
+    correlation_id, parent_event_id, schema_version, redacted_fields, organization_id)
+  select id, session_id, user_id, type, "timestamp", details, ip_address, user_agent, content_hash,
+    correlation_id, parent_event_id, schema_version, redacted_fields, organization_id
+  from jsonb_populate_recordset(null::audit_logs, p_logs);
+
+  insert into audit_outbox (event_id, session_id, event_type, payload)
+  select (m->>'event_id')::uuid, (m->>'session_id')::uuid, m->>'event_type', m->'payload'
+  from jsonb_array_elements(p_outbox) as m
+  on conflict (event_id) do nothing;
+$$;
+
+-- Replaces the function from 001. A null p_organization_id counts the entries of every
+-- organization; the empty string selects the default organization.
+drop function if exists public.session_audit_stats(uuid);
+
+create or replace function public.session_audit_stats(p_session_id uuid, p_organization_id text default null)
+returns jsonb
+language sql
+stable
+as $$
+  with scoped as (
+    select *
+    from audit_logs
+    where session_id = p_session_id
+      and (p_organization_id is null or coalesce(organization_id, '') = p_organization_id)
+  )
+  select jsonb_build_object(
+    'total_events', count(*),
+    'contributors', count(distinct user_id),
+    'first_activity', min("timestamp"),
+    'last_activity', max("timestamp"),
+    'by_type', coalesce((
+      select jsonb_object_agg(type, n)
+      from (
+        select type, count(*) as n
+        from scoped
+        group by type
+      ) counts
+    ), '{}'::jsonb),
+    'edits_per_slide', coalesce((
+      select jsonb_object_agg(slide_id, n)
+      from (
+        select details->>'slideId' as slide_id, count(*) as n
+        from scoped
+        where type = 'edit'
+          and details ? 'slideId'
+        group by details->>'slideId'
+      ) edits
+    ), '{}'::jsonb)
+  )
+  from scoped;
+$$;
+
+-- Replaces the function from 011 so holds can be released by admins of their organization only
+drop function if exists public.release_audit_legal_hold(uuid, text, timestamptz);
+
+create or replace function public.release_audit_legal_hold(p_id uuid, p_released_by text, p_released_at timestamptz,
+  p_organization_id text default null)
+returns setof audit_legal_holds
+language sql
+as $$
+  update audit_legal_holds
+  set released_by = p_released_by,
+      released_at = p_released_at
+  where id = p_id
+    and released_at is null
+    and (p_organization_id is null or coalesce(organization_id, '') = p_organization_id)
+  returning *;
+$$;
+
+-- Replaces the function from 011: counts are reported per session and organization, so the
+-- summary events of a purge are recorded in the organization of the purged entries
+create or replace function public.purge_audit_logs(p_type text, p_before timestamptz, p_limit integer)
+returns jsonb
+language plpgsql
+as $$
+declare
+  result jsonb;
+begin
+  -- Only one service replica purges at a time; the others see an empty result
+  if not pg_try_advisory_xact_lock(hashtext('purge_audit_logs')) then
+    return '[]'::jsonb;
+  end if;
+
+  with expired as (
+    select id
+    from audit_logs
+    where type = p_type
+      and "timestamp" < p_before
+      and not public.audit_log_on_hold(session_id, user_id)
+    limit p_limit
+  ), deleted as (
+    delete from audit_logs a
+    using expired e
+    where a.id = e.id
+    returning a.session_id, a.organization_id
+  )
+  select coalesce(jsonb_agg(jsonb_strip_nulls(jsonb_build_object(
+    'session_id', session_id, 'organization_id', organization_id, 'deleted', n))), '[]'::jsonb)
+  into result
+  from (
+    select session_id, organization_id, count(*) as n
+    from deleted
+    group by session_id, organization_id
+  ) counts;
+
+  return result;
+end;
+$$;
+
+-- Replaces the function from 011 with counts per session and organization
+create or replace function public.delete_audit_logs(p_ids uuid[])
+returns jsonb
+language sql
+as $$
+  with deleted as (
+    delete from audit_logs
+    where id = any(p_ids)
+      and not public.audit_log_on_hold(session_id, user_id)
+    returning session_id, organization_id
+  )
+  select coalesce(jsonb_agg(jsonb_strip_nulls(jsonb_build_object(
+    'session_id', session_id, 'organization_id', organization_id, 'deleted', n))), '[]'::jsonb)
+  from (
+    select session_id, organization_id, count(*) as n
+    from deleted
+    group by session_id, organization_id
+  ) counts;
+$$;
+
+-- Replaces the function from 011: erasure is limited to the entries of p_organization_id
+-- unless it is null, and counts are reported per session and organization
+drop function if exists public.erase_user_audit_logs(text, text, integer, boolean);
+
+create or replace function public.erase_user_audit_logs(p_user_id text, p_mode text, p_limit integer,
+  p_override_holds boolean default false, p_organization_id text default null)
+returns jsonb
+language plpgsql
+as $$
+declare
+  result jsonb;
+begin
+  if p_mode = 'delete' then
+    with target as (
+      select id from audit_logs
+      where user_id = p_user_id
+        and (p_organization_id is null or coalesce(organization_id, '') = p_organization_id)
+        and (p_override_holds or not public.audit_log_on_hold(session_id, user_id))
+      limit p_limit
+    ), affected as (
+      delete from audit_logs a
+      using target t
+      where a.id = t.id
+      returning a.session_id, a.organization_id
+    )
+    select coalesce(jsonb_agg(jsonb_strip_nulls(jsonb_build_object(
+      'session_id', session_id, 'organization_id', organization_id, 'deleted', n))), '[]'::jsonb)
+    into result
+    from (select session_id, organization_id, count(*) as n from affected group by session_id, organization_id) counts;
+
+  elsif p_mode = 'anonymize' then
+    -- Transaction-local, lets audit_logs_immutable accept the redaction below
+    perform set_config('audit.allow_redaction', 'on', true);
+
+    with target as (
+      select id from audit_logs
+      where user_id = p_user_id
+        and (p_organization_id is null or coalesce(organization_id, '') = p_organization_id)
+        and (p_override_holds or not public.audit_log_on_hold(session_id, user_id))
+      limit p_limit
+    ), affected as (
+      update audit_logs a
+      set user_id = 'redacted',
+          ip_address = null,
+          user_agent = null,
+          details = coalesce(a.details, '{}'::jsonb) - array['text', 'comment', 'email', 'name', 'userName', 'userEmail'],
+          redacted_at = now()
+      from target t
+      where a.id = t.id
+      returning a.session_id, a.organization_id
+    )
+    select coalesce(jsonb_agg(jsonb_strip_nulls(jsonb_build_object(
+      'session_id', session_id, 'organization_id', organization_id, 'deleted', n))), '[]'::jsonb)
+    into result
+    from (select session_id, organization_id, count(*) as n from affected group by session_id, organization_id) counts;
+
+  else
+    raise exception 'unknown erasure mode %', p_mode;
+  end if;
+
+  return result;
+end;
+$$;
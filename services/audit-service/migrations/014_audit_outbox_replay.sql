@@ -0,0 +1,20 @@
+-- Requeues dead outbox messages, for example after a webhook destination was down for longer
+-- than the retry schedule. Messages created at or after p_since are made due immediately with
+-- a fresh attempt count; a null p_since replays every dead message. Returns the number requeued.
+create or replace function public.replay_audit_outbox(p_since timestamptz default null)
+returns integer
+language sql
+as $$
+  with replayed as (
+    update audit_outbox
+    set attempts = 0,
+        available_at = now(),
+        locked_until = null,
+        last_error = null,
+        dead_at = null
+    where dead_at is not null
+      and (p_since is null or created_at >= p_since)
+    returning 1
+  )
+  select count(*)::integer from replayed;
+$$;
@@ -0,0 +1,33 @@
+-- Revocations reject access tokens before they expire: every token of a user, the tokens of a
+-- login session (the session_id claim) or a single token (the jti claim). Lifting a revocation
+-- sets lifted_at; rows are never deleted so every revocation can be reviewed.
+create table if not exists audit_token_revocations (
+  id uuid primary key default gen_random_uuid(),
+  kind text not null check (kind in ('user', 'session', 'token')),
+  target text not null,
+  reason text not null,
+  revoked_by text not null,
+  revoked_at timestamptz not null default now(),
+  expires_at timestamptz,
+  lifted_by text,
+  lifted_at timestamptz,
+  organization_id text
+);
+
+create index if not exists audit_token_revocations_active_idx on audit_token_revocations (kind, target) where lifted_at is null;
+
+-- Lifts a revocation of the organization and returns it, or nothing when no revocation that is
+-- not lifted has the ID. A null p_organization_id matches every organization.
+create or replace function public.lift_audit_token_revocation(p_id uuid, p_lifted_by text, p_lifted_at timestamptz,
+  p_organization_id text default null)
+returns setof audit_token_revocations
+language sql
+as $$
+  update audit_token_revocations
+  set lifted_by = p_lifted_by,
+      lifted_at = p_lifted_at
+  where id = p_id
+    and lifted_at is null
+    and (p_organization_id is null or coalesce(organization_id, '') = p_organization_id)
+  returning *;
+$$;
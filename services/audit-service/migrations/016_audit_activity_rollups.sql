@@ -0,0 +1,63 @@
+-- Hourly and daily event counts per session and user, rebuilt from audit_logs by the rollup job
+-- so activity charts of long-running sessions do not aggregate the raw events on every request.
+create table if not exists audit_activity_rollups (
+  granularity text not null check (granularity in ('hour', 'day')),
+  bucket_start timestamptz not null,
+  session_id uuid not null,
+  user_id text not null,
+  event_count integer not null,
+  by_type jsonb not null default '{}'::jsonb,
+  organization_id text
+);
+
+create index if not exists audit_activity_rollups_session_idx on audit_activity_rollups (session_id, granularity, bucket_start);
+create index if not exists audit_activity_rollups_bucket_idx on audit_activity_rollups (bucket_start);
+create index if not exists audit_activity_rollups_user_idx on audit_activity_rollups (user_id);
+
+-- Replaces the rollups starting in [p_from, p_to) with the aggregates of the events recorded in
+-- that range and returns the number of rollups written. p_from must be the start of a UTC day,
+-- so the day rollups are rebuilt from all of their events.
+create or replace function public.rollup_audit_activity(p_from timestamptz, p_to timestamptz)
+returns integer
+language plpgsql
+as $$
+declare
+  written integer;
+begin
+  -- Replicas rolling up at once wait for each other instead of interleaving deletes and inserts
+  perform pg_advisory_xact_lock(hashtext('rollup_audit_activity'));
+
+  delete from audit_activity_rollups
+  where bucket_start >= p_from
+    and bucket_start < p_to;
+
+  with counts as (
+    select g.granularity,
+      date_trunc(g.granularity, l."timestamp" at time zone 'UTC') at time zone 'UTC' as bucket_start,
+      l.session_id, l.user_id, l.organization_id, l.type, count(*) as n
+    from audit_logs l
+    cross join (values ('hour'), ('day')) as g (granularity)
+    where l."timestamp" >= p_from
+      and l."timestamp" < p_to
+    group by 1, 2, l.session_id, l.user_id, l.organization_id, l.type
+  )
+  insert into audit_activity_rollups (granularity, bucket_start, session_id, user_id, event_count, by_type, organization_id)
+  select granularity, bucket_start, session_id, user_id, sum(n), jsonb_object_agg(type, n), organization_id
+  from counts
+  group by granularity, bucket_start, session_id, user_id, organization_id;
+
+  get diagnostics written = row_count;
+  return written;
+end;
+$$;
+
+-- Deletes the rollups of a user whose entries were erased. A null p_organization_id matches
+-- every organization.
+create or replace function public.delete_user_audit_activity(p_user_id text, p_organization_id text default null)
+returns void
+language sql
+as $$
+  delete from audit_activity_rollups
+  where user_id = p_user_id
+    and (p_organization_id is null or coalesce(organization_id, '') = p_organization_id);
+$$;
@@ -0,0 +1,41 @@
+-- Slide and shape of each entry, copied from details.slideId and details.shapeId by the service so
+-- the history of a slide is read from an index instead of searching details. Null for entries
+-- without them.
+alter table audit_logs add column if not exists slide_id text;
+alter table audit_logs add column if not exists shape_id text;
+
+create index if not exists audit_logs_session_slide_idx on audit_logs (session_id, slide_id, "timestamp" desc, id desc)
+  where slide_id is not null;
+create index if not exists audit_logs_session_shape_idx on audit_logs (session_id, shape_id, "timestamp" desc, id desc)
+  where shape_id is not null;
+
+-- Entries written before keep their references in details only. The columns repeat hashed
+-- details, so the immutability trigger is bypassed for this backfill, within the migration's
+-- transaction. Encrypted details cannot be read here and stay without references.
+alter table audit_logs disable trigger audit_logs_immutable;
+
+update audit_logs
+set slide_id = case when jsonb_typeof(details->'slideId') = 'string' then details->>'slideId' end,
+    shape_id = case when jsonb_typeof(details->'shapeId') = 'string' then details->>'shapeId' end
+where slide_id is null
+  and shape_id is null
+  and (jsonb_typeof(details->'slideId') = 'string' or jsonb_typeof(details->'shapeId') = 'string');
+
+alter table audit_logs enable trigger audit_logs_immutable;
+
+-- Replaces the function from 013 so transactional writes store the references
+create or replace function public.insert_audit_logs(p_logs jsonb, p_outbox jsonb)
+returns void
+language sql
+as $$
+  insert into audit_logs (id, session_id, user_id, type, "timestamp", details, ip_address, user_agent, content_hash,
+    correlation_id, parent_event_id, schema_version, redacted_fields, organization_id, slide_id, shape_id)
+  select id, session_id, user_id, type, "timestamp", details, ip_address, user_agent, content_hash,
+    correlation_id, parent_event_id, schema_version, redacted_fields, organization_id, slide_id, shape_id
+  from jsonb_populate_recordset(null::audit_logs, p_logs);
+
+  insert into audit_outbox (event_id, session_id, event_type, payload)
+  select (m->>'event_id')::uuid, (m->>'session_id')::uuid, m->>'event_type', m->'payload'
+  from jsonb_array_elements(p_outbox) as m
+  on conflict (event_id) do nothing;
+$$;
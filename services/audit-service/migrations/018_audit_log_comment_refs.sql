@@ -0,0 +1,42 @@
+-- Comment and thread of each entry, copied from details.commentId and details.threadId by the
+-- service so the comments panel reads the activity of a thread from an index instead of
+-- searching details. Null for entries without them.
+alter table audit_logs add column if not exists comment_id text;
+alter table audit_logs add column if not exists thread_id text;
+
+create index if not exists audit_logs_session_thread_idx on audit_logs (session_id, thread_id, "timestamp" desc, id desc)
+  where thread_id is not null;
+create index if not exists audit_logs_session_comment_idx on audit_logs (session_id, comment_id, "timestamp" desc, id desc)
+  where comment_id is not null;
+
+-- As in 017, references of entries written before are copied with the immutability trigger
+-- bypassed within the migration's transaction; encrypted details stay without references.
+alter table audit_logs disable trigger audit_logs_immutable;
+
+update audit_logs
+set comment_id = case when jsonb_typeof(details->'commentId') = 'string' then details->>'commentId' end,
+    thread_id = case when jsonb_typeof(details->'threadId') = 'string' then details->>'threadId' end
+where comment_id is null
+  and thread_id is null
+  and (jsonb_typeof(details->'commentId') = 'string' or jsonb_typeof(details->'threadId') = 'string');
+
+alter table audit_logs enable trigger audit_logs_immutable;
+
+-- Replaces the function from 017 so transactional writes store the references
+create or replace function public.insert_audit_logs(p_logs jsonb, p_outbox jsonb)
+returns void
+language sql
+as $$
+  insert into audit_logs (id, session_id, user_id, type, "timestamp", details, ip_address, user_agent, content_hash,
+    correlation_id, parent_event_id, schema_version, redacted_fields, organization_id, slide_id, shape_id,
+    comment_id, thread_id)
+  select id, session_id, user_id, type, "timestamp", details, ip_address, user_agent, content_hash,
+    correlation_id, parent_event_id, schema_version, redacted_fields, organization_id, slide_id, shape_id,
+    comment_id, thread_id
+  from jsonb_populate_recordset(null::audit_logs, p_logs);
+
+  insert into audit_outbox (event_id, session_id, event_type, payload)
+  select (m->>'event_id')::uuid, (m->>'session_id')::uuid, m->>'event_type', m->'payload'
+  from jsonb_array_elements(p_outbox) as m
+  on conflict (event_id) do nothing;
+$$;
@@ -0,0 +1,28 @@
+-- Hourly event counts per slide of a session for the heatmap of GET /sessions/:id/heatmap, read
+-- from the slide_id column of 017. Slides are numbered from the slides table of the editor;
+-- slides that no longer exist there get a null slide_index. A null p_organization_id matches
+-- every organization.
+create or replace function public.session_slide_heatmap(p_session_id uuid, p_from timestamptz, p_to timestamptz,
+  p_user_id text default null, p_organization_id text default null)
+returns table (slide_id text, slide_index integer, hour timestamptz, event_count integer)
+language sql
+stable
+as $$
+  with counts as (
+    select l.slide_id,
+      date_trunc('hour', l."timestamp" at time zone 'UTC') at time zone 'UTC' as hour,
+      count(*)::integer as event_count
+    from audit_logs l
+    where l.session_id = p_session_id
+      and l.slide_id is not null
+      and l."timestamp" >= p_from
+      and l."timestamp" < p_to
+      and (p_user_id is null or l.user_id = p_user_id)
+      and (p_organization_id is null or coalesce(l.organization_id, '') = p_organization_id)
+    group by 1, 2
+  )
+  select c.slide_id, s.slide_number, c.hour, c.event_count
+  from counts c
+  left join slides s on s.id::text = c.slide_id and s.session_id::text = p_session_id::text
+  order by s.slide_number nulls last, c.slide_id, c.hour;
+$$;
@@ -0,0 +1,26 @@
+-- Contribution report of a session: edits, comments and merges per user with their first and
+-- last activity, for GET /sessions/:id/contributors. Comments are the comment and
+-- comment_created events. Null bounds are open; a null p_organization_id matches every
+-- organization.
+create or replace function public.session_contributors(p_session_id uuid, p_from timestamptz default null,
+  p_to timestamptz default null, p_organization_id text default null)
+returns table (user_id text, edits integer, comments integer, merges integer, total_events integer,
+  first_activity timestamptz, last_activity timestamptz)
+language sql
+stable
+as $$
+  select l.user_id,
+    (count(*) filter (where l.type = 'edit'))::integer,
+    (count(*) filter (where l.type in ('comment', 'comment_created')))::integer,
+    (count(*) filter (where l.type = 'merge'))::integer,
+    count(*)::integer,
+    min(l."timestamp"),
+    max(l."timestamp")
+  from audit_logs l
+  where l.session_id = p_session_id
+    and (p_from is null or l."timestamp" >= p_from)
+    and (p_to is null or l."timestamp" <= p_to)
+    and (p_organization_id is null or coalesce(l.organization_id, '') = p_organization_id)
+  group by l.user_id
+  order by count(*) desc, l.user_id;
+$$;
@@ -0,0 +1,37 @@
+-- Watches subscribe users to the share, export and comment events of a session, which are
+-- forwarded to the notification sink in digests. A user watches a session at most once;
+-- watching it again replaces the watched event types and keeps created_at.
+create table if not exists audit_session_watches (
+  session_id uuid not null,
+  user_id text not null,
+  event_types text[] not null,
+  created_at timestamptz not null default now(),
+  organization_id text,
+  primary key (session_id, user_id)
+);
+
+create index if not exists audit_session_watches_user_idx on audit_session_watches (user_id, created_at desc);
+
+-- Creates or replaces the watch of a user on a session and returns it
+create or replace function public.put_audit_session_watch(p_session_id uuid, p_user_id text, p_event_types text[],
+  p_created_at timestamptz, p_organization_id text default null)
+returns setof audit_session_watches
+language sql
+as $$
+  insert into audit_session_watches (session_id, user_id, event_types, created_at, organization_id)
+  values (p_session_id, p_user_id, p_event_types, p_created_at, p_organization_id)
+  on conflict (session_id, user_id) do update
+  set event_types = excluded.event_types
+  returning *;
+$$;
+
+-- Deletes the watch of a user on a session and returns it, or nothing when there is none
+create or replace function public.delete_audit_session_watch(p_session_id uuid, p_user_id text)
+returns setof audit_session_watches
+language sql
+as $$
+  delete from audit_session_watches
+  where session_id = p_session_id
+    and user_id = p_user_id
+  returning *;
+$$;
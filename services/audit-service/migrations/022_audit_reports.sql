@@ -0,0 +1,62 @@
+-- Reports are recurring summaries of the audit events of an organization, generated on a cron
+-- schedule and delivered to a webhook or Supabase Storage. Every generation is recorded as a run.
+create table if not exists audit_reports (
+  id uuid primary key default gen_random_uuid(),
+  name text not null,
+  kind text not null check (kind in ('session_activity', 'export_summary')),
+  session_id uuid,
+  schedule text not null,
+  format text not null check (format in ('csv', 'json')),
+  delivery text not null check (delivery in ('webhook', 'storage')),
+  target text not null,
+  created_by text not null,
+  created_at timestamptz not null default now(),
+  next_run_at timestamptz not null,
+  last_run_at timestamptz,
+  organization_id text
+);
+
+create index if not exists audit_reports_next_run_idx on audit_reports (next_run_at);
+
+create table if not exists audit_report_runs (
+  id uuid primary key default gen_random_uuid(),
+  report_id uuid not null references audit_reports (id) on delete cascade,
+  triggered_by text not null check (triggered_by in ('schedule', 'manual')),
+  status text not null check (status in ('succeeded', 'failed')),
+  period_from timestamptz not null,
+  period_to timestamptz not null,
+  started_at timestamptz not null,
+  finished_at timestamptz not null,
+  row_count integer not null default 0,
+  location text,
+  error text,
+  organization_id text
+);
+
+create index if not exists audit_report_runs_report_idx on audit_report_runs (report_id, started_at desc);
+
+-- Moves the schedule of a report on from the run due at p_scheduled_at and returns the report,
+-- or nothing when another replica already claimed that run
+create or replace function public.advance_audit_report(p_id uuid, p_scheduled_at timestamptz, p_next_run_at timestamptz)
+returns setof audit_reports
+language sql
+as $$
+  update audit_reports
+  set last_run_at = p_scheduled_at,
+      next_run_at = p_next_run_at
+  where id = p_id
+    and next_run_at = p_scheduled_at
+  returning *;
+$$;
+
+-- Deletes a report of the organization with its runs and returns it, or nothing when there is
+-- none. A null p_organization_id matches every organization.
+create or replace function public.delete_audit_report(p_id uuid, p_organization_id text default null)
+returns setof audit_reports
+language sql
+as $$
+  delete from audit_reports
+  where id = p_id
+    and (p_organization_id is null or coalesce(organization_id, '') = p_organization_id)
+  returning *;
+$$;
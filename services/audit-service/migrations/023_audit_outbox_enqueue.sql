@@ -0,0 +1,23 @@
+-- Queues stored events for forwarding again, for example after a webhook consumer lost data.
+-- Events without an outbox message get a new one; dead messages are made due again with a
+-- fresh attempt count, and messages still waiting for delivery are left alone. Returns the
+-- number of messages queued.
+create or replace function public.enqueue_audit_outbox(p_outbox jsonb)
+returns integer
+language sql
+as $$
+  with queued as (
+    insert into audit_outbox (event_id, session_id, event_type, payload)
+    select (m->>'event_id')::uuid, (m->>'session_id')::uuid, m->>'event_type', m->'payload'
+    from jsonb_array_elements(p_outbox) as m
+    on conflict (event_id) do update
+    set attempts = 0,
+        available_at = now(),
+        locked_until = null,
+        last_error = null,
+        dead_at = null
+    where audit_outbox.dead_at is not null
+    returning 1
+  )
+  select count(*)::integer from queued;
+$$;
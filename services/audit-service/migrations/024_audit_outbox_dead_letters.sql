@@ -0,0 +1,42 @@
+-- Lists and requeues dead outbox messages one by one, for operators inspecting failed deliveries.
+-- A null p_organization_id matches messages of every organization; the empty string selects the
+-- default organization, whose payloads carry no organizationId.
+
+-- Returns up to p_limit dead messages with an id below p_before_id, newest first
+create or replace function public.list_dead_audit_outbox(p_limit integer, p_before_id bigint default null, p_organization_id text default null)
+returns jsonb
+language sql
+stable
+as $$
+  with dead as (
+    select id, event_id, session_id, event_type, payload, attempts, last_error, created_at, dead_at
+    from audit_outbox
+    where dead_at is not null
+      and (p_before_id is null or id < p_before_id)
+      and (p_organization_id is null or coalesce(payload->>'organizationId', '') = p_organization_id)
+    order by id desc
+    limit p_limit
+  )
+  select coalesce(jsonb_agg(to_jsonb(dead) order by id desc), '[]'::jsonb) from dead;
+$$;
+
+-- Makes one dead message due again with a fresh attempt count. Returns false when no dead
+-- message of the organization has the id.
+create or replace function public.retry_dead_audit_outbox(p_id bigint, p_organization_id text default null)
+returns boolean
+language sql
+as $$
+  with retried as (
+    update audit_outbox
+    set attempts = 0,
+        available_at = now(),
+        locked_until = null,
+        last_error = null,
+        dead_at = null
+    where id = p_id
+      and dead_at is not null
+      and (p_organization_id is null or coalesce(payload->>'organizationId', '') = p_organization_id)
+    returning 1
+  )
+  select exists (select 1 from retried);
+$$;
@@ -0,0 +1,23 @@
+-- Server time each entry arrived at. "timestamp" is the time the client says the action happened
+-- and may be off by its clock skew; received_at is recorded by the service and is authoritative.
+-- Null for entries written before and entries the service wrote itself.
+alter table audit_logs add column if not exists received_at timestamptz;
+
+-- Replaces the function from 018 so transactional writes store the received time
+create or replace function public.insert_audit_logs(p_logs jsonb, p_outbox jsonb)
+returns void
+language sql
+as $$
+  insert into audit_logs (id, session_id, user_id, type, "timestamp", details, ip_address, user_agent, content_hash,
+    correlation_id, parent_event_id, schema_version, redacted_fields, organization_id, slide_id, shape_id,
+    comment_id, thread_id, received_at)
+  select id, session_id, user_id, type, "timestamp", details, ip_address, user_agent, content_hash,
+    correlation_id, parent_event_id, schema_version, redacted_fields, organization_id, slide_id, shape_id,
+    comment_id, thread_id, received_at
+  from jsonb_populate_recordset(null::audit_logs, p_logs);
+
+  insert into audit_outbox (event_id, session_id, event_type, payload)
+  select (m->>'event_id')::uuid, (m->>'session_id')::uuid, m->>'event_type', m->'payload'
+  from jsonb_array_elements(p_outbox) as m
+  on conflict (event_id) do nothing;
+$$;
@@ -0,0 +1,45 @@
+-- Saved queries are named filter sets of the event queries, such as "My edits this week". They are
+-- private to their creator or shared with every user of the organization.
+create table if not exists audit_saved_queries (
+  id uuid primary key default gen_random_uuid(),
+  name text not null,
+  description text not null default '',
+  scope text not null check (scope in ('private', 'organization')),
+  filter jsonb not null default '{}'::jsonb,
+  created_by text not null,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  organization_id text
+);
+
+create index if not exists audit_saved_queries_created_by_idx on audit_saved_queries (created_by);
+
+-- Replaces a saved query of the organization and returns it, or nothing when there is none. A
+-- null p_organization_id matches every organization.
+create or replace function public.update_audit_saved_query(p_id uuid, p_name text, p_description text, p_scope text,
+  p_filter jsonb, p_updated_at timestamptz, p_organization_id text default null)
+returns setof audit_saved_queries
+language sql
+as $$
+  update audit_saved_queries
+  set name = p_name,
+      description = p_description,
+      scope = p_scope,
+      filter = p_filter,
+      updated_at = p_updated_at
+  where id = p_id
+    and (p_organization_id is null or coalesce(organization_id, '') = p_organization_id)
+  returning *;
+$$;
+
+-- Deletes a saved query of the organization and returns it, or nothing when there is none. A
+-- null p_organization_id matches every organization.
+create or replace function public.delete_audit_saved_query(p_id uuid, p_organization_id text default null)
+returns setof audit_saved_queries
+language sql
+as $$
+  delete from audit_saved_queries
+  where id = p_id
+    and (p_organization_id is null or coalesce(organization_id, '') = p_organization_id)
+  returning *;
+$$;
@@ -0,0 +1,44 @@
+-- Labels reviewers keep on audit events, such as "billing-relevant" or "disputed". They are stored
+-- beside the events, which stay unchanged, as one versioned label set per event; the labels of an
+-- event are deleted with it.
+create table if not exists audit_event_labels (
+  event_id uuid primary key references audit_logs (id) on delete cascade,
+  session_id uuid not null,
+  labels text[] not null default '{}',
+  version bigint not null check (version > 0),
+  updated_by text not null,
+  updated_at timestamptz not null default now(),
+  organization_id text
+);
+
+create index if not exists audit_event_labels_session_idx on audit_event_labels (session_id);
+create index if not exists audit_event_labels_labels_idx on audit_event_labels using gin (labels);
+
+-- Stores the label set of an event at p_version, provided the stored set is at the version before,
+-- no set standing for version 0, and returns it. Returns nothing when another change was stored
+-- first. Event IDs are unique across organizations, so the event's organization is only stored.
+create or replace function public.put_audit_event_labels(p_event_id uuid, p_session_id uuid, p_labels text[],
+  p_version bigint, p_updated_by text, p_updated_at timestamptz, p_organization_id text default null)
+returns setof audit_event_labels
+language plpgsql
+as $$
+begin
+  if p_version = 1 then
+    return query
+      insert into audit_event_labels (event_id, session_id, labels, version, updated_by, updated_at, organization_id)
+      values (p_event_id, p_session_id, p_labels, 1, p_updated_by, p_updated_at, p_organization_id)
+      on conflict (event_id) do nothing
+      returning *;
+  else
+    return query
+      update audit_event_labels
+      set labels = p_labels,
+          version = p_version,
+          updated_by = p_updated_by,
+          updated_at = p_updated_at
+      where event_id = p_event_id
+        and version = p_version - 1
+      returning *;
+  end if;
+end;
+$$;
@@ -0,0 +1,48 @@
+-- Annotations reviewers attach to audit events, such as the findings of a security investigation.
+-- The annotations of an event form its thread; they are stored beside the events, which stay
+-- unchanged, and deleted with them. Each edit moves an annotation to the next version.
+create table if not exists audit_event_annotations (
+  id uuid primary key,
+  event_id uuid not null references audit_logs (id) on delete cascade,
+  session_id uuid not null,
+  author_id text not null,
+  body text not null,
+  version bigint not null check (version > 0),
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  organization_id text
+);
+
+create index if not exists audit_event_annotations_event_idx on audit_event_annotations (event_id, created_at);
+
+-- Replaces the body of an annotation at p_version, provided it is at the version before, and
+-- returns it. Returns nothing when the annotation is gone or another edit was stored first. A null
+-- p_organization_id matches every organization.
+create or replace function public.update_audit_event_annotation(p_id uuid, p_body text, p_version bigint,
+  p_updated_at timestamptz, p_organization_id text default null)
+returns setof audit_event_annotations
+language sql
+as $$
+  update audit_event_annotations
+  set body = p_body,
+      version = p_version,
+      updated_at = p_updated_at
+  where id = p_id
+    and version = p_version - 1
+    and (p_organization_id is null or coalesce(organization_id, '') = p_organization_id)
+  returning *;
+$$;
+
+-- Deletes an annotation at p_version and returns it. Returns nothing when the annotation is gone
+-- or was edited since. A null p_organization_id matches every organization.
+create or replace function public.delete_audit_event_annotation(p_id uuid, p_version bigint,
+  p_organization_id text default null)
+returns setof audit_event_annotations
+language sql
+as $$
+  delete from audit_event_annotations
+  where id = p_id
+    and version = p_version
+    and (p_organization_id is null or coalesce(organization_id, '') = p_organization_id)
+  returning *;
+$$;
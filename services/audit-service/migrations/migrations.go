@@ -0,0 +1,9 @@
+// Package migrations embeds the Postgres schema of the audit service.
+package migrations
+
+import "embed"
+
+// Files holds the SQL migrations, applied in file name order
+//
+//go:embed *.sql
+var Files embed.FS
@@ -9,6 +9,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	repository "audit-service/internal/repository"
+
+	time "time"
 )
 
 // MockAuditRepository is an autogenerated mock type for the AuditRepository type
@@ -24,9 +26,283 @@ func (_m *MockAuditRepository) EXPECT() *MockAuditRepository_Expecter {
 	return &MockAuditRepository_Expecter{mock: &_m.Mock}
 }
 
-// FindBySessionID provides a mock function with given fields: ctx, sessionID, limit, offset
-func (_m *MockAuditRepository) FindBySessionID(ctx context.Context, sessionID string, limit int, offset int) ([]domain.AuditEntry, int, error) {
-	ret := _m.Called(ctx, sessionID, limit, offset)
+// CreateEvents provides a mock function with given fields: ctx, entries
+func (_m *MockAuditRepository) CreateEvents(ctx context.Context, entries []domain.AuditEntry) error {
+	ret := _m.Called(ctx, entries)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateEvents")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.AuditEntry) error); ok {
+		r0 = rf(ctx, entries)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAuditRepository_CreateEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateEvents'
+type MockAuditRepository_CreateEvents_Call struct {
+	*mock.Call
+}
+
+// CreateEvents is a helper method to define mock.On call
+//   - ctx context.Context
+//   - entries []domain.AuditEntry
+func (_e *MockAuditRepository_Expecter) CreateEvents(ctx interface{}, entries interface{}) *MockAuditRepository_CreateEvents_Call {
+	return &MockAuditRepository_CreateEvents_Call{Call: _e.mock.On("CreateEvents", ctx, entries)}
+}
+
+func (_c *MockAuditRepository_CreateEvents_Call) Run(run func(ctx context.Context, entries []domain.AuditEntry)) *MockAuditRepository_CreateEvents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]domain.AuditEntry))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_CreateEvents_Call) Return(_a0 error) *MockAuditRepository_CreateEvents_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAuditRepository_CreateEvents_Call) RunAndReturn(run func(context.Context, []domain.AuditEntry) error) *MockAuditRepository_CreateEvents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteEvents provides a mock function with given fields: ctx, ids
+func (_m *MockAuditRepository) DeleteEvents(ctx context.Context, ids []string) ([]domain.PurgedEvents, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteEvents")
+	}
+
+	var r0 []domain.PurgedEvents
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]domain.PurgedEvents, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []domain.PurgedEvents); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.PurgedEvents)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_DeleteEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteEvents'
+type MockAuditRepository_DeleteEvents_Call struct {
+	*mock.Call
+}
+
+// DeleteEvents is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ids []string
+func (_e *MockAuditRepository_Expecter) DeleteEvents(ctx interface{}, ids interface{}) *MockAuditRepository_DeleteEvents_Call {
+	return &MockAuditRepository_DeleteEvents_Call{Call: _e.mock.On("DeleteEvents", ctx, ids)}
+}
+
+func (_c *MockAuditRepository_DeleteEvents_Call) Run(run func(ctx context.Context, ids []string)) *MockAuditRepository_DeleteEvents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_DeleteEvents_Call) Return(_a0 []domain.PurgedEvents, _a1 error) *MockAuditRepository_DeleteEvents_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditRepository_DeleteEvents_Call) RunAndReturn(run func(context.Context, []string) ([]domain.PurgedEvents, error)) *MockAuditRepository_DeleteEvents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteUserActivity provides a mock function with given fields: ctx, userID
+func (_m *MockAuditRepository) DeleteUserActivity(ctx context.Context, userID domain.UserID) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUserActivity")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.UserID) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAuditRepository_DeleteUserActivity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUserActivity'
+type MockAuditRepository_DeleteUserActivity_Call struct {
+	*mock.Call
+}
+
+// DeleteUserActivity is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID domain.UserID
+func (_e *MockAuditRepository_Expecter) DeleteUserActivity(ctx interface{}, userID interface{}) *MockAuditRepository_DeleteUserActivity_Call {
+	return &MockAuditRepository_DeleteUserActivity_Call{Call: _e.mock.On("DeleteUserActivity", ctx, userID)}
+}
+
+func (_c *MockAuditRepository_DeleteUserActivity_Call) Run(run func(ctx context.Context, userID domain.UserID)) *MockAuditRepository_DeleteUserActivity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.UserID))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_DeleteUserActivity_Call) Return(_a0 error) *MockAuditRepository_DeleteUserActivity_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAuditRepository_DeleteUserActivity_Call) RunAndReturn(run func(context.Context, domain.UserID) error) *MockAuditRepository_DeleteUserActivity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EraseUserEvents provides a mock function with given fields: ctx, userID, mode, overrideHolds, limit
+func (_m *MockAuditRepository) EraseUserEvents(ctx context.Context, userID domain.UserID, mode domain.ErasureMode, overrideHolds bool, limit int) ([]domain.PurgedEvents, error) {
+	ret := _m.Called(ctx, userID, mode, overrideHolds, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EraseUserEvents")
+	}
+
+	var r0 []domain.PurgedEvents
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.UserID, domain.ErasureMode, bool, int) ([]domain.PurgedEvents, error)); ok {
+		return rf(ctx, userID, mode, overrideHolds, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.UserID, domain.ErasureMode, bool, int) []domain.PurgedEvents); ok {
+		r0 = rf(ctx, userID, mode, overrideHolds, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.PurgedEvents)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.UserID, domain.ErasureMode, bool, int) error); ok {
+		r1 = rf(ctx, userID, mode, overrideHolds, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_EraseUserEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EraseUserEvents'
+type MockAuditRepository_EraseUserEvents_Call struct {
+	*mock.Call
+}
+
+// EraseUserEvents is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID domain.UserID
+//   - mode domain.ErasureMode
+//   - overrideHolds bool
+//   - limit int
+func (_e *MockAuditRepository_Expecter) EraseUserEvents(ctx interface{}, userID interface{}, mode interface{}, overrideHolds interface{}, limit interface{}) *MockAuditRepository_EraseUserEvents_Call {
+	return &MockAuditRepository_EraseUserEvents_Call{Call: _e.mock.On("EraseUserEvents", ctx, userID, mode, overrideHolds, limit)}
+}
+
+func (_c *MockAuditRepository_EraseUserEvents_Call) Run(run func(ctx context.Context, userID domain.UserID, mode domain.ErasureMode, overrideHolds bool, limit int)) *MockAuditRepository_EraseUserEvents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.UserID), args[2].(domain.ErasureMode), args[3].(bool), args[4].(int))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_EraseUserEvents_Call) Return(_a0 []domain.PurgedEvents, _a1 error) *MockAuditRepository_EraseUserEvents_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditRepository_EraseUserEvents_Call) RunAndReturn(run func(context.Context, domain.UserID, domain.ErasureMode, bool, int) ([]domain.PurgedEvents, error)) *MockAuditRepository_EraseUserEvents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExistingEventIDs provides a mock function with given fields: ctx, ids
+func (_m *MockAuditRepository) ExistingEventIDs(ctx context.Context, ids []string) ([]string, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExistingEventIDs")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]string, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []string); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_ExistingEventIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExistingEventIDs'
+type MockAuditRepository_ExistingEventIDs_Call struct {
+	*mock.Call
+}
+
+// ExistingEventIDs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ids []string
+func (_e *MockAuditRepository_Expecter) ExistingEventIDs(ctx interface{}, ids interface{}) *MockAuditRepository_ExistingEventIDs_Call {
+	return &MockAuditRepository_ExistingEventIDs_Call{Call: _e.mock.On("ExistingEventIDs", ctx, ids)}
+}
+
+func (_c *MockAuditRepository_ExistingEventIDs_Call) Run(run func(ctx context.Context, ids []string)) *MockAuditRepository_ExistingEventIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_ExistingEventIDs_Call) Return(_a0 []string, _a1 error) *MockAuditRepository_ExistingEventIDs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditRepository_ExistingEventIDs_Call) RunAndReturn(run func(context.Context, []string) ([]string, error)) *MockAuditRepository_ExistingEventIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindBySessionID provides a mock function with given fields: ctx, sessionID, page
+func (_m *MockAuditRepository) FindBySessionID(ctx context.Context, sessionID domain.SessionID, page domain.PaginationParams) ([]domain.AuditEntry, int, error) {
+	ret := _m.Called(ctx, sessionID, page)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindBySessionID")
@@ -35,25 +311,25 @@ func (_m *MockAuditRepository) FindBySessionID(ctx context.Context, sessionID st
 	var r0 []domain.AuditEntry
 	var r1 int
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) ([]domain.AuditEntry, int, error)); ok {
-		return rf(ctx, sessionID, limit, offset)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SessionID, domain.PaginationParams) ([]domain.AuditEntry, int, error)); ok {
+		return rf(ctx, sessionID, page)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []domain.AuditEntry); ok {
-		r0 = rf(ctx, sessionID, limit, offset)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SessionID, domain.PaginationParams) []domain.AuditEntry); ok {
+		r0 = rf(ctx, sessionID, page)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]domain.AuditEntry)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) int); ok {
-		r1 = rf(ctx, sessionID, limit, offset)
+	if rf, ok := ret.Get(1).(func(context.Context, domain.SessionID, domain.PaginationParams) int); ok {
+		r1 = rf(ctx, sessionID, page)
 	} else {
 		r1 = ret.Get(1).(int)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, string, int, int) error); ok {
-		r2 = rf(ctx, sessionID, limit, offset)
+	if rf, ok := ret.Get(2).(func(context.Context, domain.SessionID, domain.PaginationParams) error); ok {
+		r2 = rf(ctx, sessionID, page)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -68,16 +344,15 @@ type MockAuditRepository_FindBySessionID_Call struct {
 
 // FindBySessionID is a helper method to define mock.On call
 //   - ctx context.Context
-//   - sessionID string
-//   - limit int
-//   - offset int
-func (_e *MockAuditRepository_Expecter) FindBySessionID(ctx interface{}, sessionID interface{}, limit interface{}, offset interface{}) *MockAuditRepository_FindBySessionID_Call {
-	return &MockAuditRepository_FindBySessionID_Call{Call: _e.mock.On("FindBySessionID", ctx, sessionID, limit, offset)}
+//   - sessionID domain.SessionID
+//   - page domain.PaginationParams
+func (_e *MockAuditRepository_Expecter) FindBySessionID(ctx interface{}, sessionID interface{}, page interface{}) *MockAuditRepository_FindBySessionID_Call {
+	return &MockAuditRepository_FindBySessionID_Call{Call: _e.mock.On("FindBySessionID", ctx, sessionID, page)}
 }
 
-func (_c *MockAuditRepository_FindBySessionID_Call) Run(run func(ctx context.Context, sessionID string, limit int, offset int)) *MockAuditRepository_FindBySessionID_Call {
+func (_c *MockAuditRepository_FindBySessionID_Call) Run(run func(ctx context.Context, sessionID domain.SessionID, page domain.PaginationParams)) *MockAuditRepository_FindBySessionID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(int))
+		run(args[0].(context.Context), args[1].(domain.SessionID), args[2].(domain.PaginationParams))
 	})
 	return _c
 }
@@ -87,34 +362,34 @@ func (_c *MockAuditRepository_FindBySessionID_Call) Return(_a0 []domain.AuditEnt
 	return _c
 }
 
-func (_c *MockAuditRepository_FindBySessionID_Call) RunAndReturn(run func(context.Context, string, int, int) ([]domain.AuditEntry, int, error)) *MockAuditRepository_FindBySessionID_Call {
+func (_c *MockAuditRepository_FindBySessionID_Call) RunAndReturn(run func(context.Context, domain.SessionID, domain.PaginationParams) ([]domain.AuditEntry, int, error)) *MockAuditRepository_FindBySessionID_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetSession provides a mock function with given fields: ctx, sessionID
-func (_m *MockAuditRepository) GetSession(ctx context.Context, sessionID string) (*repository.Session, error) {
-	ret := _m.Called(ctx, sessionID)
+// FindChain provides a mock function with given fields: ctx, sessionID, afterSeq, limit
+func (_m *MockAuditRepository) FindChain(ctx context.Context, sessionID domain.SessionID, afterSeq int64, limit int) ([]domain.ChainedEntry, error) {
+	ret := _m.Called(ctx, sessionID, afterSeq, limit)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetSession")
+		panic("no return value specified for FindChain")
 	}
 
-	var r0 *repository.Session
+	var r0 []domain.ChainedEntry
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*repository.Session, error)); ok {
-		return rf(ctx, sessionID)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SessionID, int64, int) ([]domain.ChainedEntry, error)); ok {
+		return rf(ctx, sessionID, afterSeq, limit)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *repository.Session); ok {
-		r0 = rf(ctx, sessionID)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SessionID, int64, int) []domain.ChainedEntry); ok {
+		r0 = rf(ctx, sessionID, afterSeq, limit)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*repository.Session)
+			r0 = ret.Get(0).([]domain.ChainedEntry)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, sessionID)
+	if rf, ok := ret.Get(1).(func(context.Context, domain.SessionID, int64, int) error); ok {
+		r1 = rf(ctx, sessionID, afterSeq, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -122,56 +397,119 @@ func (_m *MockAuditRepository) GetSession(ctx context.Context, sessionID string)
 	return r0, r1
 }
 
-// MockAuditRepository_GetSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSession'
-type MockAuditRepository_GetSession_Call struct {
+// MockAuditRepository_FindChain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindChain'
+type MockAuditRepository_FindChain_Call struct {
 	*mock.Call
 }
 
-// GetSession is a helper method to define mock.On call
+// FindChain is a helper method to define mock.On call
 //   - ctx context.Context
-//   - sessionID string
-func (_e *MockAuditRepository_Expecter) GetSession(ctx interface{}, sessionID interface{}) *MockAuditRepository_GetSession_Call {
-	return &MockAuditRepository_GetSession_Call{Call: _e.mock.On("GetSession", ctx, sessionID)}
+//   - sessionID domain.SessionID
+//   - afterSeq int64
+//   - limit int
+func (_e *MockAuditRepository_Expecter) FindChain(ctx interface{}, sessionID interface{}, afterSeq interface{}, limit interface{}) *MockAuditRepository_FindChain_Call {
+	return &MockAuditRepository_FindChain_Call{Call: _e.mock.On("FindChain", ctx, sessionID, afterSeq, limit)}
 }
 
-func (_c *MockAuditRepository_GetSession_Call) Run(run func(ctx context.Context, sessionID string)) *MockAuditRepository_GetSession_Call {
+func (_c *MockAuditRepository_FindChain_Call) Run(run func(ctx context.Context, sessionID domain.SessionID, afterSeq int64, limit int)) *MockAuditRepository_FindChain_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(domain.SessionID), args[2].(int64), args[3].(int))
 	})
 	return _c
 }
 
-func (_c *MockAuditRepository_GetSession_Call) Return(_a0 *repository.Session, _a1 error) *MockAuditRepository_GetSession_Call {
+func (_c *MockAuditRepository_FindChain_Call) Return(_a0 []domain.ChainedEntry, _a1 error) *MockAuditRepository_FindChain_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditRepository_FindChain_Call) RunAndReturn(run func(context.Context, domain.SessionID, int64, int) ([]domain.ChainedEntry, error)) *MockAuditRepository_FindChain_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindEvent provides a mock function with given fields: ctx, eventID
+func (_m *MockAuditRepository) FindEvent(ctx context.Context, eventID domain.EventID) (*domain.AuditEntry, error) {
+	ret := _m.Called(ctx, eventID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindEvent")
+	}
+
+	var r0 *domain.AuditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.EventID) (*domain.AuditEntry, error)); ok {
+		return rf(ctx, eventID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.EventID) *domain.AuditEntry); ok {
+		r0 = rf(ctx, eventID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.EventID) error); ok {
+		r1 = rf(ctx, eventID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_FindEvent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindEvent'
+type MockAuditRepository_FindEvent_Call struct {
+	*mock.Call
+}
+
+// FindEvent is a helper method to define mock.On call
+//   - ctx context.Context
+//   - eventID domain.EventID
+func (_e *MockAuditRepository_Expecter) FindEvent(ctx interface{}, eventID interface{}) *MockAuditRepository_FindEvent_Call {
+	return &MockAuditRepository_FindEvent_Call{Call: _e.mock.On("FindEvent", ctx, eventID)}
+}
+
+func (_c *MockAuditRepository_FindEvent_Call) Run(run func(ctx context.Context, eventID domain.EventID)) *MockAuditRepository_FindEvent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.EventID))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_FindEvent_Call) Return(_a0 *domain.AuditEntry, _a1 error) *MockAuditRepository_FindEvent_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockAuditRepository_GetSession_Call) RunAndReturn(run func(context.Context, string) (*repository.Session, error)) *MockAuditRepository_GetSession_Call {
+func (_c *MockAuditRepository_FindEvent_Call) RunAndReturn(run func(context.Context, domain.EventID) (*domain.AuditEntry, error)) *MockAuditRepository_FindEvent_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ValidateShareToken provides a mock function with given fields: ctx, token, sessionID
-func (_m *MockAuditRepository) ValidateShareToken(ctx context.Context, token string, sessionID string) (bool, error) {
-	ret := _m.Called(ctx, token, sessionID)
+// FindExpiredEvents provides a mock function with given fields: ctx, eventType, before, limit
+func (_m *MockAuditRepository) FindExpiredEvents(ctx context.Context, eventType string, before time.Time, limit int) ([]domain.AuditEntry, error) {
+	ret := _m.Called(ctx, eventType, before, limit)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ValidateShareToken")
+		panic("no return value specified for FindExpiredEvents")
 	}
 
-	var r0 bool
+	var r0 []domain.AuditEntry
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, error)); ok {
-		return rf(ctx, token, sessionID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, int) ([]domain.AuditEntry, error)); ok {
+		return rf(ctx, eventType, before, limit)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
-		r0 = rf(ctx, token, sessionID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, int) []domain.AuditEntry); ok {
+		r0 = rf(ctx, eventType, before, limit)
 	} else {
-		r0 = ret.Get(0).(bool)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.AuditEntry)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, token, sessionID)
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, int) error); ok {
+		r1 = rf(ctx, eventType, before, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -179,32 +517,520 @@ func (_m *MockAuditRepository) ValidateShareToken(ctx context.Context, token str
 	return r0, r1
 }
 
-// MockAuditRepository_ValidateShareToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateShareToken'
-type MockAuditRepository_ValidateShareToken_Call struct {
+// MockAuditRepository_FindExpiredEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindExpiredEvents'
+type MockAuditRepository_FindExpiredEvents_Call struct {
 	*mock.Call
 }
 
-// ValidateShareToken is a helper method to define mock.On call
+// FindExpiredEvents is a helper method to define mock.On call
 //   - ctx context.Context
-//   - token string
-//   - sessionID string
-func (_e *MockAuditRepository_Expecter) ValidateShareToken(ctx interface{}, token interface{}, sessionID interface{}) *MockAuditRepository_ValidateShareToken_Call {
-	return &MockAuditRepository_ValidateShareToken_Call{Call: _e.mock.On("ValidateShareToken", ctx, token, sessionID)}
+//   - eventType string
+//   - before time.Time
+//   - limit int
+func (_e *MockAuditRepository_Expecter) FindExpiredEvents(ctx interface{}, eventType interface{}, before interface{}, limit interface{}) *MockAuditRepository_FindExpiredEvents_Call {
+	return &MockAuditRepository_FindExpiredEvents_Call{Call: _e.mock.On("FindExpiredEvents", ctx, eventType, before, limit)}
 }
 
-func (_c *MockAuditRepository_ValidateShareToken_Call) Run(run func(ctx context.Context, token string, sessionID string)) *MockAuditRepository_ValidateShareToken_Call {
+func (_c *MockAuditRepository_FindExpiredEvents_Call) Run(run func(ctx context.Context, eventType string, before time.Time, limit int)) *MockAuditRepository_FindExpiredEvents_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time), args[3].(int))
 	})
 	return _c
 }
 
-func (_c *MockAuditRepository_ValidateShareToken_Call) Return(_a0 bool, _a1 error) *MockAuditRepository_ValidateShareToken_Call {
+func (_c *MockAuditRepository_FindExpiredEvents_Call) Return(_a0 []domain.AuditEntry, _a1 error) *MockAuditRepository_FindExpiredEvents_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockAuditRepository_ValidateShareToken_Call) RunAndReturn(run func(context.Context, string, string) (bool, error)) *MockAuditRepository_ValidateShareToken_Call {
+func (_c *MockAuditRepository_FindExpiredEvents_Call) RunAndReturn(run func(context.Context, string, time.Time, int) ([]domain.AuditEntry, error)) *MockAuditRepository_FindExpiredEvents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetActiveShare provides a mock function with given fields: ctx, tokenJTI, sessionID
+func (_m *MockAuditRepository) GetActiveShare(ctx context.Context, tokenJTI string, sessionID domain.SessionID) (*domain.Share, error) {
+	ret := _m.Called(ctx, tokenJTI, sessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetActiveShare")
+	}
+
+	var r0 *domain.Share
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.SessionID) (*domain.Share, error)); ok {
+		return rf(ctx, tokenJTI, sessionID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.SessionID) *domain.Share); ok {
+		r0 = rf(ctx, tokenJTI, sessionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Share)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.SessionID) error); ok {
+		r1 = rf(ctx, tokenJTI, sessionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_GetActiveShare_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetActiveShare'
+type MockAuditRepository_GetActiveShare_Call struct {
+	*mock.Call
+}
+
+// GetActiveShare is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tokenJTI string
+//   - sessionID domain.SessionID
+func (_e *MockAuditRepository_Expecter) GetActiveShare(ctx interface{}, tokenJTI interface{}, sessionID interface{}) *MockAuditRepository_GetActiveShare_Call {
+	return &MockAuditRepository_GetActiveShare_Call{Call: _e.mock.On("GetActiveShare", ctx, tokenJTI, sessionID)}
+}
+
+func (_c *MockAuditRepository_GetActiveShare_Call) Run(run func(ctx context.Context, tokenJTI string, sessionID domain.SessionID)) *MockAuditRepository_GetActiveShare_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(domain.SessionID))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_GetActiveShare_Call) Return(_a0 *domain.Share, _a1 error) *MockAuditRepository_GetActiveShare_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditRepository_GetActiveShare_Call) RunAndReturn(run func(context.Context, string, domain.SessionID) (*domain.Share, error)) *MockAuditRepository_GetActiveShare_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSession provides a mock function with given fields: ctx, sessionID
+func (_m *MockAuditRepository) GetSession(ctx context.Context, sessionID domain.SessionID) (*repository.Session, error) {
+	ret := _m.Called(ctx, sessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSession")
+	}
+
+	var r0 *repository.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SessionID) (*repository.Session, error)); ok {
+		return rf(ctx, sessionID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SessionID) *repository.Session); ok {
+		r0 = rf(ctx, sessionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.SessionID) error); ok {
+		r1 = rf(ctx, sessionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_GetSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSession'
+type MockAuditRepository_GetSession_Call struct {
+	*mock.Call
+}
+
+// GetSession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID domain.SessionID
+func (_e *MockAuditRepository_Expecter) GetSession(ctx interface{}, sessionID interface{}) *MockAuditRepository_GetSession_Call {
+	return &MockAuditRepository_GetSession_Call{Call: _e.mock.On("GetSession", ctx, sessionID)}
+}
+
+func (_c *MockAuditRepository_GetSession_Call) Run(run func(ctx context.Context, sessionID domain.SessionID)) *MockAuditRepository_GetSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.SessionID))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_GetSession_Call) Return(_a0 *repository.Session, _a1 error) *MockAuditRepository_GetSession_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditRepository_GetSession_Call) RunAndReturn(run func(context.Context, domain.SessionID) (*repository.Session, error)) *MockAuditRepository_GetSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSessionActivity provides a mock function with given fields: ctx, sessionID, query
+func (_m *MockAuditRepository) GetSessionActivity(ctx context.Context, sessionID domain.SessionID, query domain.ActivityQuery) ([]domain.ActivityBucket, error) {
+	ret := _m.Called(ctx, sessionID, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSessionActivity")
+	}
+
+	var r0 []domain.ActivityBucket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SessionID, domain.ActivityQuery) ([]domain.ActivityBucket, error)); ok {
+		return rf(ctx, sessionID, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SessionID, domain.ActivityQuery) []domain.ActivityBucket); ok {
+		r0 = rf(ctx, sessionID, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ActivityBucket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.SessionID, domain.ActivityQuery) error); ok {
+		r1 = rf(ctx, sessionID, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_GetSessionActivity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSessionActivity'
+type MockAuditRepository_GetSessionActivity_Call struct {
+	*mock.Call
+}
+
+// GetSessionActivity is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID domain.SessionID
+//   - query domain.ActivityQuery
+func (_e *MockAuditRepository_Expecter) GetSessionActivity(ctx interface{}, sessionID interface{}, query interface{}) *MockAuditRepository_GetSessionActivity_Call {
+	return &MockAuditRepository_GetSessionActivity_Call{Call: _e.mock.On("GetSessionActivity", ctx, sessionID, query)}
+}
+
+func (_c *MockAuditRepository_GetSessionActivity_Call) Run(run func(ctx context.Context, sessionID domain.SessionID, query domain.ActivityQuery)) *MockAuditRepository_GetSessionActivity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.SessionID), args[2].(domain.ActivityQuery))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_GetSessionActivity_Call) Return(_a0 []domain.ActivityBucket, _a1 error) *MockAuditRepository_GetSessionActivity_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditRepository_GetSessionActivity_Call) RunAndReturn(run func(context.Context, domain.SessionID, domain.ActivityQuery) ([]domain.ActivityBucket, error)) *MockAuditRepository_GetSessionActivity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSessionContributors provides a mock function with given fields: ctx, sessionID, query
+func (_m *MockAuditRepository) GetSessionContributors(ctx context.Context, sessionID domain.SessionID, query domain.ContributorQuery) ([]domain.ContributorStats, error) {
+	ret := _m.Called(ctx, sessionID, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSessionContributors")
+	}
+
+	var r0 []domain.ContributorStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SessionID, domain.ContributorQuery) ([]domain.ContributorStats, error)); ok {
+		return rf(ctx, sessionID, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SessionID, domain.ContributorQuery) []domain.ContributorStats); ok {
+		r0 = rf(ctx, sessionID, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ContributorStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.SessionID, domain.ContributorQuery) error); ok {
+		r1 = rf(ctx, sessionID, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_GetSessionContributors_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSessionContributors'
+type MockAuditRepository_GetSessionContributors_Call struct {
+	*mock.Call
+}
+
+// GetSessionContributors is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID domain.SessionID
+//   - query domain.ContributorQuery
+func (_e *MockAuditRepository_Expecter) GetSessionContributors(ctx interface{}, sessionID interface{}, query interface{}) *MockAuditRepository_GetSessionContributors_Call {
+	return &MockAuditRepository_GetSessionContributors_Call{Call: _e.mock.On("GetSessionContributors", ctx, sessionID, query)}
+}
+
+func (_c *MockAuditRepository_GetSessionContributors_Call) Run(run func(ctx context.Context, sessionID domain.SessionID, query domain.ContributorQuery)) *MockAuditRepository_GetSessionContributors_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.SessionID), args[2].(domain.ContributorQuery))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_GetSessionContributors_Call) Return(_a0 []domain.ContributorStats, _a1 error) *MockAuditRepository_GetSessionContributors_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditRepository_GetSessionContributors_Call) RunAndReturn(run func(context.Context, domain.SessionID, domain.ContributorQuery) ([]domain.ContributorStats, error)) *MockAuditRepository_GetSessionContributors_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSessionStats provides a mock function with given fields: ctx, sessionID
+func (_m *MockAuditRepository) GetSessionStats(ctx context.Context, sessionID domain.SessionID) (*domain.SessionStats, error) {
+	ret := _m.Called(ctx, sessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSessionStats")
+	}
+
+	var r0 *domain.SessionStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SessionID) (*domain.SessionStats, error)); ok {
+		return rf(ctx, sessionID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SessionID) *domain.SessionStats); ok {
+		r0 = rf(ctx, sessionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.SessionStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.SessionID) error); ok {
+		r1 = rf(ctx, sessionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_GetSessionStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSessionStats'
+type MockAuditRepository_GetSessionStats_Call struct {
+	*mock.Call
+}
+
+// GetSessionStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID domain.SessionID
+func (_e *MockAuditRepository_Expecter) GetSessionStats(ctx interface{}, sessionID interface{}) *MockAuditRepository_GetSessionStats_Call {
+	return &MockAuditRepository_GetSessionStats_Call{Call: _e.mock.On("GetSessionStats", ctx, sessionID)}
+}
+
+func (_c *MockAuditRepository_GetSessionStats_Call) Run(run func(ctx context.Context, sessionID domain.SessionID)) *MockAuditRepository_GetSessionStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.SessionID))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_GetSessionStats_Call) Return(_a0 *domain.SessionStats, _a1 error) *MockAuditRepository_GetSessionStats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditRepository_GetSessionStats_Call) RunAndReturn(run func(context.Context, domain.SessionID) (*domain.SessionStats, error)) *MockAuditRepository_GetSessionStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSlideHeatmap provides a mock function with given fields: ctx, sessionID, query
+func (_m *MockAuditRepository) GetSlideHeatmap(ctx context.Context, sessionID domain.SessionID, query domain.HeatmapQuery) ([]domain.HeatmapCell, error) {
+	ret := _m.Called(ctx, sessionID, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSlideHeatmap")
+	}
+
+	var r0 []domain.HeatmapCell
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SessionID, domain.HeatmapQuery) ([]domain.HeatmapCell, error)); ok {
+		return rf(ctx, sessionID, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SessionID, domain.HeatmapQuery) []domain.HeatmapCell); ok {
+		r0 = rf(ctx, sessionID, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.HeatmapCell)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.SessionID, domain.HeatmapQuery) error); ok {
+		r1 = rf(ctx, sessionID, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_GetSlideHeatmap_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSlideHeatmap'
+type MockAuditRepository_GetSlideHeatmap_Call struct {
+	*mock.Call
+}
+
+// GetSlideHeatmap is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID domain.SessionID
+//   - query domain.HeatmapQuery
+func (_e *MockAuditRepository_Expecter) GetSlideHeatmap(ctx interface{}, sessionID interface{}, query interface{}) *MockAuditRepository_GetSlideHeatmap_Call {
+	return &MockAuditRepository_GetSlideHeatmap_Call{Call: _e.mock.On("GetSlideHeatmap", ctx, sessionID, query)}
+}
+
+func (_c *MockAuditRepository_GetSlideHeatmap_Call) Run(run func(ctx context.Context, sessionID domain.SessionID, query domain.HeatmapQuery)) *MockAuditRepository_GetSlideHeatmap_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.SessionID), args[2].(domain.HeatmapQuery))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_GetSlideHeatmap_Call) Return(_a0 []domain.HeatmapCell, _a1 error) *MockAuditRepository_GetSlideHeatmap_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditRepository_GetSlideHeatmap_Call) RunAndReturn(run func(context.Context, domain.SessionID, domain.HeatmapQuery) ([]domain.HeatmapCell, error)) *MockAuditRepository_GetSlideHeatmap_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PurgeEvents provides a mock function with given fields: ctx, eventType, before, limit
+func (_m *MockAuditRepository) PurgeEvents(ctx context.Context, eventType string, before time.Time, limit int) ([]domain.PurgedEvents, error) {
+	ret := _m.Called(ctx, eventType, before, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeEvents")
+	}
+
+	var r0 []domain.PurgedEvents
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, int) ([]domain.PurgedEvents, error)); ok {
+		return rf(ctx, eventType, before, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, int) []domain.PurgedEvents); ok {
+		r0 = rf(ctx, eventType, before, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.PurgedEvents)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, int) error); ok {
+		r1 = rf(ctx, eventType, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuditRepository_PurgeEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PurgeEvents'
+type MockAuditRepository_PurgeEvents_Call struct {
+	*mock.Call
+}
+
+// PurgeEvents is a helper method to define mock.On call
+//   - ctx context.Context
+//   - eventType string
+//   - before time.Time
+//   - limit int
+func (_e *MockAuditRepository_Expecter) PurgeEvents(ctx interface{}, eventType interface{}, before interface{}, limit interface{}) *MockAuditRepository_PurgeEvents_Call {
+	return &MockAuditRepository_PurgeEvents_Call{Call: _e.mock.On("PurgeEvents", ctx, eventType, before, limit)}
+}
+
+func (_c *MockAuditRepository_PurgeEvents_Call) Run(run func(ctx context.Context, eventType string, before time.Time, limit int)) *MockAuditRepository_PurgeEvents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_PurgeEvents_Call) Return(_a0 []domain.PurgedEvents, _a1 error) *MockAuditRepository_PurgeEvents_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuditRepository_PurgeEvents_Call) RunAndReturn(run func(context.Context, string, time.Time, int) ([]domain.PurgedEvents, error)) *MockAuditRepository_PurgeEvents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// QueryEvents provides a mock function with given fields: ctx, sessionID, filter, page
+func (_m *MockAuditRepository) QueryEvents(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, page domain.PaginationParams) ([]domain.AuditEntry, int, error) {
+	ret := _m.Called(ctx, sessionID, filter, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryEvents")
+	}
+
+	var r0 []domain.AuditEntry
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SessionID, domain.EventFilter, domain.PaginationParams) ([]domain.AuditEntry, int, error)); ok {
+		return rf(ctx, sessionID, filter, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.SessionID, domain.EventFilter, domain.PaginationParams) []domain.AuditEntry); ok {
+		r0 = rf(ctx, sessionID, filter, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.SessionID, domain.EventFilter, domain.PaginationParams) int); ok {
+		r1 = rf(ctx, sessionID, filter, page)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, domain.SessionID, domain.EventFilter, domain.PaginationParams) error); ok {
+		r2 = rf(ctx, sessionID, filter, page)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockAuditRepository_QueryEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'QueryEvents'
+type MockAuditRepository_QueryEvents_Call struct {
+	*mock.Call
+}
+
+// QueryEvents is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID domain.SessionID
+//   - filter domain.EventFilter
+//   - page domain.PaginationParams
+func (_e *MockAuditRepository_Expecter) QueryEvents(ctx interface{}, sessionID interface{}, filter interface{}, page interface{}) *MockAuditRepository_QueryEvents_Call {
+	return &MockAuditRepository_QueryEvents_Call{Call: _e.mock.On("QueryEvents", ctx, sessionID, filter, page)}
+}
+
+func (_c *MockAuditRepository_QueryEvents_Call) Run(run func(ctx context.Context, sessionID domain.SessionID, filter domain.EventFilter, page domain.PaginationParams)) *MockAuditRepository_QueryEvents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.SessionID), args[2].(domain.EventFilter), args[3].(domain.PaginationParams))
+	})
+	return _c
+}
+
+func (_c *MockAuditRepository_QueryEvents_Call) Return(_a0 []domain.AuditEntry, _a1 int, _a2 error) *MockAuditRepository_QueryEvents_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockAuditRepository_QueryEvents_Call) RunAndReturn(run func(context.Context, domain.SessionID, domain.EventFilter, domain.PaginationParams) ([]domain.AuditEntry, int, error)) *MockAuditRepository_QueryEvents_Call {
 	_c.Call.Return(run)
 	return _c
 }
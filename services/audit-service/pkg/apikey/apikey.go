@@ -0,0 +1,105 @@
+package apikey
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Scopes that can be granted to API keys
+const (
+	// ScopeEventsWrite allows recording audit events
+	ScopeEventsWrite = "events:write"
+)
+
+// knownScopes lists the scopes accepted in configuration
+var knownScopes = []string{ScopeEventsWrite}
+
+// Key is a configured service API key. Only the SHA-256 of the secret is kept.
+type Key struct {
+	Name   string
+	Hash   string
+	Scopes []string
+}
+
+// HasScope reports whether the key was granted the scope
+func (k Key) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// Hash returns the hex-encoded SHA-256 of a raw API key
+func Hash(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// Parse reads a comma-separated list of name:sha256:scope|scope entries,
+// e.g. "pptx-processor:5e88...:events:write"
+func Parse(value string) ([]Key, error) {
+	var keys []Key
+	names := map[string]bool{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.SplitN(item, ":", 3)
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid API key entry %q, expected name:sha256:scopes", item)
+		}
+		name, hash := parts[0], strings.ToLower(parts[1])
+
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("API key %q: hash must be a hex-encoded SHA-256", name)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("API key %q is configured twice", name)
+		}
+		names[name] = true
+
+		var scopes []string
+		for _, scope := range strings.Split(parts[2], "|") {
+			if !slices.Contains(knownScopes, scope) {
+				return nil, fmt.Errorf("API key %q: unknown scope %q", name, scope)
+			}
+			scopes = append(scopes, scope)
+		}
+
+		keys = append(keys, Key{Name: name, Hash: hash, Scopes: scopes})
+	}
+	return keys, nil
+}
+
+// Store authenticates raw API keys against the configured hashes
+type Store struct {
+	keys []Key
+}
+
+// NewStore creates a store for the configured keys
+func NewStore(keys []Key) *Store {
+	return &Store{keys: keys}
+}
+
+// Authenticate returns the key matching the raw secret
+func (s *Store) Authenticate(raw string) (Key, bool) {
+	if raw == "" {
+		return Key{}, false
+	}
+
+	hash := []byte(Hash(raw))
+	var (
+		match Key
+		found bool
+	)
+	// Compare against every key so the time taken does not reveal which one matched
+	for _, key := range s.keys {
+		if subtle.ConstantTimeCompare(hash, []byte(key.Hash)) == 1 {
+			match, found = key, true
+		}
+	}
+	return match, found
+}
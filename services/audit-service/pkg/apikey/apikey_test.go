@@ -0,0 +1,75 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash(t *testing.T) {
+	// echo -n test | sha256sum
+	assert.Equal(t, "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", Hash("test"))
+}
+
+func TestParse(t *testing.T) {
+	processorHash := Hash("processor-secret")
+	exportHash := Hash("export-secret")
+
+	t.Run("valid_entries", func(t *testing.T) {
+		keys, err := Parse("pptx-processor:" + processorHash + ":events:write, export-service:" + strings.ToUpper(exportHash) + ":events:write")
+
+		require.NoError(t, err)
+		assert.Equal(t, []Key{
+			{Name: "pptx-processor", Hash: processorHash, Scopes: []string{ScopeEventsWrite}},
+			{Name: "export-service", Hash: exportHash, Scopes: []string{ScopeEventsWrite}},
+		}, keys)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		keys, err := Parse("")
+
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "missing_scopes", value: "svc:" + processorHash},
+		{name: "missing_name", value: ":" + processorHash + ":events:write"},
+		{name: "plaintext_key", value: "svc:processor-secret:events:write"},
+		{name: "unknown_scope", value: "svc:" + processorHash + ":events:delete"},
+		{name: "duplicate_name", value: "svc:" + processorHash + ":events:write,svc:" + exportHash + ":events:write"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.value)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestStore_Authenticate(t *testing.T) {
+	store := NewStore([]Key{
+		{Name: "pptx-processor", Hash: Hash("processor-secret"), Scopes: []string{ScopeEventsWrite}},
+		{Name: "export-service", Hash: Hash("export-secret")},
+	})
+
+	key, ok := store.Authenticate("export-secret")
+	assert.True(t, ok)
+	assert.Equal(t, "export-service", key.Name)
+	assert.False(t, key.HasScope(ScopeEventsWrite))
+
+	key, ok = store.Authenticate("processor-secret")
+	assert.True(t, ok)
+	assert.True(t, key.HasScope(ScopeEventsWrite))
+
+	_, ok = store.Authenticate("wrong-secret")
+	assert.False(t, ok)
+
+	_, ok = store.Authenticate("")
+	assert.False(t, ok)
+}
@@ -0,0 +1,367 @@
+// Package auditclient records audit events from Go services. Events are queued and sent to the
+// audit service in batches from a background goroutine, with retries for transient failures.
+// Every event gets a client-generated ID and every batch an idempotency key that stays the same
+// across its retries, so a batch whose response was lost is not stored twice.
+package auditclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"audit-service/pkg/clock"
+	"audit-service/pkg/requestid"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// MaxBatchSize is the most JSON events the service accepts in one request
+const MaxBatchSize = 100
+
+// Defaults applied to zero Config fields
+const (
+	DefaultBatchSize     = MaxBatchSize
+	DefaultFlushInterval = time.Second
+	DefaultQueueSize     = 10000
+	DefaultMaxRetries    = 5
+	DefaultMinBackoff    = 200 * time.Millisecond
+	DefaultMaxBackoff    = 10 * time.Second
+	DefaultTimeout       = 10 * time.Second
+)
+
+// ErrClosed is returned for events logged after Close
+var ErrClosed = errors.New("audit client is closed")
+
+// ErrQueueFull is returned when events are logged faster than they can be sent
+var ErrQueueFull = errors.New("audit event queue is full")
+
+// APIError is a request the service rejected, decoded from its problem document
+type APIError struct {
+	StatusCode int
+	Code       string `json:"code"`
+	Message    string `json:"detail"`
+	RequestID  string `json:"requestId"`
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("audit service responded with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("audit service responded with status %d: %s (%s)", e.StatusCode, e.Message, e.Code)
+}
+
+// Retryable reports whether repeating the request may succeed
+func (e *APIError) Retryable() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+// Config configures a Client
+type Config struct {
+	// BaseURL is the audit service URL, such as http://audit-service:4006
+	BaseURL string
+	// APIKey is a service API key with the events:write scope
+	APIKey string
+	// HTTPClient sends the requests; defaults to a client with DefaultTimeout
+	HTTPClient *http.Client
+
+	// BatchSize is the most events sent per request, at most MaxBatchSize
+	BatchSize int
+	// FlushInterval is how long a partial batch waits for more events
+	FlushInterval time.Duration
+	// QueueSize bounds the events waiting to be sent; Log fails with ErrQueueFull beyond it
+	QueueSize int
+
+	// MaxRetries is how often a failed batch is resent before it is dropped
+	MaxRetries int
+	// MinBackoff and MaxBackoff bound the exponential delay between retries
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// OnError is called with the events of a batch that could not be sent; failures are logged
+	// when it is nil
+	OnError func(events []Event, err error)
+
+	Clock  clock.Clock
+	Logger *zap.Logger
+}
+
+// Client sends audit events to the audit service
+type Client struct {
+	cfg     Config
+	baseURL string
+
+	queue chan Event
+	flush chan chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+// New creates a client and starts sending queued events; call Close to send the rest
+func New(cfg Config) (*Client, error) {
+	if cfg.BaseURL == "" {
+		return nil, errors.New("auditclient: BaseURL is required")
+	}
+	if cfg.APIKey == "" {
+		return nil, errors.New("auditclient: APIKey is required")
+	}
+	if cfg.BatchSize <= 0 || cfg.BatchSize > MaxBatchSize {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DefaultFlushInterval
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultQueueSize
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = DefaultMinBackoff
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = max(DefaultMaxBackoff, cfg.MinBackoff)
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.New()
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = zap.NewNop()
+	}
+
+	c := &Client{
+		cfg:     cfg,
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
+		queue:   make(chan Event, cfg.QueueSize),
+		flush:   make(chan chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go c.run()
+	return c, nil
+}
+
+// Log queues an event to be sent with the next batch. It fills in the ID and timestamp when they
+// are empty and uses the request ID carried by ctx as the correlation ID when the event has none,
+// so the events of one request can be found together. Log does not wait for the event to be sent.
+func (c *Client) Log(ctx context.Context, event Event) error {
+	if err := event.prepare(ctx, c.cfg.Clock); err != nil {
+		return err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return ErrClosed
+	}
+
+	select {
+	case c.queue <- event:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Send records events right away, retrying transient failures, and returns once they are
+// stored. The request ID carried by ctx is forwarded to the service.
+func (c *Client) Send(ctx context.Context, events ...Event) error {
+	for i := range events {
+		if err := events[i].prepare(ctx, c.cfg.Clock); err != nil {
+			return err
+		}
+	}
+	for start := 0; start < len(events); start += c.cfg.BatchSize {
+		end := min(start+c.cfg.BatchSize, len(events))
+		if err := c.sendWithRetry(ctx, events[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush sends the queued events and waits until they were sent or given up
+func (c *Client) Flush(ctx context.Context) error {
+	flushed := make(chan struct{})
+	select {
+	case c.flush <- flushed:
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting events and waits for the queued ones to be sent
+func (c *Client) Close(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.queue)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("audit client did not send queued events: %w", ctx.Err())
+	}
+}
+
+// run batches queued events until the queue is closed
+func (c *Client) run() {
+	defer close(c.done)
+
+	ticker := time.NewTicker(c.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, c.cfg.BatchSize)
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := c.sendWithRetry(context.Background(), batch); err != nil {
+			c.fail(batch, err)
+		}
+		batch = make([]Event, 0, c.cfg.BatchSize)
+	}
+
+	for {
+		select {
+		case event, ok := <-c.queue:
+			if !ok {
+				send()
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= c.cfg.BatchSize {
+				send()
+			}
+		case flushed := <-c.flush:
+			// Take what is already queued along with the partial batch
+			for drained := false; !drained; {
+				select {
+				case event, ok := <-c.queue:
+					if !ok {
+						drained = true
+						break
+					}
+					batch = append(batch, event)
+					if len(batch) >= c.cfg.BatchSize {
+						send()
+					}
+				default:
+					drained = true
+				}
+			}
+			send()
+			close(flushed)
+		case <-ticker.C:
+			send()
+		}
+	}
+}
+
+func (c *Client) fail(events []Event, err error) {
+	if c.cfg.OnError != nil {
+		c.cfg.OnError(events, err)
+		return
+	}
+	c.cfg.Logger.Error("failed to send audit events",
+		zap.Int("events", len(events)),
+		zap.Error(err),
+	)
+}
+
+// sendWithRetry posts a batch, retrying transport errors, 429 and 5xx with exponential backoff
+func (c *Client) sendWithRetry(ctx context.Context, events []Event) error {
+	idempotencyKey := "auditclient:" + uuid.NewString()
+	backoff := c.cfg.MinBackoff
+	for attempt := 0; ; attempt++ {
+		err := c.post(ctx, events, idempotencyKey)
+		if err == nil || attempt >= c.cfg.MaxRetries || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		c.cfg.Logger.Warn("retrying audit events",
+			requestid.Field(ctx),
+			zap.Int("events", len(events)),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("retry_in", backoff),
+			zap.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, c.cfg.MaxBackoff)
+	}
+}
+
+// post sends events to the batch endpoint
+func (c *Client) post(ctx context.Context, events []Event, idempotencyKey string) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit events: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/events/batch", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", c.cfg.APIKey)
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
+
+	resp, err := c.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		apiErr := &APIError{}
+		_ = json.Unmarshal(data, apiErr)
+		apiErr.StatusCode = resp.StatusCode
+		return apiErr
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// retryable reports whether a failed request may succeed if repeated
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,202 @@
+package auditclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"audit-service/pkg/clock"
+	"audit-service/pkg/requestid"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSessionID = "550e8400-e29b-41d4-a716-446655440000"
+
+var testNow = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+// request is a batch received by the fake audit service
+type request struct {
+	header http.Header
+	events []map[string]interface{}
+}
+
+// fakeService records batches and answers with the queued statuses, then 201
+type fakeService struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []request
+}
+
+func (f *fakeService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var events []map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&events)
+
+	f.mu.Lock()
+	f.requests = append(f.requests, request{header: r.Header.Clone(), events: events})
+	status := http.StatusCreated
+	if len(f.statuses) > 0 {
+		status, f.statuses = f.statuses[0], f.statuses[1:]
+	}
+	f.mu.Unlock()
+
+	w.WriteHeader(status)
+	if status >= 300 {
+		_, _ = w.Write([]byte(`{"type":"urn:audit-service:problem:validation_failed","code":"validation_failed",` +
+			`"detail":"Event 0: details do not match the schema"}`))
+	}
+}
+
+func (f *fakeService) received() []request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]request(nil), f.requests...)
+}
+
+func newTestClient(t *testing.T, service *fakeService, cfg Config) *Client {
+	t.Helper()
+	server := httptest.NewServer(service)
+	t.Cleanup(server.Close)
+
+	cfg.BaseURL = server.URL
+	cfg.APIKey = "ak_test"
+	cfg.MinBackoff = time.Millisecond
+	cfg.Clock = clock.NewFakeClock(testNow)
+	client, err := New(cfg)
+	require.NoError(t, err)
+	return client
+}
+
+func TestClient_BatchesQueuedEvents(t *testing.T) {
+	service := &fakeService{}
+	client := newTestClient(t, service, Config{BatchSize: 2, FlushInterval: time.Hour})
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	require.NoError(t, client.LogEdit(ctx, testSessionID, "user-1", EditDetails{SlideID: "slide-1", Before: "Hallo", After: "Hello"}))
+	require.NoError(t, client.LogExport(ctx, testSessionID, "user-1", ExportDetails{Format: "pptx", SlideCount: 12}))
+	require.NoError(t, client.LogView(context.Background(), testSessionID, "user-2"))
+
+	// The full batch is sent right away, the rest on close
+	require.Eventually(t, func() bool { return len(service.received()) == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, client.Close(context.Background()))
+
+	requests := service.received()
+	require.Len(t, requests, 2)
+	assert.Equal(t, "ak_test", requests[0].header.Get("X-API-Key"))
+	assert.NotEmpty(t, requests[0].header.Get("Idempotency-Key"))
+
+	first := requests[0].events
+	require.Len(t, first, 2)
+	assert.Equal(t, "edit", first[0]["type"])
+	assert.Equal(t, "user-1", first[0]["userId"])
+	assert.Equal(t, map[string]interface{}{"slideId": "slide-1", "before": "Hallo", "after": "Hello"}, first[0]["details"])
+	assert.Equal(t, "2024-03-01T10:00:00Z", first[0]["timestamp"])
+	assert.NotEmpty(t, first[0]["id"])
+	// Events of one request share its ID as correlation ID
+	assert.Equal(t, "req-1", first[0]["correlationId"])
+	assert.Equal(t, "req-1", first[1]["correlationId"])
+
+	require.Len(t, requests[1].events, 1)
+	assert.Equal(t, "view", requests[1].events[0]["type"])
+	assert.NotContains(t, requests[1].events[0], "correlationId")
+
+	assert.ErrorIs(t, client.LogView(ctx, testSessionID, "user-1"), ErrClosed)
+}
+
+func TestClient_Flush(t *testing.T) {
+	service := &fakeService{}
+	client := newTestClient(t, service, Config{FlushInterval: time.Hour})
+	defer client.Close(context.Background())
+
+	require.NoError(t, client.LogComment(context.Background(), testSessionID, "user-1", CommentDetails{Text: "Typo on slide 3"}))
+	require.NoError(t, client.Flush(context.Background()))
+
+	requests := service.received()
+	require.Len(t, requests, 1)
+	assert.Equal(t, map[string]interface{}{"text": "Typo on slide 3"}, requests[0].events[0]["details"])
+}
+
+func TestClient_ShareLinksAreCorrelatedByTokenID(t *testing.T) {
+	service := &fakeService{}
+	client := newTestClient(t, service, Config{FlushInterval: time.Hour})
+	defer client.Close(context.Background())
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	require.NoError(t, client.LogShare(ctx, testSessionID, "user-1", ShareDetails{TokenID: "share-91c2", Permissions: []string{"VIEW"}}))
+	require.NoError(t, client.LogShareRevoked(ctx, testSessionID, "user-1", "share-91c2"))
+	require.NoError(t, client.Flush(context.Background()))
+
+	requests := service.received()
+	require.Len(t, requests, 1)
+	require.Len(t, requests[0].events, 2)
+	for _, event := range requests[0].events {
+		assert.Equal(t, "share-91c2", event["correlationId"])
+		assert.Equal(t, "share-91c2", event["details"].(map[string]interface{})["tokenId"])
+	}
+}
+
+func TestClient_RetriesTransientFailures(t *testing.T) {
+	service := &fakeService{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	client := newTestClient(t, service, Config{})
+	defer client.Close(context.Background())
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	require.NoError(t, client.Send(ctx, Event{SessionID: testSessionID, Type: TypeShare, Details: ShareDetails{SessionName: "Deck"}}))
+
+	requests := service.received()
+	require.Len(t, requests, 3)
+	// Retries resend the same events under the same idempotency key
+	assert.Equal(t, requests[0].events, requests[2].events)
+	assert.Equal(t, requests[0].header.Get("Idempotency-Key"), requests[2].header.Get("Idempotency-Key"))
+	assert.Equal(t, "req-1", requests[2].header.Get(requestid.Header))
+}
+
+func TestClient_RejectedBatch(t *testing.T) {
+	service := &fakeService{statuses: []int{http.StatusUnprocessableEntity}}
+	failed := make(chan []Event, 1)
+	client := newTestClient(t, service, Config{
+		FlushInterval: time.Hour,
+		OnError:       func(events []Event, err error) { failed <- events },
+	})
+	defer client.Close(context.Background())
+
+	// Rejected events are not retried
+	err := client.Send(context.Background(), Event{SessionID: testSessionID, Type: TypeMerge, Details: MergeDetails{ShapeIDs: []string{"a"}}})
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+	assert.Equal(t, "validation_failed", apiErr.Code)
+	assert.Len(t, service.received(), 1)
+
+	// Queued events that cannot be sent are handed to OnError
+	service.mu.Lock()
+	service.statuses = []int{http.StatusBadRequest}
+	service.mu.Unlock()
+	require.NoError(t, client.LogReorder(context.Background(), testSessionID, "user-1", ReorderDetails{FromIndex: 0, ToIndex: 2}))
+	require.NoError(t, client.Flush(context.Background()))
+
+	events := <-failed
+	require.Len(t, events, 1)
+	assert.Equal(t, TypeReorder, events[0].Type)
+}
+
+func TestClient_InvalidEvent(t *testing.T) {
+	client := newTestClient(t, &fakeService{}, Config{})
+	defer client.Close(context.Background())
+
+	assert.Error(t, client.Log(context.Background(), Event{Type: TypeView}))
+	assert.Error(t, client.Send(context.Background(), Event{SessionID: testSessionID}))
+}
+
+func TestNew_RequiresConfig(t *testing.T) {
+	_, err := New(Config{APIKey: "ak_test"})
+	assert.Error(t, err)
+
+	_, err = New(Config{BaseURL: "http://audit-service:4006"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,339 @@
+package auditclient
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"audit-service/pkg/clock"
+	"audit-service/pkg/requestid"
+
+	"github.com/google/uuid"
+)
+
+// Event types with a details schema, as registered by the audit service
+const (
+	TypeCreate  = "create"
+	TypeEdit    = "edit"
+	TypeMerge   = "merge"
+	TypeReorder = "reorder"
+	TypeComment = "comment"
+	TypeExport  = "export"
+	TypeShare   = "share"
+	TypeUnshare = "unshare"
+	TypeView    = "view"
+
+	TypeCommentCreated  = "comment_created"
+	TypeCommentEdited   = "comment_edited"
+	TypeCommentResolved = "comment_resolved"
+	TypeCommentDeleted  = "comment_deleted"
+
+	TypeSubmittedForReview = "submitted_for_review"
+	TypeApproved           = "approved"
+	TypeRejected           = "rejected"
+	TypeReopened           = "reopened"
+
+	TypeTMHit      = "tm_hit"
+	TypeTMOverride = "tm_override"
+
+	TypeMTRequest = "mt_request"
+)
+
+// Event is an audit event as accepted by POST /api/v1/events
+type Event struct {
+	// ID is generated when empty and identifies the event across retries
+	ID        string `json:"id"`
+	SessionID string `json:"sessionId"`
+	Type      string `json:"type"`
+	// Details must match the schema of the type, if it has one
+	Details interface{} `json:"details,omitempty"`
+	// Timestamp defaults to the time the event is logged
+	Timestamp time.Time `json:"timestamp"`
+	// UserID is the user the service acted for
+	UserID string `json:"userId,omitempty"`
+	// OrganizationID is the organization the service acted for; empty for the default organization
+	OrganizationID string `json:"organizationId,omitempty"`
+	// CorrelationID groups the events of one operation; defaults to the request ID of the context
+	CorrelationID string `json:"correlationId,omitempty"`
+	// ParentEventID is the ID of the event that caused this one
+	ParentEventID string `json:"parentEventId,omitempty"`
+	// SchemaVersion selects the version of the details schema; zero uses version 1
+	SchemaVersion int `json:"schemaVersion,omitempty"`
+}
+
+// prepare checks the required fields and fills in the defaults
+func (e *Event) prepare(ctx context.Context, clk clock.Clock) error {
+	if e.SessionID == "" || e.Type == "" {
+		return errors.New("auditclient: event requires a session ID and a type")
+	}
+	if e.ID == "" {
+		e.ID = uuid.NewString()
+	}
+	if e.Timestamp.IsZero() {
+		e.Timestamp = clk.Now()
+	}
+	if e.CorrelationID == "" {
+		e.CorrelationID = requestid.FromContext(ctx)
+	}
+	return nil
+}
+
+// EditDetails describes a change to slide content
+type EditDetails struct {
+	Action    string `json:"action,omitempty"`
+	SlideID   string `json:"slideId,omitempty"`
+	ShapeID   string `json:"shapeId,omitempty"`
+	TextID    string `json:"textId,omitempty"`
+	Before    string `json:"before,omitempty"`
+	After     string `json:"after,omitempty"`
+	NewStatus string `json:"newStatus,omitempty"`
+	// State snapshots the edited object, so the edit can be reverted
+	State *EditState `json:"state,omitempty"`
+}
+
+// EditState is the state of an edited object before and after an edit; After is required
+type EditState struct {
+	Before map[string]interface{} `json:"before,omitempty"`
+	After  map[string]interface{} `json:"after"`
+}
+
+// MergeDetails describes shapes merged into one; at least two shapes are required
+type MergeDetails struct {
+	SlideID       string   `json:"slideId,omitempty"`
+	ShapeIDs      []string `json:"shapeIds"`
+	TargetShapeID string   `json:"targetShapeId,omitempty"`
+}
+
+// ReorderDetails describes a slide or shape moved to another position
+type ReorderDetails struct {
+	SlideID   string `json:"slideId,omitempty"`
+	FromIndex int    `json:"fromIndex"`
+	ToIndex   int    `json:"toIndex"`
+}
+
+// CommentDetails describes a comment; ParentID is set for replies
+type CommentDetails struct {
+	Text     string `json:"text"`
+	SlideID  string `json:"slideId,omitempty"`
+	ShapeID  string `json:"shapeId,omitempty"`
+	ParentID string `json:"parentId,omitempty"`
+}
+
+// CommentLifecycleDetails describes a change to a comment of a thread; CommentID and ThreadID
+// are required, Text is required when a comment is created or edited
+type CommentLifecycleDetails struct {
+	CommentID string `json:"commentId"`
+	ThreadID  string `json:"threadId"`
+	Text      string `json:"text,omitempty"`
+	// PreviousText is the text replaced by an edit
+	PreviousText string `json:"previousText,omitempty"`
+	// Resolved is false when a resolved thread is reopened
+	Resolved *bool  `json:"resolved,omitempty"`
+	SlideID  string `json:"slideId,omitempty"`
+	ShapeID  string `json:"shapeId,omitempty"`
+	ParentID string `json:"parentId,omitempty"`
+}
+
+// ReviewDetails describes a review workflow step of a slide, or of one of its shapes when ShapeID
+// is set
+type ReviewDetails struct {
+	SlideID string `json:"slideId"`
+	ShapeID string `json:"shapeId,omitempty"`
+	Note    string `json:"note,omitempty"`
+}
+
+// TMHitDetails describes a translation memory match offered for a segment and whether the
+// translator took it
+type TMHitDetails struct {
+	SegmentSource string `json:"segmentSource"`
+	// MatchScore runs from 0 to 100, 101 for context matches
+	MatchScore float64 `json:"matchScore"`
+	Accepted   bool    `json:"accepted"`
+	TargetText string  `json:"targetText,omitempty"`
+	TMID       string  `json:"tmId,omitempty"`
+	WordCount  int     `json:"wordCount,omitempty"`
+	SlideID    string  `json:"slideId,omitempty"`
+	ShapeID    string  `json:"shapeId,omitempty"`
+}
+
+// TMOverrideDetails describes an accepted translation memory match the translator changed
+type TMOverrideDetails struct {
+	SegmentSource  string  `json:"segmentSource"`
+	MatchScore     float64 `json:"matchScore"`
+	TargetText     string  `json:"targetText,omitempty"`
+	TranslatedText string  `json:"translatedText,omitempty"`
+	TMID           string  `json:"tmId,omitempty"`
+	WordCount      int     `json:"wordCount,omitempty"`
+	SlideID        string  `json:"slideId,omitempty"`
+	ShapeID        string  `json:"shapeId,omitempty"`
+}
+
+// Access methods of ViewDetails
+const (
+	AccessJWT        = "jwt"
+	AccessShareToken = "share_token"
+)
+
+// ViewDetails records how a session was opened, for access reviews
+type ViewDetails struct {
+	// AccessMethod is AccessJWT or AccessShareToken
+	AccessMethod string `json:"accessMethod"`
+	// ShareTokenID identifies the share link the session was opened through
+	ShareTokenID string `json:"shareTokenId,omitempty"`
+}
+
+// MTRequestDetails describes a call to a machine translation provider
+type MTRequestDetails struct {
+	Provider         string `json:"provider"`
+	Model            string `json:"model,omitempty"`
+	SourceLanguage   string `json:"sourceLanguage,omitempty"`
+	TargetLanguage   string `json:"targetLanguage,omitempty"`
+	SourceCharacters int    `json:"sourceCharacters"`
+	TargetCharacters int    `json:"targetCharacters,omitempty"`
+	SegmentCount     int    `json:"segmentCount,omitempty"`
+	LatencyMs        int64  `json:"latencyMs,omitempty"`
+	// CostEstimate is in Currency, an ISO 4217 code that defaults to USD
+	CostEstimate float64 `json:"costEstimate,omitempty"`
+	Currency     string  `json:"currency,omitempty"`
+	// Error is set when the provider call failed
+	Error   string `json:"error,omitempty"`
+	SlideID string `json:"slideId,omitempty"`
+	ShapeID string `json:"shapeId,omitempty"`
+}
+
+// ExportDetails describes an export of the translated presentation
+type ExportDetails struct {
+	Action      string `json:"action,omitempty"`
+	Format      string `json:"format,omitempty"`
+	SlideCount  int    `json:"slideCount,omitempty"`
+	SessionName string `json:"sessionName,omitempty"`
+	// Error is set when the export failed
+	Error string `json:"error,omitempty"`
+}
+
+// ShareDetails describes a share link handed out for a session
+type ShareDetails struct {
+	Action      string     `json:"action,omitempty"`
+	SessionName string     `json:"sessionName,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	// TokenID is the jti of the share token; the uses of the link are traced by it
+	TokenID string `json:"tokenId,omitempty"`
+	// Permissions are the roles granted by the link, VIEW or COMMENT
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// UnshareDetails identifies the share link revoked
+type UnshareDetails struct {
+	TokenID string `json:"tokenId"`
+}
+
+// LogCreate queues the creation of a session
+func (c *Client) LogCreate(ctx context.Context, sessionID, userID string, details map[string]interface{}) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeCreate, Details: details})
+}
+
+// LogEdit queues a change to slide content
+func (c *Client) LogEdit(ctx context.Context, sessionID, userID string, details EditDetails) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeEdit, Details: details})
+}
+
+// LogMerge queues shapes merged into one
+func (c *Client) LogMerge(ctx context.Context, sessionID, userID string, details MergeDetails) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeMerge, Details: details})
+}
+
+// LogReorder queues a slide or shape moved to another position
+func (c *Client) LogReorder(ctx context.Context, sessionID, userID string, details ReorderDetails) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeReorder, Details: details})
+}
+
+// LogComment queues a comment
+func (c *Client) LogComment(ctx context.Context, sessionID, userID string, details CommentDetails) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeComment, Details: details})
+}
+
+// LogCommentCreated queues a comment added to a thread
+func (c *Client) LogCommentCreated(ctx context.Context, sessionID, userID string, details CommentLifecycleDetails) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeCommentCreated, Details: details})
+}
+
+// LogCommentEdited queues a change to the text of a comment
+func (c *Client) LogCommentEdited(ctx context.Context, sessionID, userID string, details CommentLifecycleDetails) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeCommentEdited, Details: details})
+}
+
+// LogCommentResolved queues a comment resolved, or reopened when details.Resolved is false
+func (c *Client) LogCommentResolved(ctx context.Context, sessionID, userID string, details CommentLifecycleDetails) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeCommentResolved, Details: details})
+}
+
+// LogCommentDeleted queues a comment removed from a thread
+func (c *Client) LogCommentDeleted(ctx context.Context, sessionID, userID string, details CommentLifecycleDetails) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeCommentDeleted, Details: details})
+}
+
+// LogSubmittedForReview queues a translation submitted for review
+func (c *Client) LogSubmittedForReview(ctx context.Context, sessionID, userID string, details ReviewDetails) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeSubmittedForReview, Details: details})
+}
+
+// LogApproved queues a translation approved by a reviewer
+func (c *Client) LogApproved(ctx context.Context, sessionID, userID string, details ReviewDetails) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeApproved, Details: details})
+}
+
+// LogRejected queues a translation rejected by a reviewer
+func (c *Client) LogRejected(ctx context.Context, sessionID, userID string, details ReviewDetails) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeRejected, Details: details})
+}
+
+// LogReopened queues an approved translation reopened for changes
+func (c *Client) LogReopened(ctx context.Context, sessionID, userID string, details ReviewDetails) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeReopened, Details: details})
+}
+
+// LogTMHit queues a translation memory match accepted or rejected for a segment
+func (c *Client) LogTMHit(ctx context.Context, sessionID, userID string, details TMHitDetails) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeTMHit, Details: details})
+}
+
+// LogTMOverride queues an accepted translation memory match changed by the translator
+func (c *Client) LogTMOverride(ctx context.Context, sessionID, userID string, details TMOverrideDetails) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeTMOverride, Details: details})
+}
+
+// LogMTRequest queues a call of the translation pipeline to a machine translation provider
+func (c *Client) LogMTRequest(ctx context.Context, sessionID, userID string, details MTRequestDetails) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeMTRequest, Details: details})
+}
+
+// LogExport queues an export
+func (c *Client) LogExport(ctx context.Context, sessionID, userID string, details ExportDetails) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeExport, Details: details})
+}
+
+// LogShare queues a share link handed out, correlated by details.TokenID when set
+func (c *Client) LogShare(ctx context.Context, sessionID, userID string, details ShareDetails) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeShare, Details: details, CorrelationID: details.TokenID})
+}
+
+// LogUnshare queues a share link revoked
+func (c *Client) LogUnshare(ctx context.Context, sessionID, userID string) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeUnshare})
+}
+
+// LogShareRevoked queues the share link issued with the token ID revoked, correlated with its
+// share event and uses
+func (c *Client) LogShareRevoked(ctx context.Context, sessionID, userID, tokenID string) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeUnshare, Details: UnshareDetails{TokenID: tokenID}, CorrelationID: tokenID})
+}
+
+// LogView queues a session opened for reading
+func (c *Client) LogView(ctx context.Context, sessionID, userID string) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeView})
+}
+
+// LogViewVia queues a session opened for reading, with the access method listed in access reviews
+func (c *Client) LogViewVia(ctx context.Context, sessionID, userID string, details ViewDetails) error {
+	return c.Log(ctx, Event{SessionID: sessionID, UserID: userID, Type: TypeView, Details: details})
+}
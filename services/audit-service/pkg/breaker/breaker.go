@@ -0,0 +1,169 @@
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"audit-service/pkg/clock"
+)
+
+// ErrOpen is returned while the breaker rejects calls
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the position of the breaker
+type State int
+
+// Breaker states; the values are exposed as a metric
+const (
+	// StateClosed lets every call through
+	StateClosed State = iota
+	// StateHalfOpen lets a limited number of probe calls through after the open timeout
+	StateHalfOpen
+	// StateOpen rejects every call until the open timeout has passed
+	StateOpen
+)
+
+// String returns the lower-case state name
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half_open"
+	case StateOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// Config controls when the breaker opens and how it recovers
+type Config struct {
+	// FailureThreshold is the number of consecutive failures that opens the breaker
+	FailureThreshold int
+	// OpenTimeout is how long the breaker rejects calls before probing again
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of probe calls allowed at once, and the number of
+	// successful probes needed to close the breaker
+	HalfOpenRequests int
+}
+
+// Breaker stops calls to a failing dependency so callers fail fast instead of queueing on timeouts
+type Breaker struct {
+	cfg      Config
+	clock    clock.Clock
+	onChange func(from, to State)
+
+	mu    sync.Mutex
+	state State
+	// generation changes on every transition so outcomes of calls started in an earlier state are ignored
+	generation int
+	failures   int
+	openedAt   time.Time
+	probes     int
+	successes  int
+}
+
+// New creates a closed breaker. onChange, when set, is called on every state transition
+// while the breaker lock is held, so it must not call back into the breaker.
+func New(cfg Config, clk clock.Clock, onChange func(from, to State)) *Breaker {
+	return &Breaker{
+		cfg:      cfg,
+		clock:    clk,
+		onChange: onChange,
+	}
+}
+
+// State returns the current state, moving from open to half-open once the open timeout has passed
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh()
+	return b.state
+}
+
+// Allow reserves a call. It returns ErrOpen when the call must not be made; otherwise the
+// caller reports the outcome through done, passing true when the call failed.
+func (b *Breaker) Allow() (done func(failed bool), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh()
+	switch b.state {
+	case StateOpen:
+		return nil, ErrOpen
+	case StateHalfOpen:
+		if b.probes >= b.cfg.HalfOpenRequests {
+			return nil, ErrOpen
+		}
+		b.probes++
+	}
+
+	generation := b.generation
+	var once sync.Once
+	return func(failed bool) {
+		once.Do(func() { b.record(generation, failed) })
+	}, nil
+}
+
+// record updates the breaker with the outcome of a call
+func (b *Breaker) record(generation int, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+
+	switch b.state {
+	case StateClosed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold {
+			b.open()
+		}
+
+	case StateHalfOpen:
+		b.probes--
+		if failed {
+			b.open()
+			return
+		}
+		b.successes++
+		if b.successes >= b.cfg.HalfOpenRequests {
+			b.transition(StateClosed)
+		}
+	}
+}
+
+// refresh moves an open breaker to half-open once the open timeout has passed
+func (b *Breaker) refresh() {
+	if b.state == StateOpen && b.clock.Now().Sub(b.openedAt) >= b.cfg.OpenTimeout {
+		b.transition(StateHalfOpen)
+	}
+}
+
+// open starts rejecting calls
+func (b *Breaker) open() {
+	b.openedAt = b.clock.Now()
+	b.transition(StateOpen)
+}
+
+// transition switches to the given state and resets the counters of the previous one
+func (b *Breaker) transition(to State) {
+	from := b.state
+	b.state = to
+	b.generation++
+	b.failures = 0
+	b.probes = 0
+	b.successes = 0
+
+	if b.onChange != nil && from != to {
+		b.onChange(from, to)
+	}
+}
@@ -0,0 +1,112 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// transition records a state change reported by the breaker
+type transition struct {
+	from, to State
+}
+
+func newTestBreaker(clk clock.Clock, changes *[]transition) *Breaker {
+	return New(Config{FailureThreshold: 3, OpenTimeout: 30 * time.Second, HalfOpenRequests: 1}, clk,
+		func(from, to State) { *changes = append(*changes, transition{from, to}) })
+}
+
+// call runs one call through the breaker and reports whether it was allowed
+func call(b *Breaker, failed bool) bool {
+	done, err := b.Allow()
+	if err != nil {
+		return false
+	}
+	done(failed)
+	return true
+}
+
+func TestBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	var changes []transition
+	b := newTestBreaker(clock.NewFakeClock(time.Now()), &changes)
+
+	assert.True(t, call(b, true))
+	assert.True(t, call(b, true))
+	// A success resets the count
+	assert.True(t, call(b, false))
+	assert.True(t, call(b, true))
+	assert.True(t, call(b, true))
+	assert.Equal(t, StateClosed, b.State())
+
+	assert.True(t, call(b, true))
+	assert.Equal(t, StateOpen, b.State())
+	assert.Equal(t, []transition{{StateClosed, StateOpen}}, changes)
+
+	_, err := b.Allow()
+	assert.ErrorIs(t, err, ErrOpen)
+}
+
+func TestBreaker_HalfOpenProbe(t *testing.T) {
+	var changes []transition
+	clk := clock.NewFakeClock(time.Now())
+	b := newTestBreaker(clk, &changes)
+
+	for i := 0; i < 3; i++ {
+		call(b, true)
+	}
+	require.Equal(t, StateOpen, b.State())
+
+	clk.Advance(30 * time.Second)
+	assert.Equal(t, StateHalfOpen, b.State())
+
+	// Only one probe at a time
+	done, err := b.Allow()
+	require.NoError(t, err)
+	_, err = b.Allow()
+	assert.ErrorIs(t, err, ErrOpen)
+
+	// A failed probe opens the breaker again for a full timeout
+	done(true)
+	assert.Equal(t, StateOpen, b.State())
+	clk.Advance(29 * time.Second)
+	assert.Equal(t, StateOpen, b.State())
+	clk.Advance(time.Second)
+
+	// A successful probe closes it
+	assert.True(t, call(b, false))
+	assert.Equal(t, StateClosed, b.State())
+
+	assert.Equal(t, []transition{
+		{StateClosed, StateOpen},
+		{StateOpen, StateHalfOpen},
+		{StateHalfOpen, StateOpen},
+		{StateOpen, StateHalfOpen},
+		{StateHalfOpen, StateClosed},
+	}, changes)
+}
+
+func TestBreaker_IgnoresOutcomesFromEarlierStates(t *testing.T) {
+	var changes []transition
+	b := newTestBreaker(clock.NewFakeClock(time.Now()), &changes)
+
+	// A slow call started while closed finishes after the breaker opened and closed again
+	slow, err := b.Allow()
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		call(b, true)
+	}
+	require.Equal(t, StateOpen, b.State())
+
+	slow(false)
+	assert.Equal(t, StateOpen, b.State())
+}
+
+func TestState_String(t *testing.T) {
+	assert.Equal(t, "closed", StateClosed.String())
+	assert.Equal(t, "half_open", StateHalfOpen.String())
+	assert.Equal(t, "open", StateOpen.String())
+}
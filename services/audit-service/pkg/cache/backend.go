@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// Cache stores validated token information under hashed keys
+type Cache interface {
+	// Get returns the entry stored under key
+	Get(key string) (*CachedTokenInfo, bool)
+	// Set stores an entry that is dropped after ttl
+	Set(key string, info *CachedTokenInfo, ttl time.Duration)
+	// Delete removes the entry stored under key
+	Delete(key string)
+	// ItemCount returns the number of stored entries
+	ItemCount() int
+	// Flush removes all entries
+	Flush()
+}
+
+// MemoryCache keeps entries in process memory
+type MemoryCache struct {
+	cache *cache.Cache
+}
+
+// NewMemoryCache creates an in-process cache that drops expired entries every cleanupInterval
+func NewMemoryCache(cleanupInterval time.Duration) *MemoryCache {
+	return &MemoryCache{
+		cache: cache.New(cache.NoExpiration, cleanupInterval),
+	}
+}
+
+// Get returns the entry stored under key
+func (m *MemoryCache) Get(key string) (*CachedTokenInfo, bool) {
+	val, found := m.cache.Get(key)
+	if !found {
+		return nil, false
+	}
+	info, ok := val.(*CachedTokenInfo)
+	return info, ok
+}
+
+// Set stores an entry that is dropped after ttl
+func (m *MemoryCache) Set(key string, info *CachedTokenInfo, ttl time.Duration) {
+	m.cache.Set(key, info, ttl)
+}
+
+// Delete removes the entry stored under key
+func (m *MemoryCache) Delete(key string) {
+	m.cache.Delete(key)
+}
+
+// ItemCount returns the number of entries, including expired ones not yet cleaned up
+func (m *MemoryCache) ItemCount() int {
+	return m.cache.ItemCount()
+}
+
+// Flush removes all entries
+func (m *MemoryCache) Flush() {
+	m.cache.Flush()
+}
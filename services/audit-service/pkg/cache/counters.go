@@ -0,0 +1,178 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"audit-service/pkg/clock"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Counters keep counts that expire, such as the failed authentications of a client
+type Counters interface {
+	// Incr adds one to the count under key and returns it; a new count expires after ttl
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	// Set stores n under key, expiring after ttl
+	Set(ctx context.Context, key string, n int64, ttl time.Duration) error
+	// Get returns the count under key and the time until it expires; missing counts are zero
+	Get(ctx context.Context, key string) (int64, time.Duration, error)
+	// Delete removes the count under key
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryCounters keep counts in process memory, so each replica counts on its own
+type MemoryCounters struct {
+	mu     sync.Mutex
+	counts map[string]*memoryCount
+	// lastPrune is when expired counts were last dropped
+	lastPrune time.Time
+	clock     clock.Clock
+}
+
+// memoryCount is one count and when it expires
+type memoryCount struct {
+	n         int64
+	expiresAt time.Time
+}
+
+// NewMemoryCounters creates empty in-process counters that expire by clk
+func NewMemoryCounters(clk clock.Clock) *MemoryCounters {
+	return &MemoryCounters{counts: map[string]*memoryCount{}, clock: clk}
+}
+
+// Incr adds one to the count under key and returns it; a new count expires after ttl
+func (m *MemoryCounters) Incr(_ context.Context, key string, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+	m.prune(now)
+	c, ok := m.counts[key]
+	if !ok || !now.Before(c.expiresAt) {
+		c = &memoryCount{expiresAt: now.Add(ttl)}
+		m.counts[key] = c
+	}
+	c.n++
+	return c.n, nil
+}
+
+// Set stores n under key, expiring after ttl
+func (m *MemoryCounters) Set(_ context.Context, key string, n int64, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+	m.prune(now)
+	m.counts[key] = &memoryCount{n: n, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// Get returns the count under key and the time until it expires
+func (m *MemoryCounters) Get(_ context.Context, key string) (int64, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+	c, ok := m.counts[key]
+	if !ok || !now.Before(c.expiresAt) {
+		return 0, 0, nil
+	}
+	return c.n, c.expiresAt.Sub(now), nil
+}
+
+// Delete removes the count under key
+func (m *MemoryCounters) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.counts, key)
+	return nil
+}
+
+// prune drops expired counts at most once a minute
+func (m *MemoryCounters) prune(now time.Time) {
+	if now.Sub(m.lastPrune) < time.Minute {
+		return
+	}
+	m.lastPrune = now
+	for key, c := range m.counts {
+		if !now.Before(c.expiresAt) {
+			delete(m.counts, key)
+		}
+	}
+}
+
+// RedisCounters share counts between service replicas through Redis
+type RedisCounters struct {
+	client  redis.UniversalClient
+	prefix  string
+	timeout time.Duration
+}
+
+// NewRedisCounters creates counters kept under prefix, giving each command timeout to complete
+func NewRedisCounters(client redis.UniversalClient, prefix string, timeout time.Duration) *RedisCounters {
+	return &RedisCounters{client: client, prefix: prefix, timeout: timeout}
+}
+
+// Incr adds one to the count under key and returns it; a new count expires after ttl
+func (r *RedisCounters) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	n, err := r.client.Incr(ctx, r.prefix+key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// The first increment creates the count
+	if n == 1 {
+		if err := r.client.PExpire(ctx, r.prefix+key, ttl).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// Set stores n under key, expiring after ttl
+func (r *RedisCounters) Set(ctx context.Context, key string, n int64, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return r.client.Set(ctx, r.prefix+key, n, ttl).Err()
+}
+
+// Get returns the count under key and the time until it expires
+func (r *RedisCounters) Get(ctx context.Context, key string) (int64, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var get *redis.StringCmd
+	var ttl *redis.DurationCmd
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, r.prefix+key)
+		ttl = pipe.PTTL(ctx, r.prefix+key)
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	n, err := get.Int64()
+	if err != nil {
+		return 0, 0, err
+	}
+	// Counts without an expiry report none left
+	return n, max(ttl.Val(), 0), nil
+}
+
+// Delete removes the count under key
+func (r *RedisCounters) Delete(ctx context.Context, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return r.client.Del(ctx, r.prefix+key).Err()
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"audit-service/pkg/clock"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCounters(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	counters := NewMemoryCounters(clk)
+
+	n, ttl, err := counters.Get(ctx, "ip:203.0.113.7")
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Zero(t, ttl)
+
+	for i := int64(1); i <= 3; i++ {
+		n, err = counters.Incr(ctx, "ip:203.0.113.7", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, i, n)
+	}
+
+	// The window starts with the first increment
+	clk.Advance(40 * time.Second)
+	n, ttl, err = counters.Get(ctx, "ip:203.0.113.7")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.Equal(t, 20*time.Second, ttl)
+
+	clk.Advance(20 * time.Second)
+	n, err = counters.Incr(ctx, "ip:203.0.113.7", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	require.NoError(t, counters.Set(ctx, "lock:ip:203.0.113.7", 2, time.Hour))
+	n, ttl, err = counters.Get(ctx, "lock:ip:203.0.113.7")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, time.Hour, ttl)
+
+	require.NoError(t, counters.Delete(ctx, "lock:ip:203.0.113.7"))
+	n, _, err = counters.Get(ctx, "lock:ip:203.0.113.7")
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestRedisCounters(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	counters := NewRedisCounters(client, "audit:", time.Second)
+
+	n, ttl, err := counters.Get(ctx, "ip:203.0.113.7")
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Zero(t, ttl)
+
+	for i := int64(1); i <= 3; i++ {
+		n, err = counters.Incr(ctx, "ip:203.0.113.7", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, i, n)
+	}
+	assert.Equal(t, time.Minute, server.TTL("audit:ip:203.0.113.7"))
+
+	server.FastForward(40 * time.Second)
+	n, ttl, err = counters.Get(ctx, "ip:203.0.113.7")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.Equal(t, 20*time.Second, ttl)
+
+	require.NoError(t, counters.Set(ctx, "lock:ip:203.0.113.7", 2, time.Hour))
+	n, ttl, err = counters.Get(ctx, "lock:ip:203.0.113.7")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, time.Hour, ttl)
+
+	require.NoError(t, counters.Delete(ctx, "lock:ip:203.0.113.7"))
+	assert.False(t, server.Exists("audit:lock:ip:203.0.113.7"))
+}
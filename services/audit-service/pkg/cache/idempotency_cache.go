@@ -0,0 +1,278 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"audit-service/pkg/clock"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrIdempotencyInProgress is returned while the first request with a key is still running
+	ErrIdempotencyInProgress = errors.New("request with this idempotency key is in progress")
+	// ErrIdempotencyKeyReused is returned when a key is sent again with a different payload
+	ErrIdempotencyKeyReused = errors.New("idempotency key was used with a different payload")
+)
+
+// IdempotentResult is the stored outcome of a completed request
+type IdempotentResult struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// IdempotencyRecord tracks a key from the first request until its window expires
+type IdempotencyRecord struct {
+	Fingerprint string            `json:"fingerprint"`
+	Result      *IdempotentResult `json:"result,omitempty"` // nil while the request is in progress
+}
+
+// IdempotencyStore keeps idempotency records until they expire
+type IdempotencyStore interface {
+	// Claim stores record under key unless the key is already held, and reports whether it did
+	Claim(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) (bool, error)
+	// Get returns the record under key, or nil if there is none
+	Get(ctx context.Context, key string) (*IdempotencyRecord, error)
+	// Set stores record under key, expiring after ttl
+	Set(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error
+	// Delete removes the record under key
+	Delete(ctx context.Context, key string) error
+}
+
+// IdempotencyCache remembers responses to requests carrying an idempotency key
+type IdempotencyCache struct {
+	store IdempotencyStore
+	// ttl is a duration that can be changed while the cache is in use
+	ttl atomic.Int64
+}
+
+// NewIdempotencyCache creates a cache kept in process memory that deduplicates requests within ttl.
+// Each replica remembers only the keys it has seen.
+func NewIdempotencyCache(ttl time.Duration, clk clock.Clock) *IdempotencyCache {
+	return NewIdempotencyCacheWithStore(NewMemoryIdempotencyStore(clk), ttl)
+}
+
+// NewIdempotencyCacheWithStore creates a cache that keeps its records in store
+func NewIdempotencyCacheWithStore(store IdempotencyStore, ttl time.Duration) *IdempotencyCache {
+	ic := &IdempotencyCache{store: store}
+	ic.SetTTL(ttl)
+	return ic
+}
+
+// SetTTL changes how long keys are remembered; keys already claimed keep their TTL
+func (ic *IdempotencyCache) SetTTL(ttl time.Duration) {
+	ic.ttl.Store(int64(ttl))
+}
+
+// Begin claims a key for a request whose payload hashes to fingerprint.
+// It returns nil when the caller should process the request, or the stored result of an
+// earlier request with the same key and payload.
+func (ic *IdempotencyCache) Begin(ctx context.Context, key, fingerprint string) (*IdempotentResult, error) {
+	cacheKey := ic.getKey(key)
+	claim := IdempotencyRecord{Fingerprint: fingerprint}
+	claimed, err := ic.store.Claim(ctx, cacheKey, claim, ic.getTTL())
+	if err != nil {
+		return nil, err
+	}
+	if claimed {
+		return nil, nil
+	}
+
+	record, err := ic.store.Get(ctx, cacheKey)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		// Expired between Claim and Get; claim it again
+		return nil, ic.store.Set(ctx, cacheKey, claim, ic.getTTL())
+	}
+
+	if record.Fingerprint != fingerprint {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if record.Result == nil {
+		return nil, ErrIdempotencyInProgress
+	}
+	return record.Result, nil
+}
+
+// Complete stores the result of a request claimed with Begin
+func (ic *IdempotencyCache) Complete(ctx context.Context, key, fingerprint string, result *IdempotentResult) error {
+	return ic.store.Set(ctx, ic.getKey(key), IdempotencyRecord{Fingerprint: fingerprint, Result: result}, ic.getTTL())
+}
+
+// Release forgets a claimed key so the request can be retried
+func (ic *IdempotencyCache) Release(ctx context.Context, key string) error {
+	return ic.store.Delete(ctx, ic.getKey(key))
+}
+
+// getTTL returns how long new keys are remembered
+func (ic *IdempotencyCache) getTTL() time.Duration {
+	return time.Duration(ic.ttl.Load())
+}
+
+// getKey generates a cache key for an idempotency key
+func (ic *IdempotencyCache) getKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return fmt.Sprintf("idem:%x", hash)
+}
+
+// MemoryIdempotencyStore keeps records in process memory, so retries are only recognized
+// by the replica that handled the first request
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*memoryIdempotencyRecord
+	// lastPrune is when expired records were last dropped
+	lastPrune time.Time
+	clock     clock.Clock
+}
+
+// memoryIdempotencyRecord is one record and when it expires
+type memoryIdempotencyRecord struct {
+	record    IdempotencyRecord
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore creates an empty in-process store that expires records by clk
+func NewMemoryIdempotencyStore(clk clock.Clock) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{records: map[string]*memoryIdempotencyRecord{}, clock: clk}
+}
+
+// Claim stores record under key unless the key is already held, and reports whether it did
+func (m *MemoryIdempotencyStore) Claim(_ context.Context, key string, record IdempotencyRecord, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+	m.prune(now)
+	if r, ok := m.records[key]; ok && now.Before(r.expiresAt) {
+		return false, nil
+	}
+	m.records[key] = &memoryIdempotencyRecord{record: record, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+// Get returns the record under key, or nil if there is none
+func (m *MemoryIdempotencyStore) Get(_ context.Context, key string) (*IdempotencyRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, ok := m.records[key]
+	if !ok || !m.clock.Now().Before(r.expiresAt) {
+		return nil, nil
+	}
+	record := r.record
+	return &record, nil
+}
+
+// Set stores record under key, expiring after ttl
+func (m *MemoryIdempotencyStore) Set(_ context.Context, key string, record IdempotencyRecord, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+	m.prune(now)
+	m.records[key] = &memoryIdempotencyRecord{record: record, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// Delete removes the record under key
+func (m *MemoryIdempotencyStore) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.records, key)
+	return nil
+}
+
+// ItemCount returns the number of records, including expired ones not yet pruned
+func (m *MemoryIdempotencyStore) ItemCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.records)
+}
+
+// prune drops expired records at most once a minute
+func (m *MemoryIdempotencyStore) prune(now time.Time) {
+	if now.Sub(m.lastPrune) < time.Minute {
+		return
+	}
+	m.lastPrune = now
+	for key, r := range m.records {
+		if !now.Before(r.expiresAt) {
+			delete(m.records, key)
+		}
+	}
+}
+
+// RedisIdempotencyStore shares records between service replicas through Redis
+type RedisIdempotencyStore struct {
+	client  redis.UniversalClient
+	prefix  string
+	timeout time.Duration
+}
+
+// NewRedisIdempotencyStore creates a store kept under prefix, giving each command timeout to complete
+func NewRedisIdempotencyStore(client redis.UniversalClient, prefix string, timeout time.Duration) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{client: client, prefix: prefix, timeout: timeout}
+}
+
+// Claim stores record under key unless the key is already held, and reports whether it did
+func (r *RedisIdempotencyStore) Claim(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return false, err
+	}
+	return r.client.SetNX(ctx, r.prefix+key, data, ttl).Result()
+}
+
+// Get returns the record under key, or nil if there is none
+func (r *RedisIdempotencyStore) Get(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	data, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var record IdempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Set stores record under key, expiring after ttl
+func (r *RedisIdempotencyStore) Set(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.prefix+key, data, ttl).Err()
+}
+
+// Delete removes the record under key
+func (r *RedisIdempotencyStore) Delete(ctx context.Context, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return r.client.Del(ctx, r.prefix+key).Err()
+}
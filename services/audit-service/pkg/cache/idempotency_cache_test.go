@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"audit-service/pkg/clock"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyCache_Begin(t *testing.T) {
+	ctx := context.Background()
+	ic := NewIdempotencyCache(time.Minute, clock.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)))
+
+	// First request claims the key
+	result, err := ic.Begin(ctx, "user-1:key-1", "fp-1")
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+
+	// A concurrent retry sees the request in progress
+	_, err = ic.Begin(ctx, "user-1:key-1", "fp-1")
+	assert.ErrorIs(t, err, ErrIdempotencyInProgress)
+
+	// Reusing the key for another payload is rejected
+	_, err = ic.Begin(ctx, "user-1:key-1", "fp-2")
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+	// Once completed, retries get the stored result
+	stored := &IdempotentResult{Status: 201, Body: []byte(`{"id":"event-1"}`)}
+	require.NoError(t, ic.Complete(ctx, "user-1:key-1", "fp-1", stored))
+
+	result, err = ic.Begin(ctx, "user-1:key-1", "fp-1")
+	assert.NoError(t, err)
+	assert.Equal(t, stored, result)
+
+	// Keys are independent
+	result, err = ic.Begin(ctx, "user-2:key-1", "fp-1")
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestIdempotencyCache_Release(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryIdempotencyStore(clock.New())
+	ic := NewIdempotencyCacheWithStore(store, time.Minute)
+
+	_, err := ic.Begin(ctx, "key", "fp")
+	assert.NoError(t, err)
+
+	require.NoError(t, ic.Release(ctx, "key"))
+
+	result, err := ic.Begin(ctx, "key", "fp")
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, 1, store.ItemCount())
+}
+
+func TestIdempotencyCache_Expiry(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	ic := NewIdempotencyCache(time.Minute, clk)
+
+	require.NoError(t, ic.Complete(ctx, "key", "fp", &IdempotentResult{Status: 201}))
+
+	clk.Advance(59 * time.Second)
+	_, err := ic.Begin(ctx, "key", "fp-other")
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+	clk.Advance(time.Second)
+	result, err := ic.Begin(ctx, "key", "fp-other")
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestIdempotencyCache_SetTTL(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	ic := NewIdempotencyCache(time.Hour, clk)
+
+	// Keys claimed after the change use the new TTL
+	ic.SetTTL(time.Minute)
+	require.NoError(t, ic.Complete(ctx, "key", "fp", &IdempotentResult{Status: 201}))
+	clk.Advance(time.Minute)
+
+	result, err := ic.Begin(ctx, "key", "fp-other")
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestIdempotencyCache_Redis(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	store := NewRedisIdempotencyStore(client, "test:idempotency:", time.Second)
+
+	// Two replicas share the records
+	first := NewIdempotencyCacheWithStore(store, time.Minute)
+	second := NewIdempotencyCacheWithStore(store, time.Minute)
+
+	result, err := first.Begin(ctx, "key", "fp")
+	require.NoError(t, err)
+	assert.Nil(t, result)
+
+	_, err = second.Begin(ctx, "key", "fp")
+	assert.ErrorIs(t, err, ErrIdempotencyInProgress)
+
+	stored := &IdempotentResult{Status: 201, ContentType: "application/json", Body: []byte(`{"id":"event-1"}`)}
+	require.NoError(t, first.Complete(ctx, "key", "fp", stored))
+
+	result, err = second.Begin(ctx, "key", "fp")
+	require.NoError(t, err)
+	assert.Equal(t, stored, result)
+
+	_, err = second.Begin(ctx, "key", "fp-other")
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+	// Records expire with the TTL
+	mr.FastForward(time.Minute)
+	result, err = second.Begin(ctx, "key", "fp-other")
+	require.NoError(t, err)
+	assert.Nil(t, result)
+
+	require.NoError(t, second.Release(ctx, "key"))
+	assert.False(t, mr.Exists("test:idempotency:"+first.getKey("key")))
+}
+
+func TestIdempotencyCache_RedisUnavailable(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ic := NewIdempotencyCacheWithStore(NewRedisIdempotencyStore(client, "test:idempotency:", 100*time.Millisecond), time.Minute)
+	mr.Close()
+
+	_, err := ic.Begin(ctx, "key", "fp")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrIdempotencyInProgress)
+}
@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"audit-service/pkg/clock"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// LRU is an in-process cache of at most size entries that evicts the least recently used
+// entry when full. Entries expire ttl after they are stored. Loads of missing keys through
+// Load are deduplicated, so concurrent lookups of a key reach the source once.
+type LRU[V any] struct {
+	size  int
+	ttl   time.Duration
+	clock clock.Clock
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// order holds the entries most recently used first
+	order *list.List
+
+	loads singleflight.Group
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	shared atomic.Uint64
+}
+
+// lruEntry is a cached value with its key, kept in the recency list
+type lruEntry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// LRUStats holds cumulative lookup results of an LRU cache
+type LRUStats struct {
+	// Hits are lookups answered from the cache
+	Hits uint64
+	// Misses are lookups that loaded the value from the source
+	Misses uint64
+	// Shared are lookups that waited for the load of a concurrent lookup of the same key
+	Shared uint64
+}
+
+// NewLRU creates an LRU cache holding up to size entries for ttl
+func NewLRU[V any](size int, ttl time.Duration, clk clock.Clock) *LRU[V] {
+	return &LRU[V]{
+		size:    size,
+		ttl:     ttl,
+		clock:   clk,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+// Get returns the unexpired value stored under key and marks it as recently used
+func (c *LRU[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	element, found := c.entries[key]
+	if !found {
+		return zero, false
+	}
+	entry := element.Value.(*lruEntry[V])
+	if !c.clock.Now().Before(entry.expiresAt) {
+		c.remove(element)
+		return zero, false
+	}
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// Set stores a value under key, evicting the least recently used entry when the cache is full
+func (c *LRU[V]) Set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.clock.Now().Add(c.ttl)
+	if element, found := c.entries[key]; found {
+		entry := element.Value.(*lruEntry[V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry[V]{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// Delete removes the entry stored under key
+func (c *LRU[V]) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, found := c.entries[key]; found {
+		c.remove(element)
+	}
+}
+
+// remove drops an entry; the caller holds the lock
+func (c *LRU[V]) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*lruEntry[V]).key)
+}
+
+// Load returns the value cached under key, or loads and caches it. Concurrent loads of a key
+// share the first one, which keeps running when its caller gives up so the others still get
+// its result. Errors are returned to every waiting caller and not cached.
+func (c *LRU[V]) Load(ctx context.Context, key string, load func(ctx context.Context) (V, error)) (V, error) {
+	if value, found := c.Get(key); found {
+		c.hits.Add(1)
+		return value, nil
+	}
+
+	// Only the first caller's function runs, so led tells it apart from callers sharing its load
+	led := false
+	results := c.loads.DoChan(key, func() (interface{}, error) {
+		led = true
+		value, err := load(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		c.Set(key, value)
+		return value, nil
+	})
+
+	var zero V
+	select {
+	case result := <-results:
+		if led {
+			c.misses.Add(1)
+		} else {
+			c.shared.Add(1)
+		}
+		if result.Err != nil {
+			return zero, result.Err
+		}
+		return result.Val.(V), nil
+	case <-ctx.Done():
+		c.misses.Add(1)
+		return zero, ctx.Err()
+	}
+}
+
+// Len returns the number of cached entries, including expired ones not yet looked up
+func (c *LRU[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Stats returns the cumulative hit, miss and shared load counts
+func (c *LRU[V]) Stats() LRUStats {
+	return LRUStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Shared: c.shared.Load(),
+	}
+}
+
+// Flush removes all entries
+func (c *LRU[V]) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*list.Element, c.size)
+	c.order.Init()
+}
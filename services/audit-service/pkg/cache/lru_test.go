@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewLRU[string](2, time.Minute, clock.New())
+
+	cache.Set("a", "1")
+	cache.Set("b", "2")
+	// Reading a makes b the least recently used entry
+	_, found := cache.Get("a")
+	require.True(t, found)
+	cache.Set("c", "3")
+
+	_, found = cache.Get("b")
+	assert.False(t, found)
+	value, found := cache.Get("a")
+	assert.True(t, found)
+	assert.Equal(t, "1", value)
+	assert.Equal(t, 2, cache.Len())
+
+	cache.Delete("a")
+	_, found = cache.Get("a")
+	assert.False(t, found)
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestLRU_Expiration(t *testing.T) {
+	clk := clock.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	cache := NewLRU[string](10, time.Minute, clk)
+
+	cache.Set("a", "1")
+	clk.Advance(59 * time.Second)
+	_, found := cache.Get("a")
+	assert.True(t, found)
+
+	clk.Advance(time.Second)
+	_, found = cache.Get("a")
+	assert.False(t, found)
+	assert.Zero(t, cache.Len())
+}
+
+func TestLRU_Load(t *testing.T) {
+	cache := NewLRU[string](10, time.Minute, clock.New())
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (string, error) {
+		loads.Add(1)
+		<-release
+		return "owner-1", nil
+	}
+
+	// Concurrent lookups of a missing key share one load
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := cache.Load(context.Background(), "session-1", load)
+			assert.NoError(t, err)
+			results[i] = value
+		}()
+	}
+	require.Eventually(t, func() bool { return loads.Load() == 1 }, time.Second, time.Millisecond)
+	// Give the other lookups time to join the running load
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loads.Load())
+	assert.Equal(t, []string{"owner-1", "owner-1", "owner-1", "owner-1", "owner-1"}, results)
+
+	// The loaded value is served from the cache
+	value, err := cache.Load(context.Background(), "session-1", load)
+	require.NoError(t, err)
+	assert.Equal(t, "owner-1", value)
+	assert.Equal(t, int32(1), loads.Load())
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(5), stats.Hits+stats.Shared)
+}
+
+func TestLRU_LoadErrorsAreNotCached(t *testing.T) {
+	cache := NewLRU[string](10, time.Minute, clock.New())
+	errUpstream := errors.New("upstream unavailable")
+
+	_, err := cache.Load(context.Background(), "session-1", func(ctx context.Context) (string, error) {
+		return "", errUpstream
+	})
+	assert.ErrorIs(t, err, errUpstream)
+
+	value, err := cache.Load(context.Background(), "session-1", func(ctx context.Context) (string, error) {
+		return "owner-1", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "owner-1", value)
+	assert.Equal(t, uint64(2), cache.Stats().Misses)
+}
+
+func TestLRU_LoadOutlivesCanceledCaller(t *testing.T) {
+	cache := NewLRU[string](10, time.Minute, clock.New())
+	release := make(chan struct{})
+	done := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		defer close(done)
+		_, err := cache.Load(ctx, "session-1", func(ctx context.Context) (string, error) {
+			<-release
+			return "owner-1", ctx.Err()
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	}()
+	cancel()
+	<-done
+	close(release)
+
+	// The abandoned load still completes and fills the cache
+	require.Eventually(t, func() bool {
+		_, found := cache.Get("session-1")
+		return found
+	}, time.Second, time.Millisecond)
+}
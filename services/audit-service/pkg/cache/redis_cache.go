@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// redisScanCount is the number of keys requested per SCAN call when counting entries
+const redisScanCount = 500
+
+// RedisCache shares entries between service replicas through Redis
+type RedisCache struct {
+	client  redis.UniversalClient
+	prefix  string
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+// NewRedisCache creates a cache that stores entries under prefix, giving each command timeout to complete
+func NewRedisCache(client redis.UniversalClient, prefix string, timeout time.Duration, logger *zap.Logger) *RedisCache {
+	return &RedisCache{
+		client:  client,
+		prefix:  prefix,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+// Get returns the entry stored under key; Redis failures are treated as misses
+func (r *RedisCache) Get(key string) (*CachedTokenInfo, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	data, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			r.logger.Warn("Token cache lookup failed", zap.Error(err))
+		}
+		return nil, false
+	}
+
+	var info CachedTokenInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		r.logger.Warn("Discarding malformed token cache entry", zap.Error(err))
+		return nil, false
+	}
+	return &info, true
+}
+
+// Set stores an entry that Redis expires after ttl
+func (r *RedisCache) Set(key string, info *CachedTokenInfo, ttl time.Duration) {
+	data, err := json.Marshal(info)
+	if err != nil {
+		r.logger.Warn("Failed to encode token cache entry", zap.Error(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	if err := r.client.Set(ctx, r.prefix+key, data, ttl).Err(); err != nil {
+		r.logger.Warn("Failed to store token cache entry", zap.Error(err))
+	}
+}
+
+// Delete removes the entry stored under key
+func (r *RedisCache) Delete(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	if err := r.client.Del(ctx, r.prefix+key).Err(); err != nil {
+		r.logger.Warn("Failed to delete token cache entry", zap.Error(err))
+	}
+}
+
+// ItemCount returns the number of entries under the prefix, or 0 when Redis is unavailable
+func (r *RedisCache) ItemCount() int {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	count := 0
+	iter := r.client.Scan(ctx, 0, r.prefix+"*", redisScanCount).Iterator()
+	for iter.Next(ctx) {
+		count++
+	}
+	if err := iter.Err(); err != nil {
+		r.logger.Warn("Failed to count token cache entries", zap.Error(err))
+		return 0
+	}
+	return count
+}
+
+// Flush removes all entries under the prefix
+func (r *RedisCache) Flush() {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	iter := r.client.Scan(ctx, 0, r.prefix+"*", redisScanCount).Iterator()
+	for iter.Next(ctx) {
+		if err := r.client.Del(ctx, iter.Val()).Err(); err != nil {
+			r.logger.Warn("Failed to flush token cache entry", zap.Error(err))
+			return
+		}
+	}
+	if err := iter.Err(); err != nil {
+		r.logger.Warn("Failed to flush token cache", zap.Error(err))
+	}
+}
+
+// Close releases the Redis connections
+func (r *RedisCache) Close(ctx context.Context) error {
+	return r.client.Close()
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"audit-service/pkg/clock"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestRedisCache(t *testing.T) (*RedisCache, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return NewRedisCache(client, "audit:", time.Second, zap.NewNop()), server
+}
+
+func TestRedisCache_Operations(t *testing.T) {
+	backend, server := newTestRedisCache(t)
+	expiresAt := time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC)
+
+	_, found := backend.Get("jwt:abc")
+	assert.False(t, found)
+
+	backend.Set("jwt:abc", &CachedTokenInfo{UserID: "user-123", ExpiresAt: expiresAt}, time.Minute)
+	assert.True(t, server.Exists("audit:jwt:abc"))
+	assert.Equal(t, time.Minute, server.TTL("audit:jwt:abc"))
+
+	info, found := backend.Get("jwt:abc")
+	require.True(t, found)
+	assert.Equal(t, "user-123", info.UserID)
+	assert.True(t, expiresAt.Equal(info.ExpiresAt))
+	assert.Equal(t, 1, backend.ItemCount())
+
+	backend.Delete("jwt:abc")
+	_, found = backend.Get("jwt:abc")
+	assert.False(t, found)
+}
+
+func TestRedisCache_ExpiresWithTTL(t *testing.T) {
+	backend, server := newTestRedisCache(t)
+
+	backend.Set("share:abc:session-1", &CachedTokenInfo{SessionID: "session-1"}, time.Minute)
+	server.FastForward(2 * time.Minute)
+
+	_, found := backend.Get("share:abc:session-1")
+	assert.False(t, found)
+}
+
+func TestRedisCache_FlushKeepsOtherKeys(t *testing.T) {
+	backend, server := newTestRedisCache(t)
+	require.NoError(t, server.Set("other:key", "value"))
+
+	backend.Set("jwt:a", &CachedTokenInfo{UserID: "user-1"}, time.Minute)
+	backend.Set("jwt:b", &CachedTokenInfo{UserID: "user-2"}, time.Minute)
+	assert.Equal(t, 2, backend.ItemCount())
+
+	backend.Flush()
+
+	assert.Equal(t, 0, backend.ItemCount())
+	assert.True(t, server.Exists("other:key"))
+}
+
+func TestRedisCache_UnavailableIsMiss(t *testing.T) {
+	backend, server := newTestRedisCache(t)
+	backend.Set("jwt:abc", &CachedTokenInfo{UserID: "user-123"}, time.Minute)
+
+	server.Close()
+
+	_, found := backend.Get("jwt:abc")
+	assert.False(t, found)
+	assert.Equal(t, 0, backend.ItemCount())
+}
+
+func TestTokenCache_SharedBetweenReplicas(t *testing.T) {
+	backend, _ := newTestRedisCache(t)
+	fakeClock := clock.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	first := NewTokenCacheWithBackend(backend, 5*time.Minute, time.Minute, fakeClock)
+	second := NewTokenCacheWithBackend(backend, 5*time.Minute, time.Minute, fakeClock)
+
+	first.SetJWT("jwt-token", &CachedTokenInfo{UserID: "user-123", ExpiresAt: fakeClock.Now().Add(time.Hour)})
+
+	info, found := second.GetJWT("jwt-token")
+	require.True(t, found)
+	assert.Equal(t, "user-123", info.UserID)
+}
@@ -3,71 +3,128 @@ package cache
 import (
 	"crypto/sha256"
 	"fmt"
+	"sync/atomic"
 	"time"
 
-	"github.com/patrickmn/go-cache"
+	"audit-service/pkg/clock"
 )
 
 // TokenCache provides caching for validated tokens
 type TokenCache struct {
-	cache *cache.Cache
-	jwtTTL time.Duration
-	shareTokenTTL time.Duration
+	cache Cache
+	clock clock.Clock
+
+	// TTLs are durations that can be changed while the cache is in use
+	jwtTTL        atomic.Int64
+	shareTokenTTL atomic.Int64
+
+	jwtHits     atomic.Uint64
+	jwtMisses   atomic.Uint64
+	shareHits   atomic.Uint64
+	shareMisses atomic.Uint64
 }
 
-// NewTokenCache creates a new token cache instance
-func NewTokenCache(jwtTTL, shareTokenTTL, cleanupInterval time.Duration) *TokenCache {
-	return &TokenCache{
-		cache:         cache.New(cache.NoExpiration, cleanupInterval),
-		jwtTTL:        jwtTTL,
-		shareTokenTTL: shareTokenTTL,
+// HitStats holds cumulative lookup results per token kind
+type HitStats struct {
+	JWTHits     uint64
+	JWTMisses   uint64
+	ShareHits   uint64
+	ShareMisses uint64
+}
+
+// NewTokenCache creates a new token cache instance kept in process memory
+func NewTokenCache(jwtTTL, shareTokenTTL, cleanupInterval time.Duration, clk clock.Clock) *TokenCache {
+	return NewTokenCacheWithBackend(NewMemoryCache(cleanupInterval), jwtTTL, shareTokenTTL, clk)
+}
+
+// NewTokenCacheWithBackend creates a token cache that stores entries in backend
+func NewTokenCacheWithBackend(backend Cache, jwtTTL, shareTokenTTL time.Duration, clk clock.Clock) *TokenCache {
+	tc := &TokenCache{
+		cache: backend,
+		clock: clk,
 	}
+	tc.SetTTLs(jwtTTL, shareTokenTTL)
+	return tc
+}
+
+// SetTTLs changes how long validation results are cached; entries already cached keep their TTL
+func (tc *TokenCache) SetTTLs(jwtTTL, shareTokenTTL time.Duration) {
+	tc.jwtTTL.Store(int64(jwtTTL))
+	tc.shareTokenTTL.Store(int64(shareTokenTTL))
 }
 
 // CachedTokenInfo stores the validated token information
 type CachedTokenInfo struct {
-	UserID    string
-	SessionID string
-	ExpiresAt time.Time
+	UserID      string   `json:"userId,omitempty"`
+	SessionID   string   `json:"sessionId,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	// OrganizationID is the tenant the token is scoped to
+	OrganizationID string `json:"organizationId,omitempty"`
+	// AuthSessionID and TokenID are the session_id and jti claims of a JWT, checked against revocations
+	AuthSessionID string    `json:"authSessionId,omitempty"`
+	TokenID       string    `json:"tokenId,omitempty"`
+	ExpiresAt     time.Time `json:"expiresAt"`
 }
 
+// Kinds of revocation markers, matching the revocation kinds of the domain
+const (
+	RevokedUser    = "user"
+	RevokedSession = "session"
+	RevokedToken   = "token"
+)
+
 // GetJWT retrieves a cached JWT validation result
 func (tc *TokenCache) GetJWT(token string) (*CachedTokenInfo, bool) {
 	key := tc.getJWTKey(token)
-	if val, found := tc.cache.Get(key); found {
-		if info, ok := val.(*CachedTokenInfo); ok {
-			// Check if the cached info has expired
-			if time.Now().Before(info.ExpiresAt) {
-				return info, true
-			}
-			// Remove expired entry
-			tc.cache.Delete(key)
+	if info, found := tc.cache.Get(key); found {
+		// Check if the cached info has expired
+		if tc.clock.Now().Before(info.ExpiresAt) {
+			tc.jwtHits.Add(1)
+			return info, true
 		}
+		// Remove expired entry
+		tc.cache.Delete(key)
 	}
+	tc.jwtMisses.Add(1)
 	return nil, false
 }
 
+// PeekJWT returns a cached JWT validation result without counting the lookup in the hit
+// statistics, for checks made before the token is authenticated
+func (tc *TokenCache) PeekJWT(token string) (*CachedTokenInfo, bool) {
+	info, found := tc.cache.Get(tc.getJWTKey(token))
+	if !found || !tc.clock.Now().Before(info.ExpiresAt) {
+		return nil, false
+	}
+	return info, true
+}
+
 // SetJWT caches a JWT validation result
 func (tc *TokenCache) SetJWT(token string, info *CachedTokenInfo) {
 	key := tc.getJWTKey(token)
-	tc.cache.Set(key, info, tc.jwtTTL)
+	tc.cache.Set(key, info, time.Duration(tc.jwtTTL.Load()))
 }
 
 // GetShareToken retrieves a cached share token validation result
 func (tc *TokenCache) GetShareToken(token, sessionID string) (*CachedTokenInfo, bool) {
 	key := tc.getShareTokenKey(token, sessionID)
-	if val, found := tc.cache.Get(key); found {
-		if info, ok := val.(*CachedTokenInfo); ok {
+	if info, found := tc.cache.Get(key); found {
+		// Share links can expire before the cache entry does
+		if tc.clock.Now().Before(info.ExpiresAt) {
+			tc.shareHits.Add(1)
 			return info, true
 		}
+		tc.cache.Delete(key)
 	}
+	tc.shareMisses.Add(1)
 	return nil, false
 }
 
 // SetShareToken caches a share token validation result
 func (tc *TokenCache) SetShareToken(token, sessionID string, info *CachedTokenInfo) {
 	key := tc.getShareTokenKey(token, sessionID)
-	tc.cache.Set(key, info, tc.shareTokenTTL)
+	tc.cache.Set(key, info, time.Duration(tc.shareTokenTTL.Load()))
 }
 
 // InvalidateJWT removes a JWT from the cache
@@ -82,6 +139,40 @@ func (tc *TokenCache) InvalidateShareToken(token, sessionID string) {
 	tc.cache.Delete(key)
 }
 
+// SetRevoked marks the tokens of a user, auth session or token ID of an organization revoked
+// until the marker is cleared or ttl passes. A zero ttl keeps the marker until it is cleared.
+func (tc *TokenCache) SetRevoked(kind, organizationID, target string, ttl time.Duration) {
+	tc.cache.Set(tc.getRevokedKey(kind, organizationID, target), &CachedTokenInfo{}, ttl)
+}
+
+// ClearRevoked removes a revocation marker
+func (tc *TokenCache) ClearRevoked(kind, organizationID, target string) {
+	tc.cache.Delete(tc.getRevokedKey(kind, organizationID, target))
+}
+
+// IsRevoked reports whether a marker revokes the user, auth session or token ID of a validated JWT
+func (tc *TokenCache) IsRevoked(info *CachedTokenInfo) bool {
+	targets := []struct{ kind, target string }{
+		{RevokedUser, info.UserID},
+		{RevokedSession, info.AuthSessionID},
+		{RevokedToken, info.TokenID},
+	}
+	for _, t := range targets {
+		if t.target == "" {
+			continue
+		}
+		if _, found := tc.cache.Get(tc.getRevokedKey(t.kind, info.OrganizationID, t.target)); found {
+			return true
+		}
+	}
+	return false
+}
+
+// getRevokedKey generates the key of a revocation marker
+func (tc *TokenCache) getRevokedKey(kind, organizationID, target string) string {
+	return fmt.Sprintf("revoked:%s:%s:%s", kind, organizationID, target)
+}
+
 // getJWTKey generates a cache key for JWT tokens
 func (tc *TokenCache) getJWTKey(token string) string {
 	// Hash the token to avoid storing sensitive data
@@ -91,7 +182,9 @@ func (tc *TokenCache) getJWTKey(token string) string {
 
 // getShareTokenKey generates a cache key for share tokens
 func (tc *TokenCache) getShareTokenKey(token, sessionID string) string {
-	return fmt.Sprintf("share:%s:%s", token, sessionID)
+	// Share tokens are bearer credentials too, so only their hash is kept
+	hash := sha256.Sum256([]byte(token))
+	return fmt.Sprintf("share:%x:%s", hash, sessionID)
 }
 
 // Stats returns cache statistics
@@ -99,12 +192,27 @@ func (tc *TokenCache) Stats() map[string]interface{} {
 	items := tc.cache.ItemCount()
 	return map[string]interface{}{
 		"items":     items,
-		"jwt_ttl":   tc.jwtTTL.String(),
-		"share_ttl": tc.shareTokenTTL.String(),
+		"jwt_ttl":   time.Duration(tc.jwtTTL.Load()).String(),
+		"share_ttl": time.Duration(tc.shareTokenTTL.Load()).String(),
+	}
+}
+
+// HitStats returns the cumulative hit and miss counts
+func (tc *TokenCache) HitStats() HitStats {
+	return HitStats{
+		JWTHits:     tc.jwtHits.Load(),
+		JWTMisses:   tc.jwtMisses.Load(),
+		ShareHits:   tc.shareHits.Load(),
+		ShareMisses: tc.shareMisses.Load(),
 	}
 }
 
+// ItemCount returns the number of cached entries, including expired ones not yet cleaned up
+func (tc *TokenCache) ItemCount() int {
+	return tc.cache.ItemCount()
+}
+
 // Clear removes all items from the cache
 func (tc *TokenCache) Clear() {
 	tc.cache.Flush()
-} 
\ No newline at end of file
+}
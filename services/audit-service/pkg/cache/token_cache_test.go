@@ -4,6 +4,8 @@ import (
 	"testing"
 	"time"
 
+	"audit-service/pkg/clock"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -12,16 +14,25 @@ func TestNewTokenCache(t *testing.T) {
 	shareTokenTTL := 1 * time.Minute
 	cleanupInterval := 10 * time.Minute
 
-	cache := NewTokenCache(jwtTTL, shareTokenTTL, cleanupInterval)
+	cache := NewTokenCache(jwtTTL, shareTokenTTL, cleanupInterval, clock.New())
 
 	assert.NotNil(t, cache)
-	assert.Equal(t, jwtTTL, cache.jwtTTL)
-	assert.Equal(t, shareTokenTTL, cache.shareTokenTTL)
+	assert.Equal(t, jwtTTL, time.Duration(cache.jwtTTL.Load()))
+	assert.Equal(t, shareTokenTTL, time.Duration(cache.shareTokenTTL.Load()))
 	assert.NotNil(t, cache.cache)
 }
 
+func TestTokenCache_SetTTLs(t *testing.T) {
+	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute, clock.New())
+
+	cache.SetTTLs(2*time.Minute, 30*time.Second)
+
+	assert.Equal(t, 2*time.Minute, time.Duration(cache.jwtTTL.Load()))
+	assert.Equal(t, 30*time.Second, time.Duration(cache.shareTokenTTL.Load()))
+}
+
 func TestTokenCache_JWT_Operations(t *testing.T) {
-	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute, clock.New())
 	token := "test-jwt-token"
 
 	// Test cache miss
@@ -49,7 +60,7 @@ func TestTokenCache_JWT_Operations(t *testing.T) {
 }
 
 func TestTokenCache_ShareToken_Operations(t *testing.T) {
-	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute, clock.New())
 	token := "test-share-token"
 	sessionID := "session-123"
 
@@ -78,7 +89,7 @@ func TestTokenCache_ShareToken_Operations(t *testing.T) {
 }
 
 func TestTokenCache_JWT_Expiration(t *testing.T) {
-	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute, clock.New())
 	token := "expired-jwt-token"
 
 	// Set token with past expiration
@@ -94,8 +105,53 @@ func TestTokenCache_JWT_Expiration(t *testing.T) {
 	assert.Nil(t, info)
 }
 
+func TestTokenCache_JWT_ExpiresWithFakeClock(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute, fakeClock)
+	token := "short-lived-jwt-token"
+
+	cache.SetJWT(token, &CachedTokenInfo{
+		UserID:    "user-123",
+		ExpiresAt: fakeClock.Now().Add(1 * time.Minute),
+	})
+
+	// Still valid before the token expires
+	info, found := cache.GetJWT(token)
+	assert.True(t, found)
+	assert.Equal(t, "user-123", info.UserID)
+
+	// Move past the token expiry without sleeping
+	fakeClock.Advance(2 * time.Minute)
+
+	info, found = cache.GetJWT(token)
+	assert.False(t, found)
+	assert.Nil(t, info)
+}
+
+func TestTokenCache_ShareToken_ExpiresWithFakeClock(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	cache := NewTokenCache(5*time.Minute, 5*time.Minute, 10*time.Minute, fakeClock)
+
+	cache.SetShareToken("share-token", "session-1", &CachedTokenInfo{
+		SessionID:   "session-1",
+		Permissions: []string{"VIEW"},
+		ExpiresAt:   fakeClock.Now().Add(1 * time.Minute),
+	})
+
+	info, found := cache.GetShareToken("share-token", "session-1")
+	assert.True(t, found)
+	assert.Equal(t, []string{"VIEW"}, info.Permissions)
+
+	// The share link expires before the cache TTL
+	fakeClock.Advance(2 * time.Minute)
+
+	info, found = cache.GetShareToken("share-token", "session-1")
+	assert.False(t, found)
+	assert.Nil(t, info)
+}
+
 func TestTokenCache_JWTKeyGeneration(t *testing.T) {
-	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute, clock.New())
 
 	// Test that same token generates same key
 	token := "test-token"
@@ -111,7 +167,7 @@ func TestTokenCache_JWTKeyGeneration(t *testing.T) {
 }
 
 func TestTokenCache_ShareTokenKeyGeneration(t *testing.T) {
-	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute, clock.New())
 
 	token := "share-token"
 	sessionID := "session-123"
@@ -121,7 +177,7 @@ func TestTokenCache_ShareTokenKeyGeneration(t *testing.T) {
 	key2 := cache.getShareTokenKey(token, sessionID)
 	assert.Equal(t, key1, key2)
 	assert.Contains(t, key1, "share:")
-	assert.Contains(t, key1, token)
+	assert.NotContains(t, key1, token)
 	assert.Contains(t, key1, sessionID)
 
 	// Test different session generates different key
@@ -130,7 +186,7 @@ func TestTokenCache_ShareTokenKeyGeneration(t *testing.T) {
 }
 
 func TestTokenCache_Stats(t *testing.T) {
-	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute, clock.New())
 
 	// Initial stats
 	stats := cache.Stats()
@@ -150,7 +206,7 @@ func TestTokenCache_Stats(t *testing.T) {
 }
 
 func TestTokenCache_Clear(t *testing.T) {
-	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute)
+	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute, clock.New())
 
 	// Add some items
 	cache.SetJWT("jwt-token", &CachedTokenInfo{UserID: "user1"})
@@ -174,3 +230,46 @@ func TestTokenCache_Clear(t *testing.T) {
 	_, found = cache.GetShareToken("share-token", "session1")
 	assert.False(t, found)
 }
+
+func TestTokenCache_HitStats(t *testing.T) {
+	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute, clock.New())
+
+	cache.SetJWT("jwt-token", &CachedTokenInfo{UserID: "user1", ExpiresAt: time.Now().Add(time.Hour)})
+	cache.SetShareToken("share-token", "session1", &CachedTokenInfo{SessionID: "session1", ExpiresAt: time.Now().Add(time.Hour)})
+
+	cache.GetJWT("jwt-token")
+	cache.GetJWT("jwt-token")
+	cache.GetJWT("unknown-jwt")
+	cache.GetShareToken("share-token", "session1")
+	cache.GetShareToken("share-token", "session2")
+
+	assert.Equal(t, HitStats{
+		JWTHits:     2,
+		JWTMisses:   1,
+		ShareHits:   1,
+		ShareMisses: 1,
+	}, cache.HitStats())
+}
+
+func TestTokenCache_Revoked(t *testing.T) {
+	cache := NewTokenCache(5*time.Minute, 1*time.Minute, 10*time.Minute, clock.New())
+	info := &CachedTokenInfo{
+		UserID:         "user-123",
+		AuthSessionID:  "session-1",
+		TokenID:        "token-1",
+		OrganizationID: "acme",
+	}
+	assert.False(t, cache.IsRevoked(info))
+
+	for _, kind := range []string{RevokedUser, RevokedSession, RevokedToken} {
+		target := map[string]string{RevokedUser: info.UserID, RevokedSession: info.AuthSessionID, RevokedToken: info.TokenID}[kind]
+		cache.SetRevoked(kind, "acme", target, time.Minute)
+		assert.True(t, cache.IsRevoked(info), kind)
+		cache.ClearRevoked(kind, "acme", target)
+		assert.False(t, cache.IsRevoked(info), kind)
+	}
+
+	// Markers only apply to tokens of their organization
+	cache.SetRevoked(RevokedUser, "globex", info.UserID, 0)
+	assert.False(t, cache.IsRevoked(info))
+}
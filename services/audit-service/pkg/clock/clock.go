@@ -0,0 +1,58 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides the current time to components that need it
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock implements the Clock interface using the system time
+type realClock struct{}
+
+// New creates a clock backed by the system time, always returning UTC
+func New() Clock {
+	return realClock{}
+}
+
+// Now returns the current system time in UTC
+func (realClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+// FakeClock is a manually controlled clock for deterministic tests
+type FakeClock struct {
+	now   time.Time
+	mutex sync.RWMutex
+}
+
+// NewFakeClock creates a fake clock frozen at the given time
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		now: now.UTC(),
+	}
+}
+
+// Now returns the current fake time
+func (f *FakeClock) Now() time.Time {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.now
+}
+
+// Set moves the fake clock to the given time
+func (f *FakeClock) Set(now time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.now = now.UTC()
+}
+
+// Advance moves the fake clock forward by the given duration
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.now = f.now.Add(d)
+}
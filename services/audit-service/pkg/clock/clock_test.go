@@ -0,0 +1,44 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_ReturnsUTC(t *testing.T) {
+	clk := New()
+
+	now := clk.Now()
+	assert.Equal(t, time.UTC, now.Location())
+	assert.WithinDuration(t, time.Now(), now, time.Second)
+}
+
+func TestFakeClock_NormalizesToUTC(t *testing.T) {
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	start := time.Date(2024, 1, 1, 15, 0, 0, 0, loc)
+
+	clk := NewFakeClock(start)
+
+	assert.Equal(t, time.UTC, clk.Now().Location())
+	assert.True(t, clk.Now().Equal(start))
+}
+
+func TestFakeClock_Advance(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clk := NewFakeClock(start)
+
+	clk.Advance(90 * time.Minute)
+
+	assert.Equal(t, start.Add(90*time.Minute), clk.Now())
+}
+
+func TestFakeClock_Set(t *testing.T) {
+	clk := NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	target := time.Date(2025, 6, 1, 8, 30, 0, 0, time.UTC)
+
+	clk.Set(target)
+
+	assert.Equal(t, target, clk.Now())
+}
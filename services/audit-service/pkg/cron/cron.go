@@ -0,0 +1,200 @@
+// Package cron parses the five-field cron expressions used to schedule recurring jobs and
+// computes their next run times in UTC.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSchedule is returned for expressions that cannot be parsed
+var ErrInvalidSchedule = errors.New("invalid cron schedule")
+
+// descriptors are the shorthand expressions accepted in place of the five fields
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field is the range of values of one cron field
+type field struct {
+	name     string
+	min, max int
+}
+
+var (
+	minuteField = field{"minute", 0, 59}
+	hourField   = field{"hour", 0, 23}
+	domField    = field{"day of month", 1, 31}
+	monthField  = field{"month", 1, 12}
+	// Day of week 7 is accepted as Sunday like in most cron implementations
+	dowField = field{"day of week", 0, 7}
+)
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny are set when the day field is *; when both day fields are restricted a
+	// day matches either of them
+	domAny, dowAny bool
+}
+
+// Parse parses a five-field expression (minute hour day-of-month month day-of-week) or one of
+// @yearly, @monthly, @weekly, @daily and @hourly. Fields accept *, values, ranges, lists and
+// steps such as */15 or 1-5.
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if expanded, ok := descriptors[strings.ToLower(expr)]; ok {
+		expr = expanded
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: expected 5 fields, got %d", ErrInvalidSchedule, len(fields))
+	}
+
+	var s Schedule
+	var err error
+	if s.minute, err = parseField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseField(fields[2], domField); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseField(fields[4], dowField); err != nil {
+		return nil, err
+	}
+	// Fold Sunday as 7 into Sunday as 0
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	s.domAny = fields[2] == "*"
+	s.dowAny = fields[4] == "*"
+	return &s, nil
+}
+
+// parseField returns the bit set of the values matched by a comma-separated field
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		set, err := parseRange(part, f)
+		if err != nil {
+			return 0, err
+		}
+		bits |= set
+	}
+	return bits, nil
+}
+
+// parseRange returns the bit set of one *, value, range or stepped range
+func parseRange(expr string, f field) (uint64, error) {
+	rangeExpr, stepExpr, stepped := strings.Cut(expr, "/")
+	step := 1
+	if stepped {
+		var err error
+		if step, err = strconv.Atoi(stepExpr); err != nil || step <= 0 {
+			return 0, fmt.Errorf("%w: invalid step %q in %s field", ErrInvalidSchedule, stepExpr, f.name)
+		}
+	}
+
+	var low, high int
+	switch {
+	case rangeExpr == "*":
+		low, high = f.min, f.max
+	case strings.Contains(rangeExpr, "-"):
+		lowExpr, highExpr, _ := strings.Cut(rangeExpr, "-")
+		var err error
+		if low, err = parseValue(lowExpr, f); err != nil {
+			return 0, err
+		}
+		if high, err = parseValue(highExpr, f); err != nil {
+			return 0, err
+		}
+		if low > high {
+			return 0, fmt.Errorf("%w: range %q in %s field is reversed", ErrInvalidSchedule, rangeExpr, f.name)
+		}
+	default:
+		var err error
+		if low, err = parseValue(rangeExpr, f); err != nil {
+			return 0, err
+		}
+		high = low
+		// 5/10 runs from 5 to the end of the range
+		if stepped {
+			high = f.max
+		}
+	}
+
+	var bits uint64
+	for v := low; v <= high; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+// parseValue parses a number within the range of the field
+func parseValue(expr string, f field) (int, error) {
+	v, err := strconv.Atoi(expr)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid value %q in %s field", ErrInvalidSchedule, expr, f.name)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%w: %s value %d is outside %d-%d", ErrInvalidSchedule, f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// maxSearchYears bounds the search for expressions that never match, such as February 30
+const maxSearchYears = 5
+
+// Next returns the first time matching the schedule strictly after t, in UTC. It returns the
+// zero time when the schedule never matches.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches reports whether the day of t matches the day-of-month and day-of-week fields
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,58 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule_Next(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2024, 1, 17, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		want time.Time
+	}{
+		{"every minute", "* * * * *", time.Date(2024, 1, 17, 10, 31, 0, 0, time.UTC)},
+		{"quarter hours", "*/15 * * * *", time.Date(2024, 1, 17, 10, 45, 0, 0, time.UTC)},
+		{"daily", "@daily", time.Date(2024, 1, 18, 0, 0, 0, 0, time.UTC)},
+		{"weekly on monday morning", "0 6 * * 1", time.Date(2024, 1, 22, 6, 0, 0, 0, time.UTC)},
+		{"sunday as 7", "0 0 * * 7", time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)},
+		{"monthly", "@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"weekdays list", "0 9 * * 1,3,5", time.Date(2024, 1, 19, 9, 0, 0, 0, time.UTC)},
+		{"hour range", "0 8-10 * * *", time.Date(2024, 1, 18, 8, 0, 0, 0, time.UTC)},
+		{"leap day", "0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"day of month or week", "0 0 1 * 5", time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"never", "0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, schedule.Next(from))
+		})
+	}
+}
+
+func TestSchedule_NextIsStrictlyAfter(t *testing.T) {
+	schedule, err := Parse("0 6 * * 1")
+	require.NoError(t, err)
+
+	at := time.Date(2024, 1, 22, 6, 0, 0, 0, time.UTC)
+	assert.Equal(t, at.AddDate(0, 0, 7), schedule.Next(at))
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8",
+		"5-1 * * * *", "*/0 * * * *", "a * * * *", "@fortnightly"} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := Parse(expr)
+			assert.ErrorIs(t, err, ErrInvalidSchedule)
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"audit-service/pkg/clock"
+)
+
+var (
+	// ErrUnknownKey is returned when a value was wrapped by a key that is not configured
+	ErrUnknownKey = errors.New("unknown encryption key")
+	// ErrDecrypt is returned when a value or data key fails authentication
+	ErrDecrypt = errors.New("failed to decrypt")
+)
+
+const (
+	// envelopeVersion is the format version written to new envelopes
+	envelopeVersion = 1
+
+	// envelopeField is the only key of a JSON object holding an encrypted value
+	envelopeField = "$encrypted"
+
+	// maxCachedKeys bounds the unwrapped data keys kept in memory
+	maxCachedKeys = 1024
+)
+
+// KeyProvider wraps data keys with a key-encryption key held locally or by a KMS
+type KeyProvider interface {
+	// WrapKey encrypts a data key and returns the ID of the key that wrapped it
+	WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+	// UnwrapKey decrypts a data key wrapped by the key with the given ID
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// envelope is the stored form of an encrypted value
+type envelope struct {
+	Version int    `json:"v"`
+	KeyID   string `json:"kid"`
+	DataKey []byte `json:"dek"`
+	// Ciphertext is the AES-GCM nonce followed by the sealed value
+	Ciphertext []byte `json:"ct"`
+}
+
+// dataKey is a plaintext data key with its wrapped form
+type dataKey struct {
+	plain   []byte
+	keyID   string
+	wrapped []byte
+	expires time.Time
+}
+
+// Encryptor encrypts JSON values with AES-GCM data keys wrapped by a KeyProvider. A data key
+// is reused until dataKeyTTL has passed, so the provider is not called for every value.
+type Encryptor struct {
+	keys       KeyProvider
+	dataKeyTTL time.Duration
+	clock      clock.Clock
+
+	mu        sync.Mutex
+	current   *dataKey
+	unwrapped map[string][]byte
+}
+
+// New creates an encryptor
+func New(keys KeyProvider, dataKeyTTL time.Duration, clk clock.Clock) *Encryptor {
+	return &Encryptor{
+		keys:       keys,
+		dataKeyTTL: dataKeyTTL,
+		clock:      clk,
+		unwrapped:  map[string][]byte{},
+	}
+}
+
+// IsEncrypted reports whether a JSON value is an envelope written by Encrypt
+func IsEncrypted(value []byte) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(value, &fields); err != nil || len(fields) != 1 {
+		return false
+	}
+	_, ok := fields[envelopeField]
+	return ok
+}
+
+// Encrypt seals a JSON value into a JSON envelope. The additional data, such as the ID of the
+// record holding the value, must be passed again to Decrypt, so envelopes cannot be swapped.
+func (e *Encryptor) Encrypt(ctx context.Context, value, additionalData []byte) ([]byte, error) {
+	key, err := e.dataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(key.plain)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := seal(aead, value, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(map[string]envelope{envelopeField: {
+		Version:    envelopeVersion,
+		KeyID:      key.keyID,
+		DataKey:    key.wrapped,
+		Ciphertext: ciphertext,
+	}})
+}
+
+// Decrypt opens an envelope written by Encrypt; values that are not envelopes are returned as is
+func (e *Encryptor) Decrypt(ctx context.Context, value, additionalData []byte) ([]byte, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	var wrapper map[string]envelope
+	if err := json.Unmarshal(value, &wrapper); err != nil {
+		return nil, fmt.Errorf("invalid envelope: %w", err)
+	}
+	env := wrapper[envelopeField]
+	if env.Version != envelopeVersion {
+		return nil, fmt.Errorf("unsupported envelope version %d", env.Version)
+	}
+
+	plainKey, err := e.unwrap(ctx, env.KeyID, env.DataKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(plainKey)
+	if err != nil {
+		return nil, err
+	}
+	return open(aead, env.Ciphertext, additionalData)
+}
+
+// dataKey returns the current data key, generating and wrapping a new one when it has expired
+func (e *Encryptor) dataKey(ctx context.Context) (*dataKey, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.clock.Now()
+	if e.current != nil && now.Before(e.current.expires) {
+		return e.current, nil
+	}
+
+	plain := make([]byte, KeySize)
+	if _, err := rand.Read(plain); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	keyID, wrapped, err := e.keys.WrapKey(ctx, plain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	e.current = &dataKey{plain: plain, keyID: keyID, wrapped: wrapped, expires: now.Add(e.dataKeyTTL)}
+	e.cacheLocked(cacheKey(keyID, wrapped), plain)
+	return e.current, nil
+}
+
+// unwrap returns the plaintext of a wrapped data key, asking the provider only on a cache miss
+func (e *Encryptor) unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	id := cacheKey(keyID, wrapped)
+
+	e.mu.Lock()
+	plain, ok := e.unwrapped[id]
+	e.mu.Unlock()
+	if ok {
+		return plain, nil
+	}
+
+	plain, err := e.keys.UnwrapKey(ctx, keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	e.cacheLocked(id, plain)
+	e.mu.Unlock()
+	return plain, nil
+}
+
+// cacheLocked remembers an unwrapped data key, starting over when the cache is full
+func (e *Encryptor) cacheLocked(id string, plain []byte) {
+	if len(e.unwrapped) >= maxCachedKeys {
+		e.unwrapped = map[string][]byte{}
+	}
+	e.unwrapped[id] = plain
+}
+
+// cacheKey identifies a wrapped data key
+func cacheKey(keyID string, wrapped []byte) string {
+	var b bytes.Buffer
+	b.WriteString(keyID)
+	b.WriteByte(0)
+	b.Write(wrapped)
+	return b.String()
+}
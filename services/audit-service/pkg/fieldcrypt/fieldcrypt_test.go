@@ -0,0 +1,162 @@
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingProvider counts the calls made to the keyring it wraps
+type countingProvider struct {
+	*Keyring
+	wraps, unwraps int
+}
+
+func (p *countingProvider) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	p.wraps++
+	return p.Keyring.WrapKey(ctx, dataKey)
+}
+
+func (p *countingProvider) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	p.unwraps++
+	return p.Keyring.UnwrapKey(ctx, keyID, wrapped)
+}
+
+func newTestKey(t *testing.T, id string) Key {
+	t.Helper()
+	secret := make([]byte, KeySize)
+	_, err := rand.Read(secret)
+	require.NoError(t, err)
+	return Key{ID: id, Secret: secret}
+}
+
+func newTestKeyring(t *testing.T, keys ...Key) *Keyring {
+	t.Helper()
+	keyring, err := NewKeyring(keys)
+	require.NoError(t, err)
+	return keyring
+}
+
+func TestEncryptor_RoundTrip(t *testing.T) {
+	enc := New(newTestKeyring(t, newTestKey(t, "k1")), time.Hour, clock.New())
+	ctx := context.Background()
+	details := []byte(`{"slideId":"slide-1","after":"Confidential launch date"}`)
+
+	sealed, err := enc.Encrypt(ctx, details, []byte("event-1"))
+	require.NoError(t, err)
+
+	assert.True(t, IsEncrypted(sealed))
+	assert.NotContains(t, string(sealed), "Confidential")
+	// The envelope is a JSON object, so it can be stored in a jsonb column
+	var stored map[string]map[string]interface{}
+	require.NoError(t, json.Unmarshal(sealed, &stored))
+	assert.Equal(t, "k1", stored["$encrypted"]["kid"])
+
+	opened, err := enc.Decrypt(ctx, sealed, []byte("event-1"))
+	require.NoError(t, err)
+	assert.JSONEq(t, string(details), string(opened))
+
+	// An envelope copied to another record does not open
+	_, err = enc.Decrypt(ctx, sealed, []byte("event-2"))
+	assert.ErrorIs(t, err, ErrDecrypt)
+}
+
+func TestEncryptor_PassesThroughPlainValues(t *testing.T) {
+	enc := New(newTestKeyring(t, newTestKey(t, "k1")), time.Hour, clock.New())
+
+	for _, value := range []string{`{"slideId":"slide-1"}`, `{"$encrypted":{},"other":1}`, `null`, ``} {
+		assert.False(t, IsEncrypted([]byte(value)), value)
+		opened, err := enc.Decrypt(context.Background(), []byte(value), nil)
+		require.NoError(t, err)
+		assert.Equal(t, value, string(opened))
+	}
+}
+
+func TestEncryptor_ReusesDataKeyUntilExpiry(t *testing.T) {
+	provider := &countingProvider{Keyring: newTestKeyring(t, newTestKey(t, "k1"))}
+	clk := clock.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	enc := New(provider, time.Hour, clk)
+	ctx := context.Background()
+
+	first, err := enc.Encrypt(ctx, []byte(`{"n":1}`), nil)
+	require.NoError(t, err)
+	_, err = enc.Encrypt(ctx, []byte(`{"n":2}`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, provider.wraps)
+
+	clk.Advance(time.Hour)
+	_, err = enc.Encrypt(ctx, []byte(`{"n":3}`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, provider.wraps)
+
+	// Data keys wrapped by this process are already known
+	_, err = enc.Decrypt(ctx, first, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, provider.unwraps)
+
+	// Another process unwraps once per data key
+	other := New(provider, time.Hour, clk)
+	for i := 0; i < 2; i++ {
+		_, err = other.Decrypt(ctx, first, nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, provider.unwraps)
+}
+
+func TestEncryptor_KeyRotation(t *testing.T) {
+	oldKey, newKey := newTestKey(t, "2024-01"), newTestKey(t, "2024-06")
+	ctx := context.Background()
+
+	before := New(newTestKeyring(t, oldKey), time.Hour, clock.New())
+	sealed, err := before.Encrypt(ctx, []byte(`{"text":"old"}`), nil)
+	require.NoError(t, err)
+
+	// The new key wraps new data keys; the old one still opens existing values
+	after := New(newTestKeyring(t, newKey, oldKey), time.Hour, clock.New())
+	opened, err := after.Decrypt(ctx, sealed, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"text":"old"}`, string(opened))
+
+	resealed, err := after.Encrypt(ctx, []byte(`{"text":"new"}`), nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(resealed), `"kid":"2024-06"`)
+
+	// Once the old key is removed its values can no longer be read
+	withoutOld := New(newTestKeyring(t, newKey), time.Hour, clock.New())
+	_, err = withoutOld.Decrypt(ctx, sealed, nil)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestParseKeys(t *testing.T) {
+	secret := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", KeySize)))
+
+	keys, err := ParseKeys("2024-06:" + secret + ", 2024-01:" + secret)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, "2024-06", keys[0].ID)
+	assert.Equal(t, []byte(strings.Repeat("k", KeySize)), keys[1].Secret)
+
+	keys, err = ParseKeys("")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	for _, value := range []string{
+		"no-separator",
+		":" + secret,
+		"short:" + base64.StdEncoding.EncodeToString([]byte("too short")),
+		"bad:not base64!",
+		"dup:" + secret + ",dup:" + secret,
+	} {
+		_, err := ParseKeys(value)
+		assert.Error(t, err, value)
+	}
+}
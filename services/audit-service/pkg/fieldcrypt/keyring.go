@@ -0,0 +1,124 @@
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// KeySize is the length of key-encryption and data keys, selecting AES-256
+const KeySize = 32
+
+// Key is a configured key-encryption key
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// ParseKeys reads a comma-separated list of id:base64 entries, e.g. "2024-06:q83v...,2024-01:Zm9v...".
+// Secrets must decode to 32 bytes.
+func ParseKeys(value string) ([]Key, error) {
+	var keys []Key
+	ids := map[string]bool{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(item, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid key entry, expected id:base64")
+		}
+		secret, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(secret) != KeySize {
+			return nil, fmt.Errorf("key %q: secret must be %d base64-encoded bytes", id, KeySize)
+		}
+		if ids[id] {
+			return nil, fmt.Errorf("key %q is configured twice", id)
+		}
+		ids[id] = true
+
+		keys = append(keys, Key{ID: id, Secret: secret})
+	}
+	return keys, nil
+}
+
+// Keyring wraps data keys with locally configured keys. The first key wraps new data keys;
+// the others only unwrap, so a key can be rotated by putting a new one first.
+type Keyring struct {
+	active string
+	aeads  map[string]cipher.AEAD
+}
+
+// NewKeyring creates a keyring from at least one key
+func NewKeyring(keys []Key) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one key is required")
+	}
+
+	aeads := make(map[string]cipher.AEAD, len(keys))
+	for _, key := range keys {
+		aead, err := newAEAD(key.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key.ID, err)
+		}
+		aeads[key.ID] = aead
+	}
+	return &Keyring{active: keys[0].ID, aeads: aeads}, nil
+}
+
+// WrapKey encrypts a data key with the first configured key
+func (k *Keyring) WrapKey(_ context.Context, dataKey []byte) (string, []byte, error) {
+	wrapped, err := seal(k.aeads[k.active], dataKey, []byte(k.active))
+	if err != nil {
+		return "", nil, err
+	}
+	return k.active, wrapped, nil
+}
+
+// UnwrapKey decrypts a data key wrapped by the key with the given ID
+func (k *Keyring) UnwrapKey(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := k.aeads[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+	return open(aead, wrapped, []byte(keyID))
+}
+
+// newAEAD returns AES-GCM for a 32-byte key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes", KeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts with a random nonce and returns nonce || ciphertext
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts nonce || ciphertext produced by seal
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], additionalData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,130 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"audit-service/pkg/clock"
+	"audit-service/pkg/sigv4"
+)
+
+// KMSConfig identifies an AWS KMS key and the credentials allowed to use it
+type KMSConfig struct {
+	// KeyID is the ID, ARN or alias of the key that wraps new data keys
+	KeyID  string
+	Region string
+	// Endpoint overrides https://kms.{region}.amazonaws.com, e.g. for LocalStack
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// KMS wraps data keys with an AWS KMS key. Unwrapping works for any key the credentials may
+// use, so data keys wrapped before the key ID was changed stay readable.
+type KMS struct {
+	cfg        KMSConfig
+	endpoint   string
+	httpClient *http.Client
+	clock      clock.Clock
+}
+
+// NewKMS creates a KMS key provider
+func NewKMS(cfg KMSConfig, httpClient *http.Client, clk clock.Clock) (*KMS, error) {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://kms." + cfg.Region + ".amazonaws.com"
+	}
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid KMS endpoint %q: scheme and host are required", endpoint)
+	}
+
+	return &KMS{
+		cfg:        cfg,
+		endpoint:   strings.TrimSuffix(endpoint, "/") + "/",
+		httpClient: httpClient,
+		clock:      clk,
+	}, nil
+}
+
+// WrapKey encrypts a data key with the configured KMS key
+func (k *KMS) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	var resp struct {
+		CiphertextBlob []byte `json:"CiphertextBlob"`
+		KeyID          string `json:"KeyId"`
+	}
+	if err := k.call(ctx, "Encrypt", map[string]interface{}{
+		"KeyId":     k.cfg.KeyID,
+		"Plaintext": dataKey,
+	}, &resp); err != nil {
+		return "", nil, err
+	}
+	return resp.KeyID, resp.CiphertextBlob, nil
+}
+
+// UnwrapKey decrypts a data key wrapped by the KMS key with the given ID
+func (k *KMS) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	var resp struct {
+		Plaintext []byte `json:"Plaintext"`
+	}
+	if err := k.call(ctx, "Decrypt", map[string]interface{}{
+		"KeyId":          keyID,
+		"CiphertextBlob": wrapped,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Plaintext, nil
+}
+
+// call sends a signed request to a KMS JSON API action
+func (k *KMS) call(ctx context.Context, action string, params, result interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create KMS request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService."+action)
+	sigv4.Sign(req, body, sigv4.Credentials{
+		AccessKeyID:     k.cfg.AccessKeyID,
+		SecretAccessKey: k.cfg.SecretAccessKey,
+	}, k.cfg.Region, "kms", k.clock.Now())
+
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("KMS %s request failed: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read KMS response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		var kmsErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &kmsErr)
+		// A blob that does not belong to a usable key cannot be decrypted
+		if strings.HasSuffix(kmsErr.Type, "InvalidCiphertextException") || strings.HasSuffix(kmsErr.Type, "IncorrectKeyException") {
+			return fmt.Errorf("%w: %s", ErrDecrypt, kmsErr.Message)
+		}
+		return fmt.Errorf("KMS %s failed with status %d: %s %s", action, resp.StatusCode, kmsErr.Type, kmsErr.Message)
+	}
+
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("failed to parse KMS %s response: %w", action, err)
+	}
+	return nil
+}
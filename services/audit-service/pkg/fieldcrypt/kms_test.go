@@ -0,0 +1,91 @@
+package fieldcrypt
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"audit-service/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKMS reverses plaintexts instead of encrypting them
+func fakeKMS(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/20240101/eu-west-1/kms/aws4_request"))
+		assert.Equal(t, "application/x-amz-json-1.1", r.Header.Get("Content-Type"))
+
+		body, _ := io.ReadAll(r.Body)
+		var params struct {
+			KeyID          string `json:"KeyId"`
+			Plaintext      []byte `json:"Plaintext"`
+			CiphertextBlob []byte `json:"CiphertextBlob"`
+		}
+		require.NoError(t, json.Unmarshal(body, &params))
+
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.Encrypt":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"KeyId":          "arn:aws:kms:eu-west-1:111122223333:key/" + params.KeyID,
+				"CiphertextBlob": reverse(params.Plaintext),
+			})
+		case "TrentService.Decrypt":
+			if !strings.HasPrefix(params.KeyID, "arn:aws:kms:") {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"__type":"IncorrectKeyException","message":"The key ID in the request does not identify a CMK"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"Plaintext": reverse(params.CiphertextBlob)})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+}
+
+func reverse(data []byte) []byte {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[len(data)-1-i] = b
+	}
+	return out
+}
+
+func TestKMS_WrapsDataKeys(t *testing.T) {
+	server := fakeKMS(t)
+	defer server.Close()
+
+	kms, err := NewKMS(KMSConfig{
+		KeyID: "audit-details", Region: "eu-west-1", Endpoint: server.URL,
+		AccessKeyID: "key", SecretAccessKey: "secret",
+	}, server.Client(), clock.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	enc := New(kms, time.Hour, clock.New())
+	sealed, err := enc.Encrypt(ctx, []byte(`{"text":"Bonjour"}`), []byte("event-1"))
+	require.NoError(t, err)
+	assert.Contains(t, string(sealed), `"kid":"arn:aws:kms:eu-west-1:111122223333:key/audit-details"`)
+
+	opened, err := New(kms, time.Hour, clock.New()).Decrypt(ctx, sealed, []byte("event-1"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"text":"Bonjour"}`, string(opened))
+
+	_, err = kms.UnwrapKey(ctx, "unknown", []byte("blob"))
+	assert.ErrorIs(t, err, ErrDecrypt)
+}
+
+func TestNewKMS_DefaultEndpoint(t *testing.T) {
+	kms, err := NewKMS(KMSConfig{KeyID: "alias/audit", Region: "eu-west-1"}, http.DefaultClient, clock.New())
+	require.NoError(t, err)
+	assert.Equal(t, "https://kms.eu-west-1.amazonaws.com/", kms.endpoint)
+
+	_, err = NewKMS(KMSConfig{Endpoint: "localhost:4566"}, http.DefaultClient, clock.New())
+	assert.Error(t, err)
+}
@@ -0,0 +1,285 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"audit-service/pkg/clock"
+
+	"go.uber.org/zap"
+)
+
+// minJWKSRefreshInterval limits refetches triggered by tokens with an unknown key ID, so
+// forged key IDs cannot flood the auth server
+const minJWKSRefreshInterval = 30 * time.Second
+
+// ErrUnknownKey is returned for tokens signed with a key the key set does not contain
+var ErrUnknownKey = errors.New("signing key not found in JWKS")
+
+// SupabaseJWKSURL returns the JWKS endpoint of a Supabase project
+func SupabaseJWKSURL(supabaseURL string) string {
+	return SupabaseIssuer(supabaseURL) + "/.well-known/jwks.json"
+}
+
+// SupabaseIssuer returns the iss claim of tokens issued by the auth server of a Supabase project
+func SupabaseIssuer(supabaseURL string) string {
+	return strings.TrimSuffix(supabaseURL, "/") + "/auth/v1"
+}
+
+// jsonWebKey is a public key of a JWKS document
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey is a parsed key with the algorithm it is restricted to, if any
+type publicKey struct {
+	alg string
+	key interface{}
+}
+
+// KeySet caches the public keys of a JWKS endpoint. Keys are refreshed periodically and as
+// soon as a token names a key ID that is not cached, so keys rotated in by the auth server are
+// picked up without a restart.
+type KeySet struct {
+	url             string
+	client          *http.Client
+	refreshInterval time.Duration
+	clock           clock.Clock
+	logger          *zap.Logger
+
+	mu        sync.RWMutex
+	keys      map[string]publicKey
+	fetchedAt time.Time
+
+	// fetchMu serializes fetches so concurrent misses share one request
+	fetchMu sync.Mutex
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// NewKeySet creates a key set for a JWKS URL; call Refresh to load the keys and Start to keep
+// them current
+func NewKeySet(url string, client *http.Client, refreshInterval time.Duration, clk clock.Clock, logger *zap.Logger) *KeySet {
+	return &KeySet{
+		url:             url,
+		client:          client,
+		refreshInterval: refreshInterval,
+		clock:           clk,
+		logger:          logger,
+		keys:            make(map[string]publicKey),
+		stop:            make(chan struct{}),
+		stopped:         make(chan struct{}),
+	}
+}
+
+// Start refreshes the keys every refresh interval until Close
+func (k *KeySet) Start() {
+	go func() {
+		defer close(k.stopped)
+		if k.refreshInterval <= 0 {
+			<-k.stop
+			return
+		}
+
+		ticker := time.NewTicker(k.refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-k.stop:
+				return
+			case <-ticker.C:
+				if err := k.Refresh(context.Background()); err != nil {
+					// The cached keys stay in use until a fetch succeeds
+					k.logger.Warn("failed to refresh JWKS", zap.String("url", k.url), zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Close stops the periodic refresh
+func (k *KeySet) Close(ctx context.Context) error {
+	k.closeOnce.Do(func() { close(k.stop) })
+
+	select {
+	case <-k.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("JWKS refresh did not stop: %w", ctx.Err())
+	}
+}
+
+// Refresh fetches the key set and replaces the cached keys
+func (k *KeySet) Refresh(ctx context.Context) error {
+	k.fetchMu.Lock()
+	defer k.fetchMu.Unlock()
+	return k.fetch(ctx)
+}
+
+func (k *KeySet) fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&doc); err != nil {
+		return fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]publicKey, len(doc.Keys))
+	for _, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			k.logger.Warn("skipping JWKS key", zap.String("kid", jwk.Kid), zap.Error(err))
+			continue
+		}
+		keys[jwk.Kid] = publicKey{alg: jwk.Alg, key: key}
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.fetchedAt = k.clock.Now()
+	k.mu.Unlock()
+	return nil
+}
+
+// Key returns the verification key for a token's kid and alg header. An unknown key ID
+// refetches the key set, at most once per minJWKSRefreshInterval.
+func (k *KeySet) Key(ctx context.Context, kid, alg string) (interface{}, error) {
+	if key, ok := k.lookup(kid, alg); ok {
+		return key, nil
+	}
+
+	k.fetchMu.Lock()
+	defer k.fetchMu.Unlock()
+	// Another request may have fetched the key while this one waited
+	if key, ok := k.lookup(kid, alg); ok {
+		return key, nil
+	}
+	k.mu.RLock()
+	fetchedAt := k.fetchedAt
+	k.mu.RUnlock()
+	if !fetchedAt.IsZero() && k.clock.Now().Sub(fetchedAt) < minJWKSRefreshInterval {
+		return nil, ErrUnknownKey
+	}
+	if err := k.fetch(ctx); err != nil {
+		return nil, err
+	}
+	if key, ok := k.lookup(kid, alg); ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// lookup finds a cached key; a token without kid matches the only key of the set
+func (k *KeySet) lookup(kid, alg string) (interface{}, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok := k.keys[kid]
+	if !ok && kid == "" && len(k.keys) == 1 {
+		for _, only := range k.keys {
+			key, ok = only, true
+		}
+	}
+	if !ok || (key.alg != "" && key.alg != alg) {
+		return nil, false
+	}
+	return key.key, true
+}
+
+// publicKey parses an RSA or EC public key
+func (j jsonWebKey) publicKey() (interface{}, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeBigInt(j.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBigInt(j.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+		}
+		x, err := decodeBigInt(j.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := decodeBigInt(j.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", j.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
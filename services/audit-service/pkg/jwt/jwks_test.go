@@ -0,0 +1,257 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"audit-service/pkg/clock"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const (
+	testIssuer   = "https://project.supabase.co/auth/v1"
+	testAudience = "authenticated"
+)
+
+// fakeJWKS serves the public keys it holds and counts the requests
+type fakeJWKS struct {
+	mu       sync.Mutex
+	keys     []map[string]string
+	requests int
+}
+
+func (f *fakeJWKS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": f.keys})
+}
+
+func (f *fakeJWKS) setKeys(keys ...map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys = keys
+}
+
+func (f *fakeJWKS) requestCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+func encodeBigInt(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kid": kid, "kty": "RSA", "alg": "RS256", "use": "sig",
+		"n": encodeBigInt(key.N), "e": encodeBigInt(big.NewInt(int64(key.E))),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kid": kid, "kty": "EC", "alg": "ES256", "crv": "P-256",
+		"x": encodeBigInt(key.X), "y": encodeBigInt(key.Y),
+	}
+}
+
+func signWithKid(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims *Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func strictClaims(now time.Time) *Claims {
+	return &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   testUserID,
+			Issuer:    testIssuer,
+			Audience:  jwt.ClaimStrings{testAudience},
+			IssuedAt:  jwt.NewNumericDate(now.Add(-time.Minute)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+}
+
+func newTestKeySet(t *testing.T, jwks *fakeJWKS, clk clock.Clock) *KeySet {
+	t.Helper()
+	server := httptest.NewServer(jwks)
+	t.Cleanup(server.Close)
+	return NewKeySet(server.URL, server.Client(), time.Hour, clk, zap.NewNop())
+}
+
+func TestKeySet_RotatesKeys(t *testing.T) {
+	now := time.Now()
+	fakeClock := clock.NewFakeClock(now)
+	oldKey, _, err := generateTestRSAKeys()
+	require.NoError(t, err)
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwks := &fakeJWKS{}
+	jwks.setKeys(rsaJWK("old", &oldKey.PublicKey))
+	keySet := newTestKeySet(t, jwks, fakeClock)
+	require.NoError(t, keySet.Refresh(context.Background()))
+
+	validator, err := NewValidator(Options{KeySet: keySet, Issuer: testIssuer, Audience: testAudience, Clock: fakeClock})
+	require.NoError(t, err)
+
+	claims, err := validator.ValidateToken(context.Background(), signWithKid(t, jwt.SigningMethodRS256, "old", oldKey, strictClaims(now)))
+	require.NoError(t, err)
+	assert.Equal(t, testUserID, claims.UserID)
+	assert.Equal(t, 1, jwks.requestCount())
+
+	// The auth server starts signing with a new key; it is fetched on first use
+	jwks.setKeys(rsaJWK("old", &oldKey.PublicKey), ecJWK("new", &newKey.PublicKey))
+	fakeClock.Advance(time.Minute)
+	_, err = validator.ValidateToken(context.Background(), signWithKid(t, jwt.SigningMethodES256, "new", newKey, strictClaims(now)))
+	require.NoError(t, err)
+	assert.Equal(t, 2, jwks.requestCount())
+
+	// Unknown key IDs do not refetch more than once per interval
+	_, err = validator.ValidateToken(context.Background(), signWithKid(t, jwt.SigningMethodES256, "forged", newKey, strictClaims(now)))
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	assert.Equal(t, 2, jwks.requestCount())
+
+	// Retired keys are dropped with the next fetch
+	jwks.setKeys(ecJWK("new", &newKey.PublicKey))
+	require.NoError(t, keySet.Refresh(context.Background()))
+	_, err = validator.ValidateToken(context.Background(), signWithKid(t, jwt.SigningMethodRS256, "old", oldKey, strictClaims(now)))
+	assert.Error(t, err)
+}
+
+func TestKeySet_KeyAlgorithmMustMatch(t *testing.T) {
+	key, _, err := generateTestRSAKeys()
+	require.NoError(t, err)
+
+	jwks := &fakeJWKS{}
+	jwks.setKeys(rsaJWK("rsa", &key.PublicKey))
+	keySet := newTestKeySet(t, jwks, clock.New())
+
+	_, err = keySet.Key(context.Background(), "rsa", "RS512")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// A token without kid uses the only key
+	found, err := keySet.Key(context.Background(), "", "RS256")
+	require.NoError(t, err)
+	assert.Equal(t, key.PublicKey.N, found.(*rsa.PublicKey).N)
+}
+
+func TestKeySet_Close(t *testing.T) {
+	keySet := newTestKeySet(t, &fakeJWKS{}, clock.New())
+	keySet.Start()
+	require.NoError(t, keySet.Close(context.Background()))
+	require.NoError(t, keySet.Close(context.Background()))
+}
+
+func TestValidator_StrictClaims(t *testing.T) {
+	now := time.Now()
+	validator, err := NewValidator(Options{Secret: testHMACSecret, Issuer: testIssuer, Audience: testAudience})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		modify  func(c *Claims)
+		wantErr string
+	}{
+		{name: "valid", modify: func(c *Claims) {}},
+		{name: "wrong_issuer", modify: func(c *Claims) { c.Issuer = "https://other.supabase.co/auth/v1" }, wantErr: "invalid issuer"},
+		{name: "missing_issuer", modify: func(c *Claims) { c.Issuer = "" }, wantErr: "iss claim is required"},
+		{name: "wrong_audience", modify: func(c *Claims) { c.Audience = jwt.ClaimStrings{"anon"} }, wantErr: "invalid audience"},
+		{name: "missing_expiration", modify: func(c *Claims) { c.ExpiresAt = nil }, wantErr: "token has no expiration"},
+		{name: "expired", modify: func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute)) }, wantErr: "token is expired"},
+		{name: "issued_in_future", modify: func(c *Claims) { c.IssuedAt = jwt.NewNumericDate(now.Add(time.Hour)) }, wantErr: "token used before issued"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := strictClaims(now)
+			tt.modify(claims)
+			token, err := createTestHMACToken(claims, testHMACSecret)
+			require.NoError(t, err)
+
+			got, err := validator.ValidateToken(context.Background(), token)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testUserID, got.UserID)
+		})
+	}
+}
+
+func TestValidator_PreviousSecret(t *testing.T) {
+	now := time.Now()
+	validator, err := NewValidator(Options{Secret: "new-secret", PreviousSecret: "old-secret"})
+	require.NoError(t, err)
+
+	for _, secret := range []string{"new-secret", "old-secret"} {
+		token, err := createTestHMACToken(strictClaims(now), secret)
+		require.NoError(t, err)
+		_, err = validator.ValidateToken(context.Background(), token)
+		assert.NoError(t, err, secret)
+	}
+
+	token, err := createTestHMACToken(strictClaims(now), "unknown-secret")
+	require.NoError(t, err)
+	_, err = validator.ValidateToken(context.Background(), token)
+	assert.Error(t, err)
+}
+
+func TestValidator_RotateSecret(t *testing.T) {
+	now := time.Now()
+	validator, err := NewValidator(Options{Secret: "first-secret", PreviousSecret: "retired-secret"})
+	require.NoError(t, err)
+
+	validator.(SecretRotator).RotateSecret("second-secret")
+
+	// The replaced secret becomes the previous one
+	for secret, valid := range map[string]bool{"second-secret": true, "first-secret": true, "retired-secret": false} {
+		token, err := createTestHMACToken(strictClaims(now), secret)
+		require.NoError(t, err)
+		_, err = validator.ValidateToken(context.Background(), token)
+		assert.Equal(t, valid, err == nil, secret)
+	}
+}
+
+func TestValidator_HMACRejectedWithoutSecret(t *testing.T) {
+	validator, err := NewValidator(Options{KeySet: newTestKeySet(t, &fakeJWKS{}, clock.New())})
+	require.NoError(t, err)
+
+	token, err := createTestHMACToken(strictClaims(time.Now()), testHMACSecret)
+	require.NoError(t, err)
+	_, err = validator.ValidateToken(context.Background(), token)
+	assert.Error(t, err)
+}
+
+func TestNewValidator_RequiresKeys(t *testing.T) {
+	_, err := NewValidator(Options{})
+	assert.Error(t, err)
+}
+
+func TestSupabaseURLs(t *testing.T) {
+	assert.Equal(t, testIssuer, SupabaseIssuer("https://project.supabase.co/"))
+	assert.Equal(t, testIssuer+"/.well-known/jwks.json", SupabaseJWKSURL("https://project.supabase.co"))
+}
@@ -0,0 +1,70 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Issuer and audience set by the share service on share tokens
+const (
+	ShareTokenIssuer   = "ShareService"
+	ShareTokenAudience = "PptxTranslatorApp"
+)
+
+// ShareClaims represents the claims of a share-link token
+type ShareClaims struct {
+	jwt.RegisteredClaims
+	SessionID   string   `json:"sessionId"`
+	Permissions []string `json:"permissions"`
+	// OrganizationID is the organization owning the shared session, empty for the default organization
+	OrganizationID string `json:"organizationId,omitempty"`
+}
+
+// ShareTokenValidator defines the interface for share token validation
+type ShareTokenValidator interface {
+	ValidateShareToken(ctx context.Context, tokenString string) (*ShareClaims, error)
+}
+
+// shareTokenValidator implements the ShareTokenValidator interface
+type shareTokenValidator struct {
+	secret []byte
+}
+
+// NewShareTokenValidator creates a validator for share tokens signed with the share service's HMAC secret
+func NewShareTokenValidator(secret string) ShareTokenValidator {
+	return &shareTokenValidator{secret: []byte(secret)}
+}
+
+// ValidateShareToken verifies the signature, issuer, audience and expiry of a share token
+func (v *shareTokenValidator) ValidateShareToken(ctx context.Context, tokenString string) (*ShareClaims, error) {
+	if len(v.secret) == 0 {
+		return nil, errors.New("share tokens are not enabled")
+	}
+
+	claims := &ShareClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return v.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(ShareTokenIssuer),
+		jwt.WithAudience(ShareTokenAudience),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse share token: %w", err)
+	}
+
+	// Share links must always expire
+	if claims.ExpiresAt == nil {
+		return nil, errors.New("share token has no expiry")
+	}
+
+	if claims.ID == "" || claims.SessionID == "" {
+		return nil, errors.New("share token is missing jti or sessionId")
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,137 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testShareSecret = "test-share-secret-for-testing-purposes"
+
+func createTestShareToken(t *testing.T, mutate func(*ShareClaims), method jwt.SigningMethod, secret string) string {
+	claims := &ShareClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "share-jti-1",
+			Issuer:    ShareTokenIssuer,
+			Audience:  jwt.ClaimStrings{ShareTokenAudience},
+			IssuedAt:  jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		SessionID:   "session-1",
+		Permissions: []string{"VIEW"},
+	}
+	if mutate != nil {
+		mutate(claims)
+	}
+
+	token, err := jwt.NewWithClaims(method, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+func TestShareTokenValidator_ValidateShareToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   func(t *testing.T) string
+		secret  string
+		wantErr bool
+	}{
+		{
+			name: "valid_token",
+			token: func(t *testing.T) string {
+				return createTestShareToken(t, nil, jwt.SigningMethodHS256, testShareSecret)
+			},
+			secret: testShareSecret,
+		},
+		{
+			name: "wrong_secret",
+			token: func(t *testing.T) string {
+				return createTestShareToken(t, nil, jwt.SigningMethodHS256, "another-secret")
+			},
+			secret:  testShareSecret,
+			wantErr: true,
+		},
+		{
+			name: "wrong_algorithm",
+			token: func(t *testing.T) string {
+				return createTestShareToken(t, nil, jwt.SigningMethodHS512, testShareSecret)
+			},
+			secret:  testShareSecret,
+			wantErr: true,
+		},
+		{
+			name: "expired",
+			token: func(t *testing.T) string {
+				return createTestShareToken(t, func(c *ShareClaims) {
+					c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+				}, jwt.SigningMethodHS256, testShareSecret)
+			},
+			secret:  testShareSecret,
+			wantErr: true,
+		},
+		{
+			name: "missing_expiry",
+			token: func(t *testing.T) string {
+				return createTestShareToken(t, func(c *ShareClaims) { c.ExpiresAt = nil }, jwt.SigningMethodHS256, testShareSecret)
+			},
+			secret:  testShareSecret,
+			wantErr: true,
+		},
+		{
+			name: "wrong_audience",
+			token: func(t *testing.T) string {
+				return createTestShareToken(t, func(c *ShareClaims) {
+					c.Audience = jwt.ClaimStrings{"other-app"}
+				}, jwt.SigningMethodHS256, testShareSecret)
+			},
+			secret:  testShareSecret,
+			wantErr: true,
+		},
+		{
+			name: "wrong_issuer",
+			token: func(t *testing.T) string {
+				return createTestShareToken(t, func(c *ShareClaims) { c.Issuer = "someone" }, jwt.SigningMethodHS256, testShareSecret)
+			},
+			secret:  testShareSecret,
+			wantErr: true,
+		},
+		{
+			name: "missing_jti",
+			token: func(t *testing.T) string {
+				return createTestShareToken(t, func(c *ShareClaims) { c.ID = "" }, jwt.SigningMethodHS256, testShareSecret)
+			},
+			secret:  testShareSecret,
+			wantErr: true,
+		},
+		{
+			name: "not_configured",
+			token: func(t *testing.T) string {
+				return createTestShareToken(t, nil, jwt.SigningMethodHS256, "")
+			},
+			secret:  "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewShareTokenValidator(tt.secret)
+
+			claims, err := validator.ValidateShareToken(context.Background(), tt.token(t))
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, claims)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "share-jti-1", claims.ID)
+			assert.Equal(t, "session-1", claims.SessionID)
+			assert.Equal(t, []string{"VIEW"}, claims.Permissions)
+		})
+	}
+}
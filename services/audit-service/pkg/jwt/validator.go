@@ -5,15 +5,46 @@ import (
 	"crypto/rsa"
 	"errors"
 	"fmt"
-	"time"
+	"sync/atomic"
+
+	"audit-service/pkg/clock"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	// RoleAdmin is the app_metadata role that grants access to the admin endpoints
+	RoleAdmin = "admin"
+	// RoleReviewer is the app_metadata role of security reviewers, who may annotate the audit
+	// events of any session
+	RoleReviewer = "reviewer"
+)
+
 // Claims represents the JWT claims we care about
 type Claims struct {
 	jwt.RegisteredClaims
 	UserID string // UserID is populated from Subject claim
+	// SessionID is the login session of a Supabase access token, shared by its refreshed tokens
+	SessionID string `json:"session_id,omitempty"`
+	// AppMetadata can only be changed with the Supabase service role, unlike user_metadata
+	AppMetadata AppMetadata `json:"app_metadata,omitempty"`
+}
+
+// AppMetadata holds the roles and organization of a user in the Supabase app_metadata claim
+type AppMetadata struct {
+	Role  string   `json:"role,omitempty"`
+	Roles []string `json:"roles,omitempty"`
+	// OrganizationID is the tenant the user belongs to; users without one belong to the default organization
+	OrganizationID string `json:"organization_id,omitempty"`
+}
+
+// Roles returns every role granted in app_metadata
+func (c *Claims) Roles() []string {
+	var roles []string
+	if c.AppMetadata.Role != "" {
+		roles = append(roles, c.AppMetadata.Role)
+	}
+	return append(roles, c.AppMetadata.Roles...)
 }
 
 // TokenValidator defines the interface for JWT token validation
@@ -22,102 +53,167 @@ type TokenValidator interface {
 	ExtractUserID(ctx context.Context, tokenString string) (string, error)
 }
 
-// tokenValidator implements the TokenValidator interface
-type tokenValidator struct {
-	verifyKey *rsa.PublicKey
+// Options configures a validator created with NewValidator
+type Options struct {
+	// Secret is the HMAC secret or RSA public key PEM tokens are signed with; optional when
+	// KeySet is set
+	Secret string
+	// PreviousSecret is an HMAC secret still accepted while the secret is rotated
+	PreviousSecret string
+	// KeySet supplies the public keys of asymmetrically signed tokens by their key ID
+	KeySet *KeySet
+	// Issuer and Audience must match the iss and aud claims; empty skips the check
+	Issuer   string
+	Audience string
+	Clock    clock.Clock
 }
 
-// NewTokenValidator creates a new JWT token validator
-func NewTokenValidator(jwtSecret string) (TokenValidator, error) {
-	// Parse the RSA public key from the JWT secret
-	verifyKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(jwtSecret))
-	if err != nil {
-		// If RSA parsing fails, try as HMAC secret for backward compatibility
-		// In production, Supabase uses RS256
-		return &tokenValidator{
-			verifyKey: nil,
-		}, nil
-	}
+// validator verifies tokens against a JWKS, an RSA key or HMAC secrets and checks the
+// registered claims strictly
+type validator struct {
+	keySet   *KeySet
+	keys     atomic.Pointer[secretKeys]
+	issuer   string
+	audience string
+	clock    clock.Clock
+}
 
-	return &tokenValidator{
-		verifyKey: verifyKey,
-	}, nil
+// secretKeys are the keys configured as secrets, replaced as a whole when the secret rotates
+type secretKeys struct {
+	rsaKey      *rsa.PublicKey
+	hmacSecrets [][]byte
 }
 
-// ValidateToken validates a JWT token and returns the claims
-func (v *tokenValidator) ValidateToken(ctx context.Context, tokenString string) (*Claims, error) {
-	// Parse the token
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verify the signing algorithm
-		switch token.Method.(type) {
-		case *jwt.SigningMethodRSA:
-			if v.verifyKey == nil {
-				return nil, errors.New("no RSA key configured")
-			}
-			return v.verifyKey, nil
-		case *jwt.SigningMethodHMAC:
-			// Fallback for local development/testing
-			if v.verifyKey != nil {
-				return nil, errors.New("token signed with HMAC but RSA key configured")
-			}
-			// Return the raw secret for HMAC
-			return []byte(jwtSecret), nil
-		default:
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-	})
+// SecretRotator is implemented by validators whose secret can be replaced while they are in use
+type SecretRotator interface {
+	// RotateSecret replaces the HMAC secret or RSA public key PEM tokens are signed with. A
+	// replaced HMAC secret stays accepted as the previous secret.
+	RotateSecret(secret string)
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse token: %w", err)
+// NewValidator creates a validator that requires an exp claim, rejects tokens issued in the
+// future and checks iss and aud when configured
+func NewValidator(opts Options) (TokenValidator, error) {
+	v := &validator{
+		keySet:   opts.KeySet,
+		issuer:   opts.Issuer,
+		audience: opts.Audience,
+		clock:    opts.Clock,
+	}
+	if v.clock == nil {
+		v.clock = clock.New()
 	}
 
-	// Check if token is valid
-	if !token.Valid {
-		return nil, errors.New("invalid token")
+	keys := &secretKeys{}
+	if opts.Secret != "" {
+		if key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(opts.Secret)); err == nil {
+			keys.rsaKey = key
+		} else {
+			keys.hmacSecrets = append(keys.hmacSecrets, []byte(opts.Secret))
+		}
 	}
+	if opts.PreviousSecret != "" {
+		keys.hmacSecrets = append(keys.hmacSecrets, []byte(opts.PreviousSecret))
+	}
+	if v.keySet == nil && keys.rsaKey == nil && len(keys.hmacSecrets) == 0 {
+		return nil, errors.New("a JWT secret, public key or JWKS is required")
+	}
+	v.keys.Store(keys)
+	return v, nil
+}
 
-	// Extract claims
-	claims, ok := token.Claims.(*Claims)
-	if !ok {
-		return nil, errors.New("invalid token claims")
+// RotateSecret replaces the secret; tokens signed with the replaced HMAC secret stay valid
+// until they expire, as with a configured previous secret
+func (v *validator) RotateSecret(secret string) {
+	current := v.keys.Load()
+	rotated := &secretKeys{rsaKey: current.rsaKey, hmacSecrets: current.hmacSecrets}
+	if key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(secret)); err == nil {
+		rotated.rsaKey = key
+	} else {
+		rotated.hmacSecrets = [][]byte{[]byte(secret)}
+		if len(current.hmacSecrets) > 0 {
+			rotated.hmacSecrets = append(rotated.hmacSecrets, current.hmacSecrets[0])
+		}
 	}
+	v.keys.Store(rotated)
+}
 
-	// Validate expiration
-	if claims.ExpiresAt != nil && claims.ExpiresAt.Time.Before(time.Now()) {
-		return nil, errors.New("token expired")
+// ValidateToken validates a JWT token and returns the claims
+func (v *validator) ValidateToken(ctx context.Context, tokenString string) (*Claims, error) {
+	options := []jwt.ParserOption{
+		jwt.WithTimeFunc(v.clock.Now),
+		jwt.WithIssuedAt(),
+	}
+	if v.issuer != "" {
+		options = append(options, jwt.WithIssuer(v.issuer))
+	}
+	if v.audience != "" {
+		options = append(options, jwt.WithAudience(v.audience))
 	}
 
-	// Validate issued at
-	if claims.IssuedAt != nil && claims.IssuedAt.Time.After(time.Now()) {
-		return nil, errors.New("token used before issued")
+	// Each HMAC secret is tried in turn, so tokens signed before a rotation stay valid
+	keys := v.keys.Load()
+	var claims *Claims
+	var err error
+	for attempt := 0; ; attempt++ {
+		hmac := false
+		claims = &Claims{}
+		_, err = jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			_, hmac = token.Method.(*jwt.SigningMethodHMAC)
+			return v.key(ctx, token, keys, attempt)
+		}, options...)
+		if err == nil || !hmac || !errors.Is(err, jwt.ErrTokenSignatureInvalid) || attempt+1 >= len(keys.hmacSecrets) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
 
-	// Extract user ID from sub claim
+	if claims.ExpiresAt == nil {
+		return nil, errors.New("token has no expiration")
+	}
 	if claims.Subject != "" {
 		claims.UserID = claims.Subject
 	}
-
 	return claims, nil
 }
 
+// IsExpired reports whether a token or share token was rejected for being past its expiry
+func IsExpired(err error) bool {
+	return errors.Is(err, jwt.ErrTokenExpired)
+}
+
+// key selects the verification key for the signing method of a token
+func (v *validator) key(ctx context.Context, token *jwt.Token, keys *secretKeys, attempt int) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		if v.keySet != nil {
+			kid, _ := token.Header["kid"].(string)
+			return v.keySet.Key(ctx, kid, token.Method.Alg())
+		}
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); ok && keys.rsaKey != nil {
+			return keys.rsaKey, nil
+		}
+		return nil, fmt.Errorf("no key configured for %s", token.Method.Alg())
+	case *jwt.SigningMethodHMAC:
+		if len(keys.hmacSecrets) == 0 {
+			return nil, errors.New("token signed with HMAC but no HMAC secret configured")
+		}
+		return keys.hmacSecrets[attempt], nil
+	default:
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+}
+
 // ExtractUserID is a convenience method to get just the user ID
-func (v *tokenValidator) ExtractUserID(ctx context.Context, tokenString string) (string, error) {
+func (v *validator) ExtractUserID(ctx context.Context, tokenString string) (string, error) {
 	claims, err := v.ValidateToken(ctx, tokenString)
 	if err != nil {
 		return "", err
 	}
-
 	if claims.UserID == "" {
 		return "", errors.New("no user ID in token")
 	}
-
 	return claims.UserID, nil
 }
-
-// For HMAC fallback, we need to store the secret
-var jwtSecret string
-
-// SetHMACSecret sets the HMAC secret for fallback authentication
-func SetHMACSecret(secret string) {
-	jwtSecret = secret
-}
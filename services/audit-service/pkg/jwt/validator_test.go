@@ -11,6 +11,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Test constants
@@ -57,52 +58,6 @@ func getPublicKeyPEM(publicKey *rsa.PublicKey) (string, error) {
 	return string(pubPEM), nil
 }
 
-func TestNewTokenValidator(t *testing.T) {
-	// Generate a valid RSA key for testing
-	_, publicKey, err := generateTestRSAKeys()
-	assert.NoError(t, err)
-
-	publicKeyPEM, err := getPublicKeyPEM(publicKey)
-	assert.NoError(t, err)
-
-	tests := []struct {
-		name        string
-		jwtSecret   string
-		expectError bool
-	}{
-		{
-			name:        "valid_rsa_public_key",
-			jwtSecret:   publicKeyPEM,
-			expectError: false,
-		},
-		{
-			name:        "invalid_rsa_key",
-			jwtSecret:   "invalid-key-data",
-			expectError: false, // Should fallback to HMAC
-		},
-		{
-			name:        "hmac_secret",
-			jwtSecret:   testHMACSecret,
-			expectError: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			validator, err := NewTokenValidator(tt.jwtSecret)
-
-			if tt.expectError {
-				assert.Error(t, err)
-				assert.Nil(t, validator)
-			} else {
-				assert.NoError(t, err)
-				assert.NotNil(t, validator)
-				assert.Implements(t, (*TokenValidator)(nil), validator)
-			}
-		})
-	}
-}
-
 func TestTokenValidator_ValidateToken(t *testing.T) {
 	// Generate test keys
 	privateKey, publicKey, err := generateTestRSAKeys()
@@ -112,11 +67,10 @@ func TestTokenValidator_ValidateToken(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Create validators
-	rsaValidator, err := NewTokenValidator(publicKeyPEM)
+	rsaValidator, err := NewValidator(Options{Secret: publicKeyPEM})
 	assert.NoError(t, err)
 
-	SetHMACSecret(testHMACSecret)
-	hmacValidator, err := NewTokenValidator("invalid-rsa-key")
+	hmacValidator, err := NewValidator(Options{Secret: testHMACSecret})
 	assert.NoError(t, err)
 
 	tests := []struct {
@@ -261,6 +215,7 @@ func TestTokenValidator_ValidateToken(t *testing.T) {
 			if tt.expectedError != "" {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Equal(t, tt.name == "expired_token", IsExpired(err))
 				assert.Nil(t, claims)
 			} else {
 				assert.NoError(t, err)
@@ -285,7 +240,7 @@ func TestTokenValidator_ExtractUserID(t *testing.T) {
 	publicKeyPEM, err := getPublicKeyPEM(publicKey)
 	assert.NoError(t, err)
 
-	validator, err := NewTokenValidator(publicKeyPEM)
+	validator, err := NewValidator(Options{Secret: publicKeyPEM})
 	assert.NoError(t, err)
 
 	tests := []struct {
@@ -373,15 +328,6 @@ func TestTokenValidator_ExtractUserID(t *testing.T) {
 	}
 }
 
-func TestSetHMACSecret(t *testing.T) {
-	testSecret := "new-test-secret"
-
-	SetHMACSecret(testSecret)
-
-	// Verify the secret was set by checking it's used in validation
-	assert.Equal(t, testSecret, jwtSecret)
-}
-
 func TestClaims(t *testing.T) {
 	claims := &Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
@@ -396,3 +342,24 @@ func TestClaims(t *testing.T) {
 	assert.Equal(t, testUserID, claims.Subject)
 	assert.Equal(t, "test-issuer", claims.Issuer)
 }
+
+func TestTokenValidator_AppMetadataRoles(t *testing.T) {
+	validator, err := NewValidator(Options{Secret: testHMACSecret})
+	require.NoError(t, err)
+
+	token, err := createTestHMACToken(&Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   testUserID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		AppMetadata: AppMetadata{Role: RoleAdmin, Roles: []string{"support"}},
+	}, testHMACSecret)
+	require.NoError(t, err)
+
+	claims, err := validator.ValidateToken(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, []string{RoleAdmin, "support"}, claims.Roles())
+
+	// Tokens without app_metadata grant no roles
+	assert.Empty(t, (&Claims{}).Roles())
+}
@@ -5,17 +5,27 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-// New creates a new Zap logger instance
-func New(level string) (*zap.Logger, error) {
-	// Parse log level
+// New creates a new Zap logger instance. Secrets, Authorization headers and share tokens are
+// masked in all output, as are the given configured secrets.
+func New(level string, secrets ...string) (*zap.Logger, error) {
+	return NewWithLevel(NewLevel(level), secrets...)
+}
+
+// NewLevel parses a log level that can be changed while the logger runs; unknown levels
+// fall back to info
+func NewLevel(level string) zap.AtomicLevel {
 	zapLevel, err := zapcore.ParseLevel(level)
 	if err != nil {
 		zapLevel = zapcore.InfoLevel
 	}
+	return zap.NewAtomicLevelAt(zapLevel)
+}
 
+// NewWithLevel creates a logger like New whose level follows the given atomic level
+func NewWithLevel(level zap.AtomicLevel, secrets ...string) (*zap.Logger, error) {
 	// Create config
 	config := zap.Config{
-		Level:       zap.NewAtomicLevelAt(zapLevel),
+		Level:       level,
 		Development: false,
 		Encoding:    "json",
 		EncoderConfig: zapcore.EncoderConfig{
@@ -37,7 +47,7 @@ func New(level string) (*zap.Logger, error) {
 	}
 
 	// Build logger
-	logger, err := config.Build()
+	logger, err := config.Build(withSanitizer(secrets))
 	if err != nil {
 		return nil, err
 	}
@@ -46,8 +56,15 @@ func New(level string) (*zap.Logger, error) {
 }
 
 // NewDevelopment creates a development logger with console output
-func NewDevelopment() (*zap.Logger, error) {
+func NewDevelopment(secrets ...string) (*zap.Logger, error) {
 	config := zap.NewDevelopmentConfig()
 	config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
-	return config.Build()
+	return config.Build(withSanitizer(secrets))
+}
+
+// withSanitizer wraps the core of a logger in a sanitizing core
+func withSanitizer(secrets []string) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return NewSanitizingCore(core, secrets...)
+	})
 }
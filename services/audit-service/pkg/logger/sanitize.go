@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted replaces sensitive values in log output
+const Redacted = "[REDACTED]"
+
+// minSecretLength is the shortest configured secret masked verbatim; shorter values would
+// garble unrelated log text
+const minSecretLength = 8
+
+// sensitiveKeyParts mark field keys whose string values are always masked
+var sensitiveKeyParts = []string{"authorization", "password", "secret", "token", "api_key", "apikey", "cookie", "credential"}
+
+var (
+	// bearerPattern matches credentials in Authorization header values
+	bearerPattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`)
+	// jwtPattern matches compact JWTs, such as share tokens, wherever they appear
+	jwtPattern = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	// queryTokenPattern matches credentials passed as query parameters, e.g. share_token=...
+	queryTokenPattern = regexp.MustCompile(`(?i)\b((?:share_token|access_token|token|api_key|apikey|secret|password)=)[^&\s"]+`)
+)
+
+// sanitizer masks secrets, Authorization headers and share tokens in log entries
+type sanitizer struct {
+	secrets []string
+}
+
+// newSanitizer creates a sanitizer that also masks the given configured secrets verbatim
+func newSanitizer(secrets []string) *sanitizer {
+	s := &sanitizer{}
+	for _, secret := range secrets {
+		if len(secret) >= minSecretLength {
+			s.secrets = append(s.secrets, secret)
+		}
+	}
+	return s
+}
+
+// maskString removes credentials from free text
+func (s *sanitizer) maskString(value string) string {
+	for _, secret := range s.secrets {
+		value = strings.ReplaceAll(value, secret, Redacted)
+	}
+	value = bearerPattern.ReplaceAllString(value, "$1 "+Redacted)
+	value = jwtPattern.ReplaceAllLiteralString(value, Redacted)
+	return queryTokenPattern.ReplaceAllString(value, "${1}"+Redacted)
+}
+
+// fields returns a copy of fields with sensitive values masked
+func (s *sanitizer) fields(fields []zapcore.Field) []zapcore.Field {
+	sanitized := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		sanitized[i] = s.field(field)
+	}
+	return sanitized
+}
+
+// field masks one field. Values of sensitive keys are replaced entirely; other strings,
+// errors and stringers have credentials removed. Numbers, bools and durations pass through.
+func (s *sanitizer) field(field zapcore.Field) zapcore.Field {
+	switch field.Type {
+	case zapcore.StringType:
+		if isSensitiveKey(field.Key) && field.String != "" {
+			field.String = Redacted
+		} else {
+			field.String = s.maskString(field.String)
+		}
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok && err != nil {
+			return zapcore.Field{Key: field.Key, Type: zapcore.StringType, String: s.maskString(err.Error())}
+		}
+	case zapcore.StringerType:
+		if stringer, ok := field.Interface.(fmt.Stringer); ok && stringer != nil {
+			return zapcore.Field{Key: field.Key, Type: zapcore.StringType, String: s.maskString(stringer.String())}
+		}
+	case zapcore.ByteStringType:
+		return zapcore.Field{Key: field.Key, Type: zapcore.StringType, String: s.maskString(string(field.Interface.([]byte)))}
+	}
+	return field
+}
+
+// isSensitiveKey reports whether a field key names a credential
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizingCore masks sensitive values in every entry before the wrapped core encodes it
+type sanitizingCore struct {
+	zapcore.Core
+	sanitizer *sanitizer
+}
+
+// NewSanitizingCore wraps core so that secrets, Authorization headers and share tokens never
+// reach the log output. Configured secrets are masked wherever they appear in messages or fields.
+func NewSanitizingCore(core zapcore.Core, secrets ...string) zapcore.Core {
+	return &sanitizingCore{Core: core, sanitizer: newSanitizer(secrets)}
+}
+
+// With adds sanitized fields to the wrapped core
+func (c *sanitizingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &sanitizingCore{Core: c.Core.With(c.sanitizer.fields(fields)), sanitizer: c.sanitizer}
+}
+
+// Check adds the sanitizing core to entries the wrapped core would log
+func (c *sanitizingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write masks the message and fields and writes them to the wrapped core
+func (c *sanitizingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = c.sanitizer.maskString(entry.Message)
+	return c.Core.Write(entry, c.sanitizer.fields(fields))
+}
@@ -0,0 +1,83 @@
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// ServerConfig builds the TLS configuration of a listener that requires client certificates
+// signed by the CA bundle in caFile. When allowedSANs is not empty, the client certificate must
+// also carry one of them as a DNS name, URI (such as a SPIFFE ID) or IP address.
+func ServerConfig(certFile, keyFile, caFile string, allowedSANs []string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA bundle: %w", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("client CA bundle %s holds no PEM certificates", caFile)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	}
+	if len(allowedSANs) > 0 {
+		// Runs after the chain has been verified against ClientCAs
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return errors.New("client certificate required")
+			}
+			if !Allowed(state.PeerCertificates[0], allowedSANs) {
+				return fmt.Errorf("client certificate %q carries no allowed subject alternative name", PeerIdentity(state.PeerCertificates[0]))
+			}
+			return nil
+		}
+	}
+	return config, nil
+}
+
+// Allowed reports whether the certificate carries one of the subject alternative names
+func Allowed(cert *x509.Certificate, allowedSANs []string) bool {
+	for _, san := range SANs(cert) {
+		if slices.Contains(allowedSANs, san) {
+			return true
+		}
+	}
+	return false
+}
+
+// SANs returns the URI, DNS and IP subject alternative names of the certificate
+func SANs(cert *x509.Certificate) []string {
+	sans := make([]string, 0, len(cert.URIs)+len(cert.DNSNames)+len(cert.IPAddresses))
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	sans = append(sans, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return sans
+}
+
+// PeerIdentity names the client of a certificate: its first URI or DNS name, or its common name
+func PeerIdentity(cert *x509.Certificate) string {
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String()
+	}
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0]
+	}
+	return cert.Subject.CommonName
+}
@@ -0,0 +1,171 @@
+package mtls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// authority signs test certificates
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newAuthority(t *testing.T, name string) *authority {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &authority{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue signs a certificate for the SANs and returns it with its key, PEM encoded
+func (a *authority) issue(t *testing.T, usage x509.ExtKeyUsage, dnsNames []string, uris []string) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     dnsNames,
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	for _, raw := range uris {
+		uri, err := url.Parse(raw)
+		require.NoError(t, err)
+		template.URIs = append(template.URIs, uri)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, a.cert, &key.PublicKey, a.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestServerConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca := newAuthority(t, "cluster CA")
+	serverCert, serverKey := ca.issue(t, x509.ExtKeyUsageServerAuth, []string{"audit-service"}, nil)
+	certFile := writeFile(t, dir, "tls.crt", serverCert)
+	keyFile := writeFile(t, dir, "tls.key", serverKey)
+	caFile := writeFile(t, dir, "ca.crt", ca.pem)
+
+	config, err := ServerConfig(certFile, keyFile, caFile, []string{"spiffe://cluster.local/ns/pptx/sa/translator"})
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(PeerIdentity(r.TLS.PeerCertificates[0])))
+	}))
+	server.TLS = config
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(ca.cert)
+	request := func(clientCert, clientKey []byte) (*http.Response, error) {
+		tlsConfig := &tls.Config{RootCAs: rootCAs, ServerName: "audit-service"}
+		if clientCert != nil {
+			pair, err := tls.X509KeyPair(clientCert, clientKey)
+			require.NoError(t, err)
+			tlsConfig.Certificates = []tls.Certificate{pair}
+		}
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+		defer client.CloseIdleConnections()
+		return client.Get(server.URL)
+	}
+
+	t.Run("allowed SAN", func(t *testing.T) {
+		resp, err := request(ca.issue(t, x509.ExtKeyUsageClientAuth, []string{"translator"}, []string{"spiffe://cluster.local/ns/pptx/sa/translator"}))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("SAN not allowed", func(t *testing.T) {
+		_, err := request(ca.issue(t, x509.ExtKeyUsageClientAuth, []string{"frontend"}, []string{"spiffe://cluster.local/ns/pptx/sa/frontend"}))
+		assert.Error(t, err)
+	})
+
+	t.Run("other CA", func(t *testing.T) {
+		other := newAuthority(t, "other CA")
+		_, err := request(other.issue(t, x509.ExtKeyUsageClientAuth, nil, []string{"spiffe://cluster.local/ns/pptx/sa/translator"}))
+		assert.Error(t, err)
+	})
+
+	t.Run("no client certificate", func(t *testing.T) {
+		_, err := request(nil, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestServerConfig_InvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	ca := newAuthority(t, "cluster CA")
+	serverCert, serverKey := ca.issue(t, x509.ExtKeyUsageServerAuth, []string{"audit-service"}, nil)
+	certFile := writeFile(t, dir, "tls.crt", serverCert)
+	keyFile := writeFile(t, dir, "tls.key", serverKey)
+
+	_, err := ServerConfig(certFile, keyFile, filepath.Join(dir, "missing.crt"), nil)
+	assert.ErrorContains(t, err, "client CA bundle")
+
+	_, err = ServerConfig(certFile, keyFile, writeFile(t, dir, "empty.crt", []byte("not a certificate")), nil)
+	assert.ErrorContains(t, err, "no PEM certificates")
+
+	_, err = ServerConfig(certFile, certFile, writeFile(t, dir, "ca.crt", ca.pem), nil)
+	assert.ErrorContains(t, err, "server certificate")
+}
+
+func TestSANs(t *testing.T) {
+	ca := newAuthority(t, "cluster CA")
+	certPEM, _ := ca.issue(t, x509.ExtKeyUsageClientAuth, []string{"translator.pptx.svc"}, []string{"spiffe://cluster.local/ns/pptx/sa/translator"})
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"spiffe://cluster.local/ns/pptx/sa/translator", "translator.pptx.svc", "127.0.0.1"}, SANs(cert))
+	assert.Equal(t, "spiffe://cluster.local/ns/pptx/sa/translator", PeerIdentity(cert))
+	assert.True(t, Allowed(cert, []string{"translator.pptx.svc"}))
+	assert.False(t, Allowed(cert, []string{"frontend.pptx.svc"}))
+}
@@ -0,0 +1,50 @@
+package requestid
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// Header carries the request ID between the frontend, this service and Supabase
+const Header = "X-Request-ID"
+
+// MaxLength is the longest request ID accepted from a caller
+const MaxLength = 128
+
+// contextKey is the private type of the context key, so other packages cannot collide with it
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the request ID
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID carried by ctx, or an empty string
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Field returns the request_id log field for ctx; it is omitted when ctx carries no request ID
+func Field(ctx context.Context) zap.Field {
+	id := FromContext(ctx)
+	if id == "" {
+		return zap.Skip()
+	}
+	return zap.String("request_id", id)
+}
+
+// Valid reports whether a caller-supplied request ID is safe to log and echo back:
+// non-empty, at most MaxLength characters and printable ASCII without spaces
+func Valid(id string) bool {
+	if id == "" || len(id) > MaxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
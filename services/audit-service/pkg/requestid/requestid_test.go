@@ -0,0 +1,44 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestContext(t *testing.T) {
+	ctx := NewContext(context.Background(), "req-1")
+	assert.Equal(t, "req-1", FromContext(ctx))
+	assert.Empty(t, FromContext(context.Background()))
+}
+
+func TestField(t *testing.T) {
+	assert.Equal(t, zap.String("request_id", "req-1"), Field(NewContext(context.Background(), "req-1")))
+	assert.Equal(t, zapcore.SkipType, Field(context.Background()).Type)
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{name: "uuid", id: "3f2a8c1e-6b7d-4e5f-9a0b-1c2d3e4f5a6b", want: true},
+		{name: "opaque token", id: "web:01HZX3.abc_def", want: true},
+		{name: "empty", id: "", want: false},
+		{name: "too long", id: strings.Repeat("a", MaxLength+1), want: false},
+		{name: "newline", id: "req-1\nlevel=error", want: false},
+		{name: "space", id: "req 1", want: false},
+		{name: "non-ascii", id: "req-ü", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Valid(tt.id))
+		})
+	}
+}
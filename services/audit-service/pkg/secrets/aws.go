@@ -0,0 +1,97 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"audit-service/pkg/clock"
+	"audit-service/pkg/sigv4"
+)
+
+// AWSCredentials sign the requests to AWS Secrets Manager; SessionToken is set for temporary credentials
+type AWSCredentials struct {
+	sigv4.Credentials
+	SessionToken string
+}
+
+// AWS reads a secret of AWS Secrets Manager whose SecretString is a JSON object of strings
+type AWS struct {
+	endpoint string
+	region   string
+	secretID string
+	creds    AWSCredentials
+	client   *http.Client
+	clock    clock.Clock
+}
+
+// NewAWS creates a provider reading the secret with the given name or ARN. An empty endpoint
+// selects the public endpoint of the region.
+func NewAWS(endpoint, region, secretID string, creds AWSCredentials, client *http.Client, clk clock.Clock) *AWS {
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", region)
+	}
+	return &AWS{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		region:   region,
+		secretID: secretID,
+		creds:    creds,
+		client:   client,
+		clock:    clk,
+	}
+}
+
+// awsError is the body of a failed Secrets Manager request
+type awsError struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+// Fetch reads the key-value pairs of the secret
+func (a *AWS) Fetch(ctx context.Context) (map[string]string, error) {
+	payload, err := json.Marshal(map[string]string{"SecretId": a.secretID})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint+"/", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	if a.creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.creds.SessionToken)
+	}
+	sigv4.Sign(req, payload, a.creds.Credentials, a.region, "secretsmanager", a.clock.Now())
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AWS secret %s: %w", a.secretID, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AWS secret %s: %w", a.secretID, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var failure awsError
+		_ = json.Unmarshal(body, &failure)
+		return nil, fmt.Errorf("secrets manager returned %d for %s: %s %s", resp.StatusCode, a.secretID, failure.Type, failure.Message)
+	}
+
+	var secret struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("invalid Secrets Manager response for %s: %w", a.secretID, err)
+	}
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(secret.SecretString), &data); err != nil {
+		return nil, fmt.Errorf("AWS secret %s is not a JSON object: %w", a.secretID, err)
+	}
+	return stringValues(data)
+}
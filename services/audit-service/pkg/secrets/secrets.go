@@ -0,0 +1,119 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// fetchTimeout bounds each read of the secret
+const fetchTimeout = 10 * time.Second
+
+// Provider reads a secret holding key-value pairs from a secrets manager
+type Provider interface {
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// Fetch reads the secret once, within fetchTimeout
+func Fetch(ctx context.Context, provider Provider) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+	return provider.Fetch(ctx)
+}
+
+// Refresher reads the secret again on an interval and passes it to apply whenever it changed,
+// so rotated credentials are picked up without a restart
+type Refresher struct {
+	provider Provider
+	interval time.Duration
+	apply    func(values map[string]string)
+	logger   *zap.Logger
+
+	// last is the secret seen by the previous read; only accessed by Refresh, which runs one at a time
+	mu   sync.Mutex
+	last map[string]string
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// NewRefresher creates a refresher of the secret last read as current; call Start to begin refreshing
+func NewRefresher(provider Provider, current map[string]string, interval time.Duration, apply func(values map[string]string), logger *zap.Logger) *Refresher {
+	return &Refresher{
+		provider: provider,
+		interval: interval,
+		apply:    apply,
+		logger:   logger,
+		last:     current,
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// Start reads the secret once per interval
+func (r *Refresher) Start() {
+	go r.run()
+}
+
+// Close stops refreshing
+func (r *Refresher) Close(ctx context.Context) error {
+	r.closeOnce.Do(func() { close(r.stop) })
+
+	select {
+	case <-r.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("secrets refresher did not stop: %w", ctx.Err())
+	}
+}
+
+// run refreshes on the interval until Close is called
+func (r *Refresher) run() {
+	defer close(r.stopped)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			// The credentials in use stay valid until the next successful read
+			if _, err := r.Refresh(context.Background()); err != nil {
+				r.logger.Error("failed to refresh secrets", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Refresh reads the secret and applies it when it changed, reporting whether it did
+func (r *Refresher) Refresh(ctx context.Context) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	values, err := Fetch(ctx, r.provider)
+	if err != nil {
+		return false, err
+	}
+	if maps.Equal(values, r.last) {
+		return false, nil
+	}
+
+	changed := make([]string, 0, len(values))
+	for key, value := range values {
+		if r.last[key] != value {
+			changed = append(changed, key)
+		}
+	}
+	r.last = values
+	r.apply(values)
+	// Only the names of the rotated keys are logged
+	r.logger.Info("secrets rotated", zap.Strings("keys", changed))
+	return true, nil
+}
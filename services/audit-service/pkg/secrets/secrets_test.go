@@ -0,0 +1,138 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"audit-service/pkg/clock"
+	"audit-service/pkg/sigv4"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestVault_FetchKVv2(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/secret/data/audit-service", r.URL.Path)
+		assert.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+		_, _ = w.Write([]byte(`{"data":{"data":{"SUPABASE_JWT_SECRET":"jwt-secret"},"metadata":{"version":3}}}`))
+	}))
+	defer server.Close()
+
+	values, err := NewVault(server.URL+"/", "vault-token", "/secret/data/audit-service", server.Client()).Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"SUPABASE_JWT_SECRET": "jwt-secret"}, values)
+}
+
+func TestVault_FetchKVv1(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"SUPABASE_SERVICE_ROLE_KEY":"service-role-key","data":"plain"}}`))
+	}))
+	defer server.Close()
+
+	values, err := NewVault(server.URL, "vault-token", "kv/audit-service", server.Client()).Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"SUPABASE_SERVICE_ROLE_KEY": "service-role-key", "data": "plain"}, values)
+}
+
+func TestVault_FetchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+	}))
+	defer server.Close()
+
+	_, err := NewVault(server.URL, "vault-token", "secret/data/audit-service", server.Client()).Fetch(context.Background())
+	assert.ErrorContains(t, err, "403")
+	assert.ErrorContains(t, err, "permission denied")
+}
+
+func TestAWS_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.Equal(t, "session-token", r.Header.Get("X-Amz-Security-Token"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240301/eu-west-1/secretsmanager/aws4_request"))
+		assert.Contains(t, r.Header.Get("Authorization"), "x-amz-security-token")
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"SecretId":"audit-service/supabase"}`, string(body))
+
+		secret, _ := json.Marshal(map[string]string{"SUPABASE_JWT_SECRET": "jwt-secret", "SUPABASE_SERVICE_ROLE_KEY": "service-role-key"})
+		_ = json.NewEncoder(w).Encode(map[string]string{"Name": "audit-service/supabase", "SecretString": string(secret)})
+	}))
+	defer server.Close()
+
+	clk := clock.NewFakeClock(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC))
+	creds := AWSCredentials{Credentials: sigv4.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}, SessionToken: "session-token"}
+	values, err := NewAWS(server.URL, "eu-west-1", "audit-service/supabase", creds, server.Client(), clk).Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"SUPABASE_JWT_SECRET": "jwt-secret", "SUPABASE_SERVICE_ROLE_KEY": "service-role-key"}, values)
+}
+
+func TestAWS_FetchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`))
+	}))
+	defer server.Close()
+
+	creds := AWSCredentials{Credentials: sigv4.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}}
+	_, err := NewAWS(server.URL, "eu-west-1", "missing", creds, server.Client(), clock.New()).Fetch(context.Background())
+	assert.ErrorContains(t, err, "ResourceNotFoundException")
+}
+
+func TestNewAWS_DefaultEndpoint(t *testing.T) {
+	provider := NewAWS("", "eu-west-1", "audit-service/supabase", AWSCredentials{}, http.DefaultClient, clock.New())
+	assert.Equal(t, "https://secretsmanager.eu-west-1.amazonaws.com", provider.endpoint)
+}
+
+// fakeProvider returns the values or error it is set to
+type fakeProvider struct {
+	values map[string]string
+	err    error
+}
+
+func (f *fakeProvider) Fetch(context.Context) (map[string]string, error) {
+	return f.values, f.err
+}
+
+func TestRefresher_Refresh(t *testing.T) {
+	ctx := context.Background()
+	provider := &fakeProvider{values: map[string]string{"SUPABASE_JWT_SECRET": "first"}}
+	var applied []map[string]string
+	refresher := NewRefresher(provider, provider.values, time.Minute, func(values map[string]string) {
+		applied = append(applied, values)
+	}, zap.NewNop())
+
+	changed, err := refresher.Refresh(ctx)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	provider.values = map[string]string{"SUPABASE_JWT_SECRET": "second"}
+	changed, err = refresher.Refresh(ctx)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	// A failed read keeps the credentials in use
+	provider.err = errors.New("vault sealed")
+	_, err = refresher.Refresh(ctx)
+	assert.Error(t, err)
+
+	assert.Equal(t, []map[string]string{{"SUPABASE_JWT_SECRET": "second"}}, applied)
+}
+
+func TestRefresher_Close(t *testing.T) {
+	refresher := NewRefresher(&fakeProvider{}, nil, time.Hour, func(map[string]string) {}, zap.NewNop())
+	refresher.Start()
+	require.NoError(t, refresher.Close(context.Background()))
+	// Closing twice is safe
+	require.NoError(t, refresher.Close(context.Background()))
+}
@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Vault reads a secret of the KV secrets engine of HashiCorp Vault, version 1 or 2
+type Vault struct {
+	addr   string
+	token  string
+	path   string
+	client *http.Client
+}
+
+// NewVault creates a provider reading the secret at path, such as secret/data/audit-service for
+// version 2 of the KV engine, authenticating with token
+func NewVault(addr, token, path string, client *http.Client) *Vault {
+	return &Vault{
+		addr:   strings.TrimRight(addr, "/"),
+		token:  token,
+		path:   strings.Trim(path, "/"),
+		client: client,
+	}
+}
+
+// vaultResponse is the response of a KV read; version 2 nests the pairs in data.data
+type vaultResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []string                   `json:"errors"`
+}
+
+// Fetch reads the key-value pairs of the secret
+func (v *Vault) Fetch(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.addr+"/v1/"+v.path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Vault secret %s: %w", v.path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Vault secret %s: %w", v.path, err)
+	}
+
+	var decoded vaultResponse
+	if err := json.Unmarshal(body, &decoded); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("invalid Vault response for %s: %w", v.path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned %d for %s: %s", resp.StatusCode, v.path, strings.Join(decoded.Errors, "; "))
+	}
+
+	data := decoded.Data
+	if nested, ok := data["data"]; ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = nil
+			if err := json.Unmarshal(nested, &data); err != nil {
+				return nil, fmt.Errorf("invalid Vault secret %s: %w", v.path, err)
+			}
+		}
+	}
+	return stringValues(data)
+}
+
+// stringValues decodes the values of a secret, which must be strings
+func stringValues(data map[string]json.RawMessage) (map[string]string, error) {
+	values := make(map[string]string, len(data))
+	for key, raw := range data {
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("value of %s is not a string", key)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,39 @@
+package tenant
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// contextKey is the private type of the context key, so other packages cannot collide with it
+type contextKey struct{}
+
+// NewContext returns a copy of ctx scoped to an organization. The empty ID is the default
+// organization of users whose token names none, whose entries have no organization stored.
+func NewContext(ctx context.Context, organizationID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, organizationID)
+}
+
+// FromContext returns the organization ctx is scoped to. ok is false for unscoped contexts,
+// such as those of service callers and background workers, which may access every organization.
+func FromContext(ctx context.Context) (organizationID string, ok bool) {
+	organizationID, ok = ctx.Value(contextKey{}).(string)
+	return organizationID, ok
+}
+
+// Allows reports whether ctx may access data of the organization
+func Allows(ctx context.Context, organizationID string) bool {
+	scope, ok := FromContext(ctx)
+	return !ok || scope == organizationID
+}
+
+// Field returns the organization_id log field for ctx; it is omitted when ctx is unscoped
+// or scoped to the default organization
+func Field(ctx context.Context) zap.Field {
+	organizationID, _ := FromContext(ctx)
+	if organizationID == "" {
+		return zap.Skip()
+	}
+	return zap.String("organization_id", organizationID)
+}
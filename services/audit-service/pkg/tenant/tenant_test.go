@@ -0,0 +1,45 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestContext(t *testing.T) {
+	organizationID, ok := FromContext(NewContext(context.Background(), "org-1"))
+	assert.True(t, ok)
+	assert.Equal(t, "org-1", organizationID)
+
+	// The default organization is a scope of its own
+	organizationID, ok = FromContext(NewContext(context.Background(), ""))
+	assert.True(t, ok)
+	assert.Empty(t, organizationID)
+
+	_, ok = FromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestAllows(t *testing.T) {
+	scoped := NewContext(context.Background(), "org-1")
+	assert.True(t, Allows(scoped, "org-1"))
+	assert.False(t, Allows(scoped, "org-2"))
+	assert.False(t, Allows(scoped, ""))
+
+	defaultOrg := NewContext(context.Background(), "")
+	assert.True(t, Allows(defaultOrg, ""))
+	assert.False(t, Allows(defaultOrg, "org-1"))
+
+	// Unscoped contexts access every organization
+	assert.True(t, Allows(context.Background(), "org-1"))
+	assert.True(t, Allows(context.Background(), ""))
+}
+
+func TestField(t *testing.T) {
+	assert.Equal(t, zap.String("organization_id", "org-1"), Field(NewContext(context.Background(), "org-1")))
+	assert.Equal(t, zapcore.SkipType, Field(NewContext(context.Background(), "")).Type)
+	assert.Equal(t, zapcore.SkipType, Field(context.Background()).Type)
+}
@@ -0,0 +1,82 @@
+package workpool
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Stats describes the load on a pool
+type Stats struct {
+	// Size is the number of operations allowed to run at once; zero is unlimited
+	Size int
+	// Running is the number of operations holding a slot
+	Running int
+	// Queued is the number of operations waiting for a slot
+	Queued int
+}
+
+// Pool bounds how many expensive operations run at once. Operations beyond the bound wait in
+// line for a slot, so a few large ones cannot take every storage connection and goroutine the
+// rest of the service needs.
+type Pool struct {
+	// slots holds a token per running operation; nil when unlimited
+	slots   chan struct{}
+	running atomic.Int64
+	queued  atomic.Int64
+}
+
+// New creates a pool running up to size operations at once. A size that is not positive
+// leaves the pool unlimited; it still counts the running operations.
+func New(size int) *Pool {
+	p := &Pool{}
+	if size > 0 {
+		p.slots = make(chan struct{}, size)
+	}
+	return p
+}
+
+// Acquire waits for a free slot, or until ctx is done. The returned func releases the slot
+// and must be called exactly once.
+func (p *Pool) Acquire(ctx context.Context) (func(), error) {
+	if p.slots != nil {
+		select {
+		case p.slots <- struct{}{}:
+		default:
+			p.queued.Add(1)
+			select {
+			case p.slots <- struct{}{}:
+				p.queued.Add(-1)
+			case <-ctx.Done():
+				p.queued.Add(-1)
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	p.running.Add(1)
+	return func() {
+		p.running.Add(-1)
+		if p.slots != nil {
+			<-p.slots
+		}
+	}, nil
+}
+
+// Run calls fn once a slot is free and releases the slot when it returns
+func (p *Pool) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	release, err := p.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return fn(ctx)
+}
+
+// Stats returns the current load on the pool
+func (p *Pool) Stats() Stats {
+	return Stats{
+		Size:    cap(p.slots),
+		Running: int(p.running.Load()),
+		Queued:  int(p.queued.Load()),
+	}
+}
@@ -0,0 +1,60 @@
+package workpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_QueuesBeyondSize(t *testing.T) {
+	pool := New(1)
+	ctx := context.Background()
+
+	release, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Size: 1, Running: 1}, pool.Stats())
+
+	acquired := make(chan func())
+	go func() {
+		next, err := pool.Acquire(ctx)
+		assert.NoError(t, err)
+		acquired <- next
+	}()
+	require.Eventually(t, func() bool { return pool.Stats().Queued == 1 }, time.Second, time.Millisecond)
+
+	release()
+	next := <-acquired
+	assert.Equal(t, Stats{Size: 1, Running: 1}, pool.Stats())
+	next()
+	assert.Equal(t, Stats{Size: 1}, pool.Stats())
+}
+
+func TestPool_AcquireGivesUpWithContext(t *testing.T) {
+	pool := New(1)
+	release, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = pool.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, Stats{Size: 1, Running: 1}, pool.Stats())
+}
+
+func TestPool_Unlimited(t *testing.T) {
+	pool := New(0)
+
+	err := pool.Run(context.Background(), func(context.Context) error {
+		err := pool.Run(context.Background(), func(context.Context) error {
+			assert.Equal(t, Stats{Running: 2}, pool.Stats())
+			return nil
+		})
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Stats{}, pool.Stats())
+}